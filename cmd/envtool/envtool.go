@@ -106,7 +106,7 @@ func setupAnyPostgres(ctx context.Context, logger *zap.SugaredLogger, uri string
 		return err
 	}
 
-	p, err := pool.New(uri, logger.Desugar(), sp)
+	p, err := pool.New(uri, logger.Desugar(), sp, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -451,6 +451,13 @@ var cli struct {
 			Dst string `arg:"" help:"Destination, one of: 'seed', 'generated', or collected corpus' directory."`
 		} `cmd:"" help:"Sync fuzz corpora."`
 	} `cmd:""`
+
+	Replay struct {
+		Dir        string `arg:"" help:"Directory with recorded .bin files." type:"existingdir"`
+		TargetAddr string `default:"127.0.0.1:27017" help:"Target (FerretDB) address."`
+		CompatAddr string `default:"127.0.0.1:47017" help:"Compat (MongoDB) address."`
+		Limit      int    `default:"0" help:"Number of randomly selected files to replay; 0 for all."`
+	} `cmd:"" help:"Replay recorded traffic against target and compat, and compare responses."`
 }
 
 // makeLogger returns a human-friendly logger.
@@ -578,6 +585,12 @@ func main() {
 
 		err = fuzzCopyCorpus(src, dst, logger)
 
+	case "replay <dir>":
+		ctx, stop := ctxutil.SigTerm(context.Background())
+		defer stop()
+
+		err = replay(ctx, cli.Replay.Dir, cli.Replay.Limit, cli.Replay.TargetAddr, cli.Replay.CompatAddr, os.Stdout, logger)
+
 	default:
 		err = fmt.Errorf("unknown command: %s", cmd)
 	}
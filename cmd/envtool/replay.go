@@ -0,0 +1,439 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// replayTimeout is the maximum time for sending a single request and receiving a response.
+const replayTimeout = 30 * time.Second
+
+// replaySkippedCommands contains commands that are not replayed.
+//
+// Recorded authentication conversations can't be replayed because server nonces differ,
+// so services should be started without authentication.
+var replaySkippedCommands = []string{
+	"authenticate",
+	"saslContinue",
+	"saslStart",
+}
+
+// replayIgnoredFields contains top-level response fields
+// that are expected to be different between services and runs.
+var replayIgnoredFields = []string{
+	"$clusterTime",
+	"connectionId",
+	"electionId",
+	"lastWrite",
+	"localTime",
+	"operationTime",
+	"topologyVersion",
+}
+
+// replayServices contains names of services used in the output.
+var replayServices = [2]string{"target", "compat"}
+
+// replayResult represents replay statistics.
+type replayResult struct {
+	files     int
+	requests  int
+	skipped   int
+	different int
+}
+
+// replayConn represents a connection to the wire protocol compatible service.
+type replayConn struct {
+	conn net.Conn
+	bufr *bufio.Reader
+	bufw *bufio.Writer
+}
+
+// dialReplay connects to the service with given address.
+func dialReplay(ctx context.Context, addr string) (*replayConn, error) {
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &replayConn{
+		conn: conn,
+		bufr: bufio.NewReader(conn),
+		bufw: bufio.NewWriter(conn),
+	}, nil
+}
+
+// roundTrip sends the request and returns the response document.
+//
+// If noResponse is true, the response is not read, and nil is returned.
+func (c *replayConn) roundTrip(header *wire.MsgHeader, body wire.MsgBody, noResponse bool) (*types.Document, error) {
+	if err := c.conn.SetDeadline(time.Now().Add(replayTimeout)); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err := wire.WriteMessage(c.bufw, header, body); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err := c.bufw.Flush(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if noResponse {
+		return nil, nil
+	}
+
+	resHeader, resBody, err := wire.ReadMessage(c.bufr)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if resHeader.ResponseTo != header.RequestID {
+		return nil, lazyerrors.Errorf("expected response to %d, got %d", header.RequestID, resHeader.ResponseTo)
+	}
+
+	var doc *types.Document
+
+	switch resBody := resBody.(type) {
+	case *wire.OpMsg:
+		doc, err = resBody.Document()
+	case *wire.OpReply:
+		doc, err = resBody.Document()
+	default:
+		return nil, lazyerrors.Errorf("unexpected response %s", resHeader.OpCode)
+	}
+
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return doc, nil
+}
+
+// close closes the connection.
+func (c *replayConn) close() {
+	_ = c.conn.Close()
+}
+
+// replayCursors maps recorded cursor IDs to cursor IDs of target and compat services.
+//
+// Recorded responses are not available, so recorded cursor IDs are mapped
+// to cursors returned by services in order of their first use.
+type replayCursors struct {
+	ids     map[int64][2]int64
+	pending [][2]int64
+}
+
+// add adds cursor IDs returned by services for the same request.
+func (rc *replayCursors) add(ids [2]int64) {
+	if ids == [2]int64{} {
+		return
+	}
+
+	rc.pending = append(rc.pending, ids)
+}
+
+// get returns the cursor ID of the given service for the recorded cursor ID.
+func (rc *replayCursors) get(recorded int64, service int) int64 {
+	if rc.ids == nil {
+		rc.ids = map[int64][2]int64{}
+	}
+
+	ids, ok := rc.ids[recorded]
+	if !ok {
+		if len(rc.pending) == 0 {
+			return recorded
+		}
+
+		ids, rc.pending = rc.pending[0], rc.pending[1:]
+		rc.ids[recorded] = ids
+	}
+
+	return ids[service]
+}
+
+// rewrite replaces recorded cursor IDs in getMore and killCursors commands
+// with cursor IDs of the given service.
+// It returns a new document and does not modify the given one.
+func (rc *replayCursors) rewrite(doc *types.Document, service int) *types.Document {
+	doc = doc.DeepCopy()
+
+	switch doc.Command() {
+	case "getMore":
+		if id, ok := must.NotFail(doc.Get("getMore")).(int64); ok {
+			doc.Set("getMore", rc.get(id, service))
+		}
+
+	case "killCursors":
+		v, _ := doc.Get("cursors")
+
+		cursors, ok := v.(*types.Array)
+		if !ok {
+			break
+		}
+
+		res := types.MakeArray(cursors.Len())
+
+		iter := cursors.Iterator()
+		defer iter.Close()
+
+		for {
+			_, v, err := iter.Next()
+			if err != nil {
+				break
+			}
+
+			if id, ok := v.(int64); ok {
+				v = rc.get(id, service)
+			}
+
+			res.Append(v)
+		}
+
+		doc.Set("cursors", res)
+	}
+
+	return doc
+}
+
+// replayCursorID returns cursor ID from the response document, or 0.
+func replayCursorID(doc *types.Document) int64 {
+	v, _ := doc.Get("cursor")
+
+	cursor, ok := v.(*types.Document)
+	if !ok {
+		return 0
+	}
+
+	id, _ := cursor.Get("id")
+	res, _ := id.(int64)
+
+	return res
+}
+
+// replayNormalize returns a copy of the response document without fields
+// that are expected to be different, with non-zero cursor ID replaced by 1.
+func replayNormalize(doc *types.Document) *types.Document {
+	if doc == nil {
+		return nil
+	}
+
+	doc = doc.DeepCopy()
+
+	for _, f := range replayIgnoredFields {
+		doc.Remove(f)
+	}
+
+	if replayCursorID(doc) != 0 {
+		cursor := must.NotFail(doc.Get("cursor")).(*types.Document)
+		cursor.Set("id", int64(1))
+	}
+
+	return doc
+}
+
+// replayDump returns a readable representation of the document.
+func replayDump(doc *types.Document) string {
+	if doc == nil {
+		return "<nil>\n"
+	}
+
+	return bson.LogMessageBlock(must.NotFail(bson.ConvertDocument(doc))) + "\n"
+}
+
+// replayFile replays all records of a single recorded connection
+// using a new connection to each service, and writes differences between responses to w.
+func replayFile(ctx context.Context, records []wire.Record, addrs [2]string, w io.Writer, res *replayResult) error {
+	var conns [2]*replayConn
+
+	for i, addr := range addrs {
+		conn, err := dialReplay(ctx, addr)
+		if err != nil {
+			return lazyerrors.Errorf("%s: %w", replayServices[i], err)
+		}
+
+		defer conn.close()
+
+		conns[i] = conn
+	}
+
+	var cursors replayCursors
+
+	for n, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		res.requests++
+
+		var req *types.Document
+		var flags wire.OpMsgFlags
+
+		switch body := rec.Body.(type) {
+		case *wire.OpMsg:
+			var err error
+			if req, err = body.Document(); err != nil {
+				res.skipped++
+				continue
+			}
+
+			// checksum is not recalculated, and exhaust cursors are not supported
+			flags = body.Flags & wire.OpMsgFlags(wire.OpMsgMoreToCome)
+
+		case *wire.OpQuery:
+			req = body.Query()
+
+		default:
+			// invalid or unsupported message
+			res.skipped++
+			continue
+		}
+
+		if req == nil || slices.Contains(replaySkippedCommands, req.Command()) {
+			res.skipped++
+			continue
+		}
+
+		// signed cluster time is not valid for other services
+		req.Remove("$clusterTime")
+
+		var docs [2]*types.Document
+		var ids [2]int64
+
+		for i, conn := range conns {
+			header := *rec.Header
+			var body wire.MsgBody = rec.Body
+
+			if header.OpCode == wire.OpCodeMsg {
+				msg := &wire.OpMsg{Flags: flags}
+				if err := msg.SetSections(wire.MakeOpMsgSection(cursors.rewrite(req, i))); err != nil {
+					return lazyerrors.Error(err)
+				}
+
+				b, err := msg.MarshalBinary()
+				if err != nil {
+					return lazyerrors.Error(err)
+				}
+
+				header.MessageLength = int32(wire.MsgHeaderLen + len(b))
+				body = msg
+			}
+
+			doc, err := conn.roundTrip(&header, body, flags.FlagSet(wire.OpMsgMoreToCome))
+			if err != nil {
+				return lazyerrors.Errorf("%s: %s: %w", replayServices[i], req.Command(), err)
+			}
+
+			docs[i] = doc
+
+			if doc != nil {
+				ids[i] = replayCursorID(doc)
+			}
+		}
+
+		cursors.add(ids)
+
+		a, b := replayDump(replayNormalize(docs[0])), replayDump(replayNormalize(docs[1]))
+		if a == b {
+			continue
+		}
+
+		res.different++
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(a),
+			FromFile: replayServices[0],
+			B:        difflib.SplitLines(b),
+			ToFile:   replayServices[1],
+			Context:  1,
+		})
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		_, err = fmt.Fprintf(
+			w, "%s, request %d: %s\n%s\n",
+			rec.Path, n, bson.LogMessageFlow(must.NotFail(bson.ConvertDocument(req))), diff,
+		)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// replay replays recorded wire protocol requests from dir against target and compat services
+// and writes differences between their responses to w.
+//
+// Requests recorded by a single connection are replayed sequentially by a single connection to each service.
+// If limit is positive, only that number of randomly selected files are replayed.
+func replay(ctx context.Context, dir string, limit int, targetAddr, compatAddr string, w io.Writer, logger *zap.SugaredLogger) error {
+	records, err := wire.LoadRecords(dir, limit)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if len(records) == 0 {
+		return fmt.Errorf("no records found in %s", dir)
+	}
+
+	var res replayResult
+	addrs := [2]string{targetAddr, compatAddr}
+
+	for len(records) > 0 {
+		i := 1
+		for i < len(records) && records[i].Path == records[0].Path {
+			i++
+		}
+
+		logger.Debugf("Replaying %d requests from %s.", i, records[0].Path)
+
+		if err = replayFile(ctx, records[:i], addrs, w, &res); err != nil {
+			return lazyerrors.Errorf("%s: %w", records[0].Path, err)
+		}
+
+		res.files++
+		records = records[i:]
+	}
+
+	logger.Infof(
+		"Replayed %d requests from %d files (%d skipped); %d responses are different.",
+		res.requests, res.files, res.skipped, res.different,
+	)
+
+	if res.different > 0 {
+		return fmt.Errorf("found %d different responses", res.different)
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// replayTestMessage returns OP_MSG message (header and body) with the given request ID and document.
+func replayTestMessage(requestID int32, doc *types.Document) []byte {
+	var msg wire.OpMsg
+	must.NoError(msg.SetSections(wire.MakeOpMsgSection(doc)))
+
+	body := must.NotFail(msg.MarshalBinary())
+
+	header := must.NotFail((&wire.MsgHeader{
+		MessageLength: int32(wire.MsgHeaderLen + len(body)),
+		RequestID:     requestID,
+		OpCode:        wire.OpCodeMsg,
+	}).MarshalBinary())
+
+	return append(header, body...)
+}
+
+// replayTestServer starts a service that responds to OP_MSG requests using the given function.
+// It returns its address.
+func replayTestServer(t *testing.T, f func(req *types.Document) *types.Document) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, l.Close())
+	})
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				bufr := bufio.NewReader(conn)
+				bufw := bufio.NewWriter(conn)
+
+				for {
+					reqHeader, reqBody, err := wire.ReadMessage(bufr)
+					if err != nil {
+						return
+					}
+
+					req := must.NotFail(reqBody.(*wire.OpMsg).Document())
+
+					var res wire.OpMsg
+					must.NoError(res.SetSections(wire.MakeOpMsgSection(f(req))))
+
+					b := must.NotFail(res.MarshalBinary())
+
+					resHeader := &wire.MsgHeader{
+						MessageLength: int32(wire.MsgHeaderLen + len(b)),
+						RequestID:     reqHeader.RequestID + 1000,
+						ResponseTo:    reqHeader.RequestID,
+						OpCode:        wire.OpCodeMsg,
+					}
+
+					if wire.WriteMessage(bufw, resHeader, &res) != nil || bufw.Flush() != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "ab"), 0o777))
+
+	var b []byte
+	b = append(b, replayTestMessage(1, must.NotFail(types.NewDocument("saslStart", int32(1), "$db", "admin")))...)
+	b = append(b, replayTestMessage(2, must.NotFail(types.NewDocument("find", "test", "$db", "db")))...)
+	b = append(b, replayTestMessage(3, must.NotFail(types.NewDocument("getMore", int64(42), "$db", "db")))...)
+	b = append(b, replayTestMessage(4, must.NotFail(types.NewDocument(
+		"ping", int32(1),
+		"$db", "db",
+		"$clusterTime", must.NotFail(types.NewDocument("clusterTime", types.Timestamp(42))),
+	)))...)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ab", "abcd.bin"), b, 0o666))
+
+	// both services return different cursor IDs and expect them in getMore
+	service := func(cursorID int64, version string) func(req *types.Document) *types.Document {
+		return func(req *types.Document) *types.Document {
+			assert.False(t, req.Has("$clusterTime"))
+
+			switch req.Command() {
+			case "find":
+				return must.NotFail(types.NewDocument(
+					"cursor", must.NotFail(types.NewDocument("firstBatch", new(types.Array), "id", cursorID, "ns", "db.test")),
+					"ok", float64(1),
+				))
+
+			case "getMore":
+				assert.Equal(t, cursorID, must.NotFail(req.Get("getMore")))
+
+				return must.NotFail(types.NewDocument(
+					"cursor", must.NotFail(types.NewDocument("nextBatch", new(types.Array), "id", int64(0), "ns", "db.test")),
+					"ok", float64(1),
+				))
+
+			case "ping":
+				return must.NotFail(types.NewDocument("version", version, "localTime", version, "ok", float64(1)))
+
+			default:
+				t.Errorf("unexpected command %q", req.Command())
+
+				return must.NotFail(types.NewDocument("ok", float64(0)))
+			}
+		}
+	}
+
+	target := replayTestServer(t, service(1234, "7.0.42"))
+	compat := replayTestServer(t, service(5678, "7.0.5"))
+
+	var out bytes.Buffer
+	err := replay(ctx, dir, 0, target, compat, &out, zap.NewNop().Sugar())
+	require.Error(t, err)
+	assert.Equal(t, "found 1 different responses", err.Error())
+
+	actual := out.String()
+	t.Log(actual)
+
+	assert.True(t, strings.HasPrefix(actual, filepath.Join(dir, "ab", "abcd.bin")+", request 3: "), "%s", actual)
+	assert.Contains(t, actual, `-  "version": "7.0.42",`)
+	assert.Contains(t, actual, `+  "version": "7.0.5",`)
+	assert.NotContains(t, actual, "localTime")
+
+	t.Run("NoRecords", func(t *testing.T) {
+		t.Parallel()
+
+		err := replay(ctx, t.TempDir(), 0, target, compat, &out, zap.NewNop().Sugar())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no records found")
+	})
+}
@@ -16,8 +16,11 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -34,17 +37,23 @@ import (
 	_ "golang.org/x/crypto/x509roots/fallback" // register root TLS certificates for production Docker image
 
 	"github.com/FerretDB/FerretDB/build/version"
+	"github.com/FerretDB/FerretDB/internal/backends/decorators/offload"
 	"github.com/FerretDB/FerretDB/internal/clientconn"
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/dataapi"
+	"github.com/FerretDB/FerretDB/internal/handler"
 	"github.com/FerretDB/FerretDB/internal/handler/registry"
 	"github.com/FerretDB/FerretDB/internal/util/ctxutil"
 	"github.com/FerretDB/FerretDB/internal/util/debug"
 	"github.com/FerretDB/FerretDB/internal/util/debugbuild"
 	"github.com/FerretDB/FerretDB/internal/util/logging"
 	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/observability"
 	"github.com/FerretDB/FerretDB/internal/util/password"
+	"github.com/FerretDB/FerretDB/internal/util/reloader"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 	"github.com/FerretDB/FerretDB/internal/util/telemetry"
+	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // The cli struct represents all command-line commands, fields and flags.
@@ -62,6 +71,8 @@ var cli struct {
 	StateDir    string `default:"."               help:"Process state directory."`
 	ReplSetName string `default:""                help:"Replica set name."`
 
+	Config kong.ConfigFlag `help:"JSON configuration file path; reloaded on SIGHUP."`
+
 	Listen struct {
 		Addr        string `default:"127.0.0.1:27017" help:"Listen TCP address."`
 		Unix        string `default:""                help:"Listen Unix domain socket path."`
@@ -69,6 +80,7 @@ var cli struct {
 		TLSCertFile string `default:""                help:"TLS cert file path."`
 		TLSKeyFile  string `default:""                help:"TLS key file path."`
 		TLSCaFile   string `default:""                help:"TLS CA file path."`
+		MaxConns    int    `default:"0"               help:"Maximum number of client connections; 0 means no limit." name:"max-connections"`
 	} `embed:"" prefix:"listen-"`
 
 	Proxy struct {
@@ -78,7 +90,13 @@ var cli struct {
 		TLSCaFile   string `default:"" help:"Proxy TLS CA file path."`
 	} `embed:"" prefix:"proxy-"`
 
-	DebugAddr string `default:"127.0.0.1:8088" help:"Listen address for HTTP handlers for metrics, pprof, etc."`
+	DebugAddr  string `default:"127.0.0.1:8088" help:"Listen address for HTTP handlers for metrics, pprof, etc."`
+	DebugToken string `default:""               help:"Token required for debug state pages."`
+
+	DataAPI struct {
+		Addr string   `default:"" help:"Listen address for HTTP Data API; disabled if empty."`
+		Keys []string `help:"Data API keys; at least one is required if enabled."`
+	} `embed:"" prefix:"data-api-"`
 
 	// see setCLIPlugins
 	kong.Plugins
@@ -90,15 +108,58 @@ var cli struct {
 		Timeout  time.Duration `default:"30s" help:"Setup timeout."`
 	} `embed:"" prefix:"setup-"`
 
+	Compat struct {
+		MongoDBVersion string `default:""                          help:"MongoDB version reported to clients; empty for the default one." name:"mongodb-version"`
+		Profile        string `default:"${default_compat_profile}" help:"${help_compat_profile}"                                          enum:"${enum_compat_profile}"`
+	} `embed:"" prefix:"compat-"`
+
+	GridFSBuckets []string `default:"fs" help:"GridFS buckets which file chunks are streamed without sorting in memory." name:"gridfs-buckets"`
+
+	OperationTimeout time.Duration `default:"0s" help:"Default time limit for operations without maxTimeMS; 0 means no limit."`
+
+	BSONValidation string `default:"${default_bson_validation}" help:"${help_bson_validation}" enum:"${enum_bson_validation}" name:"bson-validation"`
+
+	Offload struct {
+		ThresholdKiB int `default:"1024" help:"Offload binary values larger than that size in KiB." name:"threshold"`
+
+		S3 struct {
+			Endpoint        string `default:""          help:"S3-compatible object storage endpoint URL; offloading is disabled if empty."`
+			Region          string `default:"us-east-1" help:"S3 region."`
+			Bucket          string `default:""          help:"S3 bucket."`
+			Prefix          string `default:""          help:"S3 object key prefix."`
+			AccessKeyID     string `default:""          help:"S3 access key ID."                                                            name:"access-key-id"`
+			SecretAccessKey string `default:""          help:"S3 secret access key."`
+		} `embed:"" prefix:"s3-"`
+	} `embed:"" prefix:"offload-"`
+
 	Log struct {
 		Level  string `default:"${default_log_level}" help:"${help_log_level}"`
 		Format string `default:"console"              help:"${help_log_format}"                     enum:"${enum_log_format}"`
 		UUID   bool   `default:"false"                help:"Add instance UUID to all log messages." negatable:""`
+		Syslog string `default:""                     help:"${help_log_syslog}"`
+
+		SampleDebug bool `default:"false" help:"Sample repeated debug log messages."`
+
+		File           string        `default:""   help:"Log file path; if empty, logs are written to the standard error stream."`
+		FileMaxSizeMiB int64         `default:"0"  help:"Rotate log file when it exceeds that size in MiB; 0 disables."       name:"file-max-size"`
+		FileMaxAge     time.Duration `default:"0s" help:"Rotate log file when it is older than that; 0 disables."`
+		FileMaxBackups int           `default:"0"  help:"Maximum number of rotated log files to keep; 0 keeps all."`
 	} `embed:"" prefix:"log-"`
 
+	OTel struct {
+		Traces struct {
+			URL string `default:"" help:"OpenTelemetry OTLP/HTTP traces endpoint URL (e.g. 'http://host:4318/v1/traces')."`
+		} `embed:"" prefix:"traces-"`
+		Logs struct {
+			URL string `default:"" help:"OpenTelemetry OTLP/HTTP logs endpoint URL (e.g. 'http://host:4318/v1/logs')."`
+		} `embed:"" prefix:"logs-"`
+	} `embed:"" prefix:"otel-"`
+
 	MetricsUUID bool `default:"false" help:"Add instance UUID to all metrics." negatable:""`
 
-	Telemetry telemetry.Flag `default:"undecided" help:"Enable or disable basic telemetry. See https://beacon.ferretdb.com."`
+	Telemetry      telemetry.Flag `default:"undecided"                 help:"Enable or disable basic telemetry. See https://beacon.ferretdb.com."`
+	TelemetryLevel string         `default:"${default_telemetry_level}" help:"${help_telemetry_level}"                                            enum:"${enum_telemetry_level}"`
+	TelemetryFile  string         `default:""                          help:"Write telemetry reports to that file instead of sending them."`
 
 	Test struct {
 		RecordsDir string `default:"" help:"Testing: directory for record files."`
@@ -113,8 +174,11 @@ var cli struct {
 
 		EnableNewAuth bool `default:"false" help:"Experimental: enable new authentication."`
 
-		BatchSize            int `default:"100" help:"Experimental: maximum insertion batch size."`
+		BatchSize            int `default:"100" help:"Experimental: maximum write batch size."`
 		MaxBsonObjectSizeMiB int `default:"16"  help:"Experimental: maximum BSON object size in MiB."`
+		SortMemoryLimitMiB   int `default:"100" help:"Experimental: maximum memory used by a single sort in MiB."`
+
+		ExactCount bool `default:"false" help:"Experimental: count documents exactly instead of using table statistics."`
 
 		Telemetry struct {
 			URL            string        `default:"https://beacon.ferretdb.com/" help:"Telemetry: reporting URL."`
@@ -132,7 +196,12 @@ var cli struct {
 //
 //nolint:lll // some tags are long
 var postgreSQLFlags struct {
-	PostgreSQLURL string `name:"postgresql-url" default:"postgres://127.0.0.1:5432/ferretdb" help:"PostgreSQL URL for 'postgresql' handler."`
+	PostgreSQLURL              string        `name:"postgresql-url"                default:"postgres://127.0.0.1:5432/ferretdb" help:"PostgreSQL URL for 'postgresql' handler."`
+	PostgreSQLSlowQuery        time.Duration `name:"postgresql-slow-query"         default:"0s"                                 help:"Log PostgreSQL queries slower than that (0 to disable)."`
+	PostgreSQLSlowQueryExplain bool          `name:"postgresql-slow-query-explain" default:"false"                              help:"Log query plans of slow PostgreSQL queries."`
+	PostgreSQLSQLViews         bool          `name:"postgresql-sql-views"          default:"false"                              help:"Maintain read-only SQL views of collections for BI tools."`
+	PostgreSQLMinConns         int32         `name:"postgresql-min-conns"          default:"0"                                  help:"Minimal number of PostgreSQL connections to open on startup and keep (0 to disable)."`
+	PostgreSQLPingInterval     time.Duration `name:"postgresql-ping-interval"      default:"0s"                                 help:"Ping idle PostgreSQL connections that often (0 to disable)."`
 }
 
 // The sqliteFlags struct represents flags that are used by the "sqlite" backend.
@@ -186,7 +255,7 @@ var (
 		zap.ErrorLevel.String(),
 	}
 
-	logFormats = []string{"console", "json"}
+	logFormats = []string{"console", "json", "mongo"}
 
 	kongOptions = []kong.Option{
 		kong.HelpOptions{
@@ -196,15 +265,38 @@ var (
 			"default_log_level": defaultLogLevel().String(),
 			"default_mode":      clientconn.AllModes[0],
 
-			"enum_log_format": strings.Join(logFormats, ","),
-			"enum_mode":       strings.Join(clientconn.AllModes, ","),
+			"default_compat_profile":  handler.AllCompatProfiles[0],
+			"default_bson_validation": wire.AllValidationLevels[0],
+			"default_telemetry_level": telemetry.AllLevels[0],
+
+			"enum_log_format":      strings.Join(logFormats, ","),
+			"enum_mode":            strings.Join(clientconn.AllModes, ","),
+			"enum_compat_profile":  strings.Join(handler.AllCompatProfiles, ","),
+			"enum_bson_validation": strings.Join(wire.AllValidationLevels, ","),
+			"enum_telemetry_level": strings.Join(telemetry.AllLevels, ","),
 
 			"help_handler":    fmt.Sprintf("Backend handler: '%s'.", strings.Join(registry.Handlers(), "', '")),
 			"help_log_format": fmt.Sprintf("Log format: '%s'.", strings.Join(logFormats, "', '")),
 			"help_log_level":  fmt.Sprintf("Log level: '%s'.", strings.Join(logLevels, "', '")),
+			"help_log_syslog": "Also send logs to syslog: 'local' socket, 'udp://host:port', or 'tcp://host:port'.",
 			"help_mode":       fmt.Sprintf("Operation mode: '%s'.", strings.Join(clientconn.AllModes, "', '")),
+
+			"help_telemetry_level": fmt.Sprintf(
+				"Basic telemetry level: '%s' (all data, command names only, or failed commands only).",
+				strings.Join(telemetry.AllLevels, "', '"),
+			),
+			"help_bson_validation": fmt.Sprintf(
+				"Validation level of incoming BSON documents: '%s'.",
+				strings.Join(wire.AllValidationLevels, "', '"),
+			),
+
+			"help_compat_profile": fmt.Sprintf(
+				"Compatibility profile: '%s'; 'mongodb' omits FerretDB-specific response fields.",
+				strings.Join(handler.AllCompatProfiles, "', '"),
+			),
 		},
 		kong.DefaultEnvars("FERRETDB"),
+		kong.Configuration(kong.JSON),
 	}
 )
 
@@ -270,8 +362,105 @@ func setupMetrics(stateProvider *state.Provider) prometheus.Registerer {
 	return r
 }
 
+// setupOTLPLogs setups OTLP log handler.
+//
+// The returned function flushes buffered records and should be called on exit.
+func setupOTLPLogs() (slog.Handler, func()) {
+	level, err := zapcore.ParseLevel(cli.Log.Level)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	h, err := logging.NewOTLPHandler(&logging.NewOTLPHandlerOpts{
+		URL:     cli.OTel.Logs.URL,
+		Level:   logging.SlogLevel(level),
+		Service: "ferretdb",
+		Version: version.Get().Version,
+	})
+	if err != nil {
+		log.Fatalf("Failed to setup OTLP logs: %s.", err)
+	}
+
+	return h, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_ = h.Shutdown(ctx)
+	}
+}
+
+// setupSyslog setups syslog log handler.
+//
+// The returned function closes the connection and should be called on exit.
+func setupSyslog() (slog.Handler, func()) {
+	level, err := zapcore.ParseLevel(cli.Log.Level)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := &logging.NewSyslogHandlerOpts{
+		Level:   logging.SlogLevel(level),
+		AppName: "ferretdb",
+	}
+
+	if addr := cli.Log.Syslog; addr != "local" {
+		var ok bool
+		if opts.Network, opts.Addr, ok = strings.Cut(addr, "://"); !ok {
+			log.Fatalf("Invalid syslog address %q.", addr)
+		}
+	}
+
+	h, err := logging.NewSyslogHandler(opts)
+	if err != nil {
+		log.Fatalf("Failed to setup syslog: %s.", err)
+	}
+
+	return h, func() {
+		_ = h.Close()
+	}
+}
+
+// setupLogFile opens log file with rotation.
+//
+// The returned file should be closed on exit.
+func setupLogFile() *logging.RotatingFile {
+	f, err := logging.NewRotatingFile(&logging.NewRotatingFileOpts{
+		Path:       cli.Log.File,
+		MaxSize:    cli.Log.FileMaxSizeMiB * 1024 * 1024,
+		MaxAge:     cli.Log.FileMaxAge,
+		MaxBackups: cli.Log.FileMaxBackups,
+	})
+	if err != nil {
+		log.Fatalf("Failed to open log file: %s.", err)
+	}
+
+	return f
+}
+
+// reopenLogFileOnSigHup reopens log file on SIGHUP (for example, after logrotate) until ctx is done.
+func reopenLogFileOnSigHup(ctx context.Context, f *logging.RotatingFile, l *zap.Logger) {
+	sigHup := ctxutil.SigHup(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigHup:
+			if err := f.Reopen(); err != nil {
+				l.Sugar().Errorf("Failed to reopen log file: %s.", err)
+				continue
+			}
+
+			l.Info("Log file reopened.")
+		}
+	}
+}
+
 // setupLogger setups zap logger.
-func setupLogger(stateProvider *state.Provider, format string) *zap.Logger {
+//
+// If output is nil, logs are written to the standard error stream.
+// Additional handlers receive all log records in addition to the output.
+func setupLogger(stateProvider *state.Provider, format string, output io.Writer, handlers ...slog.Handler) *zap.Logger {
 	info := version.Get()
 
 	startupFields := []zap.Field{
@@ -296,7 +485,14 @@ func setupLogger(stateProvider *state.Provider, format string) *zap.Logger {
 		log.Fatal(err)
 	}
 
-	logging.Setup(level, format, logUUID)
+	logging.SetupWithOpts(&logging.SetupOpts{
+		Level:       level,
+		Encoding:    format,
+		UUID:        logUUID,
+		Output:      output,
+		Handlers:    handlers,
+		SampleDebug: cli.Log.SampleDebug,
+	})
 	l := zap.L()
 
 	l.Info("Starting FerretDB "+info.Version+"...", startupFields...)
@@ -325,6 +521,34 @@ func checkFlags(logger *zap.Logger) {
 	}
 }
 
+// setupOtel setups OpenTelemetry tracing if enabled.
+//
+// The returned function flushes buffered spans and should be called on exit.
+func setupOtel(logger *zap.Logger) func() {
+	u := cli.OTel.Traces.URL
+	if u == "" {
+		return func() {}
+	}
+
+	shutdown, err := observability.SetupOtelWithOpts(&observability.OtelOpts{
+		Service:   "ferretdb",
+		Version:   version.Get().Version,
+		TracesURL: u,
+	})
+	if err != nil {
+		logger.Sugar().Fatalf("Failed to setup OpenTelemetry: %s.", err)
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := shutdown(ctx); err != nil {
+			logger.Sugar().Warnf("Failed to shutdown OpenTelemetry: %s.", err)
+		}
+	}
+}
+
 // dumpMetrics dumps all Prometheus metrics to stderr.
 func dumpMetrics() {
 	mfs := must.NotFail(prometheus.DefaultGatherer.Gather())
@@ -368,10 +592,40 @@ func run() {
 
 	metricsRegisterer := setupMetrics(stateProvider)
 
-	logger := setupLogger(stateProvider, cli.Log.Format)
+	var logHandlers []slog.Handler
+
+	if cli.OTel.Logs.URL != "" {
+		h, shutdownOTLPLogs := setupOTLPLogs()
+		defer shutdownOTLPLogs()
+
+		logHandlers = append(logHandlers, h)
+	}
+
+	if cli.Log.Syslog != "" {
+		h, closeSyslog := setupSyslog()
+		defer closeSyslog()
+
+		logHandlers = append(logHandlers, h)
+	}
+
+	// avoid storing nil *RotatingFile in non-nil io.Writer
+	var logOutput io.Writer
+	var logFile *logging.RotatingFile
+
+	if cli.Log.File != "" {
+		logFile = setupLogFile()
+		defer logFile.Close()
+
+		logOutput = logFile
+	}
+
+	logger := setupLogger(stateProvider, cli.Log.Format, logOutput, logHandlers...)
 
 	checkFlags(logger)
 
+	shutdownOtel := setupOtel(logger)
+	defer shutdownOtel()
+
 	if _, err := maxprocs.Set(maxprocs.Logger(logger.Sugar().Debugf)); err != nil {
 		logger.Sugar().Warnf("Failed to set GOMAXPROCS: %s.", err)
 	}
@@ -384,6 +638,10 @@ func run() {
 		stop()
 	}()
 
+	if logFile != nil {
+		go reopenLogFileOnSigHup(ctx, logFile, logger)
+	}
+
 	var wg sync.WaitGroup
 
 	if cli.DebugAddr != "" && cli.DebugAddr != "-" {
@@ -398,6 +656,7 @@ func run() {
 				TCPAddr: cli.DebugAddr,
 				L:       l,
 				R:       metricsRegisterer,
+				Token:   cli.DebugToken,
 			})
 			if err != nil {
 				l.Sugar().Fatalf("Failed to create debug handler: %s.", err)
@@ -426,6 +685,8 @@ func run() {
 			UndecidedDelay: cli.Test.Telemetry.UndecidedDelay,
 			ReportInterval: cli.Test.Telemetry.ReportInterval,
 			ReportTimeout:  cli.Test.Telemetry.ReportTimeout,
+			Level:          telemetry.Level(cli.TelemetryLevel),
+			File:           cli.TelemetryFile,
 		}
 
 		r, err := telemetry.NewReporter(opts)
@@ -436,10 +697,48 @@ func run() {
 		r.Run(ctx)
 	}()
 
+	var offloadStorage offload.Storage
+
+	if cli.Offload.S3.Endpoint != "" {
+		var err error
+
+		offloadStorage, err = offload.NewS3(&offload.S3Params{
+			Endpoint:        cli.Offload.S3.Endpoint,
+			Region:          cli.Offload.S3.Region,
+			Bucket:          cli.Offload.S3.Bucket,
+			Prefix:          cli.Offload.S3.Prefix,
+			AccessKeyID:     cli.Offload.S3.AccessKeyID,
+			SecretAccessKey: cli.Offload.S3.SecretAccessKey,
+		})
+		if err != nil {
+			logger.Sugar().Fatalf("Failed to create offload storage: %s.", err)
+		}
+	}
+
+	// empty slice (not nil) disables GridFS optimizations
+	gridFSBuckets := []string{}
+
+	for _, b := range cli.GridFSBuckets {
+		if b != "" {
+			gridFSBuckets = append(gridFSBuckets, b)
+		}
+	}
+
+	// listener and other components are set below, before the first reload
+	cr := &configReloader{
+		current: currentReloadableConfig(),
+		slowQuery: observability.NewSlowQueryOpts(
+			postgreSQLFlags.PostgreSQLSlowQuery,
+			postgreSQLFlags.PostgreSQLSlowQueryExplain,
+		),
+	}
+	rl := reloader.New(cr.reload, logger)
+
 	h, closeBackend, err := registry.NewHandler(cli.Handler, &registry.NewHandlerOpts{
 		Logger:        logger,
 		ConnMetrics:   metrics.ConnMetrics,
 		StateProvider: stateProvider,
+		Reloader:      rl,
 		TCPHost:       cli.Listen.Addr,
 		ReplSetName:   cli.ReplSetName,
 
@@ -448,7 +747,21 @@ func run() {
 		SetupPassword: password.WrapPassword(cli.Setup.Password),
 		SetupTimeout:  cli.Setup.Timeout,
 
-		PostgreSQLURL: postgreSQLFlags.PostgreSQLURL,
+		MongoDBVersion: cli.Compat.MongoDBVersion,
+		CompatProfile:  handler.CompatProfile(cli.Compat.Profile),
+
+		OffloadStorage:   offloadStorage,
+		OffloadThreshold: cli.Offload.ThresholdKiB * 1024, //nolint:mnd // converting KiB to bytes
+
+		GridFSBuckets: gridFSBuckets,
+
+		OperationTimeout: cli.OperationTimeout,
+
+		PostgreSQLURL:          postgreSQLFlags.PostgreSQLURL,
+		PostgreSQLSlowQuery:    cr.slowQuery,
+		PostgreSQLSQLViews:     postgreSQLFlags.PostgreSQLSQLViews,
+		PostgreSQLMinConns:     postgreSQLFlags.PostgreSQLMinConns,
+		PostgreSQLPingInterval: postgreSQLFlags.PostgreSQLPingInterval,
 
 		SQLiteURL: sqliteFlags.SQLiteURL,
 
@@ -464,6 +777,8 @@ func run() {
 			EnableNewAuth:           cli.Test.EnableNewAuth,
 			BatchSize:               cli.Test.BatchSize,
 			MaxBsonObjectSizeBytes:  cli.Test.MaxBsonObjectSizeMiB * 1024 * 1024, //nolint:mnd // converting MiB to bytes
+			SortMemoryLimitBytes:    cli.Test.SortMemoryLimitMiB * 1024 * 1024,   //nolint:mnd // converting MiB to bytes
+			ExactCount:              cli.Test.ExactCount,
 		},
 	})
 	if err != nil {
@@ -486,7 +801,9 @@ func run() {
 		ProxyTLSKeyFile:  cli.Proxy.TLSKeyFile,
 		ProxyTLSCAFile:   cli.Proxy.TLSCaFile,
 
+		MaxConns:       cli.Listen.MaxConns,
 		Mode:           clientconn.Mode(cli.Mode),
+		BSONValidation: wire.ValidationLevel(cli.BSONValidation),
 		Metrics:        metrics,
 		Handler:        h,
 		Logger:         logger,
@@ -498,6 +815,56 @@ func run() {
 
 	metricsRegisterer.MustRegister(l)
 
+	debug.RegisterState("pool", "Backend connection pool statistics", debug.CollectorState(h.BackendCollector()))
+	debug.RegisterState("cursors", "Open cursors", func() (any, error) { return h.Cursors(), nil })
+	debug.RegisterState("conns", "Client connections", func() (any, error) { return l.Conns(), nil })
+	debug.RegisterState("operations", "In-flight operations", func() (any, error) { return l.Operations(), nil })
+
+	debug.RegisterProbe("backend", h.Ping)
+	debug.RegisterProbe("listener", func(context.Context) error {
+		// listener is bound at that point; report not ready while stopping
+		if ctx.Err() != nil {
+			return errors.New("listener is stopping")
+		}
+
+		return nil
+	})
+
+	cr.listener = l
+	cr.tls = cli.Listen.TLS != ""
+
+	if cli.DataAPI.Addr != "" {
+		dl := logger.Named("dataapi")
+
+		s, err := dataapi.Listen(&dataapi.ListenOpts{
+			TCPAddr: cli.DataAPI.Addr,
+			L:       dl,
+			Handler: h,
+			APIKeys: cli.DataAPI.Keys,
+		})
+		if err != nil {
+			dl.Sugar().Fatalf("Failed to create Data API server: %s.", err)
+		}
+
+		cr.dataAPI = s
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			s.Serve(ctx)
+		}()
+	}
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		rl.RunOnSigHup(ctx)
+	}()
+
 	l.Run(ctx)
 	logger.Info("Listener stopped")
 
@@ -0,0 +1,163 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn"
+	"github.com/FerretDB/FerretDB/internal/dataapi"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/logging"
+	"github.com/FerretDB/FerretDB/internal/util/observability"
+)
+
+// reloadableConfig contains configuration settings that could be changed at runtime.
+type reloadableConfig struct {
+	LogLevel    string
+	MaxConns    int
+	SlowQuery   time.Duration
+	DataAPIKeys []string
+}
+
+// currentReloadableConfig returns reloadable settings from the configuration parsed on startup.
+func currentReloadableConfig() *reloadableConfig {
+	return &reloadableConfig{
+		LogLevel:    cli.Log.Level,
+		MaxConns:    cli.Listen.MaxConns,
+		SlowQuery:   postgreSQLFlags.PostgreSQLSlowQuery,
+		DataAPIKeys: cli.DataAPI.Keys,
+	}
+}
+
+// loadReloadableConfig parses command-line flags, environment variables and configuration file again
+// and returns reloadable settings.
+//
+// Global flag variables are not modified.
+func loadReloadableConfig() (*reloadableConfig, error) {
+	next := cli
+	reflect.ValueOf(&next).Elem().SetZero()
+
+	// handler flags are pointers to global variables, so use fresh copies
+	next.Plugins = make(kong.Plugins, len(cli.Plugins))
+	for i, p := range cli.Plugins {
+		next.Plugins[i] = reflect.New(reflect.TypeOf(p).Elem()).Interface()
+	}
+
+	parser, err := kong.New(&next, kongOptions...)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if _, err = parser.Parse(os.Args[1:]); err != nil {
+		return nil, err
+	}
+
+	res := &reloadableConfig{
+		LogLevel:    next.Log.Level,
+		MaxConns:    next.Listen.MaxConns,
+		DataAPIKeys: next.DataAPI.Keys,
+	}
+
+	for _, p := range next.Plugins {
+		if reflect.TypeOf(p) == reflect.TypeOf(&postgreSQLFlags) {
+			res.SlowQuery = reflect.ValueOf(p).Elem().FieldByName("PostgreSQLSlowQuery").Interface().(time.Duration)
+		}
+	}
+
+	return res, nil
+}
+
+// configReloader applies reloadable configuration settings to running components.
+//
+// Calls are serialized by [reloader.Reloader].
+type configReloader struct {
+	current *reloadableConfig
+
+	// set before the first reload
+	listener  *clientconn.Listener
+	slowQuery *observability.SlowQueryOpts
+	dataAPI   *dataapi.Server // nil if Data API is disabled
+	tls       bool
+}
+
+// reload parses configuration again, applies changed settings, and returns their descriptions.
+//
+// Nothing is applied if the new configuration is invalid.
+// TLS files are always reloaded if TLS listener is enabled.
+func (r *configReloader) reload() ([]string, error) {
+	next, err := loadReloadableConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	level, err := zapcore.ParseLevel(next.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	if next.MaxConns < 0 {
+		return nil, fmt.Errorf("invalid maximum number of connections: %d", next.MaxConns)
+	}
+
+	if r.dataAPI != nil && len(next.DataAPIKeys) == 0 {
+		return nil, fmt.Errorf("at least one Data API key is required")
+	}
+
+	if r.tls {
+		if err = r.listener.ReloadTLS(); err != nil {
+			return nil, err
+		}
+	}
+
+	var changes []string
+
+	if next.LogLevel != r.current.LogLevel {
+		logging.Verbosity.LevelVar().Set(logging.SlogLevel(level))
+		changes = append(changes, fmt.Sprintf("log-level: %s -> %s", r.current.LogLevel, next.LogLevel))
+	}
+
+	if next.MaxConns != r.current.MaxConns {
+		r.listener.SetMaxConns(next.MaxConns)
+		changes = append(changes, fmt.Sprintf("listen-max-connections: %d -> %d", r.current.MaxConns, next.MaxConns))
+	}
+
+	if next.SlowQuery != r.current.SlowQuery && r.slowQuery != nil {
+		r.slowQuery.SetThreshold(next.SlowQuery)
+		changes = append(changes, fmt.Sprintf("postgresql-slow-query: %s -> %s", r.current.SlowQuery, next.SlowQuery))
+	}
+
+	if !slices.Equal(next.DataAPIKeys, r.current.DataAPIKeys) && r.dataAPI != nil {
+		_ = r.dataAPI.SetAPIKeys(next.DataAPIKeys) // checked above
+
+		// do not log keys themselves
+		changes = append(changes, fmt.Sprintf("data-api-keys: %d -> %d keys", len(r.current.DataAPIKeys), len(next.DataAPIKeys)))
+	}
+
+	if r.tls {
+		changes = append(changes, "listen-tls-*-file: reloaded")
+	}
+
+	r.current = next
+
+	return changes, nil
+}
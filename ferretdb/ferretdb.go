@@ -22,17 +22,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/url"
 	"sync"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/build/version"
 	"github.com/FerretDB/FerretDB/internal/clientconn"
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/handler"
 	"github.com/FerretDB/FerretDB/internal/handler/registry"
 	"github.com/FerretDB/FerretDB/internal/util/logging"
+	"github.com/FerretDB/FerretDB/internal/util/observability"
 	"github.com/FerretDB/FerretDB/internal/util/state"
+	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // Config represents FerretDB configuration.
@@ -42,6 +49,15 @@ type Config struct {
 	// Logger to use; if nil, it uses the default global logger.
 	Logger *zap.Logger
 
+	// Slog logger to use instead of Logger.
+	// If set, all FerretDB logs are sent to its handler, and Logger is ignored.
+	SlogLogger *slog.Logger
+
+	// Minimal level of logs sent to SlogLogger; it could be changed at runtime with [*slog.LevelVar].
+	// If nil, only SlogLogger's handler decides what is logged
+	// until the level is changed with [*FerretDB.Update].
+	SlogLevel slog.Leveler
+
 	// Handler to use; one of `postgresql` or `sqlite`.
 	Handler string
 
@@ -52,9 +68,34 @@ type Config struct {
 	//   - https://pkg.go.dev/github.com/jackc/pgx/v5/pgconn#ParseConfig
 	PostgreSQLURL string // For example: `postgres://hostname:5432/ferretdb`.
 
+	// PostgreSQL queries running longer than that are logged; zero disables logging.
+	// It could be changed at runtime with [*FerretDB.Update].
+	PostgreSQLSlowQuery time.Duration
+
 	// SQLite URI (directory) for `sqlite` handler.
 	// See https://www.sqlite.org/uri.html.
 	SQLiteURL string // For example: `file:data/`.
+
+	// Where `sqlite` handler stores data; if not set, SQLiteURL is used.
+	// Other values are intended for tests that need throwaway databases.
+	SQLiteStorage SQLiteStorage
+
+	// Prometheus registerer for FerretDB metrics; if nil, metrics are not registered.
+	// Pass the registerer of the application's existing /metrics endpoint
+	// (for example, [prometheus.DefaultRegisterer]) to expose them there.
+	// Metrics are unregistered when [*FerretDB.Run] returns.
+	MetricsRegisterer prometheus.Registerer
+
+	// Namespace prefix for FerretDB metrics names registered with MetricsRegisterer.
+	// For example, `myapp` changes `ferretdb_client_requests_total` to `myapp_ferretdb_client_requests_total`.
+	MetricsNamespace string
+
+	// If set, called on every [State] change with the previous and the new state.
+	// The error is set for [StateBackendLost].
+	//
+	// It is called from a goroutine started by [*FerretDB.Run], one call at a time.
+	// It should not block for long.
+	OnStateChange func(from, to State, err error)
 }
 
 // ListenerConfig represents listener configuration.
@@ -79,6 +120,14 @@ type ListenerConfig struct {
 
 	// Root CA certificate path.
 	TLSCAFile string
+
+	// If true, in-process listener is enabled.
+	// Use [*FerretDB.DialContext] to connect to it without any sockets.
+	InProcess bool
+
+	// Maximum number of client connections; zero means no limit.
+	// It could be changed at runtime with [*FerretDB.Update].
+	MaxConnections int
 }
 
 // FerretDB represents an instance of embeddable FerretDB implementation.
@@ -87,7 +136,19 @@ type FerretDB struct {
 
 	closeBackend func()
 
-	l *clientconn.Listener
+	sp  *state.Provider
+	h   *handler.Handler
+	l   *clientconn.Listener
+	log *zap.Logger
+
+	logLevel  *slog.LevelVar // nil if it can't be changed
+	slowQuery *observability.SlowQueryOpts
+
+	metricsRegisterer prometheus.Registerer // nil if metrics are not registered
+	metricsCollectors []prometheus.Collector
+
+	stateM sync.Mutex
+	state  State
 }
 
 // New creates a new instance of embeddable FerretDB implementation.
@@ -96,8 +157,9 @@ func New(config *Config) (*FerretDB, error) {
 
 	if config.Listener.TCP == "" &&
 		config.Listener.Unix == "" &&
-		config.Listener.TLS == "" {
-		return nil, errors.New("Listener TCP, Unix and TLS are empty")
+		config.Listener.TLS == "" &&
+		!config.Listener.InProcess {
+		return nil, errors.New("Listener TCP, Unix and TLS are empty, and InProcess is false")
 	}
 
 	sp, err := state.NewProvider("")
@@ -117,11 +179,36 @@ func New(config *Config) (*FerretDB, error) {
 
 	metrics := connmetrics.NewListenerMetrics()
 
-	log := config.Logger
-	if log == nil {
+	var log *zap.Logger
+	var logLevel *slog.LevelVar
+
+	switch {
+	case config.SlogLogger != nil:
+		level := config.SlogLevel
+
+		switch l := level.(type) {
+		case nil:
+			// the most verbose level of zap
+			logLevel = new(slog.LevelVar)
+			logLevel.Set(slog.LevelDebug)
+			level = logLevel
+		case *slog.LevelVar:
+			logLevel = l
+		}
+
+		log = logging.WithHooks(logging.NewZapLogger(config.SlogLogger.Handler(), level))
+	case config.Logger != nil:
+		log = logging.WithHooks(config.Logger)
+	default:
 		log = getGlobalLogger()
-	} else {
-		log = logging.WithHooks(log)
+		logLevel = logging.Verbosity.LevelVar()
+	}
+
+	slowQuery := observability.NewSlowQueryOpts(config.PostgreSQLSlowQuery, false)
+
+	sqliteURI, removeSQLiteTemp, err := sqliteURL(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct handler: %s", err)
 	}
 
 	h, closeBackend, err := registry.NewHandler(config.Handler, &registry.NewHandlerOpts{
@@ -130,9 +217,10 @@ func New(config *Config) (*FerretDB, error) {
 		StateProvider: sp,
 		TCPHost:       config.Listener.TCP,
 
-		PostgreSQLURL: config.PostgreSQLURL,
+		PostgreSQLURL:       config.PostgreSQLURL,
+		PostgreSQLSlowQuery: slowQuery,
 
-		SQLiteURL: config.SQLiteURL,
+		SQLiteURL: sqliteURI,
 
 		//nolint:mnd // Command-line default flags
 		TestOpts: registry.TestOpts{
@@ -144,9 +232,16 @@ func New(config *Config) (*FerretDB, error) {
 		if closeBackend != nil {
 			closeBackend()
 		}
+		removeSQLiteTemp()
 		return nil, fmt.Errorf("failed to construct handler: %s", err)
 	}
 
+	closeHandler := closeBackend
+	closeBackend = func() {
+		closeHandler()
+		removeSQLiteTemp()
+	}
+
 	l, err := clientconn.Listen(&clientconn.NewListenerOpts{
 		TCP:  config.Listener.TCP,
 		Unix: config.Listener.Unix,
@@ -156,20 +251,80 @@ func New(config *Config) (*FerretDB, error) {
 		TLSKeyFile:  config.Listener.TLSKeyFile,
 		TLSCAFile:   config.Listener.TLSCAFile,
 
-		Mode:    clientconn.NormalMode,
-		Metrics: metrics,
-		Handler: h,
-		Logger:  log,
+		Memory:   config.Listener.InProcess,
+		MaxConns: config.Listener.MaxConnections,
+
+		Mode:           clientconn.NormalMode,
+		BSONValidation: wire.ValidationBasic,
+		Metrics:        metrics,
+		Handler:        h,
+		Logger:         log,
 	})
 	if err != nil {
+		closeBackend()
 		return nil, fmt.Errorf("failed to construct handler: %s", err)
 	}
 
-	return &FerretDB{
+	f := &FerretDB{
 		config:       config,
 		closeBackend: closeBackend,
+		sp:           sp,
+		h:            h,
 		l:            l,
-	}, nil
+		log:          log.Named("embedded"),
+		logLevel:     logLevel,
+		slowQuery:    slowQuery,
+	}
+
+	if config.MetricsRegisterer != nil {
+		if err = f.registerMetrics(sp.MetricsCollector(true), l); err != nil {
+			// close listener and handler; Run returns immediately for canceled context
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			l.Run(ctx)
+
+			closeBackend()
+			return nil, fmt.Errorf("failed to register metrics: %s", err)
+		}
+	}
+
+	return f, nil
+}
+
+// registerMetrics registers given collectors with [Config] MetricsRegisterer.
+//
+// If any collector can't be registered, already registered ones are unregistered.
+func (f *FerretDB) registerMetrics(cs ...prometheus.Collector) error {
+	r := f.config.MetricsRegisterer
+	if f.config.MetricsNamespace != "" {
+		r = prometheus.WrapRegistererWithPrefix(f.config.MetricsNamespace+"_", r)
+	}
+
+	for i, c := range cs {
+		if err := r.Register(c); err != nil {
+			for _, registered := range cs[:i] {
+				r.Unregister(registered)
+			}
+
+			return err
+		}
+	}
+
+	f.metricsRegisterer = r
+	f.metricsCollectors = cs
+
+	return nil
+}
+
+// unregisterMetrics unregisters collectors registered by registerMetrics.
+func (f *FerretDB) unregisterMetrics() {
+	if f.metricsRegisterer == nil {
+		return
+	}
+
+	for _, c := range f.metricsCollectors {
+		f.metricsRegisterer.Unregister(c)
+	}
 }
 
 // Run runs FerretDB until ctx is canceled.
@@ -179,18 +334,47 @@ func New(config *Config) (*FerretDB, error) {
 // It is required to run this method in order to initialize the listeners with their respective
 // IP address and port. Calling methods which require the listener's address (eg: [*FerretDB.MongoDBURI]
 // requires it for configuring its Host URL) before calling this method might result in a deadlock.
+//
+// State changes are reported to [Config] OnStateChange callback.
 func (f *FerretDB) Run(ctx context.Context) error {
+	defer f.setState(StateStopped, nil)
 	defer f.closeBackend()
+	defer f.unregisterMetrics()
+
+	f.setState(StateStarting, nil)
+
+	monitorDone := make(chan struct{})
+
+	go func() {
+		defer close(monitorDone)
+		f.monitorBackend(ctx)
+	}()
 
 	f.l.Run(ctx)
 
+	<-monitorDone
+
 	return nil
 }
 
+// DialContext returns a new in-process connection to this FerretDB instance.
+//
+// It implements the `ContextDialer` interface of MongoDB Go driver,
+// so the instance could be used with `options.Client().SetDialer(f)`.
+// Network and address are ignored.
+//
+// The in-process listener should be enabled with [ListenerConfig] InProcess field,
+// and [*FerretDB.Run] should be running.
+func (f *FerretDB) DialContext(ctx context.Context, _, _ string) (net.Conn, error) {
+	return f.l.DialMemory(ctx)
+}
+
 // MongoDBURI returns MongoDB URI for this FerretDB instance.
 //
 // TCP's connection string is returned if both TCP and Unix listeners are enabled.
 // TLS is preferred over both.
+// If only in-process listener is enabled, the returned URI contains a placeholder host
+// and should be used together with [*FerretDB.DialContext].
 func (f *FerretDB) MongoDBURI() string {
 	var u *url.URL
 
@@ -219,6 +403,12 @@ func (f *FerretDB) MongoDBURI() string {
 			Host:   f.l.UnixAddr().String(),
 			Path:   "/",
 		}
+	case f.config.Listener.InProcess:
+		u = &url.URL{
+			Scheme: "mongodb",
+			Host:   "in-process",
+			Path:   "/",
+		}
 	}
 
 	return u.String()
@@ -17,10 +17,16 @@ package ferretdb_test
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -143,6 +149,46 @@ func Example_tls() {
 	// Output: mongodb://127.0.0.1:17028/?tls=true
 }
 
+func Example_inProcess() {
+	f, err := ferretdb.New(&ferretdb.Config{
+		Listener: ferretdb.ListenerConfig{
+			InProcess: true,
+		},
+		Handler:       "postgresql",
+		PostgreSQLURL: "postgres://127.0.0.1:5432/ferretdb",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+
+	go func() {
+		log.Print(f.Run(ctx))
+		close(done)
+	}()
+
+	uri := f.MongoDBURI()
+	fmt.Println(uri)
+
+	// Use FerretDB instance as a dialer. No sockets are used.
+	// For example:
+	//
+	// import "go.mongodb.org/mongo-driver/mongo"
+	// import "go.mongodb.org/mongo-driver/mongo/options"
+	//
+	// [...]
+	//
+	// mongo.Connect(ctx, options.Client().ApplyURI(uri).SetDialer(f))
+
+	cancel()
+	<-done
+
+	// Output: mongodb://in-process/
+}
+
 func TestEmbedded(t *testing.T) {
 	ctx, cancel := context.WithCancel(testutil.Ctx(t))
 
@@ -176,3 +222,289 @@ func TestEmbedded(t *testing.T) {
 	cancel()
 	<-done
 }
+
+func TestEmbeddedInProcess(t *testing.T) {
+	ctx, cancel := context.WithCancel(testutil.Ctx(t))
+
+	var (
+		statesM sync.Mutex
+		states  []ferretdb.State
+	)
+
+	f, err := ferretdb.New(&ferretdb.Config{
+		Listener: ferretdb.ListenerConfig{
+			InProcess: true,
+		},
+		Handler:   "sqlite",
+		SQLiteURL: "file:" + t.TempDir() + "/",
+		OnStateChange: func(_, to ferretdb.State, _ error) {
+			statesM.Lock()
+			defer statesM.Unlock()
+
+			states = append(states, to)
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ferretdb.StateNew, f.State())
+
+	done := make(chan struct{})
+
+	go func() {
+		require.NoError(t, f.Run(ctx))
+		close(done)
+	}()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(f.MongoDBURI()).SetDialer(f))
+	require.NoError(t, err)
+
+	dbName, collName := testutil.DatabaseName(t), testutil.CollectionName(t)
+
+	//nolint:forbidigo // bson is required to use the driver
+	_, err = client.Database(dbName).Collection(collName).InsertOne(ctx, bson.M{"foo": "bar"})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Disconnect(ctx))
+
+	require.Eventually(t, func() bool { return f.State() == ferretdb.StateReady }, 10*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+
+	expected := []ferretdb.State{
+		ferretdb.StateStarting,
+		ferretdb.StateReady,
+		ferretdb.StateShuttingDown,
+		ferretdb.StateStopped,
+	}
+	assert.Equal(t, expected, states)
+}
+
+func TestEmbeddedUpdate(t *testing.T) {
+	t.Parallel()
+
+	level := new(slog.LevelVar)
+
+	f, err := ferretdb.New(&ferretdb.Config{
+		Listener: ferretdb.ListenerConfig{
+			InProcess: true,
+		},
+		Handler:    "sqlite",
+		SQLiteURL:  "file:" + t.TempDir() + "/",
+		SlogLogger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		SlogLevel:  level,
+	})
+	require.NoError(t, err)
+
+	cleanupFerretDB(t, f)
+
+	debug := slog.LevelDebug
+	maxConns := 10
+	slowQuery := time.Second
+
+	err = f.Update(&ferretdb.ConfigUpdate{
+		LogLevel:            &debug,
+		PostgreSQLSlowQuery: &slowQuery,
+		MaxConnections:      &maxConns,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, level.Level())
+
+	err = f.Update(&ferretdb.ConfigUpdate{ReloadTLS: true})
+	assert.EqualError(t, err, "TLS listener is not enabled")
+
+	invalid := -1
+	err = f.Update(&ferretdb.ConfigUpdate{LogLevel: new(slog.Level), MaxConnections: &invalid})
+	assert.EqualError(t, err, "invalid MaxConnections: -1")
+	assert.Equal(t, slog.LevelDebug, level.Level(), "no changes should be made")
+}
+
+func TestEmbeddedMetrics(t *testing.T) {
+	t.Parallel()
+
+	r := prometheus.NewRegistry()
+
+	config := &ferretdb.Config{
+		Listener: ferretdb.ListenerConfig{
+			InProcess: true,
+		},
+		Handler:           "sqlite",
+		SQLiteURL:         "file:" + t.TempDir() + "/",
+		MetricsRegisterer: r,
+		MetricsNamespace:  "app",
+	}
+
+	f, err := ferretdb.New(config)
+	require.NoError(t, err)
+
+	cleanupFerretDB(t, f)
+
+	mfs, err := r.Gather()
+	require.NoError(t, err)
+
+	var names []string
+	for _, mf := range mfs {
+		names = append(names, mf.GetName())
+	}
+
+	assert.Contains(t, names, "app_ferretdb_up")
+
+	// the second instance could not register the same metrics
+	f2, err := ferretdb.New(config)
+	if err == nil {
+		cleanupFerretDB(t, f2)
+	}
+
+	assert.ErrorContains(t, err, "failed to register metrics")
+}
+
+func TestEmbeddedSQLiteStorage(t *testing.T) {
+	t.Parallel()
+
+	for _, storage := range []ferretdb.SQLiteStorage{ferretdb.SQLiteStorageMemory, ferretdb.SQLiteStorageTempDir} {
+		storage := storage
+
+		t.Run(storage.String(), func(t *testing.T) {
+			t.Parallel()
+
+			ctx := testutil.Ctx(t)
+			dbName, collName := testutil.DatabaseName(t), testutil.CollectionName(t)
+
+			// two instances running in parallel should not share data
+			colls := make([]*mongo.Collection, 2)
+
+			for i := range colls {
+				f, err := ferretdb.New(&ferretdb.Config{
+					Listener: ferretdb.ListenerConfig{
+						InProcess: true,
+					},
+					Handler:       "sqlite",
+					SQLiteStorage: storage,
+				})
+				require.NoError(t, err)
+
+				runCtx, cancel := context.WithCancel(ctx)
+				done := make(chan struct{})
+
+				go func() {
+					defer close(done)
+					assert.NoError(t, f.Run(runCtx))
+				}()
+
+				t.Cleanup(func() {
+					cancel()
+					<-done
+				})
+
+				client, err := mongo.Connect(ctx, options.Client().ApplyURI(f.MongoDBURI()).SetDialer(f))
+				require.NoError(t, err)
+
+				t.Cleanup(func() {
+					require.NoError(t, client.Disconnect(ctx))
+				})
+
+				colls[i] = client.Database(dbName).Collection(collName)
+			}
+
+			//nolint:forbidigo // bson is required to use the driver
+			_, err := colls[0].InsertOne(ctx, bson.M{"foo": "bar"})
+			require.NoError(t, err)
+
+			//nolint:forbidigo // bson is required to use the driver
+			n, err := colls[0].CountDocuments(ctx, bson.M{})
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), n)
+
+			//nolint:forbidigo // bson is required to use the driver
+			n, err = colls[1].CountDocuments(ctx, bson.M{})
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), n)
+		})
+	}
+
+	_, err := ferretdb.New(&ferretdb.Config{
+		Listener: ferretdb.ListenerConfig{
+			InProcess: true,
+		},
+		Handler:       "sqlite",
+		SQLiteURL:     "file:" + t.TempDir() + "/",
+		SQLiteStorage: ferretdb.SQLiteStorageMemory,
+	})
+	assert.ErrorContains(t, err, "SQLiteURL should be empty")
+}
+
+func TestEmbeddedUsers(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	f, err := ferretdb.New(&ferretdb.Config{
+		Listener: ferretdb.ListenerConfig{
+			InProcess: true,
+		},
+		Handler:       "sqlite",
+		SQLiteStorage: ferretdb.SQLiteStorageMemory,
+	})
+	require.NoError(t, err)
+
+	cleanupFerretDB(t, f)
+
+	user := &ferretdb.User{
+		Username:   "user",
+		Password:   "password",
+		Mechanisms: []string{"SCRAM-SHA-256"},
+	}
+
+	require.NoError(t, f.CreateUser(ctx, user))
+
+	err = f.CreateUser(ctx, user)
+	assert.ErrorIs(t, err, ferretdb.ErrUserAlreadyExists)
+
+	err = f.CreateUser(ctx, &ferretdb.User{Username: "other", Password: "password", Mechanisms: []string{"PLAIN"}})
+	assert.EqualError(t, err, `unknown authentication mechanism "PLAIN"`)
+
+	require.NoError(t, f.DropUser(ctx, "", "user"))
+
+	err = f.DropUser(ctx, "admin", "user")
+	assert.ErrorIs(t, err, ferretdb.ErrUserNotFound)
+}
+
+func TestEmbeddedStats(t *testing.T) {
+	t.Parallel()
+
+	f, err := ferretdb.New(&ferretdb.Config{
+		Listener: ferretdb.ListenerConfig{
+			InProcess:      true,
+			MaxConnections: 10,
+		},
+		Handler:       "sqlite",
+		SQLiteStorage: ferretdb.SQLiteStorageMemory,
+	})
+	require.NoError(t, err)
+
+	cleanupFerretDB(t, f)
+
+	expected := &ferretdb.Stats{
+		State:          ferretdb.StateNew,
+		MaxConnections: 10,
+	}
+	assert.Equal(t, expected, f.Stats())
+
+	h := f.Health(testutil.Ctx(t))
+	assert.Equal(t, ferretdb.StateNew, h.State)
+	assert.NoError(t, h.BackendError)
+	assert.False(t, h.Healthy(), "instance is not running")
+
+	assert.Equal(t, "embedded", f.BuildInfo().Package)
+}
+
+// cleanupFerretDB registers a function that releases resources of FerretDB instance that was not run.
+func cleanupFerretDB(tb testing.TB, f *ferretdb.FerretDB) {
+	tb.Helper()
+
+	tb.Cleanup(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.NoError(tb, f.Run(ctx))
+	})
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ferretdb
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Backend checks parameters.
+const (
+	backendCheckInterval = 5 * time.Second
+	backendCheckTimeout  = 5 * time.Second
+)
+
+// State represents the state of FerretDB instance.
+type State int
+
+// States of FerretDB instance.
+const (
+	// StateNew is the state of the instance created by [New] before [*FerretDB.Run] is called.
+	StateNew State = iota
+
+	// StateStarting means that listeners accept connections, but the backend is not reachable yet.
+	StateStarting
+
+	// StateReady means that the backend is reachable and the instance is ready for queries.
+	StateReady
+
+	// StateBackendLost means that the backend was reachable before, but is not now.
+	// The instance returns to StateReady when the backend is reachable again.
+	StateBackendLost
+
+	// StateShuttingDown means that the context passed to [*FerretDB.Run] is canceled,
+	// and the instance waits for client connections to be closed.
+	StateShuttingDown
+
+	// StateStopped means that [*FerretDB.Run] returned.
+	StateStopped
+)
+
+// String implements [fmt.Stringer].
+func (s State) String() string {
+	switch s {
+	case StateNew:
+		return "new"
+	case StateStarting:
+		return "starting"
+	case StateReady:
+		return "ready"
+	case StateBackendLost:
+		return "backend lost"
+	case StateShuttingDown:
+		return "shutting down"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// State returns the current state of FerretDB instance.
+func (f *FerretDB) State() State {
+	f.stateM.Lock()
+	defer f.stateM.Unlock()
+
+	return f.state
+}
+
+// setState changes the state and calls [Config] OnStateChange callback if needed.
+//
+// It should not be called concurrently.
+func (f *FerretDB) setState(to State, err error) {
+	f.stateM.Lock()
+	from := f.state
+	f.state = to
+	f.stateM.Unlock()
+
+	if from == to {
+		return
+	}
+
+	fields := []zap.Field{zap.Stringer("from", from), zap.Stringer("to", to)}
+	if err != nil {
+		f.log.Warn("State changed", append(fields, zap.Error(err))...)
+	} else {
+		f.log.Info("State changed", fields...)
+	}
+
+	if f.config.OnStateChange != nil {
+		f.config.OnStateChange(from, to, err)
+	}
+}
+
+// monitorBackend periodically checks the backend and updates the state until ctx is canceled.
+func (f *FerretDB) monitorBackend(ctx context.Context) {
+	ticker := time.NewTicker(backendCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		f.checkBackend(ctx)
+
+		select {
+		case <-ctx.Done():
+			f.setState(StateShuttingDown, nil)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkBackend checks the backend once and updates the state.
+func (f *FerretDB) checkBackend(ctx context.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, backendCheckTimeout)
+	defer cancel()
+
+	err := f.h.Ping(checkCtx)
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	switch {
+	case err == nil:
+		f.setState(StateReady, nil)
+	case f.State() == StateReady:
+		f.setState(StateBackendLost, err)
+	}
+}
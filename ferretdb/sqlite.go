@@ -0,0 +1,94 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ferretdb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SQLiteStorage represents where `sqlite` handler stores data.
+type SQLiteStorage int
+
+// SQLite storages.
+const (
+	// SQLiteStorageURL stores data as specified by [Config] SQLiteURL.
+	SQLiteStorageURL SQLiteStorage = iota
+
+	// SQLiteStorageMemory stores data in memory only.
+	//
+	// Data is lost when [*FerretDB.Run] returns.
+	// Each FerretDB instance has its own databases, even if several instances run in parallel.
+	SQLiteStorageMemory
+
+	// SQLiteStorageTempDir stores data in a new temporary directory.
+	//
+	// The directory is removed with all data when [*FerretDB.Run] returns.
+	// Each FerretDB instance has its own directory, even if several instances run in parallel.
+	SQLiteStorageTempDir
+)
+
+// String implements [fmt.Stringer].
+func (s SQLiteStorage) String() string {
+	switch s {
+	case SQLiteStorageURL:
+		return "url"
+	case SQLiteStorageMemory:
+		return "memory"
+	case SQLiteStorageTempDir:
+		return "temp-dir"
+	default:
+		return "unknown"
+	}
+}
+
+// sqliteURL returns SQLite URI for the given configuration
+// and a function that removes temporary files, if any.
+// That function should always be called, even if the handler was not created.
+func sqliteURL(config *Config) (string, func(), error) {
+	noop := func() {}
+
+	if config.SQLiteStorage == SQLiteStorageURL {
+		return config.SQLiteURL, noop, nil
+	}
+
+	if config.Handler != "sqlite" {
+		return "", nil, fmt.Errorf("SQLiteStorage %s requires `sqlite` handler", config.SQLiteStorage)
+	}
+
+	if config.SQLiteURL != "" {
+		return "", nil, fmt.Errorf("SQLiteURL should be empty for SQLiteStorage %s", config.SQLiteStorage)
+	}
+
+	switch config.SQLiteStorage {
+	case SQLiteStorageMemory:
+		// the directory should exist, but nothing is written there;
+		// each connection to in-memory database uses its own private database
+		return "file:/?mode=memory", noop, nil
+
+	case SQLiteStorageTempDir:
+		dir, err := os.MkdirTemp("", "ferretdb-sqlite-")
+		if err != nil {
+			return "", nil, err
+		}
+
+		return "file:" + filepath.ToSlash(dir) + "/", func() { _ = os.RemoveAll(dir) }, nil
+
+	default:
+		return "", nil, errors.New("unknown SQLiteStorage")
+	}
+}
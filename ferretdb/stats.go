@@ -0,0 +1,121 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ferretdb
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/build/version"
+)
+
+// Health represents the health of FerretDB instance.
+type Health struct {
+	State State
+
+	// BackendName and BackendVersion are empty until the backend is reachable.
+	BackendName    string
+	BackendVersion string
+
+	// BackendError is nil if the backend is reachable.
+	BackendError error
+}
+
+// Healthy returns true if the instance is ready and the backend is reachable.
+func (h *Health) Healthy() bool {
+	return h.State == StateReady && h.BackendError == nil
+}
+
+// Stats represents statistics of FerretDB instance.
+type Stats struct {
+	State State
+
+	// Current number of client connections.
+	Connections int
+
+	// Maximum number of client connections; zero means no limit.
+	MaxConnections int
+
+	// Current number of in-flight operations.
+	Operations int
+
+	// Current number of open cursors.
+	Cursors int
+}
+
+// BuildInfo represents information about FerretDB build.
+type BuildInfo struct {
+	Version    string
+	Commit     string
+	Branch     string
+	Dirty      bool
+	Package    string
+	DebugBuild bool
+
+	// Fake MongoDB version reported to clients.
+	MongoDBVersion string
+}
+
+// Health checks the backend and returns the health of FerretDB instance.
+//
+// Unlike [*FerretDB.State], it checks the backend immediately;
+// the check is limited by ctx.
+// The state is not changed.
+func (f *FerretDB) Health(ctx context.Context) *Health {
+	err := f.h.Ping(ctx)
+	s := f.sp.Get()
+
+	return &Health{
+		State:          f.State(),
+		BackendName:    s.BackendName,
+		BackendVersion: s.BackendVersion,
+		BackendError:   err,
+	}
+}
+
+// Stats returns current statistics of FerretDB instance.
+func (f *FerretDB) Stats() *Stats {
+	conns := f.l.Conns()
+
+	var ops int
+
+	for _, c := range conns {
+		if c.Op != nil {
+			ops++
+		}
+	}
+
+	return &Stats{
+		State:          f.State(),
+		Connections:    len(conns),
+		MaxConnections: f.l.MaxConns(),
+		Operations:     ops,
+		Cursors:        len(f.h.Cursors()),
+	}
+}
+
+// BuildInfo returns information about FerretDB build.
+func (f *FerretDB) BuildInfo() *BuildInfo {
+	info := version.Get()
+
+	return &BuildInfo{
+		Version:        info.Version,
+		Commit:         info.Commit,
+		Branch:         info.Branch,
+		Dirty:          info.Dirty,
+		Package:        info.Package,
+		DebugBuild:     info.DebugBuild,
+		MongoDBVersion: info.MongoDBVersion,
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ferretdb
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ConfigUpdate represents a change of FerretDB instance configuration at runtime.
+//
+// Nil fields are not changed.
+type ConfigUpdate struct {
+	// Minimal level of logs.
+	// It can't be changed if [Config] Logger is used
+	// or if SlogLevel is set to something other than [*slog.LevelVar].
+	LogLevel *slog.Level
+
+	// See [Config] PostgreSQLSlowQuery.
+	PostgreSQLSlowQuery *time.Duration
+
+	// See [ListenerConfig] MaxConnections.
+	// Existing connections over the new limit are not closed.
+	MaxConnections *int
+
+	// If true, TLS certificate, key, and CA files are read again.
+	// New connections use them; existing connections are not affected.
+	ReloadTLS bool
+}
+
+// Update changes the configuration of FerretDB instance at runtime.
+//
+// It could be called concurrently with [*FerretDB.Run].
+// If an error is returned, no changes are made.
+func (f *FerretDB) Update(u *ConfigUpdate) error {
+	if u.LogLevel != nil && f.logLevel == nil {
+		return errors.New("log level can't be changed for this logger")
+	}
+
+	if u.PostgreSQLSlowQuery != nil && *u.PostgreSQLSlowQuery < 0 {
+		return fmt.Errorf("invalid PostgreSQLSlowQuery: %s", *u.PostgreSQLSlowQuery)
+	}
+
+	if u.MaxConnections != nil && *u.MaxConnections < 0 {
+		return fmt.Errorf("invalid MaxConnections: %d", *u.MaxConnections)
+	}
+
+	if u.ReloadTLS && f.config.Listener.TLS == "" {
+		return errors.New("TLS listener is not enabled")
+	}
+
+	// reload TLS first as it is the only change that could fail
+	if u.ReloadTLS {
+		if err := f.l.ReloadTLS(); err != nil {
+			return fmt.Errorf("failed to reload TLS: %s", err)
+		}
+	}
+
+	var fields []zap.Field
+
+	if u.LogLevel != nil {
+		f.logLevel.Set(*u.LogLevel)
+		fields = append(fields, zap.Stringer("log_level", *u.LogLevel))
+	}
+
+	if u.PostgreSQLSlowQuery != nil {
+		f.slowQuery.SetThreshold(*u.PostgreSQLSlowQuery)
+		fields = append(fields, zap.Duration("postgresql_slow_query", *u.PostgreSQLSlowQuery))
+	}
+
+	if u.MaxConnections != nil {
+		f.l.SetMaxConns(*u.MaxConnections)
+		fields = append(fields, zap.Int("max_connections", *u.MaxConnections))
+	}
+
+	if u.ReloadTLS {
+		fields = append(fields, zap.Bool("reload_tls", true))
+	}
+
+	f.log.Info("Configuration updated", fields...)
+
+	return nil
+}
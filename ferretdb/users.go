@@ -0,0 +1,129 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ferretdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/handler/users"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/password"
+)
+
+// Errors returned by user management methods.
+var (
+	// ErrUserAlreadyExists is returned by [*FerretDB.CreateUser] if the user already exists.
+	ErrUserAlreadyExists = errors.New("user already exists")
+
+	// ErrUserNotFound is returned by [*FerretDB.DropUser] if the user does not exist.
+	ErrUserNotFound = errors.New("user not found")
+)
+
+// User represents a user created with [*FerretDB.CreateUser].
+//
+// Roles are not supported yet, the same as for `createUser` command.
+type User struct {
+	// Database where the user is defined; `admin` if empty.
+	Database string
+
+	Username string
+	Password string
+
+	// Authentication mechanisms: `SCRAM-SHA-1`, `SCRAM-SHA-256`, or both.
+	// If empty, both are used.
+	Mechanisms []string
+}
+
+// CreateUser creates a new user the same way as `createUser` command does.
+//
+// It could be called before [*FerretDB.Run] to create users before connections are accepted.
+func (f *FerretDB) CreateUser(ctx context.Context, u *User) error {
+	if u.Username == "" {
+		return errors.New("username should not be empty")
+	}
+
+	if u.Password == "" {
+		return errors.New("password should not be empty")
+	}
+
+	dbName := u.Database
+	if dbName == "" {
+		dbName = "admin"
+	}
+
+	var mechanisms *types.Array
+
+	if len(u.Mechanisms) > 0 {
+		mechanisms = types.MakeArray(len(u.Mechanisms))
+
+		for _, m := range u.Mechanisms {
+			switch m {
+			case "SCRAM-SHA-1", "SCRAM-SHA-256":
+				mechanisms.Append(m)
+			default:
+				return fmt.Errorf("unknown authentication mechanism %q", m)
+			}
+		}
+	}
+
+	err := f.h.CreateUser(bypassAuth(ctx), &users.CreateUserParams{
+		Database:   dbName,
+		Username:   u.Username,
+		Password:   password.WrapPassword(u.Password),
+		Mechanisms: mechanisms,
+	})
+
+	switch {
+	case err == nil:
+		return nil
+	case backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID):
+		return fmt.Errorf("%w: %s@%s", ErrUserAlreadyExists, u.Username, dbName)
+	default:
+		return fmt.Errorf("failed to create user %s@%s: %s", u.Username, dbName, err)
+	}
+}
+
+// DropUser drops the user from the given database (`admin` if empty)
+// the same way as `dropUser` command does.
+//
+// It could be called before [*FerretDB.Run].
+func (f *FerretDB) DropUser(ctx context.Context, database, username string) error {
+	if database == "" {
+		database = "admin"
+	}
+
+	deleted, err := f.h.DropUser(bypassAuth(ctx), database, username)
+	if err != nil {
+		return fmt.Errorf("failed to drop user %s@%s: %s", username, database, err)
+	}
+
+	if !deleted {
+		return fmt.Errorf("%w: %s@%s", ErrUserNotFound, username, database)
+	}
+
+	return nil
+}
+
+// bypassAuth returns a context that does not require backend authentication.
+func bypassAuth(ctx context.Context) context.Context {
+	info := conninfo.New()
+	info.SetBypassBackendAuth()
+
+	return conninfo.Ctx(ctx, info)
+}
@@ -405,6 +405,402 @@ func TestAggregateCompatCount(t *testing.T) {
 	testAggregateStagesCompat(t, testCases)
 }
 
+func TestAggregateCompatFacet(t *testing.T) {
+	t.Parallel()
+
+	match := bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "string"}}}}}}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Count": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$facet", bson.D{{"count", bson.A{bson.D{{"$count", "n"}}}}}}},
+			},
+		},
+		"SeveralPipelines": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$facet", bson.D{
+					{"all", bson.A{bson.D{{"$project", bson.D{{"v", 1}}}}}},
+					{"limit", bson.A{bson.D{{"$limit", 2}}}},
+					{"count", bson.A{bson.D{{"$count", "n"}}}},
+				}}},
+			},
+		},
+		"SharedMatch": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$facet", bson.D{
+					{"count", bson.A{match, bson.D{{"$count", "n"}}}},
+					{"ids", bson.A{match, bson.D{{"$project", bson.D{{"_id", 1}}}}}},
+					{"skip", bson.A{match, bson.D{{"$skip", 1}}}},
+				}}},
+			},
+		},
+		"MatchNothing": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$facet", bson.D{
+					{"count", bson.A{bson.D{{"$match", bson.D{{"_id", "nonexistent"}}}}, bson.D{{"$count", "n"}}}},
+					{"docs", bson.A{bson.D{{"$match", bson.D{{"_id", "nonexistent"}}}}}},
+				}}},
+			},
+		},
+		"AfterFacet": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$facet", bson.D{{"docs", bson.A{bson.D{{"$project", bson.D{{"_id", 1}}}}}}}}},
+				bson.D{{"$unwind", "$docs"}},
+			},
+		},
+		"Empty": {
+			pipeline:   bson.A{bson.D{{"$facet", bson.D{}}}},
+			resultType: emptyResult,
+		},
+		"NotDocument": {
+			pipeline:   bson.A{bson.D{{"$facet", "foo"}}},
+			resultType: emptyResult,
+		},
+		"NotArray": {
+			pipeline:   bson.A{bson.D{{"$facet", bson.D{{"foo", bson.D{}}}}}},
+			resultType: emptyResult,
+		},
+		"EmptySubPipeline": {
+			pipeline:   bson.A{bson.D{{"$facet", bson.D{{"foo", bson.A{}}}}}},
+			resultType: emptyResult,
+		},
+		"InvalidElement": {
+			pipeline:   bson.A{bson.D{{"$facet", bson.D{{"foo", bson.A{42}}}}}},
+			resultType: emptyResult,
+		},
+		"DollarName": {
+			pipeline:   bson.A{bson.D{{"$facet", bson.D{{"$foo", bson.A{bson.D{{"$count", "n"}}}}}}}},
+			resultType: emptyResult,
+		},
+		"NestedFacet": {
+			pipeline: bson.A{bson.D{{"$facet", bson.D{{"foo", bson.A{
+				bson.D{{"$facet", bson.D{{"bar", bson.A{bson.D{{"$count", "n"}}}}}}},
+			}}}}}},
+			resultType: emptyResult,
+		},
+		"CollStats": {
+			pipeline: bson.A{bson.D{{"$facet", bson.D{{"foo", bson.A{
+				bson.D{{"$collStats", bson.D{}}},
+			}}}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, shareddata.Providers{shareddata.Scalars, shareddata.Composites}, testCases)
+}
+
+func TestAggregateCompatBucket(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Numbers": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{int32(0), int64(10), 100.5}},
+				{"default", "other"},
+			}}}},
+		},
+		"Strings": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{"", "a", "z"}},
+				{"default", int32(0)},
+			}}}},
+		},
+		"Output": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{int32(-100), int32(0), int32(100)}},
+				{"default", "other"},
+				{"output", bson.D{
+					{"n", bson.D{{"$sum", 1}}},
+					{"ids", bson.D{{"$addToSet", "$_id"}}},
+				}},
+			}}}},
+		},
+		"DefaultBeforeBoundaries": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{int32(100), int32(1000)}},
+				{"default", int32(-1)},
+			}}}},
+		},
+		"Expression": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", bson.D{{"$type", "$v"}}},
+				{"boundaries", bson.A{"a", "n", "z"}},
+			}}}},
+		},
+		"NoDefault": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{int32(0), int32(1)}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"NotDocument": {
+			pipeline:   bson.A{bson.D{{"$bucket", "foo"}}},
+			resultType: emptyResult,
+		},
+		"GroupByNotExpression": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "v"},
+				{"boundaries", bson.A{int32(0), int32(1)}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"BoundariesNotArray": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", int32(1)},
+			}}}},
+			resultType: emptyResult,
+		},
+		"BoundariesTooFew": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{int32(0)}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"BoundariesMixedTypes": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{int32(0), "a"}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"BoundariesNotSorted": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{int32(10), 5.5}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"BoundariesDuplicate": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{int32(0), int64(0)}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"BoundariesNotConstant": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{int32(0), "$v"}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"DefaultNotConstant": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{int32(0), int32(1)}},
+				{"default", "$v"},
+			}}}},
+			resultType: emptyResult,
+		},
+		"DefaultInRange": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{int32(0), int32(10)}},
+				{"default", 5.5},
+			}}}},
+			resultType: emptyResult,
+		},
+		"OutputNotDocument": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{int32(0), int32(1)}},
+				{"default", "other"},
+				{"output", int32(1)},
+			}}}},
+			resultType: emptyResult,
+		},
+		"OutputNotAccumulator": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{int32(0), int32(1)}},
+				{"default", "other"},
+				{"output", bson.D{{"n", int32(1)}}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"UnknownOption": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{int32(0), int32(1)}},
+				{"foo", int32(1)},
+			}}}},
+			resultType: emptyResult,
+		},
+		"MissingGroupBy": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"boundaries", bson.A{int32(0), int32(1)}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"MissingBoundaries": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+			}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, shareddata.Providers{shareddata.Scalars, shareddata.Composites}, testCases)
+}
+
+func TestAggregateCompatBucketAuto(t *testing.T) {
+	t.Parallel()
+
+	// providers with values of the same type that are not equal to each other,
+	// so min and max values of buckets do not depend on the order of equal values
+	providers := shareddata.Providers{shareddata.Int32s, shareddata.Int64s, shareddata.Doubles, shareddata.Strings}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Buckets": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", "$v"},
+				{"buckets", int32(3)},
+			}}}},
+		},
+		"One": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", "$v"},
+				{"buckets", int64(1)},
+			}}}},
+		},
+		"MoreThanDocuments": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", "$v"},
+				{"buckets", 100.0},
+			}}}},
+		},
+		"Output": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", "$v"},
+				{"buckets", int32(2)},
+				{"output", bson.D{
+					{"n", bson.D{{"$sum", 1}}},
+					{"ids", bson.D{{"$addToSet", "$_id"}}},
+				}},
+			}}}},
+		},
+		"Expression": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", bson.D{{"$type", "$v"}}},
+				{"buckets", int32(2)},
+			}}}},
+		},
+		"Granularity": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", "$v"},
+				{"buckets", int32(2)},
+				{"granularity", "R5"},
+			}}}},
+			resultType: emptyResult,
+			skip:       "https://github.com/FerretDB/FerretDB/issues/1414",
+		},
+		"NotDocument": {
+			pipeline:   bson.A{bson.D{{"$bucketAuto", "foo"}}},
+			resultType: emptyResult,
+		},
+		"GroupByNotExpression": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", "v"},
+				{"buckets", int32(2)},
+			}}}},
+			resultType: emptyResult,
+		},
+		"BucketsNotNumber": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", "$v"},
+				{"buckets", "2"},
+			}}}},
+			resultType: emptyResult,
+		},
+		"BucketsNotWhole": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", "$v"},
+				{"buckets", 1.5},
+			}}}},
+			resultType: emptyResult,
+		},
+		"BucketsZero": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", "$v"},
+				{"buckets", int32(0)},
+			}}}},
+			resultType: emptyResult,
+		},
+		"BucketsNegative": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", "$v"},
+				{"buckets", int64(-1)},
+			}}}},
+			resultType: emptyResult,
+		},
+		"OutputNotDocument": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", "$v"},
+				{"buckets", int32(2)},
+				{"output", int32(1)},
+			}}}},
+			resultType: emptyResult,
+		},
+		"UnknownOption": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", "$v"},
+				{"buckets", int32(2)},
+				{"foo", int32(1)},
+			}}}},
+			resultType: emptyResult,
+		},
+		"MissingBuckets": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", "$v"},
+			}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatRedact(t *testing.T) {
+	t.Parallel()
+
+	providers := shareddata.Providers{shareddata.Scalars, shareddata.Composites}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Keep": {
+			pipeline: bson.A{bson.D{{"$redact", "$$KEEP"}}},
+		},
+		"Descend": {
+			pipeline: bson.A{bson.D{{"$redact", "$$DESCEND"}}},
+		},
+		"Prune": {
+			pipeline:   bson.A{bson.D{{"$redact", "$$PRUNE"}}},
+			resultType: emptyResult,
+		},
+		"FieldPath": {
+			pipeline:   bson.A{bson.D{{"$redact", "$v"}}},
+			resultType: emptyResult,
+		},
+		"InvalidResult": {
+			pipeline:   bson.A{bson.D{{"$redact", int32(42)}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
 func TestAggregateCompatGroupDeterministicCollections(t *testing.T) {
 	t.Parallel()
 
@@ -430,6 +826,17 @@ func TestAggregateCompatGroupDeterministicCollections(t *testing.T) {
 				bson.D{{"$sort", bson.D{{"_id", -1}}}},
 			},
 		},
+		"AddToSet": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", -1}}}},
+				bson.D{{"$group", bson.D{
+					{"_id", "$v"},
+					// each group has a single distinct value, so the set order is deterministic
+					{"set", bson.D{{"$addToSet", "$v"}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", -1}}}},
+			},
+		},
 		"Distinct": {
 			pipeline: bson.A{
 				// sort collection to ensure the order is consistent
@@ -1593,6 +2000,66 @@ func TestAggregateCompatSkip(t *testing.T) {
 	testAggregateStagesCompat(t, testCases)
 }
 
+func TestAggregateCompatSample(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"All": {
+			pipeline: bson.A{
+				bson.D{{"$sample", bson.D{{"size", int32(1000)}}}},
+				bson.D{{"$count", "v"}},
+			},
+		},
+		"Zero": {
+			pipeline: bson.A{
+				bson.D{{"$sample", bson.D{{"size", int32(0)}}}},
+			},
+			resultType: emptyResult,
+		},
+		"Double": {
+			pipeline: bson.A{
+				bson.D{{"$sample", bson.D{{"size", 1.5}}}},
+				bson.D{{"$count", "v"}},
+			},
+		},
+		"NotDocument": {
+			pipeline:   bson.A{bson.D{{"$sample", int32(1)}}},
+			resultType: emptyResult,
+		},
+		"SizeString": {
+			pipeline:   bson.A{bson.D{{"$sample", bson.D{{"size", "1"}}}}},
+			resultType: emptyResult,
+		},
+		"SizeNegative": {
+			pipeline:   bson.A{bson.D{{"$sample", bson.D{{"size", int32(-1)}}}}},
+			resultType: emptyResult,
+		},
+		"SizeMissing": {
+			pipeline:   bson.A{bson.D{{"$sample", bson.D{}}}},
+			resultType: emptyResult,
+		},
+		"UnknownOption": {
+			pipeline:   bson.A{bson.D{{"$sample", bson.D{{"size", int32(1)}, {"foo", int32(1)}}}}},
+			resultType: emptyResult,
+		},
+		"SchemaAnalysis": {
+			// similar to the pipeline used by Compass for schema analysis
+			pipeline: bson.A{
+				bson.D{{"$sample", bson.D{{"size", int32(1000)}}}},
+				bson.D{{"$project", bson.D{{"kv", bson.D{{"$objectToArray", "$$ROOT"}}}}}},
+				bson.D{{"$unwind", "$kv"}},
+				bson.D{{"$group", bson.D{
+					{"_id", bson.D{{"k", "$kv.k"}, {"type", bson.D{{"$type", "$kv.v"}}}}},
+					{"count", bson.D{{"$sum", int32(1)}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id.k", 1}, {"_id.type", 1}}}},
+			},
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
+
 func TestAggregateCompatProject(t *testing.T) {
 	t.Parallel()
 
@@ -2507,3 +2974,975 @@ func TestAggregateCompatUnset(t *testing.T) {
 	}
 	testAggregateStagesCompat(t, testCases)
 }
+
+func TestAggregateCompatDensify(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{shareddata.TimeSeries}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Full": {
+			pipeline: bson.A{
+				bson.D{{"$densify", bson.D{
+					{"field", "n"},
+					{"range", bson.D{{"step", int32(1)}, {"bounds", "full"}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"n", 1}, {"_id", 1}}}},
+			},
+		},
+		"Partition": {
+			pipeline: bson.A{
+				bson.D{{"$densify", bson.D{
+					{"field", "n"},
+					{"partitionByFields", bson.A{"sensor"}},
+					{"range", bson.D{{"step", int32(1)}, {"bounds", "partition"}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"sensor", 1}, {"n", 1}, {"_id", 1}}}},
+			},
+		},
+		"PartitionFull": {
+			pipeline: bson.A{
+				bson.D{{"$densify", bson.D{
+					{"field", "n"},
+					{"partitionByFields", bson.A{"sensor"}},
+					{"range", bson.D{{"step", int32(2)}, {"bounds", "full"}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"sensor", 1}, {"n", 1}, {"_id", 1}}}},
+			},
+		},
+		"Bounds": {
+			pipeline: bson.A{
+				bson.D{{"$densify", bson.D{
+					{"field", "n"},
+					{"range", bson.D{{"step", 1.5}, {"bounds", bson.A{int32(1), int32(5)}}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"n", 1}, {"_id", 1}}}},
+			},
+		},
+		"DateHour": {
+			pipeline: bson.A{
+				bson.D{{"$densify", bson.D{
+					{"field", "time"},
+					{"partitionByFields", bson.A{"sensor"}},
+					{"range", bson.D{{"step", int32(1)}, {"unit", "hour"}, {"bounds", "partition"}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"sensor", 1}, {"time", 1}, {"_id", 1}}}},
+			},
+		},
+		"DateBounds": {
+			pipeline: bson.A{
+				bson.D{{"$densify", bson.D{
+					{"field", "time"},
+					{"range", bson.D{
+						{"step", int32(90)},
+						{"unit", "minute"},
+						{"bounds", bson.A{
+							time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC),
+							time.Date(2024, 1, 1, 5, 0, 0, 0, time.UTC),
+						}},
+					}},
+				}}},
+				bson.D{{"$sort", bson.D{{"time", 1}, {"_id", 1}}}},
+			},
+		},
+		"MissingField": {
+			pipeline: bson.A{
+				bson.D{{"$densify", bson.D{{"field", "n"}}}},
+			},
+			resultType: emptyResult,
+		},
+		"UnknownField": {
+			pipeline: bson.A{
+				bson.D{{"$densify", bson.D{
+					{"field", "n"},
+					{"range", bson.D{{"step", int32(1)}, {"bounds", "full"}}},
+					{"foo", "bar"},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatFill(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{shareddata.TimeSeries}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Value": {
+			pipeline: bson.A{
+				bson.D{{"$fill", bson.D{
+					{"output", bson.D{{"value", bson.D{{"value", int32(0)}}}}},
+				}}},
+			},
+		},
+		"ValueExpression": {
+			pipeline: bson.A{
+				bson.D{{"$fill", bson.D{
+					{"output", bson.D{{"value", bson.D{{"value", "$n"}}}}},
+				}}},
+			},
+		},
+		"Locf": {
+			pipeline: bson.A{
+				bson.D{{"$fill", bson.D{
+					{"sortBy", bson.D{{"n", 1}}},
+					{"partitionByFields", bson.A{"sensor"}},
+					{"output", bson.D{{"value", bson.D{{"method", "locf"}}}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+		},
+		"LocfNoPartition": {
+			pipeline: bson.A{
+				bson.D{{"$fill", bson.D{
+					{"sortBy", bson.D{{"time", -1}, {"_id", 1}}},
+					{"output", bson.D{{"value", bson.D{{"method", "locf"}}}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+		},
+		"Linear": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"n", bson.D{{"$exists", true}}}}}},
+				bson.D{{"$fill", bson.D{
+					{"sortBy", bson.D{{"n", 1}}},
+					{"partitionBy", "$sensor"},
+					{"output", bson.D{{"value", bson.D{{"method", "linear"}}}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+		},
+		"LinearDate": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"sensor", "a"}}}},
+				bson.D{{"$fill", bson.D{
+					{"sortBy", bson.D{{"time", 1}}},
+					{"output", bson.D{{"value", bson.D{{"method", "linear"}}}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+		},
+		"MissingOutput": {
+			pipeline: bson.A{
+				bson.D{{"$fill", bson.D{{"sortBy", bson.D{{"n", 1}}}}}},
+			},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatSetWindowFields(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{shareddata.TimeSeries}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"RunningTotal": {
+			pipeline: bson.A{
+				bson.D{{"$setWindowFields", bson.D{
+					{"partitionBy", "$sensor"},
+					{"sortBy", bson.D{{"_id", 1}}},
+					{"output", bson.D{{"total", bson.D{
+						{"$sum", "$value"},
+						{"window", bson.D{{"documents", bson.A{"unbounded", "current"}}}},
+					}}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+		},
+		"MovingCount": {
+			pipeline: bson.A{
+				bson.D{{"$setWindowFields", bson.D{
+					{"sortBy", bson.D{{"_id", 1}}},
+					{"output", bson.D{{"count", bson.D{
+						{"$count", bson.D{}},
+						{"window", bson.D{{"documents", bson.A{int32(-1), int32(1)}}}},
+					}}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+		},
+		"Rank": {
+			pipeline: bson.A{
+				bson.D{{"$setWindowFields", bson.D{
+					{"sortBy", bson.D{{"n", 1}}},
+					{"output", bson.D{
+						{"rank", bson.D{{"$rank", bson.D{}}}},
+						{"dense", bson.D{{"$denseRank", bson.D{}}}},
+					}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+		},
+		"Shift": {
+			pipeline: bson.A{
+				bson.D{{"$setWindowFields", bson.D{
+					{"partitionBy", "$sensor"},
+					{"sortBy", bson.D{{"_id", 1}}},
+					{"output", bson.D{{"next", bson.D{
+						{"$shift", bson.D{{"output", "$value"}, {"by", int32(1)}}},
+					}}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+		},
+		"RangeUnit": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"time", bson.D{{"$exists", true}}}}}},
+				bson.D{{"$setWindowFields", bson.D{
+					{"partitionBy", "$sensor"},
+					{"sortBy", bson.D{{"time", 1}}},
+					{"output", bson.D{{"total", bson.D{
+						{"$sum", "$n"},
+						{"window", bson.D{{"range", bson.A{int32(-2), "current"}}, {"unit", "hour"}}},
+					}}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+		},
+		"UnknownFunction": {
+			pipeline: bson.A{
+				bson.D{{"$setWindowFields", bson.D{
+					{"output", bson.D{{"v", bson.D{{"$foo", "$n"}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatProjectDateOperators(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{shareddata.DateTimes, shareddata.Scalars}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"DateToString": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "date"}}}}}},
+				bson.D{{"$project", bson.D{
+					{"s", bson.D{{"$dateToString", bson.D{{"date", "$v"}}}}},
+				}}},
+			},
+		},
+		"DateToStringFormat": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "date"}}}}}},
+				bson.D{{"$project", bson.D{
+					{"s", bson.D{{"$dateToString", bson.D{
+						{"date", "$v"},
+						{"format", "%Y/%m/%d %H:%M:%S.%L %j %w %u %U %V %G %z %Z %% %b %B"},
+					}}}},
+				}}},
+			},
+		},
+		"DateToStringTimezone": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "date"}}}}}},
+				bson.D{{"$project", bson.D{
+					{"s", bson.D{{"$dateToString", bson.D{{"date", "$v"}, {"timezone", "America/New_York"}}}}},
+					{"o", bson.D{{"$dateToString", bson.D{
+						{"date", "$v"},
+						{"format", "%H:%M %z"},
+						{"timezone", "+04:45"},
+					}}}},
+				}}},
+			},
+		},
+		"DateToStringOnNull": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"s", bson.D{{"$dateToString", bson.D{{"date", "$missing"}, {"onNull", "none"}}}}},
+				}}},
+			},
+		},
+		"DateToStringNotDate": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"s", bson.D{{"$dateToString", bson.D{{"date", "$v"}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"DateToStringInvalidFormat": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"s", bson.D{{"$dateToString", bson.D{{"date", "$v"}, {"format", "%Q"}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"DateToStringUnknownTimezone": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"s", bson.D{{"$dateToString", bson.D{{"date", "$v"}, {"timezone", "Mars/Olympus"}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"DateToParts": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "date"}}}}}},
+				bson.D{{"$project", bson.D{
+					{"parts", bson.D{{"$dateToParts", bson.D{{"date", "$v"}}}}},
+					{"iso", bson.D{{"$dateToParts", bson.D{{"date", "$v"}, {"iso8601", true}, {"timezone", "Asia/Tokyo"}}}}},
+				}}},
+			},
+		},
+		"DateFromParts": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"d", bson.D{{"$dateFromParts", bson.D{
+						{"year", int32(2017)},
+						{"month", int32(14)},
+						{"day", int32(1)},
+						{"hour", int32(12)},
+						{"timezone", "Europe/Berlin"},
+					}}}},
+					{"iso", bson.D{{"$dateFromParts", bson.D{
+						{"isoWeekYear", int32(2017)},
+						{"isoWeek", int32(8)},
+						{"isoDayOfWeek", int32(3)},
+					}}}},
+				}}},
+			},
+		},
+		"DateFromPartsMixed": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"d", bson.D{{"$dateFromParts", bson.D{{"year", int32(2017)}, {"isoWeek", int32(8)}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"DateFromString": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"iso", bson.D{{"$dateFromString", bson.D{{"dateString", "2017-02-08T12:10:40.787Z"}}}}},
+					{"offset", bson.D{{"$dateFromString", bson.D{{"dateString", "2017-02-08T12:10:40+03:00"}}}}},
+					{"tz", bson.D{{"$dateFromString", bson.D{
+						{"dateString", "2017-02-08 12:10"},
+						{"timezone", "America/New_York"},
+					}}}},
+					{"format", bson.D{{"$dateFromString", bson.D{
+						{"dateString", "08/02/2017"},
+						{"format", "%d/%m/%Y"},
+					}}}},
+				}}},
+			},
+		},
+		"DateFromStringOnError": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"d", bson.D{{"$dateFromString", bson.D{
+						{"dateString", "$v"},
+						{"onError", "invalid"},
+						{"onNull", "null"},
+					}}}},
+				}}},
+			},
+		},
+		"DateFromStringError": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"d", bson.D{{"$dateFromString", bson.D{{"dateString", "2017-13-45"}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatProjectConditionalOperators(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{shareddata.Scalars, shareddata.Composites}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"CondArray": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"c", bson.D{{"$cond", bson.A{"$v", "truthy", "falsy"}}}},
+				}}},
+			},
+		},
+		"CondDocument": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"c", bson.D{{"$cond", bson.D{
+						{"if", "$v"},
+						{"then", bson.D{{"type", bson.D{{"$type", "$v"}}}}},
+						{"else", "$_id"},
+					}}}},
+				}}},
+			},
+		},
+		"CondNested": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"c", bson.D{{"$cond", bson.A{
+						bson.D{{"$cond", bson.A{"$v", false, true}}},
+						"falsy",
+						bson.A{"$_id", bson.D{{"$type", "$v"}}},
+					}}}},
+				}}},
+			},
+		},
+		"CondTooFewArgs": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"c", bson.D{{"$cond", bson.A{"$v", "truthy"}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"CondMissingElse": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"c", bson.D{{"$cond", bson.D{{"if", "$v"}, {"then", "truthy"}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"CondUnknownArg": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"c", bson.D{{"$cond", bson.D{{"if", "$v"}, {"then", 1}, {"else", 2}, {"foo", 3}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"Switch": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"s", bson.D{{"$switch", bson.D{
+						{"branches", bson.A{
+							bson.D{{"case", "$v"}, {"then", bson.D{{"$type", "$v"}}}},
+							bson.D{{"case", bson.D{{"$type", "$v"}}}, {"then", "falsy"}},
+						}},
+					}}}},
+				}}},
+			},
+		},
+		"SwitchDefault": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"s", bson.D{{"$switch", bson.D{
+						{"branches", bson.A{bson.D{{"case", "$v"}, {"then", "truthy"}}}},
+						{"default", "$_id"},
+					}}}},
+				}}},
+			},
+		},
+		"SwitchNoMatchingBranch": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"s", bson.D{{"$switch", bson.D{
+						{"branches", bson.A{bson.D{{"case", "$v"}, {"then", "truthy"}}}},
+					}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"SwitchNoBranches": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"s", bson.D{{"$switch", bson.D{{"branches", bson.A{}}, {"default", 1}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"SwitchBranchMissingThen": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"s", bson.D{{"$switch", bson.D{{"branches", bson.A{bson.D{{"case", "$v"}}}}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"SwitchUnknownArg": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"s", bson.D{{"$switch", bson.D{
+						{"branches", bson.A{bson.D{{"case", "$v"}, {"then", 1}}}},
+						{"foo", 1},
+					}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatProjectStringOperators(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{shareddata.Scalars, shareddata.Composites}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"RegexMatch": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"r", bson.D{{"$regexMatch", bson.D{{"input", "$v"}, {"regex", "^f"}, {"options", "i"}}}}},
+				}}},
+			},
+		},
+		"RegexFind": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"r", bson.D{{"$regexFind", bson.D{{"input", "$v"}, {"regex", primitive.Regex{Pattern: "(o)(x)?"}}}}}},
+				}}},
+			},
+		},
+		"RegexInputNotString": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"r", bson.D{{"$regexMatch", bson.D{{"input", int32(1)}, {"regex", "a"}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"RegexMissingRegex": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"r", bson.D{{"$regexFind", bson.D{{"input", "$v"}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"Split": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "string"}}}}}},
+				bson.D{{"$project", bson.D{
+					{"s", bson.D{{"$split", bson.A{"$v", "o"}}}},
+				}}},
+			},
+		},
+		"SplitNotString": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"s", bson.D{{"$split", bson.A{int32(1), "o"}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"Trim": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "string"}}}}}},
+				bson.D{{"$project", bson.D{
+					{"t", bson.D{{"$trim", bson.D{{"input", "$v"}}}}},
+					{"l", bson.D{{"$ltrim", bson.D{{"input", "$v"}, {"chars", "fo"}}}}},
+					{"r", bson.D{{"$rtrim", bson.D{{"input", "$v"}, {"chars", "o"}}}}},
+				}}},
+			},
+		},
+		"TrimUnknownArg": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"t", bson.D{{"$trim", bson.D{{"input", "$v"}, {"foo", 1}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"ReplaceAll": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "string"}}}}}},
+				bson.D{{"$project", bson.D{
+					{"r", bson.D{{"$replaceAll", bson.D{{"input", "$v"}, {"find", "o"}, {"replacement", "0"}}}}},
+				}}},
+			},
+		},
+		"ReplaceAllMissingFind": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"r", bson.D{{"$replaceAll", bson.D{{"input", "$v"}, {"replacement", "0"}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"IndexOfCP": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "string"}}}}}},
+				bson.D{{"$project", bson.D{
+					{"i", bson.D{{"$indexOfCP", bson.A{"$v", "o"}}}},
+					{"start", bson.D{{"$indexOfCP", bson.A{"$v", "o", 2}}}},
+					{"end", bson.D{{"$indexOfCP", bson.A{"$v", "o", 0, 2}}}},
+				}}},
+			},
+		},
+		"IndexOfCPNotIntegral": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"i", bson.D{{"$indexOfCP", bson.A{"foo", "o", 1.5}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatProjectArithmeticOperators(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{shareddata.Int32s, shareddata.Int64s, shareddata.Doubles, shareddata.Decimal128s}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Multiply": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"m", bson.D{{"$multiply", bson.A{"$v", int32(2)}}}},
+					{"m64", bson.D{{"$multiply", bson.A{"$v", int64(3), int32(-1)}}}},
+					{"mDouble", bson.D{{"$multiply", bson.A{"$v", 0.5}}}},
+				}}},
+			},
+		},
+		"Divide": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$ne", 0}}}}}},
+				bson.D{{"$project", bson.D{
+					{"d", bson.D{{"$divide", bson.A{int32(1), "$v"}}}},
+					{"half", bson.D{{"$divide", bson.A{"$v", int32(2)}}}},
+				}}},
+			},
+		},
+		"DivideByZero": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{{"d", bson.D{{"$divide", bson.A{"$v", int32(0)}}}}}}},
+			},
+			resultType: emptyResult,
+		},
+		"Mod": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"m", bson.D{{"$mod", bson.A{"$v", int32(3)}}}},
+					{"m64", bson.D{{"$mod", bson.A{"$v", int64(-7)}}}},
+					{"mDouble", bson.D{{"$mod", bson.A{"$v", 2.5}}}},
+				}}},
+			},
+		},
+		"Pow": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$gte", -1000}, {"$lte", 1000}}}}}},
+				bson.D{{"$project", bson.D{
+					{"p", bson.D{{"$pow", bson.A{"$v", int32(2)}}}},
+					{"p64", bson.D{{"$pow", bson.A{"$v", int64(3)}}}},
+				}}},
+			},
+		},
+		"PowNotNumeric": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{{"p", bson.D{{"$pow", bson.A{"$v", "foo"}}}}}}},
+			},
+			resultType: emptyResult,
+		},
+		"Sqrt": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$gte", 0}}}}}},
+				bson.D{{"$project", bson.D{{"s", bson.D{{"$sqrt", "$v"}}}}}},
+			},
+		},
+		"Log": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$gt", 0}}}}}},
+				bson.D{{"$project", bson.D{{"l", bson.D{{"$log", bson.A{"$v", int32(2)}}}}}}},
+			},
+		},
+		"Round": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"r", bson.D{{"$round", "$v"}}},
+					{"r2", bson.D{{"$round", bson.A{"$v", int32(2)}}}},
+					{"rNegative", bson.D{{"$round", bson.A{"$v", int32(-2)}}}},
+				}}},
+			},
+		},
+		"Trunc": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"t", bson.D{{"$trunc", "$v"}}},
+					{"t2", bson.D{{"$trunc", bson.A{"$v", int32(2)}}}},
+					{"tNegative", bson.D{{"$trunc", bson.A{"$v", int32(-2)}}}},
+				}}},
+			},
+		},
+		"RoundPlaceNotIntegral": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{{"r", bson.D{{"$round", bson.A{"$v", 1.5}}}}}}},
+			},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatProjectArrayOperators(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{
+		shareddata.ArrayStrings,
+		shareddata.ArrayDoubles,
+		shareddata.ArrayInt32s,
+		shareddata.ArrayDocuments,
+	}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Map": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"m", bson.D{{"$map", bson.D{{"input", "$v"}, {"in", bson.D{{"wrapped", "$$this"}}}}}}},
+					{"as", bson.D{{"$map", bson.D{{"input", "$v"}, {"as", "e"}, {"in", bson.A{"$$e", "$_id"}}}}}},
+				}}},
+			},
+		},
+		"Filter": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"f", bson.D{{"$filter", bson.D{{"input", "$v"}, {"cond", "$$this"}}}}},
+					{"limit", bson.D{{"$filter", bson.D{{"input", "$v"}, {"cond", true}, {"limit", int64(1)}}}}},
+				}}},
+			},
+		},
+		"FilterLimitNotIntegral": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"f", bson.D{{"$filter", bson.D{{"input", "$v"}, {"cond", true}, {"limit", 1.5}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+		"Reduce": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"count", bson.D{{"$reduce", bson.D{
+						{"input", "$v"},
+						{"initialValue", int32(0)},
+						{"in", bson.D{{"$sum", bson.A{"$$value", int32(1)}}}},
+					}}}},
+					{"last", bson.D{{"$reduce", bson.D{{"input", "$v"}, {"initialValue", nil}, {"in", "$$this"}}}}},
+				}}},
+			},
+		},
+		"Zip": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"z", bson.D{{"$zip", bson.D{{"inputs", bson.A{"$v", "$v"}}}}}},
+					{"longest", bson.D{{"$zip", bson.D{
+						{"inputs", bson.A{"$v", bson.A{int32(1)}}},
+						{"useLongestLength", true},
+					}}}},
+				}}},
+			},
+		},
+		"ZipNotArray": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{{"z", bson.D{{"$zip", bson.D{{"inputs", bson.A{"$v", "$_id"}}}}}}}}},
+			},
+			resultType: emptyResult,
+		},
+		"ArrayToObject": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"o", bson.D{{"$arrayToObject", bson.D{{"$map", bson.D{
+						{"input", "$v"},
+						{"in", bson.D{{"k", "key"}, {"v", "$$this"}}},
+					}}}}}},
+				}}},
+			},
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatProjectFieldOperators(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{shareddata.Scalars, shareddata.Composites}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"GetField": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"id", bson.D{{"$getField", "_id"}}},
+					{"v", bson.D{{"$getField", bson.D{{"field", "v"}, {"input", "$$ROOT"}}}}},
+				}}},
+			},
+		},
+		"SetField": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"d", bson.D{{"$setField", bson.D{
+						{"field", bson.D{{"$literal", "$v.x"}}},
+						{"input", "$$ROOT"},
+						{"value", "$v"},
+					}}}},
+				}}},
+			},
+		},
+		"UnsetField": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"d", bson.D{{"$unsetField", bson.D{{"field", "v"}, {"input", "$$ROOT"}}}}},
+				}}},
+			},
+		},
+		"InputNotObject": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"d", bson.D{{"$unsetField", bson.D{{"field", "v"}, {"input", "$_id"}}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatGroupStdDev(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{shareddata.Int32s, shareddata.Int64s, shareddata.Doubles, shareddata.Scalars}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"GroupNullID": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$group", bson.D{
+					{"_id", nil},
+					{"pop", bson.D{{"$stdDevPop", "$v"}}},
+					{"samp", bson.D{{"$stdDevSamp", "$v"}}},
+				}}},
+			},
+		},
+		"GroupByID": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$group", bson.D{
+					{"_id", "$_id"},
+					{"pop", bson.D{{"$stdDevPop", "$v"}}},
+					{"samp", bson.D{{"$stdDevSamp", "$v"}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", -1}}}},
+			},
+		},
+		"Constant": {
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{
+					{"_id", nil},
+					{"pop", bson.D{{"$stdDevPop", int32(1)}}},
+					{"samp", bson.D{{"$stdDevSamp", "foo"}}},
+				}}},
+			},
+		},
+		"NonExistent": {
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{
+					{"_id", nil},
+					{"pop", bson.D{{"$stdDevPop", "$non-existent"}}},
+					{"samp", bson.D{{"$stdDevSamp", "$non-existent"}}},
+				}}},
+			},
+		},
+		"NotUnary": {
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{
+					{"_id", nil},
+					{"pop", bson.D{{"$stdDevPop", bson.A{"$v", "$v"}}}},
+				}}},
+			},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatProjectConvert(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{shareddata.Scalars, shareddata.Composites}
+
+	testCases := map[string]aggregateStagesCompatTestCase{}
+
+	for name, to := range map[string]string{
+		"ToDouble":   "double",
+		"ToString":   "string",
+		"ToObjectID": "objectId",
+		"ToBool":     "bool",
+		"ToDate":     "date",
+		"ToInt":      "int",
+		"ToLong":     "long",
+		"ToDecimal":  "decimal",
+	} {
+		testCases[name] = aggregateStagesCompatTestCase{
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$project", bson.D{{"v", bson.D{{"$convert", bson.D{
+					{"input", "$v"},
+					{"to", to},
+					{"onError", "error"},
+					{"onNull", "null"},
+				}}}}}}},
+			},
+		}
+	}
+
+	testCases["ToLongShorthand"] = aggregateStagesCompatTestCase{
+		pipeline: bson.A{
+			bson.D{{"$match", bson.D{{"v", bson.D{{"$type", bson.A{"int", "long", "bool"}}}}}}},
+			bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			bson.D{{"$project", bson.D{{"v", bson.D{{"$toLong", "$v"}}}}}},
+		},
+	}
+	testCases["ToStringShorthand"] = aggregateStagesCompatTestCase{
+		pipeline: bson.A{
+			bson.D{{"$match", bson.D{{"v", bson.D{{"$type", bson.A{"string", "int", "long", "objectId", "bool"}}}}}}},
+			bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			bson.D{{"$project", bson.D{{"v", bson.D{{"$toString", "$v"}}}}}},
+		},
+	}
+	testCases["NumericTo"] = aggregateStagesCompatTestCase{
+		pipeline: bson.A{
+			bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			bson.D{{"$project", bson.D{{"v", bson.D{{"$convert", bson.D{
+				{"input", "$v"},
+				{"to", int32(8)},
+			}}}}}}},
+		},
+	}
+	testCases["InvalidTo"] = aggregateStagesCompatTestCase{
+		pipeline: bson.A{
+			bson.D{{"$project", bson.D{{"v", bson.D{{"$convert", bson.D{
+				{"input", "$v"},
+				{"to", int32(100)},
+				{"onError", "error"},
+			}}}}}}},
+		},
+		resultType: emptyResult,
+	}
+	testCases["Unsupported"] = aggregateStagesCompatTestCase{
+		pipeline: bson.A{
+			bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "array"}}}}}},
+			bson.D{{"$project", bson.D{{"v", bson.D{{"$toInt", "$v"}}}}}},
+		},
+		resultType: emptyResult,
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
@@ -17,12 +17,16 @@ package integration
 import (
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 	"github.com/FerretDB/FerretDB/integration/shareddata"
@@ -1300,3 +1304,2829 @@ func TestAggregateCommandCursor(t *testing.T) {
 		})
 	}
 }
+
+func TestAggregateFacetExplain(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.Scalars)
+
+	match := bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "string"}}}}}}
+
+	var res bson.D
+	err := collection.Database().RunCommand(ctx, bson.D{
+		{"explain", bson.D{
+			{"aggregate", collection.Name()},
+			{"pipeline", bson.A{
+				bson.D{{"$facet", bson.D{
+					{"all", bson.A{bson.D{{"$count", "n"}}}},
+					{"strings", bson.A{match, bson.D{{"$count", "n"}}}},
+					{"ids", bson.A{match, bson.D{{"$project", bson.D{{"_id", 1}}}}}},
+				}}},
+			}},
+		}},
+		{"verbosity", "executionStats"},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	t.Run("Facets", func(tt *testing.T) {
+		t := setup.FailsForMongoDB(tt, "MongoDB does not report per-facet statistics")
+
+		stages, ok := res.Map()["stages"].(bson.A)
+		require.True(t, ok)
+		require.Len(t, stages, 1)
+
+		facets, ok := stages[0].(bson.D).Map()["facets"].(bson.D)
+		require.True(t, ok)
+
+		all := facets.Map()["all"].(bson.D).Map()
+		assert.Equal(t, int64(1), all["nReturned"])
+		assert.Equal(t, true, all["countOnly"])
+		assert.Equal(t, false, all["sharedMatch"])
+
+		strings := facets.Map()["strings"].(bson.D).Map()
+		assert.Equal(t, true, strings["countOnly"])
+		assert.Equal(t, false, strings["sharedMatch"])
+
+		ids := facets.Map()["ids"].(bson.D).Map()
+		assert.Equal(t, false, ids["countOnly"])
+		assert.Equal(t, true, ids["sharedMatch"])
+		assert.Greater(t, ids["nReturned"], int64(0))
+	})
+}
+
+func TestAggregateGroupStdDev(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	var docs []any
+	for i, v := range []any{int32(2), int64(4), 4.0, int32(4), int32(5), int32(5), int32(7), int32(9), "foo", nil} {
+		docs = append(docs, bson.D{{"_id", int32(i)}, {"k", "a"}, {"v", v}})
+	}
+
+	docs = append(docs, bson.D{{"_id", "b1"}, {"k", "b"}, {"v", int32(42)}}, bson.D{{"_id", "c1"}, {"k", "c"}})
+
+	_, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	cursor, err := collection.Aggregate(ctx, bson.A{
+		bson.D{{"$group", bson.D{
+			{"_id", "$k"},
+			{"pop", bson.D{{"$stdDevPop", "$v"}}},
+			{"samp", bson.D{{"$stdDevSamp", "$v"}}},
+		}}},
+		bson.D{{"$sort", bson.D{{"_id", 1}}}},
+	})
+	require.NoError(t, err)
+
+	var res []bson.D
+	require.NoError(t, cursor.All(ctx, &res))
+
+	expected := []bson.D{
+		{{"_id", "a"}, {"pop", 2.0}, {"samp", math.Sqrt(32.0 / 7)}},
+		{{"_id", "b"}, {"pop", 0.0}, {"samp", nil}},
+		{{"_id", "c"}, {"pop", nil}, {"samp", nil}},
+	}
+	assert.Equal(t, expected, res)
+}
+
+func TestAggregateGroupAccumulator(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "a1"}, {"k", "a"}, {"v", int32(1)}},
+		bson.D{{"_id", "a2"}, {"k", "a"}, {"v", int32(2)}},
+		bson.D{{"_id", "b1"}, {"k", "b"}, {"v", int32(5)}},
+		bson.D{{"_id", "b2"}, {"k", "b"}},
+	})
+	require.NoError(t, err)
+
+	t.Run("Expression", func(tt *testing.T) {
+		t := setup.FailsForMongoDB(tt, "lang 'expression' is FerretDB-specific")
+
+		cursor, err := collection.Aggregate(ctx, bson.A{
+			bson.D{{"$group", bson.D{
+				{"_id", "$k"},
+				{"stats", bson.D{{"$accumulator", bson.D{
+					{"init", bson.D{{"count", "$arg0"}, {"sum", int32(0)}}},
+					{"initArgs", bson.A{int32(0)}},
+					{"accumulate", bson.D{
+						{"count", bson.D{{"$sum", bson.A{"$state.count", int32(1)}}}},
+						{"sum", bson.D{{"$sum", bson.A{"$state.sum", "$arg0"}}}},
+					}},
+					{"accumulateArgs", bson.A{"$v"}},
+					{"merge", bson.D{{"$sum", bson.A{"$state1", "$state2"}}}},
+					{"finalize", "$state.sum"},
+					{"lang", "expression"},
+				}}}},
+			}}},
+			bson.D{{"$sort", bson.D{{"_id", 1}}}},
+		})
+		require.NoError(t, err)
+
+		var res []bson.D
+		require.NoError(t, cursor.All(ctx, &res))
+
+		expected := []bson.D{
+			{{"_id", "a"}, {"stats", int32(3)}},
+			{{"_id", "b"}, {"stats", int32(5)}},
+		}
+		assert.Equal(t, expected, res)
+	})
+
+	for name, tc := range map[string]struct {
+		accumulator bson.D
+		err         *mongo.CommandError
+	}{
+		"JS": {
+			accumulator: bson.D{
+				{"init", "function() { return 0 }"},
+				{"accumulate", "function(state, v) { return state + v }"},
+				{"accumulateArgs", bson.A{"$v"}},
+				{"merge", "function(s1, s2) { return s1 + s2 }"},
+				{"lang", "js"},
+			},
+			err: &mongo.CommandError{
+				Code:    238,
+				Name:    "NotImplemented",
+				Message: "$accumulator with lang 'js' is not implemented yet; use lang 'expression' with aggregation expressions",
+			},
+		},
+		"MissingMerge": {
+			accumulator: bson.D{
+				{"init", int32(0)},
+				{"accumulate", "$state"},
+				{"accumulateArgs", bson.A{}},
+				{"lang", "expression"},
+			},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "$accumulator missing required argument 'merge'",
+			},
+		},
+		"AccumulateArgsNotArray": {
+			accumulator: bson.D{
+				{"init", int32(0)},
+				{"accumulate", "$state"},
+				{"accumulateArgs", "$v"},
+				{"merge", "$state1"},
+				{"lang", "expression"},
+			},
+			err: &mongo.CommandError{
+				Code:    14,
+				Name:    "TypeMismatch",
+				Message: `$accumulator accumulateArgs must be an array: found "$v"`,
+			},
+		},
+		"UnknownField": {
+			accumulator: bson.D{
+				{"init", int32(0)},
+				{"foo", int32(0)},
+			},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "$accumulator got an unrecognized field: foo",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(tt *testing.T) {
+			tt.Parallel()
+
+			t := setup.FailsForMongoDB(tt, "MongoDB supports only lang 'js'")
+
+			_, err := collection.Aggregate(ctx, bson.A{
+				bson.D{{"$group", bson.D{
+					{"_id", "$k"},
+					{"v", bson.D{{"$accumulator", tc.accumulator}}},
+				}}},
+			})
+			AssertEqualCommandError(t, *tc.err, err)
+		})
+	}
+}
+
+func TestAggregateLookup(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "o1"}, {"item", "a"}, {"qty", int32(1)}},
+		bson.D{{"_id", "o2"}, {"item", "b"}, {"qty", int32(2)}},
+		bson.D{{"_id", "o3"}, {"item", bson.A{"a", "c"}}, {"qty", int32(3)}},
+		bson.D{{"_id", "o4"}},
+	})
+	require.NoError(t, err)
+
+	inventory := collection.Database().Collection(collection.Name() + "_inventory")
+	t.Cleanup(func() { require.NoError(t, inventory.Drop(ctx)) })
+
+	_, err = inventory.InsertMany(ctx, []any{
+		bson.D{{"_id", "i1"}, {"sku", "a"}, {"stock", int32(10)}},
+		bson.D{{"_id", "i2"}, {"sku", "b"}, {"stock", int32(20)}},
+		bson.D{{"_id", "i3"}, {"sku", "c"}, {"stock", int32(30)}},
+		bson.D{{"_id", "i4"}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A
+		expected []bson.D
+		err      *mongo.CommandError
+	}{
+		"Fields": {
+			pipeline: bson.A{
+				bson.D{{"$lookup", bson.D{
+					{"from", inventory.Name()},
+					{"localField", "item"},
+					{"foreignField", "sku"},
+					{"as", "docs"},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", "o1"}, {"item", "a"}, {"qty", int32(1)}, {"docs", bson.A{
+					bson.D{{"_id", "i1"}, {"sku", "a"}, {"stock", int32(10)}},
+				}}},
+				{{"_id", "o2"}, {"item", "b"}, {"qty", int32(2)}, {"docs", bson.A{
+					bson.D{{"_id", "i2"}, {"sku", "b"}, {"stock", int32(20)}},
+				}}},
+				{{"_id", "o3"}, {"item", bson.A{"a", "c"}}, {"qty", int32(3)}, {"docs", bson.A{
+					bson.D{{"_id", "i1"}, {"sku", "a"}, {"stock", int32(10)}},
+					bson.D{{"_id", "i3"}, {"sku", "c"}, {"stock", int32(30)}},
+				}}},
+				{{"_id", "o4"}, {"docs", bson.A{bson.D{{"_id", "i4"}}}}},
+			},
+		},
+		"PipelineLet": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"_id", "o2"}}}},
+				bson.D{{"$lookup", bson.D{
+					{"from", inventory.Name()},
+					{"let", bson.D{{"item", "$item"}}},
+					{"pipeline", bson.A{
+						bson.D{{"$match", bson.D{{"$expr", bson.D{{"$eq", bson.A{"$sku", "$$item"}}}}}}},
+						bson.D{{"$project", bson.D{{"_id", 0}, {"stock", 1}}}},
+					}},
+					{"as", "docs"},
+				}}},
+			},
+			expected: []bson.D{
+				{{"_id", "o2"}, {"item", "b"}, {"qty", int32(2)}, {"docs", bson.A{bson.D{{"stock", int32(20)}}}}},
+			},
+		},
+		"FieldsAndPipeline": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"_id", "o3"}}}},
+				bson.D{{"$lookup", bson.D{
+					{"from", inventory.Name()},
+					{"localField", "item"},
+					{"foreignField", "sku"},
+					{"pipeline", bson.A{
+						bson.D{{"$match", bson.D{{"stock", bson.D{{"$gt", int32(10)}}}}}},
+						bson.D{{"$project", bson.D{{"stock", 1}}}},
+					}},
+					{"as", "docs"},
+				}}},
+			},
+			expected: []bson.D{
+				{
+					{"_id", "o3"}, {"item", bson.A{"a", "c"}}, {"qty", int32(3)},
+					{"docs", bson.A{bson.D{{"_id", "i3"}, {"stock", int32(30)}}}},
+				},
+			},
+		},
+		"MissingAs": {
+			pipeline: bson.A{
+				bson.D{{"$lookup", bson.D{
+					{"from", inventory.Name()},
+					{"localField", "item"},
+					{"foreignField", "sku"},
+				}}},
+			},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "must specify 'as' field for a $lookup",
+			},
+		},
+		"OnlyLocalField": {
+			pipeline: bson.A{
+				bson.D{{"$lookup", bson.D{
+					{"from", inventory.Name()},
+					{"localField", "item"},
+					{"as", "docs"},
+				}}},
+			},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "$lookup requires both or neither of 'localField' and 'foreignField' to be specified",
+			},
+		},
+		"NotAllowedStage": {
+			pipeline: bson.A{
+				bson.D{{"$lookup", bson.D{
+					{"from", inventory.Name()},
+					{"pipeline", bson.A{bson.D{{"$out", "foo"}}}},
+					{"as", "docs"},
+				}}},
+			},
+			err: &mongo.CommandError{
+				Code:    51047,
+				Name:    "Location51047",
+				Message: "$out is not allowed within a $lookup's sub-pipeline",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			if tc.err != nil {
+				AssertEqualCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestAggregateRedact(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{
+			{"_id", int32(1)},
+			{"level", "descend"},
+			{"a", bson.D{{"level", "prune"}, {"secret", int32(1)}}},
+			{"b", bson.D{{"level", "keep"}, {"c", bson.D{{"level", int32(42)}}}}},
+			{"arr", bson.A{
+				bson.D{{"level", "prune"}},
+				bson.D{{"level", "descend"}, {"x", int32(1)}},
+				int32(2),
+			}},
+		},
+		bson.D{{"_id", int32(2)}, {"level", "prune"}},
+		bson.D{{"_id", int32(3)}, {"level", "keep"}, {"a", bson.D{{"level", "prune"}}}},
+		bson.D{{"_id", int32(4)}, {"level", "descend"}, {"a", bson.D{{"foo", "bar"}}}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A
+		expected []bson.D
+		err      *mongo.CommandError
+	}{
+		"FieldPath": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"_id", bson.D{{"$ne", int32(4)}}}}}},
+				bson.D{{"$redact", "$level"}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+			expected: []bson.D{
+				{
+					{"_id", int32(1)},
+					{"level", "descend"},
+					{"b", bson.D{{"level", "keep"}, {"c", bson.D{{"level", int32(42)}}}}},
+					{"arr", bson.A{bson.D{{"level", "descend"}, {"x", int32(1)}}, int32(2)}},
+				},
+				{{"_id", int32(3)}, {"level", "keep"}, {"a", bson.D{{"level", "prune"}}}},
+			},
+		},
+		"Variable": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"_id", int32(2)}}}},
+				bson.D{{"$redact", "$$KEEP"}},
+			},
+			expected: []bson.D{{{"_id", int32(2)}, {"level", "prune"}}},
+		},
+		"InvalidResult": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"_id", int32(4)}}}},
+				bson.D{{"$redact", "$level"}},
+			},
+			err: &mongo.CommandError{
+				Code:    17053,
+				Name:    "Location17053",
+				Message: "$redact's expression should not return anything aside from the variables $KEEP, $DESCEND, and $PRUNE, but returned null",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			if tc.err != nil {
+				AssertEqualCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestAggregateSamplePushdown(t *testing.T) {
+	t.Parallel()
+
+	// must use a collection of documents which does not support filter pushdown
+	s := setup.SetupWithOpts(t, &setup.SetupOpts{Providers: []shareddata.Provider{shareddata.Composites}})
+	ctx, collection := s.Ctx, s.Collection
+
+	for name, tc := range map[string]struct { //nolint:vet // used for testing only
+		pipeline       bson.A
+		len            int            // expected length of results
+		samplePushdown resultPushdown // optional, defaults to noPushdown
+	}{
+		"Sample": {
+			pipeline:       bson.A{bson.D{{"$sample", bson.D{{"size", int32(3)}}}}},
+			len:            3,
+			samplePushdown: allPushdown,
+		},
+		"All": {
+			pipeline:       bson.A{bson.D{{"$sample", bson.D{{"size", int64(len(shareddata.Composites.Docs()))}}}}},
+			len:            len(shareddata.Composites.Docs()),
+			samplePushdown: allPushdown,
+		},
+		"More": {
+			pipeline:       bson.A{bson.D{{"$sample", bson.D{{"size", 1000.0}}}}},
+			len:            len(shareddata.Composites.Docs()),
+			samplePushdown: allPushdown,
+		},
+		"Zero": {
+			pipeline:       bson.A{bson.D{{"$sample", bson.D{{"size", int32(0)}}}}},
+			len:            0,
+			samplePushdown: noPushdown,
+		},
+		"BeforeSort": {
+			pipeline: bson.A{
+				bson.D{{"$sample", bson.D{{"size", int32(2)}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+			len:            2,
+			samplePushdown: allPushdown,
+		},
+		"AfterMatch": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"_id", bson.D{{"$ne", "array"}}}}}},
+				bson.D{{"$sample", bson.D{{"size", int32(2)}}}},
+			},
+			len:            2,
+			samplePushdown: noPushdown,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			t.Run("Explain", func(t *testing.T) {
+				setup.SkipForMongoDB(t, "pushdown is FerretDB specific feature")
+
+				var res bson.D
+				err := collection.Database().RunCommand(ctx, bson.D{{"explain", bson.D{
+					{"aggregate", collection.Name()},
+					{"pipeline", tc.pipeline},
+				}}}).Decode(&res)
+				require.NoError(t, err)
+
+				samplePushdown, _ := ConvertDocument(t, res).Get("samplePushdown")
+				assert.Equal(t, tc.samplePushdown.PushdownExpected(t), samplePushdown)
+			})
+
+			t.Run("Aggregate", func(t *testing.T) {
+				cursor, err := collection.Aggregate(ctx, tc.pipeline)
+				require.NoError(t, err)
+
+				// do not check the content, sampled documents are random
+				require.Len(t, FetchAll(t, ctx, cursor), tc.len)
+			})
+		})
+	}
+}
+
+func TestAggregateMerge(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"v", "a"}, {"n", int32(1)}},
+		bson.D{{"_id", int32(2)}, {"v", "b"}, {"n", int32(2)}},
+		bson.D{{"_id", int32(3)}, {"v", "c"}, {"n", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	targetDocs := []any{
+		bson.D{{"_id", int32(1)}, {"v", "x"}, {"m", int32(10)}},
+		bson.D{{"_id", int32(4)}, {"v", "d"}},
+	}
+
+	for name, tc := range map[string]struct { //nolint:vet // for readability
+		merge    func(into string) any // $merge stage argument for the target collection
+		stages   bson.A                // stages before $merge
+		index    mongo.IndexModel      // optional, created on the target collection
+		expected []bson.D              // target collection documents
+		err      *mongo.CommandError
+	}{
+		"Default": {
+			merge: func(into string) any { return bson.D{{"into", into}} },
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", "a"}, {"m", int32(10)}, {"n", int32(1)}},
+				{{"_id", int32(2)}, {"v", "b"}, {"n", int32(2)}},
+				{{"_id", int32(3)}, {"v", "c"}, {"n", int32(3)}},
+				{{"_id", int32(4)}, {"v", "d"}},
+			},
+		},
+		"String": {
+			merge: func(into string) any { return into },
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", "a"}, {"m", int32(10)}, {"n", int32(1)}},
+				{{"_id", int32(2)}, {"v", "b"}, {"n", int32(2)}},
+				{{"_id", int32(3)}, {"v", "c"}, {"n", int32(3)}},
+				{{"_id", int32(4)}, {"v", "d"}},
+			},
+		},
+		"IntoDatabase": {
+			merge: func(into string) any {
+				return bson.D{
+					{"into", bson.D{{"db", collection.Database().Name()}, {"coll", into}}},
+					{"whenNotMatched", "discard"},
+				}
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", "a"}, {"m", int32(10)}, {"n", int32(1)}},
+				{{"_id", int32(4)}, {"v", "d"}},
+			},
+		},
+		"Replace": {
+			merge: func(into string) any { return bson.D{{"into", into}, {"whenMatched", "replace"}} },
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", "a"}, {"n", int32(1)}},
+				{{"_id", int32(2)}, {"v", "b"}, {"n", int32(2)}},
+				{{"_id", int32(3)}, {"v", "c"}, {"n", int32(3)}},
+				{{"_id", int32(4)}, {"v", "d"}},
+			},
+		},
+		"KeepExisting": {
+			merge: func(into string) any { return bson.D{{"into", into}, {"whenMatched", "keepExisting"}} },
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", "x"}, {"m", int32(10)}},
+				{{"_id", int32(2)}, {"v", "b"}, {"n", int32(2)}},
+				{{"_id", int32(3)}, {"v", "c"}, {"n", int32(3)}},
+				{{"_id", int32(4)}, {"v", "d"}},
+			},
+		},
+		"Pipeline": {
+			merge: func(into string) any {
+				return bson.D{
+					{"into", into},
+					{"whenMatched", bson.A{bson.D{{"$set", bson.D{{"v", "$$new.v"}, {"type", bson.D{{"$type", "$v"}}}}}}}},
+					{"whenNotMatched", "discard"},
+				}
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", "a"}, {"m", int32(10)}, {"type", "string"}},
+				{{"_id", int32(4)}, {"v", "d"}},
+			},
+		},
+		"PipelineLet": {
+			merge: func(into string) any {
+				return bson.D{
+					{"into", into},
+					{"let", bson.D{{"n", "$n"}}},
+					{"whenMatched", bson.A{bson.D{{"$addFields", bson.D{{"sum", bson.D{{"$sum", bson.A{"$m", "$$n"}}}}}}}}},
+					{"whenNotMatched", "discard"},
+				}
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", "x"}, {"m", int32(10)}, {"sum", int32(11)}},
+				{{"_id", int32(4)}, {"v", "d"}},
+			},
+		},
+		"On": {
+			stages: bson.A{bson.D{{"$project", bson.D{{"_id", 0}}}}},
+			merge: func(into string) any {
+				return bson.D{{"into", into}, {"on", "v"}, {"whenMatched", "replace"}, {"whenNotMatched", "discard"}}
+			},
+			index: mongo.IndexModel{
+				Keys:    bson.D{{"v", 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", "x"}, {"m", int32(10)}},
+				{{"_id", int32(4)}, {"v", "d"}},
+			},
+		},
+		"FailNotMatched": {
+			merge: func(into string) any { return bson.D{{"into", into}, {"whenNotMatched", "fail"}} },
+			err: &mongo.CommandError{
+				Code: 13113,
+				Name: "Location13113",
+				Message: "$merge could not find a matching document in the target collection " +
+					"for at least one document in the source collection",
+			},
+		},
+		"OnNotUnique": {
+			merge: func(into string) any { return bson.D{{"into", into}, {"on", "v"}} },
+			err: &mongo.CommandError{
+				Code:    51183,
+				Name:    "Location51183",
+				Message: "Cannot find index to verify that join fields will be unique",
+			},
+		},
+		"OnMissing": {
+			stages: bson.A{bson.D{{"$project", bson.D{{"v", 0}}}}},
+			merge:  func(into string) any { return bson.D{{"into", into}, {"on", "v"}} },
+			index: mongo.IndexModel{
+				Keys:    bson.D{{"v", 1}},
+				Options: options.Index().SetUnique(true),
+			},
+			err: &mongo.CommandError{
+				Code:    51132,
+				Name:    "Location51132",
+				Message: "$merge write error: 'on' field cannot be missing, null, undefined or an array",
+			},
+		},
+		"NotLast": {
+			merge: func(into string) any { return into },
+			stages: bson.A{
+				bson.D{{"$merge", "foo"}},
+			},
+			err: &mongo.CommandError{
+				Code:    40601,
+				Name:    "Location40601",
+				Message: "$merge can only be the final stage in the pipeline",
+			},
+		},
+		"InvalidWhenMatched": {
+			merge: func(into string) any { return bson.D{{"into", into}, {"whenMatched", "foo"}} },
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "Enumeration value 'foo' for field 'whenMatched' is not a valid value.",
+			},
+		},
+		"InvalidPipelineStage": {
+			merge: func(into string) any {
+				return bson.D{{"into", into}, {"whenMatched", bson.A{bson.D{{"$match", bson.D{}}}}}}
+			},
+			err: &mongo.CommandError{
+				Code:    72,
+				Name:    "InvalidOptions",
+				Message: "$match is not allowed to be used within an update",
+			},
+		},
+		"InvalidArg": {
+			merge: func(string) any { return int32(1) },
+			err: &mongo.CommandError{
+				Code:    51182,
+				Name:    "Location51182",
+				Message: "$merge only supports a string or object argument, but found int",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			target := collection.Database().Collection(collection.Name() + "_" + name)
+
+			_, err := target.InsertMany(ctx, targetDocs)
+			require.NoError(t, err)
+
+			if tc.index.Keys != nil {
+				_, err = target.Indexes().CreateOne(ctx, tc.index)
+				require.NoError(t, err)
+			}
+
+			pipeline := append(bson.A{}, tc.stages...)
+			pipeline = append(pipeline, bson.D{{"$merge", tc.merge(target.Name())}})
+
+			cursor, err := collection.Aggregate(ctx, pipeline)
+			if tc.err != nil {
+				AssertEqualCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Empty(t, FetchAll(t, ctx, cursor))
+
+			cursor, err = target.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, FetchAll(t, ctx, cursor))
+		})
+	}
+}
+
+func TestAggregateMergeDuplicateKey(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", int32(1)}, {"v", "a"}})
+	require.NoError(t, err)
+
+	target := collection.Database().Collection(collection.Name() + "_target")
+
+	_, err = target.InsertOne(ctx, bson.D{{"_id", int32(1)}, {"v", "x"}})
+	require.NoError(t, err)
+
+	pipeline := bson.A{bson.D{{"$merge", bson.D{{"into", target.Name()}, {"whenMatched", "fail"}}}}}
+
+	_, err = collection.Aggregate(ctx, pipeline)
+
+	ns := collection.Database().Name() + "." + target.Name()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    11000,
+		Name:    "DuplicateKey",
+		Message: "E11000 duplicate key error collection: " + ns + " index: _id_ dup key: { _id: 1 }",
+	}, err)
+}
+
+func TestAggregateOut(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"v", "a"}},
+		bson.D{{"_id", int32(2)}, {"v", "b"}},
+		bson.D{{"_id", int32(3)}, {"v", "c"}},
+	})
+	require.NoError(t, err)
+
+	targetDB := collection.Database().Client().Database(collection.Database().Name() + "_out")
+
+	t.Cleanup(func() {
+		require.NoError(t, targetDB.Drop(ctx))
+	})
+
+	for name, tc := range map[string]struct { //nolint:vet // for readability
+		out      func(db *mongo.Database, coll string) any // $out stage argument for the target collection
+		db       *mongo.Database                           // target database, the current one if nil
+		stages   bson.A                                    // stages before $out
+		expected []bson.D                                  // target collection documents
+		err      *mongo.CommandError
+	}{
+		"String": {
+			out: func(_ *mongo.Database, coll string) any { return coll },
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", "a"}},
+				{{"_id", int32(2)}, {"v", "b"}},
+				{{"_id", int32(3)}, {"v", "c"}},
+			},
+		},
+		"Document": {
+			out: func(db *mongo.Database, coll string) any { return bson.D{{"db", db.Name()}, {"coll", coll}} },
+			db:  targetDB,
+			stages: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$ne", "b"}}}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", "a"}},
+				{{"_id", int32(3)}, {"v", "c"}},
+			},
+		},
+		"Empty": {
+			out: func(_ *mongo.Database, coll string) any { return coll },
+			stages: bson.A{
+				bson.D{{"$match", bson.D{{"v", "z"}}}},
+			},
+		},
+		"NotLast": {
+			out: func(_ *mongo.Database, coll string) any { return coll },
+			stages: bson.A{
+				bson.D{{"$out", "foo"}},
+			},
+			err: &mongo.CommandError{
+				Code:    40601,
+				Name:    "Location40601",
+				Message: "$out can only be the final stage in the pipeline",
+			},
+		},
+		"InvalidArg": {
+			out: func(*mongo.Database, string) any { return int32(1) },
+			err: &mongo.CommandError{
+				Code:    16990,
+				Name:    "Location16990",
+				Message: "$out only supports a string or object argument, but found int",
+			},
+		},
+		"MissingDB": {
+			out: func(_ *mongo.Database, coll string) any { return bson.D{{"coll", coll}} },
+			err: &mongo.CommandError{
+				Code:    40414,
+				Name:    "Location40414",
+				Message: "BSON field '$out.db' is missing but a required field",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			db := tc.db
+			if db == nil {
+				db = collection.Database()
+			}
+
+			target := db.Collection(collection.Name() + "_" + name)
+
+			_, err := target.InsertMany(ctx, []any{
+				bson.D{{"_id", int32(1)}, {"v", "x"}},
+				bson.D{{"_id", int32(4)}, {"v", "d"}},
+			})
+			require.NoError(t, err)
+
+			_, err = target.Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{"v", 1}},
+				Options: options.Index().SetName("v_1"),
+			})
+			require.NoError(t, err)
+
+			pipeline := append(bson.A{}, tc.stages...)
+			pipeline = append(pipeline, bson.D{{"$out", tc.out(db, target.Name())}})
+
+			cursor, err := collection.Aggregate(ctx, pipeline)
+			if tc.err != nil {
+				AssertEqualCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Empty(t, FetchAll(t, ctx, cursor))
+
+			cursor, err = target.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, FetchAll(t, ctx, cursor))
+
+			names, err := target.Indexes().ListSpecifications(ctx)
+			require.NoError(t, err)
+			require.Len(t, names, 2)
+			assert.Equal(t, "v_1", names[1].Name)
+
+			collections, err := db.ListCollectionNames(ctx, bson.D{{"name", bson.D{{"$regex", "^tmp\\."}}}})
+			require.NoError(t, err)
+			assert.Empty(t, collections)
+		})
+	}
+}
+
+func TestAggregateDensifyFill(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.TimeSeries)
+
+	hour := func(h int) primitive.DateTime {
+		return primitive.NewDateTimeFromTime(time.Date(2024, 1, 1, h, 0, 0, 0, time.UTC))
+	}
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A
+		expected []bson.D
+	}{
+		"DensifyPartition": {
+			pipeline: bson.A{
+				bson.D{{"$densify", bson.D{
+					{"field", "n"},
+					{"partitionByFields", bson.A{"sensor"}},
+					{"range", bson.D{{"step", int32(2)}, {"bounds", "partition"}}},
+				}}},
+				bson.D{{"$match", bson.D{{"sensor", "a"}}}},
+				bson.D{{"$sort", bson.D{{"n", 1}}}},
+				bson.D{{"$project", bson.D{{"_id", 0}, {"sensor", 1}, {"n", 1}}}},
+			},
+			expected: []bson.D{
+				{{"sensor", "a"}, {"n", int32(0)}},
+				{{"sensor", "a"}, {"n", int32(1)}},
+				{{"sensor", "a"}, {"n", int32(2)}},
+				{{"sensor", "a"}, {"n", int32(3)}},
+				{{"sensor", "a"}, {"n", int32(4)}},
+				{{"sensor", "a"}, {"n", int32(6)}},
+			},
+		},
+		"DensifyDate": {
+			pipeline: bson.A{
+				bson.D{{"$densify", bson.D{
+					{"field", "time"},
+					{"partitionByFields", bson.A{"sensor"}},
+					{"range", bson.D{{"step", int32(1)}, {"unit", "hour"}, {"bounds", "partition"}}},
+				}}},
+				bson.D{{"$match", bson.D{{"sensor", "b"}}}},
+				bson.D{{"$sort", bson.D{{"time", 1}}}},
+				bson.D{{"$project", bson.D{{"_id", 0}, {"time", 1}}}},
+			},
+			expected: []bson.D{
+				{{"time", hour(0)}},
+				{{"time", hour(1)}},
+				{{"time", hour(2)}},
+				{{"time", hour(3)}},
+				{{"time", hour(4)}},
+			},
+		},
+		"FillLocf": {
+			pipeline: bson.A{
+				bson.D{{"$fill", bson.D{
+					{"sortBy", bson.D{{"n", 1}}},
+					{"partitionByFields", bson.A{"sensor"}},
+					{"output", bson.D{{"value", bson.D{{"method", "locf"}}}}},
+				}}},
+				bson.D{{"$match", bson.D{{"sensor", "b"}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$project", bson.D{{"_id", 1}, {"value", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", "b-0"}, {"value", 1.5}},
+				{{"_id", "b-2"}, {"value", 1.5}},
+				{{"_id", "b-4"}, {"value", 4.5}},
+			},
+		},
+		"FillLinear": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"sensor", "a"}}}},
+				bson.D{{"$fill", bson.D{
+					{"sortBy", bson.D{{"n", 1}}},
+					{"output", bson.D{{"value", bson.D{{"method", "linear"}}}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$project", bson.D{{"_id", 1}, {"value", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", "a-0"}, {"value", int32(10)}},
+				{{"_id", "a-1"}, {"value", float64(15)}},
+				{{"_id", "a-3"}, {"value", float64(25)}},
+				{{"_id", "a-6"}, {"value", int32(40)}},
+			},
+		},
+		"FillValue": {
+			pipeline: bson.A{
+				bson.D{{"$fill", bson.D{
+					{"output", bson.D{{"value", bson.D{{"value", "$n"}}}}},
+				}}},
+				bson.D{{"$match", bson.D{{"sensor", "a"}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$project", bson.D{{"_id", 1}, {"value", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", "a-0"}, {"value", int32(10)}},
+				{{"_id", "a-1"}, {"value", int32(1)}},
+				{{"_id", "a-3"}, {"value", int32(3)}},
+				{{"_id", "a-6"}, {"value", int32(40)}},
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestAggregateSetWindowFields(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.TimeSeries)
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A
+		expected []bson.D
+	}{
+		"RunningTotal": {
+			pipeline: bson.A{
+				bson.D{{"$setWindowFields", bson.D{
+					{"partitionBy", "$sensor"},
+					{"sortBy", bson.D{{"n", 1}}},
+					{"output", bson.D{{"total", bson.D{
+						{"$sum", "$n"},
+						{"window", bson.D{{"documents", bson.A{"unbounded", "current"}}}},
+					}}}},
+				}}},
+				bson.D{{"$match", bson.D{{"sensor", "a"}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$project", bson.D{{"_id", 1}, {"total", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", "a-0"}, {"total", int32(0)}},
+				{{"_id", "a-1"}, {"total", int32(1)}},
+				{{"_id", "a-3"}, {"total", int32(4)}},
+				{{"_id", "a-6"}, {"total", int32(10)}},
+			},
+		},
+		"PartitionTotal": {
+			pipeline: bson.A{
+				bson.D{{"$setWindowFields", bson.D{
+					{"partitionBy", "$sensor"},
+					{"output", bson.D{{"total", bson.D{{"$sum", "$n"}}}}},
+				}}},
+				bson.D{{"$match", bson.D{{"sensor", "b"}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$project", bson.D{{"_id", 1}, {"total", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", "b-0"}, {"total", int32(6)}},
+				{{"_id", "b-2"}, {"total", int32(6)}},
+				{{"_id", "b-4"}, {"total", int32(6)}},
+			},
+		},
+		"Rank": {
+			pipeline: bson.A{
+				bson.D{{"$setWindowFields", bson.D{
+					{"sortBy", bson.D{{"sensor", 1}}},
+					{"output", bson.D{
+						{"rank", bson.D{{"$rank", bson.D{}}}},
+						{"dense", bson.D{{"$denseRank", bson.D{}}}},
+					}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$project", bson.D{{"_id", 1}, {"rank", 1}, {"dense", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", "a-0"}, {"rank", int32(1)}, {"dense", int32(1)}},
+				{{"_id", "a-1"}, {"rank", int32(1)}, {"dense", int32(1)}},
+				{{"_id", "a-3"}, {"rank", int32(1)}, {"dense", int32(1)}},
+				{{"_id", "a-6"}, {"rank", int32(1)}, {"dense", int32(1)}},
+				{{"_id", "b-0"}, {"rank", int32(5)}, {"dense", int32(2)}},
+				{{"_id", "b-2"}, {"rank", int32(5)}, {"dense", int32(2)}},
+				{{"_id", "b-4"}, {"rank", int32(5)}, {"dense", int32(2)}},
+				{{"_id", "c-unset"}, {"rank", int32(8)}, {"dense", int32(3)}},
+			},
+		},
+		"Shift": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"sensor", "a"}}}},
+				bson.D{{"$setWindowFields", bson.D{
+					{"sortBy", bson.D{{"n", 1}}},
+					{"output", bson.D{{"prev", bson.D{
+						{"$shift", bson.D{{"output", "$n"}, {"by", int32(-1)}, {"default", "none"}}},
+					}}}},
+				}}},
+				bson.D{{"$project", bson.D{{"_id", 1}, {"prev", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", "a-0"}, {"prev", "none"}},
+				{{"_id", "a-1"}, {"prev", int32(0)}},
+				{{"_id", "a-3"}, {"prev", int32(1)}},
+				{{"_id", "a-6"}, {"prev", int32(3)}},
+			},
+		},
+		"Derivative": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"sensor", "a"}}}},
+				bson.D{{"$setWindowFields", bson.D{
+					{"sortBy", bson.D{{"time", 1}}},
+					{"output", bson.D{{"rate", bson.D{
+						{"$derivative", bson.D{{"input", "$n"}, {"unit", "hour"}}},
+						{"window", bson.D{{"documents", bson.A{int32(-1), "current"}}}},
+					}}}},
+				}}},
+				bson.D{{"$project", bson.D{{"_id", 1}, {"rate", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", "a-0"}, {"rate", nil}},
+				{{"_id", "a-1"}, {"rate", float64(1)}},
+				{{"_id", "a-3"}, {"rate", float64(1)}},
+				{{"_id", "a-6"}, {"rate", float64(1)}},
+			},
+		},
+		"Range": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"sensor", "a"}}}},
+				bson.D{{"$setWindowFields", bson.D{
+					{"sortBy", bson.D{{"n", 1}}},
+					{"output", bson.D{{"count", bson.D{
+						{"$sum", int32(1)},
+						{"window", bson.D{{"range", bson.A{int32(-2), "current"}}}},
+					}}}},
+				}}},
+				bson.D{{"$project", bson.D{{"_id", 1}, {"count", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", "a-0"}, {"count", int32(1)}},
+				{{"_id", "a-1"}, {"count", int32(2)}},
+				{{"_id", "a-3"}, {"count", int32(2)}},
+				{{"_id", "a-6"}, {"count", int32(1)}},
+			},
+		},
+		"RangeUnit": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"sensor", "a"}}}},
+				bson.D{{"$setWindowFields", bson.D{
+					{"sortBy", bson.D{{"time", 1}}},
+					{"output", bson.D{{"total", bson.D{
+						{"$sum", "$n"},
+						{"window", bson.D{{"range", bson.A{int32(-2), int32(0)}}, {"unit", "hour"}}},
+					}}}},
+				}}},
+				bson.D{{"$project", bson.D{{"_id", 1}, {"total", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", "a-0"}, {"total", int32(0)}},
+				{{"_id", "a-1"}, {"total", int32(1)}},
+				{{"_id", "a-3"}, {"total", int32(4)}},
+				{{"_id", "a-6"}, {"total", int32(6)}},
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestAggregateSetWindowFieldsErrors(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific error messages")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.TimeSeries)
+
+	for name, tc := range map[string]struct {
+		stage bson.D
+		err   *mongo.CommandError
+	}{
+		"UnknownFunction": {
+			stage: bson.D{{"output", bson.D{{"v", bson.D{{"$foo", "$n"}}}}}},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "Unrecognized window function, $foo",
+			},
+		},
+		"RankWithoutSortBy": {
+			stage: bson.D{{"output", bson.D{{"v", bson.D{{"$rank", bson.D{}}}}}}},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "$rank must be specified with a top level sortBy expression with exactly one element",
+			},
+		},
+		"ShiftByNotInteger": {
+			stage: bson.D{
+				{"sortBy", bson.D{{"n", 1}}},
+				{"output", bson.D{{"v", bson.D{{"$shift", bson.D{{"output", "$n"}, {"by", 1.5}}}}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "'$shift:by' field must be an integer, but found by: 1.5",
+			},
+		},
+		"LowerExceedsUpper": {
+			stage: bson.D{
+				{"sortBy", bson.D{{"n", 1}}},
+				{"output", bson.D{{"v", bson.D{
+					{"$sum", "$n"},
+					{"window", bson.D{{"documents", bson.A{int32(1), int32(-1)}}}},
+				}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "Lower bound must not exceed upper bound",
+			},
+		},
+		"DerivativeWithoutWindow": {
+			stage: bson.D{
+				{"sortBy", bson.D{{"n", 1}}},
+				{"output", bson.D{{"v", bson.D{{"$derivative", bson.D{{"input", "$n"}}}}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "$derivative requires explicit window bounds",
+			},
+		},
+		"RangeNotNumber": {
+			stage: bson.D{
+				{"sortBy", bson.D{{"sensor", 1}}},
+				{"output", bson.D{{"v", bson.D{
+					{"$sum", "$n"},
+					{"window", bson.D{{"range", bson.A{int32(-1), int32(1)}}}},
+				}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    14,
+				Name:    "TypeMismatch",
+				Message: "Invalid range: Expected the sortBy field to be a number, but it was string",
+			},
+		},
+		"MissingOutput": {
+			stage: bson.D{{"sortBy", bson.D{{"n", 1}}}},
+			err: &mongo.CommandError{
+				Code:    40414,
+				Name:    "Location40414",
+				Message: "BSON field '$setWindowFields.output' is missing but a required field",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pipeline := bson.A{bson.D{{"$setWindowFields", tc.stage}}}
+
+			_, err := collection.Aggregate(ctx, pipeline)
+			AssertEqualCommandError(t, *tc.err, err)
+		})
+	}
+}
+
+func TestAggregateDateOperators(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	date := time.Date(2021, 11, 1, 10, 18, 42, 123000000, time.UTC)
+
+	_, err := collection.InsertOne(ctx, bson.D{
+		{"_id", int32(1)},
+		{"d", primitive.NewDateTimeFromTime(date)},
+		{"s", "2021-11-01T10:18:42.123Z"},
+		{"n", int32(42)},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		project  bson.D
+		expected bson.D
+		err      *mongo.CommandError
+	}{
+		"DateToString": {
+			project: bson.D{
+				{"def", bson.D{{"$dateToString", bson.D{{"date", "$d"}}}}},
+				{"tz", bson.D{{"$dateToString", bson.D{{"date", "$d"}, {"timezone", "America/New_York"}}}}},
+				{"format", bson.D{{"$dateToString", bson.D{
+					{"date", "$d"},
+					{"format", "%d %b %Y, %j %U %V %u %w %z %Z %%"},
+					{"timezone", "+05:30"},
+				}}}},
+				{"onNull", bson.D{{"$dateToString", bson.D{{"date", "$missing"}, {"onNull", "none"}}}}},
+			},
+			expected: bson.D{
+				{"def", "2021-11-01T10:18:42.123Z"},
+				{"tz", "2021-11-01T06:18:42.123"},
+				{"format", "01 Nov 2021, 305 44 44 1 2 +0530 +330 %"},
+				{"onNull", "none"},
+			},
+		},
+		"DateFromString": {
+			project: bson.D{
+				{"iso", bson.D{{"$dateFromString", bson.D{{"dateString", "$s"}}}}},
+				{"format", bson.D{{"$dateFromString", bson.D{
+					{"dateString", "01/11/2021 13:18"},
+					{"format", "%d/%m/%Y %H:%M"},
+					{"timezone", "+03"},
+				}}}},
+				{"onError", bson.D{{"$dateFromString", bson.D{{"dateString", "not a date"}, {"onError", "$n"}}}}},
+				{"onNull", bson.D{{"$dateFromString", bson.D{{"dateString", nil}, {"onNull", "null"}}}}},
+			},
+			expected: bson.D{
+				{"iso", primitive.NewDateTimeFromTime(date)},
+				{"format", primitive.NewDateTimeFromTime(time.Date(2021, 11, 1, 10, 18, 0, 0, time.UTC))},
+				{"onError", int32(42)},
+				{"onNull", "null"},
+			},
+		},
+		"DateFromParts": {
+			project: bson.D{
+				{"parts", bson.D{{"$dateFromParts", bson.D{
+					{"year", int32(2021)},
+					{"month", int32(13)},
+					{"day", "$n"},
+					{"timezone", "Europe/Berlin"},
+				}}}},
+				{"iso", bson.D{{"$dateFromParts", bson.D{
+					{"isoWeekYear", int32(2021)},
+					{"isoWeek", int32(44)},
+					{"isoDayOfWeek", int32(1)},
+					{"hour", int32(10)},
+				}}}},
+				{"null", bson.D{{"$dateFromParts", bson.D{{"year", "$missing"}}}}},
+			},
+			expected: bson.D{
+				{"parts", primitive.NewDateTimeFromTime(time.Date(2022, 2, 10, 23, 0, 0, 0, time.UTC))},
+				{"iso", primitive.NewDateTimeFromTime(time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC))},
+				{"null", nil},
+			},
+		},
+		"DateToParts": {
+			project: bson.D{
+				{"parts", bson.D{{"$dateToParts", bson.D{{"date", "$d"}, {"timezone", "Asia/Tokyo"}}}}},
+				{"iso", bson.D{{"$dateToParts", bson.D{{"date", "$d"}, {"iso8601", true}}}}},
+			},
+			expected: bson.D{
+				{"parts", bson.D{
+					{"year", int32(2021)},
+					{"month", int32(11)},
+					{"day", int32(1)},
+					{"hour", int32(19)},
+					{"minute", int32(18)},
+					{"second", int32(42)},
+					{"millisecond", int32(123)},
+				}},
+				{"iso", bson.D{
+					{"isoWeekYear", int32(2021)},
+					{"isoWeek", int32(44)},
+					{"isoDayOfWeek", int32(1)},
+					{"hour", int32(10)},
+					{"minute", int32(18)},
+					{"second", int32(42)},
+					{"millisecond", int32(123)},
+				}},
+			},
+		},
+		"DateToStringNotDate": {
+			project: bson.D{{"v", bson.D{{"$dateToString", bson.D{{"date", "$n"}}}}}},
+			err: &mongo.CommandError{
+				Code:    16006,
+				Name:    "Location16006",
+				Message: "can't convert from BSON type int to Date",
+			},
+		},
+		"DateToStringInvalidFormat": {
+			project: bson.D{{"v", bson.D{{"$dateToString", bson.D{{"date", "$d"}, {"format", "%Q"}}}}}},
+			err: &mongo.CommandError{
+				Code:    18536,
+				Name:    "Location18536",
+				Message: "Invalid format character '%Q' in format string",
+			},
+		},
+		"DateToStringMissingDate": {
+			project: bson.D{{"v", bson.D{{"$dateToString", bson.D{{"format", "%Y"}}}}}},
+			err: &mongo.CommandError{
+				Code:    18628,
+				Name:    "Location18628",
+				Message: "Missing 'date' parameter to $dateToString",
+			},
+		},
+		"UnknownTimezone": {
+			project: bson.D{{"v", bson.D{{"$dateToParts", bson.D{{"date", "$d"}, {"timezone", "Mars/Olympus"}}}}}},
+			err: &mongo.CommandError{
+				Code:    40485,
+				Name:    "Location40485",
+				Message: `unrecognized time zone identifier: "Mars/Olympus"`,
+			},
+		},
+		"DateFromStringError": {
+			project: bson.D{{"v", bson.D{{"$dateFromString", bson.D{{"dateString", "2021-13-01"}}}}}},
+			err: &mongo.CommandError{
+				Code:    241,
+				Name:    "ConversionFailure",
+				Message: "Error parsing date string '2021-13-01'; month is out of range",
+			},
+		},
+		"DateFromPartsMixed": {
+			project: bson.D{{"v", bson.D{{"$dateFromParts", bson.D{{"year", int32(2021)}, {"isoWeek", int32(1)}}}}}},
+			err: &mongo.CommandError{
+				Code:    40489,
+				Name:    "Location40489",
+				Message: "$dateFromParts does not allow mixing natural dates with ISO dates",
+			},
+		},
+		"DateFromPartsYearRange": {
+			project: bson.D{{"v", bson.D{{"$dateFromParts", bson.D{{"year", int32(10000)}}}}}},
+			err: &mongo.CommandError{
+				Code:    40523,
+				Name:    "Location40523",
+				Message: "'year' must evaluate to an integer in the range 1 to 9999, found 10000",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			project := append(bson.D{{"_id", 0}}, tc.project...)
+
+			cursor, err := collection.Aggregate(ctx, bson.A{bson.D{{"$project", project}}})
+			if tc.err != nil {
+				AssertEqualCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			require.Len(t, res, 1)
+			assert.Equal(t, tc.expected, res[0])
+		})
+	}
+}
+
+func TestAggregateConditionalOperators(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"v", int32(42)}},
+		bson.D{{"_id", int32(2)}, {"v", int32(0)}},
+		bson.D{{"_id", int32(3)}, {"v", ""}},
+		bson.D{{"_id", int32(4)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		project  bson.D
+		expected []bson.D
+		err      *mongo.CommandError
+	}{
+		"Cond": {
+			project: bson.D{
+				{"array", bson.D{{"$cond", bson.A{"$v", "truthy", "falsy"}}}},
+				{"doc", bson.D{{"$cond", bson.D{{"if", "$v"}, {"then", "$v"}, {"else", "$_id"}}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"array", "truthy"}, {"doc", int32(42)}},
+				{{"_id", int32(2)}, {"array", "falsy"}, {"doc", int32(2)}},
+				{{"_id", int32(3)}, {"array", "truthy"}, {"doc", ""}},
+				{{"_id", int32(4)}, {"array", "falsy"}, {"doc", int32(4)}},
+			},
+		},
+		"Switch": {
+			project: bson.D{
+				{"s", bson.D{{"$switch", bson.D{
+					{"branches", bson.A{
+						bson.D{{"case", "$v"}, {"then", bson.D{{"$type", "$v"}}}},
+						bson.D{{"case", bson.D{{"$cond", bson.A{"$v", false, "$_id"}}}}, {"then", "falsy"}},
+					}},
+					{"default", "default"},
+				}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"s", "int"}},
+				{{"_id", int32(2)}, {"s", "falsy"}},
+				{{"_id", int32(3)}, {"s", "string"}},
+				{{"_id", int32(4)}, {"s", "falsy"}},
+			},
+		},
+		"CondTooFewArgs": {
+			project: bson.D{{"c", bson.D{{"$cond", bson.A{"$v", 1}}}}},
+			err: &mongo.CommandError{
+				Code:    16020,
+				Name:    "Location16020",
+				Message: "Invalid $project :: caused by :: Expression $cond takes exactly 3 arguments. 2 were passed in.",
+			},
+		},
+		"CondMissingIf": {
+			project: bson.D{{"c", bson.D{{"$cond", bson.D{{"then", 1}, {"else", 2}}}}}},
+			err: &mongo.CommandError{
+				Code:    17080,
+				Name:    "Location17080",
+				Message: "Missing 'if' parameter to $cond",
+			},
+		},
+		"CondUnknownArg": {
+			project: bson.D{{"c", bson.D{{"$cond", bson.D{{"if", true}, {"foo", 1}}}}}},
+			err: &mongo.CommandError{
+				Code:    17083,
+				Name:    "Location17083",
+				Message: "Unrecognized parameter to $cond: foo",
+			},
+		},
+		"SwitchNoMatchingBranch": {
+			project: bson.D{{"s", bson.D{{"$switch", bson.D{
+				{"branches", bson.A{bson.D{{"case", "$v"}, {"then", 1}}}},
+			}}}}},
+			err: &mongo.CommandError{
+				Code:    40066,
+				Name:    "Location40066",
+				Message: "$switch could not find a matching branch for an input, and no default was specified.",
+			},
+		},
+		"SwitchNotObject": {
+			project: bson.D{{"s", bson.D{{"$switch", "foo"}}}},
+			err: &mongo.CommandError{
+				Code:    40060,
+				Name:    "Location40060",
+				Message: "$switch requires an object as an argument, found: string",
+			},
+		},
+		"SwitchBranchesNotArray": {
+			project: bson.D{{"s", bson.D{{"$switch", bson.D{{"branches", "foo"}}}}}},
+			err: &mongo.CommandError{
+				Code:    40061,
+				Name:    "Location40061",
+				Message: "$switch expected an array for 'branches', found: string",
+			},
+		},
+		"SwitchBranchNotObject": {
+			project: bson.D{{"s", bson.D{{"$switch", bson.D{{"branches", bson.A{"foo"}}}}}}},
+			err: &mongo.CommandError{
+				Code:    40062,
+				Name:    "Location40062",
+				Message: "$switch expected each branch to be an object, found: string",
+			},
+		},
+		"SwitchBranchUnknownArg": {
+			project: bson.D{{"s", bson.D{{"$switch", bson.D{
+				{"branches", bson.A{bson.D{{"case", true}, {"foo", 1}}}},
+			}}}}},
+			err: &mongo.CommandError{
+				Code:    40063,
+				Name:    "Location40063",
+				Message: "$switch found an unknown argument to a branch: foo",
+			},
+		},
+		"SwitchBranchMissingCase": {
+			project: bson.D{{"s", bson.D{{"$switch", bson.D{{"branches", bson.A{bson.D{{"then", 1}}}}}}}}},
+			err: &mongo.CommandError{
+				Code:    40064,
+				Name:    "Location40064",
+				Message: "$switch requires each branch have a 'case' expression",
+			},
+		},
+		"SwitchNoBranches": {
+			project: bson.D{{"s", bson.D{{"$switch", bson.D{{"default", 1}}}}}},
+			err: &mongo.CommandError{
+				Code:    40068,
+				Name:    "Location40068",
+				Message: "$switch requires at least one branch.",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pipeline := bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$project", tc.project}},
+			}
+
+			cursor, err := collection.Aggregate(ctx, pipeline)
+			if tc.err != nil {
+				AssertEqualCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestAggregateHintNatural(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{"v", 1}}})
+	require.NoError(t, err)
+
+	// _id values are not in the insertion order
+	docs := []bson.D{
+		{{"_id", int32(2)}, {"v", int32(1)}},
+		{{"_id", int32(3)}, {"v", int32(2)}},
+		{{"_id", int32(1)}, {"v", int32(3)}},
+	}
+
+	_, err = collection.InsertMany(ctx, []any{docs[0], docs[1], docs[2]})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		hint     any
+		pipeline bson.A
+
+		expected []bson.D
+		err      *mongo.CommandError
+	}{
+		"Asc": {
+			hint:     bson.D{{"$natural", int32(1)}},
+			pipeline: bson.A{},
+			expected: []bson.D{docs[0], docs[1], docs[2]},
+		},
+		"Desc": {
+			hint:     bson.D{{"$natural", int32(-1)}},
+			pipeline: bson.A{},
+			expected: []bson.D{docs[2], docs[1], docs[0]},
+		},
+		"DescMatchLimit": {
+			hint: bson.D{{"$natural", int32(-1)}},
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$lt", int32(3)}}}}}},
+				bson.D{{"$limit", 1}},
+			},
+			expected: []bson.D{docs[1]},
+		},
+		"DescSort": {
+			hint:     bson.D{{"$natural", int32(-1)}},
+			pipeline: bson.A{bson.D{{"$sort", bson.D{{"_id", 1}}}}},
+			expected: []bson.D{docs[2], docs[0], docs[1]},
+		},
+		"Index": {
+			hint:     "v_1",
+			pipeline: bson.A{bson.D{{"$sort", bson.D{{"v", -1}}}}},
+			expected: []bson.D{docs[2], docs[1], docs[0]},
+		},
+		"IndexNotFound": {
+			hint:     "foo_1",
+			pipeline: bson.A{},
+			err: &mongo.CommandError{
+				Code: 2,
+				Name: "BadValue",
+				Message: "error processing query: planner returned error :: caused by :: " +
+					"hint provided does not correspond to an existing index",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline, options.Aggregate().SetHint(tc.hint))
+			if tc.err != nil {
+				AssertMatchesCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, FetchAll(t, ctx, cursor))
+		})
+	}
+}
+
+func TestAggregateStringOperators(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"v", "  héllo, wörld  "}},
+		bson.D{{"_id", int32(2)}, {"v", "∂ab∂ab"}},
+		bson.D{{"_id", int32(3)}, {"v", nil}},
+		bson.D{{"_id", int32(4)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		project  bson.D
+		expected []bson.D
+		err      *mongo.CommandError
+	}{
+		"Trim": {
+			project: bson.D{
+				{"trim", bson.D{{"$trim", bson.D{{"input", "$v"}}}}},
+				{"ltrim", bson.D{{"$ltrim", bson.D{{"input", "$v"}, {"chars", " h∂"}}}}},
+				{"rtrim", bson.D{{"$rtrim", bson.D{{"input", "$v"}, {"chars", "b d"}}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"trim", "héllo, wörld"}, {"ltrim", "éllo, wörld  "}, {"rtrim", "  héllo, wörl"}},
+				{{"_id", int32(2)}, {"trim", "∂ab∂ab"}, {"ltrim", "ab∂ab"}, {"rtrim", "∂ab∂a"}},
+				{{"_id", int32(3)}, {"trim", nil}, {"ltrim", nil}, {"rtrim", nil}},
+				{{"_id", int32(4)}, {"trim", nil}, {"ltrim", nil}, {"rtrim", nil}},
+			},
+		},
+		"Split": {
+			project: bson.D{{"s", bson.D{{"$split", bson.A{"$v", "∂"}}}}},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"s", bson.A{"  héllo, wörld  "}}},
+				{{"_id", int32(2)}, {"s", bson.A{"", "ab", "ab"}}},
+				{{"_id", int32(3)}, {"s", nil}},
+				{{"_id", int32(4)}, {"s", nil}},
+			},
+		},
+		"Replace": {
+			project: bson.D{
+				{"one", bson.D{{"$replaceOne", bson.D{{"input", "$v"}, {"find", "ab"}, {"replacement", "X"}}}}},
+				{"all", bson.D{{"$replaceAll", bson.D{{"input", "$v"}, {"find", "ab"}, {"replacement", "X"}}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"one", "  héllo, wörld  "}, {"all", "  héllo, wörld  "}},
+				{{"_id", int32(2)}, {"one", "∂X∂ab"}, {"all", "∂X∂X"}},
+				{{"_id", int32(3)}, {"one", nil}, {"all", nil}},
+				{{"_id", int32(4)}, {"one", nil}, {"all", nil}},
+			},
+		},
+		"IndexOfCP": {
+			project: bson.D{
+				{"i", bson.D{{"$indexOfCP", bson.A{"$v", "ab"}}}},
+				{"start", bson.D{{"$indexOfCP", bson.A{"$v", "ab", 2}}}},
+				{"end", bson.D{{"$indexOfCP", bson.A{"$v", "ab", 2, 5}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"i", int32(-1)}, {"start", int32(-1)}, {"end", int32(-1)}},
+				{{"_id", int32(2)}, {"i", int32(1)}, {"start", int32(4)}, {"end", int32(-1)}},
+				{{"_id", int32(3)}, {"i", nil}, {"start", nil}, {"end", nil}},
+				{{"_id", int32(4)}, {"i", nil}, {"start", nil}, {"end", nil}},
+			},
+		},
+		"Regex": {
+			project: bson.D{
+				{"match", bson.D{{"$regexMatch", bson.D{{"input", "$v"}, {"regex", "WÖR"}, {"options", "i"}}}}},
+				{"find", bson.D{{"$regexFind", bson.D{{"input", "$v"}, {"regex", primitive.Regex{Pattern: "(a)(x)?b$"}}}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"match", true}, {"find", nil}},
+				{
+					{"_id", int32(2)},
+					{"match", false},
+					{"find", bson.D{{"match", "ab"}, {"idx", int32(4)}, {"captures", bson.A{"a", nil}}}},
+				},
+				{{"_id", int32(3)}, {"match", false}, {"find", nil}},
+				{{"_id", int32(4)}, {"match", false}, {"find", nil}},
+			},
+		},
+		"SplitEmptyDelimiter": {
+			project: bson.D{{"s", bson.D{{"$split", bson.A{"$v", ""}}}}},
+			err: &mongo.CommandError{
+				Code:    40087,
+				Name:    "Location40087",
+				Message: "$split requires a non-empty separator",
+			},
+		},
+		"SplitTooFewArgs": {
+			project: bson.D{{"s", bson.D{{"$split", bson.A{"$v"}}}}},
+			err: &mongo.CommandError{
+				Code:    16020,
+				Name:    "Location16020",
+				Message: "Invalid $project :: caused by :: Expression $split takes exactly 2 arguments. 1 were passed in.",
+			},
+		},
+		"TrimNotObject": {
+			project: bson.D{{"t", bson.D{{"$trim", "$v"}}}},
+			err: &mongo.CommandError{
+				Code:    50696,
+				Name:    "Location50696",
+				Message: "$trim only supports an object as its argument",
+			},
+		},
+		"TrimMissingInput": {
+			project: bson.D{{"t", bson.D{{"$trim", bson.D{{"chars", " "}}}}}},
+			err: &mongo.CommandError{
+				Code:    50695,
+				Name:    "Location50695",
+				Message: "$trim requires an 'input' field",
+			},
+		},
+		"ReplaceFindNotString": {
+			project: bson.D{{"r", bson.D{{"$replaceAll", bson.D{{"input", "$v"}, {"find", 1}, {"replacement", ""}}}}}},
+			err: &mongo.CommandError{
+				Code:    51745,
+				Name:    "Location51745",
+				Message: "$replaceAll requires that 'find' be a string, found: 1",
+			},
+		},
+		"IndexOfCPNegativeStart": {
+			project: bson.D{{"i", bson.D{{"$indexOfCP", bson.A{"$v", "a", -1}}}}},
+			err: &mongo.CommandError{
+				Code:    40097,
+				Name:    "Location40097",
+				Message: "$indexOfCP requires a nonnegative start index, found: -1",
+			},
+		},
+		"RegexUnknownArg": {
+			project: bson.D{{"r", bson.D{{"$regexMatch", bson.D{{"input", "$v"}, {"regex", "a"}, {"foo", 1}}}}}},
+			err: &mongo.CommandError{
+				Code:    31024,
+				Name:    "Location31024",
+				Message: "$regexMatch found an unknown argument: foo",
+			},
+		},
+		"RegexOptionsConflict": {
+			project: bson.D{{"r", bson.D{{"$regexFind", bson.D{
+				{"input", "$v"},
+				{"regex", primitive.Regex{Pattern: "a", Options: "i"}},
+				{"options", "m"},
+			}}}}},
+			err: &mongo.CommandError{
+				Code:    51107,
+				Name:    "Location51107",
+				Message: "$regexFind found regex option(s) specified in both 'regex' and 'option' fields",
+			},
+		},
+		"RegexBadOption": {
+			project: bson.D{{"r", bson.D{{"$regexMatch", bson.D{{"input", "$v"}, {"regex", "a"}, {"options", "q"}}}}}},
+			err: &mongo.CommandError{
+				Code:    51108,
+				Name:    "Location51108",
+				Message: "$regexMatch invalid flag in regex options: q",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pipeline := bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$project", tc.project}},
+			}
+
+			cursor, err := collection.Aggregate(ctx, pipeline)
+			if tc.err != nil {
+				AssertEqualCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestAggregateArithmeticOperators(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"v", int32(7)}},
+		bson.D{{"_id", int32(2)}, {"v", int64(math.MaxInt64)}},
+		bson.D{{"_id", int32(3)}, {"v", -2.5}},
+		bson.D{{"_id", int32(4)}, {"v", must.NotFail(primitive.ParseDecimal128("1.2345"))}},
+		bson.D{{"_id", int32(5)}, {"v", nil}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		project  bson.D
+		expected []bson.D
+		err      *mongo.CommandError
+	}{
+		"MultiplyDivide": {
+			project: bson.D{
+				{"m", bson.D{{"$multiply", bson.A{"$v", int32(2)}}}},
+				{"d", bson.D{{"$divide", bson.A{"$v", int32(2)}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"m", int32(14)}, {"d", 3.5}},
+				{{"_id", int32(2)}, {"m", float64(math.MaxInt64) * 2}, {"d", float64(math.MaxInt64) / 2}},
+				{{"_id", int32(3)}, {"m", -5.0}, {"d", -1.25}},
+				{
+					{"_id", int32(4)},
+					{"m", must.NotFail(primitive.ParseDecimal128("2.4690"))},
+					{"d", must.NotFail(primitive.ParseDecimal128("0.61725"))},
+				},
+				{{"_id", int32(5)}, {"m", nil}, {"d", nil}},
+			},
+		},
+		"Mod": {
+			project: bson.D{{"m", bson.D{{"$mod", bson.A{"$v", int32(2)}}}}},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"m", int32(1)}},
+				{{"_id", int32(2)}, {"m", int64(1)}},
+				{{"_id", int32(3)}, {"m", -0.5}},
+				{{"_id", int32(4)}, {"m", must.NotFail(primitive.ParseDecimal128("1.2345"))}},
+				{{"_id", int32(5)}, {"m", nil}},
+			},
+		},
+		"Pow": {
+			project: bson.D{{"p", bson.D{{"$pow", bson.A{"$v", int32(2)}}}}},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"p", int32(49)}},
+				{{"_id", int32(2)}, {"p", math.Pow(math.MaxInt64, 2)}},
+				{{"_id", int32(3)}, {"p", 6.25}},
+				{{"_id", int32(4)}, {"p", must.NotFail(primitive.ParseDecimal128("1.52399025000000"))}},
+				{{"_id", int32(5)}, {"p", nil}},
+			},
+		},
+		"RoundTrunc": {
+			project: bson.D{
+				{"r", bson.D{{"$round", bson.A{"$v", int32(2)}}}},
+				{"t", bson.D{{"$trunc", bson.A{"$v", int32(-1)}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"r", int32(7)}, {"t", int32(0)}},
+				{{"_id", int32(2)}, {"r", int64(math.MaxInt64)}, {"t", int64(9223372036854775800)}},
+				{{"_id", int32(3)}, {"r", -2.5}, {"t", math.Copysign(0, -1)}},
+				{
+					{"_id", int32(4)},
+					{"r", must.NotFail(primitive.ParseDecimal128("1.23"))},
+					{"t", must.NotFail(primitive.ParseDecimal128("0E+1"))},
+				},
+				{{"_id", int32(5)}, {"r", nil}, {"t", nil}},
+			},
+		},
+		"RoundHalfEven": {
+			project: bson.D{
+				{"r", bson.D{{"$round", bson.A{2.5}}}},
+				{"n", bson.D{{"$round", bson.A{-3.5}}}},
+				{"i", bson.D{{"$round", bson.A{int32(25), int32(-1)}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"r", 2.0}, {"n", -4.0}, {"i", int32(20)}},
+				{{"_id", int32(2)}, {"r", 2.0}, {"n", -4.0}, {"i", int32(20)}},
+				{{"_id", int32(3)}, {"r", 2.0}, {"n", -4.0}, {"i", int32(20)}},
+				{{"_id", int32(4)}, {"r", 2.0}, {"n", -4.0}, {"i", int32(20)}},
+				{{"_id", int32(5)}, {"r", 2.0}, {"n", -4.0}, {"i", int32(20)}},
+			},
+		},
+		"SqrtLog": {
+			project: bson.D{
+				{"s", bson.D{{"$sqrt", int32(16)}}},
+				{"l", bson.D{{"$log", bson.A{int32(8), int32(2)}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"s", 4.0}, {"l", 3.0}},
+				{{"_id", int32(2)}, {"s", 4.0}, {"l", 3.0}},
+				{{"_id", int32(3)}, {"s", 4.0}, {"l", 3.0}},
+				{{"_id", int32(4)}, {"s", 4.0}, {"l", 3.0}},
+				{{"_id", int32(5)}, {"s", 4.0}, {"l", 3.0}},
+			},
+		},
+		"MultiplyNotNumeric": {
+			project: bson.D{{"m", bson.D{{"$multiply", bson.A{"$v", "foo"}}}}},
+			err: &mongo.CommandError{
+				Code:    16555,
+				Name:    "Location16555",
+				Message: "$multiply only supports numeric types, not string",
+			},
+		},
+		"DivideByZero": {
+			project: bson.D{{"d", bson.D{{"$divide", bson.A{"$v", int32(0)}}}}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "can't $divide by zero",
+			},
+		},
+		"DivideTooFewArgs": {
+			project: bson.D{{"d", bson.D{{"$divide", bson.A{"$v"}}}}},
+			err: &mongo.CommandError{
+				Code:    16020,
+				Name:    "Location16020",
+				Message: "Invalid $project :: caused by :: Expression $divide takes exactly 2 arguments. 1 were passed in.",
+			},
+		},
+		"ModByZero": {
+			project: bson.D{{"m", bson.D{{"$mod", bson.A{"$v", 0.0}}}}},
+			err: &mongo.CommandError{
+				Code:    16610,
+				Name:    "Location16610",
+				Message: "can't $mod by zero",
+			},
+		},
+		"PowZeroNegativeExponent": {
+			project: bson.D{{"p", bson.D{{"$pow", bson.A{int32(0), int32(-1)}}}}},
+			err: &mongo.CommandError{
+				Code:    28764,
+				Name:    "Location28764",
+				Message: "$pow cannot take a base of 0 and a negative exponent",
+			},
+		},
+		"SqrtNegative": {
+			project: bson.D{{"s", bson.D{{"$sqrt", "$v"}}}},
+			err: &mongo.CommandError{
+				Code:    28714,
+				Name:    "Location28714",
+				Message: "$sqrt's argument must be greater than or equal to 0",
+			},
+		},
+		"LogInvalidBase": {
+			project: bson.D{{"l", bson.D{{"$log", bson.A{int32(2), int32(1)}}}}},
+			err: &mongo.CommandError{
+				Code:    28759,
+				Name:    "Location28759",
+				Message: "$log's base must be a positive number not equal to 1, but is 1",
+			},
+		},
+		"RoundPlaceOutOfRange": {
+			project: bson.D{{"r", bson.D{{"$round", bson.A{"$v", int32(101)}}}}},
+			err: &mongo.CommandError{
+				Code:    51083,
+				Name:    "Location51083",
+				Message: "cannot apply $round with precision value 101 value must be in [-20, 100]",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pipeline := bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$project", tc.project}},
+			}
+
+			cursor, err := collection.Aggregate(ctx, pipeline)
+			if tc.err != nil {
+				AssertEqualCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestAggregateArrayOperators(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{
+			{"_id", int32(1)},
+			{"a", bson.A{int32(1), int32(2), int32(3)}},
+			{"b", bson.A{"x", "y"}},
+			{"kv", bson.A{bson.D{{"k", "a"}, {"v", int32(1)}}, bson.D{{"k", "b"}, {"v", int32(2)}}}},
+		},
+		bson.D{
+			{"_id", int32(2)},
+			{"a", bson.A{}},
+			{"b", nil},
+			{"kv", bson.A{bson.D{{"k", "c"}, {"v", true}}}},
+		},
+		bson.D{{"_id", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		project  bson.D
+		expected []bson.D
+		err      *mongo.CommandError
+	}{
+		"Map": {
+			project: bson.D{
+				{"m", bson.D{{"$map", bson.D{
+					{"input", "$a"},
+					{"as", "n"},
+					{"in", bson.D{{"$multiply", bson.A{"$$n", int32(10)}}}},
+				}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"m", bson.A{int32(10), int32(20), int32(30)}}},
+				{{"_id", int32(2)}, {"m", bson.A{}}},
+				{{"_id", int32(3)}, {"m", nil}},
+			},
+		},
+		"MapThis": {
+			project: bson.D{
+				{"m", bson.D{{"$map", bson.D{
+					{"input", "$b"},
+					{"in", bson.D{{"v", "$$this"}, {"id", "$_id"}, {"missing", "$$this.foo"}}},
+				}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"m", bson.A{bson.D{{"v", "x"}, {"id", int32(1)}}, bson.D{{"v", "y"}, {"id", int32(1)}}}}},
+				{{"_id", int32(2)}, {"m", nil}},
+				{{"_id", int32(3)}, {"m", nil}},
+			},
+		},
+		"MapNested": {
+			project: bson.D{
+				{"m", bson.D{{"$map", bson.D{
+					{"input", "$a"},
+					{"as", "x"},
+					{"in", bson.D{{"$map", bson.D{
+						{"input", "$a"},
+						{"in", bson.D{{"$multiply", bson.A{"$$x", "$$this"}}}},
+					}}}},
+				}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"m", bson.A{
+					bson.A{int32(1), int32(2), int32(3)},
+					bson.A{int32(2), int32(4), int32(6)},
+					bson.A{int32(3), int32(6), int32(9)},
+				}}},
+				{{"_id", int32(2)}, {"m", bson.A{}}},
+				{{"_id", int32(3)}, {"m", nil}},
+			},
+		},
+		"Filter": {
+			project: bson.D{
+				{"odd", bson.D{{"$filter", bson.D{
+					{"input", "$a"},
+					{"cond", bson.D{{"$mod", bson.A{"$$this", int32(2)}}}},
+				}}}},
+				{"limit", bson.D{{"$filter", bson.D{
+					{"input", "$a"},
+					{"as", "n"},
+					{"cond", true},
+					{"limit", int32(2)},
+				}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"odd", bson.A{int32(1), int32(3)}}, {"limit", bson.A{int32(1), int32(2)}}},
+				{{"_id", int32(2)}, {"odd", bson.A{}}, {"limit", bson.A{}}},
+				{{"_id", int32(3)}, {"odd", nil}, {"limit", nil}},
+			},
+		},
+		"Reduce": {
+			project: bson.D{
+				{"r", bson.D{{"$reduce", bson.D{
+					{"input", "$a"},
+					{"initialValue", int32(1)},
+					{"in", bson.D{{"$multiply", bson.A{"$$value", "$$this", int32(2)}}}},
+				}}}},
+				{"count", bson.D{{"$reduce", bson.D{
+					{"input", "$b"},
+					{"initialValue", int32(0)},
+					{"in", bson.D{{"$sum", bson.A{"$$value", int32(1)}}}},
+				}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"r", int32(48)}, {"count", int32(2)}},
+				{{"_id", int32(2)}, {"r", int32(1)}, {"count", nil}},
+				{{"_id", int32(3)}, {"r", nil}, {"count", nil}},
+			},
+		},
+		"Zip": {
+			project: bson.D{
+				{"z", bson.D{{"$zip", bson.D{{"inputs", bson.A{"$a", "$b"}}}}}},
+				{"longest", bson.D{{"$zip", bson.D{
+					{"inputs", bson.A{"$a", bson.A{"x"}}},
+					{"useLongestLength", true},
+					{"defaults", bson.A{int32(0), "z"}},
+				}}}},
+			},
+			expected: []bson.D{
+				{
+					{"_id", int32(1)},
+					{"z", bson.A{bson.A{int32(1), "x"}, bson.A{int32(2), "y"}}},
+					{"longest", bson.A{bson.A{int32(1), "x"}, bson.A{int32(2), "z"}, bson.A{int32(3), "z"}}},
+				},
+				{{"_id", int32(2)}, {"z", nil}, {"longest", bson.A{bson.A{int32(0), "x"}}}},
+				{{"_id", int32(3)}, {"z", nil}, {"longest", nil}},
+			},
+		},
+		"ArrayToObject": {
+			project: bson.D{{"o", bson.D{{"$arrayToObject", "$kv"}}}},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"o", bson.D{{"a", int32(1)}, {"b", int32(2)}}}},
+				{{"_id", int32(2)}, {"o", bson.D{{"c", true}}}},
+				{{"_id", int32(3)}, {"o", nil}},
+			},
+		},
+		"ArrayToObjectPairs": {
+			project: bson.D{{"o", bson.D{{"$arrayToObject", bson.D{{"$literal", bson.A{
+				bson.A{"a", int32(1)},
+				bson.A{"b", int32(2)},
+				bson.A{"a", int32(3)},
+			}}}}}}},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"o", bson.D{{"a", int32(3)}, {"b", int32(2)}}}},
+				{{"_id", int32(2)}, {"o", bson.D{{"a", int32(3)}, {"b", int32(2)}}}},
+				{{"_id", int32(3)}, {"o", bson.D{{"a", int32(3)}, {"b", int32(2)}}}},
+			},
+		},
+		"Literal": {
+			project: bson.D{
+				{"s", bson.D{{"$literal", "$a"}}},
+				{"arr", bson.D{{"$literal", bson.A{int32(1)}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"s", "$a"}, {"arr", bson.A{int32(1)}}},
+				{{"_id", int32(2)}, {"s", "$a"}, {"arr", bson.A{int32(1)}}},
+				{{"_id", int32(3)}, {"s", "$a"}, {"arr", bson.A{int32(1)}}},
+			},
+		},
+		"Let": {
+			project: bson.D{
+				{"l", bson.D{{"$let", bson.D{
+					{"vars", bson.D{{"x", "$_id"}, {"y", int32(10)}}},
+					{"in", bson.D{{"$multiply", bson.A{"$$x", "$$y"}}}},
+				}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"l", int32(10)}},
+				{{"_id", int32(2)}, {"l", int32(20)}},
+				{{"_id", int32(3)}, {"l", int32(30)}},
+			},
+		},
+		"LetNested": {
+			project: bson.D{
+				{"l", bson.D{{"$let", bson.D{
+					{"vars", bson.D{{"x", "$_id"}}},
+					{"in", bson.D{{"$let", bson.D{
+						{"vars", bson.D{{"x", int32(100)}, {"y", "$$x"}}},
+						{"in", bson.A{"$$x", "$$y"}},
+					}}}},
+				}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"l", bson.A{int32(100), int32(1)}}},
+				{{"_id", int32(2)}, {"l", bson.A{int32(100), int32(2)}}},
+				{{"_id", int32(3)}, {"l", bson.A{int32(100), int32(3)}}},
+			},
+		},
+		"LetMissing": {
+			project: bson.D{
+				{"l", bson.D{{"$let", bson.D{
+					{"vars", bson.D{{"m", "$missing"}}},
+					{"in", bson.D{{"v", "$$m"}, {"id", "$_id"}}},
+				}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"l", bson.D{{"id", int32(1)}}}},
+				{{"_id", int32(2)}, {"l", bson.D{{"id", int32(2)}}}},
+				{{"_id", int32(3)}, {"l", bson.D{{"id", int32(3)}}}},
+			},
+		},
+		"LetInMap": {
+			project: bson.D{
+				{"m", bson.D{{"$map", bson.D{
+					{"input", "$a"},
+					{"as", "n"},
+					{"in", bson.D{{"$let", bson.D{
+						{"vars", bson.D{{"d", bson.D{{"$multiply", bson.A{"$$n", int32(2)}}}}}},
+						{"in", bson.A{"$$n", "$$d"}},
+					}}}},
+				}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"m", bson.A{
+					bson.A{int32(1), int32(2)},
+					bson.A{int32(2), int32(4)},
+					bson.A{int32(3), int32(6)},
+				}}},
+				{{"_id", int32(2)}, {"m", bson.A{}}},
+				{{"_id", int32(3)}, {"m", nil}},
+			},
+		},
+		"MapInLet": {
+			project: bson.D{
+				{"m", bson.D{{"$let", bson.D{
+					{"vars", bson.D{{"this", int32(10)}, {"k", int32(10)}}},
+					{"in", bson.D{{"$map", bson.D{
+						{"input", "$a"},
+						{"in", bson.D{{"$multiply", bson.A{"$$this", "$$k"}}}},
+					}}}},
+				}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"m", bson.A{int32(10), int32(20), int32(30)}}},
+				{{"_id", int32(2)}, {"m", bson.A{}}},
+				{{"_id", int32(3)}, {"m", nil}},
+			},
+		},
+		"LetMissingIn": {
+			project: bson.D{{"l", bson.D{{"$let", bson.D{{"vars", bson.D{}}}}}}},
+			err: &mongo.CommandError{
+				Code:    16877,
+				Name:    "Location16877",
+				Message: "Missing 'in' parameter to $let",
+			},
+		},
+		"LetVarsNotObject": {
+			project: bson.D{{"l", bson.D{{"$let", bson.D{{"vars", int32(1)}, {"in", int32(1)}}}}}},
+			err: &mongo.CommandError{
+				Code:    10065,
+				Name:    "Location10065",
+				Message: "invalid parameter: expected an object (vars)",
+			},
+		},
+		"LetInvalidVariableName": {
+			project: bson.D{{"l", bson.D{{"$let", bson.D{{"vars", bson.D{{"Foo", int32(1)}}}, {"in", "$$Foo"}}}}}},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "'Foo' starts with an invalid character for a user variable name",
+			},
+		},
+		"MapMissingIn": {
+			project: bson.D{{"m", bson.D{{"$map", bson.D{{"input", "$a"}}}}}},
+			err: &mongo.CommandError{
+				Code:    16882,
+				Name:    "Location16882",
+				Message: "Missing 'in' parameter to $map",
+			},
+		},
+		"MapInvalidAs": {
+			project: bson.D{{"m", bson.D{{"$map", bson.D{{"input", "$a"}, {"as", "Foo"}, {"in", "$$Foo"}}}}}},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "'Foo' starts with an invalid character for a user variable name",
+			},
+		},
+		"FilterInputNotArray": {
+			project: bson.D{{"f", bson.D{{"$filter", bson.D{{"input", "$_id"}, {"cond", true}}}}}},
+			err: &mongo.CommandError{
+				Code:    28651,
+				Name:    "Location28651",
+				Message: "input to $filter must be an array not int",
+			},
+		},
+		"FilterZeroLimit": {
+			project: bson.D{{"f", bson.D{{"$filter", bson.D{{"input", "$a"}, {"cond", true}, {"limit", int32(0)}}}}}},
+			err: &mongo.CommandError{
+				Code:    327392,
+				Name:    "Location327392",
+				Message: "$filter: limit must be greater than 0: 0",
+			},
+		},
+		"ReduceMissingInitialValue": {
+			project: bson.D{{"r", bson.D{{"$reduce", bson.D{{"input", "$a"}, {"in", "$$this"}}}}}},
+			err: &mongo.CommandError{
+				Code:    40078,
+				Name:    "Location40078",
+				Message: "$reduce requires 'initialValue' to be specified",
+			},
+		},
+		"ZipDefaultsWithoutLongest": {
+			project: bson.D{{"z", bson.D{{"$zip", bson.D{{"inputs", bson.A{"$a"}}, {"defaults", bson.A{int32(0)}}}}}}},
+			err: &mongo.CommandError{
+				Code:    34466,
+				Name:    "Location34466",
+				Message: "cannot specify defaults unless useLongestLength is true",
+			},
+		},
+		"ArrayToObjectBadPair": {
+			project: bson.D{{"o", bson.D{{"$arrayToObject", bson.A{bson.A{bson.A{"a", int32(1), int32(2)}}}}}}},
+			err: &mongo.CommandError{
+				Code: 40395,
+				Name: "Location40395",
+				Message: "$arrayToObject requires an array of key-value pairs, where each pair is an array of size 2. " +
+					"Found array of size: 3",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pipeline := bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$project", tc.project}},
+			}
+
+			cursor, err := collection.Aggregate(ctx, pipeline)
+			if tc.err != nil {
+				AssertEqualCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestAggregateFieldOperators(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"a", bson.D{{"b", int32(1)}, {"c", "foo"}}}},
+		bson.D{{"_id", int32(2)}, {"a", nil}},
+		bson.D{{"_id", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A
+		expected []bson.D
+		err      *mongo.CommandError
+	}{
+		"Dotted": {
+			pipeline: bson.A{
+				bson.D{{"$addFields", bson.D{
+					{"d", bson.D{{"$setField", bson.D{{"field", "x.y"}, {"input", bson.D{}}, {"value", "$_id"}}}}},
+				}}},
+				bson.D{{"$project", bson.D{
+					{"d", int32(1)},
+					{"v", bson.D{{"$getField", bson.D{{"field", "x.y"}, {"input", "$d"}}}}},
+				}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"d", bson.D{{"x.y", int32(1)}}}, {"v", int32(1)}},
+				{{"_id", int32(2)}, {"d", bson.D{{"x.y", int32(2)}}}, {"v", int32(2)}},
+				{{"_id", int32(3)}, {"d", bson.D{{"x.y", int32(3)}}}, {"v", int32(3)}},
+			},
+		},
+		"Dollar": {
+			pipeline: bson.A{
+				bson.D{{"$addFields", bson.D{
+					{"d", bson.D{{"$setField", bson.D{
+						{"field", bson.D{{"$literal", "$price"}}},
+						{"input", bson.D{}},
+						{"value", "$_id"},
+					}}}},
+				}}},
+				bson.D{{"$project", bson.D{
+					{"v", bson.D{{"$getField", bson.D{{"field", bson.D{{"$literal", "$price"}}}, {"input", "$d"}}}}},
+				}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", int32(1)}},
+				{{"_id", int32(2)}, {"v", int32(2)}},
+				{{"_id", int32(3)}, {"v", int32(3)}},
+			},
+		},
+		"GetFieldShorthand": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{{"v", bson.D{{"$getField", "_id"}}}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", int32(1)}},
+				{{"_id", int32(2)}, {"v", int32(2)}},
+				{{"_id", int32(3)}, {"v", int32(3)}},
+			},
+		},
+		"SetFieldRemove": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"a", bson.D{{"$setField", bson.D{{"field", "b"}, {"input", "$a"}, {"value", "$$REMOVE"}}}}},
+				}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"a", bson.D{{"c", "foo"}}}},
+				{{"_id", int32(2)}, {"a", nil}},
+				{{"_id", int32(3)}, {"a", nil}},
+			},
+		},
+		"UnsetField": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"a", bson.D{{"$unsetField", bson.D{{"field", "c"}, {"input", "$a"}}}}},
+				}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"a", bson.D{{"b", int32(1)}}}},
+				{{"_id", int32(2)}, {"a", nil}},
+				{{"_id", int32(3)}, {"a", nil}},
+			},
+		},
+		"SetFieldReplace": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{
+					{"a", bson.D{{"$setField", bson.D{{"field", "b"}, {"input", "$$ROOT"}, {"value", true}}}}},
+				}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"a", bson.D{{"_id", int32(1)}, {"a", bson.D{{"b", int32(1)}, {"c", "foo"}}}, {"b", true}}}},
+				{{"_id", int32(2)}, {"a", bson.D{{"_id", int32(2)}, {"a", nil}, {"b", true}}}},
+				{{"_id", int32(3)}, {"a", bson.D{{"_id", int32(3)}, {"b", true}}}},
+			},
+		},
+		"GetFieldNonConstant": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{{"v", bson.D{{"$getField", bson.D{{"field", "$a"}, {"input", "$$ROOT"}}}}}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    5654601,
+				Name:    "Location5654601",
+				Message: "$getField requires 'field' to evaluate to a constant, but got a non-constant argument",
+			},
+		},
+		"GetFieldNotString": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{{"v", bson.D{{"$getField", bson.D{{"field", int32(1)}, {"input", "$a"}}}}}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    5654602,
+				Name:    "Location5654602",
+				Message: "$getField requires 'field' to evaluate to type String, but got int",
+			},
+		},
+		"GetFieldInputNotObject": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{{"v", bson.D{{"$getField", bson.D{{"field", "x"}, {"input", "$_id"}}}}}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    3041705,
+				Name:    "Location3041705",
+				Message: "$getField requires 'input' to evaluate to type Object, but got int",
+			},
+		},
+		"SetFieldMissingValue": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{{"v", bson.D{{"$setField", bson.D{{"field", "x"}, {"input", "$a"}}}}}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    4161104,
+				Name:    "Location4161104",
+				Message: "$setField requires 'value' to be specified",
+			},
+		},
+		"UnsetFieldUnknownArgument": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{{"v", bson.D{{"$unsetField", bson.D{
+					{"field", "x"},
+					{"input", "$a"},
+					{"value", int32(1)},
+				}}}}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    4161101,
+				Name:    "Location4161101",
+				Message: "$unsetField found an unknown argument: value",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pipeline := append(bson.A{bson.D{{"$sort", bson.D{{"_id", 1}}}}}, tc.pipeline...)
+
+			cursor, err := collection.Aggregate(ctx, pipeline)
+			if tc.err != nil {
+				AssertEqualCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestAggregateConvertOperators(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", int32(1)}})
+	require.NoError(t, err)
+
+	id := must.NotFail(primitive.ObjectIDFromHex("507f1f77bcf86cd799439011"))
+	date := time.Date(2021, 1, 2, 3, 4, 5, 6_000_000, time.UTC)
+
+	for name, tc := range map[string]struct {
+		expr     bson.D
+		expected any
+		err      *mongo.CommandError
+	}{
+		"StringToInt": {
+			expr:     bson.D{{"$toInt", "+42"}},
+			expected: int32(42),
+		},
+		"StringToIntFraction": {
+			expr: bson.D{{"$toInt", "4.2"}},
+			err: &mongo.CommandError{
+				Code:    241,
+				Name:    "ConversionFailure",
+				Message: "Failed to parse number '4.2' in $convert with no onError value: Did not consume whole string.",
+			},
+		},
+		"StringToIntEmpty": {
+			expr: bson.D{{"$toInt", ""}},
+			err: &mongo.CommandError{
+				Code:    241,
+				Name:    "ConversionFailure",
+				Message: "Failed to parse number '' in $convert with no onError value: No digits",
+			},
+		},
+		"StringToIntOverflow": {
+			expr: bson.D{{"$toInt", "2147483648"}},
+			err: &mongo.CommandError{
+				Code:    241,
+				Name:    "ConversionFailure",
+				Message: "Failed to parse number '2147483648' in $convert with no onError value: Overflow",
+			},
+		},
+		"StringToLong": {
+			expr:     bson.D{{"$toLong", "-2147483649"}},
+			expected: int64(-2147483649),
+		},
+		"StringToLongWhitespace": {
+			expr: bson.D{{"$toLong", " 1"}},
+			err: &mongo.CommandError{
+				Code:    241,
+				Name:    "ConversionFailure",
+				Message: "Failed to parse number ' 1' in $convert with no onError value: Did not consume whole string.",
+			},
+		},
+		"StringToDoubleExponent": {
+			expr:     bson.D{{"$toDouble", "1.5e3"}},
+			expected: float64(1500),
+		},
+		"StringToDoubleInfinity": {
+			expr:     bson.D{{"$toDouble", "-Infinity"}},
+			expected: math.Inf(-1),
+		},
+		"StringToDoubleHex": {
+			expr: bson.D{{"$toDouble", "0x1A"}},
+			err: &mongo.CommandError{
+				Code:    241,
+				Name:    "ConversionFailure",
+				Message: "Failed to parse number '0x1A' in $convert with no onError value: Did not consume whole string.",
+			},
+		},
+		"StringToDecimal": {
+			expr:     bson.D{{"$toDecimal", "1.50"}},
+			expected: must.NotFail(primitive.ParseDecimal128("1.50")),
+		},
+		"DoubleToDecimal": {
+			expr:     bson.D{{"$toDecimal", 2.5}},
+			expected: must.NotFail(primitive.ParseDecimal128("2.50000000000000")),
+		},
+		"DoubleToInt": {
+			expr:     bson.D{{"$toInt", -2.9}},
+			expected: int32(-2),
+		},
+		"DoubleToIntInfinity": {
+			expr: bson.D{{"$toInt", math.Inf(1)}},
+			err: &mongo.CommandError{
+				Code:    241,
+				Name:    "ConversionFailure",
+				Message: "Attempt to convert infinity value to integer type in $convert with no onError value",
+			},
+		},
+		"LongToIntOverflow": {
+			expr: bson.D{{"$toInt", int64(math.MaxInt32 + 1)}},
+			err: &mongo.CommandError{
+				Code:    241,
+				Name:    "ConversionFailure",
+				Message: "Conversion would overflow target type in $convert with no onError value: 2147483648",
+			},
+		},
+		"BoolToLong": {
+			expr:     bson.D{{"$toLong", true}},
+			expected: int64(1),
+		},
+		"ZeroToBool": {
+			expr:     bson.D{{"$toBool", 0.0}},
+			expected: false,
+		},
+		"StringToBool": {
+			expr:     bson.D{{"$toBool", "false"}},
+			expected: true,
+		},
+		"IntToString": {
+			expr:     bson.D{{"$toString", int32(42)}},
+			expected: "42",
+		},
+		"DoubleToString": {
+			expr:     bson.D{{"$toString", 2.5}},
+			expected: "2.5",
+		},
+		"DateToString": {
+			expr:     bson.D{{"$toString", date}},
+			expected: "2021-01-02T03:04:05.006Z",
+		},
+		"ObjectIDToString": {
+			expr:     bson.D{{"$toString", id}},
+			expected: "507f1f77bcf86cd799439011",
+		},
+		"StringToObjectID": {
+			expr:     bson.D{{"$toObjectId", "507f1f77bcf86cd799439011"}},
+			expected: id,
+		},
+		"StringToObjectIDLength": {
+			expr: bson.D{{"$toObjectId", "507f"}},
+			err: &mongo.CommandError{
+				Code: 241,
+				Name: "ConversionFailure",
+				Message: "Failed to parse objectId '507f' in $convert with no onError value: " +
+					"Invalid string length for parsing to OID, expected 24 but found 4",
+			},
+		},
+		"ObjectIDToDate": {
+			expr:     bson.D{{"$toDate", id}},
+			expected: primitive.NewDateTimeFromTime(time.Unix(0x507f1f77, 0)),
+		},
+		"StringToDate": {
+			expr:     bson.D{{"$toDate", "2021-01-02T03:04:05.006Z"}},
+			expected: primitive.NewDateTimeFromTime(date),
+		},
+		"LongToDate": {
+			expr:     bson.D{{"$toDate", date.UnixMilli()}},
+			expected: primitive.NewDateTimeFromTime(date),
+		},
+		"IntToDate": {
+			expr: bson.D{{"$toDate", int32(1)}},
+			err: &mongo.CommandError{
+				Code:    241,
+				Name:    "ConversionFailure",
+				Message: "Unsupported conversion from int to date in $convert with no onError value",
+			},
+		},
+		"ToNull": {
+			expr:     bson.D{{"$toInt", nil}},
+			expected: nil,
+		},
+		"ConvertNumericTo": {
+			expr:     bson.D{{"$convert", bson.D{{"input", "12"}, {"to", int32(16)}}}},
+			expected: int32(12),
+		},
+		"ConvertOnError": {
+			expr:     bson.D{{"$convert", bson.D{{"input", "abc"}, {"to", "int"}, {"onError", "bad"}}}},
+			expected: "bad",
+		},
+		"ConvertOnNull": {
+			expr:     bson.D{{"$convert", bson.D{{"input", "$missing"}, {"to", "int"}, {"onNull", int32(0)}}}},
+			expected: int32(0),
+		},
+		"ConvertNullTo": {
+			expr:     bson.D{{"$convert", bson.D{{"input", int32(1)}, {"to", nil}}}},
+			expected: nil,
+		},
+		"ConvertUnknownTo": {
+			expr: bson.D{{"$convert", bson.D{{"input", int32(1)}, {"to", "integer"}, {"onError", int32(0)}}}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "Unknown type name: integer",
+			},
+		},
+		"ConvertMissingTo": {
+			expr: bson.D{{"$convert", bson.D{{"input", int32(1)}}}},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "Missing 'to' parameter to $convert",
+			},
+		},
+		"ConvertUnknownArgument": {
+			expr: bson.D{{"$convert", bson.D{{"input", int32(1)}, {"to", "int"}, {"format", "x"}}}},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "$convert found an unknown argument: format",
+			},
+		},
+		"ToIntTooManyArgs": {
+			expr: bson.D{{"$toInt", bson.A{int32(1), int32(2)}}},
+			err: &mongo.CommandError{
+				Code:    16020,
+				Name:    "Location16020",
+				Message: "Invalid $project :: caused by :: Expression $toInt takes exactly 1 arguments. 2 were passed in.",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pipeline := bson.A{bson.D{{"$project", bson.D{{"_id", 0}, {"v", tc.expr}}}}}
+
+			cursor, err := collection.Aggregate(ctx, pipeline)
+			if tc.err != nil {
+				AssertEqualCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			require.Len(t, res, 1)
+
+			assert.Equal(t, tc.expected, res[0].Map()["v"])
+		})
+	}
+}
@@ -314,3 +314,55 @@ func TestAggregateCollStatsCommandIndexSizes(t *testing.T) {
 		}
 	}
 }
+
+func TestAggregateIndexStats(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.DocumentsStrings)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"v", -1}},
+		Options: options.Index().SetUnique(true),
+	})
+	require.NoError(t, err)
+
+	cursor, err := collection.Aggregate(ctx, bson.A{
+		bson.D{{"$indexStats", bson.D{}}},
+		bson.D{{"$sort", bson.D{{"name", 1}}}},
+	})
+	require.NoError(t, err)
+
+	res := FetchAll(t, ctx, cursor)
+	require.Len(t, res, 2)
+
+	for i, expected := range []struct {
+		name string
+		key  bson.D
+	}{
+		{name: "_id_", key: bson.D{{"_id", int32(1)}}},
+		{name: "v_-1", key: bson.D{{"v", int32(-1)}}},
+	} {
+		doc := ConvertDocument(t, res[i])
+
+		assert.Equal(t, expected.name, must.NotFail(doc.Get("name")))
+		assert.Equal(t, ConvertDocument(t, expected.key), must.NotFail(doc.Get("key")))
+		assert.NotEmpty(t, must.NotFail(doc.Get("host")))
+
+		accesses := must.NotFail(doc.Get("accesses")).(*types.Document)
+		assert.IsType(t, int64(0), must.NotFail(accesses.Get("ops")))
+		assert.True(t, accesses.Has("since"))
+
+		spec := must.NotFail(doc.Get("spec")).(*types.Document)
+		assert.Equal(t, expected.name, must.NotFail(spec.Get("name")))
+	}
+
+	_, err = collection.Aggregate(ctx, bson.A{
+		bson.D{{"$match", bson.D{}}},
+		bson.D{{"$indexStats", bson.D{}}},
+	})
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    40602,
+		Name:    "Location40602",
+		Message: "$indexStats is only valid as the first stage in a pipeline",
+	}, err)
+}
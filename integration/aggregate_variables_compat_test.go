@@ -137,3 +137,56 @@ func TestAggregateVariablesCompatRoot(t *testing.T) {
 
 	testAggregateStagesCompatWithProviders(t, providers, testCases)
 }
+
+func TestAggregateVariablesCompatLet(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{shareddata.Scalars, shareddata.Composites}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Value": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{{"l", bson.D{{"$let", bson.D{
+					{"vars", bson.D{{"x", "$v"}}},
+					{"in", bson.A{"$$x", "$$x.foo"}},
+				}}}}}}},
+			},
+		},
+		"Shadowing": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{{"l", bson.D{{"$let", bson.D{
+					{"vars", bson.D{{"x", "$v"}}},
+					{"in", bson.D{{"$let", bson.D{
+						{"vars", bson.D{{"x", "$_id"}, {"y", "$$x"}}},
+						{"in", bson.D{{"x", "$$x"}, {"y", "$$y"}}},
+					}}}},
+				}}}}}}},
+			},
+		},
+		"Map": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "array"}}}}}},
+				bson.D{{"$project", bson.D{{"l", bson.D{{"$let", bson.D{
+					{"vars", bson.D{{"id", "$_id"}}},
+					{"in", bson.D{{"$map", bson.D{
+						{"input", "$v"},
+						{"as", "id"},
+						{"in", bson.A{"$$id", "$_id"}},
+					}}}},
+				}}}}}}},
+			},
+		},
+		"UndefinedVariable": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{{"l", bson.D{{"$let", bson.D{
+					{"vars", bson.D{{"x", int32(1)}}},
+					{"in", "$$y"},
+				}}}}}}},
+			},
+			resultType: emptyResult,
+			skip:       "https://github.com/FerretDB/FerretDB/issues/2275",
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
@@ -0,0 +1,169 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+func TestAPIVersion(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.Scalars)
+
+	for name, tc := range map[string]struct { //nolint:vet // for readability
+		command bson.D
+		err     *mongo.CommandError // optional
+	}{
+		"Version": {
+			command: bson.D{{"ping", int32(1)}, {"apiVersion", "1"}},
+		},
+		"Strict": {
+			command: bson.D{{"find", collection.Name()}, {"apiVersion", "1"}, {"apiStrict", true}},
+		},
+		"StrictDeprecationErrors": {
+			command: bson.D{
+				{"find", collection.Name()},
+				{"apiVersion", "1"},
+				{"apiStrict", true},
+				{"apiDeprecationErrors", true},
+			},
+		},
+		"NotStrict": {
+			command: bson.D{{"buildInfo", int32(1)}, {"apiVersion", "1"}, {"apiStrict", false}},
+		},
+		"StrictCommand": {
+			command: bson.D{{"buildInfo", int32(1)}, {"apiVersion", "1"}, {"apiStrict", true}},
+			err: &mongo.CommandError{
+				Code: 323,
+				Name: "APIStrictError",
+				Message: "Provided apiStrict:true, but the command buildInfo is not in API Version 1. " +
+					"Information on supported commands and migrations in API Version 1 can be found at " +
+					"https://dochub.mongodb.org/core/manual-versioned-api",
+			},
+		},
+		"StrictField": {
+			command: bson.D{
+				{"find", collection.Name()},
+				{"returnKey", true},
+				{"apiVersion", "1"},
+				{"apiStrict", true},
+			},
+			err: &mongo.CommandError{
+				Code:    323,
+				Name:    "APIStrictError",
+				Message: "BSON field 'find.returnKey' is not allowed with apiStrict:true.",
+			},
+		},
+		"InvalidVersion": {
+			command: bson.D{{"ping", int32(1)}, {"apiVersion", "2"}},
+			err: &mongo.CommandError{
+				Code:    322,
+				Name:    "APIVersionError",
+				Message: `API version must be "1"`,
+			},
+		},
+		"VersionType": {
+			command: bson.D{{"ping", int32(1)}, {"apiVersion", int32(1)}},
+			err: &mongo.CommandError{
+				Code:    14,
+				Name:    "TypeMismatch",
+				Message: "BSON field 'apiVersion' is the wrong type 'int', expected type 'string'",
+			},
+		},
+		"StrictType": {
+			command: bson.D{{"ping", int32(1)}, {"apiVersion", "1"}, {"apiStrict", int32(1)}},
+			err: &mongo.CommandError{
+				Code:    14,
+				Name:    "TypeMismatch",
+				Message: "BSON field 'apiStrict' is the wrong type 'int', expected type 'bool'",
+			},
+		},
+		"StrictWithoutVersion": {
+			command: bson.D{{"ping", int32(1)}, {"apiStrict", true}},
+			err: &mongo.CommandError{
+				Code:    322,
+				Name:    "APIVersionError",
+				Message: "Provided apiStrict without passing apiVersion",
+			},
+		},
+		"DeprecationErrorsWithoutVersion": {
+			command: bson.D{{"ping", int32(1)}, {"apiDeprecationErrors", false}},
+			err: &mongo.CommandError{
+				Code:    322,
+				Name:    "APIVersionError",
+				Message: "Provided apiDeprecationErrors without passing apiVersion",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var res bson.D
+			err := collection.Database().RunCommand(ctx, tc.command).Decode(&res)
+
+			if tc.err != nil {
+				AssertEqualCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAPIVersionStrictClient(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, &setup.SetupOpts{Providers: []shareddata.Provider{shareddata.Scalars}})
+
+	opts := options.Client().ApplyURI(s.MongoDBURI).
+		SetServerAPIOptions(options.ServerAPI(options.ServerAPIVersion1).SetStrict(true))
+
+	client, err := mongo.Connect(s.Ctx, opts)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, client.Disconnect(s.Ctx))
+	})
+
+	collection := client.Database(s.Collection.Database().Name()).Collection(s.Collection.Name())
+
+	_, err = collection.InsertOne(s.Ctx, bson.D{{"_id", "strict"}})
+	require.NoError(t, err)
+
+	n, err := collection.CountDocuments(s.Ctx, bson.D{{"_id", "strict"}})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	err = client.Database("admin").RunCommand(s.Ctx, bson.D{{"buildInfo", int32(1)}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code: 323,
+		Name: "APIStrictError",
+		Message: "Provided apiStrict:true, but the command buildInfo is not in API Version 1. " +
+			"Information on supported commands and migrations in API Version 1 can be found at " +
+			"https://dochub.mongodb.org/core/manual-versioned-api",
+	}, err)
+}
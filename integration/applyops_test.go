@@ -0,0 +1,145 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestApplyOps(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "existing"}, {"v", int32(1)}})
+	require.NoError(t, err)
+
+	ns := collection.Database().Name() + "." + collection.Name()
+	admin := collection.Database().Client().Database("admin")
+
+	var res bson.D
+	err = admin.RunCommand(ctx, bson.D{{"applyOps", bson.A{
+		bson.D{{"op", "i"}, {"ns", ns}, {"o", bson.D{{"_id", "a"}, {"v", int32(1)}, {"n", bson.D{{"x", int32(1)}, {"y", int32(2)}}}}}},
+		bson.D{{"op", "i"}, {"ns", ns}, {"o", bson.D{{"_id", "b"}, {"v", int32(2)}}}},
+		bson.D{{"op", "u"}, {"ns", ns}, {"o2", bson.D{{"_id", "a"}}}, {"o", bson.D{
+			{"$v", int32(2)},
+			{"diff", bson.D{
+				{"u", bson.D{{"v", int32(10)}}},
+				{"i", bson.D{{"w", "new"}}},
+				{"sn", bson.D{{"d", bson.D{{"x", false}}}}},
+			}},
+		}}},
+		bson.D{{"op", "u"}, {"ns", ns}, {"o2", bson.D{{"_id", "existing"}}}, {"o", bson.D{
+			{"_id", "existing"}, {"v", int32(42)},
+		}}},
+		bson.D{{"op", "c"}, {"ns", "admin.$cmd"}, {"o", bson.D{{"applyOps", bson.A{
+			bson.D{{"op", "d"}, {"ns", ns}, {"o", bson.D{{"_id", "b"}}}},
+		}}}}},
+		bson.D{{"op", "c"}, {"ns", collection.Database().Name() + ".$cmd"}, {"o", bson.D{
+			{"createIndexes", collection.Name()}, {"v", int32(2)}, {"key", bson.D{{"v", int32(1)}}}, {"name", "v_1"},
+		}}},
+		bson.D{{"op", "n"}, {"ns", ""}, {"o", bson.D{{"msg", "noop"}}}},
+	}}}).Decode(&res)
+	require.NoError(t, err)
+
+	m := res.Map()
+	assert.EqualValues(t, 7, m["applied"])
+	assert.Equal(t, bson.A{true, true, true, true, true, true, true}, m["results"])
+
+	expected := []bson.D{
+		{{"_id", "a"}, {"v", int32(10)}, {"n", bson.D{{"y", int32(2)}}}, {"w", "new"}},
+		{{"_id", "existing"}, {"v", int32(42)}},
+	}
+	assert.Equal(t, expected, FindAll(t, ctx, collection))
+
+	cursor, err := collection.Indexes().List(ctx)
+	require.NoError(t, err)
+
+	var indexes []bson.D
+	require.NoError(t, cursor.All(ctx, &indexes))
+
+	var names []any
+	for _, index := range indexes {
+		names = append(names, index.Map()["name"])
+	}
+
+	assert.Equal(t, []any{"_id_", "v_1"}, names)
+}
+
+func TestApplyOpsErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "existing"}, {"v", int32(1)}})
+	require.NoError(t, err)
+
+	ns := collection.Database().Name() + "." + collection.Name()
+	admin := collection.Database().Client().Database("admin")
+
+	for name, tc := range map[string]struct {
+		entry bson.D              // required
+		err   *mongo.CommandError // required
+	}{
+		"UnknownOp": {
+			entry: bson.D{{"op", "x"}, {"ns", ns}, {"o", bson.D{{"_id", "a"}}}},
+			err: &mongo.CommandError{
+				Code: 2,
+				Name: "BadValue",
+				Message: `applyOps: op type "x" is not supported; only 'i', 'u', 'd', 'c', and 'n' are: ` +
+					`{ op: "x", ns: "` + ns + `", o: { _id: "a" } }`,
+			},
+		},
+		"UnsupportedCommand": {
+			entry: bson.D{{"op", "c"}, {"ns", "admin.$cmd"}, {"o", bson.D{{"drop", collection.Name()}}}},
+			err: &mongo.CommandError{
+				Code: 238,
+				Name: "NotImplemented",
+				Message: `applyOps: command "drop" is not supported; only applyOps and createIndexes are: ` +
+					`{ op: "c", ns: "admin.$cmd", o: { drop: "` + collection.Name() + `" } }`,
+			},
+		},
+		"ArrayDiff": {
+			entry: bson.D{{"op", "u"}, {"ns", ns}, {"o2", bson.D{{"_id", "existing"}}}, {"o", bson.D{
+				{"$v", int32(2)},
+				{"diff", bson.D{{"sv", bson.D{{"a", true}, {"u0", int32(1)}}}}},
+			}}},
+			err: &mongo.CommandError{
+				Code: 238,
+				Name: "NotImplemented",
+				Message: `applyOps: array diffs are not supported: { op: "u", ns: "` + ns + `", o2: { _id: "existing" }, ` +
+					`o: { $v: 2, diff: { sv: { a: true, u0: 1 } } } }`,
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(tt *testing.T) {
+			tt.Parallel()
+
+			t := setup.FailsForMongoDB(tt, "MongoDB supports more oplog entries")
+
+			err := admin.RunCommand(ctx, bson.D{{"applyOps", bson.A{tc.entry}}}).Err()
+			AssertEqualCommandError(t, *tc.err, err)
+		})
+	}
+}
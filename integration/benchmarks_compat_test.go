@@ -0,0 +1,268 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// Compatibility benchmarks run the same operations against both the target system and the compat system (MongoDB).
+// Results are reported as `backend=target` and `backend=compat` sub-benchmarks,
+// so they could be compared with `benchstat -col=/backend`.
+
+// benchmarkCompatCollection represents a collection of the system under benchmark.
+type benchmarkCompatCollection struct {
+	backend    string
+	collection *mongo.Collection
+}
+
+// setupBenchmarkCompat setups collections filled with documents from the given provider for compatibility benchmark.
+func setupBenchmarkCompat(b *testing.B, provider shareddata.BenchmarkProvider) (context.Context, []benchmarkCompatCollection) {
+	b.Helper()
+
+	s := setup.SetupCompatWithOpts(b, &setup.SetupCompatOpts{
+		BenchmarkProvider: provider,
+	})
+
+	return s.Ctx, []benchmarkCompatCollection{
+		{backend: "target", collection: s.TargetCollections[0]},
+		{backend: "compat", collection: s.CompatCollections[0]},
+	}
+}
+
+// consumeCursor returns the number of documents returned by the cursor and closes it.
+func consumeCursor(b *testing.B, ctx context.Context, cursor *mongo.Cursor) int {
+	b.Helper()
+
+	var docs int
+	for cursor.Next(ctx) {
+		docs++
+	}
+
+	require.NoError(b, cursor.Close(ctx))
+	require.NoError(b, cursor.Err())
+
+	return docs
+}
+
+func BenchmarkInsertManyCompat(b *testing.B) {
+	for _, provider := range shareddata.AllBenchmarkProviders() {
+		b.Run(provider.Name(), func(b *testing.B) {
+			ctx, colls := setupBenchmarkCompat(b, provider)
+
+			total, err := iterator.ConsumeCount(provider.NewIterator())
+			require.NoError(b, err)
+
+			for _, batchSize := range []int{1, 100} {
+				if batchSize > total {
+					continue
+				}
+
+				for _, c := range colls {
+					b.Run(fmt.Sprintf("Batch%d/backend=%s", batchSize, c.backend), func(b *testing.B) {
+						b.StopTimer()
+
+						for range b.N {
+							require.NoError(b, c.collection.Drop(ctx))
+
+							iter := provider.NewIterator()
+
+							for {
+								docs, err := iterator.ConsumeValuesN(iter, batchSize)
+								require.NoError(b, err)
+
+								if docs == nil {
+									break
+								}
+
+								insertDocs := make([]any, len(docs))
+								for i := range insertDocs {
+									insertDocs[i] = docs[i]
+								}
+
+								b.StartTimer()
+
+								_, err = c.collection.InsertMany(ctx, insertDocs)
+								require.NoError(b, err)
+
+								b.StopTimer()
+							}
+						}
+
+						b.ReportMetric(float64(total*b.N)/b.Elapsed().Seconds(), "docs/s")
+					})
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkQueryCompat(b *testing.B) {
+	provider := shareddata.BenchmarkSmallDocuments
+
+	b.Run(provider.Name(), func(b *testing.B) {
+		ctx, colls := setupBenchmarkCompat(b, provider)
+
+		for name, bc := range map[string]struct {
+			filter bson.D
+			opts   *options.FindOptions
+		}{
+			"IDEq": {
+				filter: bson.D{{"_id", int32(42)}},
+			},
+			"FieldEq": {
+				filter: bson.D{{"id", int32(42)}},
+			},
+			"FieldEqMany": {
+				filter: bson.D{{"v", int32(42)}},
+			},
+			"DotNotation": {
+				filter: bson.D{{"v.foo", int32(42)}},
+			},
+			"Range": {
+				filter: bson.D{{"id", bson.D{{"$gte", int32(100)}, {"$lt", int32(200)}}}},
+			},
+			"In": {
+				filter: bson.D{{"_id", bson.D{{"$in", bson.A{int32(1), int32(10), int32(100)}}}}},
+			},
+			"Regex": {
+				filter: bson.D{{"v", primitive.Regex{Pattern: "^f"}}},
+			},
+			"SortLimit": {
+				filter: bson.D{},
+				opts:   options.Find().SetSort(bson.D{{"id", -1}}).SetLimit(10),
+			},
+			"Projection": {
+				filter: bson.D{{"v", "foo"}},
+				opts:   options.Find().SetProjection(bson.D{{"_id", 0}, {"v", 1}}),
+			},
+		} {
+			b.Run(name, func(b *testing.B) {
+				var expected int
+
+				for _, c := range colls {
+					b.Run("backend="+c.backend, func(b *testing.B) {
+						var docs int
+
+						for range b.N {
+							cursor, err := c.collection.Find(ctx, bc.filter, bc.opts)
+							require.NoError(b, err)
+
+							docs = consumeCursor(b, ctx, cursor)
+						}
+
+						b.StopTimer()
+
+						require.Positive(b, docs)
+
+						if expected == 0 {
+							expected = docs
+						}
+
+						require.Equal(b, expected, docs, "target and compat returned different number of documents")
+
+						b.ReportMetric(float64(docs), "docs-returned")
+					})
+				}
+			})
+		}
+	})
+}
+
+func BenchmarkAggregateCompat(b *testing.B) {
+	provider := shareddata.BenchmarkSmallDocuments
+
+	b.Run(provider.Name(), func(b *testing.B) {
+		ctx, colls := setupBenchmarkCompat(b, provider)
+
+		for name, bc := range map[string]struct {
+			pipeline bson.A
+		}{
+			"Match": {
+				pipeline: bson.A{bson.D{{"$match", bson.D{{"v", int32(42)}}}}},
+			},
+			"Group": {
+				pipeline: bson.A{bson.D{{"$group", bson.D{
+					{"_id", "$v"},
+					{"count", bson.D{{"$sum", 1}}},
+				}}}},
+			},
+			"SortLimit": {
+				pipeline: bson.A{
+					bson.D{{"$sort", bson.D{{"id", -1}}}},
+					bson.D{{"$limit", 10}},
+				},
+			},
+			"Project": {
+				pipeline: bson.A{bson.D{{"$project", bson.D{{"_id", 0}, {"v", 1}}}}},
+			},
+			"Count": {
+				pipeline: bson.A{bson.D{{"$count", "total"}}},
+			},
+			"MatchGroupSort": {
+				pipeline: bson.A{
+					bson.D{{"$match", bson.D{{"id", bson.D{{"$lt", int32(500)}}}}}},
+					bson.D{{"$group", bson.D{
+						{"_id", bson.D{{"$type", "$v"}}},
+						{"total", bson.D{{"$sum", "$id"}}},
+					}}},
+					bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				},
+			},
+		} {
+			b.Run(name, func(b *testing.B) {
+				var expected int
+
+				for _, c := range colls {
+					b.Run("backend="+c.backend, func(b *testing.B) {
+						var docs int
+
+						for range b.N {
+							cursor, err := c.collection.Aggregate(ctx, bc.pipeline)
+							require.NoError(b, err)
+
+							docs = consumeCursor(b, ctx, cursor)
+						}
+
+						b.StopTimer()
+
+						require.Positive(b, docs)
+
+						if expected == 0 {
+							expected = docs
+						}
+
+						require.Equal(b, expected, docs, "target and compat returned different number of documents")
+
+						b.ReportMetric(float64(docs), "docs-returned")
+					})
+				}
+			})
+		}
+	})
+}
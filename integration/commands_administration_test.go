@@ -15,6 +15,7 @@
 package integration
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"runtime"
@@ -886,6 +887,139 @@ func TestGetParameterCommandAuthenticationMechanismsPLAIN(tt *testing.T) {
 	require.Equal(t, expected, res)
 }
 
+func TestCommandsAdministrationGetParameterTelemetryReport(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific telemetry report")
+
+	t.Parallel()
+	s := setup.SetupWithOpts(t, &setup.SetupOpts{
+		DatabaseName: "admin",
+	})
+
+	var res bson.D
+	err := s.Collection.Database().RunCommand(s.Ctx, bson.D{{"getParameter", 1}, {"telemetryReport", 1}}).Decode(&res)
+	require.NoError(t, err)
+
+	report, ok := res.Map()["telemetryReport"].(string)
+	require.True(t, ok, "telemetryReport must be a string")
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal([]byte(report), &m))
+
+	assert.NotEmpty(t, m["uuid"])
+	assert.Equal(t, "full", m["level"])
+	assert.Contains(t, m, "command_metrics")
+
+	err = s.Collection.Database().RunCommand(s.Ctx, bson.D{{"getParameter", 1}, {"quiet", 1}}).Decode(&res)
+	require.NoError(t, err)
+	assert.NotContains(t, res.Map(), "telemetryReport")
+}
+
+func TestCommandsAdministrationSetParameter(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, &setup.SetupOpts{
+		DatabaseName: "admin",
+	})
+
+	db := s.Collection.Database()
+
+	// use the current value to not affect other tests
+	var res bson.D
+	err := db.RunCommand(s.Ctx, bson.D{{"getParameter", 1}, {"logLevel", 1}}).Decode(&res)
+	require.NoError(t, err)
+
+	logLevel := ConvertDocument(t, res).Remove("logLevel")
+	require.NotNil(t, logLevel)
+
+	t.Run("LogLevel", func(t *testing.T) {
+		t.Parallel()
+
+		var res bson.D
+		err := db.RunCommand(s.Ctx, bson.D{{"setParameter", 1}, {"logLevel", logLevel}}).Decode(&res)
+		require.NoError(t, err)
+
+		expected := bson.D{{"was", logLevel}, {"ok", float64(1)}}
+		AssertEqualDocuments(t, expected, res)
+	})
+
+	t.Run("LogComponentVerbosity", func(t *testing.T) {
+		t.Parallel()
+
+		var res bson.D
+		err := db.RunCommand(s.Ctx, bson.D{
+			{"setParameter", 1},
+			{"logComponentVerbosity", bson.D{{"storage", bson.D{{"verbosity", int32(-1)}}}}},
+		}).Decode(&res)
+		require.NoError(t, err)
+
+		doc := ConvertDocument(t, res)
+		assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+		assert.IsType(t, new(types.Document), must.NotFail(doc.Get("was")))
+	})
+
+	t.Run("GenericArguments", func(t *testing.T) {
+		t.Parallel()
+
+		var res bson.D
+		err := db.RunCommand(s.Ctx, bson.D{
+			{"setParameter", 1},
+			{"logLevel", logLevel},
+			{"apiVersion", "1"},
+			{"apiDeprecationErrors", false},
+			{"comment", "foo"},
+		}).Decode(&res)
+		require.NoError(t, err)
+
+		expected := bson.D{{"was", logLevel}, {"ok", float64(1)}}
+		AssertEqualDocuments(t, expected, res)
+	})
+
+	t.Run("InvalidSecond", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(s.Ctx, bson.D{
+			{"setParameter", 1},
+			{"logLevel", logLevel},
+			{"logComponentVerbosity", "foo"},
+		}).Err()
+
+		expected := mongo.CommandError{
+			Code:    14,
+			Name:    "TypeMismatch",
+			Message: "logComponentVerbosity must be an object",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(s.Ctx, bson.D{{"setParameter", 1}, {"unknownParameter", 1}}).Err()
+
+		expected := mongo.CommandError{
+			Code:    72,
+			Name:    "InvalidOptions",
+			Message: "attempted to set unrecognized parameter [unknownParameter], use help:true to see options ",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+
+	t.Run("NonAdmin", func(t *testing.T) {
+		t.Parallel()
+
+		err := s.Collection.Database().Client().Database(testutil.DatabaseName(t)).RunCommand(
+			s.Ctx, bson.D{{"setParameter", 1}, {"logLevel", logLevel}},
+		).Err()
+
+		expected := mongo.CommandError{
+			Code:    13,
+			Name:    "Unauthorized",
+			Message: "setParameter may only be run against the admin database.",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+}
+
 func TestCommandsAdministrationBuildInfo(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t)
@@ -1908,3 +2042,136 @@ func TestCommandsAdministrationCurrentOp(t *testing.T) {
 	_, ok := must.NotFail(doc.Get("inprog")).(*types.Array)
 	assert.True(t, ok)
 }
+
+func TestCommandsAdministrationReIndex(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.Scalars)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"v", 1}},
+		Options: options.Index().SetName("v_1"),
+	})
+	require.NoError(t, err)
+
+	t.Run("All", func(t *testing.T) {
+		t.Parallel()
+
+		var res bson.D
+		err := collection.Database().RunCommand(ctx, bson.D{{"reIndex", collection.Name()}}).Decode(&res)
+		require.NoError(t, err)
+
+		doc := ConvertDocument(t, res)
+		assert.Equal(t, int32(2), must.NotFail(doc.Get("nIndexesWas")))
+		assert.Equal(t, int32(2), must.NotFail(doc.Get("nIndexes")))
+		assert.Equal(t, 2, must.NotFail(doc.Get("indexes")).(*types.Array).Len())
+		assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+	})
+
+	t.Run("NonExistentCollection", func(t *testing.T) {
+		t.Parallel()
+
+		err := collection.Database().RunCommand(ctx, bson.D{{"reIndex", "non-existent"}}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    26,
+			Name:    "NamespaceNotFound",
+			Message: "ns does not exist: " + collection.Database().Name() + ".non-existent",
+		}, err)
+	})
+
+	t.Run("Index", func(t *testing.T) {
+		t.Parallel()
+
+		setup.SkipForMongoDB(t, "FerretDB extension")
+
+		var res bson.D
+		err := collection.Database().RunCommand(
+			ctx,
+			bson.D{{"reIndex", collection.Name()}, {"index", "v_1"}},
+		).Decode(&res)
+		require.NoError(t, err)
+
+		doc := ConvertDocument(t, res)
+		assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+	})
+
+	t.Run("IndexNotFound", func(t *testing.T) {
+		t.Parallel()
+
+		setup.SkipForMongoDB(t, "FerretDB extension")
+
+		err := collection.Database().RunCommand(
+			ctx,
+			bson.D{{"reIndex", collection.Name()}, {"index", "non-existent"}},
+		).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    27,
+			Name:    "IndexNotFound",
+			Message: "index not found with name [non-existent]",
+		}, err)
+	})
+
+	t.Run("Background", func(t *testing.T) {
+		t.Parallel()
+
+		setup.SkipForMongoDB(t, "FerretDB extension")
+
+		var res bson.D
+		err := collection.Database().RunCommand(
+			ctx,
+			bson.D{{"reIndex", collection.Name()}, {"index", "v_1"}, {"background", true}},
+		).Decode(&res)
+		require.NoError(t, err)
+
+		doc := ConvertDocument(t, res)
+		opID, ok := must.NotFail(doc.Get("opid")).(int32)
+		require.True(t, ok)
+
+		// the operation may be already finished; killing it should succeed anyway
+		err = collection.Database().Client().Database("admin").RunCommand(
+			ctx,
+			bson.D{{"killOp", int32(1)}, {"op", opID}},
+		).Decode(&res)
+		require.NoError(t, err)
+
+		doc = ConvertDocument(t, res)
+		assert.Equal(t, "attempting to kill op", must.NotFail(doc.Get("info")))
+		assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+	})
+}
+
+func TestCommandsAdministrationKillOpErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	err := collection.Database().RunCommand(ctx, bson.D{{"killOp", int32(1)}, {"op", int32(1)}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "killOp may only be run against the admin database.",
+	}, err)
+}
+
+func TestCommandsAdministrationReloadConfigurationErrors(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific command")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	err := collection.Database().RunCommand(ctx, bson.D{{"reloadConfiguration", int32(1)}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "reloadConfiguration may only be run against the admin database.",
+	}, err)
+
+	// test listener does not support configuration reload
+	err = collection.Database().Client().Database("admin").RunCommand(ctx, bson.D{{"reloadConfiguration", int32(1)}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    238,
+		Name:    "NotImplemented",
+		Message: "Configuration reload is not supported by this FerretDB instance.",
+	}, err)
+}
@@ -15,6 +15,7 @@
 package integration
 
 import (
+	"encoding/json"
 	"net"
 	"net/url"
 	"slices"
@@ -118,6 +119,13 @@ func TestCommandsDiagnosticExplain(t *testing.T) {
 
 			assert.NotEmpty(t, explainResult["queryPlanner"])
 			assert.IsType(t, bson.D{}, explainResult["queryPlanner"])
+
+			// fields used by Compass
+			queryPlanner := ConvertDocument(t, explainResult["queryPlanner"].(bson.D))
+			ns := collection.Database().Name() + "." + collection.Name()
+			assert.Equal(t, ns, must.NotFail(queryPlanner.Get("namespace")))
+			assert.IsType(t, new(types.Document), must.NotFail(queryPlanner.Get("winningPlan")))
+			assert.IsType(t, new(types.Array), must.NotFail(queryPlanner.Get("rejectedPlans")))
 		})
 	}
 }
@@ -168,7 +176,6 @@ func TestCommandsDiagnosticGetLog(t *testing.T) {
 				Name:    "OperationFailed",
 				Message: `No log named 'nonExistentName'`,
 			},
-			altMessage: `no RecentEntries named: nonExistentName`,
 		},
 		"Nil": {
 			command: bson.D{{"getLog", nil}},
@@ -221,6 +228,38 @@ func TestCommandsDiagnosticGetLog(t *testing.T) {
 	}
 }
 
+func TestCommandsDiagnosticGetLogStartupWarnings(t *testing.T) {
+	t.Parallel()
+	res := setup.SetupWithOpts(t, &setup.SetupOpts{
+		DatabaseName: "admin",
+	})
+
+	ctx, collection := res.Ctx, res.Collection
+
+	var doc bson.D
+	err := collection.Database().RunCommand(ctx, bson.D{{"getLog", "startupWarnings"}}).Decode(&doc)
+	require.NoError(t, err)
+
+	log, ok := doc.Map()["log"].(bson.A)
+	require.True(t, ok, "log must be an array")
+
+	for _, l := range log {
+		line, ok := l.(string)
+		require.True(t, ok, "log line must be a string")
+
+		var entry struct {
+			Msg  string   `json:"msg"`
+			ID   int      `json:"id"`
+			Tags []string `json:"tags"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+
+		assert.NotEmpty(t, entry.Msg, line)
+		assert.NotZero(t, entry.ID, line)
+		assert.Contains(t, entry.Tags, "startupWarnings", line)
+	}
+}
+
 func TestCommandsDiagnosticHostInfo(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t)
@@ -272,6 +311,40 @@ func TestCommandsDiagnosticListCommands(t *testing.T) {
 	assert.NotEmpty(t, must.NotFail(listCommands.Get("help")).(string))
 }
 
+func TestCommandsDiagnosticTop(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "top"}})
+	require.NoError(t, err)
+
+	adminDB := collection.Database().Client().Database("admin")
+
+	var a bson.D
+	err = adminDB.RunCommand(ctx, bson.D{{"top", 1}}).Decode(&a)
+	require.NoError(t, err)
+
+	actual := ConvertDocument(t, a)
+	assert.Equal(t, float64(1), must.NotFail(actual.Get("ok")))
+
+	totals := must.NotFail(actual.Get("totals")).(*types.Document)
+	ns := must.NotFail(totals.Get(collection.Database().Name() + "." + collection.Name())).(*types.Document)
+	assert.Equal(t, []string{
+		"total", "readLock", "writeLock", "queries", "getmore", "insert", "update", "remove", "commands",
+	}, ns.Keys())
+
+	insert := must.NotFail(ns.Get("insert")).(*types.Document)
+	assert.Equal(t, []string{"time", "count"}, insert.Keys())
+	assert.GreaterOrEqual(t, must.NotFail(insert.Get("count")), int64(1))
+
+	err = collection.Database().RunCommand(ctx, bson.D{{"top", 1}}).Decode(&a)
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "top may only be run against the admin database.",
+	}, err)
+}
+
 func TestCommandsDiagnosticValidate(t *testing.T) {
 	t.Parallel()
 
@@ -396,6 +469,86 @@ func TestCommandsDiagnosticValidateError(t *testing.T) {
 	}
 }
 
+func TestCommandsDiagnosticValidateDBMetadata(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.Doubles)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"_id", 1}, {"v", -1}},
+		Options: options.Index().SetUnique(true),
+	})
+	require.NoError(t, err)
+
+	db := collection.Database()
+	apiParameters := bson.D{{"version", "1"}, {"strict", true}, {"deprecationErrors", true}}
+
+	for name, tc := range map[string]struct { //nolint:vet // for readability
+		command bson.D
+		err     *mongo.CommandError
+	}{
+		"Collection": {
+			command: bson.D{
+				{"validateDBMetadata", int32(1)},
+				{"apiParameters", apiParameters},
+				{"db", db.Name()},
+				{"collection", collection.Name()},
+			},
+		},
+		"Database": {
+			command: bson.D{
+				{"validateDBMetadata", int32(1)},
+				{"apiParameters", apiParameters},
+				{"db", db.Name()},
+			},
+		},
+		"AllDatabases": {
+			command: bson.D{
+				{"validateDBMetadata", int32(1)},
+				{"apiParameters", bson.D{{"version", "1"}}},
+			},
+		},
+		"NonExistentCollection": {
+			command: bson.D{
+				{"validateDBMetadata", int32(1)},
+				{"apiParameters", apiParameters},
+				{"db", db.Name()},
+				{"collection", "nonExistentCollection"},
+			},
+		},
+		"UnsupportedVersion": {
+			command: bson.D{
+				{"validateDBMetadata", int32(1)},
+				{"apiParameters", bson.D{{"version", "2"}}},
+			},
+			err: &mongo.CommandError{
+				Code:    322,
+				Name:    "APIVersionError",
+				Message: `API version must be "1" but got "2"`,
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var res bson.D
+			err := db.RunCommand(ctx, tc.command).Decode(&res)
+
+			if tc.err != nil {
+				AssertEqualCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			doc := ConvertDocument(t, res)
+			assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+			assert.Equal(t, types.MakeArray(0), must.NotFail(doc.Get("apiVersionErrors")))
+		})
+	}
+}
+
 func TestCommandsDiagnosticWhatsMyURI(t *testing.T) {
 	t.Parallel()
 
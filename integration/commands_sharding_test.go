@@ -0,0 +1,123 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestCommandsSharding(t *testing.T) {
+	setup.SkipForMongoDB(t, "MongoDB is not sharded in tests; FerretDB emulates sharding commands")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	db := collection.Database()
+	adminDB := db.Client().Database("admin")
+	configDB := db.Client().Database("config")
+	ns := db.Name() + "." + collection.Name()
+
+	err := db.RunCommand(ctx, bson.D{{"enableSharding", db.Name()}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "enableSharding may only be run against the admin database.",
+	}, err)
+
+	err = adminDB.RunCommand(ctx, bson.D{{"shardCollection", ns}, {"key", bson.D{{"v", "range"}}}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code: 2,
+		Name: "BadValue",
+		Message: "Unsupported shard key pattern. Pattern must either be a single hashed field, " +
+			"or a list of ascending fields",
+	}, err)
+
+	var res bson.D
+	err = adminDB.RunCommand(ctx, bson.D{{"enableSharding", db.Name()}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{"ok", float64(1)}}, res)
+
+	err = adminDB.RunCommand(ctx, bson.D{{"shardCollection", ns}, {"key", bson.D{{"_id", "hashed"}}}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{"collectionsharded", ns}, {"ok", float64(1)}}, res)
+
+	// the same key could be used again
+	err = adminDB.RunCommand(ctx, bson.D{{"shardCollection", ns}, {"key", bson.D{{"_id", "hashed"}}}}).Err()
+	require.NoError(t, err)
+
+	err = adminDB.RunCommand(ctx, bson.D{{"shardCollection", ns}, {"key", bson.D{{"v", 1}}}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    23,
+		Name:    "AlreadyInitialized",
+		Message: "sharding already enabled for collection " + ns + " with a different shard key",
+	}, err)
+
+	t.Run("ListShards", func(t *testing.T) {
+		var actual struct {
+			Shards []bson.M `bson:"shards"`
+		}
+		err = adminDB.RunCommand(ctx, bson.D{{"listShards", 1}}).Decode(&actual)
+		require.NoError(t, err)
+		require.Len(t, actual.Shards, 1)
+		assert.Equal(t, "shard0", actual.Shards[0]["_id"])
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		var dbDoc bson.M
+		err = configDB.Collection("databases").FindOne(ctx, bson.D{{"_id", db.Name()}}).Decode(&dbDoc)
+		require.NoError(t, err)
+		assert.Equal(t, "shard0", dbDoc["primary"])
+
+		var collDoc bson.M
+		err = configDB.Collection("collections").FindOne(ctx, bson.D{{"_id", ns}}).Decode(&collDoc)
+		require.NoError(t, err)
+		assert.Equal(t, bson.M{"_id": "hashed"}, collDoc["key"])
+		assert.Equal(t, false, collDoc["unique"])
+		assert.NotNil(t, collDoc["uuid"])
+
+		var shardDoc bson.M
+		err = configDB.Collection("shards").FindOne(ctx, bson.D{{"_id", "shard0"}}).Decode(&shardDoc)
+		require.NoError(t, err)
+		assert.NotEmpty(t, shardDoc["host"])
+	})
+
+	t.Run("CollStats", func(t *testing.T) {
+		pipeline := bson.A{bson.D{{"$collStats", bson.D{{"storageStats", bson.D{}}}}}}
+
+		cursor, err := collection.Aggregate(ctx, pipeline)
+		require.NoError(t, err)
+
+		var stats []bson.M
+		require.NoError(t, cursor.All(ctx, &stats))
+		require.Len(t, stats, 1)
+		assert.Equal(t, "shard0", stats[0]["shard"])
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		require.NoError(t, collection.Drop(ctx))
+
+		count, err := configDB.Collection("collections").CountDocuments(ctx, bson.D{{"_id", ns}})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+}
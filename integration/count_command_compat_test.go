@@ -213,3 +213,68 @@ func TestCountCommandCompatErrors(t *testing.T) {
 
 	testCountCommandCompat(t, testCases)
 }
+
+func TestCountCommandCompatOptions(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]countCommandCompatTestCase{
+		"Skip": {
+			command: bson.D{
+				{"query", bson.D{}},
+				{"skip", int64(2)},
+			},
+		},
+		"SkipTooLarge": {
+			command: bson.D{
+				{"skip", int64(1000)},
+			},
+		},
+		"Limit": {
+			command: bson.D{
+				{"query", bson.D{}},
+				{"limit", int64(3)},
+			},
+		},
+		"SkipLimit": {
+			command: bson.D{
+				{"skip", int64(1)},
+				{"limit", int64(2)},
+			},
+		},
+		"QueryID": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "int32"}}},
+			},
+		},
+		"QuerySkipLimit": {
+			command: bson.D{
+				{"query", bson.D{{"v", bson.D{{"$exists", true}}}}},
+				{"skip", int64(1)},
+				{"limit", int64(1)},
+			},
+		},
+		"HintID": {
+			command: bson.D{
+				{"query", bson.D{}},
+				{"hint", bson.D{{"_id", 1}}},
+			},
+		},
+		"HintName": {
+			command: bson.D{
+				{"hint", "_id_"},
+			},
+		},
+		"HintNatural": {
+			command: bson.D{
+				{"hint", bson.D{{"$natural", 1}}},
+			},
+		},
+		"HintNotFound": {
+			command: bson.D{
+				{"hint", "foo_1"},
+			},
+		},
+	}
+
+	testCountCommandCompat(t, testCases)
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestCountCommandOptions(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{"v", 1}}})
+	require.NoError(t, err)
+
+	_, err = collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "a"}, {"v", int32(1)}},
+		bson.D{{"_id", "b"}, {"v", int32(2)}},
+		bson.D{{"_id", "c"}, {"v", int32(2)}},
+		bson.D{{"_id", "d"}, {"v", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		command bson.D // required, without the `count` field
+
+		n   int32               // expected count
+		err *mongo.CommandError // optional, expected error
+	}{
+		"Empty": {
+			command: bson.D{},
+			n:       4,
+		},
+		"Skip": {
+			command: bson.D{{"skip", int64(1)}},
+			n:       3,
+		},
+		"SkipTooLarge": {
+			command: bson.D{{"skip", int64(10)}},
+			n:       0,
+		},
+		"Limit": {
+			command: bson.D{{"limit", int64(2)}},
+			n:       2,
+		},
+		"SkipLimit": {
+			command: bson.D{{"skip", int64(3)}, {"limit", int64(2)}},
+			n:       1,
+		},
+		"QueryID": {
+			command: bson.D{{"query", bson.D{{"_id", "b"}}}},
+			n:       1,
+		},
+		"QueryIDNotFound": {
+			command: bson.D{{"query", bson.D{{"_id", "z"}}}},
+			n:       0,
+		},
+		"Query": {
+			command: bson.D{{"query", bson.D{{"v", int32(2)}}}, {"skip", int64(1)}},
+			n:       1,
+		},
+		"HintName": {
+			command: bson.D{{"query", bson.D{{"v", int32(2)}}}, {"hint", "v_1"}},
+			n:       2,
+		},
+		"HintKeyPattern": {
+			command: bson.D{{"hint", bson.D{{"v", 1}}}},
+			n:       4,
+		},
+		"HintNatural": {
+			command: bson.D{{"hint", bson.D{{"$natural", -1}}}},
+			n:       4,
+		},
+		"HintNotFound": {
+			command: bson.D{{"hint", "foo_1"}},
+			err: &mongo.CommandError{
+				Code: 2,
+				Name: "BadValue",
+				Message: "error processing query: planner returned error :: caused by :: " +
+					"hint provided does not correspond to an existing index",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			command := append(bson.D{{"count", collection.Name()}}, tc.command...)
+
+			var res bson.D
+			err := collection.Database().RunCommand(ctx, command).Decode(&res)
+
+			if tc.err != nil {
+				AssertMatchesCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.n, res.Map()["n"])
+		})
+	}
+}
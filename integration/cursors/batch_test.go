@@ -17,6 +17,7 @@ package cursors
 import (
 	"errors"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,6 +26,9 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+
 	"github.com/FerretDB/FerretDB/integration"
 	"github.com/FerretDB/FerretDB/integration/setup"
 )
@@ -285,3 +289,68 @@ func TestCursorsFindAfterExhausted(t *testing.T) {
 	require.True(t, errors.As(err, &ce))
 	require.Equal(t, int32(43), ce.Code, "invalid error: %v", ce)
 }
+
+func TestCursorsFirstBatchSizeLimit(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	// 6 MiB documents; only two of them fit into 16 MiB batch,
+	// and the third one that does not fit is returned in the next batch
+	v := strings.Repeat("x", 6<<20)
+
+	for i := range 5 {
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", int32(i)}, {"v", v}})
+		require.NoError(t, err)
+	}
+
+	for name, cmd := range map[string]bson.D{
+		"Find": {
+			{"find", collection.Name()},
+			{"sort", bson.D{{"_id", 1}}},
+		},
+		"Aggregate": {
+			{"aggregate", collection.Name()},
+			{"pipeline", bson.A{bson.D{{"$sort", bson.D{{"_id", 1}}}}}},
+			{"cursor", bson.D{}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var res bson.D
+			err := db.RunCommand(ctx, cmd).Decode(&res)
+			require.NoError(t, err)
+
+			firstBatch, cursorID := getFirstBatch(t, res)
+			require.NotZero(t, cursorID)
+
+			ids := make([]any, firstBatch.Len())
+			for i := range ids {
+				ids[i] = must.NotFail(must.NotFail(firstBatch.Get(i)).(*types.Document).Get("_id"))
+			}
+
+			require.Len(t, ids, 2)
+
+			for nextID := cursorID; nextID != int64(0); {
+				err = db.RunCommand(ctx, bson.D{
+					{"getMore", cursorID},
+					{"collection", collection.Name()},
+				}).Decode(&res)
+				require.NoError(t, err)
+
+				var nextBatch *types.Array
+				nextBatch, nextID = getNextBatch(t, res)
+				require.LessOrEqual(t, nextBatch.Len(), 2)
+				require.NotZero(t, nextBatch.Len())
+
+				for i := range nextBatch.Len() {
+					ids = append(ids, must.NotFail(must.NotFail(nextBatch.Get(i)).(*types.Document).Get("_id")))
+				}
+			}
+
+			assert.Equal(t, []any{int32(0), int32(1), int32(2), int32(3), int32(4)}, ids)
+		})
+	}
+}
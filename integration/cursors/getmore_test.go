@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"math"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -601,10 +602,8 @@ func TestCursorsGetMoreCommandConnection(t *testing.T) {
 		require.NoError(t, err)
 	})
 
-	t.Run("DifferentClient", func(tt *testing.T) {
-		// The error returned from MongoDB is a session error, FerretDB does not
-		// return an error because db, collection and username are the same.
-		t := setup.FailsForFerretDB(tt, "https://github.com/FerretDB/FerretDB/issues/153")
+	t.Run("DifferentClient", func(t *testing.T) {
+		// different clients use different sessions, so the cursor does not belong to the second one
 
 		// do not run subtest in parallel to avoid breaking another parallel subtest
 		var res bson.D
@@ -890,6 +889,55 @@ func TestCursorsGetMoreExhausted(t *testing.T) {
 	integration.AssertEqualCommandError(t, expectedErr, err)
 }
 
+func TestCursorsGetMoreBatchSizeLimit(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	// 6 MiB documents; only two of them fit into 16 MiB batch
+	v := strings.Repeat("x", 6<<20)
+
+	for i := range 4 {
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", int32(i)}, {"v", v}})
+		require.NoError(t, err)
+	}
+
+	var res bson.D
+	err := db.RunCommand(ctx, bson.D{
+		{"find", collection.Name()},
+		{"sort", bson.D{{"_id", 1}}},
+		{"batchSize", 1},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	firstBatch, cursorID := getFirstBatch(t, res)
+	require.Equal(t, 1, firstBatch.Len())
+	require.NotZero(t, cursorID)
+
+	err = db.RunCommand(ctx, bson.D{
+		{"getMore", cursorID},
+		{"collection", collection.Name()},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	nextBatch, nextID := getNextBatch(t, res)
+	require.Equal(t, 2, nextBatch.Len())
+	assert.Equal(t, int32(1), must.NotFail(must.NotFail(nextBatch.Get(0)).(*types.Document).Get("_id")))
+	assert.Equal(t, cursorID, nextID)
+
+	err = db.RunCommand(ctx, bson.D{
+		{"getMore", cursorID},
+		{"collection", collection.Name()},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	nextBatch, nextID = getNextBatch(t, res)
+	require.Equal(t, 1, nextBatch.Len())
+	assert.Equal(t, int32(3), must.NotFail(must.NotFail(nextBatch.Get(0)).(*types.Document).Get("_id")))
+	assert.Equal(t, int64(0), nextID)
+}
+
 func TestCursorsGetMoreCommandMaxTimeMSCursor(t *testing.T) {
 	// do not run tests in parallel to avoid using too many backend connections
 
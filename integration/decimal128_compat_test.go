@@ -0,0 +1,184 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// decimal128Providers returns providers for Decimal128 compatibility tests.
+//
+// Decimal128 values can't be converted to types package values for comparison,
+// so test cases project only `_id` fields, and sort by `v` field to check the order.
+func decimal128Providers(t *testing.T) shareddata.Providers {
+	t.Helper()
+
+	if !setup.IsMongoDB(t) {
+		t.Skip("https://github.com/FerretDB/FerretDB/issues/66")
+	}
+
+	return shareddata.Providers{
+		shareddata.Decimal128s,
+		shareddata.Doubles,
+		shareddata.Int64s,
+	}
+}
+
+// dec parses the given Decimal128 string.
+func dec(s string) primitive.Decimal128 {
+	return must.NotFail(primitive.ParseDecimal128(s))
+}
+
+func TestQueryCompatDecimal128(t *testing.T) {
+	t.Parallel()
+
+	providers := decimal128Providers(t)
+	idOnly := bson.D{{"_id", int32(1)}}
+
+	testCases := map[string]queryCompatTestCase{
+		"Eq": {
+			filter:     bson.D{{"v", dec("42.13")}},
+			projection: idOnly,
+		},
+		"EqTrailingZeros": {
+			filter:     bson.D{{"v", dec("42.130000")}},
+			projection: idOnly,
+		},
+		"EqWhole": {
+			filter:     bson.D{{"v", dec("42")}},
+			projection: idOnly,
+		},
+		"EqDouble": {
+			filter:     bson.D{{"v", float64(42)}},
+			projection: idOnly,
+		},
+		"EqNegativeZero": {
+			filter:     bson.D{{"v", dec("-0")}},
+			projection: idOnly,
+		},
+		"EqNaN": {
+			filter:     bson.D{{"v", dec("NaN")}},
+			projection: idOnly,
+		},
+		"EqInfinity": {
+			filter:     bson.D{{"v", dec("Infinity")}},
+			projection: idOnly,
+		},
+		"EqMax": {
+			filter:     bson.D{{"v", dec("9.999999999999999999999999999999999E+6144")}},
+			projection: idOnly,
+		},
+		"EqSmallest": {
+			filter:     bson.D{{"v", dec("1E-6176")}},
+			projection: idOnly,
+		},
+		"EqTenthDouble": {
+			filter:     bson.D{{"v", 0.1}},
+			projection: idOnly,
+		},
+		"Gt": {
+			filter:     bson.D{{"v", bson.D{{"$gt", dec("42")}}}},
+			projection: idOnly,
+		},
+		"GtNaN": {
+			filter:     bson.D{{"v", bson.D{{"$gt", dec("NaN")}}}},
+			projection: idOnly,
+		},
+		"GteNegativeInfinity": {
+			filter:     bson.D{{"v", bson.D{{"$gte", dec("-Infinity")}}}},
+			projection: idOnly,
+		},
+		"LtSmallest": {
+			filter:     bson.D{{"v", bson.D{{"$lt", dec("1E-6176")}}}},
+			projection: idOnly,
+		},
+		"LteZero": {
+			filter:     bson.D{{"v", bson.D{{"$lte", dec("0E-6176")}}}},
+			projection: idOnly,
+		},
+		"In": {
+			filter:     bson.D{{"v", bson.D{{"$in", bson.A{dec("NaN"), dec("-0"), dec("42.0")}}}}},
+			projection: idOnly,
+		},
+		"Type": {
+			filter:     bson.D{{"v", bson.D{{"$type", "decimal"}}}},
+			projection: idOnly,
+		},
+		"TypeNumber": {
+			filter:     bson.D{{"v", bson.D{{"$type", "number"}}}},
+			projection: idOnly,
+		},
+		"SortAsc": {
+			filter:     bson.D{},
+			sort:       bson.D{{"v", int32(1)}, {"_id", int32(1)}},
+			projection: idOnly,
+		},
+		"SortDesc": {
+			filter:     bson.D{},
+			sort:       bson.D{{"v", int32(-1)}, {"_id", int32(1)}},
+			projection: idOnly,
+		},
+	}
+
+	testQueryCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatDecimal128(t *testing.T) {
+	t.Parallel()
+
+	providers := decimal128Providers(t)
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Match": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$gte", dec("-0")}}}}}},
+				bson.D{{"$project", bson.D{{"_id", int32(1)}}}},
+			},
+		},
+		"Sort": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"v", int32(1)}, {"_id", int32(1)}}}},
+				bson.D{{"$project", bson.D{{"_id", int32(1)}}}},
+			},
+		},
+		"GroupEqual": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", int32(1)}}}},
+				bson.D{{"$group", bson.D{
+					{"_id", "$v"},
+					{"ids", bson.D{{"$push", "$_id"}}},
+				}}},
+				bson.D{{"$project", bson.D{{"_id", int32(0)}, {"ids", int32(1)}}}},
+				bson.D{{"$sort", bson.D{{"ids", int32(1)}}}},
+			},
+		},
+		"Count": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "decimal"}}}}}},
+				bson.D{{"$count", "count"}},
+			},
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
@@ -71,9 +71,10 @@ func TestDiffInsertObjectIDHexString(t *testing.T) {
 	}
 
 	expected := mongo.WriteError{
-		Index:   0,
-		Code:    11000,
-		Message: `E11000 duplicate key error collection: TestDiffInsertObjectIDHexString.TestDiffInsertObjectIDHexString`,
+		Index: 0,
+		Code:  11000,
+		Message: `E11000 duplicate key error collection: TestDiffInsertObjectIDHexString.TestDiffInsertObjectIDHexString ` +
+			`index: _id_ dup key: { _id: "000102030405060708091011" }`,
 	}
 	AssertEqualWriteError(t, expected, err)
 }
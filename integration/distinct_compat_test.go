@@ -20,6 +20,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 	"github.com/FerretDB/FerretDB/integration/shareddata"
@@ -29,6 +30,7 @@ import (
 type distinctCompatTestCase struct {
 	field      string                   // required
 	filter     bson.D                   // required
+	collation  *options.Collation       // optional
 	resultType compatTestCaseResultType // defaults to nonEmptyResult
 }
 
@@ -55,6 +57,11 @@ func testDistinctCompat(t *testing.T, testCases map[string]distinctCompatTestCas
 			filter := tc.filter
 			require.NotNil(t, filter, "filter should be set")
 
+			opts := options.Distinct()
+			if tc.collation != nil {
+				opts.SetCollation(tc.collation)
+			}
+
 			var nonEmptyResults bool
 			for i := range targetCollections {
 				targetCollection := targetCollections[i]
@@ -62,8 +69,8 @@ func testDistinctCompat(t *testing.T, testCases map[string]distinctCompatTestCas
 				t.Run(targetCollection.Name(), func(t *testing.T) {
 					t.Helper()
 
-					targetRes, targetErr := targetCollection.Distinct(ctx, tc.field, tc.filter)
-					compatRes, compatErr := compatCollection.Distinct(ctx, tc.field, tc.filter)
+					targetRes, targetErr := targetCollection.Distinct(ctx, tc.field, tc.filter, opts)
+					compatRes, compatErr := compatCollection.Distinct(ctx, tc.field, tc.filter, opts)
 
 					if targetErr != nil {
 						t.Logf("Target error: %v", targetErr)
@@ -150,6 +157,37 @@ func TestDistinctCompat(t *testing.T) {
 			field:  "v.0.foo",
 			filter: bson.D{},
 		},
+		"DotNotationArrayDocuments": {
+			field:  "v.foo.bar",
+			filter: bson.D{},
+		},
+		"DotNotationNestedArray": {
+			field:  "v.array",
+			filter: bson.D{},
+		},
+		"FilterNull": {
+			field:  "v",
+			filter: bson.D{{"v", nil}},
+		},
+		"FilterNotExists": {
+			field:      "v",
+			filter:     bson.D{{"v", bson.D{{"$exists", false}}}},
+			resultType: emptyResult,
+		},
+		"FilterOperator": {
+			field:  "v",
+			filter: bson.D{{"v", bson.D{{"$gt", 0}}}},
+		},
+		"Collation": {
+			field:     "v",
+			filter:    bson.D{},
+			collation: &options.Collation{Locale: "en", Strength: 2},
+		},
+		"CollationSimple": {
+			field:     "v",
+			filter:    bson.D{},
+			collation: &options.Collation{Locale: "simple"},
+		},
 	}
 
 	testDistinctCompat(t, testCases)
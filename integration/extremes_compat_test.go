@@ -0,0 +1,130 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// nestedPath returns dot notation path to the innermost value of `v` field
+// of DocumentsNestedToLimit documents nested to the given depth.
+func nestedPath(depth int) string {
+	return "v" + strings.Repeat(".a", depth)
+}
+
+func TestQueryCompatExtremes(t *testing.T) {
+	t.Parallel()
+
+	// top-level document is the first level
+	depth := shareddata.MaxNestingDepth - 1
+
+	testCases := map[string]queryCompatTestCase{
+		"All": {
+			filter: bson.D{},
+		},
+		"LargeExclude": {
+			filter:     bson.D{},
+			projection: bson.D{{"large", int32(0)}},
+		},
+		"LargeInclude": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", int32(1)}},
+		},
+		"LargeType": {
+			filter: bson.D{{"large", bson.D{{"$type", "string"}}}},
+		},
+		"LargeSort": {
+			filter: bson.D{{"large", bson.D{{"$exists", true}}}},
+			sort:   bson.D{{"large", int32(-1)}, {"_id", int32(1)}},
+		},
+		"Nested": {
+			filter: bson.D{{nestedPath(depth), int32(42)}},
+		},
+		"NestedHalf": {
+			filter: bson.D{{nestedPath(depth / 2), int32(42)}},
+		},
+		"NestedExists": {
+			filter: bson.D{{nestedPath(depth - 1), bson.D{{"$exists", true}}}},
+		},
+		"NestedType": {
+			filter: bson.D{{nestedPath(depth - 1), bson.D{{"$type", "object"}}}},
+		},
+		"LargeArraySize": {
+			filter: bson.D{{"v", bson.D{{"$size", shareddata.LargeArrayLen}}}},
+		},
+		"LargeArrayLastElement": {
+			filter: bson.D{{"v." + strconv.Itoa(shareddata.LargeArrayLen-1), int32(shareddata.LargeArrayLen - 1)}},
+		},
+		"LargeArrayElemMatch": {
+			filter: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"i", bson.D{{"$gte", shareddata.LargeArrayLen - 10}}}}}}}},
+		},
+		"LargeArrayAll": {
+			filter: bson.D{{"v", bson.D{{"$all", bson.A{int32(0), int32(shareddata.LargeArrayLen - 1)}}}}},
+		},
+		"LongFieldName": {
+			filter: bson.D{{shareddata.LongFieldName("a"), int32(42)}},
+		},
+		"LongFieldNameEmbedded": {
+			filter: bson.D{{"v." + shareddata.LongFieldName("a"), int32(42)}},
+		},
+		"LongFieldNameProjection": {
+			filter:     bson.D{},
+			projection: bson.D{{shareddata.LongFieldName("b"), int32(1)}},
+		},
+	}
+
+	testQueryCompatWithProviders(t, shareddata.ExtremesProviders(), testCases)
+}
+
+func TestAggregateCompatExtremes(t *testing.T) {
+	t.Parallel()
+
+	// top-level document is the first level
+	depth := shareddata.MaxNestingDepth - 1
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"LargeProject": {
+			pipeline: bson.A{
+				bson.D{{"$project", bson.D{{"large", int32(0)}}}},
+			},
+		},
+		"NestedMatch": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{nestedPath(depth), int32(42)}}}},
+			},
+		},
+		"LargeArrayUnwind": {
+			pipeline: bson.A{
+				bson.D{{"$unwind", "$v"}},
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$gte", shareddata.LargeArrayLen - 10}}}}}},
+				bson.D{{"$sort", bson.D{{"_id", int32(1)}, {"v", int32(1)}}}},
+			},
+		},
+		"LongFieldNameGroup": {
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{{"_id", "$" + shareddata.LongFieldName("a")}}}},
+				bson.D{{"$sort", bson.D{{"_id", int32(1)}}}},
+			},
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, shareddata.ExtremesProviders(), testCases)
+}
@@ -0,0 +1,293 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/internal/backends/decorators/faults"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestFaults(tt *testing.T) {
+	tt.Parallel()
+
+	find := func(ctx context.Context, collection *mongo.Collection) error {
+		cursor, err := collection.Find(ctx, bson.D{})
+		if err != nil {
+			return err
+		}
+
+		var res []bson.D
+		return cursor.All(ctx, &res)
+	}
+
+	for name, tc := range map[string]struct {
+		op    faults.Op
+		fault faults.Fault
+		f     func(context.Context, *mongo.Collection) error
+
+		operationTimeout time.Duration // default operation timeout, 0 for no limit
+
+		err      *mongo.CommandError // nil if operation should succeed
+		injected int                 // expected number of injected faults, 0 to skip check
+	}{
+		"QueryConnectionDrop": {
+			op:    faults.OpQuery,
+			fault: faults.Fault{Kind: faults.KindConnectionDrop},
+			f:     find,
+			err: &mongo.CommandError{
+				Code:   6,
+				Name:   "HostUnreachable",
+				Labels: []string{"RetryableWriteError"},
+			},
+		},
+		"QueryConnectionDropRetried": {
+			op:       faults.OpQuery,
+			fault:    faults.Fault{Kind: faults.KindConnectionDrop, Times: 1},
+			f:        find,
+			injected: 1,
+		},
+		"QueryTimeoutRetried": {
+			op:       faults.OpQuery,
+			fault:    faults.Fault{Kind: faults.KindTimeout, Times: 1},
+			f:        find,
+			injected: 1,
+		},
+		"QuerySlow": {
+			op:       faults.OpQuery,
+			fault:    faults.Fault{Kind: faults.KindSlow, Delay: 100 * time.Millisecond},
+			f:        find,
+			injected: 1,
+		},
+		"QuerySlowMaxTime": {
+			op:    faults.OpQuery,
+			fault: faults.Fault{Kind: faults.KindSlow, Delay: time.Minute},
+			f: func(ctx context.Context, collection *mongo.Collection) error {
+				_, err := collection.Find(ctx, bson.D{}, options.Find().SetMaxTime(100*time.Millisecond))
+				return err
+			},
+			err: &mongo.CommandError{
+				Code: 50,
+				Name: "MaxTimeMSExpired",
+			},
+		},
+		"QuerySlowOperationTimeout": {
+			op:               faults.OpQuery,
+			fault:            faults.Fault{Kind: faults.KindSlow, Delay: time.Minute},
+			f:                find,
+			operationTimeout: 100 * time.Millisecond,
+			err: &mongo.CommandError{
+				Code: 50,
+				Name: "MaxTimeMSExpired",
+			},
+		},
+		"QuerySlowOperationTimeoutMaxTime": {
+			op:    faults.OpQuery,
+			fault: faults.Fault{Kind: faults.KindSlow, Delay: 100 * time.Millisecond},
+			f: func(ctx context.Context, collection *mongo.Collection) error {
+				_, err := collection.Find(ctx, bson.D{}, options.Find().SetMaxTime(time.Minute))
+				return err
+			},
+			operationTimeout: 10 * time.Millisecond,
+			injected:         1,
+		},
+		"AggregateSlowOperationTimeout": {
+			op:    faults.OpQuery,
+			fault: faults.Fault{Kind: faults.KindSlow, Delay: time.Minute},
+			f: func(ctx context.Context, collection *mongo.Collection) error {
+				_, err := collection.Aggregate(ctx, bson.A{})
+				return err
+			},
+			operationTimeout: 100 * time.Millisecond,
+			err: &mongo.CommandError{
+				Code: 50,
+				Name: "MaxTimeMSExpired",
+			},
+		},
+		"FindAndModifySlowOperationTimeout": {
+			op:    faults.OpQuery,
+			fault: faults.Fault{Kind: faults.KindSlow, Delay: time.Minute},
+			f: func(ctx context.Context, collection *mongo.Collection) error {
+				return collection.FindOneAndDelete(ctx, bson.D{{"_id", "foo"}}).Err()
+			},
+			operationTimeout: 100 * time.Millisecond,
+			err: &mongo.CommandError{
+				Code: 50,
+				Name: "MaxTimeMSExpired",
+			},
+		},
+		"CountSlowOperationTimeout": {
+			op:    faults.OpQuery,
+			fault: faults.Fault{Kind: faults.KindSlow, Delay: time.Minute},
+			f: func(ctx context.Context, collection *mongo.Collection) error {
+				cmd := bson.D{{"count", collection.Name()}, {"query", bson.D{{"v", "bar"}}}}
+				return collection.Database().RunCommand(ctx, cmd).Err()
+			},
+			operationTimeout: 100 * time.Millisecond,
+			err: &mongo.CommandError{
+				Code: 50,
+				Name: "MaxTimeMSExpired",
+			},
+		},
+		"CountSlowMaxTime": {
+			op:    faults.OpQuery,
+			fault: faults.Fault{Kind: faults.KindSlow, Delay: time.Minute},
+			f: func(ctx context.Context, collection *mongo.Collection) error {
+				cmd := bson.D{{"count", collection.Name()}, {"query", bson.D{{"v", "bar"}}}, {"maxTimeMS", int32(100)}}
+				return collection.Database().RunCommand(ctx, cmd).Err()
+			},
+			err: &mongo.CommandError{
+				Code: 50,
+				Name: "MaxTimeMSExpired",
+			},
+		},
+		"UpdateSlowOperationTimeoutMaxTime": {
+			op:    faults.OpQuery,
+			fault: faults.Fault{Kind: faults.KindSlow, Delay: 100 * time.Millisecond},
+			f: func(ctx context.Context, collection *mongo.Collection) error {
+				cmd := bson.D{
+					{"update", collection.Name()},
+					{"updates", bson.A{bson.D{{"q", bson.D{{"_id", "foo"}}}, {"u", bson.D{{"$set", bson.D{{"v", "baz"}}}}}}}},
+					{"maxTimeMS", int32(60000)},
+				}
+				return collection.Database().RunCommand(ctx, cmd).Err()
+			},
+			operationTimeout: 10 * time.Millisecond,
+			injected:         1,
+		},
+		"DropDatabaseSlowOperationTimeout": {
+			op:    faults.OpDropDatabase,
+			fault: faults.Fault{Kind: faults.KindSlow, Delay: 100 * time.Millisecond},
+			f: func(ctx context.Context, collection *mongo.Collection) error {
+				return collection.Database().Drop(ctx)
+			},
+			operationTimeout: 10 * time.Millisecond,
+			injected:         1,
+		},
+		"UpdateSlowOperationTimeout": {
+			op:    faults.OpQuery,
+			fault: faults.Fault{Kind: faults.KindSlow, Delay: time.Minute},
+			f: func(ctx context.Context, collection *mongo.Collection) error {
+				_, err := collection.UpdateOne(ctx, bson.D{{"_id", "foo"}}, bson.D{{"$set", bson.D{{"v", "baz"}}}})
+				return err
+			},
+			operationTimeout: 100 * time.Millisecond,
+			err: &mongo.CommandError{
+				Code: 50,
+				Name: "MaxTimeMSExpired",
+			},
+		},
+		"DeleteSlowOperationTimeout": {
+			op:    faults.OpDeleteAll,
+			fault: faults.Fault{Kind: faults.KindSlow, Delay: time.Minute},
+			f: func(ctx context.Context, collection *mongo.Collection) error {
+				_, err := collection.DeleteOne(ctx, bson.D{{"_id", "foo"}})
+				return err
+			},
+			operationTimeout: 100 * time.Millisecond,
+			err: &mongo.CommandError{
+				Code: 50,
+				Name: "MaxTimeMSExpired",
+			},
+		},
+		"InsertTimeout": {
+			op:    faults.OpInsertAll,
+			fault: faults.Fault{Kind: faults.KindTimeout},
+			f: func(ctx context.Context, collection *mongo.Collection) error {
+				_, err := collection.InsertOne(ctx, bson.D{{"_id", "new"}})
+				return err
+			},
+			err: &mongo.CommandError{
+				Code:   89,
+				Name:   "NetworkTimeout",
+				Labels: []string{"RetryableWriteError"},
+			},
+		},
+		"UpdateSerializationFailure": {
+			op:    faults.OpUpdateAll,
+			fault: faults.Fault{Kind: faults.KindSerializationFailure},
+			f: func(ctx context.Context, collection *mongo.Collection) error {
+				_, err := collection.UpdateOne(ctx, bson.D{{"_id", "foo"}}, bson.D{{"$set", bson.D{{"v", "baz"}}}})
+				return err
+			},
+			err: &mongo.CommandError{
+				Code:   112,
+				Name:   "WriteConflict",
+				Labels: []string{"TransientTransactionError"},
+			},
+		},
+		"DeleteConnectionDrop": {
+			op:    faults.OpDeleteAll,
+			fault: faults.Fault{Kind: faults.KindConnectionDrop},
+			f: func(ctx context.Context, collection *mongo.Collection) error {
+				_, err := collection.DeleteOne(ctx, bson.D{{"_id", "foo"}})
+				return err
+			},
+			err: &mongo.CommandError{
+				Code:   6,
+				Name:   "HostUnreachable",
+				Labels: []string{"RetryableWriteError"},
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		tt.Run(name, func(tt *testing.T) {
+			tt.Parallel()
+
+			t := setup.FailsForMongoDB(tt, "faults could be injected only into FerretDB")
+
+			i := faults.NewInjector()
+
+			s := setup.SetupWithOpts(tt, &setup.SetupOpts{
+				BackendOptions: &setup.BackendOpts{
+					DisableNewAuth:   true,
+					Faults:           i,
+					OperationTimeout: tc.operationTimeout,
+				},
+			})
+			ctx, collection := s.Ctx, s.Collection
+
+			_, err := collection.InsertOne(ctx, bson.D{{"_id", "foo"}, {"v", "bar"}})
+			require.NoError(t, err)
+
+			// clear faults before setup's cleanup
+			i.Set(tc.op, tc.fault)
+			t.Cleanup(i.Clear)
+
+			err = tc.f(ctx, collection)
+
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				AssertMatchesCommandError(t, *tc.err, err)
+			}
+
+			if tc.injected != 0 {
+				assert.Equal(t, tc.injected, i.Injected(tc.op))
+			}
+		})
+	}
+}
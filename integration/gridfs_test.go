@@ -0,0 +1,127 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestGridFS(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetChunkSizeBytes(1024))
+	require.NoError(t, err)
+
+	data := make([]byte, 100*1024+42)
+	_, err = rand.New(rand.NewSource(1)).Read(data)
+	require.NoError(t, err)
+
+	id, err := bucket.UploadFromStream("file", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	t.Run("Download", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		_, err := bucket.DownloadToStream(id, &buf)
+		require.NoError(t, err)
+		assert.Equal(t, data, buf.Bytes())
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		t.Parallel()
+
+		stream, err := bucket.OpenDownloadStream(id)
+		require.NoError(t, err)
+
+		defer stream.Close()
+
+		_, err = stream.Skip(50*1024 + 7)
+		require.NoError(t, err)
+
+		actual, err := io.ReadAll(stream)
+		require.NoError(t, err)
+		assert.Equal(t, data[50*1024+7:], actual)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		t.Parallel()
+
+		filter := bson.D{{"files_id", id}, {"n", bson.D{{"$gte", 10}, {"$lt", 20}}}}
+		opts := options.Find().SetSort(bson.D{{"n", 1}}).SetBatchSize(3)
+
+		cursor, err := db.Collection("fs.chunks").Find(ctx, filter, opts)
+		require.NoError(t, err)
+
+		var chunks []struct {
+			Data primitive.Binary `bson:"data"`
+			N    int32            `bson:"n"`
+		}
+		require.NoError(t, cursor.All(ctx, &chunks))
+		require.Len(t, chunks, 10)
+
+		for i, chunk := range chunks {
+			n := int32(10 + i)
+			assert.Equal(t, n, chunk.N)
+			assert.Equal(t, data[n*1024:(n+1)*1024], chunk.Data.Data)
+		}
+	})
+}
+
+func TestGridFSChunksIndex(tt *testing.T) {
+	tt.Parallel()
+
+	ctx, collection := setup.Setup(tt)
+	chunks := collection.Database().Collection("fs.chunks")
+
+	t := setup.FailsForMongoDB(tt, "MongoDB does not create GridFS indexes on insert")
+
+	_, err := chunks.InsertOne(ctx, bson.D{{"files_id", int32(1)}, {"n", int32(0)}, {"data", primitive.Binary{Data: []byte("foo")}}})
+	require.NoError(t, err)
+
+	cursor, err := chunks.Indexes().List(ctx)
+	require.NoError(t, err)
+
+	var indexes []struct {
+		Key    bson.D `bson:"key"`
+		Name   string `bson:"name"`
+		Unique bool   `bson:"unique"`
+	}
+	require.NoError(t, cursor.All(ctx, &indexes))
+	require.Len(t, indexes, 2)
+
+	assert.Equal(t, "files_id_1_n_1", indexes[1].Name)
+	assert.Equal(t, bson.D{{"files_id", int32(1)}, {"n", int32(1)}}, indexes[1].Key)
+	assert.True(t, indexes[1].Unique)
+
+	_, err = chunks.InsertOne(ctx, bson.D{{"files_id", int32(1)}, {"n", int32(0)}, {"data", primitive.Binary{Data: []byte("bar")}}})
+	assert.True(t, mongo.IsDuplicateKeyError(err), "duplicated chunk should be rejected: %v", err)
+}
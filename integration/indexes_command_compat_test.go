@@ -42,6 +42,7 @@ func TestCreateIndexesCommandCompat(t *testing.T) {
 		indexName      any
 		key            any
 		unique         any
+		version        any
 		resultType     compatTestCaseResultType // defaults to nonEmptyResult
 
 		skip string // optional, skip test with a specified reason
@@ -114,6 +115,19 @@ func TestCreateIndexesCommandCompat(t *testing.T) {
 			unique:         bson.D{},
 			resultType:     emptyResult,
 		},
+		"Version": {
+			collectionName: "version",
+			key:            bson.D{{"v", 1}},
+			indexName:      "version",
+			version:        int32(2),
+		},
+		"InvalidVersion": {
+			collectionName: "test",
+			key:            bson.D{{"v", 1}},
+			indexName:      "test",
+			version:        int32(3),
+			resultType:     emptyResult,
+		},
 	} {
 		name, tc := name, tc
 		t.Run(name, func(t *testing.T) {
@@ -138,6 +152,10 @@ func TestCreateIndexesCommandCompat(t *testing.T) {
 				indexesDoc = append(indexesDoc, bson.E{Key: "unique", Value: tc.unique})
 			}
 
+			if tc.version != nil {
+				indexesDoc = append(indexesDoc, bson.E{Key: "v", Value: tc.version})
+			}
+
 			var targetRes bson.D
 			targetErr := targetCollection.Database().RunCommand(ctx, bson.D{
 				{"createIndexes", tc.collectionName},
@@ -21,6 +21,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 	"github.com/FerretDB/FerretDB/integration/shareddata"
@@ -62,6 +63,90 @@ func TestListIndexesCommandNonExistentNS(t *testing.T) {
 	AssertEqualCommandError(t, expected, err)
 }
 
+func TestListIndexesCommandOptions(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.Composites)
+
+	indexes := bson.A{
+		bson.D{
+			{"key", bson.D{{"foo", int32(1)}}},
+			{"name", "foo_partial"},
+			{"partialFilterExpression", bson.D{{"foo", bson.D{{"$gt", int32(0)}}}}},
+		},
+		bson.D{{"key", bson.D{{"ts", int32(1)}}}, {"name", "ts_ttl"}, {"expireAfterSeconds", int32(3600)}},
+		bson.D{{"key", bson.D{{"v", int32(1)}}}, {"name", "v_sparse"}, {"sparse", true}, {"hidden", true}},
+	}
+
+	err := collection.Database().RunCommand(ctx, bson.D{
+		{"createIndexes", collection.Name()},
+		{"indexes", indexes},
+	}).Err()
+	require.NoError(t, err)
+
+	var res bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{{"listIndexes", collection.Name()}}).Decode(&res)
+	require.NoError(t, err)
+
+	expected := []bson.D{
+		{{"v", int32(2)}, {"key", bson.D{{"_id", int32(1)}}}, {"name", "_id_"}},
+		{
+			{"v", int32(2)},
+			{"key", bson.D{{"foo", int32(1)}}},
+			{"name", "foo_partial"},
+			{"partialFilterExpression", bson.D{{"foo", bson.D{{"$gt", int32(0)}}}}},
+		},
+		{{"v", int32(2)}, {"key", bson.D{{"ts", int32(1)}}}, {"name", "ts_ttl"}, {"expireAfterSeconds", int32(3600)}},
+		{{"v", int32(2)}, {"key", bson.D{{"v", int32(1)}}}, {"name", "v_sparse"}, {"sparse", true}, {"hidden", true}},
+	}
+
+	firstBatch, ok := res.Map()["cursor"].(bson.D).Map()["firstBatch"].(bson.A)
+	require.True(t, ok)
+	require.Len(t, firstBatch, len(expected))
+
+	for i, spec := range firstBatch {
+		AssertEqualDocuments(t, expected[i], spec.(bson.D))
+	}
+}
+
+func TestListIndexesCommandBatchSize(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.Composites)
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"a", 1}}},
+		{Keys: bson.D{{"b", 1}}},
+		{Keys: bson.D{{"c", 1}}},
+		{Keys: bson.D{{"d", 1}}},
+	})
+	require.NoError(t, err)
+
+	var res bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{
+		{"listIndexes", collection.Name()},
+		{"cursor", bson.D{{"batchSize", int32(2)}}},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	cursor := res.Map()["cursor"].(bson.D).Map()
+	assert.Len(t, cursor["firstBatch"], 2)
+	assert.NotZero(t, cursor["id"])
+
+	c, err := collection.Indexes().List(ctx, options.ListIndexes().SetBatchSize(2))
+	require.NoError(t, err)
+
+	var specs []bson.D
+	require.NoError(t, c.All(ctx, &specs))
+
+	names := make([]string, len(specs))
+	for i, spec := range specs {
+		names[i] = spec.Map()["name"].(string)
+	}
+
+	assert.Equal(t, []string{"_id_", "a_1", "b_1", "c_1", "d_1"}, names)
+}
+
 func TestDropIndexesCommandErrors(t *testing.T) {
 	t.Parallel()
 
@@ -211,6 +296,47 @@ func TestDropIndexesCommandErrors(t *testing.T) {
 	}
 }
 
+func TestDropIndexesCommandPartialFailure(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.Composites)
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"v", 1}}},
+		{Keys: bson.D{{"foo", 1}}},
+	})
+	require.NoError(t, err)
+
+	err = collection.Database().RunCommand(ctx, bson.D{
+		{"dropIndexes", collection.Name()},
+		{"index", bson.A{"v_1", "non-existent", "foo_1"}},
+	}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    27,
+		Name:    "IndexNotFound",
+		Message: "index not found with name [non-existent]",
+	}, err)
+
+	// no indexes were dropped
+	specs, err := collection.Indexes().ListSpecifications(ctx)
+	require.NoError(t, err)
+	require.Len(t, specs, 3)
+
+	var res bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{
+		{"dropIndexes", collection.Name()},
+		{"index", bson.A{"v_1", "foo_1"}},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	AssertEqualDocuments(t, bson.D{{"nIndexesWas", int32(3)}, {"ok", float64(1)}}, res)
+
+	specs, err = collection.Indexes().ListSpecifications(ctx)
+	require.NoError(t, err)
+	require.Len(t, specs, 1)
+	assert.Equal(t, "_id_", specs[0].Name)
+}
+
 func TestCreateIndexesCommandInvalidSpec(t *testing.T) {
 	t.Parallel()
 
@@ -272,6 +398,20 @@ func TestCreateIndexesCommandInvalidSpec(t *testing.T) {
 				Message: "BSON field 'createIndexes.indexes.0' is the wrong type 'string', expected type 'object'",
 			},
 		},
+		"TTLCompound": {
+			indexes: bson.A{
+				bson.D{
+					{"key", bson.D{{"a", 1}, {"b", 1}}},
+					{"name", "a_1_b_1"},
+					{"expireAfterSeconds", int32(1)},
+				},
+			},
+			err: &mongo.CommandError{
+				Code:    67,
+				Name:    "CannotCreateIndex",
+				Message: "TTL indexes are single-field indexes, compound indexes do not support TTL.",
+			},
+		},
 		"IDIndex": {
 			indexes: bson.A{
 				bson.D{
@@ -544,6 +684,15 @@ func TestDropIndexesCommandInvalidCollection(t *testing.T) {
 				Message: "ns not found TestDropIndexesCommandInvalidCollection-NonExistentCollection.non-existent",
 			},
 		},
+		"NonExistentAll": {
+			collectionName: "non-existent",
+			indexName:      "*",
+			err: &mongo.CommandError{
+				Code:    26,
+				Name:    "NamespaceNotFound",
+				Message: "ns not found TestDropIndexesCommandInvalidCollection-NonExistentAll.non-existent",
+			},
+		},
 		"InvalidTypeCollection": {
 			collectionName: 42,
 			indexName:      "index",
@@ -23,6 +23,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 	"github.com/FerretDB/FerretDB/integration/shareddata"
@@ -62,7 +63,6 @@ func TestInsertCommandErrors(t *testing.T) {
 				Message: `E11000 duplicate key error collection: ` +
 					`TestInsertCommandErrors-InsertDuplicateKey.TestInsertCommandErrors-InsertDuplicateKey index: _id_ dup key: { _id: "double" }`,
 			},
-			altMessage: "E11000 duplicate key error collection: TestInsertCommandErrors-InsertDuplicateKey.TestInsertCommandErrors-InsertDuplicateKey",
 		},
 		"InsertDuplicateKeyOrdered": {
 			toInsert: []any{
@@ -76,7 +76,6 @@ func TestInsertCommandErrors(t *testing.T) {
 				Message: `E11000 duplicate key error collection: ` +
 					`TestInsertCommandErrors-InsertDuplicateKeyOrdered.TestInsertCommandErrors-InsertDuplicateKeyOrdered index: _id_ dup key: { _id: "double" }`,
 			},
-			altMessage: `E11000 duplicate key error collection: TestInsertCommandErrors-InsertDuplicateKeyOrdered.TestInsertCommandErrors-InsertDuplicateKeyOrdered`,
 		},
 		"InsertArray": {
 			toInsert: []any{
@@ -175,26 +174,111 @@ func TestInsertIDDifferentTypes(t *testing.T) {
 		{"v", "foo1"},
 	})
 
-	AssertEqualAltWriteError(t, mongo.WriteError{
+	AssertEqualWriteError(t, mongo.WriteError{
 		Message: "E11000 duplicate key error collection: TestInsertIDDifferentTypes.TestInsertIDDifferentTypes index: _id_ dup key: { _id: 1 }",
 		Code:    11000,
-	},
-		"E11000 duplicate key error collection: TestInsertIDDifferentTypes.TestInsertIDDifferentTypes",
-		err,
-	)
+	}, err)
 
 	_, err = collection.InsertOne(ctx, bson.D{
 		{"_id", float32(1)},
 		{"v", "foo3"},
 	})
 
-	AssertEqualAltWriteError(t, mongo.WriteError{
+	AssertEqualWriteError(t, mongo.WriteError{
 		Message: "E11000 duplicate key error collection: TestInsertIDDifferentTypes.TestInsertIDDifferentTypes index: _id_ dup key: { _id: 1.0 }",
 		Code:    11000,
-	},
-		"E11000 duplicate key error collection: TestInsertIDDifferentTypes.TestInsertIDDifferentTypes",
-		err,
-	)
+	}, err)
+}
+
+func TestInsertUniqueIndexDuplicateKey(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"v", -1}},
+		Options: options.Index().SetUnique(true),
+	})
+	require.NoError(t, err)
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", int32(1)}, {"v", "foo"}})
+	require.NoError(t, err)
+
+	ns := collection.Database().Name() + "." + collection.Name()
+	msg := "E11000 duplicate key error collection: " + ns + ` index: v_-1 dup key: { v: "foo" }`
+
+	t.Run("Insert", func(t *testing.T) {
+		_, err = collection.InsertMany(ctx, []any{
+			bson.D{{"_id", int32(2)}, {"v", "bar"}},
+			bson.D{{"_id", int32(3)}, {"v", "foo"}},
+		}, options.InsertMany().SetOrdered(false))
+
+		var we mongo.BulkWriteException
+		require.ErrorAs(t, err, &we)
+		require.Len(t, we.WriteErrors, 1)
+
+		actual := we.WriteErrors[0]
+		assert.Equal(t, 1, actual.Index)
+		assert.Equal(t, 11000, actual.Code)
+		assert.Equal(t, msg, actual.Message)
+
+		var raw struct {
+			KeyPattern bson.D `bson:"keyPattern"`
+			KeyValue   bson.D `bson:"keyValue"`
+		}
+		require.NoError(t, bson.Unmarshal(actual.Raw, &raw))
+
+		AssertEqualDocuments(t, bson.D{{"v", int32(-1)}}, raw.KeyPattern)
+		AssertEqualDocuments(t, bson.D{{"v", "foo"}}, raw.KeyValue)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		_, err = collection.InsertOne(ctx, bson.D{{"_id", int32(4)}, {"v", "baz"}})
+		require.NoError(t, err)
+
+		_, err = collection.UpdateOne(ctx, bson.D{{"_id", int32(4)}}, bson.D{{"$set", bson.D{{"v", "foo"}}}})
+		AssertEqualWriteError(t, mongo.WriteError{Code: 11000, Message: msg}, err)
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		models := []mongo.WriteModel{
+			mongo.NewUpdateOneModel().
+				SetFilter(bson.D{{"_id", int32(5)}}).
+				SetUpdate(bson.D{{"$set", bson.D{{"v", "qux"}}}}).
+				SetUpsert(true),
+			mongo.NewUpdateOneModel().
+				SetFilter(bson.D{{"_id", int32(2)}}).
+				SetUpdate(bson.D{{"$set", bson.D{{"v", "qux"}}}}),
+			mongo.NewUpdateOneModel().
+				SetFilter(bson.D{{"_id", int32(6)}}).
+				SetUpdate(bson.D{{"$set", bson.D{{"v", "foo"}}}}).
+				SetUpsert(true),
+		}
+
+		res, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+
+		var we mongo.BulkWriteException
+		require.ErrorAs(t, err, &we)
+		require.Len(t, we.WriteErrors, 2)
+
+		for i, index := range []int{1, 2} {
+			assert.Equal(t, index, we.WriteErrors[i].Index)
+			assert.Equal(t, 11000, we.WriteErrors[i].Code)
+		}
+
+		require.NotNil(t, res)
+		assert.Equal(t, int64(0), res.MatchedCount)
+		assert.Equal(t, int64(0), res.ModifiedCount)
+		assert.Equal(t, map[int64]any{0: int32(5)}, res.UpsertedIDs)
+
+		expected := []bson.D{
+			{{"_id", int32(1)}, {"v", "foo"}},
+			{{"_id", int32(2)}, {"v", "bar"}},
+			{{"_id", int32(4)}, {"v", "baz"}},
+			{{"_id", int32(5)}, {"v", "qux"}},
+		}
+		AssertEqualDocumentsSlice(t, expected, FindAll(t, ctx, collection))
+	})
 }
 
 func TestInsertTooLargeDocument(tt *testing.T) {
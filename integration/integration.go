@@ -190,6 +190,9 @@ func AssertEqualWriteError(t testtb.TB, expected mongo.WriteError, actual error)
 
 // AssertMatchesError asserts that both errors are of same type and
 // are equal in value, except the message and Raw part.
+//
+// In strict errors mode, messages and labels are compared too,
+// unless the test is in the allowlist; see strictErrors.
 func AssertMatchesError(t testtb.TB, expected, actual error) {
 	t.Helper()
 
@@ -206,7 +209,7 @@ func AssertMatchesError(t testtb.TB, expected, actual error) {
 }
 
 // AssertMatchesCommandError asserts that both errors are equal CommandErrors,
-// except messages (and ignoring the Raw part).
+// except messages in non-strict mode (and ignoring the Raw part).
 func AssertMatchesCommandError(t testtb.TB, expected, actual error) {
 	t.Helper()
 
@@ -220,7 +223,9 @@ func AssertMatchesCommandError(t testtb.TB, expected, actual error) {
 	e.Raw = nil
 
 	actualMessage := a.Message
-	a.Message = e.Message
+	if !strictErrors(t) {
+		a.Message = e.Message
+	}
 
 	if !AssertEqualCommandError(t, e, a) {
 		t.Logf("actual message: %s", actualMessage)
@@ -228,7 +233,7 @@ func AssertMatchesCommandError(t testtb.TB, expected, actual error) {
 }
 
 // AssertMatchesWriteError asserts that both errors are WriteExceptions containing exactly one WriteError,
-// and those WriteErrors are equal, except messages (and ignoring the Raw part).
+// and those WriteErrors are equal, except messages in non-strict mode (and ignoring the Raw part).
 func AssertMatchesWriteError(t testtb.TB, expected, actual error) {
 	t.Helper()
 
@@ -247,7 +252,11 @@ func AssertMatchesWriteError(t testtb.TB, expected, actual error) {
 	eErr.Raw = nil
 
 	actualMessage := aErr.Message
-	aErr.Message = eErr.Message
+	if !strictErrors(t) {
+		aErr.Message = eErr.Message
+	} else {
+		assert.Equal(t, e.Labels, a.Labels)
+	}
 
 	if !AssertEqualWriteError(t, eErr, aErr) {
 		t.Logf("actual message: %s", actualMessage)
@@ -255,7 +264,7 @@ func AssertMatchesWriteError(t testtb.TB, expected, actual error) {
 }
 
 // AssertMatchesBulkException asserts that both errors are BulkWriteExceptions containing the same number of WriteErrors,
-// and those WriteErrors are equal, except messages (and ignoring the Raw part).
+// and those WriteErrors are equal, except messages in non-strict mode (and ignoring the Raw part).
 //
 // TODO https://github.com/FerretDB/FerretDB/issues/3290
 func AssertMatchesBulkException(t testtb.TB, expected, actual error) {
@@ -272,10 +281,18 @@ func AssertMatchesBulkException(t testtb.TB, expected, actual error) {
 		return
 	}
 
+	strict := strictErrors(t)
+	if strict {
+		assert.Equal(t, e.Labels, a.Labels)
+	}
+
 	for i, we := range a.WriteErrors {
 		expectedWe := e.WriteErrors[i]
 
-		expectedWe.Message = we.Message
+		if !strict {
+			expectedWe.Message = we.Message
+		}
+
 		expectedWe.Raw = we.Raw
 
 		assert.Equal(t, expectedWe, we)
@@ -0,0 +1,248 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestMapReduce(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "a1"}, {"k", "a"}, {"v", int32(1)}},
+		bson.D{{"_id", "a2"}, {"k", "a"}, {"v", int32(2)}},
+		bson.D{{"_id", "b1"}, {"k", "b"}, {"v", int32(5)}},
+		bson.D{{"_id", "b2"}, {"k", "b"}},
+		bson.D{{"_id", "c1"}, {"k", "c"}, {"v", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	mapExpr := bson.D{{"key", "$k"}, {"value", "$v"}}
+	reduceExpr := bson.D{{"$sum", "$values"}}
+
+	for name, tc := range map[string]struct {
+		command  bson.D // required, without mapReduce, map, and reduce fields
+		expected bson.A // required
+	}{
+		"Inline": {
+			command: bson.D{{"out", bson.D{{"inline", int32(1)}}}},
+			expected: bson.A{
+				bson.D{{"_id", "a"}, {"value", int32(3)}},
+				bson.D{{"_id", "b"}, {"value", int32(5)}},
+				bson.D{{"_id", "c"}, {"value", int32(3)}},
+			},
+		},
+		"QuerySortLimit": {
+			command: bson.D{
+				{"out", bson.D{{"inline", int32(1)}}},
+				{"query", bson.D{{"k", bson.D{{"$ne", "c"}}}}},
+				{"sort", bson.D{{"_id", int32(-1)}}},
+				{"limit", int32(3)},
+			},
+			expected: bson.A{
+				bson.D{{"_id", "a"}, {"value", int32(2)}},
+				bson.D{{"_id", "b"}, {"value", int32(5)}},
+			},
+		},
+		"Finalize": {
+			command: bson.D{
+				{"out", bson.D{{"inline", int32(1)}}},
+				{"finalize", bson.D{{"key", "$key"}, {"total", "$value"}}},
+			},
+			expected: bson.A{
+				bson.D{{"_id", "a"}, {"value", bson.D{{"key", "a"}, {"total", int32(3)}}}},
+				bson.D{{"_id", "b"}, {"value", bson.D{{"key", "b"}, {"total", int32(5)}}}},
+				bson.D{{"_id", "c"}, {"value", bson.D{{"key", "c"}, {"total", int32(3)}}}},
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(tt *testing.T) {
+			tt.Parallel()
+
+			t := setup.FailsForMongoDB(tt, "MongoDB supports only JavaScript functions")
+
+			command := append(bson.D{
+				{"mapReduce", collection.Name()},
+				{"map", mapExpr},
+				{"reduce", reduceExpr},
+			}, tc.command...)
+
+			var res bson.D
+			err := collection.Database().RunCommand(ctx, command).Decode(&res)
+			require.NoError(t, err)
+
+			expected := bson.D{{"results", tc.expected}, {"ok", float64(1)}}
+			AssertEqualDocuments(t, expected, res)
+		})
+	}
+
+	for name, tc := range map[string]struct {
+		action   string   // required
+		existing []any    // required
+		expected []bson.D // required
+	}{
+		"Replace": {
+			action: "replace",
+			existing: []any{
+				bson.D{{"_id", "a"}, {"value", int32(100)}},
+				bson.D{{"_id", "x"}, {"value", int32(1)}},
+			},
+			expected: []bson.D{
+				{{"_id", "a"}, {"value", int32(3)}},
+				{{"_id", "b"}, {"value", int32(5)}},
+				{{"_id", "c"}, {"value", int32(3)}},
+			},
+		},
+		"Merge": {
+			action: "merge",
+			existing: []any{
+				bson.D{{"_id", "a"}, {"value", int32(100)}},
+				bson.D{{"_id", "x"}, {"value", int32(1)}},
+			},
+			expected: []bson.D{
+				{{"_id", "a"}, {"value", int32(3)}},
+				{{"_id", "b"}, {"value", int32(5)}},
+				{{"_id", "c"}, {"value", int32(3)}},
+				{{"_id", "x"}, {"value", int32(1)}},
+			},
+		},
+		"Reduce": {
+			action: "reduce",
+			existing: []any{
+				bson.D{{"_id", "a"}, {"value", int32(100)}},
+				bson.D{{"_id", "x"}, {"value", int32(1)}},
+			},
+			expected: []bson.D{
+				{{"_id", "a"}, {"value", int32(103)}},
+				{{"_id", "b"}, {"value", int32(5)}},
+				{{"_id", "c"}, {"value", int32(3)}},
+				{{"_id", "x"}, {"value", int32(1)}},
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run("Out"+name, func(tt *testing.T) {
+			tt.Parallel()
+
+			t := setup.FailsForMongoDB(tt, "MongoDB supports only JavaScript functions")
+
+			out := collection.Database().Collection(collection.Name() + "_" + tc.action)
+
+			_, err := out.InsertMany(ctx, tc.existing)
+			require.NoError(t, err)
+
+			var res bson.D
+			err = collection.Database().RunCommand(ctx, bson.D{
+				{"mapReduce", collection.Name()},
+				{"map", mapExpr},
+				{"reduce", reduceExpr},
+				{"out", bson.D{{tc.action, out.Name()}}},
+			}).Decode(&res)
+			require.NoError(t, err)
+
+			AssertEqualDocuments(t, bson.D{{"result", out.Name()}, {"ok", float64(1)}}, res)
+			assert.Equal(t, tc.expected, FindAll(t, ctx, out))
+		})
+	}
+}
+
+func TestMapReduceErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "a1"}, {"k", "a"}, {"v", int32(1)}})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		command bson.D              // required, without mapReduce field
+		err     *mongo.CommandError // required
+	}{
+		"JavaScript": {
+			command: bson.D{
+				{"map", "function() { emit(this.k, this.v) }"},
+				{"reduce", "function(k, vs) { return Array.sum(vs) }"},
+				{"out", bson.D{{"inline", int32(1)}}},
+			},
+			err: &mongo.CommandError{
+				Code:    238,
+				Name:    "NotImplemented",
+				Message: "mapReduce with JavaScript 'map' function is not implemented yet; use an aggregation expression",
+			},
+		},
+		"MapWithoutValue": {
+			command: bson.D{
+				{"map", bson.D{{"key", "$k"}}},
+				{"reduce", bson.D{{"$sum", "$values"}}},
+				{"out", bson.D{{"inline", int32(1)}}},
+			},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "'map' expression must be a document with 'key' and 'value' fields",
+			},
+		},
+		"OutWithoutAction": {
+			command: bson.D{
+				{"map", bson.D{{"key", "$k"}, {"value", "$v"}}},
+				{"reduce", bson.D{{"$sum", "$values"}}},
+				{"out", bson.D{{"db", "test"}}},
+			},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "'out' must specify one of 'inline', 'replace', 'merge', or 'reduce'",
+			},
+		},
+		"InlineWithOptions": {
+			command: bson.D{
+				{"map", bson.D{{"key", "$k"}, {"value", "$v"}}},
+				{"reduce", bson.D{{"$sum", "$values"}}},
+				{"out", bson.D{{"inline", int32(1)}, {"db", "test"}}},
+			},
+			err: &mongo.CommandError{
+				Code:    72,
+				Name:    "InvalidOptions",
+				Message: "If 'inline' is specified in 'out', no other options are allowed",
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(tt *testing.T) {
+			tt.Parallel()
+
+			t := setup.FailsForMongoDB(tt, "MongoDB supports only JavaScript functions")
+
+			command := append(bson.D{{"mapReduce", collection.Name()}}, tc.command...)
+
+			err := collection.Database().RunCommand(ctx, command).Err()
+			AssertEqualCommandError(t, *tc.err, err)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongotools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration"
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// runTool runs the given MongoDB Database Tools binary with given arguments.
+//
+// The test is skipped if the binary is not found in $PATH.
+func runTool(t *testing.T, ctx context.Context, name string, args ...string) {
+	t.Helper()
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		t.Skipf("%s not found: %s", name, err)
+	}
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	b, err := cmd.CombinedOutput()
+	t.Logf("%s %v:\n%s", name, args, b)
+	require.NoError(t, err)
+}
+
+// listIndexes returns index specifications of the given collection without fields
+// that are expected to differ between collections.
+func listIndexes(t *testing.T, ctx context.Context, coll *mongo.Collection) []bson.D {
+	t.Helper()
+
+	cursor, err := coll.Indexes().List(ctx)
+	require.NoError(t, err)
+
+	indexes := integration.FetchAll(t, ctx, cursor)
+
+	for i, index := range indexes {
+		res := make(bson.D, 0, len(index))
+
+		for _, e := range index {
+			if e.Key != "ns" {
+				res = append(res, e)
+			}
+		}
+
+		indexes[i] = res
+	}
+
+	return indexes
+}
+
+// collectionSpec returns listCollections specification of the given collection.
+func collectionSpec(t *testing.T, ctx context.Context, coll *mongo.Collection) *mongo.CollectionSpecification {
+	t.Helper()
+
+	specs, err := coll.Database().ListCollectionSpecifications(ctx, bson.D{{"name", coll.Name()}})
+	require.NoError(t, err)
+	require.Len(t, specs, 1)
+
+	return specs[0]
+}
+
+func TestDumpRestore(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, &setup.SetupOpts{
+		Providers: []shareddata.Provider{shareddata.Scalars, shareddata.Composites},
+	})
+	ctx, coll := s.Ctx, s.Collection
+	db := coll.Database()
+
+	_, err := coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"v", 1}}},
+		{Keys: bson.D{{"v", -1}, {"foo", 1}}, Options: options.Index().SetName("custom")},
+	})
+	require.NoError(t, err)
+
+	cappedName := coll.Name() + "_capped"
+	opts := options.CreateCollection().SetCapped(true).SetSizeInBytes(4096).SetMaxDocuments(10)
+	require.NoError(t, db.CreateCollection(ctx, cappedName, opts))
+
+	capped := db.Collection(cappedName)
+	_, err = capped.InsertMany(ctx, []any{bson.D{{"_id", int32(1)}}, bson.D{{"_id", int32(2)}}})
+	require.NoError(t, err)
+
+	_, err = db.Collection(coll.Name()+"_unique").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"email", 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	require.NoError(t, err)
+
+	out := t.TempDir()
+
+	// point-in-time dump without --oplog
+	runTool(t, ctx, "mongodump", "--uri="+s.MongoDBURI, "--db="+db.Name(), "--out="+out)
+
+	t.Run("Metadata", func(t *testing.T) {
+		b, err := os.ReadFile(filepath.Join(out, db.Name(), coll.Name()+".metadata.json"))
+		require.NoError(t, err)
+
+		var metadata struct {
+			UUID    string           `json:"uuid"`
+			Indexes []map[string]any `json:"indexes"`
+		}
+		require.NoError(t, json.Unmarshal(b, &metadata))
+
+		assert.Len(t, metadata.UUID, 32, "collection UUID should be dumped")
+		assert.Len(t, metadata.Indexes, 3)
+	})
+
+	restoredDB := db.Client().Database(db.Name() + "_restored")
+	t.Cleanup(func() {
+		require.NoError(t, restoredDB.Drop(context.Background()))
+	})
+
+	// restore without --preserveUUID does not use applyOps
+	runTool(
+		t, ctx, "mongorestore",
+		"--uri="+s.MongoDBURI,
+		"--nsFrom="+db.Name()+".*", "--nsTo="+restoredDB.Name()+".*",
+		"--drop",
+		out,
+	)
+
+	for _, name := range []string{coll.Name(), cappedName, coll.Name() + "_unique"} {
+		t.Run(name, func(t *testing.T) {
+			expected, actual := db.Collection(name), restoredDB.Collection(name)
+
+			integration.AssertEqualDocumentsSlice(
+				t,
+				integration.FindAll(t, ctx, expected),
+				integration.FindAll(t, ctx, actual),
+			)
+
+			assert.Equal(t, listIndexes(t, ctx, expected), listIndexes(t, ctx, actual))
+
+			expectedSpec, actualSpec := collectionSpec(t, ctx, expected), collectionSpec(t, ctx, actual)
+			assert.Equal(t, expectedSpec.Options, actualSpec.Options)
+			assert.NotNil(t, actualSpec.UUID)
+			assert.NotEqual(t, expectedSpec.UUID, actualSpec.UUID, "restored collection should get a new UUID")
+		})
+	}
+}
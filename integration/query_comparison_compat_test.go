@@ -1154,6 +1154,14 @@ func TestQueryComparisonCompatIn(t *testing.T) {
 		"ForCompositeDataTypes": {
 			filter: bson.D{{"v", bson.D{{"$in", compositeDataTypesFilter}}}},
 		},
+		"ID": {
+			filter:         bson.D{{"_id", bson.D{{"$in", bson.A{"string", "int32", "non-existent"}}}}},
+			resultPushdown: allPushdown,
+		},
+		"IDMixed": {
+			filter:         bson.D{{"_id", bson.D{{"$in", bson.A{"string", int32(42)}}}}},
+			resultPushdown: pgPushdown,
+		},
 		"RegexString": {
 			filter:     bson.D{{"v", bson.D{{"$in", bson.A{bson.D{{"$regex", "/foo/"}}}}}}},
 			resultType: emptyResult,
@@ -1411,6 +1419,7 @@ func TestQueryComparisonCompatMultipleOperators(t *testing.T) {
 				{"_id", bson.D{{"$in", bson.A{"int32"}}}},
 				{"v", bson.D{{"$lte", int32(42)}, {"$gte", int32(0)}}},
 			},
+			resultPushdown: pgPushdown,
 		},
 		"NinEqNe": {
 			filter: bson.D{
@@ -506,17 +506,34 @@ func TestQueryCompatSort(t *testing.T) {
 func TestQueryCompatSortDotNotation(t *testing.T) {
 	t.Parallel()
 
-	providers := shareddata.AllProviders().
-		// TODO https://github.com/FerretDB/FerretDB/issues/2618
-		Remove(shareddata.ArrayDocuments)
-
 	testCases := map[string]queryCompatTestCase{
 		"DotNotation": {
 			filter: bson.D{},
 			sort:   bson.D{{"v.foo", 1}, {"_id", 1}},
 		},
+		"DotNotationDesc": {
+			filter: bson.D{},
+			sort:   bson.D{{"v.foo", -1}, {"_id", 1}},
+		},
+		"DotNotationArrayIndex": {
+			filter: bson.D{},
+			sort:   bson.D{{"v.0", 1}, {"_id", 1}},
+		},
+		"DotNotationArrayIndexDesc": {
+			filter: bson.D{},
+			sort:   bson.D{{"v.0", -1}, {"_id", 1}},
+		},
+		"DotNotationNested": {
+			filter: bson.D{},
+			sort:   bson.D{{"v.foo.bar", 1}, {"_id", 1}},
+		},
+		"DotNotationNestedDesc": {
+			filter: bson.D{},
+			sort:   bson.D{{"v.foo.bar", -1}, {"_id", 1}},
+		},
 	}
-	testQueryCompatWithProviders(t, providers, testCases)
+
+	testQueryCompat(t, testCases)
 }
 
 func TestQueryCompatSkip(t *testing.T) {
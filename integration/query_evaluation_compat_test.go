@@ -388,7 +388,62 @@ func TestQueryEvaluationCompatExpr(t *testing.T) {
 		},
 		"Gt": {
 			filter: bson.D{{"$expr", bson.D{{"$gt", bson.A{"$v", 2}}}}},
-			skip:   "https://github.com/FerretDB/FerretDB/issues/1456",
+		},
+		"Gte": {
+			filter: bson.D{{"$expr", bson.D{{"$gte", bson.A{"$v", int32(42)}}}}},
+		},
+		"Lt": {
+			filter: bson.D{{"$expr", bson.D{{"$lt", bson.A{"$v", int64(42)}}}}},
+		},
+		"Lte": {
+			filter: bson.D{{"$expr", bson.D{{"$lte", bson.A{"$v", 42.0}}}}},
+		},
+		"Eq": {
+			filter: bson.D{{"$expr", bson.D{{"$eq", bson.A{"$v", int32(42)}}}}},
+		},
+		"Ne": {
+			filter: bson.D{{"$expr", bson.D{{"$ne", bson.A{"$v", "foo"}}}}},
+		},
+		"Cmp": {
+			filter: bson.D{{"$expr", bson.D{{"$eq", bson.A{bson.D{{"$cmp", bson.A{"$v", nil}}}, int32(1)}}}}},
+		},
+		"CompareFields": {
+			filter: bson.D{{"$expr", bson.D{{"$lt", bson.A{"$v", "$_id"}}}}},
+		},
+		"CompareMissing": {
+			filter: bson.D{{"$expr", bson.D{{"$lt", bson.A{"$non-existent", nil}}}}},
+		},
+		"And": {
+			filter: bson.D{{"$expr", bson.D{{"$and", bson.A{
+				bson.D{{"$gte", bson.A{"$v", int32(0)}}},
+				bson.D{{"$lte", bson.A{"$v", int32(42)}}},
+			}}}}},
+		},
+		"Or": {
+			filter: bson.D{{"$expr", bson.D{{"$or", bson.A{
+				bson.D{{"$eq", bson.A{"$v", "foo"}}},
+				bson.D{{"$eq", bson.A{"$v", false}}},
+			}}}}},
+		},
+		"Not": {
+			filter: bson.D{{"$expr", bson.D{{"$not", bson.A{"$v"}}}}},
+		},
+		"InOr": {
+			filter: bson.D{{"$or", bson.A{
+				bson.D{{"$expr", bson.D{{"$eq", bson.A{"$v", int32(42)}}}}},
+				bson.D{{"_id", "string"}},
+			}}},
+		},
+		"InNor": {
+			filter: bson.D{{"$nor", bson.A{
+				bson.D{{"$expr", bson.D{{"$gt", bson.A{"$v", int32(0)}}}}},
+			}}},
+		},
+		"WithField": {
+			filter: bson.D{
+				{"_id", bson.D{{"$type", "string"}}},
+				{"$expr", bson.D{{"$ne", bson.A{"$v", nil}}}},
+			},
 		},
 	}
 
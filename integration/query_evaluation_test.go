@@ -189,7 +189,6 @@ func TestQueryEvaluationExprErrors(t *testing.T) {
 				Name:    "Location16020",
 				Message: "Expression $gt takes exactly 2 arguments. 1 were passed in.",
 			},
-			skip: "https://github.com/FerretDB/FerretDB/issues/1456",
 		},
 		"GtOneParameter": {
 			filter: bson.D{{"$expr", bson.D{{"$gt", bson.A{1}}}}},
@@ -198,7 +197,22 @@ func TestQueryEvaluationExprErrors(t *testing.T) {
 				Name:    "Location16020",
 				Message: "Expression $gt takes exactly 2 arguments. 1 were passed in.",
 			},
-			skip: "https://github.com/FerretDB/FerretDB/issues/1456",
+		},
+		"NotTwoParameters": {
+			filter: bson.D{{"$expr", bson.D{{"$not", bson.A{1, 2}}}}},
+			err: &mongo.CommandError{
+				Code:    16020,
+				Name:    "Location16020",
+				Message: "Expression $not takes exactly 1 arguments. 2 were passed in.",
+			},
+		},
+		"ElemMatch": {
+			filter: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"$expr", true}}}}}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "$expr can only be applied to the top-level document",
+			},
 		},
 		"GtThreeParameters": {
 			filter: bson.D{{"$expr", bson.D{{"$gt", bson.A{1, 2, 3}}}}},
@@ -207,7 +221,6 @@ func TestQueryEvaluationExprErrors(t *testing.T) {
 				Name:    "Location16020",
 				Message: "Expression $gt takes exactly 2 arguments. 3 were passed in.",
 			},
-			skip: "https://github.com/FerretDB/FerretDB/issues/1456",
 		},
 	} {
 		name, tc := name, tc
@@ -225,3 +238,70 @@ func TestQueryEvaluationExprErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryEvaluationExprCommands(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"a", int32(1)}, {"b", int32(2)}},
+		bson.D{{"_id", int32(2)}, {"a", int64(3)}, {"b", 2.5}},
+		bson.D{{"_id", int32(3)}, {"a", "foo"}},
+	})
+	require.NoError(t, err)
+
+	// missing b is less than any a
+	filter := bson.D{{"$expr", bson.D{{"$gt", bson.A{"$a", "$b"}}}}}
+
+	findIDs := func(t *testing.T, filter bson.D) []any {
+		t.Helper()
+
+		cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{"_id", 1}}))
+		require.NoError(t, err)
+
+		var res []bson.D
+		require.NoError(t, cursor.All(ctx, &res))
+
+		return CollectIDs(t, res)
+	}
+
+	// subtests modify the collection, so they are not parallel
+
+	t.Run("Find", func(t *testing.T) {
+		assert.Equal(t, []any{int32(2), int32(3)}, findIDs(t, filter))
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		n, err := collection.CountDocuments(ctx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		res, err := collection.UpdateMany(ctx, filter, bson.D{{"$set", bson.D{{"c", int32(1)}}}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), res.ModifiedCount)
+
+		assert.Equal(t, []any{int32(2), int32(3)}, findIDs(t, bson.D{{"c", int32(1)}}))
+	})
+
+	t.Run("FindAndModify", func(t *testing.T) {
+		var res bson.D
+		err := collection.FindOneAndUpdate(
+			ctx,
+			bson.D{{"$expr", bson.D{{"$lt", bson.A{"$a", "$b"}}}}},
+			bson.D{{"$set", bson.D{{"c", int32(0)}}}},
+		).Decode(&res)
+		require.NoError(t, err)
+		AssertEqualDocuments(t, bson.D{{"_id", int32(1)}, {"a", int32(1)}, {"b", int32(2)}}, res)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		res, err := collection.DeleteMany(ctx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), res.DeletedCount)
+
+		assert.Equal(t, []any{int32(1)}, findIDs(t, bson.D{}))
+	})
+}
@@ -353,3 +353,107 @@ func TestQueryProjectionPositionalOperatorCompat(t *testing.T) {
 
 	testQueryCompatWithProviders(t, providers, testCases)
 }
+
+func TestQueryProjectionSliceCompat(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]queryCompatTestCase{
+		"Count": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", int32(2)}}}},
+		},
+		"CountZero": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", int32(0)}}}},
+		},
+		"CountNegative": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", int32(-2)}}}},
+		},
+		"CountDouble": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", 1.5}}}},
+		},
+		"SkipLimit": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{int32(1), int32(2)}}}}},
+		},
+		"SkipNegativeLimit": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{int32(-2), int32(1)}}}}},
+		},
+		"SkipTooLarge": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{int32(100), int32(1)}}}}},
+		},
+		"WithInclusion": {
+			filter:     bson.D{},
+			projection: bson.D{{"_id", true}, {"v", bson.D{{"$slice", int32(1)}}}, {"foo", true}},
+		},
+		"WithExclusion": {
+			filter:     bson.D{},
+			projection: bson.D{{"foo", false}, {"v", bson.D{{"$slice", int32(-1)}}}},
+		},
+		"DotNotation": {
+			filter:     bson.D{},
+			projection: bson.D{{"v.array", bson.D{{"$slice", int32(1)}}}},
+		},
+		"LimitZero": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{int32(1), int32(0)}}}}},
+			resultType: emptyResult,
+		},
+		"InvalidArray": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{int32(1)}}}}},
+			resultType: emptyResult,
+		},
+		"SkipString": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{"foo", int32(1)}}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testQueryCompat(t, testCases)
+}
+
+func TestQueryProjectionElemMatchCompat(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]queryCompatTestCase{
+		"Operator": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"$gte", int32(42)}}}}}},
+		},
+		"Field": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"foo", int32(42)}}}}}},
+		},
+		"NoMatch": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"foo", "non-existent"}}}}}},
+		},
+		"WithInclusion": {
+			filter:     bson.D{},
+			projection: bson.D{{"_id", false}, {"v", bson.D{{"$elemMatch", bson.D{{"$lt", int32(42)}}}}}, {"foo", true}},
+		},
+		"NestedField": {
+			filter:     bson.D{},
+			projection: bson.D{{"v.foo", bson.D{{"$elemMatch", bson.D{{"$lt", int32(42)}}}}}},
+			resultType: emptyResult,
+		},
+		"NotDocument": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$elemMatch", int32(42)}}}},
+			resultType: emptyResult,
+		},
+		"WithPositional": {
+			filter:     bson.D{{"v", int32(42)}},
+			projection: bson.D{{"v.$", true}, {"foo", bson.D{{"$elemMatch", bson.D{{"$lt", int32(42)}}}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testQueryCompat(t, testCases)
+}
@@ -58,6 +58,33 @@ func TestQueryProjectionErrors(t *testing.T) {
 				Message: "FieldPath field names may not be empty strings.",
 			},
 		},
+		"MetaInvalid": {
+			filter:     bson.D{},
+			projection: bson.D{{"meta", bson.D{{"$meta", "foo"}}}},
+			err: &mongo.CommandError{
+				Code:    17308,
+				Name:    "Location17308",
+				Message: "Unsupported argument to $meta: foo",
+			},
+		},
+		"MetaNotString": {
+			filter:     bson.D{},
+			projection: bson.D{{"meta", bson.D{{"$meta", int32(1)}}}},
+			err: &mongo.CommandError{
+				Code:    17307,
+				Name:    "Location17307",
+				Message: "$meta only supports string arguments",
+			},
+		},
+		"MetaTextScoreWithoutText": {
+			filter:     bson.D{},
+			projection: bson.D{{"score", bson.D{{"$meta", "textScore"}}}},
+			err: &mongo.CommandError{
+				Code:    40218,
+				Name:    "Location40218",
+				Message: "query requires text score metadata, but it is not available",
+			},
+		},
 		"ExcludeInclude": {
 			filter:     bson.D{},
 			projection: bson.D{{"foo", false}, {"bar", true}},
@@ -295,3 +322,72 @@ func TestQueryProjectionSuccess(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryProjectionMeta(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"v", int32(42)}, {"foo", "bar"}},
+		bson.D{{"_id", int32(2)}, {"foo", "baz"}},
+	})
+	require.NoError(t, err)
+
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{"v", int32(1)}}})
+	require.NoError(t, err)
+
+	t.Run("IndexKey", func(t *testing.T) {
+		t.Parallel()
+
+		opts := options.Find().
+			SetProjection(bson.D{{"foo", int32(1)}, {"key", bson.D{{"$meta", "indexKey"}}}}).
+			SetHint(bson.D{{"v", int32(1)}}).
+			SetSort(bson.D{{"_id", int32(1)}})
+
+		cursor, err := collection.Find(ctx, bson.D{}, opts)
+		require.NoError(t, err)
+
+		var res []bson.D
+		require.NoError(t, cursor.All(ctx, &res))
+
+		expected := []bson.D{
+			{{"_id", int32(1)}, {"foo", "bar"}, {"key", bson.D{{"v", int32(42)}}}},
+			{{"_id", int32(2)}, {"foo", "baz"}, {"key", bson.D{{"v", nil}}}},
+		}
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("IndexKeyCollectionScan", func(t *testing.T) {
+		t.Parallel()
+
+		opts := options.Find().
+			SetProjection(bson.D{{"_id", int32(0)}, {"key", bson.D{{"$meta", "indexKey"}}}}).
+			SetHint(bson.D{{"$natural", int32(1)}})
+
+		cursor, err := collection.Find(ctx, bson.D{{"foo", "bar"}}, opts)
+		require.NoError(t, err)
+
+		var res []bson.D
+		require.NoError(t, cursor.All(ctx, &res))
+
+		expected := []bson.D{
+			{{"v", int32(42)}, {"foo", "bar"}},
+		}
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("SortTextScore", func(t *testing.T) {
+		t.Parallel()
+
+		opts := options.Find().SetSort(bson.D{{"score", bson.D{{"$meta", "textScore"}}}})
+
+		_, err := collection.Find(ctx, bson.D{}, opts)
+		expected := mongo.CommandError{
+			Code:    40218,
+			Name:    "Location40218",
+			Message: "query requires text score metadata, but it is not available",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+}
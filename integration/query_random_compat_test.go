@@ -0,0 +1,78 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+func TestQueryCompatRandom(t *testing.T) {
+	t.Parallel()
+
+	providers := shareddata.Providers{
+		shareddata.NewRandomDocuments(&shareddata.RandomDocumentsOpts{
+			Seed:          1,
+			Docs:          500,
+			Fields:        3,
+			FieldsPercent: 50,
+		}),
+		shareddata.NewRandomDocuments(&shareddata.RandomDocumentsOpts{
+			Seed: 2,
+			Docs: 100,
+		}),
+	}
+
+	testCases := map[string]queryCompatTestCase{
+		"All": {
+			filter: bson.D{},
+		},
+		"Exists": {
+			filter: bson.D{{"f0", bson.D{{"$exists", true}}}},
+		},
+		"NotExists": {
+			filter: bson.D{{"f1", bson.D{{"$exists", false}}}},
+		},
+		"TypeString": {
+			filter: bson.D{{"v", bson.D{{"$type", "string"}}}},
+		},
+		"TypeNumber": {
+			filter: bson.D{{"v", bson.D{{"$type", "number"}}}},
+		},
+		"Gt": {
+			filter: bson.D{{"v", bson.D{{"$gt", int32(0)}}}},
+		},
+		"DotNotation": {
+			filter: bson.D{{"v.a", bson.D{{"$exists", true}}}},
+		},
+		"Sort": {
+			filter: bson.D{},
+			sort:   bson.D{{"v", int32(1)}, {"_id", int32(1)}},
+		},
+		"SortOptional": {
+			filter: bson.D{},
+			sort:   bson.D{{"f2", int32(-1)}, {"_id", int32(1)}},
+		},
+		"Projection": {
+			filter:     bson.D{},
+			projection: bson.D{{"f0", int32(1)}, {"f2", int32(1)}},
+		},
+	}
+
+	testQueryCompatWithProviders(t, providers, testCases)
+}
@@ -16,6 +16,7 @@ package integration
 
 import (
 	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -985,3 +986,412 @@ func TestQueryShowRecordIDErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryCollation(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "b"}, {"v", "b"}},
+		bson.D{{"_id", "c"}, {"v", "C"}},
+		bson.D{{"_id", "a"}, {"v", "a"}},
+	})
+	require.NoError(t, err)
+
+	sort := bson.D{{"v", 1}}
+
+	t.Run("Simple", func(t *testing.T) {
+		t.Parallel()
+
+		cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(sort))
+		require.NoError(t, err)
+
+		expected := []bson.D{
+			{{"_id", "c"}, {"v", "C"}},
+			{{"_id", "a"}, {"v", "a"}},
+			{{"_id", "b"}, {"v", "b"}},
+		}
+		AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+	})
+
+	collation := &options.Collation{Locale: "en", Strength: 2}
+
+	expected := []bson.D{
+		{{"_id", "a"}, {"v", "a"}},
+		{{"_id", "b"}, {"v", "b"}},
+		{{"_id", "c"}, {"v", "C"}},
+	}
+
+	t.Run("Find", func(t *testing.T) {
+		t.Parallel()
+
+		cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(sort).SetCollation(collation))
+		require.NoError(t, err)
+
+		AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := bson.A{bson.D{{"$sort", sort}}}
+
+		cursor, err := collection.Aggregate(ctx, pipeline, options.Aggregate().SetCollation(collation))
+		require.NoError(t, err)
+
+		AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+	})
+
+	t.Run("FilterSimple", func(t *testing.T) {
+		t.Parallel()
+
+		cursor, err := collection.Find(ctx, bson.D{{"v", "c"}})
+		require.NoError(t, err)
+
+		AssertEqualDocumentsSlice(t, []bson.D{}, FetchAll(t, ctx, cursor))
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		t.Parallel()
+
+		cursor, err := collection.Find(ctx, bson.D{{"v", "c"}}, options.Find().SetCollation(collation))
+		require.NoError(t, err)
+
+		AssertEqualDocumentsSlice(t, []bson.D{{{"_id", "c"}, {"v", "C"}}}, FetchAll(t, ctx, cursor))
+	})
+
+	t.Run("FilterOperator", func(t *testing.T) {
+		t.Parallel()
+
+		filter := bson.D{{"v", bson.D{{"$gt", "B"}}}}
+
+		cursor, err := collection.Find(ctx, filter, options.Find().SetSort(sort).SetCollation(collation))
+		require.NoError(t, err)
+
+		AssertEqualDocumentsSlice(t, []bson.D{{{"_id", "c"}, {"v", "C"}}}, FetchAll(t, ctx, cursor))
+	})
+
+	t.Run("AggregateMatch", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := bson.A{bson.D{{"$match", bson.D{{"v", "c"}}}}}
+
+		cursor, err := collection.Aggregate(ctx, pipeline, options.Aggregate().SetCollation(collation))
+		require.NoError(t, err)
+
+		AssertEqualDocumentsSlice(t, []bson.D{{{"_id", "c"}, {"v", "C"}}}, FetchAll(t, ctx, cursor))
+	})
+
+	t.Run("InvalidLocale", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := collection.Find(ctx, bson.D{}, options.Find().SetCollation(&options.Collation{Strength: 2}))
+		require.Error(t, err)
+	})
+}
+
+func TestQuerySortAllowDiskUse(t *testing.T) {
+	t.Parallel()
+
+	limit := 64 * 1024
+	s := setup.SetupWithOpts(t, &setup.SetupOpts{BackendOptions: &setup.BackendOpts{SortMemoryLimitBytes: limit}})
+	ctx, collection := s.Ctx, s.Collection
+
+	// documents take about four times more than the limit
+	docs := make([]any, 256)
+	for i := range docs {
+		docs[i] = bson.D{{"_id", int32(i)}, {"v", strings.Repeat("x", 1024)}}
+	}
+
+	_, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	sort := bson.D{{"_id", -1}}
+
+	t.Run("Find", func(t *testing.T) {
+		t.Parallel()
+
+		cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(sort).SetAllowDiskUse(true))
+		require.NoError(t, err)
+
+		res := FetchAll(t, ctx, cursor)
+		require.Len(t, res, len(docs))
+
+		for i, doc := range res {
+			assert.Equal(t, int32(len(docs)-1-i), doc[0].Value)
+		}
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := bson.A{bson.D{{"$sort", sort}}, bson.D{{"$limit", 1}}}
+
+		cursor, err := collection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+		require.NoError(t, err)
+
+		expected := []bson.D{docs[len(docs)-1].(bson.D)}
+		AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+	})
+
+	t.Run("NoDiskUse", func(tt *testing.T) {
+		tt.Parallel()
+
+		t := setup.FailsForMongoDB(tt, "sort memory limit is only configurable for FerretDB")
+
+		_, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(sort).SetAllowDiskUse(false))
+
+		expected := mongo.CommandError{
+			Code: 292,
+			Name: "QueryExceededMemoryLimitNoDiskUseAllowed",
+			Message: "Sort exceeded memory limit of 65536 bytes, " +
+				"but did not opt in to external sorting.",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+
+	t.Run("Explain", func(tt *testing.T) {
+		tt.Parallel()
+
+		t := setup.FailsForMongoDB(tt, "sort memory limit is only configurable for FerretDB")
+
+		var res bson.D
+		err := collection.Database().RunCommand(ctx, bson.D{
+			{"explain", bson.D{
+				{"find", collection.Name()},
+				{"sort", sort},
+			}},
+			{"verbosity", "executionStats"},
+		}).Decode(&res)
+		require.NoError(t, err)
+
+		stats, ok := res.Map()["executionStats"].(bson.D)
+		require.True(t, ok)
+		assert.Equal(t, int32(len(docs)), stats.Map()["nReturned"])
+
+		stage, ok := stats.Map()["executionStages"].(bson.D)
+		require.True(t, ok)
+		assert.Equal(t, "SORT", stage.Map()["stage"])
+		assert.Equal(t, true, stage.Map()["usedDisk"])
+	})
+}
+
+func TestQueryHint(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{"v", 1}}})
+	require.NoError(t, err)
+
+	docs := []any{
+		bson.D{{"_id", int32(1)}, {"v", int32(3)}},
+		bson.D{{"_id", int32(2)}, {"v", int32(2)}},
+		bson.D{{"_id", int32(3)}, {"v", int32(1)}},
+	}
+
+	_, err = collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		hint any // required
+
+		err *mongo.CommandError // optional, expected error
+	}{
+		"Name": {
+			hint: "v_1",
+		},
+		"KeyPattern": {
+			hint: bson.D{{"v", 1}},
+		},
+		"Natural": {
+			hint: bson.D{{"$natural", -1}},
+		},
+		"NameNotFound": {
+			hint: "foo_1",
+			err: &mongo.CommandError{
+				Code: 2,
+				Name: "BadValue",
+				Message: "error processing query: planner returned error :: caused by :: " +
+					"hint provided does not correspond to an existing index",
+			},
+		},
+		"KeyPatternNotFound": {
+			hint: bson.D{{"v", -1}},
+			err: &mongo.CommandError{
+				Code: 2,
+				Name: "BadValue",
+				Message: "error processing query: planner returned error :: caused by :: " +
+					"hint provided does not correspond to an existing index",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := options.Find().SetHint(tc.hint).SetSort(bson.D{{"_id", 1}})
+			cursor, err := collection.Find(ctx, bson.D{{"v", bson.D{{"$gt", int32(0)}}}}, opts)
+
+			if tc.err != nil {
+				AssertMatchesCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			expected := []bson.D{docs[0].(bson.D), docs[1].(bson.D), docs[2].(bson.D)}
+			AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+		})
+	}
+}
+
+func TestQueryHintNaturalExplain(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.Scalars)
+
+	for name, tc := range map[string]struct {
+		hint      bson.D
+		direction string
+	}{
+		"Asc": {
+			hint:      bson.D{{"$natural", int32(1)}},
+			direction: "forward",
+		},
+		"Desc": {
+			hint:      bson.D{{"$natural", int32(-1)}},
+			direction: "backward",
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var res bson.D
+			err := collection.Database().RunCommand(ctx, bson.D{
+				{"explain", bson.D{
+					{"find", collection.Name()},
+					{"filter", bson.D{{"v", int32(42)}}},
+					{"hint", tc.hint},
+				}},
+			}).Decode(&res)
+			require.NoError(t, err)
+
+			queryPlanner, ok := res.Map()["queryPlanner"].(bson.D)
+			require.True(t, ok)
+
+			winningPlan, ok := queryPlanner.Map()["winningPlan"].(bson.D)
+			require.True(t, ok)
+
+			assert.Equal(t, "COLLSCAN", winningPlan.Map()["stage"])
+			assert.Equal(t, tc.direction, winningPlan.Map()["direction"])
+		})
+	}
+}
+
+func TestQuerySortNatural(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	// _id values are not in the insertion order
+	docs := []bson.D{
+		{{"_id", int32(3)}, {"v", "foo"}},
+		{{"_id", int32(1)}, {"v", "bar"}},
+		{{"_id", int32(2)}, {"v", "baz"}},
+	}
+
+	_, err := collection.InsertMany(ctx, []any{docs[0], docs[1], docs[2]})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		opts *options.FindOptions // required
+
+		expected []bson.D // required
+	}{
+		"Asc": {
+			opts:     options.Find().SetSort(bson.D{{"$natural", int32(1)}}),
+			expected: []bson.D{docs[0], docs[1], docs[2]},
+		},
+		"Desc": {
+			opts:     options.Find().SetSort(bson.D{{"$natural", int32(-1)}}),
+			expected: []bson.D{docs[2], docs[1], docs[0]},
+		},
+		"DescLimit": {
+			opts:     options.Find().SetSort(bson.D{{"$natural", int32(-1)}}).SetLimit(2),
+			expected: []bson.D{docs[2], docs[1]},
+		},
+		"HintDesc": {
+			opts:     options.Find().SetHint(bson.D{{"$natural", int32(-1)}}),
+			expected: []bson.D{docs[2], docs[1], docs[0]},
+		},
+		"HintDescLimit": {
+			opts:     options.Find().SetHint(bson.D{{"$natural", int32(-1)}}).SetLimit(1),
+			expected: []bson.D{docs[2]},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Find(ctx, bson.D{}, tc.opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, FetchAll(t, ctx, cursor))
+		})
+	}
+}
+
+func TestQueryPlanCache(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{"v", 1}}})
+	require.NoError(t, err)
+
+	// MongoDB caches a plan only if there are several candidate plans
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{"v", 1}, {"w", 1}}})
+	require.NoError(t, err)
+
+	_, err = collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"v", int32(1)}, {"w", int32(1)}},
+		bson.D{{"_id", int32(2)}, {"v", int32(2)}, {"w", int32(2)}},
+	})
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err = collection.Find(ctx, bson.D{{"v", int32(1)}})
+		require.NoError(t, err)
+	}
+
+	pipeline := bson.A{bson.D{{"$planCacheStats", bson.D{}}}}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	require.NoError(t, err)
+
+	res := FetchAll(t, ctx, cursor)
+	require.NotEmpty(t, res)
+	assert.NotEmpty(t, res[0].Map()["planCacheKey"])
+
+	err = collection.Database().RunCommand(ctx, bson.D{{"planCacheClear", collection.Name()}}).Err()
+	require.NoError(t, err)
+
+	cursor, err = collection.Aggregate(ctx, pipeline)
+	require.NoError(t, err)
+	assert.Empty(t, FetchAll(t, ctx, cursor))
+
+	t.Run("NotFirstStage", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := collection.Aggregate(ctx, bson.A{bson.D{{"$match", bson.D{}}}, pipeline[0]})
+
+		expected := mongo.CommandError{
+			Code:    40602,
+			Name:    "Location40602",
+			Message: "$planCacheStats is only valid as the first stage in a pipeline",
+		}
+		AssertMatchesCommandError(t, expected, err)
+	})
+}
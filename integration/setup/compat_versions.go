@@ -0,0 +1,112 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package setup
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// urlsFlag is a [flag.Value] for a flag that could be repeated to set several URLs.
+type urlsFlag []string
+
+// String implements [flag.Value].
+func (f *urlsFlag) String() string {
+	return strings.Join(*f, " ")
+}
+
+// Set implements [flag.Value].
+func (f *urlsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// compatSystem represents a single compat system (MongoDB) of a particular version.
+type compatSystem struct {
+	version string // major and minor, like "7.0"
+	url     string
+}
+
+// compatSystems contains compat systems selected for this run.
+//
+// It is set by [Startup].
+var compatSystems []compatSystem
+
+// compatVersion returns MongoDB's major and minor version (like "7.0") for the given URL.
+func compatVersion(ctx context.Context, url string) (string, error) {
+	client, err := makeClient(ctx, url)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	defer client.Disconnect(ctx) //nolint:errcheck // we are only reading
+
+	var res struct {
+		Version string `bson:"version"`
+	}
+
+	if err = client.Database("admin").RunCommand(ctx, bson.D{{"buildInfo", 1}}).Decode(&res); err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	parts := strings.SplitN(res.Version, ".", 3)
+	if len(parts) < 2 {
+		return "", lazyerrors.Errorf("unexpected version %q", res.Version)
+	}
+
+	return parts[0] + "." + parts[1], nil
+}
+
+// selectCompatSystems returns compat systems for the given versions (all if empty) in the original order.
+func selectCompatSystems(systems []compatSystem, versions []string) ([]compatSystem, error) {
+	res := make([]compatSystem, 0, len(systems))
+	seen := make(map[string]string, len(systems))
+
+	for _, s := range systems {
+		if url, ok := seen[s.version]; ok {
+			return nil, fmt.Errorf("both %s and %s compat systems are MongoDB %s", url, s.url, s.version)
+		}
+
+		seen[s.version] = s.url
+
+		if len(versions) == 0 || slices.Contains(versions, s.version) {
+			res = append(res, s)
+		}
+	}
+
+	for _, v := range versions {
+		if _, ok := seen[v]; !ok {
+			return nil, fmt.Errorf("no compat system for MongoDB %s", v)
+		}
+	}
+
+	return res, nil
+}
+
+// versionSuffix returns collection name suffix for the given compat version,
+// or an empty string if only a single version is used.
+func versionSuffix(version string) string {
+	if len(compatSystems) < 2 {
+		return ""
+	}
+
+	return "_v" + strings.ReplaceAll(version, ".", "")
+}
@@ -17,6 +17,7 @@ package setup
 import (
 	"flag"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 
@@ -57,16 +58,20 @@ func IsHana(tb testtb.TB) bool {
 
 // ensureIssueURL panics if URL is not a valid FerretDB issue URL.
 func ensureIssueURL(url string) {
-	must.BeTrue(strings.HasPrefix(url, "https://github.com/FerretDB/FerretDB/issues/"))
+	must.BeTrue(strings.HasPrefix(url, issueURLPrefix))
 }
 
 // FailsForFerretDB return testtb.TB that expects test to fail for FerretDB and pass for MongoDB.
+// If the test passes for FerretDB, it fails, so that the expectation could be removed.
+//
+// If -check-issues flag is set, the test also fails if the issue is closed or does not exist.
 //
 // This function should not be used lightly and always with an issue URL.
 func FailsForFerretDB(tb testtb.TB, url string) testtb.TB {
 	tb.Helper()
 
 	ensureIssueURL(url)
+	checkIssue(tb, url)
 
 	if IsMongoDB(tb) {
 		return tb
@@ -75,6 +80,24 @@ func FailsForFerretDB(tb testtb.TB, url string) testtb.TB {
 	return testfail.Expected(tb, url)
 }
 
+// FailsForFerretDBOn is like [FailsForFerretDB], but only expects test to fail
+// when FerretDB is compared with one of the given MongoDB versions (like "7.0").
+// compatVersion is the version of the compat collection used by the test, see [SetupCompatResult].
+//
+// This function should not be used lightly and always with an issue URL.
+func FailsForFerretDBOn(tb testtb.TB, url, compatVersion string, versions ...string) testtb.TB {
+	tb.Helper()
+
+	ensureIssueURL(url)
+	checkIssue(tb, url)
+
+	if IsMongoDB(tb) || !slices.Contains(versions, compatVersion) {
+		return tb
+	}
+
+	return testfail.Expected(tb, url)
+}
+
 // FailsForMongoDB return testtb.TB that expects test to fail for MongoDB and pass for FerretDB.
 //
 // This function should not be used lightly.
@@ -106,6 +129,11 @@ func PushdownDisabled() bool {
 	return *disablePushdownF
 }
 
+// StrictErrors returns true if compat tests should compare error messages and labels.
+func StrictErrors() bool {
+	return *strictErrorsF
+}
+
 // Main is the entry point for all integration test packages.
 // It should be called from main_test.go in each package.
 func Main(m *testing.M) {
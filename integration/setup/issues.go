@@ -0,0 +1,156 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package setup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/testutil/testtb"
+)
+
+// issueURLPrefix is a prefix of FerretDB issue URLs.
+const issueURLPrefix = "https://github.com/FerretDB/FerretDB/issues/"
+
+// issueStatus represents a GitHub issue status.
+type issueStatus string
+
+// Known issue statuses.
+const (
+	issueOpen     issueStatus = "open"
+	issueClosed   issueStatus = "closed"
+	issueNotFound issueStatus = "not found"
+)
+
+// issues caches issue statuses for the whole test run.
+var issues struct {
+	m                sync.Mutex
+	statuses         map[string]issueStatus
+	rateLimitReached bool
+}
+
+// checkIssue fails the test if the issue with the given URL is not open.
+//
+// It does nothing unless -check-issues flag is set.
+// If GitHub API rate limit is reached, the issue is assumed to be open.
+func checkIssue(tb testtb.TB, url string) {
+	tb.Helper()
+
+	if !*checkIssuesF {
+		return
+	}
+
+	issues.m.Lock()
+	defer issues.m.Unlock()
+
+	if issues.statuses == nil {
+		issues.statuses = map[string]issueStatus{}
+	}
+
+	status, ok := issues.statuses[url]
+	if !ok {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var err error
+		status, err = fetchIssueStatus(ctx, url)
+		require.NoError(tb, err)
+
+		if status == "" {
+			if !issues.rateLimitReached {
+				tb.Logf("GitHub API rate limit reached, assuming that issues are open. Set GITHUB_TOKEN to avoid that.")
+				issues.rateLimitReached = true
+			}
+
+			return
+		}
+
+		issues.statuses[url] = status
+	}
+
+	if status != issueOpen {
+		tb.Fatalf("Issue %s is %s; remove the expected failure or update the issue URL.", url, status)
+	}
+}
+
+// fetchIssueStatus returns the status of the issue with the given URL via GitHub API.
+//
+// It returns an empty status if rate limit is reached.
+func fetchIssueStatus(ctx context.Context, url string) (issueStatus, error) {
+	num, err := strconv.Atoi(strings.TrimPrefix(url, issueURLPrefix))
+	if err != nil || !strings.HasPrefix(url, issueURLPrefix) {
+		return "", lazyerrors.Errorf("invalid issue URL %q", url)
+	}
+
+	apiURL := fmt.Sprintf("https://api.github.com/repos/FerretDB/FerretDB/issues/%d", num)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// handled below
+	case http.StatusNotFound:
+		return issueNotFound, nil
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		if resp.StatusCode == http.StatusTooManyRequests || resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			return "", nil
+		}
+
+		return "", lazyerrors.Errorf("%s: unexpected status %s", apiURL, resp.Status)
+	default:
+		return "", lazyerrors.Errorf("%s: unexpected status %s", apiURL, resp.Status)
+	}
+
+	var issue struct {
+		State string `json:"state"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&issue); err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	switch s := issueStatus(issue.State); s {
+	case issueOpen, issueClosed:
+		return s, nil
+	default:
+		return "", lazyerrors.Errorf("%s: unknown issue state %q", apiURL, issue.State)
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/FerretDB/FerretDB/internal/util/state"
 	"github.com/FerretDB/FerretDB/internal/util/testutil"
 	"github.com/FerretDB/FerretDB/internal/util/testutil/testtb"
+	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // unixSocketPath returns temporary Unix domain socket path for that test.
@@ -187,6 +188,8 @@ func setupListener(tb testtb.TB, ctx context.Context, logger *zap.Logger, opts *
 		MySQLURL:      mysqlURL,
 		HANAURL:       *hanaURLF,
 
+		OperationTimeout: opts.OperationTimeout,
+
 		TestOpts: registry.TestOpts{
 			DisablePushdown:         *disablePushdownF,
 			CappedCleanupPercentage: opts.CappedCleanupPercentage,
@@ -194,6 +197,8 @@ func setupListener(tb testtb.TB, ctx context.Context, logger *zap.Logger, opts *
 			EnableNewAuth:           !opts.DisableNewAuth,
 			BatchSize:               *batchSizeF,
 			MaxBsonObjectSizeBytes:  opts.MaxBsonObjectSizeBytes,
+			SortMemoryLimitBytes:    opts.SortMemoryLimitBytes,
+			Faults:                  opts.Faults,
 		},
 	}
 
@@ -215,6 +220,7 @@ func setupListener(tb testtb.TB, ctx context.Context, logger *zap.Logger, opts *
 	listenerOpts := clientconn.NewListenerOpts{
 		ProxyAddr:      *targetProxyAddrF,
 		Mode:           clientconn.NormalMode,
+		BSONValidation: wire.ValidationBasic,
 		Metrics:        listenerMetrics,
 		Handler:        h,
 		Logger:         logger,
@@ -20,6 +20,7 @@ import (
 	"flag"
 	"fmt"
 	"net/url"
+	"os"
 	"runtime/trace"
 	"slices"
 	"strings"
@@ -32,6 +33,7 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
 
+	"github.com/FerretDB/FerretDB/internal/backends/decorators/faults"
 	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/observability"
 	"github.com/FerretDB/FerretDB/internal/util/testutil"
@@ -54,9 +56,15 @@ var (
 	mysqlURLF      = flag.String("mysql-url", "", "in-process FerretDB: MySQL URL for 'mysql' handler.")
 	hanaURLF       = flag.String("hana-url", "", "in-process FerretDB: Hana URL for 'hana' handler.")
 
-	batchSizeF = flag.Int("batch-size", 100, "maximum insertion batch size")
+	batchSizeF = flag.Int("batch-size", 100, "maximum write batch size")
 
-	compatURLF = flag.String("compat-url", "", "compat system's (MongoDB) URL for compatibility tests; if empty, they are skipped")
+	compatURLsF     urlsFlag
+	compatVersionsF = flag.String(
+		"compat-versions",
+		os.Getenv("FERRETDB_TEST_COMPAT_VERSIONS"),
+		"compat tests: comma-separated MongoDB versions (like '6.0,7.0,8.0') to compare with; "+
+			"if empty, all compat systems are used; defaults to $FERRETDB_TEST_COMPAT_VERSIONS",
+	)
 
 	benchDocsF = flag.Int("bench-docs", 1000, "benchmarks: number of documents to generate per iteration")
 
@@ -65,8 +73,21 @@ var (
 	logLevelF   = zap.LevelFlag("log-level", zap.DebugLevel, "log level for tests")
 
 	disablePushdownF = flag.Bool("disable-pushdown", false, "disable pushdown")
+
+	checkIssuesF = flag.Bool("check-issues", false, "check via GitHub API that issues of expected failures are open")
+
+	strictErrorsF = flag.Bool("strict-errors", false, "compat tests: compare error messages and labels too")
 )
 
+func init() {
+	flag.Var(
+		&compatURLsF,
+		"compat-url",
+		"compat system's (MongoDB) URL for compatibility tests; "+
+			"could be repeated for different MongoDB versions; if empty, they are skipped",
+	)
+}
+
 // Other globals.
 var (
 	allBackends = []string{"ferretdb-postgresql", "ferretdb-sqlite", "ferretdb-mysql", "ferretdb-hana", "mongodb"}
@@ -104,8 +125,17 @@ type BackendOpts struct {
 	// MaxBsonObjectSizeBytes is the maximum allowed size of a document, if not set FerretDB sets the default.
 	MaxBsonObjectSizeBytes int
 
+	// SortMemoryLimitBytes is the maximum size of documents sorted in memory, if not set FerretDB sets the default.
+	SortMemoryLimitBytes int
+
 	// DisableNewAuth true uses the old backend authentication.
 	DisableNewAuth bool
+
+	// Faults injects backend failures, if set. Tests are skipped if in-process FerretDB is not used.
+	Faults *faults.Injector
+
+	// OperationTimeout is the default time limit for operations without maxTimeMS; 0 means no limit.
+	OperationTimeout time.Duration
 }
 
 // SetupResult represents setup results.
@@ -152,6 +182,10 @@ func SetupWithOpts(tb testtb.TB, opts *SetupOpts) *SetupResult {
 	}
 	logger := testutil.LevelLogger(tb, level)
 
+	if opts.BackendOptions != nil && opts.BackendOptions.Faults != nil && *targetURLF != "" {
+		tb.Skip("Faults could be injected only into in-process FerretDB.")
+	}
+
 	uri := *targetURLF
 	if uri == "" {
 		uri = setupListener(tb, setupCtx, logger, opts.BackendOptions)
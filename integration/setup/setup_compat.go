@@ -40,6 +40,10 @@ type SetupCompatOpts struct {
 	// Data providers.
 	Providers []shareddata.Provider
 
+	// Benchmark data provider.
+	// If set, a single collection is created for it, and Providers should not be set.
+	BenchmarkProvider shareddata.BenchmarkProvider
+
 	// If true, a non-existent collection will be added to the list of collections.
 	// This is useful to test the behavior when a collection is not found.
 	//
@@ -52,17 +56,23 @@ type SetupCompatOpts struct {
 }
 
 // SetupCompatResult represents compatibility test setup results.
+//
+// If several compat systems are used, target and compat collections are created for each of them;
+// collections with the same index should be compared.
 type SetupCompatResult struct {
 	Ctx               context.Context
 	TargetCollections []*mongo.Collection
 	CompatCollections []*mongo.Collection
+
+	// MongoDB version (like "7.0") of each compat collection.
+	CompatVersions []string
 }
 
 // SetupCompatWithOpts setups the compatibility test according to given options.
 func SetupCompatWithOpts(tb testtb.TB, opts *SetupCompatOpts) *SetupCompatResult {
 	tb.Helper()
 
-	if *compatURLF == "" {
+	if len(compatSystems) == 0 {
 		tb.Skip("-compat-url is empty, skipping compatibility test")
 	}
 
@@ -112,10 +122,28 @@ func SetupCompatWithOpts(tb testtb.TB, opts *SetupCompatOpts) *SetupCompatResult
 	// register cleanup function after setupListener registers its own to preserve full logs
 	tb.Cleanup(cancel)
 
-	targetCollections := setupCompatCollections(tb, setupCtx, targetClient, opts, *targetBackendF)
+	versions := make([]string, len(compatSystems))
+	for i, s := range compatSystems {
+		versions[i] = s.version
+	}
+
+	targetCollections := setupCompatCollections(tb, setupCtx, targetClient, opts, *targetBackendF, versions...)
+
+	var compatCollections []*mongo.Collection
+	var compatVersions []string
+
+	for _, s := range compatSystems {
+		compatClient := setupClient(tb, setupCtx, s.url)
+		collections := setupCompatCollections(tb, setupCtx, compatClient, opts, "mongodb", s.version)
 
-	compatClient := setupClient(tb, setupCtx, *compatURLF)
-	compatCollections := setupCompatCollections(tb, setupCtx, compatClient, opts, "mongodb")
+		compatCollections = append(compatCollections, collections...)
+
+		for range collections {
+			compatVersions = append(compatVersions, s.version)
+		}
+	}
+
+	require.Len(tb, compatCollections, len(targetCollections))
 
 	level.SetLevel(*logLevelF)
 
@@ -123,6 +151,7 @@ func SetupCompatWithOpts(tb testtb.TB, opts *SetupCompatOpts) *SetupCompatResult
 		Ctx:               ctx,
 		TargetCollections: targetCollections,
 		CompatCollections: compatCollections,
+		CompatVersions:    compatVersions,
 	}
 }
 
@@ -137,7 +166,11 @@ func SetupCompat(tb testtb.TB) (context.Context, []*mongo.Collection, []*mongo.C
 }
 
 // setupCompatCollections setups a single database with one collection per provider for compatibility tests.
-func setupCompatCollections(tb testtb.TB, ctx context.Context, client *mongo.Client, opts *SetupCompatOpts, backend string) []*mongo.Collection {
+//
+// Collections are created for each given compat version, see [versionSuffix].
+//
+//nolint:lll // for readability
+func setupCompatCollections(tb testtb.TB, ctx context.Context, client *mongo.Client, opts *SetupCompatOpts, backend string, versions ...string) []*mongo.Collection {
 	tb.Helper()
 
 	ctx, span := otel.Tracer("").Start(ctx, "setupCompatCollections")
@@ -158,50 +191,70 @@ func setupCompatCollections(tb testtb.TB, ctx context.Context, client *mongo.Cli
 		cleanupDatabase(ctx, tb, database, nil)
 	})
 
-	collections := make([]*mongo.Collection, 0, len(opts.Providers))
-	for _, provider := range opts.Providers {
-		collectionName := opts.baseCollectionName + "_" + provider.Name()
-		fullName := opts.databaseName + "." + collectionName
+	var collections []*mongo.Collection
 
-		spanName := fmt.Sprintf("setupCompatCollections/%s", collectionName)
-		collCtx, span := otel.Tracer("").Start(ctx, spanName)
-		region := trace.StartRegion(collCtx, spanName)
+	for _, version := range versions {
+		suffix := versionSuffix(version)
 
-		collection := database.Collection(collectionName)
+		if opts.BenchmarkProvider != nil {
+			require.Empty(tb, opts.Providers, "Both Providers and BenchmarkProvider were set")
 
-		// drop remnants of the previous failed run
-		_ = collection.Drop(collCtx)
+			collection := database.Collection(opts.baseCollectionName + "_" + opts.BenchmarkProvider.Name() + suffix)
 
-		docs := shareddata.Docs(provider)
-		require.NotEmpty(tb, docs)
+			// drop remnants of the previous failed run
+			_ = collection.Drop(ctx)
 
-		res, err := collection.InsertMany(collCtx, docs)
-		require.NoError(tb, err, "%s: backend %q, collection %s", provider.Name(), backend, fullName)
-		require.Len(tb, res.InsertedIDs, len(docs))
+			require.True(tb, insertBenchmarkProvider(tb, ctx, collection, opts.BenchmarkProvider))
 
-		// delete collection unless test failed
-		tb.Cleanup(func() {
-			if tb.Failed() {
-				tb.Logf("Keeping %s for debugging.", fullName)
-				return
-			}
+			collections = append(collections, collection)
 
-			err := collection.Drop(collCtx)
-			require.NoError(tb, err)
-		})
+			continue
+		}
 
-		collections = append(collections, collection)
+		for _, provider := range opts.Providers {
+			collectionName := opts.baseCollectionName + "_" + provider.Name() + suffix
+			fullName := opts.databaseName + "." + collectionName
 
-		region.End()
-		span.End()
-	}
+			spanName := fmt.Sprintf("setupCompatCollections/%s", collectionName)
+			collCtx, span := otel.Tracer("").Start(ctx, spanName)
+			region := trace.StartRegion(collCtx, spanName)
 
-	// opts.AddNonExistentCollection is not needed, always add a non-existent collection
-	// TODO https://github.com/FerretDB/FerretDB/issues/1545
-	if opts.AddNonExistentCollection {
-		nonExistedCollectionName := opts.baseCollectionName + "-non-existent"
-		collection := database.Collection(nonExistedCollectionName)
-		collections = append(collections, collection)
+			collection := database.Collection(collectionName)
+
+			// drop remnants of the previous failed run
+			_ = collection.Drop(collCtx)
+
+			docs := shareddata.Docs(provider)
+			require.NotEmpty(tb, docs)
+
+			res, err := collection.InsertMany(collCtx, docs)
+			require.NoError(tb, err, "%s: backend %q, collection %s", provider.Name(), backend, fullName)
+			require.Len(tb, res.InsertedIDs, len(docs))
+
+			// delete collection unless test failed
+			tb.Cleanup(func() {
+				if tb.Failed() {
+					tb.Logf("Keeping %s for debugging.", fullName)
+					return
+				}
+
+				err := collection.Drop(collCtx)
+				require.NoError(tb, err)
+			})
+
+			collections = append(collections, collection)
+
+			region.End()
+			span.End()
+		}
+
+		// opts.AddNonExistentCollection is not needed, always add a non-existent collection
+		// TODO https://github.com/FerretDB/FerretDB/issues/1545
+		if opts.AddNonExistentCollection {
+			nonExistedCollectionName := opts.baseCollectionName + "-non-existent" + suffix
+			collection := database.Collection(nonExistedCollectionName)
+			collections = append(collections, collection)
+		}
 	}
 
 	require.NotEmpty(tb, collections)
@@ -20,6 +20,7 @@ import (
 	"runtime"
 	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -125,25 +126,38 @@ func Startup() {
 		zap.S().Infof("Target system: %s (built-in).", *targetBackendF)
 	}
 
-	if *compatURLF != "" {
-		var err error
+	systems := make([]compatSystem, 0, len(compatURLsF))
 
-		*compatURLF, err = setClientPaths(*compatURLF)
+	for _, u := range compatURLsF {
+		u, err := setClientPaths(u)
 		if err != nil {
 			zap.S().Fatal(err)
 		}
 
-		client, err := makeClient(clientCtx, *compatURLF)
+		v, err := compatVersion(clientCtx, u)
 		if err != nil {
-			zap.S().Fatalf("Failed to connect to compat system %s: %s", *compatURLF, err)
+			zap.S().Fatalf("Failed to connect to compat system %s: %s", u, err)
 		}
 
-		_ = client.Disconnect(clientCtx)
+		systems = append(systems, compatSystem{version: v, url: u})
+	}
 
-		zap.S().Infof("Compat system: MongoDB (%s).", *compatURLF)
-	} else {
+	var versions []string
+	if *compatVersionsF != "" {
+		versions = strings.Split(*compatVersionsF, ",")
+	}
+
+	if compatSystems, err = selectCompatSystems(systems, versions); err != nil {
+		zap.S().Fatal(err)
+	}
+
+	if len(compatSystems) == 0 {
 		zap.S().Infof("Compat system: none, compatibility tests will be skipped.")
 	}
+
+	for _, s := range compatSystems {
+		zap.S().Infof("Compat system: MongoDB %s (%s).", s.version, s.url)
+	}
 }
 
 // Shutdown cleans up after all tests.
@@ -60,7 +60,7 @@ var BenchmarkSmallDocuments = newGeneratorBenchmarkProvider("SmallDocuments", fu
 // It simulates a settings document like the one FastNetMon uses.
 var BenchmarkSettingsDocuments = newGeneratorBenchmarkProvider("SettingsDocuments", func(docs int) generatorFunc {
 	var total int
-	f := newFaker()
+	f := newFaker(1)
 
 	return func() bson.D {
 		if total >= docs {
@@ -0,0 +1,173 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shareddata
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxDocumentLen is the maximum BSON document size accepted by MongoDB.
+const maxDocumentLen = 16 * 1024 * 1024
+
+// MaxNestingDepth is the maximum nesting depth of documents accepted by MongoDB,
+// including the top-level document.
+const MaxNestingDepth = 100
+
+// LargeArrayLen is the number of elements in large arrays of LargeArrays provider.
+const LargeArrayLen = 100_000
+
+// LongFieldNameLen is the length of field names in LongFieldNames provider.
+const LongFieldNameLen = 64 * 1024
+
+// ExtremesProviders returns providers with documents at or close to MongoDB limits.
+//
+// They are not included in AllProviders because they are slow to insert and query.
+func ExtremesProviders() Providers {
+	return Providers{
+		LargeDocuments,
+		DocumentsNestedToLimit,
+		LargeArrays,
+		LongFieldNames,
+	}
+}
+
+// generatedValues stores shared data documents that are generated on the first use.
+//
+// It is used for documents that are too large to be kept in memory when they are not needed.
+type generatedValues struct {
+	name     string
+	generate func() []bson.D
+
+	once sync.Once
+	docs []bson.D
+}
+
+// Name implements [Provider].
+func (g *generatedValues) Name() string {
+	return g.name
+}
+
+// Docs implements [Provider].
+func (g *generatedValues) Docs() []bson.D {
+	g.once.Do(func() {
+		g.docs = g.generate()
+	})
+
+	return g.docs
+}
+
+// LargeDocuments contains documents that are a bit smaller than the maximum BSON document size.
+//
+// Each document has a small `v` field and a large `large` field.
+var LargeDocuments = &generatedValues{
+	name: "LargeDocuments",
+	generate: func() []bson.D {
+		// leave some space for other fields and for small updates
+		size := maxDocumentLen - 1024
+
+		return []bson.D{
+			{{"_id", "string"}, {"v", "string"}, {"large", strings.Repeat("a", size)}},
+			{{"_id", "binary"}, {"v", "binary"}, {"large", primitive.Binary{Data: bytes.Repeat([]byte{0x42}, size)}}},
+			{{"_id", "small"}, {"v", "small"}, {"large", "a"}},
+		}
+	},
+}
+
+// DocumentsNestedToLimit contains documents with `v` field nested in multiple levels
+// up to the maximum nesting depth.
+//
+// Documents are nested in `a` fields; arrays are nested directly.
+// The innermost value is int32(42).
+var DocumentsNestedToLimit = &generatedValues{
+	name: "DocumentsNestedToLimit",
+	generate: func() []bson.D {
+		// top-level document is the first level
+		depth := MaxNestingDepth - 1
+
+		return []bson.D{
+			{{"_id", "documents-half"}, {"v", nestedDocument(depth / 2)}},
+			{{"_id", "documents"}, {"v", nestedDocument(depth)}},
+			{{"_id", "arrays"}, {"v", nestedArray(depth)}},
+		}
+	},
+}
+
+// nestedDocument returns a document nested in `a` fields to the given depth,
+// with int32(42) as the innermost value.
+func nestedDocument(depth int) any {
+	var res any = int32(42)
+	for i := 0; i < depth; i++ {
+		res = bson.D{{"a", res}}
+	}
+
+	return res
+}
+
+// nestedArray returns an array nested to the given depth,
+// with int32(42) as the innermost value.
+func nestedArray(depth int) any {
+	var res any = int32(42)
+	for i := 0; i < depth; i++ {
+		res = bson.A{res}
+	}
+
+	return res
+}
+
+// LargeArrays contains documents with `v` field that contains large arrays.
+var LargeArrays = &generatedValues{
+	name: "LargeArrays",
+	generate: func() []bson.D {
+		int32s := make(bson.A, LargeArrayLen)
+		docs := make(bson.A, LargeArrayLen)
+
+		for i := range LargeArrayLen {
+			int32s[i] = int32(i)
+			docs[i] = bson.D{{"i", int32(i)}}
+		}
+
+		return []bson.D{
+			{{"_id", "int32s"}, {"v", int32s}},
+			{{"_id", "documents"}, {"v", docs}},
+		}
+	},
+}
+
+// LongFieldName returns a field name of LongFieldNames provider with the given prefix.
+func LongFieldName(prefix string) string {
+	return prefix + strings.Repeat("f", LongFieldNameLen-len(prefix))
+}
+
+// LongFieldNames contains documents with very long field names,
+// both at the top level and in the embedded document in `v` field.
+var LongFieldNames = &generatedValues{
+	name: "LongFieldNames",
+	generate: func() []bson.D {
+		return []bson.D{
+			{{"_id", "top-level"}, {LongFieldName("a"), int32(42)}, {LongFieldName("b"), "foo"}},
+			{{"_id", "embedded"}, {"v", bson.D{{LongFieldName("a"), int32(42)}}}},
+		}
+	},
+}
+
+// check interfaces
+var (
+	_ Provider = (*generatedValues)(nil)
+)
@@ -20,6 +20,7 @@ import (
 	"strconv"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -224,9 +225,11 @@ type faker struct {
 	r *rand.Rand
 }
 
-// newFaker creates a new faker.
-func newFaker() *faker {
-	src := rand.NewSource(1)
+// newFaker creates a new faker with the given seed.
+//
+// Fakers with the same seed generate the same data.
+func newFaker(seed int64) *faker {
+	src := rand.NewSource(seed)
 
 	return &faker{
 		r: rand.New(src),
@@ -285,3 +288,27 @@ func (f *faker) ScalarValue() any {
 		}
 	}
 }
+
+// Value generates a random scalar value, or a small document or array of scalar values.
+func (f *faker) Value() any {
+	switch f.r.Intn(10) {
+	case 0:
+		doc := make(bson.D, f.r.Intn(4))
+		for i := range doc {
+			doc[i] = bson.E{Key: string(rune('a' + i)), Value: f.ScalarValue()}
+		}
+
+		return doc
+
+	case 1:
+		arr := make(bson.A, f.r.Intn(4))
+		for i := range arr {
+			arr[i] = f.ScalarValue()
+		}
+
+		return arr
+
+	default:
+		return f.ScalarValue()
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shareddata
+
+import (
+	"fmt"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// RandomDocumentsOpts represents options for random documents provider.
+type RandomDocumentsOpts struct {
+	// Seed for the random generator; providers with the same options provide the same documents.
+	Seed int64
+
+	// Number of documents; must be positive.
+	Docs int
+
+	// Number of optional fields named f0, f1, etc.
+	Fields int
+
+	// Percentage of documents with each optional field set, from 0 to 100.
+	FieldsPercent int
+}
+
+// randomDocuments provides documents that are generated deterministically from a seed.
+type randomDocuments struct {
+	opts RandomDocumentsOpts
+}
+
+// NewRandomDocuments returns a provider of documents that look like:
+//
+//	{_id: int32(0), v: <value>, f0: <value>, f2: <value>}
+//	{_id: int32(1), v: <value>, f1: <value>}
+//	...
+//
+// `_id` is an int32 that starts from 0.
+// `v` is always set to a random scalar, document, or array value.
+// Optional fields are set to random values for some documents.
+func NewRandomDocuments(opts *RandomDocumentsOpts) Provider {
+	if opts.Docs <= 0 {
+		panic("number of documents must be positive")
+	}
+
+	if opts.Fields < 0 || opts.FieldsPercent < 0 || opts.FieldsPercent > 100 {
+		panic(fmt.Sprintf("invalid fields options: %d, %d%%", opts.Fields, opts.FieldsPercent))
+	}
+
+	return &randomDocuments{
+		opts: *opts,
+	}
+}
+
+// Name implements [Provider].
+func (r *randomDocuments) Name() string {
+	return fmt.Sprintf("RandomDocuments-%d-%d-%d-%d", r.opts.Seed, r.opts.Docs, r.opts.Fields, r.opts.FieldsPercent)
+}
+
+// Docs implements [Provider].
+func (r *randomDocuments) Docs() []bson.D {
+	f := newFaker(r.opts.Seed)
+
+	res := make([]bson.D, r.opts.Docs)
+
+	for i := range res {
+		doc := bson.D{{"_id", int32(i)}, {"v", f.Value()}}
+
+		for j := 0; j < r.opts.Fields; j++ {
+			if f.r.Intn(100) < r.opts.FieldsPercent {
+				doc = append(doc, bson.E{Key: "f" + strconv.Itoa(j), Value: f.Value()})
+			}
+		}
+
+		res[i] = doc
+	}
+
+	return res
+}
+
+// check interfaces
+var (
+	_ Provider = (*randomDocuments)(nil)
+)
@@ -319,6 +319,43 @@ var Int64s = &Values[string]{
 	},
 }
 
+// Decimal128s contains Decimal128 values for tests, including special values and extreme exponents.
+//
+// FerretDB does not support Decimal128 values yet, so this provider is not included in AllProviders.
+// TODO https://github.com/FerretDB/FerretDB/issues/66
+var Decimal128s = &Values[string]{
+	name: "Decimal128s",
+	data: map[string]any{
+		"decimal128":       must.NotFail(primitive.ParseDecimal128("42.13")),
+		"decimal128-whole": must.NotFail(primitive.ParseDecimal128("42")),
+		"decimal128-int64": must.NotFail(primitive.ParseDecimal128("9223372036854775807")),
+		"decimal128-tenth": must.NotFail(primitive.ParseDecimal128("0.1")),
+
+		// the same values as above with different representation
+		"decimal128-trailing-zeros":       must.NotFail(primitive.ParseDecimal128("42.1300")),
+		"decimal128-whole-trailing-zeros": must.NotFail(primitive.ParseDecimal128("42.000")),
+		"decimal128-whole-exponent":       must.NotFail(primitive.ParseDecimal128("420E-1")),
+
+		"decimal128-zero":          must.NotFail(primitive.ParseDecimal128("0")),
+		"decimal128-negative-zero": must.NotFail(primitive.ParseDecimal128("-0")),
+		"decimal128-zero-exponent": must.NotFail(primitive.ParseDecimal128("0E+10")),
+
+		"decimal128-nan":               must.NotFail(primitive.ParseDecimal128("NaN")),
+		"decimal128-infinity":          must.NotFail(primitive.ParseDecimal128("Infinity")),
+		"decimal128-negative-infinity": must.NotFail(primitive.ParseDecimal128("-Infinity")),
+
+		// largest and smallest finite values, and smallest positive and negative subnormal values
+		"decimal128-max":               must.NotFail(primitive.ParseDecimal128("9.999999999999999999999999999999999E+6144")),
+		"decimal128-min":               must.NotFail(primitive.ParseDecimal128("-9.999999999999999999999999999999999E+6144")),
+		"decimal128-smallest":          must.NotFail(primitive.ParseDecimal128("1E-6176")),
+		"decimal128-negative-smallest": must.NotFail(primitive.ParseDecimal128("-1E-6176")),
+
+		// values with the maximum and minimum exponents that are not representable as double
+		"decimal128-max-exponent": must.NotFail(primitive.ParseDecimal128("1E+6144")),
+		"decimal128-min-exponent": must.NotFail(primitive.ParseDecimal128("1E-6143")),
+	},
+}
+
 // Unsets contains unset value for tests.
 var Unsets = &Values[string]{
 	name: "Unsets",
@@ -0,0 +1,78 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shareddata
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// timeSeriesHour returns the date of the given hour of the fixed day for TimeSeries provider.
+func timeSeriesHour(h int) primitive.DateTime {
+	return primitive.NewDateTimeFromTime(time.Date(2024, 1, 1, h, 0, 0, 0, time.UTC))
+}
+
+// TimeSeries contains measurements of several sensors with gaps in time and values for tests.
+//
+// Each document has time, sensor, sequence number n, and value fields;
+// some values are null or missing.
+// It is not included in AllProviders because it is intended for $densify and $fill tests.
+var TimeSeries = NewTopLevelFieldsProvider("TimeSeries", nil, map[string]Fields{
+	"a-0": {
+		{Key: "time", Value: timeSeriesHour(0)},
+		{Key: "sensor", Value: "a"},
+		{Key: "n", Value: int32(0)},
+		{Key: "value", Value: int32(10)},
+	},
+	"a-1": {
+		{Key: "time", Value: timeSeriesHour(1)},
+		{Key: "sensor", Value: "a"},
+		{Key: "n", Value: int32(1)},
+		{Key: "value", Value: nil},
+	},
+	"a-3": {
+		{Key: "time", Value: timeSeriesHour(3)},
+		{Key: "sensor", Value: "a"},
+		{Key: "n", Value: int32(3)},
+	},
+	"a-6": {
+		{Key: "time", Value: timeSeriesHour(6)},
+		{Key: "sensor", Value: "a"},
+		{Key: "n", Value: int32(6)},
+		{Key: "value", Value: int32(40)},
+	},
+	"b-0": {
+		{Key: "time", Value: timeSeriesHour(0)},
+		{Key: "sensor", Value: "b"},
+		{Key: "n", Value: int32(0)},
+		{Key: "value", Value: 1.5},
+	},
+	"b-2": {
+		{Key: "time", Value: timeSeriesHour(2)},
+		{Key: "sensor", Value: "b"},
+		{Key: "n", Value: int32(2)},
+	},
+	"b-4": {
+		{Key: "time", Value: timeSeriesHour(4)},
+		{Key: "sensor", Value: "b"},
+		{Key: "n", Value: int32(4)},
+		{Key: "value", Value: 4.5},
+	},
+	"c-unset": {
+		{Key: "sensor", Value: "c"},
+		{Key: "value", Value: int32(7)},
+	},
+})
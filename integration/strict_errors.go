@@ -0,0 +1,53 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/util/testutil/testtb"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+// strictErrorsAllowlist contains names of tests with error messages or labels
+// that are known to be different between FerretDB and MongoDB.
+// Subtests of listed tests are allowed too.
+//
+// Errors of those tests are compared without messages and labels even in strict errors mode.
+// Please remove tests from this list when their errors become compatible,
+// and keep it sorted.
+var strictErrorsAllowlist = []string{}
+
+// strictErrors returns true if error messages and labels should be compared for the current test.
+//
+// That mode is enabled by -strict-errors flag and tracks error messages compatibility progress.
+func strictErrors(t testtb.TB) bool {
+	t.Helper()
+
+	if !setup.StrictErrors() {
+		return false
+	}
+
+	name := t.Name()
+
+	for _, allowed := range strictErrorsAllowlist {
+		if name == allowed || strings.HasPrefix(name, allowed+"/") {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package users
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration"
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestAuthStateExportImport(t *testing.T) {
+	t.Parallel()
+
+	setup.SkipForMongoDB(t, "FerretDB-specific commands")
+
+	s := setup.SetupWithOpts(t, nil)
+	ctx := s.Ctx
+	db := s.Collection.Database()
+
+	require.NoError(t, db.RunCommand(ctx, createUser("one", "pwd1")).Err())
+	require.NoError(t, db.RunCommand(ctx, createUser("two", "pwd2")).Err())
+
+	var exported bson.D
+	require.NoError(t, db.RunCommand(ctx, bson.D{{"exportAuth", 1}}).Decode(&exported))
+
+	state, ok := exported.Map()["state"].(bson.D)
+	require.True(t, ok)
+	assert.Equal(t, int32(1), state.Map()["version"])
+
+	exportedUsers, ok := state.Map()["users"].(bson.A)
+	require.True(t, ok)
+	require.Len(t, exportedUsers, 2)
+	assert.Equal(t, "one", exportedUsers[0].(bson.D).Map()["user"])
+	assert.Equal(t, "two", exportedUsers[1].(bson.D).Map()["user"])
+
+	// replace users with the first one only
+	partial := bson.D{{"version", int32(1)}, {"users", bson.A{exportedUsers[0]}}}
+
+	var res bson.D
+	require.NoError(t, db.RunCommand(ctx, bson.D{{"importAuth", 1}, {"state", partial}}).Decode(&res))
+	assert.Equal(t, int32(1), res.Map()["n"])
+
+	var usersInfo bson.D
+	require.NoError(t, db.RunCommand(ctx, bson.D{{"usersInfo", 1}}).Decode(&usersInfo))
+	require.Len(t, usersInfo.Map()["users"], 1)
+
+	// restore the exported state
+	require.NoError(t, db.RunCommand(ctx, bson.D{{"importAuth", 1}, {"state", state}}).Decode(&res))
+	assert.Equal(t, int32(2), res.Map()["n"])
+
+	var reexported bson.D
+	require.NoError(t, db.RunCommand(ctx, bson.D{{"exportAuth", 1}}).Decode(&reexported))
+	assert.Equal(t, exported.Map()["state"], reexported.Map()["state"])
+
+	t.Run("WrongDatabase", func(t *testing.T) {
+		t.Parallel()
+
+		other := bson.D{
+			{"version", int32(1)},
+			{"users", bson.A{bson.D{
+				{"user", "foo"},
+				{"db", "other"},
+				{"credentials", exportedUsers[0].(bson.D).Map()["credentials"]},
+				{"roles", bson.A{}},
+			}}},
+		}
+
+		err := db.RunCommand(ctx, bson.D{{"importAuth", 1}, {"state", other}}).Err()
+		integration.AssertEqualCommandError(t, mongo.CommandError{
+			Code:    2,
+			Name:    "BadValue",
+			Message: `User "foo@other" does not belong to database "` + db.Name() + `"`,
+		}, err)
+	})
+
+	t.Run("InvalidCredentials", func(t *testing.T) {
+		t.Parallel()
+
+		invalid := bson.D{
+			{"version", int32(1)},
+			{"users", bson.A{bson.D{
+				{"user", "foo"},
+				{"db", db.Name()},
+				{"credentials", bson.D{{"SCRAM-SHA-256", bson.D{{"salt", "foo"}}}}},
+				{"roles", bson.A{}},
+			}}},
+		}
+
+		err := db.RunCommand(ctx, bson.D{{"importAuth", 1}, {"state", invalid}}).Err()
+		integration.AssertEqualCommandError(t, mongo.CommandError{
+			Code:    2,
+			Name:    "BadValue",
+			Message: `User "foo@` + db.Name() + `" has invalid SCRAM-SHA-256 credentials`,
+		}, err)
+
+		// nothing was changed
+		var usersInfo bson.D
+		require.NoError(t, db.RunCommand(ctx, bson.D{{"usersInfo", 1}}).Decode(&usersInfo))
+		assert.Len(t, usersInfo.Map()["users"], 2)
+	})
+}
@@ -42,12 +42,14 @@ const DefaultIndexName = "_id_"
 type Collection interface {
 	Query(context.Context, *QueryParams) (*QueryResult, error)
 	Explain(context.Context, *ExplainParams) (*ExplainResult, error)
+	Count(context.Context, *CountParams) (*CountResult, error)
 	InsertAll(context.Context, *InsertAllParams) (*InsertAllResult, error)
 	UpdateAll(context.Context, *UpdateAllParams) (*UpdateAllResult, error)
 	DeleteAll(context.Context, *DeleteAllParams) (*DeleteAllResult, error)
 
 	Stats(context.Context, *CollectionStatsParams) (*CollectionStatsResult, error)
 	Compact(context.Context, *CompactParams) (*CompactResult, error)
+	ReIndex(context.Context, *ReIndexParams) (*ReIndexResult, error)
 
 	ListIndexes(context.Context, *ListIndexesParams) (*ListIndexesResult, error)
 	CreateIndexes(context.Context, *CreateIndexesParams) (*CreateIndexesResult, error)
@@ -76,6 +78,7 @@ type QueryParams struct {
 	Filter *types.Document
 	Sort   *types.Document
 	Limit  int64
+	Sample int64
 
 	OnlyRecordIDs bool
 	Comment       string
@@ -102,6 +105,10 @@ type QueryResult struct {
 // If non-empty, it should be applied.
 //
 // Limit, if non-zero, should be applied.
+//
+// Sample, if non-zero, may be ignored, or applied by returning that number of randomly selected documents
+// (or all documents if there are fewer) in random order.
+// It could not be combined with Filter, Sort, or Limit.
 func (cc *collectionContract) Query(ctx context.Context, params *QueryParams) (*QueryResult, error) {
 	defer observability.FuncCall(ctx)()
 
@@ -118,6 +125,10 @@ func (cc *collectionContract) Query(ctx context.Context, params *QueryParams) (*
 		}
 	}
 
+	if params.Sample != 0 {
+		must.BeTrue(params.Filter.Len() == 0 && params.Sort.Len() == 0 && params.Limit == 0)
+	}
+
 	res, err := cc.c.Query(ctx, params)
 	checkError(err)
 
@@ -129,6 +140,7 @@ type ExplainParams struct {
 	Filter *types.Document
 	Sort   *types.Document
 	Limit  int64
+	Sample int64
 }
 
 // ExplainResult represents the results of Collection.Explain method.
@@ -137,6 +149,7 @@ type ExplainResult struct {
 	FilterPushdown bool
 	SortPushdown   bool
 	LimitPushdown  bool
+	SamplePushdown bool
 }
 
 // Explain return a backend-specific execution plan for the given query.
@@ -150,6 +163,8 @@ type ExplainResult struct {
 //
 // The ExplainResult's SortPushdown field is set to true if the backend could have applied the whole requested sorting.
 // If it was possible to apply it only partially or not at all, that field should be set to false.
+//
+// The ExplainResult's SamplePushdown field is set to true if the backend could have applied the requested sampling.
 func (cc *collectionContract) Explain(ctx context.Context, params *ExplainParams) (*ExplainResult, error) {
 	defer observability.FuncCall(ctx)()
 
@@ -166,12 +181,58 @@ func (cc *collectionContract) Explain(ctx context.Context, params *ExplainParams
 		}
 	}
 
+	if params.Sample != 0 {
+		must.BeTrue(params.Filter.Len() == 0 && params.Sort.Len() == 0 && params.Limit == 0)
+	}
+
 	res, err := cc.c.Explain(ctx, params)
 	checkError(err)
 
 	return res, err
 }
 
+// CountEstimateMin is the minimal number of documents in the collection, according to the backend's
+// table statistics, for which Collection.Count may return an estimate instead of the exact count.
+// Smaller collections are cheap to count exactly.
+const CountEstimateMin = 100_000
+
+// CountParams represents the parameters of Collection.Count method.
+type CountParams struct {
+	Filter *types.Document
+
+	// If set and Filter is empty, the backend may use table statistics
+	// instead of counting all rows; see CountEstimateMin.
+	Estimate bool
+}
+
+// CountResult represents the results of Collection.Count method.
+type CountResult struct {
+	Count          int64
+	FilterPushdown bool
+}
+
+// Count returns the number of documents matching the filter without fetching them.
+//
+// If database or collection does not exist it returns 0.
+//
+// The CountResult's FilterPushdown field is set to true if the backend applied the whole filter exactly.
+// If it wasn't possible, that field should be set to false, and Count should be ignored;
+// the handler will count documents returned by Query instead.
+//
+// The Count is exact unless Estimate parameter is set and the filter is empty.
+func (cc *collectionContract) Count(ctx context.Context, params *CountParams) (*CountResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	if params == nil {
+		params = new(CountParams)
+	}
+
+	res, err := cc.c.Count(ctx, params)
+	checkError(err)
+
+	return res, err
+}
+
 // InsertAllParams represents the parameters of Collection.InsertAll method.
 type InsertAllParams struct {
 	Docs []*types.Document
@@ -190,6 +251,9 @@ type InsertAllResult struct{}
 // They will be frozen.
 //
 // Both database and collection may or may not exist; they should be created automatically if needed.
+//
+// Unique index violations are returned as ErrorCodeInsertDuplicateID errors
+// with the violated index, if it is known (see [NewDuplicateKeyError]).
 func (cc *collectionContract) InsertAll(ctx context.Context, params *InsertAllParams) (*InsertAllResult, error) {
 	defer observability.FuncCall(ctx)()
 
@@ -225,6 +289,8 @@ type UpdateAllResult struct {
 // They will be frozen.
 //
 // Database or collection may not exist; that's not an error.
+//
+// Unique index violations are returned the same way as by InsertAll.
 func (cc *collectionContract) UpdateAll(ctx context.Context, params *UpdateAllParams) (*UpdateAllResult, error) {
 	defer observability.FuncCall(ctx)()
 
@@ -233,7 +299,7 @@ func (cc *collectionContract) UpdateAll(ctx context.Context, params *UpdateAllPa
 	}
 
 	res, err := cc.c.UpdateAll(ctx, params)
-	checkError(err)
+	checkError(err, ErrorCodeInsertDuplicateID)
 
 	return res, err
 }
@@ -325,6 +391,29 @@ func (cc *collectionContract) Compact(ctx context.Context, params *CompactParams
 	return res, err
 }
 
+// ReIndexParams represents the parameters of Collection.ReIndex method.
+type ReIndexParams struct {
+	Indexes      []string // nil for all indexes
+	Concurrently bool
+}
+
+// ReIndexResult represents the results of Collection.ReIndex method.
+type ReIndexResult struct{}
+
+// ReIndex rebuilds the given indexes of the collection, or all of them.
+// Unknown index names are ignored.
+//
+// If concurrently is true, the operation should try to avoid blocking writes to the collection,
+// even if rebuilding takes longer.
+func (cc *collectionContract) ReIndex(ctx context.Context, params *ReIndexParams) (*ReIndexResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	res, err := cc.c.ReIndex(ctx, params)
+	checkError(err, ErrorCodeDatabaseDoesNotExist, ErrorCodeCollectionDoesNotExist)
+
+	return res, err
+}
+
 // ListIndexesParams represents the parameters of Collection.ListIndexes method.
 type ListIndexesParams struct{}
 
@@ -334,10 +423,84 @@ type ListIndexesResult struct {
 }
 
 // IndexInfo represents information about a single index.
+//
+// Options after Unique are stored by backends as is;
+// backends could use [IndexInfo.OptionsDocument] and [IndexInfo.SetOptions] for that.
 type IndexInfo struct {
 	Name   string
 	Key    []IndexKeyPair
 	Unique bool
+
+	Sparse                  bool
+	Hidden                  bool            // hidden indexes are not used by the query planner
+	ExpireAfterSeconds      *int32          // nil if not set
+	PartialFilterExpression *types.Document // nil if not set
+	Collation               *types.Document // nil if not set
+	WildcardProjection      *types.Document // nil if not set
+}
+
+// OptionsDocument returns index options after Unique as a document with MongoDB field names,
+// or nil if none of them are set.
+func (index *IndexInfo) OptionsDocument() *types.Document {
+	res := types.MakeDocument(0)
+
+	if index.Sparse {
+		res.Set("sparse", true)
+	}
+
+	if index.Hidden {
+		res.Set("hidden", true)
+	}
+
+	if index.ExpireAfterSeconds != nil {
+		res.Set("expireAfterSeconds", *index.ExpireAfterSeconds)
+	}
+
+	if index.PartialFilterExpression != nil {
+		res.Set("partialFilterExpression", index.PartialFilterExpression.DeepCopy())
+	}
+
+	if index.Collation != nil {
+		res.Set("collation", index.Collation.DeepCopy())
+	}
+
+	if index.WildcardProjection != nil {
+		res.Set("wildcardProjection", index.WildcardProjection.DeepCopy())
+	}
+
+	if res.Len() == 0 {
+		return nil
+	}
+
+	return res
+}
+
+// SetOptions sets index options from the document returned by [IndexInfo.OptionsDocument].
+// Nil document is allowed.
+func (index *IndexInfo) SetOptions(doc *types.Document) {
+	if doc == nil {
+		return
+	}
+
+	v, _ := doc.Get("sparse")
+	index.Sparse, _ = v.(bool)
+
+	v, _ = doc.Get("hidden")
+	index.Hidden, _ = v.(bool)
+
+	if v, _ = doc.Get("expireAfterSeconds"); v != nil {
+		expireAfterSeconds := v.(int32)
+		index.ExpireAfterSeconds = &expireAfterSeconds
+	}
+
+	v, _ = doc.Get("partialFilterExpression")
+	index.PartialFilterExpression, _ = v.(*types.Document)
+
+	v, _ = doc.Get("collation")
+	index.Collation, _ = v.(*types.Document)
+
+	v, _ = doc.Get("wildcardProjection")
+	index.WildcardProjection, _ = v.(*types.Document)
 }
 
 // IndexKeyPair consists of a field name and a sort order that are part of the index.
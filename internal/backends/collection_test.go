@@ -225,6 +225,30 @@ func TestCollectionInsertAllQueryExplain(t *testing.T) {
 				assert.True(t, explainRes.SortPushdown)
 			})
 
+			t.Run("Sample", func(t *testing.T) {
+				t.Parallel()
+
+				queryRes, err := coll.Query(ctx, &backends.QueryParams{Sample: 2})
+				require.NoError(t, err)
+
+				docs, err := iterator.ConsumeValues[struct{}, *types.Document](queryRes.Iter)
+				require.NoError(t, err)
+				require.Len(t, docs, 2)
+
+				ids := make([]any, len(insertDocs))
+				for i, doc := range insertDocs {
+					ids[i] = must.NotFail(doc.Get("_id"))
+				}
+
+				for _, doc := range docs {
+					assert.Contains(t, ids, must.NotFail(doc.Get("_id")))
+				}
+
+				explainRes, err := coll.Explain(ctx, &backends.ExplainParams{Sample: 2})
+				require.NoError(t, err)
+				assert.True(t, explainRes.SamplePushdown)
+			})
+
 			t.Run("NonCappedCollectionOnlyRecordID", func(t *testing.T) {
 				t.Parallel()
 
@@ -492,6 +516,66 @@ func TestCollectionStats(t *testing.T) {
 	}
 }
 
+func TestCollectionCount(t *testing.T) {
+	t.Parallel()
+
+	ctx := conninfo.Ctx(testutil.Ctx(t), conninfo.New())
+
+	for name, b := range testBackends(t) {
+		name, b := name, b
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbName, collName := testutil.DatabaseName(t), testutil.CollectionName(t)
+
+			db, err := b.Database(dbName)
+			require.NoError(t, err)
+
+			coll, err := db.Collection(collName)
+			require.NoError(t, err)
+
+			res, err := coll.Count(ctx, nil)
+			require.NoError(t, err)
+			assert.True(t, res.FilterPushdown)
+			assert.Zero(t, res.Count)
+
+			_, err = coll.InsertAll(ctx, &backends.InsertAllParams{
+				Docs: []*types.Document{
+					must.NotFail(types.NewDocument("_id", "a", "v", int32(1))),
+					must.NotFail(types.NewDocument("_id", "b", "v", int32(2))),
+					must.NotFail(types.NewDocument("_id", "c", "v", int32(2))),
+				},
+			})
+			require.NoError(t, err)
+
+			res, err = coll.Count(ctx, &backends.CountParams{Filter: must.NotFail(types.NewDocument())})
+			require.NoError(t, err)
+			assert.True(t, res.FilterPushdown)
+			assert.Equal(t, int64(3), res.Count)
+
+			// small collections are always counted exactly
+			res, err = coll.Count(ctx, &backends.CountParams{Estimate: true})
+			require.NoError(t, err)
+			assert.True(t, res.FilterPushdown)
+			assert.Equal(t, int64(3), res.Count)
+
+			res, err = coll.Count(ctx, &backends.CountParams{Filter: must.NotFail(types.NewDocument("_id", "b"))})
+			require.NoError(t, err)
+
+			if res.FilterPushdown {
+				assert.Equal(t, int64(1), res.Count)
+			}
+
+			res, err = coll.Count(ctx, &backends.CountParams{Filter: must.NotFail(types.NewDocument("v", int32(2)))})
+			require.NoError(t, err)
+
+			if res.FilterPushdown {
+				assert.Equal(t, int64(2), res.Count)
+			}
+		})
+	}
+}
+
 func TestCollectionCompact(t *testing.T) {
 	t.Parallel()
 
@@ -542,6 +626,89 @@ func TestCollectionCompact(t *testing.T) {
 	}
 }
 
+func TestCollectionReIndex(t *testing.T) {
+	t.Parallel()
+
+	ctx := conninfo.Ctx(testutil.Ctx(t), conninfo.New())
+
+	for name, b := range testBackends(t) {
+		name, b := name, b
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			t.Run("DatabaseDoesNotExist", func(t *testing.T) {
+				t.Parallel()
+
+				dbName, collName := testutil.DatabaseName(t), testutil.CollectionName(t)
+
+				db, err := b.Database(dbName)
+				require.NoError(t, err)
+
+				coll, err := db.Collection(collName)
+				require.NoError(t, err)
+
+				_, err = coll.ReIndex(ctx, nil)
+				assertErrorCode(t, err, backends.ErrorCodeDatabaseDoesNotExist)
+			})
+
+			t.Run("CollectionDoesNotExist", func(t *testing.T) {
+				t.Parallel()
+
+				dbName, collName := testutil.DatabaseName(t), testutil.CollectionName(t)
+				otherCollName := collName + "_other"
+
+				db, err := b.Database(dbName)
+				require.NoError(t, err)
+
+				// to create database
+				err = db.CreateCollection(ctx, &backends.CreateCollectionParams{
+					Name: otherCollName,
+				})
+				require.NoError(t, err)
+
+				coll, err := db.Collection(collName)
+				require.NoError(t, err)
+
+				_, err = coll.ReIndex(ctx, nil)
+				assertErrorCode(t, err, backends.ErrorCodeCollectionDoesNotExist)
+			})
+
+			t.Run("ReIndex", func(t *testing.T) {
+				t.Parallel()
+
+				dbName, collName := testutil.DatabaseName(t), testutil.CollectionName(t)
+
+				db, err := b.Database(dbName)
+				require.NoError(t, err)
+
+				coll, err := db.Collection(collName)
+				require.NoError(t, err)
+
+				_, err = coll.CreateIndexes(ctx, &backends.CreateIndexesParams{
+					Indexes: []backends.IndexInfo{{
+						Name: "v_1",
+						Key:  []backends.IndexKeyPair{{Field: "v"}},
+					}},
+				})
+				require.NoError(t, err)
+
+				_, err = coll.ReIndex(ctx, new(backends.ReIndexParams))
+				require.NoError(t, err)
+
+				_, err = coll.ReIndex(ctx, &backends.ReIndexParams{
+					Indexes:      []string{"v_1", "unknown"},
+					Concurrently: true,
+				})
+				require.NoError(t, err)
+
+				res, err := coll.ListIndexes(ctx, nil)
+				require.NoError(t, err)
+				require.Len(t, res.Indexes, 2)
+			})
+		})
+	}
+}
+
 func TestListCollections(t *testing.T) {
 	t.Parallel()
 
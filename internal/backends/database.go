@@ -180,13 +180,17 @@ func (dbc *databaseContract) DropCollection(ctx context.Context, params *DropCol
 
 // RenameCollectionParams represents the parameters of Database.RenameCollection method.
 type RenameCollectionParams struct {
-	OldName string
-	NewName string
+	OldName    string
+	NewName    string
+	DropTarget bool // drop existing collection with the new name instead of returning an error
 }
 
 // RenameCollection renames existing collection in the database.
 // Both old and new names should be valid.
 //
+// If DropTarget is true, the existing collection with the new name is dropped atomically
+// with renaming, so it is never observed missing.
+//
 // The errors for non-existing database and non-existing collection are the same.
 func (dbc *databaseContract) RenameCollection(ctx context.Context, params *RenameCollectionParams) error {
 	defer observability.FuncCall(ctx)()
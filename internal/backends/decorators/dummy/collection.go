@@ -55,6 +55,11 @@ func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams
 	return c.c.Explain(ctx, params)
 }
 
+// Count implements backends.Collection interface.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	return c.c.Count(ctx, params)
+}
+
 // Stats implements backends.Collection interface.
 func (c *collection) Stats(ctx context.Context, params *backends.CollectionStatsParams) (*backends.CollectionStatsResult, error) {
 	return c.c.Stats(ctx, params)
@@ -65,6 +70,11 @@ func (c *collection) Compact(ctx context.Context, params *backends.CompactParams
 	return c.c.Compact(ctx, params)
 }
 
+// ReIndex implements backends.Collection interface.
+func (c *collection) ReIndex(ctx context.Context, params *backends.ReIndexParams) (*backends.ReIndexResult, error) {
+	return c.c.ReIndex(ctx, params)
+}
+
 // ListIndexes implements backends.Collection interface.
 func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndexesParams) (*backends.ListIndexesResult, error) {
 	return c.c.ListIndexes(ctx, params)
@@ -0,0 +1,151 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package faults
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+)
+
+// collection implements backends.Collection interface by injecting faults
+// and delegating all methods to the wrapped collection.
+type collection struct {
+	origC backends.Collection
+	i     *Injector
+}
+
+// newCollection creates a new Collection that wraps the given collection.
+func newCollection(origC backends.Collection, i *Injector) backends.Collection {
+	return &collection{
+		origC: origC,
+		i:     i,
+	}
+}
+
+// Query implements backends.Collection interface.
+func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*backends.QueryResult, error) {
+	if err := c.i.inject(ctx, OpQuery); err != nil {
+		return nil, err
+	}
+
+	return c.origC.Query(ctx, params)
+}
+
+// InsertAll implements backends.Collection interface.
+func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) {
+	if err := c.i.inject(ctx, OpInsertAll); err != nil {
+		return nil, err
+	}
+
+	return c.origC.InsertAll(ctx, params)
+}
+
+// UpdateAll implements backends.Collection interface.
+func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllParams) (*backends.UpdateAllResult, error) {
+	if err := c.i.inject(ctx, OpUpdateAll); err != nil {
+		return nil, err
+	}
+
+	return c.origC.UpdateAll(ctx, params)
+}
+
+// DeleteAll implements backends.Collection interface.
+func (c *collection) DeleteAll(ctx context.Context, params *backends.DeleteAllParams) (*backends.DeleteAllResult, error) {
+	if err := c.i.inject(ctx, OpDeleteAll); err != nil {
+		return nil, err
+	}
+
+	return c.origC.DeleteAll(ctx, params)
+}
+
+// Explain implements backends.Collection interface.
+func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams) (*backends.ExplainResult, error) {
+	if err := c.i.inject(ctx, OpExplain); err != nil {
+		return nil, err
+	}
+
+	return c.origC.Explain(ctx, params)
+}
+
+// Count implements backends.Collection interface.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	if err := c.i.inject(ctx, OpCount); err != nil {
+		return nil, err
+	}
+
+	return c.origC.Count(ctx, params)
+}
+
+// Stats implements backends.Collection interface.
+func (c *collection) Stats(ctx context.Context, params *backends.CollectionStatsParams) (*backends.CollectionStatsResult, error) {
+	if err := c.i.inject(ctx, OpCollectionStats); err != nil {
+		return nil, err
+	}
+
+	return c.origC.Stats(ctx, params)
+}
+
+// Compact implements backends.Collection interface.
+func (c *collection) Compact(ctx context.Context, params *backends.CompactParams) (*backends.CompactResult, error) {
+	if err := c.i.inject(ctx, OpCompact); err != nil {
+		return nil, err
+	}
+
+	return c.origC.Compact(ctx, params)
+}
+
+// ReIndex implements backends.Collection interface.
+func (c *collection) ReIndex(ctx context.Context, params *backends.ReIndexParams) (*backends.ReIndexResult, error) {
+	if err := c.i.inject(ctx, OpReIndex); err != nil {
+		return nil, err
+	}
+
+	return c.origC.ReIndex(ctx, params)
+}
+
+// ListIndexes implements backends.Collection interface.
+func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndexesParams) (*backends.ListIndexesResult, error) {
+	if err := c.i.inject(ctx, OpListIndexes); err != nil {
+		return nil, err
+	}
+
+	return c.origC.ListIndexes(ctx, params)
+}
+
+// CreateIndexes implements backends.Collection interface.
+//
+//nolint:lll // for readability
+func (c *collection) CreateIndexes(ctx context.Context, params *backends.CreateIndexesParams) (*backends.CreateIndexesResult, error) {
+	if err := c.i.inject(ctx, OpCreateIndexes); err != nil {
+		return nil, err
+	}
+
+	return c.origC.CreateIndexes(ctx, params)
+}
+
+// DropIndexes implements backends.Collection interface.
+func (c *collection) DropIndexes(ctx context.Context, params *backends.DropIndexesParams) (*backends.DropIndexesResult, error) {
+	if err := c.i.inject(ctx, OpDropIndexes); err != nil {
+		return nil, err
+	}
+
+	return c.origC.DropIndexes(ctx, params)
+}
+
+// check interfaces
+var (
+	_ backends.Collection = (*collection)(nil)
+)
@@ -0,0 +1,216 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package faults provides decorators that inject backend failures for testing.
+//
+// Injected errors look like errors returned by real backends on failures:
+// connection drops and timeouts are net.Error values,
+// serialization failures are errors with SQLSTATE code (like *pgconn.PgError).
+// That allows tests to check how the handler converts them to MongoDB errors.
+package faults
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/util/ctxutil"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// Op represents a backend operation type.
+type Op string
+
+// Operation types.
+const (
+	OpStatus        = Op("Status")
+	OpListDatabases = Op("ListDatabases")
+	OpDropDatabase  = Op("DropDatabase")
+
+	OpListCollections  = Op("ListCollections")
+	OpCreateCollection = Op("CreateCollection")
+	OpDropCollection   = Op("DropCollection")
+	OpRenameCollection = Op("RenameCollection")
+	OpDatabaseStats    = Op("DatabaseStats")
+
+	OpQuery           = Op("Query")
+	OpInsertAll       = Op("InsertAll")
+	OpUpdateAll       = Op("UpdateAll")
+	OpDeleteAll       = Op("DeleteAll")
+	OpExplain         = Op("Explain")
+	OpCount           = Op("Count")
+	OpCollectionStats = Op("CollectionStats")
+	OpCompact         = Op("Compact")
+	OpReIndex         = Op("ReIndex")
+	OpListIndexes     = Op("ListIndexes")
+	OpCreateIndexes   = Op("CreateIndexes")
+	OpDropIndexes     = Op("DropIndexes")
+)
+
+// Kind represents a kind of injected fault.
+type Kind int
+
+const (
+	_ Kind = iota
+
+	// KindConnectionDrop makes operation fail as if the connection to the database was dropped.
+	KindConnectionDrop
+
+	// KindTimeout makes operation fail as if the database did not respond in time.
+	KindTimeout
+
+	// KindSerializationFailure makes operation fail as if the transaction could not be serialized
+	// due to concurrent updates.
+	KindSerializationFailure
+
+	// KindSlow makes operation succeed after Fault's Delay.
+	KindSlow
+)
+
+// sqlStateSerializationFailure is the SQLSTATE code for serialization failures.
+const sqlStateSerializationFailure = "40001"
+
+// Fault represents a fault injected into operations of a single type.
+type Fault struct {
+	Kind Kind
+
+	// Delay before operation fails or succeeds.
+	// The operation fails with context error if the context is canceled earlier.
+	Delay time.Duration
+
+	// Times is the number of times the fault is injected; 0 means every time.
+	Times int
+}
+
+// Injector holds faults for operation types.
+//
+// It is safe for concurrent use, so faults could be changed while the backend is used.
+type Injector struct {
+	rw       sync.RWMutex
+	faults   map[Op]*Fault
+	injected map[Op]int
+}
+
+// NewInjector creates a new Injector without any faults.
+func NewInjector() *Injector {
+	return &Injector{
+		faults:   map[Op]*Fault{},
+		injected: map[Op]int{},
+	}
+}
+
+// Set sets fault for the given operation type, replacing the previous one.
+//
+// It also resets the number of injected faults for that operation type.
+func (i *Injector) Set(op Op, f Fault) {
+	if f.Kind == 0 {
+		panic("fault kind must be set")
+	}
+
+	i.rw.Lock()
+	defer i.rw.Unlock()
+
+	i.faults[op] = &f
+	i.injected[op] = 0
+}
+
+// Clear removes all faults and resets counters.
+func (i *Injector) Clear() {
+	i.rw.Lock()
+	defer i.rw.Unlock()
+
+	clear(i.faults)
+	clear(i.injected)
+}
+
+// Injected returns the number of faults injected for the given operation type.
+func (i *Injector) Injected(op Op) int {
+	i.rw.RLock()
+	defer i.rw.RUnlock()
+
+	return i.injected[op]
+}
+
+// inject returns an error if the fault should be injected for the given operation type.
+func (i *Injector) inject(ctx context.Context, op Op) error {
+	// nil injector is valid and does not inject anything
+	if i == nil {
+		return nil
+	}
+
+	i.rw.Lock()
+
+	f := i.faults[op]
+	if f == nil || (f.Times > 0 && i.injected[op] >= f.Times) {
+		i.rw.Unlock()
+		return nil
+	}
+
+	i.injected[op]++
+
+	i.rw.Unlock()
+
+	if f.Delay > 0 {
+		ctxutil.Sleep(ctx, f.Delay)
+
+		if err := ctx.Err(); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	switch f.Kind {
+	case KindConnectionDrop:
+		return lazyerrors.Error(&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET})
+
+	case KindTimeout:
+		return lazyerrors.Error(&net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded})
+
+	case KindSerializationFailure:
+		return lazyerrors.Error(&sqlStateError{
+			code: sqlStateSerializationFailure,
+			msg:  "could not serialize access due to concurrent update",
+		})
+
+	case KindSlow:
+		return nil
+
+	default:
+		panic(fmt.Sprintf("unexpected fault kind %d", f.Kind))
+	}
+}
+
+// sqlStateError represents a database error with SQLSTATE code.
+type sqlStateError struct {
+	code string
+	msg  string
+}
+
+// Error implements error interface.
+func (e *sqlStateError) Error() string {
+	return fmt.Sprintf("ERROR: %s (SQLSTATE %s)", e.msg, e.code)
+}
+
+// SQLState returns SQLSTATE code, like *pgconn.PgError.
+func (e *sqlStateError) SQLState() string {
+	return e.code
+}
+
+// check interfaces
+var (
+	_ error = (*sqlStateError)(nil)
+)
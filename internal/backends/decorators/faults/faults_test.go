@@ -0,0 +1,113 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package faults
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestInjector(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	t.Run("Nil", func(t *testing.T) {
+		t.Parallel()
+
+		var i *Injector
+		assert.NoError(t, i.inject(ctx, OpQuery))
+	})
+
+	t.Run("Kinds", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewInjector()
+		i.Set(OpQuery, Fault{Kind: KindConnectionDrop})
+		i.Set(OpInsertAll, Fault{Kind: KindTimeout})
+		i.Set(OpUpdateAll, Fault{Kind: KindSerializationFailure})
+		i.Set(OpDeleteAll, Fault{Kind: KindSlow})
+
+		var netErr net.Error
+
+		err := i.inject(ctx, OpQuery)
+		require.ErrorAs(t, err, &netErr)
+		assert.False(t, netErr.Timeout())
+
+		err = i.inject(ctx, OpInsertAll)
+		require.ErrorAs(t, err, &netErr)
+		assert.True(t, netErr.Timeout())
+
+		var sqlStateErr interface{ SQLState() string }
+		err = i.inject(ctx, OpUpdateAll)
+		require.ErrorAs(t, err, &sqlStateErr)
+		assert.Equal(t, "40001", sqlStateErr.SQLState())
+
+		assert.NoError(t, i.inject(ctx, OpDeleteAll))
+		assert.NoError(t, i.inject(ctx, OpExplain))
+
+		assert.Equal(t, 1, i.Injected(OpQuery))
+		assert.Equal(t, 1, i.Injected(OpDeleteAll))
+		assert.Equal(t, 0, i.Injected(OpExplain))
+
+		i.Clear()
+
+		assert.NoError(t, i.inject(ctx, OpQuery))
+		assert.Equal(t, 0, i.Injected(OpQuery))
+	})
+
+	t.Run("Times", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewInjector()
+		i.Set(OpQuery, Fault{Kind: KindConnectionDrop, Times: 2})
+
+		assert.Error(t, i.inject(ctx, OpQuery))
+		assert.Error(t, i.inject(ctx, OpQuery))
+		assert.NoError(t, i.inject(ctx, OpQuery))
+		assert.Equal(t, 2, i.Injected(OpQuery))
+
+		i.Set(OpQuery, Fault{Kind: KindConnectionDrop, Times: 1})
+
+		assert.Error(t, i.inject(ctx, OpQuery))
+		assert.NoError(t, i.inject(ctx, OpQuery))
+	})
+
+	t.Run("Delay", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewInjector()
+		i.Set(OpQuery, Fault{Kind: KindSlow, Delay: 50 * time.Millisecond})
+
+		start := time.Now()
+		require.NoError(t, i.inject(ctx, OpQuery))
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+		i.Set(OpQuery, Fault{Kind: KindSlow, Delay: time.Minute})
+
+		cancelCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		err := i.inject(cancelCtx, OpQuery)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offload
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// NewBackendParams represents the parameters of NewBackend function.
+//
+//nolint:vet // for readability
+type NewBackendParams struct {
+	Storage   Storage
+	Threshold int // in bytes
+	L         *zap.Logger
+}
+
+// backend implements backends.Backend interface by delegating all methods to the wrapped backend.
+type backend struct {
+	origB backends.Backend
+	o     *offloader
+	l     *zap.Logger
+}
+
+// NewBackend creates a new Backend that wraps the given backend.
+func NewBackend(origB backends.Backend, params *NewBackendParams) backends.Backend {
+	return &backend{
+		origB: origB,
+		o: &offloader{
+			s:         params.Storage,
+			threshold: params.Threshold,
+		},
+		l: params.L,
+	}
+}
+
+// Close implements backends.Backend interface.
+func (b *backend) Close() {
+	b.origB.Close()
+}
+
+// Status implements backends.Backend interface.
+func (b *backend) Status(ctx context.Context, params *backends.StatusParams) (*backends.StatusResult, error) {
+	return b.origB.Status(ctx, params)
+}
+
+// Database implements backends.Backend interface.
+func (b *backend) Database(name string) (backends.Database, error) {
+	origDB, err := b.origB.Database(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDatabase(origDB, b.o, b.l), nil
+}
+
+// ListDatabases implements backends.Backend interface.
+//
+//nolint:lll // for readability
+func (b *backend) ListDatabases(ctx context.Context, params *backends.ListDatabasesParams) (*backends.ListDatabasesResult, error) {
+	return b.origB.ListDatabases(ctx, params)
+}
+
+// DropDatabase implements backends.Backend interface.
+func (b *backend) DropDatabase(ctx context.Context, params *backends.DropDatabaseParams) error {
+	origDB, err := b.origB.Database(params.Name)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	db := &database{origDB: origDB, o: b.o, l: b.l}
+
+	list, err := origDB.ListCollections(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	var keys []string
+
+	for _, c := range list.Collections {
+		var cKeys []string
+		if cKeys, err = db.collectionRefs(ctx, c.Name); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		keys = append(keys, cKeys...)
+	}
+
+	if err = b.origB.DropDatabase(ctx, params); err != nil {
+		return err
+	}
+
+	db.deleteObjects(ctx, keys)
+
+	return nil
+}
+
+// Describe implements prometheus.Collector.
+func (b *backend) Describe(ch chan<- *prometheus.Desc) {
+	b.origB.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (b *backend) Collect(ch chan<- prometheus.Metric) {
+	b.origB.Collect(ch)
+}
+
+// check interfaces
+var (
+	_ backends.Backend = (*backend)(nil)
+)
@@ -0,0 +1,352 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offload
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/observability"
+)
+
+// collection implements backends.Collection interface by offloading large binary values of the wrapped collection.
+type collection struct {
+	origC backends.Collection
+	o     *offloader
+	l     *zap.Logger
+}
+
+// newCollection creates a new Collection that wraps the given collection.
+func newCollection(origC backends.Collection, o *offloader, l *zap.Logger) backends.Collection {
+	return &collection{
+		origC: origC,
+		o:     o,
+		l:     l,
+	}
+}
+
+// Query implements backends.Collection interface.
+func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*backends.QueryResult, error) {
+	res, err := c.origC.Query(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	origIter := res.Iter
+
+	// the iterator could be used after the initial query is done (by getMore, for example)
+	restoreCtx := context.WithoutCancel(ctx)
+
+	iter := iterator.ForFunc(func() (struct{}, *types.Document, error) {
+		_, doc, err := origIter.Next()
+		if err != nil {
+			// do not wrap to keep iterator.ErrIteratorDone as is
+			return struct{}{}, nil, err
+		}
+
+		if _, err = c.o.restore(restoreCtx, doc); err != nil {
+			return struct{}{}, nil, lazyerrors.Error(err)
+		}
+
+		return struct{}{}, doc, nil
+	})
+
+	return &backends.QueryResult{
+		Iter: iterator.WithClose(iter, func() {
+			iter.Close()
+			origIter.Close()
+		}),
+	}, nil
+}
+
+// InsertAll implements backends.Collection interface.
+func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	docs, err := c.offloadDocuments(ctx, params.Docs, nil)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := c.origC.InsertAll(ctx, &backends.InsertAllParams{Docs: docs})
+	if err != nil {
+		c.deleteObjects(ctx, refsDiff(docs, nil))
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// UpdateAll implements backends.Collection interface.
+func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllParams) (*backends.UpdateAllResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	ids := make([]any, len(params.Docs))
+	for i, doc := range params.Docs {
+		ids[i], _ = doc.Get("_id")
+	}
+
+	// references of stored documents are reused and removed below
+	defer c.o.lockIDs(ids)()
+
+	prev, err := c.storedDocuments(ctx, ids, nil)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	docs, err := c.offloadDocuments(ctx, params.Docs, prev)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := c.origC.UpdateAll(ctx, &backends.UpdateAllParams{Docs: docs})
+	if err != nil {
+		c.deleteObjects(ctx, refsDiff(docs, prev))
+		return nil, err
+	}
+
+	c.deleteObjects(ctx, refsDiff(prev, docs))
+
+	return res, nil
+}
+
+// DeleteAll implements backends.Collection interface.
+func (c *collection) DeleteAll(ctx context.Context, params *backends.DeleteAllParams) (*backends.DeleteAllResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	// _id values of documents with given record IDs are not known in advance
+	var ids []any
+	if params.RecordIDs == nil {
+		ids = params.IDs
+	}
+
+	defer c.o.lockIDs(ids)()
+
+	prev, err := c.storedDocuments(ctx, params.IDs, params.RecordIDs)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := c.origC.DeleteAll(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	c.deleteObjects(ctx, refsDiff(prev, nil))
+
+	return res, nil
+}
+
+// Explain implements backends.Collection interface.
+func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams) (*backends.ExplainResult, error) {
+	return c.origC.Explain(ctx, params)
+}
+
+// Count implements backends.Collection interface.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	return c.origC.Count(ctx, params)
+}
+
+// Stats implements backends.Collection interface.
+func (c *collection) Stats(ctx context.Context, params *backends.CollectionStatsParams) (*backends.CollectionStatsResult, error) {
+	return c.origC.Stats(ctx, params)
+}
+
+// Compact implements backends.Collection interface.
+func (c *collection) Compact(ctx context.Context, params *backends.CompactParams) (*backends.CompactResult, error) {
+	return c.origC.Compact(ctx, params)
+}
+
+// ReIndex implements backends.Collection interface.
+func (c *collection) ReIndex(ctx context.Context, params *backends.ReIndexParams) (*backends.ReIndexResult, error) {
+	return c.origC.ReIndex(ctx, params)
+}
+
+// ListIndexes implements backends.Collection interface.
+func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndexesParams) (*backends.ListIndexesResult, error) {
+	return c.origC.ListIndexes(ctx, params)
+}
+
+// CreateIndexes implements backends.Collection interface.
+func (c *collection) CreateIndexes(ctx context.Context, params *backends.CreateIndexesParams) (*backends.CreateIndexesResult, error) { //nolint:lll // for readability
+	return c.origC.CreateIndexes(ctx, params)
+}
+
+// DropIndexes implements backends.Collection interface.
+func (c *collection) DropIndexes(ctx context.Context, params *backends.DropIndexesParams) (*backends.DropIndexesResult, error) {
+	return c.origC.DropIndexes(ctx, params)
+}
+
+// offloadDocuments returns documents with large binary values replaced with references.
+//
+// References of stored documents with the same _id in prev are reused for equal values.
+// Given documents are not modified.
+func (c *collection) offloadDocuments(ctx context.Context, docs, prev []*types.Document) ([]*types.Document, error) {
+	res := make([]*types.Document, len(docs))
+
+	for i, doc := range docs {
+		id, _ := doc.Get("_id")
+
+		var p *types.Document
+
+		for _, pd := range prev {
+			if pid, _ := pd.Get("_id"); id != nil && types.Identical(id, pid) {
+				p = pd
+				break
+			}
+		}
+
+		var err error
+		if res[i], err = c.o.offloadDocument(ctx, doc, p); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return res, nil
+}
+
+// storedBatchSize is the maximum number of _id values queried at once by storedDocuments.
+const storedBatchSize = 100
+
+// storedDocuments returns stored documents (with references) with the given _id values or record IDs.
+//
+// Only documents that reference objects are returned.
+func (c *collection) storedDocuments(ctx context.Context, ids []any, recordIDs []int64) ([]*types.Document, error) {
+	if recordIDs != nil {
+		return c.storedDocumentsByRecordIDs(ctx, recordIDs)
+	}
+
+	ids = slices.DeleteFunc(slices.Clone(ids), func(id any) bool { return id == nil })
+
+	var res []*types.Document
+
+	for len(ids) > 0 {
+		batch := ids[:min(len(ids), storedBatchSize)]
+		ids = ids[len(batch):]
+
+		filter := must.NotFail(types.NewDocument(
+			"_id", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray(batch...)))),
+		))
+
+		qr, err := c.origC.Query(ctx, &backends.QueryParams{Filter: filter})
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		docs, err := iterator.ConsumeValues(qr.Iter)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		for _, doc := range docs {
+			if refs(doc) == nil {
+				continue
+			}
+
+			// backends may return more documents than filtered
+			id, _ := doc.Get("_id")
+			if !slices.ContainsFunc(batch, func(v any) bool { return types.Identical(v, id) }) {
+				continue
+			}
+
+			res = append(res, doc)
+		}
+	}
+
+	return res, nil
+}
+
+// storedDocumentsByRecordIDs returns stored documents (with references) with the given record IDs.
+//
+// Only documents that reference objects are returned.
+func (c *collection) storedDocumentsByRecordIDs(ctx context.Context, recordIDs []int64) ([]*types.Document, error) {
+	// record IDs are increasing, so documents are scanned in that order until the largest given one
+	maxRecordID := slices.Max(recordIDs)
+
+	qr, err := c.origC.Query(ctx, &backends.QueryParams{
+		Sort: must.NotFail(types.NewDocument("$natural", int64(1))),
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	defer qr.Iter.Close()
+
+	var res []*types.Document
+
+	for {
+		_, doc, err := qr.Iter.Next()
+		if err != nil {
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				return res, nil
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		if doc.RecordID() > maxRecordID {
+			return res, nil
+		}
+
+		if refs(doc) != nil && slices.Contains(recordIDs, doc.RecordID()) {
+			res = append(res, doc)
+		}
+	}
+}
+
+// deleteObjects removes objects with the given keys, logging errors.
+//
+// Errors are not returned, because objects are removed after the operation is done.
+func (c *collection) deleteObjects(ctx context.Context, keys []string) {
+	if err := c.o.deleteObjects(context.WithoutCancel(ctx), keys); err != nil {
+		c.l.Warn("Failed to remove offloaded objects", zap.Error(err))
+	}
+}
+
+// refsDiff returns keys of objects referenced by docs, but not by other documents.
+func refsDiff(docs, other []*types.Document) []string {
+	keep := map[string]struct{}{}
+
+	for _, doc := range other {
+		for _, key := range refs(doc) {
+			keep[key] = struct{}{}
+		}
+	}
+
+	var res []string
+
+	for _, doc := range docs {
+		for _, key := range refs(doc) {
+			if _, ok := keep[key]; !ok {
+				res = append(res, key)
+			}
+		}
+	}
+
+	return res
+}
+
+// check interfaces
+var (
+	_ backends.Collection = (*collection)(nil)
+)
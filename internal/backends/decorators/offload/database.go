@@ -0,0 +1,149 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offload
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// database implements backends.Database interface by delegating all methods to the wrapped database.
+type database struct {
+	origDB backends.Database
+	o      *offloader
+	l      *zap.Logger
+}
+
+// newDatabase creates a new Database that wraps the given database.
+func newDatabase(origDB backends.Database, o *offloader, l *zap.Logger) backends.Database {
+	return &database{
+		origDB: origDB,
+		o:      o,
+		l:      l,
+	}
+}
+
+// Collection implements backends.Database interface.
+func (db *database) Collection(name string) (backends.Collection, error) {
+	origC, err := db.origDB.Collection(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCollection(origC, db.o, db.l), nil
+}
+
+// ListCollections implements backends.Database interface.
+//
+//nolint:lll // for readability
+func (db *database) ListCollections(ctx context.Context, params *backends.ListCollectionsParams) (*backends.ListCollectionsResult, error) {
+	return db.origDB.ListCollections(ctx, params)
+}
+
+// CreateCollection implements backends.Database interface.
+func (db *database) CreateCollection(ctx context.Context, params *backends.CreateCollectionParams) error {
+	return db.origDB.CreateCollection(ctx, params)
+}
+
+// DropCollection implements backends.Database interface.
+func (db *database) DropCollection(ctx context.Context, params *backends.DropCollectionParams) error {
+	keys, err := db.collectionRefs(ctx, params.Name)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = db.origDB.DropCollection(ctx, params); err != nil {
+		return err
+	}
+
+	db.deleteObjects(ctx, keys)
+
+	return nil
+}
+
+// RenameCollection implements backends.Database interface.
+func (db *database) RenameCollection(ctx context.Context, params *backends.RenameCollectionParams) error {
+	var keys []string
+
+	if params.DropTarget {
+		var err error
+		if keys, err = db.collectionRefs(ctx, params.NewName); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if err := db.origDB.RenameCollection(ctx, params); err != nil {
+		return err
+	}
+
+	db.deleteObjects(ctx, keys)
+
+	return nil
+}
+
+// Stats implements backends.Database interface.
+func (db *database) Stats(ctx context.Context, params *backends.DatabaseStatsParams) (*backends.DatabaseStatsResult, error) {
+	return db.origDB.Stats(ctx, params)
+}
+
+// collectionRefs returns keys of all objects referenced by documents of the given collection.
+func (db *database) collectionRefs(ctx context.Context, name string) ([]string, error) {
+	c, err := db.origDB.Collection(name)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	qr, err := c.Query(ctx, nil)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	defer qr.Iter.Close()
+
+	var res []string
+
+	for {
+		_, doc, err := qr.Iter.Next()
+		if err != nil {
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				return res, nil
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		res = append(res, refs(doc)...)
+	}
+}
+
+// deleteObjects removes objects with the given keys, logging errors.
+//
+// Errors are not returned, because objects are removed after the operation is done.
+func (db *database) deleteObjects(ctx context.Context, keys []string) {
+	if err := db.o.deleteObjects(context.WithoutCancel(ctx), keys); err != nil {
+		db.l.Warn("Failed to remove offloaded objects", zap.Error(err))
+	}
+}
+
+// check interfaces
+var (
+	_ backends.Database = (*database)(nil)
+)
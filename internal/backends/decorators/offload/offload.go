@@ -0,0 +1,414 @@
+// Package offload provides decorators that store large binary values in S3-compatible object storage.
+//
+// Binary values larger than the threshold are uploaded to the object storage
+// and replaced with references in documents stored by the wrapped backend.
+// References are replaced back with the original values when documents are queried,
+// so clients never see them.
+//
+// Every object is referenced by a single document.
+// Objects are removed when documents that reference them are updated or deleted,
+// or when their collections or databases are dropped.
+// Updates and deletions of documents with the same _id are serialized,
+// so objects referenced by a concurrently written document are never removed.
+//
+// References to equal values contain different object keys,
+// so unique indexes do not enforce uniqueness of offloaded values.
+// _id values are never offloaded for that reason.
+package offload
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Storage represents object storage.
+type Storage interface {
+	// Put stores the object with the given key, replacing the existing one.
+	Put(ctx context.Context, key string, data []byte) error
+
+	// Get returns the object with the given key.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Delete removes the object with the given key.
+	// Removing a non-existing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// refMagic is the prefix of data of binary values stored by offloader in place of some client values.
+//
+// It is followed by a single byte:
+//   - refKind for a reference; it is followed by the original value's subtype and the object key;
+//   - escapedKind for a client value of user-defined subtype that starts with refMagic;
+//     it is followed by the original value's data.
+//
+// Client values are escaped so they are never confused with references.
+var refMagic = []byte("\x00ferretdb-offload\x00")
+
+const (
+	refKind     = 'r'
+	escapedKind = 'e'
+)
+
+// makeRef returns a reference to the object with the given key for the value with the given subtype.
+func makeRef(key string, subtype types.BinarySubtype) types.Binary {
+	b := make([]byte, 0, len(refMagic)+2+len(key))
+	b = append(b, refMagic...)
+	b = append(b, refKind, byte(subtype))
+	b = append(b, key...)
+
+	return types.Binary{
+		B:       b,
+		Subtype: types.BinaryUser,
+	}
+}
+
+// parseRef returns the object key and the original subtype if the given value is a reference.
+func parseRef(v types.Binary) (string, types.BinarySubtype, bool) {
+	if v.Subtype != types.BinaryUser || !bytes.HasPrefix(v.B, refMagic) || len(v.B) <= len(refMagic)+2 {
+		return "", 0, false
+	}
+
+	if v.B[len(refMagic)] != refKind {
+		return "", 0, false
+	}
+
+	return string(v.B[len(refMagic)+2:]), types.BinarySubtype(v.B[len(refMagic)+1]), true
+}
+
+// needsEscape returns true if the given client value could be confused with a value stored by offloader.
+func needsEscape(v types.Binary) bool {
+	return v.Subtype == types.BinaryUser && bytes.HasPrefix(v.B, refMagic)
+}
+
+// escape returns a value that is stored in place of the given client value that needs escaping.
+func escape(v types.Binary) types.Binary {
+	b := make([]byte, 0, len(refMagic)+1+len(v.B))
+	b = append(b, refMagic...)
+	b = append(b, escapedKind)
+	b = append(b, v.B...)
+
+	return types.Binary{
+		B:       b,
+		Subtype: types.BinaryUser,
+	}
+}
+
+// unescape returns the original client value if the given value is escaped.
+func unescape(v types.Binary) (types.Binary, bool) {
+	if v.Subtype != types.BinaryUser || !bytes.HasPrefix(v.B, refMagic) || len(v.B) <= len(refMagic) {
+		return v, false
+	}
+
+	if v.B[len(refMagic)] != escapedKind {
+		return v, false
+	}
+
+	return types.Binary{B: v.B[len(refMagic)+1:], Subtype: types.BinaryUser}, true
+}
+
+// makeKey returns a new unique object key for data with the given hex-encoded SHA-256 hash.
+//
+// The key starts with that hash, so references could be reused for equal values.
+func makeKey(hash string) string {
+	var r [16]byte
+	must.NotFail(rand.Read(r[:]))
+
+	return hash + "-" + hex.EncodeToString(r[:])
+}
+
+// keyHash returns the SHA-256 hash part of the object key.
+func keyHash(key string) string {
+	h, _, _ := strings.Cut(key, "-")
+	return h
+}
+
+// refs returns keys of all objects referenced by the given value.
+func refs(v any) []string {
+	var res []string
+
+	switch v := v.(type) {
+	case *types.Document:
+		for _, f := range v.Keys() {
+			res = append(res, refs(must.NotFail(v.Get(f)))...)
+		}
+
+	case *types.Array:
+		for i := 0; i < v.Len(); i++ {
+			res = append(res, refs(must.NotFail(v.Get(i)))...)
+		}
+
+	case types.Binary:
+		if key, _, ok := parseRef(v); ok {
+			res = append(res, key)
+		}
+	}
+
+	return res
+}
+
+// idLockStripes is the number of mutexes used to serialize writes of documents with the same _id.
+const idLockStripes = 64
+
+// offloader stores large binary values in object storage.
+type offloader struct {
+	s         Storage
+	threshold int
+
+	// idLocks are locked by _id values hashes, see lockIDs
+	idLocks [idLockStripes]sync.Mutex
+}
+
+// lockIDs locks writes of documents with the given _id values and returns a function that unlocks them.
+// If ids is nil, writes of all documents are locked.
+//
+// Documents of different collections and with different _id values may share locks.
+func (o *offloader) lockIDs(ids []any) func() {
+	stripes := make([]int, 0, idLockStripes)
+
+	if ids == nil {
+		for i := range o.idLocks {
+			stripes = append(stripes, i)
+		}
+	} else {
+		for _, id := range ids {
+			var i int
+
+			if id != nil {
+				h := fnv.New32a()
+				_, _ = h.Write([]byte(types.FormatAnyValue(id)))
+				i = int(h.Sum32() % idLockStripes)
+			}
+
+			if !slices.Contains(stripes, i) {
+				stripes = append(stripes, i)
+			}
+		}
+
+		// lock in the same order to avoid deadlocks
+		slices.Sort(stripes)
+	}
+
+	for _, i := range stripes {
+		o.idLocks[i].Lock()
+	}
+
+	return func() {
+		for _, i := range stripes {
+			o.idLocks[i].Unlock()
+		}
+	}
+}
+
+// offloadDocument returns a document with large binary values replaced with references,
+// and client values that need escaping escaped.
+//
+// References of the given previous version of the document (that may be nil) are reused for equal values;
+// other values are uploaded as new objects.
+// The _id value is not offloaded, so the backend could enforce its uniqueness.
+//
+// The given document is not modified; if there are no values to replace, it is returned as is.
+func (o *offloader) offloadDocument(ctx context.Context, doc, prev *types.Document) (*types.Document, error) {
+	keys := map[string]string{}
+
+	if prev != nil {
+		for _, key := range refs(prev) {
+			keys[keyHash(key)] = key
+		}
+	}
+
+	res := types.MakeDocument(doc.Len())
+	res.SetRecordID(doc.RecordID())
+
+	var changed bool
+
+	for _, f := range doc.Keys() {
+		fv := must.NotFail(doc.Get(f))
+
+		nv, c, err := o.offload(ctx, fv, keys, f == "_id")
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		changed = changed || c
+
+		res.Set(f, nv)
+	}
+
+	if !changed {
+		return doc, nil
+	}
+
+	return res, nil
+}
+
+// offload returns a value with large binary values replaced with references,
+// and client values that need escaping escaped, and true if there were such values.
+// If keep is true, binary values are only escaped.
+//
+// The given map of SHA-256 hashes of values to object keys is used to reuse objects and updated with new ones.
+//
+// Documents and arrays are copied only if they contain replaced values.
+func (o *offloader) offload(ctx context.Context, v any, keys map[string]string, keep bool) (any, bool, error) {
+	switch v := v.(type) {
+	case *types.Document:
+		var res *types.Document
+
+		for i, f := range v.Keys() {
+			fv := must.NotFail(v.Get(f))
+
+			nv, changed, err := o.offload(ctx, fv, keys, keep)
+			if err != nil {
+				return nil, false, lazyerrors.Error(err)
+			}
+
+			if res == nil && changed {
+				res = types.MakeDocument(v.Len())
+				res.SetRecordID(v.RecordID())
+
+				for _, k := range v.Keys()[:i] {
+					res.Set(k, must.NotFail(v.Get(k)))
+				}
+			}
+
+			if res != nil {
+				res.Set(f, nv)
+			}
+		}
+
+		if res == nil {
+			return v, false, nil
+		}
+
+		return res, true, nil
+
+	case *types.Array:
+		var res *types.Array
+
+		for i := 0; i < v.Len(); i++ {
+			ev := must.NotFail(v.Get(i))
+
+			nv, changed, err := o.offload(ctx, ev, keys, keep)
+			if err != nil {
+				return nil, false, lazyerrors.Error(err)
+			}
+
+			if res == nil && changed {
+				res = types.MakeArray(v.Len())
+
+				for j := 0; j < i; j++ {
+					res.Append(must.NotFail(v.Get(j)))
+				}
+			}
+
+			if res != nil {
+				res.Append(nv)
+			}
+		}
+
+		if res == nil {
+			return v, false, nil
+		}
+
+		return res, true, nil
+
+	case types.Binary:
+		if keep || len(v.B) <= o.threshold {
+			if needsEscape(v) {
+				return escape(v), true, nil
+			}
+
+			return v, false, nil
+		}
+
+		h := sha256.Sum256(v.B)
+		hash := hex.EncodeToString(h[:])
+
+		key, ok := keys[hash]
+		if !ok {
+			key = makeKey(hash)
+
+			if err := o.s.Put(ctx, key, v.B); err != nil {
+				return nil, false, lazyerrors.Error(err)
+			}
+
+			keys[hash] = key
+		}
+
+		return makeRef(key, v.Subtype), true, nil
+
+	default:
+		return v, false, nil
+	}
+}
+
+// restore returns a value with references replaced with the original values.
+//
+// Documents and arrays are modified in place.
+func (o *offloader) restore(ctx context.Context, v any) (any, error) {
+	switch v := v.(type) {
+	case *types.Document:
+		for _, f := range v.Keys() {
+			nv, err := o.restore(ctx, must.NotFail(v.Get(f)))
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			v.Set(f, nv)
+		}
+
+	case *types.Array:
+		for i := 0; i < v.Len(); i++ {
+			nv, err := o.restore(ctx, must.NotFail(v.Get(i)))
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			must.NoError(v.Set(i, nv))
+		}
+
+	case types.Binary:
+		if orig, ok := unescape(v); ok {
+			return orig, nil
+		}
+
+		key, subtype, ok := parseRef(v)
+		if !ok {
+			return v, nil
+		}
+
+		data, err := o.s.Get(ctx, key)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		return types.Binary{B: data, Subtype: subtype}, nil
+	}
+
+	return v, nil
+}
+
+// deleteObjects removes objects with the given keys.
+//
+// It tries to remove all of them and returns the first error, if any.
+func (o *offloader) deleteObjects(ctx context.Context, keys []string) error {
+	var res error
+
+	for _, key := range keys {
+		if err := o.s.Delete(ctx, key); err != nil && res == nil {
+			res = lazyerrors.Error(err)
+		}
+	}
+
+	return res
+}
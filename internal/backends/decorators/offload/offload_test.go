@@ -0,0 +1,341 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offload
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/state"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// memoryStorage implements Storage interface in memory for tests.
+type memoryStorage struct {
+	m       sync.Mutex
+	objects map[string][]byte
+}
+
+// Put implements Storage interface.
+func (ms *memoryStorage) Put(ctx context.Context, key string, data []byte) error {
+	ms.m.Lock()
+	defer ms.m.Unlock()
+
+	ms.objects[key] = bytes.Clone(data)
+
+	return nil
+}
+
+// Get implements Storage interface.
+func (ms *memoryStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	ms.m.Lock()
+	defer ms.m.Unlock()
+
+	b, ok := ms.objects[key]
+	if !ok {
+		return nil, lazyerrors.Errorf("no object %q", key)
+	}
+
+	return bytes.Clone(b), nil
+}
+
+// Delete implements Storage interface.
+func (ms *memoryStorage) Delete(ctx context.Context, key string) error {
+	ms.m.Lock()
+	defer ms.m.Unlock()
+
+	delete(ms.objects, key)
+
+	return nil
+}
+
+// keys returns keys of all stored objects.
+func (ms *memoryStorage) keys() []string {
+	ms.m.Lock()
+	defer ms.m.Unlock()
+
+	res := make([]string, 0, len(ms.objects))
+	for k := range ms.objects {
+		res = append(res, k)
+	}
+
+	slices.Sort(res)
+
+	return res
+}
+
+func TestOffload(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	s := &memoryStorage{objects: map[string][]byte{}}
+	o := &offloader{s: s, threshold: 4}
+
+	large := types.Binary{B: []byte("large value"), Subtype: types.BinaryMD5}
+	small := types.Binary{B: []byte("tiny"), Subtype: types.BinaryGeneric}
+
+	doc := must.NotFail(types.NewDocument(
+		"_id", int32(1),
+		"small", small,
+		"large", large,
+		"nested", must.NotFail(types.NewDocument(
+			"array", must.NotFail(types.NewArray(small, large, "foo")),
+		)),
+	))
+	doc.SetRecordID(42)
+
+	expected := doc.DeepCopy()
+
+	offloaded, err := o.offloadDocument(ctx, doc, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, doc, "the original document should not be modified")
+	assert.Len(t, s.objects, 1, "equal values should be stored once")
+	assert.Equal(t, int64(42), offloaded.RecordID())
+
+	ref := must.NotFail(offloaded.Get("large")).(types.Binary)
+	key, subtype, ok := parseRef(ref)
+	require.True(t, ok)
+	assert.Equal(t, types.BinaryMD5, subtype)
+	assert.Equal(t, large.B, s.objects[key])
+
+	assert.Equal(t, small, must.NotFail(offloaded.Get("small")))
+
+	_, err = o.restore(ctx, offloaded)
+	require.NoError(t, err)
+	assert.Equal(t, expected, offloaded)
+
+	t.Run("NoLargeValues", func(t *testing.T) {
+		t.Parallel()
+
+		doc := must.NotFail(types.NewDocument("_id", int32(2), "small", small))
+
+		actual, err := o.offloadDocument(ctx, doc, nil)
+		require.NoError(t, err)
+		assert.Same(t, doc, actual)
+	})
+
+	t.Run("Reuse", func(t *testing.T) {
+		t.Parallel()
+
+		doc := must.NotFail(types.NewDocument("_id", int32(4), "large", large))
+
+		prev, err := o.offloadDocument(ctx, doc, nil)
+		require.NoError(t, err)
+
+		doc.Set("foo", "bar")
+
+		actual, err := o.offloadDocument(ctx, doc, prev)
+		require.NoError(t, err)
+		assert.Equal(t, refs(prev), refs(actual))
+	})
+
+	t.Run("ID", func(t *testing.T) {
+		t.Parallel()
+
+		doc := must.NotFail(types.NewDocument("_id", large, "large", large))
+
+		actual, err := o.offloadDocument(ctx, doc, nil)
+		require.NoError(t, err)
+		assert.Equal(t, large, must.NotFail(actual.Get("_id")), "_id should not be offloaded")
+		assert.Len(t, refs(actual), 1)
+	})
+
+	t.Run("NotRef", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, ok := parseRef(types.Binary{B: refMagic, Subtype: types.BinaryUser})
+		assert.False(t, ok)
+
+		_, _, ok = parseRef(types.Binary{B: []byte("user data"), Subtype: types.BinaryUser})
+		assert.False(t, ok)
+	})
+
+	t.Run("Escape", func(t *testing.T) {
+		t.Parallel()
+
+		// small client value that looks like a reference to the existing object
+		fake := makeRef(key, types.BinaryGeneric)
+		doc := must.NotFail(types.NewDocument("_id", int32(3), "fake", fake))
+
+		o := &offloader{s: s, threshold: 1024}
+
+		actual, err := o.offloadDocument(ctx, doc, nil)
+		require.NoError(t, err)
+		assert.NotEqual(t, fake, must.NotFail(actual.Get("fake")))
+		assert.Empty(t, refs(actual))
+
+		_, err = o.restore(ctx, actual)
+		require.NoError(t, err)
+		assert.Equal(t, fake, must.NotFail(actual.Get("fake")))
+	})
+}
+
+func TestBackend(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	l := testutil.Logger(t)
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	origB, err := sqlite.NewBackend(&sqlite.NewBackendParams{
+		URI:       testutil.TestSQLiteURI(t, ""),
+		L:         l,
+		P:         sp,
+		BatchSize: 100,
+	})
+	require.NoError(t, err)
+
+	s := &memoryStorage{objects: map[string][]byte{}}
+	b := NewBackend(origB, &NewBackendParams{Storage: s, Threshold: 4, L: l})
+	t.Cleanup(b.Close)
+
+	dbName := testutil.DatabaseName(t)
+
+	db, err := b.Database(dbName)
+	require.NoError(t, err)
+
+	c, err := db.Collection("test")
+	require.NoError(t, err)
+
+	large1 := types.Binary{B: []byte("large value 1")}
+	large2 := types.Binary{B: []byte("large value 2")}
+
+	doc1 := must.NotFail(types.NewDocument("_id", int32(1), "v", large1))
+	doc2 := must.NotFail(types.NewDocument("_id", int32(2), "v", large1))
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{doc1, doc2}})
+	require.NoError(t, err)
+	require.Len(t, s.keys(), 2, "every document should reference its own object")
+
+	keys := s.keys()
+
+	doc1.Set("foo", "bar")
+	_, err = c.UpdateAll(ctx, &backends.UpdateAllParams{Docs: []*types.Document{doc1}})
+	require.NoError(t, err)
+	assert.Equal(t, keys, s.keys(), "unchanged value should not be uploaded again")
+
+	doc1.Set("v", large2)
+	_, err = c.UpdateAll(ctx, &backends.UpdateAllParams{Docs: []*types.Document{doc1}})
+	require.NoError(t, err)
+	require.Len(t, s.keys(), 2, "replaced value should be removed")
+
+	qr, err := c.Query(ctx, nil)
+	require.NoError(t, err)
+
+	docs, err := iterator.ConsumeValues(qr.Iter)
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, large2, must.NotFail(docs[0].Get("v")))
+	assert.Equal(t, large1, must.NotFail(docs[1].Get("v")))
+
+	_, err = c.DeleteAll(ctx, &backends.DeleteAllParams{IDs: []any{int32(1)}})
+	require.NoError(t, err)
+	require.Len(t, s.keys(), 1)
+
+	err = db.DropCollection(ctx, &backends.DropCollectionParams{Name: "test"})
+	require.NoError(t, err)
+	assert.Empty(t, s.keys())
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{doc2}})
+	require.NoError(t, err)
+	require.Len(t, s.keys(), 1)
+
+	t.Run("InsertError", func(t *testing.T) {
+		_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{doc2}})
+		require.Error(t, err)
+		assert.Len(t, s.keys(), 1, "objects of not inserted documents should be removed")
+	})
+
+	t.Run("ConcurrentUpdates", func(t *testing.T) {
+		var wg sync.WaitGroup
+
+		for i := range 10 {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for j := range 10 {
+					v := large1
+					if (i+j)%2 == 0 {
+						v = large2
+					}
+
+					doc := must.NotFail(types.NewDocument("_id", int32(2), "v", v))
+					_, err := c.UpdateAll(ctx, &backends.UpdateAllParams{Docs: []*types.Document{doc}})
+					assert.NoError(t, err)
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		qr, err := c.Query(ctx, nil)
+		require.NoError(t, err)
+
+		docs, err := iterator.ConsumeValues(qr.Iter)
+		require.NoError(t, err, "referenced objects should not be removed")
+		require.Len(t, docs, 1)
+		assert.Len(t, s.keys(), 1, "replaced values should be removed")
+	})
+
+	t.Run("Capped", func(t *testing.T) {
+		err = db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: "capped", CappedSize: 1024 * 1024})
+		require.NoError(t, err)
+
+		capped, err := db.Collection("capped")
+		require.NoError(t, err)
+
+		docs := []*types.Document{
+			must.NotFail(types.NewDocument("_id", int32(1), "v", large1)),
+			must.NotFail(types.NewDocument("_id", int32(2), "v", large2)),
+		}
+
+		_, err = capped.InsertAll(ctx, &backends.InsertAllParams{Docs: docs})
+		require.NoError(t, err)
+		require.Len(t, s.keys(), 3)
+
+		qr, err := capped.Query(ctx, &backends.QueryParams{OnlyRecordIDs: true})
+		require.NoError(t, err)
+
+		stored, err := iterator.ConsumeValues(qr.Iter)
+		require.NoError(t, err)
+		require.Len(t, stored, 2)
+
+		_, err = capped.DeleteAll(ctx, &backends.DeleteAllParams{RecordIDs: []int64{stored[0].RecordID()}})
+		require.NoError(t, err)
+		assert.Len(t, s.keys(), 2, "objects of deleted document should be removed")
+	})
+
+	err = b.DropDatabase(ctx, &backends.DropDatabaseParams{Name: dbName})
+	require.NoError(t, err)
+	assert.Empty(t, s.keys())
+}
@@ -0,0 +1,274 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offload
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// S3Params represents the parameters of NewS3 function.
+//
+//nolint:vet // for readability
+type S3Params struct {
+	Endpoint        string // like "https://s3.us-east-1.amazonaws.com" or "http://127.0.0.1:9000"
+	Region          string
+	Bucket          string
+	Prefix          string // prepended to object keys
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// s3 implements Storage interface for S3-compatible object storage.
+//
+// It uses path-style requests signed with AWS Signature Version 4.
+type s3 struct {
+	c      *http.Client
+	u      *url.URL
+	params *S3Params
+}
+
+// NewS3 returns a new Storage for S3-compatible object storage.
+//
+//checkerrors:ignore // user-facing configuration errors
+func NewS3(params *S3Params) (Storage, error) {
+	u, err := url.Parse(params.Endpoint)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q, expected http(s)://host[:port]", params.Endpoint)
+	}
+
+	if params.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is not set")
+	}
+
+	if params.Region == "" {
+		return nil, fmt.Errorf("S3 region is not set")
+	}
+
+	return &s3{
+		c:      http.DefaultClient,
+		u:      u,
+		params: params,
+	}, nil
+}
+
+// Put implements Storage interface.
+func (s *s3) Put(ctx context.Context, key string, data []byte) error {
+	res, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer res.Body.Close() //nolint:errcheck // we are only reading it
+
+	if res.StatusCode != http.StatusOK {
+		return s3Error(res)
+	}
+
+	return nil
+}
+
+// Get implements Storage interface.
+func (s *s3) Get(ctx context.Context, key string) ([]byte, error) {
+	res, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	defer res.Body.Close() //nolint:errcheck // we are only reading it
+
+	if res.StatusCode != http.StatusOK {
+		return nil, s3Error(res)
+	}
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return b, nil
+}
+
+// Delete implements Storage interface.
+func (s *s3) Delete(ctx context.Context, key string) error {
+	res, err := s.do(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer res.Body.Close() //nolint:errcheck // we are only reading it
+
+	// S3 returns 204 No Content for non-existing objects too
+	if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK {
+		return s3Error(res)
+	}
+
+	return nil
+}
+
+// do sends a signed request for the object with the given key.
+func (s *s3) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	u := *s.u
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.params.Bucket + "/" + s.params.Prefix + key
+	u.RawPath = uriEncode(u.Path, false)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if body == nil {
+		req.Body = http.NoBody
+	}
+
+	h := sha256.Sum256(body)
+	s.sign(req, hex.EncodeToString(h[:]), time.Now())
+
+	res, err := s.c.Do(req)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// sign adds AWS Signature Version 4 headers to the request with the given payload hash.
+//
+// All headers set on the request are signed.
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html.
+func (s *s3) sign(req *http.Request, payloadHash string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+
+	slices.Sort(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	query := req.URL.Query()
+	queryKeys := make([]string, 0, len(query))
+
+	for k := range query {
+		queryKeys = append(queryKeys, k)
+	}
+
+	slices.Sort(queryKeys)
+
+	canonicalQuery := make([]string, 0, len(queryKeys))
+	for _, k := range queryKeys {
+		canonicalQuery = append(canonicalQuery, uriEncode(k, true)+"="+uriEncode(query.Get(k), true))
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		strings.Join(canonicalQuery, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.params.Region + "/s3/aws4_request"
+
+	h := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(h[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.params.SecretAccessKey), date)
+	key = hmacSHA256(key, s.params.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		s.params.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// hmacSHA256 returns HMAC-SHA256 of data with the given key.
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
+
+// uriEncode encodes the string as required by AWS Signature Version 4.
+//
+// All characters except unreserved ones are percent-encoded.
+// Slashes are encoded only if encodeSlash is true.
+func uriEncode(s string, encodeSlash bool) string {
+	var res strings.Builder
+
+	for _, b := range []byte(s) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '-', b == '_', b == '.', b == '~':
+			res.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			res.WriteByte(b)
+		default:
+			fmt.Fprintf(&res, "%%%02X", b)
+		}
+	}
+
+	return res.String()
+}
+
+// s3Error returns an error for unexpected S3 response.
+func s3Error(res *http.Response) error {
+	b, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+
+	return lazyerrors.Errorf("unexpected S3 response status %s: %s", res.Status, bytes.TrimSpace(b))
+}
+
+// check interfaces
+var (
+	_ Storage = (*s3)(nil)
+)
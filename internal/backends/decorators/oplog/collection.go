@@ -191,6 +191,11 @@ func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams
 	return c.origC.Explain(ctx, params)
 }
 
+// Count implements backends.Collection interface.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	return c.origC.Count(ctx, params)
+}
+
 // Stats implements backends.Collection interface.
 func (c *collection) Stats(ctx context.Context, params *backends.CollectionStatsParams) (*backends.CollectionStatsResult, error) {
 	return c.origC.Stats(ctx, params)
@@ -201,6 +206,11 @@ func (c *collection) Compact(ctx context.Context, params *backends.CompactParams
 	return c.origC.Compact(ctx, params)
 }
 
+// ReIndex implements backends.Collection interface.
+func (c *collection) ReIndex(ctx context.Context, params *backends.ReIndexParams) (*backends.ReIndexResult, error) {
+	return c.origC.ReIndex(ctx, params)
+}
+
 // ListIndexes implements backends.Collection interface.
 func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndexesParams) (*backends.ListIndexesResult, error) {
 	return c.origC.ListIndexes(ctx, params)
@@ -47,6 +47,9 @@ type Error struct {
 	// It may be nil.
 	err error
 
+	// index is the unique index violated by ErrorCodeInsertDuplicateID error, if known.
+	index *IndexInfo
+
 	code ErrorCode
 }
 
@@ -64,11 +67,30 @@ func NewError(code ErrorCode, err error) *Error {
 	}
 }
 
+// NewDuplicateKeyError creates a new backend error with ErrorCodeInsertDuplicateID code
+// for the given violated unique index.
+//
+// Index may be nil if it is unknown. Err may be nil.
+func NewDuplicateKeyError(index *IndexInfo, err error) *Error {
+	return &Error{
+		code:  ErrorCodeInsertDuplicateID,
+		err:   err,
+		index: index,
+	}
+}
+
 // Code returns the error code.
 func (err *Error) Code() ErrorCode {
 	return err.code
 }
 
+// DuplicateKeyIndex returns the unique index violated by ErrorCodeInsertDuplicateID error.
+//
+// It returns nil if the index is unknown or the error has another code.
+func (err *Error) DuplicateKeyIndex() *IndexInfo {
+	return err.index
+}
+
 // There is intentionally no method to return the internal error.
 
 // Error implements error interface.
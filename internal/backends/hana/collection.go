@@ -291,6 +291,12 @@ func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams
 	}, nil
 }
 
+// Count implements backends.Collection interface.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	// HANATODO Push down COUNT(*) queries.
+	return new(backends.CountResult), nil
+}
+
 // Stats implements backends.Collection interface.
 func (c *collection) Stats(ctx context.Context, params *backends.CollectionStatsParams) (*backends.CollectionStatsResult, error) {
 	var res backends.CollectionStatsResult
@@ -311,6 +317,12 @@ func (c *collection) Compact(ctx context.Context, params *backends.CompactParams
 	return new(backends.CompactResult), nil
 }
 
+// ReIndex implements backends.Collection interface.
+func (c *collection) ReIndex(ctx context.Context, params *backends.ReIndexParams) (*backends.ReIndexResult, error) {
+	// HANA rebuilds indexes automatically.
+	return new(backends.ReIndexResult), nil
+}
+
 // Prefixes an index with the collection name to store it in hana.
 //
 // Reasoning:
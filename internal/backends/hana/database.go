@@ -129,6 +129,12 @@ func (db *database) RenameCollection(ctx context.Context, params *backends.Renam
 		return lazyerrors.Errorf("old database %q or collection %q does not exist", db.schema, params.OldName)
 	}
 
+	// HANATODO HANA commits DDL statements implicitly, so the target collection
+	// can't be dropped atomically with renaming.
+	if params.DropTarget {
+		return lazyerrors.New("renaming with dropping the target collection is not implemented")
+	}
+
 	sqlStmt := fmt.Sprintf("RENAME COLLECTION %q.%q to %q", db.schema, params.OldName, params.NewName)
 
 	_, err = db.hdb.ExecContext(ctx, sqlStmt)
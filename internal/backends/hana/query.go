@@ -40,7 +40,7 @@ func makeFilter(table, key, op string, value any) string {
 
 	switch v := value.(type) {
 	case *types.Document, *types.Array, types.Binary,
-		types.NullType, types.Regex, types.Timestamp:
+		types.NullType, types.Regex, types.Timestamp, types.Decimal128:
 	// type not supported for pushdown
 	case float64:
 		// If value is not safe double, fetch all numbers out of safe range.
@@ -135,7 +135,7 @@ func prepareWhereClause(table string, filter *types.Document) (string, error) {
 				}
 			}
 
-		case *types.Array, types.Binary, types.NullType, types.Regex, types.Timestamp:
+		case *types.Array, types.Binary, types.NullType, types.Regex, types.Timestamp, types.Decimal128:
 			// type not supported for pushdown
 
 		case float64, string, types.ObjectID, bool, time.Time, int32, int64:
@@ -19,6 +19,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
@@ -101,6 +103,11 @@ func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*
 		args = append(args, params.Limit)
 	}
 
+	if params.Sample != 0 {
+		q += ` ORDER BY RAND() LIMIT ?`
+		args = append(args, params.Sample)
+	}
+
 	rows, err := p.QueryContext(ctx, q, args...)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -151,7 +158,7 @@ func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllPa
 			if _, err = tx.ExecContext(ctx, q, args...); err != nil {
 				var mysqlErr *mysql.MySQLError
 				if errors.As(err, &mysqlErr) && mysqlErr.Number == ErrDuplicateEntry {
-					return backends.NewError(backends.ErrorCodeInsertDuplicateID, err)
+					return duplicateKeyError(meta, mysqlErr)
 				}
 
 				return lazyerrors.Error(err)
@@ -213,6 +220,11 @@ func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllPa
 
 			stats, err = tx.ExecContext(ctx, q, b, arg)
 			if err != nil {
+				var mysqlErr *mysql.MySQLError
+				if errors.As(err, &mysqlErr) && mysqlErr.Number == ErrDuplicateEntry {
+					return duplicateKeyError(meta, mysqlErr)
+				}
+
 				return lazyerrors.Error(err)
 			}
 
@@ -229,6 +241,10 @@ func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllPa
 		return nil
 	})
 	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID) {
+			return nil, err
+		}
+
 		return nil, lazyerrors.Error(err)
 	}
 
@@ -349,6 +365,12 @@ func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams
 		res.LimitPushdown = true
 	}
 
+	if params.Sample != 0 {
+		q += ` ORDER BY RAND() LIMIT ?`
+		args = append(args, params.Sample)
+		res.SamplePushdown = true
+	}
+
 	var b []byte
 	if err = p.QueryRowContext(ctx, q, args...).Scan(&b); err != nil {
 		return nil, lazyerrors.Error(err)
@@ -364,6 +386,58 @@ func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams
 	return res, nil
 }
 
+// Count implements backends.Collection interface.
+//
+// Only empty filter is pushed down, because other filters are applied by the backend partially.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	if params.Filter.Len() != 0 {
+		return new(backends.CountResult), nil
+	}
+
+	p, err := c.r.DatabaseGetExisting(ctx, c.dbName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if p == nil {
+		return &backends.CountResult{FilterPushdown: true}, nil
+	}
+
+	meta, err := c.r.CollectionGet(ctx, c.dbName, c.name)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if meta == nil {
+		return &backends.CountResult{FilterPushdown: true}, nil
+	}
+
+	if params.Estimate {
+		// TABLE_ROWS is an approximation for InnoDB tables, and it could be NULL
+		var estimate sql.NullInt64
+
+		q := `SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`
+		if err = p.QueryRowContext(ctx, q, c.dbName, meta.TableName).Scan(&estimate); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if estimate.Valid && estimate.Int64 >= backends.CountEstimateMin {
+			return &backends.CountResult{Count: estimate.Int64, FilterPushdown: true}, nil
+		}
+	}
+
+	q := fmt.Sprintf(`SELECT COUNT(*) FROM %q.%q`, c.dbName, meta.TableName)
+
+	var res backends.CountResult
+	if err = p.QueryRowContext(ctx, q).Scan(&res.Count); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res.FilterPushdown = true
+
+	return &res, nil
+}
+
 // Stats implements backends.Collection interface.
 func (c *collection) Stats(ctx context.Context, params *backends.CollectionStatsParams) (*backends.CollectionStatsResult, error) {
 	p, err := c.r.DatabaseGetExisting(ctx, c.dbName)
@@ -491,6 +565,53 @@ func (c *collection) Compact(ctx context.Context, params *backends.CompactParams
 	return new(backends.CompactResult), nil
 }
 
+// ReIndex implements backends.Collection interface.
+//
+// MySQL can't rebuild a single index, so the whole table with all indexes is rebuilt.
+// That is done in place without locking writes if concurrently is true.
+func (c *collection) ReIndex(ctx context.Context, params *backends.ReIndexParams) (*backends.ReIndexResult, error) {
+	p, err := c.r.DatabaseGetExisting(ctx, c.dbName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if p == nil {
+		return nil, backends.NewError(
+			backends.ErrorCodeDatabaseDoesNotExist,
+			lazyerrors.Errorf("no ns %s.%s", c.dbName, c.name),
+		)
+	}
+
+	coll, err := c.r.CollectionGet(ctx, c.dbName, c.name)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if coll == nil {
+		return nil, backends.NewError(
+			backends.ErrorCodeCollectionDoesNotExist,
+			lazyerrors.Errorf("no ns %s.%s", c.dbName, c.name),
+		)
+	}
+
+	if params != nil && params.Indexes != nil && !slices.ContainsFunc(coll.Indexes, func(index metadata.IndexInfo) bool {
+		return slices.Contains(params.Indexes, index.Name)
+	}) {
+		return new(backends.ReIndexResult), nil
+	}
+
+	q := fmt.Sprintf("ALTER TABLE %q.%q FORCE", c.dbName, coll.TableName)
+	if params != nil && params.Concurrently {
+		q += ", ALGORITHM=INPLACE, LOCK=NONE"
+	}
+
+	if _, err = p.ExecContext(ctx, q); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return new(backends.ReIndexResult), nil
+}
+
 // ListIndexes implements backends.Collection interface.
 func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndexesParams) (*backends.ListIndexesResult, error) {
 	db, err := c.r.DatabaseGetExisting(ctx, c.dbName)
@@ -534,6 +655,8 @@ func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndex
 				Descending: key.Descending,
 			}
 		}
+
+		res.Indexes[i].SetOptions(index.Options)
 	}
 
 	sort.Slice(res.Indexes, func(i, j int) bool { return res.Indexes[i].Name < res.Indexes[j].Name })
@@ -557,6 +680,8 @@ func (c *collection) CreateIndexes(ctx context.Context, params *backends.CreateI
 				Descending: key.Descending,
 			}
 		}
+
+		indexes[i].Options = index.OptionsDocument()
 	}
 
 	err := c.r.IndexesCreate(ctx, c.dbName, c.name, indexes)
@@ -577,6 +702,42 @@ func (c *collection) DropIndexes(ctx context.Context, params *backends.DropIndex
 	return new(backends.DropIndexesResult), nil
 }
 
+// duplicateKeyRe matches MySQL duplicate entry error messages like
+// "Duplicate entry 'foo' for key 'table.index'".
+var duplicateKeyRe = regexp.MustCompile(`for key '(?:[^']*\.)?([^'.]+)'$`)
+
+// duplicateKeyError returns backend error for the given duplicate entry error
+// with the violated index of the given collection, if it could be found.
+func duplicateKeyError(meta *metadata.Collection, mysqlErr *mysql.MySQLError) error {
+	m := duplicateKeyRe.FindStringSubmatch(mysqlErr.Message)
+	if m == nil {
+		return backends.NewDuplicateKeyError(nil, mysqlErr)
+	}
+
+	for _, index := range meta.Indexes {
+		if index.Index != m[1] {
+			continue
+		}
+
+		res := &backends.IndexInfo{
+			Name:   index.Name,
+			Unique: index.Unique,
+			Key:    make([]backends.IndexKeyPair, len(index.Key)),
+		}
+
+		for i, key := range index.Key {
+			res.Key[i] = backends.IndexKeyPair{
+				Field:      key.Field,
+				Descending: key.Descending,
+			}
+		}
+
+		return backends.NewDuplicateKeyError(res, mysqlErr)
+	}
+
+	return backends.NewDuplicateKeyError(nil, mysqlErr)
+}
+
 // check interfaces
 var (
 	_ backends.Collection = (*collection)(nil)
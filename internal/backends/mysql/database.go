@@ -140,14 +140,19 @@ func (db *database) RenameCollection(ctx context.Context, params *backends.Renam
 		return lazyerrors.Error(err)
 	}
 
-	if c == nil {
+	if c != nil && !params.DropTarget {
 		return backends.NewError(
 			backends.ErrorCodeCollectionAlreadyExists,
 			lazyerrors.Errorf("new database %q and collection %q already exists", db.name, params.NewName),
 		)
 	}
 
-	renamed, err := db.r.CollectionRename(ctx, db.name, params.OldName, params.NewName)
+	rename := db.r.CollectionRename
+	if params.DropTarget {
+		rename = db.r.CollectionReplace
+	}
+
+	renamed, err := rename(ctx, db.name, params.OldName, params.NewName)
 	if err != nil {
 		return lazyerrors.Error(err)
 	}
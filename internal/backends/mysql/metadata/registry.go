@@ -718,6 +718,110 @@ func (r *Registry) CollectionRename(ctx context.Context, dbName, oldCollectionNa
 	r.rw.Lock()
 	defer r.rw.Unlock()
 
+	return r.collectionRename(ctx, p, dbName, oldCollectionName, newCollectionName)
+}
+
+// CollectionReplace renames a collection in the database,
+// dropping the existing collection with the new name first, if any.
+//
+// Metadata of both collections is updated in a single transaction,
+// so the new name always refers to either the old or the new collection.
+// The table of the dropped collection is removed after that,
+// because MySQL commits the transaction implicitly on DROP TABLE.
+//
+// Returned boolean value indicates whether the collection was renamed.
+// If database or old collection did not exist, (false, nil) is returned.
+//
+// If the user is not authenticated, it returns error.
+func (r *Registry) CollectionReplace(ctx context.Context, dbName, oldCollectionName, newCollectionName string) (bool, error) {
+	defer observability.FuncCall(ctx)()
+
+	p, err := r.getPool(ctx)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	c := r.collectionGet(dbName, oldCollectionName)
+	if c == nil {
+		return false, nil
+	}
+
+	target := r.collectionGet(dbName, newCollectionName)
+
+	c.Name = newCollectionName
+
+	b, err := sjson.Marshal(c.marshal())
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	oldArg, err := sjson.MarshalSingleValue(oldCollectionName)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	newArg, err := sjson.MarshalSingleValue(newCollectionName)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	err = p.InTransaction(ctx, func(tx *fsql.Tx) error {
+		if target != nil {
+			q := fmt.Sprintf(
+				`DELETE FROM %s.%s WHERE %s IN (?)`,
+				dbName, metadataTableName,
+				IDIndexColumn,
+			)
+
+			if _, err := tx.ExecContext(ctx, q, string(newArg)); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		q := fmt.Sprintf(
+			`UPDATE %s.%s SET %s = ? WHERE %s = ?`,
+			dbName, metadataTableName,
+			DefaultColumn,
+			IDIndexColumn,
+		)
+
+		if _, err := tx.ExecContext(ctx, q, string(b), oldArg); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	r.colls[dbName][newCollectionName] = c
+	delete(r.colls[dbName], oldCollectionName)
+
+	if target != nil {
+		q := fmt.Sprintf(`DROP TABLE %s.%s`, dbName, target.TableName)
+
+		// the collection is already replaced; the table is only leaked
+		if _, err = p.ExecContext(ctx, q); err != nil {
+			r.l.Error("Failed to drop replaced collection's table", zap.String("table", target.TableName), zap.Error(err))
+		}
+	}
+
+	return true, nil
+}
+
+// collectionRename renames a collection in the database.
+//
+// Returned boolean value indicates whether the collection was renamed.
+// If database or collection did not exist, (false, nil) is returned.
+//
+// It does not hold the lock.
+func (r *Registry) collectionRename(ctx context.Context, p *fsql.DB, dbName, oldCollectionName, newCollectionName string) (bool, error) { //nolint:lll // for readability
+	defer observability.FuncCall(ctx)()
+
 	db := r.colls[dbName]
 	if db == nil {
 		return false, nil
@@ -170,6 +170,17 @@ func prepareWhereClause(sqlFilters *types.Document) (string, []any, error) {
 						args = append(args, a...)
 					}
 
+				case "$in":
+					// only lookups by _id are pushed down for now
+					if rootKey != "_id" {
+						continue
+					}
+
+					if f, a := filterIn(rootKey, v); f != "" {
+						filters = append(filters, f)
+						args = append(args, a...)
+					}
+
 				case "$ne":
 					sql := `NOT ( ` +
 						// check if the value under the key is equal to filter value
@@ -179,7 +190,7 @@ func prepareWhereClause(sqlFilters *types.Document) (string, []any, error) {
 
 					switch v := v.(type) {
 					case *types.Document, *types.Array, types.Binary,
-						types.NullType, types.Regex, types.Timestamp:
+						types.NullType, types.Regex, types.Timestamp, types.Decimal128:
 					// type not supported for pushdown
 
 					case float64, bool, int32, int64:
@@ -211,7 +222,7 @@ func prepareWhereClause(sqlFilters *types.Document) (string, []any, error) {
 				}
 			}
 
-		case *types.Array, types.Binary, types.NullType, types.Regex, types.Timestamp:
+		case *types.Array, types.Binary, types.NullType, types.Regex, types.Timestamp, types.Decimal128:
 			// type not supported for pushdown
 
 		case float64, string, types.ObjectID, bool, time.Time, int32, int64:
@@ -241,7 +252,7 @@ func filterEqual(k string, v any) (filter string, args []any) {
 
 	switch v := v.(type) {
 	case *types.Document, *types.Array, types.Binary,
-		types.NullType, types.Regex, types.Timestamp:
+		types.NullType, types.Regex, types.Timestamp, types.Decimal128:
 		// type not supported for pushdown
 
 	case float64:
@@ -297,3 +308,34 @@ func filterEqual(k string, v any) (filter string, args []any) {
 
 	return
 }
+
+// filterIn returns the proper SQL filter with arguments that filters documents
+// where the value under k is equal to one of the values of the given $in array.
+//
+// If v is not an array, is empty, or contains values of types not supported by filterEqual,
+// it returns empty filter.
+func filterIn(k string, v any) (filter string, args []any) {
+	in, ok := v.(*types.Array)
+	if !ok || in.Len() == 0 {
+		return "", nil
+	}
+
+	for i := 0; i < in.Len(); i++ {
+		switch must.NotFail(in.Get(i)).(type) {
+		case *types.Document, *types.Array, types.Binary,
+			types.NullType, types.Regex, types.Timestamp, types.Decimal128:
+			// type not supported for pushdown
+			return "", nil
+		}
+	}
+
+	filters := make([]string, in.Len())
+
+	for i := 0; i < in.Len(); i++ {
+		var a []any
+		filters[i], a = filterEqual(k, must.NotFail(in.Get(i)))
+		args = append(args, a...)
+	}
+
+	return "(" + strings.Join(filters, " OR ") + ")", args
+}
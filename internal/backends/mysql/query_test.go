@@ -291,6 +291,29 @@ func TestPrepareWhereClause(t *testing.T) {
 			expected: whereNotEq + `'"objectId"' )`,
 		},
 
+		"InID": {
+			filter: must.NotFail(types.NewDocument(
+				"_id", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray("foo", "bar")))),
+			)),
+			expected: ` WHERE (JSON_CONTAINS(_ferretdb_sjson->$.?, ?, '$') OR JSON_CONTAINS(_ferretdb_sjson->$.?, ?, '$'))`,
+			args:     []any{`_id`, `"foo"`, `_id`, `"bar"`},
+		},
+		"InIDMixed": {
+			filter: must.NotFail(types.NewDocument(
+				"_id", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray("foo", types.Null)))),
+			)),
+		},
+		"InIDEmpty": {
+			filter: must.NotFail(types.NewDocument(
+				"_id", must.NotFail(types.NewDocument("$in", types.MakeArray(0))),
+			)),
+		},
+		"InField": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray("foo")))),
+			)),
+		},
+
 		"Comment": {
 			filter: must.NotFail(types.NewDocument("$comment", "I'm comment")),
 		},
@@ -18,13 +18,16 @@ import (
 	"cmp"
 	"context"
 	"slices"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/backends/postgresql/metadata"
+	"github.com/FerretDB/FerretDB/internal/backends/postgresql/metadata/pool"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/observability"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 )
 
@@ -41,12 +44,26 @@ type NewBackendParams struct {
 	L         *zap.Logger
 	P         *state.Provider
 	BatchSize int
-	_         struct{} // prevent unkeyed literals
+	SlowQuery *observability.SlowQueryOpts // nil to disable slow queries logging
+	SQLViews  bool                         // maintain read-only SQL views of collections
+
+	MinConns     int32         // connections to establish and keep open, 0 to disable
+	PingInterval time.Duration // interval between pings of idle connections, 0 to disable
+
+	_ struct{} // prevent unkeyed literals
 }
 
 // NewBackend creates a new Backend.
 func NewBackend(params *NewBackendParams) (backends.Backend, error) {
-	r, err := metadata.NewRegistry(params.URI, params.BatchSize, params.L, params.P)
+	var wu *pool.WarmUpOpts
+	if params.MinConns > 0 || params.PingInterval > 0 {
+		wu = &pool.WarmUpOpts{
+			MinConns:     params.MinConns,
+			PingInterval: params.PingInterval,
+		}
+	}
+
+	r, err := metadata.NewRegistry(params.URI, params.BatchSize, params.L, params.P, params.SlowQuery, wu, params.SQLViews)
 	if err != nil {
 		return nil, err
 	}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -104,6 +105,13 @@ func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*
 		args = append(args, params.Limit)
 	}
 
+	// TABLESAMPLE is not used because it returns an approximate number of rows;
+	// that still scans the whole table, but only sampled documents are fetched and decoded
+	if params.Sample != 0 {
+		q += fmt.Sprintf(` ORDER BY random() LIMIT %s`, placeholder.Next())
+		args = append(args, params.Sample)
+	}
+
 	rows, err := p.Query(ctx, q, args...)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -157,7 +165,7 @@ func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllPa
 			if _, err = tx.Exec(ctx, q, args...); err != nil {
 				var pgErr *pgconn.PgError
 				if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
-					return backends.NewError(backends.ErrorCodeInsertDuplicateID, err)
+					return duplicateKeyError(meta, pgErr)
 				}
 
 				return lazyerrors.Error(err)
@@ -170,6 +178,8 @@ func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllPa
 		return nil, err
 	}
 
+	c.r.ViewUpdate(ctx, c.dbName, c.name, params.Docs)
+
 	return new(backends.InsertAllResult), nil
 }
 
@@ -215,6 +225,11 @@ func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllPa
 
 			var tag pgconn.CommandTag
 			if tag, err = tx.Exec(ctx, q, b, arg); err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+					return duplicateKeyError(meta, pgErr)
+				}
+
 				return lazyerrors.Error(err)
 			}
 
@@ -224,9 +239,15 @@ func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllPa
 		return nil
 	})
 	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID) {
+			return nil, err
+		}
+
 		return nil, lazyerrors.Error(err)
 	}
 
+	c.r.ViewUpdate(ctx, c.dbName, c.name, params.Docs)
+
 	return &res, nil
 }
 
@@ -355,6 +376,12 @@ func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams
 		res.LimitPushdown = true
 	}
 
+	if params.Sample != 0 {
+		q += fmt.Sprintf(` ORDER BY random() LIMIT %s`, placeholder.Next())
+		args = append(args, params.Sample)
+		res.SamplePushdown = true
+	}
+
 	var b []byte
 	if err = p.QueryRow(ctx, q, args...).Scan(&b); err != nil {
 		return nil, lazyerrors.Error(err)
@@ -370,6 +397,58 @@ func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams
 	return res, nil
 }
 
+// Count implements backends.Collection interface.
+//
+// Only empty filter is pushed down, because other filters are applied by the backend partially.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	if params.Filter.Len() != 0 {
+		return new(backends.CountResult), nil
+	}
+
+	p, err := c.r.DatabaseGetExisting(ctx, c.dbName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if p == nil {
+		return &backends.CountResult{FilterPushdown: true}, nil
+	}
+
+	meta, err := c.r.CollectionGet(ctx, c.dbName, c.name)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if meta == nil {
+		return &backends.CountResult{FilterPushdown: true}, nil
+	}
+
+	table := pgx.Identifier{c.dbName, meta.TableName}.Sanitize()
+
+	if params.Estimate {
+		// reltuples is -1 if the table was never vacuumed or analyzed
+		var estimate float64
+		if err = p.QueryRow(ctx, `SELECT reltuples FROM pg_class WHERE oid = $1::regclass`, table).Scan(&estimate); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if estimate >= backends.CountEstimateMin {
+			return &backends.CountResult{Count: int64(estimate), FilterPushdown: true}, nil
+		}
+	}
+
+	q := `SELECT COUNT(*) FROM ` + table
+
+	var res backends.CountResult
+	if err = p.QueryRow(ctx, q).Scan(&res.Count); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res.FilterPushdown = true
+
+	return &res, nil
+}
+
 // Stats implements backends.Collection interface.
 func (c *collection) Stats(ctx context.Context, params *backends.CollectionStatsParams) (*backends.CollectionStatsResult, error) {
 	p, err := c.r.DatabaseGetExisting(ctx, c.dbName)
@@ -498,6 +577,59 @@ func (c *collection) Compact(ctx context.Context, params *backends.CompactParams
 	return new(backends.CompactResult), nil
 }
 
+// ReIndex implements backends.Collection interface.
+func (c *collection) ReIndex(ctx context.Context, params *backends.ReIndexParams) (*backends.ReIndexResult, error) {
+	db, err := c.r.DatabaseGetExisting(ctx, c.dbName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if db == nil {
+		return nil, backends.NewError(
+			backends.ErrorCodeDatabaseDoesNotExist,
+			lazyerrors.Errorf("no ns %s.%s", c.dbName, c.name),
+		)
+	}
+
+	coll, err := c.r.CollectionGet(ctx, c.dbName, c.name)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if coll == nil {
+		return nil, backends.NewError(
+			backends.ErrorCodeCollectionDoesNotExist,
+			lazyerrors.Errorf("no ns %s.%s", c.dbName, c.name),
+		)
+	}
+
+	// REINDEX CONCURRENTLY builds a new index without locking writes and then swaps it with the old one
+	var concurrently string
+	if params != nil && params.Concurrently {
+		concurrently = "CONCURRENTLY "
+	}
+
+	var qs []string
+
+	if params == nil || params.Indexes == nil {
+		qs = append(qs, "REINDEX TABLE "+concurrently+pgx.Identifier{c.dbName, coll.TableName}.Sanitize())
+	} else {
+		for _, index := range coll.Indexes {
+			if slices.Contains(params.Indexes, index.Name) {
+				qs = append(qs, "REINDEX INDEX "+concurrently+pgx.Identifier{c.dbName, index.PgIndex}.Sanitize())
+			}
+		}
+	}
+
+	for _, q := range qs {
+		if _, err = db.Exec(ctx, q); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return new(backends.ReIndexResult), nil
+}
+
 // ListIndexes implements backends.Collection interface.
 func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndexesParams) (*backends.ListIndexesResult, error) {
 	db, err := c.r.DatabaseGetExisting(ctx, c.dbName)
@@ -541,6 +673,8 @@ func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndex
 				Descending: key.Descending,
 			}
 		}
+
+		res.Indexes[i].SetOptions(index.Options)
 	}
 
 	sort.Slice(res.Indexes, func(i, j int) bool {
@@ -566,6 +700,8 @@ func (c *collection) CreateIndexes(ctx context.Context, params *backends.CreateI
 				Descending: key.Descending,
 			}
 		}
+
+		indexes[i].Options = index.OptionsDocument()
 	}
 
 	err := c.r.IndexesCreate(ctx, c.dbName, c.name, indexes)
@@ -586,6 +722,33 @@ func (c *collection) DropIndexes(ctx context.Context, params *backends.DropIndex
 	return new(backends.DropIndexesResult), nil
 }
 
+// duplicateKeyError returns backend error for the given unique violation error
+// with the violated index of the given collection, if it could be found.
+func duplicateKeyError(meta *metadata.Collection, pgErr *pgconn.PgError) error {
+	for _, index := range meta.Indexes {
+		if index.PgIndex != pgErr.ConstraintName {
+			continue
+		}
+
+		res := &backends.IndexInfo{
+			Name:   index.Name,
+			Unique: index.Unique,
+			Key:    make([]backends.IndexKeyPair, len(index.Key)),
+		}
+
+		for i, key := range index.Key {
+			res.Key[i] = backends.IndexKeyPair{
+				Field:      key.Field,
+				Descending: key.Descending,
+			}
+		}
+
+		return backends.NewDuplicateKeyError(res, pgErr)
+	}
+
+	return backends.NewDuplicateKeyError(nil, pgErr)
+}
+
 // check interfaces
 var (
 	_ backends.Collection = (*collection)(nil)
@@ -33,6 +33,7 @@ type IndexInfo struct {
 	PgIndex string
 	Key     []IndexKeyPair
 	Unique  bool
+	Options *types.Document // see backends.IndexInfo.OptionsDocument; nil if not set
 }
 
 // IndexKeyPair consists of a field name and a sort order that are part of the index.
@@ -52,6 +53,10 @@ func (indexes Indexes) deepCopy() Indexes {
 			Key:     slices.Clone(index.Key),
 			Unique:  index.Unique,
 		}
+
+		if index.Options != nil {
+			res[i].Options = index.Options.DeepCopy()
+		}
 	}
 
 	return res
@@ -73,12 +78,18 @@ func (indexes Indexes) marshal() *types.Array {
 			key.Set(pair.Field, order)
 		}
 
-		res.Append(must.NotFail(types.NewDocument(
+		doc := must.NotFail(types.NewDocument(
 			"pgindex", index.PgIndex,
 			"name", index.Name,
 			"key", key,
 			"unique", index.Unique,
-		)))
+		))
+
+		if index.Options != nil {
+			doc.Set("options", index.Options)
+		}
+
+		res.Append(doc)
 	}
 
 	return res
@@ -130,6 +141,10 @@ func (s *Indexes) unmarshal(a *types.Array) error {
 			Key:     key,
 			Unique:  unique,
 		}
+
+		if v, _ = index.Get("options"); v != nil {
+			res[i].Options = v.(*types.Document)
+		}
 	}
 
 	*s = res
@@ -16,17 +16,23 @@ package pool
 
 import (
 	"context"
+	"fmt"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	zapadapter "github.com/jackc/pgx-zap"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/tracelog"
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/logging"
+	"github.com/FerretDB/FerretDB/internal/util/observability"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 )
 
@@ -38,14 +44,23 @@ var (
 	locales = []string{"POSIX", "C", "C.UTF8", "en_US.UTF8"}
 )
 
+// minMajorVersion is the oldest PostgreSQL major version that is tested;
+// older versions reached end of life.
+const minMajorVersion = 14
+
 // openDB creates a pool of connections to PostgreSQL database
 // and check that it works (authentication passes, settings are okay).
-func openDB(uri string, l *zap.Logger, sp *state.Provider) (*pgxpool.Pool, error) {
+//
+// If sq is not nil, slow queries are logged.
+// If minConns is greater than zero, that many connections are established (but not more than pool_max_conns).
+func openDB(uri string, l *zap.Logger, sp *state.Provider, sq *observability.SlowQueryOpts, minConns int32) (*pgxpool.Pool, error) { //nolint:lll // for readability
 	config, err := pgxpool.ParseConfig(uri)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
+	config.MinConns = min(max(config.MinConns, minConns), config.MaxConns)
+
 	// version could change without FerretDB restart
 	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
 		var v string
@@ -65,20 +80,43 @@ func openDB(uri string, l *zap.Logger, sp *state.Provider) (*pgxpool.Pool, error
 			}
 		}
 
+		// unparsable versions (for example, of PostgreSQL-compatible databases) are ignored
+		major, _, _ := strings.Cut(v, ".")
+		if m, _ := strconv.Atoi(major); m > 0 && m < minMajorVersion {
+			logging.RecentEntries.AddStartupWarning(l, fmt.Sprintf(
+				"%s %s is too old and not supported. Please upgrade to PostgreSQL %d or later.",
+				n, major, minMajorVersion,
+			))
+		}
+
 		return nil
 	}
 
-	// port tracing, tweak logging
+	// tweak logging
 	// TODO https://github.com/FerretDB/FerretDB/issues/3554
 
 	// try to log everything; logger's configuration will skip extra levels if needed
-	config.ConnConfig.Tracer = &tracelog.TraceLog{
-		Logger:   zapadapter.NewLogger(l),
-		LogLevel: tracelog.LogLevelTrace,
+	t := &tracer{
+		TraceLog: &tracelog.TraceLog{
+			Logger:   zapadapter.NewLogger(l),
+			LogLevel: tracelog.LogLevelTrace,
+		},
+		l:         l.Named("slow"),
+		slowQuery: sq,
 	}
+	config.ConnConfig.Tracer = t
 
 	config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
 
+	// send cancel requests for canceled contexts (for example, when maxTimeMS expires),
+	// so PostgreSQL stops executing queries instead of running them to completion
+	config.ConnConfig.BuildContextWatcherHandler = func(c *pgconn.PgConn) ctxwatch.Handler {
+		return &pgconn.CancelRequestContextWatcherHandler{
+			Conn:          c,
+			DeadlineDelay: time.Second,
+		}
+	}
+
 	// see https://github.com/jackc/pgx/issues/1726#issuecomment-1711612138
 	ctx := context.TODO()
 
@@ -87,6 +125,8 @@ func openDB(uri string, l *zap.Logger, sp *state.Provider) (*pgxpool.Pool, error
 		return nil, lazyerrors.Error(err)
 	}
 
+	t.p.Store(p)
+
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
@@ -95,6 +135,15 @@ func openDB(uri string, l *zap.Logger, sp *state.Provider) (*pgxpool.Pool, error
 		return nil, lazyerrors.Error(err)
 	}
 
+	if config.MinConns > 0 {
+		// pgxpool establishes them in the background; wait for them, but do not fail
+		if err = warmUp(ctx, p, config.MinConns); err != nil {
+			l.Warn("openDB: connections warm-up failed", zap.Error(err))
+		} else {
+			l.Info("openDB: connections warmed up", zap.Int32("connections", config.MinConns))
+		}
+	}
+
 	return p, nil
 }
 
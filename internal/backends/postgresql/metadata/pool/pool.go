@@ -26,6 +26,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/observability"
 	"github.com/FerretDB/FerretDB/internal/util/resource"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 )
@@ -43,15 +44,23 @@ type Pool struct {
 	baseURI url.URL
 	l       *zap.Logger
 	sp      *state.Provider
+	sq      *observability.SlowQueryOpts
+	wu      *WarmUpOpts
 
 	rw    sync.RWMutex
 	pools map[string]*pgxpool.Pool // by full URI
 
+	pingStop chan struct{}
+	pingWG   sync.WaitGroup
+
 	token *resource.Token
 }
 
 // New creates a new Pool.
-func New(u string, l *zap.Logger, sp *state.Provider) (*Pool, error) {
+//
+// If sq is not nil, slow queries are logged.
+// If wu is not nil, connections are kept warm; see [WarmUpOpts].
+func New(u string, l *zap.Logger, sp *state.Provider, sq *observability.SlowQueryOpts, wu *WarmUpOpts) (*Pool, error) {
 	baseURI, err := url.Parse(u)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -65,17 +74,45 @@ func New(u string, l *zap.Logger, sp *state.Provider) (*Pool, error) {
 		baseURI: *baseURI,
 		l:       l,
 		sp:      sp,
+		sq:      sq,
+		wu:      wu,
 		pools:   map[string]*pgxpool.Pool{},
 		token:   resource.NewToken(),
 	}
 
 	resource.Track(p, p.token)
 
+	if wu == nil {
+		return p, nil
+	}
+
+	if wu.MinConns > 0 {
+		// pool for the base URI is used for connections without authentication;
+		// failure is not fatal, as PostgreSQL may become available later
+		_, _ = p.Get("", "")
+	}
+
+	if wu.PingInterval > 0 {
+		p.pingStop = make(chan struct{})
+
+		p.pingWG.Add(1)
+
+		go func() {
+			defer p.pingWG.Done()
+			p.runPings()
+		}()
+	}
+
 	return p, nil
 }
 
 // Close closes all connections in the pool.
 func (p *Pool) Close() {
+	if p.pingStop != nil {
+		close(p.pingStop)
+		p.pingWG.Wait()
+	}
+
 	p.rw.Lock()
 	defer p.rw.Unlock()
 
@@ -122,7 +159,12 @@ func (p *Pool) Get(username, password string) (*pgxpool.Pool, error) {
 		return res, nil
 	}
 
-	res, err := openDB(u, p.l, p.sp)
+	var minConns int32
+	if p.wu != nil {
+		minConns = p.wu.MinConns
+	}
+
+	res, err := openDB(u, p.l, p.sp, p.sq, minConns)
 	if err != nil {
 		p.l.Warn("Pool: connection failed", zap.String("username", username), zap.Error(err))
 		return nil, lazyerrors.Error(err)
@@ -0,0 +1,166 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/tracelog"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	otelsemconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/util/observability"
+)
+
+// explainTimeout is the maximum time spent capturing a plan of a slow query.
+const explainTimeout = 5 * time.Second
+
+// slowQueryKey is a context key for slowQueryData.
+type slowQueryKey struct{}
+
+// slowQueryData contains query data stored in the context until the query ends.
+type slowQueryData struct {
+	start time.Time
+	sql   string
+	args  []any
+}
+
+// explainKey is a context key that marks EXPLAIN queries issued by the tracer itself.
+type explainKey struct{}
+
+// tracer adds OpenTelemetry spans and slow queries logging for queries to pgx's TraceLog.
+//
+// Other tracing interfaces (batch, copy, connect, prepare) are implemented by the embedded TraceLog.
+type tracer struct {
+	*tracelog.TraceLog
+
+	l         *zap.Logger
+	slowQuery *observability.SlowQueryOpts
+
+	// used to capture plans of slow queries; set after the pool is created
+	p atomic.Pointer[pgxpool.Pool]
+}
+
+// TraceQueryStart implements [pgx.QueryTracer].
+func (t *tracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	op, _, _ := strings.Cut(strings.TrimSpace(data.SQL), " ")
+	op = strings.ToUpper(op)
+
+	if t.slowQuery.Enabled() && ctx.Value(explainKey{}) == nil {
+		ctx = context.WithValue(ctx, slowQueryKey{}, &slowQueryData{
+			start: time.Now(),
+			sql:   data.SQL,
+			args:  data.Args,
+		})
+	}
+
+	ctx, _ = otel.Tracer("").Start(
+		ctx,
+		op,
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(
+			otelsemconv.DBSystemPostgreSQL,
+			otelsemconv.DBOperationKey.String(op),
+			otelsemconv.DBStatementKey.String(data.SQL),
+		),
+	)
+
+	return t.TraceLog.TraceQueryStart(ctx, conn, data)
+}
+
+// TraceQueryEnd implements [pgx.QueryTracer].
+func (t *tracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	t.TraceLog.TraceQueryEnd(ctx, conn, data)
+
+	span := oteltrace.SpanFromContext(ctx)
+
+	if data.Err != nil {
+		span.SetStatus(otelcodes.Error, data.Err.Error())
+	} else {
+		span.SetStatus(otelcodes.Ok, "")
+	}
+
+	span.End()
+
+	if sq, _ := ctx.Value(slowQueryKey{}).(*slowQueryData); sq != nil {
+		t.checkSlowQuery(ctx, sq)
+	}
+}
+
+// checkSlowQuery logs the query if it took longer than the threshold.
+func (t *tracer) checkSlowQuery(ctx context.Context, sq *slowQueryData) {
+	// threshold could be changed while the query was running
+	d := time.Since(sq.start)
+	if threshold := t.slowQuery.Threshold(); threshold <= 0 || d < threshold {
+		return
+	}
+
+	fields := append(
+		[]zap.Field{
+			zap.String("sql", sq.sql),
+			zap.Strings("args", observability.RedactArgs(sq.args)),
+			zap.Duration("duration", d),
+		},
+		observability.CommandFields(ctx)...,
+	)
+
+	p := t.p.Load()
+
+	// EXPLAIN does not execute the query, but be extra careful and capture only plans of SELECTs
+	if !t.slowQuery.Explain || p == nil || !isSelect(sq.sql) {
+		t.l.Warn("Slow query", fields...)
+		return
+	}
+
+	// do not block the caller
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+		defer cancel()
+
+		ctx = context.WithValue(ctx, explainKey{}, struct{}{})
+
+		var plan string
+		if err := p.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+sq.sql, sq.args...).Scan(&plan); err != nil {
+			fields = append(fields, zap.NamedError("explain_error", err))
+		} else {
+			fields = append(fields, zap.String("plan", plan))
+		}
+
+		t.l.Warn("Slow query", fields...)
+	}()
+}
+
+// isSelect returns true if the given SQL statement is a SELECT query.
+func isSelect(sql string) bool {
+	op, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	op = strings.ToUpper(op)
+
+	return op == "SELECT" || op == "WITH"
+}
+
+// check interfaces
+var (
+	_ pgx.QueryTracer   = (*tracer)(nil)
+	_ pgx.BatchTracer   = (*tracer)(nil)
+	_ pgx.ConnectTracer = (*tracer)(nil)
+)
@@ -0,0 +1,132 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// WarmUpOpts represents options for keeping PostgreSQL connections warm.
+type WarmUpOpts struct {
+	// MinConns is the minimal number of connections of each pool.
+	// They are established when the pool is created, and the pool for the base URI is created on startup.
+	// Zero disables warm-up.
+	MinConns int32
+
+	// PingInterval is the interval between pings of idle connections.
+	// Connections that fail to respond are closed;
+	// pgxpool re-establishes them up to MinConns during its periodic health check.
+	// Zero disables pings.
+	PingInterval time.Duration
+}
+
+// warmUp establishes n connections of the pool by acquiring them concurrently.
+func warmUp(ctx context.Context, p *pgxpool.Pool, n int32) error {
+	conns := make(chan *pgxpool.Conn, n)
+	errs := make(chan error, n)
+
+	for range n {
+		go func() {
+			conn, err := p.Acquire(ctx)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			conns <- conn
+		}()
+	}
+
+	// connections are released only after all of them are acquired,
+	// so each acquire establishes a new connection
+	acquired := make([]*pgxpool.Conn, 0, n)
+
+	var err error
+
+	for range n {
+		select {
+		case conn := <-conns:
+			acquired = append(acquired, conn)
+
+		case e := <-errs:
+			if err == nil {
+				err = e
+			}
+		}
+	}
+
+	for _, conn := range acquired {
+		conn.Release()
+	}
+
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// runPings pings idle connections of all pools with the configured interval until the stop channel is closed.
+func (p *Pool) runPings() {
+	p.l.Info("Pool: idle connections pings enabled.", zap.Duration("interval", p.wu.PingInterval))
+
+	ticker := time.NewTicker(p.wu.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.pingIdle()
+
+		case <-p.pingStop:
+			p.l.Info("Pool: idle connections pings stopped.")
+			return
+		}
+	}
+}
+
+// pingIdle pings idle connections of all pools and closes ones that fail to respond.
+func (p *Pool) pingIdle() {
+	p.rw.RLock()
+
+	pools := make([]*pgxpool.Pool, 0, len(p.pools))
+	for _, pool := range p.pools {
+		pools = append(pools, pool)
+	}
+
+	p.rw.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.wu.PingInterval)
+	defer cancel()
+
+	for _, pool := range pools {
+		for _, conn := range pool.AcquireAllIdle(ctx) {
+			if err := conn.Ping(ctx); err != nil {
+				p.l.Warn("Pool: idle connection ping failed, closing it", zap.Error(err))
+
+				// closed connection is destroyed on release instead of being returned to the pool
+				_ = conn.Conn().Close(ctx)
+			}
+
+			conn.Release()
+		}
+	}
+}
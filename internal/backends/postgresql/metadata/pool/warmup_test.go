@@ -0,0 +1,52 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/util/state"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestWarmUp(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	u := testutil.TestPostgreSQLURI(t, ctx, "")
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	p, err := New(u, testutil.Logger(t), sp, nil, &WarmUpOpts{
+		MinConns:     3,
+		PingInterval: time.Hour,
+	})
+	require.NoError(t, err)
+	t.Cleanup(p.Close)
+
+	// pool for the base URI is created on startup
+	pool := p.GetAny()
+	require.NotNil(t, pool)
+	assert.GreaterOrEqual(t, pool.Stat().TotalConns(), int32(3))
+
+	p.pingIdle()
+	assert.GreaterOrEqual(t, pool.Stat().IdleConns(), int32(3))
+}
@@ -76,19 +76,25 @@ type Registry struct {
 	p         *pool.Pool
 	l         *zap.Logger
 	BatchSize int
+	sqlViews  bool
 
-	// rw protects colls but also acts like a global lock for the whole registry.
+	// rw protects colls and views but also acts like a global lock for the whole registry.
 	// The latter effectively replaces transactions (see the postgresql backend package description for more info).
 	// One global lock should be replaced by more granular locks – one per database or even one per collection.
 	// But that requires some redesign.
 	// TODO https://github.com/FerretDB/FerretDB/issues/2755
 	rw    sync.RWMutex
 	colls map[string]map[string]*Collection // database name -> collection name -> collection
+	views map[string][]viewColumn           // view key -> columns, only if SQL views are enabled
 }
 
 // NewRegistry creates a registry for PostgreSQL databases with a given base URI.
-func NewRegistry(u string, batchSize int, l *zap.Logger, sp *state.Provider) (*Registry, error) {
-	p, err := pool.New(u, l, sp)
+//
+// If sq is not nil, slow queries are logged.
+// If wu is not nil, connections are kept warm.
+// If sqlViews is true, read-only SQL views of collections are maintained.
+func NewRegistry(u string, batchSize int, l *zap.Logger, sp *state.Provider, sq *observability.SlowQueryOpts, wu *pool.WarmUpOpts, sqlViews bool) (*Registry, error) { //nolint:lll // for readability
+	p, err := pool.New(u, l, sp, sq, wu)
 	if err != nil {
 		return nil, err
 	}
@@ -97,6 +103,7 @@ func NewRegistry(u string, batchSize int, l *zap.Logger, sp *state.Provider) (*R
 		p:         p,
 		l:         l,
 		BatchSize: batchSize,
+		sqlViews:  sqlViews,
 	}
 
 	return r, nil
@@ -161,6 +168,10 @@ func (r *Registry) getPool(ctx context.Context) (*pgxpool.Pool, error) {
 		}
 	}
 
+	if err = r.viewsInit(ctx, p); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
 	return p, nil
 }
 
@@ -423,6 +434,13 @@ func (r *Registry) databaseDrop(ctx context.Context, p *pgxpool.Pool, dbName str
 
 	delete(r.colls, dbName)
 
+	// views were dropped together with tables
+	for key := range r.views {
+		if strings.HasPrefix(key, dbName+".") {
+			delete(r.views, key)
+		}
+	}
+
 	return true, nil
 }
 
@@ -585,6 +603,13 @@ func (r *Registry) collectionCreate(ctx context.Context, p *pgxpool.Pool, params
 		return false, lazyerrors.Error(err)
 	}
 
+	if r.sqlViews {
+		if err = r.viewCreate(ctx, p, dbName, c, nil, false); err != nil {
+			_, _ = r.collectionDrop(ctx, p, dbName, collectionName)
+			return false, lazyerrors.Error(err)
+		}
+	}
+
 	return true, nil
 }
 
@@ -689,6 +714,9 @@ func (r *Registry) collectionDrop(ctx context.Context, p *pgxpool.Pool, dbName,
 
 	delete(r.colls[dbName], collectionName)
 
+	// the view was dropped together with the table
+	delete(r.views, viewKey(dbName, collectionName))
+
 	return true, nil
 }
 
@@ -711,6 +739,101 @@ func (r *Registry) CollectionRename(ctx context.Context, dbName, oldCollectionNa
 	r.rw.Lock()
 	defer r.rw.Unlock()
 
+	return r.collectionRename(ctx, p, dbName, oldCollectionName, newCollectionName)
+}
+
+// CollectionReplace renames a collection in the database,
+// dropping the existing collection with the new name first, if any.
+// Both operations are performed in a single transaction.
+//
+// Returned boolean value indicates whether the collection was renamed.
+// If database or old collection did not exist, (false, nil) is returned.
+//
+// If the user is not authenticated, it returns error.
+func (r *Registry) CollectionReplace(ctx context.Context, dbName, oldCollectionName, newCollectionName string) (bool, error) {
+	defer observability.FuncCall(ctx)()
+
+	p, err := r.getPool(ctx)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	c := r.collectionGet(dbName, oldCollectionName)
+	if c == nil {
+		return false, nil
+	}
+
+	target := r.collectionGet(dbName, newCollectionName)
+
+	c.Name = newCollectionName
+
+	b, err := sjson.Marshal(c.marshal())
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	oldArg, err := sjson.MarshalSingleValue(oldCollectionName)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	newArg, err := sjson.MarshalSingleValue(newCollectionName)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	metadataTable := pgx.Identifier{dbName, metadataTableName}.Sanitize()
+
+	err = pool.InTransaction(ctx, p, func(tx pgx.Tx) error {
+		if target != nil {
+			// TODO https://github.com/FerretDB/FerretDB/issues/811
+			q := fmt.Sprintf(`DROP TABLE %s CASCADE`, pgx.Identifier{dbName, target.TableName}.Sanitize())
+			if _, err := tx.Exec(ctx, q); err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			q = fmt.Sprintf(`DELETE FROM %s WHERE %s IN ($1)`, metadataTable, IDColumn)
+			if _, err := tx.Exec(ctx, q, newArg); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		q := fmt.Sprintf(`UPDATE %s SET %s = $1 WHERE %s = $2`, metadataTable, DefaultColumn, IDColumn)
+		if _, err := tx.Exec(ctx, q, string(b), oldArg); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	// the view of the target was dropped together with the table
+	delete(r.views, viewKey(dbName, newCollectionName))
+
+	r.colls[dbName][newCollectionName] = c
+	delete(r.colls[dbName], oldCollectionName)
+
+	if err = r.viewRename(ctx, p, dbName, oldCollectionName, c); err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	return true, nil
+}
+
+// collectionRename renames a collection in the database.
+//
+// Returned boolean value indicates whether the collection was renamed.
+// If database or collection did not exist, (false, nil) is returned.
+//
+// It does not hold the lock.
+func (r *Registry) collectionRename(ctx context.Context, p *pgxpool.Pool, dbName, oldCollectionName, newCollectionName string) (bool, error) { //nolint:lll // for readability
+	defer observability.FuncCall(ctx)()
+
 	db := r.colls[dbName]
 	if db == nil {
 		return false, nil
@@ -747,6 +870,10 @@ func (r *Registry) CollectionRename(ctx context.Context, dbName, oldCollectionNa
 	r.colls[dbName][newCollectionName] = c
 	delete(r.colls[dbName], oldCollectionName)
 
+	if err = r.viewRename(ctx, p, dbName, oldCollectionName, c); err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
 	return true, nil
 }
 
@@ -941,6 +1068,8 @@ func (r *Registry) IndexesDrop(ctx context.Context, dbName, collectionName strin
 //
 // If database or collection does not exist, nil is returned.
 //
+// Indexes are dropped in a single transaction: either all or none of them are dropped.
+//
 // It does not hold the lock.
 func (r *Registry) indexesDrop(ctx context.Context, p *pgxpool.Pool, dbName, collectionName string, indexNames []string) error {
 	defer observability.FuncCall(ctx)()
@@ -950,38 +1079,45 @@ func (r *Registry) indexesDrop(ctx context.Context, p *pgxpool.Pool, dbName, col
 		return nil
 	}
 
-	for _, name := range indexNames {
-		i := slices.IndexFunc(c.Indexes, func(i IndexInfo) bool { return name == i.Name })
-		if i < 0 {
-			continue
+	err := pool.InTransaction(ctx, p, func(tx pgx.Tx) error {
+		for _, name := range indexNames {
+			i := slices.IndexFunc(c.Indexes, func(i IndexInfo) bool { return name == i.Name })
+			if i < 0 {
+				continue
+			}
+
+			q := fmt.Sprintf("DROP INDEX %s", pgx.Identifier{dbName, c.Indexes[i].PgIndex}.Sanitize())
+			if _, err := tx.Exec(ctx, q); err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			c.Indexes = slices.Delete(c.Indexes, i, i+1)
 		}
 
-		q := fmt.Sprintf("DROP INDEX %s", pgx.Identifier{dbName, c.Indexes[i].PgIndex}.Sanitize())
-		if _, err := p.Exec(ctx, q); err != nil {
+		b, err := sjson.Marshal(c.marshal())
+		if err != nil {
 			return lazyerrors.Error(err)
 		}
 
-		c.Indexes = slices.Delete(c.Indexes, i, i+1)
-	}
-
-	b, err := sjson.Marshal(c.marshal())
-	if err != nil {
-		return lazyerrors.Error(err)
-	}
+		arg, err := sjson.MarshalSingleValue(collectionName)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
 
-	arg, err := sjson.MarshalSingleValue(collectionName)
-	if err != nil {
-		return lazyerrors.Error(err)
-	}
+		q := fmt.Sprintf(
+			`UPDATE %s SET %s = $1 WHERE %s = $2`,
+			pgx.Identifier{dbName, metadataTableName}.Sanitize(),
+			DefaultColumn,
+			IDColumn,
+		)
 
-	q := fmt.Sprintf(
-		`UPDATE %s SET %s = $1 WHERE %s = $2`,
-		pgx.Identifier{dbName, metadataTableName}.Sanitize(),
-		DefaultColumn,
-		IDColumn,
-	)
+		if _, err := tx.Exec(ctx, q, string(b), arg); err != nil {
+			return lazyerrors.Error(err)
+		}
 
-	if _, err := p.Exec(ctx, q, string(b), arg); err != nil {
+		return nil
+	})
+	if err != nil {
 		return lazyerrors.Error(err)
 	}
 
@@ -0,0 +1,441 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/postgresql/metadata/pool"
+	"github.com/FerretDB/FerretDB/internal/handler/sjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/observability"
+)
+
+const (
+	// PostgreSQL schema name where read-only SQL views of collections are stored.
+	viewsSchemaName = backends.ReservedPrefix + "sql"
+
+	// PostgreSQL max column name length.
+	maxColumnNameLength = 63
+
+	// Max number of columns in a single view.
+	// PostgreSQL allows up to 1664 columns in a select list; other fields are not exposed.
+	maxViewColumns = 1600
+
+	// Kind of view column that contains values of different types.
+	mixedKind = "mixed"
+)
+
+// viewColumn describes a single column of collection's SQL view.
+type viewColumn struct {
+	Field string // top-level document field name
+	Kind  string // sjson type of all field's values or mixedKind
+}
+
+// sqlType returns PostgreSQL type of the view column.
+func (vc viewColumn) sqlType() string {
+	switch vc.Kind {
+	case "double":
+		return "double precision"
+	case "int":
+		return "integer"
+	case "long":
+		return "bigint"
+	case "string", "objectId":
+		return "text"
+	case "bool":
+		return "boolean"
+	case "date":
+		return "timestamp with time zone"
+	default:
+		return "jsonb"
+	}
+}
+
+// expression returns PostgreSQL expression that extracts the view column value from the document.
+func (vc viewColumn) expression() string {
+	field := quoteString(vc.Field)
+
+	switch t := vc.sqlType(); t {
+	case "jsonb":
+		return fmt.Sprintf(`%s->%s`, DefaultColumn, field)
+	case "text":
+		return fmt.Sprintf(`%s->>%s`, DefaultColumn, field)
+	case "timestamp with time zone":
+		// dates are stored as milliseconds since epoch
+		return fmt.Sprintf(`to_timestamp((%s->>%s)::bigint / 1000.0)`, DefaultColumn, field)
+	default:
+		return fmt.Sprintf(`(%s->>%s)::%s`, DefaultColumn, field, t)
+	}
+}
+
+// mergeKinds returns the kind of view column that can hold values of both given kinds.
+//
+// Empty kind means that there are no values yet.
+// Nulls are compatible with any kind, integer kinds are promoted to wider numeric kinds.
+func mergeKinds(a, b string) string {
+	switch {
+	case a == "" || a == b || a == "null":
+		return b
+	case b == "" || b == "null":
+		return a
+	}
+
+	numeric := map[string]int{"int": 1, "long": 2, "double": 3}
+
+	ra, oka := numeric[a]
+	rb, okb := numeric[b]
+
+	if !oka || !okb {
+		return mixedKind
+	}
+
+	if ra > rb {
+		return a
+	}
+
+	return b
+}
+
+// mergeColumns returns view columns updated with fields of given documents.
+//
+// The first returned boolean value indicates whether columns were changed.
+// The second one indicates whether the only change is new columns added to the end,
+// so the existing view could be replaced without being dropped.
+func mergeColumns(columns []viewColumn, docs []*types.Document) ([]viewColumn, bool, bool) {
+	res := slices.Clone(columns)
+	changed, extended := false, true
+
+	for _, doc := range docs {
+		for _, field := range doc.Keys() {
+			kind := sjson.GetTypeOfValue(must.NotFail(doc.Get(field)))
+
+			i := slices.IndexFunc(res, func(vc viewColumn) bool { return vc.Field == field })
+			if i >= 0 {
+				merged := viewColumn{Field: field, Kind: mergeKinds(res[i].Kind, kind)}
+				if merged == res[i] {
+					continue
+				}
+
+				// columns added by previous documents could be changed freely
+				if i < len(columns) && merged.sqlType() != res[i].sqlType() {
+					extended = false
+				}
+
+				res[i] = merged
+				changed = true
+
+				continue
+			}
+
+			if len(res) >= maxViewColumns || len(field) > maxColumnNameLength {
+				continue
+			}
+
+			res = append(res, viewColumn{Field: field, Kind: kind})
+			changed = true
+		}
+	}
+
+	return res, changed, extended
+}
+
+// viewKey returns the key of r.views map for the given collection.
+func viewKey(dbName, collectionName string) string {
+	// database names can't contain dots, so that key is unique
+	return dbName + "." + collectionName
+}
+
+// viewName returns PostgreSQL view name for the given collection.
+//
+// It is `database.collection` if that fits the PostgreSQL identifier length limit;
+// otherwise, it is truncated and a hash suffix is added.
+func viewName(dbName, collectionName string) string {
+	name := viewKey(dbName, collectionName)
+	if len(name) <= maxTableNameLength {
+		return name
+	}
+
+	h := fnv.New32a()
+	must.NotFail(h.Write([]byte(name)))
+
+	suffix := fmt.Sprintf("_%08x", h.Sum32())
+	name = name[:maxTableNameLength-len(suffix)]
+
+	// do not cut multibyte characters in half
+	for !utf8.ValidString(name) {
+		name = name[:len(name)-1]
+	}
+
+	return name + suffix
+}
+
+// viewsInit creates SQL views for all collections if they are enabled.
+//
+// Views created by previous runs are kept if they are disabled.
+//
+// It does not hold the lock.
+func (r *Registry) viewsInit(ctx context.Context, p *pgxpool.Pool) error {
+	defer observability.FuncCall(ctx)()
+
+	if !r.sqlViews {
+		return nil
+	}
+
+	q := fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS %s`, pgx.Identifier{viewsSchemaName}.Sanitize())
+	if _, err := p.Exec(ctx, q); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	r.views = map[string][]viewColumn{}
+
+	for dbName, colls := range r.colls {
+		for _, c := range colls {
+			columns, err := r.viewColumnsScan(ctx, p, dbName, c)
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			// views could be left from the previous run with different columns
+			if err = r.viewCreate(ctx, p, dbName, c, columns, false); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// viewColumnsScan returns view columns for all fields of all documents in the collection.
+//
+// It does not hold the lock.
+func (r *Registry) viewColumnsScan(ctx context.Context, p *pgxpool.Pool, dbName string, c *Collection) ([]viewColumn, error) {
+	defer observability.FuncCall(ctx)()
+
+	q := fmt.Sprintf(
+		`SELECT DISTINCT p.key, p.value->>'t' FROM %s, jsonb_each(%s->'$s'->'p') AS p`,
+		pgx.Identifier{dbName, c.TableName}.Sanitize(),
+		DefaultColumn,
+	)
+
+	rows, err := p.Query(ctx, q)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	kinds := map[string]string{}
+
+	for rows.Next() {
+		var field, kind string
+		if err = rows.Scan(&field, &kind); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		kinds[field] = mergeKinds(kinds[field], kind)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	fields := make([]string, 0, len(kinds))
+	for field := range kinds {
+		if len(field) <= maxColumnNameLength {
+			fields = append(fields, field)
+		}
+	}
+
+	// _id first, other fields sorted
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i] == "_id" || fields[j] == "_id" {
+			return fields[i] == "_id"
+		}
+
+		return fields[i] < fields[j]
+	})
+
+	if len(fields) > maxViewColumns {
+		fields = fields[:maxViewColumns]
+	}
+
+	columns := make([]viewColumn, len(fields))
+	for i, field := range fields {
+		columns[i] = viewColumn{Field: field, Kind: kinds[field]}
+	}
+
+	return columns, nil
+}
+
+// viewCreate creates or re-creates SQL view for the collection with given columns.
+//
+// If replace is true, the existing view is replaced without being dropped;
+// that is possible only if existing columns are unchanged and new ones are added to the end.
+//
+// It does not hold the lock.
+func (r *Registry) viewCreate(ctx context.Context, p *pgxpool.Pool, dbName string, c *Collection, columns []viewColumn, replace bool) error { //nolint:lll // for readability
+	defer observability.FuncCall(ctx)()
+
+	exprs := make([]string, len(columns))
+	for i, column := range columns {
+		exprs[i] = column.expression() + " AS " + pgx.Identifier{column.Field}.Sanitize()
+	}
+
+	view := pgx.Identifier{viewsSchemaName, viewName(dbName, c.Name)}.Sanitize()
+
+	q := fmt.Sprintf(
+		`CREATE OR REPLACE VIEW %s AS SELECT %s FROM %s`,
+		view,
+		strings.Join(exprs, ", "),
+		pgx.Identifier{dbName, c.TableName}.Sanitize(),
+	)
+
+	err := pool.InTransaction(ctx, p, func(tx pgx.Tx) error {
+		if !replace {
+			if _, err := tx.Exec(ctx, `DROP VIEW IF EXISTS `+view); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		if _, err := tx.Exec(ctx, q); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	r.views[viewKey(dbName, c.Name)] = columns
+
+	return nil
+}
+
+// viewDrop drops SQL view of the collection.
+//
+// It does not hold the lock.
+func (r *Registry) viewDrop(ctx context.Context, p *pgxpool.Pool, dbName, collectionName string) error {
+	defer observability.FuncCall(ctx)()
+
+	q := fmt.Sprintf(
+		`DROP VIEW IF EXISTS %s`,
+		pgx.Identifier{viewsSchemaName, viewName(dbName, collectionName)}.Sanitize(),
+	)
+
+	if _, err := p.Exec(ctx, q); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	delete(r.views, viewKey(dbName, collectionName))
+
+	return nil
+}
+
+// viewRename moves SQL view of the collection renamed from oldCollectionName to c.Name.
+//
+// It does nothing if SQL views are disabled.
+//
+// It does not hold the lock.
+func (r *Registry) viewRename(ctx context.Context, p *pgxpool.Pool, dbName, oldCollectionName string, c *Collection) error {
+	defer observability.FuncCall(ctx)()
+
+	if !r.sqlViews {
+		return nil
+	}
+
+	columns := r.views[viewKey(dbName, oldCollectionName)]
+
+	if err := r.viewDrop(ctx, p, dbName, oldCollectionName); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err := r.viewCreate(ctx, p, dbName, c, columns, false); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// ViewUpdate refreshes SQL view of the collection if given inserted or updated documents
+// contain fields or value types that the view does not have yet.
+//
+// It does nothing if SQL views are disabled or the collection does not exist.
+//
+// It is called after documents are stored, so errors are logged instead of failing the write.
+// The view is refreshed again by the next write with new fields.
+func (r *Registry) ViewUpdate(ctx context.Context, dbName, collectionName string, docs []*types.Document) {
+	defer observability.FuncCall(ctx)()
+
+	if !r.sqlViews {
+		return
+	}
+
+	if err := r.viewUpdate(ctx, dbName, collectionName, docs); err != nil {
+		r.l.Warn(
+			"Failed to update SQL view",
+			zap.String("db", dbName), zap.String("collection", collectionName), zap.Error(err),
+		)
+	}
+}
+
+// viewUpdate refreshes SQL view of the collection if needed.
+//
+// If the user is not authenticated, it returns error.
+func (r *Registry) viewUpdate(ctx context.Context, dbName, collectionName string, docs []*types.Document) error {
+	p, err := r.getPool(ctx)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	key := viewKey(dbName, collectionName)
+
+	// check without blocking other readers first
+	r.rw.RLock()
+	_, changed, _ := mergeColumns(r.views[key], docs)
+	r.rw.RUnlock()
+
+	if !changed {
+		return nil
+	}
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	c := r.collectionGet(dbName, collectionName)
+	if c == nil {
+		return nil
+	}
+
+	columns, changed, extended := mergeColumns(r.views[key], docs)
+	if !changed {
+		return nil
+	}
+
+	return r.viewCreate(ctx, p, dbName, c, columns, extended)
+}
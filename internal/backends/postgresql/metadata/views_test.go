@@ -0,0 +1,184 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/state"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestMergeColumns(t *testing.T) {
+	t.Parallel()
+
+	columns, changed, extended := mergeColumns(nil, []*types.Document{
+		must.NotFail(types.NewDocument("_id", int32(1), "v", int32(1), "n", types.Null)),
+		must.NotFail(types.NewDocument("_id", int32(2), "v", int64(2), "s", "foo")),
+	})
+	assert.True(t, changed)
+	assert.True(t, extended)
+
+	expected := []viewColumn{
+		{Field: "_id", Kind: "int"},
+		{Field: "v", Kind: "long"},
+		{Field: "n", Kind: "null"},
+		{Field: "s", Kind: "string"},
+	}
+	assert.Equal(t, expected, columns)
+
+	_, changed, _ = mergeColumns(columns, []*types.Document{
+		must.NotFail(types.NewDocument("_id", int32(3), "v", types.Null, "s", "bar")),
+	})
+	assert.False(t, changed)
+
+	columns, changed, extended = mergeColumns(columns, []*types.Document{
+		must.NotFail(types.NewDocument("_id", int32(4), "n", int32(4), "d", time.Now())),
+	})
+	assert.True(t, changed)
+	assert.False(t, extended, "n column type changed from jsonb to integer")
+
+	expected = []viewColumn{
+		{Field: "_id", Kind: "int"},
+		{Field: "v", Kind: "long"},
+		{Field: "n", Kind: "int"},
+		{Field: "s", Kind: "string"},
+		{Field: "d", Kind: "date"},
+	}
+	assert.Equal(t, expected, columns)
+
+	columns, changed, extended = mergeColumns(columns, []*types.Document{
+		must.NotFail(types.NewDocument("_id", "five", "v", 5.5)),
+	})
+	assert.True(t, changed)
+	assert.False(t, extended)
+	assert.Equal(t, viewColumn{Field: "_id", Kind: mixedKind}, columns[0])
+	assert.Equal(t, viewColumn{Field: "v", Kind: "double"}, columns[1])
+}
+
+func TestViewName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "db.coll", viewName("db", "coll"))
+
+	assert.Equal(t, "db."+strings.Repeat("a", 60), viewName("db", strings.Repeat("a", 60)))
+
+	long := viewName("db", strings.Repeat("a", 61))
+	assert.Len(t, long, maxTableNameLength)
+	assert.NotEqual(t, long, viewName("db", strings.Repeat("a", 62)))
+
+	multibyte := viewName("db", strings.Repeat("ы", 40))
+	assert.LessOrEqual(t, len(multibyte), maxTableNameLength)
+	assert.True(t, utf8.ValidString(multibyte))
+}
+
+func TestViews(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in -short mode")
+	}
+
+	t.Parallel()
+
+	ctx := conninfo.Ctx(testutil.Ctx(t), conninfo.New())
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	r, err := NewRegistry(testutil.TestPostgreSQLURI(t, ctx, ""), 100, testutil.Logger(t), sp, nil, nil, true)
+	require.NoError(t, err)
+	t.Cleanup(r.Close)
+
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	p, err := r.DatabaseGetOrCreate(ctx, dbName)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = r.DatabaseDrop(ctx, dbName)
+	})
+
+	created, err := r.CollectionCreate(ctx, &CollectionCreateParams{DBName: dbName, Name: collectionName})
+	require.NoError(t, err)
+	require.True(t, created)
+
+	c, err := r.CollectionGet(ctx, dbName, collectionName)
+	require.NoError(t, err)
+
+	q := fmt.Sprintf(
+		`INSERT INTO %s (%s) VALUES($1)`,
+		pgx.Identifier{dbName, c.TableName}.Sanitize(),
+		DefaultColumn,
+	)
+	doc := `{"$s": {"p": {"_id": {"t": "int"}, "v": {"t": "string"}}, "$k": ["_id", "v"]}, "_id": 42, "v": "foo"}`
+	_, err = p.Exec(ctx, q, doc)
+	require.NoError(t, err)
+
+	r.ViewUpdate(ctx, dbName, collectionName, []*types.Document{
+		must.NotFail(types.NewDocument("_id", int32(42), "v", "foo")),
+	})
+
+	view := pgx.Identifier{viewsSchemaName, viewName(dbName, collectionName)}.Sanitize()
+
+	var id int32
+	var v string
+	err = p.QueryRow(ctx, `SELECT _id, v FROM `+view).Scan(&id, &v)
+	require.NoError(t, err)
+	assert.Equal(t, int32(42), id)
+	assert.Equal(t, "foo", v)
+
+	t.Run("Scan", func(t *testing.T) {
+		var columns []viewColumn
+		columns, err = r.viewColumnsScan(ctx, p, dbName, c)
+		require.NoError(t, err)
+
+		expected := []viewColumn{{Field: "_id", Kind: "int"}, {Field: "v", Kind: "string"}}
+		assert.Equal(t, expected, columns)
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		var renamed bool
+		renamed, err = r.CollectionRename(ctx, dbName, collectionName, "renamed")
+		require.NoError(t, err)
+		require.True(t, renamed)
+
+		renamedView := pgx.Identifier{viewsSchemaName, viewName(dbName, "renamed")}.Sanitize()
+		err = p.QueryRow(ctx, `SELECT _id FROM `+renamedView).Scan(&id)
+		require.NoError(t, err)
+
+		_, err = p.Exec(ctx, `SELECT 1 FROM `+view)
+		require.Error(t, err)
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		var dropped bool
+		dropped, err = r.CollectionDrop(ctx, dbName, "renamed")
+		require.NoError(t, err)
+		require.True(t, dropped)
+
+		assert.NotContains(t, r.views, viewKey(dbName, "renamed"))
+	})
+}
@@ -160,6 +160,17 @@ func prepareWhereClause(p *metadata.Placeholder, sqlFilters *types.Document) (st
 						args = append(args, a...)
 					}
 
+				case "$in":
+					// only lookups by _id are pushed down for now
+					if rootKey != "_id" {
+						continue
+					}
+
+					if f, a := filterIn(p, key, v, keyOperator); f != "" {
+						filters = append(filters, f)
+						args = append(args, a...)
+					}
+
 				case "$ne":
 					sql := `NOT ( ` +
 						// does document contain the key,
@@ -172,7 +183,7 @@ func prepareWhereClause(p *metadata.Placeholder, sqlFilters *types.Document) (st
 
 					switch v := v.(type) {
 					case *types.Document, *types.Array, types.Binary,
-						types.NullType, types.Regex, types.Timestamp:
+						types.NullType, types.Regex, types.Timestamp, types.Decimal128:
 						// type not supported for pushdown
 
 					case float64, bool, int32, int64:
@@ -212,7 +223,7 @@ func prepareWhereClause(p *metadata.Placeholder, sqlFilters *types.Document) (st
 				}
 			}
 
-		case *types.Array, types.Binary, types.NullType, types.Regex, types.Timestamp:
+		case *types.Array, types.Binary, types.NullType, types.Regex, types.Timestamp, types.Decimal128:
 			// type not supported for pushdown
 
 		case float64, string, types.ObjectID, bool, time.Time, int32, int64:
@@ -266,7 +277,7 @@ func filterEqual(p *metadata.Placeholder, k any, v any, operator string) (filter
 
 	switch v := v.(type) {
 	case *types.Document, *types.Array, types.Binary,
-		types.NullType, types.Regex, types.Timestamp:
+		types.NullType, types.Regex, types.Timestamp, types.Decimal128:
 		// type not supported for pushdown
 
 	case float64:
@@ -327,5 +338,47 @@ func filterEqual(p *metadata.Placeholder, k any, v any, operator string) (filter
 		panic(fmt.Sprintf("Unexpected type of value: %v", v))
 	}
 
+	switch v.(type) {
+	case float64, int32, int64:
+		// Decimal128 values are stored as JSON strings, so they should not be excluded by numeric filters;
+		// the handler compares them with the filter value.
+		filter = fmt.Sprintf(
+			`(%s OR %s%s%s @? '$[*] ? (@.type() == "string")')`,
+			filter, metadata.DefaultColumn, operator, p.Next(),
+		)
+		args = append(args, k)
+	}
+
 	return
 }
+
+// filterIn returns the proper SQL filter with arguments that filters documents
+// where the value under k is equal to one of the values of the given $in array.
+//
+// If v is not an array, is empty, or contains values of types not supported by filterEqual,
+// it returns empty filter.
+func filterIn(p *metadata.Placeholder, k any, v any, operator string) (filter string, args []any) {
+	in, ok := v.(*types.Array)
+	if !ok || in.Len() == 0 {
+		return "", nil
+	}
+
+	for i := 0; i < in.Len(); i++ {
+		switch must.NotFail(in.Get(i)).(type) {
+		case *types.Document, *types.Array, types.Binary,
+			types.NullType, types.Regex, types.Timestamp, types.Decimal128:
+			// type not supported for pushdown
+			return "", nil
+		}
+	}
+
+	filters := make([]string, in.Len())
+
+	for i := 0; i < in.Len(); i++ {
+		var a []any
+		filters[i], a = filterEqual(p, k, must.NotFail(in.Get(i)), operator)
+		args = append(args, a...)
+	}
+
+	return "(" + strings.Join(filters, " OR ") + ")", args
+}
@@ -97,7 +97,9 @@ func TestPrepareWhereClause(t *testing.T) {
 	whereContain := " WHERE _jsonb->$1 @> $2"
 	whereContainDotNotation := " WHERE _jsonb#>$1 @> $2"
 
-	whereGt := " WHERE _jsonb->$1 > $2"
+	// numeric filters also select Decimal128 values stored as strings
+	whereContainNumber := ` WHERE (_jsonb->$1 @> $2 OR _jsonb->$3 @? '$[*] ? (@.type() == "string")')`
+	whereGtNumber := ` WHERE (_jsonb->$1 > $2 OR _jsonb->$3 @? '$[*] ? (@.type() == "string")')`
 	whereNotEq := ` WHERE NOT ( _jsonb ? $1 AND _jsonb->$1 @> $2 AND _jsonb->'$s'->'p'->$1->'t' = `
 
 	for name, tc := range map[string]struct {
@@ -142,19 +144,19 @@ func TestPrepareWhereClause(t *testing.T) {
 		},
 		"ImplicitInt32": {
 			filter:   must.NotFail(types.NewDocument("v", int32(42))),
-			expected: whereContain,
+			expected: whereContainNumber,
 		},
 		"ImplicitInt64": {
 			filter:   must.NotFail(types.NewDocument("v", int64(42))),
-			expected: whereContain,
+			expected: whereContainNumber,
 		},
 		"ImplicitFloat64": {
 			filter:   must.NotFail(types.NewDocument("v", float64(42.13))),
-			expected: whereContain,
+			expected: whereContainNumber,
 		},
 		"ImplicitMaxFloat64": {
 			filter:   must.NotFail(types.NewDocument("v", math.MaxFloat64)),
-			expected: whereGt,
+			expected: whereGtNumber,
 		},
 		"ImplicitBool": {
 			filter:   must.NotFail(types.NewDocument("v", true)),
@@ -170,6 +172,9 @@ func TestPrepareWhereClause(t *testing.T) {
 			filter:   must.NotFail(types.NewDocument("v", objectID)),
 			expected: whereContain,
 		},
+		"ImplicitDecimal128": {
+			filter: must.NotFail(types.NewDocument("v", types.MustParseDecimal128("42.13"))),
+		},
 
 		"EqString": {
 			filter: must.NotFail(types.NewDocument(
@@ -188,34 +193,34 @@ func TestPrepareWhereClause(t *testing.T) {
 			filter: must.NotFail(types.NewDocument(
 				"v", must.NotFail(types.NewDocument("$eq", int32(42))),
 			)),
-			expected: whereContain,
+			expected: whereContainNumber,
 		},
 		"EqInt64": {
 			filter: must.NotFail(types.NewDocument(
 				"v", must.NotFail(types.NewDocument("$eq", int64(42))),
 			)),
-			expected: whereContain,
+			expected: whereContainNumber,
 		},
 		"EqFloat64": {
 			filter: must.NotFail(types.NewDocument(
 				"v", must.NotFail(types.NewDocument("$eq", float64(42.13))),
 			)),
-			expected: whereContain,
+			expected: whereContainNumber,
 		},
 		"EqMaxFloat64": {
 			filter: must.NotFail(types.NewDocument(
 				"v", must.NotFail(types.NewDocument("$eq", math.MaxFloat64)),
 			)),
-			args:     []any{`v`, types.MaxSafeDouble},
-			expected: whereGt,
+			args:     []any{`v`, types.MaxSafeDouble, `v`},
+			expected: whereGtNumber,
 		},
 		"EqDoubleBigInt64": {
 			filter: must.NotFail(types.NewDocument(
 				// TODO https://github.com/FerretDB/FerretDB/issues/3626
 				"v", must.NotFail(types.NewDocument("$eq", float64(2<<61))),
 			)),
-			args:     []any{`v`, types.MaxSafeDouble},
-			expected: whereGt,
+			args:     []any{`v`, types.MaxSafeDouble, `v`},
+			expected: whereGtNumber,
 		},
 		"EqBool": {
 			filter: must.NotFail(types.NewDocument(
@@ -296,6 +301,29 @@ func TestPrepareWhereClause(t *testing.T) {
 			expected: whereNotEq + `'"objectId"' )`,
 		},
 
+		"InID": {
+			filter: must.NotFail(types.NewDocument(
+				"_id", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray("foo", "bar")))),
+			)),
+			expected: ` WHERE (_jsonb->$1 @> $2 OR _jsonb->$3 @> $4)`,
+			args:     []any{`_id`, `"foo"`, `_id`, `"bar"`},
+		},
+		"InIDMixed": {
+			filter: must.NotFail(types.NewDocument(
+				"_id", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray("foo", types.Null)))),
+			)),
+		},
+		"InIDEmpty": {
+			filter: must.NotFail(types.NewDocument(
+				"_id", must.NotFail(types.NewDocument("$in", types.MakeArray(0))),
+			)),
+		},
+		"InField": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray("foo")))),
+			)),
+		},
+
 		"Comment": {
 			filter: must.NotFail(types.NewDocument("$comment", "I'm comment")),
 		},
@@ -18,6 +18,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
@@ -67,19 +69,7 @@ func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*
 
 	q := prepareSelectClause(meta.TableName, params.Comment, meta.Capped(), params.OnlyRecordIDs)
 
-	var whereClause string
-	var args []any
-
-	// that logic should exist in one place
-	// TODO https://github.com/FerretDB/FerretDB/issues/3235
-	if params.Filter.Len() == 1 {
-		v, _ := params.Filter.Get("_id")
-		switch v.(type) {
-		case string, types.ObjectID:
-			whereClause = fmt.Sprintf(` WHERE %s = ?`, metadata.IDColumn)
-			args = []any{string(must.NotFail(sjson.MarshalSingleValue(v)))}
-		}
-	}
+	whereClause, args := prepareWhereClause(params.Filter)
 
 	q += whereClause
 	q += prepareOrderByClause(params.Sort)
@@ -89,6 +79,11 @@ func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*
 		args = append(args, params.Limit)
 	}
 
+	if params.Sample != 0 {
+		q += ` ORDER BY random() LIMIT ?`
+		args = append(args, params.Sample)
+	}
+
 	rows, err := db.QueryContext(ctx, q, args...)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -129,7 +124,7 @@ func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllPa
 			if _, err = tx.ExecContext(ctx, q, args...); err != nil {
 				var se *sqlite3.Error
 				if errors.As(err, &se) && se.Code() == sqlite3lib.SQLITE_CONSTRAINT_UNIQUE {
-					return backends.NewError(backends.ErrorCodeInsertDuplicateID, err)
+					return duplicateKeyError(meta, err)
 				}
 
 				return lazyerrors.Error(err)
@@ -174,6 +169,11 @@ func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllPa
 
 			r, err := tx.ExecContext(ctx, q, string(b), arg)
 			if err != nil {
+				var se *sqlite3.Error
+				if errors.As(err, &se) && se.Code() == sqlite3lib.SQLITE_CONSTRAINT_UNIQUE {
+					return duplicateKeyError(meta, err)
+				}
+
 				return lazyerrors.Error(err)
 			}
 
@@ -188,6 +188,10 @@ func (c *collection) UpdateAll(ctx context.Context, params *backends.UpdateAllPa
 		return nil
 	})
 	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID) {
+			return nil, err
+		}
+
 		return nil, lazyerrors.Error(err)
 	}
 
@@ -269,28 +273,15 @@ func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams
 
 	selectClause := prepareSelectClause(meta.TableName, "", meta.Capped(), false)
 
-	var filterPushdown bool
-	var whereClause string
-	var args []any
-
-	// that logic should exist in one place
-	// TODO https://github.com/FerretDB/FerretDB/issues/3235
-	if params.Filter.Len() == 1 {
-		v, _ := params.Filter.Get("_id")
-		switch v.(type) {
-		case string, types.ObjectID:
-			filterPushdown = true
-			whereClause = fmt.Sprintf(` WHERE %s = ?`, metadata.IDColumn)
-			args = []any{string(must.NotFail(sjson.MarshalSingleValue(v)))}
-		}
-	}
+	whereClause, args := prepareWhereClause(params.Filter)
+	filterPushdown := whereClause != ""
 
 	orderByClause := prepareOrderByClause(params.Sort)
 	sortPushdown := orderByClause != ""
 
 	q := `EXPLAIN QUERY PLAN ` + selectClause + whereClause + orderByClause
 
-	var limitPushdown bool
+	var limitPushdown, samplePushdown bool
 
 	if params.Limit != 0 {
 		q += ` LIMIT ?`
@@ -298,6 +289,12 @@ func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams
 		limitPushdown = true
 	}
 
+	if params.Sample != 0 {
+		q += ` ORDER BY random() LIMIT ?`
+		args = append(args, params.Sample)
+		samplePushdown = true
+	}
+
 	rows, err := db.QueryContext(ctx, q, args...)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -332,9 +329,42 @@ func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams
 		FilterPushdown: filterPushdown,
 		SortPushdown:   sortPushdown,
 		LimitPushdown:  limitPushdown,
+		SamplePushdown: samplePushdown,
 	}, nil
 }
 
+// Count implements backends.Collection interface.
+//
+// Estimate parameter is ignored because SQLite does not maintain row counts for tables;
+// the exact count is always returned.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	db := c.r.DatabaseGetExisting(ctx, c.dbName)
+	if db == nil {
+		return &backends.CountResult{FilterPushdown: true}, nil
+	}
+
+	meta := c.r.CollectionGet(ctx, c.dbName, c.name)
+	if meta == nil {
+		return &backends.CountResult{FilterPushdown: true}, nil
+	}
+
+	whereClause, args := prepareWhereClause(params.Filter)
+	if whereClause == "" && params.Filter.Len() != 0 {
+		return new(backends.CountResult), nil
+	}
+
+	q := fmt.Sprintf(`SELECT COUNT(*) FROM %q`, meta.TableName) + whereClause
+
+	var res backends.CountResult
+	if err := db.QueryRowContext(ctx, q, args...).Scan(&res.Count); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res.FilterPushdown = true
+
+	return &res, nil
+}
+
 // Stats implements backends.Collection interface.
 func (c *collection) Stats(ctx context.Context, params *backends.CollectionStatsParams) (*backends.CollectionStatsResult, error) {
 	db := c.r.DatabaseGetExisting(ctx, c.dbName)
@@ -451,6 +481,40 @@ func (c *collection) Compact(ctx context.Context, params *backends.CompactParams
 	return new(backends.CompactResult), nil
 }
 
+// ReIndex implements backends.Collection interface.
+//
+// SQLite can't rebuild indexes concurrently, so that parameter is ignored.
+func (c *collection) ReIndex(ctx context.Context, params *backends.ReIndexParams) (*backends.ReIndexResult, error) {
+	db := c.r.DatabaseGetExisting(ctx, c.dbName)
+	if db == nil {
+		return nil, backends.NewError(
+			backends.ErrorCodeDatabaseDoesNotExist,
+			lazyerrors.Errorf("no ns %s.%s", c.dbName, c.name),
+		)
+	}
+
+	coll := c.r.CollectionGet(ctx, c.dbName, c.name)
+	if coll == nil {
+		return nil, backends.NewError(
+			backends.ErrorCodeCollectionDoesNotExist,
+			lazyerrors.Errorf("no ns %s.%s", c.dbName, c.name),
+		)
+	}
+
+	for _, index := range coll.Settings.Indexes {
+		if params != nil && params.Indexes != nil && !slices.Contains(params.Indexes, index.Name) {
+			continue
+		}
+
+		q := fmt.Sprintf("REINDEX %q", coll.TableName+"_"+index.Name)
+		if _, err := db.ExecContext(ctx, q); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return new(backends.ReIndexResult), nil
+}
+
 // ListIndexes implements backends.Collection interface.
 func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndexesParams) (*backends.ListIndexesResult, error) {
 	db := c.r.DatabaseGetExisting(ctx, c.dbName)
@@ -486,6 +550,15 @@ func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndex
 				Descending: key.Descending,
 			}
 		}
+
+		if index.Options != nil {
+			options, err := sjson.Unmarshal(index.Options)
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			res.Indexes[i].SetOptions(options)
+		}
 	}
 
 	sort.Slice(res.Indexes, func(i, j int) bool {
@@ -511,6 +584,15 @@ func (c *collection) CreateIndexes(ctx context.Context, params *backends.CreateI
 				Descending: key.Descending,
 			}
 		}
+
+		if options := index.OptionsDocument(); options != nil {
+			b, err := sjson.Marshal(options)
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			indexes[i].Options = b
+		}
 	}
 
 	err := c.r.IndexesCreate(ctx, c.dbName, c.name, indexes)
@@ -531,6 +613,41 @@ func (c *collection) DropIndexes(ctx context.Context, params *backends.DropIndex
 	return new(backends.DropIndexesResult), nil
 }
 
+// uniqueIndexRe matches SQLite unique constraint error messages for expression indexes.
+var uniqueIndexRe = regexp.MustCompile(`UNIQUE constraint failed: index '([^']+)'`)
+
+// duplicateKeyError returns backend error for the given SQLite unique constraint violation error
+// with the violated index of the given collection, if it could be found.
+func duplicateKeyError(meta *metadata.Collection, err error) error {
+	m := uniqueIndexRe.FindStringSubmatch(err.Error())
+	if m == nil {
+		return backends.NewDuplicateKeyError(nil, err)
+	}
+
+	for _, index := range meta.Settings.Indexes {
+		if meta.TableName+"_"+index.Name != m[1] {
+			continue
+		}
+
+		res := &backends.IndexInfo{
+			Name:   index.Name,
+			Unique: index.Unique,
+			Key:    make([]backends.IndexKeyPair, len(index.Key)),
+		}
+
+		for i, key := range index.Key {
+			res.Key[i] = backends.IndexKeyPair{
+				Field:      key.Field,
+				Descending: key.Descending,
+			}
+		}
+
+		return backends.NewDuplicateKeyError(res, err)
+	}
+
+	return backends.NewDuplicateKeyError(nil, err)
+}
+
 // check interfaces
 var (
 	_ backends.Collection = (*collection)(nil)
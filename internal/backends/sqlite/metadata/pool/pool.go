@@ -71,6 +71,7 @@ type Pool struct {
 func New(u string, l *zap.Logger, sp *state.Provider) (*Pool, map[string]*fsql.DB, error) {
 	uri, err := parseURI(u)
 	if err != nil {
+		//checkerrors:ignore // user-facing configuration error
 		return nil, nil, fmt.Errorf("failed to parse SQLite URI %q: %s", u, err)
 	}
 
@@ -28,6 +28,8 @@ import (
 //
 // Returned URL contains path in both Path and Opaque to make String() method work correctly.
 // Callers should use Path.
+//
+//checkerrors:ignore // user-facing configuration errors
 func parseURI(u string) (*url.URL, error) {
 	uri, err := url.Parse(u)
 	if err != nil {
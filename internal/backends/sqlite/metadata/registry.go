@@ -431,13 +431,80 @@ func (r *Registry) collectionDrop(ctx context.Context, dbName, collectionName st
 func (r *Registry) CollectionRename(ctx context.Context, dbName, oldCollectionName, newCollectionName string) (bool, error) {
 	defer observability.FuncCall(ctx)()
 
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	return r.collectionRename(ctx, dbName, oldCollectionName, newCollectionName)
+}
+
+// CollectionReplace renames a collection in the database,
+// dropping the existing collection with the new name first, if any.
+// Both operations are performed in a single transaction.
+//
+// Returned boolean value indicates whether the collection was renamed.
+// If database or old collection did not exist, (false, nil) is returned.
+func (r *Registry) CollectionReplace(ctx context.Context, dbName, oldCollectionName, newCollectionName string) (bool, error) {
+	defer observability.FuncCall(ctx)()
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
 	db := r.DatabaseGetExisting(ctx, dbName)
 	if db == nil {
 		return false, nil
 	}
 
-	r.rw.Lock()
-	defer r.rw.Unlock()
+	c := r.collectionGet(dbName, oldCollectionName)
+	if c == nil {
+		return false, nil
+	}
+
+	target := r.collectionGet(dbName, newCollectionName)
+
+	err := db.InTransaction(ctx, func(tx *fsql.Tx) error {
+		if target != nil {
+			q := fmt.Sprintf("DELETE FROM %q WHERE name = ?", metadataTableName)
+			if _, err := tx.ExecContext(ctx, q, newCollectionName); err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			q = fmt.Sprintf("DROP TABLE %q", target.TableName)
+			if _, err := tx.ExecContext(ctx, q); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		q := fmt.Sprintf(`UPDATE %q SET name = ? WHERE table_name = ?`, metadataTableName)
+		if _, err := tx.ExecContext(ctx, q, newCollectionName, c.TableName); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	c.Name = newCollectionName
+	r.colls[dbName][newCollectionName] = c
+	delete(r.colls[dbName], oldCollectionName)
+
+	return true, nil
+}
+
+// collectionRename renames a collection in the database.
+//
+// Returned boolean value indicates whether the collection was renamed.
+// If database or collection did not exist, (false, nil) is returned.
+//
+// It does not hold the lock.
+func (r *Registry) collectionRename(ctx context.Context, dbName, oldCollectionName, newCollectionName string) (bool, error) {
+	defer observability.FuncCall(ctx)()
+
+	db := r.DatabaseGetExisting(ctx, dbName)
+	if db == nil {
+		return false, nil
+	}
 
 	c := r.collectionGet(dbName, oldCollectionName)
 	if c == nil {
@@ -563,6 +630,7 @@ func (r *Registry) IndexesDrop(ctx context.Context, dbName, collectionName strin
 // indexesDrop removes given connection's indexes.
 //
 // Non-existing indexes are ignored.
+// Indexes are dropped in a single transaction: either all or none of them are dropped.
 //
 // If database or collection does not exist, nil is returned.
 //
@@ -580,22 +648,29 @@ func (r *Registry) indexesDrop(ctx context.Context, dbName, collectionName strin
 		return nil
 	}
 
-	for _, name := range indexNames {
-		i := slices.IndexFunc(c.Settings.Indexes, func(i IndexInfo) bool { return name == i.Name })
-		if i < 0 {
-			continue
+	err := db.InTransaction(ctx, func(tx *fsql.Tx) error {
+		for _, name := range indexNames {
+			i := slices.IndexFunc(c.Settings.Indexes, func(i IndexInfo) bool { return name == i.Name })
+			if i < 0 {
+				continue
+			}
+
+			q := fmt.Sprintf("DROP INDEX %q", c.TableName+"_"+name)
+			if _, err := tx.ExecContext(ctx, q); err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			c.Settings.Indexes = slices.Delete(c.Settings.Indexes, i, i+1)
 		}
 
-		q := fmt.Sprintf("DROP INDEX %q", c.TableName+"_"+name)
-		if _, err := db.ExecContext(ctx, q); err != nil {
+		q := fmt.Sprintf("UPDATE %q SET settings = ? WHERE table_name = ?", metadataTableName)
+		if _, err := tx.ExecContext(ctx, q, c.Settings, c.TableName); err != nil {
 			return lazyerrors.Error(err)
 		}
 
-		c.Settings.Indexes = slices.Delete(c.Settings.Indexes, i, i+1)
-	}
-
-	q := fmt.Sprintf("UPDATE %q SET settings = ? WHERE table_name = ?", metadataTableName)
-	if _, err := db.ExecContext(ctx, q, c.Settings, c.TableName); err != nil {
+		return nil
+	})
+	if err != nil {
 		return lazyerrors.Error(err)
 	}
 
@@ -459,3 +459,61 @@ func TestIndexesCreateDrop(t *testing.T) {
 		require.Equal(t, 1, len(collection.Settings.Indexes))
 	})
 }
+
+func TestCollectionReplace(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Ctx(t)
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	r, err := NewRegistry(testutil.TestSQLiteURI(t, ""), 100, testutil.Logger(t), sp)
+	require.NoError(t, err)
+	t.Cleanup(r.Close)
+
+	dbName := testutil.DatabaseName(t)
+
+	db, err := r.DatabaseGetOrCreate(ctx, dbName)
+	require.NoError(t, err)
+	require.NotNil(t, db)
+
+	for _, name := range []string{"old", "new"} {
+		created, err := r.CollectionCreate(ctx, &CollectionCreateParams{DBName: dbName, Name: name})
+		require.NoError(t, err)
+		require.True(t, created)
+	}
+
+	old := r.CollectionGet(ctx, dbName, "old")
+	newTable := r.CollectionGet(ctx, dbName, "new").TableName
+
+	replaced, err := r.CollectionReplace(ctx, dbName, "old", "new")
+	require.NoError(t, err)
+	require.True(t, replaced)
+
+	require.Nil(t, r.CollectionGet(ctx, dbName, "old"))
+
+	c := r.CollectionGet(ctx, dbName, "new")
+	require.NotNil(t, c)
+	require.Equal(t, old.TableName, c.TableName)
+
+	list, err := r.CollectionList(ctx, dbName)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	require.NoError(t, r.initCollections(ctx, dbName, db))
+
+	c = r.CollectionGet(ctx, dbName, "new")
+	require.NotNil(t, c)
+	require.Equal(t, old.TableName, c.TableName)
+	require.Nil(t, r.CollectionGet(ctx, dbName, "old"))
+
+	q := "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+
+	var count int
+	require.NoError(t, db.QueryRowContext(ctx, q, newTable).Scan(&count))
+	require.Equal(t, 0, count)
+
+	replaced, err = r.CollectionReplace(ctx, dbName, "old", "new")
+	require.NoError(t, err)
+	require.False(t, replaced)
+}
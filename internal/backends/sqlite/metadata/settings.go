@@ -36,6 +36,9 @@ type IndexInfo struct {
 	Name   string         `json:"name"`
 	Key    []IndexKeyPair `json:"key"`
 	Unique bool           `json:"unique"`
+
+	// Options contain sjson-encoded document returned by backends.IndexInfo.OptionsDocument.
+	Options json.RawMessage `json:"options,omitempty"`
 }
 
 // IndexKeyPair consists of a field name and a sort order that are part of the index.
@@ -50,9 +53,10 @@ func (s Settings) deepCopy() Settings {
 
 	for i, index := range s.Indexes {
 		indexes[i] = IndexInfo{
-			Name:   index.Name,
-			Key:    slices.Clone(index.Key),
-			Unique: index.Unique,
+			Name:    index.Name,
+			Key:     slices.Clone(index.Key),
+			Unique:  index.Unique,
+			Options: slices.Clone(index.Options),
 		}
 	}
 
@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/FerretDB/FerretDB/internal/backends/sqlite/metadata"
+	"github.com/FerretDB/FerretDB/internal/handler/sjson"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
@@ -46,6 +47,52 @@ func prepareSelectClause(table, comment string, capped, onlyRecordIDs bool) stri
 	return fmt.Sprintf(`SELECT %s %s FROM %q`, comment, metadata.DefaultColumn, table)
 }
 
+// prepareWhereClause returns WHERE clause with arguments for given filter.
+//
+// Only filters by a single _id string or ObjectID value, or by $in of such values, are pushed down;
+// for other filters, empty clause is returned.
+func prepareWhereClause(filter *types.Document) (string, []any) {
+	if filter.Len() != 1 {
+		return "", nil
+	}
+
+	v, _ := filter.Get("_id")
+
+	switch v := v.(type) {
+	case string, types.ObjectID:
+		return fmt.Sprintf(` WHERE %s = ?`, metadata.IDColumn), []any{string(must.NotFail(sjson.MarshalSingleValue(v)))}
+
+	case *types.Document:
+		if v.Len() != 1 {
+			return "", nil
+		}
+
+		arr, _ := v.Get("$in")
+
+		in, ok := arr.(*types.Array)
+		if !ok || in.Len() == 0 {
+			return "", nil
+		}
+
+		placeholders := make([]string, in.Len())
+		args := make([]any, in.Len())
+
+		for i := 0; i < in.Len(); i++ {
+			switch id := must.NotFail(in.Get(i)); id.(type) {
+			case string, types.ObjectID:
+				placeholders[i] = "?"
+				args[i] = string(must.NotFail(sjson.MarshalSingleValue(id)))
+			default:
+				return "", nil
+			}
+		}
+
+		return fmt.Sprintf(` WHERE %s IN (%s)`, metadata.IDColumn, strings.Join(placeholders, ", ")), args
+	}
+
+	return "", nil
+}
+
 // prepareOrderByClause returns ORDER BY clause for given sort document.
 //
 // The provided sort document should be already validated.
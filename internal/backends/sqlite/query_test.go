@@ -119,3 +119,58 @@ func TestPrepareOrderByClause(t *testing.T) {
 		})
 	}
 }
+
+func TestPrepareWhereClause(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:vet // used for test only
+		filter *types.Document
+		where  string
+		args   []any
+	}{
+		"Nil": {},
+		"ID": {
+			filter: must.NotFail(types.NewDocument("_id", "foo")),
+			where:  ` WHERE _ferretdb_sjson->'$._id' = ?`,
+			args:   []any{`"foo"`},
+		},
+		"IDInt": {
+			filter: must.NotFail(types.NewDocument("_id", int32(1))),
+		},
+		"IDIn": {
+			filter: must.NotFail(types.NewDocument("_id", must.NotFail(types.NewDocument(
+				"$in", must.NotFail(types.NewArray("foo", "bar")),
+			)))),
+			where: ` WHERE _ferretdb_sjson->'$._id' IN (?, ?)`,
+			args:  []any{`"foo"`, `"bar"`},
+		},
+		"IDInEmpty": {
+			filter: must.NotFail(types.NewDocument("_id", must.NotFail(types.NewDocument(
+				"$in", types.MakeArray(0),
+			)))),
+		},
+		"IDInMixed": {
+			filter: must.NotFail(types.NewDocument("_id", must.NotFail(types.NewDocument(
+				"$in", must.NotFail(types.NewArray("foo", int32(1))),
+			)))),
+		},
+		"IDInOtherOperator": {
+			filter: must.NotFail(types.NewDocument("_id", must.NotFail(types.NewDocument(
+				"$in", must.NotFail(types.NewArray("foo")),
+				"$ne", "bar",
+			)))),
+		},
+		"Other": {
+			filter: must.NotFail(types.NewDocument("_id", "foo", "v", "bar")),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			where, args := prepareWhereClause(tc.filter)
+			assert.Equal(t, tc.where, where)
+			assert.Equal(t, tc.args, args)
+		})
+	}
+}
@@ -34,6 +34,7 @@
 //	32-bit integer      int32
 //	Timestamp           bson.Timestamp
 //	64-bit integer      int64
+//	Decimal128          bson.Decimal128
 //
 // Composite types (Document and Array) are passed by pointers.
 // Raw composite type and scalars are passed by values.
@@ -72,6 +73,9 @@ type (
 
 	// Timestamp represents BSON scalar type timestamp.
 	Timestamp = bsonproto.Timestamp
+
+	// Decimal128 represents BSON scalar type decimal128.
+	Decimal128 = bsonproto.Decimal128
 )
 
 const (
@@ -197,6 +201,7 @@ func validBSONType(v any) error {
 	case int32:
 	case Timestamp:
 	case int64:
+	case Decimal128:
 
 	default:
 		return lazyerrors.Errorf("invalid BSON type %T", v)
@@ -287,6 +292,8 @@ func convertToTypes(v any) (any, error) {
 		return types.Timestamp(v), nil
 	case int64:
 		return v, nil
+	case Decimal128:
+		return types.Decimal128{H: v.H, L: v.L}, nil
 
 	default:
 		panic(fmt.Sprintf("invalid BSON type %T", v))
@@ -349,6 +356,8 @@ func convertFromTypes(v any) (any, error) {
 		return Timestamp(v), nil
 	case int64:
 		return v, nil
+	case types.Decimal128:
+		return Decimal128{H: v.H, L: v.L}, nil
 
 	default:
 		panic(fmt.Sprintf("invalid type %T", v))
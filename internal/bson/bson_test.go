@@ -703,6 +703,81 @@ func TestDecode(t *testing.T) {
 	}
 }
 
+func TestDecodeLazy(t *testing.T) {
+	for _, tc := range normalTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := tc.raw.Decode()
+			require.NoError(t, err)
+
+			deep, err := tc.raw.DecodeDeep()
+			require.NoError(t, err)
+
+			for _, name := range doc.FieldNames() {
+				switch v := doc.Get(name).(type) {
+				case bson.RawDocument, bson.RawArray:
+					assert.NotNil(t, v)
+				default:
+					assert.Equal(t, deep.Get(name), v, name)
+				}
+			}
+
+			expected, err := bson.ConvertDocument(tc.tdoc)
+			require.NoError(t, err)
+
+			require.NoError(t, expected.Add("lazy", int32(42)))
+			require.NoError(t, doc.Add("lazy", int32(42)))
+
+			expectedRaw, err := expected.Encode()
+			require.NoError(t, err)
+
+			raw, err := doc.Encode()
+			require.NoError(t, err)
+			assert.Equal(t, expectedRaw, raw)
+
+			name := doc.Command()
+			expected.Remove(name)
+			doc.Remove(name)
+
+			expectedRaw, err = expected.Encode()
+			require.NoError(t, err)
+
+			raw, err = doc.Encode()
+			require.NoError(t, err)
+			assert.Equal(t, expectedRaw, raw)
+		})
+	}
+}
+
+func TestRawDocumentGet(t *testing.T) {
+	for _, tc := range normalTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := tc.raw.Decode()
+			require.NoError(t, err)
+
+			for _, name := range doc.FieldNames() {
+				v, err := tc.raw.Get(name)
+				require.NoError(t, err)
+				assert.Equal(t, doc.Get(name), v, name)
+			}
+
+			v, err := tc.raw.Get("no-such-field")
+			require.NoError(t, err)
+			assert.Nil(t, v)
+		})
+	}
+
+	for _, tc := range decodeTestCases {
+		if tc.decodeErr == nil {
+			continue
+		}
+
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.raw.Get("no-such-field")
+			require.Error(t, err)
+		})
+	}
+}
+
 func BenchmarkDocument(b *testing.B) {
 	for _, tc := range normalTestCases {
 		b.Run(tc.name, func(b *testing.B) {
@@ -758,6 +833,23 @@ func BenchmarkDocument(b *testing.B) {
 					assert.NotEmpty(b, m)
 				})
 
+				b.Run("PassThrough", func(b *testing.B) {
+					b.ReportAllocs()
+
+					for range b.N {
+						doc, err = tc.raw.Decode()
+						if err == nil {
+							_ = doc.Get(doc.Command())
+							raw, err = doc.Encode()
+						}
+					}
+
+					b.StopTimer()
+
+					require.NoError(b, err)
+					assert.NotNil(b, raw)
+				})
+
 				b.Run("DecodeDeep", func(b *testing.B) {
 					b.ReportAllocs()
 
@@ -788,6 +880,23 @@ func BenchmarkDocument(b *testing.B) {
 					assert.NotNil(b, raw)
 				})
 
+				b.Run("PassThroughDeep", func(b *testing.B) {
+					b.ReportAllocs()
+
+					for range b.N {
+						doc, err = tc.raw.DecodeDeep()
+						if err == nil {
+							_ = doc.Get(doc.Command())
+							raw, err = doc.Encode()
+						}
+					}
+
+					b.StopTimer()
+
+					require.NoError(b, err)
+					assert.NotNil(b, raw)
+				})
+
 				b.Run("LogMessageDeep", func(b *testing.B) {
 					doc, err = tc.raw.DecodeDeep()
 					require.NoError(b, err)
@@ -15,6 +15,7 @@
 package bson
 
 import (
+	"bytes"
 	"encoding/binary"
 
 	"github.com/cristalhq/bson/bsonproto"
@@ -58,6 +59,108 @@ func decodeCheckOffset(b []byte, offset, size int) error {
 	return nil
 }
 
+// sizeScalarField returns the size of the scalar value with the given tag encoded at the start of b.
+//
+// It performs the same checks as decodeScalarField, but does not decode the value.
+// If it returns no error, decodeScalarField with the same b[:size] and t never fails.
+func sizeScalarField(b []byte, t tag) (int, error) {
+	var size int
+
+	switch t {
+	case tagFloat64:
+		size = bsonproto.SizeFloat64
+
+	case tagString:
+		if err := decodeCheckOffset(b, 0, 5); err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+
+		i := int(binary.LittleEndian.Uint32(b))
+		if i < 1 {
+			return 0, lazyerrors.Errorf("string length = %d: %w", i, ErrDecodeInvalidInput)
+		}
+
+		size = 4 + i
+		if err := decodeCheckOffset(b, 0, size); err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+
+		if b[size-1] != 0 {
+			return 0, lazyerrors.Errorf("invalid last string byte: %w", ErrDecodeInvalidInput)
+		}
+
+		return size, nil
+
+	case tagBinary:
+		if err := decodeCheckOffset(b, 0, 5); err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+
+		size = 5 + int(binary.LittleEndian.Uint32(b))
+
+	case tagObjectID:
+		size = bsonproto.SizeObjectID
+
+	case tagBool:
+		if err := decodeCheckOffset(b, 0, bsonproto.SizeBool); err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+
+		if b[0] > 1 {
+			return 0, lazyerrors.Errorf("invalid bool value 0x%02x: %w", b[0], ErrDecodeInvalidInput)
+		}
+
+		return bsonproto.SizeBool, nil
+
+	case tagTime:
+		size = bsonproto.SizeTime
+
+	case tagNull:
+		return 0, nil
+
+	case tagRegex:
+		p := bytes.IndexByte(b, 0)
+		if p == -1 {
+			return 0, lazyerrors.Errorf("no regex pattern end: %w", ErrDecodeShortInput)
+		}
+
+		o := bytes.IndexByte(b[p+1:], 0)
+		if o == -1 {
+			return 0, lazyerrors.Errorf("no regex options end: %w", ErrDecodeShortInput)
+		}
+
+		return p + 1 + o + 1, nil
+
+	case tagInt32:
+		size = bsonproto.SizeInt32
+
+	case tagTimestamp:
+		size = bsonproto.SizeTimestamp
+
+	case tagInt64:
+		size = bsonproto.SizeInt64
+
+	case tagDecimal128:
+		size = bsonproto.SizeDecimal128
+
+	case tagUndefined, tagDBPointer, tagJavaScript, tagSymbol, tagJavaScriptScope, tagMinKey, tagMaxKey:
+		return 0, lazyerrors.Errorf("unsupported tag %s: %w", t, ErrDecodeInvalidInput)
+
+	case tagDocument, tagArray:
+		return 0, lazyerrors.Errorf("non-scalar tag: %s", t)
+
+	default:
+		return 0, lazyerrors.Errorf("unexpected tag %s: %w", t, ErrDecodeInvalidInput)
+	}
+
+	if err := decodeCheckOffset(b, 0, size); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	return size, nil
+}
+
+// decodeScalarField decodes the scalar value with the given tag encoded at the start of b.
 func decodeScalarField(b []byte, t tag) (v any, size int, err error) {
 	switch t {
 	case tagFloat64:
@@ -111,7 +214,11 @@ func decodeScalarField(b []byte, t tag) (v any, size int, err error) {
 		v, err = bsonproto.DecodeInt64(b)
 		size = bsonproto.SizeInt64
 
-	case tagUndefined, tagDBPointer, tagJavaScript, tagSymbol, tagJavaScriptScope, tagDecimal128, tagMinKey, tagMaxKey:
+	case tagDecimal128:
+		v, err = bsonproto.DecodeDecimal128(b)
+		size = bsonproto.SizeDecimal128
+
+	case tagUndefined, tagDBPointer, tagJavaScript, tagSymbol, tagJavaScriptScope, tagMinKey, tagMaxKey:
 		err = lazyerrors.Errorf("unsupported tag %s: %w", t, ErrDecodeInvalidInput)
 
 	case tagDocument, tagArray:
@@ -31,14 +31,25 @@ import (
 type field struct {
 	value any
 	name  string
+
+	// offset of the encoded scalar value in the Document's src;
+	// used instead of value only if tag is not zero
+	offset int32
+	tag    tag
 }
 
 // Document represents a BSON document a.k.a object in the (partially) decoded form.
 //
+// Documents decoded by [RawDocument.Decode] are decoded lazily:
+// scalar field values are decoded on access,
+// and the original encoded form is reused by Encode until the document is modified.
+//
 // It may contain duplicate field names.
 type Document struct {
-	fields []field
-	frozen bool
+	src      RawDocument // the encoded form the document was lazily decoded from, or nil
+	fields   []field
+	modified bool // true if fields were modified after decoding
+	frozen   bool
 }
 
 // NewDocument creates a new Document from the given pairs of field names and values.
@@ -106,7 +117,7 @@ func (doc *Document) Convert() (*types.Document, error) {
 	pairs := make([]any, 0, len(doc.fields)*2)
 
 	for _, f := range doc.fields {
-		v, err := convertToTypes(f.value)
+		v, err := convertToTypes(doc.fieldValue(&f))
 		if err != nil {
 			return nil, lazyerrors.Error(err)
 		}
@@ -122,6 +133,27 @@ func (doc *Document) Convert() (*types.Document, error) {
 	return res, nil
 }
 
+// fieldValue returns the value of the given document field, decoding it if needed.
+func (doc *Document) fieldValue(f *field) any {
+	if f.tag == 0 {
+		return f.value
+	}
+
+	// src was checked by sizeScalarField
+	v, _, err := decodeScalarField(doc.src[f.offset:], f.tag)
+	must.NoError(err)
+
+	return v
+}
+
+// fieldRaw returns the encoded scalar value of the given lazily decoded document field.
+func (doc *Document) fieldRaw(f *field) []byte {
+	// src was checked by sizeScalarField
+	size := must.NotFail(sizeScalarField(doc.src[f.offset:], f.tag))
+
+	return doc.src[f.offset : int(f.offset)+size]
+}
+
 // Freeze prevents document from further field modifications.
 // Any methods that would modify document fields will panic.
 //
@@ -158,7 +190,7 @@ func (doc *Document) FieldNames() []string {
 func (doc *Document) Get(name string) any {
 	for _, f := range doc.fields {
 		if f.name == name {
-			return f.value
+			return doc.fieldValue(&f)
 		}
 	}
 
@@ -173,6 +205,7 @@ func (doc *Document) Add(name string, value any) error {
 
 	doc.checkFrozen()
 
+	doc.modified = true
 	doc.fields = append(doc.fields, field{
 		name:  name,
 		value: value,
@@ -186,6 +219,8 @@ func (doc *Document) Add(name string, value any) error {
 func (doc *Document) Remove(name string) {
 	doc.checkFrozen()
 
+	doc.modified = true
+
 	var found bool
 	doc.fields = slices.DeleteFunc(doc.fields, func(f field) bool {
 		if f.name != name {
@@ -213,7 +248,12 @@ func (doc *Document) Replace(name string, value any) error {
 
 	for i, f := range doc.fields {
 		if f.name == name {
-			doc.fields[i].value = value
+			doc.modified = true
+			doc.fields[i] = field{
+				name:  name,
+				value: value,
+			}
+
 			return nil
 		}
 	}
@@ -233,10 +273,17 @@ func (doc *Document) Command() string {
 
 // Encode encodes BSON document.
 //
+// If the document was decoded from RawDocument and not modified since then,
+// that RawDocument is returned without copying.
+//
 // TODO https://github.com/FerretDB/FerretDB/issues/3759
 // This method should accept a slice of bytes, not return it.
 // That would allow to avoid unnecessary allocations.
 func (doc *Document) Encode() (RawDocument, error) {
+	if doc.src != nil && !doc.modified {
+		return doc.src, nil
+	}
+
 	size := sizeAny(doc)
 	buf := bytes.NewBuffer(make([]byte, 0, size))
 
@@ -245,7 +292,14 @@ func (doc *Document) Encode() (RawDocument, error) {
 	}
 
 	for _, f := range doc.fields {
-		if err := encodeField(buf, f.name, f.value); err != nil {
+		var err error
+		if f.tag == 0 {
+			err = encodeField(buf, f.name, f.value)
+		} else {
+			err = encodeRawField(buf, f.name, f.tag, doc.fieldRaw(&f))
+		}
+
+		if err != nil {
 			return nil, lazyerrors.Error(err)
 		}
 	}
@@ -137,6 +137,8 @@ func encodeScalarField(buf *bytes.Buffer, name string, v any) error {
 		buf.WriteByte(byte(tagTimestamp))
 	case int64:
 		buf.WriteByte(byte(tagInt64))
+	case Decimal128:
+		buf.WriteByte(byte(tagDecimal128))
 	default:
 		panic(fmt.Sprintf("invalid BSON type %T", v))
 	}
@@ -157,3 +159,23 @@ func encodeScalarField(buf *bytes.Buffer, name string, v any) error {
 
 	return nil
 }
+
+// encodeRawField encodes document field with the given tag and encoded scalar value.
+func encodeRawField(buf *bytes.Buffer, name string, t tag, raw []byte) error {
+	if err := buf.WriteByte(byte(t)); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	b := make([]byte, SizeCString(name))
+	EncodeCString(b, name)
+
+	if _, err := buf.Write(b); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if _, err := buf.Write(raw); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
@@ -23,6 +23,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
 )
 
 // logMaxDepth is the maximum depth of a recursive representation of a BSON value.
@@ -59,7 +61,7 @@ func slogValue(v any, depth int) slog.Value {
 		var attrs []slog.Attr
 
 		for _, f := range v.fields {
-			attrs = append(attrs, slog.Attr{Key: f.name, Value: slogValue(f.value, depth+1)})
+			attrs = append(attrs, slog.Attr{Key: f.name, Value: slogValue(v.fieldValue(&f), depth+1)})
 		}
 
 		return slog.GroupValue(attrs...)
@@ -138,6 +140,9 @@ func slogValue(v any, depth int) slog.Value {
 	case int64:
 		return slog.Int64Value(v)
 
+	case Decimal128:
+		return slog.StringValue("Decimal128(" + types.Decimal128{H: v.H, L: v.L}.String() + ")")
+
 	default:
 		panic(fmt.Sprintf("invalid BSON type %T", v))
 	}
@@ -189,7 +194,7 @@ func logMessage(v any, maxFlowLength int, indent string, depth int) string {
 
 			for i, f := range v.fields {
 				res += strconv.Quote(f.name) + `: `
-				res += logMessage(f.value, maxFlowLength, "", depth+1)
+				res += logMessage(v.fieldValue(&f), maxFlowLength, "", depth+1)
 
 				if i != l-1 {
 					res += ", "
@@ -212,7 +217,7 @@ func logMessage(v any, maxFlowLength int, indent string, depth int) string {
 		for _, f := range v.fields {
 			res += indent + "  "
 			res += strconv.Quote(f.name) + `: `
-			res += logMessage(f.value, maxFlowLength, indent+"  ", depth+1) + ",\n"
+			res += logMessage(v.fieldValue(&f), maxFlowLength, indent+"  ", depth+1) + ",\n"
 		}
 
 		res += indent + `}`
@@ -324,6 +329,9 @@ func logMessage(v any, maxFlowLength int, indent string, depth int) string {
 	case int64:
 		return "int64(" + strconv.FormatInt(int64(v), 10) + ")"
 
+	case Decimal128:
+		return "Decimal128(" + types.Decimal128{H: v.H, L: v.L}.String() + ")"
+
 	default:
 		panic(fmt.Sprintf("invalid BSON type %T", v))
 	}
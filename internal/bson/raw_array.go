@@ -89,7 +89,7 @@ func (raw RawArray) decode(mode decodeMode) (*Array, error) {
 			return nil, lazyerrors.Errorf("invalid array index: %q", f.name)
 		}
 
-		res.elements[i] = f.value
+		res.elements[i] = doc.fieldValue(&f)
 	}
 
 	return res, nil
@@ -19,7 +19,6 @@ import (
 
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
-	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 // RawDocument represents a single BSON document a.k.a object in the binary encoded form.
@@ -37,6 +36,9 @@ func (raw RawDocument) Encode() (RawDocument, error) {
 // Only top-level fields are decoded;
 // nested documents and arrays are converted to RawDocument and RawArray respectively,
 // using raw's subslices without copying.
+// Scalar values are checked, but decoded only when accessed.
+// raw is reused by [*Document.Encode] until the document is modified,
+// so it should not be modified by the caller.
 func (raw RawDocument) Decode() (*Document, error) {
 	res, err := raw.decode(decodeShallow)
 	if err != nil {
@@ -73,6 +75,87 @@ func (raw RawDocument) Convert() (*types.Document, error) {
 	return res, nil
 }
 
+// Get returns the value of the first field with the given name, or nil if there is no such field.
+//
+// Only field names and sizes of preceding fields are read; nothing else is decoded.
+// Nested documents and arrays are returned as RawDocument and RawArray respectively,
+// using raw's subslices without copying.
+func (raw RawDocument) Get(name string) (any, error) {
+	l, err := FindRaw(raw)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if rl := len(raw); rl != l {
+		return nil, lazyerrors.Errorf("len(raw) = %d, l = %d: %w", rl, l, ErrDecodeInvalidInput)
+	}
+
+	offset := 4
+
+	for {
+		if err = decodeCheckOffset(raw, offset, 1); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		t := tag(raw[offset])
+		if t == 0 {
+			if rl := len(raw); rl != offset+1 {
+				return nil, lazyerrors.Errorf("len(raw) = %d, offset = %d, got %s: %w", rl, offset, t, ErrDecodeInvalidInput)
+			}
+
+			return nil, nil
+		}
+
+		offset++
+
+		if err = decodeCheckOffset(raw, offset, 1); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		var n string
+		if n, err = DecodeCString(raw[offset:]); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		offset += SizeCString(n)
+
+		if err = decodeCheckOffset(raw, offset, 0); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		var v any
+
+		switch t { //nolint:exhaustive // other tags are handled by sizeScalarField and decodeScalarField
+		case tagDocument:
+			if l, err = FindRaw(raw[offset:]); err == nil {
+				v = RawDocument(raw[offset : offset+l])
+			}
+
+		case tagArray:
+			if l, err = FindRaw(raw[offset:]); err == nil {
+				v = RawArray(raw[offset : offset+l])
+			}
+
+		default:
+			if n == name {
+				v, l, err = decodeScalarField(raw[offset:], t)
+			} else {
+				l, err = sizeScalarField(raw[offset:], t)
+			}
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if n == name {
+			return v, nil
+		}
+
+		offset += l
+	}
+}
+
 // decode decodes a single BSON document that takes the whole byte slice.
 func (raw RawDocument) decode(mode decodeMode) (*Document, error) {
 	l, err := FindRaw(raw)
@@ -99,6 +182,10 @@ func (raw RawDocument) decode(mode decodeMode) (*Document, error) {
 				return nil, lazyerrors.Errorf("len(raw) = %d, offset = %d, got %s: %w", rl, offset, t, ErrDecodeInvalidInput)
 			}
 
+			if mode == decodeShallow {
+				res.src = raw
+			}
+
 			return res, nil
 		}
 
@@ -115,7 +202,7 @@ func (raw RawDocument) decode(mode decodeMode) (*Document, error) {
 
 		offset += SizeCString(name)
 
-		var v any
+		f := field{name: name}
 
 		// to check if we can even `raw[offset:]` below
 		if err = decodeCheckOffset(raw, offset, 0); err != nil {
@@ -133,9 +220,9 @@ func (raw RawDocument) decode(mode decodeMode) (*Document, error) {
 
 			switch mode {
 			case decodeShallow:
-				v = rawDoc
+				f.value = rawDoc
 			case decodeDeep:
-				v, err = rawDoc.decode(decodeDeep)
+				f.value, err = rawDoc.decode(decodeDeep)
 			}
 
 		case tagArray:
@@ -148,13 +235,21 @@ func (raw RawDocument) decode(mode decodeMode) (*Document, error) {
 
 			switch mode {
 			case decodeShallow:
-				v = rawArr
+				f.value = rawArr
 			case decodeDeep:
-				v, err = rawArr.decode(decodeDeep)
+				f.value, err = rawArr.decode(decodeDeep)
 			}
 
 		default:
-			v, l, err = decodeScalarField(raw[offset:], t)
+			switch mode {
+			case decodeShallow:
+				if l, err = sizeScalarField(raw[offset:], t); err == nil {
+					f.offset, f.tag = int32(offset), t
+				}
+			case decodeDeep:
+				f.value, l, err = decodeScalarField(raw[offset:], t)
+			}
+
 			offset += l
 		}
 
@@ -162,7 +257,7 @@ func (raw RawDocument) decode(mode decodeMode) (*Document, error) {
 			return nil, lazyerrors.Error(err)
 		}
 
-		must.NoError(res.Add(name, v))
+		res.fields = append(res.fields, f)
 	}
 }
 
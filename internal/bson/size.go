@@ -40,10 +40,20 @@ func sizeAny(v any) int {
 
 // sizeDocument returns a size of the encoding of Document doc in bytes.
 func sizeDocument(doc *Document) int {
+	if doc.src != nil && !doc.modified {
+		return len(doc.src)
+	}
+
 	size := 5
 
 	for _, f := range doc.fields {
-		size += 1 + len(f.name) + 1 + sizeAny(f.value)
+		size += 1 + len(f.name) + 1
+
+		if f.tag == 0 {
+			size += sizeAny(f.value)
+		} else {
+			size += len(doc.fieldRaw(&f))
+		}
 	}
 
 	return size
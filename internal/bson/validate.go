@@ -0,0 +1,249 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bson
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ValidationErrorCode represents the kind of [ValidationError].
+type ValidationErrorCode int
+
+const (
+	_ ValidationErrorCode = iota
+
+	// ValidationErrorInvalidBSON indicates that the document structure is invalid.
+	ValidationErrorInvalidBSON
+
+	// ValidationErrorDepth indicates that documents and arrays are nested too deeply.
+	ValidationErrorDepth
+
+	// ValidationErrorNonConformant indicates that the document is structurally valid,
+	// but contains invalid UTF-8 strings or duplicate field names.
+	ValidationErrorNonConformant
+
+	// ValidationErrorUnsupported indicates that the document contains a valid, but unsupported BSON type.
+	ValidationErrorUnsupported
+)
+
+// ValidationError is returned by [RawDocument.Validate] for invalid documents.
+//
+// Its message is compatible with MongoDB.
+type ValidationError struct {
+	code ValidationErrorCode
+	msg  string
+}
+
+// newValidationError returns a new ValidationError with the given code and formatted message.
+func newValidationError(code ValidationErrorCode, format string, args ...any) *ValidationError {
+	return &ValidationError{
+		code: code,
+		msg:  fmt.Sprintf(format, args...),
+	}
+}
+
+// Error implements error interface.
+func (e *ValidationError) Error() string {
+	return e.msg
+}
+
+// Code returns the kind of the validation error.
+func (e *ValidationError) Code() ValidationErrorCode {
+	return e.code
+}
+
+// ValidateOpts represents options for [RawDocument.Validate].
+type ValidateOpts struct {
+	// Maximum nesting depth of documents and arrays, including the top-level document; zero means no limit.
+	MaxDepth int
+
+	// If true, field names, strings, and regular expressions are checked to be valid UTF-8.
+	UTF8 bool
+
+	// If true, documents (but not arrays) are checked to not contain duplicate field names.
+	DuplicateFields bool
+}
+
+// Validate checks that raw is a single valid BSON document that takes the whole byte slice.
+//
+// Unlike [RawDocument.DecodeDeep], it does not allocate decoded values,
+// checks all nested documents and arrays, and returns [*ValidationError] for the first problem found.
+// Structural checks are always performed; other checks are controlled by opts.
+func (raw RawDocument) Validate(opts *ValidateOpts) error {
+	if opts == nil {
+		opts = new(ValidateOpts)
+	}
+
+	l, err := validateDocument(raw, false, opts, 1)
+	if err != nil {
+		return err
+	}
+
+	if l != len(raw) {
+		return newValidationError(ValidationErrorInvalidBSON, "BSON size %d does not match buffer size %d", l, len(raw))
+	}
+
+	return nil
+}
+
+// validateDocument validates a document or array encoded at the start of b and returns its size.
+func validateDocument(b []byte, array bool, opts *ValidateOpts, depth int) (int, error) {
+	if opts.MaxDepth > 0 && depth > opts.MaxDepth {
+		return 0, newValidationError(
+			ValidationErrorDepth,
+			"BSONObj exceeded maximum nested object depth: %d", opts.MaxDepth,
+		)
+	}
+
+	if len(b) < 5 {
+		return 0, newValidationError(ValidationErrorInvalidBSON, "BSON size is larger than buffer size")
+	}
+
+	l := int(int32(binary.LittleEndian.Uint32(b)))
+	if l < 5 {
+		return 0, newValidationError(ValidationErrorInvalidBSON, "Invalid BSON size: %d", l)
+	}
+
+	if l > len(b) {
+		return 0, newValidationError(ValidationErrorInvalidBSON, "BSON size is larger than buffer size")
+	}
+
+	if b[l-1] != 0 {
+		return 0, newValidationError(ValidationErrorInvalidBSON, "BSON object not terminated with EOO")
+	}
+
+	// the last byte is EOO; fields must not overlap it
+	b = b[:l-1]
+
+	var fields map[string]struct{}
+	if opts.DuplicateFields && !array {
+		fields = map[string]struct{}{}
+	}
+
+	offset := 4
+
+	for offset < len(b) {
+		t := tag(b[offset])
+		offset++
+
+		if t == 0 {
+			return 0, newValidationError(ValidationErrorInvalidBSON, "BSON object not terminated with EOO")
+		}
+
+		// field names can't contain null bytes as they are terminated by one
+		p := bytes.IndexByte(b[offset:], 0)
+		if p == -1 {
+			return 0, newValidationError(ValidationErrorInvalidBSON, "Not null terminated string")
+		}
+
+		name := b[offset : offset+p]
+		offset += p + 1
+
+		if opts.UTF8 && !utf8.Valid(name) {
+			return 0, newValidationError(ValidationErrorNonConformant, "Found string that doesn't follow UTF-8 encoding.")
+		}
+
+		if fields != nil {
+			if _, ok := fields[string(name)]; ok {
+				return 0, newValidationError(
+					ValidationErrorNonConformant,
+					"A BSON document contains a duplicate field name : %s", name,
+				)
+			}
+
+			fields[string(name)] = struct{}{}
+		}
+
+		size, err := validateValue(b[offset:], t, opts, depth)
+		if err != nil {
+			return 0, err
+		}
+
+		offset += size
+	}
+
+	return l, nil
+}
+
+// validateValue validates the value with the given tag encoded at the start of b and returns its size.
+func validateValue(b []byte, t tag, opts *ValidateOpts, depth int) (int, error) {
+	switch t {
+	case tagDocument, tagArray:
+		return validateDocument(b, t == tagArray, opts, depth+1)
+
+	case tagString:
+		if len(b) < 4 {
+			return 0, newValidationError(ValidationErrorInvalidBSON, "BSON size is larger than buffer size")
+		}
+
+		l := int(int32(binary.LittleEndian.Uint32(b)))
+		if l < 1 || len(b) < 4+l {
+			return 0, newValidationError(ValidationErrorInvalidBSON, "Invalid string length: %d", l)
+		}
+
+		if b[4+l-1] != 0 {
+			return 0, newValidationError(ValidationErrorInvalidBSON, "Not null terminated string")
+		}
+
+		if opts.UTF8 && !utf8.Valid(b[4:4+l-1]) {
+			return 0, newValidationError(ValidationErrorNonConformant, "Found string that doesn't follow UTF-8 encoding.")
+		}
+
+		return 4 + l, nil
+
+	case tagRegex:
+		p := bytes.IndexByte(b, 0)
+		if p == -1 {
+			return 0, newValidationError(ValidationErrorInvalidBSON, "Not null terminated string")
+		}
+
+		o := bytes.IndexByte(b[p+1:], 0)
+		if o == -1 {
+			return 0, newValidationError(ValidationErrorInvalidBSON, "Not null terminated string")
+		}
+
+		if opts.UTF8 && !utf8.Valid(b[:p+1+o]) {
+			return 0, newValidationError(ValidationErrorNonConformant, "Found string that doesn't follow UTF-8 encoding.")
+		}
+
+		return p + 1 + o + 1, nil
+
+	case tagFloat64, tagBinary, tagObjectID, tagBool, tagTime, tagNull, tagInt32, tagTimestamp, tagInt64, tagDecimal128:
+		size, err := sizeScalarField(b, t)
+		if err != nil {
+			if t == tagBool && len(b) > 0 {
+				return 0, newValidationError(ValidationErrorInvalidBSON, "Invalid boolean value: %d", b[0])
+			}
+
+			return 0, newValidationError(ValidationErrorInvalidBSON, "BSON size is larger than buffer size")
+		}
+
+		return size, nil
+
+	case tagUndefined, tagDBPointer, tagJavaScript, tagSymbol, tagJavaScriptScope, tagMinKey, tagMaxKey:
+		return 0, newValidationError(ValidationErrorUnsupported, "BSON type %s is not supported", t)
+
+	default:
+		return 0, newValidationError(ValidationErrorInvalidBSON, "Unrecognized BSON type: %d", byte(t))
+	}
+}
+
+// check interfaces
+var (
+	_ error = (*ValidationError)(nil)
+)
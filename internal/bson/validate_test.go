@@ -0,0 +1,184 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bson_test // to avoid import cycle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	basic := &bson.ValidateOpts{MaxDepth: 200}
+	strict := &bson.ValidateOpts{MaxDepth: 200, UTF8: true, DuplicateFields: true}
+
+	t.Run("Normal", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tc := range normalTestCases {
+			assert.NoError(t, tc.raw.Validate(basic), tc.name)
+		}
+	})
+
+	t.Run("Decode", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tc := range decodeTestCases {
+			var ve *bson.ValidationError
+			assert.ErrorAs(t, tc.raw.Validate(nil), &ve, tc.name)
+		}
+	})
+
+	// nested returns a document with the given number of nesting levels
+	nested := func(levels int) bson.RawDocument {
+		doc := must.NotFail(bson.NewDocument("v", int32(42)))
+		for range levels - 1 {
+			doc = must.NotFail(bson.NewDocument("v", doc))
+		}
+
+		return must.NotFail(doc.Encode())
+	}
+
+	for name, tc := range map[string]struct {
+		raw  bson.RawDocument
+		opts *bson.ValidateOpts
+
+		code bson.ValidationErrorCode // zero if no error is expected
+		msg  string
+	}{
+		"MaxDepth": {
+			raw:  nested(200),
+			opts: strict,
+		},
+		"MaxDepthExceeded": {
+			raw:  nested(201),
+			opts: strict,
+			code: bson.ValidationErrorDepth,
+			msg:  "BSONObj exceeded maximum nested object depth: 200",
+		},
+		"NoMaxDepth": {
+			raw:  nested(201),
+			opts: &bson.ValidateOpts{},
+		},
+		"DuplicateFields": {
+			raw:  must.NotFail(must.NotFail(bson.NewDocument("foo", int32(1), "foo", int32(2))).Encode()),
+			opts: strict,
+			code: bson.ValidationErrorNonConformant,
+			msg:  "A BSON document contains a duplicate field name : foo",
+		},
+		"DuplicateFieldsBasic": {
+			raw:  must.NotFail(must.NotFail(bson.NewDocument("foo", int32(1), "foo", int32(2))).Encode()),
+			opts: basic,
+		},
+		"InvalidUTF8Value": {
+			raw:  must.NotFail(must.NotFail(bson.NewDocument("foo", "\xff")).Encode()),
+			opts: strict,
+			code: bson.ValidationErrorNonConformant,
+			msg:  "Found string that doesn't follow UTF-8 encoding.",
+		},
+		"InvalidUTF8Name": {
+			raw:  must.NotFail(must.NotFail(bson.NewDocument("\xfe", "foo")).Encode()),
+			opts: strict,
+			code: bson.ValidationErrorNonConformant,
+			msg:  "Found string that doesn't follow UTF-8 encoding.",
+		},
+		"InvalidUTF8Basic": {
+			raw:  must.NotFail(must.NotFail(bson.NewDocument("foo", "\xff")).Encode()),
+			opts: basic,
+		},
+		"SubdocumentTooLong": {
+			raw: bson.RawDocument{
+				0x0f, 0x00, 0x00, 0x00, // document length
+				0x03, 0x66, 0x6f, 0x6f, 0x00, // subdocument "foo"
+				0x07, 0x00, 0x00, 0x00, // invalid subdocument length
+				0x00, // end of subdocument
+				0x00, // end of document
+			},
+			code: bson.ValidationErrorInvalidBSON,
+			msg:  "BSON size is larger than buffer size",
+		},
+		"SubdocumentTooShort": {
+			raw: bson.RawDocument{
+				0x10, 0x00, 0x00, 0x00, // document length
+				0x03, 0x66, 0x6f, 0x6f, 0x00, // subdocument "foo"
+				0x05, 0x00, 0x00, 0x00, // invalid subdocument length
+				0x00, // end of subdocument
+				0x00, // extra byte
+				0x00, // end of document
+			},
+			code: bson.ValidationErrorInvalidBSON,
+			msg:  "BSON object not terminated with EOO",
+		},
+		"FieldNameNotTerminated": {
+			raw: bson.RawDocument{
+				0x09, 0x00, 0x00, 0x00, // document length
+				0x10, 0x66, 0x6f, 0x6f, // int32 "foo" without terminator
+				0x00, // end of document
+			},
+			code: bson.ValidationErrorInvalidBSON,
+			msg:  "Not null terminated string",
+		},
+		"InvalidStringLength": {
+			raw: bson.RawDocument{
+				0x0e, 0x00, 0x00, 0x00, // document length
+				0x02, 0x66, 0x00, // string "f"
+				0x10, 0x00, 0x00, 0x00, // invalid string length
+				0x61, 0x00, // "a"
+				0x00, // end of document
+			},
+			code: bson.ValidationErrorInvalidBSON,
+			msg:  "Invalid string length: 16",
+		},
+		"UnrecognizedType": {
+			raw: bson.RawDocument{
+				0x08, 0x00, 0x00, 0x00, // document length
+				0x42, 0x66, 0x00, // unknown type with name "f"
+				0x00, // end of document
+			},
+			code: bson.ValidationErrorInvalidBSON,
+			msg:  "Unrecognized BSON type: 66",
+		},
+		"UnsupportedType": {
+			raw: bson.RawDocument{
+				0x08, 0x00, 0x00, 0x00, // document length
+				0xff, 0x66, 0x00, // MinKey "f"
+				0x00, // end of document
+			},
+			code: bson.ValidationErrorUnsupported,
+			msg:  "BSON type MinKey is not supported",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.raw.Validate(tc.opts)
+			if tc.code == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var ve *bson.ValidationError
+			require.ErrorAs(t, err, &ve)
+			assert.Equal(t, tc.code, ve.Code())
+			assert.Equal(t, tc.msg, ve.Error())
+		})
+	}
+}
@@ -17,9 +17,11 @@ package clientconn
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -32,6 +34,10 @@ import (
 	"time"
 
 	"github.com/pmezard/go-difflib/difflib"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	otelsemconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
@@ -75,21 +81,29 @@ var AllModes = []string{
 type conn struct {
 	netConn        net.Conn
 	mode           Mode
+	validation     wire.ValidationLevel
 	l              *zap.SugaredLogger
 	h              *handler.Handler
 	m              *connmetrics.ConnMetrics
 	proxy          *proxy.Router
 	lastRequestID  atomic.Int32
 	testRecordsDir string // if empty, no records are created
+
+	id       string
+	started  time.Time
+	connInfo atomic.Pointer[conninfo.ConnInfo] // set by run
+	op       atomic.Pointer[OpState]           // in-flight operation, if any
 }
 
 // newConnOpts represents newConn options.
 type newConnOpts struct {
 	netConn     net.Conn
 	mode        Mode
+	validation  wire.ValidationLevel
 	l           *zap.Logger
 	handler     *handler.Handler
 	connMetrics *connmetrics.ConnMetrics
+	id          string
 
 	proxyAddr        string
 	proxyTLSCertFile string
@@ -104,6 +118,9 @@ func newConn(opts *newConnOpts) (*conn, error) {
 	if opts.mode == "" {
 		panic("mode required")
 	}
+	if opts.validation == "" {
+		panic("validation level required")
+	}
 	if opts.handler == nil {
 		panic("handler required")
 	}
@@ -119,11 +136,14 @@ func newConn(opts *newConnOpts) (*conn, error) {
 	return &conn{
 		netConn:        opts.netConn,
 		mode:           opts.mode,
+		validation:     opts.validation,
 		l:              opts.l.Sugar(),
 		h:              opts.handler,
 		m:              opts.connMetrics,
 		proxy:          p,
 		testRecordsDir: opts.testRecordsDir,
+		id:             opts.id,
+		started:        time.Now(),
 	}, nil
 }
 
@@ -140,7 +160,9 @@ func (c *conn) run(ctx context.Context) (err error) {
 	}()
 
 	connInfo := conninfo.New()
-	if c.netConn.RemoteAddr().Network() != "unix" {
+	connInfo.Local = c.netConn.LocalAddr().String()
+
+	if network := c.netConn.RemoteAddr().Network(); network != "unix" && network != "memory" {
 		connInfo.Peer, err = netip.ParseAddrPort(c.netConn.RemoteAddr().String())
 		if err != nil {
 			return
@@ -148,6 +170,7 @@ func (c *conn) run(ctx context.Context) (err error) {
 	}
 
 	ctx = conninfo.Ctx(ctx, connInfo)
+	c.connInfo.Store(connInfo)
 
 	done := make(chan struct{})
 
@@ -230,6 +253,10 @@ func (c *conn) run(ctx context.Context) (err error) {
 
 	bufw := bufio.NewWriter(c.netConn)
 
+	// request messages (and their raw documents) are valid only until the next message is read
+	reader := wire.NewReader(bufr, c.validation)
+	defer reader.Close()
+
 	defer func() {
 		if e := bufw.Flush(); err == nil {
 			err = e
@@ -249,7 +276,7 @@ func (c *conn) run(ctx context.Context) (err error) {
 		var resBody wire.MsgBody
 		var validationErr *wire.ValidationError
 
-		reqHeader, reqBody, err = wire.ReadMessage(bufr)
+		reqHeader, reqBody, err = reader.Read()
 		if err != nil && errors.As(err, &validationErr) {
 			// Currently, we respond with OP_MSG containing an error and don't close the connection.
 			// That's probably not right. First, we always respond with OP_MSG, even to OP_QUERY.
@@ -344,11 +371,11 @@ func (c *conn) run(ctx context.Context) (err error) {
 			var resBodyString, proxyBodyString string
 
 			if resBody != nil {
-				resBodyString = resBody.StringBlock()
+				resBodyString = diffBodyString(resBody)
 			}
 
 			if proxyBody != nil {
-				proxyBodyString = proxyBody.StringBlock()
+				proxyBodyString = diffBodyString(proxyBody)
 			}
 
 			var diffBody string
@@ -400,8 +427,19 @@ func (c *conn) run(ctx context.Context) (err error) {
 //
 // Returned resBody can be nil.
 func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wire.MsgBody) (resHeader *wire.MsgHeader, resBody wire.MsgBody, closeConn bool) { //nolint:lll // argument list is too long
-	var command, result, argument string
+	ctx, span := otel.Tracer("").Start(
+		traceContext(ctx, reqBody),
+		reqHeader.OpCode.String(),
+		oteltrace.WithSpanKind(oteltrace.SpanKindServer),
+		oteltrace.WithAttributes(otelsemconv.DBSystemMongoDB),
+	)
+
+	start := time.Now()
+
+	var command, result, argument, db, collection string
 	defer func() {
+		c.m.Namespaces.Record(db, collection, command, time.Since(start))
+
 		if result == "" {
 			result = "panic"
 		}
@@ -411,6 +449,19 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 		}
 
 		c.m.Responses.WithLabelValues(resHeader.OpCode.String(), command, argument, result).Inc()
+
+		if command != "" && command != "unknown" {
+			span.SetName(command)
+			span.SetAttributes(otelsemconv.DBOperationKey.String(command))
+		}
+
+		if result == "ok" {
+			span.SetStatus(otelcodes.Ok, "")
+		} else {
+			span.SetStatus(otelcodes.Error, result)
+		}
+
+		span.End()
 	}()
 
 	resHeader = new(wire.MsgHeader)
@@ -419,17 +470,39 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 	case wire.OpCodeMsg:
 		var document *types.Document
 		msg := reqBody.(*wire.OpMsg)
+
+		_, decodeSpan := otel.Tracer("").Start(ctx, "decode")
 		document, err = msg.Document()
+		decodeSpan.End()
 
 		command = document.Command()
 
+		db, collection = namespace(document)
+
+		c.op.Store(&OpState{
+			Conn:       c.id,
+			Command:    command,
+			DB:         db,
+			Collection: collection,
+			Started:    start,
+		})
+		defer c.op.Store(nil)
+
+		if db != "" {
+			span.SetAttributes(otelsemconv.DBNameKey.String(db))
+		}
+
+		if collection != "" {
+			span.SetAttributes(otelsemconv.DBMongoDBCollectionKey.String(collection))
+		}
+
 		resHeader.OpCode = wire.OpCodeMsg
 
 		if err == nil {
 			// do not store typed nil in interface, it makes it non-nil
 
 			var resMsg *wire.OpMsg
-			resMsg, err = c.handleOpMsg(ctx, msg, command)
+			resMsg, err = c.handleOpMsg(ctx, msg, command, db, collection)
 
 			if resMsg != nil {
 				resBody = resMsg
@@ -562,14 +635,28 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 
 // handleOpMsg processes OP_MSG request.
 //
+// Command, database, and collection names (if not empty) are set as pprof labels
+// for the handler goroutine and all goroutines started by it,
+// so CPU profiles show which workloads consume CPU.
+//
 // The passed context is canceled when the client disconnects.
-func (c *conn) handleOpMsg(ctx context.Context, msg *wire.OpMsg, command string) (*wire.OpMsg, error) {
+func (c *conn) handleOpMsg(ctx context.Context, msg *wire.OpMsg, command, db, collection string) (*wire.OpMsg, error) {
 	if cmd, ok := c.h.Commands()[command]; ok {
 		if cmd.Handler != nil {
 			defer observability.FuncCall(ctx)()
 
+			labels := []string{"command", command}
+
+			if db != "" {
+				labels = append(labels, "db", db)
+			}
+
+			if collection != "" {
+				labels = append(labels, "collection", collection)
+			}
+
 			defer pprof.SetGoroutineLabels(ctx)
-			ctx = pprof.WithLabels(ctx, pprof.Labels("command", command))
+			ctx = pprof.WithLabels(ctx, pprof.Labels(labels...))
 			pprof.SetGoroutineLabels(ctx)
 
 			return cmd.Handler(ctx, msg)
@@ -617,3 +704,59 @@ func (c *conn) logResponse(who string, resHeader *wire.MsgHeader, resBody wire.M
 
 	return level
 }
+
+// namespace returns database and collection names of the command, if any.
+//
+// For most commands, the value of the first field is a collection name;
+// getMore is an exception.
+func namespace(document *types.Document) (db, collection string) {
+	if v, _ := document.Get("$db"); v != nil {
+		db, _ = v.(string)
+	}
+
+	field := document.Command()
+	if field == "getMore" {
+		field = "collection"
+	}
+
+	if v, _ := document.Get(field); v != nil {
+		collection, _ = v.(string)
+	}
+
+	return
+}
+
+// diffBodyString returns a normalized string representation of the message body for diff modes.
+//
+// Response documents are represented as indented canonical Extended JSON,
+// so type differences (like int32 vs int64) are visible in the diff.
+// Other bodies use the logging representation.
+func diffBodyString(body wire.MsgBody) string {
+	var doc *types.Document
+	var err error
+
+	switch body := body.(type) {
+	case *wire.OpMsg:
+		doc, err = body.Document()
+	case *wire.OpReply:
+		doc, err = body.Document()
+	default:
+		return body.StringBlock()
+	}
+
+	if err != nil {
+		return body.StringBlock()
+	}
+
+	b, err := types.MarshalExtJSON(doc, true)
+	if err != nil {
+		return body.StringBlock()
+	}
+
+	var buf bytes.Buffer
+	if err = json.Indent(&buf, b, "", "  "); err != nil {
+		return body.StringBlock()
+	}
+
+	return buf.String() + "\n"
+}
@@ -0,0 +1,244 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite"
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/handler"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/state"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// maxFuzzAlloc is the maximum number of bytes that could be allocated while handling a single fuzz input.
+//
+// A message header could make us allocate up to wire.MaxMsgLen bytes for the message body,
+// and OP_COMPRESSED message could make us allocate up to wire.MaxMsgLen bytes for the uncompressed body;
+// everything else should be much smaller.
+const maxFuzzAlloc = 4 * wire.MaxMsgLen
+
+// fuzzMessage returns OP_MSG message (header and body) with the given command document.
+func fuzzMessage(doc *types.Document) []byte {
+	var msg wire.OpMsg
+	must.NoError(msg.SetSections(wire.MakeOpMsgSection(doc)))
+
+	body := must.NotFail(msg.MarshalBinary())
+
+	header := must.NotFail((&wire.MsgHeader{
+		MessageLength: int32(wire.MsgHeaderLen + len(body)),
+		RequestID:     1,
+		OpCode:        wire.OpCodeMsg,
+	}).MarshalBinary())
+
+	return append(header, body...)
+}
+
+// fuzzCompressedMessage returns OP_COMPRESSED message with zlib-compressed given message.
+func fuzzCompressedMessage(b []byte) []byte {
+	var buf bytes.Buffer
+
+	w := zlib.NewWriter(&buf)
+	must.NotFail(w.Write(b[wire.MsgHeaderLen:]))
+	must.NoError(w.Close())
+
+	res := bytes.Clone(b[:wire.MsgHeaderLen])
+	res = binary.LittleEndian.AppendUint32(res, binary.LittleEndian.Uint32(b[12:16]))
+	res = binary.LittleEndian.AppendUint32(res, uint32(len(b)-wire.MsgHeaderLen))
+	res = append(res, byte(wire.CompressorZlib))
+	res = append(res, buf.Bytes()...)
+
+	binary.LittleEndian.PutUint32(res[0:4], uint32(len(res)))
+	binary.LittleEndian.PutUint32(res[12:16], uint32(wire.OpCodeCompressed))
+
+	return res
+}
+
+// FuzzRoute feeds arbitrary byte streams into the wire decoder and command dispatcher
+// with the in-memory SQLite backend.
+//
+// It checks that there are no panics, that every decoded message gets a valid response,
+// and that the memory usage is bounded.
+func FuzzRoute(f *testing.F) {
+	sp, err := state.NewProvider("")
+	require.NoError(f, err)
+
+	// test logger can't be used inside the fuzz function
+	l := zap.NewNop()
+
+	b, err := sqlite.NewBackend(&sqlite.NewBackendParams{
+		URI:       "file:./?mode=memory",
+		L:         l,
+		P:         sp,
+		BatchSize: 100,
+	})
+	require.NoError(f, err)
+	f.Cleanup(b.Close)
+
+	h, err := handler.New(&handler.NewOpts{
+		Backend:       b,
+		L:             l,
+		ConnMetrics:   connmetrics.NewListenerMetrics().ConnMetrics,
+		StateProvider: sp,
+		BatchSize:     100,
+	})
+	require.NoError(f, err)
+	f.Cleanup(h.Close)
+
+	c, err := newConn(&newConnOpts{
+		mode:        NormalMode,
+		validation:  wire.ValidationBasic,
+		l:           l,
+		handler:     h,
+		connMetrics: connmetrics.NewListenerMetrics().ConnMetrics,
+	})
+	require.NoError(f, err)
+
+	for _, doc := range []*types.Document{
+		must.NotFail(types.NewDocument("hello", int32(1), "$db", "admin")),
+		must.NotFail(types.NewDocument("buildInfo", int32(1), "$db", "admin")),
+		must.NotFail(types.NewDocument(
+			"insert", "test",
+			"documents", must.NotFail(types.NewArray(must.NotFail(types.NewDocument("_id", int32(1), "v", "foo")))),
+			"$db", "fuzz",
+		)),
+		must.NotFail(types.NewDocument(
+			"find", "test",
+			"filter", must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$gt", int32(0))))),
+			"sort", must.NotFail(types.NewDocument("v", int32(-1))),
+			"$db", "fuzz",
+		)),
+		must.NotFail(types.NewDocument(
+			"update", "test",
+			"updates", must.NotFail(types.NewArray(must.NotFail(types.NewDocument(
+				"q", must.NotFail(types.NewDocument("_id", int32(1))),
+				"u", must.NotFail(types.NewDocument("$inc", must.NotFail(types.NewDocument("v", int32(1))))),
+			)))),
+			"$db", "fuzz",
+		)),
+		must.NotFail(types.NewDocument(
+			"aggregate", "test",
+			"pipeline", must.NotFail(types.NewArray(must.NotFail(types.NewDocument(
+				"$group", must.NotFail(types.NewDocument("_id", "$v", "count", must.NotFail(types.NewDocument("$sum", int32(1))))),
+			)))),
+			"cursor", must.NotFail(types.NewDocument()),
+			"$db", "fuzz",
+		)),
+		must.NotFail(types.NewDocument(
+			"delete", "test",
+			"deletes", must.NotFail(types.NewArray(must.NotFail(types.NewDocument(
+				"q", must.NotFail(types.NewDocument()),
+				"limit", int32(0),
+			)))),
+			"$db", "fuzz",
+		)),
+		must.NotFail(types.NewDocument("dropDatabase", int32(1), "$db", "fuzz")),
+	} {
+		msg := fuzzMessage(doc)
+		f.Add(msg)
+		f.Add(fuzzCompressedMessage(msg))
+	}
+
+	if !testing.Short() {
+		records, err := wire.LoadRecords(testutil.TmpRecordsDir, 100)
+		require.NoError(f, err)
+
+		for _, rec := range records {
+			if rec.HeaderB == nil || rec.BodyB == nil {
+				continue
+			}
+
+			f.Add(append(bytes.Clone(rec.HeaderB), rec.BodyB...))
+		}
+
+		f.Logf("%d recorded messages were added to the seed corpus", len(records))
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		// do not run in parallel to measure allocations
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		ctx = conninfo.Ctx(ctx, conninfo.New())
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+
+		bufr := bufio.NewReader(bytes.NewReader(b))
+
+		for {
+			reqHeader, reqBody, err := wire.ReadMessage(bufr)
+			if err != nil {
+				break
+			}
+
+			resHeader, resBody, closeConn := c.route(ctx, reqHeader, reqBody)
+			if closeConn {
+				break
+			}
+
+			require.NotNil(t, resHeader)
+			require.NotNil(t, resBody)
+
+			var buf bytes.Buffer
+			bufw := bufio.NewWriter(&buf)
+			require.NoError(t, wire.WriteMessage(bufw, resHeader, resBody))
+			require.NoError(t, bufw.Flush())
+
+			assert.Equal(t, reqHeader.RequestID, resHeader.ResponseTo)
+		}
+
+		runtime.ReadMemStats(&after)
+
+		alloc := after.TotalAlloc - before.TotalAlloc
+		assert.LessOrEqual(t, alloc, uint64(maxFuzzAlloc), "too much memory allocated for %d bytes of input", len(b))
+	})
+}
+
+func TestDiffBodyString(t *testing.T) {
+	t.Parallel()
+
+	var msg wire.OpMsg
+	require.NoError(t, msg.SetSections(wire.MakeOpMsgSection(
+		must.NotFail(types.NewDocument("n", int32(1), "ok", float64(1))),
+	)))
+
+	expected := "{\n" +
+		`  "n": {` + "\n" +
+		`    "$numberInt": "1"` + "\n" +
+		"  },\n" +
+		`  "ok": {` + "\n" +
+		`    "$numberDouble": "1.0"` + "\n" +
+		"  }\n" +
+		"}\n"
+	assert.Equal(t, expected, diffBodyString(&msg))
+}
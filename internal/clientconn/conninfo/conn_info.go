@@ -37,6 +37,8 @@ type ConnInfo struct {
 
 	Peer netip.AddrPort // invalid for Unix domain sockets
 
+	Local string // local address of the connection: listener's host:port or Unix socket path
+
 	username string // protected by rw
 	password string // protected by rw
 
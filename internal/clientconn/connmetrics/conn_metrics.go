@@ -26,8 +26,29 @@ import (
 
 // ConnMetrics represents metrics of an individual conn or a collection of conns.
 type ConnMetrics struct {
-	Requests  *prometheus.CounterVec
-	Responses *prometheus.CounterVec
+	Requests   *prometheus.CounterVec
+	Responses  *prometheus.CounterVec
+	Namespaces *NamespaceMetrics
+}
+
+// Descriptions of metrics derived from responses.
+var (
+	commandsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "commands_total"),
+		"Total number of command invocations (like serverStatus' metrics.commands.<command>.total).",
+		[]string{"command"}, nil,
+	)
+	commandsFailedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "commands_failed_total"),
+		"Total number of failed command invocations (like serverStatus' metrics.commands.<command>.failed).",
+		[]string{"command"}, nil,
+	)
+)
+
+// CommandCounts represents invocation counts of a single command.
+type CommandCounts struct {
+	Total  int64 // both ok and errors
+	Failed int64
 }
 
 // commandMetrics represents command results metrics.
@@ -57,6 +78,7 @@ func newConnMetrics() *ConnMetrics {
 			},
 			[]string{"opcode", "command", "argument", "result"},
 		),
+		Namespaces: newNamespaceMetrics(maxNamespaces),
 	}
 }
 
@@ -64,12 +86,46 @@ func newConnMetrics() *ConnMetrics {
 func (cm *ConnMetrics) Describe(ch chan<- *prometheus.Desc) {
 	cm.Requests.Describe(ch)
 	cm.Responses.Describe(ch)
+	cm.Namespaces.Describe(ch)
+
+	ch <- commandsTotalDesc
+	ch <- commandsFailedDesc
 }
 
 // Collect implements [prometheus.Collector].
 func (cm *ConnMetrics) Collect(ch chan<- prometheus.Metric) {
 	cm.Requests.Collect(ch)
 	cm.Responses.Collect(ch)
+	cm.Namespaces.Collect(ch)
+
+	for command, c := range cm.GetCommands() {
+		ch <- prometheus.MustNewConstMetric(commandsTotalDesc, prometheus.CounterValue, float64(c.Total), command)
+		ch <- prometheus.MustNewConstMetric(commandsFailedDesc, prometheus.CounterValue, float64(c.Failed), command)
+	}
+}
+
+// GetCommands returns a map with invocation counts by command name (e.g. "find", "aggregate"),
+// summed over all opcodes and arguments.
+func (cm *ConnMetrics) GetCommands() map[string]CommandCounts {
+	res := map[string]CommandCounts{}
+
+	for _, commands := range cm.GetResponses() {
+		for command, arguments := range commands {
+			c := res[command]
+
+			for _, m := range arguments {
+				c.Total += int64(m.Total)
+
+				for _, v := range m.Failures {
+					c.Failed += int64(v)
+				}
+			}
+
+			res[command] = c
+		}
+	}
+
+	return res
 }
 
 // GetResponses returns a map with all response metrics:
@@ -16,6 +16,7 @@ package connmetrics
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -38,3 +39,52 @@ func TestGetResponses(t *testing.T) {
 	}
 	assert.Equal(t, expected, m.GetResponses())
 }
+
+func TestGetCommands(t *testing.T) {
+	m := newConnMetrics()
+	m.Responses.WithLabelValues("OP_MSG", "find", "unknown", "ok").Add(3)
+	m.Responses.WithLabelValues("OP_MSG", "find", "sort", "BadValue").Inc()
+	m.Responses.WithLabelValues("OP_QUERY", "find", "unknown", "ok").Inc()
+	m.Responses.WithLabelValues("OP_MSG", "insert", "unknown", "write-error").Inc()
+
+	expected := map[string]CommandCounts{
+		"find":   {Total: 5, Failed: 1},
+		"insert": {Total: 1, Failed: 1},
+	}
+	assert.Equal(t, expected, m.GetCommands())
+}
+
+func TestNamespaceMetrics(t *testing.T) {
+	t.Parallel()
+
+	nm := newNamespaceMetrics(2)
+	nm.Record("db", "c1", "find", 3*time.Microsecond)
+	nm.Record("db", "c1", "insert", 5*time.Microsecond)
+	nm.Record("db", "c1", "ping", time.Microsecond)
+	nm.Record("db", "", "listCollections", time.Microsecond)
+	nm.Record("db", "c2", "getMore", 2*time.Microsecond)
+	nm.Record("db", "c3", "delete", 7*time.Microsecond)
+	nm.Record("db", "c4", "delete", 1*time.Microsecond)
+
+	expected := map[Namespace]NamespaceStats{
+		{DB: "db", Collection: "c1"}: {
+			Total:     OpMetrics{Count: 3, Micros: 9},
+			ReadLock:  OpMetrics{Count: 1, Micros: 3},
+			WriteLock: OpMetrics{Count: 1, Micros: 5},
+			Queries:   OpMetrics{Count: 1, Micros: 3},
+			Insert:    OpMetrics{Count: 1, Micros: 5},
+			Commands:  OpMetrics{Count: 1, Micros: 1},
+		},
+		{DB: "db", Collection: "c2"}: {
+			Total:    OpMetrics{Count: 1, Micros: 2},
+			ReadLock: OpMetrics{Count: 1, Micros: 2},
+			GetMore:  OpMetrics{Count: 1, Micros: 2},
+		},
+		OtherNamespace: {
+			Total:     OpMetrics{Count: 2, Micros: 8},
+			WriteLock: OpMetrics{Count: 2, Micros: 8},
+			Remove:    OpMetrics{Count: 2, Micros: 8},
+		},
+	}
+	assert.Equal(t, expected, nm.GetNamespaces())
+}
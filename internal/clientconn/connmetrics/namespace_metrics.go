@@ -0,0 +1,228 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmetrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxNamespaces is the default maximum number of namespaces tracked separately.
+const maxNamespaces = 1000
+
+// OtherNamespace is used for operations on namespaces exceeding the limit.
+//
+// MongoDB database names can't contain "*", so it never clashes with a real namespace.
+var OtherNamespace = Namespace{DB: "*", Collection: "*"}
+
+// Descriptions of per-namespace metrics.
+var (
+	namespaceOperationsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "namespace_operations_total"),
+		"Total number of operations per namespace and type (read, write, or command).",
+		[]string{"db", "collection", "type"}, nil,
+	)
+	namespaceSecondsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "namespace_operations_seconds_total"),
+		"Total time spent handling operations per namespace and type (read, write, or command).",
+		[]string{"db", "collection", "type"}, nil,
+	)
+)
+
+// readCommands contains commands that read data, like `top`'s readLock.
+var readCommands = map[string]struct{}{
+	"aggregate":   {},
+	"collStats":   {},
+	"count":       {},
+	"dataSize":    {},
+	"distinct":    {},
+	"find":        {},
+	"getMore":     {},
+	"listIndexes": {},
+}
+
+// writeCommands contains commands that modify data or metadata, like `top`'s writeLock.
+var writeCommands = map[string]struct{}{
+	"collMod":          {},
+	"compact":          {},
+	"create":           {},
+	"createIndexes":    {},
+	"delete":           {},
+	"drop":             {},
+	"dropIndexes":      {},
+	"findAndModify":    {},
+	"insert":           {},
+	"renameCollection": {},
+	"update":           {},
+}
+
+// Namespace represents a database and collection pair.
+type Namespace struct {
+	DB         string
+	Collection string
+}
+
+// String returns namespace in "db.collection" form.
+func (ns Namespace) String() string {
+	return ns.DB + "." + ns.Collection
+}
+
+// OpMetrics represents the number and total duration of operations.
+type OpMetrics struct {
+	Count  int64
+	Micros int64
+}
+
+// add adds a single operation.
+func (m *OpMetrics) add(d time.Duration) {
+	m.Count++
+	m.Micros += d.Microseconds()
+}
+
+// NamespaceStats represents operation metrics of a single namespace
+// in the same breakdown as MongoDB's `top` command.
+type NamespaceStats struct {
+	Total     OpMetrics
+	ReadLock  OpMetrics
+	WriteLock OpMetrics
+	Queries   OpMetrics
+	GetMore   OpMetrics
+	Insert    OpMetrics
+	Update    OpMetrics
+	Remove    OpMetrics
+	Commands  OpMetrics
+}
+
+// NamespaceMetrics tracks operation metrics per namespace.
+//
+// The number of tracked namespaces is limited to keep Prometheus labels cardinality low;
+// operations on other namespaces are aggregated into [OtherNamespace].
+type NamespaceMetrics struct {
+	maxNamespaces int
+
+	rw         sync.RWMutex
+	namespaces map[Namespace]*NamespaceStats
+}
+
+// newNamespaceMetrics creates per-namespace metrics tracking up to maxNamespaces namespaces.
+func newNamespaceMetrics(maxNamespaces int) *NamespaceMetrics {
+	return &NamespaceMetrics{
+		maxNamespaces: maxNamespaces,
+		namespaces:    map[Namespace]*NamespaceStats{},
+	}
+}
+
+// Record records a single command execution on the given namespace.
+//
+// Commands without a collection are ignored.
+func (nm *NamespaceMetrics) Record(db, collection, command string, d time.Duration) {
+	if db == "" || collection == "" {
+		return
+	}
+
+	ns := Namespace{DB: db, Collection: collection}
+
+	nm.rw.Lock()
+	defer nm.rw.Unlock()
+
+	s := nm.namespaces[ns]
+	if s == nil {
+		if len(nm.namespaces) >= nm.maxNamespaces {
+			ns = OtherNamespace
+		}
+
+		if s = nm.namespaces[ns]; s == nil {
+			s = new(NamespaceStats)
+			nm.namespaces[ns] = s
+		}
+	}
+
+	s.Total.add(d)
+
+	if _, ok := readCommands[command]; ok {
+		s.ReadLock.add(d)
+	}
+
+	if _, ok := writeCommands[command]; ok {
+		s.WriteLock.add(d)
+	}
+
+	switch command {
+	case "find":
+		s.Queries.add(d)
+	case "getMore":
+		s.GetMore.add(d)
+	case "insert":
+		s.Insert.add(d)
+	case "update":
+		s.Update.add(d)
+	case "delete":
+		s.Remove.add(d)
+	default:
+		s.Commands.add(d)
+	}
+}
+
+// GetNamespaces returns a copy of metrics for all tracked namespaces.
+func (nm *NamespaceMetrics) GetNamespaces() map[Namespace]NamespaceStats {
+	nm.rw.RLock()
+	defer nm.rw.RUnlock()
+
+	res := make(map[Namespace]NamespaceStats, len(nm.namespaces))
+	for ns, s := range nm.namespaces {
+		res[ns] = *s
+	}
+
+	return res
+}
+
+// Describe implements [prometheus.Collector].
+func (nm *NamespaceMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- namespaceOperationsDesc
+	ch <- namespaceSecondsDesc
+}
+
+// Collect implements [prometheus.Collector].
+func (nm *NamespaceMetrics) Collect(ch chan<- prometheus.Metric) {
+	for ns, s := range nm.GetNamespaces() {
+		// commands that neither read nor write
+		other := OpMetrics{
+			Count:  s.Total.Count - s.ReadLock.Count - s.WriteLock.Count,
+			Micros: s.Total.Micros - s.ReadLock.Micros - s.WriteLock.Micros,
+		}
+
+		for t, m := range map[string]OpMetrics{"read": s.ReadLock, "write": s.WriteLock, "command": other} {
+			if m.Count == 0 {
+				continue
+			}
+
+			seconds := time.Duration(m.Micros * int64(time.Microsecond)).Seconds()
+
+			ch <- prometheus.MustNewConstMetric(
+				namespaceOperationsDesc, prometheus.CounterValue, float64(m.Count), ns.DB, ns.Collection, t,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				namespaceSecondsDesc, prometheus.CounterValue, seconds, ns.DB, ns.Collection, t,
+			)
+		}
+	}
+}
+
+// check interfaces
+var (
+	_ prometheus.Collector = (*NamespaceMetrics)(nil)
+)
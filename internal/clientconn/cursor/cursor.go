@@ -64,8 +64,10 @@ const (
 type Cursor struct {
 	// the order of fields is weird to make the struct smaller due to alignment
 
-	created time.Time
-	iter    types.DocumentsIterator // protected by m
+	created  time.Time
+	lastUsed time.Time               // protected by m
+	iter     types.DocumentsIterator // protected by m
+	pending  *types.Document         // protected by m; see Unread
 	*NewParams
 	r            *Registry
 	l            *zap.Logger
@@ -73,6 +75,7 @@ type Cursor struct {
 	removed      chan struct{} // protected by m
 	ID           int64
 	lastRecordID int64 // protected by m
+	prevRecordID int64 // protected by m; lastRecordID before the last Next call
 	m            sync.Mutex
 }
 
@@ -82,13 +85,16 @@ func newCursor(id int64, iter types.DocumentsIterator, params *NewParams, r *Reg
 		panic("Cursor type must be specified")
 	}
 
+	now := time.Now()
+
 	c := &Cursor{
 		ID:        id,
 		iter:      iter,
 		NewParams: params,
 		r:         r,
 		l:         r.l.With(zap.Int64("id", id), zap.Stringer("type", params.Type)),
-		created:   time.Now(),
+		created:   now,
+		lastUsed:  now,
 		removed:   make(chan struct{}),
 		token:     resource.NewToken(),
 	}
@@ -98,6 +104,34 @@ func newCursor(id int64, iter types.DocumentsIterator, params *NewParams, r *Reg
 	return c
 }
 
+// Info represents cursor information for the debug handler.
+type Info struct {
+	ID         int64     `json:"id"`
+	Type       string    `json:"type"`
+	DB         string    `json:"db"`
+	Collection string    `json:"collection"`
+	Username   string    `json:"username,omitempty"`
+	Created    time.Time `json:"created"`
+	Closed     bool      `json:"closed"`
+}
+
+// Info returns cursor information.
+func (c *Cursor) Info() *Info {
+	c.m.Lock()
+	closed := c.iter == nil
+	c.m.Unlock()
+
+	return &Info{
+		ID:         c.ID,
+		Type:       c.Type.String(),
+		DB:         c.DB,
+		Collection: c.Collection,
+		Username:   c.Username,
+		Created:    c.created,
+		Closed:     closed,
+	}
+}
+
 // Reset replaces the underlying iterator with a given one
 // and advanced it until the last known record ID is reached.
 //
@@ -111,6 +145,7 @@ func (c *Cursor) Reset(iter types.DocumentsIterator) error {
 
 	c.l.Debug("Resetting cursor")
 	c.iter = iter
+	c.pending = nil
 	recordID := c.lastRecordID
 
 	c.m.Unlock()
@@ -136,17 +171,47 @@ func (c *Cursor) Next() (struct{}, *types.Document, error) {
 		return struct{}{}, nil, iterator.ErrIteratorDone
 	}
 
-	zero, doc, err := c.iter.Next()
-	if doc != nil {
-		recordID := doc.RecordID()
-		c.lastRecordID = recordID
+	c.lastUsed = time.Now()
+
+	doc := c.pending
+	c.pending = nil
 
-		if c.ShowRecordID {
-			doc.Set("$recordId", recordID)
+	if doc == nil {
+		var err error
+		if _, doc, err = c.iter.Next(); err != nil {
+			return struct{}{}, nil, err
 		}
 	}
 
-	return zero, doc, err
+	recordID := doc.RecordID()
+	c.prevRecordID = c.lastRecordID
+	c.lastRecordID = recordID
+
+	if c.ShowRecordID {
+		doc.Set("$recordId", recordID)
+	}
+
+	return struct{}{}, doc, nil
+}
+
+// Unread returns the document received by the last Next call back to the cursor,
+// so the next Next call returns it again.
+//
+// It is used when that document does not fit into the current batch.
+func (c *Cursor) Unread(doc *types.Document) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.iter == nil {
+		return
+	}
+
+	if c.pending != nil {
+		panic("Unread called twice")
+	}
+
+	c.pending = doc
+	c.lastRecordID = c.prevRecordID
 }
 
 // Close implements types.DocumentsIterator interface.
@@ -164,6 +229,7 @@ func (c *Cursor) Close() {
 	c.l.Debug("Closing cursor's iterator")
 	c.iter.Close()
 	c.iter = nil
+	c.pending = nil
 
 	c.m.Unlock()
 
@@ -175,6 +241,14 @@ func (c *Cursor) Close() {
 	resource.Untrack(c, c.token)
 }
 
+// idle returns the time since the cursor was last used.
+func (c *Cursor) idle() time.Duration {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	return time.Since(c.lastUsed)
+}
+
 // check interfaces
 var (
 	_ types.DocumentsIterator = (*Cursor)(nil)
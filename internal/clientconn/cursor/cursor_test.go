@@ -91,6 +91,33 @@ func TestCursor(t *testing.T) {
 			assert.Nil(t, r.Get(c.ID), "cursor should be removed")
 		})
 
+		t.Run("Timeout", func(t *testing.T) {
+			t.Parallel()
+
+			r := NewRegistry(testutil.Logger(t))
+			r.timeout = 500 * time.Millisecond
+
+			t.Cleanup(r.Close)
+
+			c := r.NewCursor(ctx, iterator.Values(iterator.ForSlice(all)), params)
+
+			time.Sleep(300 * time.Millisecond)
+
+			_, doc, err := c.Next()
+			require.NoError(t, err)
+			assert.Equal(t, doc1, doc)
+
+			time.Sleep(300 * time.Millisecond)
+			assert.Same(t, c, r.Get(c.ID), "cursor was used recently and should not be removed")
+
+			time.Sleep(time.Second)
+
+			_, _, err = c.Next()
+			assert.ErrorIs(t, err, iterator.ErrIteratorDone)
+
+			assert.Nil(t, r.Get(c.ID), "cursor should be removed")
+		})
+
 		t.Run("Reset", func(t *testing.T) {
 			t.Parallel()
 
@@ -107,6 +134,26 @@ func TestCursor(t *testing.T) {
 				_ = c.Reset(iter)
 			})
 		})
+
+		t.Run("Unread", func(t *testing.T) {
+			t.Parallel()
+
+			c := r.NewCursor(ctx, iterator.Values(iterator.ForSlice(all)), params)
+
+			_, doc, err := c.Next()
+			require.NoError(t, err)
+			assert.Equal(t, doc1, doc)
+
+			_, doc, err = c.Next()
+			require.NoError(t, err)
+			assert.Equal(t, doc2, doc)
+
+			c.Unread(doc)
+
+			actual, err := iterator.ConsumeValues(c)
+			require.NoError(t, err)
+			assert.Equal(t, []*types.Document{doc2, doc3}, actual)
+		})
 	})
 
 	t.Run("Tailable", func(t *testing.T) {
@@ -166,5 +213,47 @@ func TestCursor(t *testing.T) {
 			require.NoError(t, err)
 			assert.Equal(t, []*types.Document{doc3}, actual)
 		})
+
+		t.Run("UnreadReset", func(t *testing.T) {
+			t.Parallel()
+
+			c := r.NewCursor(ctx, iterator.Values(iterator.ForSlice(all)), params)
+
+			_, _, err := c.Next()
+			require.NoError(t, err)
+
+			_, doc, err := c.Next()
+			require.NoError(t, err)
+
+			c.Unread(doc)
+
+			err = c.Reset(iterator.Values(iterator.ForSlice(all)))
+			require.NoError(t, err)
+
+			actual, err := iterator.ConsumeValues(c)
+			require.NoError(t, err)
+			assert.Equal(t, []*types.Document{doc2, doc3}, actual)
+		})
 	})
 }
+
+func TestRegistryClose(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry(testutil.Logger(t))
+
+	ctx := testutil.Ctx(t)
+	doc := must.NotFail(types.NewDocument("v", int32(1)))
+
+	normal := r.NewCursor(ctx, iterator.Values(iterator.ForSlice([]*types.Document{doc})), &NewParams{Type: Normal})
+	tailable := r.NewCursor(ctx, iterator.Values(iterator.ForSlice([]*types.Document{doc})), &NewParams{Type: Tailable})
+
+	r.Close()
+
+	assert.Empty(t, r.All())
+
+	for _, c := range []*Cursor{normal, tailable} {
+		_, _, err := c.Next()
+		assert.ErrorIs(t, err, iterator.ErrIteratorDone)
+	}
+}
@@ -35,6 +35,10 @@ const (
 	subsystem = "cursors"
 )
 
+// DefaultTimeout is the default time after which unused cursors are closed and removed.
+// It matches MongoDB's default value of the cursorTimeoutMillis parameter.
+const DefaultTimeout = 10 * time.Minute
+
 // Global last cursor ID.
 var lastCursorID atomic.Uint32
 
@@ -47,13 +51,18 @@ func init() {
 
 // Registry stores cursors.
 //
+// Cursors are keyed by their IDs and are not bound to client connections,
+// so they could be used by any connection of the same user
+// (for example, when a driver retries getMore on a different pooled connection).
+//
 //nolint:vet // for readability
 type Registry struct {
 	rw sync.RWMutex
 	m  map[int64]*Cursor
 
-	l  *zap.Logger
-	wg sync.WaitGroup
+	l       *zap.Logger
+	wg      sync.WaitGroup
+	timeout time.Duration
 
 	created  *prometheus.CounterVec
 	duration *prometheus.HistogramVec
@@ -62,8 +71,9 @@ type Registry struct {
 // NewRegistry creates a new Registry.
 func NewRegistry(l *zap.Logger) *Registry {
 	return &Registry{
-		m: map[int64]*Cursor{},
-		l: l,
+		m:       map[int64]*Cursor{},
+		l:       l,
+		timeout: DefaultTimeout,
 		created: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
@@ -99,8 +109,12 @@ func NewRegistry(l *zap.Logger) *Registry {
 	}
 }
 
-// Close waits for all cursors to be closed and removed from the registry.
+// Close closes and removes all cursors, then waits for that to finish.
 func (r *Registry) Close() {
+	for _, c := range r.All() {
+		r.CloseAndRemove(c)
+	}
+
 	// we mainly do that for tests; see https://github.com/uber-go/zap/issues/687
 
 	r.wg.Wait()
@@ -113,8 +127,12 @@ type NewParams struct {
 	// Stored as any to avoid dependency cycle.
 	Data any
 
+	// Logical session ID of the command that created the cursor, or nil.
+	// It is used for ownership checks by `getMore` and `killCursors`.
+	LSID *types.Document
+
 	// those fields are used for limited authorization checks
-	// before we implement proper authz and/or sessions
+	// before we implement proper authz
 	DB         string
 	Collection string
 	Username   string
@@ -127,8 +145,11 @@ type NewParams struct {
 
 // NewCursor creates and stores a new cursor.
 //
-// The cursor of any type will be closed automatically when a given context is canceled,
+// The cursor of any type will be closed automatically when a given context is canceled
+// or when it was not used for the registry timeout,
 // even if the cursor is not being used at that time.
+// The context should not be canceled when the client connection is closed
+// to allow other connections to use the cursor.
 func (r *Registry) NewCursor(ctx context.Context, iter types.DocumentsIterator, params *NewParams) *Cursor {
 	r.rw.Lock()
 	defer r.rw.Unlock()
@@ -154,10 +175,30 @@ func (r *Registry) NewCursor(ctx context.Context, iter types.DocumentsIterator,
 	go func() {
 		defer r.wg.Done()
 
-		select {
-		case <-ctx.Done():
-			r.CloseAndRemove(c)
-		case <-c.removed: // for c.Close() and normal cursors
+		t := time.NewTimer(r.timeout)
+		defer t.Stop()
+
+	loop:
+		for {
+			select {
+			case <-ctx.Done():
+				r.CloseAndRemove(c)
+				break loop
+
+			case <-t.C:
+				if idle := c.idle(); idle < r.timeout {
+					t.Reset(r.timeout - idle)
+					continue
+				}
+
+				c.l.Debug("Cursor timed out")
+				r.CloseAndRemove(c)
+
+				break loop
+
+			case <-c.removed: // for c.Close() and normal cursors
+				break loop
+			}
 		}
 
 		<-c.removed
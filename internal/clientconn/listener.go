@@ -23,6 +23,7 @@ import (
 	"net"
 	"runtime/pprof"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -37,7 +38,7 @@ import (
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
-// Listener listens on one or multiple interfaces (TCP, Unix, TLS sockets)
+// Listener listens on one or multiple interfaces (TCP, Unix, TLS sockets, in-process)
 // and accepts incoming client connections.
 type Listener struct {
 	*NewListenerOpts
@@ -47,10 +48,18 @@ type Listener struct {
 	tcpListener  net.Listener
 	unixListener net.Listener
 	tlsListener  net.Listener
+	memListener  *memoryListener
+
+	tlsConfig atomic.Pointer[tls.Config] // could be reloaded
+	maxConns  atomic.Int64
+	numConns  atomic.Int64
 
 	tcpListenerReady  chan struct{}
 	unixListenerReady chan struct{}
 	tlsListenerReady  chan struct{}
+
+	connsM sync.Mutex
+	conns  map[*conn]struct{}
 }
 
 // NewListenerOpts represents listener configuration.
@@ -63,12 +72,20 @@ type NewListenerOpts struct {
 	TLSKeyFile  string
 	TLSCAFile   string
 
+	// If true, in-process connections could be created with [Listener.DialMemory].
+	Memory bool
+
+	// Maximum number of client connections; zero means no limit.
+	// It could be changed at runtime with [Listener.SetMaxConns].
+	MaxConns int
+
 	ProxyAddr        string
 	ProxyTLSCertFile string
 	ProxyTLSKeyFile  string
 	ProxyTLSCAFile   string
 
 	Mode           Mode
+	BSONValidation wire.ValidationLevel
 	Metrics        *connmetrics.ListenerMetrics
 	Handler        *handler.Handler
 	Logger         *zap.Logger
@@ -84,8 +101,11 @@ func Listen(opts *NewListenerOpts) (*Listener, error) {
 		tcpListenerReady:  make(chan struct{}),
 		unixListenerReady: make(chan struct{}),
 		tlsListenerReady:  make(chan struct{}),
+		conns:             map[*conn]struct{}{},
 	}
 
+	l.maxConns.Store(int64(opts.MaxConns))
+
 	var err error
 
 	if l.TCP != "" {
@@ -109,13 +129,18 @@ func Listen(opts *NewListenerOpts) (*Listener, error) {
 	}
 
 	if l.TLS != "" {
-		var config *tls.Config
-
-		if config, err = tlsutil.Config(l.TLSCertFile, l.TLSKeyFile, l.TLSCAFile); err != nil {
+		if err = l.ReloadTLS(); err != nil {
 			opts.Handler.Close()
 			return nil, err
 		}
 
+		// use the current config for each new connection
+		config := &tls.Config{
+			GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+				return l.tlsConfig.Load(), nil
+			},
+		}
+
 		if l.tlsListener, err = tls.Listen("tcp", l.TLS, config); err != nil {
 			opts.Handler.Close()
 			return nil, lazyerrors.Error(err)
@@ -125,6 +150,11 @@ func Listen(opts *NewListenerOpts) (*Listener, error) {
 		ll.Sugar().Infof("Listening on TLS %s ...", l.TLSAddr())
 	}
 
+	if l.Memory {
+		l.memListener = newMemoryListener()
+		ll.Info("Listening for in-process connections ...")
+	}
+
 	return l, nil
 }
 
@@ -153,6 +183,10 @@ func (l *Listener) Run(ctx context.Context) {
 		if l.tlsListener != nil {
 			l.tlsListener.Close()
 		}
+
+		if l.memListener != nil {
+			l.memListener.Close()
+		}
 	}()
 
 	if l.TCP != "" {
@@ -194,6 +228,19 @@ func (l *Listener) Run(ctx context.Context) {
 		}()
 	}
 
+	if l.Memory {
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				l.ll.Info("In-process listener stopped.")
+				wg.Done()
+			}()
+
+			acceptLoop(ctx, l.memListener, &wg, l)
+		}()
+	}
+
 	<-ctx.Done()
 	l.ll.Info("Waiting for all connections to stop...")
 	wg.Wait()
@@ -220,6 +267,20 @@ func acceptLoop(ctx context.Context, listener net.Listener, wg *sync.WaitGroup,
 			continue
 		}
 
+		if maxConns := l.maxConns.Load(); maxConns > 0 && l.numConns.Load() >= maxConns {
+			l.Metrics.Accepts.WithLabelValues("1").Inc()
+
+			l.ll.Warn(
+				"Connection rejected: too many connections",
+				zap.Stringer("remote", netConn.RemoteAddr()), zap.Int64("max", maxConns),
+			)
+			netConn.Close()
+
+			continue
+		}
+
+		l.numConns.Add(1)
+
 		wg.Add(1)
 		l.Metrics.Accepts.WithLabelValues("0").Inc()
 
@@ -235,6 +296,7 @@ func acceptLoop(ctx context.Context, listener net.Listener, wg *sync.WaitGroup,
 
 				l.Metrics.Durations.WithLabelValues(lv).Observe(time.Since(start).Seconds())
 				netConn.Close()
+				l.numConns.Add(-1)
 				wg.Done()
 			}()
 
@@ -243,9 +305,9 @@ func acceptLoop(ctx context.Context, listener net.Listener, wg *sync.WaitGroup,
 			defer connCancel(nil)
 
 			remoteAddr := netConn.RemoteAddr().String()
-			if netConn.RemoteAddr().Network() == "unix" {
-				// otherwise, all of them would be "" or "@"
-				remoteAddr = fmt.Sprintf("unix:%d", rand.Int())
+			if network := netConn.RemoteAddr().Network(); network == "unix" || network == "memory" {
+				// otherwise, all of them would be "" or "@" (or "memory")
+				remoteAddr = fmt.Sprintf("%s:%d", network, rand.Int())
 			}
 
 			connID := fmt.Sprintf("%s -> %s", remoteAddr, netConn.LocalAddr())
@@ -257,9 +319,11 @@ func acceptLoop(ctx context.Context, listener net.Listener, wg *sync.WaitGroup,
 			opts := &newConnOpts{
 				netConn:     netConn,
 				mode:        l.Mode,
+				validation:  l.BSONValidation,
 				l:           l.Logger.Named("// " + connID + " "), // derive from the original unnamed logger
 				handler:     l.Handler,
 				connMetrics: l.Metrics.ConnMetrics, // share between all conns
+				id:          connID,
 
 				proxyAddr:        l.ProxyAddr,
 				proxyTLSCertFile: l.ProxyTLSCertFile,
@@ -277,6 +341,9 @@ func acceptLoop(ctx context.Context, listener net.Listener, wg *sync.WaitGroup,
 
 			l.ll.Info("Connection started", zap.String("conn", connID))
 
+			l.addConn(conn)
+			defer l.removeConn(conn)
+
 			connErr = conn.run(connCtx)
 			if errors.Is(connErr, wire.ErrZeroRead) {
 				connErr = nil
@@ -312,6 +379,52 @@ func (l *Listener) TLSAddr() net.Addr {
 	return l.tlsListener.Addr()
 }
 
+// ReloadTLS reloads TLS certificate, key, and CA files.
+//
+// Only new connections use reloaded files.
+func (l *Listener) ReloadTLS() error {
+	if l.TLS == "" {
+		return lazyerrors.New("TLS listener is not enabled")
+	}
+
+	config, err := tlsutil.Config(l.TLSCertFile, l.TLSKeyFile, l.TLSCAFile)
+	if err != nil {
+		return err
+	}
+
+	l.tlsConfig.Store(config)
+
+	return nil
+}
+
+// SetMaxConns sets the maximum number of client connections; zero means no limit.
+//
+// Existing connections are not closed if the new limit is lower.
+func (l *Listener) SetMaxConns(maxConns int) {
+	l.maxConns.Store(int64(maxConns))
+}
+
+// MaxConns returns the maximum number of client connections; zero means no limit.
+func (l *Listener) MaxConns() int {
+	return int(l.maxConns.Load())
+}
+
+// DialMemory returns a new in-process connection to the listener.
+//
+// The listener should be created with Memory option and running.
+func (l *Listener) DialMemory(ctx context.Context) (net.Conn, error) {
+	if l.memListener == nil {
+		return nil, lazyerrors.New("in-process listener is not enabled")
+	}
+
+	conn, err := l.memListener.dial(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return conn, nil
+}
+
 // Describe implements [prometheus.Collector].
 func (l *Listener) Describe(ch chan<- *prometheus.Desc) {
 	l.Metrics.Describe(ch)
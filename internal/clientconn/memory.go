@@ -0,0 +1,109 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+// memoryAddr is the address of in-process connections.
+type memoryAddr struct{}
+
+// Network implements [net.Addr].
+func (memoryAddr) Network() string { return "memory" }
+
+// String implements [net.Addr].
+func (memoryAddr) String() string { return "memory" }
+
+// memoryConn is a server side of in-process connection.
+type memoryConn struct {
+	net.Conn
+}
+
+// LocalAddr implements [net.Conn].
+func (memoryConn) LocalAddr() net.Addr { return memoryAddr{} }
+
+// RemoteAddr implements [net.Conn].
+func (memoryConn) RemoteAddr() net.Addr { return memoryAddr{} }
+
+// memoryListener is a [net.Listener] for in-process connections without sockets.
+type memoryListener struct {
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newMemoryListener creates a new memoryListener.
+func newMemoryListener() *memoryListener {
+	return &memoryListener{
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+// Accept implements [net.Listener].
+func (ml *memoryListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-ml.conns:
+		return c, nil
+	case <-ml.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close implements [net.Listener].
+func (ml *memoryListener) Close() error {
+	ml.closeOnce.Do(func() {
+		close(ml.done)
+	})
+
+	return nil
+}
+
+// Addr implements [net.Listener].
+func (ml *memoryListener) Addr() net.Addr {
+	return memoryAddr{}
+}
+
+// dial returns a client side of a new in-process connection.
+func (ml *memoryListener) dial(ctx context.Context) (net.Conn, error) {
+	client, server := net.Pipe()
+
+	select {
+	case ml.conns <- memoryConn{Conn: server}:
+		return client, nil
+
+	case <-ml.done:
+		client.Close()
+		server.Close()
+
+		return nil, net.ErrClosed
+
+	case <-ctx.Done():
+		client.Close()
+		server.Close()
+
+		return nil, ctx.Err()
+	}
+}
+
+// check interfaces
+var (
+	_ net.Addr     = memoryAddr{}
+	_ net.Conn     = memoryConn{}
+	_ net.Listener = (*memoryListener)(nil)
+)
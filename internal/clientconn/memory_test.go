@@ -0,0 +1,77 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestMemoryListener(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	ml := newMemoryListener()
+
+	accepted := make(chan net.Conn, 1)
+
+	go func() {
+		c, err := ml.Accept()
+		assert.NoError(t, err)
+		accepted <- c
+	}()
+
+	client, err := ml.dial(ctx)
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	server := <-accepted
+
+	defer server.Close()
+
+	assert.Equal(t, "memory", server.RemoteAddr().Network())
+
+	go func() {
+		_, err := client.Write([]byte("ping"))
+		assert.NoError(t, err)
+	}()
+
+	b := make([]byte, 4)
+	_, err = io.ReadFull(server, b)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(b))
+
+	require.NoError(t, ml.Close())
+
+	_, err = ml.Accept()
+	assert.ErrorIs(t, err, net.ErrClosed)
+
+	_, err = ml.dial(ctx)
+	assert.ErrorIs(t, err, net.ErrClosed)
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = newMemoryListener().dial(canceledCtx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"slices"
+	"time"
+)
+
+// ConnState represents a client connection state for the debug handler.
+type ConnState struct {
+	ID       string    `json:"id"`
+	Username string    `json:"username,omitempty"`
+	Started  time.Time `json:"started"`
+	Op       *OpState  `json:"op,omitempty"`
+}
+
+// OpState represents an in-flight operation state for the debug handler.
+type OpState struct {
+	Conn       string    `json:"conn"`
+	Command    string    `json:"command"`
+	DB         string    `json:"db,omitempty"`
+	Collection string    `json:"collection,omitempty"`
+	Started    time.Time `json:"started"`
+}
+
+// addConn registers a client connection.
+func (l *Listener) addConn(c *conn) {
+	l.connsM.Lock()
+	defer l.connsM.Unlock()
+
+	l.conns[c] = struct{}{}
+}
+
+// removeConn unregisters a client connection.
+func (l *Listener) removeConn(c *conn) {
+	l.connsM.Lock()
+	defer l.connsM.Unlock()
+
+	delete(l.conns, c)
+}
+
+// Conns returns the state of all client connections, the oldest first.
+func (l *Listener) Conns() []*ConnState {
+	l.connsM.Lock()
+	defer l.connsM.Unlock()
+
+	res := make([]*ConnState, 0, len(l.conns))
+
+	for c := range l.conns {
+		s := &ConnState{
+			ID:      c.id,
+			Started: c.started,
+			Op:      c.op.Load(),
+		}
+
+		if connInfo := c.connInfo.Load(); connInfo != nil {
+			s.Username = connInfo.Username()
+		}
+
+		res = append(res, s)
+	}
+
+	slices.SortFunc(res, func(a, b *ConnState) int {
+		return a.Started.Compare(b.Started)
+	})
+
+	return res
+}
+
+// Operations returns the state of all in-flight operations, the oldest first.
+func (l *Listener) Operations() []*OpState {
+	var res []*OpState
+
+	for _, c := range l.Conns() {
+		if c.Op != nil {
+			res = append(res, c.Op)
+		}
+	}
+
+	slices.SortFunc(res, func(a, b *OpState) int {
+		return a.Started.Compare(b.Started)
+	})
+
+	return res
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/util/observability"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// traceContextFields contains command fields that could carry W3C trace context, in order of priority.
+var traceContextFields = []string{"comment", "$audit"}
+
+// traceContextKeys contains W3C trace context keys.
+var traceContextKeys = []string{"traceparent", "tracestate"}
+
+// traceContext returns a context with the remote span context passed by the client, if any.
+//
+// Clients can't set arbitrary wire protocol headers, so trace context is passed in the `comment`
+// (or `$audit`) field of the command: either as a document or as a JSON object string
+// with `traceparent` and optional `tracestate` fields. For example:
+//
+//	{find: "values", comment: '{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}'}
+//
+// Invalid or missing trace context is ignored; the passed context is returned as is in that case.
+// It is also returned as is when tracing is not set up, without looking at the command.
+func traceContext(ctx context.Context, reqBody wire.MsgBody) context.Context {
+	if !observability.TracingEnabled() {
+		return ctx
+	}
+
+	msg, ok := reqBody.(*wire.OpMsg)
+	if !ok {
+		return ctx
+	}
+
+	raw, err := msg.RawDocument()
+	if err != nil {
+		return ctx
+	}
+
+	return remoteTraceContext(ctx, raw)
+}
+
+// remoteTraceContext returns a context with the remote span context passed in command fields, if any.
+//
+// Only those fields are read; the rest of the command is not decoded.
+func remoteTraceContext(ctx context.Context, raw bson.RawDocument) context.Context {
+	for _, f := range traceContextFields {
+		v, err := raw.Get(f)
+		if err != nil {
+			return ctx
+		}
+
+		carrier := traceCarrier(v)
+		if carrier == nil {
+			continue
+		}
+
+		remoteCtx := propagation.TraceContext{}.Extract(ctx, carrier)
+		if oteltrace.SpanContextFromContext(remoteCtx).IsValid() {
+			return remoteCtx
+		}
+	}
+
+	return ctx
+}
+
+// traceCarrier returns a carrier with W3C trace context from the given field value,
+// or nil if the value does not contain it.
+func traceCarrier(v any) propagation.MapCarrier {
+	carrier := propagation.MapCarrier{}
+
+	switch v := v.(type) {
+	case bson.RawDocument:
+		doc, err := v.Decode()
+		if err != nil {
+			return nil
+		}
+
+		for _, k := range traceContextKeys {
+			if s, ok := doc.Get(k).(string); ok {
+				carrier[k] = s
+			}
+		}
+
+	case string:
+		var m map[string]any
+		if err := json.Unmarshal([]byte(v), &m); err != nil {
+			return nil
+		}
+
+		for _, k := range traceContextKeys {
+			if s, ok := m[k].(string); ok {
+				carrier[k] = s
+			}
+		}
+
+	default:
+		return nil
+	}
+
+	if carrier["traceparent"] == "" {
+		return nil
+	}
+
+	return carrier
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+func TestTraceContext(t *testing.T) {
+	t.Parallel()
+
+	const (
+		traceParent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+		traceID     = "0af7651916cd43dd8448eb211c80319c"
+		spanID      = "b7ad6b7169203331"
+	)
+
+	for name, tc := range map[string]struct {
+		doc     *types.Document
+		traceID string
+		spanID  string
+	}{
+		"CommentString": {
+			doc: must.NotFail(types.NewDocument(
+				"find", "values",
+				"comment", `{"traceparent":"`+traceParent+`"}`,
+			)),
+			traceID: traceID,
+			spanID:  spanID,
+		},
+		"CommentDocument": {
+			doc: must.NotFail(types.NewDocument(
+				"find", "values",
+				"comment", must.NotFail(types.NewDocument("traceparent", traceParent)),
+			)),
+			traceID: traceID,
+			spanID:  spanID,
+		},
+		"Audit": {
+			doc: must.NotFail(types.NewDocument(
+				"find", "values",
+				"comment", "just a comment",
+				"$audit", must.NotFail(types.NewDocument("traceparent", traceParent)),
+			)),
+			traceID: traceID,
+			spanID:  spanID,
+		},
+		"InvalidTraceParent": {
+			doc: must.NotFail(types.NewDocument(
+				"find", "values",
+				"comment", `{"traceparent":"invalid"}`,
+			)),
+		},
+		"NoComment": {
+			doc: must.NotFail(types.NewDocument("find", "values")),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var msg wire.OpMsg
+			require.NoError(t, msg.SetSections(wire.MakeOpMsgSection(tc.doc)))
+
+			raw, err := msg.RawDocument()
+			require.NoError(t, err)
+
+			sc := oteltrace.SpanContextFromContext(remoteTraceContext(context.Background(), raw))
+
+			if tc.traceID == "" {
+				assert.False(t, sc.IsValid())
+				return
+			}
+
+			assert.True(t, sc.IsRemote())
+			assert.Equal(t, tc.traceID, sc.TraceID().String())
+			assert.Equal(t, tc.spanID, sc.SpanID().String())
+		})
+	}
+}
@@ -0,0 +1,516 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// actionError represents an error caused by the request that is returned to the client.
+type actionError struct {
+	code string
+	msg  string
+}
+
+// newActionError creates a new actionError.
+func newActionError(code, format string, args ...any) error {
+	return &actionError{
+		code: code,
+		msg:  fmt.Sprintf(format, args...),
+	}
+}
+
+// Error implements [error].
+func (e *actionError) Error() string {
+	return e.code + ": " + e.msg
+}
+
+// namespace returns database and collection names from the request.
+func namespace(req *types.Document) (string, string, error) {
+	// dataSource is ignored as there is only one
+	db, err := getParam[string](req, "database", true)
+	if err != nil {
+		return "", "", err
+	}
+
+	coll, err := getParam[string](req, "collection", true)
+	if err != nil {
+		return "", "", err
+	}
+
+	return db, coll, nil
+}
+
+// getParam returns the request parameter with the given type.
+//
+// If the parameter is not required and is not present, zero value is returned.
+func getParam[T any](req *types.Document, key string, required bool) (T, error) {
+	var zero T
+
+	v, _ := req.Get(key)
+	if v == nil {
+		if required {
+			return zero, newActionError("InvalidParameter", "%s is required", key)
+		}
+
+		return zero, nil
+	}
+
+	res, ok := v.(T)
+	if !ok {
+		return zero, newActionError("InvalidParameter", "%s has invalid type %T", key, v)
+	}
+
+	return res, nil
+}
+
+// copyParams sets optional request parameters to the command document.
+func copyParams(cmd, req *types.Document, keys ...string) {
+	for _, k := range keys {
+		if v, _ := req.Get(k); v != nil {
+			cmd.Set(k, v)
+		}
+	}
+}
+
+// runCommand runs the given command in the given database and returns its result.
+//
+// Command errors and write errors are returned as [*actionError].
+func (s *Server) runCommand(ctx context.Context, db string, cmd *types.Document) (*types.Document, error) {
+	cmd.Set("$db", db)
+
+	command := cmd.Command()
+
+	c := s.opts.Handler.Commands()[command]
+	if c == nil || c.Handler == nil {
+		return nil, lazyerrors.Errorf("no handler for command %q", command)
+	}
+
+	var msg wire.OpMsg
+	if err := msg.SetSections(wire.MakeOpMsgSection(cmd)); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// requests are authenticated by API keys
+	info := conninfo.New()
+	info.SetBypassBackendAuth()
+
+	resMsg, err := c.Handler(conninfo.Ctx(ctx, info), &msg)
+	if err != nil {
+		var ce *handlererrors.CommandError
+		var we *handlererrors.WriteErrors
+
+		if errors.As(err, &ce) || errors.As(err, &we) {
+			return nil, errorFromDocument(handlererrors.ProtocolError(err).Document())
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := resMsg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if res.Has("writeErrors") {
+		return nil, errorFromDocument(res)
+	}
+
+	return res, nil
+}
+
+// errorFromDocument returns [*actionError] for the error document with the first error.
+func errorFromDocument(doc *types.Document) error {
+	if v, _ := doc.Get("writeErrors"); v != nil {
+		if arr, ok := v.(*types.Array); ok && arr.Len() > 0 {
+			if we, ok := must.NotFail(arr.Get(0)).(*types.Document); ok {
+				doc = we
+			}
+		}
+	}
+
+	code := "InvalidParameter"
+	if v, _ := doc.Get("codeName"); v != nil {
+		code, _ = v.(string)
+	} else if v, _ := doc.Get("code"); v != nil {
+		if c, ok := v.(int32); ok {
+			code = handlererrors.ErrorCode(c).String()
+		}
+	}
+
+	v, _ := doc.Get("errmsg")
+	msg, _ := v.(string)
+
+	return &actionError{code: code, msg: msg}
+}
+
+// resultField returns the field of the command result with the given type.
+func resultField[T any](res *types.Document, key string) (T, error) {
+	var zero T
+
+	v, err := res.Get(key)
+	if err != nil {
+		return zero, lazyerrors.Error(err)
+	}
+
+	f, ok := v.(T)
+	if !ok {
+		return zero, lazyerrors.Errorf("invalid %s: %T", key, v)
+	}
+
+	return f, nil
+}
+
+// cursorDocuments returns all documents from the cursor of the command result.
+//
+// The cursor is killed if not all documents could be fetched.
+func (s *Server) cursorDocuments(ctx context.Context, db, coll string, res *types.Document) (_ *types.Array, err error) {
+	docs := types.MakeArray(0)
+	batchKey := "firstBatch"
+
+	var id int64
+
+	defer func() {
+		if err == nil || id == 0 {
+			return
+		}
+
+		s.killCursor(ctx, db, coll, id)
+	}()
+
+	for {
+		var cursor *types.Document
+		if cursor, err = resultField[*types.Document](res, "cursor"); err != nil {
+			return nil, err
+		}
+
+		if id, err = resultField[int64](cursor, "id"); err != nil {
+			return nil, err
+		}
+
+		var batch *types.Array
+		if batch, err = resultField[*types.Array](cursor, batchKey); err != nil {
+			return nil, err
+		}
+
+		iter := batch.Iterator()
+
+		for {
+			_, v, err := iter.Next()
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			if err != nil {
+				iter.Close()
+				return nil, lazyerrors.Error(err)
+			}
+
+			docs.Append(v)
+		}
+
+		iter.Close()
+
+		if id == 0 {
+			return docs, nil
+		}
+
+		res, err = s.runCommand(ctx, db, must.NotFail(types.NewDocument(
+			"getMore", id,
+			"collection", coll,
+		)))
+		if err != nil {
+			return nil, err
+		}
+
+		batchKey = "nextBatch"
+	}
+}
+
+// killCursor kills the cursor with the given ID, logging errors.
+func (s *Server) killCursor(ctx context.Context, db, coll string, id int64) {
+	// kill the cursor even if the request was canceled
+	ctx = context.WithoutCancel(ctx)
+
+	_, err := s.runCommand(ctx, db, must.NotFail(types.NewDocument(
+		"killCursors", coll,
+		"cursors", must.NotFail(types.NewArray(id)),
+	)))
+	if err != nil {
+		s.opts.L.Warn("Failed to kill cursor", zap.Int64("id", id), zap.Error(err))
+	}
+}
+
+// find implements `find` action.
+func (s *Server) find(ctx context.Context, req *types.Document) (*types.Document, error) {
+	db, coll, err := namespace(req)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := must.NotFail(types.NewDocument("find", coll))
+	copyParams(cmd, req, "filter", "projection", "sort", "limit", "skip")
+
+	res, err := s.runCommand(ctx, db, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := s.cursorDocuments(ctx, db, coll, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return must.NotFail(types.NewDocument("documents", docs)), nil
+}
+
+// findOne implements `findOne` action.
+func (s *Server) findOne(ctx context.Context, req *types.Document) (*types.Document, error) {
+	db, coll, err := namespace(req)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := must.NotFail(types.NewDocument("find", coll))
+	copyParams(cmd, req, "filter", "projection")
+	cmd.Set("limit", int64(1))
+
+	res, err := s.runCommand(ctx, db, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := s.cursorDocuments(ctx, db, coll, res)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc any = types.Null
+	if docs.Len() > 0 {
+		doc = must.NotFail(docs.Get(0))
+	}
+
+	return must.NotFail(types.NewDocument("document", doc)), nil
+}
+
+// insert inserts given documents, setting _id if needed, and returns their _id values.
+func (s *Server) insert(ctx context.Context, db, coll string, docs *types.Array) (*types.Array, error) {
+	ids := types.MakeArray(docs.Len())
+
+	for i := 0; i < docs.Len(); i++ {
+		doc, ok := must.NotFail(docs.Get(i)).(*types.Document)
+		if !ok {
+			return nil, newActionError("InvalidParameter", "documents should contain only documents")
+		}
+
+		if !doc.Has("_id") {
+			doc.Set("_id", types.NewObjectID())
+		}
+
+		ids.Append(must.NotFail(doc.Get("_id")))
+	}
+
+	_, err := s.runCommand(ctx, db, must.NotFail(types.NewDocument(
+		"insert", coll,
+		"documents", docs,
+	)))
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// insertOne implements `insertOne` action.
+func (s *Server) insertOne(ctx context.Context, req *types.Document) (*types.Document, error) {
+	db, coll, err := namespace(req)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := getParam[*types.Document](req, "document", true)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := s.insert(ctx, db, coll, must.NotFail(types.NewArray(doc)))
+	if err != nil {
+		return nil, err
+	}
+
+	return must.NotFail(types.NewDocument("insertedId", must.NotFail(ids.Get(0)))), nil
+}
+
+// insertMany implements `insertMany` action.
+func (s *Server) insertMany(ctx context.Context, req *types.Document) (*types.Document, error) {
+	db, coll, err := namespace(req)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := getParam[*types.Array](req, "documents", true)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := s.insert(ctx, db, coll, docs)
+	if err != nil {
+		return nil, err
+	}
+
+	return must.NotFail(types.NewDocument("insertedIds", ids)), nil
+}
+
+// updateOne implements `updateOne` action.
+func (s *Server) updateOne(ctx context.Context, req *types.Document) (*types.Document, error) {
+	db, coll, err := namespace(req)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := getParam[*types.Document](req, "filter", true)
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := getParam[*types.Document](req, "update", true)
+	if err != nil {
+		return nil, err
+	}
+
+	upsert, err := getParam[bool](req, "upsert", false)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.runCommand(ctx, db, must.NotFail(types.NewDocument(
+		"update", coll,
+		"updates", must.NotFail(types.NewArray(must.NotFail(types.NewDocument(
+			"q", filter,
+			"u", update,
+			"upsert", upsert,
+			"multi", false,
+		)))),
+	)))
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := resultField[int32](res, "n")
+	if err != nil {
+		return nil, err
+	}
+
+	modified, err := resultField[int32](res, "nModified")
+	if err != nil {
+		return nil, err
+	}
+
+	resDoc := must.NotFail(types.NewDocument(
+		"matchedCount", n,
+		"modifiedCount", modified,
+	))
+
+	if v, _ := res.Get("upserted"); v != nil {
+		if upserted, ok := v.(*types.Array); ok && upserted.Len() > 0 {
+			u, ok := must.NotFail(upserted.Get(0)).(*types.Document)
+			if !ok {
+				return nil, lazyerrors.New("invalid upserted")
+			}
+
+			id, err := u.Get("_id")
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			resDoc.Set("matchedCount", n-int32(upserted.Len()))
+			resDoc.Set("upsertedId", id)
+		}
+	}
+
+	return resDoc, nil
+}
+
+// deleteOne implements `deleteOne` action.
+func (s *Server) deleteOne(ctx context.Context, req *types.Document) (*types.Document, error) {
+	db, coll, err := namespace(req)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := getParam[*types.Document](req, "filter", true)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.runCommand(ctx, db, must.NotFail(types.NewDocument(
+		"delete", coll,
+		"deletes", must.NotFail(types.NewArray(must.NotFail(types.NewDocument(
+			"q", filter,
+			"limit", int32(1),
+		)))),
+	)))
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := resultField[int32](res, "n")
+	if err != nil {
+		return nil, err
+	}
+
+	return must.NotFail(types.NewDocument("deletedCount", n)), nil
+}
+
+// aggregate implements `aggregate` action.
+func (s *Server) aggregate(ctx context.Context, req *types.Document) (*types.Document, error) {
+	db, coll, err := namespace(req)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline, err := getParam[*types.Array](req, "pipeline", true)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.runCommand(ctx, db, must.NotFail(types.NewDocument(
+		"aggregate", coll,
+		"pipeline", pipeline,
+		"cursor", types.MakeDocument(0),
+	)))
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := s.cursorDocuments(ctx, db, coll, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return must.NotFail(types.NewDocument("documents", docs)), nil
+}
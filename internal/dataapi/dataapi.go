@@ -0,0 +1,278 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dataapi provides HTTP handler compatible with MongoDB Atlas Data API.
+//
+// Requests are authenticated with API keys and executed by the handler
+// the same way as wire protocol commands, bypassing backend authentication.
+package dataapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handler"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/ctxutil"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// maxRequestSize is the maximum size of the request body.
+const maxRequestSize = 16 * 1024 * 1024
+
+// Server represents Data API server.
+//
+//nolint:vet // for readability
+type Server struct {
+	opts *ListenOpts
+	lis  net.Listener
+	mux  *http.ServeMux
+	stdL *log.Logger
+
+	apiKeysM sync.RWMutex
+	apiKeys  []string
+}
+
+// ListenOpts represents [Listen] options.
+//
+//nolint:vet // for readability
+type ListenOpts struct {
+	TCPAddr string
+	L       *zap.Logger
+	Handler *handler.Handler
+	APIKeys []string // at least one is required; could be changed with [Server.SetAPIKeys]
+}
+
+// action represents Data API action implementation.
+type action func(s *Server, ctx context.Context, req *types.Document) (*types.Document, error)
+
+// actions contains all supported actions.
+var actions = map[string]action{
+	"aggregate":  (*Server).aggregate,
+	"deleteOne":  (*Server).deleteOne,
+	"find":       (*Server).find,
+	"findOne":    (*Server).findOne,
+	"insertMany": (*Server).insertMany,
+	"insertOne":  (*Server).insertOne,
+	"updateOne":  (*Server).updateOne,
+}
+
+// Listen creates a new Data API server and starts listener on the given TCP address.
+func Listen(opts *ListenOpts) (*Server, error) {
+	s, err := New(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.lis, err = net.Listen("tcp", opts.TCPAddr); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return s, nil
+}
+
+// New creates a new Data API server without a listener.
+//
+// It could be used as [http.Handler].
+func New(opts *ListenOpts) (*Server, error) {
+	must.NotBeZero(opts)
+
+	s := &Server{
+		opts: opts,
+		mux:  http.NewServeMux(),
+		stdL: must.NotFail(zap.NewStdLogAt(opts.L, zap.WarnLevel)),
+	}
+
+	if err := s.SetAPIKeys(opts.APIKeys); err != nil {
+		return nil, err
+	}
+
+	// the second pattern matches Atlas URLs, so clients could change only the host
+	s.mux.HandleFunc("POST /action/{action}", s.handleAction)
+	s.mux.HandleFunc("POST /app/{app}/endpoint/data/v1/action/{action}", s.handleAction)
+
+	return s, nil
+}
+
+// SetAPIKeys replaces accepted API keys.
+//
+// Requests that are already being handled are not affected.
+func (s *Server) SetAPIKeys(keys []string) error {
+	if len(keys) == 0 {
+		return errors.New("at least one API key is required")
+	}
+
+	s.apiKeysM.Lock()
+	s.apiKeys = slices.Clone(keys)
+	s.apiKeysM.Unlock()
+
+	return nil
+}
+
+// Serve runs Data API server until ctx is canceled.
+//
+// It exits when server is stopped and listener closed.
+func (s *Server) Serve(ctx context.Context) {
+	srv := http.Server{
+		Handler:  s,
+		ErrorLog: s.stdL,
+		BaseContext: func(_ net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	s.opts.L.Sugar().Infof("Starting Data API server on http://%s/action/ ...", s.lis.Addr())
+
+	go func() {
+		if err := srv.Serve(s.lis); !errors.Is(err, http.ErrServerClosed) {
+			s.opts.L.DPanic("Serve exited with unexpected error", zap.Error(err))
+		}
+	}()
+
+	<-ctx.Done()
+
+	// ctx is already canceled, but we want to inherit its values
+	stopCtx, stopCancel := ctxutil.WithDelay(ctx)
+	defer stopCancel(nil)
+
+	if err := srv.Shutdown(stopCtx); err != nil {
+		s.opts.L.DPanic("Shutdown exited with unexpected error", zap.Error(err))
+	}
+
+	if err := srv.Close(); err != nil {
+		s.opts.L.DPanic("Close exited with unexpected error", zap.Error(err))
+	}
+
+	s.opts.L.Sugar().Info("Data API server stopped.")
+}
+
+// Addr returns listener's address.
+func (s *Server) Addr() net.Addr {
+	return s.lis.Addr()
+}
+
+// ServeHTTP implements [http.Handler].
+func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.mux.ServeHTTP(rw, req)
+}
+
+// handleAction handles a single Data API request.
+func (s *Server) handleAction(rw http.ResponseWriter, req *http.Request) {
+	canonical := req.Header.Get("Accept") == contentTypeEJSON
+
+	if !s.authenticate(req) {
+		s.writeError(rw, canonical, http.StatusUnauthorized, "InvalidSession", "invalid API key")
+		return
+	}
+
+	name := req.PathValue("action")
+
+	a := actions[name]
+	if a == nil {
+		s.writeError(rw, canonical, http.StatusNotFound, "ActionNotFound", fmt.Sprintf("unknown action %q", name))
+		return
+	}
+
+	b, err := io.ReadAll(http.MaxBytesReader(rw, req.Body, maxRequestSize))
+	if err != nil {
+		s.writeError(rw, canonical, http.StatusBadRequest, "InvalidParameter", err.Error())
+		return
+	}
+
+	reqDoc, err := unmarshalEJSON(b)
+	if err != nil {
+		s.writeError(rw, canonical, http.StatusBadRequest, "InvalidParameter", err.Error())
+		return
+	}
+
+	resDoc, err := a(s, req.Context(), reqDoc)
+	if err != nil {
+		var ae *actionError
+		if errors.As(err, &ae) {
+			s.writeError(rw, canonical, http.StatusBadRequest, ae.code, ae.msg)
+			return
+		}
+
+		s.opts.L.Error("Data API action failed", zap.String("action", name), zap.Error(err))
+		s.writeError(rw, canonical, http.StatusInternalServerError, "InternalServerError", "internal server error")
+
+		return
+	}
+
+	s.write(rw, canonical, http.StatusOK, resDoc)
+}
+
+// authenticate returns true if the request contains a valid API key.
+func (s *Server) authenticate(req *http.Request) bool {
+	key := []byte(req.Header.Get("api-key"))
+	if len(key) == 0 {
+		return false
+	}
+
+	s.apiKeysM.RLock()
+	defer s.apiKeysM.RUnlock()
+
+	var ok bool
+
+	// check all keys to make timing independent of the matched key
+	for _, k := range s.apiKeys {
+		if subtle.ConstantTimeCompare(key, []byte(k)) == 1 {
+			ok = true
+		}
+	}
+
+	return ok
+}
+
+// write writes the given document as a response body.
+func (s *Server) write(rw http.ResponseWriter, canonical bool, code int, doc *types.Document) {
+	b, err := marshalEJSON(doc, canonical)
+	if err != nil {
+		s.opts.L.Error("Failed to marshal Data API response", zap.Error(err))
+		rw.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	contentType := contentTypeJSON
+	if canonical {
+		contentType = contentTypeEJSON
+	}
+
+	rw.Header().Set("Content-Type", contentType)
+	rw.WriteHeader(code)
+
+	if _, err = rw.Write(b); err != nil {
+		s.opts.L.Debug("Failed to write Data API response", zap.Error(err))
+	}
+}
+
+// writeError writes an error response.
+func (s *Server) writeError(rw http.ResponseWriter, canonical bool, code int, errCode, msg string) {
+	s.write(rw, canonical, code, must.NotFail(types.NewDocument(
+		"error", msg,
+		"error_code", errCode,
+	)))
+}
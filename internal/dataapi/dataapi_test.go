@@ -0,0 +1,242 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite"
+	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/handler"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/state"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// setup returns a test HTTP server and Data API server.
+func setup(t *testing.T) (*httptest.Server, *Server) {
+	t.Helper()
+
+	l := testutil.Logger(t)
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	b, err := sqlite.NewBackend(&sqlite.NewBackendParams{
+		URI:       testutil.TestSQLiteURI(t, ""),
+		L:         l.Named("sqlite"),
+		P:         sp,
+		BatchSize: 100,
+	})
+	require.NoError(t, err)
+	t.Cleanup(b.Close)
+
+	h, err := handler.New(&handler.NewOpts{
+		Backend:       b,
+		L:             l.Named("handler"),
+		ConnMetrics:   connmetrics.NewListenerMetrics().ConnMetrics,
+		StateProvider: sp,
+		BatchSize:     100,
+	})
+	require.NoError(t, err)
+	t.Cleanup(h.Close)
+
+	s, err := New(&ListenOpts{
+		L:       l.Named("dataapi"),
+		Handler: h,
+		APIKeys: []string{"key"},
+	})
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+
+	return ts, s
+}
+
+// request sends a Data API request and returns the response status code and body.
+func request(t *testing.T, ts *httptest.Server, action, key, body string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest("POST", ts.URL+"/action/"+action, strings.NewReader(body))
+	require.NoError(t, err)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("api-key", key)
+
+	res, err := ts.Client().Do(req)
+	require.NoError(t, err)
+
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	return res.StatusCode, string(b)
+}
+
+func TestDataAPI(t *testing.T) {
+	t.Parallel()
+
+	ts, _ := setup(t)
+
+	ns := `"dataSource":"ferretdb","database":"test","collection":"values",`
+
+	for _, tc := range []struct {
+		action   string
+		key      string
+		body     string
+		code     int
+		expected string
+	}{{
+		action:   "insertOne",
+		key:      "wrong",
+		body:     `{` + ns + `"document":{"_id":1}}`,
+		code:     http.StatusUnauthorized,
+		expected: `{"error":"invalid API key","error_code":"InvalidSession"}`,
+	}, {
+		action:   "insertOne",
+		body:     `{` + ns + `"document":{"_id":1,"v":{"$numberLong":"42"}}}`,
+		code:     http.StatusOK,
+		expected: `{"insertedId":1}`,
+	}, {
+		action:   "insertMany",
+		body:     `{` + ns + `"documents":[{"_id":2,"v":"foo"},{"_id":3,"v":"bar"}]}`,
+		code:     http.StatusOK,
+		expected: `{"insertedIds":[2,3]}`,
+	}, {
+		action:   "insertOne",
+		body:     `{` + ns + `"document":{"_id":1}}`,
+		code:     http.StatusBadRequest,
+		expected: `{"error":"E11000 duplicate key error collection: test.values index: _id_ dup key: { _id: 1 }","error_code":"DuplicateKey"}`,
+	}, {
+		action:   "findOne",
+		body:     `{` + ns + `"filter":{"_id":1}}`,
+		code:     http.StatusOK,
+		expected: `{"document":{"_id":1,"v":42}}`,
+	}, {
+		action:   "findOne",
+		body:     `{` + ns + `"filter":{"_id":4}}`,
+		code:     http.StatusOK,
+		expected: `{"document":null}`,
+	}, {
+		action:   "find",
+		body:     `{` + ns + `"filter":{"_id":{"$gt":1}},"sort":{"_id":-1}}`,
+		code:     http.StatusOK,
+		expected: `{"documents":[{"_id":3,"v":"bar"},{"_id":2,"v":"foo"}]}`,
+	}, {
+		action:   "updateOne",
+		body:     `{` + ns + `"filter":{"_id":2},"update":{"$set":{"v":"baz"}}}`,
+		code:     http.StatusOK,
+		expected: `{"matchedCount":1,"modifiedCount":1}`,
+	}, {
+		action:   "updateOne",
+		body:     `{` + ns + `"filter":{"_id":4},"update":{"$set":{"v":"qux"}},"upsert":true}`,
+		code:     http.StatusOK,
+		expected: `{"matchedCount":0,"modifiedCount":0,"upsertedId":4}`,
+	}, {
+		action:   "deleteOne",
+		body:     `{` + ns + `"filter":{"_id":3}}`,
+		code:     http.StatusOK,
+		expected: `{"deletedCount":1}`,
+	}, {
+		action:   "aggregate",
+		body:     `{` + ns + `"pipeline":[{"$match":{"_id":{"$gt":1}}},{"$sort":{"_id":1}}]}`,
+		code:     http.StatusOK,
+		expected: `{"documents":[{"_id":2,"v":"baz"},{"_id":4,"v":"qux"}]}`,
+	}, {
+		action:   "find",
+		body:     `{"database":"test"}`,
+		code:     http.StatusBadRequest,
+		expected: `{"error":"collection is required","error_code":"InvalidParameter"}`,
+	}, {
+		action:   "dropDatabase",
+		body:     `{}`,
+		code:     http.StatusNotFound,
+		expected: `{"error":"unknown action \"dropDatabase\"","error_code":"ActionNotFound"}`,
+	}} {
+		key := tc.key
+		if key == "" {
+			key = "key"
+		}
+
+		// requests depend on each other, so subtests are not used
+		code, body := request(t, ts, tc.action, key, tc.body)
+		assert.Equal(t, tc.code, code, tc.action)
+		assert.JSONEq(t, tc.expected, body, tc.action)
+	}
+}
+
+func TestErrorFromDocument(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		doc      *types.Document
+		expected error
+	}{
+		"Command": {
+			doc:      must.NotFail(types.NewDocument("ok", float64(0), "errmsg", "foo", "code", int32(2), "codeName", "BadValue")),
+			expected: &actionError{code: "BadValue", msg: "foo"},
+		},
+		"WriteErrors": {
+			doc: must.NotFail(types.NewDocument("writeErrors", must.NotFail(types.NewArray(
+				must.NotFail(types.NewDocument("index", int32(0), "code", int32(11000), "errmsg", "bar")),
+			)))),
+			expected: &actionError{code: "DuplicateKey", msg: "bar"},
+		},
+		"NoErrmsg": {
+			doc:      must.NotFail(types.NewDocument("ok", float64(0), "code", int32(2))),
+			expected: &actionError{code: "BadValue"},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, errorFromDocument(tc.doc))
+		})
+	}
+}
+
+func TestDataAPISetAPIKeys(t *testing.T) {
+	t.Parallel()
+
+	ts, s := setup(t)
+
+	body := `{"dataSource":"ferretdb","database":"test","collection":"keys","filter":{}}`
+	unauthorized := `{"error":"invalid API key","error_code":"InvalidSession"}`
+
+	code, _ := request(t, ts, "find", "key", body)
+	assert.Equal(t, http.StatusOK, code)
+
+	require.EqualError(t, s.SetAPIKeys(nil), "at least one API key is required")
+
+	require.NoError(t, s.SetAPIKeys([]string{"new1", "new2"}))
+
+	code, res := request(t, ts, "find", "key", body)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.JSONEq(t, unauthorized, res)
+
+	code, _ = request(t, ts, "find", "new2", body)
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,53 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataapi
+
+import (
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// Content types of request and response bodies.
+const (
+	contentTypeJSON  = "application/json"  // relaxed Extended JSON
+	contentTypeEJSON = "application/ejson" // canonical Extended JSON
+)
+
+// unmarshalEJSON decodes a document from canonical or relaxed Extended JSON.
+//
+// Returned errors are [*actionError].
+func unmarshalEJSON(b []byte) (*types.Document, error) {
+	v, err := types.UnmarshalExtJSON(b)
+	if err != nil {
+		return nil, newActionError("InvalidParameter", "invalid request body: %s", err)
+	}
+
+	doc, ok := v.(*types.Document)
+	if !ok {
+		return nil, newActionError("InvalidParameter", "invalid request body: expected object, got %s", types.FormatAnyValue(v))
+	}
+
+	return doc, nil
+}
+
+// marshalEJSON encodes a document to canonical or relaxed Extended JSON.
+func marshalEJSON(doc *types.Document, canonical bool) ([]byte, error) {
+	b, err := types.MarshalExtJSON(doc, canonical)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// apiVersion1 contains commands that are part of Stable API Version 1,
+// with their fields that are not.
+//
+// See https://www.mongodb.com/docs/manual/reference/stable-api-reference/.
+var apiVersion1 = map[string][]string{
+	// sorted alphabetically
+	"abortTransaction":  nil,
+	"aggregate":         nil,
+	"authenticate":      nil,
+	"collMod":           nil,
+	"commitTransaction": nil,
+	"count":             nil,
+	"create": {
+		"autoIndexId", "flags", "idIndex", "indexOptionDefaults", "recordPreImages", "storageEngine", "temp",
+	},
+	"createIndexes":   nil,
+	"delete":          nil,
+	"distinct":        nil,
+	"drop":            nil,
+	"dropDatabase":    nil,
+	"dropIndexes":     nil,
+	"endSessions":     nil,
+	"find":            {"max", "min", "returnKey", "showRecordId"},
+	"findAndModify":   nil,
+	"getMore":         nil,
+	"hello":           nil,
+	"insert":          nil,
+	"killCursors":     nil,
+	"listCollections": nil,
+	"listDatabases":   nil,
+	"listIndexes":     nil,
+	"ping":            nil,
+	"refreshSessions": nil,
+	"saslContinue":    nil,
+	"saslStart":       nil,
+	"update":          nil,
+	// please keep sorted alphabetically
+}
+
+// checkAPIVersion validates Stable API parameters of the given message.
+//
+// If apiStrict is set, it returns an error for commands and their fields
+// that are not part of API Version 1.
+// There are no deprecated commands in API Version 1,
+// so apiDeprecationErrors is only validated.
+func checkAPIVersion(msg *wire.OpMsg) error {
+	spec, _ := msg.RawSections()
+
+	document, err := spec.Convert()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	var version string
+	var strict bool
+
+	if version, err = common.GetOptionalParam(document, "apiVersion", version); err != nil {
+		return err
+	}
+
+	if strict, err = common.GetOptionalParam(document, "apiStrict", strict); err != nil {
+		return err
+	}
+
+	if _, err = common.GetOptionalParam(document, "apiDeprecationErrors", false); err != nil {
+		return err
+	}
+
+	if version == "" {
+		for _, k := range []string{"apiStrict", "apiDeprecationErrors"} {
+			if document.Has(k) {
+				return handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrAPIVersionError,
+					fmt.Sprintf("Provided %s without passing apiVersion", k),
+					command,
+				)
+			}
+		}
+
+		return nil
+	}
+
+	if version != "1" {
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrAPIVersionError,
+			`API version must be "1"`,
+			command,
+		)
+	}
+
+	if !strict {
+		return nil
+	}
+
+	unstable, ok := apiVersion1[command]
+	if !ok {
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrAPIStrictError,
+			fmt.Sprintf(
+				"Provided apiStrict:true, but the command %s is not in API Version 1. "+
+					"Information on supported commands and migrations in API Version 1 can be found at "+
+					"https://dochub.mongodb.org/core/manual-versioned-api",
+				command,
+			),
+			command,
+		)
+	}
+
+	for _, k := range document.Keys() {
+		if slices.Contains(unstable, k) {
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrAPIStrictError,
+				fmt.Sprintf("BSON field '%s.%s' is not allowed with apiStrict:true.", command, k),
+				command,
+			)
+		}
+	}
+
+	return nil
+}
@@ -73,7 +73,7 @@ func (h *Handler) CmdQuery(ctx context.Context, query *wire.OpQuery) (*wire.OpRe
 
 		dbName, err := common.GetRequiredParam[string](authDoc, "db")
 		if err != nil {
-			h.L.Debug("No `db` in `speculativeAuthenticate`", zap.Error(err))
+			h.la.Debug("No `db` in `speculativeAuthenticate`", zap.Error(err))
 
 			opReply.SetDocument(reply)
 
@@ -82,7 +82,7 @@ func (h *Handler) CmdQuery(ctx context.Context, query *wire.OpQuery) (*wire.OpRe
 
 		speculativeAuthenticate, err := h.saslStart(ctx, dbName, authDoc)
 		if err != nil {
-			h.L.Debug("Speculative authentication failed", zap.Error(err))
+			h.la.Debug("Speculative authentication failed", zap.Error(err))
 
 			// unsuccessful speculative authentication leave `speculativeAuthenticate` field unset
 			// and let `saslStart` return an error
@@ -91,7 +91,7 @@ func (h *Handler) CmdQuery(ctx context.Context, query *wire.OpQuery) (*wire.OpRe
 			return &opReply, nil
 		}
 
-		h.L.Debug("Speculative authentication passed")
+		h.la.Debug("Speculative authentication passed")
 
 		reply.Set("speculativeAuthenticate", speculativeAuthenticate)
 
@@ -16,11 +16,13 @@ package handler
 
 import (
 	"context"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
@@ -29,6 +31,13 @@ type command struct {
 	// anonymous indicates that the command does not require authentication.
 	anonymous bool
 
+	// operation indicates that the command is a query or write operation
+	// limited by its maxTimeMS option or the default operation timeout.
+	operation bool
+
+	// maxTimeMS indicates that the operation handles maxTimeMS and the default operation timeout itself.
+	maxTimeMS bool
+
 	// Handler processes this command.
 	//
 	// The passed context is canceled when the client disconnects.
@@ -44,8 +53,13 @@ func (h *Handler) initCommands() {
 	h.commands = map[string]*command{
 		// sorted alphabetically
 		"aggregate": {
-			Handler: h.MsgAggregate,
-			Help:    "Returns aggregated data.",
+			Handler:   h.MsgAggregate,
+			maxTimeMS: true,
+			Help:      "Returns aggregated data.",
+		},
+		"applyOps": {
+			Handler: h.MsgApplyOps,
+			Help:    "Applies a subset of OpLog entries.",
 		},
 		"buildInfo": {
 			Handler:   h.MsgBuildInfo,
@@ -76,8 +90,9 @@ func (h *Handler) initCommands() {
 				"specifically the state of authenticated users and their available permissions.",
 		},
 		"count": {
-			Handler: h.MsgCount,
-			Help:    "Returns the count of documents that's matched by the query.",
+			Handler:   h.MsgCount,
+			operation: true,
+			Help:      "Returns the count of documents that's matched by the query.",
 		},
 		"create": {
 			Handler: h.MsgCreate,
@@ -108,12 +123,14 @@ func (h *Handler) initCommands() {
 			Help:    "Returns error for debugging.",
 		},
 		"delete": {
-			Handler: h.MsgDelete,
-			Help:    "Deletes documents matched by the query.",
+			Handler:   h.MsgDelete,
+			operation: true,
+			Help:      "Deletes documents matched by the query.",
 		},
 		"distinct": {
-			Handler: h.MsgDistinct,
-			Help:    "Returns an array of distinct values for the given field.",
+			Handler:   h.MsgDistinct,
+			operation: true,
+			Help:      "Returns an array of distinct values for the given field.",
 		},
 		"drop": {
 			Handler: h.MsgDrop,
@@ -127,21 +144,28 @@ func (h *Handler) initCommands() {
 			Handler: h.MsgDropIndexes,
 			Help:    "Drops indexes on a collection.",
 		},
+		"enableSharding": {
+			Handler: h.MsgEnableSharding,
+			Help:    "Enables sharding on a database (emulated).",
+		},
 		"explain": {
 			Handler: h.MsgExplain,
 			Help:    "Returns the execution plan.",
 		},
 		"find": {
-			Handler: h.MsgFind,
-			Help:    "Returns documents matched by the query.",
+			Handler:   h.MsgFind,
+			maxTimeMS: true,
+			Help:      "Returns documents matched by the query.",
 		},
 		"findAndModify": {
-			Handler: h.MsgFindAndModify,
-			Help:    "Updates or deletes, and returns a document matched by the query.",
+			Handler:   h.MsgFindAndModify,
+			maxTimeMS: true,
+			Help:      "Updates or deletes, and returns a document matched by the query.",
 		},
 		"findandmodify": { // old lowercase variant
-			Handler: h.MsgFindAndModify,
-			Help:    "", // hidden
+			Handler:   h.MsgFindAndModify,
+			maxTimeMS: true,
+			Help:      "", // hidden
 		},
 		"getCmdLineOpts": {
 			Handler: h.MsgGetCmdLineOpts,
@@ -156,8 +180,9 @@ func (h *Handler) initCommands() {
 			Help:    "Returns the most recent logged events from memory.",
 		},
 		"getMore": {
-			Handler: h.MsgGetMore,
-			Help:    "Returns the next batch of documents from a cursor.",
+			Handler:   h.MsgGetMore,
+			maxTimeMS: true,
+			Help:      "Returns the next batch of documents from a cursor.",
 		},
 		"getParameter": {
 			Handler: h.MsgGetParameter,
@@ -173,8 +198,9 @@ func (h *Handler) initCommands() {
 			Help:    "Returns a summary of the system information.",
 		},
 		"insert": {
-			Handler: h.MsgInsert,
-			Help:    "Inserts documents into the database.",
+			Handler:   h.MsgInsert,
+			operation: true,
+			Help:      "Inserts documents into the database.",
 		},
 		"isMaster": {
 			Handler:   h.MsgIsMaster,
@@ -190,6 +216,10 @@ func (h *Handler) initCommands() {
 			Handler: h.MsgKillCursors,
 			Help:    "Closes server cursors.",
 		},
+		"killOp": {
+			Handler: h.MsgKillOp,
+			Help:    "Terminates an operation as specified by the operation ID.",
+		},
 		"listCollections": {
 			Handler: h.MsgListCollections,
 			Help:    "Returns the information of the collections and views in the database.",
@@ -206,16 +236,42 @@ func (h *Handler) initCommands() {
 			Handler: h.MsgListIndexes,
 			Help:    "Returns a summary of indexes of the specified collection.",
 		},
+		"listShards": {
+			Handler: h.MsgListShards,
+			Help:    "Returns a list of shards (emulated).",
+		},
 		"logout": {
 			Handler:   h.MsgLogout,
 			anonymous: true,
 			Help:      "Logs out from the current session.",
 		},
+		"mapReduce": {
+			Handler:   h.MsgMapReduce,
+			operation: true,
+			Help:      "Runs map-reduce aggregation using aggregation expressions.",
+		},
+		"mapreduce": { // old lowercase variant
+			Handler:   h.MsgMapReduce,
+			operation: true,
+			Help:      "", // hidden
+		},
 		"ping": {
 			Handler:   h.MsgPing,
 			anonymous: true,
 			Help:      "Returns a pong response.",
 		},
+		"planCacheClear": {
+			Handler: h.MsgPlanCacheClear,
+			Help:    "Removes cached query plans for a collection.",
+		},
+		"reIndex": {
+			Handler: h.MsgReIndex,
+			Help:    "Rebuilds indexes on a collection.",
+		},
+		"reloadConfiguration": {
+			Handler: h.MsgReloadConfiguration,
+			Help:    "Reloads runtime-changeable configuration settings.",
+		},
 		"renameCollection": {
 			Handler: h.MsgRenameCollection,
 			Help:    "Changes the name of an existing collection.",
@@ -238,14 +294,31 @@ func (h *Handler) initCommands() {
 			Handler: h.MsgSetFreeMonitoring,
 			Help:    "Toggles free monitoring.",
 		},
+		"setParameter": {
+			Handler: h.MsgSetParameter,
+			Help:    "Sets the value of the parameter.",
+		},
+		"shardCollection": {
+			Handler: h.MsgShardCollection,
+			Help:    "Shards a collection (emulated).",
+		},
+		"top": {
+			Handler: h.MsgTop,
+			Help:    "Returns usage statistics for each collection.",
+		},
 		"update": {
-			Handler: h.MsgUpdate,
-			Help:    "Updates documents that are matched by the query.",
+			Handler:   h.MsgUpdate,
+			operation: true,
+			Help:      "Updates documents that are matched by the query.",
 		},
 		"validate": {
 			Handler: h.MsgValidate,
 			Help:    "Validates collection.",
 		},
+		"validateDBMetadata": {
+			Handler: h.MsgValidateDBMetadata,
+			Help:    "Checks collection options and index specs for API Version compatibility.",
+		},
 		"whatsmyuri": {
 			Handler:   h.MsgWhatsMyURI,
 			anonymous: true,
@@ -268,6 +341,14 @@ func (h *Handler) initCommands() {
 			Handler: h.MsgDropUser,
 			Help:    "Drops user.",
 		}
+		h.commands["exportAuth"] = &command{
+			Handler: h.MsgExportAuth,
+			Help:    "Exports users. Roles and privileges are not implemented.",
+		}
+		h.commands["importAuth"] = &command{
+			Handler: h.MsgImportAuth,
+			Help:    "Replaces users with exported ones. Roles and privileges are not implemented.",
+		}
 		h.commands["updateUser"] = &command{
 			Handler: h.MsgUpdateUser,
 			Help:    "Updates user.",
@@ -280,18 +361,71 @@ func (h *Handler) initCommands() {
 	}
 
 	for name, cmd := range h.commands {
+		if cmd.operation && !cmd.maxTimeMS {
+			cmdHandler := h.commands[name].Handler
+
+			h.commands[name].Handler = func(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+				maxTimeMS := h.maxTimeMS(operationMaxTimeMS(msg))
+				if maxTimeMS == 0 {
+					return cmdHandler(ctx, msg)
+				}
+
+				ctx, cancel := context.WithTimeout(ctx, time.Duration(maxTimeMS)*time.Millisecond)
+				defer cancel()
+
+				res, err := cmdHandler(ctx, msg)
+				if err != nil {
+					return nil, handleOperationTimeoutError(ctx, err, name)
+				}
+
+				return res, nil
+			}
+		}
+
 		if h.EnableNewAuth && !cmd.anonymous {
 			cmdHandler := h.commands[name].Handler
 
 			h.commands[name].Handler = func(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-				if err := checkSCRAMConversation(ctx, h.L); err != nil {
+				if err := checkSCRAMConversation(ctx, h.la); err != nil {
 					return nil, err
 				}
 
 				return cmdHandler(ctx, msg)
 			}
 		}
+
+		cmdHandler := h.commands[name].Handler
+
+		h.commands[name].Handler = func(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+			if err := checkAPIVersion(msg); err != nil {
+				return nil, err
+			}
+
+			return cmdHandler(ctx, msg)
+		}
+	}
+}
+
+// operationMaxTimeMS returns the maxTimeMS option of the command, or 0 if it is not set.
+//
+// Invalid values are ignored there and reported by the command's own parameters validation.
+func operationMaxTimeMS(msg *wire.OpMsg) int64 {
+	document, err := msg.Document()
+	if err != nil {
+		return 0
 	}
+
+	v, _ := document.Get("maxTimeMS")
+	if v == nil {
+		return 0
+	}
+
+	maxTimeMS, err := handlerparams.GetWholeNumberParam(v)
+	if err != nil || maxTimeMS < 0 {
+		return 0
+	}
+
+	return maxTimeMS
 }
 
 // checkSCRAMConversation returns error if SCRAM conversation is not valid.
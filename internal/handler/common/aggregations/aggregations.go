@@ -27,3 +27,11 @@ type Stage interface {
 	// Process applies an aggregate stage on documents from iterator.
 	Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error)
 }
+
+// ExplainStage is implemented by stages that report execution statistics for explain.
+type ExplainStage interface {
+	Stage
+
+	// Explain returns execution statistics of the last Process call.
+	Explain() *types.Document
+}
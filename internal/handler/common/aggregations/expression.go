@@ -15,7 +15,6 @@
 package aggregations
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/FerretDB/FerretDB/internal/handler/commonpath"
@@ -176,7 +175,7 @@ func (e *Expression) Evaluate(doc *types.Document) (any, error) {
 			return must.NotFail(types.NewArray()), nil
 		}
 
-		return nil, fmt.Errorf("no document found under %s path", path)
+		return nil, lazyerrors.Errorf("no document found under %s path", path)
 	}
 
 	if len(vals) == 1 && !isArrayField {
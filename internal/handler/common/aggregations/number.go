@@ -17,12 +17,15 @@ package aggregations
 import (
 	"math"
 	"math/big"
+
+	"github.com/FerretDB/FerretDB/internal/types"
 )
 
 // SumNumbers accumulate numbers and returns the result of summation.
 // The result has the same type as the input, except when the result
 // cannot be presented accurately. Then int32 is converted to int64,
-// and int64 is converted to float64. It ignores non-number values.
+// and int64 is converted to float64. If any value is Decimal128,
+// the result is Decimal128. It ignores non-number values.
 // For empty `vs`, it returns int32(0).
 // This should only be used for aggregation, aggregation does not return
 // error on overflow.
@@ -34,7 +37,10 @@ func SumNumbers(vs ...any) any {
 	// TODO https://github.com/FerretDB/FerretDB/issues/2300
 	var floatSum float64
 
-	var hasFloat64, hasInt64 bool
+	// accumulate all numbers as Decimal128 in case there is at least one Decimal128 value
+	decimalSum := types.NewDecimal128FromInt64(0)
+
+	var hasFloat64, hasInt64, hasDecimal128 bool
 
 	for _, v := range vs {
 		switch v := v.(type) {
@@ -42,17 +48,28 @@ func SumNumbers(vs ...any) any {
 			hasFloat64 = true
 
 			floatSum = floatSum + v
+			decimalSum = decimalSum.Add(types.NewDecimal128FromFloat64(v))
 		case int32:
 			intSum.Add(intSum, big.NewInt(int64(v)))
+			decimalSum = decimalSum.Add(types.NewDecimal128FromInt64(int64(v)))
 		case int64:
 			hasInt64 = true
 
 			intSum.Add(intSum, big.NewInt(v))
+			decimalSum = decimalSum.Add(types.NewDecimal128FromInt64(v))
+		case types.Decimal128:
+			hasDecimal128 = true
+
+			decimalSum = decimalSum.Add(v)
 		default:
 			// ignore non-number
 		}
 	}
 
+	if hasDecimal128 {
+		return decimalSum
+	}
+
 	if hasFloat64 || !intSum.IsInt64() {
 		// ignore accuracy because there is no rounding from int64.
 		intAsFloat, _ := new(big.Float).SetInt(intSum).Float64()
@@ -0,0 +1,284 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accumulators
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// accumulator represents $accumulator aggregation operator.
+//
+// There is no JavaScript engine, so only the restricted variant with lang "expression" is supported.
+// In that variant, init, accumulate, merge, and finalize are aggregation expressions
+// evaluated against a document with the following fields instead of JavaScript function arguments:
+//   - init: arg0, arg1, ... for initArgs;
+//   - accumulate: state, and arg0, arg1, ... for accumulateArgs evaluated against the input document;
+//   - merge: state1 and state2;
+//   - finalize: state.
+//
+// For example, `{$sum: ["$state", "$arg0"]}` accumulates the sum of the first argument.
+type accumulator struct {
+	init           any
+	initArgs       []any
+	accumulate     any
+	accumulateArgs []any
+	merge          any
+	finalize       any // nil if not set
+}
+
+// newAccumulator creates a new $accumulator aggregation operator.
+func newAccumulator(args ...any) (Accumulator, error) {
+	var spec *types.Document
+	if len(args) == 1 {
+		spec, _ = args[0].(*types.Document)
+	}
+
+	if spec == nil {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFailedToParse,
+			fmt.Sprintf("$accumulator expects an object as an argument: found %s", types.FormatAnyValue(args)),
+			"$accumulator (accumulator)",
+		)
+	}
+
+	res := new(accumulator)
+
+	var lang string
+
+	for _, k := range spec.Keys() {
+		v := must.NotFail(spec.Get(k))
+
+		switch k {
+		case "init":
+			res.init = v
+		case "initArgs":
+			arr, ok := v.(*types.Array)
+			if !ok {
+				return nil, accumulatorArgsError(k, v)
+			}
+
+			res.initArgs = must.NotFail(iterator.ConsumeValues(arr.Iterator()))
+		case "accumulate":
+			res.accumulate = v
+		case "accumulateArgs":
+			arr, ok := v.(*types.Array)
+			if !ok {
+				return nil, accumulatorArgsError(k, v)
+			}
+
+			res.accumulateArgs = must.NotFail(iterator.ConsumeValues(arr.Iterator()))
+		case "merge":
+			res.merge = v
+		case "finalize":
+			res.finalize = v
+		case "lang":
+			s, ok := v.(string)
+			if !ok {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrFailedToParse,
+					fmt.Sprintf("$accumulator lang must be a string: found %s", types.FormatAnyValue(v)),
+					"$accumulator (accumulator)",
+				)
+			}
+
+			lang = s
+		default:
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParse,
+				fmt.Sprintf("$accumulator got an unrecognized field: %s", k),
+				"$accumulator (accumulator)",
+			)
+		}
+	}
+
+	for _, k := range []string{"init", "accumulate", "accumulateArgs", "merge", "lang"} {
+		if !spec.Has(k) {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParse,
+				fmt.Sprintf("$accumulator missing required argument '%s'", k),
+				"$accumulator (accumulator)",
+			)
+		}
+	}
+
+	switch lang {
+	case "expression":
+	case "js":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrNotImplemented,
+			"$accumulator with lang 'js' is not implemented yet; use lang 'expression' with aggregation expressions",
+			"$accumulator (accumulator)",
+		)
+	default:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFailedToParse,
+			fmt.Sprintf("$accumulator lang must be 'expression' or 'js': found %q", lang),
+			"$accumulator (accumulator)",
+		)
+	}
+
+	return res, nil
+}
+
+// accumulatorArgsError returns an error for initArgs or accumulateArgs that is not an array.
+func accumulatorArgsError(field string, v any) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(
+		handlererrors.ErrTypeMismatch,
+		fmt.Sprintf("$accumulator %s must be an array: found %s", field, types.FormatAnyValue(v)),
+		"$accumulator (accumulator)",
+	)
+}
+
+// Accumulate implements Accumulator interface.
+//
+// Since documents of a group are never split, merge is validated but not used.
+func (a *accumulator) Accumulate(iter types.DocumentsIterator) (any, error) {
+	defer iter.Close()
+
+	args, err := evaluateArgs(a.initArgs, new(types.Document))
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := evaluateAccumulatorExpression(a.init, args)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		_, doc, err := iter.Next()
+		if err != nil {
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		if args, err = evaluateArgs(a.accumulateArgs, doc); err != nil {
+			return nil, err
+		}
+
+		args.Set("state", state)
+
+		if state, err = evaluateAccumulatorExpression(a.accumulate, args); err != nil {
+			return nil, err
+		}
+	}
+
+	if a.finalize == nil {
+		return state, nil
+	}
+
+	return evaluateAccumulatorExpression(a.finalize, must.NotFail(types.NewDocument("state", state)))
+}
+
+// evaluateArgs evaluates given arguments against the document and returns them as arg0, arg1, etc. fields.
+func evaluateArgs(args []any, doc *types.Document) (*types.Document, error) {
+	res := types.MakeDocument(len(args) + 1)
+
+	for i, arg := range args {
+		v, err := evaluateAccumulatorExpression(arg, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Set("arg"+strconv.Itoa(i), v)
+	}
+
+	return res, nil
+}
+
+// evaluateAccumulatorExpression recursively evaluates field paths, operators, documents and arrays
+// against the given document. Other values are returned as is.
+//
+// Non-existent field paths are evaluated to null.
+func evaluateAccumulatorExpression(expr any, doc *types.Document) (any, error) {
+	switch expr := expr.(type) {
+	case *types.Document:
+		if operators.IsOperator(expr) {
+			op, err := operators.NewOperator(expr)
+			if err != nil {
+				return nil, err
+			}
+
+			return op.Process(doc)
+		}
+
+		res := types.MakeDocument(expr.Len())
+
+		for _, k := range expr.Keys() {
+			v, err := evaluateAccumulatorExpression(must.NotFail(expr.Get(k)), doc)
+			if err != nil {
+				return nil, err
+			}
+
+			res.Set(k, v)
+		}
+
+		return res, nil
+
+	case *types.Array:
+		res := types.MakeArray(expr.Len())
+
+		for _, e := range must.NotFail(iterator.ConsumeValues(expr.Iterator())) {
+			v, err := evaluateAccumulatorExpression(e, doc)
+			if err != nil {
+				return nil, err
+			}
+
+			res.Append(v)
+		}
+
+		return res, nil
+
+	case string:
+		expression, err := aggregations.NewExpression(expr, nil)
+
+		var exprErr *aggregations.ExpressionError
+		if errors.As(err, &exprErr) && exprErr.Code() == aggregations.ErrNotExpression {
+			return expr, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := expression.Evaluate(doc)
+		if err != nil {
+			return types.Null, nil
+		}
+
+		return v, nil
+
+	default:
+		return expr, nil
+	}
+}
+
+// check interfaces
+var (
+	_ Accumulator = (*accumulator)(nil)
+)
@@ -103,7 +103,11 @@ func NewAccumulator(stage, key string, value any) (Accumulator, error) {
 // Accumulators maps all aggregation accumulators.
 var Accumulators = map[string]newAccumulatorFunc{
 	// sorted alphabetically
-	"$count": newCount,
-	"$sum":   newSum,
+	"$accumulator": newAccumulator,
+	"$addToSet":    newAddToSet,
+	"$count":       newCount,
+	"$stdDevPop":   newStdDevPop,
+	"$stdDevSamp":  newStdDevSamp,
+	"$sum":         newSum,
 	// please keep sorted alphabetically
 }
@@ -0,0 +1,150 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accumulators
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// addToSet represents $addToSet aggregation operator.
+type addToSet struct {
+	expression *aggregations.Expression
+	operator   operators.Operator
+	value      any
+}
+
+// newAddToSet creates a new $addToSet aggregation operator.
+func newAddToSet(args ...any) (Accumulator, error) {
+	if len(args) != 1 {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageGroupUnaryOperator,
+			"The $addToSet accumulator is a unary operator",
+			"$addToSet (accumulator)",
+		)
+	}
+
+	accumulator := new(addToSet)
+
+	switch arg := args[0].(type) {
+	case *types.Document:
+		if !operators.IsOperator(arg) {
+			accumulator.value = arg
+			break
+		}
+
+		op, err := operators.NewOperator(arg)
+		if err != nil {
+			var opErr operators.OperatorError
+			if !errors.As(err, &opErr) {
+				return nil, lazyerrors.Error(err)
+			}
+
+			return nil, opErr
+		}
+
+		accumulator.operator = op
+
+	case string:
+		if !strings.HasPrefix(arg, "$") {
+			accumulator.value = arg
+			break
+		}
+
+		expression, err := aggregations.NewExpression(arg, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		accumulator.expression = expression
+
+	default:
+		accumulator.value = arg
+	}
+
+	return accumulator, nil
+}
+
+// Accumulate implements Accumulator interface.
+//
+// Missing values are not added to the set.
+func (a *addToSet) Accumulate(iter types.DocumentsIterator) (any, error) {
+	defer iter.Close()
+
+	res := types.MakeArray(0)
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		var v any
+
+		switch {
+		case a.operator != nil:
+			if v, err = a.operator.Process(doc); err != nil {
+				return nil, err
+			}
+
+		case a.expression != nil:
+			if v, err = a.expression.Evaluate(doc); err != nil {
+				continue
+			}
+
+		default:
+			v = a.value
+		}
+
+		if !containsEqual(res, v) {
+			res.Append(v)
+		}
+	}
+
+	return res, nil
+}
+
+// containsEqual returns true if the array contains a value equal to the given one.
+func containsEqual(arr *types.Array, v any) bool {
+	iter := arr.Iterator()
+	defer iter.Close()
+
+	for {
+		_, elem, err := iter.Next()
+		if err != nil {
+			return false
+		}
+
+		if types.CompareForAggregation(elem, v) == types.Equal {
+			return true
+		}
+	}
+}
+
+// check interfaces
+var (
+	_ Accumulator = (*addToSet)(nil)
+)
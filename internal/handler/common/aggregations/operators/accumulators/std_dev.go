@@ -0,0 +1,175 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accumulators
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// stdDev represents $stdDevPop and $stdDevSamp aggregation operators.
+type stdDev struct {
+	expression *aggregations.Expression
+	operator   operators.Operator
+	value      any
+	sample     bool
+}
+
+// newStdDevPop creates a new $stdDevPop aggregation operator.
+func newStdDevPop(args ...any) (Accumulator, error) {
+	return newStdDev("$stdDevPop", false, args)
+}
+
+// newStdDevSamp creates a new $stdDevSamp aggregation operator.
+func newStdDevSamp(args ...any) (Accumulator, error) {
+	return newStdDev("$stdDevSamp", true, args)
+}
+
+// newStdDev creates a new standard deviation aggregation operator with the given name.
+func newStdDev(name string, sample bool, args []any) (Accumulator, error) {
+	if len(args) != 1 {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageGroupUnaryOperator,
+			"The "+name+" accumulator is a unary operator",
+			name+" (accumulator)",
+		)
+	}
+
+	accumulator := &stdDev{
+		sample: sample,
+	}
+
+	switch arg := args[0].(type) {
+	case *types.Document:
+		if !operators.IsOperator(arg) {
+			accumulator.value = arg
+			break
+		}
+
+		op, err := operators.NewOperator(arg)
+		if err != nil {
+			var opErr operators.OperatorError
+			if !errors.As(err, &opErr) {
+				return nil, lazyerrors.Error(err)
+			}
+
+			return nil, opErr
+		}
+
+		accumulator.operator = op
+
+	case string:
+		if !strings.HasPrefix(arg, "$") {
+			accumulator.value = arg
+			break
+		}
+
+		expression, err := aggregations.NewExpression(arg, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		accumulator.expression = expression
+
+	default:
+		accumulator.value = arg
+	}
+
+	return accumulator, nil
+}
+
+// Accumulate implements Accumulator interface.
+//
+// Non-numeric and missing values are ignored.
+// It returns null if there are no numeric values (or less than two for $stdDevSamp).
+//
+// Welford's online algorithm is used, so values are not buffered and the result is numerically stable.
+func (s *stdDev) Accumulate(iter types.DocumentsIterator) (any, error) {
+	defer iter.Close()
+
+	var count int64
+	var mean, m2 float64
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		var v any
+
+		switch {
+		case s.operator != nil:
+			if v, err = s.operator.Process(doc); err != nil {
+				return nil, err
+			}
+
+		case s.expression != nil:
+			if v, err = s.expression.Evaluate(doc); err != nil {
+				continue
+			}
+
+		default:
+			v = s.value
+		}
+
+		var f float64
+
+		switch v := v.(type) {
+		case float64:
+			f = v
+		case int32:
+			f = float64(v)
+		case int64:
+			f = float64(v)
+		case types.Decimal128:
+			f = v.Float64()
+		default:
+			continue
+		}
+
+		count++
+
+		delta := f - mean
+		mean += delta / float64(count)
+		m2 += delta * (f - mean)
+	}
+
+	switch {
+	case count == 0, s.sample && count == 1:
+		return types.Null, nil
+	case s.sample:
+		return math.Sqrt(m2 / float64(count-1)), nil
+	default:
+		return math.Sqrt(m2 / float64(count)), nil
+	}
+}
+
+// check interfaces
+var (
+	_ Accumulator = (*stdDev)(nil)
+)
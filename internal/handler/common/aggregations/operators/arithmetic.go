@@ -0,0 +1,119 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"math/big"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// isNumber returns true if the evaluated value is int32, int64, float64 or Decimal128.
+func isNumber(v any) bool {
+	switch v.(type) {
+	case float64, int32, int64, types.Decimal128:
+		return true
+	default:
+		return false
+	}
+}
+
+// isZero returns true if the number is zero.
+func isZero(v any) bool {
+	switch v := v.(type) {
+	case float64:
+		return v == 0
+	case int32:
+		return v == 0
+	case int64:
+		return v == 0
+	case types.Decimal128:
+		return v.IsZero()
+	default:
+		return false
+	}
+}
+
+// toFloat64 returns float64 value of the number.
+func toFloat64(v any) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case types.Decimal128:
+		return v.Float64()
+	default:
+		panic("operators.toFloat64: not a number")
+	}
+}
+
+// toInt64 returns int64 value of the integer number.
+func toInt64(v any) int64 {
+	switch v := v.(type) {
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	default:
+		panic("operators.toInt64: not an integer")
+	}
+}
+
+// toDecimal128 returns Decimal128 value of the number.
+func toDecimal128(v any) types.Decimal128 {
+	switch v := v.(type) {
+	case float64:
+		return types.NewDecimal128FromFloat64(v)
+	case int32:
+		return types.NewDecimal128FromInt64(int64(v))
+	case int64:
+		return types.NewDecimal128FromInt64(v)
+	case types.Decimal128:
+		return v
+	default:
+		panic("operators.toDecimal128: not a number")
+	}
+}
+
+// hasType returns true if any of the given values has the same type as t.
+func hasType[T any](vs ...any) bool {
+	for _, v := range vs {
+		if _, ok := v.(T); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// integerResult returns the integer as int32 if all inputs are int32 and the result fits,
+// as int64 if the result fits, and as float64 otherwise.
+func integerResult(n *big.Int, inputs ...any) any {
+	if !n.IsInt64() {
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return f
+	}
+
+	i := n.Int64()
+
+	if !hasType[int64](inputs...) && int64(int32(i)) == i {
+		return int32(i)
+	}
+
+	return i
+}
@@ -0,0 +1,196 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// arrayToObject represents `$arrayToObject` operator.
+type arrayToObject struct {
+	param any
+}
+
+// newArrayToObject returns `$arrayToObject` operator.
+func newArrayToObject(args ...any) (Operator, error) {
+	if len(args) != 1 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			"$arrayToObject",
+			fmt.Sprintf("Expression $arrayToObject takes exactly 1 arguments. %d were passed in.", len(args)),
+		)
+	}
+
+	return &arrayToObject{
+		param: args[0],
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// The input array should contain either `[key, value]` arrays or `{k: key, v: value}` documents.
+// It returns a document with those fields; if the key is repeated, the last value is used.
+// If the input is null or missing, it returns null.
+func (a *arrayToObject) Process(doc *types.Document) (any, error) {
+	input, err := evaluate(a.param, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNullish(input) {
+		return types.Null, nil
+	}
+
+	arr, ok := input.(*types.Array)
+	if !ok {
+		return nil, a.error(
+			handlererrors.ErrArrayToObjectNotArray,
+			"$arrayToObject requires an array input, found: %s", typeAlias(input),
+		)
+	}
+
+	res := types.MakeDocument(arr.Len())
+
+	if arr.Len() == 0 {
+		return res, nil
+	}
+
+	var pairs bool
+
+	switch first := must.NotFail(arr.Get(0)).(type) {
+	case *types.Document:
+		// objects with k and v fields
+	case *types.Array:
+		pairs = true
+	default:
+		return nil, a.error(
+			handlererrors.ErrArrayToObjectBadElement,
+			"Unrecognised input type format for $arrayToObject: %s", typeAlias(first),
+		)
+	}
+
+	for i := 0; i < arr.Len(); i++ {
+		var k, v any
+
+		switch elem := must.NotFail(arr.Get(i)).(type) {
+		case *types.Document:
+			if pairs {
+				return nil, a.error(
+					handlererrors.ErrArrayToObjectMixedFormat,
+					"$arrayToObject requires a consistent input format. "+
+						"Elements must all be arrays or all be objects. Array was detected, now found: %s",
+					typeAlias(elem),
+				)
+			}
+
+			if elem.Len() != 2 {
+				return nil, a.error(
+					handlererrors.ErrArrayToObjectBadKeysCount,
+					"$arrayToObject requires an object keys of 'k' and 'v'. Found incorrect number of keys:%d",
+					elem.Len(),
+				)
+			}
+
+			if !elem.Has("k") || !elem.Has("v") {
+				return nil, a.error(
+					handlererrors.ErrArrayToObjectMissingKeys,
+					"$arrayToObject requires an object with keys 'k' and 'v'. Missing either or both keys from: %s",
+					types.FormatAnyValue(elem),
+				)
+			}
+
+			k, v = must.NotFail(elem.Get("k")), must.NotFail(elem.Get("v"))
+
+			if _, ok := k.(string); !ok {
+				return nil, a.error(
+					handlererrors.ErrArrayToObjectKeyNotString,
+					"$arrayToObject requires an object with keys 'k' and 'v', "+
+						"where the value of 'k' must be of type string. Found type: %s",
+					typeAlias(k),
+				)
+			}
+
+		case *types.Array:
+			if !pairs {
+				return nil, a.error(
+					handlererrors.ErrArrayToObjectMixedFormat,
+					"$arrayToObject requires a consistent input format. "+
+						"Elements must all be arrays or all be objects. Object was detected, now found: %s",
+					typeAlias(elem),
+				)
+			}
+
+			if elem.Len() != 2 {
+				return nil, a.error(
+					handlererrors.ErrArrayToObjectBadPairSize,
+					"$arrayToObject requires an array of key-value pairs, where each pair is an array of size 2. "+
+						"Found array of size: %d",
+					elem.Len(),
+				)
+			}
+
+			k, v = must.NotFail(elem.Get(0)), must.NotFail(elem.Get(1))
+
+			if _, ok := k.(string); !ok {
+				return nil, a.error(
+					handlererrors.ErrArrayToObjectPairKeyNotString,
+					"$arrayToObject requires an array of key-value pairs, where the key must be of type string. "+
+						"Found key type: %s",
+					typeAlias(k),
+				)
+			}
+
+		default:
+			format := "Object"
+			if pairs {
+				format = "Array"
+			}
+
+			return nil, a.error(
+				handlererrors.ErrArrayToObjectMixedFormat,
+				"$arrayToObject requires a consistent input format. "+
+					"Elements must all be arrays or all be objects. %s was detected, now found: %s",
+				format, typeAlias(elem),
+			)
+		}
+
+		key := k.(string)
+		if strings.ContainsRune(key, 0) {
+			return nil, a.error(
+				handlererrors.ErrArrayToObjectKeyNullByte,
+				"Key field cannot contain an embedded null byte",
+			)
+		}
+
+		res.Set(key, v)
+	}
+
+	return res, nil
+}
+
+// error returns a command error with the given code and formatted message for that operator.
+func (a *arrayToObject) error(code handlererrors.ErrorCode, format string, args ...any) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(code, fmt.Sprintf(format, args...), "$arrayToObject (operator)")
+}
+
+// check interfaces
+var (
+	_ Operator = (*arrayToObject)(nil)
+)
@@ -0,0 +1,122 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// and represents `$and` operator.
+type and struct {
+	args []any
+}
+
+// newAnd returns `$and` operator.
+func newAnd(args ...any) (Operator, error) {
+	return &and{
+		args: args,
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns true if all arguments are true; evaluation stops at the first false argument.
+// It returns true for no arguments.
+func (a *and) Process(doc *types.Document) (any, error) {
+	for _, arg := range a.args {
+		v, err := evaluate(arg, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isTruthy(v) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// or represents `$or` operator.
+type or struct {
+	args []any
+}
+
+// newOr returns `$or` operator.
+func newOr(args ...any) (Operator, error) {
+	return &or{
+		args: args,
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns true if any argument is true; evaluation stops at the first true argument.
+// It returns false for no arguments.
+func (o *or) Process(doc *types.Document) (any, error) {
+	for _, arg := range o.args {
+		v, err := evaluate(arg, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if isTruthy(v) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// not represents `$not` operator.
+type not struct {
+	arg any
+}
+
+// newNot returns `$not` operator.
+func newNot(args ...any) (Operator, error) {
+	if len(args) != 1 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			"$not",
+			fmt.Sprintf("Expression $not takes exactly 1 arguments. %d were passed in.", len(args)),
+		)
+	}
+
+	return &not{
+		arg: args[0],
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the opposite boolean value of the argument.
+func (n *not) Process(doc *types.Document) (any, error) {
+	v, err := evaluate(n.arg, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return !isTruthy(v), nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*and)(nil)
+	_ Operator = (*or)(nil)
+	_ Operator = (*not)(nil)
+)
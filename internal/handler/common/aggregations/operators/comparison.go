@@ -0,0 +1,116 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// Comparison operators.
+var (
+	newCmp = newComparison("$cmp")
+	newEq  = newComparison("$eq")
+	newGt  = newComparison("$gt")
+	newGte = newComparison("$gte")
+	newLt  = newComparison("$lt")
+	newLte = newComparison("$lte")
+	newNe  = newComparison("$ne")
+)
+
+// comparison represents comparison operators like `$eq`, `$gt` and `$cmp`.
+type comparison struct {
+	operator string
+	a        any
+	b        any
+}
+
+// newComparison returns a function that creates the given comparison operator.
+func newComparison(operator string) newOperatorFunc {
+	return func(args ...any) (Operator, error) {
+		if len(args) != 2 {
+			return nil, newOperatorError(
+				ErrArgsInvalidLen,
+				operator,
+				fmt.Sprintf("Expression %s takes exactly 2 arguments. %d were passed in.", operator, len(args)),
+			)
+		}
+
+		return &comparison{
+			operator: operator,
+			a:        args[0],
+			b:        args[1],
+		}, nil
+	}
+}
+
+// Process implements Operator interface.
+//
+// It compares both arguments using BSON comparison order, including values of different types;
+// the missing value is less than any other value, including null.
+// `$cmp` returns -1, 0 or 1; other operators return a boolean.
+func (c *comparison) Process(doc *types.Document) (any, error) {
+	a, err := evaluate(c.a, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := evaluate(c.b, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	res := compareValues(a, b)
+
+	switch c.operator {
+	case "$cmp":
+		return int32(res), nil
+	case "$eq":
+		return res == types.Equal, nil
+	case "$ne":
+		return res != types.Equal, nil
+	case "$gt":
+		return res == types.Greater, nil
+	case "$gte":
+		return res != types.Less, nil
+	case "$lt":
+		return res == types.Less, nil
+	case "$lte":
+		return res != types.Greater, nil
+	default:
+		panic(fmt.Sprintf("unexpected comparison operator %q", c.operator))
+	}
+}
+
+// compareValues compares evaluated values using BSON comparison order.
+// The missing value (nil) is less than any other value.
+func compareValues(a, b any) types.CompareResult {
+	switch {
+	case a == nil && b == nil:
+		return types.Equal
+	case a == nil:
+		return types.Less
+	case b == nil:
+		return types.Greater
+	default:
+		return types.CompareOrder(a, b, types.Ascending)
+	}
+}
+
+// check interfaces
+var (
+	_ Operator = (*comparison)(nil)
+)
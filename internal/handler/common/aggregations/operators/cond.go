@@ -0,0 +1,117 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// cond represents `$cond` operator.
+type cond struct {
+	ifExpr   any
+	thenExpr any
+	elseExpr any
+}
+
+// newCond returns `$cond` operator.
+//
+// Both array `[<if>, <then>, <else>]` and document `{if: <if>, then: <then>, else: <else>}` forms are supported.
+func newCond(args ...any) (Operator, error) {
+	if len(args) == 3 {
+		return &cond{
+			ifExpr:   args[0],
+			thenExpr: args[1],
+			elseExpr: args[2],
+		}, nil
+	}
+
+	fields, unknown, ok, err := operatorFields(args, "if", "then", "else")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !ok:
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			"$cond",
+			fmt.Sprintf("Expression $cond takes exactly 3 arguments. %d were passed in.", len(args)),
+		)
+	case unknown != "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrCondUnknownArg,
+			fmt.Sprintf("Unrecognized parameter to $cond: %s", unknown),
+			"$cond (operator)",
+		)
+	}
+
+	for _, f := range []struct {
+		name string
+		code handlererrors.ErrorCode
+	}{
+		{"if", handlererrors.ErrCondMissingIf},
+		{"then", handlererrors.ErrCondMissingThen},
+		{"else", handlererrors.ErrCondMissingElse},
+	} {
+		if _, ok = fields[f.name]; !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				f.code,
+				fmt.Sprintf("Missing '%s' parameter to $cond", f.name),
+				"$cond (operator)",
+			)
+		}
+	}
+
+	return &cond{
+		ifExpr:   fields["if"],
+		thenExpr: fields["then"],
+		elseExpr: fields["else"],
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It evaluates and returns then expression if the if expression is true, and else expression otherwise.
+// Only the selected branch is evaluated.
+// If the selected expression refers to a missing field, null is returned.
+func (c *cond) Process(doc *types.Document) (any, error) {
+	v, err := evaluate(c.ifExpr, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	expr := c.elseExpr
+	if isTruthy(v) {
+		expr = c.thenExpr
+	}
+
+	if v, err = evaluate(expr, doc); err != nil {
+		return nil, err
+	}
+
+	if v == nil {
+		return types.Null, nil
+	}
+
+	return v, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*cond)(nil)
+)
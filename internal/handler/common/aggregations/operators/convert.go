@@ -0,0 +1,572 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// convert represents `$convert` operator and its shorthands like `$toInt`.
+type convert struct {
+	fields   map[string]any // input, to, onError, onNull
+	operator string
+}
+
+// newConvert returns `$convert` operator.
+func newConvert(args ...any) (Operator, error) {
+	fields, unknown, ok, err := operatorFields(args, "input", "to", "onError", "onNull")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !ok:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFailedToParse,
+			fmt.Sprintf("$convert expects an object of named arguments but found: %s", argsTypeAlias(args)),
+			"$convert (operator)",
+		)
+	case unknown != "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFailedToParse,
+			fmt.Sprintf("$convert found an unknown argument: %s", unknown),
+			"$convert (operator)",
+		)
+	}
+
+	for _, f := range []string{"input", "to"} {
+		if _, ok = fields[f]; !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParse,
+				fmt.Sprintf("Missing '%s' parameter to $convert", f),
+				"$convert (operator)",
+			)
+		}
+	}
+
+	return &convert{
+		fields:   fields,
+		operator: "$convert",
+	}, nil
+}
+
+// Shorthands for `$convert` operator.
+var (
+	newToBool     = newToType("$toBool", handlerparams.TypeCodeBool)
+	newToDate     = newToType("$toDate", handlerparams.TypeCodeDate)
+	newToDecimal  = newToType("$toDecimal", handlerparams.TypeCodeDecimal)
+	newToDouble   = newToType("$toDouble", handlerparams.TypeCodeDouble)
+	newToInt      = newToType("$toInt", handlerparams.TypeCodeInt)
+	newToLong     = newToType("$toLong", handlerparams.TypeCodeLong)
+	newToObjectID = newToType("$toObjectId", handlerparams.TypeCodeObjectID)
+	newToString   = newToType("$toString", handlerparams.TypeCodeString)
+)
+
+// newToType returns a function that creates a shorthand operator like `$toInt`
+// that converts its only argument to the given type without onError and onNull values.
+func newToType(operator string, to handlerparams.TypeCode) newOperatorFunc {
+	return func(args ...any) (Operator, error) {
+		if len(args) != 1 {
+			return nil, newOperatorError(
+				ErrArgsInvalidLen,
+				operator,
+				fmt.Sprintf("Expression %s takes exactly 1 arguments. %d were passed in.", operator, len(args)),
+			)
+		}
+
+		return &convert{
+			fields: map[string]any{
+				"input": args[0],
+				"to":    to.String(),
+			},
+			operator: operator,
+		}, nil
+	}
+}
+
+// Process implements Operator interface.
+//
+// If the input is null or missing, onNull value or null is returned.
+// If the target type is null or missing, null is returned.
+// If the conversion fails, onError value is returned if set;
+// errors in the target type are returned even if onError is set.
+func (c *convert) Process(doc *types.Document) (any, error) {
+	to, err := evaluate(c.fields["to"], doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var target handlerparams.TypeCode
+
+	if !isNullish(to) {
+		if target, err = c.targetType(to); err != nil {
+			return nil, err
+		}
+	}
+
+	input, err := evaluate(c.fields["input"], doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNullish(input) {
+		return c.fallback("onNull", doc)
+	}
+
+	if isNullish(to) {
+		return types.Null, nil
+	}
+
+	res, err := convertValue(input, target)
+	if err != nil {
+		if _, ok := c.fields["onError"]; ok {
+			return c.fallback("onError", doc)
+		}
+
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrConversionFailure,
+			err.Error(),
+			c.operator+" (operator)",
+		)
+	}
+
+	return res, nil
+}
+
+// targetType returns the type code for the evaluated `to` value.
+func (c *convert) targetType(to any) (handlerparams.TypeCode, error) {
+	switch to := to.(type) {
+	case float64, int32, int64, types.Decimal128:
+		n, err := handlerparams.GetWholeNumberParam(to)
+		if err != nil {
+			return 0, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParse,
+				"In $convert, numeric 'to' argument is not an integer",
+				c.operator+" (operator)",
+			)
+		}
+
+		code := handlerparams.TypeCode(n)
+		if _, err = handlerparams.NewTypeCode(int32(n)); err != nil || code == handlerparams.TypeCodeNumber ||
+			int64(code) != n {
+			return 0, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParse,
+				fmt.Sprintf("In $convert, numeric value for 'to' does not correspond to a BSON type: %d", n),
+				c.operator+" (operator)",
+			)
+		}
+
+		return code, nil
+
+	case string:
+		code, err := handlerparams.ParseTypeCode(to)
+		if err != nil || code == handlerparams.TypeCodeNumber {
+			return 0, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrBadValue,
+				fmt.Sprintf("Unknown type name: %s", to),
+				c.operator+" (operator)",
+			)
+		}
+
+		return code, nil
+
+	default:
+		return 0, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFailedToParse,
+			fmt.Sprintf("$convert's 'to' argument must be a string or number, but is %s", typeAlias(to)),
+			c.operator+" (operator)",
+		)
+	}
+}
+
+// fallback returns the evaluated value of onNull or onError field, or null if it is not set.
+func (c *convert) fallback(field string, doc *types.Document) (any, error) {
+	v, ok := c.fields[field]
+	if !ok {
+		return types.Null, nil
+	}
+
+	v, err := evaluate(v, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if v == nil {
+		return types.Null, nil
+	}
+
+	return v, nil
+}
+
+// convertValue converts non-null value to the given type like MongoDB's `$convert` does.
+//
+// Returned error message is suitable for ConversionFailure error.
+func convertValue(v any, to handlerparams.TypeCode) (any, error) {
+	switch to {
+	case handlerparams.TypeCodeBool:
+		return isTruthy(v), nil
+
+	case handlerparams.TypeCodeDouble:
+		switch v := v.(type) {
+		case float64:
+			return v, nil
+		case string:
+			f, err := parseDouble(v)
+			if err != nil {
+				return nil, errConversionParse(v, err)
+			}
+
+			return f, nil
+		case bool:
+			return float64(boolToInt(v)), nil
+		case time.Time:
+			return float64(v.UnixMilli()), nil
+		case int32:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case types.Decimal128:
+			f := v.Float64()
+			if math.IsInf(f, 0) && !v.IsInf(0) {
+				return nil, errConversionOverflow(v)
+			}
+
+			return f, nil
+		}
+
+	case handlerparams.TypeCodeInt:
+		var i int64
+
+		switch v := v.(type) {
+		case float64, types.Decimal128:
+			var err error
+			if i, err = truncateToInt64(v); err != nil {
+				return nil, err
+			}
+		case string:
+			n, err := parseInt(v, 32)
+			if err != nil {
+				return nil, errConversionParse(v, err)
+			}
+
+			return int32(n), nil
+		case bool:
+			return int32(boolToInt(v)), nil
+		case int32:
+			return v, nil
+		case int64:
+			i = v
+		default:
+			return nil, errUnsupportedConversion(v, to)
+		}
+
+		if i < math.MinInt32 || i > math.MaxInt32 {
+			return nil, errConversionOverflow(v)
+		}
+
+		return int32(i), nil
+
+	case handlerparams.TypeCodeLong:
+		switch v := v.(type) {
+		case float64, types.Decimal128:
+			return truncateToInt64(v)
+		case string:
+			n, err := parseInt(v, 64)
+			if err != nil {
+				return nil, errConversionParse(v, err)
+			}
+
+			return n, nil
+		case bool:
+			return boolToInt(v), nil
+		case time.Time:
+			return v.UnixMilli(), nil
+		case int32:
+			return int64(v), nil
+		case int64:
+			return v, nil
+		}
+
+	case handlerparams.TypeCodeDecimal:
+		switch v := v.(type) {
+		case float64:
+			return types.NewDecimal128FromFloat64(v), nil
+		case string:
+			d, err := types.ParseDecimal128(v)
+			if err != nil {
+				return nil, errConversionParse(v, conversionError("Failed to parse string to decimal"))
+			}
+
+			return d, nil
+		case bool:
+			return types.NewDecimal128FromInt64(boolToInt(v)), nil
+		case time.Time:
+			return types.NewDecimal128FromInt64(v.UnixMilli()), nil
+		case int32:
+			return types.NewDecimal128FromInt64(int64(v)), nil
+		case int64:
+			return types.NewDecimal128FromInt64(v), nil
+		case types.Decimal128:
+			return v, nil
+		}
+
+	case handlerparams.TypeCodeString:
+		switch v := v.(type) {
+		case float64:
+			return formatDouble(v), nil
+		case string:
+			return v, nil
+		case types.ObjectID:
+			return hex.EncodeToString(v[:]), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		case time.Time:
+			return v.UTC().Format("2006-01-02T15:04:05.000Z"), nil
+		case int32:
+			return strconv.FormatInt(int64(v), 10), nil
+		case int64:
+			return strconv.FormatInt(v, 10), nil
+		case types.Decimal128:
+			return v.String(), nil
+		}
+
+	case handlerparams.TypeCodeObjectID:
+		switch v := v.(type) {
+		case string:
+			return parseObjectID(v)
+		case types.ObjectID:
+			return v, nil
+		}
+
+	case handlerparams.TypeCodeDate:
+		switch v := v.(type) {
+		case float64, types.Decimal128:
+			ms, err := truncateToInt64(v)
+			if err != nil {
+				return nil, err
+			}
+
+			return time.UnixMilli(ms).UTC(), nil
+		case string:
+			parts, err := parseISODate(v)
+
+			var t time.Time
+			if err == nil {
+				t, err = parts.date(time.UTC)
+			}
+
+			if err != nil {
+				return nil, newConversionError("Error parsing date string '%s'; %s", v, err)
+			}
+
+			return t, nil
+		case types.ObjectID:
+			return time.Unix(int64(binary.BigEndian.Uint32(v[:4])), 0).UTC(), nil
+		case time.Time:
+			return v, nil
+		case types.Timestamp:
+			return v.Time().UTC(), nil
+		case int64:
+			return time.UnixMilli(v).UTC(), nil
+		}
+	}
+
+	return nil, errUnsupportedConversion(v, to)
+}
+
+// boolToInt returns 1 for true and 0 for false.
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// truncateToInt64 truncates double or Decimal128 value towards zero.
+func truncateToInt64(v any) (int64, error) {
+	switch v := v.(type) {
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return 0, errNaNToInteger
+		case math.IsInf(v, 0):
+			return 0, errInfToInteger
+		case v < math.MinInt64 || v >= math.MaxInt64:
+			return 0, errConversionOverflow(v)
+		}
+
+		return int64(v), nil
+
+	case types.Decimal128:
+		switch {
+		case v.IsNaN():
+			return 0, errNaNToInteger
+		case v.IsInf(0):
+			return 0, errInfToInteger
+		}
+
+		i, ok := v.Int64()
+		if !ok {
+			return 0, errConversionOverflow(v)
+		}
+
+		return i, nil
+
+	default:
+		panic(fmt.Sprintf("unexpected type %T", v))
+	}
+}
+
+// parseDouble parses the string as double like MongoDB does.
+//
+// Unlike [strconv.ParseFloat], hexadecimal values, underscores and whitespace are not allowed.
+func parseDouble(s string) (float64, error) {
+	if s == "" {
+		return 0, errNoDigits
+	}
+
+	if strings.ContainsAny(s, "xX_ \t\n\v\f\r") {
+		return 0, errNotWholeString
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, conversionError("Out of range")
+		}
+
+		return 0, errNotWholeString
+	}
+
+	return f, nil
+}
+
+// parseInt parses the string as decimal integer of the given bit size like MongoDB does.
+func parseInt(s string, bitSize int) (int64, error) {
+	if strings.TrimLeft(s, "+-") == "" {
+		return 0, errNoDigits
+	}
+
+	n, err := strconv.ParseInt(s, 10, bitSize)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, conversionError("Overflow")
+		}
+
+		return 0, errNotWholeString
+	}
+
+	return n, nil
+}
+
+// parseObjectID parses ObjectID from the 24 hex characters string.
+func parseObjectID(s string) (types.ObjectID, error) {
+	var res types.ObjectID
+
+	if len(s) != 2*types.ObjectIDLen {
+		return res, newConversionError(
+			"Failed to parse objectId '%s' in $convert with no onError value: "+
+				"Invalid string length for parsing to OID, expected 24 but found %d",
+			s, len(s),
+		)
+	}
+
+	if _, err := hex.Decode(res[:], []byte(s)); err != nil {
+		return res, newConversionError(
+			"Failed to parse objectId '%s' in $convert with no onError value: Invalid character found in hex string: %s",
+			s, s,
+		)
+	}
+
+	return res, nil
+}
+
+// formatDouble returns the shortest string representation of the double value,
+// using exponent notation only for very large and very small values.
+func formatDouble(f float64) string {
+	if f != 0 && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		if exp := math.Floor(math.Log10(math.Abs(f))); exp < -4 || exp >= 17 {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	}
+
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// conversionError represents a conversion failure with MongoDB-compatible message.
+type conversionError string
+
+// Conversion failure reasons.
+const (
+	errNoDigits       = conversionError("No digits")
+	errNotWholeString = conversionError("Did not consume whole string.")
+	errNaNToInteger   = conversionError("Attempt to convert NaN value to integer type in $convert with no onError value")
+	errInfToInteger   = conversionError("Attempt to convert infinity value to integer type in $convert with no onError value")
+)
+
+// newConversionError returns a new conversionError with formatted message.
+func newConversionError(format string, args ...any) error {
+	return conversionError(fmt.Sprintf(format, args...))
+}
+
+// Error implements error interface.
+func (e conversionError) Error() string {
+	return string(e)
+}
+
+// errUnsupportedConversion returns an error for the conversion that is not supported.
+func errUnsupportedConversion(v any, to handlerparams.TypeCode) error {
+	return newConversionError("Unsupported conversion from %s to %s in $convert with no onError value", typeAlias(v), to)
+}
+
+// errConversionOverflow returns an error for the value that does not fit into the target type.
+func errConversionOverflow(v any) error {
+	return newConversionError(
+		"Conversion would overflow target type in $convert with no onError value: %s",
+		types.FormatAnyValue(v),
+	)
+}
+
+// errConversionParse returns an error for the string that could not be parsed as a number.
+func errConversionParse(s string, err error) error {
+	return newConversionError("Failed to parse number '%s' in $convert with no onError value: %s", s, err)
+}
+
+// check interfaces
+var (
+	_ Operator = (*convert)(nil)
+	_ error    = conversionError("")
+)
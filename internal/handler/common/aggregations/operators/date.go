@@ -0,0 +1,478 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// timezoneOffsetRe matches UTC offsets like +04:45, -0300 and +03.
+var timezoneOffsetRe = regexp.MustCompile(`^([+-])(\d{2})(?::?(\d{2}))?$`)
+
+// getTimezone returns the location for the evaluated timezone argument of the date operator.
+//
+// Olson time zone identifiers and UTC offsets are supported.
+func getTimezone(v any, operator string) (*time.Location, error) {
+	tz, ok := v.(string)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrTimezoneNotString,
+			fmt.Sprintf("timezone must evaluate to a string, found %s", handlerparams.AliasFromType(v)),
+			operator+" (operator)",
+		)
+	}
+
+	if m := timezoneOffsetRe.FindStringSubmatch(tz); m != nil {
+		hours, _ := strconv.Atoi(m[2])
+		minutes, _ := strconv.Atoi(m[3])
+
+		offset := hours*3600 + minutes*60
+		if m[1] == "-" {
+			offset = -offset
+		}
+
+		return time.FixedZone(tz, offset), nil
+	}
+
+	// time.LoadLocation treats empty name as UTC and "Local" as the local time zone
+	if tz != "" && tz != "Local" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			return loc, nil
+		}
+	}
+
+	return nil, handlererrors.NewCommandErrorMsgWithArgument(
+		handlererrors.ErrTimezoneUnrecognized,
+		fmt.Sprintf("unrecognized time zone identifier: %q", tz),
+		operator+" (operator)",
+	)
+}
+
+// getDate returns the time for the evaluated date argument of the date operator.
+//
+// Date, timestamp and ObjectID values are supported.
+func getDate(v any, operator string) (time.Time, error) {
+	switch v := v.(type) {
+	case types.ObjectID:
+		return time.Unix(int64(binary.BigEndian.Uint32(v[0:4])), 0).UTC(), nil
+	case time.Time:
+		return v.UTC(), nil
+	case types.Timestamp:
+		return v.Time(), nil
+	default:
+		return time.Time{}, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrExpressionNotDate,
+			fmt.Sprintf("can't convert from BSON type %s to Date", handlerparams.AliasFromType(v)),
+			operator+" (operator)",
+		)
+	}
+}
+
+// validateDateFormat returns an error if the format string of the date operator is invalid.
+func validateDateFormat(format, operator string) error {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+
+		if i == len(format)-1 {
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrDateFormatUnmatchedPercent,
+				"Unmatched '%' at end of format string",
+				operator+" (operator)",
+			)
+		}
+
+		i++
+
+		if !strings.ContainsRune("bBdGHjLmMSuUVwYzZ%", rune(format[i])) {
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrDateFormatInvalidChar,
+				fmt.Sprintf("Invalid format character '%%%c' in format string", format[i]),
+				operator+" (operator)",
+			)
+		}
+	}
+
+	return nil
+}
+
+// formatDate formats the time in its location according to the validated format string.
+func formatDate(t time.Time, format string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			sb.WriteByte(format[i])
+			continue
+		}
+
+		i++
+
+		switch format[i] {
+		case 'b':
+			sb.WriteString(t.Month().String()[:3])
+		case 'B':
+			sb.WriteString(t.Month().String())
+		case 'd':
+			fmt.Fprintf(&sb, "%02d", t.Day())
+		case 'G':
+			year, _ := t.ISOWeek()
+			fmt.Fprintf(&sb, "%04d", year)
+		case 'H':
+			fmt.Fprintf(&sb, "%02d", t.Hour())
+		case 'j':
+			fmt.Fprintf(&sb, "%03d", t.YearDay())
+		case 'L':
+			fmt.Fprintf(&sb, "%03d", t.Nanosecond()/int(time.Millisecond))
+		case 'm':
+			fmt.Fprintf(&sb, "%02d", t.Month())
+		case 'M':
+			fmt.Fprintf(&sb, "%02d", t.Minute())
+		case 'S':
+			fmt.Fprintf(&sb, "%02d", t.Second())
+		case 'u':
+			fmt.Fprintf(&sb, "%d", isoWeekday(t))
+		case 'U':
+			fmt.Fprintf(&sb, "%02d", (t.YearDay()+6-int(t.Weekday()))/7)
+		case 'V':
+			_, week := t.ISOWeek()
+			fmt.Fprintf(&sb, "%02d", week)
+		case 'w':
+			fmt.Fprintf(&sb, "%d", t.Weekday()+1)
+		case 'Y':
+			fmt.Fprintf(&sb, "%04d", t.Year())
+		case 'z':
+			_, offset := t.Zone()
+			sign, offset := offsetSign(offset)
+			fmt.Fprintf(&sb, "%c%02d%02d", sign, offset/3600, offset%3600/60)
+		case 'Z':
+			_, offset := t.Zone()
+			sign, offset := offsetSign(offset)
+			fmt.Fprintf(&sb, "%c%03d", sign, offset/60)
+		case '%':
+			sb.WriteByte('%')
+		}
+	}
+
+	return sb.String()
+}
+
+// offsetSign returns the sign and the absolute value of the UTC offset in seconds.
+func offsetSign(offset int) (byte, int) {
+	if offset < 0 {
+		return '-', -offset
+	}
+
+	return '+', offset
+}
+
+// isoWeekday returns ISO 8601 day of the week, from 1 (Monday) to 7 (Sunday).
+func isoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+
+	return int(t.Weekday())
+}
+
+// isoWeekStart returns the first day (Monday) of the given ISO 8601 week-numbering year
+// in the given location.
+func isoWeekStart(year int, loc *time.Location) time.Time {
+	// January 4th is always in the first ISO week
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	return jan4.AddDate(0, 0, 1-isoWeekday(jan4))
+}
+
+// dateParts contains parsed parts of the date string.
+// Unset parts are -1.
+type dateParts struct {
+	year, month, day, yearDay         int
+	hour, minute, second, millisecond int
+	isoWeekYear, isoWeek, isoDay      int
+	offset                            *int // UTC offset in seconds
+}
+
+// date returns the time for parsed parts in the given location
+// which is used only if parsed parts do not contain UTC offset.
+//
+//checkerrors:ignore // messages are returned to the client
+func (p *dateParts) date(loc *time.Location) (time.Time, error) {
+	if p.offset != nil {
+		loc = time.FixedZone("", *p.offset)
+	}
+
+	orDefault := func(v, def int) int {
+		if v < 0 {
+			return def
+		}
+
+		return v
+	}
+
+	hour, minute, second := orDefault(p.hour, 0), orDefault(p.minute, 0), orDefault(p.second, 0)
+	if hour > 23 || minute > 59 || second > 59 {
+		return time.Time{}, errors.New("time is out of range")
+	}
+
+	nsec := orDefault(p.millisecond, 0) * int(time.Millisecond)
+
+	if p.isoWeekYear >= 0 || p.isoWeek >= 0 || p.isoDay >= 0 {
+		if p.year >= 0 || p.month >= 0 || p.day >= 0 || p.yearDay >= 0 {
+			return time.Time{}, errors.New("mixing of ISO dates with natural dates is not allowed")
+		}
+
+		week, day := orDefault(p.isoWeek, 1), orDefault(p.isoDay, 1)
+		if week < 1 || week > 53 || day < 1 || day > 7 {
+			return time.Time{}, errors.New("ISO week or day is out of range")
+		}
+
+		start := isoWeekStart(orDefault(p.isoWeekYear, 1970), loc)
+		t := time.Date(start.Year(), start.Month(), start.Day()+(week-1)*7+day-1, hour, minute, second, nsec, loc)
+
+		return t.UTC(), nil
+	}
+
+	if p.year < 0 {
+		return time.Time{}, errors.New("no year")
+	}
+
+	if p.yearDay >= 0 {
+		if p.month >= 0 || p.day >= 0 {
+			return time.Time{}, errors.New("day of year can't be used with month or day")
+		}
+
+		t := time.Date(p.year, time.January, p.yearDay, hour, minute, second, nsec, loc)
+		if p.yearDay < 1 || t.Year() != p.year {
+			return time.Time{}, errors.New("day of year is out of range")
+		}
+
+		return t.UTC(), nil
+	}
+
+	month, day := orDefault(p.month, 1), orDefault(p.day, 1)
+	if month < 1 || month > 12 {
+		return time.Time{}, errors.New("month is out of range")
+	}
+
+	t := time.Date(p.year, time.Month(month), day, hour, minute, second, nsec, loc)
+	if t.Day() != day {
+		return time.Time{}, errors.New("day is out of range")
+	}
+
+	return t.UTC(), nil
+}
+
+// newDateParts returns date parts with all parts unset.
+func newDateParts() *dateParts {
+	return &dateParts{
+		year: -1, month: -1, day: -1, yearDay: -1,
+		hour: -1, minute: -1, second: -1, millisecond: -1,
+		isoWeekYear: -1, isoWeek: -1, isoDay: -1,
+	}
+}
+
+// isoDateRe matches ISO 8601 date strings accepted by $dateFromString without format.
+var isoDateRe = regexp.MustCompile(
+	`^(\d{4})-(\d{2})-(\d{2})(?:[T ](\d{2}):(\d{2})(?::(\d{2})(?:\.(\d{1,9}))?)?)?\s*(Z|[+-]\d{2}(?::?\d{2})?)?$`,
+)
+
+// parseISODate parses ISO 8601 date string.
+//
+//checkerrors:ignore // messages are returned to the client
+func parseISODate(s string) (*dateParts, error) {
+	m := isoDateRe.FindStringSubmatch(s)
+	if m == nil {
+		return nil, errors.New("unexpected data found")
+	}
+
+	p := newDateParts()
+
+	for i, dst := range []*int{&p.year, &p.month, &p.day, &p.hour, &p.minute, &p.second} {
+		if m[i+1] != "" {
+			*dst, _ = strconv.Atoi(m[i+1])
+		}
+	}
+
+	if frac := m[7]; frac != "" {
+		frac = (frac + "00")[:3]
+		p.millisecond, _ = strconv.Atoi(frac)
+	}
+
+	switch tz := m[8]; tz {
+	case "":
+	case "Z":
+		offset := 0
+		p.offset = &offset
+	default:
+		sm := timezoneOffsetRe.FindStringSubmatch(tz)
+		hours, _ := strconv.Atoi(sm[2])
+		minutes, _ := strconv.Atoi(sm[3])
+
+		offset := hours*3600 + minutes*60
+		if sm[1] == "-" {
+			offset = -offset
+		}
+
+		p.offset = &offset
+	}
+
+	return p, nil
+}
+
+// parseDateFormat parses date string according to the validated format string.
+//
+//checkerrors:ignore // messages are returned to the client
+func parseDateFormat(s, format string) (*dateParts, error) {
+	p := newDateParts()
+
+	// digits consumes from 1 to max digits from s.
+	digits := func(max int) (int, error) {
+		var n int
+		for n < max && n < len(s) && s[n] >= '0' && s[n] <= '9' {
+			n++
+		}
+
+		if n == 0 {
+			return 0, errors.New("unexpected data found")
+		}
+
+		v, _ := strconv.Atoi(s[:n])
+		s = s[n:]
+
+		return v, nil
+	}
+
+	var err error
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			if s == "" || s[0] != format[i] {
+				return nil, errors.New("format literal not found")
+			}
+
+			s = s[1:]
+
+			continue
+		}
+
+		i++
+
+		switch format[i] {
+		case 'b', 'B':
+			p.month = -1
+
+			for m := time.January; m <= time.December; m++ {
+				name := m.String()
+				if format[i] == 'b' {
+					name = name[:3]
+				}
+
+				if len(s) >= len(name) && strings.EqualFold(s[:len(name)], name) {
+					p.month = int(m)
+					s = s[len(name):]
+
+					break
+				}
+			}
+
+			if p.month < 0 {
+				return nil, errors.New("unknown month name")
+			}
+
+		case 'd':
+			p.day, err = digits(2)
+		case 'G':
+			p.isoWeekYear, err = digits(4)
+		case 'H':
+			p.hour, err = digits(2)
+		case 'j':
+			p.yearDay, err = digits(3)
+		case 'L':
+			p.millisecond, err = digits(3)
+		case 'm':
+			p.month, err = digits(2)
+		case 'M':
+			p.minute, err = digits(2)
+		case 'S':
+			p.second, err = digits(2)
+		case 'u':
+			p.isoDay, err = digits(1)
+		case 'V':
+			p.isoWeek, err = digits(2)
+		case 'Y':
+			p.year, err = digits(4)
+		case 'z', 'Z':
+			if s == "" || (s[0] != '+' && s[0] != '-') {
+				return nil, errors.New("UTC offset not found")
+			}
+
+			sign := 1
+			if s[0] == '-' {
+				sign = -1
+			}
+
+			s = s[1:]
+
+			var offset int
+
+			if format[i] == 'Z' {
+				var minutes int
+				if minutes, err = digits(3); err == nil {
+					offset = minutes * 60
+				}
+			} else {
+				var hours, minutes int
+				if hours, err = digits(2); err == nil {
+					s = strings.TrimPrefix(s, ":")
+					if minutes, err = digits(2); err == nil {
+						offset = hours*3600 + minutes*60
+					}
+				}
+			}
+
+			offset *= sign
+			p.offset = &offset
+		case 'U', 'w':
+			return nil, fmt.Errorf("format specifier %%%c is not supported for parsing", format[i])
+		case '%':
+			if s == "" || s[0] != '%' {
+				return nil, errors.New("format literal not found")
+			}
+
+			s = s[1:]
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if s != "" {
+		return nil, errors.New("trailing data")
+	}
+
+	return p, nil
+}
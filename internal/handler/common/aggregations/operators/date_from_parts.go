@@ -0,0 +1,194 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// dateFromParts represents `$dateFromParts` operator.
+type dateFromParts struct {
+	fields map[string]any
+	iso    bool
+}
+
+// newDateFromParts returns `$dateFromParts` operator.
+func newDateFromParts(args ...any) (Operator, error) {
+	fields, unknown, ok, err := operatorFields(
+		args,
+		"year", "month", "day",
+		"isoWeekYear", "isoWeek", "isoDayOfWeek",
+		"hour", "minute", "second", "millisecond", "timezone",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !ok:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrDateFromPartsInvalidArg,
+			"$dateFromParts only supports an object as its argument",
+			"$dateFromParts (operator)",
+		)
+	case unknown != "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrDateFromPartsUnknownArg,
+			fmt.Sprintf("Unrecognized argument to $dateFromParts: %s", unknown),
+			"$dateFromParts (operator)",
+		)
+	}
+
+	var natural, iso bool
+
+	for _, f := range []string{"year", "month", "day"} {
+		if _, ok = fields[f]; ok {
+			natural = true
+		}
+	}
+
+	for _, f := range []string{"isoWeekYear", "isoWeek", "isoDayOfWeek"} {
+		if _, ok = fields[f]; ok {
+			iso = true
+		}
+	}
+
+	if natural && iso {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrDateFromPartsMixedISO,
+			"$dateFromParts does not allow mixing natural dates with ISO dates",
+			"$dateFromParts (operator)",
+		)
+	}
+
+	_, hasYear := fields["year"]
+	_, hasISOWeekYear := fields["isoWeekYear"]
+
+	if !hasYear && !hasISOWeekYear {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrDateFromPartsMissingYear,
+			"$dateFromParts requires either 'year' or 'isoWeekYear' to be present",
+			"$dateFromParts (operator)",
+		)
+	}
+
+	return &dateFromParts{
+		fields: fields,
+		iso:    iso,
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the date constructed from the given parts in the given time zone.
+// Parts outside of their usual range carry over to the next part like in MongoDB,
+// so month 14 is February of the next year.
+// If any part is null or missing, null is returned.
+func (d *dateFromParts) Process(doc *types.Document) (any, error) {
+	names := []string{"year", "month", "day", "hour", "minute", "second", "millisecond"}
+	defaults := []int{1970, 1, 1, 0, 0, 0, 0}
+
+	if d.iso {
+		names[0], names[1], names[2] = "isoWeekYear", "isoWeek", "isoDayOfWeek"
+	}
+
+	parts := make([]int, len(names))
+
+	for i, name := range names {
+		parts[i] = defaults[i]
+
+		arg, ok := d.fields[name]
+		if !ok {
+			continue
+		}
+
+		v, err := evaluate(arg, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if isNullish(v) {
+			return types.Null, nil
+		}
+
+		n, err := handlerparams.GetWholeNumberParam(v)
+		if err != nil {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrDateFromPartsNotInteger,
+				fmt.Sprintf(
+					"'%s' must evaluate to an integer, found %s with value %s",
+					name, handlerparams.AliasFromType(v), types.FormatAnyValue(v),
+				),
+				"$dateFromParts (operator)",
+			)
+		}
+
+		switch {
+		case i == 0 && (n < 1 || n > 9999):
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrDateFromPartsYearRange,
+				fmt.Sprintf("'%s' must evaluate to an integer in the range 1 to 9999, found %d", name, n),
+				"$dateFromParts (operator)",
+			)
+		case i > 0 && (n < math.MinInt16 || n > math.MaxInt16):
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrDateFromPartsValueRange,
+				fmt.Sprintf("'%s' must evaluate to a value in the range [-32768, 32767]; value %d is not in range", name, n),
+				"$dateFromParts (operator)",
+			)
+		}
+
+		parts[i] = int(n)
+	}
+
+	loc := time.UTC
+
+	if tz, ok := d.fields["timezone"]; ok {
+		tz, err := evaluate(tz, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if isNullish(tz) {
+			return types.Null, nil
+		}
+
+		if loc, err = getTimezone(tz, "$dateFromParts"); err != nil {
+			return nil, err
+		}
+	}
+
+	year, month, day := parts[0], time.Month(parts[1]), parts[2]
+
+	if d.iso {
+		start := isoWeekStart(parts[0], loc)
+		year, month, day = start.Year(), start.Month(), start.Day()+(parts[1]-1)*7+parts[2]-1
+	}
+
+	t := time.Date(year, month, day, parts[3], parts[4], parts[5], parts[6]*int(time.Millisecond), loc)
+
+	return t.UTC(), nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*dateFromParts)(nil)
+)
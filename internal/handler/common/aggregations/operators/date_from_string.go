@@ -0,0 +1,208 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// dateFromString represents `$dateFromString` operator.
+type dateFromString struct {
+	fields map[string]any // dateString, format, timezone, onError, onNull
+}
+
+// newDateFromString returns `$dateFromString` operator.
+func newDateFromString(args ...any) (Operator, error) {
+	fields, unknown, ok, err := operatorFields(args, "dateString", "format", "timezone", "onError", "onNull")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !ok:
+		var found any = types.Null
+		if len(args) == 1 {
+			found = args[0]
+		}
+
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrDateFromStringInvalidArg,
+			fmt.Sprintf(
+				"$dateFromString only supports an object as an argument. Found: %s",
+				handlerparams.AliasFromType(found),
+			),
+			"$dateFromString (operator)",
+		)
+	case unknown != "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrDateFromStringUnknownArg,
+			fmt.Sprintf("Unrecognized argument to $dateFromString: %s", unknown),
+			"$dateFromString (operator)",
+		)
+	}
+
+	if _, ok = fields["dateString"]; !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrDateFromStringMissingDateString,
+			"Missing 'dateString' parameter to $dateFromString",
+			"$dateFromString (operator)",
+		)
+	}
+
+	return &dateFromString{
+		fields: fields,
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the date parsed from the string according to the format string in the given time zone.
+// If the date string is null or missing, onNull value or null is returned.
+// If the date string can't be parsed, onError value is returned if set.
+func (d *dateFromString) Process(doc *types.Document) (any, error) {
+	dateString, err := evaluate(d.fields["dateString"], doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var format any
+
+	if f, ok := d.fields["format"]; ok {
+		if format, err = evaluate(f, doc); err != nil {
+			return nil, err
+		}
+
+		if !isNullish(format) {
+			s, ok := format.(string)
+			if !ok {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrDateFromStringFormatNotString,
+					fmt.Sprintf(
+						"$dateFromString requires that 'format' be a string, found: %s with value %s",
+						handlerparams.AliasFromType(format), types.FormatAnyValue(format),
+					),
+					"$dateFromString (operator)",
+				)
+			}
+
+			if err = validateDateFormat(s, "$dateFromString"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if isNullish(dateString) {
+		return d.fallback("onNull", doc)
+	}
+
+	loc := time.UTC
+
+	tz, hasTimezone := d.fields["timezone"]
+	if hasTimezone {
+		if tz, err = evaluate(tz, doc); err != nil {
+			return nil, err
+		}
+
+		if isNullish(tz) {
+			return types.Null, nil
+		}
+
+		if loc, err = getTimezone(tz, "$dateFromString"); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := d.fields["format"]; ok && isNullish(format) {
+		return types.Null, nil
+	}
+
+	s, ok := dateString.(string)
+	if !ok {
+		if _, ok = d.fields["onError"]; ok {
+			return d.fallback("onError", doc)
+		}
+
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrConversionFailure,
+			fmt.Sprintf(
+				"$dateFromString requires that 'dateString' be a string, found: %s with value %s",
+				handlerparams.AliasFromType(dateString), types.FormatAnyValue(dateString),
+			),
+			"$dateFromString (operator)",
+		)
+	}
+
+	var parts *dateParts
+
+	if format == nil {
+		parts, err = parseISODate(s)
+	} else {
+		parts, err = parseDateFormat(s, format.(string))
+	}
+
+	var t time.Time
+
+	if err == nil {
+		if parts.offset != nil && hasTimezone {
+			err = errors.New("you cannot pass in a date/time string with GMT offset together with a timezone argument")
+		} else {
+			t, err = parts.date(loc)
+		}
+	}
+
+	if err != nil {
+		if _, ok = d.fields["onError"]; ok {
+			return d.fallback("onError", doc)
+		}
+
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrConversionFailure,
+			fmt.Sprintf("Error parsing date string '%s'; %s", s, err),
+			"$dateFromString (operator)",
+		)
+	}
+
+	return t, nil
+}
+
+// fallback returns the evaluated value of onNull or onError field, or null if it is not set.
+func (d *dateFromString) fallback(field string, doc *types.Document) (any, error) {
+	v, ok := d.fields[field]
+	if !ok {
+		return types.Null, nil
+	}
+
+	v, err := evaluate(v, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if v == nil {
+		return types.Null, nil
+	}
+
+	return v, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*dateFromString)(nil)
+)
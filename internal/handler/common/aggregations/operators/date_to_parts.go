@@ -0,0 +1,148 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// dateToParts represents `$dateToParts` operator.
+type dateToParts struct {
+	fields map[string]any // date, timezone, iso8601
+}
+
+// newDateToParts returns `$dateToParts` operator.
+func newDateToParts(args ...any) (Operator, error) {
+	fields, unknown, ok, err := operatorFields(args, "date", "timezone", "iso8601")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !ok:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrDateToPartsInvalidArg,
+			"$dateToParts only supports an object as its argument",
+			"$dateToParts (operator)",
+		)
+	case unknown != "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrDateToPartsUnknownArg,
+			fmt.Sprintf("Unrecognized argument to $dateToParts: %s", unknown),
+			"$dateToParts (operator)",
+		)
+	}
+
+	if _, ok = fields["date"]; !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrDateToPartsMissingDate,
+			"Missing 'date' parameter to $dateToParts",
+			"$dateToParts (operator)",
+		)
+	}
+
+	return &dateToParts{
+		fields: fields,
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns a document with parts of the date in the given time zone,
+// using ISO 8601 week date parts if iso8601 is true.
+// If any argument is null or missing, null is returned.
+func (d *dateToParts) Process(doc *types.Document) (any, error) {
+	date, err := evaluate(d.fields["date"], doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNullish(date) {
+		return types.Null, nil
+	}
+
+	loc := time.UTC
+
+	if tz, ok := d.fields["timezone"]; ok {
+		if tz, err = evaluate(tz, doc); err != nil {
+			return nil, err
+		}
+
+		if isNullish(tz) {
+			return types.Null, nil
+		}
+
+		if loc, err = getTimezone(tz, "$dateToParts"); err != nil {
+			return nil, err
+		}
+	}
+
+	var iso bool
+
+	if v, ok := d.fields["iso8601"]; ok {
+		if v, err = evaluate(v, doc); err != nil {
+			return nil, err
+		}
+
+		if isNullish(v) {
+			return types.Null, nil
+		}
+
+		if iso, ok = v.(bool); !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrDateToPartsISO8601NotBool,
+				fmt.Sprintf("iso8601 must evaluate to a bool, found %s", handlerparams.AliasFromType(v)),
+				"$dateToParts (operator)",
+			)
+		}
+	}
+
+	t, err := getDate(date, "$dateToParts")
+	if err != nil {
+		return nil, err
+	}
+
+	t = t.In(loc)
+
+	res := types.MakeDocument(7)
+
+	if iso {
+		year, week := t.ISOWeek()
+		res.Set("isoWeekYear", int32(year))
+		res.Set("isoWeek", int32(week))
+		res.Set("isoDayOfWeek", int32(isoWeekday(t)))
+	} else {
+		res.Set("year", int32(t.Year()))
+		res.Set("month", int32(t.Month()))
+		res.Set("day", int32(t.Day()))
+	}
+
+	res.Set("hour", int32(t.Hour()))
+	res.Set("minute", int32(t.Minute()))
+	res.Set("second", int32(t.Second()))
+	res.Set("millisecond", int32(t.Nanosecond()/int(time.Millisecond)))
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*dateToParts)(nil)
+)
@@ -0,0 +1,157 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// dateToString represents `$dateToString` operator.
+type dateToString struct {
+	fields map[string]any // date, format, timezone, onNull
+}
+
+// newDateToString returns `$dateToString` operator.
+func newDateToString(args ...any) (Operator, error) {
+	fields, unknown, ok, err := operatorFields(args, "date", "format", "timezone", "onNull")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !ok:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrDateToStringInvalidArg,
+			"$dateToString only supports an object as its argument",
+			"$dateToString (operator)",
+		)
+	case unknown != "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrDateToStringUnknownArg,
+			fmt.Sprintf("Unrecognized parameter to $dateToString: %s", unknown),
+			"$dateToString (operator)",
+		)
+	}
+
+	if _, ok = fields["date"]; !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrDateToStringMissingDate,
+			"Missing 'date' parameter to $dateToString",
+			"$dateToString (operator)",
+		)
+	}
+
+	return &dateToString{
+		fields: fields,
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the date formatted according to the format string in the given time zone.
+// If date is null or missing, onNull value or null is returned.
+func (d *dateToString) Process(doc *types.Document) (any, error) {
+	date, err := evaluate(d.fields["date"], doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var format any
+
+	if f, ok := d.fields["format"]; ok {
+		if format, err = evaluate(f, doc); err != nil {
+			return nil, err
+		}
+
+		if !isNullish(format) {
+			s, ok := format.(string)
+			if !ok {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrDateFormatNotString,
+					fmt.Sprintf(
+						"$dateToString requires that 'format' be a string, found: %s with value %s",
+						handlerparams.AliasFromType(format), types.FormatAnyValue(format),
+					),
+					"$dateToString (operator)",
+				)
+			}
+
+			if err = validateDateFormat(s, "$dateToString"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if isNullish(date) {
+		onNull, ok := d.fields["onNull"]
+		if !ok {
+			return types.Null, nil
+		}
+
+		if onNull, err = evaluate(onNull, doc); err != nil {
+			return nil, err
+		}
+
+		if onNull == nil {
+			return types.Null, nil
+		}
+
+		return onNull, nil
+	}
+
+	loc := time.UTC
+	defaultFormat := "%Y-%m-%dT%H:%M:%S.%LZ"
+
+	if tz, ok := d.fields["timezone"]; ok {
+		if tz, err = evaluate(tz, doc); err != nil {
+			return nil, err
+		}
+
+		if isNullish(tz) {
+			return types.Null, nil
+		}
+
+		if loc, err = getTimezone(tz, "$dateToString"); err != nil {
+			return nil, err
+		}
+
+		defaultFormat = "%Y-%m-%dT%H:%M:%S.%L"
+	}
+
+	t, err := getDate(date, "$dateToString")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := d.fields["format"]; !ok {
+		format = defaultFormat
+	}
+
+	if isNullish(format) {
+		return types.Null, nil
+	}
+
+	return formatDate(t.In(loc), format.(string)), nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*dateToString)(nil)
+)
@@ -0,0 +1,95 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// divide represents `$divide` operator.
+type divide struct {
+	dividend any
+	divisor  any
+}
+
+// newDivide returns `$divide` operator.
+func newDivide(args ...any) (Operator, error) {
+	if len(args) != 2 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			"$divide",
+			fmt.Sprintf("Expression $divide takes exactly 2 arguments. %d were passed in.", len(args)),
+		)
+	}
+
+	return &divide{
+		dividend: args[0],
+		divisor:  args[1],
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the quotient as Decimal128 if any argument is Decimal128, and as double otherwise.
+// If any argument is null or missing, null is returned.
+func (d *divide) Process(doc *types.Document) (any, error) {
+	dividend, err := evaluate(d.dividend, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	divisor, err := evaluate(d.divisor, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case isNumber(dividend) && isNumber(divisor):
+		// handled below
+	case isNullish(dividend) || isNullish(divisor):
+		return types.Null, nil
+	default:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrDivideNotNumeric,
+			fmt.Sprintf(
+				"$divide only supports numeric types, not %s and %s",
+				typeAlias(dividend), typeAlias(divisor),
+			),
+			"$divide (operator)",
+		)
+	}
+
+	if isZero(divisor) {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrBadValue,
+			"can't $divide by zero",
+			"$divide (operator)",
+		)
+	}
+
+	if hasType[types.Decimal128](dividend, divisor) {
+		return toDecimal128(dividend).Quo(toDecimal128(divisor)), nil
+	}
+
+	return toFloat64(dividend) / toFloat64(divisor), nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*divide)(nil)
+)
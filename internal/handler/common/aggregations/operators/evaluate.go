@@ -0,0 +1,239 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// evaluate returns the value of the operator argument for the given document.
+//
+// Nested operators are created and processed, field path expressions are evaluated,
+// documents and arrays are evaluated recursively; other values are returned as is.
+// It returns nil for a path of the missing field.
+func evaluate(arg any, doc *types.Document) (any, error) {
+	switch arg := arg.(type) {
+	case *types.Document:
+		if IsOperator(arg) {
+			op, err := NewOperator(arg)
+			if err != nil {
+				var opErr OperatorError
+				if !errors.As(err, &opErr) {
+					return nil, lazyerrors.Error(err)
+				}
+
+				if opErr.Code() == ErrInvalidExpression {
+					opErr.code = ErrInvalidNestedExpression
+				}
+
+				return nil, opErr
+			}
+
+			return op.Process(doc)
+		}
+
+		res := types.MakeDocument(arg.Len())
+
+		iter := arg.Iterator()
+		defer iter.Close()
+
+		for {
+			k, v, err := iter.Next()
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			if v, err = evaluate(v, doc); err != nil {
+				return nil, err
+			}
+
+			// missing fields are not set
+			if v != nil {
+				res.Set(k, v)
+			}
+		}
+
+		return res, nil
+
+	case *types.Array:
+		res := types.MakeArray(arg.Len())
+
+		iter := arg.Iterator()
+		defer iter.Close()
+
+		for {
+			_, v, err := iter.Next()
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			if v, err = evaluate(v, doc); err != nil {
+				return nil, err
+			}
+
+			// missing values are null in arrays
+			if v == nil {
+				v = types.Null
+			}
+
+			res.Append(v)
+		}
+
+		return res, nil
+
+	case string:
+		// user variables are replaced by bindVariables before evaluation,
+		// other system variables are not supported by expressions yet
+		// TODO https://github.com/FerretDB/FerretDB/issues/2275
+		if arg == "$$ROOT" || arg == "$$CURRENT" {
+			return doc, nil
+		}
+
+		if arg == variableRemove {
+			return nil, nil
+		}
+
+		if !strings.HasPrefix(arg, "$") {
+			return arg, nil
+		}
+
+		expression, err := aggregations.NewExpression(arg, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := expression.Evaluate(doc)
+		if err != nil {
+			// missing field
+			return nil, nil
+		}
+
+		return v, nil
+
+	default:
+		return arg, nil
+	}
+}
+
+// isNullish returns true if the evaluated value is null or missing.
+func isNullish(v any) bool {
+	return v == nil || v == types.Null
+}
+
+// typeAlias returns BSON type alias of the evaluated value, or "missing" for the missing value.
+func typeAlias(v any) string {
+	if v == nil {
+		return "missing"
+	}
+
+	return handlerparams.AliasFromType(v)
+}
+
+// argsTypeAlias returns BSON type alias of the operator argument extracted by NewOperator.
+//
+// Multiple or no arguments mean that the argument is an array.
+func argsTypeAlias(args []any) string {
+	if len(args) != 1 {
+		return handlerparams.AliasFromType(new(types.Array))
+	}
+
+	return handlerparams.AliasFromType(args[0])
+}
+
+// isTruthy returns true if the evaluated value is considered true by boolean expressions.
+//
+// False, null, missing and zero numbers are false; all other values,
+// including empty strings and arrays, are true.
+func isTruthy(v any) bool {
+	switch v := v.(type) {
+	case nil, types.NullType:
+		return false
+	case float64:
+		return v != 0
+	case bool:
+		return v
+	case int32:
+		return v != 0
+	case int64:
+		return v != 0
+	case types.Decimal128:
+		return !v.IsZero()
+	default:
+		return true
+	}
+}
+
+// operatorFields returns fields of the document argument of the operator.
+//
+// It returns false if the argument is not a single document.
+// If the document contains the field not listed in known, that field name is returned as unknown.
+func operatorFields(args []any, known ...string) (fields map[string]any, unknown string, ok bool, err error) {
+	if len(args) != 1 {
+		return nil, "", false, nil
+	}
+
+	doc, ok := args[0].(*types.Document)
+	if !ok {
+		return nil, "", false, nil
+	}
+
+	fields = make(map[string]any, doc.Len())
+
+	iter := doc.Iterator()
+	defer iter.Close()
+
+	for {
+		k, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, "", false, lazyerrors.Error(err)
+		}
+
+		var found bool
+
+		for _, f := range known {
+			if k == f {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, k, true, nil
+		}
+
+		fields[k] = v
+	}
+
+	return fields, "", true, nil
+}
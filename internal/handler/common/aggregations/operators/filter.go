@@ -0,0 +1,193 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// filter represents `$filter` operator.
+type filter struct {
+	input any
+	as    string
+	cond  any
+	limit any // nil if not set
+}
+
+// newFilter returns `$filter` operator.
+func newFilter(args ...any) (Operator, error) {
+	fields, unknown, ok, err := operatorFields(args, "input", "as", "cond", "limit")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !ok:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFilterBadArgument,
+			"$filter only supports an object as its argument",
+			"$filter (operator)",
+		)
+	case unknown != "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFilterUnknownField,
+			fmt.Sprintf("Unrecognized parameter to $filter: %s", unknown),
+			"$filter (operator)",
+		)
+	}
+
+	if _, ok = fields["input"]; !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFilterMissingInput,
+			"Missing 'input' parameter to $filter",
+			"$filter (operator)",
+		)
+	}
+
+	if _, ok = fields["cond"]; !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFilterMissingCond,
+			"Missing 'cond' parameter to $filter",
+			"$filter (operator)",
+		)
+	}
+
+	as, err := asVariableName(fields, "$filter")
+	if err != nil {
+		return nil, err
+	}
+
+	return &filter{
+		input: fields["input"],
+		as:    as,
+		cond:  fields["cond"],
+		limit: fields["limit"],
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns an array of input elements for which `cond` expression is true,
+// with the element bound to the variable named by `as` (`this` by default).
+// At most `limit` elements are returned, if set.
+// If the input is null or missing, it returns null.
+func (f *filter) Process(doc *types.Document) (any, error) {
+	input, err := evaluate(f.input, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := f.processLimit(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNullish(input) {
+		return types.Null, nil
+	}
+
+	arr, ok := input.(*types.Array)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFilterInputNotArray,
+			fmt.Sprintf("input to $filter must be an array not %s", typeAlias(input)),
+			"$filter (operator)",
+		)
+	}
+
+	res := types.MakeArray(0)
+
+	for i := 0; i < arr.Len() && (limit == 0 || res.Len() < limit); i++ {
+		v := must.NotFail(arr.Get(i))
+
+		c, err := evaluateWithVariables(f.cond, doc, map[string]any{f.as: v})
+		if err != nil {
+			return nil, err
+		}
+
+		if isTruthy(c) {
+			res.Append(v)
+		}
+	}
+
+	return res, nil
+}
+
+// processLimit evaluates `limit` argument; it returns 0 if it is not set, null or missing.
+func (f *filter) processLimit(doc *types.Document) (int, error) {
+	if f.limit == nil {
+		return 0, nil
+	}
+
+	v, err := evaluate(f.limit, doc)
+	if err != nil {
+		return 0, err
+	}
+
+	if isNullish(v) {
+		return 0, nil
+	}
+
+	var limit int64
+
+	switch l := v.(type) {
+	case float64, types.Decimal128:
+		n := toFloat64(l)
+		if n != math.Trunc(n) || n < math.MinInt32 || n > math.MaxInt32 {
+			return 0, f.limitError(v)
+		}
+
+		limit = int64(n)
+	case int32:
+		limit = int64(l)
+	case int64:
+		limit = l
+	default:
+		return 0, f.limitError(v)
+	}
+
+	if limit < math.MinInt32 || limit > math.MaxInt32 {
+		return 0, f.limitError(v)
+	}
+
+	if limit <= 0 {
+		return 0, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFilterLimitNotPositive,
+			fmt.Sprintf("$filter: limit must be greater than 0: %d", limit),
+			"$filter (operator)",
+		)
+	}
+
+	return int(limit), nil
+}
+
+// limitError returns an error for `limit` argument that is not a 32-bit integral value.
+func (f *filter) limitError(v any) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(
+		handlererrors.ErrFilterLimitNotInt,
+		fmt.Sprintf("$filter: limit must be represented as a 32-bit integral value: %s", types.FormatAnyValue(v)),
+		"$filter (operator)",
+	)
+}
+
+// check interfaces
+var (
+	_ Operator = (*filter)(nil)
+)
@@ -0,0 +1,171 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// getField represents `$getField` operator.
+type getField struct {
+	field string
+	input any
+}
+
+// newGetField returns `$getField` operator.
+func newGetField(args ...any) (Operator, error) {
+	var fieldArg any
+	input := any("$$CURRENT")
+
+	var doc *types.Document
+	if len(args) == 1 {
+		doc, _ = args[0].(*types.Document)
+	}
+
+	switch {
+	case doc != nil && !IsOperator(doc):
+		fields, unknown, _, err := operatorFields(args, "field", "input")
+		if err != nil {
+			return nil, err
+		}
+
+		if unknown != "" {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrGetFieldUnknownField,
+				fmt.Sprintf("$getField found an unknown argument: %s", unknown),
+				"$getField (operator)",
+			)
+		}
+
+		var ok bool
+		if fieldArg, ok = fields["field"]; !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrGetFieldMissingField,
+				"$getField requires 'field' to be specified",
+				"$getField (operator)",
+			)
+		}
+
+		if input, ok = fields["input"]; !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrGetFieldMissingInput,
+				"$getField requires 'input' to be specified",
+				"$getField (operator)",
+			)
+		}
+
+	case len(args) == 1:
+		// shorthand form, like `{$getField: "a.b"}`
+		fieldArg = args[0]
+
+	default:
+		fieldArg = must.NotFail(types.NewArray(args...))
+	}
+
+	field, err := constantFieldName(
+		fieldArg,
+		"$getField",
+		handlererrors.ErrGetFieldNonConstantField,
+		handlererrors.ErrGetFieldFieldNotString,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &getField{
+		field: field,
+		input: input,
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the value of the field with the given name as is,
+// even if that name contains dots or starts with a dollar sign.
+// If the input or the field is null or missing, it returns null.
+func (g *getField) Process(doc *types.Document) (any, error) {
+	input, err := evaluate(g.input, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNullish(input) {
+		return types.Null, nil
+	}
+
+	d, ok := input.(*types.Document)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrGetFieldInputNotObject,
+			fmt.Sprintf("$getField requires 'input' to evaluate to type Object, but got %s", typeAlias(input)),
+			"$getField (operator)",
+		)
+	}
+
+	v, err := d.Get(g.field)
+	if err != nil {
+		return types.Null, nil
+	}
+
+	return v, nil
+}
+
+// constantFieldName returns the field name given by the constant `field` argument
+// of `$getField`, `$setField` and `$unsetField`.
+//
+// Strings starting with a dollar sign are field paths, so such names should be wrapped in `$literal`.
+func constantFieldName(arg any, operator string, nonConstantCode, notStringCode handlererrors.ErrorCode) (string, error) {
+	if doc, ok := arg.(*types.Document); ok && doc.Len() == 1 && doc.Command() == "$literal" {
+		arg = must.NotFail(doc.Get("$literal"))
+	} else {
+		var nonConstant bool
+
+		switch arg := arg.(type) {
+		case *types.Document:
+			nonConstant = IsOperator(arg)
+		case string:
+			nonConstant = strings.HasPrefix(arg, "$")
+		}
+
+		if nonConstant {
+			return "", handlererrors.NewCommandErrorMsgWithArgument(
+				nonConstantCode,
+				fmt.Sprintf("%s requires 'field' to evaluate to a constant, but got a non-constant argument", operator),
+				operator+" (operator)",
+			)
+		}
+	}
+
+	field, ok := arg.(string)
+	if !ok {
+		return "", handlererrors.NewCommandErrorMsgWithArgument(
+			notStringCode,
+			fmt.Sprintf("%s requires 'field' to evaluate to type String, but got %s", operator, typeAlias(arg)),
+			operator+" (operator)",
+		)
+	}
+
+	return field, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*getField)(nil)
+)
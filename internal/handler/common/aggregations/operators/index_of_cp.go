@@ -0,0 +1,173 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// indexOfCP represents `$indexOfCP` operator.
+type indexOfCP struct {
+	str       any
+	substring any
+	start     any // nil if not set
+	end       any // nil if not set
+}
+
+// newIndexOfCP returns `$indexOfCP` operator.
+func newIndexOfCP(args ...any) (Operator, error) {
+	if len(args) < 2 || len(args) > 4 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			"$indexOfCP",
+			fmt.Sprintf(
+				"Expression $indexOfCP takes at least 2 arguments, and at most 4, but %d were passed in.",
+				len(args),
+			),
+		)
+	}
+
+	op := &indexOfCP{
+		str:       args[0],
+		substring: args[1],
+	}
+
+	if len(args) > 2 {
+		op.start = args[2]
+	}
+
+	if len(args) > 3 {
+		op.end = args[3]
+	}
+
+	return op, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the code point index of the first occurrence of the substring
+// within [start, end) code points range of the string, or -1 if there is no occurrence.
+// If the string is null or missing, null is returned.
+func (i *indexOfCP) Process(doc *types.Document) (any, error) {
+	v, err := evaluate(i.str, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNullish(v) {
+		return types.Null, nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrIndexOfCPStringNotString,
+			fmt.Sprintf("$indexOfCP requires a string as the first argument, found: %s", handlerparams.AliasFromType(v)),
+			"$indexOfCP (operator)",
+		)
+	}
+
+	if v, err = evaluate(i.substring, doc); err != nil {
+		return nil, err
+	}
+
+	substring, ok := v.(string)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrIndexOfCPSubstringNotString,
+			fmt.Sprintf("$indexOfCP requires a string as the second argument, found: %s", typeAlias(v)),
+			"$indexOfCP (operator)",
+		)
+	}
+
+	runes := []rune(s)
+
+	start := 0
+	if i.start != nil {
+		if start, err = i.index(i.start, "starting", "start", doc); err != nil {
+			return nil, err
+		}
+	}
+
+	end := len(runes)
+	if i.end != nil {
+		if end, err = i.index(i.end, "ending", "ending", doc); err != nil {
+			return nil, err
+		}
+
+		end = min(end, len(runes))
+	}
+
+	if start > end {
+		return int32(-1), nil
+	}
+
+	sub := []rune(substring)
+
+	for pos := start; pos+len(sub) <= end; pos++ {
+		if string(runes[pos:pos+len(sub)]) == substring {
+			return int32(pos), nil
+		}
+	}
+
+	return int32(-1), nil
+}
+
+// index evaluates and validates the start or end index argument.
+func (i *indexOfCP) index(expr any, integralName, negativeName string, doc *types.Document) (int, error) {
+	v, err := evaluate(expr, doc)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := handlerparams.GetWholeNumberParam(v)
+	if err != nil || n > math.MaxInt32 {
+		alias := typeAlias(v)
+
+		// missing value is formatted as null
+		if v == nil {
+			v = types.Null
+		}
+
+		return 0, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrIndexOfCPIndexNotIntegral,
+			fmt.Sprintf(
+				"$indexOfCP requires an integral %s index, found a value of type: %s, with value: %s",
+				integralName, alias, types.FormatAnyValue(v),
+			),
+			"$indexOfCP (operator)",
+		)
+	}
+
+	if n < 0 {
+		return 0, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrIndexOfCPIndexNegative,
+			fmt.Sprintf("$indexOfCP requires a nonnegative %s index, found: %d", negativeName, n),
+			"$indexOfCP (operator)",
+		)
+	}
+
+	return int(n), nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*indexOfCP)(nil)
+)
@@ -0,0 +1,135 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// let represents `$let` operator.
+type let struct {
+	vars *types.Document
+	in   any
+}
+
+// newLet returns `$let` operator.
+func newLet(args ...any) (Operator, error) {
+	fields, unknown, ok, err := operatorFields(args, "vars", "in")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !ok:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrLetBadArgument,
+			"$let only supports an object as its argument",
+			"$let (operator)",
+		)
+	case unknown != "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrLetUnknownField,
+			fmt.Sprintf("Unrecognized parameter to $let: %s", unknown),
+			"$let (operator)",
+		)
+	}
+
+	v, ok := fields["vars"]
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrLetMissingVars,
+			"Missing 'vars' parameter to $let",
+			"$let (operator)",
+		)
+	}
+
+	if _, ok = fields["in"]; !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrLetMissingIn,
+			"Missing 'in' parameter to $let",
+			"$let (operator)",
+		)
+	}
+
+	vars, ok := v.(*types.Document)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrIndexesWrongType,
+			"invalid parameter: expected an object (vars)",
+			"$let (operator)",
+		)
+	}
+
+	for _, name := range vars.Keys() {
+		if err = validateVariableName(name, "$let"); err != nil {
+			return nil, err
+		}
+	}
+
+	return &let{
+		vars: vars,
+		in:   fields["in"],
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It evaluates `vars` expressions in the outer scope,
+// then evaluates `in` expression with those variables bound to their values.
+// Variables of the outer scope with the same names are shadowed.
+func (l *let) Process(doc *types.Document) (any, error) {
+	vars := make(map[string]any, l.vars.Len())
+
+	iter := l.vars.Iterator()
+	defer iter.Close()
+
+	for {
+		name, expr, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if vars[name], err = evaluate(expr, doc); err != nil {
+			return nil, err
+		}
+	}
+
+	return evaluateWithVariables(l.in, doc, vars)
+}
+
+// letVariableNames returns the names of the variables defined by `vars` argument of `$let`.
+func letVariableNames(args *types.Document) []string {
+	vars, _ := args.Get("vars")
+	if vars, ok := vars.(*types.Document); ok {
+		return vars.Keys()
+	}
+
+	return nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*let)(nil)
+)
@@ -0,0 +1,45 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// literal represents `$literal` operator.
+type literal struct {
+	value any
+}
+
+// newLiteral returns `$literal` operator.
+//
+// Unlike other operators, its argument is not flattened by NewOperator.
+func newLiteral(args ...any) (Operator, error) {
+	return &literal{
+		value: args[0],
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the argument as is, without evaluating it.
+func (l *literal) Process(*types.Document) (any, error) {
+	return l.value, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*literal)(nil)
+)
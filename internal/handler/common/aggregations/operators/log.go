@@ -0,0 +1,115 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// log represents `$log` operator.
+type log struct {
+	number any
+	base   any
+}
+
+// newLog returns `$log` operator.
+func newLog(args ...any) (Operator, error) {
+	if len(args) != 2 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			"$log",
+			fmt.Sprintf("Expression $log takes exactly 2 arguments. %d were passed in.", len(args)),
+		)
+	}
+
+	return &log{
+		number: args[0],
+		base:   args[1],
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the logarithm of the number in the given base
+// as Decimal128 if any argument is Decimal128, and as double otherwise.
+// Decimal128 value is computed with double precision.
+// If any argument is null or missing, null is returned.
+func (l *log) Process(doc *types.Document) (any, error) {
+	number, err := evaluate(l.number, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := evaluate(l.base, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNullish(number) || isNullish(base) {
+		return types.Null, nil
+	}
+
+	if !isNumber(number) {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrLogArgNotNumeric,
+			fmt.Sprintf("$log's argument must be numeric, not %s", handlerparams.AliasFromType(number)),
+			"$log (operator)",
+		)
+	}
+
+	if !isNumber(base) {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrLogBaseNotNumeric,
+			fmt.Sprintf("$log's base must be numeric, not %s", handlerparams.AliasFromType(base)),
+			"$log (operator)",
+		)
+	}
+
+	n, b := toFloat64(number), toFloat64(base)
+
+	if n <= 0 {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrLogArgNotPositive,
+			fmt.Sprintf("$log's argument must be a positive number, but is %s", types.FormatAnyValue(number)),
+			"$log (operator)",
+		)
+	}
+
+	if b <= 0 || b == 1 {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrLogInvalidBase,
+			fmt.Sprintf("$log's base must be a positive number not equal to 1, but is %s", types.FormatAnyValue(base)),
+			"$log (operator)",
+		)
+	}
+
+	res := math.Log(n) / math.Log(b)
+
+	if hasType[types.Decimal128](number, base) {
+		return types.NewDecimal128FromFloat64(res), nil
+	}
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*log)(nil)
+)
@@ -0,0 +1,128 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// mapOp represents `$map` operator.
+type mapOp struct {
+	input any
+	as    string
+	in    any
+}
+
+// newMap returns `$map` operator.
+func newMap(args ...any) (Operator, error) {
+	fields, unknown, ok, err := operatorFields(args, "input", "as", "in")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !ok:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrMapBadArgument,
+			"$map only supports an object as its argument",
+			"$map (operator)",
+		)
+	case unknown != "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrMapUnknownField,
+			fmt.Sprintf("Unrecognized parameter to $map: %s", unknown),
+			"$map (operator)",
+		)
+	}
+
+	if _, ok = fields["input"]; !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrMapMissingInput,
+			"Missing 'input' parameter to $map",
+			"$map (operator)",
+		)
+	}
+
+	if _, ok = fields["in"]; !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrMapMissingIn,
+			"Missing 'in' parameter to $map",
+			"$map (operator)",
+		)
+	}
+
+	as, err := asVariableName(fields, "$map")
+	if err != nil {
+		return nil, err
+	}
+
+	return &mapOp{
+		input: fields["input"],
+		as:    as,
+		in:    fields["in"],
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns an array of results of `in` expression evaluated for each element of the input array,
+// with the element bound to the variable named by `as` (`this` by default).
+// If the input is null or missing, it returns null.
+func (m *mapOp) Process(doc *types.Document) (any, error) {
+	input, err := evaluate(m.input, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNullish(input) {
+		return types.Null, nil
+	}
+
+	arr, ok := input.(*types.Array)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrMapInputNotArray,
+			fmt.Sprintf("input to $map must be an array not %s", typeAlias(input)),
+			"$map (operator)",
+		)
+	}
+
+	res := types.MakeArray(arr.Len())
+
+	for i := 0; i < arr.Len(); i++ {
+		v, err := evaluateWithVariables(m.in, doc, map[string]any{m.as: must.NotFail(arr.Get(i))})
+		if err != nil {
+			return nil, err
+		}
+
+		// missing values are null in arrays
+		if v == nil {
+			v = types.Null
+		}
+
+		res.Append(v)
+	}
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*mapOp)(nil)
+)
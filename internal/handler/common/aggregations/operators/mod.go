@@ -0,0 +1,111 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// mod represents `$mod` operator.
+type mod struct {
+	dividend any
+	divisor  any
+}
+
+// newMod returns `$mod` operator.
+func newMod(args ...any) (Operator, error) {
+	if len(args) != 2 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			"$mod",
+			fmt.Sprintf("Expression $mod takes exactly 2 arguments. %d were passed in.", len(args)),
+		)
+	}
+
+	return &mod{
+		dividend: args[0],
+		divisor:  args[1],
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the remainder of the division truncated towards zero with the widest type of arguments.
+// If any argument is null or missing, null is returned.
+func (m *mod) Process(doc *types.Document) (any, error) {
+	dividend, err := evaluate(m.dividend, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	divisor, err := evaluate(m.divisor, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case isNumber(dividend) && isNumber(divisor):
+		// handled below
+	case isNullish(dividend) || isNullish(divisor):
+		return types.Null, nil
+	default:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrModNotNumeric,
+			fmt.Sprintf(
+				"$mod only supports numeric types, not %s and %s",
+				typeAlias(dividend), typeAlias(divisor),
+			),
+			"$mod (operator)",
+		)
+	}
+
+	if isZero(divisor) {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrModByZero,
+			"can't $mod by zero",
+			"$mod (operator)",
+		)
+	}
+
+	switch {
+	case hasType[types.Decimal128](dividend, divisor):
+		return toDecimal128(dividend).Rem(toDecimal128(divisor)), nil
+	case hasType[float64](dividend, divisor):
+		return math.Mod(toFloat64(dividend), toFloat64(divisor)), nil
+	}
+
+	a, b := toInt64(dividend), toInt64(divisor)
+
+	// avoid overflow of math.MinInt64 % -1
+	var res int64
+	if b != -1 {
+		res = a % b
+	}
+
+	if hasType[int64](dividend, divisor) {
+		return res, nil
+	}
+
+	return int32(res), nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*mod)(nil)
+)
@@ -0,0 +1,98 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// multiply represents `$multiply` operator.
+type multiply struct {
+	args []any
+}
+
+// newMultiply returns `$multiply` operator.
+func newMultiply(args ...any) (Operator, error) {
+	return &multiply{
+		args: args,
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the product of all arguments.
+// The result is Decimal128 if any argument is Decimal128, double if any argument is double,
+// and an integer of the widest argument type otherwise.
+// Integer overflow of int32 is converted to int64, and overflow of int64 is converted to double.
+// If any argument is null or missing, null is returned.
+func (m *multiply) Process(doc *types.Document) (any, error) {
+	values := make([]any, 0, len(m.args))
+
+	for _, arg := range m.args {
+		v, err := evaluate(arg, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if isNullish(v) {
+			return types.Null, nil
+		}
+
+		if !isNumber(v) {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrMultiplyNotNumeric,
+				fmt.Sprintf("$multiply only supports numeric types, not %s", handlerparams.AliasFromType(v)),
+				"$multiply (operator)",
+			)
+		}
+
+		values = append(values, v)
+	}
+
+	switch {
+	case hasType[types.Decimal128](values...):
+		res := types.NewDecimal128FromInt64(1)
+		for _, v := range values {
+			res = res.Mul(toDecimal128(v))
+		}
+
+		return res, nil
+
+	case hasType[float64](values...):
+		res := float64(1)
+		for _, v := range values {
+			res *= toFloat64(v)
+		}
+
+		return res, nil
+	}
+
+	res := big.NewInt(1)
+	for _, v := range values {
+		res.Mul(res, big.NewInt(toInt64(v)))
+	}
+
+	return integerResult(res, values...), nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*multiply)(nil)
+)
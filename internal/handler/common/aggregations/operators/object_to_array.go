@@ -0,0 +1,151 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// objectToArray represents `$objectToArray` operator.
+type objectToArray struct {
+	param any
+}
+
+// newObjectToArray returns `$objectToArray` operator.
+func newObjectToArray(args ...any) (Operator, error) {
+	if len(args) != 1 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			"$objectToArray",
+			fmt.Sprintf("Expression $objectToArray takes exactly 1 arguments. %d were passed in.", len(args)),
+		)
+	}
+
+	return &objectToArray{
+		param: args[0],
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns an array of documents with `k` and `v` fields for each field of the input document,
+// or null if the input is null or missing.
+func (o *objectToArray) Process(doc *types.Document) (any, error) {
+	var value any
+
+	switch param := o.param.(type) {
+	case *types.Document:
+		if !IsOperator(param) {
+			value = param
+			break
+		}
+
+		operator, err := NewOperator(param)
+		if err != nil {
+			var opErr OperatorError
+			if !errors.As(err, &opErr) {
+				return nil, lazyerrors.Error(err)
+			}
+
+			if opErr.Code() == ErrInvalidExpression {
+				opErr.code = ErrInvalidNestedExpression
+			}
+
+			return nil, opErr
+		}
+
+		if value, err = operator.Process(doc); err != nil {
+			return nil, err
+		}
+
+	case string:
+		// variables are not supported by expressions yet,
+		// but schema analysis pipelines of tools like Compass use that one
+		// TODO https://github.com/FerretDB/FerretDB/issues/2275
+		if param == "$$ROOT" || param == "$$CURRENT" {
+			value = doc
+			break
+		}
+
+		if !strings.HasPrefix(param, "$") {
+			value = param
+			break
+		}
+
+		expression, err := aggregations.NewExpression(param, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if value, err = expression.Evaluate(doc); err != nil {
+			// missing field
+			return types.Null, nil
+		}
+
+	default:
+		value = param
+	}
+
+	switch value := value.(type) {
+	case *types.Document:
+		res := types.MakeArray(value.Len())
+
+		iter := value.Iterator()
+		defer iter.Close()
+
+		for {
+			k, v, err := iter.Next()
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			res.Append(must.NotFail(types.NewDocument("k", k, "v", v)))
+		}
+
+		return res, nil
+
+	case types.NullType:
+		return types.Null, nil
+
+	default:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrObjectToArrayNotDocument,
+			fmt.Sprintf(
+				"$objectToArray requires a document input, found: %s",
+				handlerparams.AliasFromType(value),
+			),
+			"$objectToArray (operator)",
+		)
+	}
+}
+
+// check interfaces
+var (
+	_ Operator = (*objectToArray)(nil)
+)
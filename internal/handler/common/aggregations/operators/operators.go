@@ -101,7 +101,8 @@ func NewOperator(doc *types.Document) (Operator, error) {
 
 	var args []any
 
-	if arr, ok := expr.(*types.Array); ok {
+	// `$literal` argument is never evaluated, so it is passed as is
+	if arr, ok := expr.(*types.Array); ok && operator != "$literal" {
 		iter := arr.Iterator()
 		defer iter.Close()
 
@@ -139,8 +140,61 @@ func NewOperator(doc *types.Document) (Operator, error) {
 // Operators maps all standard aggregation operators.
 var Operators = map[string]newOperatorFunc{
 	// sorted alphabetically
-	"$sum":  newSum,
-	"$type": newType,
+	"$and":            newAnd,
+	"$arrayToObject":  newArrayToObject,
+	"$cmp":            newCmp,
+	"$cond":           newCond,
+	"$convert":        newConvert,
+	"$dateFromParts":  newDateFromParts,
+	"$dateFromString": newDateFromString,
+	"$dateToParts":    newDateToParts,
+	"$dateToString":   newDateToString,
+	"$divide":         newDivide,
+	"$eq":             newEq,
+	"$filter":         newFilter,
+	"$getField":       newGetField,
+	"$gt":             newGt,
+	"$gte":            newGte,
+	"$indexOfCP":      newIndexOfCP,
+	"$let":            newLet,
+	"$literal":        newLiteral,
+	"$log":            newLog,
+	"$lt":             newLt,
+	"$lte":            newLte,
+	"$ltrim":          newLTrim,
+	"$map":            newMap,
+	"$mod":            newMod,
+	"$multiply":       newMultiply,
+	"$ne":             newNe,
+	"$not":            newNot,
+	"$objectToArray":  newObjectToArray,
+	"$or":             newOr,
+	"$pow":            newPow,
+	"$reduce":         newReduce,
+	"$regexFind":      newRegexFind,
+	"$regexMatch":     newRegexMatch,
+	"$replaceAll":     newReplaceAll,
+	"$replaceOne":     newReplaceOne,
+	"$round":          newRound,
+	"$rtrim":          newRTrim,
+	"$setField":       newSetField,
+	"$split":          newSplit,
+	"$sqrt":           newSqrt,
+	"$sum":            newSum,
+	"$switch":         newSwitch,
+	"$toBool":         newToBool,
+	"$toDate":         newToDate,
+	"$toDecimal":      newToDecimal,
+	"$toDouble":       newToDouble,
+	"$toInt":          newToInt,
+	"$toLong":         newToLong,
+	"$toObjectId":     newToObjectID,
+	"$toString":       newToString,
+	"$trim":           newTrim,
+	"$trunc":          newTrunc,
+	"$type":           newType,
+	"$unsetField":     newUnsetField,
+	"$zip":            newZip,
 	// please keep sorted alphabetically
 }
 
@@ -152,10 +206,8 @@ var unsupportedOperators = map[string]struct{}{
 	"$acosh":            {},
 	"$add":              {},
 	"$allElementsTrue":  {},
-	"$and":              {},
 	"$anyElementTrue":   {},
 	"$arrayElemAt":      {},
-	"$arrayToObject":    {},
 	"$asin":             {},
 	"$asinh":            {},
 	"$atan":             {},
@@ -165,95 +217,59 @@ var unsupportedOperators = map[string]struct{}{
 	"$binarySize":       {},
 	"$bsonSize":         {},
 	"$ceil":             {},
-	"$cmp":              {},
 	"$concat":           {},
 	"$concatArrays":     {},
-	"$cond":             {},
-	"$convert":          {},
 	"$cos":              {},
 	"$cosh":             {},
 	"$covariancePop":    {},
 	"$covarianceSamp":   {},
 	"$dateAdd":          {},
 	"$dateDiff":         {},
-	"$dateFromParts":    {},
 	"$dateSubtract":     {},
 	"$dateTrunc":        {},
-	"$dateToParts":      {},
-	"$dateFromString":   {},
-	"$dateToString":     {},
 	"$dayOfMonth":       {},
 	"$dayOfWeek":        {},
 	"$dayOfYear":        {},
 	"$degreesToRadians": {},
 	"$denseRank":        {},
 	"$derivative":       {},
-	"$divide":           {},
 	"$documentNumber":   {},
-	"$eq":               {},
 	"$exp":              {},
 	"$expMovingAvg":     {},
-	"$filter":           {},
 	"$floor":            {},
 	"$function":         {},
-	"$getField":         {},
-	"$gt":               {},
-	"$gte":              {},
 	"$hour":             {},
 	"$ifNull":           {},
 	"$in":               {},
 	"$indexOfArray":     {},
 	"$indexOfBytes":     {},
-	"$indexOfCP":        {},
 	"$integral":         {},
 	"$isArray":          {},
 	"$isNumber":         {},
 	"$isoDayOfWeek":     {},
 	"$isoWeek":          {},
 	"$isoWeekYear":      {},
-	"$let":              {},
 	"$linearFill":       {},
-	"$literal":          {},
 	"$ln":               {},
 	"$locf":             {},
-	"$log":              {},
 	"$log10":            {},
-	"$lt":               {},
-	"$lte":              {},
-	"$ltrim":            {},
-	"$map":              {},
 	"$max":              {},
 	"$meta":             {},
 	"$min":              {},
 	"$minN":             {},
 	"$millisecond":      {},
 	"$minute":           {},
-	"$mod":              {},
 	"$month":            {},
-	"$multiply":         {},
-	"$ne":               {},
-	"$not":              {},
-	"$objectToArray":    {},
-	"$or":               {},
-	"$pow":              {},
 	"$radiansToDegrees": {},
 	"$rand":             {},
 	"$range":            {},
 	"$rank":             {},
-	"$reduce":           {},
-	"$regexFind":        {},
 	"$regexFindAll":     {},
-	"$regexMatch":       {},
-	"$replaceOne":       {},
-	"$replaceAll":       {},
 	"$reverseArray":     {},
-	"$round":            {},
-	"$rtrim":            {},
 	"$sampleRate":       {},
 	"$second":           {},
 	"$setDifference":    {},
 	"$setEquals":        {},
-	"$setField":         {},
 	"$setIntersection":  {},
 	"$setIsSubset":      {},
 	"$setUnion":         {},
@@ -263,8 +279,6 @@ var unsupportedOperators = map[string]struct{}{
 	"$sinh":             {},
 	"$slice":            {},
 	"$sortArray":        {},
-	"$split":            {},
-	"$sqrt":             {},
 	"$stdDevPop":        {},
 	"$stdDevSamp":       {},
 	"$strcasecmp":       {},
@@ -274,26 +288,13 @@ var unsupportedOperators = map[string]struct{}{
 	"$substrBytes":      {},
 	"$substrCP":         {},
 	"$subtract":         {},
-	"$switch":           {},
 	"$tan":              {},
 	"$tanh":             {},
-	"$toBool":           {},
-	"$toDate":           {},
-	"$toDecimal":        {},
-	"$toDouble":         {},
-	"$toInt":            {},
-	"$toLong":           {},
-	"$toObjectId":       {},
-	"$toString":         {},
 	"$toLower":          {},
 	"$toUpper":          {},
-	"$trim":             {},
-	"$trunc":            {},
 	"$tsIncrement":      {},
 	"$tsSecond":         {},
-	"$unsetField":       {},
 	"$week":             {},
 	"$year":             {},
-	"$zip":              {},
 	// please keep sorted alphabetically
 }
@@ -0,0 +1,125 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// pow represents `$pow` operator.
+type pow struct {
+	base     any
+	exponent any
+}
+
+// newPow returns `$pow` operator.
+func newPow(args ...any) (Operator, error) {
+	if len(args) != 2 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			"$pow",
+			fmt.Sprintf("Expression $pow takes exactly 2 arguments. %d were passed in.", len(args)),
+		)
+	}
+
+	return &pow{
+		base:     args[0],
+		exponent: args[1],
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the base raised to the exponent.
+// The result is Decimal128 if any argument is Decimal128, and double if any argument is double.
+// For integer arguments, the result is an integer of the widest argument type if it could be represented,
+// and double otherwise.
+// Decimal128 value is computed with double precision.
+// If any argument is null or missing, null is returned.
+func (p *pow) Process(doc *types.Document) (any, error) {
+	base, err := evaluate(p.base, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent, err := evaluate(p.exponent, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNullish(base) || isNullish(exponent) {
+		return types.Null, nil
+	}
+
+	if !isNumber(base) {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrPowBaseNotNumeric,
+			fmt.Sprintf("$pow's base must be numeric, not %s", handlerparams.AliasFromType(base)),
+			"$pow (operator)",
+		)
+	}
+
+	if !isNumber(exponent) {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrPowExponentNotNumeric,
+			fmt.Sprintf("$pow's exponent must be numeric, not %s", handlerparams.AliasFromType(exponent)),
+			"$pow (operator)",
+		)
+	}
+
+	if isZero(base) && toFloat64(exponent) < 0 {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrPowZeroNegativeExponent,
+			"$pow cannot take a base of 0 and a negative exponent",
+			"$pow (operator)",
+		)
+	}
+
+	switch {
+	case hasType[types.Decimal128](base, exponent):
+		return types.NewDecimal128FromFloat64(math.Pow(toFloat64(base), toFloat64(exponent))), nil
+	case hasType[float64](base, exponent):
+		return math.Pow(toFloat64(base), toFloat64(exponent)), nil
+	}
+
+	b, e := toInt64(base), toInt64(exponent)
+
+	switch {
+	case b == 1:
+		return integerResult(big.NewInt(1), base, exponent), nil
+	case b == -1:
+		return integerResult(big.NewInt(1-2*(e&1)), base, exponent), nil
+	case e < 0:
+		return math.Pow(float64(b), float64(e)), nil
+	case b != 0 && e >= 64:
+		// the result does not fit into int64
+		return math.Pow(float64(b), float64(e)), nil
+	}
+
+	res := new(big.Int).Exp(big.NewInt(b), big.NewInt(e), nil)
+
+	return integerResult(res, base, exponent), nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*pow)(nil)
+)
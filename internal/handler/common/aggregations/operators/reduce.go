@@ -0,0 +1,128 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// reduce represents `$reduce` operator.
+type reduce struct {
+	input        any
+	initialValue any
+	in           any
+}
+
+// newReduce returns `$reduce` operator.
+func newReduce(args ...any) (Operator, error) {
+	fields, unknown, ok, err := operatorFields(args, "input", "initialValue", "in")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !ok:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrReduceBadArgument,
+			fmt.Sprintf("$reduce requires an object as an argument, found: %s", argsTypeAlias(args)),
+			"$reduce (operator)",
+		)
+	case unknown != "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrReduceUnknownField,
+			fmt.Sprintf("$reduce found an unknown argument: %s", unknown),
+			"$reduce (operator)",
+		)
+	}
+
+	for _, f := range []struct {
+		name string
+		code handlererrors.ErrorCode
+	}{
+		{"input", handlererrors.ErrReduceMissingInput},
+		{"initialValue", handlererrors.ErrReduceMissingInitialValue},
+		{"in", handlererrors.ErrReduceMissingIn},
+	} {
+		if _, ok = fields[f.name]; !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				f.code,
+				fmt.Sprintf("$reduce requires '%s' to be specified", f.name),
+				"$reduce (operator)",
+			)
+		}
+	}
+
+	return &reduce{
+		input:        fields["input"],
+		initialValue: fields["initialValue"],
+		in:           fields["in"],
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It applies `in` expression to each element of the input array, with the element bound to `this` variable,
+// and the result of the previous application (or `initialValue` for the first element) bound to `value` variable.
+// If the input is null or missing, it returns null.
+func (r *reduce) Process(doc *types.Document) (any, error) {
+	input, err := evaluate(r.input, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNullish(input) {
+		return types.Null, nil
+	}
+
+	arr, ok := input.(*types.Array)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrReduceInputNotArray,
+			fmt.Sprintf("$reduce requires that 'input' be an array, found: %s", types.FormatAnyValue(input)),
+			"$reduce (operator)",
+		)
+	}
+
+	value, err := evaluate(r.initialValue, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < arr.Len(); i++ {
+		vars := map[string]any{
+			"this":  must.NotFail(arr.Get(i)),
+			"value": value,
+		}
+
+		if value, err = evaluateWithVariables(r.in, doc, vars); err != nil {
+			return nil, err
+		}
+	}
+
+	if value == nil {
+		return types.Null, nil
+	}
+
+	return value, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*reduce)(nil)
+)
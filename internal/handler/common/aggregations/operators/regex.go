@@ -0,0 +1,247 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// regex represents `$regexMatch` and `$regexFind` operators.
+type regex struct {
+	input   any
+	regex   any
+	options any // nil if not set
+	name    string
+	find    bool
+}
+
+// newRegexMatch returns `$regexMatch` operator.
+func newRegexMatch(args ...any) (Operator, error) {
+	return newRegex("$regexMatch", false, args)
+}
+
+// newRegexFind returns `$regexFind` operator.
+func newRegexFind(args ...any) (Operator, error) {
+	return newRegex("$regexFind", true, args)
+}
+
+// newRegex returns `$regexMatch` or `$regexFind` operator with the given name.
+func newRegex(name string, find bool, args []any) (Operator, error) {
+	fields, unknown, ok, err := operatorFields(args, "input", "regex", "options")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !ok:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrRegexInvalidArg,
+			fmt.Sprintf("%s expects an object of named arguments but found: %s", name, argsTypeAlias(args)),
+			name+" (operator)",
+		)
+	case unknown != "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrRegexUnknownArg,
+			fmt.Sprintf("%s found an unknown argument: %s", name, unknown),
+			name+" (operator)",
+		)
+	}
+
+	if _, ok = fields["input"]; !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrRegexMissingInput,
+			fmt.Sprintf("%s requires 'input' parameter", name),
+			name+" (operator)",
+		)
+	}
+
+	if _, ok = fields["regex"]; !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrRegexMissingRegex,
+			fmt.Sprintf("%s requires 'regex' parameter", name),
+			name+" (operator)",
+		)
+	}
+
+	return &regex{
+		input:   fields["input"],
+		regex:   fields["regex"],
+		options: fields["options"],
+		name:    name,
+		find:    find,
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// `$regexMatch` returns true if the input matches the regular expression, and false otherwise.
+// `$regexFind` returns a document with the matched string, its code point index and captures
+// of the first match, or null if there is no match.
+// If input or regex is null or missing, there is no match.
+func (r *regex) Process(doc *types.Document) (any, error) {
+	input, err := evaluate(r.input, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	re, err := r.compile(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isNullish(input) {
+		if _, ok := input.(string); !ok {
+			return nil, r.error(handlererrors.ErrRegexInputNotString, "%s needs 'input' to be of type string", r.name)
+		}
+	}
+
+	s, _ := input.(string)
+
+	if re == nil || isNullish(input) {
+		if r.find {
+			return types.Null, nil
+		}
+
+		return false, nil
+	}
+
+	if !r.find {
+		return re.MatchString(s), nil
+	}
+
+	loc := re.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return types.Null, nil
+	}
+
+	captures := types.MakeArray(len(loc)/2 - 1)
+
+	for i := 2; i < len(loc); i += 2 {
+		if loc[i] < 0 {
+			captures.Append(types.Null)
+			continue
+		}
+
+		captures.Append(s[loc[i]:loc[i+1]])
+	}
+
+	return must.NotFail(types.NewDocument(
+		"match", s[loc[0]:loc[1]],
+		"idx", int32(utf8.RuneCountInString(s[:loc[0]])),
+		"captures", captures,
+	)), nil
+}
+
+// compile evaluates regex and options and returns the compiled regular expression,
+// or nil if regex is null or missing.
+func (r *regex) compile(doc *types.Document) (*regexp.Regexp, error) {
+	v, err := evaluate(r.regex, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var pattern, options string
+
+	switch v := v.(type) {
+	case nil, types.NullType:
+		// options are still validated below
+	case string:
+		pattern = v
+	case types.Regex:
+		pattern, options = v.Pattern, v.Options
+	default:
+		return nil, r.error(handlererrors.ErrRegexRegexNotString, "%s needs 'regex' to be of type string or regex", r.name)
+	}
+
+	if r.options != nil {
+		var o any
+		if o, err = evaluate(r.options, doc); err != nil {
+			return nil, err
+		}
+
+		switch o := o.(type) {
+		case nil, types.NullType:
+		case string:
+			if options != "" && o != "" {
+				return nil, r.error(
+					handlererrors.ErrRegexOptionsConflict,
+					"%s found regex option(s) specified in both 'regex' and 'option' fields",
+					r.name,
+				)
+			}
+
+			if o != "" {
+				options = o
+			}
+		default:
+			return nil, r.error(handlererrors.ErrRegexOptionsNotString, "%s needs 'options' to be of type string", r.name)
+		}
+	}
+
+	if strings.ContainsRune(options, 0) {
+		return nil, r.error(
+			handlererrors.ErrRegexOptionsNullByte,
+			"%s: regular expression options cannot contain an embedded null byte",
+			r.name,
+		)
+	}
+
+	for _, o := range options {
+		switch o {
+		case 'i', 'm', 's':
+		case 'x':
+			// TODO https://github.com/FerretDB/FerretDB/issues/592
+			return nil, r.error(handlererrors.ErrNotImplemented, "%s: option 'x' not implemented", r.name)
+		default:
+			return nil, r.error(handlererrors.ErrBadRegexOption, "%s invalid flag in regex options: %c", r.name, o)
+		}
+	}
+
+	if isNullish(v) {
+		return nil, nil
+	}
+
+	if strings.ContainsRune(pattern, 0) {
+		return nil, r.error(
+			handlererrors.ErrRegexNullByte,
+			"%s: regular expression cannot contain an embedded null byte",
+			r.name,
+		)
+	}
+
+	re, err := types.Regex{Pattern: pattern, Options: options}.Compile()
+	if err != nil {
+		return nil, r.error(handlererrors.ErrRegexInvalid, "Invalid Regex in %s: %s", r.name, err)
+	}
+
+	return re, nil
+}
+
+// error returns a command error with the given code and formatted message for that operator.
+func (r *regex) error(code handlererrors.ErrorCode, format string, a ...any) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(code, fmt.Sprintf(format, a...), r.name+" (operator)")
+}
+
+// check interfaces
+var (
+	_ Operator = (*regex)(nil)
+)
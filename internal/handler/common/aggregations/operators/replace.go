@@ -0,0 +1,151 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// replace represents `$replaceOne` and `$replaceAll` operators.
+type replace struct {
+	input       any
+	find        any
+	replacement any
+	name        string
+	all         bool
+}
+
+// newReplaceOne returns `$replaceOne` operator.
+func newReplaceOne(args ...any) (Operator, error) {
+	return newReplace("$replaceOne", false, args)
+}
+
+// newReplaceAll returns `$replaceAll` operator.
+func newReplaceAll(args ...any) (Operator, error) {
+	return newReplace("$replaceAll", true, args)
+}
+
+// newReplace returns `$replaceOne` or `$replaceAll` operator with the given name.
+func newReplace(name string, all bool, args []any) (Operator, error) {
+	fields, unknown, ok, err := operatorFields(args, "input", "find", "replacement")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !ok:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrReplaceInvalidArg,
+			fmt.Sprintf("%s requires an object as an argument, found: %s", name, argsTypeAlias(args)),
+			name+" (operator)",
+		)
+	case unknown != "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrReplaceUnknownArg,
+			fmt.Sprintf("%s found an unknown argument: %s", name, unknown),
+			name+" (operator)",
+		)
+	}
+
+	for _, f := range []struct {
+		name string
+		code handlererrors.ErrorCode
+	}{
+		{"input", handlererrors.ErrReplaceMissingInput},
+		{"find", handlererrors.ErrReplaceMissingFind},
+		{"replacement", handlererrors.ErrReplaceMissingReplacement},
+	} {
+		if _, ok = fields[f.name]; !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				f.code,
+				fmt.Sprintf("%s requires '%s' to be specified", name, f.name),
+				name+" (operator)",
+			)
+		}
+	}
+
+	return &replace{
+		input:       fields["input"],
+		find:        fields["find"],
+		replacement: fields["replacement"],
+		name:        name,
+		all:         all,
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the input with the first (`$replaceOne`) or all (`$replaceAll`) occurrences
+// of find replaced by replacement.
+// If any argument is null or missing, null is returned.
+func (r *replace) Process(doc *types.Document) (any, error) {
+	var nullish bool
+
+	values := make([]string, 3)
+
+	for i, f := range []struct {
+		name string
+		expr any
+		code handlererrors.ErrorCode
+	}{
+		{"input", r.input, handlererrors.ErrReplaceInputNotString},
+		{"find", r.find, handlererrors.ErrReplaceFindNotString},
+		{"replacement", r.replacement, handlererrors.ErrReplaceReplacementNotString},
+	} {
+		v, err := evaluate(f.expr, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if isNullish(v) {
+			nullish = true
+			continue
+		}
+
+		s, ok := v.(string)
+		if !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				f.code,
+				fmt.Sprintf(
+					"%s requires that '%s' be a string, found: %s",
+					r.name, f.name, types.FormatAnyValue(v),
+				),
+				r.name+" (operator)",
+			)
+		}
+
+		values[i] = s
+	}
+
+	if nullish {
+		return types.Null, nil
+	}
+
+	n := 1
+	if r.all {
+		n = -1
+	}
+
+	return strings.Replace(values[0], values[1], values[2], n), nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*replace)(nil)
+)
@@ -0,0 +1,202 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// round represents `$round` and `$trunc` operators.
+type round struct {
+	number any
+	place  any // nil if not set
+	name   string
+	trunc  bool
+}
+
+// newRound returns `$round` operator.
+func newRound(args ...any) (Operator, error) {
+	return newRoundOperator("$round", false, args)
+}
+
+// newTrunc returns `$trunc` operator.
+func newTrunc(args ...any) (Operator, error) {
+	return newRoundOperator("$trunc", true, args)
+}
+
+// newRoundOperator returns `$round` or `$trunc` operator with the given name.
+func newRoundOperator(name string, trunc bool, args []any) (Operator, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			name,
+			fmt.Sprintf(
+				"Expression %s takes at least 1 arguments, and at most 2, but %d were passed in.",
+				name, len(args),
+			),
+		)
+	}
+
+	op := &round{
+		number: args[0],
+		name:   name,
+		trunc:  trunc,
+	}
+
+	if len(args) > 1 {
+		op.place = args[1]
+	}
+
+	return op, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the number rounded half to even (`$round`) or truncated (`$trunc`)
+// to the given decimal place with the same type.
+// Integer overflow of int32 is converted to int64, and overflow of int64 is converted to double.
+// If any argument is null or missing, null is returned.
+func (r *round) Process(doc *types.Document) (any, error) {
+	number, err := evaluate(r.number, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNullish(number) {
+		return types.Null, nil
+	}
+
+	if !isNumber(number) {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrRoundNotNumeric,
+			fmt.Sprintf("%s only supports numeric types, not %s", r.name, handlerparams.AliasFromType(number)),
+			r.name+" (operator)",
+		)
+	}
+
+	var place int64
+
+	if r.place != nil {
+		var v any
+		if v, err = evaluate(r.place, doc); err != nil {
+			return nil, err
+		}
+
+		if isNullish(v) {
+			return types.Null, nil
+		}
+
+		if place, err = handlerparams.GetWholeNumberParam(v); err != nil {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrRoundPlaceNotIntegral,
+				fmt.Sprintf("precision argument to %s must be a integral value", r.name),
+				r.name+" (operator)",
+			)
+		}
+
+		if place < -20 || place > 100 {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrRoundPlaceOutOfRange,
+				fmt.Sprintf("cannot apply %s with precision value %d value must be in [-20, 100]", r.name, place),
+				r.name+" (operator)",
+			)
+		}
+	}
+
+	switch number := number.(type) {
+	case types.Decimal128:
+		if r.trunc {
+			return number.Trunc(int(place)), nil
+		}
+
+		return number.Round(int(place)), nil
+
+	case float64:
+		if math.IsNaN(number) || math.IsInf(number, 0) || number == 0 {
+			return number, nil
+		}
+
+		scale := new(big.Rat).SetInt(pow10(place))
+		if place < 0 {
+			scale.Inv(scale)
+		}
+
+		v := new(big.Rat).SetFloat64(number)
+		v.Mul(v, scale)
+
+		res := new(big.Rat).SetInt(r.integer(v))
+		res.Quo(res, scale)
+
+		f, _ := res.Float64()
+
+		// keep the sign of zero
+		return math.Copysign(f, number), nil
+
+	default:
+		if place >= 0 {
+			return number, nil
+		}
+
+		scale := pow10(-place)
+
+		v := new(big.Rat).SetFrac(big.NewInt(toInt64(number)), scale)
+
+		res := r.integer(v)
+		res.Mul(res, scale)
+
+		return integerResult(res, number), nil
+	}
+}
+
+// integer returns the rational number rounded half to even or truncated to an integer.
+func (r *round) integer(v *big.Rat) *big.Int {
+	q, rem := new(big.Int).QuoRem(v.Num(), v.Denom(), new(big.Int))
+	if r.trunc || rem.Sign() == 0 {
+		return q
+	}
+
+	one := big.NewInt(int64(v.Sign()))
+
+	switch rem.Abs(rem).Lsh(rem, 1).Cmp(v.Denom()) {
+	case 1:
+		q.Add(q, one)
+	case 0:
+		if q.Bit(0) == 1 {
+			q.Add(q, one)
+		}
+	}
+
+	return q
+}
+
+// pow10 returns 10 raised to the absolute value of n.
+func pow10(n int64) *big.Int {
+	if n < 0 {
+		n = -n
+	}
+
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
+
+// check interfaces
+var (
+	_ Operator = (*round)(nil)
+)
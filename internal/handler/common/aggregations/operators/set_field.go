@@ -0,0 +1,162 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// setField represents `$setField` and `$unsetField` operators.
+type setField struct {
+	operator string
+	field    string
+	input    any
+	value    any // `$$REMOVE` for `$unsetField`
+}
+
+// newSetField returns `$setField` operator.
+func newSetField(args ...any) (Operator, error) {
+	return newSetFieldOperator("$setField", args)
+}
+
+// newUnsetField returns `$unsetField` operator.
+//
+// It is the same as `$setField` with `$$REMOVE` value.
+func newUnsetField(args ...any) (Operator, error) {
+	return newSetFieldOperator("$unsetField", args)
+}
+
+// newSetFieldOperator returns `$setField` or `$unsetField` operator.
+func newSetFieldOperator(operator string, args []any) (Operator, error) {
+	known := []string{"field", "input", "value"}
+	if operator == "$unsetField" {
+		known = known[:2]
+	}
+
+	fields, unknown, ok, err := operatorFields(args, known...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !ok:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSetFieldBadArgument,
+			fmt.Sprintf("%s only supports an object as its argument", operator),
+			operator+" (operator)",
+		)
+	case unknown != "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSetFieldUnknownField,
+			fmt.Sprintf("%s found an unknown argument: %s", operator, unknown),
+			operator+" (operator)",
+		)
+	}
+
+	if _, ok = fields["field"]; !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSetFieldMissingField,
+			fmt.Sprintf("%s requires 'field' to be specified", operator),
+			operator+" (operator)",
+		)
+	}
+
+	if _, ok = fields["input"]; !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSetFieldMissingInput,
+			fmt.Sprintf("%s requires 'input' to be specified", operator),
+			operator+" (operator)",
+		)
+	}
+
+	value := any(variableRemove)
+
+	if operator == "$setField" {
+		if value, ok = fields["value"]; !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrSetFieldMissingValue,
+				"$setField requires 'value' to be specified",
+				"$setField (operator)",
+			)
+		}
+	}
+
+	field, err := constantFieldName(
+		fields["field"],
+		operator,
+		handlererrors.ErrSetFieldNonConstantField,
+		handlererrors.ErrSetFieldFieldNotString,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &setField{
+		operator: operator,
+		field:    field,
+		input:    fields["input"],
+		value:    value,
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns a copy of the input document with the field with the given name set to the value,
+// even if that name contains dots or starts with a dollar sign.
+// If the value is missing (for example, `$$REMOVE`), the field is removed instead.
+// If the input is null or missing, it returns null.
+func (s *setField) Process(doc *types.Document) (any, error) {
+	input, err := evaluate(s.input, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNullish(input) {
+		return types.Null, nil
+	}
+
+	d, ok := input.(*types.Document)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSetFieldInputNotObject,
+			fmt.Sprintf("%s requires 'input' to evaluate to type Object, but got %s", s.operator, typeAlias(input)),
+			s.operator+" (operator)",
+		)
+	}
+
+	value, err := evaluate(s.value, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	res := d.DeepCopy()
+
+	if value == nil {
+		res.Remove(s.field)
+		return res, nil
+	}
+
+	res.Set(s.field, value)
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*setField)(nil)
+)
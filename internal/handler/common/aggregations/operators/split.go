@@ -0,0 +1,112 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// split represents `$split` operator.
+type split struct {
+	input     any
+	delimiter any
+}
+
+// newSplit returns `$split` operator.
+func newSplit(args ...any) (Operator, error) {
+	if len(args) != 2 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			"$split",
+			fmt.Sprintf("Expression $split takes exactly 2 arguments. %d were passed in.", len(args)),
+		)
+	}
+
+	return &split{
+		input:     args[0],
+		delimiter: args[1],
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns an array of substrings of the input separated by the delimiter.
+// If input or delimiter is null or missing, null is returned.
+func (s *split) Process(doc *types.Document) (any, error) {
+	input, err := evaluate(s.input, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	delimiter, err := evaluate(s.delimiter, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNullish(input) || isNullish(delimiter) {
+		return types.Null, nil
+	}
+
+	str, ok := input.(string)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSplitInputNotString,
+			fmt.Sprintf(
+				"$split requires an expression that evaluates to a string as a first argument, found: %s",
+				handlerparams.AliasFromType(input),
+			),
+			"$split (operator)",
+		)
+	}
+
+	sep, ok := delimiter.(string)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSplitDelimiterNotString,
+			fmt.Sprintf(
+				"$split requires an expression that evaluates to a string as a second argument, found: %s",
+				handlerparams.AliasFromType(delimiter),
+			),
+			"$split (operator)",
+		)
+	}
+
+	if sep == "" {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSplitEmptyDelimiter,
+			"$split requires a non-empty separator",
+			"$split (operator)",
+		)
+	}
+
+	parts := strings.Split(str, sep)
+
+	res := types.MakeArray(len(parts))
+	for _, p := range parts {
+		res.Append(p)
+	}
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*split)(nil)
+)
@@ -0,0 +1,88 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// sqrt represents `$sqrt` operator.
+type sqrt struct {
+	number any
+}
+
+// newSqrt returns `$sqrt` operator.
+func newSqrt(args ...any) (Operator, error) {
+	if len(args) != 1 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			"$sqrt",
+			fmt.Sprintf("Expression $sqrt takes exactly 1 arguments. %d were passed in.", len(args)),
+		)
+	}
+
+	return &sqrt{
+		number: args[0],
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the square root as Decimal128 if the argument is Decimal128, and as double otherwise.
+// Decimal128 value is computed with double precision.
+// If the argument is null or missing, null is returned.
+func (s *sqrt) Process(doc *types.Document) (any, error) {
+	v, err := evaluate(s.number, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNullish(v) {
+		return types.Null, nil
+	}
+
+	if !isNumber(v) {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSqrtNotNumeric,
+			fmt.Sprintf("$sqrt only supports numeric types, not %s", handlerparams.AliasFromType(v)),
+			"$sqrt (operator)",
+		)
+	}
+
+	f := toFloat64(v)
+	if f < 0 {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSqrtNegative,
+			"$sqrt's argument must be greater than or equal to 0",
+			"$sqrt (operator)",
+		)
+	}
+
+	if _, ok := v.(types.Decimal128); ok {
+		return types.NewDecimal128FromFloat64(math.Sqrt(f)), nil
+	}
+
+	return math.Sqrt(f), nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*sqrt)(nil)
+)
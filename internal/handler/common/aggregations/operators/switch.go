@@ -0,0 +1,228 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// switchBranch represents a single branch of `$switch` operator.
+type switchBranch struct {
+	caseExpr any
+	thenExpr any
+}
+
+// switchOp represents `$switch` operator.
+type switchOp struct {
+	defaultExpr any
+	branches    []switchBranch
+	hasDefault  bool
+}
+
+// newSwitch returns `$switch` operator.
+func newSwitch(args ...any) (Operator, error) {
+	var arg any = types.MakeArray(len(args))
+	if len(args) == 1 {
+		arg = args[0]
+	}
+
+	doc, ok := arg.(*types.Document)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSwitchInvalidArg,
+			fmt.Sprintf("$switch requires an object as an argument, found: %s", handlerparams.AliasFromType(arg)),
+			"$switch (operator)",
+		)
+	}
+
+	op := new(switchOp)
+
+	iter := doc.Iterator()
+	defer iter.Close()
+
+	for {
+		k, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		switch k {
+		case "branches":
+			if op.branches, err = newSwitchBranches(v); err != nil {
+				return nil, err
+			}
+
+		case "default":
+			op.defaultExpr = v
+			op.hasDefault = true
+
+		default:
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrSwitchUnknownArg,
+				fmt.Sprintf("$switch found an unknown argument: %s", k),
+				"$switch (operator)",
+			)
+		}
+	}
+
+	if len(op.branches) == 0 {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSwitchNoBranches,
+			"$switch requires at least one branch.",
+			"$switch (operator)",
+		)
+	}
+
+	return op, nil
+}
+
+// newSwitchBranches returns branches of `$switch` operator for the given branches argument.
+func newSwitchBranches(v any) ([]switchBranch, error) {
+	arr, ok := v.(*types.Array)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSwitchBranchesNotArray,
+			fmt.Sprintf("$switch expected an array for 'branches', found: %s", handlerparams.AliasFromType(v)),
+			"$switch (operator)",
+		)
+	}
+
+	res := make([]switchBranch, 0, arr.Len())
+
+	iter := arr.Iterator()
+	defer iter.Close()
+
+	for {
+		_, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		fields, unknown, ok, err := operatorFields([]any{v}, "case", "then")
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !ok:
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrSwitchBranchNotObject,
+				fmt.Sprintf("$switch expected each branch to be an object, found: %s", handlerparams.AliasFromType(v)),
+				"$switch (operator)",
+			)
+		case unknown != "":
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrSwitchBranchUnknownArg,
+				fmt.Sprintf("$switch found an unknown argument to a branch: %s", unknown),
+				"$switch (operator)",
+			)
+		}
+
+		caseExpr, ok := fields["case"]
+		if !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrSwitchBranchMissingCase,
+				"$switch requires each branch have a 'case' expression",
+				"$switch (operator)",
+			)
+		}
+
+		thenExpr, ok := fields["then"]
+		if !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrSwitchBranchMissingThen,
+				"$switch requires each branch have a 'then' expression.",
+				"$switch (operator)",
+			)
+		}
+
+		res = append(res, switchBranch{
+			caseExpr: caseExpr,
+			thenExpr: thenExpr,
+		})
+	}
+
+	return res, nil
+}
+
+// Process implements Operator interface.
+//
+// It evaluates branch cases in order and returns the then expression of the first true case.
+// If no case is true, the default expression is returned.
+// If the returned expression refers to a missing field, null is returned.
+//
+// If no case is true and there is no default, an error is returned.
+// A nil document is used for validation only, so null is returned instead in that case.
+func (s *switchOp) Process(doc *types.Document) (any, error) {
+	expr := s.defaultExpr
+	found := s.hasDefault
+
+	for _, b := range s.branches {
+		v, err := evaluate(b.caseExpr, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if isTruthy(v) {
+			expr = b.thenExpr
+			found = true
+
+			break
+		}
+	}
+
+	if !found {
+		if doc == nil {
+			return types.Null, nil
+		}
+
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSwitchNoMatchingBranch,
+			"$switch could not find a matching branch for an input, and no default was specified.",
+			"$switch (operator)",
+		)
+	}
+
+	v, err := evaluate(expr, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if v == nil {
+		return types.Null, nil
+	}
+
+	return v, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*switchOp)(nil)
+)
@@ -0,0 +1,158 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// trimWhitespace contains code points trimmed by `$trim`, `$ltrim` and `$rtrim` when chars are not set.
+const trimWhitespace = "\x00 \t\n\v\f\r\u00a0\u1680\u2000\u2001\u2002\u2003\u2004\u2005\u2006\u2007\u2008\u2009\u200a" +
+	"\u2028\u2029\u202f\u205f\u3000"
+
+// trim represents `$trim`, `$ltrim` and `$rtrim` operators.
+type trim struct {
+	input any
+	chars any // nil if not set
+	name  string
+	left  bool
+	right bool
+}
+
+// newTrim returns `$trim` operator.
+func newTrim(args ...any) (Operator, error) {
+	return newTrimOperator("$trim", true, true, args)
+}
+
+// newLTrim returns `$ltrim` operator.
+func newLTrim(args ...any) (Operator, error) {
+	return newTrimOperator("$ltrim", true, false, args)
+}
+
+// newRTrim returns `$rtrim` operator.
+func newRTrim(args ...any) (Operator, error) {
+	return newTrimOperator("$rtrim", false, true, args)
+}
+
+// newTrimOperator returns trim operator with the given name that trims the given sides of the input.
+func newTrimOperator(name string, left, right bool, args []any) (Operator, error) {
+	fields, unknown, ok, err := operatorFields(args, "input", "chars")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !ok:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrTrimInvalidArg,
+			fmt.Sprintf("%s only supports an object as its argument", name),
+			name+" (operator)",
+		)
+	case unknown != "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrTrimUnknownArg,
+			fmt.Sprintf("%s found an unknown argument: %s", name, unknown),
+			name+" (operator)",
+		)
+	}
+
+	if _, ok = fields["input"]; !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrTrimMissingInput,
+			fmt.Sprintf("%s requires an 'input' field", name),
+			name+" (operator)",
+		)
+	}
+
+	return &trim{
+		input: fields["input"],
+		chars: fields["chars"],
+		name:  name,
+		left:  left,
+		right: right,
+	}, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns the input with the leading and/or trailing code points contained in chars removed.
+// Whitespace characters are removed if chars are not set.
+// If input or chars is null or missing, null is returned.
+func (t *trim) Process(doc *types.Document) (any, error) {
+	input, err := evaluate(t.input, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNullish(input) {
+		return types.Null, nil
+	}
+
+	s, ok := input.(string)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrTrimInputNotString,
+			fmt.Sprintf(
+				"%s requires its input to be a string, got %s (of type %s) instead.",
+				t.name, types.FormatAnyValue(input), handlerparams.AliasFromType(input),
+			),
+			t.name+" (operator)",
+		)
+	}
+
+	cutset := trimWhitespace
+
+	if t.chars != nil {
+		var chars any
+		if chars, err = evaluate(t.chars, doc); err != nil {
+			return nil, err
+		}
+
+		if isNullish(chars) {
+			return types.Null, nil
+		}
+
+		if cutset, ok = chars.(string); !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrTrimCharsNotString,
+				fmt.Sprintf(
+					"%s requires 'chars' to be a string, got %s (of type %s) instead.",
+					t.name, types.FormatAnyValue(chars), handlerparams.AliasFromType(chars),
+				),
+				t.name+" (operator)",
+			)
+		}
+	}
+
+	if t.left {
+		s = strings.TrimLeft(s, cutset)
+	}
+
+	if t.right {
+		s = strings.TrimRight(s, cutset)
+	}
+
+	return s, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*trim)(nil)
+)
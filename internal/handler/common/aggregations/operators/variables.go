@@ -0,0 +1,287 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// variableRemove is a system variable that evaluates to the missing value.
+const variableRemove = "$$REMOVE"
+
+// scopeOperators maps operators that define their own variables to the fields
+// where those variables are visible, and to the function returning their names.
+var scopeOperators = map[string]struct {
+	fields []string
+	names  func(args *types.Document) []string
+}{
+	"$filter": {
+		fields: []string{"cond"},
+		names:  asVariableNames,
+	},
+	"$let": {
+		fields: []string{"in"},
+		names:  letVariableNames,
+	},
+	"$map": {
+		fields: []string{"in"},
+		names:  asVariableNames,
+	},
+	"$reduce": {
+		fields: []string{"in"},
+		names: func(*types.Document) []string {
+			return []string{"this", "value"}
+		},
+	},
+}
+
+// asVariableNames returns the name of the variable defined by `as` argument of `$map` and `$filter`.
+func asVariableNames(args *types.Document) []string {
+	if as, _ := args.Get("as"); as != nil {
+		name, _ := as.(string)
+		return []string{name}
+	}
+
+	return []string{"this"}
+}
+
+// validateVariableName returns an error if the given name could not be used for a user variable.
+func validateVariableName(name, operator string) error {
+	if name == "" {
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFailedToParse,
+			"empty variable names are not allowed",
+			operator+" (operator)",
+		)
+	}
+
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r > 0x7f:
+			continue
+		case i > 0 && (r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_'):
+			continue
+		case i == 0:
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParse,
+				fmt.Sprintf("'%s' starts with an invalid character for a user variable name", name),
+				operator+" (operator)",
+			)
+		default:
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParse,
+				fmt.Sprintf("'%s' contains an invalid character for a variable name: '%c'", name, r),
+				operator+" (operator)",
+			)
+		}
+	}
+
+	return nil
+}
+
+// bindVariables returns a copy of the operator argument with all references to the given variables
+// (like `$$this` or `$$this.field`) replaced by their values.
+//
+// Values are wrapped in `$literal` so they are not evaluated again;
+// missing values are replaced by `$$REMOVE`.
+// Variables redefined by nested operators (like `$map` with the same `as` name) are not replaced
+// inside the scope of those operators.
+func bindVariables(arg any, vars map[string]any) (any, error) {
+	if len(vars) == 0 {
+		return arg, nil
+	}
+
+	switch arg := arg.(type) {
+	case *types.Document:
+		if arg.Len() == 1 && arg.Command() == "$literal" {
+			return arg, nil
+		}
+
+		if scope, ok := scopeOperators[arg.Command()]; ok && arg.Len() == 1 {
+			if args, ok := must.NotFail(arg.Get(arg.Command())).(*types.Document); ok {
+				scopeVars := withoutVariables(vars, scope.names(args))
+				res := types.MakeDocument(args.Len())
+
+				iter := args.Iterator()
+				defer iter.Close()
+
+				for {
+					k, v, err := iter.Next()
+					if errors.Is(err, iterator.ErrIteratorDone) {
+						break
+					}
+
+					if err != nil {
+						return nil, lazyerrors.Error(err)
+					}
+
+					fieldVars := vars
+					if slices.Contains(scope.fields, k) {
+						fieldVars = scopeVars
+					}
+
+					if v, err = bindVariables(v, fieldVars); err != nil {
+						return nil, lazyerrors.Error(err)
+					}
+
+					res.Set(k, v)
+				}
+
+				return must.NotFail(types.NewDocument(arg.Command(), res)), nil
+			}
+		}
+
+		res := types.MakeDocument(arg.Len())
+
+		iter := arg.Iterator()
+		defer iter.Close()
+
+		for {
+			k, v, err := iter.Next()
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			if v, err = bindVariables(v, vars); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			res.Set(k, v)
+		}
+
+		return res, nil
+
+	case *types.Array:
+		res := types.MakeArray(arg.Len())
+
+		iter := arg.Iterator()
+		defer iter.Close()
+
+		for {
+			_, v, err := iter.Next()
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			if v, err = bindVariables(v, vars); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			res.Append(v)
+		}
+
+		return res, nil
+
+	case string:
+		if !strings.HasPrefix(arg, "$$") {
+			return arg, nil
+		}
+
+		name, path, _ := strings.Cut(strings.TrimPrefix(arg, "$$"), ".")
+
+		v, ok := vars[name]
+		if !ok {
+			return arg, nil
+		}
+
+		if path != "" && v != nil {
+			v = variableField(v, path)
+		}
+
+		if v == nil {
+			return variableRemove, nil
+		}
+
+		return must.NotFail(types.NewDocument("$literal", v)), nil
+
+	default:
+		return arg, nil
+	}
+}
+
+// withoutVariables returns a copy of vars without variables with the given names.
+func withoutVariables(vars map[string]any, names []string) map[string]any {
+	res := make(map[string]any, len(vars))
+
+	for k, v := range vars {
+		if !slices.Contains(names, k) {
+			res[k] = v
+		}
+	}
+
+	return res
+}
+
+// variableField returns the value of the variable's field with the given dot notation path,
+// or nil if it is missing.
+func variableField(v any, path string) any {
+	expression, err := aggregations.NewExpression("$v."+path, nil)
+	if err != nil {
+		return nil
+	}
+
+	res, err := expression.Evaluate(must.NotFail(types.NewDocument("v", v)))
+	if err != nil {
+		return nil
+	}
+
+	return res
+}
+
+// asVariableName returns the name of the variable defined by `as` argument of `$map` and `$filter`,
+// or an error if it is not valid.
+func asVariableName(fields map[string]any, operator string) (string, error) {
+	as, ok := fields["as"]
+	if !ok {
+		return "this", nil
+	}
+
+	// non-string values are treated as empty names
+	name, _ := as.(string)
+	if err := validateVariableName(name, operator); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// evaluateWithVariables evaluates the operator argument for the given document
+// with the given variables bound to their values.
+func evaluateWithVariables(arg any, doc *types.Document, vars map[string]any) (any, error) {
+	bound, err := bindVariables(arg, vars)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return evaluate(bound, doc)
+}
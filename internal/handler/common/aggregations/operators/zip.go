@@ -0,0 +1,206 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// zip represents `$zip` operator.
+type zip struct {
+	inputs           []any
+	defaults         []any // nil if not set
+	useLongestLength bool
+}
+
+// newZip returns `$zip` operator.
+func newZip(args ...any) (Operator, error) {
+	fields, unknown, ok, err := operatorFields(args, "inputs", "useLongestLength", "defaults")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !ok:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrZipBadArgument,
+			fmt.Sprintf("$zip only supports an object as an argument, found %s", argsTypeAlias(args)),
+			"$zip (operator)",
+		)
+	case unknown != "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrZipUnknownField,
+			fmt.Sprintf("$zip found an unknown argument: %s", unknown),
+			"$zip (operator)",
+		)
+	}
+
+	var z zip
+
+	if v, ok := fields["useLongestLength"]; ok {
+		if z.useLongestLength, ok = v.(bool); !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrZipUseLongestLengthNotBool,
+				fmt.Sprintf("useLongestLength must be a bool, found %s", typeAlias(v)),
+				"$zip (operator)",
+			)
+		}
+	}
+
+	if v, ok := fields["inputs"]; ok {
+		arr, ok := v.(*types.Array)
+		if !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrZipInputsNotArray,
+				fmt.Sprintf("inputs must be an array of expressions, found %s", typeAlias(v)),
+				"$zip (operator)",
+			)
+		}
+
+		z.inputs = must.NotFail(iterator.ConsumeValues(arr.Iterator()))
+	}
+
+	if v, ok := fields["defaults"]; ok {
+		arr, ok := v.(*types.Array)
+		if !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrZipDefaultsNotArray,
+				fmt.Sprintf("defaults must be an array of expressions, found %s", typeAlias(v)),
+				"$zip (operator)",
+			)
+		}
+
+		// non-nil even for an empty array
+		z.defaults = append([]any{}, must.NotFail(iterator.ConsumeValues(arr.Iterator()))...)
+	}
+
+	switch {
+	case len(z.inputs) == 0:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrZipMissingInputs,
+			"$zip requires at least one input array",
+			"$zip (operator)",
+		)
+	case z.defaults != nil && !z.useLongestLength:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrZipDefaultsWithoutLongest,
+			"cannot specify defaults unless useLongestLength is true",
+			"$zip (operator)",
+		)
+	case z.defaults != nil && len(z.defaults) != len(z.inputs):
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrZipDefaultsLength,
+			"defaults and inputs must have the same length",
+			"$zip (operator)",
+		)
+	}
+
+	return &z, nil
+}
+
+// Process implements Operator interface.
+//
+// It returns an array of arrays, where the n-th array contains the n-th elements of all input arrays.
+// The result has the length of the shortest input array, or the longest one if useLongestLength is set;
+// in that case missing elements are set to defaults or null.
+// If any input is null or missing, it returns null.
+func (z *zip) Process(doc *types.Document) (any, error) {
+	arrs := make([]*types.Array, len(z.inputs))
+
+	var n int
+	var null bool
+
+	for i, input := range z.inputs {
+		v, err := evaluate(input, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if isNullish(v) {
+			null = true
+			continue
+		}
+
+		arr, ok := v.(*types.Array)
+		if !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrZipInputNotArray,
+				fmt.Sprintf("$zip found a non-array expression in input: %s", types.FormatAnyValue(v)),
+				"$zip (operator)",
+			)
+		}
+
+		arrs[i] = arr
+
+		switch {
+		case i == 0, z.useLongestLength && arr.Len() > n:
+			n = arr.Len()
+		case !z.useLongestLength && arr.Len() < n:
+			n = arr.Len()
+		}
+	}
+
+	if null {
+		return types.Null, nil
+	}
+
+	defaults := make([]any, len(z.inputs))
+
+	for i := range defaults {
+		defaults[i] = types.Null
+
+		if z.defaults == nil {
+			continue
+		}
+
+		v, err := evaluate(z.defaults[i], doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if v != nil {
+			defaults[i] = v
+		}
+	}
+
+	res := types.MakeArray(n)
+
+	for j := 0; j < n; j++ {
+		elem := types.MakeArray(len(arrs))
+
+		for i, arr := range arrs {
+			if j < arr.Len() {
+				elem.Append(must.NotFail(arr.Get(j)))
+				continue
+			}
+
+			elem.Append(defaults[i])
+		}
+
+		res.Append(elem)
+	}
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ Operator = (*zip)(nil)
+)
@@ -72,3 +72,37 @@ func GetPushdownQuery(stagesDocs []any) (match *types.Document, sort *types.Docu
 
 	return
 }
+
+// GetPushdownSample gets pushdown sample size for aggregation.
+//
+// It could be pushed down only if the first stage is $sample,
+// because the backend samples documents of the whole collection.
+// If the first stage is not a valid $sample stage, 0 is returned.
+func GetPushdownSample(stagesDocs []any) int64 {
+	if len(stagesDocs) == 0 {
+		return 0
+	}
+
+	stage, isDoc := stagesDocs[0].(*types.Document)
+	if !isDoc || stage.Len() != 1 || !stage.Has("$sample") {
+		return 0
+	}
+
+	fields, isDoc := must.NotFail(stage.Get("$sample")).(*types.Document)
+	if !isDoc || fields.Len() != 1 {
+		return 0
+	}
+
+	var size int64
+
+	switch v, _ := fields.Get("size"); v := v.(type) {
+	case float64:
+		size = int64(v)
+	case int32:
+		size = int64(v)
+	case int64:
+		size = v
+	}
+
+	return max(size, 0)
+}
@@ -0,0 +1,455 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations/operators/accumulators"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// bucket represents $bucket stage.
+//
+//	{ $bucket: {
+//		groupBy: <expression>,
+//		boundaries: [<lowerbound1>, <lowerbound2>, ...],
+//		default: <literal>,
+//		output: {
+//			<output1>: {<accumulator1>: <expression1>},
+//			...
+//		}
+//	}}
+//
+// $bucket groups documents into buckets by the evaluated groupBy expression.
+// Each bucket includes values from its lower boundary (inclusive) to the next boundary (exclusive);
+// values outside of boundaries are grouped into the default bucket.
+// Buckets without documents are not returned.
+type bucket struct {
+	groupBy    operators.Operator
+	boundaries []any
+	def        any // nil if default bucket is not specified
+	output     []groupBy
+	collation  *types.Collation
+}
+
+// newBucket creates a new $bucket stage.
+func newBucket(stage *types.Document) (aggregations.Stage, error) {
+	fields, ok := must.NotFail(stage.Get("$bucket")).(*types.Document)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageBucketInvalid,
+			fmt.Sprintf(
+				"Argument to $bucket stage must be an object, but found type: %s.",
+				handlerparams.AliasFromType(must.NotFail(stage.Get("$bucket"))),
+			),
+			"$bucket (stage)",
+		)
+	}
+
+	var b bucket
+	var err error
+
+	iter := fields.Iterator()
+	defer iter.Close()
+
+	for {
+		k, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		switch k {
+		case "groupBy":
+			if b.groupBy, err = newBucketGroupBy("$bucket", v); err != nil {
+				return nil, err
+			}
+
+		case "boundaries":
+			if b.boundaries, err = bucketBoundaries(v); err != nil {
+				return nil, err
+			}
+
+		case "default":
+			if !isBucketConstant(v) {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrStageBucketDefaultNotConstant,
+					fmt.Sprintf(
+						"The $bucket 'default' field must be a constant expression, but found: %s.",
+						types.FormatAnyValue(v),
+					),
+					"$bucket (stage)",
+				)
+			}
+
+			b.def = v
+
+		case "output":
+			if b.output, err = newBucketOutput("$bucket", v); err != nil {
+				return nil, err
+			}
+
+		default:
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrStageBucketUnknownOption,
+				fmt.Sprintf("Unrecognized option to $bucket: %s.", k),
+				"$bucket (stage)",
+			)
+		}
+	}
+
+	if b.groupBy == nil || b.boundaries == nil {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageBucketMissingRequired,
+			"$bucket requires 'groupBy' and 'boundaries' to be specified.",
+			"$bucket (stage)",
+		)
+	}
+
+	if b.def != nil && bucketType(b.def) == bucketType(b.boundaries[0]) {
+		lowest, highest := b.boundaries[0], b.boundaries[len(b.boundaries)-1]
+
+		if types.CompareOrder(b.def, lowest, types.Ascending) != types.Less &&
+			types.CompareOrder(b.def, highest, types.Ascending) == types.Less {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrStageBucketDefaultInRange,
+				"The $bucket 'default' field must be less than the lowest boundary "+
+					"or greater than or equal to the highest boundary.",
+				"$bucket (stage)",
+			)
+		}
+	}
+
+	if b.output == nil {
+		b.output, err = newBucketOutput("$bucket", nil)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return &b, nil
+}
+
+// bucketBoundaries validates and returns $bucket boundaries.
+func bucketBoundaries(v any) ([]any, error) {
+	arr, ok := v.(*types.Array)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageBucketBoundariesNotArray,
+			fmt.Sprintf(
+				"The $bucket 'boundaries' field must be an array, but found type: %s.",
+				handlerparams.AliasFromType(v),
+			),
+			"$bucket (stage)",
+		)
+	}
+
+	boundaries := make([]any, arr.Len())
+
+	for i := range boundaries {
+		boundaries[i] = must.NotFail(arr.Get(i))
+
+		if !isBucketConstant(boundaries[i]) {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrStageBucketBoundariesNotConstant,
+				fmt.Sprintf(
+					"The $bucket 'boundaries' field must be an array of constant values, but found value: %s.",
+					types.FormatAnyValue(boundaries[i]),
+				),
+				"$bucket (stage)",
+			)
+		}
+	}
+
+	if len(boundaries) < 2 {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageBucketBoundariesTooFew,
+			fmt.Sprintf(
+				"The $bucket 'boundaries' field must have at least 2 values, but found %d value(s).",
+				len(boundaries),
+			),
+			"$bucket (stage)",
+		)
+	}
+
+	for i := 1; i < len(boundaries); i++ {
+		lower, upper := boundaries[i-1], boundaries[i]
+
+		if bucketType(lower) != bucketType(upper) {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrStageBucketBoundariesMixedTypes,
+				fmt.Sprintf(
+					"All values in the 'boundaries' option to $bucket must have the same type. "+
+						"Found conflicting types %s and %s.",
+					handlerparams.AliasFromType(lower), handlerparams.AliasFromType(upper),
+				),
+				"$bucket (stage)",
+			)
+		}
+
+		if types.CompareOrder(lower, upper, types.Ascending) != types.Less {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrStageBucketBoundariesNotSorted,
+				fmt.Sprintf(
+					"The 'boundaries' option to $bucket must be sorted, but elements %d and %d "+
+						"are not in ascending order (%s is not less than %s).",
+					i-1, i, types.FormatAnyValue(lower), types.FormatAnyValue(upper),
+				),
+				"$bucket (stage)",
+			)
+		}
+	}
+
+	return boundaries, nil
+}
+
+// Process implements Stage interface.
+func (b *bucket) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	defer iter.Close()
+
+	// the last element is for the default bucket
+	buckets := make([][]*types.Document, len(b.boundaries))
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		v, err := b.groupBy.Process(doc)
+		if err != nil {
+			return nil, processGroupStageError(err)
+		}
+
+		i := b.find(v)
+		if i < 0 {
+			if b.def == nil {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrSwitchNoMatchingBranch,
+					"$switch could not find a matching branch for an input, and no default was specified.",
+					"$bucket (stage)",
+				)
+			}
+
+			i = len(buckets) - 1
+		}
+
+		buckets[i] = append(buckets[i], doc)
+	}
+
+	var res []*types.Document
+
+	for i, docs := range buckets {
+		if len(docs) == 0 {
+			continue
+		}
+
+		id := b.def
+		if i < len(b.boundaries)-1 {
+			id = b.boundaries[i]
+		}
+
+		doc, err := accumulateBucket(id, docs, b.output)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, doc)
+	}
+
+	// buckets are sorted by _id, including the default one
+	slices.SortStableFunc(res, func(x, y *types.Document) int {
+		return int(b.collation.CompareOrderForSort(must.NotFail(x.Get("_id")), must.NotFail(y.Get("_id")), types.Ascending))
+	})
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// find returns the index of the bucket for the given value, or -1 if it is outside of boundaries.
+func (b *bucket) find(v any) int {
+	if b.collation.CompareOrder(v, b.boundaries[0], types.Ascending) == types.Less {
+		return -1
+	}
+
+	i, _ := slices.BinarySearchFunc(b.boundaries[1:], v, func(boundary, v any) int {
+		if b.collation.CompareOrder(boundary, v, types.Ascending) == types.Greater {
+			return 1
+		}
+
+		return -1
+	})
+
+	if i == len(b.boundaries)-1 {
+		return -1
+	}
+
+	return i
+}
+
+// newBucketGroupBy validates and returns groupBy expression of $bucket and $bucketAuto stages.
+func newBucketGroupBy(stage string, v any) (operators.Operator, error) {
+	var valid bool
+
+	switch v := v.(type) {
+	case *types.Document:
+		valid = operators.IsOperator(v)
+	case string:
+		valid = strings.HasPrefix(v, "$")
+	}
+
+	if !valid {
+		code := handlererrors.ErrStageBucketGroupByInvalid
+		msg := "The $bucket 'groupBy' field must be defined as a $-prefixed path or an expression, but found: %s."
+
+		if stage == "$bucketAuto" {
+			code = handlererrors.ErrStageBucketAutoGroupByInvalid
+			msg = "The $bucketAuto 'groupBy' field must be defined as a $-prefixed path or an expression object, " +
+				"but found: %s."
+		}
+
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			code,
+			fmt.Sprintf(msg, types.FormatAnyValue(v)),
+			stage+" (stage)",
+		)
+	}
+
+	return operators.NewExpr(must.NotFail(types.NewDocument("$expr", v)), stage+" (stage)")
+}
+
+// newBucketOutput validates and returns output accumulators of $bucket and $bucketAuto stages.
+//
+// If output is nil, it returns the default `count: {$sum: 1}` accumulator.
+func newBucketOutput(stage string, v any) ([]groupBy, error) {
+	if v == nil {
+		v = must.NotFail(types.NewDocument("count", must.NotFail(types.NewDocument("$sum", int32(1)))))
+	}
+
+	output, ok := v.(*types.Document)
+	if !ok {
+		code := handlererrors.ErrStageBucketOutputNotObject
+		if stage == "$bucketAuto" {
+			code = handlererrors.ErrStageBucketAutoOutputNotObject
+		}
+
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			code,
+			fmt.Sprintf(
+				"The %s 'output' field must be an object, but found type: %s.",
+				stage, handlerparams.AliasFromType(v),
+			),
+			stage+" (stage)",
+		)
+	}
+
+	res := []groupBy{}
+
+	iter := output.Iterator()
+	defer iter.Close()
+
+	for {
+		field, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		accumulator, err := accumulators.NewAccumulator(stage, field, v)
+		if err != nil {
+			// $bucket and $bucketAuto are implemented as $group by MongoDB and return the same errors
+			return nil, processGroupStageError(err)
+		}
+
+		res = append(res, groupBy{
+			outputField: field,
+			accumulator: accumulator,
+		})
+	}
+
+	return res, nil
+}
+
+// accumulateBucket returns a document with the given _id and output fields accumulated over given documents.
+func accumulateBucket(id any, docs []*types.Document, output []groupBy) (*types.Document, error) {
+	res := must.NotFail(types.NewDocument("_id", id))
+
+	for _, o := range output {
+		iter := iterator.Values(iterator.ForSlice(docs))
+
+		v, err := o.accumulator.Accumulate(iter)
+		iter.Close()
+
+		if err != nil {
+			return nil, processGroupStageError(err)
+		}
+
+		res.Set(o.outputField, v)
+	}
+
+	return res, nil
+}
+
+// isBucketConstant returns true if the given value is a constant, not a path or an operator.
+func isBucketConstant(v any) bool {
+	switch v := v.(type) {
+	case *types.Document:
+		return !operators.IsOperator(v)
+	case string:
+		return !strings.HasPrefix(v, "$")
+	default:
+		return true
+	}
+}
+
+// bucketType returns the type alias of the given value used to check that
+// $bucket boundaries have the same type; all numbers have the same type.
+func bucketType(v any) string {
+	switch v.(type) {
+	case float64, int32, int64, types.Decimal128:
+		return handlerparams.TypeCodeNumber.String()
+	default:
+		return handlerparams.AliasFromType(v)
+	}
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*bucket)(nil)
+)
@@ -0,0 +1,267 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// bucketAuto represents $bucketAuto stage.
+//
+//	{ $bucketAuto: {
+//		groupBy: <expression>,
+//		buckets: <number>,
+//		output: {
+//			<output1>: {<accumulator1>: <expression1>},
+//			...
+//		}
+//	}}
+//
+// $bucketAuto sorts documents by the evaluated groupBy expression and splits them into
+// the given number of buckets with about the same number of documents.
+// Documents with the same value are always placed into the same bucket,
+// so the number of returned buckets could be less than requested.
+type bucketAuto struct {
+	groupBy   operators.Operator
+	buckets   int
+	output    []groupBy
+	collation *types.Collation
+}
+
+// newBucketAuto creates a new $bucketAuto stage.
+func newBucketAuto(stage *types.Document) (aggregations.Stage, error) {
+	fields, ok := must.NotFail(stage.Get("$bucketAuto")).(*types.Document)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageBucketAutoInvalid,
+			fmt.Sprintf(
+				"The argument to $bucketAuto must be an object, but found type: %s.",
+				handlerparams.AliasFromType(must.NotFail(stage.Get("$bucketAuto"))),
+			),
+			"$bucketAuto (stage)",
+		)
+	}
+
+	var b bucketAuto
+	var err error
+
+	iter := fields.Iterator()
+	defer iter.Close()
+
+	for {
+		k, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		switch k {
+		case "groupBy":
+			if b.groupBy, err = newBucketGroupBy("$bucketAuto", v); err != nil {
+				return nil, err
+			}
+
+		case "buckets":
+			if b.buckets, err = bucketAutoBuckets(v); err != nil {
+				return nil, err
+			}
+
+		case "output":
+			if b.output, err = newBucketOutput("$bucketAuto", v); err != nil {
+				return nil, err
+			}
+
+		case "granularity":
+			// TODO https://github.com/FerretDB/FerretDB/issues/1414
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrNotImplemented,
+				"$bucketAuto 'granularity' option is not implemented yet",
+				"$bucketAuto (stage)",
+			)
+
+		default:
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrStageBucketAutoUnknownOption,
+				fmt.Sprintf("Unrecognized option to $bucketAuto: %s.", k),
+				"$bucketAuto (stage)",
+			)
+		}
+	}
+
+	if b.groupBy == nil || b.buckets == 0 {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageBucketAutoMissingRequired,
+			"$bucketAuto requires 'groupBy' and 'buckets' to be specified",
+			"$bucketAuto (stage)",
+		)
+	}
+
+	if b.output == nil {
+		b.output, err = newBucketOutput("$bucketAuto", nil)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return &b, nil
+}
+
+// bucketAutoBuckets validates and returns $bucketAuto buckets number.
+func bucketAutoBuckets(v any) (int, error) {
+	var f float64
+
+	switch v := v.(type) {
+	case float64:
+		f = v
+	case int32:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	default:
+		return 0, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageBucketAutoBucketsNotNumber,
+			fmt.Sprintf(
+				"The $bucketAuto 'buckets' field must be a numeric value, but found type: %s.",
+				handlerparams.AliasFromType(v),
+			),
+			"$bucketAuto (stage)",
+		)
+	}
+
+	if f != math.Trunc(f) || f > math.MaxInt32 || f < math.MinInt32 {
+		return 0, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageBucketAutoBucketsNotInt32,
+			fmt.Sprintf(
+				"The $bucketAuto 'buckets' field must be representable as a 32-bit integer, but found %s.",
+				types.FormatAnyValue(v),
+			),
+			"$bucketAuto (stage)",
+		)
+	}
+
+	if f <= 0 {
+		return 0, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageBucketAutoBucketsNotPositive,
+			fmt.Sprintf(
+				"The $bucketAuto 'buckets' field must be greater than 0, but found: %s.",
+				types.FormatAnyValue(v),
+			),
+			"$bucketAuto (stage)",
+		)
+	}
+
+	return int(f), nil
+}
+
+// bucketAutoValue represents an input document with its evaluated groupBy value.
+type bucketAutoValue struct {
+	v   any
+	doc *types.Document
+}
+
+// Process implements Stage interface.
+func (b *bucketAuto) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	defer iter.Close()
+
+	var values []bucketAutoValue
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		v, err := b.groupBy.Process(doc)
+		if err != nil {
+			return nil, processGroupStageError(err)
+		}
+
+		values = append(values, bucketAutoValue{v: v, doc: doc})
+	}
+
+	slices.SortStableFunc(values, func(x, y bucketAutoValue) int {
+		return int(b.collation.CompareOrder(x.v, y.v, types.Ascending))
+	})
+
+	// the same rounding as MongoDB uses
+	size := max(int(math.Round(float64(len(values))/float64(b.buckets))), 1)
+
+	var res []*types.Document
+
+	for start := 0; start < len(values); {
+		end := len(values)
+
+		// the last bucket contains all remaining documents
+		if len(res) < b.buckets-1 {
+			end = min(start+size, len(values))
+
+			// documents with the same value are not split between buckets
+			for end < len(values) && b.collation.CompareOrder(values[end].v, values[end-1].v, types.Ascending) == types.Equal {
+				end++
+			}
+		}
+
+		// the upper bound is the lower bound of the next bucket, if any
+		upper := values[end-1].v
+		if end < len(values) {
+			upper = values[end].v
+		}
+
+		docs := make([]*types.Document, end-start)
+		for i, v := range values[start:end] {
+			docs[i] = v.doc
+		}
+
+		id := must.NotFail(types.NewDocument("min", values[start].v, "max", upper))
+
+		doc, err := accumulateBucket(id, docs, b.output)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, doc)
+		start = end
+	}
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*bucketAuto)(nil)
+)
@@ -0,0 +1,493 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// densifyMaxDocuments is the maximum number of documents $densify stage could generate.
+const densifyMaxDocuments = 500_000
+
+// densifyUnits contains valid values of $densify range unit.
+var densifyUnits = []string{"millisecond", "second", "minute", "hour", "day", "week", "month", "quarter", "year"}
+
+// densify represents $densify stage.
+//
+//	{ $densify: {
+//		field: <field>,
+//		partitionByFields: [<field>, ...],
+//		range: {
+//			step: <number>,
+//			unit: <time unit>,
+//			bounds: "full" | "partition" | [<lower bound>, <upper bound>]
+//		}
+//	}}
+//
+// $densify creates new documents to fill gaps of field values in the sequence of documents.
+// Generated values start at the lower bound and increase by step.
+// For "full" bounds, they are computed across all documents;
+// for "partition" bounds, they are computed for each partition.
+// Explicit upper bound is exclusive.
+// Generated documents contain only the densified field and partition fields.
+// Documents without the field (or with null value) are returned as is.
+type densify struct {
+	field             types.Path
+	partitionByFields []types.Path
+	step              any    // positive number
+	unit              string // empty for numeric field
+	bounds            string // "full", "partition", or empty for explicit bounds
+	lower             any    // for explicit bounds
+	upper             any    // for explicit bounds
+}
+
+// newDensify creates a new $densify stage.
+func newDensify(stage *types.Document) (aggregations.Stage, error) {
+	fields, ok := must.NotFail(stage.Get("$densify")).(*types.Document)
+	if !ok {
+		return nil, densifyTypeError("$densify", must.NotFail(stage.Get("$densify")), "object")
+	}
+
+	var d densify
+
+	for _, key := range fields.Keys() {
+		v := must.NotFail(fields.Get(key))
+
+		switch key {
+		case "field":
+			field, ok := v.(string)
+			if !ok {
+				return nil, densifyTypeError("$densify.field", v, "string")
+			}
+
+			path, err := types.NewPathFromString(field)
+			if err != nil {
+				return nil, densifyBadValue(fmt.Sprintf("Invalid field path '%s'", field))
+			}
+
+			d.field = path
+
+		case "partitionByFields":
+			arr, ok := v.(*types.Array)
+			if !ok {
+				return nil, densifyTypeError("$densify.partitionByFields", v, "array")
+			}
+
+			for i := 0; i < arr.Len(); i++ {
+				v := must.NotFail(arr.Get(i))
+
+				field, ok := v.(string)
+				if !ok {
+					return nil, densifyTypeError(fmt.Sprintf("$densify.partitionByFields.%d", i), v, "string")
+				}
+
+				path, err := types.NewPathFromString(field)
+				if err != nil {
+					return nil, densifyBadValue(fmt.Sprintf("Invalid field path '%s'", field))
+				}
+
+				d.partitionByFields = append(d.partitionByFields, path)
+			}
+
+		case "range":
+			rangeDoc, ok := v.(*types.Document)
+			if !ok {
+				return nil, densifyTypeError("$densify.range", v, "object")
+			}
+
+			if err := d.parseRange(rangeDoc); err != nil {
+				return nil, err
+			}
+
+		default:
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$densify.%s' is an unknown field.", key),
+				"$densify (stage)",
+			)
+		}
+	}
+
+	for _, key := range []string{"field", "range"} {
+		if !fields.Has(key) {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrMissingField,
+				fmt.Sprintf("BSON field '$densify.%s' is missing but a required field", key),
+				"$densify (stage)",
+			)
+		}
+	}
+
+	if d.bounds == "partition" && len(d.partitionByFields) == 0 {
+		return nil, densifyBadValue(
+			"one may not specify the bounds as 'partition' without specifying a non-empty array of partitionByFields",
+		)
+	}
+
+	for _, p := range d.partitionByFields {
+		if p.String() == d.field.String() {
+			return nil, densifyBadValue("field cannot be included in partitionByFields array")
+		}
+	}
+
+	return &d, nil
+}
+
+// parseRange validates and sets fields of $densify range.
+func (d *densify) parseRange(rangeDoc *types.Document) error {
+	for _, key := range rangeDoc.Keys() {
+		v := must.NotFail(rangeDoc.Get(key))
+
+		switch key {
+		case "step":
+			switch v.(type) {
+			case float64, int32, int64:
+			default:
+				return densifyBadValue("The step parameter in a range statement must be a strictly positive numeric value")
+			}
+
+			if types.CompareOrder(v, int32(0), types.Ascending) != types.Greater {
+				return densifyBadValue("The step parameter in a range statement must be a strictly positive numeric value")
+			}
+
+			d.step = v
+
+		case "unit":
+			unit, ok := v.(string)
+			if !ok {
+				return densifyTypeError("$densify.range.unit", v, "string")
+			}
+
+			if !slices.Contains(densifyUnits, unit) {
+				return densifyBadValue(fmt.Sprintf("unknown time unit value: %s", unit))
+			}
+
+			d.unit = unit
+
+		case "bounds":
+			switch v := v.(type) {
+			case *types.Array:
+				if v.Len() != 2 {
+					return densifyBadValue("A bounding array in a range statement must have exactly two elements")
+				}
+
+				d.lower, d.upper = must.NotFail(v.Get(0)), must.NotFail(v.Get(1))
+
+			case string:
+				if v != "full" && v != "partition" {
+					return densifyBadValue(fmt.Sprintf("Bounds string must either be 'full' or 'partition', found %s", v))
+				}
+
+				d.bounds = v
+
+			default:
+				return densifyBadValue("Range bounds must be a string or an array")
+			}
+
+		default:
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$densify.range.%s' is an unknown field.", key),
+				"$densify (stage)",
+			)
+		}
+	}
+
+	for _, key := range []string{"step", "bounds"} {
+		if !rangeDoc.Has(key) {
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrMissingField,
+				fmt.Sprintf("BSON field '$densify.range.%s' is missing but a required field", key),
+				"$densify (stage)",
+			)
+		}
+	}
+
+	if d.unit != "" {
+		if _, err := handlerparams.GetWholeNumberParam(d.step); err != nil {
+			return densifyBadValue("The step parameter in a range statement must be a whole number when densifying a date range")
+		}
+	}
+
+	if d.lower == nil {
+		return nil
+	}
+
+	for _, bound := range []any{d.lower, d.upper} {
+		if err := d.checkValue(bound); err != nil {
+			if d.unit == "" {
+				return densifyBadValue("A bounding array must contain either both dates or both numeric types")
+			}
+
+			return densifyBadValue("A bounding array must be both dates if using a unit")
+		}
+	}
+
+	if types.CompareOrder(d.lower, d.upper, types.Ascending) == types.Greater {
+		return densifyBadValue("A bounding array in a range statement must be sorted")
+	}
+
+	return nil
+}
+
+// densifyTypeError returns an error for $densify field of the wrong type.
+func densifyTypeError(field string, v any, expected string) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(
+		handlererrors.ErrTypeMismatch,
+		fmt.Sprintf("BSON field '%s' is the wrong type '%s', expected type '%s'", field, handlerparams.AliasFromType(v), expected),
+		"$densify (stage)",
+	)
+}
+
+// densifyBadValue returns an error for invalid $densify value.
+func densifyBadValue(msg string) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrBadValue, msg, "$densify (stage)")
+}
+
+// checkValue returns an error if the given value of the densified field
+// is not a number (for numeric range) or not a date (for range with unit).
+func (d *densify) checkValue(v any) error {
+	if d.unit != "" {
+		if _, ok := v.(time.Time); !ok {
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrTypeMismatch,
+				"Densify field type must be a date when unit is specified",
+				"$densify (stage)",
+			)
+		}
+
+		return nil
+	}
+
+	switch v.(type) {
+	case float64, int32, int64:
+		return nil
+	default:
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrTypeMismatch,
+			"Densify field type must be numeric",
+			"$densify (stage)",
+		)
+	}
+}
+
+// Process implements Stage interface.
+func (d *densify) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	docs, err := iterator.ConsumeValues(iter)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// documents without densified field are returned at the end as is
+	var rest []*types.Document
+
+	var partitions groupMap
+
+	var lo, hi any
+
+	for _, doc := range docs {
+		v, _ := doc.GetByPath(d.field)
+		if v == nil || v == types.Null {
+			rest = append(rest, doc)
+			continue
+		}
+
+		if err = d.checkValue(v); err != nil {
+			return nil, err
+		}
+
+		if lo == nil || types.CompareOrder(v, lo, types.Ascending) == types.Less {
+			lo = v
+		}
+
+		if hi == nil || types.CompareOrder(v, hi, types.Ascending) == types.Greater {
+			hi = v
+		}
+
+		key := types.MakeDocument(len(d.partitionByFields))
+
+		for _, p := range d.partitionByFields {
+			if pv, _ := doc.GetByPath(p); pv != nil {
+				key.Set(p.String(), pv)
+			}
+		}
+
+		partitions.addOrAppend(key, doc)
+	}
+
+	var res []*types.Document
+	var generated int
+
+	for _, partition := range partitions.docs {
+		group := partition.documents
+
+		slices.SortStableFunc(group, func(a, b *types.Document) int {
+			return int(types.CompareOrder(must.NotFail(a.GetByPath(d.field)), must.NotFail(b.GetByPath(d.field)), types.Ascending))
+		})
+
+		var start, end any
+		inclusive := true
+
+		switch d.bounds {
+		case "full":
+			start, end = lo, hi
+		case "partition":
+			start = must.NotFail(group[0].GetByPath(d.field))
+			end = must.NotFail(group[len(group)-1].GetByPath(d.field))
+		default:
+			start, end = d.lower, d.upper
+			inclusive = false
+		}
+
+		// inRange checks if generated value is not above the upper bound.
+		inRange := func(v any) bool {
+			res := types.CompareOrder(v, end, types.Ascending)
+			return res == types.Less || (inclusive && res == types.Equal)
+		}
+
+		key := partition.groupID.(*types.Document)
+
+		cur := start
+		var n int64
+
+		for _, doc := range group {
+			v := must.NotFail(doc.GetByPath(d.field))
+
+			for types.CompareOrder(cur, v, types.Ascending) == types.Less && inRange(cur) {
+				if generated++; generated > densifyMaxDocuments {
+					return nil, d.tooManyDocumentsError()
+				}
+
+				res = append(res, d.generate(key, cur))
+				n++
+				cur = d.next(start, cur, n)
+			}
+
+			if types.CompareOrder(cur, v, types.Ascending) == types.Equal && inRange(cur) {
+				n++
+				cur = d.next(start, cur, n)
+			}
+
+			res = append(res, doc)
+		}
+
+		for inRange(cur) {
+			if generated++; generated > densifyMaxDocuments {
+				return nil, d.tooManyDocumentsError()
+			}
+
+			res = append(res, d.generate(key, cur))
+			n++
+			cur = d.next(start, cur, n)
+		}
+	}
+
+	res = append(res, rest...)
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// generate returns a new document with partition fields and the given value of densified field.
+func (d *densify) generate(key *types.Document, v any) *types.Document {
+	doc := types.MakeDocument(key.Len() + 1)
+
+	for _, k := range key.Keys() {
+		must.NoError(doc.SetByPath(must.NotFail(types.NewPathFromString(k)), must.NotFail(key.Get(k))))
+	}
+
+	must.NoError(doc.SetByPath(d.field, v))
+
+	return doc
+}
+
+// next returns the n-th value after the start value.
+//
+// Numbers are incremented by step from the current value, keeping their type when possible.
+// Dates are computed from the start value to avoid drifting of month days.
+func (d *densify) next(start, cur any, n int64) any {
+	if d.unit == "" {
+		return aggregations.SumNumbers(cur, d.step)
+	}
+
+	step := must.NotFail(handlerparams.GetWholeNumberParam(d.step))
+
+	return densifyDateAdd(start.(time.Time), d.unit, n*step)
+}
+
+// tooManyDocumentsError returns an error for exceeding the limit of generated documents.
+func (d *densify) tooManyDocumentsError() error {
+	return handlererrors.NewCommandErrorMsgWithArgument(
+		handlererrors.ErrStageDensifyTooManyDocuments,
+		fmt.Sprintf("Generated more than %d documents in $densify", densifyMaxDocuments),
+		"$densify (stage)",
+	)
+}
+
+// densifyDateAdd adds amount of units to the given time.
+//
+// If the day of month does not exist after adding months, quarters or years,
+// the last day of the month is used.
+func densifyDateAdd(t time.Time, unit string, amount int64) time.Time {
+	var months int64
+
+	switch unit {
+	case "millisecond":
+		return t.Add(time.Duration(amount) * time.Millisecond)
+	case "second":
+		return t.Add(time.Duration(amount) * time.Second)
+	case "minute":
+		return t.Add(time.Duration(amount) * time.Minute)
+	case "hour":
+		return t.Add(time.Duration(amount) * time.Hour)
+	case "day":
+		return t.Add(time.Duration(amount) * 24 * time.Hour)
+	case "week":
+		return t.Add(time.Duration(amount) * 7 * 24 * time.Hour)
+	case "month":
+		months = amount
+	case "quarter":
+		months = amount * 3
+	case "year":
+		months = amount * 12
+	default:
+		panic(fmt.Sprintf("unexpected unit %q", unit))
+	}
+
+	y, m, day := t.Date()
+
+	first := time.Date(y, m+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+
+	return first.AddDate(0, 0, min(day, last)-1)
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*densify)(nil)
+)
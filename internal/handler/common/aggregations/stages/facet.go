@@ -0,0 +1,402 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// DefaultFacetMemoryLimit is the default maximum total size of documents in bytes
+// produced by a single $facet sub-pipeline.
+// It is the same as MongoDB's internalQueryFacetMaxOutputDocSizeBytes default.
+const DefaultFacetMemoryLimit = 100 * 1024 * 1024
+
+// facetNotAllowedStages contains stages that could not be used within $facet stage.
+var facetNotAllowedStages = map[string]struct{}{
+	"$collStats":      {},
+	"$facet":          {},
+	"$indexStats":     {},
+	"$out":            {},
+	"$merge":          {},
+	"$planCacheStats": {},
+	"$queryStats":     {},
+}
+
+// facet represents $facet stage.
+//
+// Input documents are read once into a shared buffer that all sub-pipelines iterate.
+// Sub-pipelines that start with the same $match stage share a single buffer of matched documents,
+// and sub-pipelines that only count those documents with $count do not iterate them at all.
+type facet struct {
+	pipelines    []*facetPipeline
+	collation    *types.Collation // for the leading $match stages
+	allowDiskUse bool
+	memoryLimit  int // for the shared buffers
+	outputLimit  int // for each sub-pipeline's output
+	spills       int // set by Process
+}
+
+// facetPipeline represents a single $facet sub-pipeline.
+type facetPipeline struct {
+	name     string
+	stages   []*types.Document
+	pipeline []aggregations.Stage // stages without the leading $match and the only $count
+
+	match  *types.Document // filter of the leading $match stage, if any
+	count  string          // field of the only remaining $count stage, if any
+	shared *facetPipeline  // previous sub-pipeline with the same $match stage, if any
+
+	buffer *common.Buffer // matched documents, set by Process
+	stats  facetStats     // set by Process
+}
+
+// facetStats represents execution statistics of a single $facet sub-pipeline.
+type facetStats struct {
+	nReturned   int
+	outputBytes int
+	spills      int
+}
+
+// newFacet creates a new $facet stage.
+func newFacet(stage *types.Document) (aggregations.Stage, error) {
+	v := must.NotFail(stage.Get("$facet"))
+
+	spec, ok := v.(*types.Document)
+	if !ok || spec.Len() == 0 {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageFacetInvalid,
+			fmt.Sprintf("the $facet specification must be a non-empty object, but found: %s", types.FormatAnyValue(v)),
+			"$facet (stage)",
+		)
+	}
+
+	pipelines := make([]*facetPipeline, 0, spec.Len())
+
+	for _, name := range spec.Keys() {
+		switch {
+		case name == "":
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrPathContainsEmptyElement,
+				"FieldPath field names may not be empty strings.",
+				"$facet (stage)",
+			)
+		case strings.HasPrefix(name, "$"):
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFieldPathInvalidName,
+				"FieldPath field names may not start with '$'. Consider using $getField or $setField.",
+				"$facet (stage)",
+			)
+		}
+
+		v = must.NotFail(spec.Get(name))
+
+		arr, ok := v.(*types.Array)
+		if !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrStageFacetNotArray,
+				fmt.Sprintf(
+					"arguments to $facet must be arrays, %s is type %s",
+					name, handlerparams.AliasFromType(v),
+				),
+				"$facet (stage)",
+			)
+		}
+
+		if arr.Len() == 0 {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrBadValue,
+				"sub-pipeline in $facet stage cannot be empty",
+				"$facet (stage)",
+			)
+		}
+
+		p := &facetPipeline{
+			name:   name,
+			stages: make([]*types.Document, arr.Len()),
+		}
+
+		for i, v := range must.NotFail(iterator.ConsumeValues(arr.Iterator())) {
+			d, ok := v.(*types.Document)
+			if !ok || d.Len() == 0 {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrStageFacetInvalidElement,
+					fmt.Sprintf(
+						"elements of arrays in $facet spec must be non-empty objects, %s argument contained an element of type %s: %s",
+						name, handlerparams.AliasFromType(v), types.FormatAnyValue(v),
+					),
+					"$facet (stage)",
+				)
+			}
+
+			if _, ok = facetNotAllowedStages[d.Command()]; ok {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrStageFacetNotAllowed,
+					fmt.Sprintf("%s is not allowed to be used within a $facet stage", d.Command()),
+					"$facet (stage)",
+				)
+			}
+
+			p.stages[i] = d
+		}
+
+		pipelines = append(pipelines, p)
+	}
+
+	return &facet{
+		pipelines: pipelines,
+	}, nil
+}
+
+// init creates sub-pipelines' stages with the given parameters.
+func (f *facet) init(params *NewStageParams) error {
+	f.collation = params.Collation
+	f.allowDiskUse = params.AllowDiskUse
+	f.memoryLimit = params.SortMemoryLimit
+
+	for i, p := range f.pipelines {
+		stages := p.stages
+
+		if stages[0].Command() == "$match" {
+			s, err := NewStage(stages[0], params)
+			if err != nil {
+				return err
+			}
+
+			p.match = s.(*match).filter
+			stages = stages[1:]
+
+			for _, prev := range f.pipelines[:i] {
+				if prev.match != nil && types.Identical(prev.match, p.match) {
+					p.shared = prev
+					break
+				}
+			}
+		}
+
+		p.pipeline = make([]aggregations.Stage, len(stages))
+
+		for j, d := range stages {
+			s, err := NewStage(d, params)
+			if err != nil {
+				return err
+			}
+
+			p.pipeline[j] = s
+		}
+
+		if len(p.pipeline) == 1 {
+			if c, ok := p.pipeline[0].(*count); ok {
+				p.count = c.field
+				p.pipeline = nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// Process implements Stage interface.
+//
+// It fully consumes and closes the input iterator and returns a single document with sub-pipelines' results.
+func (f *facet) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	bufferParams := &common.BufferParams{
+		MemoryLimit:  f.memoryLimit,
+		AllowDiskUse: f.allowDiskUse,
+	}
+
+	input, err := common.NewBuffer(iter, bufferParams)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	defer input.Close()
+
+	f.spills = input.Spills()
+
+	defer func() {
+		for _, p := range f.pipelines {
+			if p.buffer != nil {
+				p.buffer.Close()
+				p.buffer = nil
+			}
+		}
+	}()
+
+	res := types.MakeDocument(len(f.pipelines))
+
+	for _, p := range f.pipelines {
+		var arr *types.Array
+
+		if arr, err = f.processPipeline(ctx, p, input, bufferParams); err != nil {
+			return nil, err
+		}
+
+		res.Set(p.name, arr)
+	}
+
+	iter = iterator.Values(iterator.ForSlice([]*types.Document{res}))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// processPipeline runs a single sub-pipeline on the shared input buffer and returns its results.
+func (f *facet) processPipeline(ctx context.Context, p *facetPipeline, input *common.Buffer, params *common.BufferParams) (*types.Array, error) { //nolint:lll // for readability
+	p.stats = facetStats{}
+
+	src := input
+
+	if p.match != nil {
+		switch {
+		case p.shared != nil:
+			src = p.shared.buffer
+
+		default:
+			closer := iterator.NewMultiCloser()
+			matched := common.FilterIterator(input.Iterator(closer), closer, p.match, f.collation)
+
+			var err error
+
+			p.buffer, err = common.NewBuffer(matched, params)
+
+			closer.Close()
+
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			src = p.buffer
+		}
+
+		p.stats.spills = src.Spills()
+	}
+
+	if p.count != "" {
+		res := types.MakeArray(1)
+
+		if src.Len() > 0 {
+			res.Append(must.NotFail(types.NewDocument(p.count, int32(src.Len()))))
+			p.stats.nReturned = 1
+		}
+
+		return res, nil
+	}
+
+	closer := iterator.NewMultiCloser()
+	defer closer.Close()
+
+	iter := src.Iterator(closer)
+
+	for _, s := range p.pipeline {
+		var err error
+		if iter, err = s.Process(ctx, iter, closer); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := f.outputLimit
+	if limit == 0 {
+		limit = DefaultFacetMemoryLimit
+	}
+
+	res := types.MakeArray(0)
+
+	for {
+		_, doc, err := iter.Next()
+		if err != nil {
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		size, err := documentSize(doc)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if p.stats.outputBytes += size; p.stats.outputBytes > limit {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrStageFacetTooLarge,
+				fmt.Sprintf(
+					"document constructed by $facet is %d bytes, which exceeds the limit of %d bytes",
+					p.stats.outputBytes, limit,
+				),
+				"$facet (stage)",
+			)
+		}
+
+		res.Append(doc)
+		p.stats.nReturned++
+	}
+
+	return res, nil
+}
+
+// Explain implements ExplainStage interface.
+func (f *facet) Explain() *types.Document {
+	facets := types.MakeDocument(len(f.pipelines))
+
+	for _, p := range f.pipelines {
+		facets.Set(p.name, must.NotFail(types.NewDocument(
+			"nReturned", int64(p.stats.nReturned),
+			"outputBytes", int64(p.stats.outputBytes),
+			"sharedMatch", p.shared != nil,
+			"countOnly", p.count != "",
+			"usedDisk", p.stats.spills > 0,
+		)))
+	}
+
+	return must.NotFail(types.NewDocument(
+		"usedDisk", f.spills > 0,
+		"spills", int64(f.spills),
+		"facets", facets,
+	))
+}
+
+// documentSize returns the size of the encoded document in bytes.
+func documentSize(doc *types.Document) (int, error) {
+	d, err := bson.ConvertDocument(doc)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	raw, err := d.Encode()
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	return len(raw), nil
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage        = (*facet)(nil)
+	_ aggregations.ExplainStage = (*facet)(nil)
+)
@@ -0,0 +1,441 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// fillOutput represents a single output field of $fill stage.
+type fillOutput struct {
+	field  types.Path
+	value  operators.Operator // nil if method is set
+	method string             // "locf", "linear", or empty if value is set
+}
+
+// fill represents $fill stage.
+//
+//	{ $fill: {
+//		partitionBy: <expression>,
+//		partitionByFields: [<field>, ...],
+//		sortBy: { <field>: <sort order>, ... },
+//		output: {
+//			<field>: { value: <expression> },
+//			<field>: { method: "linear" | "locf" },
+//			...
+//		}
+//	}}
+//
+// $fill sets null and missing output fields of documents.
+// The value option sets them to the evaluated expression.
+// The locf method sets them to the last non-null value of the field in the partition sorted by sortBy;
+// the linear method interpolates them between surrounding non-null values using the single sortBy field.
+type fill struct {
+	partitionBy       operators.Operator // nil if not set
+	partitionByFields []types.Path
+	sortBy            *types.Document // nil if not set
+	output            []fillOutput
+}
+
+// newFill creates a new $fill stage.
+func newFill(stage *types.Document) (aggregations.Stage, error) {
+	fields, ok := must.NotFail(stage.Get("$fill")).(*types.Document)
+	if !ok {
+		return nil, fillTypeError("$fill", must.NotFail(stage.Get("$fill")), "object")
+	}
+
+	var f fill
+	var err error
+
+	for _, key := range fields.Keys() {
+		v := must.NotFail(fields.Get(key))
+
+		switch key {
+		case "partitionBy":
+			if f.partitionBy, err = operators.NewExpr(must.NotFail(types.NewDocument("$expr", v)), "$fill (stage)"); err != nil {
+				return nil, err
+			}
+
+		case "partitionByFields":
+			arr, ok := v.(*types.Array)
+			if !ok {
+				return nil, fillTypeError("$fill.partitionByFields", v, "array")
+			}
+
+			for i := 0; i < arr.Len(); i++ {
+				v := must.NotFail(arr.Get(i))
+
+				field, ok := v.(string)
+				if !ok {
+					return nil, fillTypeError(fmt.Sprintf("$fill.partitionByFields.%d", i), v, "string")
+				}
+
+				path, err := types.NewPathFromString(field)
+				if err != nil {
+					return nil, fillBadValue(fmt.Sprintf("Invalid field path '%s'", field))
+				}
+
+				f.partitionByFields = append(f.partitionByFields, path)
+			}
+
+		case "sortBy":
+			if f.sortBy, ok = v.(*types.Document); !ok {
+				return nil, fillTypeError("$fill.sortBy", v, "object")
+			}
+
+			for _, k := range f.sortBy.Keys() {
+				if _, err = common.GetSortType(k, must.NotFail(f.sortBy.Get(k))); err != nil {
+					return nil, err
+				}
+			}
+
+		case "output":
+			output, ok := v.(*types.Document)
+			if !ok {
+				return nil, fillTypeError("$fill.output", v, "object")
+			}
+
+			if f.output, err = newFillOutput(output); err != nil {
+				return nil, err
+			}
+
+		default:
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$fill.%s' is an unknown field.", key),
+				"$fill (stage)",
+			)
+		}
+	}
+
+	if !fields.Has("output") {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrMissingField,
+			"BSON field '$fill.output' is missing but a required field",
+			"$fill (stage)",
+		)
+	}
+
+	if f.partitionBy != nil && f.partitionByFields != nil {
+		return nil, fillBadValue("Only one of 'partitionBy' and 'partitionByFields' may be specified in '$fill'")
+	}
+
+	for _, o := range f.output {
+		switch o.method {
+		case "":
+			continue
+		case "linear":
+			if f.sortBy == nil || f.sortBy.Len() != 1 {
+				return nil, fillBadValue("Method 'linear' in '$fill' requires exactly one field in 'sortBy'")
+			}
+		}
+
+		if f.sortBy == nil {
+			return nil, fillBadValue("'sortBy' is required in '$fill' if any output field specifies a 'method'")
+		}
+	}
+
+	return &f, nil
+}
+
+// newFillOutput validates and returns output fields of $fill stage.
+func newFillOutput(output *types.Document) ([]fillOutput, error) {
+	if output.Len() == 0 {
+		return nil, fillBadValue("'output' in '$fill' must contain at least one field")
+	}
+
+	res := make([]fillOutput, 0, output.Len())
+
+	for _, field := range output.Keys() {
+		path, err := types.NewPathFromString(field)
+		if err != nil {
+			return nil, fillBadValue(fmt.Sprintf("Invalid field path '%s'", field))
+		}
+
+		v := must.NotFail(output.Get(field))
+
+		spec, ok := v.(*types.Document)
+		if !ok {
+			return nil, fillTypeError("$fill.output."+field, v, "object")
+		}
+
+		if spec.Len() != 1 {
+			return nil, fillBadValue("Exactly one of 'value' or 'method' must be specified in '$fill' output field")
+		}
+
+		o := fillOutput{
+			field: path,
+		}
+
+		switch spec.Command() {
+		case "value":
+			o.value, err = operators.NewExpr(must.NotFail(types.NewDocument("$expr", must.NotFail(spec.Get("value")))), "$fill (stage)")
+			if err != nil {
+				return nil, err
+			}
+
+		case "method":
+			method := must.NotFail(spec.Get("method"))
+
+			if method != "locf" && method != "linear" {
+				return nil, fillBadValue(fmt.Sprintf("Method must be either 'locf' or 'linear', found %s", types.FormatAnyValue(method)))
+			}
+
+			o.method = method.(string)
+
+		default:
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$fill.output.%s.%s' is an unknown field.", field, spec.Command()),
+				"$fill (stage)",
+			)
+		}
+
+		res = append(res, o)
+	}
+
+	return res, nil
+}
+
+// fillTypeError returns an error for $fill field of the wrong type.
+func fillTypeError(field string, v any, expected string) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(
+		handlererrors.ErrTypeMismatch,
+		fmt.Sprintf("BSON field '%s' is the wrong type '%s', expected type '%s'", field, handlerparams.AliasFromType(v), expected),
+		"$fill (stage)",
+	)
+}
+
+// fillBadValue returns an error for invalid $fill value.
+func fillBadValue(msg string) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrBadValue, msg, "$fill (stage)")
+}
+
+// Process implements Stage interface.
+func (f *fill) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	docs, err := iterator.ConsumeValues(iter)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var partitions groupMap
+
+	for _, doc := range docs {
+		var key any
+
+		switch {
+		case f.partitionBy != nil:
+			if key, err = f.partitionBy.Process(doc); err != nil {
+				return nil, err
+			}
+
+		case f.partitionByFields != nil:
+			keyDoc := types.MakeDocument(len(f.partitionByFields))
+
+			for _, p := range f.partitionByFields {
+				if v, _ := doc.GetByPath(p); v != nil {
+					keyDoc.Set(p.String(), v)
+				}
+			}
+
+			key = keyDoc
+
+		default:
+			key = types.Null
+		}
+
+		partitions.addOrAppend(key, doc)
+	}
+
+	res := make([]*types.Document, 0, len(docs))
+
+	for _, partition := range partitions.docs {
+		group := partition.documents
+
+		if f.sortBy != nil {
+			if err = common.SortDocuments(group, f.sortBy, nil); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		for _, o := range f.output {
+			if err = f.fillPartition(group, &o); err != nil {
+				return nil, err
+			}
+		}
+
+		res = append(res, group...)
+	}
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// fillPartition sets null and missing output field of sorted partition documents.
+func (f *fill) fillPartition(docs []*types.Document, o *fillOutput) error {
+	switch o.method {
+	case "":
+		for _, doc := range docs {
+			if !fillIsNull(doc, o.field) {
+				continue
+			}
+
+			v, err := o.value.Process(doc)
+			if err != nil {
+				return err
+			}
+
+			if err = doc.SetByPath(o.field, v); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+	case "locf":
+		var last any = types.Null
+
+		for _, doc := range docs {
+			if !fillIsNull(doc, o.field) {
+				last = must.NotFail(doc.GetByPath(o.field))
+				continue
+			}
+
+			if err := doc.SetByPath(o.field, last); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+	case "linear":
+		return f.fillLinear(docs, o)
+
+	default:
+		panic(fmt.Sprintf("unexpected method %q", o.method))
+	}
+
+	return nil
+}
+
+// fillLinear sets null and missing output field of sorted partition documents
+// by linear interpolation between surrounding non-null values.
+// Documents without preceding or following non-null value get null.
+func (f *fill) fillLinear(docs []*types.Document, o *fillOutput) error {
+	sortPath := must.NotFail(types.NewPathFromString(f.sortBy.Command()))
+
+	xs := make([]float64, len(docs))
+	ys := make([]any, len(docs)) // nil for null and missing values
+
+	for i, doc := range docs {
+		x, _ := doc.GetByPath(sortPath)
+
+		switch x := x.(type) {
+		case float64, int32, int64:
+			xs[i] = fillFloat(x)
+		case time.Time:
+			xs[i] = float64(x.UnixMilli())
+		default:
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrTypeMismatch,
+				"The sortBy field of '$fill' with method 'linear' must be numeric or a date",
+				"$fill (stage)",
+			)
+		}
+
+		if i > 0 && xs[i] == xs[i-1] {
+			return fillBadValue("There can be no repeated values in the sortBy field of '$fill' with method 'linear'")
+		}
+
+		if fillIsNull(doc, o.field) {
+			continue
+		}
+
+		y := must.NotFail(doc.GetByPath(o.field))
+
+		switch y.(type) {
+		case float64, int32, int64:
+			ys[i] = y
+		default:
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrTypeMismatch,
+				"Values filled by '$fill' with method 'linear' must be numeric or null",
+				"$fill (stage)",
+			)
+		}
+	}
+
+	prev := -1
+
+	for i := range docs {
+		if ys[i] != nil {
+			prev = i
+			continue
+		}
+
+		var v any = types.Null
+
+		next := i + 1
+		for next < len(docs) && ys[next] == nil {
+			next++
+		}
+
+		if prev >= 0 && next < len(docs) {
+			y0, y1 := fillFloat(ys[prev]), fillFloat(ys[next])
+			v = y0 + (y1-y0)*(xs[i]-xs[prev])/(xs[next]-xs[prev])
+		}
+
+		if err := docs[i].SetByPath(o.field, v); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// fillIsNull returns true if the field is null or missing.
+func fillIsNull(doc *types.Document, path types.Path) bool {
+	v, _ := doc.GetByPath(path)
+	return v == nil || v == types.Null
+}
+
+// fillFloat converts number to float64.
+func fillFloat(v any) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	default:
+		panic(fmt.Sprintf("unexpected type %T", v))
+	}
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*fill)(nil)
+)
@@ -45,6 +45,7 @@ import (
 // For each group of documents, accumulators are applied.
 type group struct {
 	groupExpression any
+	collation       *types.Collation
 	groupBy         []groupBy
 }
 
@@ -128,11 +129,12 @@ func (g *group) Process(ctx context.Context, iter types.DocumentsIterator, close
 	for _, groupedDocument := range groupedDocuments {
 		doc := must.NotFail(types.NewDocument("_id", groupedDocument.groupID))
 
-		groupIter := iterator.Values(iterator.ForSlice(groupedDocument.documents))
-		defer groupIter.Close()
-
 		for _, accumulation := range g.groupBy {
+			// each accumulator consumes its own iterator over the group
+			groupIter := iterator.Values(iterator.ForSlice(groupedDocument.documents))
 			out, err := accumulation.accumulator.Accumulate(groupIter)
+			groupIter.Close()
+
 			if err != nil {
 				// existing accumulators do not return error
 				return nil, processGroupStageError(err)
@@ -227,7 +229,9 @@ func validateGroupKey(groupKey any) error {
 // groupDocuments groups documents into groups using group key. If group key contains expressions
 // or operators, they are evaluated before using it as the group key of documents.
 func (g *group) groupDocuments(iter types.DocumentsIterator) ([]groupedDocuments, error) {
-	var m groupMap
+	m := groupMap{
+		collation: g.collation,
+	}
 
 	for {
 		_, doc, err := iter.Next()
@@ -249,7 +253,7 @@ func (g *group) groupDocuments(iter types.DocumentsIterator) ([]groupedDocuments
 
 			m.addOrAppend(val, doc)
 		case *types.Array, float64, types.Binary, types.ObjectID, bool, time.Time, types.NullType,
-			types.Regex, int32, types.Timestamp, int64:
+			types.Regex, int32, types.Timestamp, int64, types.Decimal128:
 			m.addOrAppend(groupKey, doc)
 		case string:
 			expression, err := aggregations.NewExpression(groupKey, nil)
@@ -364,7 +368,8 @@ type groupedDocuments struct {
 
 // groupMap holds groups of documents.
 type groupMap struct {
-	docs []groupedDocuments
+	collation *types.Collation // used to compare string group keys
+	docs      []groupedDocuments
 }
 
 // addOrAppend adds a groupID documents pair if the groupID does not exist,
@@ -375,7 +380,7 @@ func (m *groupMap) addOrAppend(groupKey any, docs ...*types.Document) {
 		// so we cannot use structure like map.
 		// Compare is used to check if groupID exists in groupMap, because
 		// numbers are grouped for the same value regardless of their number type.
-		if types.CompareForAggregation(groupKey, g.groupID) == types.Equal {
+		if m.collation.CompareForAggregation(groupKey, g.groupID) == types.Equal {
 			m.docs[i].documents = append(m.docs[i].documents, docs...)
 			return
 		}
@@ -0,0 +1,56 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+)
+
+// indexStats represents $indexStats stage.
+//
+// Input documents (one per index) are produced by the handler.
+type indexStats struct{}
+
+// newIndexStats creates a new $indexStats stage.
+func newIndexStats(stage *types.Document) (aggregations.Stage, error) {
+	fields, err := common.GetRequiredParam[*types.Document](stage, "$indexStats")
+	if err != nil || fields.Len() != 0 {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageIndexStatsInvalidArg,
+			"The $indexStats stage specification must be an empty object",
+			"$indexStats (stage)",
+		)
+	}
+
+	return new(indexStats), nil
+}
+
+// Process implements Stage interface.
+//
+// It returns input documents as is.
+func (is *indexStats) Process(_ context.Context, iter types.DocumentsIterator, _ *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	return iter, nil
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*indexStats)(nil)
+)
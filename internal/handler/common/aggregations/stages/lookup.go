@@ -0,0 +1,737 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handler/commonpath"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// LookupFunc returns documents of the given collection in the current database for $lookup stage.
+//
+// Filter may be nil, ignored, or safely applied partially or entirely by the backend;
+// extra documents are filtered out by the stage.
+type LookupFunc func(ctx context.Context, collection string, filter *types.Document) (types.DocumentsIterator, error)
+
+// lookupNotAllowedStages contains stages that could not be used within $lookup sub-pipeline.
+var lookupNotAllowedStages = map[string]struct{}{
+	"$collStats":      {},
+	"$indexStats":     {},
+	"$merge":          {},
+	"$out":            {},
+	"$planCacheStats": {},
+	"$queryStats":     {},
+}
+
+// lookup represents $lookup stage.
+//
+// For each input document, it reads documents of the foreign collection that match
+// the equality of localField and foreignField, and/or processes them through the sub-pipeline
+// with let variables of the input document; the result is set as an array to the as field.
+//
+// The equality condition and the leading $match stage of the sub-pipeline are pushed down to the backend.
+// To make that possible for the pipeline form, let variables are substituted into sub-pipeline stages,
+// and $expr with $eq of a field path and a let variable in $match stage is replaced by the equality condition.
+type lookup struct {
+	from         string
+	as           types.Path
+	localField   *types.Path // nil for the pipeline form
+	foreignField *types.Path // nil for the pipeline form
+	let          *types.Document
+	pipeline     []*types.Document // nil for the localField/foreignField form
+
+	params *NewStageParams // for sub-pipeline stages, set by init
+}
+
+// newLookup creates a new $lookup stage.
+func newLookup(stage *types.Document) (aggregations.Stage, error) {
+	v := must.NotFail(stage.Get("$lookup"))
+
+	spec, ok := v.(*types.Document)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageLookupInvalid,
+			fmt.Sprintf("the $lookup specification must be an Object, but found %s", handlerparams.AliasFromType(v)),
+			"$lookup (stage)",
+		)
+	}
+
+	var l lookup
+	var as, localField, foreignField string
+	var hasPipeline bool
+
+	for _, key := range spec.Keys() {
+		v = must.NotFail(spec.Get(key))
+
+		switch key {
+		case "from":
+			if _, ok = v.(*types.Document); ok {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrNotImplemented,
+					"$lookup 'from' with 'db' and 'coll' fields is not implemented yet",
+					"$lookup (stage)",
+				)
+			}
+
+			if l.from, ok = v.(string); !ok {
+				return nil, lookupStringError(key, v)
+			}
+
+		case "as":
+			if as, ok = v.(string); !ok {
+				return nil, lookupStringError(key, v)
+			}
+
+		case "localField":
+			if localField, ok = v.(string); !ok {
+				return nil, lookupStringError(key, v)
+			}
+
+		case "foreignField":
+			if foreignField, ok = v.(string); !ok {
+				return nil, lookupStringError(key, v)
+			}
+
+		case "let":
+			if l.let, ok = v.(*types.Document); !ok {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrFailedToParse,
+					fmt.Sprintf("$lookup argument 'let' must be an object, is type %s", handlerparams.AliasFromType(v)),
+					"$lookup (stage)",
+				)
+			}
+
+		case "pipeline":
+			var err error
+			if l.pipeline, err = lookupPipeline(v); err != nil {
+				return nil, err
+			}
+
+			hasPipeline = true
+
+		default:
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParse,
+				fmt.Sprintf("unknown argument to $lookup: %s", key),
+				"$lookup (stage)",
+			)
+		}
+	}
+
+	switch {
+	case as == "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFailedToParse,
+			"must specify 'as' field for a $lookup",
+			"$lookup (stage)",
+		)
+
+	case l.from == "":
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFailedToParse,
+			"must specify 'from' field for a $lookup",
+			"$lookup (stage)",
+		)
+
+	case (localField == "") != (foreignField == ""):
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFailedToParse,
+			"$lookup requires both or neither of 'localField' and 'foreignField' to be specified",
+			"$lookup (stage)",
+		)
+
+	case localField == "" && !hasPipeline:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFailedToParse,
+			"$lookup requires either 'pipeline' or both 'localField' and 'foreignField' to be specified",
+			"$lookup (stage)",
+		)
+	}
+
+	var err error
+
+	if l.as, err = lookupPath(as); err != nil {
+		return nil, err
+	}
+
+	if localField != "" {
+		var local, foreign types.Path
+
+		if local, err = lookupPath(localField); err != nil {
+			return nil, err
+		}
+
+		if foreign, err = lookupPath(foreignField); err != nil {
+			return nil, err
+		}
+
+		l.localField, l.foreignField = &local, &foreign
+	}
+
+	if l.let != nil {
+		for _, name := range l.let.Keys() {
+			if err = validateLookupVariable(name, must.NotFail(l.let.Get(name))); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &l, nil
+}
+
+// lookupStringError returns an error for the $lookup argument that is not a string.
+func lookupStringError(key string, v any) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(
+		handlererrors.ErrFailedToParse,
+		fmt.Sprintf(
+			"$lookup argument '%s: %s' must be a string, is type %s",
+			key, types.FormatAnyValue(v), handlerparams.AliasFromType(v),
+		),
+		"$lookup (stage)",
+	)
+}
+
+// lookupPath returns the path for the given $lookup field name.
+func lookupPath(s string) (types.Path, error) {
+	path, err := types.NewPathFromString(s)
+	if err != nil || strings.HasPrefix(s, "$") {
+		return types.Path{}, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFailedToParse,
+			fmt.Sprintf("$lookup field %q is not a valid field path", s),
+			"$lookup (stage)",
+		)
+	}
+
+	return path, nil
+}
+
+// lookupPipeline returns stages of $lookup sub-pipeline.
+func lookupPipeline(v any) ([]*types.Document, error) {
+	arr, ok := v.(*types.Array)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFailedToParse,
+			fmt.Sprintf("$lookup argument 'pipeline' must be an array, is type %s", handlerparams.AliasFromType(v)),
+			"$lookup (stage)",
+		)
+	}
+
+	res := make([]*types.Document, arr.Len())
+
+	for i, v := range must.NotFail(iterator.ConsumeValues(arr.Iterator())) {
+		d, ok := v.(*types.Document)
+		if !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrTypeMismatch,
+				"Each element of the 'pipeline' array must be an object",
+				"$lookup (stage)",
+			)
+		}
+
+		if _, ok = lookupNotAllowedStages[d.Command()]; ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrStageLookupNotAllowed,
+				fmt.Sprintf("%s is not allowed within a $lookup's sub-pipeline", d.Command()),
+				"$lookup (stage)",
+			)
+		}
+
+		res[i] = d
+	}
+
+	return res, nil
+}
+
+// validateLookupVariable validates the name and the expression of let variable.
+func validateLookupVariable(name string, expr any) error {
+	if name == "" {
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFailedToParse,
+			"empty variable names are not allowed",
+			"$lookup (stage)",
+		)
+	}
+
+	for i, r := range name {
+		switch {
+		case i == 0 && !unicode.IsLower(r) && r < unicode.MaxASCII:
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParse,
+				fmt.Sprintf("'%s' starts with an invalid character for a user variable name", name),
+				"$lookup (stage)",
+			)
+		case r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) && r < unicode.MaxASCII:
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParse,
+				fmt.Sprintf("'%s' contains an invalid character for a variable name: '%c'", name, r),
+				"$lookup (stage)",
+			)
+		}
+	}
+
+	if _, ok := expr.(string); ok {
+		return nil
+	}
+
+	_, err := operators.NewExpr(must.NotFail(types.NewDocument("$expr", expr)), "$lookup (stage)")
+
+	return err
+}
+
+// init validates sub-pipeline stages and stores the parameters for creating them.
+func (l *lookup) init(params *NewStageParams) error {
+	if params.Lookup == nil {
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrNotImplemented,
+			"$lookup is not supported in this context",
+			"$lookup (stage)",
+		)
+	}
+
+	l.params = params
+
+	// let variables are not known yet, validate stages with null values
+	vars := map[string]any{}
+
+	if l.let != nil {
+		for _, name := range l.let.Keys() {
+			vars[name] = types.Null
+		}
+	}
+
+	for _, d := range l.pipeline {
+		stage, _ := lookupStage(d, vars)
+
+		if _, err := NewStage(stage, params); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Process implements Stage interface.
+func (l *lookup) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	docs, err := iterator.ConsumeValues(iter)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res := make([]*types.Document, len(docs))
+
+	for i, doc := range docs {
+		var joined *types.Array
+
+		if joined, err = l.join(ctx, doc); err != nil {
+			return nil, err
+		}
+
+		res[i] = doc.DeepCopy()
+
+		if err = res[i].SetByPath(l.as, joined); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// join returns foreign documents for the given input document.
+func (l *lookup) join(ctx context.Context, doc *types.Document) (*types.Array, error) {
+	var filter, pushdown *types.Document
+
+	if l.localField != nil {
+		filter = l.equalityFilter(doc)
+		pushdown = filter
+	}
+
+	var pipeline []*types.Document
+
+	if l.pipeline != nil {
+		vars, err := l.variables(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		pipeline = make([]*types.Document, len(l.pipeline))
+
+		for i, d := range l.pipeline {
+			var matchPushdown *types.Document
+			pipeline[i], matchPushdown = lookupStage(d, vars)
+
+			if i == 0 && pushdown == nil {
+				pushdown = matchPushdown
+			}
+		}
+	}
+
+	foreign, err := l.params.Lookup(ctx, l.from, pushdown)
+	if err != nil {
+		return nil, err
+	}
+
+	closer := iterator.NewMultiCloser(foreign)
+	defer closer.Close()
+
+	iter := foreign
+
+	if filter != nil {
+		iter = common.FilterIterator(iter, closer, filter, nil)
+	}
+
+	for _, d := range pipeline {
+		var s aggregations.Stage
+
+		if s, err = NewStage(d, l.params); err != nil {
+			return nil, err
+		}
+
+		if iter, err = s.Process(ctx, iter, closer); err != nil {
+			return nil, err
+		}
+	}
+
+	docs, err := iterator.ConsumeValues(iter)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res := types.MakeArray(len(docs))
+	for _, d := range docs {
+		res.Append(d)
+	}
+
+	return res, nil
+}
+
+// equalityFilter returns the filter that matches foreign documents with foreignField equal
+// to localField of the given document.
+//
+// Local arrays are matched by their elements; missing local field matches null and missing foreign fields.
+func (l *lookup) equalityFilter(doc *types.Document) *types.Document {
+	vals, _ := commonpath.FindValues(doc, *l.localField, &commonpath.FindValuesOpts{
+		FindArrayDocuments: true,
+	})
+
+	in := types.MakeArray(len(vals))
+
+	for _, v := range vals {
+		arr, ok := v.(*types.Array)
+		if !ok {
+			in.Append(v)
+			continue
+		}
+
+		for _, elem := range must.NotFail(iterator.ConsumeValues(arr.Iterator())) {
+			in.Append(elem)
+		}
+	}
+
+	if len(vals) == 0 {
+		in.Append(types.Null)
+	}
+
+	if in.Len() == 1 {
+		switch v := must.NotFail(in.Get(0)).(type) {
+		case *types.Document, types.Regex:
+			// not an equality condition in a filter
+		default:
+			return must.NotFail(types.NewDocument(l.foreignField.String(), v))
+		}
+	}
+
+	return must.NotFail(types.NewDocument(
+		l.foreignField.String(), must.NotFail(types.NewDocument("$in", in)),
+	))
+}
+
+// variables returns values of let variables for the given document.
+func (l *lookup) variables(doc *types.Document) (map[string]any, error) {
+	vars := map[string]any{}
+
+	if l.let == nil {
+		return vars, nil
+	}
+
+	for _, name := range l.let.Keys() {
+		v, err := evaluateVariable(must.NotFail(l.let.Get(name)), doc, "$lookup")
+		if err != nil {
+			return nil, err
+		}
+
+		vars[name] = v
+	}
+
+	return vars, nil
+}
+
+// evaluateVariable returns the value of let variable expression of the given stage
+// (like "$lookup") for the given document.
+//
+// Missing fields are evaluated to null.
+func evaluateVariable(expr any, doc *types.Document, stage string) (any, error) {
+	if s, ok := expr.(string); ok {
+		switch {
+		case s == "$$ROOT", s == "$$CURRENT":
+			return doc, nil
+
+		case strings.HasPrefix(s, "$$"):
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrGroupUndefinedVariable,
+				fmt.Sprintf("Use of undefined variable: %s", strings.TrimPrefix(s, "$$")),
+				stage+" (stage)",
+			)
+
+		case strings.HasPrefix(s, "$"):
+			e, err := aggregations.NewExpression(s, nil)
+			if err != nil {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrFailedToParse,
+					fmt.Sprintf("invalid %s variable expression %q", stage, s),
+					stage+" (stage)",
+				)
+			}
+
+			v, err := e.Evaluate(doc)
+			if err != nil {
+				return types.Null, nil
+			}
+
+			return v, nil
+
+		default:
+			return s, nil
+		}
+	}
+
+	op, err := operators.NewExpr(must.NotFail(types.NewDocument("$expr", expr)), stage+" (stage)")
+	if err != nil {
+		return nil, err
+	}
+
+	return op.Process(doc)
+}
+
+// lookupStage returns a copy of the sub-pipeline stage with let variables substituted.
+//
+// For $match stage, it also returns the filter that could be pushed down.
+// $expr with $eq of top-level fields and constants is replaced by equivalent conditions
+// that could be pushed down (partially) instead of $expr itself.
+func lookupStage(stage *types.Document, vars map[string]any) (*types.Document, *types.Document) {
+	res := substituteVariables(stage, vars).(*types.Document)
+
+	if res.Command() != "$match" {
+		return res, nil
+	}
+
+	filter, ok := must.NotFail(res.Get("$match")).(*types.Document)
+	if !ok || !filter.Has("$expr") {
+		return res, filter
+	}
+
+	conds, ok := exprEqualities(must.NotFail(filter.Get("$expr")))
+	if !ok {
+		return res, nil
+	}
+
+	newFilter := filter.DeepCopy()
+	newFilter.Remove("$expr")
+
+	pushdown := newFilter.DeepCopy()
+
+	for _, k := range conds.Keys() {
+		if newFilter.Has(k) {
+			return res, nil
+		}
+
+		v := must.NotFail(conds.Get(k))
+
+		// unlike filter equality, $eq does not match array elements, and null does not match missing fields
+		cond := must.NotFail(types.NewDocument(
+			"$eq", v,
+			"$not", must.NotFail(types.NewDocument("$type", "array")),
+		))
+
+		if v == types.Null {
+			cond = must.NotFail(types.NewDocument(
+				"$type", "null",
+				"$not", must.NotFail(types.NewDocument("$type", "array")),
+			))
+		} else {
+			pushdown.Set(k, v)
+		}
+
+		newFilter.Set(k, cond)
+	}
+
+	return must.NotFail(types.NewDocument("$match", newFilter)), pushdown
+}
+
+// substituteVariables returns a copy of the value with `$$name` and `$$name.path` strings replaced
+// by values of the given variables.
+// Unknown variables (including system ones like `$$ROOT`) are left as is.
+func substituteVariables(v any, vars map[string]any) any {
+	switch v := v.(type) {
+	case *types.Document:
+		res := types.MakeDocument(v.Len())
+
+		for _, k := range v.Keys() {
+			res.Set(k, substituteVariables(must.NotFail(v.Get(k)), vars))
+		}
+
+		return res
+
+	case *types.Array:
+		res := types.MakeArray(v.Len())
+
+		for _, elem := range must.NotFail(iterator.ConsumeValues(v.Iterator())) {
+			res.Append(substituteVariables(elem, vars))
+		}
+
+		return res
+
+	case string:
+		if !strings.HasPrefix(v, "$$") {
+			return v
+		}
+
+		name, rest, _ := strings.Cut(strings.TrimPrefix(v, "$$"), ".")
+
+		val, ok := vars[name]
+		if !ok {
+			return v
+		}
+
+		if rest == "" {
+			return val
+		}
+
+		d, ok := val.(*types.Document)
+		if !ok {
+			return types.Null
+		}
+
+		path, err := types.NewPathFromString(rest)
+		if err != nil {
+			return types.Null
+		}
+
+		if val, err = d.GetByPath(path); err != nil {
+			return types.Null
+		}
+
+		return val
+
+	default:
+		return v
+	}
+}
+
+// exprEqualities converts $expr value that consists of $eq operators with a top-level field path
+// and a constant, possibly combined with $and, to a document with field names and constants.
+//
+// Only scalar constants are supported, as conditions on arrays, documents, and regular expressions
+// in a filter have different semantics.
+func exprEqualities(expr any) (*types.Document, bool) {
+	d, ok := expr.(*types.Document)
+	if !ok || d.Len() != 1 {
+		return nil, false
+	}
+
+	args, ok := must.NotFail(d.Get(d.Command())).(*types.Array)
+	if !ok {
+		return nil, false
+	}
+
+	res := types.MakeDocument(args.Len())
+
+	switch d.Command() {
+	case "$and":
+		for _, arg := range must.NotFail(iterator.ConsumeValues(args.Iterator())) {
+			conds, ok := exprEqualities(arg)
+			if !ok {
+				return nil, false
+			}
+
+			for _, k := range conds.Keys() {
+				if res.Has(k) {
+					return nil, false
+				}
+
+				res.Set(k, must.NotFail(conds.Get(k)))
+			}
+		}
+
+		return res, true
+
+	case "$eq":
+		if args.Len() != 2 {
+			return nil, false
+		}
+
+		a, b := must.NotFail(args.Get(0)), must.NotFail(args.Get(1))
+
+		field, ok := a.(string)
+		if !ok || !strings.HasPrefix(field, "$") || strings.HasPrefix(field, "$$") {
+			field, ok = b.(string)
+			a, b = b, a
+		}
+
+		if !ok || !strings.HasPrefix(field, "$") || strings.HasPrefix(field, "$$") || len(field) == 1 {
+			return nil, false
+		}
+
+		if strings.ContainsRune(field, '.') {
+			return nil, false
+		}
+
+		switch b := b.(type) {
+		case *types.Document, *types.Array, types.Regex:
+			return nil, false
+		case string:
+			if strings.HasPrefix(b, "$") {
+				return nil, false
+			}
+		}
+
+		res.Set(strings.TrimPrefix(field, "$"), b)
+
+		return res, true
+
+	default:
+		return nil, false
+	}
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*lookup)(nil)
+)
@@ -27,7 +27,8 @@ import (
 
 // match represents $match stage.
 type match struct {
-	filter *types.Document
+	filter    *types.Document
+	collation *types.Collation
 }
 
 // newMatch creates a new $match stage.
@@ -52,7 +53,7 @@ func newMatch(stage *types.Document) (aggregations.Stage, error) {
 
 // Process implements Stage interface.
 func (m *match) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
-	return common.FilterIterator(iter, closer, m.filter), nil
+	return common.FilterIterator(iter, closer, m.filter, m.collation), nil
 }
 
 // validateMatch validates $expr field if any.
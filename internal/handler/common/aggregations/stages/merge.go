@@ -0,0 +1,602 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// MergeFunc returns the target collection of $merge stage.
+// Empty database name means the current database.
+//
+// Unless on fields are just _id, it returns an error if the collection has no unique index
+// on exactly those fields.
+type MergeFunc func(ctx context.Context, db, collection string, on []string) (MergeCollection, error)
+
+// MergeCollection represents the target collection of $merge stage.
+//
+// Writes are performed by the existing write path, so errors like unique index violations
+// are returned the same way as for insert and update commands.
+type MergeCollection interface {
+	// Query returns documents matched by the given filter.
+	// Like for LookupFunc, filter may be applied partially or not at all.
+	Query(ctx context.Context, filter *types.Document) (types.DocumentsIterator, error)
+
+	// Insert inserts the given document.
+	Insert(ctx context.Context, doc *types.Document) error
+
+	// Replace replaces the document with the same _id by the given document.
+	Replace(ctx context.Context, doc *types.Document) error
+}
+
+// Values of $merge whenMatched and whenNotMatched options.
+const (
+	mergeReplace      = "replace"
+	mergeKeepExisting = "keepExisting"
+	mergeMerge        = "merge"
+	mergeFail         = "fail"
+	mergePipeline     = "pipeline" // whenMatched is an array
+	mergeInsert       = "insert"
+	mergeDiscard      = "discard"
+)
+
+// mergePipelineStages contains stages that could be used within $merge whenMatched pipeline,
+// the same as for updates with aggregation pipeline.
+var mergePipelineStages = map[string]struct{}{
+	"$addFields":   {},
+	"$project":     {},
+	"$replaceRoot": {},
+	"$replaceWith": {},
+	"$set":         {},
+	"$unset":       {},
+}
+
+// merge represents $merge stage.
+//
+//	{ $merge: {
+//	  into: <collection> | { db: <db>, coll: <collection> },
+//	  on: <field> | [ <field1>, <field2>, ... ],
+//	  let: <variables>,
+//	  whenMatched: <replace|keepExisting|merge|fail|pipeline>,
+//	  whenNotMatched: <insert|discard|fail>
+//	} }
+//
+// It is the terminal stage: each input document is written to the target collection
+// depending on whether a document with the same values of on fields exists there,
+// and no documents are returned.
+// For the pipeline form of whenMatched, the existing document is processed
+// by the given stages with the input document available as $$new variable (by default).
+type merge struct {
+	db             string // empty for the current database
+	coll           string
+	on             []types.Path
+	let            *types.Document
+	whenMatched    string
+	whenNotMatched string
+	pipeline       []*types.Document // for whenMatched pipeline only
+
+	params *NewStageParams // for the target collection and pipeline stages, set by init
+}
+
+// newMerge creates a new $merge stage.
+func newMerge(stage *types.Document) (aggregations.Stage, error) {
+	m := merge{
+		on:             []types.Path{types.NewStaticPath("_id")},
+		whenMatched:    mergeMerge,
+		whenNotMatched: mergeInsert,
+	}
+
+	spec := types.MakeDocument(0)
+
+	switch v := must.NotFail(stage.Get("$merge")).(type) {
+	case *types.Document:
+		spec = v
+
+	case string:
+		m.coll = v
+
+	default:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageMergeInvalidArg,
+			fmt.Sprintf("$merge only supports a string or object argument, but found %s", handlerparams.AliasFromType(v)),
+			"$merge (stage)",
+		)
+	}
+
+	for _, key := range spec.Keys() {
+		v := must.NotFail(spec.Get(key))
+
+		var err error
+
+		switch key {
+		case "into":
+			if m.db, m.coll, err = mergeInto(v); err != nil {
+				return nil, err
+			}
+
+		case "on":
+			if m.on, err = mergeOn(v); err != nil {
+				return nil, err
+			}
+
+		case "let":
+			var ok bool
+			if m.let, ok = v.(*types.Document); !ok {
+				return nil, mergeTypeError(key, v, "type 'object'")
+			}
+
+		case "whenMatched":
+			switch v := v.(type) {
+			case *types.Array:
+				if m.pipeline, err = mergePipelineDocuments(v); err != nil {
+					return nil, err
+				}
+
+				m.whenMatched = mergePipeline
+
+			case string:
+				switch v {
+				case mergeReplace, mergeKeepExisting, mergeMerge, mergeFail:
+					m.whenMatched = v
+				default:
+					return nil, mergeEnumError(key, v)
+				}
+
+			default:
+				return nil, mergeTypeError(key, v, "types '[string, array]'")
+			}
+
+		case "whenNotMatched":
+			s, ok := v.(string)
+			if !ok {
+				return nil, mergeTypeError(key, v, "type 'string'")
+			}
+
+			switch s {
+			case mergeInsert, mergeDiscard, mergeFail:
+				m.whenNotMatched = s
+			default:
+				return nil, mergeEnumError(key, s)
+			}
+
+		default:
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$merge.%s' is an unknown field.", key),
+				"$merge (stage)",
+			)
+		}
+	}
+
+	if m.coll == "" {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrMissingField,
+			"BSON field '$merge.into' is missing but a required field",
+			"$merge (stage)",
+		)
+	}
+
+	return &m, nil
+}
+
+// mergeInto returns the database (empty for the current one) and collection names of $merge 'into' field.
+func mergeInto(v any) (string, string, error) {
+	switch v := v.(type) {
+	case *types.Document:
+		var db, coll string
+
+		for _, key := range v.Keys() {
+			var ok bool
+
+			switch key {
+			case "db":
+				if db, ok = must.NotFail(v.Get(key)).(string); !ok {
+					return "", "", mergeTypeError("into."+key, must.NotFail(v.Get(key)), "type 'string'")
+				}
+
+			case "coll":
+				if coll, ok = must.NotFail(v.Get(key)).(string); !ok {
+					return "", "", mergeTypeError("into."+key, must.NotFail(v.Get(key)), "type 'string'")
+				}
+
+			default:
+				return "", "", handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrFailedToParseInput,
+					fmt.Sprintf("BSON field 'into.%s' is an unknown field.", key),
+					"$merge (stage)",
+				)
+			}
+		}
+
+		if coll == "" {
+			return "", "", handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrMissingField,
+				"BSON field 'into.coll' is missing but a required field",
+				"$merge (stage)",
+			)
+		}
+
+		return db, coll, nil
+
+	case string:
+		return "", v, nil
+
+	default:
+		return "", "", handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageMergeInvalidInto,
+			fmt.Sprintf("$merge 'into' field  must be either a string or an object, but found %s", handlerparams.AliasFromType(v)),
+			"$merge (stage)",
+		)
+	}
+}
+
+// mergeOn returns paths of $merge 'on' field.
+func mergeOn(v any) ([]types.Path, error) {
+	var fields []string
+
+	switch v := v.(type) {
+	case *types.Array:
+		if v.Len() == 0 {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrStageMergeEmptyOn,
+				"If explicitly specifying $merge 'on', must include at least one field",
+				"$merge (stage)",
+			)
+		}
+
+		for _, elem := range must.NotFail(iterator.ConsumeValues(v.Iterator())) {
+			s, ok := elem.(string)
+			if !ok {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrStageMergeOnNotString,
+					fmt.Sprintf("Array elements of $merge 'on' must be strings, but found %s", handlerparams.AliasFromType(elem)),
+					"$merge (stage)",
+				)
+			}
+
+			fields = append(fields, s)
+		}
+
+	case string:
+		fields = []string{v}
+
+	default:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageMergeInvalidOn,
+			fmt.Sprintf(
+				"$merge 'on' field  must be either a string or an array of strings, but found %s",
+				handlerparams.AliasFromType(v),
+			),
+			"$merge (stage)",
+		)
+	}
+
+	res := make([]types.Path, len(fields))
+
+	for i, f := range fields {
+		path, err := types.NewPathFromString(f)
+		if err != nil {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrBadValue,
+				fmt.Sprintf("$merge 'on' field %q is not a valid field path", f),
+				"$merge (stage)",
+			)
+		}
+
+		res[i] = path
+	}
+
+	return res, nil
+}
+
+// mergePipelineDocuments returns stages of $merge whenMatched pipeline.
+func mergePipelineDocuments(arr *types.Array) ([]*types.Document, error) {
+	res := make([]*types.Document, arr.Len())
+
+	for i, v := range must.NotFail(iterator.ConsumeValues(arr.Iterator())) {
+		d, ok := v.(*types.Document)
+		if !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrTypeMismatch,
+				"Each element of the 'pipeline' array must be an object",
+				"$merge (stage)",
+			)
+		}
+
+		if _, ok = mergePipelineStages[d.Command()]; !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrInvalidOptions,
+				fmt.Sprintf("%s is not allowed to be used within an update", d.Command()),
+				"$merge (stage)",
+			)
+		}
+
+		res[i] = d
+	}
+
+	return res, nil
+}
+
+// mergeTypeError returns an error for $merge field of the wrong type;
+// expected is like "type 'string'" or "types '[string, array]'".
+func mergeTypeError(key string, v any, expected string) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(
+		handlererrors.ErrTypeMismatch,
+		fmt.Sprintf("BSON field '$merge.%s' is the wrong type '%s', expected %s", key, handlerparams.AliasFromType(v), expected),
+		"$merge (stage)",
+	)
+}
+
+// mergeEnumError returns an error for the invalid value of $merge whenMatched or whenNotMatched field.
+func mergeEnumError(key, v string) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(
+		handlererrors.ErrBadValue,
+		fmt.Sprintf("Enumeration value '%s' for field '%s' is not a valid value.", v, key),
+		"$merge (stage)",
+	)
+}
+
+// init validates whenMatched pipeline stages and stores the parameters for creating them.
+func (m *merge) init(params *NewStageParams) error {
+	if params.Merge == nil {
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrNotImplemented,
+			"$merge is not supported in this context",
+			"$merge (stage)",
+		)
+	}
+
+	m.params = params
+
+	// variables are not known yet, validate stages with null values
+	vars := m.variables()
+	for name := range vars {
+		vars[name] = types.Null
+	}
+
+	for _, d := range m.pipeline {
+		if _, err := NewStage(substituteVariables(d, vars).(*types.Document), params); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// variables returns let variables expressions; $$new refers to the input document by default.
+func (m *merge) variables() map[string]any {
+	if m.let == nil {
+		return map[string]any{"new": "$$ROOT"}
+	}
+
+	res := make(map[string]any, m.let.Len())
+
+	for _, name := range m.let.Keys() {
+		res[name] = must.NotFail(m.let.Get(name))
+	}
+
+	return res
+}
+
+// Process implements Stage interface.
+func (m *merge) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	docs, err := iterator.ConsumeValues(iter)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	on := make([]string, len(m.on))
+	for i, path := range m.on {
+		on[i] = path.String()
+	}
+
+	target, err := m.params.Merge(ctx, m.db, m.coll, on)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, doc := range docs {
+		if err = m.mergeDocument(ctx, target, doc.DeepCopy()); err != nil {
+			return nil, err
+		}
+	}
+
+	iter = iterator.Values(iterator.ForSlice([]*types.Document{}))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// mergeDocument writes the given input document to the target collection.
+func (m *merge) mergeDocument(ctx context.Context, target MergeCollection, doc *types.Document) error {
+	if !doc.Has("_id") && slices.ContainsFunc(m.on, func(p types.Path) bool { return p.String() == "_id" }) {
+		doc.Set("_id", types.NewObjectID())
+	}
+
+	filter := types.MakeDocument(len(m.on))
+
+	for _, path := range m.on {
+		v, err := doc.GetByPath(path)
+
+		switch v.(type) {
+		case *types.Array, types.NullType:
+			err = fmt.Errorf("invalid value %v", v)
+		}
+
+		if err != nil {
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrStageMergeInvalidOnValue,
+				"$merge write error: 'on' field cannot be missing, null, undefined or an array",
+				"$merge (stage)",
+			)
+		}
+
+		filter.Set(path.String(), v)
+	}
+
+	existing, err := m.find(ctx, target, filter)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		switch m.whenNotMatched {
+		case mergeInsert:
+			if !doc.Has("_id") {
+				doc.Set("_id", types.NewObjectID())
+			}
+
+			return target.Insert(ctx, doc)
+
+		case mergeDiscard:
+			return nil
+
+		case mergeFail:
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrStageMergeNoMatch,
+				"$merge could not find a matching document in the target collection "+
+					"for at least one document in the source collection",
+				"$merge (stage)",
+			)
+
+		default:
+			panic(fmt.Sprintf("unexpected whenNotMatched %q", m.whenNotMatched))
+		}
+	}
+
+	var res *types.Document
+
+	switch m.whenMatched {
+	case mergeReplace:
+		res = doc
+
+	case mergeKeepExisting:
+		return nil
+
+	case mergeMerge:
+		res = existing.DeepCopy()
+
+		for _, k := range doc.Keys() {
+			res.Set(k, must.NotFail(doc.Get(k)))
+		}
+
+	case mergeFail:
+		// the write path reports the duplicate key error
+		return target.Insert(ctx, doc)
+
+	case mergePipeline:
+		if res, err = m.processPipeline(ctx, existing, doc); err != nil {
+			return err
+		}
+
+	default:
+		panic(fmt.Sprintf("unexpected whenMatched %q", m.whenMatched))
+	}
+
+	id := must.NotFail(existing.Get("_id"))
+
+	if v, _ := res.Get("_id"); v != nil && types.Compare(v, id) != types.Equal {
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrImmutableField,
+			"$merge failed to update the matching document, did you attempt to modify the _id or the shard key?",
+			"$merge (stage)",
+		)
+	}
+
+	res.Set("_id", id)
+
+	return target.Replace(ctx, res)
+}
+
+// find returns the target document matched by the given filter of on fields, or nil.
+func (m *merge) find(ctx context.Context, target MergeCollection, filter *types.Document) (*types.Document, error) {
+	iter, err := target.Query(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	closer := iterator.NewMultiCloser(iter)
+	defer closer.Close()
+
+	// unique index on fields guarantees at most one match
+	docs, err := iterator.ConsumeValues(common.FilterIterator(iter, closer, filter, nil))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	return docs[0], nil
+}
+
+// processPipeline returns the result of whenMatched pipeline for the given existing and input documents.
+func (m *merge) processPipeline(ctx context.Context, existing, doc *types.Document) (*types.Document, error) {
+	vars := m.variables()
+
+	for name, expr := range vars {
+		v, err := evaluateVariable(expr, doc, "$merge")
+		if err != nil {
+			return nil, err
+		}
+
+		vars[name] = v
+	}
+
+	closer := iterator.NewMultiCloser()
+	defer closer.Close()
+
+	iter := iterator.Values(iterator.ForSlice([]*types.Document{existing.DeepCopy()}))
+	closer.Add(iter)
+
+	for _, d := range m.pipeline {
+		s, err := NewStage(substituteVariables(d, vars).(*types.Document), m.params)
+		if err != nil {
+			return nil, err
+		}
+
+		if iter, err = s.Process(ctx, iter, closer); err != nil {
+			return nil, err
+		}
+	}
+
+	docs, err := iterator.ConsumeValues(iter)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if len(docs) != 1 {
+		return nil, lazyerrors.Errorf("expected 1 document, got %d", len(docs))
+	}
+
+	return docs[0], nil
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*merge)(nil)
+)
@@ -0,0 +1,150 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// OutFunc replaces all documents of the target collection of $out stage with documents from the given iterator.
+// Empty database name means the current database.
+// Documents without _id get a new ObjectID.
+//
+// Replacement is atomic: the target collection is never observed empty or partially written.
+// Existing indexes of the target collection are kept.
+type OutFunc func(ctx context.Context, db, collection string, iter types.DocumentsIterator) error
+
+// out represents $out stage.
+//
+//	{ $out: <collection> }
+//	{ $out: { db: <db>, coll: <collection> } }
+//
+// It is the terminal stage: input documents replace the content of the target collection,
+// and no documents are returned.
+type out struct {
+	db   string // empty for the current database
+	coll string
+
+	out OutFunc // set by init
+}
+
+// newOut creates a new $out stage.
+func newOut(stage *types.Document) (aggregations.Stage, error) {
+	var o out
+
+	switch v := must.NotFail(stage.Get("$out")).(type) {
+	case *types.Document:
+		for _, key := range v.Keys() {
+			var ok bool
+
+			switch key {
+			case "db":
+				if o.db, ok = must.NotFail(v.Get(key)).(string); !ok {
+					return nil, outTypeError(key, must.NotFail(v.Get(key)))
+				}
+
+			case "coll":
+				if o.coll, ok = must.NotFail(v.Get(key)).(string); !ok {
+					return nil, outTypeError(key, must.NotFail(v.Get(key)))
+				}
+
+			case "timeseries":
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrNotImplemented,
+					"$out option 'timeseries' is not implemented yet",
+					"$out (stage)",
+				)
+
+			default:
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrFailedToParseInput,
+					fmt.Sprintf("BSON field '$out.%s' is an unknown field.", key),
+					"$out (stage)",
+				)
+			}
+		}
+
+		for _, key := range []string{"db", "coll"} {
+			if !v.Has(key) {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrMissingField,
+					fmt.Sprintf("BSON field '$out.%s' is missing but a required field", key),
+					"$out (stage)",
+				)
+			}
+		}
+
+	case string:
+		o.coll = v
+
+	default:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageOutInvalidArg,
+			fmt.Sprintf("$out only supports a string or object argument, but found %s", handlerparams.AliasFromType(v)),
+			"$out (stage)",
+		)
+	}
+
+	return &o, nil
+}
+
+// outTypeError returns an error for $out field of the wrong type.
+func outTypeError(key string, v any) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(
+		handlererrors.ErrTypeMismatch,
+		fmt.Sprintf("BSON field '$out.%s' is the wrong type '%s', expected type 'string'", key, handlerparams.AliasFromType(v)),
+		"$out (stage)",
+	)
+}
+
+// init stores the function for writing to the target collection.
+func (o *out) init(params *NewStageParams) error {
+	if params.Out == nil {
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrNotImplemented,
+			"$out is not supported in this context",
+			"$out (stage)",
+		)
+	}
+
+	o.out = params.Out
+
+	return nil
+}
+
+// Process implements Stage interface.
+func (o *out) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	if err := o.out(ctx, o.db, o.coll, iter); err != nil {
+		return nil, err
+	}
+
+	iter = iterator.Values(iterator.ForSlice([]*types.Document{}))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*out)(nil)
+)
@@ -164,6 +164,13 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 			}
 
 			_, err = op.Process(must.NotFail(types.NewDocument("key", "value")))
+
+			// $switch without matching branch fails only for actual documents
+			var ce *handlererrors.CommandError
+			if errors.As(err, &ce) && ce.Code() == handlererrors.ErrSwitchNoMatchingBranch {
+				err = nil
+			}
+
 			if err = processOperatorError(err); err != nil {
 				return nil, false, err
 			}
@@ -178,7 +185,7 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 			result = true
 
 			validated.Set(key, value)
-		case float64, int32, int64:
+		case float64, int32, int64, types.Decimal128:
 			// projection treats 0 as false and any other value as true
 			comparison := types.Compare(value, int32(0))
 
@@ -237,12 +244,17 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 }
 
 // ProjectDocument applies projection to the copy of the document.
+//
+// Documents without _id field (like ones generated by $densify stage) are projected without it.
 func ProjectDocument(doc, projection *types.Document, inclusion bool) (*types.Document, error) {
-	projected, err := types.NewDocument("_id", must.NotFail(doc.Get("_id")))
-	if err != nil {
-		return nil, err
+	projected := types.MakeDocument(1)
+
+	if id, _ := doc.Get("_id"); id != nil {
+		projected.Set("_id", id)
 	}
 
+	var err error
+
 	if projection.Has("_id") {
 		idValue := must.NotFail(projection.Get("_id"))
 
@@ -0,0 +1,61 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+)
+
+// queryStats represents $queryStats stage.
+//
+// Input documents (one per query shape) are produced by the handler.
+type queryStats struct{}
+
+// newQueryStats creates a new $queryStats stage.
+func newQueryStats(stage *types.Document) (aggregations.Stage, error) {
+	fields, err := common.GetRequiredParam[*types.Document](stage, "$queryStats")
+	if err != nil {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFailedToParse,
+			fmt.Sprintf("$queryStats must take a nested object but found: %s", types.FormatAnyValue(stage)),
+			"$queryStats (stage)",
+		)
+	}
+
+	if err = common.Unimplemented(fields, "transformIdentifiers"); err != nil {
+		return nil, err
+	}
+
+	return new(queryStats), nil
+}
+
+// Process implements Stage interface.
+//
+// It returns input documents as is.
+func (qs *queryStats) Process(_ context.Context, iter types.DocumentsIterator, _ *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	return iter, nil
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*queryStats)(nil)
+)
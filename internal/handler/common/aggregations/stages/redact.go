@@ -0,0 +1,183 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Values of $redact system variables.
+// Like MongoDB, they are plain strings, so the expression could also return them directly.
+const (
+	redactDescend = "descend"
+	redactPrune   = "prune"
+	redactKeep    = "keep"
+)
+
+// redactVariables contains $redact system variables.
+var redactVariables = map[string]any{
+	"DESCEND": redactDescend,
+	"PRUNE":   redactPrune,
+	"KEEP":    redactKeep,
+}
+
+// redact represents $redact stage.
+//
+//	{ $redact: <expression> }
+//
+// The expression is evaluated for the document and should return one of
+// $$DESCEND, $$PRUNE or $$KEEP system variables.
+// $$KEEP returns the (sub)document as is, $$PRUNE removes it,
+// and $$DESCEND evaluates the expression again for each embedded document,
+// including documents in arrays, and keeps all other fields.
+// Field paths in the expression refer to the (sub)document being evaluated.
+type redact struct {
+	expr operators.Operator
+}
+
+// newRedact creates a new $redact stage.
+func newRedact(stage *types.Document) (aggregations.Stage, error) {
+	v := substituteVariables(must.NotFail(stage.Get("$redact")), redactVariables)
+
+	expr, err := operators.NewExpr(must.NotFail(types.NewDocument("$expr", v)), "$redact (stage)")
+	if err != nil {
+		return nil, err
+	}
+
+	return &redact{
+		expr: expr,
+	}, nil
+}
+
+// Process implements Stage interface.
+func (r *redact) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	docs, err := iterator.ConsumeValues(iter)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var res []*types.Document
+
+	for _, doc := range docs {
+		var redacted *types.Document
+
+		if redacted, err = r.redactDocument(doc); err != nil {
+			return nil, err
+		}
+
+		if redacted != nil {
+			res = append(res, redacted)
+		}
+	}
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// redactDocument evaluates the expression for the given (sub)document and
+// returns redacted document, or nil if it is pruned.
+func (r *redact) redactDocument(doc *types.Document) (*types.Document, error) {
+	v, err := r.expr.Process(doc)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	switch v {
+	case redactKeep:
+		return doc, nil
+
+	case redactPrune:
+		return nil, nil
+
+	case redactDescend:
+		res := types.MakeDocument(doc.Len())
+
+		for _, k := range doc.Keys() {
+			var fv any
+
+			if fv, err = r.redactValue(must.NotFail(doc.Get(k))); err != nil {
+				return nil, err
+			}
+
+			if fv != nil {
+				res.Set(k, fv)
+			}
+		}
+
+		return res, nil
+
+	default:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageRedactInvalidResult,
+			fmt.Sprintf(
+				"$redact's expression should not return anything aside from the variables "+
+					"$KEEP, $DESCEND, and $PRUNE, but returned %s",
+				types.FormatAnyValue(v),
+			),
+			"$redact (stage)",
+		)
+	}
+}
+
+// redactValue returns redacted field value, or nil if it is a pruned document.
+//
+// Documents are redacted recursively, pruned documents are removed from arrays,
+// and other values are returned as is.
+func (r *redact) redactValue(v any) (any, error) {
+	switch v := v.(type) {
+	case *types.Document:
+		doc, err := r.redactDocument(v)
+		if err != nil || doc == nil {
+			return nil, err
+		}
+
+		return doc, nil
+
+	case *types.Array:
+		res := types.MakeArray(v.Len())
+
+		for _, elem := range must.NotFail(iterator.ConsumeValues(v.Iterator())) {
+			redacted, err := r.redactValue(elem)
+			if err != nil {
+				return nil, err
+			}
+
+			if redacted != nil {
+				res.Append(redacted)
+			}
+		}
+
+		return res, nil
+
+	default:
+		return v, nil
+	}
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*redact)(nil)
+)
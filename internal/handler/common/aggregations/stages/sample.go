@@ -0,0 +1,142 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// sample represents $sample stage.
+type sample struct {
+	size int64
+}
+
+// newSample creates a new $sample stage.
+func newSample(stage *types.Document) (aggregations.Stage, error) {
+	fields, ok := must.NotFail(stage.Get("$sample")).(*types.Document)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageSampleInvalidArg,
+			"the $sample stage specification must be an object",
+			"$sample (stage)",
+		)
+	}
+
+	for _, k := range fields.Keys() {
+		if k != "size" {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrStageSampleUnknownOption,
+				fmt.Sprintf("unrecognized option to $sample: %s", k),
+				"$sample (stage)",
+			)
+		}
+	}
+
+	v, _ := fields.Get("size")
+	if v == nil {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageSampleMissingSize,
+			"$sample stage must specify a size",
+			"$sample (stage)",
+		)
+	}
+
+	var size int64
+
+	switch v := v.(type) {
+	case float64:
+		size = int64(v)
+	case int32:
+		size = int64(v)
+	case int64:
+		size = v
+	default:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageSampleSizeNotNumber,
+			"size argument to $sample must be a number",
+			"$sample (stage)",
+		)
+	}
+
+	if size < 0 {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrStageSampleNegativeSize,
+			"size argument to $sample must not be negative",
+			"$sample (stage)",
+		)
+	}
+
+	return &sample{
+		size: size,
+	}, nil
+}
+
+// Process implements Stage interface.
+//
+// It reads all input documents and returns a random subset of them in random order.
+func (s *sample) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	defer iter.Close()
+
+	var res []*types.Document
+
+	// reservoir sampling, see https://en.wikipedia.org/wiki/Reservoir_sampling
+	var n int64
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		n++
+
+		if int64(len(res)) < s.size {
+			res = append(res, doc)
+			continue
+		}
+
+		if i := rand.Int63n(n); i < s.size {
+			res[i] = doc
+		}
+	}
+
+	rand.Shuffle(len(res), func(i, j int) {
+		res[i], res[j] = res[j], res[i]
+	})
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*sample)(nil)
+)
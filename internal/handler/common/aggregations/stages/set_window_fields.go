@@ -0,0 +1,915 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations/operators/accumulators"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// windowBound represents a single bound of the window.
+type windowBound struct {
+	unbounded bool
+	current   bool
+	offset    any // int64 for documents-based windows, float64 or int64 for range-based windows
+}
+
+// window represents window of $setWindowFields output field.
+type window struct {
+	byRange bool
+	lower   windowBound
+	upper   windowBound
+	unit    string // set only for range-based windows over dates
+}
+
+// windowOutput represents a single output field of $setWindowFields stage.
+type windowOutput struct {
+	field  types.Path
+	fn     string  // window function name, like "$rank"
+	window *window // nil for the whole partition
+
+	// set for accumulator window functions
+	accumulator accumulators.Accumulator
+
+	// set for $shift and $derivative
+	input operators.Operator
+
+	// set for $shift
+	by           int64
+	defaultValue any
+
+	// set for $derivative
+	unit string
+}
+
+// setWindowFields represents $setWindowFields stage.
+//
+//	{ $setWindowFields: {
+//		partitionBy: <expression>,
+//		sortBy: { <field>: <sort order>, ... },
+//		output: {
+//			<field>: {
+//				<window function>: <arguments>,
+//				window: {
+//					documents: [ <lower bound>, <upper bound> ],
+//					range: [ <lower bound>, <upper bound> ],
+//					unit: <time unit>
+//				}
+//			},
+//			...
+//		}
+//	}}
+//
+// $setWindowFields sets output fields to the results of window functions
+// computed over the partition of the document sorted by sortBy.
+// Accumulators (like $sum) are computed over the window;
+// $rank, $denseRank, $documentNumber, $shift and $derivative are supported too.
+//
+// Partitions are processed in memory; window functions are not pushed down to backends.
+type setWindowFields struct {
+	partitionBy operators.Operator // nil if not set
+	sortBy      *types.Document    // nil if not set
+	output      []windowOutput
+}
+
+// windowUnits contains units supported by range-based windows and $derivative, in milliseconds.
+var windowUnits = map[string]int64{
+	"week":        7 * 24 * 60 * 60 * 1000,
+	"day":         24 * 60 * 60 * 1000,
+	"hour":        60 * 60 * 1000,
+	"minute":      60 * 1000,
+	"second":      1000,
+	"millisecond": 1,
+}
+
+// unsupportedWindowFunctions contains MongoDB window functions that are not implemented yet.
+var unsupportedWindowFunctions = map[string]struct{}{
+	// sorted alphabetically
+	"$avg":            {},
+	"$bottom":         {},
+	"$bottomN":        {},
+	"$covariancePop":  {},
+	"$covarianceSamp": {},
+	"$expMovingAvg":   {},
+	"$first":          {},
+	"$firstN":         {},
+	"$integral":       {},
+	"$last":           {},
+	"$lastN":          {},
+	"$linearFill":     {},
+	"$locf":           {},
+	"$max":            {},
+	"$maxN":           {},
+	"$median":         {},
+	"$min":            {},
+	"$minN":           {},
+	"$percentile":     {},
+	"$push":           {},
+	"$top":            {},
+	"$topN":           {},
+	// please keep sorted alphabetically
+}
+
+// newSetWindowFields creates a new $setWindowFields stage.
+func newSetWindowFields(stage *types.Document) (aggregations.Stage, error) {
+	fields, ok := must.NotFail(stage.Get("$setWindowFields")).(*types.Document)
+	if !ok {
+		return nil, windowTypeError("$setWindowFields", must.NotFail(stage.Get("$setWindowFields")), "object")
+	}
+
+	var s setWindowFields
+	var err error
+
+	for _, key := range fields.Keys() {
+		v := must.NotFail(fields.Get(key))
+
+		switch key {
+		case "partitionBy":
+			s.partitionBy, err = operators.NewExpr(must.NotFail(types.NewDocument("$expr", v)), "$setWindowFields (stage)")
+			if err != nil {
+				return nil, err
+			}
+
+		case "sortBy":
+			if s.sortBy, ok = v.(*types.Document); !ok {
+				return nil, windowTypeError("$setWindowFields.sortBy", v, "object")
+			}
+
+			for _, k := range s.sortBy.Keys() {
+				if _, err = common.GetSortType(k, must.NotFail(s.sortBy.Get(k))); err != nil {
+					return nil, err
+				}
+			}
+
+		case "output":
+			output, ok := v.(*types.Document)
+			if !ok {
+				return nil, windowTypeError("$setWindowFields.output", v, "object")
+			}
+
+			for _, field := range output.Keys() {
+				o, err := s.newOutput(field, must.NotFail(output.Get(field)))
+				if err != nil {
+					return nil, err
+				}
+
+				s.output = append(s.output, *o)
+			}
+
+		default:
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$setWindowFields.%s' is an unknown field.", key),
+				"$setWindowFields (stage)",
+			)
+		}
+	}
+
+	if !fields.Has("output") {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrMissingField,
+			"BSON field '$setWindowFields.output' is missing but a required field",
+			"$setWindowFields (stage)",
+		)
+	}
+
+	return &s, nil
+}
+
+// newOutput validates and returns a single output field of $setWindowFields stage.
+//
+// It relies on sortBy being already parsed.
+func (s *setWindowFields) newOutput(field string, v any) (*windowOutput, error) {
+	path, err := types.NewPathFromString(field)
+	if err != nil {
+		return nil, windowFailedToParse(fmt.Sprintf("Invalid field path '%s'", field))
+	}
+
+	spec, ok := v.(*types.Document)
+	if !ok {
+		return nil, windowFailedToParse(fmt.Sprintf("Expected a document for output field '%s'", field))
+	}
+
+	o := windowOutput{
+		field: path,
+	}
+
+	var args any
+
+	for _, k := range spec.Keys() {
+		if k == "window" {
+			if o.window, err = s.newWindow(must.NotFail(spec.Get(k))); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if o.fn != "" {
+			return nil, windowFailedToParse(fmt.Sprintf("Window function found in the output field '%s' more than once", field))
+		}
+
+		o.fn = k
+		args = must.NotFail(spec.Get(k))
+	}
+
+	if o.fn == "" {
+		return nil, windowFailedToParse(fmt.Sprintf("Expected a window function in the output field '%s'", field))
+	}
+
+	switch o.fn {
+	case "$rank", "$denseRank", "$documentNumber":
+		if a, ok := args.(*types.Document); !ok || a.Len() != 0 {
+			return nil, windowFailedToParse(fmt.Sprintf("%s must be specified with '{}' as the value", o.fn))
+		}
+
+		if o.window != nil {
+			return nil, windowFailedToParse("Rank style window functions take no other arguments")
+		}
+
+		if s.sortBy == nil || s.sortBy.Len() != 1 {
+			return nil, windowFailedToParse(
+				fmt.Sprintf("%s must be specified with a top level sortBy expression with exactly one element", o.fn),
+			)
+		}
+
+	case "$shift":
+		if err = s.newShift(&o, args); err != nil {
+			return nil, err
+		}
+
+	case "$derivative":
+		if err = s.newDerivative(&o, args); err != nil {
+			return nil, err
+		}
+
+	default:
+		if _, ok := accumulators.Accumulators[o.fn]; !ok {
+			if _, ok = unsupportedWindowFunctions[o.fn]; ok {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrNotImplemented,
+					fmt.Sprintf("$setWindowFields window function %q is not implemented yet", o.fn),
+					o.fn+" (window function)",
+				)
+			}
+
+			return nil, windowFailedToParse(fmt.Sprintf("Unrecognized window function, %s", o.fn))
+		}
+
+		accumulation := must.NotFail(types.NewDocument(o.fn, args))
+		if o.accumulator, err = accumulators.NewAccumulator("$setWindowFields", field, accumulation); err != nil {
+			return nil, err
+		}
+
+		if o.window != nil && !o.window.byRange && s.sortBy == nil &&
+			!(o.window.lower.unbounded && o.window.upper.unbounded) {
+			return nil, windowFailedToParse("Document-based bounds require a sortBy")
+		}
+	}
+
+	return &o, nil
+}
+
+// newShift validates arguments of $shift window function.
+func (s *setWindowFields) newShift(o *windowOutput, args any) error {
+	spec, ok := args.(*types.Document)
+	if !ok {
+		return windowFailedToParse("Argument to $shift must be an object")
+	}
+
+	for _, k := range spec.Keys() {
+		v := must.NotFail(spec.Get(k))
+
+		switch k {
+		case "output":
+			var err error
+			if o.input, err = operators.NewExpr(must.NotFail(types.NewDocument("$expr", v)), "$shift"); err != nil {
+				return err
+			}
+
+		case "by":
+			by, ok := windowInteger(v)
+			if !ok {
+				return windowFailedToParse(fmt.Sprintf("'$shift:by' field must be an integer, but found by: %s", types.FormatAnyValue(v)))
+			}
+
+			o.by = by
+
+		case "default":
+			if _, ok := v.(*types.Document); ok {
+				return windowFailedToParse("'$shift:default' expression must yield a constant value.")
+			}
+
+			o.defaultValue = v
+
+		default:
+			return windowFailedToParse(fmt.Sprintf("Unknown argument in $shift: %s", k))
+		}
+	}
+
+	switch {
+	case o.input == nil:
+		return windowFailedToParse("$shift requires an 'output' expression.")
+	case !spec.Has("by"):
+		return windowFailedToParse("$shift requires 'by' as an integer value.")
+	case o.window != nil:
+		return windowFailedToParse("$shift does not accept a 'window' field")
+	case s.sortBy == nil:
+		return windowFailedToParse("$shift requires a sortBy")
+	}
+
+	if o.defaultValue == nil {
+		o.defaultValue = types.Null
+	}
+
+	return nil
+}
+
+// newDerivative validates arguments of $derivative window function.
+func (s *setWindowFields) newDerivative(o *windowOutput, args any) error {
+	spec, ok := args.(*types.Document)
+	if !ok {
+		return windowFailedToParse("$derivative only supports an object as its argument")
+	}
+
+	for _, k := range spec.Keys() {
+		v := must.NotFail(spec.Get(k))
+
+		switch k {
+		case "input":
+			var err error
+			if o.input, err = operators.NewExpr(must.NotFail(types.NewDocument("$expr", v)), "$derivative"); err != nil {
+				return err
+			}
+
+		case "unit":
+			unit, ok := v.(string)
+			if _, known := windowUnits[unit]; !ok || !known {
+				return windowFailedToParse(fmt.Sprintf("$derivative 'unit' must be one of %s, found %s", windowUnitsList, types.FormatAnyValue(v)))
+			}
+
+			o.unit = unit
+
+		default:
+			return windowFailedToParse(fmt.Sprintf("$derivative got unexpected argument: %s", k))
+		}
+	}
+
+	switch {
+	case o.input == nil:
+		return windowFailedToParse("$derivative requires an 'input' expression")
+	case s.sortBy == nil || s.sortBy.Len() != 1:
+		return windowFailedToParse("$derivative requires a sortBy with exactly one field")
+	case o.window == nil:
+		return windowFailedToParse("$derivative requires explicit window bounds")
+	}
+
+	return nil
+}
+
+// newWindow validates and returns window of $setWindowFields output field.
+//
+// It relies on sortBy being already parsed.
+func (s *setWindowFields) newWindow(v any) (*window, error) {
+	spec, ok := v.(*types.Document)
+	if !ok {
+		return nil, windowFailedToParse("'window' field must be an object")
+	}
+
+	var w window
+	var bounds *types.Array
+
+	for _, k := range spec.Keys() {
+		v := must.NotFail(spec.Get(k))
+
+		switch k {
+		case "documents", "range":
+			if bounds != nil {
+				return nil, windowFailedToParse("Window bounds can only specify one of 'documents' or 'range'")
+			}
+
+			if bounds, ok = v.(*types.Array); !ok || bounds.Len() != 2 {
+				return nil, windowFailedToParse(fmt.Sprintf("Window bounds must be a 2-element array: %s: %s", k, types.FormatAnyValue(v)))
+			}
+
+			w.byRange = k == "range"
+
+		case "unit":
+			unit, ok := v.(string)
+			if _, known := windowUnits[unit]; !ok || !known {
+				return nil, windowFailedToParse(fmt.Sprintf("'unit' must be one of %s, found %s", windowUnitsList, types.FormatAnyValue(v)))
+			}
+
+			w.unit = unit
+
+		default:
+			return nil, windowFailedToParse(fmt.Sprintf("'window' field that is not 'documents', 'range' or 'unit': %s", k))
+		}
+	}
+
+	if bounds == nil {
+		return nil, windowFailedToParse("'window' field must specify either 'documents' or 'range'")
+	}
+
+	if w.unit != "" && !w.byRange {
+		return nil, windowFailedToParse("Document-based bounds can't have a 'unit'")
+	}
+
+	var err error
+
+	if w.lower, err = w.newBound(must.NotFail(bounds.Get(0))); err != nil {
+		return nil, err
+	}
+
+	if w.upper, err = w.newBound(must.NotFail(bounds.Get(1))); err != nil {
+		return nil, err
+	}
+
+	if !w.lower.unbounded && !w.upper.unbounded && w.boundOffset(w.lower) > w.boundOffset(w.upper) {
+		return nil, windowFailedToParse("Lower bound must not exceed upper bound")
+	}
+
+	if w.byRange && (s.sortBy == nil || s.sortBy.Len() != 1) {
+		return nil, windowFailedToParse("Range-based window requires sortBy a single field")
+	}
+
+	return &w, nil
+}
+
+// newBound validates and returns a single window bound.
+func (w *window) newBound(v any) (windowBound, error) {
+	switch v {
+	case "unbounded":
+		return windowBound{unbounded: true}, nil
+	case "current":
+		return windowBound{current: true}, nil
+	}
+
+	if !w.byRange {
+		n, ok := windowInteger(v)
+		if !ok {
+			return windowBound{}, windowFailedToParse(
+				fmt.Sprintf("Numeric document-based bounds must be an integer: %s", types.FormatAnyValue(v)),
+			)
+		}
+
+		return windowBound{offset: n}, nil
+	}
+
+	if w.unit != "" {
+		n, ok := windowInteger(v)
+		if !ok {
+			return windowBound{}, windowFailedToParse(
+				fmt.Sprintf("With 'unit', range-based bounds must be an integer: %s", types.FormatAnyValue(v)),
+			)
+		}
+
+		return windowBound{offset: n}, nil
+	}
+
+	switch v := v.(type) {
+	case float64:
+		return windowBound{offset: v}, nil
+	case int32:
+		return windowBound{offset: int64(v)}, nil
+	case int64:
+		return windowBound{offset: v}, nil
+	default:
+		return windowBound{}, windowFailedToParse(
+			fmt.Sprintf("Range-based bounds expression must be a number: %s", types.FormatAnyValue(v)),
+		)
+	}
+}
+
+// boundOffset returns the numeric offset of the bounded bound; "current" is zero.
+func (w *window) boundOffset(b windowBound) float64 {
+	if b.current {
+		return 0
+	}
+
+	return windowFloat(b.offset)
+}
+
+// windowTypeError returns an error for $setWindowFields field of the wrong type.
+func windowTypeError(field string, v any, expected string) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(
+		handlererrors.ErrTypeMismatch,
+		fmt.Sprintf("BSON field '%s' is the wrong type '%s', expected type '%s'", field, handlerparams.AliasFromType(v), expected),
+		"$setWindowFields (stage)",
+	)
+}
+
+// windowFailedToParse returns an error for invalid $setWindowFields specification.
+func windowFailedToParse(msg string) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrFailedToParse, msg, "$setWindowFields (stage)")
+}
+
+// windowUnitsList is a list of supported units for error messages.
+const windowUnitsList = "'week', 'day', 'hour', 'minute', 'second', 'millisecond'"
+
+// windowInteger returns v as an integer if it is a whole number.
+func windowInteger(v any) (int64, bool) {
+	switch v := v.(type) {
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) || v > math.MaxInt64 || v < math.MinInt64 {
+			return 0, false
+		}
+
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// windowFloat converts number to float64.
+func windowFloat(v any) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case types.Decimal128:
+		return v.Float64()
+	default:
+		panic(fmt.Sprintf("unexpected type %T", v))
+	}
+}
+
+// Process implements Stage interface.
+func (s *setWindowFields) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	docs, err := iterator.ConsumeValues(iter)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var partitions groupMap
+
+	for _, doc := range docs {
+		var key any = types.Null
+
+		if s.partitionBy != nil {
+			if key, err = s.partitionBy.Process(doc); err != nil {
+				return nil, err
+			}
+		}
+
+		partitions.addOrAppend(key, doc)
+	}
+
+	res := make([]*types.Document, 0, len(docs))
+
+	for _, partition := range partitions.docs {
+		group := partition.documents
+
+		if s.sortBy != nil {
+			if err = common.SortDocuments(group, s.sortBy, nil); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		// all outputs are computed before any of them is set,
+		// so window functions see original values of documents
+		values := make([][]any, len(s.output))
+
+		for i, o := range s.output {
+			if values[i], err = s.computeOutput(group, &o); err != nil {
+				return nil, err
+			}
+		}
+
+		for i, o := range s.output {
+			for j, doc := range group {
+				if err = doc.SetByPath(o.field, values[i][j]); err != nil {
+					return nil, handlererrors.NewCommandErrorMsgWithArgument(
+						handlererrors.ErrBadValue,
+						fmt.Sprintf("Cannot set output field '%s': %s", o.field.String(), err),
+						"$setWindowFields (stage)",
+					)
+				}
+			}
+		}
+
+		res = append(res, group...)
+	}
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// computeOutput returns values of the output field for each document of the sorted partition.
+func (s *setWindowFields) computeOutput(docs []*types.Document, o *windowOutput) ([]any, error) {
+	res := make([]any, len(docs))
+
+	switch o.fn {
+	case "$rank", "$denseRank", "$documentNumber":
+		sortPath := must.NotFail(types.NewPathFromString(s.sortBy.Command()))
+
+		var prev any
+		var rank, dense int64
+
+		for i, doc := range docs {
+			v, _ := doc.GetByPath(sortPath)
+			if v == nil {
+				v = types.Null
+			}
+
+			if i == 0 || types.CompareOrderForSort(prev, v, types.Ascending) != types.Equal {
+				rank = int64(i + 1)
+				dense++
+			}
+
+			prev = v
+
+			switch o.fn {
+			case "$rank":
+				res[i] = windowInt(rank)
+			case "$denseRank":
+				res[i] = windowInt(dense)
+			default:
+				res[i] = windowInt(int64(i + 1))
+			}
+		}
+
+	case "$shift":
+		for i := range docs {
+			j := int64(i) + o.by
+			if j < 0 || j >= int64(len(docs)) {
+				res[i] = o.defaultValue
+				continue
+			}
+
+			v, err := o.input.Process(docs[j])
+			if err != nil {
+				return nil, err
+			}
+
+			res[i] = v
+		}
+
+	case "$derivative":
+		for i := range docs {
+			lo, hi, err := s.windowRange(docs, i, o.window)
+			if err != nil {
+				return nil, err
+			}
+
+			if res[i], err = s.derivative(docs[lo:hi], o); err != nil {
+				return nil, err
+			}
+		}
+
+	default:
+		if o.window == nil {
+			v, err := windowAccumulate(o.accumulator, docs)
+			if err != nil {
+				return nil, err
+			}
+
+			for i := range docs {
+				res[i] = v
+			}
+
+			break
+		}
+
+		for i := range docs {
+			lo, hi, err := s.windowRange(docs, i, o.window)
+			if err != nil {
+				return nil, err
+			}
+
+			if res[i], err = windowAccumulate(o.accumulator, docs[lo:hi]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// windowAccumulate returns the result of the accumulator over the given window documents.
+func windowAccumulate(accumulator accumulators.Accumulator, docs []*types.Document) (any, error) {
+	// not all accumulators close the iterator
+	iter := iterator.Values(iterator.ForSlice(docs))
+	defer iter.Close()
+
+	return accumulator.Accumulate(iter)
+}
+
+// windowRange returns the half-open range of indexes of sorted partition documents
+// that belong to the window of the document with the given index.
+func (s *setWindowFields) windowRange(docs []*types.Document, i int, w *window) (int, int, error) {
+	if !w.byRange {
+		lo, hi := 0, len(docs)
+
+		if !w.lower.unbounded {
+			lo = i + int(w.boundOffset(w.lower))
+		}
+
+		if !w.upper.unbounded {
+			hi = i + int(w.boundOffset(w.upper)) + 1
+		}
+
+		lo, hi = max(lo, 0), min(hi, len(docs))
+		if lo > hi {
+			lo = hi
+		}
+
+		return lo, hi, nil
+	}
+
+	sortPath := must.NotFail(types.NewPathFromString(s.sortBy.Command()))
+
+	cur, err := s.rangeValue(docs[i], sortPath, w)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	lower, upper := math.Inf(-1), math.Inf(1)
+
+	if !w.lower.unbounded {
+		lower = windowRangeBound(cur, w.lower, w.unit)
+	}
+
+	if !w.upper.unbounded {
+		upper = windowRangeBound(cur, w.upper, w.unit)
+	}
+
+	lo, hi := -1, -1
+
+	for j, doc := range docs {
+		v, err := s.rangeValue(doc, sortPath, w)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if v < lower || v > upper {
+			continue
+		}
+
+		if lo < 0 {
+			lo = j
+		}
+
+		hi = j + 1
+	}
+
+	if lo < 0 {
+		return i, i, nil
+	}
+
+	return lo, hi, nil
+}
+
+// rangeValue returns the sortBy value of the document for range-based windows.
+// Dates are returned as milliseconds since epoch.
+func (s *setWindowFields) rangeValue(doc *types.Document, sortPath types.Path, w *window) (float64, error) {
+	v, _ := doc.GetByPath(sortPath)
+	if v == nil {
+		v = types.Null
+	}
+
+	if w.unit != "" {
+		t, ok := v.(time.Time)
+		if !ok {
+			return 0, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrTypeMismatch,
+				fmt.Sprintf("Invalid range: Expected the sortBy field to be a Date, but it was %s", handlerparams.AliasFromType(v)),
+				"$setWindowFields (stage)",
+			)
+		}
+
+		return float64(t.UnixMilli()), nil
+	}
+
+	switch v := v.(type) {
+	case float64, int32, int64, types.Decimal128:
+		return windowFloat(v), nil
+	default:
+		return 0, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrTypeMismatch,
+			fmt.Sprintf("Invalid range: Expected the sortBy field to be a number, but it was %s", handlerparams.AliasFromType(v)),
+			"$setWindowFields (stage)",
+		)
+	}
+}
+
+// windowRangeBound returns the value of range-based window bound for the current sortBy value.
+func windowRangeBound(cur float64, b windowBound, unit string) float64 {
+	switch {
+	case b.current:
+		return cur
+	case unit != "":
+		t := time.UnixMilli(int64(cur)).UTC()
+		return float64(densifyDateAdd(t, unit, b.offset.(int64)).UnixMilli())
+	default:
+		return cur + windowFloat(b.offset)
+	}
+}
+
+// derivative returns the rate of change of the input between the first and the last documents of the window.
+// It returns null if the window contains less than two documents.
+func (s *setWindowFields) derivative(docs []*types.Document, o *windowOutput) (any, error) {
+	if len(docs) < 2 {
+		return types.Null, nil
+	}
+
+	sortPath := must.NotFail(types.NewPathFromString(s.sortBy.Command()))
+
+	var xs, ys [2]float64
+
+	for i, doc := range []*types.Document{docs[0], docs[len(docs)-1]} {
+		x, _ := doc.GetByPath(sortPath)
+
+		switch x := x.(type) {
+		case float64, int32, int64, types.Decimal128:
+			if o.unit != "" {
+				return nil, windowTypeMismatch("$derivative with 'unit' expects the sortBy field to be a Date")
+			}
+
+			xs[i] = windowFloat(x)
+
+		case time.Time:
+			if o.unit == "" {
+				return nil, windowTypeMismatch("$derivative where the sortBy is a Date requires an 'unit'")
+			}
+
+			xs[i] = float64(x.UnixMilli()) / float64(windowUnits[o.unit])
+
+		default:
+			return nil, windowTypeMismatch("$derivative expects the sortBy field to be numeric or a Date")
+		}
+
+		y, err := o.input.Process(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		switch y := y.(type) {
+		case float64, int32, int64, types.Decimal128:
+			ys[i] = windowFloat(y)
+		default:
+			return nil, windowTypeMismatch(
+				fmt.Sprintf("$derivative input must be numeric, but found %s", handlerparams.AliasFromType(y)),
+			)
+		}
+	}
+
+	if xs[1] == xs[0] {
+		return types.Null, nil
+	}
+
+	return (ys[1] - ys[0]) / (xs[1] - xs[0]), nil
+}
+
+// windowTypeMismatch returns a TypeMismatch error for $setWindowFields stage.
+func windowTypeMismatch(msg string) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrTypeMismatch, msg, "$setWindowFields (stage)")
+}
+
+// windowInt returns int32 if the value fits into it, int64 otherwise.
+func windowInt(v int64) any {
+	if v >= math.MinInt32 && v <= math.MaxInt32 {
+		return int32(v)
+	}
+
+	return v
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*setWindowFields)(nil)
+)
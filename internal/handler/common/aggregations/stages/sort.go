@@ -28,7 +28,10 @@ import (
 
 // sort represents $sort stage.
 type sort struct {
-	fields *types.Document
+	fields       *types.Document
+	collation    *types.Collation
+	allowDiskUse bool
+	memoryLimit  int
 }
 
 // newSort creates a new $sort stage.
@@ -61,7 +64,12 @@ func newSort(stage *types.Document) (aggregations.Stage, error) {
 //
 // If sort path is invalid, it returns a possibly wrapped types.PathError.
 func (s *sort) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
-	iter, err := common.SortIterator(iter, closer, s.fields)
+	iter, err := common.SortIterator(iter, closer, &common.SortParams{
+		Sort:         s.fields,
+		Collation:    s.collation,
+		MemoryLimit:  s.memoryLimit,
+		AllowDiskUse: s.allowDiskUse,
+	})
 	if err != nil {
 		// TODO https://github.com/FerretDB/FerretDB/issues/3125
 		var pathErr *types.PathError
@@ -17,6 +17,7 @@ package stages
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
 	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
@@ -29,56 +30,80 @@ type newStageFunc func(stage *types.Document) (aggregations.Stage, error)
 // Stages maps all supported aggregation Stages.
 var Stages = map[string]newStageFunc{
 	// sorted alphabetically
-	"$addFields": newAddFields,
-	"$collStats": newCollStats,
-	"$count":     newCount,
-	"$group":     newGroup,
-	"$limit":     newLimit,
-	"$match":     newMatch,
-	"$project":   newProject,
-	"$set":       newSet,
-	"$skip":      newSkip,
-	"$sort":      newSort,
-	"$unset":     newUnset,
-	"$unwind":    newUnwind,
+	"$addFields":       newAddFields,
+	"$bucket":          newBucket,
+	"$bucketAuto":      newBucketAuto,
+	"$collStats":       newCollStats,
+	"$count":           newCount,
+	"$densify":         newDensify,
+	"$facet":           newFacet,
+	"$fill":            newFill,
+	"$group":           newGroup,
+	"$indexStats":      newIndexStats,
+	"$limit":           newLimit,
+	"$lookup":          newLookup,
+	"$match":           newMatch,
+	"$merge":           newMerge,
+	"$out":             newOut,
+	"$planCacheStats":  newPlanCacheStats,
+	"$project":         newProject,
+	"$queryStats":      newQueryStats,
+	"$redact":          newRedact,
+	"$sample":          newSample,
+	"$set":             newSet,
+	"$setWindowFields": newSetWindowFields,
+	"$skip":            newSkip,
+	"$sort":            newSort,
+	"$unset":           newUnset,
+	"$unwind":          newUnwind,
 	// please keep sorted alphabetically
 }
 
 // unsupportedStages maps all unsupported yet stages.
 var unsupportedStages = map[string]struct{}{
 	// sorted alphabetically
-	"$bucket":                 {},
-	"$bucketAuto":             {},
 	"$changeStream":           {},
 	"$currentOp":              {},
-	"$densify":                {},
 	"$documents":              {},
-	"$facet":                  {},
-	"$fill":                   {},
 	"$geoNear":                {},
 	"$graphLookup":            {},
-	"$indexStats":             {},
 	"$listLocalSessions":      {},
 	"$listSessions":           {},
-	"$lookup":                 {},
-	"$merge":                  {},
-	"$out":                    {},
-	"$planCacheStats":         {},
-	"$redact":                 {},
 	"$replaceRoot":            {},
 	"$replaceWith":            {},
-	"$sample":                 {},
 	"$search":                 {},
 	"$searchMeta":             {},
-	"$setWindowFields":        {},
 	"$sharedDataDistribution": {},
 	"$sortByCount":            {},
 	"$unionWith":              {},
 	// please keep sorted alphabetically
 }
 
+// NewStageParams represents parameters shared by aggregation stages.
+type NewStageParams struct {
+	// Stages that compare strings ($bucket, $bucketAuto, $group and $sort) use the given collation;
+	// nil collation compares strings as binary.
+	Collation *types.Collation
+
+	// $sort stage parameters; see common.SortParams.
+	AllowDiskUse    bool
+	SortMemoryLimit int
+
+	// $lookup stage reads documents of other collections with that function;
+	// nil function makes $lookup stage unsupported.
+	Lookup LookupFunc
+
+	// $merge stage writes documents to the target collection returned by that function;
+	// nil function makes $merge stage unsupported.
+	Merge MergeFunc
+
+	// $out stage writes documents to the target collection with that function;
+	// nil function makes $out stage unsupported.
+	Out OutFunc
+}
+
 // NewStage creates a new aggregation stage.
-func NewStage(stage *types.Document) (aggregations.Stage, error) {
+func NewStage(stage *types.Document, params *NewStageParams) (aggregations.Stage, error) {
 	if stage.Len() != 1 {
 		return nil, handlererrors.NewCommandErrorMsgWithArgument(
 			handlererrors.ErrStageInvalid,
@@ -97,7 +122,43 @@ func NewStage(stage *types.Document) (aggregations.Stage, error) {
 		panic(fmt.Sprintf("stage %q is in both `stages` and `unsupportedStages`", name))
 
 	case supported && !unsupported:
-		return f(stage)
+		s, err := f(stage)
+		if err != nil {
+			return nil, err
+		}
+
+		switch s := s.(type) {
+		case *bucket:
+			s.collation = params.Collation
+		case *bucketAuto:
+			s.collation = params.Collation
+		case *facet:
+			if err = s.init(params); err != nil {
+				return nil, err
+			}
+		case *group:
+			s.collation = params.Collation
+		case *lookup:
+			if err = s.init(params); err != nil {
+				return nil, err
+			}
+		case *match:
+			s.collation = params.Collation
+		case *merge:
+			if err = s.init(params); err != nil {
+				return nil, err
+			}
+		case *out:
+			if err = s.init(params); err != nil {
+				return nil, err
+			}
+		case *sort:
+			s.collation = params.Collation
+			s.allowDiskUse = params.AllowDiskUse
+			s.memoryLimit = params.SortMemoryLimit
+		}
+
+		return s, nil
 
 	case !supported && unsupported:
 		return nil, handlererrors.NewCommandErrorMsgWithArgument(
@@ -116,3 +177,10 @@ func NewStage(stage *types.Document) (aggregations.Stage, error) {
 
 	panic("not reached")
 }
+
+// Name returns the name of the given stage, like "$match".
+//
+// It relies on stage types being named after stages.
+func Name(s aggregations.Stage) string {
+	return "$" + reflect.TypeOf(s).Elem().Name()
+}
@@ -0,0 +1,334 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// ApplyOpsParams represents `applyOps` command parameters.
+//
+// Only a subset of oplog entries is supported; that is enough for mongorestore --oplogReplay
+// and similar tools.
+type ApplyOpsParams struct {
+	Ops []*ApplyOp
+
+	// If true (the default), inserts and updates of non-existing documents are performed as upserts.
+	AlwaysUpsert bool
+}
+
+// ApplyOp represents a single oplog entry of `applyOps` command.
+type ApplyOp struct {
+	// Op is "i" for insert, "u" for update, "d" for delete, "c" for command, or "n" for no-op.
+	Op string
+
+	DB         string
+	Collection string
+
+	// Document to insert for "i", _id document for "d", index specification for "c" with createIndexes.
+	Doc *types.Document
+
+	// Update for "u"; nil if the update does not change anything.
+	Update *Update
+
+	// Nested entries of "c" with applyOps (transaction envelope).
+	Ops []*ApplyOp
+}
+
+// GetApplyOpsParams returns `applyOps` command parameters.
+func GetApplyOpsParams(document *types.Document, l *zap.Logger) (*ApplyOpsParams, error) {
+	if err := Unimplemented(document, "preCondition"); err != nil {
+		return nil, err
+	}
+
+	Ignored(
+		document, l,
+		"allowAtomic", "bypassDocumentValidation", "oplogApplicationMode", "writeConcern",
+	)
+
+	arr, err := GetRequiredParam[*types.Array](document, document.Command())
+	if err != nil {
+		return nil, err
+	}
+
+	var params ApplyOpsParams
+
+	if params.AlwaysUpsert, err = GetOptionalParam(document, "alwaysUpsert", true); err != nil {
+		return nil, err
+	}
+
+	if params.Ops, err = getApplyOps(arr, params.AlwaysUpsert); err != nil {
+		return nil, err
+	}
+
+	return &params, nil
+}
+
+// getApplyOps returns oplog entries of the given array.
+//
+// If alwaysUpsert is true, inserts and updates of non-existing documents are performed as upserts.
+func getApplyOps(arr *types.Array, alwaysUpsert bool) ([]*ApplyOp, error) {
+	res := make([]*ApplyOp, arr.Len())
+
+	for i := 0; i < arr.Len(); i++ {
+		entry, ok := must.NotFail(arr.Get(i)).(*types.Document)
+		if !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrTypeMismatch,
+				fmt.Sprintf("applyOps: each entry must be an object, found %s", types.FormatAnyValue(must.NotFail(arr.Get(i)))),
+				"applyOps",
+			)
+		}
+
+		op, err := getApplyOp(entry, alwaysUpsert)
+		if err != nil {
+			return nil, err
+		}
+
+		res[i] = op
+	}
+
+	return res, nil
+}
+
+// getApplyOp returns a single oplog entry.
+func getApplyOp(entry *types.Document, alwaysUpsert bool) (*ApplyOp, error) {
+	op, _ := entry.Get("op")
+	ns, _ := entry.Get("ns")
+
+	opStr, ok := op.(string)
+	if !ok {
+		return nil, applyOpsError(handlererrors.ErrFailedToParse, "'op' field must be a string", entry)
+	}
+
+	res := &ApplyOp{
+		Op: opStr,
+	}
+
+	if res.Op == "n" {
+		return res, nil
+	}
+
+	nsStr, ok := ns.(string)
+	if !ok {
+		return nil, applyOpsError(handlererrors.ErrFailedToParse, "'ns' field must be a string", entry)
+	}
+
+	if res.DB, res.Collection, ok = strings.Cut(nsStr, "."); !ok || res.DB == "" || res.Collection == "" {
+		return nil, applyOpsError(handlererrors.ErrInvalidNamespace, fmt.Sprintf("invalid namespace %q", nsStr), entry)
+	}
+
+	oValue, _ := entry.Get("o")
+
+	o, ok := oValue.(*types.Document)
+	if !ok {
+		return nil, applyOpsError(handlererrors.ErrFailedToParse, "'o' field must be an object", entry)
+	}
+
+	switch res.Op {
+	case "i":
+		if !o.Has("_id") {
+			return nil, applyOpsError(handlererrors.ErrFailedToParse, "inserted document must have _id", entry)
+		}
+
+		res.Doc = o
+
+		if alwaysUpsert {
+			res.Update = &Update{
+				Filter: must.NotFail(types.NewDocument("_id", must.NotFail(o.Get("_id")))),
+				Update: o,
+				Upsert: true,
+			}
+		}
+
+	case "u":
+		o2, _ := entry.Get("o2")
+
+		filter, ok := o2.(*types.Document)
+		if !ok || !filter.Has("_id") {
+			return nil, applyOpsError(handlererrors.ErrFailedToParse, "'o2' field must be an object with _id", entry)
+		}
+
+		b, _ := entry.Get("b")
+		upsert, _ := b.(bool)
+
+		update, hasOperators, err := oplogUpdate(o, entry)
+		if err != nil {
+			return nil, err
+		}
+
+		if update != nil {
+			res.Update = &Update{
+				Filter:             filter,
+				Update:             update,
+				Upsert:             alwaysUpsert || upsert,
+				HasUpdateOperators: hasOperators,
+			}
+		}
+
+	case "d":
+		if !o.Has("_id") {
+			return nil, applyOpsError(handlererrors.ErrFailedToParse, "deleted document must be specified by _id", entry)
+		}
+
+		res.Doc = o
+
+	case "c":
+		switch o.Command() {
+		case "applyOps":
+			var arr *types.Array
+			arr, ok = must.NotFail(o.Get("applyOps")).(*types.Array)
+			if !ok {
+				return nil, applyOpsError(handlererrors.ErrFailedToParse, "nested 'applyOps' field must be an array", entry)
+			}
+
+			var err error
+			if res.Ops, err = getApplyOps(arr, alwaysUpsert); err != nil {
+				return nil, err
+			}
+
+		case "createIndexes":
+			res.Collection, ok = must.NotFail(o.Get("createIndexes")).(string)
+			if !ok {
+				return nil, applyOpsError(handlererrors.ErrFailedToParse, "'createIndexes' field must be a string", entry)
+			}
+
+			res.Doc = o.DeepCopy()
+			res.Doc.Remove("createIndexes")
+
+		default:
+			return nil, applyOpsError(
+				handlererrors.ErrNotImplemented,
+				fmt.Sprintf("command %q is not supported; only applyOps and createIndexes are", o.Command()),
+				entry,
+			)
+		}
+
+	default:
+		return nil, applyOpsError(
+			handlererrors.ErrBadValue,
+			fmt.Sprintf("op type %q is not supported; only 'i', 'u', 'd', 'c', and 'n' are", res.Op),
+			entry,
+		)
+	}
+
+	return res, nil
+}
+
+// oplogUpdate returns update document and whether it contains update operators
+// for the given oplog update entry.
+//
+// Replacement documents, update operators with optional `$v: 1`,
+// and `$v: 2` diffs without array changes are supported.
+// Returned update is nil if the diff is empty.
+func oplogUpdate(o, entry *types.Document) (*types.Document, bool, error) {
+	v, _ := o.Get("$v")
+	version, _ := handlerparams.GetWholeNumberParam(v)
+
+	if version == 2 {
+		d, _ := o.Get("diff")
+
+		diff, ok := d.(*types.Document)
+		if !ok {
+			return nil, false, applyOpsError(handlererrors.ErrFailedToParse, "$v: 2 update must have 'diff' object", entry)
+		}
+
+		set, unset := types.MakeDocument(0), types.MakeDocument(0)
+
+		if err := oplogDiff(diff, "", set, unset, entry); err != nil {
+			return nil, false, err
+		}
+
+		res := types.MakeDocument(2)
+
+		if set.Len() > 0 {
+			res.Set("$set", set)
+		}
+
+		if unset.Len() > 0 {
+			res.Set("$unset", unset)
+		}
+
+		if res.Len() == 0 {
+			return nil, false, nil
+		}
+
+		return res, true, nil
+	}
+
+	res := o.DeepCopy()
+	res.Remove("$v")
+
+	for _, k := range res.Keys() {
+		if strings.HasPrefix(k, "$") {
+			return res, true, nil
+		}
+	}
+
+	return res, false, nil
+}
+
+// oplogDiff adds fields of the `$v: 2` diff with the given path prefix
+// to $set and $unset update operators.
+func oplogDiff(diff *types.Document, prefix string, set, unset, entry *types.Document) error {
+	for _, k := range diff.Keys() {
+		fields, ok := must.NotFail(diff.Get(k)).(*types.Document)
+
+		switch {
+		case k == "a":
+			return applyOpsError(handlererrors.ErrNotImplemented, "array diffs are not supported", entry)
+
+		case !ok:
+			return applyOpsError(handlererrors.ErrFailedToParse, fmt.Sprintf("invalid diff field %q", prefix+k), entry)
+
+		case k == "i", k == "u":
+			for _, f := range fields.Keys() {
+				set.Set(prefix+f, must.NotFail(fields.Get(f)))
+			}
+
+		case k == "d":
+			for _, f := range fields.Keys() {
+				unset.Set(prefix+f, "")
+			}
+
+		case strings.HasPrefix(k, "s") && len(k) > 1:
+			if err := oplogDiff(fields, prefix+k[1:]+".", set, unset, entry); err != nil {
+				return err
+			}
+
+		default:
+			return applyOpsError(handlererrors.ErrFailedToParse, fmt.Sprintf("invalid diff field %q", prefix+k), entry)
+		}
+	}
+
+	return nil
+}
+
+// applyOpsError returns an error for the given invalid oplog entry.
+func applyOpsError(code handlererrors.ErrorCode, msg string, entry *types.Document) error {
+	return handlererrors.NewCommandErrorMsgWithArgument(
+		code,
+		fmt.Sprintf("applyOps: %s: %s", msg, types.FormatAnyValue(entry)),
+		"applyOps",
+	)
+}
@@ -0,0 +1,245 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// BufferParams represents parameters of NewBuffer.
+type BufferParams struct {
+	// Maximum total size of documents kept in memory, in bytes; 0 for DefaultSortMemoryLimit.
+	MemoryLimit int
+
+	// If true, documents that exceed MemoryLimit are written to temporary files
+	// in TempDir (os.TempDir() if empty); otherwise, exceeding MemoryLimit is an error.
+	AllowDiskUse bool
+	TempDir      string
+}
+
+// Buffer stores all documents of the iterator, so they could be iterated several times.
+//
+// It should be closed after all its iterators are closed.
+type Buffer struct {
+	runs *sortRuns          // documents written to temporary files, in order; nil if none
+	docs []*types.Document  // remaining documents in memory
+	raws []bson.RawDocument // encoded docs, written to a temporary file as is
+	n    int
+}
+
+// NewBuffer returns a new buffer with all documents of the given iterator.
+//
+// This function fully consumes and closes the iterator.
+// Documents that do not fit into the memory limit are written to temporary files if disk use is allowed.
+func NewBuffer(iter types.DocumentsIterator, params *BufferParams) (*Buffer, error) {
+	defer iter.Close()
+
+	limit := params.MemoryLimit
+	if limit == 0 {
+		limit = DefaultSortMemoryLimit
+	}
+
+	b := new(Buffer)
+
+	var size int
+
+	for {
+		_, doc, err := iter.Next()
+		if err != nil {
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			b.Close()
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		var raw bson.RawDocument
+
+		if raw, err = encodeSortDocument(doc); err != nil {
+			b.Close()
+			return nil, lazyerrors.Error(err)
+		}
+
+		b.docs = append(b.docs, doc)
+		b.raws = append(b.raws, raw)
+		b.n++
+
+		if size += len(raw); size <= limit {
+			continue
+		}
+
+		if !params.AllowDiskUse {
+			b.Close()
+
+			return nil, handlererrors.NewCommandErrorMsg(
+				handlererrors.ErrQueryExceededMemoryLimitNoDiskUseAllowed,
+				fmt.Sprintf("Exceeded memory limit of %d bytes, but did not opt in to disk use. Pass allowDiskUse:true to opt in.", limit),
+			)
+		}
+
+		if b.runs == nil {
+			if b.runs, err = newSortRuns(params.TempDir, "ferretdb-buffer-"); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		if err = b.runs.write(b.raws); err != nil {
+			b.Close()
+			return nil, lazyerrors.Error(err)
+		}
+
+		b.docs = nil
+		b.raws = nil
+		size = 0
+	}
+
+	// remaining documents are not written
+	b.raws = nil
+
+	return b, nil
+}
+
+// Len returns the number of stored documents.
+func (b *Buffer) Len() int {
+	return b.n
+}
+
+// Spills returns the number of temporary files used to store documents.
+func (b *Buffer) Spills() int {
+	if b.runs == nil {
+		return 0
+	}
+
+	return len(b.runs.files)
+}
+
+// Iterator returns a new iterator over stored documents in the original order.
+// It will be added to the given closer.
+func (b *Buffer) Iterator(closer *iterator.MultiCloser) types.DocumentsIterator {
+	var files []string
+
+	if b.runs != nil {
+		files = make([]string, len(b.runs.files))
+		for i, f := range b.runs.files {
+			files[i] = f.Name()
+		}
+	}
+
+	res := &bufferIterator{
+		files: files,
+		docs:  b.docs,
+	}
+
+	closer.Add(res)
+
+	return res
+}
+
+// Close removes temporary files.
+func (b *Buffer) Close() {
+	b.runs.close()
+	b.runs = nil
+	b.docs = nil
+	b.raws = nil
+}
+
+// bufferIterator iterates over documents stored in the buffer.
+//
+//nolint:vet // for readability
+type bufferIterator struct {
+	m     sync.Mutex
+	files []string                                          // names of remaining temporary files
+	f     *os.File                                          // current temporary file, if any
+	next  func() (*types.Document, bson.RawDocument, error) // reads the current temporary file
+	docs  []*types.Document                                 // remaining documents in memory
+}
+
+// Next implements iterator.Interface.
+func (iter *bufferIterator) Next() (struct{}, *types.Document, error) {
+	iter.m.Lock()
+	defer iter.m.Unlock()
+
+	var unused struct{}
+
+	for iter.next != nil || len(iter.files) > 0 {
+		if iter.next == nil {
+			f, err := os.Open(iter.files[0])
+			if err != nil {
+				return unused, nil, lazyerrors.Error(err)
+			}
+
+			iter.files = iter.files[1:]
+			iter.f = f
+			iter.next = fileRun(f)
+		}
+
+		doc, _, err := iter.next()
+		if err == nil {
+			return unused, doc, nil
+		}
+
+		if !errors.Is(err, iterator.ErrIteratorDone) {
+			return unused, nil, lazyerrors.Error(err)
+		}
+
+		iter.closeFile()
+	}
+
+	if len(iter.docs) == 0 {
+		return unused, nil, iterator.ErrIteratorDone
+	}
+
+	doc := iter.docs[0]
+	iter.docs = iter.docs[1:]
+
+	return unused, doc, nil
+}
+
+// Close implements iterator.Interface.
+func (iter *bufferIterator) Close() {
+	iter.m.Lock()
+	defer iter.m.Unlock()
+
+	iter.closeFile()
+
+	iter.files = nil
+	iter.docs = nil
+}
+
+// closeFile closes the current temporary file, if any.
+func (iter *bufferIterator) closeFile() {
+	if iter.f != nil {
+		_ = iter.f.Close()
+	}
+
+	iter.f = nil
+	iter.next = nil
+}
+
+// check interfaces
+var (
+	_ types.DocumentsIterator = (*bufferIterator)(nil)
+)
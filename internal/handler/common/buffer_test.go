@@ -0,0 +1,105 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestBuffer(t *testing.T) {
+	t.Parallel()
+
+	const n = 1000
+
+	docs := make([]*types.Document, n)
+	for i := range n {
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(i), "v", "foo"))
+	}
+
+	for name, tc := range map[string]struct {
+		memoryLimit int
+		spills      bool
+	}{
+		"InMemory": {},
+		"AllowDiskUse": {
+			memoryLimit: 1024,
+			spills:      true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+
+			b, err := NewBuffer(iterator.Values(iterator.ForSlice(docs)), &BufferParams{
+				MemoryLimit:  tc.memoryLimit,
+				AllowDiskUse: true,
+				TempDir:      dir,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, n, b.Len())
+
+			if tc.spills {
+				assert.Greater(t, b.Spills(), 1)
+			} else {
+				assert.Zero(t, b.Spills())
+			}
+
+			closer := iterator.NewMultiCloser()
+
+			// iterate several times, including concurrently opened iterators
+			iter1 := b.Iterator(closer)
+			iter2 := b.Iterator(closer)
+
+			for _, iter := range []types.DocumentsIterator{iter1, iter2, b.Iterator(closer)} {
+				res, err := iterator.ConsumeValues(iter)
+				require.NoError(t, err)
+				require.Len(t, res, n)
+
+				for i, doc := range res {
+					assert.Equal(t, int32(i), must.NotFail(doc.Get("_id")))
+				}
+			}
+
+			closer.Close()
+			b.Close()
+
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Empty(t, entries, "temporary files should be removed")
+		})
+	}
+
+	t.Run("NoDiskUse", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewBuffer(iterator.Values(iterator.ForSlice(docs)), &BufferParams{
+			MemoryLimit: 1024,
+		})
+
+		var cmdErr *handlererrors.CommandError
+		require.ErrorAs(t, err, &cmdErr)
+		assert.Equal(t, handlererrors.ErrQueryExceededMemoryLimitNoDiskUseAllowed, cmdErr.Code())
+	})
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// GetCollation returns collation for the given `collation` command parameter.
+//
+// It returns nil (the simple binary collation) if the parameter is not set.
+// Invalid and unsupported collation documents result in ErrBadValue.
+func GetCollation(command string, doc *types.Document) (*types.Collation, error) {
+	c, err := types.NewCollation(doc)
+	if err != nil {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrBadValue, err.Error(), command)
+	}
+
+	return c, nil
+}
+
+// compareFunc returns a function that compares values in ascending BSON order
+// with strings compared according to the given collation.
+// It is suitable for the slices package.
+func compareFunc(c *types.Collation) func(a, b any) int {
+	return func(a, b any) int {
+		switch c.CompareOrder(a, b, types.Ascending) {
+		case types.Less:
+			return -1
+		case types.Greater:
+			return 1
+		default:
+			return 0
+		}
+	}
+}
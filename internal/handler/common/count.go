@@ -34,8 +34,9 @@ type CountParams struct {
 
 	Fields any `ferretdb:"fields,ignored"` // legacy MongoDB shell adds it, but it is never actually used
 
-	MaxTimeMS      int64           `ferretdb:"maxTimeMS,ignored"`
-	Hint           any             `ferretdb:"hint,ignored"`
+	Hint any `ferretdb:"hint,opt"`
+
+	MaxTimeMS      int64           `ferretdb:"maxTimeMS,opt,wholePositiveNumber"`
 	ReadConcern    *types.Document `ferretdb:"readConcern,ignored"`
 	Comment        string          `ferretdb:"comment,ignored"`
 	LSID           any             `ferretdb:"lsid,ignored"`
@@ -17,6 +17,7 @@ package common
 import (
 	"errors"
 	"fmt"
+	"slices"
 
 	"go.uber.org/zap"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 // DistinctParams contains `distinct` command parameters supported by at least one handler.
@@ -38,9 +40,11 @@ type DistinctParams struct {
 	Filter     *types.Document `ferretdb:"-"`
 	Comment    string          `ferretdb:"comment,opt"`
 
-	Query any `ferretdb:"query,opt"`
+	Query     any   `ferretdb:"query,opt"`
+	MaxTimeMS int64 `ferretdb:"maxTimeMS,opt,wholePositiveNumber"`
 
-	Collation *types.Document `ferretdb:"collation,unimplemented"`
+	CollationDoc *types.Document  `ferretdb:"collation,opt"`
+	Collation    *types.Collation `ferretdb:"-"`
 
 	ReadConcern    *types.Document `ferretdb:"readConcern,ignored"`
 	LSID           any             `ferretdb:"lsid,ignored"`
@@ -80,20 +84,34 @@ func GetDistinctParams(document *types.Document, l *zap.Logger) (*DistinctParams
 		)
 	}
 
+	if dp.Collation, err = GetCollation("distinct", dp.CollationDoc); err != nil {
+		return nil, err
+	}
+
 	return &dp, nil
 }
 
 // FilterDistinctValues returns distinct values from the given slice of documents with the given key.
 //
 // If the key is not found in the document, the document is ignored.
+// Null values are not ignored.
 //
 // If the key is found in the document, and the value is an array, each element of the array is added to the result.
 // Otherwise, the value itself is added to the result.
-func FilterDistinctValues(iter types.DocumentsIterator, key string) (*types.Array, error) {
-	distinct := types.MakeArray(0)
-
+//
+// Like in MongoDB, values are deduplicated and sorted using BSON comparison rules
+// with strings compared according to the given collation (nil compares them as binary).
+// The first of equal values (like int32(1) and float64(1)) is returned.
+func FilterDistinctValues(iter types.DocumentsIterator, key string, c *types.Collation) (*types.Array, error) {
 	defer iter.Close()
 
+	path, err := types.NewPathFromString(key)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var values []any
+
 	for {
 		_, doc, err := iter.Next()
 		if errors.Is(err, iterator.ErrIteratorDone) {
@@ -104,11 +122,6 @@ func FilterDistinctValues(iter types.DocumentsIterator, key string) (*types.Arra
 			return nil, lazyerrors.Error(err)
 		}
 
-		path, err := types.NewPathFromString(key)
-		if err != nil {
-			return nil, lazyerrors.Error(err)
-		}
-
 		// distinct using dot notation returns the value by valid array index
 		// or values for the given key in array's document
 		vals, err := commonpath.FindValues(doc, path, &commonpath.FindValuesOpts{
@@ -120,28 +133,33 @@ func FilterDistinctValues(iter types.DocumentsIterator, key string) (*types.Arra
 		}
 
 		for _, val := range vals {
-			switch v := val.(type) {
-			case *types.Array:
-				for i := 0; i < v.Len(); i++ {
-					el, err := v.Get(i)
-					if err != nil {
-						return nil, lazyerrors.Error(err)
-					}
-
-					if !distinct.Contains(el) {
-						distinct.Append(el)
-					}
-				}
-
-			default:
-				if !distinct.Contains(v) {
-					distinct.Append(v)
-				}
+			arr, ok := val.(*types.Array)
+			if !ok {
+				values = append(values, val)
+				continue
+			}
+
+			// only one level of arrays is unwound
+			for i := 0; i < arr.Len(); i++ {
+				values = append(values, must.NotFail(arr.Get(i)))
 			}
 		}
 	}
 
-	SortArray(distinct, types.Ascending)
+	compare := compareFunc(c)
+
+	// stable sort keeps the first of equal values first
+	slices.SortStableFunc(values, compare)
+
+	distinct := types.MakeArray(len(values))
+
+	for i, v := range values {
+		if i > 0 && compare(must.NotFail(distinct.Get(distinct.Len()-1)), v) == 0 {
+			continue
+		}
+
+		distinct.Append(v)
+	}
 
 	return distinct, nil
 }
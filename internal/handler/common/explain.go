@@ -37,11 +37,16 @@ type ExplainParams struct {
 	Skip   int64           `ferretdb:"skip,opt"`
 	Limit  int64           `ferretdb:"limit,opt"`
 
+	AllowDiskUse bool `ferretdb:"allowDiskUse,opt"`
+	Hint         any  `ferretdb:"hint,opt"`
+
 	StagesDocs []any           `ferretdb:"-"`
 	Aggregate  bool            `ferretdb:"-"`
 	Command    *types.Document `ferretdb:"-"`
 
-	Verbosity string `ferretdb:"verbosity,ignored"`
+	// Execution statistics are collected only if verbosity is explicitly set
+	// to "executionStats" or "allPlansExecution".
+	Verbosity string `ferretdb:"verbosity,opt"`
 }
 
 // GetExplainParams returns the parameters for the explain command.
@@ -54,7 +59,10 @@ func GetExplainParams(document *types.Document, l *zap.Logger) (*ExplainParams,
 		return nil, lazyerrors.Error(err)
 	}
 
-	Ignored(document, l, "verbosity")
+	verbosity, err := GetOptionalParam(document, "verbosity", "queryPlanner")
+	if err != nil {
+		return nil, err
+	}
 
 	var cmd *types.Document
 
@@ -107,6 +115,14 @@ func GetExplainParams(document *types.Document, l *zap.Logger) (*ExplainParams,
 		return nil, err
 	}
 
+	// like MongoDB's allowDiskUseByDefault
+	allowDiskUse, err := GetOptionalParam(explain, "allowDiskUse", true)
+	if err != nil {
+		return nil, err
+	}
+
+	hint, _ := explain.Get("hint")
+
 	var stagesDocs []any
 
 	if cmd.Command() == "aggregate" {
@@ -134,14 +150,17 @@ func GetExplainParams(document *types.Document, l *zap.Logger) (*ExplainParams,
 	}
 
 	return &ExplainParams{
-		DB:         db,
-		Collection: collection,
-		Filter:     filter,
-		Sort:       sort,
-		Skip:       skip,
-		Limit:      limit,
-		StagesDocs: stagesDocs,
-		Aggregate:  cmd.Command() == "aggregate",
-		Command:    cmd,
+		DB:           db,
+		Collection:   collection,
+		Filter:       filter,
+		Sort:         sort,
+		Skip:         skip,
+		Limit:        limit,
+		AllowDiskUse: allowDiskUse,
+		Hint:         hint,
+		StagesDocs:   stagesDocs,
+		Aggregate:    cmd.Command() == "aggregate",
+		Command:      cmd,
+		Verbosity:    verbosity,
 	}, nil
 }
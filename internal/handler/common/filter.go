@@ -33,9 +33,10 @@ import (
 )
 
 // FilterDocument returns true if given document satisfies given filter expression.
+// Strings are compared according to the given collation; nil collation means simple binary comparison.
 //
 // Passed arguments must not be modified.
-func FilterDocument(doc, filter *types.Document) (bool, error) {
+func FilterDocument(doc, filter *types.Document, c *types.Collation) (bool, error) {
 	iter := filter.Iterator()
 	defer iter.Close()
 
@@ -50,7 +51,7 @@ func FilterDocument(doc, filter *types.Document) (bool, error) {
 		}
 
 		// top-level filters are ANDed together
-		matches, err := filterDocumentPair(doc, filterKey, filterValue)
+		matches, err := filterDocumentPair(doc, filterKey, filterValue, c)
 		if err != nil {
 			return false, lazyerrors.Error(err)
 		}
@@ -61,7 +62,7 @@ func FilterDocument(doc, filter *types.Document) (bool, error) {
 }
 
 // filterDocumentPair handles a single filter element key/value pair {filterKey: filterValue}.
-func filterDocumentPair(doc *types.Document, filterKey string, filterValue any) (bool, error) {
+func filterDocumentPair(doc *types.Document, filterKey string, filterValue any, c *types.Collation) (bool, error) {
 	var vals []any
 	filterSuffix := filterKey
 
@@ -89,7 +90,7 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 
 	if strings.HasPrefix(filterKey, "$") {
 		// {$operator: filterValue}
-		return filterOperator(doc, filterKey, filterValue)
+		return filterOperator(doc, filterKey, filterValue, c)
 	}
 
 	switch filterValue := filterValue.(type) {
@@ -106,7 +107,7 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 
 		for _, doc := range docs {
 			// {field: {expr}} or {field: {document}}
-			ok, err := filterFieldExpr(doc, filterKey, filterSuffix, filterValue)
+			ok, err := filterFieldExpr(doc, filterKey, filterSuffix, filterValue, c)
 			if err != nil {
 				return false, err
 			}
@@ -122,7 +123,7 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 		}
 
 		for _, val := range vals {
-			if result := types.Compare(val, filterValue); result == types.Equal {
+			if result := c.Compare(val, filterValue); result == types.Equal {
 				return true, nil
 			}
 		}
@@ -139,7 +140,7 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 		}
 	default:
 		for _, val := range vals {
-			if result := types.Compare(val, filterValue); result == types.Equal {
+			if result := c.Compare(val, filterValue); result == types.Equal {
 				return true, nil
 			}
 		}
@@ -150,7 +151,7 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 }
 
 // filterOperator handles a top-level operator filter {$operator: filterValue}.
-func filterOperator(doc *types.Document, operator string, filterValue any) (bool, error) {
+func filterOperator(doc *types.Document, operator string, filterValue any, c *types.Collation) (bool, error) {
 	switch operator {
 	case "$and":
 		// {$and: [{expr1}, {expr2}, ...]}
@@ -185,7 +186,7 @@ func filterOperator(doc *types.Document, operator string, filterValue any) (bool
 		for i := 0; i < exprs.Len(); i++ {
 			expr := must.NotFail(exprs.Get(i)).(*types.Document)
 
-			matches, err := FilterDocument(doc, expr)
+			matches, err := FilterDocument(doc, expr, c)
 			if err != nil {
 				return false, err
 			}
@@ -229,7 +230,7 @@ func filterOperator(doc *types.Document, operator string, filterValue any) (bool
 		for i := 0; i < exprs.Len(); i++ {
 			expr := must.NotFail(exprs.Get(i)).(*types.Document)
 
-			matches, err := FilterDocument(doc, expr)
+			matches, err := FilterDocument(doc, expr, c)
 			if err != nil {
 				return false, err
 			}
@@ -273,7 +274,7 @@ func filterOperator(doc *types.Document, operator string, filterValue any) (bool
 		for i := 0; i < exprs.Len(); i++ {
 			expr := must.NotFail(exprs.Get(i)).(*types.Document)
 
-			matches, err := FilterDocument(doc, expr)
+			matches, err := FilterDocument(doc, expr, c)
 			if err != nil {
 				return false, err
 			}
@@ -321,7 +322,7 @@ func filterExprOperator(doc, filter *types.Document) (bool, error) {
 	switch v := v.(type) {
 	case *types.Document, *types.Array, string, types.Binary, types.ObjectID, time.Time, types.Regex, types.Timestamp:
 		return true, nil
-	case float64, int32, int64:
+	case float64, int32, int64, types.Decimal128:
 		return types.Compare(v, int32(0)) != types.Equal, nil
 	case bool:
 		return v, nil
@@ -333,7 +334,7 @@ func filterExprOperator(doc, filter *types.Document) (bool, error) {
 }
 
 // filterFieldExpr handles {field: {expr}} or {field: {document}} filter.
-func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *types.Document) (bool, error) {
+func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *types.Document, c *types.Collation) (bool, error) { //nolint:lll // for readability
 	// check if both documents are empty
 	if expr.Len() == 0 {
 		fieldValue, err := doc.Get(filterSuffix)
@@ -373,7 +374,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 
 		if !strings.HasPrefix(exprKey, "$") {
 			if documentValue, ok := fieldValue.(*types.Document); ok {
-				result := types.Compare(documentValue, expr)
+				result := c.Compare(documentValue, expr)
 				return result == types.Equal, nil
 			}
 			return false, nil
@@ -385,12 +386,12 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 			switch exprValue := exprValue.(type) {
 			case *types.Document:
 				if fieldValue, ok := fieldValue.(*types.Document); ok {
-					result := types.Compare(exprValue, fieldValue)
+					result := c.Compare(exprValue, fieldValue)
 					return result == types.Equal, nil
 				}
 				return false, nil
 			default:
-				result := types.Compare(fieldValue, exprValue)
+				result := c.Compare(fieldValue, exprValue)
 				if result != types.Equal {
 					return false, nil
 				}
@@ -401,7 +402,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 			switch exprValue := exprValue.(type) {
 			case *types.Document:
 				if fieldValue, ok := fieldValue.(*types.Document); ok {
-					result := types.Compare(exprValue, fieldValue)
+					result := c.Compare(exprValue, fieldValue)
 					return result != types.Equal, nil
 				}
 
@@ -413,7 +414,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 					exprKey,
 				)
 			default:
-				result := types.Compare(fieldValue, exprValue)
+				result := c.Compare(fieldValue, exprValue)
 				if result == types.Equal {
 					return false, nil
 				}
@@ -439,7 +440,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 			// and results in Less. Other values "foo" and nil which are
 			// not number type are not considered for $gt comparison.
 
-			result := types.CompareOrderForOperator(fieldValue, exprValue, types.Descending)
+			result := c.CompareOrderForOperator(fieldValue, exprValue, types.Descending)
 			if result != types.Greater {
 				return false, nil
 			}
@@ -463,7 +464,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 			// Above compares the maximum number of array 41.5 to the filter 42,
 			// and results in Less. Other values "foo" and nil which are
 			// not number type are not considered for $gte comparison.
-			result := types.CompareOrderForOperator(fieldValue, exprValue, types.Descending)
+			result := c.CompareOrderForOperator(fieldValue, exprValue, types.Descending)
 			if result != types.Equal && result != types.Greater {
 				return false, nil
 			}
@@ -488,7 +489,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 			// and results in Less. Other values "foo" and nil which are
 			// not number type are not considered for $lt comparison.
 
-			result := types.CompareOrderForOperator(fieldValue, exprValue, types.Ascending)
+			result := c.CompareOrderForOperator(fieldValue, exprValue, types.Ascending)
 			if result != types.Less {
 				return false, nil
 			}
@@ -513,7 +514,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 			// and results in Less. Other values "foo" and nil which are
 			// not number type are not considered for $lt comparison.
 
-			result := types.CompareOrderForOperator(fieldValue, exprValue, types.Ascending)
+			result := c.CompareOrderForOperator(fieldValue, exprValue, types.Ascending)
 			if result != types.Equal && result != types.Less {
 				return false, nil
 			}
@@ -548,7 +549,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 					}
 
 					if fieldValue, ok := fieldValue.(*types.Document); ok {
-						if result := types.Compare(fieldValue, arrValue); result == types.Equal {
+						if result := c.Compare(fieldValue, arrValue); result == types.Equal {
 							found = true
 						}
 					}
@@ -561,7 +562,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 						found = true
 					}
 				default:
-					result := types.Compare(fieldValue, arrValue)
+					result := c.Compare(fieldValue, arrValue)
 					if result == types.Equal {
 						found = true
 					}
@@ -602,7 +603,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 					}
 
 					if fieldValue, ok := fieldValue.(*types.Document); ok {
-						if result := types.Compare(fieldValue, arrValue); result == types.Equal {
+						if result := c.Compare(fieldValue, arrValue); result == types.Equal {
 							found = true
 						}
 					}
@@ -615,7 +616,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 						found = true
 					}
 				default:
-					result := types.Compare(fieldValue, arrValue)
+					result := c.Compare(fieldValue, arrValue)
 					if result == types.Equal {
 						found = true
 					}
@@ -630,7 +631,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 			// {field: {$not: {expr}}}
 			switch exprValue := exprValue.(type) {
 			case *types.Document:
-				res, err := filterFieldExpr(doc, filterKey, filterSuffix, exprValue)
+				res, err := filterFieldExpr(doc, filterKey, filterSuffix, exprValue, c)
 				if res || err != nil {
 					return false, err
 				}
@@ -658,7 +659,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 
 		case "$elemMatch":
 			// {field: {$elemMatch: value}}
-			res, err := filterFieldExprElemMatch(doc, filterKey, filterSuffix, exprValue)
+			res, err := filterFieldExprElemMatch(doc, filterKey, filterSuffix, exprValue, c)
 			if !res || err != nil {
 				return false, err
 			}
@@ -672,7 +673,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 
 		case "$all":
 			// {field: {$all: [value, another_value, ...]}}
-			res, err := filterFieldExprAll(fieldValue, exprValue)
+			res, err := filterFieldExprAll(fieldValue, exprValue, c)
 			if !res || err != nil {
 				return false, err
 			}
@@ -891,7 +892,7 @@ func filterFieldExprSize(fieldValue any, sizeValue any) (bool, error) {
 // filterFieldExprAll handles {field: {$all: [value, another_value, ...]}} filter.
 // The main purpose of $all is to filter arrays.
 // It is possible to filter non-arrays: {field: {$all: [value]}}, but such statement is equivalent to {field: value}.
-func filterFieldExprAll(fieldValue any, allValue any) (bool, error) {
+func filterFieldExprAll(fieldValue any, allValue any, c *types.Collation) (bool, error) {
 	query, ok := allValue.(*types.Array)
 	if !ok {
 		return false, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrBadValue, "$all needs an array", "$all")
@@ -914,7 +915,7 @@ func filterFieldExprAll(fieldValue any, allValue any) (bool, error) {
 		// For other types (scalars) we check that the value is equal to each scalar in the query.
 		// Example: value: 42, query: [42, 42] should give us `true`
 		for i := 0; i < query.Len(); i++ {
-			res := types.Compare(value, must.NotFail(query.Get(i)))
+			res := c.Compare(value, must.NotFail(query.Get(i)))
 			if res != types.Equal {
 				return false, nil
 			}
@@ -1424,15 +1425,19 @@ func filterFieldValueByTypeCode(fieldValue any, code handlerparams.TypeCode) (bo
 		if _, ok := fieldValue.(int64); !ok {
 			return false, nil
 		}
+	case handlerparams.TypeCodeDecimal:
+		if _, ok := fieldValue.(types.Decimal128); !ok {
+			return false, nil
+		}
 	case handlerparams.TypeCodeNumber:
-		// TypeCodeNumber should match int32, int64 and float64 types
+		// TypeCodeNumber should match int32, int64, float64 and Decimal128 types
 		switch fieldValue.(type) {
-		case float64, int32, int64:
+		case float64, int32, int64, types.Decimal128:
 			return true, nil
 		default:
 			return false, nil
 		}
-	case handlerparams.TypeCodeDecimal, handlerparams.TypeCodeMinKey, handlerparams.TypeCodeMaxKey:
+	case handlerparams.TypeCodeMinKey, handlerparams.TypeCodeMaxKey:
 		return false, handlererrors.NewCommandErrorMsgWithArgument(
 			handlererrors.ErrNotImplemented,
 			fmt.Sprintf(`Type code %v not implemented`, code),
@@ -1451,7 +1456,7 @@ func filterFieldValueByTypeCode(fieldValue any, code handlerparams.TypeCode) (bo
 
 // filterFieldExprElemMatch handles {field: {$elemMatch: value}}.
 // Returns false if doc value is not an array.
-func filterFieldExprElemMatch(doc *types.Document, filterKey, filterSuffix string, exprValue any) (bool, error) {
+func filterFieldExprElemMatch(doc *types.Document, filterKey, filterSuffix string, exprValue any, c *types.Collation) (bool, error) { //nolint:lll // for readability
 	expr, ok := exprValue.(*types.Document)
 	if !ok {
 		return false, handlererrors.NewCommandErrorMsgWithArgument(
@@ -1462,7 +1467,7 @@ func filterFieldExprElemMatch(doc *types.Document, filterKey, filterSuffix strin
 	}
 
 	for _, key := range expr.Keys() {
-		if slices.Contains([]string{"$text", "$where"}, key) {
+		if slices.Contains([]string{"$expr", "$text", "$where"}, key) {
 			return false, handlererrors.NewCommandErrorMsgWithArgument(
 				handlererrors.ErrBadValue,
 				fmt.Sprintf("%s can only be applied to the top-level document", key),
@@ -1506,5 +1511,5 @@ func filterFieldExprElemMatch(doc *types.Document, filterKey, filterSuffix strin
 		return false, nil
 	}
 
-	return filterFieldExpr(doc, filterKey, filterSuffix, expr)
+	return filterFieldExpr(doc, filterKey, filterSuffix, expr, c)
 }
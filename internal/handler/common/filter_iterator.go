@@ -21,15 +21,17 @@ import (
 )
 
 // FilterIterator returns an iterator that filters out documents that don't match the filter.
+// Strings are compared according to the given collation (that may be nil).
 // It will be added to the given closer.
 //
 // Next method returns the next document that matches the filter.
 //
 // Close method closes the underlying iterator.
-func FilterIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, filter *types.Document) types.DocumentsIterator {
+func FilterIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, filter *types.Document, c *types.Collation) types.DocumentsIterator { //nolint:lll // for readability
 	res := &filterIterator{
 		iter:   iter,
 		filter: filter,
+		c:      c,
 	}
 	closer.Add(res)
 
@@ -40,6 +42,7 @@ func FilterIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser,
 type filterIterator struct {
 	iter   types.DocumentsIterator
 	filter *types.Document
+	c      *types.Collation
 }
 
 // Next implements iterator.Interface. See FilterIterator for details.
@@ -52,7 +55,7 @@ func (iter *filterIterator) Next() (struct{}, *types.Document, error) {
 			return unused, nil, lazyerrors.Error(err)
 		}
 
-		matches, err := FilterDocument(doc, iter.filter)
+		matches, err := FilterDocument(doc, iter.filter, iter.c)
 		if err != nil {
 			return unused, nil, lazyerrors.Error(err)
 		}
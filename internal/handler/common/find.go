@@ -40,16 +40,24 @@ type FindParams struct {
 	ShowRecordId bool            `ferretdb:"showRecordId,opt"`
 	Tailable     bool            `ferretdb:"tailable,opt"`
 	AwaitData    bool            `ferretdb:"awaitData,opt"`
+	LSID         *types.Document `ferretdb:"lsid,opt"`
+	Hint         any             `ferretdb:"hint,opt"`
 
-	Collation *types.Document `ferretdb:"collation,unimplemented"`
-	Let       *types.Document `ferretdb:"let,unimplemented"`
+	CollationDoc *types.Document `ferretdb:"collation,opt"`
+	Let          *types.Document `ferretdb:"let,unimplemented"`
+
+	Collation *types.Collation `ferretdb:"-"`
+
+	// Key pattern of the index chosen by the query planner for {$meta: "indexKey"} projections;
+	// nil for a collection scan.
+	IndexKey *types.Document `ferretdb:"-"`
+
+	// Like MongoDB's allowDiskUseByDefault, disk use is allowed unless explicitly disabled.
+	AllowDiskUse bool `ferretdb:"allowDiskUse,opt"`
 
-	AllowDiskUse     bool            `ferretdb:"allowDiskUse,ignored"`
 	ReadConcern      *types.Document `ferretdb:"readConcern,ignored"`
 	Max              *types.Document `ferretdb:"max,ignored"`
 	Min              *types.Document `ferretdb:"min,ignored"`
-	Hint             any             `ferretdb:"hint,ignored"`
-	LSID             any             `ferretdb:"lsid,ignored"`
 	TxnNumber        int64           `ferretdb:"txnNumber,ignored"`
 	StartTransaction bool            `ferretdb:"startTransaction,ignored"`
 	Autocommit       bool            `ferretdb:"autocommit,ignored"`
@@ -67,7 +75,8 @@ type FindParams struct {
 // GetFindParams returns `find` command parameters.
 func GetFindParams(doc *types.Document, l *zap.Logger) (*FindParams, error) {
 	params := FindParams{
-		BatchSize: 101,
+		BatchSize:    101,
+		AllowDiskUse: true,
 	}
 
 	if err := handlerparams.ExtractParams(doc, "find", &params, l); err != nil {
@@ -82,5 +91,10 @@ func GetFindParams(doc *types.Document, l *zap.Logger) (*FindParams, error) {
 		)
 	}
 
+	var err error
+	if params.Collation, err = GetCollation("find", params.CollationDoc); err != nil {
+		return nil, err
+	}
+
 	return &params, nil
 }
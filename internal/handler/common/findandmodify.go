@@ -44,8 +44,10 @@ type FindAndModifyParams struct {
 
 	HasUpdateOperators bool `ferretdb:"-"`
 
+	CollationDoc *types.Document  `ferretdb:"collation,opt"`
+	Collation    *types.Collation `ferretdb:"-"`
+
 	Let          *types.Document `ferretdb:"let,unimplemented"`
-	Collation    *types.Document `ferretdb:"collation,unimplemented"`
 	Fields       *types.Document `ferretdb:"fields,unimplemented"`
 	ArrayFilters *types.Array    `ferretdb:"arrayFilters,unimplemented"`
 
@@ -88,6 +90,10 @@ func GetFindAndModifyParams(doc *types.Document, l *zap.Logger) (*FindAndModifyP
 		)
 	}
 
+	if params.Collation, err = GetCollation("findAndModify", params.CollationDoc); err != nil {
+		return nil, err
+	}
+
 	if params.UpdateValue != nil {
 		switch updateParam := params.UpdateValue.(type) {
 		case *types.Document:
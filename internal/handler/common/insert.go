@@ -34,7 +34,7 @@ type InsertParams struct {
 	Collection string       `ferretdb:"insert,collection"`
 	Ordered    bool         `ferretdb:"ordered,opt"`
 
-	MaxTimeMS                int64  `ferretdb:"maxTimeMS,ignored"`
+	MaxTimeMS                int64  `ferretdb:"maxTimeMS,opt,wholePositiveNumber"`
 	WriteConcern             any    `ferretdb:"writeConcern,ignored"`
 	BypassDocumentValidation bool   `ferretdb:"bypassDocumentValidation,ignored"`
 	Comment                  string `ferretdb:"comment,ignored"`
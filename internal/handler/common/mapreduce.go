@@ -0,0 +1,367 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// MapReduceParams represents `mapReduce` command parameters.
+//
+// There is no JavaScript engine, so map, reduce, and finalize functions should be
+// aggregation expressions instead:
+//   - map is a document `{key: <expression>, value: <expression>}` evaluated against each input document;
+//     that is an equivalent of a single `emit(key, value)` call;
+//   - reduce is evaluated against `{key: <key>, values: [<value>, ...]}` document
+//     for each key with more than one emitted value;
+//   - finalize is evaluated against `{key: <key>, value: <value>}` document for each key.
+//
+// For example, `{$sum: "$values"}` reduces emitted values to their sum.
+//
+//nolint:vet // for readability
+type MapReduceParams struct {
+	DB         string          `ferretdb:"$db"`
+	Collection string          `ferretdb:"mapReduce,collection"`
+	MapExpr    any             `ferretdb:"map"`
+	ReduceExpr any             `ferretdb:"reduce"`
+	FinalExpr  any             `ferretdb:"finalize,opt"`
+	OutValue   any             `ferretdb:"out"`
+	Filter     *types.Document `ferretdb:"query,opt"`
+	Sort       *types.Document `ferretdb:"sort,opt"`
+	Limit      int64           `ferretdb:"limit,opt,positiveNumber"`
+	Comment    string          `ferretdb:"comment,opt"`
+
+	CollationDoc *types.Document `ferretdb:"collation,opt"`
+
+	Map       operators.Operator `ferretdb:"-"`
+	Reduce    operators.Operator `ferretdb:"-"`
+	Finalize  operators.Operator `ferretdb:"-"` // nil if not set
+	Out       *MapReduceOut      `ferretdb:"-"`
+	Collation *types.Collation   `ferretdb:"-"`
+
+	Scope *types.Document `ferretdb:"scope,unimplemented"`
+
+	JSMode                   bool            `ferretdb:"jsMode,ignored"`
+	Verbose                  bool            `ferretdb:"verbose,ignored"`
+	BypassDocumentValidation bool            `ferretdb:"bypassDocumentValidation,ignored"`
+	MaxTimeMS                int64           `ferretdb:"maxTimeMS,opt,wholePositiveNumber"`
+	WriteConcern             *types.Document `ferretdb:"writeConcern,ignored"`
+	ReadConcern              *types.Document `ferretdb:"readConcern,ignored"`
+	LSID                     any             `ferretdb:"lsid,ignored"`
+	ClusterTime              any             `ferretdb:"$clusterTime,ignored"`
+	ReadPreference           *types.Document `ferretdb:"$readPreference,ignored"`
+}
+
+// MapReduceOut represents `out` parameter of `mapReduce` command.
+type MapReduceOut struct {
+	// Inline is true if results are returned in the command reply.
+	// Other fields are not set in that case.
+	Inline bool
+
+	// Action is "replace", "merge", or "reduce".
+	Action string
+
+	// DB is the output database name; empty for the database of the command.
+	DB         string
+	Collection string
+}
+
+// GetMapReduceParams returns `mapReduce` command parameters.
+func GetMapReduceParams(document *types.Document, l *zap.Logger) (*MapReduceParams, error) {
+	var params MapReduceParams
+
+	err := handlerparams.ExtractParams(document, "mapReduce", &params, l)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Map, err = mapReduceExpression("map", params.MapExpr); err != nil {
+		return nil, err
+	}
+
+	mapDoc, ok := params.MapExpr.(*types.Document)
+	if !ok || mapDoc.Len() != 2 || !mapDoc.Has("key") || !mapDoc.Has("value") {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrFailedToParse,
+			"'map' expression must be a document with 'key' and 'value' fields",
+			"mapReduce",
+		)
+	}
+
+	if params.Reduce, err = mapReduceExpression("reduce", params.ReduceExpr); err != nil {
+		return nil, err
+	}
+
+	if params.FinalExpr != nil {
+		if params.Finalize, err = mapReduceExpression("finalize", params.FinalExpr); err != nil {
+			return nil, err
+		}
+	}
+
+	if params.Out, err = getMapReduceOut(params.OutValue); err != nil {
+		return nil, err
+	}
+
+	if params.Sort, err = ValidateSortDocument(params.Sort); err != nil {
+		return nil, err
+	}
+
+	if params.Collation, err = GetCollation("mapReduce", params.CollationDoc); err != nil {
+		return nil, err
+	}
+
+	return &params, nil
+}
+
+// mapReduceExpression returns an operator that evaluates the given map, reduce, or finalize expression.
+func mapReduceExpression(field string, expr any) (operators.Operator, error) {
+	switch expr.(type) {
+	case *types.Document:
+		return operators.NewExpr(must.NotFail(types.NewDocument("$expr", expr)), "mapReduce")
+
+	case string:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrNotImplemented,
+			fmt.Sprintf("mapReduce with JavaScript '%s' function is not implemented yet; use an aggregation expression", field),
+			"mapReduce",
+		)
+
+	default:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field 'mapReduce.%s' is the wrong type '%s', expected types '[object, string]'",
+				field, handlerparams.AliasFromType(expr),
+			),
+			"mapReduce",
+		)
+	}
+}
+
+// getMapReduceOut returns the output of `mapReduce` command for the given `out` parameter.
+func getMapReduceOut(v any) (*MapReduceOut, error) {
+	switch v := v.(type) {
+	case *types.Document:
+		if v.Has("inline") {
+			if v.Len() != 1 {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrInvalidOptions,
+					"If 'inline' is specified in 'out', no other options are allowed",
+					"mapReduce",
+				)
+			}
+
+			return &MapReduceOut{Inline: true}, nil
+		}
+
+		var res MapReduceOut
+
+		for _, k := range v.Keys() {
+			val := must.NotFail(v.Get(k))
+
+			switch k {
+			case "replace", "merge", "reduce":
+				if res.Action != "" {
+					return nil, handlererrors.NewCommandErrorMsgWithArgument(
+						handlererrors.ErrInvalidOptions,
+						"'out' must specify exactly one of 'replace', 'merge', or 'reduce'",
+						"mapReduce",
+					)
+				}
+
+				s, ok := val.(string)
+				if !ok || s == "" {
+					return nil, handlererrors.NewCommandErrorMsgWithArgument(
+						handlererrors.ErrTypeMismatch,
+						fmt.Sprintf("'out.%s' must be a non-empty string", k),
+						"mapReduce",
+					)
+				}
+
+				res.Action, res.Collection = k, s
+
+			case "db":
+				s, ok := val.(string)
+				if !ok || s == "" {
+					return nil, handlererrors.NewCommandErrorMsgWithArgument(
+						handlererrors.ErrTypeMismatch,
+						"'out.db' must be a non-empty string",
+						"mapReduce",
+					)
+				}
+
+				res.DB = s
+
+			case "nonAtomic":
+				// all writes are done at once after processing
+
+			case "sharded":
+				if sharded, _ := val.(bool); sharded {
+					return nil, handlererrors.NewCommandErrorMsgWithArgument(
+						handlererrors.ErrNotImplemented,
+						"mapReduce with 'out.sharded' is not implemented yet",
+						"mapReduce",
+					)
+				}
+
+			default:
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrFailedToParse,
+					fmt.Sprintf("Unknown 'out' option: %s", k),
+					"mapReduce",
+				)
+			}
+		}
+
+		if res.Action == "" {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParse,
+				"'out' must specify one of 'inline', 'replace', 'merge', or 'reduce'",
+				"mapReduce",
+			)
+		}
+
+		return &res, nil
+
+	case string:
+		return &MapReduceOut{Action: "replace", Collection: v}, nil
+
+	default:
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrTypeMismatch,
+			fmt.Sprintf("'out' must be a string or an object, not %s", handlerparams.AliasFromType(v)),
+			"mapReduce",
+		)
+	}
+}
+
+// MapReduce applies map and reduce expressions to the given documents.
+//
+// It returns `{_id: <key>, value: <value>}` documents sorted by keys.
+// Keys are compared using the given collation.
+// Finalize expression is not applied, see [FinalizeMapReduce].
+func MapReduce(iter types.DocumentsIterator, params *MapReduceParams) ([]*types.Document, error) {
+	defer iter.Close()
+
+	type emit struct {
+		key   any
+		value any
+	}
+
+	var emits []emit
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		v, err := params.Map.Process(doc)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res := v.(*types.Document)
+		emits = append(emits, emit{key: must.NotFail(res.Get("key")), value: must.NotFail(res.Get("value"))})
+	}
+
+	compare := compareFunc(params.Collation)
+
+	// stable sort keeps values of the same key in the emit order
+	slices.SortStableFunc(emits, func(a, b emit) int {
+		return compare(a.key, b.key)
+	})
+
+	var res []*types.Document
+
+	for start := 0; start < len(emits); {
+		end := start + 1
+		for end < len(emits) && compare(emits[start].key, emits[end].key) == 0 {
+			end++
+		}
+
+		values := make([]any, end-start)
+		for i, e := range emits[start:end] {
+			values[i] = e.value
+		}
+
+		value, err := ReduceMapReduce(params, emits[start].key, values)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, must.NotFail(types.NewDocument("_id", emits[start].key, "value", value)))
+
+		start = end
+	}
+
+	return res, nil
+}
+
+// ReduceMapReduce applies reduce expression to the given values of the key.
+//
+// Like in MongoDB, it is not applied to a single value.
+func ReduceMapReduce(params *MapReduceParams, key any, values []any) (any, error) {
+	if len(values) == 1 {
+		return values[0], nil
+	}
+
+	arr := types.MakeArray(len(values))
+	for _, v := range values {
+		arr.Append(v)
+	}
+
+	v, err := params.Reduce.Process(must.NotFail(types.NewDocument("key", key, "values", arr)))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return v, nil
+}
+
+// FinalizeMapReduce applies finalize expression, if any, to the value of the given result document in place.
+func FinalizeMapReduce(params *MapReduceParams, doc *types.Document) error {
+	if params.Finalize == nil {
+		return nil
+	}
+
+	v, err := params.Finalize.Process(must.NotFail(types.NewDocument(
+		"key", must.NotFail(doc.Get("_id")),
+		"value", must.NotFail(doc.Get("value")),
+	)))
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	doc.Set("value", v)
+
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Supported $meta keywords.
+const (
+	metaIndexKey  = "indexKey"
+	metaRecordID  = "recordId"
+	metaTextScore = "textScore"
+)
+
+// unsupportedMetaKeywords contains $meta keywords that are valid for MongoDB but not supported yet.
+var unsupportedMetaKeywords = map[string]struct{}{
+	"geoNearDistance":    {},
+	"geoNearPoint":       {},
+	"randVal":            {},
+	"searchHighlights":   {},
+	"searchScore":        {},
+	"searchScoreDetails": {},
+	"sortKey":            {},
+	"vectorSearchScore":  {},
+}
+
+// isMeta returns true if the given value is {$meta: <keyword>} expression.
+func isMeta(value any) bool {
+	doc, ok := value.(*types.Document)
+	return ok && doc.Len() == 1 && doc.Has("$meta")
+}
+
+// validateMeta validates {$meta: <keyword>} expression and returns the keyword.
+//
+// Command error codes:
+//   - ErrProjectionMetaNotString when keyword is not a string;
+//   - ErrProjectionMetaInvalid when keyword is unknown;
+//   - ErrNotImplemented when keyword is not supported yet.
+func validateMeta(meta *types.Document) (string, error) {
+	v := must.NotFail(meta.Get("$meta"))
+
+	keyword, ok := v.(string)
+	if !ok {
+		return "", handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrProjectionMetaNotString,
+			"$meta only supports string arguments",
+			"$meta",
+		)
+	}
+
+	switch keyword {
+	case metaIndexKey, metaRecordID, metaTextScore:
+		return keyword, nil
+	}
+
+	if _, ok = unsupportedMetaKeywords[keyword]; ok {
+		return "", handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrNotImplemented,
+			fmt.Sprintf("$meta keyword %q is not implemented yet", keyword),
+			"$meta",
+		)
+	}
+
+	return "", handlererrors.NewCommandErrorMsgWithArgument(
+		handlererrors.ErrProjectionMetaInvalid,
+		fmt.Sprintf("Unsupported argument to $meta: %s", keyword),
+		"$meta",
+	)
+}
+
+// noTextScoreError returns an error for text score metadata requested for a query without $text.
+//
+// $text query operator is not supported yet, so text score metadata is never available.
+func noTextScoreError() error {
+	return handlererrors.NewCommandErrorMsgWithArgument(
+		handlererrors.ErrNoTextScoreMetadata,
+		"query requires text score metadata, but it is not available",
+		"$meta",
+	)
+}
+
+// metaValue returns the value of $meta keyword for the given document,
+// or nil if it is not available.
+//
+// indexKey is the key pattern of the index used by the query; nil for a collection scan.
+func metaValue(keyword string, doc, indexKey *types.Document) (any, error) {
+	switch keyword {
+	case metaIndexKey:
+		if indexKey == nil {
+			return nil, nil
+		}
+
+		res := types.MakeDocument(indexKey.Len())
+
+		for _, field := range indexKey.Keys() {
+			path, err := types.NewPathFromString(field)
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			v, err := doc.GetByPath(path)
+			if err != nil {
+				v = types.Null
+			}
+
+			res.Set(field, v)
+		}
+
+		return res, nil
+
+	case metaRecordID:
+		if id := doc.RecordID(); id != 0 {
+			return id, nil
+		}
+
+		return nil, nil
+
+	case metaTextScore:
+		return nil, noTextScoreError()
+
+	default:
+		panic(fmt.Sprintf("unexpected $meta keyword %q", keyword))
+	}
+}
@@ -317,8 +317,9 @@ func getBinaryMaskParam(operator string, mask any) (uint64, error) {
 }
 
 // addNumbers returns the result of v1 and v2 addition and error if addition failed.
-// The v1 and v2 parameters could be float64, int32, int64.
-// The result would be the broader type possible, i.e. int32 + int64 produces int64.
+// The v1 and v2 parameters could be float64, int32, int64, Decimal128.
+// The result would be the broader type possible, i.e. int32 + int64 produces int64,
+// and any number + Decimal128 produces Decimal128.
 func addNumbers(v1, v2 any) (any, error) {
 	switch v1 := v1.(type) {
 	case float64:
@@ -329,6 +330,8 @@ func addNumbers(v1, v2 any) (any, error) {
 			return v1 + float64(v2), nil
 		case int64:
 			return v1 + float64(v2), nil
+		case types.Decimal128:
+			return decimal128FromNumber(v1).Add(v2), nil
 		default:
 			return nil, handlerparams.ErrUnexpectedRightOpType
 		}
@@ -358,6 +361,8 @@ func addNumbers(v1, v2 any) (any, error) {
 			}
 
 			return v2 + int64(v1), nil
+		case types.Decimal128:
+			return decimal128FromNumber(v1).Add(v2), nil
 		default:
 			return nil, handlerparams.ErrUnexpectedRightOpType
 		}
@@ -389,19 +394,27 @@ func addNumbers(v1, v2 any) (any, error) {
 			}
 
 			return v1 + v2, nil
+		case types.Decimal128:
+			return decimal128FromNumber(v1).Add(v2), nil
 		default:
 			return nil, handlerparams.ErrUnexpectedRightOpType
 		}
+	case types.Decimal128:
+		if !isNumber(v2) {
+			return nil, handlerparams.ErrUnexpectedRightOpType
+		}
+
+		return v1.Add(decimal128FromNumber(v2)), nil
 	default:
 		return nil, handlerparams.ErrUnexpectedLeftOpType
 	}
 }
 
 // multiplyNumbers returns the multiplication of v1 and v2.
-// The v1 and v2 parameters could be float64, int32 and int64.
+// The v1 and v2 parameters could be float64, int32, int64 and Decimal128.
 // Multiplication of negative number with zero produces 0, not -0.
 // The produced result maybe be the broader type:
-// int32 * int64 produces int64.
+// int32 * int64 produces int64, any number * Decimal128 produces Decimal128.
 func multiplyNumbers(v1, v2 any) (any, error) {
 	switch v1 := v1.(type) {
 	case float64:
@@ -414,6 +427,8 @@ func multiplyNumbers(v1, v2 any) (any, error) {
 			res = v1 * float64(v2)
 		case int64:
 			res = v1 * float64(v2)
+		case types.Decimal128:
+			return decimal128FromNumber(v1).Mul(v2), nil
 		default:
 			return nil, handlerparams.ErrUnexpectedRightOpType
 		}
@@ -433,6 +448,8 @@ func multiplyNumbers(v1, v2 any) (any, error) {
 		case int64:
 			return multiplyLongSafely(int64(v1), v2)
 
+		case types.Decimal128:
+			return decimal128FromNumber(v1).Mul(v2), nil
 		default:
 			return nil, handlerparams.ErrUnexpectedRightOpType
 		}
@@ -450,14 +467,50 @@ func multiplyNumbers(v1, v2 any) (any, error) {
 		case int64:
 			return multiplyLongSafely(v1, v2)
 
+		case types.Decimal128:
+			return decimal128FromNumber(v1).Mul(v2), nil
 		default:
 			return nil, handlerparams.ErrUnexpectedRightOpType
 		}
+	case types.Decimal128:
+		if !isNumber(v2) {
+			return nil, handlerparams.ErrUnexpectedRightOpType
+		}
+
+		return v1.Mul(decimal128FromNumber(v2)), nil
 	default:
 		return nil, handlerparams.ErrUnexpectedLeftOpType
 	}
 }
 
+// isNumber returns true if v is a BSON number.
+func isNumber(v any) bool {
+	switch v.(type) {
+	case float64, int32, int64, types.Decimal128:
+		return true
+	default:
+		return false
+	}
+}
+
+// decimal128FromNumber converts BSON number to Decimal128 like MongoDB does for mixed-type arithmetic.
+//
+// It panics if v is not a number.
+func decimal128FromNumber(v any) types.Decimal128 {
+	switch v := v.(type) {
+	case float64:
+		return types.NewDecimal128FromFloat64(v)
+	case int32:
+		return types.NewDecimal128FromInt64(int64(v))
+	case int64:
+		return types.NewDecimal128FromInt64(v)
+	case types.Decimal128:
+		return v
+	default:
+		panic(fmt.Sprintf("decimal128FromNumber: unexpected type %T", v))
+	}
+}
+
 // multiplyLongSafely returns the multiplication of two int64 values.
 // It handles int64 overflows, and returns errLongExceeded error on one.
 //
@@ -570,6 +623,7 @@ func performBitLogic(bitOp string, v1, v2 any) (any, error) {
 		}
 
 	default:
+		//checkerrors:ignore // message is returned to the client
 		return nil, fmt.Errorf(
 			`The $bit modifier only supports 'and', 'or', and 'xor', not '%s'`+
 				` which is an unknown operator: {%s: %v}`,
@@ -46,7 +46,10 @@ import (
 //   - `ErrBadPositionalProjection` when array or filter at positional projection path is empty;
 //   - `ErrBadPositionalProjection` when there is no filter field key for positional projection path;
 //   - `ErrElementMismatchPositionalProjection` when unexpected array was found on positional projection path;
-//   - `ErrNotImplemented` when there is unimplemented projection operators and expressions.
+//   - `ErrNotImplemented` when there is unimplemented projection operators and expressions;
+//   - `ErrProjectionMetaNotString`, `ErrProjectionMetaInvalid` for invalid `$meta` expressions;
+//   - `ErrSliceProjectionInvalid` and other `ErrSliceProjection*` codes for invalid `$slice` arguments;
+//   - `ErrBadValue` for invalid `$elemMatch` projection or when it is used with positional projection.
 func ValidateProjection(projection *types.Document) (*types.Document, bool, error) {
 	validated := types.MakeDocument(0)
 
@@ -56,6 +59,7 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 	}
 
 	var inclusion *bool
+	var positional, elemMatch bool
 
 	iter := projection.Iterator()
 	defer iter.Close()
@@ -147,6 +151,41 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 
 		switch value := value.(type) {
 		case *types.Document:
+			if isMeta(value) {
+				if _, err = validateMeta(value); err != nil {
+					return nil, false, err
+				}
+
+				// $meta fields are added to both inclusion and exclusion projections
+				validated.Set(key, value)
+
+				continue
+			}
+
+			if isProjectionOperator(value, "$slice") {
+				if _, _, err = parseSliceProjection(must.NotFail(value.Get("$slice"))); err != nil {
+					return nil, false, err
+				}
+
+				// $slice fields are added to both inclusion and exclusion projections
+				validated.Set(key, value)
+
+				continue
+			}
+
+			if isProjectionOperator(value, "$elemMatch") {
+				if err = validateElemMatchProjection(key, value); err != nil {
+					return nil, false, err
+				}
+
+				elemMatch = true
+				inclusionField = true
+
+				validated.Set(key, value)
+
+				break
+			}
+
 			return nil, false, handlererrors.NewCommandErrorMsg(
 				handlererrors.ErrNotImplemented,
 				fmt.Sprintf("projection expression %s is not supported", types.FormatAnyValue(value)),
@@ -156,7 +195,7 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 			inclusionField = true
 
 			validated.Set(key, value)
-		case float64, int32, int64:
+		case float64, int32, int64, types.Decimal128:
 			// projection treats 0 as false and any other value as true
 			comparison := types.Compare(value, int32(0))
 
@@ -179,6 +218,18 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 			return validated, inclusionField, nil
 		}
 
+		if positionalProjection {
+			positional = true
+		}
+
+		if positional && elemMatch {
+			return nil, false, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrBadValue,
+				"Cannot specify positional operator and $elemMatch.",
+				"projection",
+			)
+		}
+
 		if !inclusionField && positionalProjection {
 			return nil, false, handlererrors.NewCommandErrorMsgWithArgument(
 				handlererrors.ErrExclusionPositionalProjection,
@@ -218,6 +269,14 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 		}
 	}
 
+	if inclusion == nil {
+		// only _id and $meta fields
+		v, _ := validated.Get("_id")
+		b, isBool := v.(bool)
+
+		return validated, v != nil && (b || !isBool), nil
+	}
+
 	return validated, *inclusion, nil
 }
 
@@ -315,13 +374,37 @@ func projectDocumentWithoutID(doc *types.Document, projection, filter *types.Doc
 		}
 
 		switch value := value.(type) { // found in the projection
-		case *types.Document: // field: { $elemMatch: { field2: value }}
-			return nil, handlererrors.NewCommandErrorMsg(
-				handlererrors.ErrCommandNotFound,
-				fmt.Sprintf("projection %s is not supported",
-					types.FormatAnyValue(value),
-				),
-			)
+		case *types.Document:
+			switch {
+			case isProjectionOperator(value, "$slice"): // field: {$slice: n} or {$slice: [skip, limit]}
+				var skip *int
+				var limit int
+
+				if skip, limit, err = parseSliceProjection(must.NotFail(value.Get("$slice"))); err != nil {
+					return nil, err
+				}
+
+				if inclusion {
+					if _, err = includeProjection(path, 0, docWithoutID, projected, filter); err != nil {
+						return nil, err
+					}
+				}
+
+				sliceProjection(path, projected, skip, limit)
+
+			case isProjectionOperator(value, "$elemMatch"): // field: {$elemMatch: {field2: value}}
+				if err = elemMatchProjection(key, value, docWithoutID, projected); err != nil {
+					return nil, err
+				}
+
+			default:
+				return nil, handlererrors.NewCommandErrorMsg(
+					handlererrors.ErrCommandNotFound,
+					fmt.Sprintf("projection %s is not supported",
+						types.FormatAnyValue(value),
+					),
+				)
+			}
 
 		case *types.Array, string, types.Binary, types.ObjectID,
 			time.Time, types.NullType, types.Regex, types.Timestamp: // all these types are treated as new fields value
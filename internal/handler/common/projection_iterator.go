@@ -18,24 +18,48 @@ import (
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 // ProjectionIterator returns an iterator that projects documents returned by the underlying iterator.
 // It will be added to the given closer.
 //
+// indexKey is the key pattern of the index used by the query for {$meta: "indexKey"} projections;
+// nil for a collection scan.
+//
 // Next method returns the next projected document.
 //
 // Close method closes the underlying iterator.
-func ProjectionIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, projection, filter *types.Document) (types.DocumentsIterator, error) { //nolint:lll // for readability
+func ProjectionIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, projection, filter, indexKey *types.Document) (types.DocumentsIterator, error) { //nolint:lll // for readability
 	projectionValidated, inclusion, err := ValidateProjection(projection)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
+	// $meta fields are added after projection
+	meta := types.MakeDocument(0)
+
+	for _, k := range projectionValidated.Keys() {
+		v := must.NotFail(projectionValidated.Get(k))
+		if !isMeta(v) {
+			continue
+		}
+
+		keyword := must.NotFail(v.(*types.Document).Get("$meta")).(string)
+		if keyword == metaTextScore && (filter == nil || !filter.Has("$text")) {
+			return nil, noTextScoreError()
+		}
+
+		meta.Set(k, keyword)
+		projectionValidated.Remove(k)
+	}
+
 	res := &projectionIterator{
 		iter:       iter,
 		projection: projectionValidated,
 		filter:     filter,
+		meta:       meta,
+		indexKey:   indexKey,
 		inclusion:  inclusion,
 	}
 	closer.Add(res)
@@ -48,6 +72,8 @@ type projectionIterator struct {
 	iter       types.DocumentsIterator
 	projection *types.Document
 	filter     *types.Document // filter is used by positional operator to get first matching array element.
+	meta       *types.Document // maps field paths to $meta keywords
+	indexKey   *types.Document
 	inclusion  bool
 }
 
@@ -65,6 +91,21 @@ func (iter *projectionIterator) Next() (struct{}, *types.Document, error) {
 		return unused, nil, lazyerrors.Error(err)
 	}
 
+	for _, k := range iter.meta.Keys() {
+		var v any
+		if v, err = metaValue(must.NotFail(iter.meta.Get(k)).(string), doc, iter.indexKey); err != nil {
+			return unused, nil, lazyerrors.Error(err)
+		}
+
+		if v == nil {
+			continue
+		}
+
+		if err = projected.SetByPath(must.NotFail(types.NewPathFromString(k)), v); err != nil {
+			return unused, nil, lazyerrors.Error(err)
+		}
+	}
+
 	return unused, projected, nil
 }
 
@@ -0,0 +1,279 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// isProjectionOperator returns true if the given value is {<operator>: <argument>} projection
+// with the given operator.
+func isProjectionOperator(value any, operator string) bool {
+	doc, ok := value.(*types.Document)
+	return ok && doc.Len() == 1 && doc.Has(operator)
+}
+
+// sliceProjectionNumber converts $slice projection argument to int.
+// Fractional part is discarded, and out of range values are clamped.
+func sliceProjectionNumber(v any) (int, bool) {
+	switch v := v.(type) {
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return 0, true
+		case v > math.MaxInt32:
+			return math.MaxInt32, true
+		case v < math.MinInt32:
+			return math.MinInt32, true
+		default:
+			return int(v), true
+		}
+	case int32:
+		return int(v), true
+	case int64:
+		switch {
+		case v > math.MaxInt32:
+			return math.MaxInt32, true
+		case v < math.MinInt32:
+			return math.MinInt32, true
+		default:
+			return int(v), true
+		}
+	default:
+		return 0, false
+	}
+}
+
+// parseSliceProjection parses {$slice: <n>} and {$slice: [<skip>, <limit>]} projection argument.
+// For the first form, skip is nil.
+//
+// Command error codes:
+//   - ErrSliceProjectionInvalid when array argument does not have two elements;
+//   - ErrSliceProjectionSkipNotNumber when skip is not a number;
+//   - ErrSliceProjectionLimitNotNumber when limit is not a number;
+//   - ErrSliceProjectionLimitNotPositive when limit is not positive;
+//   - ErrNotImplemented when argument is neither a number nor an array.
+func parseSliceProjection(arg any) (skip *int, limit int, err error) {
+	if n, ok := sliceProjectionNumber(arg); ok {
+		return nil, n, nil
+	}
+
+	arr, ok := arg.(*types.Array)
+	if !ok {
+		// MongoDB treats it as $slice aggregation expression
+		return nil, 0, handlererrors.NewCommandErrorMsg(
+			handlererrors.ErrNotImplemented,
+			fmt.Sprintf("projection expression %s is not supported", types.FormatAnyValue(arg)),
+		)
+	}
+
+	if arr.Len() != 2 {
+		return nil, 0, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSliceProjectionInvalid,
+			"$slice array argument should be of form [skip, limit]",
+			"projection",
+		)
+	}
+
+	skipValue := must.NotFail(arr.Get(0))
+
+	s, ok := sliceProjectionNumber(skipValue)
+	if !ok {
+		return nil, 0, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSliceProjectionSkipNotNumber,
+			fmt.Sprintf("$slice expects the first argument to be a number, got %s", handlerparams.AliasFromType(skipValue)),
+			"projection",
+		)
+	}
+
+	limitValue := must.NotFail(arr.Get(1))
+
+	if limit, ok = sliceProjectionNumber(limitValue); !ok {
+		return nil, 0, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSliceProjectionLimitNotNumber,
+			fmt.Sprintf("$slice expects the second argument to be a number, got %s", handlerparams.AliasFromType(limitValue)),
+			"projection",
+		)
+	}
+
+	if limit <= 0 {
+		return nil, 0, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrSliceProjectionLimitNotPositive,
+			fmt.Sprintf("$slice limit must be positive, got %d", limit),
+			"projection",
+		)
+	}
+
+	return &s, limit, nil
+}
+
+// validateElemMatchProjection validates {<field>: {$elemMatch: <condition>}} projection.
+//
+// Command error codes:
+//   - ErrBadValue when field is nested or condition is not a document.
+func validateElemMatchProjection(key string, value *types.Document) error {
+	if strings.Contains(key, ".") {
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrBadValue,
+			"Cannot use $elemMatch projection on a nested field.",
+			"projection",
+		)
+	}
+
+	cond := must.NotFail(value.Get("$elemMatch"))
+	if _, ok := cond.(*types.Document); !ok {
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrBadValue,
+			fmt.Sprintf("elemMatch: Invalid argument, object required, but got %s", handlerparams.AliasFromType(cond)),
+			"projection",
+		)
+	}
+
+	return nil
+}
+
+// sliceArray returns elements of the array selected by $slice projection arguments.
+//
+// Without skip, non-negative limit selects the first elements, and negative limit selects the last elements.
+// Negative skip is counted from the end of the array.
+func sliceArray(arr *types.Array, skip *int, limit int) *types.Array {
+	l := arr.Len()
+
+	var start, end int
+
+	switch {
+	case skip == nil && limit >= 0:
+		start, end = 0, min(limit, l)
+	case skip == nil:
+		start, end = max(l+limit, 0), l
+	case *skip < 0:
+		start = max(l+*skip, 0)
+		end = min(start+limit, l)
+	default:
+		start = min(*skip, l)
+		end = min(start+limit, l)
+	}
+
+	res := types.MakeArray(end - start)
+
+	for i := start; i < end; i++ {
+		res.Append(must.NotFail(arr.Get(i)))
+	}
+
+	return res
+}
+
+// sliceProjection replaces arrays on the path in projected with their slices.
+// When an array of documents is on the path, it is applied to each document.
+// Non-array values are left as is.
+func sliceProjection(path types.Path, projected any, skip *int, limit int) {
+	switch projected := projected.(type) {
+	case *types.Document:
+		key := path.Prefix()
+
+		v, err := projected.Get(key)
+		if err != nil {
+			return
+		}
+
+		if path.Len() > 1 {
+			sliceProjection(path.TrimPrefix(), v, skip, limit)
+			return
+		}
+
+		if arr, ok := v.(*types.Array); ok {
+			projected.Set(key, sliceArray(arr, skip, limit))
+		}
+
+	case *types.Array:
+		for i := 0; i < projected.Len(); i++ {
+			sliceProjection(path, must.NotFail(projected.Get(i)), skip, limit)
+		}
+	}
+}
+
+// elemMatchProjection sets the first element of the source array field that matches $elemMatch condition
+// to projected. The field is not set if there is no such element.
+func elemMatchProjection(key string, value, source, projected *types.Document) error {
+	v, err := source.Get(key)
+	if err != nil {
+		return nil
+	}
+
+	arr, ok := v.(*types.Array)
+	if !ok {
+		return nil
+	}
+
+	cond := must.NotFail(value.Get("$elemMatch")).(*types.Document)
+
+	iter := arr.Iterator()
+	defer iter.Close()
+
+	for {
+		_, elem, err := iter.Next()
+		if err != nil {
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				return nil
+			}
+
+			return lazyerrors.Error(err)
+		}
+
+		matches, err := elemMatches(elem, cond)
+		if err != nil {
+			return err
+		}
+
+		if matches {
+			setBySourceOrder(key, must.NotFail(types.NewArray(elem)), source, projected)
+			return nil
+		}
+	}
+}
+
+// elemMatches returns true if the array element matches $elemMatch condition.
+//
+// Condition with operators like {$gt: 42} is applied to the element itself;
+// other conditions like {field: 42} are applied to document elements as queries.
+func elemMatches(elem any, cond *types.Document) (bool, error) {
+	keys := cond.Keys()
+
+	if len(keys) > 0 && strings.HasPrefix(keys[0], "$") && !slices.Contains([]string{"$and", "$or", "$nor"}, keys[0]) {
+		return FilterDocument(
+			must.NotFail(types.NewDocument("elem", elem)),
+			must.NotFail(types.NewDocument("elem", cond)),
+			nil,
+		)
+	}
+
+	doc, ok := elem.(*types.Document)
+	if !ok {
+		return false, nil
+	}
+
+	return FilterDocument(doc, cond, nil)
+}
@@ -18,8 +18,10 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/FerretDB/FerretDB/internal/bson"
 	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
 	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
 	"github.com/FerretDB/FerretDB/internal/types"
@@ -28,15 +30,36 @@ import (
 )
 
 // SortDocuments sorts given documents in place according to the given sorting conditions.
+// Strings are compared according to the given collation; nil collation compares them as binary.
 //
 // If sort path is invalid, it returns a possibly wrapped types.PathError.
-func SortDocuments(docs []*types.Document, sortDoc *types.Document) error {
-	if sortDoc.Len() == 0 {
+func SortDocuments(docs []*types.Document, sortDoc *types.Document, c *types.Collation) error {
+	sortFuncs, err := getSortFuncs(sortDoc, c)
+	if err != nil {
+		return err
+	}
+
+	if len(sortFuncs) == 0 {
+		// no keys to sort by
 		return nil
 	}
 
+	sorter := &docsSorter{docs: docs, sorts: sortFuncs}
+	sort.Sort(sorter)
+
+	return nil
+}
+
+// getSortFuncs returns functions that compare documents by each key of the given sort document.
+//
+// If sort path is invalid, it returns a possibly wrapped types.PathError.
+func getSortFuncs(sortDoc *types.Document, c *types.Collation) ([]sortFunc, error) {
+	if sortDoc.Len() == 0 {
+		return nil, nil
+	}
+
 	if sortDoc.Len() > 32 {
-		return lazyerrors.Errorf("maximum sort keys exceeded: %v", sortDoc.Len())
+		return nil, lazyerrors.Errorf("maximum sort keys exceeded: %v", sortDoc.Len())
 	}
 
 	sortFuncs := make([]sortFunc, sortDoc.Len())
@@ -50,7 +73,7 @@ func SortDocuments(docs []*types.Document, sortDoc *types.Document) error {
 			// TODO https://github.com/FerretDB/FerretDB/issues/3127
 			for _, field := range fields {
 				if strings.HasPrefix(field, "$") {
-					return handlererrors.NewCommandErrorMsgWithArgument(
+					return nil, handlererrors.NewCommandErrorMsgWithArgument(
 						handlererrors.ErrFieldPathInvalidName,
 						"FieldPath field names may not start with '$'. Consider using $getField or $setField.",
 						"sort",
@@ -63,26 +86,18 @@ func SortDocuments(docs []*types.Document, sortDoc *types.Document) error {
 
 		sortType, err := GetSortType(sortKey, sortField)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		sortPath, err := types.NewPathFromString(sortKey)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		sortFuncs[i] = lessFunc(sortPath, sortType)
+		sortFuncs[i] = lessFunc(sortPath, sortType, c)
 	}
 
-	if len(sortFuncs) == 0 {
-		// no keys to sort by
-		return nil
-	}
-
-	sorter := &docsSorter{docs: docs, sorts: sortFuncs}
-	sort.Sort(sorter)
-
-	return nil
+	return sortFuncs, nil
 }
 
 // ValidateSortDocument validates sort documents, and return
@@ -125,6 +140,23 @@ func ValidateSortDocument(sortDoc *types.Document) (*types.Document, error) {
 
 		sortField := must.NotFail(sortDoc.Get(sortKey))
 
+		if isMeta(sortField) {
+			keyword, err := validateMeta(sortField.(*types.Document))
+			if err != nil {
+				return nil, err
+			}
+
+			if keyword == metaTextScore {
+				return nil, noTextScoreError()
+			}
+
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrSortIllegalMeta,
+				fmt.Sprintf(`Illegal $meta sort: $meta: "%s"`, keyword),
+				"sort",
+			)
+		}
+
 		sortValue, err := getSortValue(sortKey, sortField)
 		if err != nil {
 			return nil, err
@@ -141,26 +173,105 @@ func ValidateSortDocument(sortDoc *types.Document) (*types.Document, error) {
 	return res, nil
 }
 
-// lessFunc takes sort key and type and returns sort.Interface's Less function which
+// lessFunc takes sort key, type and collation and returns sort.Interface's Less function which
 // compares selected key of 2 documents.
-func lessFunc(sortPath types.Path, sortType types.SortType) func(a, b *types.Document) bool {
+func lessFunc(sortPath types.Path, sortType types.SortType, c *types.Collation) func(a, b *types.Document) bool {
 	return func(a, b *types.Document) bool {
-		aField, err := a.GetByPath(sortPath)
-		if err != nil {
-			// sort order treats null and non-existent field equivalent,
-			// hence use null for sorting.
-			aField = types.Null
+		aField := sortKeyValue(a, sortPath, sortType)
+		bField := sortKeyValue(b, sortPath, sortType)
+
+		result := c.CompareOrderForSort(aField, bField, sortType)
+
+		return result == types.Less
+	}
+}
+
+// sortKeyValue returns the value used to sort the document by the given path.
+//
+// Like MongoDB, it collects all values on the path, traversing arrays of documents
+// and expanding arrays at the end of the path.
+// The result is an array of collected values, so the minimum value is used for ascending sort,
+// and the maximum value is used for descending sort.
+// Empty arrays are the lowest in the sort order, so an empty array is returned
+// for ascending sort if any empty array was found on the path.
+// Sort order treats null and non-existent field equivalent, so null is returned if nothing was found.
+//
+// Example: "v.foo" path:
+//
+//	{v: {foo: [1, 2]}}               -> [1, 2]
+//	{v: [{foo: 1}, {foo: [2, 3]}]}   -> [1, 2, 3]
+//	{v: [{foo: 1}, {bar: 2}]}        -> [1]
+//	{v: [{foo: []}, {foo: 1}]}       -> [] for ascending sort, [1] for descending sort
+//	{v: [1, 2]}                      -> null
+func sortKeyValue(doc *types.Document, path types.Path, order types.SortType) any {
+	res := types.MakeArray(0)
+
+	var emptyArray bool
+
+	collectSortKeyValues(doc, path.Slice(), res, &emptyArray)
+
+	switch {
+	case emptyArray && (order == types.Ascending || res.Len() == 0):
+		return types.MakeArray(0)
+	case res.Len() == 0:
+		return types.Null
+	default:
+		return res
+	}
+}
+
+// collectSortKeyValues appends values on the path of the given document or array to res.
+// It sets emptyArray to true if an empty array is at the end of the path.
+func collectSortKeyValues(value any, path []string, res *types.Array, emptyArray *bool) {
+	var field any
+
+	switch value := value.(type) {
+	case *types.Document:
+		var err error
+		if field, err = value.Get(path[0]); err != nil {
+			return
 		}
 
-		bField, err := b.GetByPath(sortPath)
-		if err != nil {
-			// same logic as above
-			bField = types.Null
+	case *types.Array:
+		if index, err := strconv.Atoi(path[0]); err == nil && index >= 0 {
+			if field, err = value.Get(index); err != nil {
+				return
+			}
+
+			break
 		}
 
-		result := types.CompareOrderForSort(aField, bField, sortType)
+		// traverse documents in the array; nested arrays are not traversed
+		for i := 0; i < value.Len(); i++ {
+			if elem, ok := must.NotFail(value.Get(i)).(*types.Document); ok {
+				collectSortKeyValues(elem, path, res, emptyArray)
+			}
+		}
 
-		return result == types.Less
+		return
+
+	default:
+		return
+	}
+
+	if len(path) > 1 {
+		collectSortKeyValues(field, path[1:], res, emptyArray)
+		return
+	}
+
+	arr, ok := field.(*types.Array)
+	if !ok {
+		res.Append(field)
+		return
+	}
+
+	if arr.Len() == 0 {
+		*emptyArray = true
+		return
+	}
+
+	for i := 0; i < arr.Len(); i++ {
+		res.Append(must.NotFail(arr.Get(i)))
 	}
 }
 
@@ -168,6 +279,7 @@ type sortFunc func(a, b *types.Document) bool
 
 type docsSorter struct {
 	docs  []*types.Document
+	raws  []bson.RawDocument // encoded docs swapped together with them, if not nil
 	sorts []sortFunc
 }
 
@@ -177,10 +289,18 @@ func (ds *docsSorter) Len() int {
 
 func (ds *docsSorter) Swap(i, j int) {
 	ds.docs[i], ds.docs[j] = ds.docs[j], ds.docs[i]
+
+	if ds.raws != nil {
+		ds.raws[i], ds.raws[j] = ds.raws[j], ds.raws[i]
+	}
 }
 
 func (ds *docsSorter) Less(i, j int) bool {
-	p, q := ds.docs[i], ds.docs[j]
+	return ds.less(ds.docs[i], ds.docs[j])
+}
+
+// less reports whether p should be sorted before q.
+func (ds *docsSorter) less(p, q *types.Document) bool {
 	// Try all but the last comparison.
 	var k int
 	for k = 0; k < len(ds.sorts)-1; k++ {
@@ -15,33 +15,554 @@
 package common
 
 import (
+	"bufio"
+	"cmp"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
-// SortIterator returns an iterator of sorted documents.
+// DefaultSortMemoryLimit is the default maximum total size of documents in bytes
+// that SortIterator sorts in memory.
+// It is the same as MongoDB's internalQueryMaxBlockingSortMemoryUsageBytes default.
+const DefaultSortMemoryLimit = 100 * 1024 * 1024
+
+// maxSortRuns is the maximum number of sorted runs kept in temporary files.
+//
+// When it is reached, runs are merged into a single one, so the number of open files is limited.
+const maxSortRuns = 64
+
+// SortParams represents parameters of SortIterator.
+type SortParams struct {
+	Sort      *types.Document
+	Collation *types.Collation // nil compares strings as binary
+
+	// Maximum total size of documents sorted in memory, in bytes; 0 for DefaultSortMemoryLimit.
+	MemoryLimit int
+
+	// If true, sorted runs of documents that exceed MemoryLimit are written to temporary files
+	// in TempDir (os.TempDir() if empty) and merged; otherwise, exceeding MemoryLimit is an error.
+	AllowDiskUse bool
+	TempDir      string
+
+	// Number of sorted runs written to temporary files; set by SortIterator.
+	Spills int
+}
+
+// SortIterator returns an iterator of documents sorted according to the given parameters.
 // It will be added to the given closer.
 //
-// Since sorting iterator is impossible, this function fully consumes and closes the underlying iterator,
-// sorts documents in memory and returns a new iterator over the sorted slice.
-func SortIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, sort *types.Document) (types.DocumentsIterator, error) { //nolint:lll // for readability
+// Since sorting iterator is impossible, this function fully consumes and closes the underlying iterator.
+// If documents fit into the memory limit, they are sorted in memory,
+// and a new iterator over the sorted slice is returned.
+// Otherwise, if disk use is allowed, sorted runs of documents are spilled to temporary files,
+// and the returned iterator merges them; temporary files are removed when it is closed.
+// Documents are encoded once to check their size; those encoded bytes are written as is.
+//
+// {$natural: <1|-1>} sort is handled by naturalSortIterator.
+func SortIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, params *SortParams) (types.DocumentsIterator, error) { //nolint:lll // for readability
 	// don't consume all documents if there is no sort
-	if sort.Len() == 0 {
+	if params.Sort.Len() == 0 {
 		return iter, nil
 	}
 
-	docs, err := iterator.ConsumeValues(iter)
+	if params.Sort.Len() == 1 && params.Sort.Has("$natural") {
+		order, err := GetSortType("$natural", must.NotFail(params.Sort.Get("$natural")))
+		if err != nil {
+			return nil, err
+		}
+
+		return naturalSortIterator(iter, closer, order)
+	}
+
+	defer iter.Close()
+
+	sortFuncs, err := getSortFuncs(params.Sort, params.Collation)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	if err = SortDocuments(docs, sort); err != nil {
+	limit := params.MemoryLimit
+	if limit == 0 {
+		limit = DefaultSortMemoryLimit
+	}
+
+	sorter := &docsSorter{sorts: sortFuncs}
+
+	var runs *sortRuns
+	var size, spills int
+
+	for {
+		var doc *types.Document
+
+		if _, doc, err = iter.Next(); err != nil {
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			runs.close()
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		var raw bson.RawDocument
+
+		if raw, err = encodeSortDocument(doc); err != nil {
+			runs.close()
+			return nil, lazyerrors.Error(err)
+		}
+
+		sorter.docs = append(sorter.docs, doc)
+		sorter.raws = append(sorter.raws, raw)
+
+		if size += len(raw); size <= limit {
+			continue
+		}
+
+		if !params.AllowDiskUse {
+			runs.close()
+
+			return nil, handlererrors.NewCommandErrorMsg(
+				handlererrors.ErrQueryExceededMemoryLimitNoDiskUseAllowed,
+				fmt.Sprintf("Sort exceeded memory limit of %d bytes, but did not opt in to external sorting.", limit),
+			)
+		}
+
+		if runs == nil {
+			if runs, err = newSortRuns(params.TempDir, "ferretdb-sort-"); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		sort.Sort(sorter)
+
+		if err = runs.write(sorter.raws); err != nil {
+			runs.close()
+			return nil, lazyerrors.Error(err)
+		}
+
+		spills++
+
+		if len(runs.files) >= maxSortRuns {
+			if err = runs.merge(sorter); err != nil {
+				runs.close()
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		sorter.docs = nil
+		sorter.raws = nil
+		size = 0
+	}
+
+	// remaining documents are not written
+	sorter.raws = nil
+
+	sort.Sort(sorter)
+
+	if runs == nil {
+		res := iterator.Values(iterator.ForSlice(sorter.docs))
+		closer.Add(res)
+
+		return res, nil
+	}
+
+	params.Spills = spills
+
+	res, err := newMergeIterator(runs, sorter)
+	if err != nil {
+		runs.close()
+		return nil, lazyerrors.Error(err)
+	}
+
+	closer.Add(res)
+
+	return res, nil
+}
+
+// naturalSortIterator returns an iterator of documents in the natural order.
+// It will be added to the given closer.
+//
+// Documents of capped collections are ordered by their record IDs.
+// Other documents do not have record IDs; they are kept in the order returned by the backend,
+// which is their natural (insertion or storage) order.
+// For descending order, that order is reversed.
+//
+// Like SortIterator, this function fully consumes and closes the underlying iterator.
+func naturalSortIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, order types.SortType) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	defer iter.Close()
+
+	docs, err := iterator.ConsumeValues(iter)
+	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
+	slices.SortStableFunc(docs, func(a, b *types.Document) int {
+		return cmp.Compare(a.RecordID(), b.RecordID())
+	})
+
+	if order == types.Descending {
+		slices.Reverse(docs)
+	}
+
 	res := iterator.Values(iterator.ForSlice(docs))
 	closer.Add(res)
 
 	return res, nil
 }
+
+// encodeSortDocument encodes a document for writing it to a temporary file.
+func encodeSortDocument(doc *types.Document) (bson.RawDocument, error) {
+	d, err := bson.ConvertDocument(doc)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	raw, err := d.Encode()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return raw, nil
+}
+
+// sortRuns represents sorted runs of documents stored in temporary files.
+type sortRuns struct {
+	dir   string
+	files []*os.File
+}
+
+// newSortRuns creates a new temporary directory with the given name pattern for sorted runs in the given directory.
+//
+// It is also used by [Buffer] to store unsorted documents.
+func newSortRuns(tempDir, pattern string) (*sortRuns, error) {
+	dir, err := os.MkdirTemp(tempDir, pattern)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &sortRuns{dir: dir}, nil
+}
+
+// write writes the given encoded documents to a new temporary file.
+func (runs *sortRuns) write(raws []bson.RawDocument) error {
+	i := 0
+
+	return runs.writeFile(func() (bson.RawDocument, error) {
+		if i == len(raws) {
+			return nil, iterator.ErrIteratorDone
+		}
+
+		i++
+
+		return raws[i-1], nil
+	})
+}
+
+// merge merges all sorted runs into a single one written to a new temporary file.
+func (runs *sortRuns) merge(sorter *docsSorter) error {
+	nexts := make([]func() (*types.Document, bson.RawDocument, error), len(runs.files))
+	for i, f := range runs.files {
+		nexts[i] = fileRun(f)
+	}
+
+	h, err := newSortRunsHeap(sorter, nexts)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	merged := runs.files
+
+	err = runs.writeFile(func() (bson.RawDocument, error) {
+		_, raw, err := h.next()
+		return raw, err
+	})
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for _, f := range merged {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+
+	runs.files = runs.files[len(merged):]
+
+	return nil
+}
+
+// writeFile writes encoded documents returned by next to a new temporary file
+// until next returns iterator.ErrIteratorDone.
+func (runs *sortRuns) writeFile(next func() (bson.RawDocument, error)) error {
+	f, err := os.CreateTemp(runs.dir, "run-*.bson")
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	runs.files = append(runs.files, f)
+
+	w := bufio.NewWriter(f)
+
+	for {
+		var raw bson.RawDocument
+
+		if raw, err = next(); err != nil {
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			return lazyerrors.Error(err)
+		}
+
+		if _, err = w.Write(raw); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if err = w.Flush(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// close closes and removes all temporary files.
+//
+// It does nothing for nil receiver.
+func (runs *sortRuns) close() {
+	if runs == nil {
+		return
+	}
+
+	for _, f := range runs.files {
+		_ = f.Close()
+	}
+
+	_ = os.RemoveAll(runs.dir)
+}
+
+// sortRun represents a single sorted run being merged.
+type sortRun struct {
+	next func() (*types.Document, bson.RawDocument, error) // returns iterator.ErrIteratorDone at the end
+	doc  *types.Document                                   // current document
+	raw  bson.RawDocument                                  // current encoded document; nil for documents in memory
+}
+
+// fileRun returns a function that reads documents from the temporary file one by one.
+//
+// It returns both decoded and encoded documents.
+func fileRun(f *os.File) func() (*types.Document, bson.RawDocument, error) {
+	r := bufio.NewReader(f)
+
+	return func() (*types.Document, bson.RawDocument, error) {
+		var l [4]byte
+
+		if _, err := io.ReadFull(r, l[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, nil, iterator.ErrIteratorDone
+			}
+
+			return nil, nil, lazyerrors.Error(err)
+		}
+
+		raw := make(bson.RawDocument, binary.LittleEndian.Uint32(l[:]))
+		if len(raw) < len(l) {
+			return nil, nil, lazyerrors.Errorf("invalid document length %d", len(raw))
+		}
+
+		copy(raw, l[:])
+
+		if _, err := io.ReadFull(r, raw[len(l):]); err != nil {
+			return nil, nil, lazyerrors.Error(err)
+		}
+
+		doc, err := raw.Convert()
+		if err != nil {
+			return nil, nil, lazyerrors.Error(err)
+		}
+
+		return doc, raw, nil
+	}
+}
+
+// sortRunsHeap implements heap.Interface for merging sorted runs.
+type sortRunsHeap struct {
+	sorter *docsSorter
+	runs   []*sortRun
+}
+
+// newSortRunsHeap returns a new heap of runs with their first documents.
+func newSortRunsHeap(sorter *docsSorter, nexts []func() (*types.Document, bson.RawDocument, error)) (*sortRunsHeap, error) { //nolint:lll // for readability
+	h := &sortRunsHeap{
+		sorter: sorter,
+		runs:   make([]*sortRun, 0, len(nexts)),
+	}
+
+	for _, next := range nexts {
+		doc, raw, err := next()
+		if err != nil {
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				continue
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		h.runs = append(h.runs, &sortRun{next: next, doc: doc, raw: raw})
+	}
+
+	heap.Init(h)
+
+	return h, nil
+}
+
+// next returns the smallest current document of all runs and advances its run.
+//
+// It returns iterator.ErrIteratorDone when all runs are done.
+func (h *sortRunsHeap) next() (*types.Document, bson.RawDocument, error) {
+	if h.Len() == 0 {
+		return nil, nil, iterator.ErrIteratorDone
+	}
+
+	run := h.runs[0]
+	doc, raw := run.doc, run.raw
+
+	nextDoc, nextRaw, err := run.next()
+
+	switch {
+	case err == nil:
+		run.doc, run.raw = nextDoc, nextRaw
+		heap.Fix(h, 0)
+	case errors.Is(err, iterator.ErrIteratorDone):
+		heap.Pop(h)
+	default:
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	return doc, raw, nil
+}
+
+// Len implements heap.Interface.
+func (h *sortRunsHeap) Len() int {
+	return len(h.runs)
+}
+
+// Less implements heap.Interface.
+func (h *sortRunsHeap) Less(i, j int) bool {
+	return h.sorter.less(h.runs[i].doc, h.runs[j].doc)
+}
+
+// Swap implements heap.Interface.
+func (h *sortRunsHeap) Swap(i, j int) {
+	h.runs[i], h.runs[j] = h.runs[j], h.runs[i]
+}
+
+// Push implements heap.Interface.
+func (h *sortRunsHeap) Push(x any) {
+	h.runs = append(h.runs, x.(*sortRun))
+}
+
+// Pop implements heap.Interface.
+func (h *sortRunsHeap) Pop() any {
+	n := len(h.runs)
+	run := h.runs[n-1]
+	h.runs = h.runs[:n-1]
+
+	return run
+}
+
+// mergeIterator merges sorted runs stored in temporary files and the remaining sorted documents in memory.
+//
+//nolint:vet // for readability
+type mergeIterator struct {
+	m    sync.Mutex
+	h    *sortRunsHeap
+	runs *sortRuns
+}
+
+// newMergeIterator returns a new iterator that merges given runs and sorter's documents.
+func newMergeIterator(runs *sortRuns, sorter *docsSorter) (*mergeIterator, error) {
+	nexts := make([]func() (*types.Document, bson.RawDocument, error), 0, len(runs.files)+1)
+	for _, f := range runs.files {
+		nexts = append(nexts, fileRun(f))
+	}
+
+	docs := sorter.docs
+	nexts = append(nexts, func() (*types.Document, bson.RawDocument, error) {
+		if len(docs) == 0 {
+			return nil, nil, iterator.ErrIteratorDone
+		}
+
+		doc := docs[0]
+		docs = docs[1:]
+
+		return doc, nil, nil
+	})
+
+	h, err := newSortRunsHeap(sorter, nexts)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &mergeIterator{
+		h:    h,
+		runs: runs,
+	}, nil
+}
+
+// Next implements iterator.Interface.
+func (iter *mergeIterator) Next() (struct{}, *types.Document, error) {
+	iter.m.Lock()
+	defer iter.m.Unlock()
+
+	var unused struct{}
+
+	if iter.h == nil {
+		return unused, nil, iterator.ErrIteratorDone
+	}
+
+	doc, _, err := iter.h.next()
+	if err != nil {
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			return unused, nil, err
+		}
+
+		return unused, nil, lazyerrors.Error(err)
+	}
+
+	return unused, doc, nil
+}
+
+// Close implements iterator.Interface.
+func (iter *mergeIterator) Close() {
+	iter.m.Lock()
+	defer iter.m.Unlock()
+
+	iter.h = nil
+
+	iter.runs.close()
+	iter.runs = nil
+}
+
+// check interfaces
+var (
+	_ types.DocumentsIterator = (*mergeIterator)(nil)
+	_ heap.Interface          = (*sortRunsHeap)(nil)
+)
@@ -0,0 +1,211 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestSortIteratorDisk(t *testing.T) {
+	t.Parallel()
+
+	const n = 1000
+
+	docs := make([]*types.Document, n)
+	for i, v := range rand.Perm(n) {
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(v), "v", "foo"))
+	}
+
+	sort := must.NotFail(types.NewDocument("_id", int32(1)))
+
+	t.Run("AllowDiskUse", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		params := &SortParams{
+			Sort:         sort,
+			MemoryLimit:  1024,
+			AllowDiskUse: true,
+			TempDir:      dir,
+		}
+
+		closer := iterator.NewMultiCloser()
+		iter, err := SortIterator(iterator.Values(iterator.ForSlice(docs)), closer, params)
+		require.NoError(t, err)
+		assert.Greater(t, params.Spills, 1)
+
+		res, err := iterator.ConsumeValues(iter)
+		require.NoError(t, err)
+		require.Len(t, res, n)
+
+		for i, doc := range res {
+			assert.Equal(t, int32(i), must.NotFail(doc.Get("_id")))
+		}
+
+		closer.Close()
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "temporary files should be removed")
+	})
+
+	t.Run("MergeRuns", func(t *testing.T) {
+		t.Parallel()
+
+		const n = 10000
+
+		docs := make([]*types.Document, n)
+		for i, v := range rand.Perm(n) {
+			docs[i] = must.NotFail(types.NewDocument("_id", int32(v), "v", "foo"))
+		}
+
+		dir := t.TempDir()
+		params := &SortParams{
+			Sort:         sort,
+			MemoryLimit:  1024,
+			AllowDiskUse: true,
+			TempDir:      dir,
+		}
+
+		closer := iterator.NewMultiCloser()
+		defer closer.Close()
+
+		iter, err := SortIterator(iterator.Values(iterator.ForSlice(docs)), closer, params)
+		require.NoError(t, err)
+		assert.Greater(t, params.Spills, maxSortRuns)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		files, err := os.ReadDir(filepath.Join(dir, entries[0].Name()))
+		require.NoError(t, err)
+		assert.Less(t, len(files), maxSortRuns, "runs should be merged")
+
+		res, err := iterator.ConsumeValues(iter)
+		require.NoError(t, err)
+		require.Len(t, res, n)
+
+		for i, doc := range res {
+			assert.Equal(t, int32(i), must.NotFail(doc.Get("_id")))
+		}
+	})
+
+	t.Run("InMemory", func(t *testing.T) {
+		t.Parallel()
+
+		params := &SortParams{
+			Sort:         sort,
+			AllowDiskUse: true,
+			TempDir:      t.TempDir(),
+		}
+
+		closer := iterator.NewMultiCloser()
+		defer closer.Close()
+
+		iter, err := SortIterator(iterator.Values(iterator.ForSlice(docs)), closer, params)
+		require.NoError(t, err)
+		assert.Zero(t, params.Spills)
+
+		res, err := iterator.ConsumeValues(iter)
+		require.NoError(t, err)
+		require.Len(t, res, n)
+		assert.Equal(t, int32(0), must.NotFail(res[0].Get("_id")))
+	})
+
+	t.Run("NoDiskUse", func(t *testing.T) {
+		t.Parallel()
+
+		params := &SortParams{
+			Sort:        sort,
+			MemoryLimit: 1024,
+		}
+
+		closer := iterator.NewMultiCloser()
+		defer closer.Close()
+
+		_, err := SortIterator(iterator.Values(iterator.ForSlice(docs)), closer, params)
+
+		var cmdErr *handlererrors.CommandError
+		require.ErrorAs(t, err, &cmdErr)
+		assert.Equal(t, handlererrors.ErrQueryExceededMemoryLimitNoDiskUseAllowed, cmdErr.Code())
+	})
+}
+
+func TestSortIteratorNatural(t *testing.T) {
+	t.Parallel()
+
+	ids := func(docs []*types.Document) []any {
+		res := make([]any, len(docs))
+		for i, doc := range docs {
+			res[i] = must.NotFail(doc.Get("_id"))
+		}
+
+		return res
+	}
+
+	sortNatural := func(t *testing.T, docs []*types.Document, order int64) []*types.Document {
+		t.Helper()
+
+		closer := iterator.NewMultiCloser()
+		defer closer.Close()
+
+		params := &SortParams{Sort: must.NotFail(types.NewDocument("$natural", order))}
+		iter, err := SortIterator(iterator.Values(iterator.ForSlice(docs)), closer, params)
+		require.NoError(t, err)
+
+		res, err := iterator.ConsumeValues(iter)
+		require.NoError(t, err)
+
+		return res
+	}
+
+	t.Run("NonCapped", func(t *testing.T) {
+		t.Parallel()
+
+		docs := []*types.Document{
+			must.NotFail(types.NewDocument("_id", int32(3))),
+			must.NotFail(types.NewDocument("_id", int32(1))),
+			must.NotFail(types.NewDocument("_id", int32(2))),
+		}
+
+		assert.Equal(t, []any{int32(3), int32(1), int32(2)}, ids(sortNatural(t, docs, 1)))
+		assert.Equal(t, []any{int32(2), int32(1), int32(3)}, ids(sortNatural(t, docs, -1)))
+	})
+
+	t.Run("Capped", func(t *testing.T) {
+		t.Parallel()
+
+		docs := make([]*types.Document, 3)
+		for i, recordID := range []int64{20, 30, 10} {
+			docs[i] = must.NotFail(types.NewDocument("_id", int32(i)))
+			docs[i].SetRecordID(recordID)
+		}
+
+		assert.Equal(t, []any{int32(2), int32(0), int32(1)}, ids(sortNatural(t, docs, 1)))
+		assert.Equal(t, []any{int32(1), int32(0), int32(2)}, ids(sortNatural(t, docs, -1)))
+	})
+}
@@ -83,7 +83,7 @@ func UpdateDocument(ctx context.Context, c backends.Collection, cmd string, iter
 		if !param.HasUpdateOperators {
 			modified, err = processReplacementDoc(cmd, doc, param.Update)
 		} else {
-			modified, err = processUpdateOperator(cmd, doc, param.Update, upsert)
+			modified, err = processUpdateOperator(cmd, doc, param.Update, upsert, param.Collation)
 		}
 
 		if err != nil {
@@ -210,10 +210,11 @@ func processReplacementDoc(command string, doc, update *types.Document) (bool, e
 }
 
 // processUpdateOperator updates the given document with a series of update operators.
+// Strings are compared according to the given collation.
 // Returns true if the document is changed.
 // Returns CommandError if the command is findAndModify, otherwise returns WriteError.
 // TODO https://github.com/FerretDB/FerretDB/issues/3044
-func processUpdateOperator(command string, doc, update *types.Document, upsert bool) (bool, error) {
+func processUpdateOperator(command string, doc, update *types.Document, upsert bool, c *types.Collation) (bool, error) {
 	var docUpdated bool
 	var err error
 
@@ -268,13 +269,13 @@ func processUpdateOperator(command string, doc, update *types.Document, upsert b
 			}
 
 		case "$max":
-			updated, err = processMaxFieldExpression(command, doc, key, value)
+			updated, err = processMaxFieldExpression(command, doc, key, value, c)
 			if err != nil {
 				return false, err
 			}
 
 		case "$min":
-			updated, err = processMinFieldExpression(command, doc, key, value)
+			updated, err = processMinFieldExpression(command, doc, key, value, c)
 			if err != nil {
 				return false, err
 			}
@@ -502,7 +503,7 @@ func processRenameFieldExpression(command string, doc *types.Document, key strin
 func processIncFieldExpression(command string, doc *types.Document, incKey string, incValue any) (bool, error) {
 	// ensure incValue is a valid number type.
 	switch incValue.(type) {
-	case float64, int32, int64:
+	case float64, int32, int64, types.Decimal128:
 	default:
 		return false, NewUpdateError(
 			handlererrors.ErrTypeMismatch,
@@ -600,9 +601,9 @@ func processIncFieldExpression(command string, doc *types.Document, incKey strin
 	}
 }
 
-// processMaxFieldExpression changes document according to $max operator.
+// processMaxFieldExpression changes document according to $max operator using the given collation.
 // If the document was changed it returns true.
-func processMaxFieldExpression(command string, doc *types.Document, maxKey string, maxValue any) (bool, error) {
+func processMaxFieldExpression(command string, doc *types.Document, maxKey string, maxValue any, c *types.Collation) (bool, error) { //nolint:lll // for readability
 	// maxKey has valid path, checked in ValidateUpdateOperators.
 	path := must.NotFail(types.NewPathFromString(maxKey))
 
@@ -622,7 +623,7 @@ func processMaxFieldExpression(command string, doc *types.Document, maxKey strin
 
 	// if the document value was found, compare it with max value
 	if val != nil {
-		res := types.CompareOrder(val, maxValue, types.Ascending)
+		res := c.CompareOrder(val, maxValue, types.Ascending)
 		switch res {
 		case types.Equal, types.Greater:
 			return false, nil
@@ -644,9 +645,9 @@ func processMaxFieldExpression(command string, doc *types.Document, maxKey strin
 	return true, nil
 }
 
-// processMinFieldExpression changes document according to $min operator.
+// processMinFieldExpression changes document according to $min operator using the given collation.
 // If the document was changed it returns true.
-func processMinFieldExpression(command string, doc *types.Document, minKey string, minValue any) (bool, error) {
+func processMinFieldExpression(command string, doc *types.Document, minKey string, minValue any, c *types.Collation) (bool, error) { //nolint:lll // for readability
 	// minKey has valid path, checked in ValidateUpdateOperators.
 	path := must.NotFail(types.NewPathFromString(minKey))
 
@@ -666,7 +667,7 @@ func processMinFieldExpression(command string, doc *types.Document, minKey strin
 
 	// if the document value was found, compare it with min value
 	if val != nil {
-		res := types.CompareOrder(val, minValue, types.Ascending)
+		res := c.CompareOrder(val, minValue, types.Ascending)
 		switch res {
 		case types.Equal, types.Less:
 			return false, nil
@@ -696,6 +697,8 @@ func processMulFieldExpression(command string, doc *types.Document, mulKey strin
 			mulValue = int32(0)
 		case int64:
 			mulValue = int64(0)
+		case types.Decimal128:
+			mulValue = types.NewDecimal128FromInt64(0)
 		default:
 			return false, NewUpdateError(
 				handlererrors.ErrTypeMismatch,
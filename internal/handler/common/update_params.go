@@ -32,11 +32,11 @@ type UpdateParams struct {
 	Updates []Update `ferretdb:"updates"`
 
 	Comment   string `ferretdb:"comment,opt"`
-	MaxTimeMS int64  `ferretdb:"maxTimeMS,ignored"`
+	MaxTimeMS int64  `ferretdb:"maxTimeMS,opt,wholePositiveNumber"`
 
 	Let *types.Document `ferretdb:"let,unimplemented"`
 
-	Ordered                  bool            `ferretdb:"ordered,ignored"`
+	Ordered                  bool            `ferretdb:"ordered,opt"`
 	BypassDocumentValidation bool            `ferretdb:"bypassDocumentValidation,ignored"`
 	WriteConcern             *types.Document `ferretdb:"writeConcern,ignored"`
 	LSID                     any             `ferretdb:"lsid,ignored"`
@@ -57,8 +57,10 @@ type Update struct {
 
 	HasUpdateOperators bool `ferretdb:"-"`
 
+	CollationDoc *types.Document  `ferretdb:"collation,opt"`
+	Collation    *types.Collation `ferretdb:"-"`
+
 	C            *types.Document `ferretdb:"c,unimplemented"`
-	Collation    *types.Document `ferretdb:"collation,unimplemented"`
 	ArrayFilters *types.Array    `ferretdb:"arrayFilters,unimplemented"`
 
 	Hint string `ferretdb:"hint,ignored"`
@@ -85,7 +87,9 @@ type UpdateResult struct {
 
 // GetUpdateParams returns parameters for update command.
 func GetUpdateParams(document *types.Document, l *zap.Logger) (*UpdateParams, error) {
-	var params UpdateParams
+	params := UpdateParams{
+		Ordered: true,
+	}
 
 	err := handlerparams.ExtractParams(document, "update", &params, l)
 	if err != nil {
@@ -96,6 +100,10 @@ func GetUpdateParams(document *types.Document, l *zap.Logger) (*UpdateParams, er
 		for i := range params.Updates {
 			update := &params.Updates[i]
 
+			if update.Collation, err = GetCollation("update", update.CollationDoc); err != nil {
+				return nil, err
+			}
+
 			if update.Update == nil {
 				continue
 			}
@@ -0,0 +1,124 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/FerretDB/FerretDB/build/version"
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// CompatProfile represents a compatibility profile.
+type CompatProfile string
+
+const (
+	// FerretDBProfile reports FerretDB-specific fields (like ferretdbVersion)
+	// in buildInfo, serverStatus, and explain responses.
+	FerretDBProfile CompatProfile = "ferretdb"
+
+	// MongoDBProfile omits FerretDB-specific fields from responses,
+	// so clients that detect FerretDB by them treat it as MongoDB.
+	MongoDBProfile CompatProfile = "mongodb"
+)
+
+// AllCompatProfiles includes all compatibility profiles, with the first one being the default.
+var AllCompatProfiles = []string{
+	string(FerretDBProfile),
+	string(MongoDBProfile),
+}
+
+// maxWireVersions maps MongoDB major.minor versions that could be reported to clients
+// to the corresponding maximal wire protocol versions.
+var maxWireVersions = map[string]int32{
+	"5.0": 13,
+	"5.1": 14,
+	"5.2": 15,
+	"5.3": 16,
+	"6.0": 17,
+	"6.1": 18,
+	"6.2": 19,
+	"6.3": 20,
+	"7.0": common.MaxWireVersion,
+}
+
+// mongoDBVersionRe matches MongoDB version like 6.0.14.
+var mongoDBVersionRe = regexp.MustCompile(`^([0-9]+)\.([0-9]+)\.([0-9]+)$`)
+
+// serverVersion represents MongoDB version reported to clients.
+type serverVersion struct {
+	version        string
+	versionArray   *types.Array
+	maxWireVersion int32
+}
+
+// newServerVersion returns MongoDB version reported to clients.
+//
+// Empty string means the version of the current build.
+// It returns error if the version is invalid or not supported.
+//
+//checkerrors:ignore // user-facing configuration errors
+func newServerVersion(s string) (*serverVersion, error) {
+	if s == "" {
+		s = version.Get().MongoDBVersion
+	}
+
+	parts := mongoDBVersionRe.FindStringSubmatch(s)
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid MongoDB version %q, expected major.minor.patch", s)
+	}
+
+	maxWireVersion, ok := maxWireVersions[parts[1]+"."+parts[2]]
+	if !ok {
+		supported := maps.Keys(maxWireVersions)
+		slices.Sort(supported)
+
+		return nil, fmt.Errorf(
+			"unsupported MongoDB version %q, supported versions: %s",
+			s, strings.Join(supported, ", "),
+		)
+	}
+
+	versionArray := types.MakeArray(4)
+
+	for _, p := range parts[1:] {
+		n, err := strconv.ParseInt(p, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MongoDB version %q: %w", s, err)
+		}
+
+		versionArray.Append(int32(n))
+	}
+
+	versionArray.Append(int32(0))
+
+	return &serverVersion{
+		version:        s,
+		versionArray:   versionArray,
+		maxWireVersion: maxWireVersion,
+	}, nil
+}
+
+// extensions returns true if FerretDB-specific fields should be added to responses.
+func (h *Handler) extensions() bool {
+	return h.CompatProfile != MongoDBProfile
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/build/version"
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestNewServerVersion(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		version        string
+		expected       string
+		versionArray   *types.Array
+		maxWireVersion int32
+		err            string
+	}{
+		"Default": {
+			expected:       version.Get().MongoDBVersion,
+			versionArray:   version.Get().MongoDBVersionArray,
+			maxWireVersion: common.MaxWireVersion,
+		},
+		"Old": {
+			version:        "5.0.26",
+			expected:       "5.0.26",
+			versionArray:   must.NotFail(types.NewArray(int32(5), int32(0), int32(26), int32(0))),
+			maxWireVersion: 13,
+		},
+		"Latest": {
+			version:        "7.0.1",
+			expected:       "7.0.1",
+			versionArray:   must.NotFail(types.NewArray(int32(7), int32(0), int32(1), int32(0))),
+			maxWireVersion: common.MaxWireVersion,
+		},
+		"Invalid": {
+			version: "6.0",
+			err:     `invalid MongoDB version "6.0", expected major.minor.patch`,
+		},
+		"TooOld": {
+			version: "4.4.29",
+			err:     `unsupported MongoDB version "4.4.29", supported versions: 5.0, 5.1, 5.2, 5.3, 6.0, 6.1, 6.2, 6.3, 7.0`,
+		},
+		"TooNew": {
+			version: "8.0.0",
+			err:     `unsupported MongoDB version "8.0.0", supported versions: 5.0, 5.1, 5.2, 5.3, 6.0, 6.1, 6.2, 6.3, 7.0`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := newServerVersion(tc.version)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual.version)
+			assert.Equal(t, tc.versionArray, actual.versionArray)
+			assert.Equal(t, tc.maxWireVersion, actual.maxWireVersion)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// Cursors returns information about all open cursors, sorted by ID.
+//
+// It is used by the debug handler.
+func (h *Handler) Cursors() []*cursor.Info {
+	all := h.cursors.All()
+
+	res := make([]*cursor.Info, len(all))
+	for i, c := range all {
+		res[i] = c.Info()
+	}
+
+	slices.SortFunc(res, func(a, b *cursor.Info) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return res
+}
+
+// BackendCollector returns backend's metrics collector (including connection pool statistics).
+//
+// It is used by the debug handler.
+func (h *Handler) BackendCollector() prometheus.Collector {
+	return h.b
+}
+
+// Ping checks that the backend is reachable and its metadata is loaded.
+//
+// It is used by the debug handler for readiness probes.
+func (h *Handler) Ping(ctx context.Context) error {
+	info := conninfo.New()
+	info.SetBypassBackendAuth()
+
+	if _, err := h.b.Status(conninfo.Ctx(ctx, info), nil); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
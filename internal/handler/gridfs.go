@@ -0,0 +1,312 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// GridFS drivers store files in two collections: `<bucket>.files` with metadata and `<bucket>.chunks`
+// with file content split into chunks, each having `files_id` and zero-based chunk number `n` fields.
+// To download a file (or a range of it), drivers query `{files_id: <id>, n: {$gte: <first>}}` with `{n: 1}` sort.
+//
+// Sorting all file chunks in memory would require the whole file to be loaded at once.
+// Instead, chunks of known buckets are streamed in the order returned by the backend;
+// since drivers insert chunks one by one, that order almost always matches the chunk numbers,
+// and only chunks that are returned too early are kept in memory until their turn,
+// up to the sort memory limit.
+//
+// When the first chunk of a known bucket is inserted, the chunks collection is created
+// with the unique index on files_id and n (like drivers do), so each chunk is stored once
+// and backends could look chunks of a file up by that index.
+
+// DefaultGridFSBuckets contains the names of GridFS buckets used when none are configured.
+var DefaultGridFSBuckets = []string{"fs"}
+
+// gridFSChunksSuffix is the suffix of the collection name that stores chunks of GridFS bucket.
+const gridFSChunksSuffix = ".chunks"
+
+// gridFSChunksIndex is the index of GridFS chunks collection, the same as drivers create.
+var gridFSChunksIndex = backends.IndexInfo{
+	Name:   "files_id_1_n_1",
+	Key:    []backends.IndexKeyPair{{Field: "files_id"}, {Field: "n"}},
+	Unique: true,
+}
+
+// gridFSChunks returns true if the given collection stores chunks of the known GridFS bucket.
+func (h *Handler) gridFSChunks(collection string) bool {
+	bucket, ok := strings.CutSuffix(collection, gridFSChunksSuffix)
+	return ok && slices.Contains(h.GridFSBuckets, bucket)
+}
+
+// gridFSCreateChunks creates the given collection with gridFSChunksIndex
+// if it stores chunks of the known GridFS bucket and does not exist yet.
+func (h *Handler) gridFSCreateChunks(ctx context.Context, db backends.Database, collection string) error {
+	if !h.gridFSChunks(collection) {
+		return nil
+	}
+
+	list, err := db.ListCollections(ctx, &backends.ListCollectionsParams{Name: collection})
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if len(list.Collections) > 0 {
+		return nil
+	}
+
+	c, err := db.Collection(collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	// the index is created together with the collection;
+	// the existing index with the same name is kept if another insert creates it concurrently
+	_, err = c.CreateIndexes(ctx, &backends.CreateIndexesParams{Indexes: []backends.IndexInfo{gridFSChunksIndex}})
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// gridFSChunksStart checks if the find command reads chunks of a single file from the GridFS bucket.
+//
+// If it does, it returns the first chunk number that could be returned and true.
+// Otherwise, it returns false, and chunks should be sorted as usual.
+func (h *Handler) gridFSChunksStart(params *common.FindParams) (int64, bool) {
+	if !h.gridFSChunks(params.Collection) {
+		return 0, false
+	}
+
+	if params.Sort.Len() != 1 {
+		return 0, false
+	}
+
+	if order, _ := params.Sort.Get("n"); order != int64(1) {
+		return 0, false
+	}
+
+	if params.Filter.Len() == 0 {
+		return 0, false
+	}
+
+	var start int64
+
+	for _, k := range params.Filter.Keys() {
+		v, _ := params.Filter.Get(k)
+
+		switch k {
+		case "files_id":
+			switch v.(type) {
+			case *types.Document, *types.Array, types.Regex:
+				return 0, false
+			}
+
+		case "n":
+			cond, ok := v.(*types.Document)
+			if !ok {
+				n, err := handlerparams.GetWholeNumberParam(v)
+				if err != nil {
+					return 0, false
+				}
+
+				start = max(start, n)
+
+				continue
+			}
+
+			for _, op := range cond.Keys() {
+				n, err := handlerparams.GetWholeNumberParam(must.NotFail(cond.Get(op)))
+				if err != nil {
+					return 0, false
+				}
+
+				switch op {
+				case "$gte":
+					start = max(start, n)
+				case "$gt":
+					start = max(start, n+1)
+				case "$lt", "$lte":
+					// the end of the range is handled by the filter
+				default:
+					return 0, false
+				}
+			}
+
+		default:
+			return 0, false
+		}
+	}
+
+	if !params.Filter.Has("files_id") {
+		return 0, false
+	}
+
+	return start, true
+}
+
+// gridFSChunksIterator returns GridFS chunks of a single file sorted by chunk number
+// without loading all of them in memory, starting from the given chunk number.
+//
+// Chunks returned by the underlying iterator before their turn are kept in memory
+// until their total data size exceeds the given limit; after that, the iterator returns an error.
+//
+// The underlying iterator should return only chunks of that file.
+// It will be added to the given closer.
+//
+//nolint:lll // for readability
+func gridFSChunksIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, start int64, limit int) types.DocumentsIterator {
+	res := &chunksIterator{
+		iter:    iter,
+		next:    start,
+		pending: map[int64][]*types.Document{},
+		limit:   limit,
+	}
+	closer.Add(res)
+
+	return res
+}
+
+// chunksIterator is returned by gridFSChunksIterator.
+type chunksIterator struct {
+	iter types.DocumentsIterator
+
+	// the number of the chunk that should be returned next
+	next int64
+
+	// chunks returned by the underlying iterator before their turn
+	pending map[int64][]*types.Document
+
+	// total data size of pending chunks and its limit
+	pendingSize int
+	limit       int
+
+	// true if the underlying iterator is done
+	done bool
+}
+
+// Next implements iterator.Interface. See gridFSChunksIterator for details.
+func (iter *chunksIterator) Next() (struct{}, *types.Document, error) {
+	var unused struct{}
+
+	for {
+		if docs := iter.pending[iter.next]; len(docs) > 0 {
+			doc := docs[0]
+
+			if len(docs) == 1 {
+				delete(iter.pending, iter.next)
+				iter.next++
+			} else {
+				iter.pending[iter.next] = docs[1:]
+			}
+
+			iter.pendingSize -= chunkSize(doc)
+
+			return unused, doc, nil
+		}
+
+		if iter.done {
+			if len(iter.pending) == 0 {
+				return unused, nil, iterator.ErrIteratorDone
+			}
+
+			// some chunks are missing; continue with the next existing one
+			iter.next = slices.Min(maps.Keys(iter.pending))
+
+			continue
+		}
+
+		_, doc, err := iter.iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			iter.done = true
+			continue
+		}
+
+		if err != nil {
+			return unused, nil, lazyerrors.Error(err)
+		}
+
+		v, _ := doc.Get("n")
+
+		n, err := handlerparams.GetWholeNumberParam(v)
+		if err != nil {
+			return unused, nil, lazyerrors.Errorf("GridFS chunk %v has invalid n: %v", must.NotFail(doc.Get("_id")), v)
+		}
+
+		switch {
+		case n == iter.next:
+			iter.next++
+			return unused, doc, nil
+
+		case n == iter.next-1:
+			// chunk with the same number as the previous one
+			return unused, doc, nil
+
+		case n < iter.next:
+			return unused, nil, lazyerrors.Errorf("GridFS chunk %v with n %d returned too late", must.NotFail(doc.Get("_id")), n)
+		}
+
+		iter.pending[n] = append(iter.pending[n], doc)
+
+		if iter.pendingSize += chunkSize(doc); iter.pendingSize > iter.limit {
+			return unused, nil, handlererrors.NewCommandErrorMsg(
+				handlererrors.ErrQueryExceededMemoryLimitNoDiskUseAllowed,
+				fmt.Sprintf(
+					"GridFS chunks stored out of order exceeded memory limit of %d bytes while waiting for chunk %d.",
+					iter.limit, iter.next,
+				),
+			)
+		}
+	}
+}
+
+// chunkSize returns the size of GridFS chunk data.
+func chunkSize(doc *types.Document) int {
+	data, _ := doc.Get("data")
+
+	if b, ok := data.(types.Binary); ok {
+		return len(b.B)
+	}
+
+	return 0
+}
+
+// Close implements iterator.Interface. See gridFSChunksIterator for details.
+func (iter *chunksIterator) Close() {
+	iter.pending = nil
+
+	iter.iter.Close()
+}
+
+// check interfaces
+var (
+	_ types.DocumentsIterator = (*chunksIterator)(nil)
+)
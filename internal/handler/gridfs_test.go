@@ -0,0 +1,226 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestGridFSChunksStart(t *testing.T) {
+	t.Parallel()
+
+	h := &Handler{NewOpts: &NewOpts{GridFSBuckets: []string{"fs", "images"}}}
+	id := types.NewObjectID()
+	sort := must.NotFail(types.NewDocument("n", int64(1)))
+
+	for name, tc := range map[string]struct {
+		collection string
+		filter     *types.Document
+		sort       *types.Document
+		start      int64
+		ok         bool
+	}{
+		"File": {
+			collection: "fs.chunks",
+			filter:     must.NotFail(types.NewDocument("files_id", id)),
+			sort:       sort,
+			ok:         true,
+		},
+		"Range": {
+			collection: "images.chunks",
+			filter: must.NotFail(types.NewDocument(
+				"files_id", id,
+				"n", must.NotFail(types.NewDocument("$gte", int32(3), "$lte", int32(7))),
+			)),
+			sort:  sort,
+			start: 3,
+			ok:    true,
+		},
+		"Greater": {
+			collection: "fs.chunks",
+			filter: must.NotFail(types.NewDocument(
+				"n", must.NotFail(types.NewDocument("$gt", float64(2))),
+				"files_id", "file",
+			)),
+			sort:  sort,
+			start: 3,
+			ok:    true,
+		},
+		"UnknownBucket": {
+			collection: "other.chunks",
+			filter:     must.NotFail(types.NewDocument("files_id", id)),
+			sort:       sort,
+		},
+		"Files": {
+			collection: "fs.files",
+			filter:     must.NotFail(types.NewDocument("files_id", id)),
+			sort:       sort,
+		},
+		"NoSort": {
+			collection: "fs.chunks",
+			filter:     must.NotFail(types.NewDocument("files_id", id)),
+		},
+		"Descending": {
+			collection: "fs.chunks",
+			filter:     must.NotFail(types.NewDocument("files_id", id)),
+			sort:       must.NotFail(types.NewDocument("n", int64(-1))),
+		},
+		"NoFile": {
+			collection: "fs.chunks",
+			filter:     must.NotFail(types.NewDocument("n", int32(1))),
+			sort:       sort,
+		},
+		"FileOperator": {
+			collection: "fs.chunks",
+			filter: must.NotFail(types.NewDocument(
+				"files_id", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray(id)))),
+			)),
+			sort: sort,
+		},
+		"OtherField": {
+			collection: "fs.chunks",
+			filter:     must.NotFail(types.NewDocument("files_id", id, "data", "x")),
+			sort:       sort,
+		},
+		"OtherOperator": {
+			collection: "fs.chunks",
+			filter: must.NotFail(types.NewDocument(
+				"files_id", id,
+				"n", must.NotFail(types.NewDocument("$ne", int32(1))),
+			)),
+			sort: sort,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			start, ok := h.gridFSChunksStart(&common.FindParams{
+				Collection: tc.collection,
+				Filter:     tc.filter,
+				Sort:       tc.sort,
+			})
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.start, start)
+		})
+	}
+}
+
+func TestGridFSChunksIterator(t *testing.T) {
+	t.Parallel()
+
+	chunks := func(ns ...any) []*types.Document {
+		res := make([]*types.Document, len(ns))
+		for i, n := range ns {
+			res[i] = must.NotFail(types.NewDocument("_id", int32(i), "n", n, "data", types.Binary{B: make([]byte, 10)}))
+		}
+
+		return res
+	}
+
+	numbers := func(docs []*types.Document) []int64 {
+		res := make([]int64, len(docs))
+		for i, doc := range docs {
+			n := must.NotFail(doc.Get("n"))
+
+			switch n := n.(type) {
+			case int32:
+				res[i] = int64(n)
+			case int64:
+				res[i] = n
+			}
+		}
+
+		return res
+	}
+
+	for name, tc := range map[string]struct {
+		chunks   []*types.Document
+		start    int64
+		limit    int // common.DefaultSortMemoryLimit if 0
+		expected []int64
+		err      string
+	}{
+		"Ordered": {
+			chunks:   chunks(int32(0), int32(1), int32(2), int32(3)),
+			expected: []int64{0, 1, 2, 3},
+		},
+		"Unordered": {
+			chunks:   chunks(int32(2), int32(0), int64(3), int32(1)),
+			expected: []int64{0, 1, 2, 3},
+		},
+		"Start": {
+			chunks:   chunks(int32(3), int32(5), int32(4)),
+			start:    3,
+			expected: []int64{3, 4, 5},
+		},
+		"Missing": {
+			chunks:   chunks(int32(4), int32(1), int32(0), int32(5)),
+			expected: []int64{0, 1, 4, 5},
+		},
+		"Duplicate": {
+			chunks:   chunks(int32(0), int32(1), int32(1), int32(2)),
+			expected: []int64{0, 1, 1, 2},
+		},
+		"Invalid": {
+			chunks: chunks(int32(0), "1"),
+			err:    `GridFS chunk 1 has invalid n: 1`,
+		},
+		"TooLate": {
+			chunks: chunks(int32(0), int32(1), int32(2), int32(0)),
+			err:    `GridFS chunk 3 with n 0 returned too late`,
+		},
+		"MemoryLimit": {
+			chunks: chunks(int32(3), int32(2), int32(1), int32(0)),
+			limit:  25,
+			err:    `GridFS chunks stored out of order exceeded memory limit of 25 bytes while waiting for chunk 0.`,
+		},
+		"MemoryLimitFreed": {
+			chunks:   chunks(int32(1), int32(0), int32(3), int32(2), int32(5), int32(4)),
+			limit:    15,
+			expected: []int64{0, 1, 2, 3, 4, 5},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			closer := iterator.NewMultiCloser()
+			defer closer.Close()
+
+			limit := tc.limit
+			if limit == 0 {
+				limit = common.DefaultSortMemoryLimit
+			}
+
+			iter := gridFSChunksIterator(iterator.Values(iterator.ForSlice(tc.chunks)), closer, tc.start, limit)
+
+			docs, err := iterator.ConsumeValues(iter)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, numbers(docs))
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -26,10 +27,15 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/decorators/faults"
+	"github.com/FerretDB/FerretDB/internal/backends/decorators/offload"
 	"github.com/FerretDB/FerretDB/internal/backends/decorators/oplog"
 	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
 	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/planner"
+	"github.com/FerretDB/FerretDB/internal/handler/queryshape"
 	"github.com/FerretDB/FerretDB/internal/handler/users"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/ctxutil"
@@ -37,6 +43,7 @@ import (
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/util/password"
+	"github.com/FerretDB/FerretDB/internal/util/reloader"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 )
 
@@ -53,6 +60,12 @@ const (
 
 	// Default session timeout in minutes.
 	logicalSessionTimeoutMinutes = int32(30)
+
+	// Maximum number of query shapes tracked for $queryStats.
+	maxQueryShapes = 5000
+
+	// Maximum number of cached query plans.
+	maxPlanCacheEntries = 5000
 )
 
 // Handler provides a set of methods to process clients' requests sent over wire protocol.
@@ -64,11 +77,16 @@ const (
 type Handler struct {
 	*NewOpts
 
-	b backends.Backend
+	b  backends.Backend
+	la *zap.Logger // for authentication events
 
-	cursors  *cursor.Registry
-	commands map[string]*command
-	wg       sync.WaitGroup
+	cursors       *cursor.Registry
+	operations    *operations
+	queryStats    *queryshape.Collector
+	planCache     *planner.Cache
+	commands      map[string]*command
+	serverVersion *serverVersion
+	wg            sync.WaitGroup
 
 	cappedCleanupStop             chan struct{}
 	cleanupCappedCollectionsDocs  *prometheus.CounterVec
@@ -91,6 +109,22 @@ type NewOpts struct {
 	L             *zap.Logger
 	ConnMetrics   *connmetrics.ConnMetrics
 	StateProvider *state.Provider
+	Reloader      *reloader.Reloader // nil if configuration reload is not supported
+
+	// MongoDB version reported to clients (like "6.0.14"); empty for the current build's version.
+	MongoDBVersion string
+	CompatProfile  CompatProfile // empty for the default one
+
+	// Binary values larger than OffloadThreshold bytes are stored in OffloadStorage, if set.
+	OffloadStorage   offload.Storage
+	OffloadThreshold int
+
+	// Names of GridFS buckets which chunks are streamed without sorting them in memory;
+	// nil for DefaultGridFSBuckets.
+	GridFSBuckets []string
+
+	// Default time limit for operations without maxTimeMS; 0 means no limit.
+	OperationTimeout time.Duration
 
 	// test options
 	DisablePushdown         bool
@@ -100,6 +134,9 @@ type NewOpts struct {
 	EnableNewAuth           bool
 	BatchSize               int
 	MaxBsonObjectSizeBytes  int
+	SortMemoryLimitBytes    int
+	ExactCount              bool             // do not use table statistics for count without filter
+	Faults                  *faults.Injector // injects backend failures, if set
 }
 
 // New returns a new handler.
@@ -109,6 +146,7 @@ func New(opts *NewOpts) (*Handler, error) {
 	}
 
 	if opts.CappedCleanupPercentage >= 100 || opts.CappedCleanupPercentage <= 0 {
+		//checkerrors:ignore // user-facing configuration error
 		return nil, fmt.Errorf(
 			"percentage of documents to cleanup must be in range (0, 100), but %d given",
 			opts.CappedCleanupPercentage,
@@ -119,13 +157,61 @@ func New(opts *NewOpts) (*Handler, error) {
 		opts.MaxBsonObjectSizeBytes = types.MaxDocumentLen
 	}
 
-	b := oplog.NewBackend(opts.Backend, opts.L.Named("oplog"))
+	if opts.SortMemoryLimitBytes == 0 {
+		opts.SortMemoryLimitBytes = common.DefaultSortMemoryLimit
+	}
+
+	if opts.CompatProfile == "" {
+		opts.CompatProfile = CompatProfile(AllCompatProfiles[0])
+	}
+
+	if !slices.Contains(AllCompatProfiles, string(opts.CompatProfile)) {
+		//checkerrors:ignore // user-facing configuration error
+		return nil, fmt.Errorf("unknown compatibility profile %q", opts.CompatProfile)
+	}
+
+	if opts.GridFSBuckets == nil {
+		opts.GridFSBuckets = DefaultGridFSBuckets
+	}
+
+	sv, err := newServerVersion(opts.MongoDBVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	b := opts.Backend
+
+	if opts.Faults != nil {
+		b = faults.NewBackend(b, opts.Faults)
+	}
+
+	if opts.OffloadStorage != nil {
+		if opts.OffloadThreshold <= 0 {
+			//checkerrors:ignore // user-facing configuration error
+			return nil, fmt.Errorf("offload threshold must be positive, but %d given", opts.OffloadThreshold)
+		}
+
+		b = offload.NewBackend(b, &offload.NewBackendParams{
+			Storage:   opts.OffloadStorage,
+			Threshold: opts.OffloadThreshold,
+			L:         opts.L.Named("offload"),
+		})
+	}
+
+	b = oplog.NewBackend(b, opts.L.Named("oplog"))
 
 	h := &Handler{
 		b:       b,
+		la:      opts.L.Named("access"),
 		NewOpts: opts,
 		cursors: cursor.NewRegistry(opts.L.Named("cursors")),
 
+		operations: newOperations(opts.L.Named("operations")),
+
+		queryStats:    queryshape.NewCollector(maxQueryShapes),
+		planCache:     planner.NewCache(maxPlanCacheEntries),
+		serverVersion: sv,
+
 		cappedCleanupStop: make(chan struct{}),
 		cleanupCappedCollectionsDocs: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -258,6 +344,7 @@ func (h *Handler) runCappedCleanup() {
 // It should be called after listener closes all client connections and stops listening.
 func (h *Handler) Close() {
 	h.cursors.Close()
+	h.operations.close()
 	close(h.cappedCleanupStop)
 	h.wg.Wait()
 }
@@ -0,0 +1,221 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlererrors
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// ErrorKind represents the way the classified error should be returned to the client.
+type ErrorKind int
+
+const (
+	_ ErrorKind = iota
+
+	// ErrorKindCommand is returned as the command error (ok: 0).
+	ErrorKindCommand
+
+	// ErrorKindWrite is returned as the element of writeErrors array for the failed document.
+	ErrorKindWrite
+
+	// ErrorKindWriteConcern is returned as writeConcernError field of the successful response.
+	ErrorKindWriteConcern
+)
+
+// Classification represents the result of backend error classification.
+type Classification struct {
+	// KeyPattern and KeyValue are set for duplicate key errors only.
+	KeyPattern *types.Document
+	KeyValue   *types.Document
+
+	// Index is the name of the violated unique index; it is set for duplicate key errors only.
+	Index string
+
+	Labels []string
+	Code   ErrorCode
+	Kind   ErrorKind
+}
+
+// WriteErrorDocument returns a document representation of the write error
+// with the given index and message, and with keyPattern and keyValue fields if they are set.
+func (c *Classification) WriteErrorDocument(index int32, msg string) *types.Document {
+	res := must.NotFail(types.NewDocument(
+		"index", index,
+		"code", int32(c.Code),
+	))
+
+	if c.KeyPattern != nil {
+		res.Set("keyPattern", c.KeyPattern)
+		res.Set("keyValue", c.KeyValue)
+	}
+
+	res.Set("errmsg", msg)
+
+	return res
+}
+
+// DuplicateKeyMessage returns MongoDB-style errmsg of the duplicate key error
+// for the collection with the given namespace.
+func (c *Classification) DuplicateKeyMessage(db, coll string) string {
+	msg := fmt.Sprintf("E11000 duplicate key error collection: %s.%s", db, coll)
+
+	if c.Index != "" {
+		msg += " index: " + c.Index
+	}
+
+	if c.KeyValue != nil && c.KeyValue.Len() > 0 {
+		msg += " dup key: " + types.FormatAnyValue(c.KeyValue)
+	}
+
+	return msg
+}
+
+// sqlStateError represents an error with SQLSTATE code, like *pgconn.PgError.
+type sqlStateError interface {
+	error
+	SQLState() string
+}
+
+// ClassifyError returns the classification of backend error,
+// or nil if the error is not known and should be handled by the caller
+// (typically, returned as InternalError).
+//
+// The given document is the one that caused the error, if any;
+// it is used to fill keyValue of duplicate key errors.
+//
+// Duplicate key errors are returned as DuplicateKey write errors with keyPattern and keyValue
+// of the violated unique index reported by the backend (or _id index, if the backend does not know it).
+// Serialization failures and deadlocks are returned as WriteConflict command error
+// with TransientTransactionError label like MongoDB does for conflicting transactions.
+// Backend connection failures and timeouts are returned as HostUnreachable and NetworkTimeout command errors
+// with RetryableWriteError label; drivers retry both reads and writes on them.
+// The same label is set for backend shutdowns and read-only backends, as they are usually temporary.
+// Unknown outcomes of committed transactions are returned as WriteConcernFailed write concern errors.
+func ClassifyError(err error, doc *types.Document) *Classification {
+	if backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID) {
+		return duplicateKey(doc, err.(*backends.Error).DuplicateKeyIndex()) //nolint:errorlint // checked above
+	}
+
+	var sqlStateErr sqlStateError
+	if errors.As(err, &sqlStateErr) {
+		switch sqlStateErr.SQLState() {
+		case "23505": // unique_violation
+			return duplicateKey(doc, nil)
+
+		case "40001", "40P01": // serialization_failure, deadlock_detected
+			return &Classification{
+				Labels: []string{LabelTransientTransactionError},
+				Code:   ErrWriteConflict,
+				Kind:   ErrorKindCommand,
+			}
+
+		case "57014": // query_canceled
+			return &Classification{
+				Code: ErrMaxTimeMSExpired,
+				Kind: ErrorKindCommand,
+			}
+
+		case "57P01", "57P02": // admin_shutdown, crash_shutdown
+			return &Classification{
+				Labels: []string{LabelRetryableWriteError},
+				Code:   ErrInterruptedAtShutdown,
+				Kind:   ErrorKindCommand,
+			}
+
+		case "25006": // read_only_sql_transaction
+			return &Classification{
+				Labels: []string{LabelRetryableWriteError},
+				Code:   ErrNotWritablePrimary,
+				Kind:   ErrorKindCommand,
+			}
+
+		case "08007": // transaction_resolution_unknown
+			return &Classification{
+				Code: ErrWriteConcernFailed,
+				Kind: ErrorKindWriteConcern,
+			}
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		code := ErrHostUnreachable
+		if netErr.Timeout() {
+			code = ErrNetworkTimeout
+		}
+
+		return &Classification{
+			Labels: []string{LabelRetryableWriteError},
+			Code:   code,
+			Kind:   ErrorKindCommand,
+		}
+	}
+
+	return nil
+}
+
+// duplicateKey returns the classification of duplicate key error for the given document
+// and the violated unique index.
+//
+// If index is nil, the unique index on _id is assumed.
+// Missing fields of the document are reported as nulls in keyValue.
+func duplicateKey(doc *types.Document, index *backends.IndexInfo) *Classification {
+	if index == nil {
+		index = &backends.IndexInfo{
+			Name:   backends.DefaultIndexName,
+			Key:    []backends.IndexKeyPair{{Field: "_id"}},
+			Unique: true,
+		}
+	}
+
+	res := &Classification{
+		KeyPattern: types.MakeDocument(len(index.Key)),
+		KeyValue:   types.MakeDocument(len(index.Key)),
+		Index:      index.Name,
+		Code:       ErrDuplicateKeyInsert,
+		Kind:       ErrorKindWrite,
+	}
+
+	for _, key := range index.Key {
+		order := int32(1)
+		if key.Descending {
+			order = -1
+		}
+
+		res.KeyPattern.Set(key.Field, order)
+
+		if doc == nil {
+			continue
+		}
+
+		var v any = types.Null
+
+		if path, err := types.NewPathFromString(key.Field); err == nil {
+			if pv, err := doc.GetByPath(path); err == nil {
+				v = pv
+			}
+		}
+
+		res.KeyValue.Set(key.Field, v)
+	}
+
+	return res
+}
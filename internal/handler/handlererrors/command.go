@@ -26,9 +26,10 @@ import (
 type CommandError struct {
 	// the order of fields is weird to make the struct smaller due to alignment
 
-	err  error
-	info *ErrInfo
-	code ErrorCode
+	err    error
+	info   *ErrInfo
+	labels []string
+	code   ErrorCode
 }
 
 // There should not be NewCommandError function variant that accepts printf-like format specifiers.
@@ -79,6 +80,11 @@ func (e *CommandError) Code() ErrorCode {
 	return e.code
 }
 
+// Labels returns error labels, like RetryableWriteError.
+func (e *CommandError) Labels() []string {
+	return e.labels
+}
+
 // Error implements error interface.
 func (e *CommandError) Error() string {
 	return fmt.Sprintf("%[1]s (%[1]d): %[2]v", e.code, e.err)
@@ -96,6 +102,15 @@ func (e *CommandError) Document() *types.Document {
 		d.Set("codeName", e.code.String())
 	}
 
+	if len(e.labels) > 0 {
+		labels := types.MakeArray(len(e.labels))
+		for _, l := range e.labels {
+			labels.Append(l)
+		}
+
+		d.Set("errorLabels", labels)
+	}
+
 	return d
 }
 
@@ -18,6 +18,7 @@ package handlererrors
 import (
 	"errors"
 
+	"github.com/FerretDB/FerretDB/internal/bson"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
@@ -36,6 +37,9 @@ const (
 	// ErrBadValue indicates wrong input.
 	ErrBadValue = ErrorCode(2) // BadValue
 
+	// ErrHostUnreachable indicates that the backend connection failed.
+	ErrHostUnreachable = ErrorCode(6) // HostUnreachable
+
 	// ErrFailedToParse indicates user input parsing failure.
 	ErrFailedToParse = ErrorCode(9) // FailedToParse
 
@@ -48,12 +52,21 @@ const (
 	// ErrTypeMismatch for $sort indicates that the expression in the $sort is not an object.
 	ErrTypeMismatch = ErrorCode(14) // TypeMismatch
 
+	// ErrOverflow indicates that documents in the request are nested too deeply.
+	ErrOverflow = ErrorCode(15) // Overflow
+
 	// ErrAuthenticationFailed indicates failed authentication.
 	ErrAuthenticationFailed = ErrorCode(18) // AuthenticationFailed
 
 	// ErrIllegalOperation indicated that operation is illegal.
 	ErrIllegalOperation = ErrorCode(20) // IllegalOperation
 
+	// ErrInvalidBSON indicates that the request contains structurally invalid BSON.
+	ErrInvalidBSON = ErrorCode(22) // InvalidBSON
+
+	// ErrAlreadyInitialized indicates that the object is already initialized.
+	ErrAlreadyInitialized = ErrorCode(23) // AlreadyInitialized
+
 	// ErrNamespaceNotFound indicates that a collection is not found.
 	ErrNamespaceNotFound = ErrorCode(26) // NamespaceNotFound
 
@@ -69,6 +82,9 @@ const (
 	// ErrCursorNotFound indicates that cursor is not found.
 	ErrCursorNotFound = ErrorCode(43) // CursorNotFound
 
+	// ErrNoMatchingDocument indicates that there is no document to update.
+	ErrNoMatchingDocument = ErrorCode(47) // NoMatchingDocument
+
 	// ErrNamespaceExists indicates that the collection already exists.
 	ErrNamespaceExists = ErrorCode(48) // NamespaceExists
 
@@ -87,6 +103,9 @@ const (
 	// ErrCommandNotFound indicates unknown command input.
 	ErrCommandNotFound = ErrorCode(59) // CommandNotFound
 
+	// ErrWriteConcernFailed indicates that the outcome of the write is unknown.
+	ErrWriteConcernFailed = ErrorCode(64) // WriteConcernFailed
+
 	// ErrImmutableField indicates that _id field is immutable.
 	ErrImmutableField = ErrorCode(66) // ImmutableField
 
@@ -108,9 +127,15 @@ const (
 	// ErrIndexKeySpecsConflict indicates that index build process failed due to key specs conflict.
 	ErrIndexKeySpecsConflict = ErrorCode(86) // IndexKeySpecsConflict
 
+	// ErrNetworkTimeout indicates that the backend did not respond in time.
+	ErrNetworkTimeout = ErrorCode(89) // NetworkTimeout
+
 	// ErrOperationFailed indicates that the operation failed.
 	ErrOperationFailed = ErrorCode(96) // OperationFailed
 
+	// ErrWriteConflict indicates that the write conflicted with a concurrent operation.
+	ErrWriteConflict = ErrorCode(112) // WriteConflict
+
 	// ErrDocumentValidationFailure indicates that document validation failed.
 	ErrDocumentValidationFailure = ErrorCode(121) // DocumentValidationFailure
 
@@ -126,18 +151,201 @@ const (
 	// ErrNotImplemented indicates that a flag or command is not implemented.
 	ErrNotImplemented = ErrorCode(238) // NotImplemented
 
+	// ErrConversionFailure indicates that the value could not be converted to another type.
+	ErrConversionFailure = ErrorCode(241) // ConversionFailure
+
+	// ErrQueryExceededMemoryLimitNoDiskUseAllowed indicates that a sort exceeded the memory limit
+	// without allowDiskUse.
+	ErrQueryExceededMemoryLimitNoDiskUseAllowed = ErrorCode(292) // QueryExceededMemoryLimitNoDiskUseAllowed
+
+	// ErrAPIVersionError indicates that the requested API version is not supported.
+	ErrAPIVersionError = ErrorCode(322) // APIVersionError
+
+	// ErrAPIStrictError indicates that the command or its field is not part of the requested API version.
+	ErrAPIStrictError = ErrorCode(323) // APIStrictError
+
 	// ErrMechanismUnavailable indicates that the authentication mechanism is unavailable.
 	ErrMechanismUnavailable = ErrorCode(334)
 
 	// ErrUnsupportedOpQueryCommand indicates that given op query is not supported.
 	ErrUnsupportedOpQueryCommand = ErrorCode(352) // UnsupportedOpQueryCommand
 
+	// ErrNonConformantBSON indicates that the request contains BSON with invalid UTF-8 strings
+	// or duplicate field names.
+	ErrNonConformantBSON = ErrorCode(378) // NonConformantBSON
+
 	// ErrIndexesWrongType indicates that indexes parameter has wrong type.
 	ErrIndexesWrongType = ErrorCode(10065) // Location10065
 
+	// ErrNotWritablePrimary indicates that the backend does not accept writes.
+	ErrNotWritablePrimary = ErrorCode(10107) // NotWritablePrimary
+
 	// ErrDuplicateKeyInsert indicates duplicate key violation on inserting document.
 	ErrDuplicateKeyInsert = ErrorCode(11000) // DuplicateKey
 
+	// ErrInterruptedAtShutdown indicates that the operation was interrupted by the backend shutdown.
+	ErrInterruptedAtShutdown = ErrorCode(11600) // InterruptedAtShutdown
+
+	// ErrStageMergeNoMatch indicates that $merge stage with whenNotMatched: fail
+	// found no matching document in the target collection.
+	ErrStageMergeNoMatch = ErrorCode(13113) // Location13113
+
+	// ErrMultiplyNotNumeric indicates that $multiply argument is not a number.
+	ErrMultiplyNotNumeric = ErrorCode(16555) // Location16555
+
+	// ErrDivideNotNumeric indicates that $divide argument is not a number.
+	ErrDivideNotNumeric = ErrorCode(16609) // Location16609
+
+	// ErrModByZero indicates that $mod divisor is zero.
+	ErrModByZero = ErrorCode(16610) // Location16610
+
+	// ErrModNotNumeric indicates that $mod argument is not a number.
+	ErrModNotNumeric = ErrorCode(16611) // Location16611
+
+	// ErrLetBadArgument indicates that $let argument is not an object.
+	ErrLetBadArgument = ErrorCode(16874) // Location16874
+
+	// ErrLetUnknownField indicates that $let argument contains an unknown field.
+	ErrLetUnknownField = ErrorCode(16875) // Location16875
+
+	// ErrLetMissingVars indicates that $let 'vars' parameter is missing.
+	ErrLetMissingVars = ErrorCode(16876) // Location16876
+
+	// ErrLetMissingIn indicates that $let 'in' parameter is missing.
+	ErrLetMissingIn = ErrorCode(16877) // Location16877
+
+	// ErrMapBadArgument indicates that $map argument is not an object.
+	ErrMapBadArgument = ErrorCode(16878) // Location16878
+
+	// ErrMapUnknownField indicates that $map argument contains an unknown field.
+	ErrMapUnknownField = ErrorCode(16879) // Location16879
+
+	// ErrMapMissingInput indicates that $map 'input' parameter is missing.
+	ErrMapMissingInput = ErrorCode(16880) // Location16880
+
+	// ErrMapMissingIn indicates that $map 'in' parameter is missing.
+	ErrMapMissingIn = ErrorCode(16882) // Location16882
+
+	// ErrMapInputNotArray indicates that $map input is not an array.
+	ErrMapInputNotArray = ErrorCode(16883) // Location16883
+
+	// ErrFilterBadArgument indicates that $filter argument is not an object.
+	ErrFilterBadArgument = ErrorCode(28646) // Location28646
+
+	// ErrFilterUnknownField indicates that $filter argument contains an unknown field.
+	ErrFilterUnknownField = ErrorCode(28647) // Location28647
+
+	// ErrFilterMissingInput indicates that $filter 'input' parameter is missing.
+	ErrFilterMissingInput = ErrorCode(28648) // Location28648
+
+	// ErrFilterMissingCond indicates that $filter 'cond' parameter is missing.
+	ErrFilterMissingCond = ErrorCode(28650) // Location28650
+
+	// ErrFilterInputNotArray indicates that $filter input is not an array.
+	ErrFilterInputNotArray = ErrorCode(28651) // Location28651
+
+	// ErrSqrtNegative indicates that $sqrt argument is negative.
+	ErrSqrtNegative = ErrorCode(28714) // Location28714
+
+	// ErrLogArgNotNumeric indicates that $log argument is not a number.
+	ErrLogArgNotNumeric = ErrorCode(28756) // Location28756
+
+	// ErrLogBaseNotNumeric indicates that $log base is not a number.
+	ErrLogBaseNotNumeric = ErrorCode(28757) // Location28757
+
+	// ErrLogArgNotPositive indicates that $log argument is not a positive number.
+	ErrLogArgNotPositive = ErrorCode(28758) // Location28758
+
+	// ErrLogInvalidBase indicates that $log base is not a positive number or equal to 1.
+	ErrLogInvalidBase = ErrorCode(28759) // Location28759
+
+	// ErrPowBaseNotNumeric indicates that $pow base is not a number.
+	ErrPowBaseNotNumeric = ErrorCode(28762) // Location28762
+
+	// ErrPowExponentNotNumeric indicates that $pow exponent is not a number.
+	ErrPowExponentNotNumeric = ErrorCode(28763) // Location28763
+
+	// ErrPowZeroNegativeExponent indicates that $pow has a base of 0 and a negative exponent.
+	ErrPowZeroNegativeExponent = ErrorCode(28764) // Location28764
+
+	// ErrSqrtNotNumeric indicates that $sqrt argument is not a number.
+	ErrSqrtNotNumeric = ErrorCode(28765) // Location28765
+
+	// ErrRegexMissingInput indicates that $regexFind or $regexMatch is missing 'input' parameter.
+	ErrRegexMissingInput = ErrorCode(31022) // Location31022
+
+	// ErrRegexMissingRegex indicates that $regexFind or $regexMatch is missing 'regex' parameter.
+	ErrRegexMissingRegex = ErrorCode(31023) // Location31023
+
+	// ErrRegexUnknownArg indicates that $regexFind or $regexMatch has an unknown argument.
+	ErrRegexUnknownArg = ErrorCode(31024) // Location31024
+
+	// ErrZipBadArgument indicates that $zip argument is not an object.
+	ErrZipBadArgument = ErrorCode(34460) // Location34460
+
+	// ErrZipInputsNotArray indicates that $zip 'inputs' parameter is not an array.
+	ErrZipInputsNotArray = ErrorCode(34461) // Location34461
+
+	// ErrZipDefaultsNotArray indicates that $zip 'defaults' parameter is not an array.
+	ErrZipDefaultsNotArray = ErrorCode(34462) // Location34462
+
+	// ErrZipUseLongestLengthNotBool indicates that $zip 'useLongestLength' parameter is not a boolean.
+	ErrZipUseLongestLengthNotBool = ErrorCode(34463) // Location34463
+
+	// ErrZipUnknownField indicates that $zip argument contains an unknown field.
+	ErrZipUnknownField = ErrorCode(34464) // Location34464
+
+	// ErrZipMissingInputs indicates that $zip 'inputs' parameter is missing or empty.
+	ErrZipMissingInputs = ErrorCode(34465) // Location34465
+
+	// ErrZipDefaultsWithoutLongest indicates that $zip 'defaults' is set without 'useLongestLength'.
+	ErrZipDefaultsWithoutLongest = ErrorCode(34466) // Location34466
+
+	// ErrZipDefaultsLength indicates that $zip 'defaults' and 'inputs' have different lengths.
+	ErrZipDefaultsLength = ErrorCode(34467) // Location34467
+
+	// ErrZipInputNotArray indicates that $zip input is not an array.
+	ErrZipInputNotArray = ErrorCode(34468) // Location34468
+
+	// ErrReduceBadArgument indicates that $reduce argument is not an object.
+	ErrReduceBadArgument = ErrorCode(40075) // Location40075
+
+	// ErrReduceUnknownField indicates that $reduce argument contains an unknown field.
+	ErrReduceUnknownField = ErrorCode(40076) // Location40076
+
+	// ErrReduceMissingInput indicates that $reduce 'input' parameter is missing.
+	ErrReduceMissingInput = ErrorCode(40077) // Location40077
+
+	// ErrReduceMissingInitialValue indicates that $reduce 'initialValue' parameter is missing.
+	ErrReduceMissingInitialValue = ErrorCode(40078) // Location40078
+
+	// ErrReduceMissingIn indicates that $reduce 'in' parameter is missing.
+	ErrReduceMissingIn = ErrorCode(40079) // Location40079
+
+	// ErrReduceInputNotArray indicates that $reduce input is not an array.
+	ErrReduceInputNotArray = ErrorCode(40080) // Location40080
+
+	// ErrSplitInputNotString indicates that $split input is not a string.
+	ErrSplitInputNotString = ErrorCode(40085) // Location40085
+
+	// ErrSplitDelimiterNotString indicates that $split delimiter is not a string.
+	ErrSplitDelimiterNotString = ErrorCode(40086) // Location40086
+
+	// ErrSplitEmptyDelimiter indicates that $split delimiter is an empty string.
+	ErrSplitEmptyDelimiter = ErrorCode(40087) // Location40087
+
+	// ErrIndexOfCPStringNotString indicates that $indexOfCP string argument is not a string.
+	ErrIndexOfCPStringNotString = ErrorCode(40093) // Location40093
+
+	// ErrIndexOfCPSubstringNotString indicates that $indexOfCP substring argument is not a string.
+	ErrIndexOfCPSubstringNotString = ErrorCode(40094) // Location40094
+
+	// ErrIndexOfCPIndexNotIntegral indicates that $indexOfCP start or end index is not integral.
+	ErrIndexOfCPIndexNotIntegral = ErrorCode(40096) // Location40096
+
+	// ErrIndexOfCPIndexNegative indicates that $indexOfCP start or end index is negative.
+	ErrIndexOfCPIndexNegative = ErrorCode(40097) // Location40097
+
 	// ErrSetBadExpression indicates set expression is not object.
 	ErrSetBadExpression = ErrorCode(40272) // Location40272
 
@@ -187,21 +395,85 @@ const (
 	// wrong amount of arguments.
 	ErrOperatorWrongLenOfArgs = ErrorCode(16020) // Location16020
 
+	// ErrExpressionNotDate indicates that the date expression operator got a value that can't be converted to date.
+	ErrExpressionNotDate = ErrorCode(16006) // Location16006
+
 	// ErrFieldPathInvalidName indicates that FieldPath is invalid.
 	ErrFieldPathInvalidName = ErrorCode(16410) // Location16410
 
 	// ErrGroupInvalidFieldPath indicates invalid path is given for group _id.
 	ErrGroupInvalidFieldPath = ErrorCode(16872) // Location16872
 
+	// ErrStageOutInvalidArg indicates that $out stage argument is neither a string nor a document.
+	ErrStageOutInvalidArg = ErrorCode(16990) // Location16990
+
+	// ErrStageRedactInvalidResult indicates that $redact expression returned a value
+	// other than $$DESCEND, $$PRUNE or $$KEEP.
+	ErrStageRedactInvalidResult = ErrorCode(17053) // Location17053
+
+	// ErrCondMissingIf indicates that $cond is missing 'if' parameter.
+	ErrCondMissingIf = ErrorCode(17080) // Location17080
+
+	// ErrCondMissingThen indicates that $cond is missing 'then' parameter.
+	ErrCondMissingThen = ErrorCode(17081) // Location17081
+
+	// ErrCondMissingElse indicates that $cond is missing 'else' parameter.
+	ErrCondMissingElse = ErrorCode(17082) // Location17082
+
+	// ErrCondUnknownArg indicates that $cond has an unrecognized parameter.
+	ErrCondUnknownArg = ErrorCode(17083) // Location17083
+
 	// ErrGroupUndefinedVariable indicates the variable is not defined.
 	ErrGroupUndefinedVariable = ErrorCode(17276) // Location17276
 
+	// ErrProjectionMetaNotString indicates that $meta argument is not a string.
+	ErrProjectionMetaNotString = ErrorCode(17307) // Location17307
+
+	// ErrProjectionMetaInvalid indicates unknown $meta argument.
+	ErrProjectionMetaInvalid = ErrorCode(17308) // Location17308
+
+	// ErrDateFormatNotString indicates that $dateToString format is not a string.
+	ErrDateFormatNotString = ErrorCode(18533) // Location18533
+
+	// ErrDateToStringUnknownArg indicates that $dateToString has unrecognized argument.
+	ErrDateToStringUnknownArg = ErrorCode(18534) // Location18534
+
+	// ErrDateFormatUnmatchedPercent indicates that date format string ends with a single '%'.
+	ErrDateFormatUnmatchedPercent = ErrorCode(18535) // Location18535
+
+	// ErrDateFormatInvalidChar indicates that date format string contains unknown format specifier.
+	ErrDateFormatInvalidChar = ErrorCode(18536) // Location18536
+
+	// ErrDateToStringMissingDate indicates that $dateToString has no date argument.
+	ErrDateToStringMissingDate = ErrorCode(18628) // Location18628
+
+	// ErrDateToStringInvalidArg indicates that $dateToString argument is not a document.
+	ErrDateToStringInvalidArg = ErrorCode(18629) // Location18629
+
 	// ErrInvalidArg indicates invalid argument in projection document.
 	ErrInvalidArg = ErrorCode(28667) // Location28667
 
 	// ErrSliceFirstArg for $slice indicates that the first argument is not an array.
 	ErrSliceFirstArg = ErrorCode(28724) // Location28724
 
+	// ErrStageSampleInvalidArg indicates that $sample stage argument is not an object.
+	ErrStageSampleInvalidArg = ErrorCode(28745) // Location28745
+
+	// ErrStageSampleSizeNotNumber indicates that $sample stage size is not a number.
+	ErrStageSampleSizeNotNumber = ErrorCode(28746) // Location28746
+
+	// ErrStageSampleNegativeSize indicates that $sample stage size is negative.
+	ErrStageSampleNegativeSize = ErrorCode(28747) // Location28747
+
+	// ErrStageSampleUnknownOption indicates that $sample stage has unknown option.
+	ErrStageSampleUnknownOption = ErrorCode(28748) // Location28748
+
+	// ErrStageSampleMissingSize indicates that $sample stage size is missing.
+	ErrStageSampleMissingSize = ErrorCode(28749) // Location28749
+
+	// ErrStageIndexStatsInvalidArg indicates that $indexStats stage argument is not an empty object.
+	ErrStageIndexStatsInvalidArg = ErrorCode(28803) // Location28803
+
 	// ErrStageUnsetNoPath indicates that $unwind aggregation stage is empty.
 	ErrStageUnsetNoPath = ErrorCode(31119) // Location31119
 
@@ -211,6 +483,12 @@ const (
 	// ErrStageUnsetInvalidType indicates that $unset stage arguments has unexpected type.
 	ErrStageUnsetInvalidType = ErrorCode(31002) // Location31002
 
+	// ErrDateFromPartsValueRange indicates that $dateFromParts argument is out of range.
+	ErrDateFromPartsValueRange = ErrorCode(31034) // Location31034
+
+	// ErrSortIllegalMeta indicates that $meta keyword could not be used for sorting.
+	ErrSortIllegalMeta = ErrorCode(31138) // Location31138
+
 	// ErrStageUnwindNoPath indicates that $unwind aggregation stage is empty.
 	ErrStageUnwindNoPath = ErrorCode(28812) // Location28812
 
@@ -231,6 +509,18 @@ const (
 	// while projection document already marked as inclusion.
 	ErrProjectionExIn = ErrorCode(31254) // Location31254
 
+	// ErrSliceProjectionSkipNotNumber indicates that $slice projection skip argument is not a number.
+	ErrSliceProjectionSkipNotNumber = ErrorCode(31257) // Location31257
+
+	// ErrSliceProjectionLimitNotNumber indicates that $slice projection limit argument is not a number.
+	ErrSliceProjectionLimitNotNumber = ErrorCode(31258) // Location31258
+
+	// ErrSliceProjectionLimitNotPositive indicates that $slice projection limit argument is not positive.
+	ErrSliceProjectionLimitNotPositive = ErrorCode(31259) // Location31259
+
+	// ErrSliceProjectionInvalid indicates that $slice projection array argument is not [skip, limit].
+	ErrSliceProjectionInvalid = ErrorCode(31272) // Location31272
+
 	// ErrAggregatePositionalProject indicates that positional projection cannot be used in aggregation.
 	ErrAggregatePositionalProject = ErrorCode(31324) // Location31324
 
@@ -244,6 +534,34 @@ const (
 	// ErrExclusionPositionalProjection indicates that exclusion cannot use positional projection.
 	ErrExclusionPositionalProjection = ErrorCode(31395) // Location31395
 
+	// ErrSwitchInvalidArg indicates that $switch argument is not an object.
+	ErrSwitchInvalidArg = ErrorCode(40060) // Location40060
+
+	// ErrSwitchBranchesNotArray indicates that $switch branches is not an array.
+	ErrSwitchBranchesNotArray = ErrorCode(40061) // Location40061
+
+	// ErrSwitchBranchNotObject indicates that $switch branch is not an object.
+	ErrSwitchBranchNotObject = ErrorCode(40062) // Location40062
+
+	// ErrSwitchBranchUnknownArg indicates that $switch branch has an unknown argument.
+	ErrSwitchBranchUnknownArg = ErrorCode(40063) // Location40063
+
+	// ErrSwitchBranchMissingCase indicates that $switch branch is missing 'case' expression.
+	ErrSwitchBranchMissingCase = ErrorCode(40064) // Location40064
+
+	// ErrSwitchBranchMissingThen indicates that $switch branch is missing 'then' expression.
+	ErrSwitchBranchMissingThen = ErrorCode(40065) // Location40065
+
+	// ErrSwitchNoMatchingBranch indicates that no $switch branch matched the input
+	// and no default was specified; $bucket stage returns it for values outside of boundaries.
+	ErrSwitchNoMatchingBranch = ErrorCode(40066) // Location40066
+
+	// ErrSwitchUnknownArg indicates that $switch has an unknown argument.
+	ErrSwitchUnknownArg = ErrorCode(40067) // Location40067
+
+	// ErrSwitchNoBranches indicates that $switch has no branches.
+	ErrSwitchNoBranches = ErrorCode(40068) // Location40068
+
 	// ErrStageCountNonString indicates that $count aggregation stage expected string.
 	ErrStageCountNonString = ErrorCode(40156) // Location40156
 
@@ -256,19 +574,94 @@ const (
 	// ErrStageCountBadValue indicates that $count stage contains invalid value.
 	ErrStageCountBadValue = ErrorCode(40160) // Location40160
 
+	// ErrStageFacetInvalid indicates that $facet specification is not a non-empty document.
+	ErrStageFacetInvalid = ErrorCode(40169) // Location40169
+
+	// ErrStageFacetNotArray indicates that $facet sub-pipeline is not an array.
+	ErrStageFacetNotArray = ErrorCode(40170) // Location40170
+
+	// ErrStageFacetInvalidElement indicates that $facet sub-pipeline contains a non-document element.
+	ErrStageFacetInvalidElement = ErrorCode(40171) // Location40171
+
 	// ErrAddFieldsExpressionWrongAmountOfArgs indicates that $addFields stage expression contain invalid
 	// amount of arguments.
 	ErrAddFieldsExpressionWrongAmountOfArgs = ErrorCode(40181) // Location40181
 
+	// ErrStageBucketBoundariesNotConstant indicates that $bucket stage boundaries contain a non-constant value.
+	ErrStageBucketBoundariesNotConstant = ErrorCode(40191) // Location40191
+
+	// ErrStageBucketBoundariesTooFew indicates that $bucket stage has less than two boundaries.
+	ErrStageBucketBoundariesTooFew = ErrorCode(40192) // Location40192
+
+	// ErrStageBucketBoundariesMixedTypes indicates that $bucket stage boundaries have different types.
+	ErrStageBucketBoundariesMixedTypes = ErrorCode(40193) // Location40193
+
+	// ErrStageBucketBoundariesNotSorted indicates that $bucket stage boundaries are not sorted in ascending order.
+	ErrStageBucketBoundariesNotSorted = ErrorCode(40194) // Location40194
+
+	// ErrStageBucketDefaultNotConstant indicates that $bucket stage default is not a constant.
+	ErrStageBucketDefaultNotConstant = ErrorCode(40195) // Location40195
+
+	// ErrStageBucketOutputNotObject indicates that $bucket stage output is not a document.
+	ErrStageBucketOutputNotObject = ErrorCode(40196) // Location40196
+
+	// ErrStageBucketUnknownOption indicates that $bucket stage has unknown option.
+	ErrStageBucketUnknownOption = ErrorCode(40197) // Location40197
+
+	// ErrStageBucketMissingRequired indicates that $bucket stage groupBy or boundaries is missing.
+	ErrStageBucketMissingRequired = ErrorCode(40198) // Location40198
+
+	// ErrStageBucketDefaultInRange indicates that $bucket stage default is within boundaries.
+	ErrStageBucketDefaultInRange = ErrorCode(40199) // Location40199
+
+	// ErrStageBucketBoundariesNotArray indicates that $bucket stage boundaries is not an array.
+	ErrStageBucketBoundariesNotArray = ErrorCode(40200) // Location40200
+
+	// ErrStageBucketInvalid indicates that $bucket stage argument is not a document.
+	ErrStageBucketInvalid = ErrorCode(40201) // Location40201
+
+	// ErrStageBucketGroupByInvalid indicates that $bucket stage groupBy is not a path or an expression.
+	ErrStageBucketGroupByInvalid = ErrorCode(40202) // Location40202
+
+	// ErrNoTextScoreMetadata indicates that text score metadata is requested for a query without $text.
+	ErrNoTextScoreMetadata = ErrorCode(40218) // Location40218
+
 	// ErrStageGroupUnaryOperator indicates that $sum is a unary operator.
 	ErrStageGroupUnaryOperator = ErrorCode(40237) // Location40237
 
 	// ErrStageGroupMultipleAccumulator indicates that group field must specify one accumulator.
 	ErrStageGroupMultipleAccumulator = ErrorCode(40238) // Location40238
 
+	// ErrStageBucketAutoGroupByInvalid indicates that $bucketAuto stage groupBy is not a path or an expression.
+	ErrStageBucketAutoGroupByInvalid = ErrorCode(40239) // Location40239
+
+	// ErrStageBucketAutoInvalid indicates that $bucketAuto stage argument is not a document.
+	ErrStageBucketAutoInvalid = ErrorCode(40240) // Location40240
+
+	// ErrStageBucketAutoBucketsNotNumber indicates that $bucketAuto stage buckets is not a number.
+	ErrStageBucketAutoBucketsNotNumber = ErrorCode(40241) // Location40241
+
+	// ErrStageBucketAutoBucketsNotInt32 indicates that $bucketAuto stage buckets is not a 32-bit integer.
+	ErrStageBucketAutoBucketsNotInt32 = ErrorCode(40242) // Location40242
+
+	// ErrStageBucketAutoBucketsNotPositive indicates that $bucketAuto stage buckets is not positive.
+	ErrStageBucketAutoBucketsNotPositive = ErrorCode(40243) // Location40243
+
+	// ErrStageBucketAutoOutputNotObject indicates that $bucketAuto stage output is not a document.
+	ErrStageBucketAutoOutputNotObject = ErrorCode(40244) // Location40244
+
+	// ErrStageBucketAutoUnknownOption indicates that $bucketAuto stage has unknown option.
+	ErrStageBucketAutoUnknownOption = ErrorCode(40245) // Location40245
+
+	// ErrStageBucketAutoMissingRequired indicates that $bucketAuto stage groupBy or buckets is missing.
+	ErrStageBucketAutoMissingRequired = ErrorCode(40246) // Location40246
+
 	// ErrStageGroupInvalidAccumulator indicates invalid accumulator field.
 	ErrStageGroupInvalidAccumulator = ErrorCode(40234) // Location40234
 
+	// ErrStageLookupInvalid indicates that $lookup specification is not a document.
+	ErrStageLookupInvalid = ErrorCode(40319) // Location40319
+
 	// ErrStageInvalid indicates invalid aggregation pipeline stage.
 	ErrStageInvalid = ErrorCode(40323) // Location40323
 
@@ -278,21 +671,129 @@ const (
 	// ErrInvalidFieldPath indicates that the field path is not valid.
 	ErrInvalidFieldPath = ErrorCode(40353) // Location40353
 
+	// ErrArrayToObjectNotArray indicates that $arrayToObject input is not an array.
+	ErrArrayToObjectNotArray = ErrorCode(40386) // Location40386
+
+	// ErrObjectToArrayNotDocument indicates that $objectToArray operator input is not a document.
+	ErrObjectToArrayNotDocument = ErrorCode(40390) // Location40390
+
+	// ErrArrayToObjectMixedFormat indicates that $arrayToObject input contains both arrays and documents.
+	ErrArrayToObjectMixedFormat = ErrorCode(40391) // Location40391
+
+	// ErrArrayToObjectBadKeysCount indicates that $arrayToObject input document does not have exactly two fields.
+	ErrArrayToObjectBadKeysCount = ErrorCode(40392) // Location40392
+
+	// ErrArrayToObjectMissingKeys indicates that $arrayToObject input document does not have 'k' or 'v' field.
+	ErrArrayToObjectMissingKeys = ErrorCode(40393) // Location40393
+
+	// ErrArrayToObjectKeyNotString indicates that $arrayToObject input document 'k' field is not a string.
+	ErrArrayToObjectKeyNotString = ErrorCode(40394) // Location40394
+
+	// ErrArrayToObjectBadPairSize indicates that $arrayToObject input pair is not an array of size 2.
+	ErrArrayToObjectBadPairSize = ErrorCode(40395) // Location40395
+
+	// ErrArrayToObjectPairKeyNotString indicates that $arrayToObject input pair key is not a string.
+	ErrArrayToObjectPairKeyNotString = ErrorCode(40397) // Location40397
+
+	// ErrArrayToObjectBadElement indicates that $arrayToObject input element is neither an array nor a document.
+	ErrArrayToObjectBadElement = ErrorCode(40398) // Location40398
+
 	// ErrMissingField indicates that the required field in document is missing.
 	ErrMissingField = ErrorCode(40414) // Location40414
 
 	// ErrFailedToParseInput indicates invalid input (absent or malformed fields).
 	ErrFailedToParseInput = ErrorCode(40415) // Location40415
 
+	// ErrTimezoneUnrecognized indicates that time zone identifier is not recognized.
+	ErrTimezoneUnrecognized = ErrorCode(40485) // Location40485
+
+	// ErrDateFromPartsMixedISO indicates that $dateFromParts mixes natural and ISO date parts.
+	ErrDateFromPartsMixedISO = ErrorCode(40489) // Location40489
+
+	// ErrDateFromPartsNotInteger indicates that $dateFromParts argument is not an integer.
+	ErrDateFromPartsNotInteger = ErrorCode(40515) // Location40515
+
+	// ErrDateFromPartsMissingYear indicates that $dateFromParts has neither year nor isoWeekYear argument.
+	ErrDateFromPartsMissingYear = ErrorCode(40516) // Location40516
+
+	// ErrTimezoneNotString indicates that time zone is not a string.
+	ErrTimezoneNotString = ErrorCode(40517) // Location40517
+
+	// ErrDateFromPartsUnknownArg indicates that $dateFromParts has unrecognized argument.
+	ErrDateFromPartsUnknownArg = ErrorCode(40518) // Location40518
+
+	// ErrDateFromPartsInvalidArg indicates that $dateFromParts argument is not a document.
+	ErrDateFromPartsInvalidArg = ErrorCode(40519) // Location40519
+
+	// ErrDateToPartsUnknownArg indicates that $dateToParts has unrecognized argument.
+	ErrDateToPartsUnknownArg = ErrorCode(40520) // Location40520
+
+	// ErrDateToPartsISO8601NotBool indicates that $dateToParts iso8601 argument is not a boolean.
+	ErrDateToPartsISO8601NotBool = ErrorCode(40521) // Location40521
+
+	// ErrDateToPartsMissingDate indicates that $dateToParts has no date argument.
+	ErrDateToPartsMissingDate = ErrorCode(40522) // Location40522
+
+	// ErrDateFromPartsYearRange indicates that $dateFromParts year is out of range.
+	ErrDateFromPartsYearRange = ErrorCode(40523) // Location40523
+
+	// ErrDateToPartsInvalidArg indicates that $dateToParts argument is not a document.
+	ErrDateToPartsInvalidArg = ErrorCode(40524) // Location40524
+
+	// ErrDateFromStringInvalidArg indicates that $dateFromString argument is not a document.
+	ErrDateFromStringInvalidArg = ErrorCode(40540) // Location40540
+
+	// ErrDateFromStringUnknownArg indicates that $dateFromString has unrecognized argument.
+	ErrDateFromStringUnknownArg = ErrorCode(40541) // Location40541
+
+	// ErrDateFromStringMissingDateString indicates that $dateFromString has no dateString argument.
+	ErrDateFromStringMissingDateString = ErrorCode(40542) // Location40542
+
+	// ErrStageFacetNotAllowed indicates that the stage is not allowed within $facet stage.
+	ErrStageFacetNotAllowed = ErrorCode(40600) // Location40600
+
+	// ErrStageNotLast indicates that $merge or $out is not the last stage in the pipeline.
+	ErrStageNotLast = ErrorCode(40601) // Location40601
+
 	// ErrCollStatsIsNotFirstStage indicates that $collStats must be the first stage in the pipeline.
 	ErrCollStatsIsNotFirstStage = ErrorCode(40602) // Location40602
 
+	// ErrDateFromStringFormatNotString indicates that $dateFromString format is not a string.
+	ErrDateFromStringFormatNotString = ErrorCode(40684) // Location40684
+
 	// ErrSetEmptyPassword indicates that a password must not be empty.
 	ErrSetEmptyPassword = ErrorCode(50687) // Location50687
 
 	// ErrStringProhibited indicates that a password contains prohibited runes.
 	ErrStringProhibited = ErrorCode(50692) // Location50692
 
+	// ErrTrimUnknownArg indicates that $trim, $ltrim or $rtrim has an unknown argument.
+	ErrTrimUnknownArg = ErrorCode(50694) // Location50694
+
+	// ErrTrimMissingInput indicates that $trim, $ltrim or $rtrim is missing 'input' field.
+	ErrTrimMissingInput = ErrorCode(50695) // Location50695
+
+	// ErrTrimInvalidArg indicates that $trim, $ltrim or $rtrim argument is not an object.
+	ErrTrimInvalidArg = ErrorCode(50696) // Location50696
+
+	// ErrTrimInputNotString indicates that $trim, $ltrim or $rtrim input is not a string.
+	ErrTrimInputNotString = ErrorCode(50699) // Location50699
+
+	// ErrTrimCharsNotString indicates that $trim, $ltrim or $rtrim chars is not a string.
+	ErrTrimCharsNotString = ErrorCode(50700) // Location50700
+
+	// ErrCursorNotCreatedInSession indicates that getMore was run in a session
+	// on a cursor that was created without one.
+	ErrCursorNotCreatedInSession = ErrorCode(50736) // Location50736
+
+	// ErrCursorSessionRequired indicates that getMore was run without a session
+	// on a cursor that was created in one.
+	ErrCursorSessionRequired = ErrorCode(50737) // Location50737
+
+	// ErrCursorSessionMismatch indicates that getMore was run in a session
+	// different from the one the cursor was created in.
+	ErrCursorSessionMismatch = ErrorCode(50738) // Location50738
+
 	// ErrFreeMonitoringDisabled indicates that free monitoring is disabled
 	// by command-line or config file.
 	ErrFreeMonitoringDisabled = ErrorCode(50840) // Location50840
@@ -303,15 +804,74 @@ const (
 	// ErrValueNegative indicates that value must not be negative.
 	ErrValueNegative = ErrorCode(51024) // Location51024
 
+	// ErrStageLookupNotAllowed indicates that the stage is not allowed within $lookup sub-pipeline.
+	ErrStageLookupNotAllowed = ErrorCode(51047) // Location51047
+
 	// ErrRegexOptions indicates regex options error.
 	ErrRegexOptions = ErrorCode(51075) // Location51075
 
+	// ErrRoundNotNumeric indicates that $round or $trunc argument is not a number.
+	ErrRoundNotNumeric = ErrorCode(51081) // Location51081
+
+	// ErrRoundPlaceNotIntegral indicates that $round or $trunc place is not an integral value.
+	ErrRoundPlaceNotIntegral = ErrorCode(51082) // Location51082
+
+	// ErrRoundPlaceOutOfRange indicates that $round or $trunc place is out of [-20, 100] range.
+	ErrRoundPlaceOutOfRange = ErrorCode(51083) // Location51083
+
 	// ErrRegexMissingParen indicates missing parentheses in regex expression.
 	ErrRegexMissingParen = ErrorCode(51091) // Location51091
 
+	// ErrRegexInvalidArg indicates that $regexFind or $regexMatch argument is not an object.
+	ErrRegexInvalidArg = ErrorCode(51103) // Location51103
+
+	// ErrRegexInputNotString indicates that $regexFind or $regexMatch input is not a string.
+	ErrRegexInputNotString = ErrorCode(51104) // Location51104
+
+	// ErrRegexRegexNotString indicates that $regexFind or $regexMatch regex is neither a string nor a regex.
+	ErrRegexRegexNotString = ErrorCode(51105) // Location51105
+
+	// ErrRegexOptionsNotString indicates that $regexFind or $regexMatch options is not a string.
+	ErrRegexOptionsNotString = ErrorCode(51106) // Location51106
+
+	// ErrRegexOptionsConflict indicates that $regexFind or $regexMatch options are specified
+	// in both 'regex' and 'options' fields.
+	ErrRegexOptionsConflict = ErrorCode(51107) // Location51107
+
 	// ErrBadRegexOption indicates bad regex option value passed.
 	ErrBadRegexOption = ErrorCode(51108) // Location51108
 
+	// ErrRegexNullByte indicates that $regexFind or $regexMatch regex contains a null byte.
+	ErrRegexNullByte = ErrorCode(51109) // Location51109
+
+	// ErrRegexOptionsNullByte indicates that $regexFind or $regexMatch options contain a null byte.
+	ErrRegexOptionsNullByte = ErrorCode(51110) // Location51110
+
+	// ErrRegexInvalid indicates that $regexFind or $regexMatch regex is invalid.
+	ErrRegexInvalid = ErrorCode(51111) // Location51111
+
+	// ErrStageMergeInvalidOnValue indicates that $merge 'on' field of the document is missing, null, or an array.
+	ErrStageMergeInvalidOnValue = ErrorCode(51132) // Location51132
+
+	// ErrStageMergeOnNotString indicates that $merge 'on' array contains a non-string element.
+	ErrStageMergeOnNotString = ErrorCode(51134) // Location51134
+
+	// ErrStageMergeInvalidInto indicates that $merge 'into' field is neither a string nor a document.
+	ErrStageMergeInvalidInto = ErrorCode(51178) // Location51178
+
+	// ErrStageMergeInvalidArg indicates that $merge stage argument is neither a string nor a document.
+	ErrStageMergeInvalidArg = ErrorCode(51182) // Location51182
+
+	// ErrStageMergeNoUniqueIndex indicates that the target collection of $merge stage
+	// has no unique index on 'on' fields.
+	ErrStageMergeNoUniqueIndex = ErrorCode(51183) // Location51183
+
+	// ErrStageMergeInvalidOn indicates that $merge 'on' field is neither a string nor an array.
+	ErrStageMergeInvalidOn = ErrorCode(51186) // Location51186
+
+	// ErrStageMergeEmptyOn indicates that $merge 'on' array is empty.
+	ErrStageMergeEmptyOn = ErrorCode(51187) // Location51187
+
 	// ErrBadPositionalProjection indicates that positional operator could not find a matching element in the array.
 	ErrBadPositionalProjection = ErrorCode(51246) // Location51246
 
@@ -324,9 +884,81 @@ const (
 	// ErrEmptyProject indicates that projection specification must have at least one field.
 	ErrEmptyProject = ErrorCode(51272) // Location51272
 
+	// ErrReplaceReplacementNotString indicates that $replaceOne or $replaceAll replacement is not a string.
+	ErrReplaceReplacementNotString = ErrorCode(51744) // Location51744
+
+	// ErrReplaceFindNotString indicates that $replaceOne or $replaceAll find is not a string.
+	ErrReplaceFindNotString = ErrorCode(51745) // Location51745
+
+	// ErrReplaceInputNotString indicates that $replaceOne or $replaceAll input is not a string.
+	ErrReplaceInputNotString = ErrorCode(51746) // Location51746
+
+	// ErrReplaceMissingReplacement indicates that $replaceOne or $replaceAll is missing 'replacement' parameter.
+	ErrReplaceMissingReplacement = ErrorCode(51747) // Location51747
+
+	// ErrReplaceMissingFind indicates that $replaceOne or $replaceAll is missing 'find' parameter.
+	ErrReplaceMissingFind = ErrorCode(51748) // Location51748
+
+	// ErrReplaceMissingInput indicates that $replaceOne or $replaceAll is missing 'input' parameter.
+	ErrReplaceMissingInput = ErrorCode(51749) // Location51749
+
+	// ErrReplaceUnknownArg indicates that $replaceOne or $replaceAll has an unknown argument.
+	ErrReplaceUnknownArg = ErrorCode(51750) // Location51750
+
+	// ErrReplaceInvalidArg indicates that $replaceOne or $replaceAll argument is not an object.
+	ErrReplaceInvalidArg = ErrorCode(51751) // Location51751
+
+	// ErrFilterLimitNotInt indicates that $filter limit is not a 32-bit integral value.
+	ErrFilterLimitNotInt = ErrorCode(327391) // Location327391
+
+	// ErrFilterLimitNotPositive indicates that $filter limit is not greater than 0.
+	ErrFilterLimitNotPositive = ErrorCode(327392) // Location327392
+
+	// ErrGetFieldUnknownField indicates that $getField argument contains an unknown field.
+	ErrGetFieldUnknownField = ErrorCode(3041701) // Location3041701
+
+	// ErrGetFieldMissingField indicates that $getField 'field' parameter is missing.
+	ErrGetFieldMissingField = ErrorCode(3041702) // Location3041702
+
+	// ErrGetFieldMissingInput indicates that $getField 'input' parameter is missing.
+	ErrGetFieldMissingInput = ErrorCode(3041703) // Location3041703
+
+	// ErrGetFieldInputNotObject indicates that $getField input is not an object.
+	ErrGetFieldInputNotObject = ErrorCode(3041705) // Location3041705
+
+	// ErrStageFacetTooLarge indicates that $facet output exceeds the memory limit.
+	ErrStageFacetTooLarge = ErrorCode(4031700) // Location4031700
+
+	// ErrSetFieldBadArgument indicates that $setField or $unsetField argument is not an object.
+	ErrSetFieldBadArgument = ErrorCode(4161100) // Location4161100
+
+	// ErrSetFieldUnknownField indicates that $setField or $unsetField argument contains an unknown field.
+	ErrSetFieldUnknownField = ErrorCode(4161101) // Location4161101
+
+	// ErrSetFieldMissingField indicates that $setField or $unsetField 'field' parameter is missing.
+	ErrSetFieldMissingField = ErrorCode(4161102) // Location4161102
+
+	// ErrSetFieldMissingInput indicates that $setField or $unsetField 'input' parameter is missing.
+	ErrSetFieldMissingInput = ErrorCode(4161103) // Location4161103
+
+	// ErrSetFieldMissingValue indicates that $setField 'value' parameter is missing.
+	ErrSetFieldMissingValue = ErrorCode(4161104) // Location4161104
+
+	// ErrSetFieldInputNotObject indicates that $setField or $unsetField input is not an object.
+	ErrSetFieldInputNotObject = ErrorCode(4161105) // Location4161105
+
+	// ErrSetFieldNonConstantField indicates that $setField or $unsetField 'field' parameter is not a constant.
+	ErrSetFieldNonConstantField = ErrorCode(4161106) // Location4161106
+
+	// ErrSetFieldFieldNotString indicates that $setField or $unsetField 'field' parameter is not a string.
+	ErrSetFieldFieldNotString = ErrorCode(4161107) // Location4161107
+
 	// ErrDuplicateField indicates duplicate field is specified.
 	ErrDuplicateField = ErrorCode(4822819) // Location4822819
 
+	// ErrArrayToObjectKeyNullByte indicates that $arrayToObject key contains an embedded null byte.
+	ErrArrayToObjectKeyNullByte = ErrorCode(4940400) // Location4940400
+
 	// ErrStageSkipBadValue indicates that $skip stage contains invalid value.
 	ErrStageSkipBadValue = ErrorCode(5107200) // Location5107200
 
@@ -336,13 +968,32 @@ const (
 	// ErrStageCollStatsInvalidArg indicates invalid argument for the aggregation $collStats stage.
 	ErrStageCollStatsInvalidArg = ErrorCode(5447000) // Location5447000
 
+	// ErrGetFieldNonConstantField indicates that $getField 'field' parameter is not a constant.
+	ErrGetFieldNonConstantField = ErrorCode(5654601) // Location5654601
+
+	// ErrGetFieldFieldNotString indicates that $getField 'field' parameter is not a string.
+	ErrGetFieldFieldNotString = ErrorCode(5654602) // Location5654602
+
 	// ErrOpQueryCollectionSuffixMissing indicates that op query collection does not contain .$cmd suffix.
 	ErrOpQueryCollectionSuffixMissing = ErrorCode(5739101) // Location5739101
 
+	// ErrStageDensifyTooManyDocuments indicates that $densify stage generated too many documents.
+	ErrStageDensifyTooManyDocuments = ErrorCode(5897900) // Location5897900
+
 	// ErrStageIndexedStringVectorDuplicate indicates that input to IndexedStringVector contained duplicate values.
 	ErrStageIndexedStringVectorDuplicate = ErrorCode(7582300) // Location7582300
 )
 
+// Error labels.
+const (
+	// LabelRetryableWriteError indicates that the write command could be safely retried by the client.
+	LabelRetryableWriteError = "RetryableWriteError"
+
+	// LabelTransientTransactionError indicates that the operation could be retried by the client
+	// as a part of a new transaction.
+	LabelTransientTransactionError = "TransientTransactionError"
+)
+
 // ErrInfo represents additional optional error information.
 type ErrInfo struct {
 	Argument string // command's argument, operator, or aggregation pipeline stage that caused an error
@@ -364,7 +1015,10 @@ type ProtoErr interface {
 //
 // Nil panics (it never should be passed),
 // [*CommandError] or [*WriteErrors] (possibly wrapped) are returned unwrapped,
-// [*wire.ValidationError] (possibly wrapped) is returned as CommandError with BadValue code,
+// [*bson.ValidationError] (possibly wrapped) is returned as CommandError with the matching code,
+// other [*wire.ValidationError] (possibly wrapped) is returned as CommandError with BadValue code,
+// known backend failures (possibly wrapped) are returned as CommandError with error labels
+// (see [ClassifyError]),
 // any other values (including lazy errors) are returned as CommandError with InternalError code.
 func ProtocolError(err error) ProtoErr {
 	if err == nil {
@@ -381,12 +1035,39 @@ func ProtocolError(err error) ProtoErr {
 		return writeErr
 	}
 
+	var bsonErr *bson.ValidationError
+	if errors.As(err, &bsonErr) {
+		code := ErrBadValue
+
+		switch bsonErr.Code() {
+		case bson.ValidationErrorInvalidBSON:
+			code = ErrInvalidBSON
+		case bson.ValidationErrorDepth:
+			code = ErrOverflow
+		case bson.ValidationErrorNonConformant:
+			code = ErrNonConformantBSON
+		case bson.ValidationErrorUnsupported:
+			code = ErrBadValue
+		}
+
+		//nolint:errorlint // only *CommandError could be returned
+		return NewCommandErrorMsg(code, bsonErr.Error()).(*CommandError)
+	}
+
 	var validationErr *wire.ValidationError
 	if errors.As(err, &validationErr) {
 		//nolint:errorlint // only *CommandError could be returned
 		return NewCommandError(ErrBadValue, err).(*CommandError)
 	}
 
+	if c := ClassifyError(err, nil); c != nil && c.Kind == ErrorKindCommand {
+		return &CommandError{
+			code:   c.Code,
+			err:    err,
+			labels: c.Labels,
+		}
+	}
+
 	//nolint:errorlint // only *CommandError could be returned
 	return NewCommandError(errInternalError, err).(*CommandError)
 }
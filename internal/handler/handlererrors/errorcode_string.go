@@ -11,23 +11,29 @@ func _() {
 	_ = x[errUnset-0]
 	_ = x[errInternalError-1]
 	_ = x[ErrBadValue-2]
+	_ = x[ErrHostUnreachable-6]
 	_ = x[ErrFailedToParse-9]
 	_ = x[ErrUserNotFound-11]
 	_ = x[ErrUnauthorized-13]
 	_ = x[ErrTypeMismatch-14]
+	_ = x[ErrOverflow-15]
 	_ = x[ErrAuthenticationFailed-18]
 	_ = x[ErrIllegalOperation-20]
+	_ = x[ErrInvalidBSON-22]
+	_ = x[ErrAlreadyInitialized-23]
 	_ = x[ErrNamespaceNotFound-26]
 	_ = x[ErrIndexNotFound-27]
 	_ = x[ErrUnsuitableValueType-28]
 	_ = x[ErrConflictingUpdateOperators-40]
 	_ = x[ErrCursorNotFound-43]
+	_ = x[ErrNoMatchingDocument-47]
 	_ = x[ErrNamespaceExists-48]
 	_ = x[ErrMaxTimeMSExpired-50]
 	_ = x[ErrDollarPrefixedFieldName-52]
 	_ = x[ErrInvalidID-53]
 	_ = x[ErrEmptyName-56]
 	_ = x[ErrCommandNotFound-59]
+	_ = x[ErrWriteConcernFailed-64]
 	_ = x[ErrImmutableField-66]
 	_ = x[ErrCannotCreateIndex-67]
 	_ = x[ErrIndexAlreadyExists-68]
@@ -35,16 +41,78 @@ func _() {
 	_ = x[ErrInvalidNamespace-73]
 	_ = x[ErrIndexOptionsConflict-85]
 	_ = x[ErrIndexKeySpecsConflict-86]
+	_ = x[ErrNetworkTimeout-89]
 	_ = x[ErrOperationFailed-96]
+	_ = x[ErrWriteConflict-112]
 	_ = x[ErrDocumentValidationFailure-121]
 	_ = x[ErrInvalidIndexSpecificationOption-197]
 	_ = x[ErrInvalidPipelineOperator-168]
 	_ = x[ErrClientMetadataCannotBeMutated-186]
 	_ = x[ErrNotImplemented-238]
+	_ = x[ErrConversionFailure-241]
+	_ = x[ErrQueryExceededMemoryLimitNoDiskUseAllowed-292]
+	_ = x[ErrAPIVersionError-322]
+	_ = x[ErrAPIStrictError-323]
 	_ = x[ErrMechanismUnavailable-334]
 	_ = x[ErrUnsupportedOpQueryCommand-352]
+	_ = x[ErrNonConformantBSON-378]
 	_ = x[ErrIndexesWrongType-10065]
+	_ = x[ErrNotWritablePrimary-10107]
 	_ = x[ErrDuplicateKeyInsert-11000]
+	_ = x[ErrInterruptedAtShutdown-11600]
+	_ = x[ErrStageMergeNoMatch-13113]
+	_ = x[ErrMultiplyNotNumeric-16555]
+	_ = x[ErrDivideNotNumeric-16609]
+	_ = x[ErrModByZero-16610]
+	_ = x[ErrModNotNumeric-16611]
+	_ = x[ErrLetBadArgument-16874]
+	_ = x[ErrLetUnknownField-16875]
+	_ = x[ErrLetMissingVars-16876]
+	_ = x[ErrLetMissingIn-16877]
+	_ = x[ErrMapBadArgument-16878]
+	_ = x[ErrMapUnknownField-16879]
+	_ = x[ErrMapMissingInput-16880]
+	_ = x[ErrMapMissingIn-16882]
+	_ = x[ErrMapInputNotArray-16883]
+	_ = x[ErrFilterBadArgument-28646]
+	_ = x[ErrFilterUnknownField-28647]
+	_ = x[ErrFilterMissingInput-28648]
+	_ = x[ErrFilterMissingCond-28650]
+	_ = x[ErrFilterInputNotArray-28651]
+	_ = x[ErrSqrtNegative-28714]
+	_ = x[ErrLogArgNotNumeric-28756]
+	_ = x[ErrLogBaseNotNumeric-28757]
+	_ = x[ErrLogArgNotPositive-28758]
+	_ = x[ErrLogInvalidBase-28759]
+	_ = x[ErrPowBaseNotNumeric-28762]
+	_ = x[ErrPowExponentNotNumeric-28763]
+	_ = x[ErrPowZeroNegativeExponent-28764]
+	_ = x[ErrSqrtNotNumeric-28765]
+	_ = x[ErrRegexMissingInput-31022]
+	_ = x[ErrRegexMissingRegex-31023]
+	_ = x[ErrRegexUnknownArg-31024]
+	_ = x[ErrZipBadArgument-34460]
+	_ = x[ErrZipInputsNotArray-34461]
+	_ = x[ErrZipDefaultsNotArray-34462]
+	_ = x[ErrZipUseLongestLengthNotBool-34463]
+	_ = x[ErrZipUnknownField-34464]
+	_ = x[ErrZipMissingInputs-34465]
+	_ = x[ErrZipDefaultsWithoutLongest-34466]
+	_ = x[ErrZipDefaultsLength-34467]
+	_ = x[ErrZipInputNotArray-34468]
+	_ = x[ErrReduceBadArgument-40075]
+	_ = x[ErrReduceUnknownField-40076]
+	_ = x[ErrReduceMissingInput-40077]
+	_ = x[ErrReduceMissingInitialValue-40078]
+	_ = x[ErrReduceMissingIn-40079]
+	_ = x[ErrReduceInputNotArray-40080]
+	_ = x[ErrSplitInputNotString-40085]
+	_ = x[ErrSplitDelimiterNotString-40086]
+	_ = x[ErrSplitEmptyDelimiter-40087]
+	_ = x[ErrIndexOfCPStringNotString-40093]
+	_ = x[ErrIndexOfCPSubstringNotString-40094]
+	_ = x[ErrIndexOfCPIndexNotIntegral-40096]
+	_ = x[ErrIndexOfCPIndexNegative-40097]
 	_ = x[ErrSetBadExpression-40272]
 	_ = x[ErrStageGroupInvalidFields-15947]
 	_ = x[ErrStageGroupID-15948]
@@ -61,164 +129,516 @@ func _() {
 	_ = x[ErrExpressionWrongLenOfFields-15983]
 	_ = x[ErrPathContainsEmptyElement-15998]
 	_ = x[ErrOperatorWrongLenOfArgs-16020]
+	_ = x[ErrExpressionNotDate-16006]
 	_ = x[ErrFieldPathInvalidName-16410]
 	_ = x[ErrGroupInvalidFieldPath-16872]
+	_ = x[ErrStageOutInvalidArg-16990]
+	_ = x[ErrStageRedactInvalidResult-17053]
+	_ = x[ErrCondMissingIf-17080]
+	_ = x[ErrCondMissingThen-17081]
+	_ = x[ErrCondMissingElse-17082]
+	_ = x[ErrCondUnknownArg-17083]
 	_ = x[ErrGroupUndefinedVariable-17276]
+	_ = x[ErrProjectionMetaNotString-17307]
+	_ = x[ErrProjectionMetaInvalid-17308]
+	_ = x[ErrDateFormatNotString-18533]
+	_ = x[ErrDateToStringUnknownArg-18534]
+	_ = x[ErrDateFormatUnmatchedPercent-18535]
+	_ = x[ErrDateFormatInvalidChar-18536]
+	_ = x[ErrDateToStringMissingDate-18628]
+	_ = x[ErrDateToStringInvalidArg-18629]
 	_ = x[ErrInvalidArg-28667]
 	_ = x[ErrSliceFirstArg-28724]
+	_ = x[ErrStageSampleInvalidArg-28745]
+	_ = x[ErrStageSampleSizeNotNumber-28746]
+	_ = x[ErrStageSampleNegativeSize-28747]
+	_ = x[ErrStageSampleUnknownOption-28748]
+	_ = x[ErrStageSampleMissingSize-28749]
+	_ = x[ErrStageIndexStatsInvalidArg-28803]
 	_ = x[ErrStageUnsetNoPath-31119]
 	_ = x[ErrStageUnsetArrElementInvalidType-31120]
 	_ = x[ErrStageUnsetInvalidType-31002]
+	_ = x[ErrDateFromPartsValueRange-31034]
+	_ = x[ErrSortIllegalMeta-31138]
 	_ = x[ErrStageUnwindNoPath-28812]
 	_ = x[ErrStageUnwindNoPrefix-28818]
 	_ = x[ErrUnsetPathCollision-31249]
 	_ = x[ErrUnsetPathOverwrite-31250]
 	_ = x[ErrProjectionInEx-31253]
 	_ = x[ErrProjectionExIn-31254]
+	_ = x[ErrSliceProjectionSkipNotNumber-31257]
+	_ = x[ErrSliceProjectionLimitNotNumber-31258]
+	_ = x[ErrSliceProjectionLimitNotPositive-31259]
+	_ = x[ErrSliceProjectionInvalid-31272]
 	_ = x[ErrAggregatePositionalProject-31324]
 	_ = x[ErrAggregateInvalidExpression-31325]
 	_ = x[ErrWrongPositionalOperatorLocation-31394]
 	_ = x[ErrExclusionPositionalProjection-31395]
+	_ = x[ErrSwitchInvalidArg-40060]
+	_ = x[ErrSwitchBranchesNotArray-40061]
+	_ = x[ErrSwitchBranchNotObject-40062]
+	_ = x[ErrSwitchBranchUnknownArg-40063]
+	_ = x[ErrSwitchBranchMissingCase-40064]
+	_ = x[ErrSwitchBranchMissingThen-40065]
+	_ = x[ErrSwitchNoMatchingBranch-40066]
+	_ = x[ErrSwitchUnknownArg-40067]
+	_ = x[ErrSwitchNoBranches-40068]
 	_ = x[ErrStageCountNonString-40156]
 	_ = x[ErrStageCountNonEmptyString-40157]
 	_ = x[ErrStageCountBadPrefix-40158]
 	_ = x[ErrStageCountBadValue-40160]
+	_ = x[ErrStageFacetInvalid-40169]
+	_ = x[ErrStageFacetNotArray-40170]
+	_ = x[ErrStageFacetInvalidElement-40171]
 	_ = x[ErrAddFieldsExpressionWrongAmountOfArgs-40181]
+	_ = x[ErrStageBucketBoundariesNotConstant-40191]
+	_ = x[ErrStageBucketBoundariesTooFew-40192]
+	_ = x[ErrStageBucketBoundariesMixedTypes-40193]
+	_ = x[ErrStageBucketBoundariesNotSorted-40194]
+	_ = x[ErrStageBucketDefaultNotConstant-40195]
+	_ = x[ErrStageBucketOutputNotObject-40196]
+	_ = x[ErrStageBucketUnknownOption-40197]
+	_ = x[ErrStageBucketMissingRequired-40198]
+	_ = x[ErrStageBucketDefaultInRange-40199]
+	_ = x[ErrStageBucketBoundariesNotArray-40200]
+	_ = x[ErrStageBucketInvalid-40201]
+	_ = x[ErrStageBucketGroupByInvalid-40202]
+	_ = x[ErrNoTextScoreMetadata-40218]
 	_ = x[ErrStageGroupUnaryOperator-40237]
 	_ = x[ErrStageGroupMultipleAccumulator-40238]
+	_ = x[ErrStageBucketAutoGroupByInvalid-40239]
+	_ = x[ErrStageBucketAutoInvalid-40240]
+	_ = x[ErrStageBucketAutoBucketsNotNumber-40241]
+	_ = x[ErrStageBucketAutoBucketsNotInt32-40242]
+	_ = x[ErrStageBucketAutoBucketsNotPositive-40243]
+	_ = x[ErrStageBucketAutoOutputNotObject-40244]
+	_ = x[ErrStageBucketAutoUnknownOption-40245]
+	_ = x[ErrStageBucketAutoMissingRequired-40246]
 	_ = x[ErrStageGroupInvalidAccumulator-40234]
+	_ = x[ErrStageLookupInvalid-40319]
 	_ = x[ErrStageInvalid-40323]
 	_ = x[ErrEmptyFieldPath-40352]
 	_ = x[ErrInvalidFieldPath-40353]
+	_ = x[ErrArrayToObjectNotArray-40386]
+	_ = x[ErrObjectToArrayNotDocument-40390]
+	_ = x[ErrArrayToObjectMixedFormat-40391]
+	_ = x[ErrArrayToObjectBadKeysCount-40392]
+	_ = x[ErrArrayToObjectMissingKeys-40393]
+	_ = x[ErrArrayToObjectKeyNotString-40394]
+	_ = x[ErrArrayToObjectBadPairSize-40395]
+	_ = x[ErrArrayToObjectPairKeyNotString-40397]
+	_ = x[ErrArrayToObjectBadElement-40398]
 	_ = x[ErrMissingField-40414]
 	_ = x[ErrFailedToParseInput-40415]
+	_ = x[ErrTimezoneUnrecognized-40485]
+	_ = x[ErrDateFromPartsMixedISO-40489]
+	_ = x[ErrDateFromPartsNotInteger-40515]
+	_ = x[ErrDateFromPartsMissingYear-40516]
+	_ = x[ErrTimezoneNotString-40517]
+	_ = x[ErrDateFromPartsUnknownArg-40518]
+	_ = x[ErrDateFromPartsInvalidArg-40519]
+	_ = x[ErrDateToPartsUnknownArg-40520]
+	_ = x[ErrDateToPartsISO8601NotBool-40521]
+	_ = x[ErrDateToPartsMissingDate-40522]
+	_ = x[ErrDateFromPartsYearRange-40523]
+	_ = x[ErrDateToPartsInvalidArg-40524]
+	_ = x[ErrDateFromStringInvalidArg-40540]
+	_ = x[ErrDateFromStringUnknownArg-40541]
+	_ = x[ErrDateFromStringMissingDateString-40542]
+	_ = x[ErrStageFacetNotAllowed-40600]
+	_ = x[ErrStageNotLast-40601]
 	_ = x[ErrCollStatsIsNotFirstStage-40602]
+	_ = x[ErrDateFromStringFormatNotString-40684]
 	_ = x[ErrSetEmptyPassword-50687]
 	_ = x[ErrStringProhibited-50692]
+	_ = x[ErrTrimUnknownArg-50694]
+	_ = x[ErrTrimMissingInput-50695]
+	_ = x[ErrTrimInvalidArg-50696]
+	_ = x[ErrTrimInputNotString-50699]
+	_ = x[ErrTrimCharsNotString-50700]
+	_ = x[ErrCursorNotCreatedInSession-50736]
+	_ = x[ErrCursorSessionRequired-50737]
+	_ = x[ErrCursorSessionMismatch-50738]
 	_ = x[ErrFreeMonitoringDisabled-50840]
 	_ = x[ErrUserAlreadyExists-51003]
 	_ = x[ErrValueNegative-51024]
+	_ = x[ErrStageLookupNotAllowed-51047]
 	_ = x[ErrRegexOptions-51075]
+	_ = x[ErrRoundNotNumeric-51081]
+	_ = x[ErrRoundPlaceNotIntegral-51082]
+	_ = x[ErrRoundPlaceOutOfRange-51083]
 	_ = x[ErrRegexMissingParen-51091]
+	_ = x[ErrRegexInvalidArg-51103]
+	_ = x[ErrRegexInputNotString-51104]
+	_ = x[ErrRegexRegexNotString-51105]
+	_ = x[ErrRegexOptionsNotString-51106]
+	_ = x[ErrRegexOptionsConflict-51107]
 	_ = x[ErrBadRegexOption-51108]
+	_ = x[ErrRegexNullByte-51109]
+	_ = x[ErrRegexOptionsNullByte-51110]
+	_ = x[ErrRegexInvalid-51111]
+	_ = x[ErrStageMergeInvalidOnValue-51132]
+	_ = x[ErrStageMergeOnNotString-51134]
+	_ = x[ErrStageMergeInvalidInto-51178]
+	_ = x[ErrStageMergeInvalidArg-51182]
+	_ = x[ErrStageMergeNoUniqueIndex-51183]
+	_ = x[ErrStageMergeInvalidOn-51186]
+	_ = x[ErrStageMergeEmptyOn-51187]
 	_ = x[ErrBadPositionalProjection-51246]
 	_ = x[ErrElementMismatchPositionalProjection-51247]
 	_ = x[ErrEmptySubProject-51270]
 	_ = x[ErrEmptyProject-51272]
+	_ = x[ErrReplaceReplacementNotString-51744]
+	_ = x[ErrReplaceFindNotString-51745]
+	_ = x[ErrReplaceInputNotString-51746]
+	_ = x[ErrReplaceMissingReplacement-51747]
+	_ = x[ErrReplaceMissingFind-51748]
+	_ = x[ErrReplaceMissingInput-51749]
+	_ = x[ErrReplaceUnknownArg-51750]
+	_ = x[ErrReplaceInvalidArg-51751]
+	_ = x[ErrFilterLimitNotInt-327391]
+	_ = x[ErrFilterLimitNotPositive-327392]
+	_ = x[ErrGetFieldUnknownField-3041701]
+	_ = x[ErrGetFieldMissingField-3041702]
+	_ = x[ErrGetFieldMissingInput-3041703]
+	_ = x[ErrGetFieldInputNotObject-3041705]
+	_ = x[ErrStageFacetTooLarge-4031700]
+	_ = x[ErrSetFieldBadArgument-4161100]
+	_ = x[ErrSetFieldUnknownField-4161101]
+	_ = x[ErrSetFieldMissingField-4161102]
+	_ = x[ErrSetFieldMissingInput-4161103]
+	_ = x[ErrSetFieldMissingValue-4161104]
+	_ = x[ErrSetFieldInputNotObject-4161105]
+	_ = x[ErrSetFieldNonConstantField-4161106]
+	_ = x[ErrSetFieldFieldNotString-4161107]
 	_ = x[ErrDuplicateField-4822819]
+	_ = x[ErrArrayToObjectKeyNullByte-4940400]
 	_ = x[ErrStageSkipBadValue-5107200]
 	_ = x[ErrStageLimitInvalidArg-5107201]
 	_ = x[ErrStageCollStatsInvalidArg-5447000]
+	_ = x[ErrGetFieldNonConstantField-5654601]
+	_ = x[ErrGetFieldFieldNotString-5654602]
 	_ = x[ErrOpQueryCollectionSuffixMissing-5739101]
+	_ = x[ErrStageDensifyTooManyDocuments-5897900]
 	_ = x[ErrStageIndexedStringVectorDuplicate-7582300]
 }
 
-const _ErrorCode_name = "UnsetInternalErrorBadValueFailedToParseUserNotFoundUnauthorizedTypeMismatchAuthenticationFailedIllegalOperationNamespaceNotFoundIndexNotFoundPathNotViableConflictingUpdateOperatorsCursorNotFoundNamespaceExistsMaxTimeMSExpiredDollarPrefixedFieldNameInvalidIdFieldEmptyFieldNameCommandNotFoundImmutableFieldCannotCreateIndexIndexAlreadyExistsInvalidOptionsInvalidNamespaceIndexOptionsConflictIndexKeySpecsConflictOperationFailedDocumentValidationFailureInvalidPipelineOperatorClientMetadataCannotBeMutatedInvalidIndexSpecificationOptionNotImplementedErrMechanismUnavailableUnsupportedOpQueryCommandLocation10065DuplicateKeyLocation15947Location15948Location15955Location15958Location15959Location15969Location15973Location15974Location15975Location15976Location15981Location15983Location15998Location16020Location16406Location16410Location16872Location17276Location28667Location28724Location28812Location28818Location31002Location31119Location31120Location31249Location31250Location31253Location31254Location31324Location31325Location31394Location31395Location40156Location40157Location40158Location40160Location40181Location40234Location40237Location40238Location40272Location40323Location40352Location40353Location40414Location40415Location40602Location50687Location50692Location50840Location51003Location51024Location51075Location51091Location51108Location51246Location51247Location51270Location51272Location4822819Location5107200Location5107201Location5447000Location5739101Location7582300"
+const _ErrorCode_name = "UnsetInternalErrorBadValueHostUnreachableFailedToParseUserNotFoundUnauthorizedTypeMismatchOverflowAuthenticationFailedIllegalOperationInvalidBSONAlreadyInitializedNamespaceNotFoundIndexNotFoundPathNotViableConflictingUpdateOperatorsCursorNotFoundNoMatchingDocumentNamespaceExistsMaxTimeMSExpiredDollarPrefixedFieldNameInvalidIdFieldEmptyFieldNameCommandNotFoundWriteConcernFailedImmutableFieldCannotCreateIndexIndexAlreadyExistsInvalidOptionsInvalidNamespaceIndexOptionsConflictIndexKeySpecsConflictNetworkTimeoutOperationFailedWriteConflictDocumentValidationFailureInvalidPipelineOperatorClientMetadataCannotBeMutatedInvalidIndexSpecificationOptionNotImplementedConversionFailureQueryExceededMemoryLimitNoDiskUseAllowedAPIVersionErrorAPIStrictErrorErrMechanismUnavailableUnsupportedOpQueryCommandNonConformantBSONLocation10065NotWritablePrimaryDuplicateKeyInterruptedAtShutdownLocation13113Location15947Location15948Location15955Location15958Location15959Location15969Location15973Location15974Location15975Location15976Location15981Location15983Location15998Location16006Location16020Location16406Location16410Location16555Location16609Location16610Location16611Location16872Location16874Location16875Location16876Location16877Location16878Location16879Location16880Location16882Location16883Location16990Location17053Location17080Location17081Location17082Location17083Location17276Location17307Location17308Location18533Location18534Location18535Location18536Location18628Location18629Location28646Location28647Location28648Location28650Location28651Location28667Location28714Location28724Location28745Location28746Location28747Location28748Location28749Location28756Location28757Location28758Location28759Location28762Location28763Location28764Location28765Location28803Location28812Location28818Location31002Location31022Location31023Location31024Location31034Location31119Location31120Location31138Location31249Location31250Location31253Location31254Location31257Location31258Location31259Location31272Location31324Location31325Location31394Location31395Location34460Location34461Location34462Location34463Location34464Location34465Location34466Location34467Location34468Location40060Location40061Location40062Location40063Location40064Location40065Location40066Location40067Location40068Location40075Location40076Location40077Location40078Location40079Location40080Location40085Location40086Location40087Location40093Location40094Location40096Location40097Location40156Location40157Location40158Location40160Location40169Location40170Location40171Location40181Location40191Location40192Location40193Location40194Location40195Location40196Location40197Location40198Location40199Location40200Location40201Location40202Location40218Location40234Location40237Location40238Location40239Location40240Location40241Location40242Location40243Location40244Location40245Location40246Location40272Location40319Location40323Location40352Location40353Location40386Location40390Location40391Location40392Location40393Location40394Location40395Location40397Location40398Location40414Location40415Location40485Location40489Location40515Location40516Location40517Location40518Location40519Location40520Location40521Location40522Location40523Location40524Location40540Location40541Location40542Location40600Location40601Location40602Location40684Location50687Location50692Location50694Location50695Location50696Location50699Location50700Location50736Location50737Location50738Location50840Location51003Location51024Location51047Location51075Location51081Location51082Location51083Location51091Location51103Location51104Location51105Location51106Location51107Location51108Location51109Location51110Location51111Location51132Location51134Location51178Location51182Location51183Location51186Location51187Location51246Location51247Location51270Location51272Location51744Location51745Location51746Location51747Location51748Location51749Location51750Location51751Location327391Location327392Location3041701Location3041702Location3041703Location3041705Location4031700Location4161100Location4161101Location4161102Location4161103Location4161104Location4161105Location4161106Location4161107Location4822819Location4940400Location5107200Location5107201Location5447000Location5654601Location5654602Location5739101Location5897900Location7582300"
 
 var _ErrorCode_map = map[ErrorCode]string{
 	0:       _ErrorCode_name[0:5],
 	1:       _ErrorCode_name[5:18],
 	2:       _ErrorCode_name[18:26],
-	9:       _ErrorCode_name[26:39],
-	11:      _ErrorCode_name[39:51],
-	13:      _ErrorCode_name[51:63],
-	14:      _ErrorCode_name[63:75],
-	18:      _ErrorCode_name[75:95],
-	20:      _ErrorCode_name[95:111],
-	26:      _ErrorCode_name[111:128],
-	27:      _ErrorCode_name[128:141],
-	28:      _ErrorCode_name[141:154],
-	40:      _ErrorCode_name[154:180],
-	43:      _ErrorCode_name[180:194],
-	48:      _ErrorCode_name[194:209],
-	50:      _ErrorCode_name[209:225],
-	52:      _ErrorCode_name[225:248],
-	53:      _ErrorCode_name[248:262],
-	56:      _ErrorCode_name[262:276],
-	59:      _ErrorCode_name[276:291],
-	66:      _ErrorCode_name[291:305],
-	67:      _ErrorCode_name[305:322],
-	68:      _ErrorCode_name[322:340],
-	72:      _ErrorCode_name[340:354],
-	73:      _ErrorCode_name[354:370],
-	85:      _ErrorCode_name[370:390],
-	86:      _ErrorCode_name[390:411],
-	96:      _ErrorCode_name[411:426],
-	121:     _ErrorCode_name[426:451],
-	168:     _ErrorCode_name[451:474],
-	186:     _ErrorCode_name[474:503],
-	197:     _ErrorCode_name[503:534],
-	238:     _ErrorCode_name[534:548],
-	334:     _ErrorCode_name[548:571],
-	352:     _ErrorCode_name[571:596],
-	10065:   _ErrorCode_name[596:609],
-	11000:   _ErrorCode_name[609:621],
-	15947:   _ErrorCode_name[621:634],
-	15948:   _ErrorCode_name[634:647],
-	15955:   _ErrorCode_name[647:660],
-	15958:   _ErrorCode_name[660:673],
-	15959:   _ErrorCode_name[673:686],
-	15969:   _ErrorCode_name[686:699],
-	15973:   _ErrorCode_name[699:712],
-	15974:   _ErrorCode_name[712:725],
-	15975:   _ErrorCode_name[725:738],
-	15976:   _ErrorCode_name[738:751],
-	15981:   _ErrorCode_name[751:764],
-	15983:   _ErrorCode_name[764:777],
-	15998:   _ErrorCode_name[777:790],
-	16020:   _ErrorCode_name[790:803],
-	16406:   _ErrorCode_name[803:816],
-	16410:   _ErrorCode_name[816:829],
-	16872:   _ErrorCode_name[829:842],
-	17276:   _ErrorCode_name[842:855],
-	28667:   _ErrorCode_name[855:868],
-	28724:   _ErrorCode_name[868:881],
-	28812:   _ErrorCode_name[881:894],
-	28818:   _ErrorCode_name[894:907],
-	31002:   _ErrorCode_name[907:920],
-	31119:   _ErrorCode_name[920:933],
-	31120:   _ErrorCode_name[933:946],
-	31249:   _ErrorCode_name[946:959],
-	31250:   _ErrorCode_name[959:972],
-	31253:   _ErrorCode_name[972:985],
-	31254:   _ErrorCode_name[985:998],
-	31324:   _ErrorCode_name[998:1011],
-	31325:   _ErrorCode_name[1011:1024],
-	31394:   _ErrorCode_name[1024:1037],
-	31395:   _ErrorCode_name[1037:1050],
-	40156:   _ErrorCode_name[1050:1063],
-	40157:   _ErrorCode_name[1063:1076],
-	40158:   _ErrorCode_name[1076:1089],
-	40160:   _ErrorCode_name[1089:1102],
-	40181:   _ErrorCode_name[1102:1115],
-	40234:   _ErrorCode_name[1115:1128],
-	40237:   _ErrorCode_name[1128:1141],
-	40238:   _ErrorCode_name[1141:1154],
-	40272:   _ErrorCode_name[1154:1167],
-	40323:   _ErrorCode_name[1167:1180],
-	40352:   _ErrorCode_name[1180:1193],
-	40353:   _ErrorCode_name[1193:1206],
-	40414:   _ErrorCode_name[1206:1219],
-	40415:   _ErrorCode_name[1219:1232],
-	40602:   _ErrorCode_name[1232:1245],
-	50687:   _ErrorCode_name[1245:1258],
-	50692:   _ErrorCode_name[1258:1271],
-	50840:   _ErrorCode_name[1271:1284],
-	51003:   _ErrorCode_name[1284:1297],
-	51024:   _ErrorCode_name[1297:1310],
-	51075:   _ErrorCode_name[1310:1323],
-	51091:   _ErrorCode_name[1323:1336],
-	51108:   _ErrorCode_name[1336:1349],
-	51246:   _ErrorCode_name[1349:1362],
-	51247:   _ErrorCode_name[1362:1375],
-	51270:   _ErrorCode_name[1375:1388],
-	51272:   _ErrorCode_name[1388:1401],
-	4822819: _ErrorCode_name[1401:1416],
-	5107200: _ErrorCode_name[1416:1431],
-	5107201: _ErrorCode_name[1431:1446],
-	5447000: _ErrorCode_name[1446:1461],
-	5739101: _ErrorCode_name[1461:1476],
-	7582300: _ErrorCode_name[1476:1491],
+	6:       _ErrorCode_name[26:41],
+	9:       _ErrorCode_name[41:54],
+	11:      _ErrorCode_name[54:66],
+	13:      _ErrorCode_name[66:78],
+	14:      _ErrorCode_name[78:90],
+	15:      _ErrorCode_name[90:98],
+	18:      _ErrorCode_name[98:118],
+	20:      _ErrorCode_name[118:134],
+	22:      _ErrorCode_name[134:145],
+	23:      _ErrorCode_name[145:163],
+	26:      _ErrorCode_name[163:180],
+	27:      _ErrorCode_name[180:193],
+	28:      _ErrorCode_name[193:206],
+	40:      _ErrorCode_name[206:232],
+	43:      _ErrorCode_name[232:246],
+	47:      _ErrorCode_name[246:264],
+	48:      _ErrorCode_name[264:279],
+	50:      _ErrorCode_name[279:295],
+	52:      _ErrorCode_name[295:318],
+	53:      _ErrorCode_name[318:332],
+	56:      _ErrorCode_name[332:346],
+	59:      _ErrorCode_name[346:361],
+	64:      _ErrorCode_name[361:379],
+	66:      _ErrorCode_name[379:393],
+	67:      _ErrorCode_name[393:410],
+	68:      _ErrorCode_name[410:428],
+	72:      _ErrorCode_name[428:442],
+	73:      _ErrorCode_name[442:458],
+	85:      _ErrorCode_name[458:478],
+	86:      _ErrorCode_name[478:499],
+	89:      _ErrorCode_name[499:513],
+	96:      _ErrorCode_name[513:528],
+	112:     _ErrorCode_name[528:541],
+	121:     _ErrorCode_name[541:566],
+	168:     _ErrorCode_name[566:589],
+	186:     _ErrorCode_name[589:618],
+	197:     _ErrorCode_name[618:649],
+	238:     _ErrorCode_name[649:663],
+	241:     _ErrorCode_name[663:680],
+	292:     _ErrorCode_name[680:720],
+	322:     _ErrorCode_name[720:735],
+	323:     _ErrorCode_name[735:749],
+	334:     _ErrorCode_name[749:772],
+	352:     _ErrorCode_name[772:797],
+	378:     _ErrorCode_name[797:814],
+	10065:   _ErrorCode_name[814:827],
+	10107:   _ErrorCode_name[827:845],
+	11000:   _ErrorCode_name[845:857],
+	11600:   _ErrorCode_name[857:878],
+	13113:   _ErrorCode_name[878:891],
+	15947:   _ErrorCode_name[891:904],
+	15948:   _ErrorCode_name[904:917],
+	15955:   _ErrorCode_name[917:930],
+	15958:   _ErrorCode_name[930:943],
+	15959:   _ErrorCode_name[943:956],
+	15969:   _ErrorCode_name[956:969],
+	15973:   _ErrorCode_name[969:982],
+	15974:   _ErrorCode_name[982:995],
+	15975:   _ErrorCode_name[995:1008],
+	15976:   _ErrorCode_name[1008:1021],
+	15981:   _ErrorCode_name[1021:1034],
+	15983:   _ErrorCode_name[1034:1047],
+	15998:   _ErrorCode_name[1047:1060],
+	16006:   _ErrorCode_name[1060:1073],
+	16020:   _ErrorCode_name[1073:1086],
+	16406:   _ErrorCode_name[1086:1099],
+	16410:   _ErrorCode_name[1099:1112],
+	16555:   _ErrorCode_name[1112:1125],
+	16609:   _ErrorCode_name[1125:1138],
+	16610:   _ErrorCode_name[1138:1151],
+	16611:   _ErrorCode_name[1151:1164],
+	16872:   _ErrorCode_name[1164:1177],
+	16874:   _ErrorCode_name[1177:1190],
+	16875:   _ErrorCode_name[1190:1203],
+	16876:   _ErrorCode_name[1203:1216],
+	16877:   _ErrorCode_name[1216:1229],
+	16878:   _ErrorCode_name[1229:1242],
+	16879:   _ErrorCode_name[1242:1255],
+	16880:   _ErrorCode_name[1255:1268],
+	16882:   _ErrorCode_name[1268:1281],
+	16883:   _ErrorCode_name[1281:1294],
+	16990:   _ErrorCode_name[1294:1307],
+	17053:   _ErrorCode_name[1307:1320],
+	17080:   _ErrorCode_name[1320:1333],
+	17081:   _ErrorCode_name[1333:1346],
+	17082:   _ErrorCode_name[1346:1359],
+	17083:   _ErrorCode_name[1359:1372],
+	17276:   _ErrorCode_name[1372:1385],
+	17307:   _ErrorCode_name[1385:1398],
+	17308:   _ErrorCode_name[1398:1411],
+	18533:   _ErrorCode_name[1411:1424],
+	18534:   _ErrorCode_name[1424:1437],
+	18535:   _ErrorCode_name[1437:1450],
+	18536:   _ErrorCode_name[1450:1463],
+	18628:   _ErrorCode_name[1463:1476],
+	18629:   _ErrorCode_name[1476:1489],
+	28646:   _ErrorCode_name[1489:1502],
+	28647:   _ErrorCode_name[1502:1515],
+	28648:   _ErrorCode_name[1515:1528],
+	28650:   _ErrorCode_name[1528:1541],
+	28651:   _ErrorCode_name[1541:1554],
+	28667:   _ErrorCode_name[1554:1567],
+	28714:   _ErrorCode_name[1567:1580],
+	28724:   _ErrorCode_name[1580:1593],
+	28745:   _ErrorCode_name[1593:1606],
+	28746:   _ErrorCode_name[1606:1619],
+	28747:   _ErrorCode_name[1619:1632],
+	28748:   _ErrorCode_name[1632:1645],
+	28749:   _ErrorCode_name[1645:1658],
+	28756:   _ErrorCode_name[1658:1671],
+	28757:   _ErrorCode_name[1671:1684],
+	28758:   _ErrorCode_name[1684:1697],
+	28759:   _ErrorCode_name[1697:1710],
+	28762:   _ErrorCode_name[1710:1723],
+	28763:   _ErrorCode_name[1723:1736],
+	28764:   _ErrorCode_name[1736:1749],
+	28765:   _ErrorCode_name[1749:1762],
+	28803:   _ErrorCode_name[1762:1775],
+	28812:   _ErrorCode_name[1775:1788],
+	28818:   _ErrorCode_name[1788:1801],
+	31002:   _ErrorCode_name[1801:1814],
+	31022:   _ErrorCode_name[1814:1827],
+	31023:   _ErrorCode_name[1827:1840],
+	31024:   _ErrorCode_name[1840:1853],
+	31034:   _ErrorCode_name[1853:1866],
+	31119:   _ErrorCode_name[1866:1879],
+	31120:   _ErrorCode_name[1879:1892],
+	31138:   _ErrorCode_name[1892:1905],
+	31249:   _ErrorCode_name[1905:1918],
+	31250:   _ErrorCode_name[1918:1931],
+	31253:   _ErrorCode_name[1931:1944],
+	31254:   _ErrorCode_name[1944:1957],
+	31257:   _ErrorCode_name[1957:1970],
+	31258:   _ErrorCode_name[1970:1983],
+	31259:   _ErrorCode_name[1983:1996],
+	31272:   _ErrorCode_name[1996:2009],
+	31324:   _ErrorCode_name[2009:2022],
+	31325:   _ErrorCode_name[2022:2035],
+	31394:   _ErrorCode_name[2035:2048],
+	31395:   _ErrorCode_name[2048:2061],
+	34460:   _ErrorCode_name[2061:2074],
+	34461:   _ErrorCode_name[2074:2087],
+	34462:   _ErrorCode_name[2087:2100],
+	34463:   _ErrorCode_name[2100:2113],
+	34464:   _ErrorCode_name[2113:2126],
+	34465:   _ErrorCode_name[2126:2139],
+	34466:   _ErrorCode_name[2139:2152],
+	34467:   _ErrorCode_name[2152:2165],
+	34468:   _ErrorCode_name[2165:2178],
+	40060:   _ErrorCode_name[2178:2191],
+	40061:   _ErrorCode_name[2191:2204],
+	40062:   _ErrorCode_name[2204:2217],
+	40063:   _ErrorCode_name[2217:2230],
+	40064:   _ErrorCode_name[2230:2243],
+	40065:   _ErrorCode_name[2243:2256],
+	40066:   _ErrorCode_name[2256:2269],
+	40067:   _ErrorCode_name[2269:2282],
+	40068:   _ErrorCode_name[2282:2295],
+	40075:   _ErrorCode_name[2295:2308],
+	40076:   _ErrorCode_name[2308:2321],
+	40077:   _ErrorCode_name[2321:2334],
+	40078:   _ErrorCode_name[2334:2347],
+	40079:   _ErrorCode_name[2347:2360],
+	40080:   _ErrorCode_name[2360:2373],
+	40085:   _ErrorCode_name[2373:2386],
+	40086:   _ErrorCode_name[2386:2399],
+	40087:   _ErrorCode_name[2399:2412],
+	40093:   _ErrorCode_name[2412:2425],
+	40094:   _ErrorCode_name[2425:2438],
+	40096:   _ErrorCode_name[2438:2451],
+	40097:   _ErrorCode_name[2451:2464],
+	40156:   _ErrorCode_name[2464:2477],
+	40157:   _ErrorCode_name[2477:2490],
+	40158:   _ErrorCode_name[2490:2503],
+	40160:   _ErrorCode_name[2503:2516],
+	40169:   _ErrorCode_name[2516:2529],
+	40170:   _ErrorCode_name[2529:2542],
+	40171:   _ErrorCode_name[2542:2555],
+	40181:   _ErrorCode_name[2555:2568],
+	40191:   _ErrorCode_name[2568:2581],
+	40192:   _ErrorCode_name[2581:2594],
+	40193:   _ErrorCode_name[2594:2607],
+	40194:   _ErrorCode_name[2607:2620],
+	40195:   _ErrorCode_name[2620:2633],
+	40196:   _ErrorCode_name[2633:2646],
+	40197:   _ErrorCode_name[2646:2659],
+	40198:   _ErrorCode_name[2659:2672],
+	40199:   _ErrorCode_name[2672:2685],
+	40200:   _ErrorCode_name[2685:2698],
+	40201:   _ErrorCode_name[2698:2711],
+	40202:   _ErrorCode_name[2711:2724],
+	40218:   _ErrorCode_name[2724:2737],
+	40234:   _ErrorCode_name[2737:2750],
+	40237:   _ErrorCode_name[2750:2763],
+	40238:   _ErrorCode_name[2763:2776],
+	40239:   _ErrorCode_name[2776:2789],
+	40240:   _ErrorCode_name[2789:2802],
+	40241:   _ErrorCode_name[2802:2815],
+	40242:   _ErrorCode_name[2815:2828],
+	40243:   _ErrorCode_name[2828:2841],
+	40244:   _ErrorCode_name[2841:2854],
+	40245:   _ErrorCode_name[2854:2867],
+	40246:   _ErrorCode_name[2867:2880],
+	40272:   _ErrorCode_name[2880:2893],
+	40319:   _ErrorCode_name[2893:2906],
+	40323:   _ErrorCode_name[2906:2919],
+	40352:   _ErrorCode_name[2919:2932],
+	40353:   _ErrorCode_name[2932:2945],
+	40386:   _ErrorCode_name[2945:2958],
+	40390:   _ErrorCode_name[2958:2971],
+	40391:   _ErrorCode_name[2971:2984],
+	40392:   _ErrorCode_name[2984:2997],
+	40393:   _ErrorCode_name[2997:3010],
+	40394:   _ErrorCode_name[3010:3023],
+	40395:   _ErrorCode_name[3023:3036],
+	40397:   _ErrorCode_name[3036:3049],
+	40398:   _ErrorCode_name[3049:3062],
+	40414:   _ErrorCode_name[3062:3075],
+	40415:   _ErrorCode_name[3075:3088],
+	40485:   _ErrorCode_name[3088:3101],
+	40489:   _ErrorCode_name[3101:3114],
+	40515:   _ErrorCode_name[3114:3127],
+	40516:   _ErrorCode_name[3127:3140],
+	40517:   _ErrorCode_name[3140:3153],
+	40518:   _ErrorCode_name[3153:3166],
+	40519:   _ErrorCode_name[3166:3179],
+	40520:   _ErrorCode_name[3179:3192],
+	40521:   _ErrorCode_name[3192:3205],
+	40522:   _ErrorCode_name[3205:3218],
+	40523:   _ErrorCode_name[3218:3231],
+	40524:   _ErrorCode_name[3231:3244],
+	40540:   _ErrorCode_name[3244:3257],
+	40541:   _ErrorCode_name[3257:3270],
+	40542:   _ErrorCode_name[3270:3283],
+	40600:   _ErrorCode_name[3283:3296],
+	40601:   _ErrorCode_name[3296:3309],
+	40602:   _ErrorCode_name[3309:3322],
+	40684:   _ErrorCode_name[3322:3335],
+	50687:   _ErrorCode_name[3335:3348],
+	50692:   _ErrorCode_name[3348:3361],
+	50694:   _ErrorCode_name[3361:3374],
+	50695:   _ErrorCode_name[3374:3387],
+	50696:   _ErrorCode_name[3387:3400],
+	50699:   _ErrorCode_name[3400:3413],
+	50700:   _ErrorCode_name[3413:3426],
+	50736:   _ErrorCode_name[3426:3439],
+	50737:   _ErrorCode_name[3439:3452],
+	50738:   _ErrorCode_name[3452:3465],
+	50840:   _ErrorCode_name[3465:3478],
+	51003:   _ErrorCode_name[3478:3491],
+	51024:   _ErrorCode_name[3491:3504],
+	51047:   _ErrorCode_name[3504:3517],
+	51075:   _ErrorCode_name[3517:3530],
+	51081:   _ErrorCode_name[3530:3543],
+	51082:   _ErrorCode_name[3543:3556],
+	51083:   _ErrorCode_name[3556:3569],
+	51091:   _ErrorCode_name[3569:3582],
+	51103:   _ErrorCode_name[3582:3595],
+	51104:   _ErrorCode_name[3595:3608],
+	51105:   _ErrorCode_name[3608:3621],
+	51106:   _ErrorCode_name[3621:3634],
+	51107:   _ErrorCode_name[3634:3647],
+	51108:   _ErrorCode_name[3647:3660],
+	51109:   _ErrorCode_name[3660:3673],
+	51110:   _ErrorCode_name[3673:3686],
+	51111:   _ErrorCode_name[3686:3699],
+	51132:   _ErrorCode_name[3699:3712],
+	51134:   _ErrorCode_name[3712:3725],
+	51178:   _ErrorCode_name[3725:3738],
+	51182:   _ErrorCode_name[3738:3751],
+	51183:   _ErrorCode_name[3751:3764],
+	51186:   _ErrorCode_name[3764:3777],
+	51187:   _ErrorCode_name[3777:3790],
+	51246:   _ErrorCode_name[3790:3803],
+	51247:   _ErrorCode_name[3803:3816],
+	51270:   _ErrorCode_name[3816:3829],
+	51272:   _ErrorCode_name[3829:3842],
+	51744:   _ErrorCode_name[3842:3855],
+	51745:   _ErrorCode_name[3855:3868],
+	51746:   _ErrorCode_name[3868:3881],
+	51747:   _ErrorCode_name[3881:3894],
+	51748:   _ErrorCode_name[3894:3907],
+	51749:   _ErrorCode_name[3907:3920],
+	51750:   _ErrorCode_name[3920:3933],
+	51751:   _ErrorCode_name[3933:3946],
+	327391:  _ErrorCode_name[3946:3960],
+	327392:  _ErrorCode_name[3960:3974],
+	3041701: _ErrorCode_name[3974:3989],
+	3041702: _ErrorCode_name[3989:4004],
+	3041703: _ErrorCode_name[4004:4019],
+	3041705: _ErrorCode_name[4019:4034],
+	4031700: _ErrorCode_name[4034:4049],
+	4161100: _ErrorCode_name[4049:4064],
+	4161101: _ErrorCode_name[4064:4079],
+	4161102: _ErrorCode_name[4079:4094],
+	4161103: _ErrorCode_name[4094:4109],
+	4161104: _ErrorCode_name[4109:4124],
+	4161105: _ErrorCode_name[4124:4139],
+	4161106: _ErrorCode_name[4139:4154],
+	4161107: _ErrorCode_name[4154:4169],
+	4822819: _ErrorCode_name[4169:4184],
+	4940400: _ErrorCode_name[4184:4199],
+	5107200: _ErrorCode_name[4199:4214],
+	5107201: _ErrorCode_name[4214:4229],
+	5447000: _ErrorCode_name[4229:4244],
+	5654601: _ErrorCode_name[4244:4259],
+	5654602: _ErrorCode_name[4259:4274],
+	5739101: _ErrorCode_name[4274:4289],
+	5897900: _ErrorCode_name[4289:4304],
+	7582300: _ErrorCode_name[4304:4319],
 }
 
 func (i ErrorCode) String() string {
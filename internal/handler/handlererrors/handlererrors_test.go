@@ -16,9 +16,18 @@ package handlererrors
 
 import (
 	"io"
+	"net"
+	"os"
+	"syscall"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 func TestNoWrapping(t *testing.T) {
@@ -31,3 +40,205 @@ func TestErrorCodes(t *testing.T) {
 	assert.NotEmpty(t, errUnset.String())
 	assert.NotEmpty(t, errInternalError.String())
 }
+
+// sqlStateErr implements sqlStateError interface for tests.
+type sqlStateErr string
+
+func (e sqlStateErr) Error() string    { return "SQLSTATE " + string(e) }
+func (e sqlStateErr) SQLState() string { return string(e) }
+
+func TestProtocolErrorTransient(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		err    error
+		code   ErrorCode
+		labels []string
+	}{
+		"ConnectionDrop": {
+			err:    lazyerrors.Error(&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}),
+			code:   ErrHostUnreachable,
+			labels: []string{LabelRetryableWriteError},
+		},
+		"Timeout": {
+			err:    lazyerrors.Error(&net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}),
+			code:   ErrNetworkTimeout,
+			labels: []string{LabelRetryableWriteError},
+		},
+		"SerializationFailure": {
+			err:    lazyerrors.Error(sqlStateErr("40001")),
+			code:   ErrWriteConflict,
+			labels: []string{LabelTransientTransactionError},
+		},
+		"OtherSQLState": {
+			err:  lazyerrors.Error(sqlStateErr("23505")),
+			code: errInternalError,
+		},
+		"Other": {
+			err:  lazyerrors.New("other"),
+			code: errInternalError,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			protoErr := ProtocolError(tc.err)
+			require.IsType(t, new(CommandError), protoErr)
+
+			cmdErr := protoErr.(*CommandError)
+			assert.Equal(t, tc.code, cmdErr.Code())
+			assert.Equal(t, tc.labels, cmdErr.Labels())
+
+			labels, _ := protoErr.Document().Get("errorLabels")
+			if tc.labels == nil {
+				assert.Nil(t, labels)
+				return
+			}
+
+			assert.Equal(t, must.NotFail(types.NewArray(tc.labels[0])), labels)
+		})
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(types.NewDocument("_id", int32(42), "v", "foo"))
+
+	duplicate := &Classification{
+		KeyPattern: must.NotFail(types.NewDocument("_id", int32(1))),
+		KeyValue:   must.NotFail(types.NewDocument("_id", int32(42))),
+		Index:      "_id_",
+		Code:       ErrDuplicateKeyInsert,
+		Kind:       ErrorKindWrite,
+	}
+
+	for name, tc := range map[string]struct {
+		err      error
+		expected *Classification
+	}{
+		"InsertDuplicateID": {
+			err:      backends.NewError(backends.ErrorCodeInsertDuplicateID, nil),
+			expected: duplicate,
+		},
+		"DuplicateKeyIndex": {
+			err: backends.NewDuplicateKeyError(&backends.IndexInfo{
+				Name:   "v_-1_missing_1",
+				Key:    []backends.IndexKeyPair{{Field: "v", Descending: true}, {Field: "missing"}},
+				Unique: true,
+			}, nil),
+			expected: &Classification{
+				KeyPattern: must.NotFail(types.NewDocument("v", int32(-1), "missing", int32(1))),
+				KeyValue:   must.NotFail(types.NewDocument("v", "foo", "missing", types.Null)),
+				Index:      "v_-1_missing_1",
+				Code:       ErrDuplicateKeyInsert,
+				Kind:       ErrorKindWrite,
+			},
+		},
+		"UniqueViolation": {
+			err:      lazyerrors.Error(sqlStateErr("23505")),
+			expected: duplicate,
+		},
+		"SerializationFailure": {
+			err: lazyerrors.Error(sqlStateErr("40001")),
+			expected: &Classification{
+				Labels: []string{LabelTransientTransactionError},
+				Code:   ErrWriteConflict,
+				Kind:   ErrorKindCommand,
+			},
+		},
+		"Deadlock": {
+			err: lazyerrors.Error(sqlStateErr("40P01")),
+			expected: &Classification{
+				Labels: []string{LabelTransientTransactionError},
+				Code:   ErrWriteConflict,
+				Kind:   ErrorKindCommand,
+			},
+		},
+		"QueryCanceled": {
+			err: lazyerrors.Error(sqlStateErr("57014")),
+			expected: &Classification{
+				Code: ErrMaxTimeMSExpired,
+				Kind: ErrorKindCommand,
+			},
+		},
+		"AdminShutdown": {
+			err: lazyerrors.Error(sqlStateErr("57P01")),
+			expected: &Classification{
+				Labels: []string{LabelRetryableWriteError},
+				Code:   ErrInterruptedAtShutdown,
+				Kind:   ErrorKindCommand,
+			},
+		},
+		"ReadOnly": {
+			err: lazyerrors.Error(sqlStateErr("25006")),
+			expected: &Classification{
+				Labels: []string{LabelRetryableWriteError},
+				Code:   ErrNotWritablePrimary,
+				Kind:   ErrorKindCommand,
+			},
+		},
+		"TransactionResolutionUnknown": {
+			err: lazyerrors.Error(sqlStateErr("08007")),
+			expected: &Classification{
+				Code: ErrWriteConcernFailed,
+				Kind: ErrorKindWriteConcern,
+			},
+		},
+		"ConnectionDrop": {
+			err: lazyerrors.Error(&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}),
+			expected: &Classification{
+				Labels: []string{LabelRetryableWriteError},
+				Code:   ErrHostUnreachable,
+				Kind:   ErrorKindCommand,
+			},
+		},
+		"OtherSQLState": {
+			err: lazyerrors.Error(sqlStateErr("42P01")),
+		},
+		"Other": {
+			err: lazyerrors.New("other"),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, ClassifyError(tc.err, doc))
+		})
+	}
+}
+
+func TestClassificationWriteErrorDocument(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(types.NewDocument("_id", "foo"))
+	c := ClassifyError(sqlStateErr("23505"), doc)
+	require.NotNil(t, c)
+
+	expected := must.NotFail(types.NewDocument(
+		"index", int32(1),
+		"code", int32(11000),
+		"keyPattern", must.NotFail(types.NewDocument("_id", int32(1))),
+		"keyValue", must.NotFail(types.NewDocument("_id", "foo")),
+		"errmsg", "E11000 duplicate key error",
+	))
+	assert.Equal(t, expected, c.WriteErrorDocument(1, "E11000 duplicate key error"))
+}
+
+func TestClassificationDuplicateKeyMessage(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(types.NewDocument("_id", "foo"))
+
+	c := ClassifyError(sqlStateErr("23505"), doc)
+	require.NotNil(t, c)
+
+	expected := `E11000 duplicate key error collection: db.coll index: _id_ dup key: { _id: "foo" }`
+	assert.Equal(t, expected, c.DuplicateKeyMessage("db", "coll"))
+
+	c = ClassifyError(sqlStateErr("23505"), nil)
+	require.NotNil(t, c)
+
+	expected = `E11000 duplicate key error collection: db.coll index: _id_`
+	assert.Equal(t, expected, c.DuplicateKeyMessage("db", "coll"))
+}
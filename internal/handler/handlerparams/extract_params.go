@@ -82,6 +82,12 @@ func ExtractParams(doc *types.Document, command string, value any, l *zap.Logger
 			return lazyerrors.Error(err)
 		}
 
+		// Stable API parameters are checked for all commands before they are handled
+		switch key {
+		case "apiVersion", "apiStrict", "apiDeprecationErrors":
+			continue
+		}
+
 		lookup := key
 
 		// If the key is the same as the command name, then it is a collection name.
@@ -99,6 +99,21 @@ func TestParse(t *testing.T) {
 				Filter:     must.NotFail(types.NewDocument("a", "b")),
 			},
 		},
+		"APIParameters": {
+			command: "find",
+			doc: must.NotFail(types.NewDocument(
+				"$db", "test",
+				"find", "test",
+				"apiVersion", "1",
+				"apiStrict", true,
+				"apiDeprecationErrors", false,
+			)),
+			params: new(allTagsThatPass),
+			wantParams: &allTagsThatPass{
+				DB:         "test",
+				Collection: "test",
+			},
+		},
 		"UnimplementedTag": {
 			command: "command",
 			doc: must.NotFail(types.NewDocument(
@@ -81,9 +81,9 @@ func NewTypeCode(code int32) (TypeCode, error) {
 	switch c {
 	case TypeCodeDouble, TypeCodeString, TypeCodeObject, TypeCodeArray,
 		TypeCodeBinData, TypeCodeObjectID, TypeCodeBool, TypeCodeDate,
-		TypeCodeNull, TypeCodeRegex, TypeCodeInt, TypeCodeTimestamp, TypeCodeLong, TypeCodeNumber:
+		TypeCodeNull, TypeCodeRegex, TypeCodeInt, TypeCodeTimestamp, TypeCodeLong, TypeCodeDecimal, TypeCodeNumber:
 		return c, nil
-	case TypeCodeDecimal, TypeCodeMinKey, TypeCodeMaxKey:
+	case TypeCodeMinKey, TypeCodeMaxKey:
 		return 0, handlererrors.NewCommandErrorMsgWithArgument(
 			handlererrors.ErrNotImplemented,
 			fmt.Sprintf(`Type code %v not implemented`, code),
@@ -134,7 +134,7 @@ func init() {
 	for _, i := range []TypeCode{
 		TypeCodeDouble, TypeCodeString, TypeCodeObject, TypeCodeArray,
 		TypeCodeBinData, TypeCodeObjectID, TypeCodeBool, TypeCodeDate, TypeCodeNull,
-		TypeCodeRegex, TypeCodeInt, TypeCodeTimestamp, TypeCodeLong, TypeCodeNumber,
+		TypeCodeRegex, TypeCodeInt, TypeCodeTimestamp, TypeCodeLong, TypeCodeDecimal, TypeCodeNumber,
 	} {
 		aliasToTypeCode[i.String()] = i
 	}
@@ -169,6 +169,8 @@ func AliasFromType(v any) string {
 		return TypeCodeTimestamp.String()
 	case int64:
 		return TypeCodeLong.String()
+	case types.Decimal128:
+		return TypeCodeDecimal.String()
 	default:
 		panic(fmt.Sprintf("not supported type %T", v))
 	}
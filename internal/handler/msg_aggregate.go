@@ -16,16 +16,23 @@ package handler
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"math"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/bson"
 	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
 	"github.com/FerretDB/FerretDB/internal/handler/common"
@@ -33,6 +40,8 @@ import (
 	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations/stages"
 	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
 	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/handler/planner"
+	"github.com/FerretDB/FerretDB/internal/handler/queryshape"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
@@ -47,15 +56,13 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		return nil, lazyerrors.Error(err)
 	}
 
-	common.Ignored(document, h.L, "lsid")
-
-	if err = common.Unimplemented(document, "explain", "collation", "let"); err != nil {
+	if err = common.Unimplemented(document, "explain", "let"); err != nil {
 		return nil, err
 	}
 
 	common.Ignored(
 		document, h.L,
-		"allowDiskUse", "bypassDocumentValidation", "readConcern", "hint", "comment", "writeConcern",
+		"bypassDocumentValidation", "readConcern", "comment", "writeConcern",
 	)
 
 	var dbName string
@@ -69,17 +76,23 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		return nil, err
 	}
 
-	// handle collection-agnostic pipelines ({aggregate: 1})
+	// handle collection-agnostic pipelines ({aggregate: 1});
+	// only $queryStats is supported for now
 	// TODO https://github.com/FerretDB/FerretDB/issues/1890
-	var ok bool
+	var ok, agnostic bool
 	var cName string
 
 	if cName, ok = collectionParam.(string); !ok {
-		return nil, handlererrors.NewCommandErrorMsgWithArgument(
-			handlererrors.ErrFailedToParse,
-			"Invalid command format: the 'aggregate' field must specify a collection name or 1",
-			document.Command(),
-		)
+		if n, nErr := handlerparams.GetWholeNumberParam(collectionParam); nErr != nil || n != 1 {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrFailedToParse,
+				"Invalid command format: the 'aggregate' field must specify a collection name or 1",
+				document.Command(),
+			)
+		}
+
+		agnostic = true
+		cName = "$cmd.aggregate"
 	}
 
 	db, err := h.b.Database(dbName)
@@ -92,18 +105,26 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		return nil, lazyerrors.Error(err)
 	}
 
-	c, err := db.Collection(cName)
-	if err != nil {
-		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
-			msg := fmt.Sprintf("Invalid collection name: %s", cName)
-			return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, document.Command())
-		}
+	var c backends.Collection
 
-		return nil, lazyerrors.Error(err)
+	if !agnostic {
+		if c, err = db.Collection(cName); err != nil {
+			if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+				msg := fmt.Sprintf("Invalid collection name: %s", cName)
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, document.Command())
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
 	}
 
 	username := conninfo.Get(ctx).Username()
 
+	lsid, err := common.GetOptionalParam[*types.Document](document, "lsid", nil)
+	if err != nil {
+		return nil, err
+	}
+
 	v, _ := document.Get("maxTimeMS")
 	if v == nil {
 		v = int64(0)
@@ -169,6 +190,22 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		)
 	}
 
+	collationDoc, err := common.GetOptionalParam[*types.Document](document, "collation", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	collation, err := common.GetCollation(document.Command(), collationDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	// like MongoDB's allowDiskUseByDefault
+	allowDiskUse, err := common.GetOptionalParam(document, "allowDiskUse", true)
+	if err != nil {
+		return nil, err
+	}
+
 	pipeline, err := common.GetRequiredParam[*types.Array](document, "pipeline")
 	if err != nil {
 		return nil, handlererrors.NewCommandErrorMsgWithArgument(
@@ -181,6 +218,9 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 	aggregationStages := must.NotFail(iterator.ConsumeValues(pipeline.Iterator()))
 	stagesDocuments := make([]aggregations.Stage, 0, len(aggregationStages))
 	collStatsDocuments := make([]aggregations.Stage, 0, len(aggregationStages))
+	pipelineShape := types.MakeArray(len(aggregationStages))
+
+	var hasQueryStats, hasIndexStats, hasPlanCacheStats bool
 
 	for i, v := range aggregationStages {
 		var d *types.Document
@@ -195,7 +235,15 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 
 		var s aggregations.Stage
 
-		if s, err = stages.NewStage(d); err != nil {
+		s, err = stages.NewStage(d, &stages.NewStageParams{
+			Collation:       collation,
+			AllowDiskUse:    allowDiskUse,
+			SortMemoryLimit: h.SortMemoryLimitBytes,
+			Lookup:          h.lookupFunc(db),
+			Merge:           h.mergeFunc(db, dbName),
+			Out:             h.outFunc(db, dbName),
+		})
+		if err != nil {
 			return nil, err
 		}
 
@@ -209,11 +257,76 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 				)
 			}
 
+			collStatsDocuments = append(collStatsDocuments, s)
+		case "$indexStats":
+			if i > 0 {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrCollStatsIsNotFirstStage,
+					"$indexStats is only valid as the first stage in a pipeline",
+					document.Command(),
+				)
+			}
+
+			hasIndexStats = true
+			stagesDocuments = append(stagesDocuments, s)
+			collStatsDocuments = append(collStatsDocuments, s)
+		case "$planCacheStats":
+			if i > 0 {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrCollStatsIsNotFirstStage,
+					"$planCacheStats is only valid as the first stage in a pipeline",
+					document.Command(),
+				)
+			}
+
+			hasPlanCacheStats = true
+			stagesDocuments = append(stagesDocuments, s)
+			collStatsDocuments = append(collStatsDocuments, s)
+		case "$merge", "$out":
+			if i != len(aggregationStages)-1 {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrStageNotLast,
+					d.Command()+" can only be the final stage in the pipeline",
+					document.Command(),
+				)
+			}
+
+			stagesDocuments = append(stagesDocuments, s)
+			collStatsDocuments = append(collStatsDocuments, s)
+		case "$queryStats":
+			if i > 0 {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrCollStatsIsNotFirstStage,
+					"$queryStats is only valid as the first stage in a pipeline",
+					document.Command(),
+				)
+			}
+
+			if !agnostic || dbName != "admin" {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrInvalidNamespace,
+					"$queryStats must be run against the 'admin' database with {aggregate: 1}",
+					document.Command(),
+				)
+			}
+
+			hasQueryStats = true
+			stagesDocuments = append(stagesDocuments, s)
 			collStatsDocuments = append(collStatsDocuments, s)
 		default:
 			stagesDocuments = append(stagesDocuments, s)
 			collStatsDocuments = append(collStatsDocuments, s) // It's possible to apply any stage after $collStats stage
 		}
+
+		pipelineShape.Append(queryshape.Shape(d))
+	}
+
+	if agnostic && !hasQueryStats {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrNotImplemented,
+			"{aggregate: 1} is supported only for $queryStats stage",
+			document.Command(),
+		)
 	}
 
 	// validate cursor after validating pipeline stages to keep compatibility
@@ -248,8 +361,13 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		return nil, err
 	}
 
+	// the cursor should outlive the client connection,
+	// so the driver could continue iterating it using another connection
+	ctx = context.WithoutCancel(ctx)
+
 	cancel := func() {}
 
+	maxTimeMS = h.maxTimeMS(maxTimeMS)
 	if maxTimeMS != 0 {
 		findDone := make(chan struct{})
 		defer close(findDone)
@@ -271,8 +389,21 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 	closer := iterator.NewMultiCloser(iterator.CloserFunc(cancel))
 
 	var iter iterator.Interface[struct{}, *types.Document]
+	var tracker *queryshape.Tracker
+
+	switch {
+	case hasQueryStats:
+		iter, err = processStagesQueryStats(ctx, closer, h.queryStats.Stats(), stagesDocuments)
+
+	case hasIndexStats:
+		iter, err = processStagesIndexStats(ctx, closer, &stagesIndexStatsParams{
+			c, h.StateProvider.Get().Start, stagesDocuments,
+		})
 
-	if len(collStatsDocuments) == len(stagesDocuments) {
+	case hasPlanCacheStats:
+		iter, err = processStagesPlanCacheStats(ctx, closer, h.planCache.Entries(dbName, cName), stagesDocuments)
+
+	case len(collStatsDocuments) == len(stagesDocuments):
 		filter, sort := aggregations.GetPushdownQuery(aggregationStages)
 
 		// only documents stages or no stages - fetch documents from the DB and apply stages to them
@@ -280,6 +411,7 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 
 		if !h.DisablePushdown {
 			qp.Filter = filter
+			qp.Sample = aggregations.GetPushdownSample(aggregationStages)
 		}
 
 		if !h.EnableNestedPushdown && filter != nil {
@@ -294,6 +426,11 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 			}
 		}
 
+		// backends compare strings as bytes, so the filter is applied with collation in memory only
+		if collation != nil {
+			qp.Filter = nil
+		}
+
 		if sort, err = common.ValidateSortDocument(sort); err != nil {
 			closer.Close()
 
@@ -326,33 +463,76 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		switch {
 		case h.DisablePushdown:
 			// Pushdown disabled
+		case qp.Sample != 0:
+			// sampled documents are returned in random order
 		case sort.Len() == 0 && cInfo.Capped():
 			// Pushdown default recordID sorting for capped collections
 			qp.Sort = must.NotFail(types.NewDocument("$natural", int64(1)))
 		case sort.Len() == 1:
-			if sort.Keys()[0] != "$natural" {
+			// non-capped collections don't have record IDs;
+			// they are scanned in the natural order that is reversed in memory if needed
+			if sort.Keys()[0] != "$natural" || !cInfo.Capped() {
 				break
 			}
 
-			if !cInfo.Capped() {
+			qp.Sort = sort
+		}
+
+		var backward bool
+
+		if hint, _ := document.Get("hint"); hint != nil {
+			var plan *planner.Plan
+
+			plan, err = h.queryPlan(ctx, c, dbName, cName, &planner.Params{
+				Filter: filter,
+				Sort:   sort,
+				Hint:   hint,
+			}, document.Command())
+			if err != nil {
 				closer.Close()
-				return nil, handlererrors.NewCommandErrorMsgWithArgument(
-					handlererrors.ErrNotImplemented,
-					"$natural sort for non-capped collection is not supported.",
-					"aggregate",
-				)
+				return nil, err
 			}
 
-			qp.Sort = sort
+			// honor {$natural: -1} hint; only capped collections could be scanned backward,
+			// other collections are reversed in memory
+			if plan.Hinted && plan.Index == nil && plan.Backward && sort.Len() == 0 && qp.Sample == 0 {
+				switch {
+				case cInfo.Capped() && !h.DisablePushdown:
+					qp.Sort = must.NotFail(types.NewDocument("$natural", int64(-1)))
+				case !cInfo.Capped():
+					backward = true
+				}
+			}
 		}
 
-		iter, err = processStagesDocuments(ctx, closer, &stagesDocumentsParams{c, qp, stagesDocuments})
-	} else {
+		tracker = h.queryStats.Track(&queryshape.Query{
+			Command:    "aggregate",
+			DB:         dbName,
+			Collection: cName,
+			Pipeline:   pipelineShape,
+		})
+
+		iter, err = processStagesDocuments(ctx, closer, &stagesDocumentsParams{c, qp, stagesDocuments, tracker, backward})
+
+	default:
 		// TODO https://github.com/FerretDB/FerretDB/issues/2423
 		statistics := stages.GetStatistics(collStatsDocuments)
 
+		// sharding-aware tools expect a shard name for sharded collections
+		var shard string
+
+		var sharded *types.Document
+		if sharded, err = h.configGet(ctx, "collections", dbName+"."+cName); err != nil {
+			closer.Close()
+			return nil, lazyerrors.Error(err)
+		}
+
+		if sharded != nil {
+			shard = shardID
+		}
+
 		iter, err = processStagesStats(ctx, closer, &stagesStatsParams{
-			c, db, dbName, cName, statistics, collStatsDocuments,
+			c, db, dbName, cName, shard, statistics, collStatsDocuments, h.ExactCount,
 		})
 	}
 
@@ -361,9 +541,14 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		return nil, handleMaxTimeMSError(err, maxTimeMS, "aggregate")
 	}
 
+	if tracker != nil {
+		iter = tracker.Returned(iter)
+	}
+
 	closer.Add(iter)
 
 	cursor := h.cursors.NewCursor(ctx, iterator.WithClose(iter, closer.Close), &cursor.NewParams{
+		LSID:       lsid,
 		DB:         dbName,
 		Collection: cName,
 		Username:   username,
@@ -372,48 +557,41 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 
 	cursorID := cursor.ID
 
-	docs, err := iterator.ConsumeValuesN(cursor, int(batchSize))
+	firstBatch, done, err := h.makeBatch(ctx, cursor, batchSize)
 	if err != nil {
 		return nil, handleMaxTimeMSError(err, maxTimeMS, "aggregate")
 	}
 
-	h.L.Debug(
-		"Got first batch", zap.Int64("cursor_id", cursorID), zap.Stringer("type", cursor.Type),
-		zap.Int("count", len(docs)), zap.Int64("batch_size", batchSize),
-	)
-
-	firstBatch := types.MakeArray(len(docs))
-	for _, doc := range docs {
-		firstBatch.Append(doc)
-	}
-
-	if firstBatch.Len() < int(batchSize) {
+	if done {
 		// let the client know that there are no more results
 		cursorID = 0
-
-		cursor.Close()
 	}
 
-	var reply wire.OpMsg
-	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
-		must.NotFail(types.NewDocument(
-			"cursor", must.NotFail(types.NewDocument(
-				"firstBatch", firstBatch,
-				"id", cursorID,
-				"ns", dbName+"."+cName,
-			)),
-			"ok", float64(1),
+	// firstBatch contains already encoded documents, so they are not encoded again
+	reply, err := wire.NewOpMsg(must.NotFail(bson.NewDocument(
+		"cursor", must.NotFail(bson.NewDocument(
+			"firstBatch", firstBatch,
+			"id", cursorID,
+			"ns", dbName+"."+cName,
 		)),
+		"ok", float64(1),
 	)))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
 
-	return &reply, nil
+	return reply, nil
 }
 
 // stagesDocumentsParams contains the parameters for processStagesDocuments.
 type stagesDocumentsParams struct {
-	c      backends.Collection
-	qp     *backends.QueryParams
-	stages []aggregations.Stage
+	c       backends.Collection
+	qp      *backends.QueryParams
+	stages  []aggregations.Stage
+	tracker *queryshape.Tracker
+
+	// backward is true if documents should be reversed in memory before processing stages
+	backward bool
 }
 
 // processStagesDocuments retrieves the documents from the database and then processes them through the stages.
@@ -426,10 +604,18 @@ func processStagesDocuments(ctx context.Context, closer *iterator.MultiCloser, p
 
 	closer.Add(queryRes.Iter)
 
-	iter := queryRes.Iter
+	iter := p.tracker.Examined(queryRes.Iter)
+
+	if p.backward {
+		sort := must.NotFail(types.NewDocument("$natural", int64(-1)))
+		if iter, err = common.SortIterator(iter, closer, &common.SortParams{Sort: sort}); err != nil {
+			closer.Close()
+			return nil, lazyerrors.Error(err)
+		}
+	}
 
 	for _, s := range p.stages {
-		if iter, err = s.Process(ctx, iter, closer); err != nil {
+		if iter, err = processStage(ctx, s, iter, closer); err != nil {
 			return nil, err
 		}
 	}
@@ -437,14 +623,392 @@ func processStagesDocuments(ctx context.Context, closer *iterator.MultiCloser, p
 	return iter, nil
 }
 
+// processStage applies a single aggregation stage within a tracing span.
+//
+// Most stages return lazy iterators, so the span covers only the work done by Process itself.
+func processStage(ctx context.Context, s aggregations.Stage, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	ctx, span := otel.Tracer("").Start(ctx, stages.Name(s))
+	defer span.End()
+
+	res, err := s.Process(ctx, iter, closer)
+	if err != nil {
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+
+	return res, err
+}
+
+// lookupFunc returns a function that reads documents of the given database's collections for $lookup stage.
+//
+// The filter is pushed down the same way as for the aggregation pipeline itself.
+func (h *Handler) lookupFunc(db backends.Database) stages.LookupFunc {
+	return func(ctx context.Context, collection string, filter *types.Document) (types.DocumentsIterator, error) {
+		c, err := db.Collection(collection)
+		if err != nil {
+			if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+				msg := fmt.Sprintf("Invalid collection name: %s", collection)
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, "$lookup")
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		return h.queryFiltered(ctx, c, filter)
+	}
+}
+
+// queryFiltered returns documents of the given collection for $lookup and $merge stages.
+//
+// The filter is pushed down the same way as for the aggregation pipeline itself;
+// the caller should filter out extra documents.
+func (h *Handler) queryFiltered(ctx context.Context, c backends.Collection, filter *types.Document) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	qp := new(backends.QueryParams)
+
+	if !h.DisablePushdown {
+		qp.Filter = filter
+	}
+
+	if !h.EnableNestedPushdown && qp.Filter != nil {
+		qp.Filter = qp.Filter.DeepCopy()
+
+		for _, k := range qp.Filter.Keys() {
+			if strings.ContainsRune(k, '.') {
+				qp.Filter.Remove(k)
+			}
+		}
+	}
+
+	res, err := c.Query(ctx, qp)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res.Iter, nil
+}
+
+// mergeFunc returns a function that returns target collections for $merge stage,
+// with the given database being the current one.
+func (h *Handler) mergeFunc(db backends.Database, dbName string) stages.MergeFunc {
+	return func(ctx context.Context, targetDB, collection string, on []string) (stages.MergeCollection, error) {
+		_, c, targetDB, err := h.targetCollection(db, dbName, targetDB, collection, "$merge")
+		if err != nil {
+			return nil, err
+		}
+
+		if len(on) != 1 || on[0] != "_id" {
+			var unique bool
+
+			if unique, err = hasUniqueIndex(ctx, c, on); err != nil {
+				return nil, err
+			}
+
+			if !unique {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrStageMergeNoUniqueIndex,
+					"Cannot find index to verify that join fields will be unique",
+					"$merge",
+				)
+			}
+		}
+
+		return &mergeCollection{
+			h:      h,
+			c:      c,
+			dbName: targetDB,
+			cName:  collection,
+			stage:  "$merge",
+		}, nil
+	}
+}
+
+// outFunc returns a function that replaces documents of target collections for $out stage,
+// with the given database being the current one.
+//
+// Documents are written in batches to a temporary collection with the same indexes as the target one,
+// and then it is renamed over the target collection.
+func (h *Handler) outFunc(db backends.Database, dbName string) stages.OutFunc {
+	return func(ctx context.Context, targetDB, collection string, iter types.DocumentsIterator) error {
+		d, c, targetDB, err := h.targetCollection(db, dbName, targetDB, collection, "$out")
+		if err != nil {
+			return err
+		}
+
+		tmpName := "tmp.agg_out." + uuid.NewString()
+
+		tmp, err := d.Collection(tmpName)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if err = d.CreateCollection(ctx, &backends.CreateCollectionParams{Name: tmpName}); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		renamed := false
+
+		defer func() {
+			if renamed {
+				return
+			}
+
+			// drop the temporary collection even if the client disconnected
+			dropCtx := context.WithoutCancel(ctx)
+			if err := d.DropCollection(dropCtx, &backends.DropCollectionParams{Name: tmpName}); err != nil {
+				h.L.Warn("Failed to drop temporary $out collection", zap.String("name", tmpName), zap.Error(err))
+			}
+		}()
+
+		res, err := c.ListIndexes(ctx, nil)
+
+		switch {
+		case err == nil:
+			indexes := make([]backends.IndexInfo, 0, len(res.Indexes))
+
+			for _, index := range res.Indexes {
+				if index.Name != backends.DefaultIndexName {
+					indexes = append(indexes, index)
+				}
+			}
+
+			if len(indexes) > 0 {
+				if _, err = tmp.CreateIndexes(ctx, &backends.CreateIndexesParams{Indexes: indexes}); err != nil {
+					return lazyerrors.Error(err)
+				}
+			}
+
+		case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist):
+			// nothing to copy
+
+		default:
+			return lazyerrors.Error(err)
+		}
+
+		tc := &mergeCollection{
+			h:      h,
+			c:      tmp,
+			dbName: targetDB,
+			cName:  tmpName,
+			stage:  "$out",
+		}
+
+		batch := make([]*types.Document, 0, h.BatchSize)
+
+		insert := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+
+			if _, err := tmp.InsertAll(ctx, &backends.InsertAllParams{Docs: batch}); err != nil {
+				return tc.writeError(err, nil)
+			}
+
+			batch = batch[:0]
+
+			return nil
+		}
+
+		for {
+			_, doc, err := iter.Next()
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			if !doc.Has("_id") {
+				doc = doc.DeepCopy()
+				doc.Set("_id", types.NewObjectID())
+			}
+
+			if err = tc.validate(doc); err != nil {
+				return err
+			}
+
+			if batch = append(batch, doc); len(batch) == h.BatchSize {
+				if err = insert(); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err = insert(); err != nil {
+			return err
+		}
+
+		err = d.RenameCollection(ctx, &backends.RenameCollectionParams{
+			OldName:    tmpName,
+			NewName:    collection,
+			DropTarget: true,
+		})
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		renamed = true
+
+		return nil
+	}
+}
+
+// targetCollection returns the target database, collection, and database name
+// for $merge and $out stages; empty target database name means the current database.
+func (h *Handler) targetCollection(db backends.Database, dbName, targetDB, collection, stage string) (backends.Database, backends.Collection, string, error) { //nolint:lll // for readability
+	d := db
+
+	if targetDB == "" {
+		targetDB = dbName
+	} else {
+		var err error
+
+		if d, err = h.b.Database(targetDB); err != nil {
+			if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+				msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", targetDB, collection)
+				return nil, nil, "", handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, stage)
+			}
+
+			return nil, nil, "", lazyerrors.Error(err)
+		}
+	}
+
+	c, err := d.Collection(collection)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid collection name: %s", collection)
+			return nil, nil, "", handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, stage)
+		}
+
+		return nil, nil, "", lazyerrors.Error(err)
+	}
+
+	return d, c, targetDB, nil
+}
+
+// hasUniqueIndex returns true if the given collection has a unique index on exactly the given fields
+// in any order.
+//
+// Non-existing collections have no indexes.
+func hasUniqueIndex(ctx context.Context, c backends.Collection, fields []string) (bool, error) {
+	res, err := c.ListIndexes(ctx, new(backends.ListIndexesParams))
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist) {
+			return false, nil
+		}
+
+		return false, lazyerrors.Error(err)
+	}
+
+	for _, index := range res.Indexes {
+		if !index.Unique || len(index.Key) != len(fields) {
+			continue
+		}
+
+		unique := true
+
+		for _, pair := range index.Key {
+			if !slices.Contains(fields, pair.Field) {
+				unique = false
+				break
+			}
+		}
+
+		if unique {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// mergeCollection implements stages.MergeCollection on top of the backend collection.
+//
+// It is also used for writing to the temporary collection of $out stage.
+type mergeCollection struct {
+	h      *Handler
+	c      backends.Collection
+	dbName string
+	cName  string
+	stage  string // for errors
+}
+
+// Query implements stages.MergeCollection interface.
+func (mc *mergeCollection) Query(ctx context.Context, filter *types.Document) (types.DocumentsIterator, error) {
+	return mc.h.queryFiltered(ctx, mc.c, filter)
+}
+
+// Insert implements stages.MergeCollection interface.
+func (mc *mergeCollection) Insert(ctx context.Context, doc *types.Document) error {
+	if err := mc.validate(doc); err != nil {
+		return err
+	}
+
+	if _, err := mc.c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{doc}}); err != nil {
+		return mc.writeError(err, doc)
+	}
+
+	return nil
+}
+
+// Replace implements stages.MergeCollection interface.
+func (mc *mergeCollection) Replace(ctx context.Context, doc *types.Document) error {
+	if err := mc.validate(doc); err != nil {
+		return err
+	}
+
+	if _, err := mc.c.UpdateAll(ctx, &backends.UpdateAllParams{Docs: []*types.Document{doc}}); err != nil {
+		return mc.writeError(err, doc)
+	}
+
+	return nil
+}
+
+// validate returns an error if the given document could not be stored.
+func (mc *mergeCollection) validate(doc *types.Document) error {
+	err := doc.ValidateData()
+	if err == nil {
+		return nil
+	}
+
+	var ve *types.ValidationError
+	if !errors.As(err, &ve) {
+		return lazyerrors.Error(err)
+	}
+
+	code := handlererrors.ErrBadValue
+	if ve.Code() == types.ErrWrongIDType {
+		code = handlererrors.ErrInvalidID
+	}
+
+	return handlererrors.NewCommandErrorMsgWithArgument(code, ve.Error(), mc.stage)
+}
+
+// writeError returns the error for the failed write of the given document.
+//
+// Unlike insert and update commands, $merge and $out return write errors like duplicate key as command errors.
+func (mc *mergeCollection) writeError(err error, doc *types.Document) error {
+	cl := handlererrors.ClassifyError(err, doc)
+	if cl == nil || cl.Kind != handlererrors.ErrorKindWrite {
+		return lazyerrors.Error(err)
+	}
+
+	return handlererrors.NewCommandErrorMsgWithArgument(
+		cl.Code,
+		cl.DuplicateKeyMessage(mc.dbName, mc.cName),
+		mc.stage,
+	)
+}
+
 // stagesStatsParams contains the parameters for processStagesStats.
 type stagesStatsParams struct {
 	c          backends.Collection
 	db         backends.Database
 	dbName     string
 	cName      string
+	shard      string
 	statistics map[stages.Statistic]struct{}
 	stages     []aggregations.Stage
+	exactCount bool
 }
 
 // processStagesStats retrieves the statistics from the database and then processes them through the stages.
@@ -470,6 +1034,10 @@ func processStagesStats(ctx context.Context, closer *iterator.MultiCloser, p *st
 		"localTime", time.Now().UTC().Format(time.RFC3339),
 	))
 
+	if p.shard != "" {
+		doc.Set("shard", p.shard)
+	}
+
 	var (
 		collStats *backends.CollectionStatsResult
 		cInfo     backends.CollectionInfo
@@ -548,8 +1116,21 @@ func processStagesStats(ctx context.Context, closer *iterator.MultiCloser, p *st
 	}
 
 	if hasCount {
+		count := collStats.CountDocuments
+
+		if p.exactCount {
+			var countRes *backends.CountResult
+			if countRes, err = p.c.Count(ctx, new(backends.CountParams)); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			if countRes.FilterPushdown {
+				count = countRes.Count
+			}
+		}
+
 		doc.Set(
-			"count", collStats.CountDocuments,
+			"count", count,
 		)
 	}
 
@@ -558,10 +1139,194 @@ func processStagesStats(ctx context.Context, closer *iterator.MultiCloser, p *st
 	closer.Add(iter)
 
 	for _, s := range p.stages {
-		if iter, err = s.Process(ctx, iter, closer); err != nil {
+		if iter, err = processStage(ctx, s, iter, closer); err != nil {
+			return nil, err
+		}
+	}
+
+	return iter, nil
+}
+
+// processStagesQueryStats converts query shape statistics to documents
+// and then processes them through the stages (starting with $queryStats).
+func processStagesQueryStats(ctx context.Context, closer *iterator.MultiCloser, stats []queryshape.Stats, pipeline []aggregations.Stage) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	asOf := types.NextTimestamp(time.Now())
+	docs := make([]*types.Document, len(stats))
+
+	for i, s := range stats {
+		shape := must.NotFail(types.NewDocument(
+			"cmdNs", must.NotFail(types.NewDocument("db", s.Query.DB, "coll", s.Query.Collection)),
+			"command", s.Query.Command,
+		))
+
+		if s.Query.Filter != nil {
+			shape.Set("filter", s.Query.Filter.DeepCopy())
+		}
+
+		if s.Query.Sort != nil {
+			shape.Set("sort", s.Query.Sort.DeepCopy())
+		}
+
+		if s.Query.Pipeline != nil {
+			shape.Set("pipeline", s.Query.Pipeline.DeepCopy())
+		}
+
+		hash := sha256.Sum256([]byte(s.Key))
+
+		docs[i] = must.NotFail(types.NewDocument(
+			"key", must.NotFail(types.NewDocument("queryShape", shape)),
+			"keyHash", base64.StdEncoding.EncodeToString(hash[:]),
+			"metrics", must.NotFail(types.NewDocument(
+				"lastExecutionMicros", s.LastExecMicro,
+				"execCount", s.ExecCount,
+				"totalExecMicros", queryStatsMetric(s.ExecMicros),
+				"docsReturned", queryStatsMetric(s.DocsReturned),
+				"docsExamined", queryStatsMetric(s.DocsExamined),
+				"firstSeenTimestamp", s.FirstSeen,
+				"latestSeenTimestamp", s.LastSeen,
+			)),
+			"asOf", asOf,
+		))
+	}
+
+	iter := iterator.Values(iterator.ForSlice(docs))
+	closer.Add(iter)
+
+	var err error
+
+	for _, s := range pipeline {
+		if iter, err = processStage(ctx, s, iter, closer); err != nil {
+			return nil, err
+		}
+	}
+
+	return iter, nil
+}
+
+// stagesIndexStatsParams contains the parameters for processStagesIndexStats.
+type stagesIndexStatsParams struct {
+	c      backends.Collection
+	since  time.Time
+	stages []aggregations.Stage
+}
+
+// processStagesIndexStats converts collection indexes to documents
+// and then processes them through the stages (starting with $indexStats).
+//
+// Index usage is not tracked yet, so accesses.ops is always 0.
+func processStagesIndexStats(ctx context.Context, closer *iterator.MultiCloser, p *stagesIndexStatsParams) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var indexes []backends.IndexInfo
+
+	res, err := p.c.ListIndexes(ctx, nil)
+
+	switch {
+	case err == nil:
+		indexes = res.Indexes
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist):
+		// non-existent collection has no indexes
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	docs := make([]*types.Document, len(indexes))
+
+	for i, index := range indexes {
+		key := indexKeyDocument(index.Key)
+
+		spec := must.NotFail(types.NewDocument(
+			"v", int32(2),
+			"key", key.DeepCopy(),
+			"name", index.Name,
+		))
+
+		if index.Unique && index.Name != backends.DefaultIndexName {
+			spec.Set("unique", true)
+		}
+
+		docs[i] = must.NotFail(types.NewDocument(
+			"name", index.Name,
+			"key", key,
+			"host", host,
+			"accesses", must.NotFail(types.NewDocument(
+				"ops", int64(0),
+				"since", p.since,
+			)),
+			"spec", spec,
+		))
+	}
+
+	iter := iterator.Values(iterator.ForSlice(docs))
+	closer.Add(iter)
+
+	for _, s := range p.stages {
+		if iter, err = processStage(ctx, s, iter, closer); err != nil {
 			return nil, err
 		}
 	}
 
 	return iter, nil
 }
+
+// processStagesPlanCacheStats converts cached plans to documents
+// and then processes them through the stages (starting with $planCacheStats).
+//
+//nolint:lll // for readability
+func processStagesPlanCacheStats(ctx context.Context, closer *iterator.MultiCloser, entries []planner.Entry, pipeline []aggregations.Stage) (types.DocumentsIterator, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	docs := make([]*types.Document, len(entries))
+
+	for i, e := range entries {
+		filter, sort := e.Filter, e.Sort
+
+		if filter == nil {
+			filter = must.NotFail(types.NewDocument())
+		}
+
+		if sort == nil {
+			sort = must.NotFail(types.NewDocument())
+		}
+
+		docs[i] = must.NotFail(types.NewDocument(
+			"version", "1",
+			"planCacheKey", e.Key,
+			"isActive", true,
+			"createdFromQuery", must.NotFail(types.NewDocument(
+				"query", filter,
+				"sort", sort,
+				"projection", must.NotFail(types.NewDocument()),
+			)),
+			"cachedPlan", planStage(e.Plan, filter),
+			"timeOfCreation", e.Created,
+			"host", host,
+		))
+	}
+
+	iter := iterator.Values(iterator.ForSlice(docs))
+	closer.Add(iter)
+
+	for _, s := range pipeline {
+		if iter, err = processStage(ctx, s, iter, closer); err != nil {
+			return nil, err
+		}
+	}
+
+	return iter, nil
+}
+
+// queryStatsMetric returns $queryStats document for the given metric.
+func queryStatsMetric(m queryshape.Metric) *types.Document {
+	return must.NotFail(types.NewDocument(
+		"sum", m.Sum,
+		"max", m.Max,
+		"min", m.Min,
+	))
+}
@@ -0,0 +1,209 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgApplyOps implements `applyOps` command.
+//
+// Entries are applied one by one, not atomically.
+// The first failed entry stops the command.
+func (h *Handler) MsgApplyOps(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	params, err := common.GetApplyOpsParams(document, h.L)
+	if err != nil {
+		return nil, err
+	}
+
+	results := types.MakeArray(len(params.Ops))
+
+	for _, op := range params.Ops {
+		if err = h.applyOp(ctx, op); err != nil {
+			return nil, err
+		}
+
+		results.Append(true)
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
+		must.NotFail(types.NewDocument(
+			"applied", int32(results.Len()),
+			"results", results,
+			"ok", float64(1),
+		)),
+	)))
+
+	return &reply, nil
+}
+
+// applyOp applies a single oplog entry.
+func (h *Handler) applyOp(ctx context.Context, op *common.ApplyOp) error {
+	if op.Op == "n" {
+		return nil
+	}
+
+	if op.Op == "c" && op.Doc == nil {
+		for _, nested := range op.Ops {
+			if err := h.applyOp(ctx, nested); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	db, err := h.b.Database(op.DB)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", op.DB, op.Collection)
+			return handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, "applyOps")
+		}
+
+		return lazyerrors.Error(err)
+	}
+
+	c, err := db.Collection(op.Collection)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid collection name: %s", op.Collection)
+			return handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, "applyOps")
+		}
+
+		return lazyerrors.Error(err)
+	}
+
+	switch op.Op {
+	case "i":
+		if op.Update != nil {
+			_, err = h.applyOpUpdate(ctx, c, op.Update)
+			return err
+		}
+
+		_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{op.Doc}})
+		if backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID) {
+			msg := handlererrors.ClassifyError(err, op.Doc).DuplicateKeyMessage(op.DB, op.Collection)
+			return handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrDuplicateKeyInsert, msg, "applyOps")
+		}
+
+	case "u":
+		if op.Update == nil {
+			return nil
+		}
+
+		var res *common.UpdateResult
+		if res, err = h.applyOpUpdate(ctx, c, op.Update); err != nil {
+			return err
+		}
+
+		if res.Matched.Count == 0 && res.Upserted.Doc == nil {
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrNoMatchingDocument,
+				fmt.Sprintf("applyOps: couldn't find document to update: %s", types.FormatAnyValue(op.Update.Filter)),
+				"applyOps",
+			)
+		}
+
+	case "d":
+		_, err = c.DeleteAll(ctx, &backends.DeleteAllParams{IDs: []any{must.NotFail(op.Doc.Get("_id"))}})
+
+	case "c":
+		err = h.applyOpCreateIndex(ctx, c, op.Doc)
+
+	default:
+		panic(fmt.Sprintf("unexpected op type %q", op.Op))
+	}
+
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// applyOpUpdate updates (or upserts) a single document.
+func (h *Handler) applyOpUpdate(ctx context.Context, c backends.Collection, u *common.Update) (*common.UpdateResult, error) {
+	var qp backends.QueryParams
+	if !h.DisablePushdown {
+		qp.Filter = u.Filter
+	}
+
+	queryRes, err := c.Query(ctx, &qp)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	closer := iterator.NewMultiCloser()
+	defer closer.Close()
+
+	closer.Add(queryRes.Iter)
+
+	iter := common.FilterIterator(queryRes.Iter, closer, u.Filter, nil)
+	iter = common.LimitIterator(iter, closer, 1)
+
+	return common.UpdateDocument(ctx, c, "applyOps", iter, u)
+}
+
+// applyOpCreateIndex creates the index with the given specification if it does not exist.
+func (h *Handler) applyOpCreateIndex(ctx context.Context, c backends.Collection, spec *types.Document) error {
+	index, err := processIndex("applyOps", spec)
+	if err != nil {
+		return err
+	}
+
+	var existing []backends.IndexInfo
+
+	res, err := c.ListIndexes(ctx, new(backends.ListIndexesParams))
+
+	switch {
+	case err == nil:
+		existing = res.Indexes
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist):
+		// the collection will be created with the index
+	default:
+		return lazyerrors.Error(err)
+	}
+
+	toCreate, err := validateIndexesForCreation("applyOps", existing, []backends.IndexInfo{*index})
+	if err != nil {
+		return err
+	}
+
+	if len(toCreate) == 0 {
+		return nil
+	}
+
+	if _, err = c.CreateIndexes(ctx, &backends.CreateIndexesParams{Indexes: toCreate}); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
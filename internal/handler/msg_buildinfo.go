@@ -32,28 +32,33 @@ func (h *Handler) MsgBuildInfo(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		aggregationStages.Append(stage)
 	}
 
-	var reply wire.OpMsg
-	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
-		must.NotFail(types.NewDocument(
-			"version", version.Get().MongoDBVersion,
-			"gitVersion", version.Get().Commit,
-			"modules", must.NotFail(types.NewArray()),
-			"sysInfo", "deprecated",
-			"versionArray", version.Get().MongoDBVersionArray,
-			"bits", int32(strconv.IntSize),
-			"debug", version.Get().DebugBuild,
-			"maxBsonObjectSize", int32(h.MaxBsonObjectSizeBytes),
-			"buildEnvironment", version.Get().BuildEnvironment,
-
-			// our extensions
-			"ferretdbVersion", version.Get().Version,
-			"ferretdbFeatures", must.NotFail(types.NewDocument(
-				"aggregationStages", aggregationStages,
-			)),
-
-			"ok", float64(1),
+	res := must.NotFail(types.NewDocument(
+		"version", h.serverVersion.version,
+		"gitVersion", version.Get().Commit,
+		"modules", must.NotFail(types.NewArray()),
+		"sysInfo", "deprecated",
+		"versionArray", h.serverVersion.versionArray,
+		"bits", int32(strconv.IntSize),
+		"debug", version.Get().DebugBuild,
+		"maxBsonObjectSize", int32(h.MaxBsonObjectSizeBytes),
+		"buildEnvironment", version.Get().BuildEnvironment,
+
+		// our extensions
+		"ferretdbVersion", version.Get().Version,
+		"ferretdbFeatures", must.NotFail(types.NewDocument(
+			"aggregationStages", aggregationStages,
 		)),
-	)))
+
+		"ok", float64(1),
+	))
+
+	if !h.extensions() {
+		res.Remove("ferretdbVersion")
+		res.Remove("ferretdbFeatures")
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.MakeOpMsgSection(res)))
 
 	return &reply, nil
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handler/common"
 	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/planner"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
@@ -61,6 +62,39 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		return nil, lazyerrors.Error(err)
 	}
 
+	if params.Hint != nil {
+		if _, err = h.queryPlan(ctx, c, params.DB, params.Collection, &planner.Params{
+			Filter: params.Filter,
+			Hint:   params.Hint,
+		}, "count"); err != nil {
+			return nil, err
+		}
+	}
+
+	if !h.DisablePushdown {
+		var countRes *backends.CountResult
+
+		countParams := &backends.CountParams{
+			Filter:   params.Filter,
+			Estimate: !h.ExactCount,
+		}
+
+		if countRes, err = c.Count(ctx, countParams); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		// skip and limit are applied on top of the pushed down count
+		if countRes.FilterPushdown {
+			n := max(countRes.Count-params.Skip, 0)
+
+			if params.Limit > 0 {
+				n = min(n, params.Limit)
+			}
+
+			return countReply(int32(n)), nil
+		}
+	}
+
 	var qp backends.QueryParams
 	if !h.DisablePushdown {
 		qp.Filter = params.Filter
@@ -76,7 +110,7 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 	closer := iterator.NewMultiCloser(iter)
 	defer closer.Close()
 
-	iter = common.FilterIterator(iter, closer, params.Filter)
+	iter = common.FilterIterator(iter, closer, params.Filter, nil)
 
 	iter = common.SkipIterator(iter, closer, params.Skip)
 
@@ -96,6 +130,11 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 	count, _ := res.Get("count")
 	n, _ := count.(int32)
 
+	return countReply(n), nil
+}
+
+// countReply returns the reply for the count command with the given number of documents.
+func countReply(n int32) *wire.OpMsg {
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
 		must.NotFail(types.NewDocument(
@@ -104,5 +143,5 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		)),
 	)))
 
-	return &reply, nil
+	return &reply
 }
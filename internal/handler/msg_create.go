@@ -51,6 +51,7 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 	ignoredFields := []string{
 		"autoIndexId",
+		"idIndex",
 		"storageEngine",
 		"indexOptionDefaults",
 		"writeConcern",
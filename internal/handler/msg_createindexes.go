@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"slices"
 	"strings"
 
@@ -242,6 +243,10 @@ func processIndex(command string, indexDoc *types.Document) (*backends.IndexInfo
 				)
 			}
 
+			if err = validateIndexOptions(command, &index); err != nil {
+				return nil, err
+			}
+
 			return &index, nil
 		default:
 			return nil, lazyerrors.Error(err)
@@ -351,16 +356,95 @@ func processIndex(command string, indexDoc *types.Document) (*backends.IndexInfo
 				index.Unique = true
 			}
 
-		case "background":
-			// ignore deprecated options
+		case "v":
+			v := must.NotFail(indexDoc.Get("v"))
+
+			var version int64
+
+			version, err = handlerparams.GetWholeNumberParam(v)
+			if err != nil || (version != 1 && version != 2) {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrCannotCreateIndex,
+					fmt.Sprintf(
+						"Invalid index specification { key: %s, name: %q, v: %s }; cannot create an index with v=%s",
+						types.FormatAnyValue(must.NotFail(indexDoc.Get("key"))),
+						index.Name, types.FormatAnyValue(v), types.FormatAnyValue(v),
+					),
+					command,
+				)
+			}
+
+		case "background", "ns":
+			// ignore deprecated options;
+			// "ns" is present in index specifications dumped by older versions of mongodump
+
+		case "sparse", "hidden":
+			v := must.NotFail(indexDoc.Get(opt))
+
+			var b bool
+
+			if b, err = handlerparams.GetBoolOptionalParam(opt, v); err != nil {
+				return nil, err
+			}
+
+			if opt == "sparse" {
+				// Sparse indexes are not enforced by backends for now.
+				// TODO https://github.com/FerretDB/FerretDB/issues/2448
+				index.Sparse = b
+			} else {
+				index.Hidden = b
+			}
 
-		case "sparse":
-			// Ignore for now to make Meteor apps work.
-			// TODO https://github.com/FerretDB/FerretDB/issues/2448
+		case "expireAfterSeconds":
+			v := must.NotFail(indexDoc.Get(opt))
 
-		case "partialFilterExpression", "expireAfterSeconds", "hidden", "storageEngine",
+			var seconds int64
+
+			seconds, err = handlerparams.GetWholeNumberParam(v)
+			if err != nil || seconds < 0 || seconds > math.MaxInt32 {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrInvalidIndexSpecificationOption,
+					fmt.Sprintf(
+						"TTL index 'expireAfterSeconds' option must be within an acceptable range, "+
+							"try a lower number. Index spec: { key: %s, name: %q, expireAfterSeconds: %s }",
+						types.FormatAnyValue(must.NotFail(indexDoc.Get("key"))),
+						index.Name, types.FormatAnyValue(v),
+					),
+					command,
+				)
+			}
+
+			expireAfterSeconds := int32(seconds)
+			index.ExpireAfterSeconds = &expireAfterSeconds
+
+		case "partialFilterExpression", "collation", "wildcardProjection":
+			v := must.NotFail(indexDoc.Get(opt))
+
+			doc, ok := v.(*types.Document)
+			if !ok {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrTypeMismatch,
+					fmt.Sprintf("The field '%s' must be an object, but got %s", opt, handlerparams.AliasFromType(v)),
+					command,
+				)
+			}
+
+			switch opt {
+			case "partialFilterExpression":
+				index.PartialFilterExpression = doc
+			case "collation":
+				if _, err = common.GetCollation(command, doc); err != nil {
+					return nil, err
+				}
+
+				index.Collation = doc
+			case "wildcardProjection":
+				index.WildcardProjection = doc
+			}
+
+		case "storageEngine",
 			"weights", "default_language", "language_override", "textIndexVersion", "2dsphereIndexVersion",
-			"bits", "min", "max", "bucketSize", "collation", "wildcardProjection":
+			"bits", "min", "max", "bucketSize":
 			return nil, handlererrors.NewCommandErrorMsgWithArgument(
 				handlererrors.ErrNotImplemented,
 				fmt.Sprintf("Index option %q is not implemented yet", opt),
@@ -377,6 +461,61 @@ func processIndex(command string, indexDoc *types.Document) (*backends.IndexInfo
 	}
 }
 
+// validateIndexOptions checks that index options are valid together.
+//
+// Options that change the set of unique values are not implemented for unique indexes,
+// because backends do not take them into account yet.
+func validateIndexOptions(command string, index *backends.IndexInfo) error {
+	isID := len(index.Key) == 1 && index.Key[0].Field == "_id"
+
+	var isWildcard bool
+
+	for _, pair := range index.Key {
+		if pair.Field == "$**" || strings.HasSuffix(pair.Field, ".$**") {
+			isWildcard = true
+		}
+	}
+
+	switch {
+	case index.Hidden && isID:
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrBadValue,
+			"can't hide _id index",
+			command,
+		)
+
+	case index.ExpireAfterSeconds != nil && len(index.Key) > 1:
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrCannotCreateIndex,
+			"TTL indexes are single-field indexes, compound indexes do not support TTL.",
+			command,
+		)
+
+	case index.WildcardProjection != nil && !isWildcard:
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrBadValue,
+			"The field 'wildcardProjection' is only allowed in an 'wildcard' index",
+			command,
+		)
+
+	case index.Unique && index.PartialFilterExpression != nil:
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrNotImplemented,
+			"Index option \"partialFilterExpression\" is not implemented yet for unique indexes",
+			command,
+		)
+
+	case index.Unique && index.Collation != nil:
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrNotImplemented,
+			"Index option \"collation\" is not implemented yet for unique indexes",
+			command,
+		)
+	}
+
+	return nil
+}
+
 // processIndexKey processes the document containing the index key (set of "field-order" pairs).
 func processIndexKey(command string, keyDoc *types.Document) ([]backends.IndexKeyPair, error) {
 	res := make([]backends.IndexKeyPair, 0, keyDoc.Len())
@@ -411,6 +550,14 @@ func processIndexKey(command string, keyDoc *types.Document) ([]backends.IndexKe
 
 		duplicateChecker[field] = struct{}{}
 
+		if field == "$**" || strings.HasSuffix(field, ".$**") {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrNotImplemented,
+				"Wildcard indexes are not implemented yet",
+				command,
+			)
+		}
+
 		var orderParam int64
 
 		if orderParam, err = handlerparams.GetWholeNumberParam(order); err != nil {
@@ -443,6 +590,22 @@ func processIndexKey(command string, keyDoc *types.Document) ([]backends.IndexKe
 	}
 }
 
+// indexKeyDocument returns the given index key as a key pattern document like {a: 1, b: -1}.
+func indexKeyDocument(key []backends.IndexKeyPair) *types.Document {
+	res := types.MakeDocument(len(key))
+
+	for _, pair := range key {
+		order := int32(1)
+		if pair.Descending {
+			order = -1
+		}
+
+		res.Set(pair.Field, order)
+	}
+
+	return res
+}
+
 // formatIndexKey formats the given index key to a string.
 func formatIndexKey(key []backends.IndexKeyPair) string {
 	res := make([]string, len(key))
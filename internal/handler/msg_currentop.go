@@ -23,11 +23,20 @@ import (
 )
 
 // MsgCurrentOp implements `currentOp` command.
+//
+// Only background operations (like reIndex with background option) are reported.
 func (h *Handler) MsgCurrentOp(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	ops := h.operations.list()
+
+	inprog := types.MakeArray(len(ops))
+	for _, op := range ops {
+		inprog.Append(op.document())
+	}
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
 		must.NotFail(types.NewDocument(
-			"inprog", must.NotFail(types.NewArray()),
+			"inprog", inprog,
 			"ok", float64(1),
 		)),
 	)))
@@ -29,6 +29,8 @@ import (
 )
 
 // MsgDebugError implements `debugError` command.
+//
+//checkerrors:ignore // plain errors are returned intentionally
 func (h *Handler) MsgDebugError(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
 	document, err := msg.Document()
 	if err != nil {
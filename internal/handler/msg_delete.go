@@ -19,8 +19,6 @@ import (
 	"errors"
 	"fmt"
 
-	"go.mongodb.org/mongo-driver/mongo"
-
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handler/common"
 	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
@@ -63,24 +61,26 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
-	var deleted int32
-	writeErrors := types.MakeArray(0)
+	wb := newWriteBatch(c, h.BatchSize, params.Ordered)
+
+	deleted := make([]int32, len(params.Deletes))
+	var errs []statementError
 
 	for i, p := range params.Deletes {
-		d, err := h.execDelete(ctx, c, &p)
+		wb.setIndex(i)
+
+		d, err := h.execDelete(ctx, wb, &p)
 
-		deleted += d
+		deleted[i] = d
 
 		if err != nil {
+			if errors.Is(err, errWriteBatchAborted) {
+				break
+			}
+
 			var ce *handlererrors.CommandError
 			if errors.As(err, &ce) {
-				we := &mongo.WriteError{
-					Index:   i,
-					Code:    int(ce.Code()),
-					Message: ce.Err().Error(),
-				}
-
-				writeErrors.Append(WriteErrorDocument(we))
+				errs = append(errs, statementError{err: ce, index: i})
 
 				if params.Ordered {
 					break
@@ -93,11 +93,31 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		}
 	}
 
+	failures, err := wb.flush(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// statements with rejected changes failed, so their results are not counted
+	for _, f := range failures {
+		deleted[f.index] = 0
+	}
+
+	writeErrors, first := writeErrorsArray(params.DB, params.Collection, errs, failures, params.Ordered)
+	if params.Ordered && first >= 0 {
+		deleted = deleted[:first]
+	}
+
+	var n int32
+	for _, d := range deleted {
+		n += d
+	}
+
 	res := must.NotFail(types.NewDocument(
-		"n", deleted,
+		"n", n,
 	))
 
-	if writeErrors.Len() > 0 {
+	if writeErrors != nil {
 		res.Set("writeErrors", writeErrors)
 	}
 
@@ -141,7 +161,7 @@ func (h *Handler) execDelete(ctx context.Context, c backends.Collection, p *comm
 
 		var matches bool
 
-		if matches, err = common.FilterDocument(doc, p.Filter); err != nil {
+		if matches, err = common.FilterDocument(doc, p.Filter, nil); err != nil {
 			q.Iter.Close()
 			return 0, lazyerrors.Error(err)
 		}
@@ -64,7 +64,7 @@ func (h *Handler) MsgDistinct(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg
 	defer closer.Close()
 
 	var qp backends.QueryParams
-	if !h.DisablePushdown {
+	if !h.DisablePushdown && params.Collation == nil {
 		qp.Filter = params.Filter
 	}
 
@@ -76,9 +76,9 @@ func (h *Handler) MsgDistinct(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg
 
 	closer.Add(queryRes.Iter)
 
-	iter := common.FilterIterator(queryRes.Iter, closer, params.Filter)
+	iter := common.FilterIterator(queryRes.Iter, closer, params.Filter, params.Collation)
 
-	distinct, err := common.FilterDistinctValues(iter, params.Key)
+	distinct, err := common.FilterDistinctValues(iter, params.Key, params.Collation)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
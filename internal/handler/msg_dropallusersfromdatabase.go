@@ -20,6 +20,7 @@ import (
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/users"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
@@ -46,12 +47,14 @@ func (h *Handler) MsgDropAllUsersFromDatabase(ctx context.Context, msg *wire.OpM
 		return nil, lazyerrors.Error(err)
 	}
 
-	users, err := adminDB.Collection("system.users")
+	usersCol, err := adminDB.Collection("system.users")
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	qr, err := users.Query(ctx, nil)
+	defer users.Lock()()
+
+	qr, err := usersCol.Query(ctx, nil)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
@@ -68,7 +71,7 @@ func (h *Handler) MsgDropAllUsersFromDatabase(ctx context.Context, msg *wire.OpM
 			break
 		}
 
-		matches, err := common.FilterDocument(v, filter)
+		matches, err := common.FilterDocument(v, filter, nil)
 		if err != nil {
 			return nil, lazyerrors.Error(err)
 		}
@@ -81,7 +84,7 @@ func (h *Handler) MsgDropAllUsersFromDatabase(ctx context.Context, msg *wire.OpM
 	var deleted int32
 
 	if len(ids) > 0 {
-		res, err := users.DeleteAll(ctx, &backends.DeleteAllParams{
+		res, err := usersCol.DeleteAll(ctx, &backends.DeleteAllParams{
 			IDs: ids,
 		})
 		if err != nil {
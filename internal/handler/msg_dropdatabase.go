@@ -67,6 +67,12 @@ func (h *Handler) MsgDropDatabase(ctx context.Context, msg *wire.OpMsg) (*wire.O
 		return nil, lazyerrors.Error(err)
 	}
 
+	if err = h.shardingDrop(ctx, dbName, ""); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	h.planCache.Clear(dbName, "", "")
+
 	res.Set("ok", float64(1))
 
 	var reply wire.OpMsg
@@ -123,6 +123,9 @@ func (h *Handler) MsgDropIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.Op
 
 // processDropIndexOptions parses and validates index doc and returns the list of indexes to delete
 // and true if a flag to drop all indexes except _id_ was set.
+//
+// All given index names are validated before anything is dropped,
+// so the first invalid or non-existing name fails the whole command without dropping other indexes.
 func processDropIndexOptions(command, ns string, v any, existing []backends.IndexInfo) ([]string, bool, error) { //nolint:lll // for readability
 	switch v := v.(type) {
 	case *types.Document:
@@ -230,6 +233,12 @@ func processDropIndexOptions(command, ns string, v any, existing []backends.Inde
 		}
 
 	case string:
+		if len(existing) == 0 {
+			return nil, false, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrNamespaceNotFound, fmt.Sprintf("ns not found %s", ns), command,
+			)
+		}
+
 		if v == "*" {
 			toDrop := make([]string, 0, len(existing))
 
@@ -245,12 +254,6 @@ func processDropIndexOptions(command, ns string, v any, existing []backends.Inde
 			return toDrop, true, nil
 		}
 
-		if len(existing) == 0 {
-			return nil, false, handlererrors.NewCommandErrorMsgWithArgument(
-				handlererrors.ErrNamespaceNotFound, fmt.Sprintf("ns not found %s", ns), command,
-			)
-		}
-
 		if v == backends.DefaultIndexName {
 			return nil, false, handlererrors.NewCommandErrorMsgWithArgument(
 				handlererrors.ErrInvalidOptions, "cannot drop _id index", command,
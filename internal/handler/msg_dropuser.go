@@ -18,7 +18,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handler/common"
 	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
 	"github.com/FerretDB/FerretDB/internal/types"
@@ -46,24 +45,12 @@ func (h *Handler) MsgDropUser(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg
 		return nil, err
 	}
 
-	adminDB, err := h.b.Database("admin")
+	deleted, err := h.DropUser(ctx, dbName, username)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	users, err := adminDB.Collection("system.users")
-	if err != nil {
-		return nil, lazyerrors.Error(err)
-	}
-
-	res, err := users.DeleteAll(ctx, &backends.DeleteAllParams{
-		IDs: []any{dbName + "." + username},
-	})
-	if err != nil {
-		return nil, lazyerrors.Error(err)
-	}
-
-	if res.Deleted == 0 {
+	if !deleted {
 		return nil, handlererrors.NewCommandErrorMsg(
 			handlererrors.ErrUserNotFound,
 			fmt.Sprintf("User '%s@%s' not found", username, dbName),
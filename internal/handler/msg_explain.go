@@ -20,13 +20,17 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/FerretDB/FerretDB/build/version"
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handler/common"
 	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handler/common/aggregations/stages"
 	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/planner"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
@@ -51,13 +55,17 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 	serverInfo := must.NotFail(types.NewDocument(
 		"host", hostname,
-		"version", version.Get().MongoDBVersion,
+		"version", h.serverVersion.version,
 		"gitVersion", version.Get().Commit,
 
 		// our extensions
 		"ferretdbVersion", version.Get().Version,
 	))
 
+	if !h.extensions() {
+		serverInfo.Remove("ferretdbVersion")
+	}
+
 	cmd := params.Command
 	cmd.Set("$db", params.DB)
 
@@ -89,6 +97,10 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 	if !h.DisablePushdown {
 		qp.Filter = params.Filter
+
+		if params.Aggregate {
+			qp.Sample = aggregations.GetPushdownSample(params.StagesDocs)
+		}
 	}
 
 	if !h.EnableNestedPushdown && params.Filter != nil {
@@ -132,25 +144,37 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	switch {
 	case h.DisablePushdown:
 		// Pushdown disabled
+	case qp.Sample != 0:
+		// sampled documents are returned in random order
 	case params.Sort.Len() == 0 && cInfo.Capped():
 		// Pushdown default recordID sorting for capped collections
 		qp.Sort = must.NotFail(types.NewDocument("$natural", int64(1)))
 	case params.Sort.Len() == 1:
-		if params.Sort.Keys()[0] != "$natural" {
+		// non-capped collections don't have record IDs;
+		// they are scanned in the natural order that is reversed in memory if needed
+		if params.Sort.Keys()[0] != "$natural" || !cInfo.Capped() {
 			break
 		}
 
-		if !cInfo.Capped() {
-			return nil, handlererrors.NewCommandErrorMsgWithArgument(
-				handlererrors.ErrNotImplemented,
-				"$natural sort for non-capped collection is not supported.",
-				"explain",
-			)
-		}
-
 		qp.Sort = params.Sort
 	}
 
+	plan, err := h.queryPlan(ctx, coll, params.DB, params.Collection, &planner.Params{
+		Filter: params.Filter,
+		Sort:   params.Sort,
+		Hint:   params.Hint,
+	}, document.Command())
+	if err != nil {
+		return nil, err
+	}
+
+	// honor {$natural: -1} hint; only capped collections could be scanned backward,
+	// other collections are reversed in memory
+	if plan.Hinted && plan.Index == nil && plan.Backward && cInfo.Capped() &&
+		params.Sort.Len() == 0 && qp.Sample == 0 && !h.DisablePushdown {
+		qp.Sort = must.NotFail(types.NewDocument("$natural", int64(-1)))
+	}
+
 	// Limit pushdown is not applied if:
 	//  - pushdown is disabled;
 	//  - `filter` is set, it must fetch all documents to filter them in memory;
@@ -165,23 +189,267 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
+	winning := winningPlan(params, qp, res, plan)
+
+	// add fields used by tools like Compass to the backend-specific plan
+	queryPlanner := must.NotFail(types.NewDocument(
+		"namespace", params.DB+"."+params.Collection,
+		"planCacheKey", planner.PlanCacheKey(params.DB, params.Collection, params.Filter, params.Sort),
+		"winningPlan", winning,
+		"rejectedPlans", types.MakeArray(0),
+	))
+
+	for _, k := range res.QueryPlanner.Keys() {
+		queryPlanner.Set(k, must.NotFail(res.QueryPlanner.Get(k)))
+	}
+
+	explain := must.NotFail(types.NewDocument(
+		"queryPlanner", queryPlanner,
+		"explainVersion", "1",
+		"command", cmd,
+		"serverInfo", serverInfo,
+
+		// our extensions
+		// TODO https://github.com/FerretDB/FerretDB/issues/3235
+		"filterPushdown", res.FilterPushdown,
+		"sortPushdown", res.SortPushdown,
+		"limitPushdown", res.LimitPushdown,
+		"samplePushdown", res.SamplePushdown,
+
+		"ok", float64(1),
+	))
+
+	if !h.extensions() {
+		explain.Remove("filterPushdown")
+		explain.Remove("sortPushdown")
+		explain.Remove("limitPushdown")
+		explain.Remove("samplePushdown")
+	}
+
+	// only find and aggregate queries are executed for now
+	if params.Verbosity == "executionStats" || params.Verbosity == "allPlansExecution" {
+		var stats *types.Document
+
+		switch cmd.Command() {
+		case "find":
+			if stats, err = h.explainExecutionStats(ctx, coll, params, qp, winning); err != nil {
+				return nil, err
+			}
+
+			explain.Set("executionStats", stats)
+
+		case "aggregate":
+			var pipeline *types.Array
+			if stats, pipeline, err = h.explainAggregateExecutionStats(ctx, db, coll, params, qp); err != nil {
+				return nil, err
+			}
+
+			if stats != nil {
+				explain.Set("stages", pipeline)
+				explain.Set("executionStats", stats)
+			}
+		}
+	}
+
 	var reply wire.OpMsg
-	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
-		must.NotFail(types.NewDocument(
-			"queryPlanner", res.QueryPlanner,
-			"explainVersion", "1",
-			"command", cmd,
-			"serverInfo", serverInfo,
-
-			// our extensions
-			// TODO https://github.com/FerretDB/FerretDB/issues/3235
-			"filterPushdown", res.FilterPushdown,
-			"sortPushdown", res.SortPushdown,
-			"limitPushdown", res.LimitPushdown,
-
-			"ok", float64(1),
-		)),
-	)))
+	must.NoError(reply.SetSections(wire.MakeOpMsgSection(explain)))
 
 	return &reply, nil
 }
+
+// winningPlan returns a plan tree with MongoDB stage names (like COLLSCAN or SORT)
+// that describes how the query is executed.
+//
+//nolint:lll // for readability
+func winningPlan(params *common.ExplainParams, qp *backends.ExplainParams, res *backends.ExplainResult, p *planner.Plan) *types.Document {
+	filter := params.Filter
+	if filter == nil {
+		filter = must.NotFail(types.NewDocument())
+	}
+
+	var plan *types.Document
+
+	id, _ := filter.Get("_id")
+	_, isDoc := id.(*types.Document)
+
+	if res.FilterPushdown && filter.Len() == 1 && filter.Has("_id") && !isDoc && !p.Hinted {
+		plan = must.NotFail(types.NewDocument("stage", "IDHACK"))
+	} else {
+		plan = planStage(p, filter)
+
+		if p.Index == nil {
+			if v, _ := params.Sort.Get("$natural"); v == int64(-1) {
+				plan.Set("direction", "backward")
+			}
+		}
+	}
+
+	if params.Sort.Len() > 0 && !params.Sort.Has("$natural") {
+		plan = must.NotFail(types.NewDocument(
+			"stage", "SORT",
+			"sortPattern", params.Sort,
+			"inputStage", plan,
+		))
+	}
+
+	if params.Skip > 0 {
+		plan = must.NotFail(types.NewDocument(
+			"stage", "SKIP",
+			"skipAmount", params.Skip,
+			"inputStage", plan,
+		))
+	}
+
+	if params.Limit > 0 {
+		plan = must.NotFail(types.NewDocument(
+			"stage", "LIMIT",
+			"limitAmount", params.Limit,
+			"inputStage", plan,
+		))
+	}
+
+	return plan
+}
+
+// explainExecutionStats executes the find query the same way as the find command does
+// and returns execution statistics for the given plan.
+//
+// The SORT stage reports whether temporary files were used.
+//
+//nolint:lll // for readability
+func (h *Handler) explainExecutionStats(ctx context.Context, coll backends.Collection, params *common.ExplainParams, qp *backends.ExplainParams, plan *types.Document) (*types.Document, error) {
+	start := time.Now()
+
+	queryRes, err := coll.Query(ctx, &backends.QueryParams{
+		Filter: qp.Filter,
+		Sort:   qp.Sort,
+		Limit:  qp.Limit,
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	closer := iterator.NewMultiCloser(queryRes.Iter)
+	defer closer.Close()
+
+	iter := common.FilterIterator(queryRes.Iter, closer, params.Filter, nil)
+
+	sortParams := &common.SortParams{
+		Sort:         params.Sort,
+		MemoryLimit:  h.SortMemoryLimitBytes,
+		AllowDiskUse: params.AllowDiskUse,
+	}
+
+	if iter, err = common.SortIterator(iter, closer, sortParams); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	iter = common.SkipIterator(iter, closer, params.Skip)
+	iter = common.LimitIterator(iter, closer, params.Limit)
+
+	n, err := iterator.ConsumeCount(iter)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	stages := plan.DeepCopy()
+
+	for stage := stages; stage != nil; {
+		if v, _ := stage.Get("stage"); v == "SORT" {
+			stage.Set("memLimit", int64(h.SortMemoryLimitBytes))
+			stage.Set("usedDisk", sortParams.Spills > 0)
+			stage.Set("spills", int64(sortParams.Spills))
+		}
+
+		v, _ := stage.Get("inputStage")
+		stage, _ = v.(*types.Document)
+	}
+
+	return must.NotFail(types.NewDocument(
+		"executionSuccess", true,
+		"nReturned", int32(n),
+		"executionTimeMillis", int32(time.Since(start).Milliseconds()),
+		"executionStages", stages,
+	)), nil
+}
+
+// explainAggregateExecutionStats executes the aggregation pipeline the same way as the aggregate command does
+// and returns execution statistics and stages with statistics reported by them, see [aggregations.ExplainStage].
+//
+// It returns nils for pipelines with stages that do not read documents, like $collStats.
+//
+//nolint:lll // for readability
+func (h *Handler) explainAggregateExecutionStats(ctx context.Context, db backends.Database, coll backends.Collection, params *common.ExplainParams, qp *backends.ExplainParams) (*types.Document, *types.Array, error) {
+	start := time.Now()
+
+	pipeline := make([]aggregations.Stage, len(params.StagesDocs))
+
+	for i, v := range params.StagesDocs {
+		d := v.(*types.Document)
+
+		switch d.Command() {
+		case "$collStats", "$indexStats", "$planCacheStats", "$queryStats":
+			return nil, nil, nil
+		}
+
+		s, err := stages.NewStage(d, &stages.NewStageParams{
+			AllowDiskUse:    params.AllowDiskUse,
+			SortMemoryLimit: h.SortMemoryLimitBytes,
+			Lookup:          h.lookupFunc(db),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		pipeline[i] = s
+	}
+
+	queryRes, err := coll.Query(ctx, &backends.QueryParams{
+		Filter: qp.Filter,
+		Sort:   qp.Sort,
+		Sample: qp.Sample,
+	})
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	closer := iterator.NewMultiCloser(queryRes.Iter)
+	defer closer.Close()
+
+	iter := queryRes.Iter
+
+	for _, s := range pipeline {
+		if iter, err = processStage(ctx, s, iter, closer); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	n, err := iterator.ConsumeCount(iter)
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	res := types.MakeArray(len(pipeline))
+
+	for i, s := range pipeline {
+		stage := params.StagesDocs[i].(*types.Document).DeepCopy()
+
+		if e, ok := s.(aggregations.ExplainStage); ok {
+			stats := e.Explain()
+
+			for _, k := range stats.Keys() {
+				stage.Set(k, must.NotFail(stats.Get(k)))
+			}
+		}
+
+		res.Append(stage)
+	}
+
+	stats := must.NotFail(types.NewDocument(
+		"executionSuccess", true,
+		"nReturned", int32(n),
+		"executionTimeMillis", int32(time.Since(start).Milliseconds()),
+	))
+
+	return stats, res, nil
+}
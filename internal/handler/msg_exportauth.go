@@ -0,0 +1,82 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/users"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// authStateVersion is the version of the authorization state document format
+// used by `exportAuth` and `importAuth` commands.
+const authStateVersion = int32(1)
+
+// MsgExportAuth implements `exportAuth` command.
+//
+// It returns the authorization state document with all users of the current database
+// (or of all databases with `forAllDBs: true`) including their credentials.
+// Roles and privileges are not implemented, so stored roles arrays are exported as is.
+// That document could be passed to `importAuth` command as is.
+func (h *Handler) MsgExportAuth(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(document, h.L, "comment")
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	allDBs, err := common.GetOptionalParam(document, "forAllDBs", false)
+	if err != nil {
+		return nil, err
+	}
+
+	if allDBs {
+		dbName = ""
+	}
+
+	docs, err := users.List(ctx, h.b, dbName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	usersArr := types.MakeArray(len(docs))
+	for _, doc := range docs {
+		usersArr.Append(doc)
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
+		must.NotFail(types.NewDocument(
+			"state", must.NotFail(types.NewDocument(
+				"version", authStateVersion,
+				"users", usersArr,
+			)),
+			"ok", float64(1),
+		)),
+	)))
+
+	return &reply, nil
+}
@@ -21,13 +21,14 @@ import (
 	"strings"
 	"time"
 
-	"go.uber.org/zap"
-
 	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/bson"
 	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
 	"github.com/FerretDB/FerretDB/internal/handler/common"
 	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/planner"
+	"github.com/FerretDB/FerretDB/internal/handler/queryshape"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
@@ -98,16 +99,48 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		return nil, err
 	}
 
+	plan, err := h.queryPlan(ctx, coll, params.DB, params.Collection, &planner.Params{
+		Filter: params.Filter,
+		Sort:   params.Sort,
+		Hint:   params.Hint,
+	}, "find")
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.Index != nil {
+		params.IndexKey = indexKeyDocument(plan.Index.Key)
+	}
+
+	// honor {$natural: -1} hint like {$natural: -1} sort;
+	// only capped collections could be scanned backward, other collections are reversed in memory
+	if plan.Hinted && plan.Index == nil && plan.Backward && !params.Tailable && params.Sort.Len() == 0 {
+		params.Sort = must.NotFail(types.NewDocument("$natural", int64(-1)))
+
+		switch {
+		case capped && !h.DisablePushdown:
+			qp.Sort = params.Sort
+		case !capped:
+			// the first documents in the natural order are not the last ones
+			qp.Limit = 0
+		}
+	}
+
+	// the cursor should outlive the client connection,
+	// so the driver could continue iterating it using another connection
+	ctx = context.WithoutCancel(ctx)
+
 	cancel := func() {}
 
-	if params.MaxTimeMS != 0 {
+	maxTimeMS := h.maxTimeMS(params.MaxTimeMS)
+	if maxTimeMS != 0 {
 		findDone := make(chan struct{})
 		defer close(findDone)
 
 		ctx, cancel = context.WithCancel(ctx)
 
 		go func() {
-			t := time.NewTimer(time.Duration(params.MaxTimeMS) * time.Millisecond)
+			t := time.NewTimer(time.Duration(maxTimeMS) * time.Millisecond)
 			defer t.Stop()
 
 			select {
@@ -120,17 +153,27 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 
 	queryRes, err := coll.Query(ctx, qp)
 	if err != nil {
-		return nil, handleMaxTimeMSError(err, params.MaxTimeMS, "find")
+		return nil, handleMaxTimeMSError(err, maxTimeMS, "find")
 	}
 
 	// closer accumulates all things that should be closed / canceled.
 	closer := iterator.NewMultiCloser(iterator.CloserFunc(cancel))
 
-	iter, err := h.makeFindIter(queryRes.Iter, closer, params)
+	tracker := h.queryStats.Track(&queryshape.Query{
+		Command:    "find",
+		DB:         params.DB,
+		Collection: params.Collection,
+		Filter:     queryshape.Shape(params.Filter),
+		Sort:       queryshape.Shape(params.Sort),
+	})
+
+	iter, err := h.makeFindIter(tracker.Examined(queryRes.Iter), closer, params)
 	if err != nil {
-		return nil, handleMaxTimeMSError(err, params.MaxTimeMS, "find")
+		return nil, handleMaxTimeMSError(err, maxTimeMS, "find")
 	}
 
+	iter = tracker.Returned(iter)
+
 	t := cursor.Normal
 
 	if params.Tailable {
@@ -147,6 +190,7 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 			qp:         qp,
 			findParams: params,
 		},
+		LSID:         params.LSID,
 		DB:           params.DB,
 		Collection:   params.Collection,
 		Username:     username,
@@ -156,18 +200,12 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 
 	cursorID := c.ID
 
-	docs, err := iterator.ConsumeValuesN(c, int(params.BatchSize))
+	firstBatch, done, err := h.makeBatch(ctx, c, params.BatchSize)
 	if err != nil {
-		return nil, handleMaxTimeMSError(err, params.MaxTimeMS, "find")
+		return nil, handleMaxTimeMSError(err, maxTimeMS, "find")
 	}
 
-	h.L.Debug(
-		"Got first batch", zap.Int64("cursor_id", cursorID), zap.Stringer("type", c.Type),
-		zap.Int("count", len(docs)), zap.Int64("batch_size", params.BatchSize),
-		zap.Bool("single_batch", params.SingleBatch),
-	)
-
-	if params.SingleBatch || len(docs) < int(params.BatchSize) {
+	if params.SingleBatch || done {
 		c.Close()
 
 		// It is not entirely clear if we should do that; more tests are needed.
@@ -179,24 +217,20 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		cursorID = 0
 	}
 
-	firstBatch := types.MakeArray(len(docs))
-	for _, doc := range docs {
-		firstBatch.Append(doc)
-	}
-
-	var reply wire.OpMsg
-	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
-		must.NotFail(types.NewDocument(
-			"cursor", must.NotFail(types.NewDocument(
-				"firstBatch", firstBatch,
-				"id", cursorID,
-				"ns", params.DB+"."+params.Collection,
-			)),
-			"ok", float64(1),
+	// firstBatch contains already encoded documents, so they are not encoded again
+	reply, err := wire.NewOpMsg(must.NotFail(bson.NewDocument(
+		"cursor", must.NotFail(bson.NewDocument(
+			"firstBatch", firstBatch,
+			"id", cursorID,
+			"ns", params.DB+"."+params.Collection,
 		)),
+		"ok", float64(1),
 	)))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
 
-	return &reply, nil
+	return reply, nil
 }
 
 type findCursorData struct {
@@ -234,6 +268,11 @@ func (h *Handler) makeFindQueryParams(params *common.FindParams, cInfo *backends
 		}
 	}
 
+	// backends compare strings as bytes, so the filter is applied with collation in memory only
+	if params.Collation != nil {
+		qp.Filter = nil
+	}
+
 	if params.Sort, err = common.ValidateSortDocument(params.Sort); err != nil {
 		var pathErr *types.PathError
 		if errors.As(err, &pathErr) && pathErr.Code() == types.ErrPathElementEmpty {
@@ -254,18 +293,12 @@ func (h *Handler) makeFindQueryParams(params *common.FindParams, cInfo *backends
 		// Pushdown default recordID sorting for capped collections
 		qp.Sort = must.NotFail(types.NewDocument("$natural", int64(1)))
 	case params.Sort.Len() == 1:
-		if params.Sort.Keys()[0] != "$natural" {
+		// non-capped collections don't have record IDs;
+		// they are scanned in the natural order that is reversed in memory if needed
+		if params.Sort.Keys()[0] != "$natural" || !cInfo.Capped() {
 			break
 		}
 
-		if !cInfo.Capped() {
-			return nil, handlererrors.NewCommandErrorMsgWithArgument(
-				handlererrors.ErrNotImplemented,
-				"$natural sort for non-capped collection is not supported.",
-				"find",
-			)
-		}
-
 		qp.Sort = params.Sort
 	}
 
@@ -293,9 +326,21 @@ func (h *Handler) makeFindQueryParams(params *common.FindParams, cInfo *backends
 func (h *Handler) makeFindIter(iter types.DocumentsIterator, closer *iterator.MultiCloser, params *common.FindParams) (types.DocumentsIterator, error) {
 	closer.Add(iter)
 
-	iter = common.FilterIterator(iter, closer, params.Filter)
+	iter = common.FilterIterator(iter, closer, params.Filter, params.Collation)
+
+	var err error
+
+	if start, ok := h.gridFSChunksStart(params); ok {
+		iter = gridFSChunksIterator(iter, closer, start, h.SortMemoryLimitBytes)
+	} else {
+		iter, err = common.SortIterator(iter, closer, &common.SortParams{
+			Sort:         params.Sort,
+			Collation:    params.Collation,
+			MemoryLimit:  h.SortMemoryLimitBytes,
+			AllowDiskUse: params.AllowDiskUse,
+		})
+	}
 
-	iter, err := common.SortIterator(iter, closer, params.Sort)
 	if err != nil {
 		closer.Close()
 
@@ -315,7 +360,7 @@ func (h *Handler) makeFindIter(iter types.DocumentsIterator, closer *iterator.Mu
 
 	iter = common.LimitIterator(iter, closer, params.Limit)
 
-	if iter, err = common.ProjectionIterator(iter, closer, params.Projection, params.Filter); err != nil {
+	if iter, err = common.ProjectionIterator(iter, closer, params.Projection, params.Filter, params.IndexKey); err != nil {
 		closer.Close()
 		return nil, lazyerrors.Error(err)
 	}
@@ -323,13 +368,43 @@ func (h *Handler) makeFindIter(iter types.DocumentsIterator, closer *iterator.Mu
 	return iterator.WithClose(iter, closer.Close), nil
 }
 
-// handleMaxTimeMSError returns the MaxTimeMSExpired error if provided error is a result of context cancellation.
+// maxTimeMS returns the time limit of the operation in milliseconds:
+// the given maxTimeMS value, or the default operation timeout if maxTimeMS is not set.
+func (h *Handler) maxTimeMS(maxTimeMS int64) int64 {
+	if maxTimeMS == 0 {
+		return h.OperationTimeout.Milliseconds()
+	}
+
+	return maxTimeMS
+}
+
+// handleOperationTimeoutError returns the MaxTimeMSExpired error if the command failed
+// because the time limit (maxTimeMS or the default operation timeout) of the given context expired.
+// Other errors are returned as is.
+func handleOperationTimeoutError(ctx context.Context, err error, cmd string) error {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+
+	if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	return handlererrors.NewCommandErrorMsgWithArgument(
+		handlererrors.ErrMaxTimeMSExpired,
+		"Executor error during "+cmd+" command :: caused by :: operation exceeded time limit",
+		cmd,
+	)
+}
+
+// handleMaxTimeMSError returns the MaxTimeMSExpired error if provided error is a result of context cancellation
+// or deadline.
 // The MaxTimeMSExpired error won't be returned if maxTimeMS wasn't set.
 func handleMaxTimeMSError(err error, maxTimeMS int64, cmd string) error {
 	switch {
 	case err == nil:
 		return nil
-	case maxTimeMS != 0 && errors.Is(err, context.Canceled):
+	case maxTimeMS != 0 && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)):
 		return handlererrors.NewCommandErrorMsgWithArgument(
 			handlererrors.ErrMaxTimeMSExpired,
 			"Executor error during "+cmd+" command :: caused by :: operation exceeded time limit",
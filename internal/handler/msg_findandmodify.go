@@ -58,8 +58,14 @@ func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.
 
 	var resDoc *types.Document
 
+	params.MaxTimeMS = h.maxTimeMS(params.MaxTimeMS)
+
 	res, err := h.findAndModifyDocument(ctx, params)
 	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, handleMaxTimeMSError(err, params.MaxTimeMS, "findAndModify")
+		}
+
 		return nil, handleUpdateError(params.DB, params.Collection, "findAndModify", err)
 	}
 
@@ -128,7 +134,7 @@ func (h *Handler) findAndModifyDocument(ctx context.Context, params *common.Find
 	defer closer.Close()
 
 	var qp backends.QueryParams
-	if !h.DisablePushdown {
+	if !h.DisablePushdown && params.Collation == nil {
 		qp.Filter = params.Query
 	}
 
@@ -139,9 +145,14 @@ func (h *Handler) findAndModifyDocument(ctx context.Context, params *common.Find
 
 	closer.Add(queryRes.Iter)
 
-	iter := common.FilterIterator(queryRes.Iter, closer, params.Query)
+	iter := common.FilterIterator(queryRes.Iter, closer, params.Query, params.Collation)
 
-	iter, err = common.SortIterator(iter, closer, params.Sort)
+	iter, err = common.SortIterator(iter, closer, &common.SortParams{
+		Sort:         params.Sort,
+		Collation:    params.Collation,
+		MemoryLimit:  h.SortMemoryLimitBytes,
+		AllowDiskUse: true,
+	})
 	if err != nil {
 		var pathErr *types.PathError
 		if errors.As(err, &pathErr) && pathErr.Code() == types.ErrPathElementEmpty {
@@ -188,6 +199,7 @@ func (h *Handler) findAndModifyDocument(ctx context.Context, params *common.Find
 		Update:             params.Update,
 		Upsert:             params.Upsert,
 		HasUpdateOperators: params.HasUpdateOperators,
+		Collation:          params.Collation,
 	}
 
 	// TODO https://github.com/FerretDB/FerretDB/issues/2168
@@ -226,11 +238,8 @@ func handleUpdateError(db, coll, command string, err error) error {
 	var ve *types.ValidationError
 
 	if errors.As(err, &be) && be.Code() == backends.ErrorCodeInsertDuplicateID {
-		err = common.NewUpdateError(
-			handlererrors.ErrDuplicateKeyInsert,
-			fmt.Sprintf(`E11000 duplicate key error collection: %s.%s`, db, coll),
-			command,
-		)
+		cl := handlererrors.ClassifyError(be, nil)
+		err = common.NewUpdateError(handlererrors.ErrDuplicateKeyInsert, cl.DuplicateKeyMessage(db, coll), command)
 	} else if errors.As(err, &ve) {
 		err = validationErrToUpdateErr(command, ve)
 	}
@@ -17,6 +17,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -28,6 +29,7 @@ import (
 	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/debugbuild"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/logging"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -81,7 +83,7 @@ func (h *Handler) MsgGetLog(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		}
 		resDoc = must.NotFail(types.NewDocument(
 			"log", log,
-			"totalLinesWritten", int64(log.Len()),
+			"totalLinesWritten", logging.RecentEntries.TotalLinesWritten(),
 			"ok", float64(1),
 		))
 
@@ -146,6 +148,24 @@ func (h *Handler) MsgGetLog(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 			log.Append(string(b))
 		}
+
+		// structured warnings like disabled access control or too old backend version
+		iter := logging.RecentEntries.GetStartupWarnings().Iterator()
+		defer iter.Close()
+
+		for {
+			_, line, err := iter.Next()
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			log.Append(line)
+		}
+
 		resDoc = must.NotFail(types.NewDocument(
 			"log", &log,
 			"totalLinesWritten", int64(log.Len()),
@@ -153,9 +173,9 @@ func (h *Handler) MsgGetLog(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		))
 
 	default:
-		return nil, handlererrors.NewCommandError(
+		return nil, handlererrors.NewCommandErrorMsg(
 			handlererrors.ErrOperationFailed,
-			fmt.Errorf("no RecentEntries named: %s", getLog),
+			fmt.Sprintf("No log named '%s'", getLog),
 		)
 	}
 
@@ -21,9 +21,11 @@ import (
 	"math"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/bson"
 	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
 	"github.com/FerretDB/FerretDB/internal/handler/common"
@@ -161,6 +163,15 @@ func (h *Handler) MsgGetMore(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		)
 	}
 
+	lsid, err := common.GetOptionalParam[*types.Document](document, "lsid", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkCursorSession(c, lsid, username != "", document.Command()); err != nil {
+		return nil, err
+	}
+
 	if maxTimeMSPresent && c.Type != cursor.TailableAwait {
 		return nil, handlererrors.NewCommandErrorMsgWithArgument(
 			handlererrors.ErrBadValue,
@@ -171,11 +182,9 @@ func (h *Handler) MsgGetMore(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 	v, _ = document.Get("batchSize")
 	if v == nil || types.Compare(v, int32(0)) == types.Equal {
-		// Use 16MB batchSize limit.
-		// Unlimited default batchSize is used for missing batchSize and zero values,
-		// set 250 assuming it is small enough not to crash FerretDB.
-		// TODO https://github.com/FerretDB/FerretDB/issues/2824
-		v = int32(250)
+		// Unlimited default batchSize is used for missing batchSize and zero values;
+		// the batch is still limited by the total size of documents, see makeBatch.
+		v = int32(math.MaxInt32)
 	}
 
 	batchSize, err := handlerparams.GetValidatedNumberParamWithMinValue(document.Command(), "batchSize", v, 0)
@@ -197,14 +206,23 @@ func (h *Handler) MsgGetMore(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		)
 	}
 
-	nextBatch, err := h.makeNextBatch(c, batchSize)
+	// the cursor should outlive the client connection, but not the default operation timeout;
+	// non-awaitData cursors do not accept maxTimeMS, so it is used for all of them
+	batchCtx, cancel := context.WithoutCancel(ctx), context.CancelFunc(func() {})
+	if h.OperationTimeout > 0 {
+		batchCtx, cancel = context.WithTimeout(batchCtx, h.OperationTimeout)
+	}
+
+	defer cancel()
+
+	nextBatch, done, err := h.makeBatch(batchCtx, c, batchSize)
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, handleMaxTimeMSError(err, h.OperationTimeout.Milliseconds(), document.Command())
 	}
 
 	switch c.Type {
 	case cursor.Normal:
-		if nextBatch.Len() < int(batchSize) {
+		if done {
 			// The cursor is already closed and removed;
 			// let the client know that there are no more results.
 			cursorID = 0
@@ -234,9 +252,9 @@ func (h *Handler) MsgGetMore(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			}
 
 			if nextBatch.Len() == 0 {
-				nextBatch, err = h.makeNextBatch(c, batchSize)
+				nextBatch, _, err = h.makeBatch(batchCtx, c, batchSize)
 				if err != nil {
-					return nil, lazyerrors.Error(err)
+					return nil, handleMaxTimeMSError(err, h.OperationTimeout.Milliseconds(), document.Command())
 				}
 			}
 		}
@@ -257,39 +275,159 @@ func (h *Handler) MsgGetMore(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		panic(fmt.Sprintf("unknown cursor type %s", c.Type))
 	}
 
-	var reply wire.OpMsg
-	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
-		must.NotFail(types.NewDocument(
-			"cursor", must.NotFail(types.NewDocument(
-				"nextBatch", nextBatch,
-				"id", cursorID,
-				"ns", db+"."+collection,
-			)),
-			"ok", float64(1),
+	// nextBatch contains already encoded documents, so they are not encoded again
+	reply, err := wire.NewOpMsg(must.NotFail(bson.NewDocument(
+		"cursor", must.NotFail(bson.NewDocument(
+			"nextBatch", nextBatch,
+			"id", cursorID,
+			"ns", db+"."+collection,
 		)),
+		"ok", float64(1),
 	)))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
 
-	return &reply, nil
+	return reply, nil
 }
 
-// makeNextBatch returns the next batch of documents from the cursor.
-func (h *Handler) makeNextBatch(c *cursor.Cursor, batchSize int64) (*types.Array, error) {
-	docs, err := iterator.ConsumeValuesN(c, int(batchSize))
-	if err != nil {
-		return nil, lazyerrors.Error(err)
+// checkCursorSession returns an error if the given logical session ID of `getMore` or `killCursors`
+// does not match the session ID of the command that created the cursor.
+//
+// Like MongoDB, it returns Unauthorized error for authenticated connections,
+// and more specific errors otherwise.
+func checkCursorSession(c *cursor.Cursor, lsid *types.Document, authenticated bool, command string) error {
+	switch {
+	case c.LSID == nil && lsid == nil:
+		return nil
+
+	case authenticated && c.LSID != nil && (lsid == nil || types.Compare(c.LSID, lsid) != types.Equal):
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrUnauthorized,
+			fmt.Sprintf(
+				"Cursor session id (%s) is not the same as the operation context's session id (%s)",
+				formatLSID(c.LSID), formatLSID(lsid),
+			),
+			command,
+		)
+
+	case c.LSID == nil:
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrCursorNotCreatedInSession,
+			fmt.Sprintf(
+				"Cannot run getMore on cursor %d, which was not created in a session, in session %s",
+				c.ID, formatLSID(lsid),
+			),
+			command,
+		)
+
+	case lsid == nil:
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrCursorSessionRequired,
+			fmt.Sprintf(
+				"Cannot run getMore on cursor %d, which was created in session %s, without an lsid",
+				c.ID, formatLSID(c.LSID),
+			),
+			command,
+		)
+
+	case types.Compare(c.LSID, lsid) != types.Equal:
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrCursorSessionMismatch,
+			fmt.Sprintf(
+				"Cannot run getMore on cursor %d, which was created in session %s, in session %s",
+				c.ID, formatLSID(c.LSID), formatLSID(lsid),
+			),
+			command,
+		)
+
+	default:
+		return nil
 	}
+}
 
-	h.L.Debug(
-		"Got next batch", zap.Int64("cursor_id", c.ID), zap.Stringer("type", c.Type),
-		zap.Int("count", len(docs)), zap.Int64("batch_size", batchSize),
-	)
+// formatLSID returns a string representation of the logical session ID for error messages.
+func formatLSID(lsid *types.Document) string {
+	if lsid == nil {
+		return "none"
+	}
+
+	v, _ := lsid.Get("id")
+
+	if id, ok := v.(types.Binary); ok && id.Subtype == types.BinaryUUID {
+		if u, err := uuid.FromBytes(id.B); err == nil {
+			return u.String()
+		}
+	}
+
+	return types.FormatAnyValue(lsid)
+}
+
+// makeBatch returns the next batch of documents from the cursor for `firstBatch` or `nextBatch` fields,
+// and true if the cursor is exhausted (and closed).
+//
+// Documents are encoded one by one as they are read from the cursor,
+// without collecting the whole batch of documents first.
+// Like MongoDB, the batch is limited by batchSize and by the total size of encoded documents
+// (maxBsonObjectSize, 16 MiB by default), so the reply always fits into maxMessageSizeBytes;
+// the document that does not fit is returned to the cursor for the next batch.
+// The first document is always returned even if it is larger than the size limit,
+// so the client always makes progress.
+//
+// If ctx is canceled or expired, the cursor is closed and the context error is returned.
+func (h *Handler) makeBatch(ctx context.Context, c *cursor.Cursor, batchSize int64) (*bson.Array, bool, error) {
+	nextBatch := bson.MakeArray(0)
+
+	var size int
+	var done bool
+
+	for int64(nextBatch.Len()) < batchSize {
+		if err := ctx.Err(); err != nil {
+			c.Close()
+			return nil, false, lazyerrors.Error(err)
+		}
+
+		_, doc, err := c.Next()
+		if err != nil {
+			c.Close()
+
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				done = true
+				break
+			}
+
+			return nil, false, lazyerrors.Error(err)
+		}
 
-	nextBatch := types.MakeArray(len(docs))
-	for _, doc := range docs {
-		nextBatch.Append(doc)
+		bdoc, err := bson.ConvertDocument(doc)
+		if err != nil {
+			c.Close()
+			return nil, false, lazyerrors.Error(err)
+		}
+
+		raw, err := bdoc.Encode()
+		if err != nil {
+			c.Close()
+			return nil, false, lazyerrors.Error(err)
+		}
+
+		if nextBatch.Len() > 0 && size+len(raw) > h.MaxBsonObjectSizeBytes {
+			c.Unread(doc)
+			break
+		}
+
+		size += len(raw)
+
+		must.NoError(nextBatch.Add(raw))
 	}
 
-	return nextBatch, nil
+	h.L.Debug(
+		"Got batch", zap.Int64("cursor_id", c.ID), zap.Stringer("type", c.Type),
+		zap.Int("count", nextBatch.Len()), zap.Int("size", size), zap.Int64("batch_size", batchSize),
+		zap.Bool("done", done),
+	)
+
+	return nextBatch, done, nil
 }
 
 // awaitDataParams contains parameters that can be passed to awaitData function.
@@ -301,8 +439,8 @@ type awaitDataParams struct {
 
 // awaitData stops the goroutine, and waits for a new data for the cursor.
 // If there's a new document, or the maxTimeMS have passed it returns the nextBatch.
-func (h *Handler) awaitData(ctx context.Context, params *awaitDataParams) (resBatch *types.Array, err error) {
-	resBatch = types.MakeArray(0)
+func (h *Handler) awaitData(ctx context.Context, params *awaitDataParams) (resBatch *bson.Array, err error) {
+	resBatch = bson.MakeArray(0)
 
 	closer := iterator.NewMultiCloser()
 	defer closer.Close()
@@ -322,7 +460,7 @@ func (h *Handler) awaitData(ctx context.Context, params *awaitDataParams) (resBa
 
 		// Return empty batch and no error if context timeout exceeded
 		if errors.Is(err, context.DeadlineExceeded) {
-			resBatch = types.MakeArray(0)
+			resBatch = bson.MakeArray(0)
 			err = nil
 
 			return
@@ -354,7 +492,8 @@ func (h *Handler) awaitData(ctx context.Context, params *awaitDataParams) (resBa
 			return
 		}
 
-		resBatch, err = h.makeNextBatch(c, params.batchSize)
+		// waiting is limited by maxTimeMS, not reading of the new batch
+		resBatch, _, err = h.makeBatch(context.WithoutCancel(ctx), c, params.batchSize)
 		if err != nil {
 			return
 		}
@@ -24,7 +24,9 @@ import (
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/logging"
 	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/telemetry"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
@@ -71,6 +73,16 @@ func (h *Handler) MsgGetParameter(ctx context.Context, msg *wire.OpMsg) (*wire.O
 			"settableAtRuntime", false,
 			"settableAtStartup", false,
 		)),
+		"logComponentVerbosity", must.NotFail(types.NewDocument(
+			"value", logComponentVerbosity(),
+			"settableAtRuntime", true,
+			"settableAtStartup", true,
+		)),
+		"logLevel", must.NotFail(types.NewDocument(
+			"value", logging.Verbosity.Level(),
+			"settableAtRuntime", true,
+			"settableAtStartup", true,
+		)),
 		"quiet", must.NotFail(types.NewDocument(
 			"value", false,
 			"settableAtRuntime", true,
@@ -79,6 +91,21 @@ func (h *Handler) MsgGetParameter(ctx context.Context, msg *wire.OpMsg) (*wire.O
 		// parameters are alphabetically ordered
 	))
 
+	// collecting metrics is not free, so the report is made only when requested
+	if allParameters || document.Has("telemetryReport") {
+		var report []byte
+		if report, err = telemetry.Report(h.StateProvider.Get(), h.ConnMetrics); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		// JSON exactly as it would be sent by the telemetry reporter
+		parameters.Set("telemetryReport", must.NotFail(types.NewDocument(
+			"value", string(report),
+			"settableAtRuntime", false,
+			"settableAtStartup", false,
+		)))
+	}
+
 	resDoc, err := selectParameters(document, parameters, showDetails, allParameters)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -114,7 +114,7 @@ func (h *Handler) hello(ctx context.Context, doc *types.Document, tcpHost, name
 	res.Set("logicalSessionTimeoutMinutes", logicalSessionTimeoutMinutes)
 	res.Set("connectionId", connectionID)
 	res.Set("minWireVersion", common.MinWireVersion)
-	res.Set("maxWireVersion", common.MaxWireVersion)
+	res.Set("maxWireVersion", h.serverVersion.maxWireVersion)
 	res.Set("readOnly", false)
 
 	if resSupportedMechs != nil && resSupportedMechs.Len() != 0 {
@@ -164,7 +164,7 @@ func (h *Handler) getUserSupportedMechs(ctx context.Context, db, username string
 			return nil, lazyerrors.Error(err)
 		}
 
-		matches, err := common.FilterDocument(v, filter)
+		matches, err := common.FilterDocument(v, filter, nil)
 		if err != nil {
 			return nil, lazyerrors.Error(err)
 		}
@@ -0,0 +1,302 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/handler/users"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgImportAuth implements `importAuth` command.
+//
+// It replaces all users of the current database (or of all databases with `forAllDBs: true`)
+// with users from the authorization state document returned by `exportAuth` command.
+// The whole document is validated before any changes are made.
+func (h *Handler) MsgImportAuth(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(document, h.L, "writeConcern", "comment")
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	allDBs, err := common.GetOptionalParam(document, "forAllDBs", false)
+	if err != nil {
+		return nil, err
+	}
+
+	if allDBs {
+		dbName = ""
+	}
+
+	state, err := common.GetRequiredParam[*types.Document](document, "state")
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := importAuthUsers(state, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = users.Replace(ctx, h.b, dbName, docs); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
+		must.NotFail(types.NewDocument(
+			"n", int32(len(docs)),
+			"ok", float64(1),
+		)),
+	)))
+
+	return &reply, nil
+}
+
+// importAuthUsers validates the authorization state document and returns user documents to store.
+//
+// If dbName is not empty, all users should belong to that database.
+func importAuthUsers(state *types.Document, dbName string) ([]*types.Document, error) {
+	version, err := common.GetRequiredParam[int32](state, "version")
+	if err != nil {
+		return nil, err
+	}
+
+	if version != authStateVersion {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrBadValue,
+			fmt.Sprintf("Unsupported authorization state version %d, expected %d", version, authStateVersion),
+			"importAuth",
+		)
+	}
+
+	if err = common.UnimplementedNonDefault(state, "roles", func(v any) bool {
+		r, ok := v.(*types.Array)
+		return ok && r.Len() == 0
+	}); err != nil {
+		return nil, err
+	}
+
+	usersArr, err := common.GetRequiredParam[*types.Array](state, "users")
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]*types.Document, 0, usersArr.Len())
+	seen := make(map[string]struct{}, usersArr.Len())
+
+	iter := usersArr.Iterator()
+	defer iter.Close()
+
+	for {
+		_, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		u, ok := v.(*types.Document)
+		if !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrTypeMismatch,
+				fmt.Sprintf("User document must be an object, found %s", handlerparams.AliasFromType(v)),
+				"importAuth",
+			)
+		}
+
+		doc, err := importAuthUser(u, dbName)
+		if err != nil {
+			return nil, err
+		}
+
+		id := must.NotFail(doc.Get("_id")).(string)
+		if _, ok := seen[id]; ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrBadValue,
+				fmt.Sprintf("User %q is specified more than once", id),
+				"importAuth",
+			)
+		}
+
+		seen[id] = struct{}{}
+
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+// importAuthUser validates a single user document of the authorization state
+// and returns the document to store.
+func importAuthUser(u *types.Document, dbName string) (*types.Document, error) {
+	for _, k := range u.Keys() {
+		switch k {
+		case "_id", "user", "db", "credentials", "roles", "userId", "mechanisms":
+		default:
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrBadValue,
+				fmt.Sprintf("Unknown field %q in user document", k),
+				"importAuth",
+			)
+		}
+	}
+
+	username, err := common.GetRequiredParam[string](u, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := common.GetRequiredParam[string](u, "db")
+	if err != nil {
+		return nil, err
+	}
+
+	if username == "" || db == "" {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrBadValue,
+			"User document needs non-empty 'user' and 'db' fields",
+			"importAuth",
+		)
+	}
+
+	if dbName != "" && db != dbName {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrBadValue,
+			fmt.Sprintf("User \"%s@%s\" does not belong to database %q", username, db, dbName),
+			"importAuth",
+		)
+	}
+
+	id := db + "." + username
+
+	if v, _ := u.Get("_id"); v != nil && v != id {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrBadValue,
+			fmt.Sprintf("User \"%s@%s\" should have _id %q", username, db, id),
+			"importAuth",
+		)
+	}
+
+	if err = common.UnimplementedNonDefault(u, "roles", func(v any) bool {
+		r, ok := v.(*types.Array)
+		return ok && r.Len() == 0
+	}); err != nil {
+		return nil, err
+	}
+
+	credentials, err := common.GetRequiredParam[*types.Document](u, "credentials")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = validateAuthCredentials(credentials, username, db); err != nil {
+		return nil, err
+	}
+
+	userID, _ := u.Get("userId")
+	if userID == nil {
+		userID = types.Binary{Subtype: types.BinaryUUID, B: must.NotFail(uuid.New().MarshalBinary())}
+	}
+
+	if b, ok := userID.(types.Binary); !ok || b.Subtype != types.BinaryUUID {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrBadValue,
+			fmt.Sprintf("User \"%s@%s\" should have UUID userId", username, db),
+			"importAuth",
+		)
+	}
+
+	// the same fields as users.CreateUser stores; mechanisms are derived from credentials
+	return must.NotFail(types.NewDocument(
+		"_id", id,
+		"credentials", credentials,
+		"user", username,
+		"db", db,
+		"roles", types.MakeArray(0),
+		"userId", userID,
+	)), nil
+}
+
+// validateAuthCredentials checks that user credentials contain valid SCRAM hashes.
+func validateAuthCredentials(credentials *types.Document, username, db string) error {
+	if credentials.Len() == 0 {
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrBadValue,
+			fmt.Sprintf("User \"%s@%s\" should have credentials", username, db),
+			"importAuth",
+		)
+	}
+
+	for _, mechanism := range credentials.Keys() {
+		if mechanism != "SCRAM-SHA-1" && mechanism != "SCRAM-SHA-256" {
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrBadValue,
+				fmt.Sprintf("Unknown auth mechanism '%s'", mechanism),
+				"importAuth",
+			)
+		}
+
+		hash, ok := must.NotFail(credentials.Get(mechanism)).(*types.Document)
+		if !ok {
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrBadValue,
+				fmt.Sprintf("User \"%s@%s\" has invalid %s credentials", username, db, mechanism),
+				"importAuth",
+			)
+		}
+
+		count, _ := hash.Get("iterationCount")
+		salt, _ := hash.Get("salt")
+		storedKey, _ := hash.Get("storedKey")
+		serverKey, _ := hash.Get("serverKey")
+
+		_, countOK := count.(int32)
+		_, saltOK := salt.(string)
+		_, storedKeyOK := storedKey.(string)
+		_, serverKeyOK := serverKey.(string)
+
+		if !countOK || !saltOK || !storedKeyOK || !serverKeyOK {
+			return handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrBadValue,
+				fmt.Sprintf("User \"%s@%s\" has invalid %s credentials", username, db, mechanism),
+				"importAuth",
+			)
+		}
+	}
+
+	return nil
+}
@@ -77,12 +77,19 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
+	if err = h.gridFSCreateChunks(ctx, db, params.Collection); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
 	docsIter := params.Docs.Iterator()
 	defer docsIter.Close()
 
 	var inserted int32
 	var writeErrors []*mongo.WriteError
 
+	// classified write errors by document index, used for additional fields like keyValue
+	classified := map[int]*handlererrors.Classification{}
+
 	var done bool
 	for !done {
 		docs := make([]*types.Document, 0, h.BatchSize)
@@ -162,15 +169,17 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 				continue
 			}
 
-			if !backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID) {
+			cl := handlererrors.ClassifyError(err, doc)
+			if cl == nil || cl.Kind != handlererrors.ErrorKindWrite {
 				return nil, lazyerrors.Error(err)
 			}
 
 			writeErrors = append(writeErrors, &mongo.WriteError{
 				Index:   docsIndexes[j],
-				Code:    int(handlererrors.ErrDuplicateKeyInsert),
-				Message: fmt.Sprintf(`E11000 duplicate key error collection: %s.%s`, params.DB, params.Collection),
+				Code:    int(cl.Code),
+				Message: cl.DuplicateKeyMessage(params.DB, params.Collection),
 			})
+			classified[docsIndexes[j]] = cl
 
 			if params.Ordered {
 				break
@@ -189,6 +198,11 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 		array := types.MakeArray(len(writeErrors))
 		for _, we := range writeErrors {
+			if cl := classified[we.Index]; cl != nil {
+				array.Append(cl.WriteErrorDocument(int32(we.Index), we.Message))
+				continue
+			}
+
 			array.Append(WriteErrorDocument(we))
 		}
 
@@ -51,6 +51,11 @@ func (h *Handler) MsgKillCursors(ctx context.Context, msg *wire.OpMsg) (*wire.Op
 
 	username := conninfo.Get(ctx).Username()
 
+	lsid, err := common.GetOptionalParam[*types.Document](document, "lsid", nil)
+	if err != nil {
+		return nil, err
+	}
+
 	cursors, err := common.GetRequiredParam[*types.Array](document, "cursors")
 	if err != nil {
 		return nil, err
@@ -98,6 +103,11 @@ func (h *Handler) MsgKillCursors(ctx context.Context, msg *wire.OpMsg) (*wire.Op
 			continue
 		}
 
+		if checkCursorSession(cursor, lsid, username != "", command) != nil {
+			cursorsNotFound.Append(id)
+			continue
+		}
+
 		h.cursors.CloseAndRemove(cursor)
 		cursorsKilled.Append(id)
 	}
@@ -121,7 +121,7 @@ func (h *Handler) MsgListCollections(ctx context.Context, msg *wire.OpMsg) (*wir
 
 		d.Set("info", info)
 
-		matches, err := common.FilterDocument(d, filter)
+		matches, err := common.FilterDocument(d, filter, nil)
 		if err != nil {
 			return nil, lazyerrors.Error(err)
 		}
@@ -80,7 +80,7 @@ func (h *Handler) MsgListDatabases(ctx context.Context, msg *wire.OpMsg) (*wire.
 			"empty", stats.SizeTotal == 0,
 		))
 
-		matches, err := common.FilterDocument(d, filter)
+		matches, err := common.FilterDocument(d, filter, nil)
 		if err != nil {
 			return nil, lazyerrors.Error(err)
 		}
@@ -17,11 +17,17 @@ package handler
 import (
 	"context"
 	"fmt"
+	"math"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
 	"github.com/FerretDB/FerretDB/internal/handler/common"
 	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
@@ -46,6 +52,34 @@ func (h *Handler) MsgListIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.Op
 		return nil, err
 	}
 
+	lsid, err := common.GetOptionalParam[*types.Document](document, "lsid", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// all indexes are returned in the first batch by default
+	batchSize := int64(math.MaxInt32)
+
+	if v, _ := document.Get("cursor"); v != nil {
+		cursorDoc, ok := v.(*types.Document)
+		if !ok {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					`BSON field 'listIndexes.cursor' is the wrong type '%s', expected type 'object'`,
+					handlerparams.AliasFromType(v),
+				),
+				command,
+			)
+		}
+
+		if v, _ = cursorDoc.Get("batchSize"); v != nil {
+			if batchSize, err = handlerparams.GetValidatedNumberParamWithMinValue(command, "batchSize", v, 0); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	db, err := h.b.Database(dbName)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
@@ -56,7 +90,7 @@ func (h *Handler) MsgListIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.Op
 		return nil, lazyerrors.Error(err)
 	}
 
-	c, err := db.Collection(collection)
+	coll, err := db.Collection(collection)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
 			msg := fmt.Sprintf("Invalid collection name: %s", collection)
@@ -66,7 +100,7 @@ func (h *Handler) MsgListIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.Op
 		return nil, lazyerrors.Error(err)
 	}
 
-	res, err := c.ListIndexes(ctx, nil)
+	res, err := coll.ListIndexes(ctx, nil)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist) {
 			msg := fmt.Sprintf("ns does not exist: %s.%s", dbName, collection)
@@ -76,45 +110,67 @@ func (h *Handler) MsgListIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.Op
 		return nil, lazyerrors.Error(err)
 	}
 
-	firstBatch := types.MakeArray(len(res.Indexes))
-
-	for _, index := range res.Indexes {
-		indexKey := must.NotFail(types.NewDocument())
-
-		for _, key := range index.Key {
-			order := int32(1)
-			if key.Descending {
-				order = -1
-			}
+	docs := make([]*types.Document, len(res.Indexes))
+	for i, index := range res.Indexes {
+		docs[i] = indexSpecDocument(&index)
+	}
 
-			indexKey.Set(key.Field, order)
-		}
+	// the cursor should outlive the client connection,
+	// so the driver could continue iterating it using another connection
+	c := h.cursors.NewCursor(context.WithoutCancel(ctx), iterator.Values(iterator.ForSlice(docs)), &cursor.NewParams{
+		LSID:       lsid,
+		DB:         dbName,
+		Collection: collection,
+		Username:   conninfo.Get(ctx).Username(),
+		Type:       cursor.Normal,
+	})
 
-		indexDoc := must.NotFail(types.NewDocument(
-			"v", int32(2), // for compatibility, the meaning of this field is not documented
-			"key", indexKey,
-			"name", index.Name,
-		))
+	cursorID := c.ID
 
-		// only non-default unique indexes should have unique field in the response
-		if index.Unique && index.Name != backends.DefaultIndexName {
-			indexDoc.Set("unique", index.Unique)
-		}
+	firstBatch, done, err := h.makeBatch(ctx, c, batchSize)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
 
-		firstBatch.Append(indexDoc)
+	if done {
+		cursorID = 0
 	}
 
-	var reply wire.OpMsg
-	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
-		must.NotFail(types.NewDocument(
-			"cursor", must.NotFail(types.NewDocument(
-				"id", int64(0),
-				"ns", fmt.Sprintf("%s.%s", dbName, collection),
-				"firstBatch", firstBatch,
-			)),
-			"ok", float64(1),
+	// firstBatch contains already encoded documents, so they are not encoded again
+	reply, err := wire.NewOpMsg(must.NotFail(bson.NewDocument(
+		"cursor", must.NotFail(bson.NewDocument(
+			"id", cursorID,
+			"ns", fmt.Sprintf("%s.%s", dbName, collection),
+			"firstBatch", firstBatch,
 		)),
+		"ok", float64(1),
 	)))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return reply, nil
+}
+
+// indexSpecDocument returns the index specification document
+// as returned by listIndexes and reIndex commands.
+func indexSpecDocument(index *backends.IndexInfo) *types.Document {
+	res := must.NotFail(types.NewDocument(
+		"v", int32(2), // for compatibility, the meaning of this field is not documented
+		"key", indexKeyDocument(index.Key),
+		"name", index.Name,
+	))
+
+	// only non-default unique indexes should have unique field in the response
+	if index.Unique && index.Name != backends.DefaultIndexName {
+		res.Set("unique", index.Unique)
+	}
+
+	if options := index.OptionsDocument(); options != nil {
+		for _, k := range options.Keys() {
+			res.Set(k, must.NotFail(options.Get(k)))
+		}
+	}
 
-	return &reply, nil
+	return res
 }
@@ -0,0 +1,264 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgMapReduce implements `mapReduce` command.
+//
+// See [common.MapReduceParams] for the supported map, reduce, and finalize expressions.
+func (h *Handler) MsgMapReduce(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	params, err := common.GetMapReduceParams(document, h.L)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := h.mapReduceCollection(params.DB, params.Collection, document.Command())
+	if err != nil {
+		return nil, err
+	}
+
+	closer := iterator.NewMultiCloser()
+	defer closer.Close()
+
+	var qp backends.QueryParams
+	if !h.DisablePushdown {
+		qp.Filter = params.Filter
+	}
+
+	queryRes, err := c.Query(ctx, &qp)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	closer.Add(queryRes.Iter)
+
+	iter := common.FilterIterator(queryRes.Iter, closer, params.Filter, nil)
+
+	if iter, err = common.SortIterator(iter, closer, &common.SortParams{
+		Sort:         params.Sort,
+		Collation:    params.Collation,
+		MemoryLimit:  h.SortMemoryLimitBytes,
+		AllowDiskUse: true,
+	}); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	iter = common.LimitIterator(iter, closer, params.Limit)
+
+	res, err := common.MapReduce(iter, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Out.Inline {
+		results := types.MakeArray(len(res))
+
+		for _, doc := range res {
+			if err = common.FinalizeMapReduce(params, doc); err != nil {
+				return nil, err
+			}
+
+			results.Append(doc)
+		}
+
+		var reply wire.OpMsg
+		must.NoError(reply.SetSections(wire.MakeOpMsgSection(
+			must.NotFail(types.NewDocument(
+				"results", results,
+				"ok", float64(1),
+			)),
+		)))
+
+		return &reply, nil
+	}
+
+	outDB := params.Out.DB
+	if outDB == "" {
+		outDB = params.DB
+	}
+
+	out, err := h.mapReduceCollection(outDB, params.Out.Collection, document.Command())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = h.mapReduceOutput(ctx, out, params, res); err != nil {
+		return nil, err
+	}
+
+	var result any = params.Out.Collection
+	if params.Out.DB != "" {
+		result = must.NotFail(types.NewDocument("db", params.Out.DB, "collection", params.Out.Collection))
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
+		must.NotFail(types.NewDocument(
+			"result", result,
+			"ok", float64(1),
+		)),
+	)))
+
+	return &reply, nil
+}
+
+// mapReduceCollection returns the collection for `mapReduce` command input or output.
+func (h *Handler) mapReduceCollection(dbName, cName, command string) (backends.Collection, error) {
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, cName)
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	c, err := db.Collection(cName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid collection name: %s", cName)
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	return c, nil
+}
+
+// mapReduceOutput writes `mapReduce` results to the output collection according to the output action.
+//
+// For "replace", existing documents are removed, but the collection and its indexes are kept.
+// For "merge", existing documents with the same _id are replaced.
+// For "reduce", existing documents with the same _id are reduced with the new results.
+// Results are finalized after that.
+func (h *Handler) mapReduceOutput(ctx context.Context, out backends.Collection, params *common.MapReduceParams, res []*types.Document) error { //nolint:lll // for readability
+	queryRes, err := out.Query(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	existing, err := iterator.ConsumeValues(queryRes.Iter)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if params.Out.Action == "replace" {
+		ids := make([]any, len(existing))
+		for i, doc := range existing {
+			ids[i] = must.NotFail(doc.Get("_id"))
+		}
+
+		if len(ids) > 0 {
+			if _, err = out.DeleteAll(ctx, &backends.DeleteAllParams{IDs: ids}); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		existing = nil
+	}
+
+	var inserts, updates []*types.Document
+
+	for _, doc := range res {
+		id := must.NotFail(doc.Get("_id"))
+
+		var old *types.Document
+
+		for _, e := range existing {
+			if types.Compare(must.NotFail(e.Get("_id")), id) == types.Equal {
+				old = e
+				break
+			}
+		}
+
+		if old != nil && params.Out.Action == "reduce" {
+			oldValue, _ := old.Get("value")
+			if oldValue == nil {
+				oldValue = types.Null
+			}
+
+			var v any
+			if v, err = common.ReduceMapReduce(params, id, []any{oldValue, must.NotFail(doc.Get("value"))}); err != nil {
+				return err
+			}
+
+			doc.Set("value", v)
+		}
+
+		if err = common.FinalizeMapReduce(params, doc); err != nil {
+			return err
+		}
+
+		if err = doc.ValidateData(); err != nil {
+			return handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrBadValue, err.Error(), "mapReduce")
+		}
+
+		if old != nil {
+			updates = append(updates, doc)
+			continue
+		}
+
+		inserts = append(inserts, doc)
+	}
+
+	if len(updates) > 0 {
+		if _, err = out.UpdateAll(ctx, &backends.UpdateAllParams{Docs: updates}); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if len(inserts) == 0 {
+		return nil
+	}
+
+	_, err = out.InsertAll(ctx, &backends.InsertAllParams{Docs: inserts})
+
+	if backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID) {
+		dbName := params.Out.DB
+		if dbName == "" {
+			dbName = params.DB
+		}
+
+		msg := handlererrors.ClassifyError(err, nil).DuplicateKeyMessage(dbName, params.Out.Collection)
+
+		return handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrDuplicateKeyInsert, msg, "mapReduce")
+	}
+
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
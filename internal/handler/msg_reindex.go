@@ -0,0 +1,154 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgReIndex implements `reIndex` command.
+//
+// In addition to MongoDB's form that rebuilds all collection indexes,
+// FerretDB accepts index field with the name of a single index to rebuild,
+// and background field to rebuild concurrently without blocking writes (if the backend supports that).
+// Background operations are reported by currentOp command and could be canceled by killOp command.
+func (h *Handler) MsgReIndex(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(document, h.L, "comment")
+
+	command := document.Command()
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	var index string
+
+	if index, err = common.GetOptionalParam(document, "index", index); err != nil {
+		return nil, err
+	}
+
+	var background bool
+
+	if v, _ := document.Get("background"); v != nil {
+		if background, err = handlerparams.GetBoolOptionalParam("background", v); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, collection)
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	c, err := db.Collection(collection)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, collection)
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := c.ListIndexes(ctx, nil)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist) {
+			msg := fmt.Sprintf("ns does not exist: %s.%s", dbName, collection)
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrNamespaceNotFound, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	params := &backends.ReIndexParams{
+		Concurrently: background,
+	}
+
+	if index != "" {
+		if !slices.ContainsFunc(res.Indexes, func(i backends.IndexInfo) bool { return i.Name == index }) {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrIndexNotFound,
+				fmt.Sprintf("index not found with name [%s]", index),
+				command,
+			)
+		}
+
+		params.Indexes = []string{index}
+	}
+
+	reply := types.MakeDocument(4)
+
+	if background {
+		op := &operation{
+			desc:    command,
+			ns:      dbName + "." + collection,
+			command: document,
+		}
+
+		opID := h.operations.start(ctx, op, func(ctx context.Context) error {
+			_, err := c.ReIndex(ctx, params)
+			return err
+		})
+
+		reply.Set("opid", opID)
+	} else {
+		if _, err = c.ReIndex(ctx, params); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		indexes := types.MakeArray(len(res.Indexes))
+		for _, index := range res.Indexes {
+			indexes.Append(indexSpecDocument(&index))
+		}
+
+		reply.Set("nIndexesWas", int32(len(res.Indexes)))
+		reply.Set("nIndexes", int32(len(res.Indexes)))
+		reply.Set("indexes", indexes)
+	}
+
+	reply.Set("ok", float64(1))
+
+	var resp wire.OpMsg
+	must.NoError(resp.SetSections(wire.MakeOpMsgSection(reply)))
+
+	return &resp, nil
+}
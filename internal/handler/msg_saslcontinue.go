@@ -47,7 +47,7 @@ func (h *Handler) MsgSASLContinue(ctx context.Context, msg *wire.OpMsg) (*wire.O
 	_, _, conv := conninfo.Get(ctx).Auth()
 
 	if conv == nil {
-		h.L.Warn("saslContinue: no conversation to continue")
+		h.la.Warn("saslContinue: no conversation to continue")
 
 		return nil, handlererrors.NewCommandErrorMsgWithArgument(
 			handlererrors.ErrAuthenticationFailed,
@@ -65,11 +65,11 @@ func (h *Handler) MsgSASLContinue(ctx context.Context, msg *wire.OpMsg) (*wire.O
 	}
 
 	if err != nil {
-		if h.L.Level().Enabled(zap.DebugLevel) {
+		if h.la.Level().Enabled(zap.DebugLevel) {
 			fields = append(fields, zap.Error(err))
 		}
 
-		h.L.Warn("saslContinue: step failed", fields...)
+		h.la.Warn("saslContinue: step failed", fields...)
 
 		return nil, handlererrors.NewCommandErrorMsgWithArgument(
 			handlererrors.ErrAuthenticationFailed,
@@ -78,7 +78,7 @@ func (h *Handler) MsgSASLContinue(ctx context.Context, msg *wire.OpMsg) (*wire.O
 		)
 	}
 
-	h.L.Debug("saslContinue: step succeed", fields...)
+	h.la.Debug("saslContinue: step succeed", fields...)
 
 	if conv.Valid() {
 		conninfo.Get(ctx).SetBypassBackendAuth()
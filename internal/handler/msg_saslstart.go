@@ -193,7 +193,7 @@ func (h *Handler) scramCredentialLookup(ctx context.Context, dbName, username, m
 			return nil, lazyerrors.Error(err)
 		}
 
-		matches, err := common.FilterDocument(v, filter)
+		matches, err := common.FilterDocument(v, filter, nil)
 		if err != nil {
 			return nil, lazyerrors.Error(err)
 		}
@@ -230,7 +230,7 @@ func (h *Handler) scramCredentialLookup(ctx context.Context, dbName, username, m
 		}
 	}
 
-	h.L.Warn("scramCredentialLookup: failed", zap.String("user", username))
+	h.la.Warn("scramCredentialLookup: failed", zap.String("user", username))
 
 	return nil, handlererrors.NewCommandErrorMsgWithArgument(
 		handlererrors.ErrAuthenticationFailed,
@@ -286,16 +286,16 @@ func (h *Handler) saslStartSCRAM(ctx context.Context, dbName, mechanism string,
 	}
 
 	if err != nil {
-		if h.L.Level().Enabled(zap.DebugLevel) {
+		if h.la.Level().Enabled(zap.DebugLevel) {
 			fields = append(fields, zap.Error(err))
 		}
 
-		h.L.Warn("saslStartSCRAM: step failed", fields...)
+		h.la.Warn("saslStartSCRAM: step failed", fields...)
 
 		return "", err
 	}
 
-	h.L.Debug("saslStartSCRAM: step succeed", fields...)
+	h.la.Debug("saslStartSCRAM: step succeed", fields...)
 
 	conninfo.Get(ctx).SetAuth(conv.Username(), "", conv)
 
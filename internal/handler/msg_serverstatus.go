@@ -18,8 +18,11 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
+	"golang.org/x/exp/maps"
+
 	"github.com/FerretDB/FerretDB/build/version"
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/types"
@@ -42,28 +45,14 @@ func (h *Handler) MsgServerStatus(ctx context.Context, msg *wire.OpMsg) (*wire.O
 
 	uptime := time.Since(h.StateProvider.Get().Start)
 
-	metricsDoc := types.MakeDocument(0)
-
-	metrics := h.ConnMetrics.GetResponses()
-	for _, commands := range metrics {
-		for command, arguments := range commands {
-			var total, failed int
-			for _, m := range arguments {
-				total += m.Total
-
-				for _, v := range m.Failures {
-					failed += v
-				}
-			}
-
-			d := must.NotFail(types.NewDocument("total", int64(total), "failed", int64(failed)))
-			metricsDoc.Set(command, d)
-		}
+	metricsDoc, err := h.commandsMetrics()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
 	}
 
 	res := must.NotFail(types.NewDocument(
 		"host", host,
-		"version", version.Get().MongoDBVersion,
+		"version", h.serverVersion.version,
 		"process", filepath.Base(exec),
 		"pid", int64(os.Getpid()),
 		"uptime", uptime.Seconds(),
@@ -83,6 +72,10 @@ func (h *Handler) MsgServerStatus(ctx context.Context, msg *wire.OpMsg) (*wire.O
 		"ok", float64(1),
 	))
 
+	if !h.extensions() {
+		res.Remove("ferretdbVersion")
+	}
+
 	stats, err := h.b.Status(ctx, new(backends.StatusParams))
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -105,3 +98,41 @@ func (h *Handler) MsgServerStatus(ctx context.Context, msg *wire.OpMsg) (*wire.O
 
 	return &reply, nil
 }
+
+// commandsMetrics returns `metrics.commands` document for serverStatus.
+//
+// Like MongoDB, it contains all known commands (including not invoked ones) sorted by name,
+// with invocations of unknown commands counted in `<UNKNOWN>` field.
+func (h *Handler) commandsMetrics() (*types.Document, error) {
+	counts := h.ConnMetrics.GetCommands()
+
+	names := maps.Keys(h.Commands())
+	slices.Sort(names)
+	res := types.MakeDocument(len(names) + 1)
+
+	var unknown int64
+
+	for command, c := range counts {
+		if _, ok := h.Commands()[command]; !ok {
+			unknown += c.Total
+		}
+	}
+
+	res.Set("<UNKNOWN>", unknown)
+
+	for _, command := range names {
+		c := counts[command]
+
+		doc, err := types.NewDocument(
+			"failed", c.Failed,
+			"total", c.Total,
+		)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res.Set(command, doc)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/logging"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// setParameterGenericArgs contains generic command arguments that are not parameters.
+var setParameterGenericArgs = []string{
+	"apiVersion", "apiStrict", "apiDeprecationErrors",
+	"comment", "lsid", "maxTimeMS", "readConcern", "writeConcern",
+	"txnNumber", "autocommit", "startTransaction",
+}
+
+// MsgSetParameter implements `setParameter` command.
+//
+// All parameters are validated before any of them is set.
+func (h *Handler) MsgSetParameter(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrUnauthorized,
+			"setParameter may only be run against the admin database.",
+			document.Command(),
+		)
+	}
+
+	common.Ignored(document, h.L, "comment")
+
+	var setters []func() any
+
+	for _, k := range document.Keys() {
+		if k == document.Command() || slices.Contains(setParameterGenericArgs, k) || strings.HasPrefix(k, "$") {
+			continue
+		}
+
+		v := must.NotFail(document.Get(k))
+
+		var set func() any
+
+		switch k {
+		case "logLevel":
+			set, err = logLevelSetter(v)
+		case "logComponentVerbosity":
+			set, err = logComponentVerbositySetter(v)
+		default:
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrInvalidOptions,
+				fmt.Sprintf("attempted to set unrecognized parameter [%s], use help:true to see options ", k),
+				document.Command(),
+			)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		setters = append(setters, set)
+	}
+
+	if len(setters) == 0 {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrInvalidOptions,
+			"no option found to set, use help:true to see options ",
+			document.Command(),
+		)
+	}
+
+	var was any
+
+	for i, set := range setters {
+		old := set()
+
+		// like MongoDB, return the previous value of the first parameter only
+		if i == 0 {
+			was = old
+		}
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
+		must.NotFail(types.NewDocument(
+			"was", was,
+			"ok", float64(1),
+		)),
+	)))
+
+	return &reply, nil
+}
+
+// getVerbosity returns verbosity level from the given value.
+func getVerbosity(param string, v any) (int32, error) {
+	n, err := handlerparams.GetWholeNumberParam(v)
+	if err != nil || n < -1 || n > 5 {
+		return 0, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrBadValue,
+			fmt.Sprintf("Invalid value for %s: %s", param, types.FormatAnyValue(v)),
+			"setParameter",
+		)
+	}
+
+	return int32(n), nil
+}
+
+// logLevelSetter validates the global log verbosity level and returns a function
+// that sets it and returns the previous one.
+func logLevelSetter(v any) (func() any, error) {
+	n, err := getVerbosity("logLevel", v)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() any {
+		return logging.Verbosity.SetLevel(max(n, 0))
+	}, nil
+}
+
+// logComponentVerbositySetter validates log verbosity levels of the global level and components,
+// and returns a function that sets them and returns the previous values.
+//
+// Values of components could be either numbers or documents with the `verbosity` field.
+// Unspecified components are not changed.
+func logComponentVerbositySetter(v any) (func() any, error) {
+	doc, ok := v.(*types.Document)
+	if !ok {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrTypeMismatch,
+			"logComponentVerbosity must be an object",
+			"setParameter",
+		)
+	}
+
+	levels := make(map[string]int32, doc.Len())
+
+	for _, k := range doc.Keys() {
+		cv := must.NotFail(doc.Get(k))
+
+		if k != "verbosity" {
+			if _, ok := logging.VerbosityComponents[k]; !ok {
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(
+					handlererrors.ErrBadValue,
+					fmt.Sprintf("Invalid component name logComponentVerbosity.%s", k),
+					"setParameter",
+				)
+			}
+
+			if d, ok := cv.(*types.Document); ok {
+				if cv, _ = d.Get("verbosity"); cv == nil {
+					continue
+				}
+			}
+		}
+
+		n, err := getVerbosity("logComponentVerbosity."+k, cv)
+		if err != nil {
+			return nil, err
+		}
+
+		levels[k] = n
+	}
+
+	return func() any {
+		old := logComponentVerbosity()
+
+		for k, n := range levels {
+			if k == "verbosity" {
+				logging.Verbosity.SetLevel(max(n, 0))
+				continue
+			}
+
+			must.NoError(logging.Verbosity.SetComponent(k, n))
+		}
+
+		return old
+	}, nil
+}
+
+// logComponentVerbosity returns the current `logComponentVerbosity` parameter value.
+func logComponentVerbosity() *types.Document {
+	components := logging.Verbosity.Components()
+
+	keys := maps.Keys(components)
+	slices.Sort(keys)
+
+	res := types.MakeDocument(len(keys) + 1)
+	res.Set("verbosity", logging.Verbosity.Level())
+
+	for _, k := range keys {
+		res.Set(k, must.NotFail(types.NewDocument("verbosity", components[k])))
+	}
+
+	return res
+}
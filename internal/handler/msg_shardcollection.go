@@ -0,0 +1,214 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgShardCollection implements `shardCollection` command.
+//
+// FerretDB is not sharded; the command creates the collection if needed
+// and stores emulated sharding metadata in the `config` database.
+func (h *Handler) MsgShardCollection(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(
+		document, h.L,
+		"numInitialChunks", "collation", "presplitHashedZones", "timeseries",
+		"implicitlyCreateIndex", "enforceUniquenessCheck", "writeConcern", "comment",
+	)
+
+	command := document.Command()
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	ns, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	shardedDBName, cName, ok := strings.Cut(ns, ".")
+	if !ok || shardedDBName == "" || cName == "" {
+		msg := fmt.Sprintf("Invalid namespace specified '%s'", ns)
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, command)
+	}
+
+	key, err := common.GetRequiredParam[*types.Document](document, "key")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = validateShardKey(key); err != nil {
+		return nil, err
+	}
+
+	unique, err := common.GetOptionalParam(document, "unique", false)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := h.b.Database(shardedDBName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s'", ns)
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	existing, err := h.configGet(ctx, "collections", ns)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if existing != nil {
+		existingKey, _ := existing.Get("key")
+		if d, _ := existingKey.(*types.Document); d == nil || types.Compare(d, key) != types.Equal {
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(
+				handlererrors.ErrAlreadyInitialized,
+				fmt.Sprintf("sharding already enabled for collection %s with a different shard key", ns),
+				command,
+			)
+		}
+
+		return shardCollectionReply(ns), nil
+	}
+
+	err = db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: cName})
+
+	switch {
+	case err == nil, backends.ErrorCodeIs(err, backends.ErrorCodeCollectionAlreadyExists):
+		// nothing
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid):
+		msg := fmt.Sprintf("Invalid collection name: %s", cName)
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, command)
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	list, err := db.ListCollections(ctx, &backends.ListCollectionsParams{Name: cName})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err = h.shardingEnable(ctx, shardedDBName); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	now := time.Now()
+
+	coll := must.NotFail(types.NewDocument(
+		"_id", ns,
+		"lastmodEpoch", types.NewObjectID(),
+		"lastmod", now,
+		"timestamp", types.NewTimestamp(now, 1),
+	))
+
+	if len(list.Collections) > 0 && list.Collections[0].UUID != "" {
+		var u uuid.UUID
+		if u, err = uuid.Parse(list.Collections[0].UUID); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		coll.Set("uuid", types.Binary{Subtype: types.BinaryUUID, B: must.NotFail(u.MarshalBinary())})
+	}
+
+	coll.Set("key", key)
+	coll.Set("unique", unique)
+	coll.Set("noBalance", false)
+
+	if err = h.configSave(ctx, "collections", coll); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return shardCollectionReply(ns), nil
+}
+
+// validateShardKey checks that the shard key pattern is a list of ascending fields
+// with at most one hashed field.
+func validateShardKey(key *types.Document) error {
+	var hashed int
+
+	valid := key.Len() > 0
+
+	for _, v := range key.Values() {
+		switch v := v.(type) {
+		case float64, int32, int64:
+			if types.Compare(v, int32(1)) != types.Equal {
+				valid = false
+			}
+		case string:
+			if v != "hashed" {
+				valid = false
+			}
+
+			hashed++
+		default:
+			valid = false
+		}
+	}
+
+	if !valid || hashed > 1 {
+		return handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrBadValue,
+			"Unsupported shard key pattern. Pattern must either be a single hashed field, or a list of ascending fields",
+			"shardCollection",
+		)
+	}
+
+	return nil
+}
+
+// shardCollectionReply returns `shardCollection` command reply for the given namespace.
+func shardCollectionReply(ns string) *wire.OpMsg {
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
+		must.NotFail(types.NewDocument(
+			"collectionsharded", ns,
+			"ok", float64(1),
+		)),
+	)))
+
+	return &reply
+}
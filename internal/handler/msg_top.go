@@ -0,0 +1,97 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgTop implements `top` command.
+func (h *Handler) MsgTop(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrUnauthorized,
+			"top may only be run against the admin database.",
+			document.Command(),
+		)
+	}
+
+	namespaces := h.ConnMetrics.Namespaces.GetNamespaces()
+
+	keys := make([]connmetrics.Namespace, 0, len(namespaces))
+	for ns := range namespaces {
+		keys = append(keys, ns)
+	}
+
+	slices.SortFunc(keys, func(a, b connmetrics.Namespace) int {
+		return cmp.Compare(a.String(), b.String())
+	})
+
+	totals := must.NotFail(types.NewDocument("note", "all times in microseconds"))
+
+	for _, ns := range keys {
+		s := namespaces[ns]
+
+		totals.Set(ns.String(), must.NotFail(types.NewDocument(
+			"total", topMetrics(s.Total),
+			"readLock", topMetrics(s.ReadLock),
+			"writeLock", topMetrics(s.WriteLock),
+			"queries", topMetrics(s.Queries),
+			"getmore", topMetrics(s.GetMore),
+			"insert", topMetrics(s.Insert),
+			"update", topMetrics(s.Update),
+			"remove", topMetrics(s.Remove),
+			"commands", topMetrics(s.Commands),
+		)))
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
+		must.NotFail(types.NewDocument(
+			"totals", totals,
+			"ok", float64(1),
+		)),
+	)))
+
+	return &reply, nil
+}
+
+// topMetrics returns `top` document for the given operation metrics.
+func topMetrics(m connmetrics.OpMetrics) *types.Document {
+	return must.NotFail(types.NewDocument(
+		"time", m.Micros,
+		"count", m.Count,
+	))
+}
@@ -16,6 +16,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
@@ -40,25 +41,11 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
-	// TODO https://github.com/FerretDB/FerretDB/issues/2612
-	_ = params.Ordered
-
-	matched, modified, upserted, err := h.updateDocument(ctx, params)
+	res, err := h.updateDocument(ctx, params)
 	if err != nil {
 		return nil, handleUpdateError(params.DB, params.Collection, "update", err)
 	}
 
-	res := must.NotFail(types.NewDocument(
-		"n", matched,
-	))
-
-	if upserted.Len() != 0 {
-		res.Set("upserted", upserted)
-	}
-
-	res.Set("nModified", modified)
-	res.Set("ok", float64(1))
-
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
 		res,
@@ -67,19 +54,20 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	return &reply, nil
 }
 
-// updateDocument iterate through all documents in collection and update them.
-func (h *Handler) updateDocument(ctx context.Context, params *common.UpdateParams) (int32, int32, *types.Array, error) {
-	var matched, modified int32
-	var upserted types.Array
-
+// updateDocument executes update statements and returns the command response.
+//
+// Changes of documents are sent to the backend in batches of up to BatchSize documents.
+// Write errors are reported for individual statements;
+// in ordered mode, statements after the first failed one are not executed.
+func (h *Handler) updateDocument(ctx context.Context, params *common.UpdateParams) (*types.Document, error) {
 	db, err := h.b.Database(params.DB)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
 			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", params.DB, params.Collection)
-			return 0, 0, nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, "update")
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, "update")
 		}
 
-		return 0, 0, nil, lazyerrors.Error(err)
+		return nil, lazyerrors.Error(err)
 	}
 
 	err = db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: params.Collection})
@@ -91,46 +79,74 @@ func (h *Handler) updateDocument(ctx context.Context, params *common.UpdateParam
 		// nothing
 	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid):
 		msg := fmt.Sprintf("Invalid collection name: %s", params.Collection)
-		return 0, 0, nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, "insert")
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, "insert")
 	default:
-		return 0, 0, nil, lazyerrors.Error(err)
+		return nil, lazyerrors.Error(err)
 	}
 
-	for _, u := range params.Updates {
-		c, err := db.Collection(params.Collection)
-		if err != nil {
-			if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
-				msg := fmt.Sprintf("Invalid collection name: %s", params.Collection)
-				return 0, 0, nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, "insert")
-			}
-
-			return 0, 0, nil, lazyerrors.Error(err)
+	c, err := db.Collection(params.Collection)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid collection name: %s", params.Collection)
+			return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, "insert")
 		}
 
-		var qp backends.QueryParams
-		if !h.DisablePushdown {
-			qp.Filter = u.Filter
+		return nil, lazyerrors.Error(err)
+	}
+
+	wb := newWriteBatch(c, h.BatchSize, params.Ordered)
+
+	results := make([]*common.UpdateResult, len(params.Updates))
+	var errs []statementError
+
+	for i, u := range params.Updates {
+		wb.setIndex(i)
+
+		var result *common.UpdateResult
+		if result, err = h.execUpdate(ctx, wb, &u); err == nil {
+			results[i] = result
+			continue
 		}
 
-		res, err := c.Query(ctx, &qp)
-		if err != nil {
-			return 0, 0, nil, lazyerrors.Error(err)
+		if errors.Is(err, errWriteBatchAborted) {
+			break
 		}
 
-		closer := iterator.NewMultiCloser()
-		defer closer.Close()
+		err = handleUpdateError(params.DB, params.Collection, "update", err)
 
-		closer.Add(res.Iter)
+		var we *handlererrors.WriteErrors
+		if !errors.As(err, &we) {
+			return nil, err
+		}
 
-		iter := common.FilterIterator(res.Iter, closer, u.Filter)
+		errs = append(errs, statementError{err: we, index: i})
 
-		if !u.Multi {
-			iter = common.LimitIterator(iter, closer, 1)
+		if params.Ordered {
+			break
 		}
+	}
+
+	failures, err := wb.flush(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// statements with rejected changes failed, so their results are not counted
+	for _, f := range failures {
+		results[f.index] = nil
+	}
+
+	writeErrors, first := writeErrorsArray(params.DB, params.Collection, errs, failures, params.Ordered)
+	if params.Ordered && first >= 0 {
+		results = results[:first]
+	}
 
-		result, err := common.UpdateDocument(ctx, c, "update", iter, &u)
-		if err != nil {
-			return 0, 0, nil, lazyerrors.Error(err)
+	var matched, modified int32
+	var upserted types.Array
+
+	for i, result := range results {
+		if result == nil {
+			continue
 		}
 
 		matched += result.Matched.Count
@@ -139,7 +155,7 @@ func (h *Handler) updateDocument(ctx context.Context, params *common.UpdateParam
 		if result.Upserted.Doc != nil {
 			doc := result.Upserted.Doc
 			upserted.Append(must.NotFail(types.NewDocument(
-				"index", int32(upserted.Len()),
+				"index", int32(i),
 				"_id", must.NotFail(doc.Get("_id")),
 			)))
 
@@ -148,5 +164,52 @@ func (h *Handler) updateDocument(ctx context.Context, params *common.UpdateParam
 		}
 	}
 
-	return matched, modified, &upserted, nil
+	res := must.NotFail(types.NewDocument(
+		"n", matched,
+	))
+
+	if upserted.Len() != 0 {
+		res.Set("upserted", &upserted)
+	}
+
+	res.Set("nModified", modified)
+
+	if writeErrors != nil {
+		res.Set("writeErrors", writeErrors)
+	}
+
+	res.Set("ok", float64(1))
+
+	return res, nil
+}
+
+// execUpdate performs a single update operation.
+func (h *Handler) execUpdate(ctx context.Context, c backends.Collection, u *common.Update) (*common.UpdateResult, error) {
+	var qp backends.QueryParams
+	if !h.DisablePushdown && u.Collation == nil {
+		qp.Filter = u.Filter
+	}
+
+	res, err := c.Query(ctx, &qp)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	closer := iterator.NewMultiCloser()
+	defer closer.Close()
+
+	closer.Add(res.Iter)
+
+	iter := common.FilterIterator(res.Iter, closer, u.Filter, u.Collation)
+
+	if !u.Multi {
+		iter = common.LimitIterator(iter, closer, 1)
+	}
+
+	result, err := common.UpdateDocument(ctx, c, "update", iter, u)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return result, nil
 }
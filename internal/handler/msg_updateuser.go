@@ -157,6 +157,8 @@ func (h *Handler) MsgUpdateUser(ctx context.Context, msg *wire.OpMsg) (*wire.OpM
 		return nil, lazyerrors.Error(err)
 	}
 
+	defer users.Lock()()
+
 	// Filter isn't being passed to the query as we are filtering after retrieving all data
 	// from the database due to limitations of the internal/backends filters.
 	qr, err := usersCol.Query(ctx, nil)
@@ -181,7 +183,7 @@ func (h *Handler) MsgUpdateUser(ctx context.Context, msg *wire.OpMsg) (*wire.OpM
 		}
 
 		var matches bool
-		matches, err = common.FilterDocument(v, filter)
+		matches, err = common.FilterDocument(v, filter, nil)
 		if err != nil {
 			return nil, lazyerrors.Error(err)
 		}
@@ -163,7 +163,7 @@ func (h *Handler) MsgUsersInfo(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 			return nil, lazyerrors.Error(err)
 		}
 
-		matches, err := common.FilterDocument(v, filter)
+		matches, err := common.FilterDocument(v, filter, nil)
 		if err != nil {
 			return nil, lazyerrors.Error(err)
 		}
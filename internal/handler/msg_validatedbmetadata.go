@@ -0,0 +1,115 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgValidateDBMetadata implements `validateDBMetadata` command.
+func (h *Handler) MsgValidateDBMetadata(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	apiParameters, err := common.GetRequiredParam[*types.Document](document, "apiParameters")
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := common.GetRequiredParam[string](apiParameters, "version")
+	if err != nil {
+		return nil, err
+	}
+
+	if version != "1" {
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrAPIVersionError,
+			fmt.Sprintf("API version must be \"1\" but got \"%s\"", version),
+			command,
+		)
+	}
+
+	for _, k := range []string{"strict", "deprecationErrors"} {
+		if v, _ := apiParameters.Get(k); v != nil {
+			if _, err = handlerparams.GetBoolOptionalParam(k, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var dbName, collection string
+
+	if dbName, err = common.GetOptionalParam(document, "db", dbName); err != nil {
+		return nil, err
+	}
+
+	if collection, err = common.GetOptionalParam(document, "collection", collection); err != nil {
+		return nil, err
+	}
+
+	if dbName != "" {
+		var db backends.Database
+
+		if db, err = h.b.Database(dbName); err != nil {
+			if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+				msg := fmt.Sprintf("Invalid db name: %s", dbName)
+				return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, command)
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		if collection != "" {
+			if _, err = db.Collection(collection); err != nil {
+				if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+					msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, collection)
+					return nil, handlererrors.NewCommandErrorMsgWithArgument(handlererrors.ErrInvalidNamespace, msg, command)
+				}
+
+				return nil, lazyerrors.Error(err)
+			}
+		}
+	}
+
+	// All collection options (only capped collections for now) and index specs
+	// (ascending and descending keys, unique option) that could be created are part of API Version 1,
+	// so there is nothing to report for any namespace.
+	// That should be revisited when views, validators, or other index types are supported.
+	apiVersionErrors := types.MakeArray(0)
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.MakeOpMsgSection(
+		must.NotFail(types.NewDocument(
+			"apiVersionErrors", apiVersionErrors,
+			"ok", float64(1),
+		)),
+	)))
+
+	return &reply, nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// operation represents a command running in the background,
+// like reIndex with background option.
+type operation struct {
+	opID    int32
+	desc    string // like "reIndex"
+	ns      string
+	command *types.Document
+	started time.Time
+	cancel  context.CancelFunc
+}
+
+// document returns the representation of the operation for currentOp command.
+func (op *operation) document() *types.Document {
+	running := time.Since(op.started)
+
+	return must.NotFail(types.NewDocument(
+		"type", "op",
+		"desc", op.desc,
+		"active", true,
+		"opid", op.opID,
+		"secs_running", int64(running.Seconds()),
+		"microsecs_running", running.Microseconds(),
+		"op", "command",
+		"ns", op.ns,
+		"command", op.command,
+	))
+}
+
+// operations tracks background operations.
+//
+// They are reported by currentOp command and could be canceled by killOp command.
+type operations struct {
+	l *zap.Logger
+
+	wg sync.WaitGroup
+
+	rw     sync.RWMutex
+	ops    map[int32]*operation
+	lastID int32
+}
+
+// newOperations creates a new operations tracker.
+func newOperations(l *zap.Logger) *operations {
+	return &operations{
+		l:   l,
+		ops: map[int32]*operation{},
+	}
+}
+
+// start runs the given function in the background as a new operation and returns its ID.
+//
+// The function's context has values of the given context (like connection information),
+// but it is canceled only by kill or close.
+func (o *operations) start(ctx context.Context, op *operation, f func(context.Context) error) int32 {
+	ctx, op.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	op.started = time.Now()
+
+	o.rw.Lock()
+	o.lastID++
+	op.opID = o.lastID
+	o.ops[op.opID] = op
+	o.rw.Unlock()
+
+	o.wg.Add(1)
+
+	go func() {
+		defer func() {
+			op.cancel()
+
+			o.rw.Lock()
+			delete(o.ops, op.opID)
+			o.rw.Unlock()
+
+			o.wg.Done()
+		}()
+
+		l := o.l.With(zap.Int32("opid", op.opID), zap.String("desc", op.desc), zap.String("ns", op.ns))
+		l.Info("Operation started")
+
+		if err := f(ctx); err != nil {
+			l.Error("Operation failed", zap.Error(err), zap.Duration("duration", time.Since(op.started)))
+			return
+		}
+
+		l.Info("Operation finished", zap.Duration("duration", time.Since(op.started)))
+	}()
+
+	return op.opID
+}
+
+// list returns running operations sorted by ID.
+func (o *operations) list() []*operation {
+	o.rw.RLock()
+	defer o.rw.RUnlock()
+
+	res := make([]*operation, 0, len(o.ops))
+	for _, op := range o.ops {
+		res = append(res, op)
+	}
+
+	slices.SortFunc(res, func(a, b *operation) int {
+		return cmp.Compare(a.opID, b.opID)
+	})
+
+	return res
+}
+
+// kill cancels the operation with the given ID, if it is running.
+func (o *operations) kill(opID int32) {
+	o.rw.RLock()
+	defer o.rw.RUnlock()
+
+	if op := o.ops[opID]; op != nil {
+		o.l.Info("Killing operation", zap.Int32("opid", opID))
+		op.cancel()
+	}
+}
+
+// close cancels all running operations and waits for them to finish.
+func (o *operations) close() {
+	o.rw.RLock()
+
+	for _, op := range o.ops {
+		op.cancel()
+	}
+
+	o.rw.RUnlock()
+
+	o.wg.Wait()
+}
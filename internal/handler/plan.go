@@ -0,0 +1,93 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/handler/planner"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// queryPlan returns the plan for the query on the given collection using the plan cache.
+//
+// Collection indexes are set in the given parameters.
+// Plans for non-existent collections are always collection scans; hint is not checked.
+// Invalid hints are returned as command errors for the given command.
+func (h *Handler) queryPlan(ctx context.Context, coll backends.Collection, dbName, cName string, params *planner.Params, command string) (*planner.Plan, error) { //nolint:lll // for readability
+	res, err := coll.ListIndexes(ctx, nil)
+
+	switch {
+	case err == nil:
+		params.Indexes = res.Indexes
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist):
+		return new(planner.Plan), nil
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	plan, err := h.planCache.Plan(dbName, cName, params)
+
+	switch {
+	case err == nil:
+		return plan, nil
+	case errors.Is(err, planner.ErrBadHint), errors.Is(err, planner.ErrInvalidHint):
+		return nil, handlererrors.NewCommandErrorMsgWithArgument(
+			handlererrors.ErrBadValue,
+			"error processing query: planner returned error :: caused by :: "+err.Error(),
+			command,
+		)
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+}
+
+// planStage returns a plan tree with MongoDB stage names (IXSCAN with FETCH or COLLSCAN)
+// that describes how documents matching the filter are fetched by the given plan.
+func planStage(plan *planner.Plan, filter *types.Document) *types.Document {
+	if filter == nil {
+		filter = must.NotFail(types.NewDocument())
+	}
+
+	direction := "forward"
+	if plan.Backward {
+		direction = "backward"
+	}
+
+	if plan.Index == nil {
+		return must.NotFail(types.NewDocument(
+			"stage", "COLLSCAN",
+			"filter", filter,
+			"direction", direction,
+		))
+	}
+
+	return must.NotFail(types.NewDocument(
+		"stage", "FETCH",
+		"filter", filter,
+		"inputStage", must.NotFail(types.NewDocument(
+			"stage", "IXSCAN",
+			"keyPattern", indexKeyDocument(plan.Index.Key),
+			"indexName", plan.Index.Name,
+			"isUnique", plan.Index.Unique,
+			"direction", direction,
+		)),
+	))
+}
@@ -0,0 +1,212 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handler/queryshape"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// Entry represents a single cached plan.
+type Entry struct {
+	Key        string // see PlanCacheKey
+	DB         string
+	Collection string
+
+	// Shape of the filter and the sort that the plan was created for.
+	Filter *types.Document
+	Sort   *types.Document
+
+	Plan     *Plan
+	Created  time.Time
+	LastUsed time.Time
+	Hits     int64
+
+	shape   string // see shapeKey
+	indexes string // fingerprint of collection indexes the plan was chosen from
+}
+
+// Cache caches plans per query shape and collection indexes.
+//
+// Plans are not reused after collection indexes are changed.
+// Plans forced by hint are not cached.
+// The number of entries is limited; when the limit is reached, the least recently used entry is evicted.
+//
+// It is safe for concurrent use.
+type Cache struct {
+	maxEntries int
+
+	m       sync.Mutex
+	entries map[string]*Entry
+}
+
+// NewCache creates a new cache with up to maxEntries plans.
+func NewCache(maxEntries int) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		entries:    map[string]*Entry{},
+	}
+}
+
+// shapeKey returns a string that uniquely identifies the query shape in the given collection.
+func shapeKey(db, collection string, filter, sort *types.Document) string {
+	parts := []string{db, collection, "", ""}
+
+	if filter != nil {
+		parts[2] = types.FormatAnyValue(queryshape.Shape(filter))
+	}
+
+	if sort != nil {
+		parts[3] = types.FormatAnyValue(sort)
+	}
+
+	return strings.Join(parts, "\x00")
+}
+
+// PlanCacheKey returns a hash that identifies the query shape in the given collection,
+// like MongoDB's planCacheKey.
+func PlanCacheKey(db, collection string, filter, sort *types.Document) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(shapeKey(db, collection, filter, sort)))
+
+	return fmt.Sprintf("%08X", h.Sum32())
+}
+
+// Plan returns a cached plan for the given parameters, or chooses and caches a new one.
+//
+// Errors are the same as for [Choose].
+func (c *Cache) Plan(db, collection string, params *Params) (*Plan, error) {
+	if h, ok := params.Hint.(*types.Document); params.Hint != nil && (!ok || h.Len() > 0) {
+		return Choose(params)
+	}
+
+	key := shapeKey(db, collection, params.Filter, params.Sort)
+	indexes := indexesFingerprint(params)
+	now := time.Now()
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if e := c.entries[key]; e != nil && e.indexes == indexes {
+		e.Hits++
+		e.LastUsed = now
+
+		return e.Plan, nil
+	}
+
+	plan, err := Choose(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		c.evictLocked()
+	}
+
+	c.entries[key] = &Entry{
+		Key:        PlanCacheKey(db, collection, params.Filter, params.Sort),
+		DB:         db,
+		Collection: collection,
+		Filter:     queryshape.Shape(params.Filter),
+		Sort:       params.Sort,
+		Plan:       plan,
+		Created:    now,
+		LastUsed:   now,
+		shape:      key,
+		indexes:    indexes,
+	}
+
+	return plan, nil
+}
+
+// evictLocked removes the least recently used entry.
+//
+// It should be called with locked mutex.
+func (c *Cache) evictLocked() {
+	var oldest *Entry
+
+	for _, e := range c.entries {
+		if oldest == nil || e.LastUsed.Before(oldest.LastUsed) {
+			oldest = e
+		}
+	}
+
+	if oldest != nil {
+		delete(c.entries, oldest.shape)
+	}
+}
+
+// Entries returns copies of cached entries for the given collection, sorted by key.
+func (c *Cache) Entries(db, collection string) []Entry {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	var res []Entry
+
+	for _, e := range c.entries {
+		if e.DB == db && e.Collection == collection {
+			res = append(res, *e)
+		}
+	}
+
+	slices.SortFunc(res, func(a, b Entry) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return res
+}
+
+// Clear removes cached entries for the given collection.
+//
+// If collection is empty, entries for all collections of the database are removed.
+// If key is not empty, only the entry with that key is removed.
+func (c *Cache) Clear(db, collection, key string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	for k, e := range c.entries {
+		if e.DB != db || (collection != "" && e.Collection != collection) {
+			continue
+		}
+
+		if key == "" || e.Key == key {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// indexesFingerprint returns a string that changes when indexes are changed.
+func indexesFingerprint(params *Params) string {
+	var sb strings.Builder
+
+	for _, index := range params.Indexes {
+		sb.WriteString(index.Name)
+
+		for _, pair := range index.Key {
+			fmt.Fprintf(&sb, "\x00%s\x00%t", pair.Field, pair.Descending)
+		}
+
+		sb.WriteByte('\x01')
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,256 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package planner chooses query plans based on collection indexes and hints.
+//
+// Chosen plans are reported by explain and cached per query shape, see [Cache].
+// Backends still decide themselves how to use their indexes for pushed down queries.
+package planner
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+var (
+	// ErrBadHint is returned by Choose if hint does not correspond to an existing index.
+	ErrBadHint = errors.New("hint provided does not correspond to an existing index")
+
+	// ErrInvalidHint is returned by Choose if hint has an invalid type or value.
+	ErrInvalidHint = errors.New("hint must be either a string or nested object")
+)
+
+// Params represents parameters for choosing a plan.
+type Params struct {
+	Filter *types.Document
+	Sort   *types.Document
+
+	// Index name, index key pattern, {$natural: <1|-1>}, or nil.
+	// Empty document is the same as nil.
+	Hint any
+
+	Indexes []backends.IndexInfo
+}
+
+// Plan represents a chosen query plan.
+type Plan struct {
+	// Index to scan; nil for a collection scan.
+	Index *backends.IndexInfo
+
+	// Backward is true if the index or the collection is scanned in the reverse order.
+	Backward bool
+
+	// Hinted is true if the plan was forced by hint.
+	Hinted bool
+}
+
+// Choose returns the plan for the given parameters.
+//
+// If hint is set, it is always used; ErrBadHint or ErrInvalidHint is returned for invalid hints.
+// Otherwise, the index with the longest prefix of fields used by the filter is chosen;
+// an index that also provides the sort order is preferred, and a collection scan is used if none is useful.
+// Partial indexes are used only if hinted; hidden indexes are never used.
+func Choose(params *Params) (*Plan, error) {
+	if h, ok := params.Hint.(*types.Document); params.Hint != nil && (!ok || h.Len() > 0) {
+		return chooseHinted(params)
+	}
+
+	fields := filterFields(params.Filter)
+
+	res := new(Plan)
+
+	var best int
+
+	for i := range params.Indexes {
+		index := &params.Indexes[i]
+
+		if index.Hidden || index.PartialFilterExpression != nil {
+			continue
+		}
+
+		var prefix int
+
+		for _, pair := range index.Key {
+			if _, ok := fields[pair.Field]; !ok {
+				break
+			}
+
+			prefix++
+		}
+
+		// each leading field used by the filter is worth more than the sort order
+		score := prefix * 2
+
+		sorted, backward := sortOrder(index.Key, params.Sort)
+		if !sorted {
+			sorted, backward = sortOrder(index.Key[prefix:], params.Sort)
+		}
+
+		if sorted {
+			score++
+		}
+
+		if score == 0 {
+			continue
+		}
+
+		// prefer smaller indexes
+		if score > best || (score == best && len(index.Key) < len(res.Index.Key)) {
+			best = score
+			res = &Plan{Index: index, Backward: backward}
+		}
+	}
+
+	return res, nil
+}
+
+// chooseHinted returns the plan for the given parameters with hint.
+func chooseHinted(params *Params) (*Plan, error) {
+	var index *backends.IndexInfo
+
+	switch hint := params.Hint.(type) {
+	case *types.Document:
+		if hint.Len() == 1 && hint.Has("$natural") {
+			n, err := handlerparams.GetWholeNumberParam(must.NotFail(hint.Get("$natural")))
+			if err != nil || (n != 1 && n != -1) {
+				return nil, ErrInvalidHint
+			}
+
+			return &Plan{Backward: n == -1, Hinted: true}, nil
+		}
+
+		for i := range params.Indexes {
+			if !params.Indexes[i].Hidden && keyPatternEqual(params.Indexes[i].Key, hint) {
+				index = &params.Indexes[i]
+				break
+			}
+		}
+
+	case string:
+		for i := range params.Indexes {
+			if !params.Indexes[i].Hidden && params.Indexes[i].Name == hint {
+				index = &params.Indexes[i]
+				break
+			}
+		}
+
+	default:
+		return nil, ErrInvalidHint
+	}
+
+	if index == nil {
+		return nil, ErrBadHint
+	}
+
+	_, backward := sortOrder(index.Key, params.Sort)
+
+	return &Plan{Index: index, Backward: backward, Hinted: true}, nil
+}
+
+// filterFields returns names of fields (including dot notation) used by the filter,
+// including fields used by $and conditions.
+func filterFields(filter *types.Document) map[string]struct{} {
+	res := map[string]struct{}{}
+
+	if filter == nil {
+		return res
+	}
+
+	for _, k := range filter.Keys() {
+		if k == "$and" {
+			and, ok := must.NotFail(filter.Get(k)).(*types.Array)
+			if !ok {
+				continue
+			}
+
+			for _, v := range must.NotFail(iterator.ConsumeValues(and.Iterator())) {
+				if cond, ok := v.(*types.Document); ok {
+					for f := range filterFields(cond) {
+						res[f] = struct{}{}
+					}
+				}
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(k, "$") {
+			continue
+		}
+
+		res[k] = struct{}{}
+	}
+
+	return res
+}
+
+// sortOrder reports whether the given index keys provide the sort order,
+// and if they should be scanned backward for that.
+func sortOrder(key []backends.IndexKeyPair, sort *types.Document) (sorted, backward bool) {
+	if sort.Len() == 0 || sort.Len() > len(key) {
+		return false, false
+	}
+
+	for i, field := range sort.Keys() {
+		if key[i].Field != field {
+			return false, false
+		}
+
+		n, err := handlerparams.GetWholeNumberParam(must.NotFail(sort.Get(field)))
+		if err != nil {
+			return false, false
+		}
+
+		reverse := (n < 0) != key[i].Descending
+
+		switch {
+		case i == 0:
+			backward = reverse
+		case reverse != backward:
+			return false, false
+		}
+	}
+
+	return true, backward
+}
+
+// keyPatternEqual reports whether the index keys are the same as the given key pattern.
+func keyPatternEqual(key []backends.IndexKeyPair, pattern *types.Document) bool {
+	if pattern.Len() != len(key) {
+		return false
+	}
+
+	for i, field := range pattern.Keys() {
+		if key[i].Field != field {
+			return false
+		}
+
+		n, err := handlerparams.GetWholeNumberParam(must.NotFail(pattern.Get(field)))
+		if err != nil || n == 0 {
+			return false
+		}
+
+		if (n < 0) != key[i].Descending {
+			return false
+		}
+	}
+
+	return true
+}
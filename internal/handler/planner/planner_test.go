@@ -0,0 +1,261 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// testIndexes contains indexes used by tests.
+var testIndexes = []backends.IndexInfo{
+	{Name: "_id_", Key: []backends.IndexKeyPair{{Field: "_id"}}, Unique: true},
+	{Name: "a_1", Key: []backends.IndexKeyPair{{Field: "a"}}},
+	{Name: "a_1_b_-1", Key: []backends.IndexKeyPair{{Field: "a"}, {Field: "b", Descending: true}}},
+	{Name: "c_-1", Key: []backends.IndexKeyPair{{Field: "c", Descending: true}}},
+	{Name: "e_1", Key: []backends.IndexKeyPair{{Field: "e"}}, Hidden: true},
+	{
+		Name:                    "f_1",
+		Key:                     []backends.IndexKeyPair{{Field: "f"}},
+		PartialFilterExpression: must.NotFail(types.NewDocument("f", must.NotFail(types.NewDocument("$gt", int32(0))))),
+	},
+}
+
+func TestChoose(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		params   *Params
+		index    string // empty for collection scan
+		backward bool
+		hinted   bool
+		err      error
+	}{
+		"NoFilter": {
+			params: &Params{},
+		},
+		"UnindexedFilter": {
+			params: &Params{
+				Filter: must.NotFail(types.NewDocument("d", int32(1))),
+			},
+		},
+		"ID": {
+			params: &Params{
+				Filter: must.NotFail(types.NewDocument("_id", int32(1))),
+			},
+			index: "_id_",
+		},
+		"SmallerIndex": {
+			params: &Params{
+				Filter: must.NotFail(types.NewDocument("a", int32(1))),
+			},
+			index: "a_1",
+		},
+		"LongerPrefix": {
+			params: &Params{
+				Filter: must.NotFail(types.NewDocument("b", int32(1), "a", int32(1))),
+			},
+			index: "a_1_b_-1",
+		},
+		"And": {
+			params: &Params{
+				Filter: must.NotFail(types.NewDocument("$and", must.NotFail(types.NewArray(
+					must.NotFail(types.NewDocument("a", int32(1))),
+					must.NotFail(types.NewDocument("b", int32(1))),
+				)))),
+			},
+			index: "a_1_b_-1",
+		},
+		"FilterAndSort": {
+			params: &Params{
+				Filter: must.NotFail(types.NewDocument("a", int32(1))),
+				Sort:   must.NotFail(types.NewDocument("b", int64(1))),
+			},
+			index:    "a_1_b_-1",
+			backward: true,
+		},
+		"SortOnly": {
+			params: &Params{
+				Sort: must.NotFail(types.NewDocument("c", int64(1))),
+			},
+			index:    "c_-1",
+			backward: true,
+		},
+		"SortMismatch": {
+			params: &Params{
+				Sort: must.NotFail(types.NewDocument("a", int64(1), "b", int64(1))),
+			},
+		},
+		"HintName": {
+			params: &Params{
+				Filter: must.NotFail(types.NewDocument("_id", int32(1))),
+				Hint:   "c_-1",
+			},
+			index:  "c_-1",
+			hinted: true,
+		},
+		"HintKeyPattern": {
+			params: &Params{
+				Hint: must.NotFail(types.NewDocument("a", int32(1), "b", float64(-1))),
+			},
+			index:  "a_1_b_-1",
+			hinted: true,
+		},
+		"HintNatural": {
+			params: &Params{
+				Filter: must.NotFail(types.NewDocument("a", int32(1))),
+				Hint:   must.NotFail(types.NewDocument("$natural", int32(-1))),
+			},
+			backward: true,
+			hinted:   true,
+		},
+		"HintEmpty": {
+			params: &Params{
+				Filter: must.NotFail(types.NewDocument("a", int32(1))),
+				Hint:   must.NotFail(types.NewDocument()),
+			},
+			index: "a_1",
+		},
+		"HintNotFound": {
+			params: &Params{
+				Hint: "d_1",
+			},
+			err: ErrBadHint,
+		},
+		"HintKeyPatternNotFound": {
+			params: &Params{
+				Hint: must.NotFail(types.NewDocument("a", int32(-1))),
+			},
+			err: ErrBadHint,
+		},
+		"Hidden": {
+			params: &Params{
+				Filter: must.NotFail(types.NewDocument("e", int32(1))),
+			},
+		},
+		"HintHidden": {
+			params: &Params{
+				Hint: "e_1",
+			},
+			err: ErrBadHint,
+		},
+		"Partial": {
+			params: &Params{
+				Filter: must.NotFail(types.NewDocument("f", int32(1))),
+			},
+		},
+		"HintPartial": {
+			params: &Params{
+				Filter: must.NotFail(types.NewDocument("f", int32(1))),
+				Hint:   "f_1",
+			},
+			index:  "f_1",
+			hinted: true,
+		},
+		"HintInvalidType": {
+			params: &Params{
+				Hint: int32(1),
+			},
+			err: ErrInvalidHint,
+		},
+		"HintInvalidNatural": {
+			params: &Params{
+				Hint: must.NotFail(types.NewDocument("$natural", int32(2))),
+			},
+			err: ErrInvalidHint,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			params := *tc.params
+			params.Indexes = testIndexes
+
+			plan, err := Choose(&params)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var index string
+			if plan.Index != nil {
+				index = plan.Index.Name
+			}
+
+			assert.Equal(t, tc.index, index)
+			assert.Equal(t, tc.backward, plan.Backward)
+			assert.Equal(t, tc.hinted, plan.Hinted)
+		})
+	}
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	c := NewCache(2)
+
+	params := &Params{
+		Filter:  must.NotFail(types.NewDocument("a", int32(1))),
+		Indexes: testIndexes,
+	}
+
+	plan, err := c.Plan("db", "coll", params)
+	require.NoError(t, err)
+	assert.Equal(t, "a_1", plan.Index.Name)
+
+	// the same shape with a different value
+	params.Filter = must.NotFail(types.NewDocument("a", int32(2)))
+	cached, err := c.Plan("db", "coll", params)
+	require.NoError(t, err)
+	assert.Same(t, plan, cached)
+
+	entries := c.Entries("db", "coll")
+	require.Len(t, entries, 1)
+	assert.Equal(t, PlanCacheKey("db", "coll", params.Filter, nil), entries[0].Key)
+	assert.Equal(t, must.NotFail(types.NewDocument("a", "?number")), entries[0].Filter)
+	assert.Equal(t, int64(1), entries[0].Hits)
+
+	// changed indexes
+	params.Indexes = testIndexes[:1]
+	plan, err = c.Plan("db", "coll", params)
+	require.NoError(t, err)
+	assert.Nil(t, plan.Index)
+
+	// hinted plans are not cached
+	params.Hint = "_id_"
+	_, err = c.Plan("db", "coll", params)
+	require.NoError(t, err)
+	assert.Len(t, c.Entries("db", "coll"), 1)
+
+	params.Hint = nil
+	params.Filter = must.NotFail(types.NewDocument("b", int32(1)))
+	_, err = c.Plan("db", "other", params)
+	require.NoError(t, err)
+
+	c.Clear("db", "coll", "")
+	assert.Empty(t, c.Entries("db", "coll"))
+	assert.Len(t, c.Entries("db", "other"), 1)
+
+	c.Clear("db", "", "")
+	assert.Empty(t, c.Entries("db", "other"))
+}
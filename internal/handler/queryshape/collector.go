@@ -0,0 +1,285 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queryshape
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+)
+
+// Query identifies a query shape.
+type Query struct {
+	Command    string
+	DB         string
+	Collection string
+
+	// Shapes of query parts (see [Shape]); nil if not set.
+	Filter   *types.Document
+	Sort     *types.Document
+	Pipeline *types.Array
+}
+
+// key returns a string that uniquely identifies the query shape.
+func (q *Query) key() string {
+	parts := []string{q.Command, q.DB, q.Collection, "", "", ""}
+
+	if q.Filter != nil {
+		parts[3] = types.FormatAnyValue(q.Filter)
+	}
+
+	if q.Sort != nil {
+		parts[4] = types.FormatAnyValue(q.Sort)
+	}
+
+	if q.Pipeline != nil {
+		parts[5] = types.FormatAnyValue(q.Pipeline)
+	}
+
+	return strings.Join(parts, "\x00")
+}
+
+// Metric represents aggregated values of a single per-execution metric.
+type Metric struct {
+	Sum int64
+	Min int64
+	Max int64
+}
+
+// add adds a single value; first is true for the first execution.
+func (m *Metric) add(v int64, first bool) {
+	m.Sum += v
+
+	if first || v < m.Min {
+		m.Min = v
+	}
+
+	if first || v > m.Max {
+		m.Max = v
+	}
+}
+
+// Stats represents aggregated execution statistics of a single query shape.
+type Stats struct {
+	Query *Query
+	Key   string
+
+	ExecCount     int64
+	ExecMicros    Metric
+	LastExecMicro int64
+	DocsExamined  Metric
+	DocsReturned  Metric
+
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// Collector aggregates execution statistics per query shape.
+//
+// The number of tracked shapes is limited; when the limit is reached,
+// the least recently seen shape is evicted.
+//
+// It is safe for concurrent use.
+type Collector struct {
+	maxShapes int
+
+	rw      sync.RWMutex
+	shapes  map[string]*Stats
+	evicted int64
+}
+
+// NewCollector creates a new collector tracking up to maxShapes query shapes.
+func NewCollector(maxShapes int) *Collector {
+	return &Collector{
+		maxShapes: maxShapes,
+		shapes:    map[string]*Stats{},
+	}
+}
+
+// Track starts tracking a single query execution.
+//
+// Use returned tracker to wrap query iterators.
+func (c *Collector) Track(q *Query) *Tracker {
+	return &Tracker{
+		c: c,
+		q: q,
+	}
+}
+
+// record records a single query execution.
+func (c *Collector) record(q *Query, exec time.Duration, examined, returned int64) {
+	key := q.key()
+	now := time.Now()
+
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	s := c.shapes[key]
+	if s == nil {
+		if len(c.shapes) >= c.maxShapes {
+			c.evictLocked()
+		}
+
+		s = &Stats{
+			Query:     q,
+			Key:       key,
+			FirstSeen: now,
+		}
+		c.shapes[key] = s
+	}
+
+	first := s.ExecCount == 0
+	micros := exec.Microseconds()
+
+	s.ExecCount++
+	s.ExecMicros.add(micros, first)
+	s.LastExecMicro = micros
+	s.DocsExamined.add(examined, first)
+	s.DocsReturned.add(returned, first)
+	s.LastSeen = now
+}
+
+// evictLocked removes the least recently seen shape.
+//
+// It should be called with locked mutex.
+func (c *Collector) evictLocked() {
+	var oldest *Stats
+
+	for _, s := range c.shapes {
+		if oldest == nil || s.LastSeen.Before(oldest.LastSeen) {
+			oldest = s
+		}
+	}
+
+	if oldest != nil {
+		delete(c.shapes, oldest.Key)
+		c.evicted++
+	}
+}
+
+// Stats returns copies of statistics for all tracked shapes,
+// sorted by total execution time, the slowest first.
+func (c *Collector) Stats() []Stats {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	res := make([]Stats, 0, len(c.shapes))
+	for _, s := range c.shapes {
+		res = append(res, *s)
+	}
+
+	slices.SortFunc(res, func(a, b Stats) int {
+		if c := cmp.Compare(b.ExecMicros.Sum, a.ExecMicros.Sum); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.Key, b.Key)
+	})
+
+	return res
+}
+
+// Evicted returns the number of shapes evicted due to the limit.
+func (c *Collector) Evicted() int64 {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	return c.evicted
+}
+
+// Tracker tracks a single query execution.
+type Tracker struct {
+	c *Collector
+	q *Query
+
+	exec     atomic.Int64 // time.Duration spent in Next calls of the returned iterator
+	examined atomic.Int64
+	returned atomic.Int64
+	once     sync.Once
+}
+
+// Examined wraps the iterator of documents fetched from the backend to count them.
+func (t *Tracker) Examined(iter types.DocumentsIterator) types.DocumentsIterator {
+	return &countingIterator{
+		iter: iter,
+		n:    &t.examined,
+	}
+}
+
+// Returned wraps the final iterator of documents returned to the client.
+//
+// It counts documents and the time spent producing them.
+// Statistics are recorded when it is closed.
+func (t *Tracker) Returned(iter types.DocumentsIterator) types.DocumentsIterator {
+	return &countingIterator{
+		iter: iter,
+		n:    &t.returned,
+		exec: &t.exec,
+		close: func() {
+			t.once.Do(func() {
+				t.c.record(t.q, time.Duration(t.exec.Load()), t.examined.Load(), t.returned.Load())
+			})
+		},
+	}
+}
+
+// countingIterator counts documents returned by the underlying iterator.
+type countingIterator struct {
+	iter  types.DocumentsIterator
+	n     *atomic.Int64
+	exec  *atomic.Int64 // if not nil, time spent in Next is added there
+	close func()        // if not nil, called after the underlying iterator is closed
+}
+
+// Next implements iterator.Interface.
+func (iter *countingIterator) Next() (struct{}, *types.Document, error) {
+	var start time.Time
+	if iter.exec != nil {
+		start = time.Now()
+	}
+
+	k, v, err := iter.iter.Next()
+
+	if iter.exec != nil {
+		iter.exec.Add(int64(time.Since(start)))
+	}
+
+	if err == nil {
+		iter.n.Add(1)
+	}
+
+	return k, v, err
+}
+
+// Close implements iterator.Interface.
+func (iter *countingIterator) Close() {
+	iter.iter.Close()
+
+	if iter.close != nil {
+		iter.close()
+	}
+}
+
+// check interfaces
+var (
+	_ types.DocumentsIterator = (*countingIterator)(nil)
+	_ iterator.Closer         = (*countingIterator)(nil)
+)
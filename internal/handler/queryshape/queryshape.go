@@ -0,0 +1,87 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package queryshape provides query shape statistics collection.
+//
+// Queries that differ only in constant values have the same shape.
+// Execution statistics are aggregated per shape, see [Collector].
+package queryshape
+
+import (
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handler/handlerparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Shape returns a copy of the given document with literal values replaced by type placeholders
+// like "?number", "?string", or "?array".
+//
+// Field names, operators, field path strings (starting with "$"),
+// and arrays of documents (like $and, $or, or pipelines) are kept.
+// Nil document is returned as nil.
+func Shape(doc *types.Document) *types.Document {
+	if doc == nil {
+		return nil
+	}
+
+	keys, values := doc.Keys(), doc.Values()
+	res := types.MakeDocument(len(keys))
+
+	for i, k := range keys {
+		res.Set(k, shapeValue(values[i]))
+	}
+
+	return res
+}
+
+// shapeValue returns a shape of the given value.
+func shapeValue(v any) any {
+	switch v := v.(type) {
+	case *types.Document:
+		return Shape(v)
+
+	case *types.Array:
+		if v.Len() == 0 {
+			return "?array"
+		}
+
+		res := types.MakeArray(v.Len())
+
+		for i := 0; i < v.Len(); i++ {
+			d, ok := must.NotFail(v.Get(i)).(*types.Document)
+			if !ok {
+				return "?array"
+			}
+
+			res.Append(Shape(d))
+		}
+
+		return res
+
+	case float64, int32, int64:
+		return "?number"
+
+	case string:
+		if strings.HasPrefix(v, "$") {
+			return v
+		}
+
+		return "?string"
+
+	default:
+		return "?" + handlerparams.AliasFromType(v)
+	}
+}
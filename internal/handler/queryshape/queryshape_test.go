@@ -0,0 +1,134 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queryshape
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestShape(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, Shape(nil))
+
+	for name, tc := range map[string]struct {
+		doc      *types.Document
+		expected *types.Document
+	}{
+		"Literals": {
+			doc: must.NotFail(types.NewDocument(
+				"a", int32(1),
+				"b", "foo",
+				"c", true,
+				"d", types.Null,
+			)),
+			expected: must.NotFail(types.NewDocument(
+				"a", "?number",
+				"b", "?string",
+				"c", "?bool",
+				"d", "?null",
+			)),
+		},
+		"Operators": {
+			doc: must.NotFail(types.NewDocument(
+				"$or", must.NotFail(types.NewArray(
+					must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$gt", 42.0)))),
+					must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray(int64(1), "x")))))),
+				)),
+				"$expr", must.NotFail(types.NewDocument("$eq", must.NotFail(types.NewArray("$a", "$b")))),
+			)),
+			expected: must.NotFail(types.NewDocument(
+				"$or", must.NotFail(types.NewArray(
+					must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$gt", "?number")))),
+					must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$in", "?array")))),
+				)),
+				"$expr", must.NotFail(types.NewDocument("$eq", "?array")),
+			)),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := Shape(tc.doc)
+			testutil.AssertEqual(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+
+	c := NewCollector(2)
+
+	run := func(q *Query, examined, returned int) {
+		docs := make([]*types.Document, examined)
+		for i := range docs {
+			docs[i] = must.NotFail(types.NewDocument("v", int32(i)))
+		}
+
+		tracker := c.Track(q)
+
+		iter := tracker.Examined(iterator.Values(iterator.ForSlice(docs)))
+		iter = tracker.Returned(iter)
+
+		res, err := iterator.ConsumeValuesN(iter, returned)
+		require.NoError(t, err)
+		require.Len(t, res, returned)
+
+		iter.Close()
+		iter.Close()
+	}
+
+	q1 := func(v int32) *Query {
+		return &Query{
+			Command:    "find",
+			DB:         "db",
+			Collection: "c1",
+			Filter:     Shape(must.NotFail(types.NewDocument("v", v))),
+		}
+	}
+
+	run(q1(1), 5, 5)
+	run(q1(2), 3, 1)
+
+	stats := c.Stats()
+	require.Len(t, stats, 1)
+
+	s := stats[0]
+	assert.Equal(t, int64(2), s.ExecCount)
+	assert.Equal(t, Metric{Sum: 6, Min: 1, Max: 5}, s.DocsExamined) // iterators are lazy
+	assert.Equal(t, Metric{Sum: 6, Min: 1, Max: 5}, s.DocsReturned)
+	assert.False(t, s.FirstSeen.After(s.LastSeen))
+
+	run(&Query{Command: "find", DB: "db", Collection: "c2"}, 1, 1)
+	run(&Query{Command: "find", DB: "db", Collection: "c3"}, 1, 1)
+
+	stats = c.Stats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, int64(1), c.Evicted())
+
+	for _, s := range stats {
+		assert.NotEqual(t, "c1", s.Query.Collection)
+	}
+}
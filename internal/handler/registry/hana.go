@@ -49,6 +49,17 @@ func init() {
 			L:             opts.Logger.Named("hana"),
 			ConnMetrics:   opts.ConnMetrics,
 			StateProvider: opts.StateProvider,
+			Reloader:      opts.Reloader,
+
+			MongoDBVersion: opts.MongoDBVersion,
+			CompatProfile:  opts.CompatProfile,
+
+			OffloadStorage:   opts.OffloadStorage,
+			OffloadThreshold: opts.OffloadThreshold,
+
+			GridFSBuckets: opts.GridFSBuckets,
+
+			OperationTimeout: opts.OperationTimeout,
 
 			DisablePushdown:         opts.DisablePushdown,
 			CappedCleanupPercentage: opts.CappedCleanupPercentage,
@@ -56,6 +67,9 @@ func init() {
 			EnableNewAuth:           opts.EnableNewAuth,
 			BatchSize:               opts.BatchSize,
 			MaxBsonObjectSizeBytes:  opts.MaxBsonObjectSizeBytes,
+			SortMemoryLimitBytes:    opts.SortMemoryLimitBytes,
+			ExactCount:              opts.ExactCount,
+			Faults:                  opts.Faults,
 		}
 
 		h, err := handler.New(handlerOpts)
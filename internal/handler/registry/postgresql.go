@@ -29,6 +29,11 @@ func init() {
 			L:         opts.Logger.Named("postgresql"),
 			P:         opts.StateProvider,
 			BatchSize: opts.BatchSize,
+			SlowQuery: opts.PostgreSQLSlowQuery,
+			SQLViews:  opts.PostgreSQLSQLViews,
+
+			MinConns:     opts.PostgreSQLMinConns,
+			PingInterval: opts.PostgreSQLPingInterval,
 		})
 		if err != nil {
 			return nil, nil, err
@@ -47,6 +52,17 @@ func init() {
 			L:             opts.Logger.Named("postgresql"),
 			ConnMetrics:   opts.ConnMetrics,
 			StateProvider: opts.StateProvider,
+			Reloader:      opts.Reloader,
+
+			MongoDBVersion: opts.MongoDBVersion,
+			CompatProfile:  opts.CompatProfile,
+
+			OffloadStorage:   opts.OffloadStorage,
+			OffloadThreshold: opts.OffloadThreshold,
+
+			GridFSBuckets: opts.GridFSBuckets,
+
+			OperationTimeout: opts.OperationTimeout,
 
 			DisablePushdown:         opts.DisablePushdown,
 			EnableNestedPushdown:    opts.EnableNestedPushdown,
@@ -55,6 +71,9 @@ func init() {
 			EnableNewAuth:           opts.EnableNewAuth,
 			BatchSize:               opts.BatchSize,
 			MaxBsonObjectSizeBytes:  opts.MaxBsonObjectSizeBytes,
+			SortMemoryLimitBytes:    opts.SortMemoryLimitBytes,
+			ExactCount:              opts.ExactCount,
+			Faults:                  opts.Faults,
 		}
 
 		h, err := handler.New(handlerOpts)
@@ -21,9 +21,14 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/FerretDB/FerretDB/internal/backends/decorators/faults"
+	"github.com/FerretDB/FerretDB/internal/backends/decorators/offload"
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
 	"github.com/FerretDB/FerretDB/internal/handler"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/observability"
 	"github.com/FerretDB/FerretDB/internal/util/password"
+	"github.com/FerretDB/FerretDB/internal/util/reloader"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 )
 
@@ -45,6 +50,7 @@ type NewHandlerOpts struct {
 	Logger        *zap.Logger
 	ConnMetrics   *connmetrics.ConnMetrics
 	StateProvider *state.Provider
+	Reloader      *reloader.Reloader
 	TCPHost       string
 	ReplSetName   string
 	SetupDatabase string
@@ -52,8 +58,22 @@ type NewHandlerOpts struct {
 	SetupPassword password.Password
 	SetupTimeout  time.Duration
 
+	MongoDBVersion string
+	CompatProfile  handler.CompatProfile
+
+	OffloadStorage   offload.Storage
+	OffloadThreshold int
+
+	GridFSBuckets []string
+
+	OperationTimeout time.Duration
+
 	// for `postgresql` handler
-	PostgreSQLURL string
+	PostgreSQLURL          string
+	PostgreSQLSlowQuery    *observability.SlowQueryOpts
+	PostgreSQLSQLViews     bool
+	PostgreSQLMinConns     int32
+	PostgreSQLPingInterval time.Duration
 
 	// for `sqlite` handler
 	SQLiteURL string
@@ -78,6 +98,9 @@ type TestOpts struct {
 	EnableNewAuth           bool
 	BatchSize               int
 	MaxBsonObjectSizeBytes  int
+	SortMemoryLimitBytes    int
+	ExactCount              bool
+	Faults                  *faults.Injector
 	_                       struct{} // prevent unkeyed literals
 }
 
@@ -86,7 +109,7 @@ type TestOpts struct {
 // The caller is responsible to call CloseBackendFunc when the handler is no longer needed.
 func NewHandler(name string, opts *NewHandlerOpts) (*handler.Handler, CloseBackendFunc, error) {
 	if opts == nil {
-		return nil, nil, fmt.Errorf("opts is nil")
+		return nil, nil, lazyerrors.New("opts is nil")
 	}
 
 	// handle deprecated variant
@@ -96,6 +119,7 @@ func NewHandler(name string, opts *NewHandlerOpts) (*handler.Handler, CloseBacke
 
 	newHandler := registry[name]
 	if newHandler == nil {
+		//checkerrors:ignore // user-facing configuration error
 		return nil, nil, fmt.Errorf("unknown handler %q", name)
 	}
 
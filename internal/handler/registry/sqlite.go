@@ -19,6 +19,7 @@ package registry
 import (
 	"github.com/FerretDB/FerretDB/internal/backends/sqlite"
 	"github.com/FerretDB/FerretDB/internal/handler"
+	"github.com/FerretDB/FerretDB/internal/util/logging"
 )
 
 // init registers "sqlite" handler.
@@ -47,6 +48,17 @@ func init() {
 			L:             opts.Logger.Named("sqlite"),
 			ConnMetrics:   opts.ConnMetrics,
 			StateProvider: opts.StateProvider,
+			Reloader:      opts.Reloader,
+
+			MongoDBVersion: opts.MongoDBVersion,
+			CompatProfile:  opts.CompatProfile,
+
+			OffloadStorage:   opts.OffloadStorage,
+			OffloadThreshold: opts.OffloadThreshold,
+
+			GridFSBuckets: opts.GridFSBuckets,
+
+			OperationTimeout: opts.OperationTimeout,
 
 			DisablePushdown:         opts.DisablePushdown,
 			EnableNestedPushdown:    opts.EnableNestedPushdown,
@@ -55,6 +67,9 @@ func init() {
 			EnableNewAuth:           opts.EnableNewAuth,
 			BatchSize:               opts.BatchSize,
 			MaxBsonObjectSizeBytes:  opts.MaxBsonObjectSizeBytes,
+			SortMemoryLimitBytes:    opts.SortMemoryLimitBytes,
+			ExactCount:              opts.ExactCount,
+			Faults:                  opts.Faults,
 		}
 
 		h, err := handler.New(handlerOpts)
@@ -62,6 +77,15 @@ func init() {
 			return nil, b.Close, err
 		}
 
+		// SQLite has no authentication of its own
+		if !opts.EnableNewAuth {
+			logging.RecentEntries.AddStartupWarning(
+				handlerOpts.L,
+				"Access control is not enabled for the database. "+
+					"Read and write access to data and configuration is unrestricted.",
+			)
+		}
+
 		return h, b.Close, nil
 	}
 }
@@ -0,0 +1,258 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/handler/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// FerretDB is not sharded, but tools written for sharded clusters expect sharding commands to work
+// and read cluster metadata from the `config` database.
+// Sharding commands store emulated metadata there, with the FerretDB instance being the only shard.
+const (
+	// configDatabase is the name of the database that stores sharding metadata.
+	configDatabase = "config"
+
+	// shardID is the identifier of the only shard.
+	shardID = "shard0"
+)
+
+// shardDocument returns the description of the only shard, as stored in `config.shards`.
+//
+// If the TCP host is not configured or uses a random port (as for embedded setups and Unix sockets),
+// the local address of the current connection is used instead.
+func (h *Handler) shardDocument(ctx context.Context) *types.Document {
+	// That does not work for TLS-only setups, IPv6 addresses, etc.,
+	// see the same code in MsgHello.
+	host := h.TCPHost
+	if host == "" || strings.HasSuffix(host, ":0") {
+		host = conninfo.Get(ctx).Local
+	}
+
+	if strings.HasPrefix(host, ":") {
+		host = "localhost" + host
+	}
+
+	if h.ReplSetName != "" {
+		host = h.ReplSetName + "/" + host
+	}
+
+	return must.NotFail(types.NewDocument(
+		"_id", shardID,
+		"host", host,
+		"state", int32(1),
+	))
+}
+
+// configCollection returns the given collection of the `config` database.
+func (h *Handler) configCollection(name string) (backends.Collection, error) {
+	db, err := h.b.Database(configDatabase)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	c, err := db.Collection(name)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return c, nil
+}
+
+// configQuery returns documents of the given `config` database collection that match the filter.
+func (h *Handler) configQuery(ctx context.Context, name string, filter *types.Document) ([]*types.Document, error) {
+	c, err := h.configCollection(name)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	qr, err := c.Query(ctx, nil)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	defer qr.Iter.Close()
+
+	var res []*types.Document
+
+	for {
+		var doc *types.Document
+
+		_, doc, err = qr.Iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		var matches bool
+
+		if matches, err = common.FilterDocument(doc, filter, nil); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if matches {
+			res = append(res, doc)
+		}
+	}
+
+	return res, nil
+}
+
+// configGet returns the document with the given _id from the `config` database collection,
+// or nil if there is no such document.
+func (h *Handler) configGet(ctx context.Context, name string, id any) (*types.Document, error) {
+	docs, err := h.configQuery(ctx, name, must.NotFail(types.NewDocument("_id", id)))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	return docs[0], nil
+}
+
+// configSave inserts or replaces the document in the `config` database collection.
+func (h *Handler) configSave(ctx context.Context, name string, doc *types.Document) error {
+	existing, err := h.configGet(ctx, name, must.NotFail(doc.Get("_id")))
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	c, err := h.configCollection(name)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if existing != nil {
+		_, err = c.UpdateAll(ctx, &backends.UpdateAllParams{Docs: []*types.Document{doc}})
+	} else {
+		_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{doc}})
+	}
+
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// shardingEnable stores the emulated sharding metadata for the given database.
+func (h *Handler) shardingEnable(ctx context.Context, dbName string) error {
+	version, err := h.configGet(ctx, "version", int32(1))
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if version == nil {
+		version = must.NotFail(types.NewDocument(
+			"_id", int32(1),
+			"clusterId", types.NewObjectID(),
+		))
+
+		if err = h.configSave(ctx, "version", version); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if err = h.configSave(ctx, "shards", h.shardDocument(ctx)); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	db := must.NotFail(types.NewDocument(
+		"_id", dbName,
+		"primary", shardID,
+		"partitioned", true,
+	))
+
+	if err = h.configSave(ctx, "databases", db); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// shardingDrop removes the emulated sharding metadata of the dropped collection.
+// If collectionName is empty, the metadata of the whole database is removed.
+func (h *Handler) shardingDrop(ctx context.Context, dbName, collectionName string) error {
+	colls, err := h.configQuery(ctx, "collections", must.NotFail(types.NewDocument()))
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	var ids []any
+
+	for _, coll := range colls {
+		id, _ := coll.Get("_id")
+
+		ns, ok := id.(string)
+		if !ok {
+			continue
+		}
+
+		if ns == dbName+"."+collectionName || (collectionName == "" && strings.HasPrefix(ns, dbName+".")) {
+			ids = append(ids, ns)
+		}
+	}
+
+	if len(ids) > 0 {
+		var c backends.Collection
+		if c, err = h.configCollection("collections"); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if _, err = c.DeleteAll(ctx, &backends.DeleteAllParams{IDs: ids}); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if collectionName != "" {
+		return nil
+	}
+
+	db, err := h.configGet(ctx, "databases", dbName)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if db == nil {
+		return nil
+	}
+
+	c, err := h.configCollection("databases")
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if _, err = c.DeleteAll(ctx, &backends.DeleteAllParams{IDs: []any{dbName}}); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sjson
+
+import (
+	"testing"
+
+	"github.com/AlekSi/pointer"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+var decimal128TestCases = []testCase{{
+	name: "1.50",
+	v:    pointer.To(decimal128Type(types.MustParseDecimal128("1.50"))),
+	j:    `"1.50"`,
+}, {
+	name: "exponent",
+	v:    pointer.To(decimal128Type(types.MustParseDecimal128("1E+10"))),
+	j:    `"1E+10"`,
+}, {
+	name: "negative zero",
+	v:    pointer.To(decimal128Type(types.MustParseDecimal128("-0"))),
+	j:    `"-0"`,
+}, {
+	name: "NaN",
+	v:    pointer.To(decimal128Type(types.MustParseDecimal128("NaN"))),
+	j:    `"NaN"`,
+}, {
+	name: "Infinity",
+	v:    pointer.To(decimal128Type(types.MustParseDecimal128("Infinity"))),
+	j:    `"Infinity"`,
+}, {
+	name: "EOF",
+	j:    `{`,
+	jErr: `unexpected EOF`,
+}, {
+	name: "invalid",
+	j:    `"foo"`,
+	jErr: `types.ParseDecimal128: invalid value "foo"`,
+}}
+
+func TestDecimal128(t *testing.T) {
+	t.Parallel()
+	testJSON(t, decimal128TestCases, func() sjsontype { return new(decimal128Type) })
+}
+
+func FuzzDecimal128WithFixedSchemas(f *testing.F) {
+	fuzzJSONWithFixedSchemas(f, decimal128TestCases, func() sjsontype { return new(decimal128Type) })
+}
+
+func FuzzDecimal128WithFixedDocuments(f *testing.F) {
+	fuzzJSONWithFixedDocuments(f, decimal128TestCases, func() sjsontype { return new(decimal128Type) })
+}
+
+func BenchmarkDecimal128(b *testing.B) {
+	benchmark(b, decimal128TestCases, func() sjsontype { return new(decimal128Type) })
+}
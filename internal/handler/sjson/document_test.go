@@ -391,6 +391,7 @@ var (
 			"objectID", must.NotFail(types.NewArray(types.ObjectID{0x42}, types.ObjectID{})),
 			"string", must.NotFail(types.NewArray("foo", "")),
 			"timestamp", must.NotFail(types.NewArray(types.Timestamp(42), types.Timestamp(0))),
+			"decimal", must.NotFail(types.NewArray(types.MustParseDecimal128("1.50"), types.MustParseDecimal128("-Infinity"))),
 			"null", must.NotFail(types.NewArray(types.Null, types.Null)),
 		))),
 		sch: &elem{
@@ -408,10 +409,11 @@ var (
 					"objectID":  {Type: elemTypeArray, Items: []*elem{objectIDSchema, objectIDSchema}},
 					"string":    {Type: elemTypeArray, Items: []*elem{stringSchema, stringSchema}},
 					"timestamp": {Type: elemTypeArray, Items: []*elem{timestampSchema, timestampSchema}},
+					"decimal":   {Type: elemTypeArray, Items: []*elem{decimalSchema, decimalSchema}},
 					"null":      {Type: elemTypeArray, Items: []*elem{nullSchema, nullSchema}},
 				},
 				Keys: []string{
-					"binary", "bool", "datetime", "double", "int32", "int64", "objectID", "string", "timestamp", "null",
+					"binary", "bool", "datetime", "double", "int32", "int64", "objectID", "string", "timestamp", "decimal", "null",
 				},
 			},
 		},
@@ -420,7 +422,7 @@ var (
 			`"datetime":[1627378542123,-62135596800000],"double":[42.13,0],` +
 			`"int32":[42,0],"int64":[42,0],` +
 			`"objectID":["420000000000000000000000","000000000000000000000000"],` +
-			`"string":["foo",""],"timestamp":[42,0],"null":[null,null]}`,
+			`"string":["foo",""],"timestamp":[42,0],"decimal":["1.50","-Infinity"],"null":[null,null]}`,
 	}
 
 	eof = testCase{
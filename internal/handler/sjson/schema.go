@@ -60,6 +60,7 @@ const (
 	elemTypeInt       elemType = "int"
 	elemTypeTimestamp elemType = "timestamp"
 	elemTypeLong      elemType = "long"
+	elemTypeDecimal   elemType = "decimal"
 )
 
 // GetTypeOfValue returns sjson type of supported value.
@@ -93,6 +94,8 @@ func GetTypeOfValue(v any) string {
 		return string(elemTypeTimestamp)
 	case int64:
 		return string(elemTypeLong)
+	case types.Decimal128:
+		return string(elemTypeDecimal)
 	}
 
 	panic(fmt.Sprintf("Unexpected type: %T", v))
@@ -139,6 +142,9 @@ var (
 	longSchema = &elem{
 		Type: elemTypeLong,
 	}
+	decimalSchema = &elem{
+		Type: elemTypeDecimal,
+	}
 )
 
 // marshalSchemaForDoc makes schema for the given document based on its data.
@@ -278,6 +284,9 @@ func marshalElemForSingleValue(value any) ([]byte, error) {
 	case int64:
 		buf.WriteString(`{"t":"long"}`)
 
+	case types.Decimal128:
+		buf.WriteString(`{"t":"decimal"}`)
+
 	default:
 		panic(fmt.Sprintf("sjson.marshalElemForSingleValue: unknown type %[1]T (value %[1]q)", val))
 	}
@@ -219,6 +219,7 @@ func TestGetTypeOfValue(t *testing.T) {
 		{int32(42), "int"},
 		{types.Timestamp(1), "timestamp"},
 		{int64(42), "long"},
+		{types.Decimal128{}, "decimal"},
 	} {
 		actual := GetTypeOfValue(tc.input)
 		assert.Equal(t, tc.expected, actual)
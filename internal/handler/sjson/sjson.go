@@ -59,6 +59,7 @@
 //	int        int32            *sjson.int32Type      {"t":"int"}                            JSON number
 //	timestamp  types.Timestamp  *sjson.timestampType  {"t":"timestamp"}                      JSON number
 //	long       int64            *sjson.int64Type      {"t":"long"}                           JSON number
+//	decimal    types.Decimal128 *sjson.decimal128Type {"t":"decimal"}                        "<canonical string>"
 //
 //nolint:lll // for readability
 //nolint:dupword // false positive
@@ -134,6 +135,8 @@ func fromSJSON(v sjsontype) any {
 		return types.Timestamp(*v)
 	case *int64Type:
 		return int64(*v)
+	case *decimal128Type:
+		return types.Decimal128(*v)
 	}
 
 	panic(fmt.Sprintf("not reached: %T", v)) // for sumtype to work
@@ -168,6 +171,8 @@ func toSJSON(v any) sjsontype {
 		return pointer.To(timestampType(v))
 	case int64:
 		return pointer.To(int64Type(v))
+	case types.Decimal128:
+		return pointer.To(decimal128Type(v))
 	}
 
 	panic(fmt.Sprintf("not reached: %T", v)) // for sumtype to work
@@ -310,6 +315,10 @@ func unmarshalSingleValue(data json.RawMessage, sch *elem) (any, error) {
 		var l int64Type
 		err = l.UnmarshalJSON(data)
 		res = &l
+	case elemTypeDecimal:
+		var d decimal128Type
+		err = d.UnmarshalJSON(data)
+		res = &d
 	default:
 		return nil, lazyerrors.Errorf("sjson.unmarshalSingleValue: unhandled type %q", sch.Type)
 	}
@@ -430,6 +430,8 @@ func unmarshalJSON(v sjsontype, tc *testCase) error {
 		err = v.UnmarshalJSON([]byte(tc.j))
 	case *int64Type:
 		err = v.UnmarshalJSON([]byte(tc.j))
+	case *decimal128Type:
+		err = v.UnmarshalJSON([]byte(tc.j))
 	default:
 		panic(fmt.Sprintf("not reached: %T", v)) // for sumtype to work
 	}
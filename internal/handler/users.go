@@ -0,0 +1,55 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handler/users"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// CreateUser stores a new user without command validation.
+//
+// It is used by the embedded API.
+func (h *Handler) CreateUser(ctx context.Context, params *users.CreateUserParams) error {
+	return users.CreateUser(ctx, h.b, params)
+}
+
+// DropUser deletes the user from the given database.
+// It returns false if the user does not exist.
+func (h *Handler) DropUser(ctx context.Context, dbName, username string) (bool, error) {
+	adminDB, err := h.b.Database("admin")
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	coll, err := adminDB.Collection("system.users")
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	defer users.Lock()()
+
+	res, err := coll.DeleteAll(ctx, &backends.DeleteAllParams{
+		IDs: []any{dbName + "." + username},
+	})
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	return res.Deleted > 0, nil
+}
@@ -61,6 +61,8 @@ func CreateUser(ctx context.Context, b backends.Backend, params *CreateUserParam
 	db := must.NotFail(b.Database("admin"))
 	coll := must.NotFail(db.Collection("system.users"))
 
+	defer Lock()()
+
 	_, err = coll.InsertAll(ctx, &backends.InsertAllParams{
 		Docs: []*types.Document{saved},
 	})
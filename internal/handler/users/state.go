@@ -0,0 +1,157 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package users
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// mu serializes changes of stored users.
+var mu sync.Mutex
+
+// Lock locks stored users for changes made by this process and returns a function that unlocks them.
+//
+// It should be held while reading and then changing users,
+// so those changes are not lost when Replace deletes and inserts users of the same database.
+func Lock() (unlock func()) {
+	mu.Lock()
+
+	return mu.Unlock
+}
+
+// List returns stored documents of all users of the given database sorted by their IDs,
+// or of all databases if dbName is empty.
+func List(ctx context.Context, b backends.Backend, dbName string) ([]*types.Document, error) {
+	coll, err := usersCollection(b)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	qr, err := coll.Query(ctx, nil)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	defer qr.Iter.Close()
+
+	var res []*types.Document
+
+	for {
+		_, doc, err := qr.Iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if db, _ := doc.Get("db"); dbName != "" && db != dbName {
+			continue
+		}
+
+		res = append(res, doc)
+	}
+
+	// make the order stable for tools that compare or store the exported state
+	sortByID(res)
+
+	return res, nil
+}
+
+// Replace replaces all users of the given database, or of all databases if dbName is empty,
+// with the given user documents that should be already validated.
+//
+// Backends do not support transactions spanning multiple operations,
+// so users of that database are deleted and then inserted while the users lock is held.
+// Other user changes made by this process wait for the lock, so they are not lost;
+// users of other databases are not touched.
+// Previous users are restored if the new ones could not be inserted,
+// but other clients could observe missing users in the meantime.
+func Replace(ctx context.Context, b backends.Backend, dbName string, docs []*types.Document) error {
+	defer Lock()()
+
+	prev, err := List(ctx, b, dbName)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	coll, err := usersCollection(b)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if len(prev) > 0 {
+		ids := make([]any, len(prev))
+		for i, doc := range prev {
+			ids[i] = must.NotFail(doc.Get("_id"))
+		}
+
+		if _, err = coll.DeleteAll(ctx, &backends.DeleteAllParams{IDs: ids}); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if len(docs) == 0 {
+		return nil
+	}
+
+	_, err = coll.InsertAll(ctx, &backends.InsertAllParams{Docs: docs})
+	if err == nil {
+		return nil
+	}
+
+	if len(prev) > 0 {
+		// restore users even if the client disconnected
+		restoreCtx := context.WithoutCancel(ctx)
+		if _, restoreErr := coll.InsertAll(restoreCtx, &backends.InsertAllParams{Docs: prev}); restoreErr != nil {
+			return lazyerrors.Errorf("failed to restore users after %v: %w", err, restoreErr)
+		}
+	}
+
+	return err
+}
+
+// usersCollection returns the collection where users are stored.
+func usersCollection(b backends.Backend) (backends.Collection, error) {
+	db, err := b.Database("admin")
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return db.Collection("system.users")
+}
+
+// sortByID sorts user documents by their string IDs.
+func sortByID(docs []*types.Document) {
+	slices.SortFunc(docs, func(a, b *types.Document) int {
+		aID, _ := a.Get("_id")
+		bID, _ := b.Get("_id")
+		aStr, _ := aID.(string)
+		bStr, _ := bID.(string)
+
+		return strings.Compare(aStr, bStr)
+	})
+}
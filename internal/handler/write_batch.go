@@ -0,0 +1,462 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handler/handlererrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// errWriteBatchAborted is returned by writeBatch methods after ordered batch failed to flush.
+var errWriteBatchAborted = errors.New("write batch aborted")
+
+// writeBatchFailure represents a deferred change that the backend rejected.
+type writeBatchFailure struct {
+	cl    *handlererrors.Classification
+	index int // statement index
+}
+
+// writeBatchEntry represents a deferred change of a single document.
+type writeBatchEntry struct {
+	doc     *types.Document
+	index   int // index of the statement that changed the document
+	deleted bool
+}
+
+// writeBatch implements backends.Collection interface by deferring UpdateAll and DeleteAll calls
+// of a single write command and sending them to the wrapped collection in fewer round-trips.
+//
+// Deferred changes are flushed when their number reaches the limit,
+// before InsertAll, before a statement changes a document already changed by a previous statement,
+// and by an explicit flush call at the end of the command.
+// Query results reflect deferred changes, so statements see the effects of previous ones.
+//
+// It is not thread-safe; a new instance should be created for each command.
+type writeBatch struct {
+	backends.Collection
+
+	entries map[string]*writeBatchEntry // keyed by formatted _id
+	keys    []string                    // entries keys in the order of addition
+
+	failures []writeBatchFailure
+
+	limit   int
+	index   int
+	ordered bool
+	aborted bool
+}
+
+// newWriteBatch creates a new writeBatch for the given collection.
+//
+// Deferred changes are flushed when their number reaches limit.
+// If ordered is true, changes after the first failed one are discarded.
+func newWriteBatch(c backends.Collection, limit int, ordered bool) *writeBatch {
+	return &writeBatch{
+		Collection: c,
+		entries:    map[string]*writeBatchEntry{},
+		limit:      max(limit, 1),
+		ordered:    ordered,
+	}
+}
+
+// setIndex sets the index of the statement that the following changes belong to.
+func (wb *writeBatch) setIndex(index int) {
+	wb.index = index
+}
+
+// Query implements backends.Collection interface.
+func (wb *writeBatch) Query(ctx context.Context, params *backends.QueryParams) (*backends.QueryResult, error) {
+	if wb.aborted {
+		return nil, errWriteBatchAborted
+	}
+
+	res, err := wb.Collection.Query(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(wb.keys) == 0 {
+		return res, nil
+	}
+
+	return &backends.QueryResult{
+		Iter: &writeBatchIterator{
+			iter: res.Iter,
+			wb:   wb,
+			seen: map[string]struct{}{},
+		},
+	}, nil
+}
+
+// InsertAll implements backends.Collection interface.
+//
+// Deferred changes are flushed before inserting documents.
+func (wb *writeBatch) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) {
+	if err := wb.flushChanges(ctx); err != nil {
+		return nil, err
+	}
+
+	return wb.Collection.InsertAll(ctx, params)
+}
+
+// UpdateAll implements backends.Collection interface.
+//
+// Documents are not updated until the batch is flushed.
+func (wb *writeBatch) UpdateAll(ctx context.Context, params *backends.UpdateAllParams) (*backends.UpdateAllResult, error) {
+	if wb.aborted {
+		return nil, errWriteBatchAborted
+	}
+
+	ids := make([]any, len(params.Docs))
+	for i, doc := range params.Docs {
+		ids[i] = must.NotFail(doc.Get("_id"))
+	}
+
+	if err := wb.flushIfChanged(ctx, ids); err != nil {
+		return nil, err
+	}
+
+	for _, doc := range params.Docs {
+		wb.add(doc, false)
+	}
+
+	if err := wb.flushIfFull(ctx); err != nil {
+		return nil, err
+	}
+
+	return &backends.UpdateAllResult{Updated: int32(len(params.Docs))}, nil
+}
+
+// DeleteAll implements backends.Collection interface.
+//
+// Documents are not deleted until the batch is flushed.
+// Deletion by record IDs is not deferred.
+func (wb *writeBatch) DeleteAll(ctx context.Context, params *backends.DeleteAllParams) (*backends.DeleteAllResult, error) {
+	if params.IDs == nil {
+		if err := wb.flushChanges(ctx); err != nil {
+			return nil, err
+		}
+
+		return wb.Collection.DeleteAll(ctx, params)
+	}
+
+	if wb.aborted {
+		return nil, errWriteBatchAborted
+	}
+
+	if err := wb.flushIfChanged(ctx, params.IDs); err != nil {
+		return nil, err
+	}
+
+	var deleted int32
+
+	for _, id := range params.IDs {
+		if e := wb.entries[types.FormatAnyValue(id)]; e != nil && e.deleted {
+			continue
+		}
+
+		wb.add(must.NotFail(types.NewDocument("_id", id)), true)
+		deleted++
+	}
+
+	if err := wb.flushIfFull(ctx); err != nil {
+		return nil, err
+	}
+
+	return &backends.DeleteAllResult{Deleted: deleted}, nil
+}
+
+// add defers the change of the given document.
+//
+// Changes of the same document by the same statement are merged.
+func (wb *writeBatch) add(doc *types.Document, deleted bool) {
+	key := types.FormatAnyValue(must.NotFail(doc.Get("_id")))
+
+	e := wb.entries[key]
+	if e == nil {
+		e = new(writeBatchEntry)
+		wb.entries[key] = e
+		wb.keys = append(wb.keys, key)
+	}
+
+	// keep the updated document for deleted entries; only _id is used for them
+	if !deleted || e.doc == nil {
+		e.doc = doc
+	}
+
+	e.index = wb.index
+	e.deleted = deleted
+}
+
+// flushIfChanged flushes deferred changes if some of the documents with the given _id values
+// were changed by a previous statement.
+//
+// That way, changes of different statements are never merged,
+// and a rejected statement does not discard changes of previous ones.
+func (wb *writeBatch) flushIfChanged(ctx context.Context, ids []any) error {
+	for _, id := range ids {
+		if e := wb.entries[types.FormatAnyValue(id)]; e != nil && e.index != wb.index {
+			return wb.flushChanges(ctx)
+		}
+	}
+
+	return nil
+}
+
+// flushIfFull flushes deferred changes if their number reached the limit.
+func (wb *writeBatch) flushIfFull(ctx context.Context) error {
+	if len(wb.keys) < wb.limit {
+		return nil
+	}
+
+	return wb.flushChanges(ctx)
+}
+
+// flushChanges sends deferred changes to the wrapped collection.
+//
+// Rejected changes are recorded as failures.
+// If the batch is ordered and some change was rejected, it returns errWriteBatchAborted.
+// Other errors are fatal.
+func (wb *writeBatch) flushChanges(ctx context.Context) error {
+	if wb.aborted {
+		return errWriteBatchAborted
+	}
+
+	if len(wb.keys) == 0 {
+		return nil
+	}
+
+	entries := make([]*writeBatchEntry, len(wb.keys))
+	for i, key := range wb.keys {
+		entries[i] = wb.entries[key]
+	}
+
+	clear(wb.entries)
+	wb.keys = wb.keys[:0]
+
+	err := wb.write(ctx, entries)
+	if err == nil {
+		return nil
+	}
+
+	if cl := handlererrors.ClassifyError(err, nil); cl == nil || cl.Kind != handlererrors.ErrorKindWrite {
+		return lazyerrors.Error(err)
+	}
+
+	// write changes one by one upon failing on batch write to attribute failures to statements
+	slices.SortStableFunc(entries, func(a, b *writeBatchEntry) int {
+		return cmp.Compare(a.index, b.index)
+	})
+
+	for _, e := range entries {
+		if err = wb.write(ctx, []*writeBatchEntry{e}); err == nil {
+			continue
+		}
+
+		cl := handlererrors.ClassifyError(err, e.doc)
+		if cl == nil || cl.Kind != handlererrors.ErrorKindWrite {
+			return lazyerrors.Error(err)
+		}
+
+		wb.failures = append(wb.failures, writeBatchFailure{cl: cl, index: e.index})
+
+		if wb.ordered {
+			wb.aborted = true
+			return errWriteBatchAborted
+		}
+	}
+
+	return nil
+}
+
+// write updates and deletes documents of the given entries using at most two backend calls.
+func (wb *writeBatch) write(ctx context.Context, entries []*writeBatchEntry) error {
+	var docs []*types.Document
+	var ids []any
+
+	for _, e := range entries {
+		if e.deleted {
+			ids = append(ids, must.NotFail(e.doc.Get("_id")))
+			continue
+		}
+
+		docs = append(docs, e.doc)
+	}
+
+	if len(docs) > 0 {
+		if _, err := wb.Collection.UpdateAll(ctx, &backends.UpdateAllParams{Docs: docs}); err != nil {
+			return err
+		}
+	}
+
+	if len(ids) > 0 {
+		if _, err := wb.Collection.DeleteAll(ctx, &backends.DeleteAllParams{IDs: ids}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flush sends all remaining deferred changes to the wrapped collection.
+//
+// It returns failures of all flushes ordered by statement index.
+// In ordered mode, at most one failure is returned.
+// The returned error is fatal.
+func (wb *writeBatch) flush(ctx context.Context) ([]writeBatchFailure, error) {
+	if err := wb.flushChanges(ctx); err != nil && !errors.Is(err, errWriteBatchAborted) {
+		return nil, lazyerrors.Error(err)
+	}
+
+	slices.SortStableFunc(wb.failures, func(a, b writeBatchFailure) int {
+		return cmp.Compare(a.index, b.index)
+	})
+
+	return wb.failures, nil
+}
+
+// writeBatchIterator applies deferred changes of writeBatch to the documents of the wrapped iterator.
+//
+// Deleted documents are skipped, updated documents are replaced by their copies.
+// Updated documents not returned by the wrapped iterator (for example, because they matched
+// the pushed down filter only after the update) are returned at the end.
+type writeBatchIterator struct {
+	iter types.DocumentsIterator
+	wb   *writeBatch
+	seen map[string]struct{}
+	rest []string
+	done bool
+}
+
+// Next implements iterator.Interface.
+func (iter *writeBatchIterator) Next() (struct{}, *types.Document, error) {
+	var unused struct{}
+
+	for !iter.done {
+		_, doc, err := iter.iter.Next()
+		if err != nil {
+			if !errors.Is(err, iterator.ErrIteratorDone) {
+				return unused, nil, lazyerrors.Error(err)
+			}
+
+			iter.done = true
+			iter.rest = slices.Clone(iter.wb.keys)
+
+			break
+		}
+
+		key := types.FormatAnyValue(must.NotFail(doc.Get("_id")))
+		iter.seen[key] = struct{}{}
+
+		e := iter.wb.entries[key]
+		if e == nil {
+			return unused, doc, nil
+		}
+
+		if !e.deleted {
+			return unused, e.doc.DeepCopy(), nil
+		}
+	}
+
+	for len(iter.rest) > 0 {
+		key := iter.rest[0]
+		iter.rest = iter.rest[1:]
+
+		if _, ok := iter.seen[key]; ok {
+			continue
+		}
+
+		if e := iter.wb.entries[key]; e != nil && !e.deleted {
+			return unused, e.doc.DeepCopy(), nil
+		}
+	}
+
+	return unused, nil, iterator.ErrIteratorDone
+}
+
+// Close implements iterator.Interface.
+func (iter *writeBatchIterator) Close() {
+	iter.iter.Close()
+}
+
+// check interfaces
+var (
+	_ backends.Collection     = (*writeBatch)(nil)
+	_ types.DocumentsIterator = (*writeBatchIterator)(nil)
+)
+
+// statementError represents a write or command error of a single statement of the write command.
+type statementError struct {
+	err   error
+	index int
+}
+
+// writeErrorsArray returns the writeErrors array for the given statement errors and batch failures,
+// ordered by statement index, and the index of the first error.
+//
+// Failures are reported as duplicate key errors for the given namespace.
+// In ordered mode, only the first error is returned.
+// If there are no errors, it returns nil and -1.
+func writeErrorsArray(db, coll string, errs []statementError, failures []writeBatchFailure, ordered bool) (*types.Array, int) {
+	var docs []*types.Document
+
+	for _, se := range errs {
+		var we handlererrors.WriteErrors
+
+		var writeErrs *handlererrors.WriteErrors
+		if errors.As(se.err, &writeErrs) {
+			we.Merge(writeErrs, int32(se.index))
+		} else {
+			we.Append(se.err, int32(se.index))
+		}
+
+		arr := must.NotFail(we.Document().Get("writeErrors")).(*types.Array)
+		for i := 0; i < arr.Len(); i++ {
+			docs = append(docs, must.NotFail(arr.Get(i)).(*types.Document))
+		}
+	}
+
+	for _, f := range failures {
+		docs = append(docs, f.cl.WriteErrorDocument(int32(f.index), f.cl.DuplicateKeyMessage(db, coll)))
+	}
+
+	if len(docs) == 0 {
+		return nil, -1
+	}
+
+	slices.SortStableFunc(docs, func(a, b *types.Document) int {
+		return cmp.Compare(must.NotFail(a.Get("index")).(int32), must.NotFail(b.Get("index")).(int32))
+	})
+
+	if ordered {
+		docs = docs[:1]
+	}
+
+	res := types.MakeArray(len(docs))
+	for _, doc := range docs {
+		res.Append(doc)
+	}
+
+	return res, int(must.NotFail(docs[0].Get("index")).(int32))
+}
@@ -0,0 +1,252 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// memoryCollection is a minimal in-memory backends.Collection that counts write calls.
+type memoryCollection struct {
+	backends.Collection
+
+	docs    []*types.Document
+	updates int
+	deletes int
+	dupID   any // UpdateAll fails if it contains a document with that _id
+	dupV    any // UpdateAll fails if it contains a document with that v field value
+}
+
+// Query implements backends.Collection interface.
+func (c *memoryCollection) Query(context.Context, *backends.QueryParams) (*backends.QueryResult, error) {
+	docs := make([]*types.Document, len(c.docs))
+	for i, doc := range c.docs {
+		docs[i] = doc.DeepCopy()
+	}
+
+	return &backends.QueryResult{Iter: iterator.Values(iterator.ForSlice(docs))}, nil
+}
+
+// UpdateAll implements backends.Collection interface.
+func (c *memoryCollection) UpdateAll(_ context.Context, params *backends.UpdateAllParams) (*backends.UpdateAllResult, error) {
+	c.updates++
+
+	for _, doc := range params.Docs {
+		if c.dupID != nil && must.NotFail(doc.Get("_id")) == c.dupID {
+			return nil, backends.NewError(backends.ErrorCodeInsertDuplicateID, nil)
+		}
+
+		if c.dupV != nil && must.NotFail(doc.Get("v")) == c.dupV {
+			return nil, backends.NewError(backends.ErrorCodeInsertDuplicateID, nil)
+		}
+	}
+
+	for _, doc := range params.Docs {
+		for i, d := range c.docs {
+			if must.NotFail(d.Get("_id")) == must.NotFail(doc.Get("_id")) {
+				c.docs[i] = doc
+			}
+		}
+	}
+
+	return &backends.UpdateAllResult{Updated: int32(len(params.Docs))}, nil
+}
+
+// DeleteAll implements backends.Collection interface.
+func (c *memoryCollection) DeleteAll(_ context.Context, params *backends.DeleteAllParams) (*backends.DeleteAllResult, error) {
+	c.deletes++
+
+	var deleted int32
+
+	for _, id := range params.IDs {
+		for i, d := range c.docs {
+			if must.NotFail(d.Get("_id")) == id {
+				c.docs = append(c.docs[:i], c.docs[i+1:]...)
+				deleted++
+
+				break
+			}
+		}
+	}
+
+	return &backends.DeleteAllResult{Deleted: deleted}, nil
+}
+
+// queryIDs returns _id values of all documents returned by Query.
+func queryIDs(t *testing.T, c backends.Collection) []any {
+	t.Helper()
+
+	res, err := c.Query(context.Background(), nil)
+	require.NoError(t, err)
+
+	docs, err := iterator.ConsumeValues(res.Iter)
+	require.NoError(t, err)
+
+	ids := make([]any, len(docs))
+	for i, doc := range docs {
+		ids[i] = must.NotFail(doc.Get("_id"))
+	}
+
+	return ids
+}
+
+func TestWriteBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	newColl := func() *memoryCollection {
+		return &memoryCollection{
+			docs: []*types.Document{
+				must.NotFail(types.NewDocument("_id", int32(1), "v", "a")),
+				must.NotFail(types.NewDocument("_id", int32(2), "v", "b")),
+				must.NotFail(types.NewDocument("_id", int32(3), "v", "c")),
+			},
+		}
+	}
+
+	t.Run("Deferred", func(t *testing.T) {
+		t.Parallel()
+
+		c := newColl()
+		wb := newWriteBatch(c, 10, true)
+
+		wb.setIndex(0)
+		_, err := wb.UpdateAll(ctx, &backends.UpdateAllParams{
+			Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(1), "v", "x"))},
+		})
+		require.NoError(t, err)
+
+		wb.setIndex(1)
+		res, err := wb.DeleteAll(ctx, &backends.DeleteAllParams{IDs: []any{int32(2), int32(2)}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), res.Deleted)
+
+		assert.Equal(t, 0, c.updates)
+		assert.Equal(t, 0, c.deletes)
+		assert.Equal(t, []any{int32(1), int32(3)}, queryIDs(t, wb))
+
+		failures, err := wb.flush(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, failures)
+
+		assert.Equal(t, 1, c.updates)
+		assert.Equal(t, 1, c.deletes)
+		assert.Equal(t, []any{int32(1), int32(3)}, queryIDs(t, c))
+		assert.Equal(t, "x", must.NotFail(c.docs[0].Get("v")))
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		t.Parallel()
+
+		c := newColl()
+		wb := newWriteBatch(c, 2, true)
+
+		for i, id := range []int32{1, 2, 3} {
+			wb.setIndex(i)
+			_, err := wb.UpdateAll(ctx, &backends.UpdateAllParams{
+				Docs: []*types.Document{must.NotFail(types.NewDocument("_id", id, "v", "x"))},
+			})
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, 1, c.updates)
+
+		_, err := wb.flush(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, c.updates)
+	})
+
+	t.Run("Unordered", func(t *testing.T) {
+		t.Parallel()
+
+		c := newColl()
+		c.dupID = int32(2)
+		wb := newWriteBatch(c, 10, false)
+
+		for i, id := range []int32{1, 2, 3} {
+			wb.setIndex(i)
+			_, err := wb.UpdateAll(ctx, &backends.UpdateAllParams{
+				Docs: []*types.Document{must.NotFail(types.NewDocument("_id", id, "v", "x"))},
+			})
+			require.NoError(t, err)
+		}
+
+		failures, err := wb.flush(ctx)
+		require.NoError(t, err)
+		require.Len(t, failures, 1)
+		assert.Equal(t, 1, failures[0].index)
+		assert.Equal(t, "x", must.NotFail(c.docs[2].Get("v")))
+	})
+
+	t.Run("Ordered", func(t *testing.T) {
+		t.Parallel()
+
+		c := newColl()
+		c.dupID = int32(2)
+		wb := newWriteBatch(c, 10, true)
+
+		for i, id := range []int32{1, 2, 3} {
+			wb.setIndex(i)
+			_, err := wb.UpdateAll(ctx, &backends.UpdateAllParams{
+				Docs: []*types.Document{must.NotFail(types.NewDocument("_id", id, "v", "x"))},
+			})
+			require.NoError(t, err)
+		}
+
+		failures, err := wb.flush(ctx)
+		require.NoError(t, err)
+		require.Len(t, failures, 1)
+		assert.Equal(t, 1, failures[0].index)
+		assert.Equal(t, "x", must.NotFail(c.docs[0].Get("v")))
+		assert.Equal(t, "c", must.NotFail(c.docs[2].Get("v")))
+
+		_, err = wb.UpdateAll(ctx, &backends.UpdateAllParams{
+			Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(1), "v", "y"))},
+		})
+		assert.ErrorIs(t, err, errWriteBatchAborted)
+	})
+
+	t.Run("SameDocument", func(t *testing.T) {
+		t.Parallel()
+
+		c := newColl()
+		c.dupV = "dup"
+		wb := newWriteBatch(c, 10, false)
+
+		for i, v := range []string{"x", "dup"} {
+			wb.setIndex(i)
+			_, err := wb.UpdateAll(ctx, &backends.UpdateAllParams{
+				Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(1), "v", v))},
+			})
+			require.NoError(t, err)
+		}
+
+		failures, err := wb.flush(ctx)
+		require.NoError(t, err)
+		require.Len(t, failures, 1)
+		assert.Equal(t, 1, failures[0].index)
+		assert.Equal(t, "x", must.NotFail(c.docs[0].Get("v")))
+	})
+}
@@ -149,6 +149,11 @@ func (a *Array) RemoveByPath(path Path) {
 
 // Min returns the minimum value from the array.
 func (a *Array) Min() any {
+	return a.min(nil)
+}
+
+// min returns the minimum value from the array using the given collation.
+func (a *Array) min(c *Collation) any {
 	if a == nil || a.Len() == 0 {
 		panic("cannot get Min value; array is nil or empty")
 	}
@@ -156,7 +161,7 @@ func (a *Array) Min() any {
 	min := must.NotFail(a.Get(0))
 	for i := 1; i < a.Len(); i++ {
 		value := must.NotFail(a.Get(i))
-		if CompareOrder(min, value, Ascending) == Greater {
+		if compareOrder(min, value, Ascending, c) == Greater {
 			min = value
 		}
 	}
@@ -166,6 +171,11 @@ func (a *Array) Min() any {
 
 // Max returns the maximum value from the array.
 func (a *Array) Max() any {
+	return a.max(nil)
+}
+
+// max returns the maximum value from the array using the given collation.
+func (a *Array) max(c *Collation) any {
 	if a == nil || a.Len() == 0 {
 		panic("cannot get Max value; array is nil or empty")
 	}
@@ -173,7 +183,7 @@ func (a *Array) Max() any {
 	max := must.NotFail(a.Get(0))
 	for i := 1; i < a.Len(); i++ {
 		value := must.NotFail(a.Get(i))
-		if CompareOrder(max, value, Ascending) == Less {
+		if compareOrder(max, value, Ascending, c) == Less {
 			max = value
 		}
 	}
@@ -229,7 +239,7 @@ func (a *Array) Contains(filterValue any) bool {
 			case *Document, *Array:
 				// we need elem and filterValue to be exactly equal, so we do nothing here
 			default:
-				if compareScalars(elem, filterValue) == Equal {
+				if compareScalars(elem, filterValue, nil) == Equal {
 					return true
 				}
 			}
@@ -0,0 +1,229 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// Collation represents locale-specific rules for string comparison
+// specified by the collation document like {locale: "en", strength: 2}.
+//
+// It is backed by the Unicode Collation Algorithm implementation with CLDR (ICU) locale data.
+//
+// Nil *Collation is valid and represents the "simple" collation (binary comparison of strings).
+// That allows to use its methods without checks.
+//
+// Collation is safe for concurrent use.
+type Collation struct {
+	m        sync.Mutex // protects collator that is not safe for concurrent use
+	collator *collate.Collator
+	locale   string
+}
+
+// NewCollation creates a new Collation from the collation document.
+//
+// It returns nil for nil document and for the "simple" locale.
+// Fields with unsupported non-default values result in an error.
+func NewCollation(doc *Document) (*Collation, error) {
+	if doc == nil {
+		return nil, nil
+	}
+
+	locale := "simple"
+	strength := int32(3)
+
+	var caseLevel, numericOrdering, backwards, shifted bool
+
+	for _, f := range doc.fields {
+		var ok bool
+
+		switch f.key {
+		case "locale":
+			locale, ok = f.value.(string)
+
+		case "strength":
+			strength, ok = collationInt(f.value)
+			ok = ok && strength >= 1 && strength <= 5
+
+		case "caseLevel":
+			caseLevel, ok = f.value.(bool)
+
+		case "numericOrdering":
+			numericOrdering, ok = f.value.(bool)
+
+		case "backwards":
+			backwards, ok = f.value.(bool)
+
+		case "normalization":
+			// the implementation always normalizes strings
+			_, ok = f.value.(bool)
+
+		case "alternate":
+			var v string
+			v, ok = f.value.(string)
+			ok = ok && (v == "non-ignorable" || v == "shifted")
+			shifted = v == "shifted"
+
+		case "caseFirst":
+			var v string
+			v, ok = f.value.(string)
+
+			if ok && v != "off" {
+				return nil, fmt.Errorf("types.NewCollation: caseFirst %q is not supported", v)
+			}
+
+		case "maxVariable":
+			var v string
+			v, ok = f.value.(string)
+
+			if ok && v != "punct" {
+				return nil, fmt.Errorf("types.NewCollation: maxVariable %q is not supported", v)
+			}
+
+		case "version":
+			_, ok = f.value.(string)
+
+		default:
+			return nil, fmt.Errorf("types.NewCollation: unknown field %q", f.key)
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("types.NewCollation: invalid value for field %q: %s", f.key, FormatAnyValue(f.value))
+		}
+	}
+
+	if !doc.Has("locale") {
+		return nil, errors.New("types.NewCollation: missing required field \"locale\"")
+	}
+
+	if locale == "simple" {
+		if doc.Len() > 1 {
+			return nil, errors.New(`types.NewCollation: "simple" locale must not be combined with other fields`)
+		}
+
+		return nil, nil
+	}
+
+	tag, err := language.Parse(strings.ReplaceAll(locale, "_", "-"))
+	if err != nil {
+		return nil, fmt.Errorf("types.NewCollation: invalid locale %q: %w", locale, err)
+	}
+
+	// collator options are passed as BCP 47 Unicode extension keys
+	ext := []string{
+		"ks-" + [...]string{"level1", "level2", "level3", "level4", "identic"}[strength-1],
+	}
+
+	if caseLevel {
+		ext = append(ext, "kc-true")
+	}
+
+	if numericOrdering {
+		ext = append(ext, "kn-true")
+	}
+
+	if backwards {
+		ext = append(ext, "kb-true")
+	}
+
+	if shifted {
+		ext = append(ext, "ka-shifted")
+	}
+
+	u, err := language.ParseExtension("u-" + strings.Join(ext, "-"))
+	if err != nil {
+		return nil, fmt.Errorf("types.NewCollation: %w", err)
+	}
+
+	if tag, err = language.Compose(tag, u); err != nil {
+		return nil, fmt.Errorf("types.NewCollation: %w", err)
+	}
+
+	return &Collation{
+		collator: collate.New(tag),
+		locale:   locale,
+	}, nil
+}
+
+// collationInt returns the whole number value of the collation document field.
+func collationInt(v any) (int32, bool) {
+	switch v := v.(type) {
+	case float64:
+		return int32(v), float64(int32(v)) == v
+	case int32:
+		return v, true
+	case int64:
+		return int32(v), int64(int32(v)) == v
+	default:
+		return 0, false
+	}
+}
+
+// Locale returns the collation locale, or "simple" for nil Collation.
+func (c *Collation) Locale() string {
+	if c == nil {
+		return "simple"
+	}
+
+	return c.locale
+}
+
+// CompareStrings compares two strings according to the collation rules.
+func (c *Collation) CompareStrings(a, b string) CompareResult {
+	if c == nil {
+		return compareOrdered(a, b)
+	}
+
+	c.m.Lock()
+	res := c.collator.CompareString(a, b)
+	c.m.Unlock()
+
+	return CompareResult(res)
+}
+
+// Compare is a variant of the [Compare] function that compares strings according to the collation rules.
+func (c *Collation) Compare(docValue, filterValue any) CompareResult {
+	return compare(docValue, filterValue, c)
+}
+
+// CompareForAggregation is a variant of the [CompareForAggregation] function
+// that compares strings according to the collation rules.
+func (c *Collation) CompareForAggregation(docValue, filterValue any) CompareResult {
+	return compareForAggregation(docValue, filterValue, c)
+}
+
+// CompareOrder is a variant of the [CompareOrder] function that compares strings according to the collation rules.
+func (c *Collation) CompareOrder(a, b any, order SortType) CompareResult {
+	return compareOrder(a, b, order, c)
+}
+
+// CompareOrderForSort is a variant of the [CompareOrderForSort] function
+// that compares strings according to the collation rules.
+func (c *Collation) CompareOrderForSort(a, b any, order SortType) CompareResult {
+	return compareOrderForSort(a, b, order, c)
+}
+
+// CompareOrderForOperator is a variant of the [CompareOrderForOperator] function
+// that compares strings according to the collation rules.
+func (c *Collation) CompareOrderForOperator(a, b any, order SortType) CompareResult {
+	return compareOrderForOperator(a, b, order, c)
+}
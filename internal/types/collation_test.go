@@ -0,0 +1,196 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestNewCollation(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		doc    *Document
+		locale string
+		err    string
+	}{
+		"Nil": {
+			locale: "simple",
+		},
+		"Simple": {
+			doc:    must.NotFail(NewDocument("locale", "simple")),
+			locale: "simple",
+		},
+		"Full": {
+			doc: must.NotFail(NewDocument(
+				"locale", "en_US",
+				"caseLevel", false,
+				"caseFirst", "off",
+				"strength", int32(2),
+				"numericOrdering", true,
+				"alternate", "non-ignorable",
+				"maxVariable", "punct",
+				"normalization", false,
+				"backwards", false,
+				"version", "57.1",
+			)),
+			locale: "en_US",
+		},
+		"StrengthDouble": {
+			doc:    must.NotFail(NewDocument("locale", "fr", "strength", float64(1))),
+			locale: "fr",
+		},
+		"MissingLocale": {
+			doc: must.NotFail(NewDocument("strength", int32(1))),
+			err: `types.NewCollation: missing required field "locale"`,
+		},
+		"InvalidLocale": {
+			doc: must.NotFail(NewDocument("locale", "not a locale")),
+			err: `types.NewCollation: invalid locale "not a locale": language: tag is not well-formed`,
+		},
+		"InvalidStrength": {
+			doc: must.NotFail(NewDocument("locale", "en", "strength", int32(6))),
+			err: `types.NewCollation: invalid value for field "strength": 6`,
+		},
+		"InvalidType": {
+			doc: must.NotFail(NewDocument("locale", int32(1))),
+			err: `types.NewCollation: invalid value for field "locale": 1`,
+		},
+		"UnknownField": {
+			doc: must.NotFail(NewDocument("locale", "en", "foo", "bar")),
+			err: `types.NewCollation: unknown field "foo"`,
+		},
+		"SimpleWithOptions": {
+			doc: must.NotFail(NewDocument("locale", "simple", "strength", int32(1))),
+			err: `types.NewCollation: "simple" locale must not be combined with other fields`,
+		},
+		"CaseFirst": {
+			doc: must.NotFail(NewDocument("locale", "en", "caseFirst", "upper")),
+			err: `types.NewCollation: caseFirst "upper" is not supported`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := NewCollation(tc.doc)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.locale, c.Locale())
+		})
+	}
+}
+
+func TestCollationCompare(t *testing.T) {
+	t.Parallel()
+
+	newCollation := func(pairs ...any) *Collation {
+		return must.NotFail(NewCollation(must.NotFail(NewDocument(pairs...))))
+	}
+
+	var simple *Collation
+	en := newCollation("locale", "en")
+	enSecondary := newCollation("locale", "en", "strength", int32(2))
+	enPrimary := newCollation("locale", "en", "strength", int32(1))
+	enNumeric := newCollation("locale", "en", "numericOrdering", true)
+	sv := newCollation("locale", "sv")
+
+	for name, tc := range map[string]struct {
+		c        *Collation
+		a, b     string
+		expected CompareResult
+	}{
+		"SimpleCase":        {c: simple, a: "a", b: "B", expected: Greater},
+		"EnCase":            {c: en, a: "a", b: "B", expected: Less},
+		"EnCaseTertiary":    {c: en, a: "a", b: "A", expected: Less},
+		"EnCaseSecondary":   {c: enSecondary, a: "a", b: "A", expected: Equal},
+		"EnAccentSecondary": {c: enSecondary, a: "e", b: "é", expected: Less},
+		"EnAccentPrimary":   {c: enPrimary, a: "E", b: "é", expected: Equal},
+		"SimpleNumbers":     {c: simple, a: "10", b: "9", expected: Less},
+		"EnNumericOrdering": {c: enNumeric, a: "10", b: "9", expected: Greater},
+		"EnLocaleOrder":     {c: en, a: "ä", b: "z", expected: Less},
+		"SvLocaleOrder":     {c: sv, a: "ä", b: "z", expected: Greater},
+		"EnEqual":           {c: en, a: "foo", b: "foo", expected: Equal},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, tc.c.CompareStrings(tc.a, tc.b))
+			assert.Equal(t, tc.expected, tc.c.Compare(tc.a, tc.b))
+		})
+	}
+
+	t.Run("Composite", func(t *testing.T) {
+		t.Parallel()
+
+		a := must.NotFail(NewDocument("v", must.NotFail(NewArray("B", "c"))))
+		b := must.NotFail(NewDocument("v", must.NotFail(NewArray("b", "C"))))
+
+		assert.Equal(t, Less, Compare(a, b))
+		assert.Equal(t, Equal, enSecondary.Compare(a, b))
+		assert.Equal(t, Equal, enSecondary.CompareForAggregation(a, b))
+
+		// field names are compared without collation
+		assert.Equal(t, Less, enSecondary.Compare(
+			must.NotFail(NewDocument("V", "a")),
+			must.NotFail(NewDocument("v", "a")),
+		))
+	})
+
+	t.Run("Sort", func(t *testing.T) {
+		t.Parallel()
+
+		// the minimum element of the array is selected using collation
+		arr := must.NotFail(NewArray("a", "B"))
+
+		assert.Equal(t, Greater, CompareOrderForSort(arr, "Aa", Ascending))
+		assert.Equal(t, Less, en.CompareOrderForSort(arr, "Aa", Ascending))
+
+		// the result is inverted for descending sort
+		assert.Equal(t, Less, CompareOrderForSort(arr, "C", Descending))
+		assert.Equal(t, Greater, en.CompareOrderForSort(arr, "C", Descending))
+
+		assert.Equal(t, Less, en.CompareOrder("a", "B", Ascending))
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		var wg sync.WaitGroup
+
+		for range 10 {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for range 100 {
+					assert.Equal(t, Equal, enSecondary.CompareStrings("Foo", "foo"))
+				}
+			}()
+		}
+
+		wg.Wait()
+	})
+}
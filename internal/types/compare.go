@@ -45,20 +45,25 @@ const (
 //
 // Compare and contrast with test helpers in testutil package.
 func Compare(docValue, filterValue any) CompareResult {
+	return compare(docValue, filterValue, nil)
+}
+
+// compare implements Compare and Collation.Compare.
+func compare(docValue, filterValue any, c *Collation) CompareResult {
 	assertType(docValue)
 	assertType(filterValue)
 
 	switch docValue := docValue.(type) {
 	case *Document:
 		if filterDoc, ok := filterValue.(*Document); ok {
-			return compareDocuments(docValue, filterDoc)
+			return compareDocuments(docValue, filterDoc, c)
 		}
 
 		return compareTypeOrder(docValue, filterValue)
 	case *Array:
-		return compareArray(docValue, filterValue)
+		return compareArray(docValue, filterValue, c)
 	default:
-		return compareScalars(docValue, filterValue)
+		return compareScalars(docValue, filterValue, c)
 	}
 }
 
@@ -66,29 +71,36 @@ func Compare(docValue, filterValue any) CompareResult {
 // an array and non array would not result in Equal.
 // This is specially used for aggregation grouping comparison.
 func CompareForAggregation(docValue, filterValue any) CompareResult {
+	return compareForAggregation(docValue, filterValue, nil)
+}
+
+// compareForAggregation implements CompareForAggregation and Collation.CompareForAggregation.
+func compareForAggregation(docValue, filterValue any, c *Collation) CompareResult {
 	assertType(docValue)
 	assertType(filterValue)
 
 	switch docValue := docValue.(type) {
 	case *Document:
 		if filterDoc, ok := filterValue.(*Document); ok {
-			return compareDocuments(docValue, filterDoc)
+			return compareDocuments(docValue, filterDoc, c)
 		}
 
 		return compareTypeOrder(docValue, filterValue)
 	case *Array:
 		if filterDoc, ok := filterValue.(*Array); ok {
-			return compareArrays(docValue, filterDoc)
+			return compareArrays(docValue, filterDoc, c)
 		}
 
 		return compareTypeOrder(docValue, filterValue)
 	default:
-		return compareScalars(docValue, filterValue)
+		return compareScalars(docValue, filterValue, c)
 	}
 }
 
 // compareScalars compares BSON scalar values.
-func compareScalars(v1, v2 any) CompareResult {
+//
+// Strings are compared according to the given collation.
+func compareScalars(v1, v2 any, c *Collation) CompareResult {
 	assertType(v1)
 	assertType(v2)
 
@@ -108,6 +120,8 @@ func compareScalars(v1, v2 any) CompareResult {
 			return compareNumbers(v1, int64(v2))
 		case int64:
 			return compareNumbers(v1, v2)
+		case Decimal128:
+			return compareInvert(compareDecimal128(v2, v1))
 		default:
 			return compareTypeOrder(v1, v2)
 		}
@@ -115,7 +129,7 @@ func compareScalars(v1, v2 any) CompareResult {
 	case string:
 		v, ok := v2.(string)
 		if ok {
-			return compareOrdered(v1, v)
+			return c.CompareStrings(v1, v)
 		}
 
 		return compareTypeOrder(v1, v2)
@@ -194,6 +208,8 @@ func compareScalars(v1, v2 any) CompareResult {
 			return compareOrdered(v1, v)
 		case int64:
 			return compareOrdered(int64(v1), v)
+		case Decimal128:
+			return compareInvert(compareDecimal128(v, v1))
 		default:
 			return compareTypeOrder(v1, v2)
 		}
@@ -214,9 +230,14 @@ func compareScalars(v1, v2 any) CompareResult {
 			return compareOrdered(v1, int64(v))
 		case int64:
 			return compareOrdered(v1, v)
+		case Decimal128:
+			return compareInvert(compareDecimal128(v, v1))
 		default:
 			return compareTypeOrder(v1, v2)
 		}
+
+	case Decimal128:
+		return compareDecimal128(v1, v2)
 	}
 
 	panic("not reached")
@@ -258,11 +279,82 @@ func compareNumbers(a float64, b int64) CompareResult {
 	return CompareResult(bigA.Cmp(bigB))
 }
 
+// compareDecimal128 compares Decimal128 value with any BSON value.
+//
+// Numbers are compared exactly; NaN values are equal to each other (including double NaN)
+// and are compared with other numbers by type order.
+func compareDecimal128(a Decimal128, b any) CompareResult {
+	an, _ := newExactNumber(a)
+
+	bn, ok := newExactNumber(b)
+	if !ok {
+		return compareTypeOrder(a, b)
+	}
+
+	if an.nan || bn.nan {
+		if an.nan && bn.nan {
+			return Equal
+		}
+
+		return compareTypeOrder(a, b)
+	}
+
+	if an.inf != 0 || bn.inf != 0 {
+		return compareOrdered(an.inf, bn.inf)
+	}
+
+	return CompareResult(an.r.Cmp(bn.r))
+}
+
+// exactNumber represents BSON number for exact comparison.
+type exactNumber struct {
+	r   *big.Rat // for finite values
+	inf int      // -1 for negative infinity, 1 for positive infinity
+	nan bool
+}
+
+// newExactNumber returns exactNumber for the given BSON number value, or false for other values.
+func newExactNumber(v any) (exactNumber, bool) {
+	switch v := v.(type) {
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return exactNumber{nan: true}, true
+		case math.IsInf(v, 1):
+			return exactNumber{inf: 1}, true
+		case math.IsInf(v, -1):
+			return exactNumber{inf: -1}, true
+		}
+
+		return exactNumber{r: new(big.Rat).SetFloat64(v)}, true
+
+	case int32:
+		return exactNumber{r: new(big.Rat).SetInt64(int64(v))}, true
+
+	case int64:
+		return exactNumber{r: new(big.Rat).SetInt64(v)}, true
+
+	case Decimal128:
+		p := v.parts()
+
+		switch p.kind {
+		case decimal128NaNKind:
+			return exactNumber{nan: true}, true
+		case decimal128Inf:
+			return exactNumber{inf: p.infRank()}, true
+		}
+
+		return exactNumber{r: p.rat()}, true
+	}
+
+	return exactNumber{}, false
+}
+
 // compareArrays compares indices of a filter array according to indices of a document array;
 // returns Equal when an array equals to filter array;
 // returns Less when an index of the document array is less than the index of the filter array;
 // returns Greater when an index of the document array is greater than the index of the filter array.
-func compareArrays(docArr, filterArr *Array) CompareResult {
+func compareArrays(docArr, filterArr *Array, c *Collation) CompareResult {
 	if filterArr.Len() == 0 && docArr.Len() == 0 {
 		return Equal
 	}
@@ -287,12 +379,12 @@ func compareArrays(docArr, filterArr *Array) CompareResult {
 			continue
 		}
 
-		orderResult := CompareOrder(docValue, filterValue, Ascending)
+		orderResult := compareOrder(docValue, filterValue, Ascending, c)
 		if orderResult != Equal {
 			return orderResult
 		}
 
-		iterationResult := Compare(docValue, filterValue)
+		iterationResult := compare(docValue, filterValue, c)
 		if iterationResult != Equal {
 			return iterationResult
 		}
@@ -307,7 +399,9 @@ func compareArrays(docArr, filterArr *Array) CompareResult {
 
 // compareDocuments compares documents recursively by
 // comparing them in the order of types, field names and field values.
-func compareDocuments(a, b *Document) CompareResult {
+//
+// Field names are compared without collation.
+func compareDocuments(a, b *Document, c *Collation) CompareResult {
 	if a.Len() == 0 && b.Len() == 0 {
 		return Equal
 	}
@@ -336,12 +430,12 @@ func compareDocuments(a, b *Document) CompareResult {
 		}
 
 		// compare keys
-		if result := compareScalars(aKey, bKeys[i]); result != Equal {
+		if result := compareScalars(aKey, bKeys[i], nil); result != Equal {
 			return result
 		}
 
 		// compare values
-		if result := Compare(aValues[i], bValues[i]); result != Equal {
+		if result := compare(aValues[i], bValues[i], c); result != Equal {
 			return result
 		}
 	}
@@ -354,11 +448,11 @@ func compareDocuments(a, b *Document) CompareResult {
 }
 
 // compareArray compares array to any value.
-func compareArray(as *Array, b any) CompareResult {
+func compareArray(as *Array, b any, c *Collation) CompareResult {
 	assertType(b)
 
 	if bs, ok := b.(*Array); ok {
-		return compareArrays(as, bs)
+		return compareArrays(as, bs, c)
 	}
 
 	var result CompareResult
@@ -372,7 +466,7 @@ func compareArray(as *Array, b any) CompareResult {
 			continue
 		}
 
-		result = Compare(a, b)
+		result = compare(a, b, c)
 		if result == Equal {
 			return result
 		}
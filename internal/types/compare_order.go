@@ -78,6 +78,11 @@ func detectDataType(value any) compareTypeOrderResult {
 		return timestampDataType
 	case int64:
 		return numbersDataType
+	case Decimal128:
+		if value.IsNaN() {
+			return nanDataType
+		}
+		return numbersDataType
 	default:
 		panic(fmt.Sprintf("value cannot be defined, value is %[1]v, data type of value is %[1]T", value))
 	}
@@ -98,6 +103,11 @@ const (
 // When the types are equal, it compares their values using Compare.
 // This is used by update operator $max.
 func CompareOrder(a, b any, order SortType) CompareResult {
+	return compareOrder(a, b, order, nil)
+}
+
+// compareOrder implements CompareOrder and Collation.CompareOrder.
+func compareOrder(a, b any, order SortType, c *Collation) CompareResult {
 	if a == nil {
 		panic("CompareOrder: a is nil")
 	}
@@ -113,7 +123,7 @@ func CompareOrder(a, b any, order SortType) CompareResult {
 		return result
 	}
 
-	return Compare(a, b)
+	return compare(a, b, c)
 }
 
 // CompareOrderForSort detects the data type for two values and compares them.
@@ -125,6 +135,11 @@ func CompareOrder(a, b any, order SortType) CompareResult {
 //
 // This is used by sort operation.
 func CompareOrderForSort(a, b any, order SortType) CompareResult {
+	return compareOrderForSort(a, b, order, nil)
+}
+
+// compareOrderForSort implements CompareOrderForSort and Collation.CompareOrderForSort.
+func compareOrderForSort(a, b any, order SortType, c *Collation) CompareResult {
 	if a == nil {
 		panic("CompareOrderForSort: a is nil")
 	}
@@ -162,11 +177,11 @@ func CompareOrderForSort(a, b any, order SortType) CompareResult {
 	// minimum element in array for ascending sort and
 	// maximum element in array for descending sort.
 	if isAArray {
-		a = getComparisonElementFromArray(arrA, order)
+		a = getComparisonElementFromArray(arrA, order, c)
 	}
 
 	if isBArray {
-		b = getComparisonElementFromArray(arrB, order)
+		b = getComparisonElementFromArray(arrB, order, c)
 	}
 
 	if result := compareTypeOrder(a, b); result != Equal {
@@ -178,7 +193,7 @@ func CompareOrderForSort(a, b any, order SortType) CompareResult {
 		return compareInvert(result)
 	}
 
-	result := Compare(a, b)
+	result := compare(a, b, c)
 	if order == Ascending {
 		return result
 	}
@@ -192,6 +207,11 @@ func CompareOrderForSort(a, b any, order SortType) CompareResult {
 // b type.
 // It is used by $gt, $gte, $lt and $lte comparison.
 func CompareOrderForOperator(a, b any, order SortType) CompareResult {
+	return compareOrderForOperator(a, b, order, nil)
+}
+
+// compareOrderForOperator implements CompareOrderForOperator and Collation.CompareOrderForOperator.
+func compareOrderForOperator(a, b any, order SortType, c *Collation) CompareResult {
 	if a == nil {
 		panic("CompareOrderForOperator: a is nil")
 	}
@@ -227,7 +247,7 @@ func CompareOrderForOperator(a, b any, order SortType) CompareResult {
 	}
 
 	if isAArray && !isBArray {
-		a = getComparisonElementFromArray(arrA, order)
+		a = getComparisonElementFromArray(arrA, order, c)
 	}
 
 	if result := compareTypeOrder(a, b); result != Equal {
@@ -238,7 +258,7 @@ func CompareOrderForOperator(a, b any, order SortType) CompareResult {
 		return Less
 	}
 
-	return Compare(a, b)
+	return compare(a, b, c)
 }
 
 // compareTypeOrder detects the data type for two values and compares them.
@@ -260,17 +280,17 @@ func compareTypeOrder(a, b any) CompareResult {
 // comparison according to the sort order.
 // For Ascending order minimum element is retrieved, and
 // for descending order maximum element is retrieved.
-func getComparisonElementFromArray(arr *Array, order SortType) any {
+func getComparisonElementFromArray(arr *Array, order SortType, c *Collation) any {
 	if arr.Len() == 0 {
 		return arr
 	}
 
 	if order == Ascending {
-		return arr.Min()
+		return arr.min(c)
 	}
 
 	if order == Descending {
-		return arr.Max()
+		return arr.max(c)
 	}
 
 	panic("unsupported sort type")
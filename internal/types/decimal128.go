@@ -0,0 +1,634 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Decimal128 represents BSON type Decimal128.
+//
+// It uses IEEE 754-2008 128-bit decimal floating point format with binary integer significand encoding
+// like BSON does, so values are stored exactly as they are received from the client.
+type Decimal128 struct {
+	H uint64
+	L uint64
+}
+
+// Decimal128 format constants.
+const (
+	decimal128MaxDigits = 34
+	decimal128MaxExp    = 6111
+	decimal128MinExp    = -6176
+	decimal128ExpBias   = 6176
+)
+
+// Special Decimal128 values.
+var (
+	decimal128NaN    = Decimal128{H: 0x7c00000000000000}
+	decimal128PosInf = Decimal128{H: 0x7800000000000000}
+	decimal128NegInf = Decimal128{H: 0xf800000000000000}
+)
+
+// decimal128MaxCoefficient is the maximum coefficient (10^34 - 1).
+var decimal128MaxCoefficient = new(big.Int).Sub(
+	new(big.Int).Exp(big.NewInt(10), big.NewInt(decimal128MaxDigits), nil),
+	big.NewInt(1),
+)
+
+// decimal128Kind represents a kind of Decimal128 value.
+type decimal128Kind int
+
+const (
+	decimal128Finite decimal128Kind = iota
+	decimal128Inf
+	decimal128NaNKind
+)
+
+// decimal128Parts represents decoded Decimal128 value: (-1)^neg * coef * 10^exp.
+type decimal128Parts struct {
+	coef *big.Int
+	exp  int
+	kind decimal128Kind
+	neg  bool
+}
+
+// ParseDecimal128 parses the string representation of Decimal128 value,
+// like "1.5", "-0", "1E+10", "NaN", or "-Infinity".
+//
+// Values with more than 34 significant digits are rounded half to even;
+// values that are too large are converted to infinity.
+func ParseDecimal128(s string) (Decimal128, error) {
+	orig := s
+
+	var neg bool
+
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	switch strings.ToLower(s) {
+	case "nan":
+		return decimal128NaN, nil
+	case "inf", "infinity":
+		if neg {
+			return decimal128NegInf, nil
+		}
+
+		return decimal128PosInf, nil
+	}
+
+	var exp int
+
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(strings.TrimPrefix(s[i+1:], "+"))
+		if err != nil || s[i+1:] == "" {
+			return Decimal128{}, fmt.Errorf("types.ParseDecimal128: invalid exponent in %q", orig)
+		}
+
+		exp = e
+		s = s[:i]
+	}
+
+	digits := s
+
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits = s[:i] + s[i+1:]
+		exp -= len(s) - i - 1
+	}
+
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal128{}, fmt.Errorf("types.ParseDecimal128: invalid value %q", orig)
+	}
+
+	coef, _ := new(big.Int).SetString(digits, 10)
+
+	return decimal128Parts{coef: coef, exp: exp, neg: neg}.round(), nil
+}
+
+// MustParseDecimal128 is a variant of [ParseDecimal128] that panics on error.
+//
+// It should be used only in tests and for constants.
+func MustParseDecimal128(s string) Decimal128 {
+	d, err := ParseDecimal128(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+// NewDecimal128FromInt64 returns Decimal128 value for the given integer.
+func NewDecimal128FromInt64(i int64) Decimal128 {
+	return decimal128Parts{coef: new(big.Int).Abs(big.NewInt(i)), neg: i < 0}.round()
+}
+
+// NewDecimal128FromFloat64 returns Decimal128 value for the given double.
+//
+// Like MongoDB, it uses 15 significant digits, so 2.5 is converted to 2.50000000000000.
+func NewDecimal128FromFloat64(f float64) Decimal128 {
+	switch {
+	case math.IsNaN(f):
+		return decimal128NaN
+	case math.IsInf(f, 1):
+		return decimal128PosInf
+	case math.IsInf(f, -1):
+		return decimal128NegInf
+	case f == 0:
+		return decimal128Parts{coef: new(big.Int), neg: math.Signbit(f)}.round()
+	}
+
+	return must.NotFail(ParseDecimal128(strconv.FormatFloat(f, 'e', 14, 64)))
+}
+
+// parts returns decoded parts of Decimal128 value.
+func (d Decimal128) parts() decimal128Parts {
+	res := decimal128Parts{neg: d.H>>63 == 1}
+
+	if (d.H>>61)&0b11 == 0b11 {
+		switch (d.H >> 58) & 0b11111 {
+		case 0b11111:
+			res.kind = decimal128NaNKind
+			return res
+		case 0b11110:
+			res.kind = decimal128Inf
+			return res
+		}
+
+		// the coefficient is always larger than the maximum, so the value is non-canonical zero
+		res.coef = new(big.Int)
+		res.exp = int((d.H>>47)&0x3fff) - decimal128ExpBias
+
+		return res
+	}
+
+	res.exp = int((d.H>>49)&0x3fff) - decimal128ExpBias
+
+	res.coef = new(big.Int).SetUint64(d.H & (1<<49 - 1))
+	res.coef.Lsh(res.coef, 64)
+	res.coef.Or(res.coef, new(big.Int).SetUint64(d.L))
+
+	if res.coef.Cmp(decimal128MaxCoefficient) > 0 {
+		res.coef.SetUint64(0)
+	}
+
+	return res
+}
+
+// round returns Decimal128 value for the given parts,
+// rounding the coefficient half to even and clamping the exponent as needed.
+func (p decimal128Parts) round() Decimal128 {
+	switch p.kind {
+	case decimal128NaNKind:
+		return decimal128NaN
+	case decimal128Inf:
+		if p.neg {
+			return decimal128NegInf
+		}
+
+		return decimal128PosInf
+	}
+
+	coef := new(big.Int).Set(p.coef)
+	exp := p.exp
+
+	drop := max(len(coef.String())-decimal128MaxDigits, decimal128MinExp-exp)
+	if drop > 0 {
+		coef = roundHalfEven(coef, drop)
+		exp += drop
+
+		if coef.Cmp(decimal128MaxCoefficient) > 0 {
+			coef = roundHalfEven(coef, 1)
+			exp++
+		}
+	}
+
+	// clamp large exponents by adding trailing zeros to the coefficient
+	ten := big.NewInt(10)
+	for exp > decimal128MaxExp && coef.Sign() != 0 {
+		c := new(big.Int).Mul(coef, ten)
+		if c.Cmp(decimal128MaxCoefficient) > 0 {
+			if p.neg {
+				return decimal128NegInf
+			}
+
+			return decimal128PosInf
+		}
+
+		coef = c
+		exp--
+	}
+
+	exp = min(exp, decimal128MaxExp)
+
+	var res Decimal128
+
+	if p.neg {
+		res.H = 1 << 63
+	}
+
+	res.H |= uint64(exp+decimal128ExpBias) << 49
+	res.H |= new(big.Int).Rsh(coef, 64).Uint64()
+	res.L = new(big.Int).And(coef, new(big.Int).SetUint64(math.MaxUint64)).Uint64()
+
+	return res
+}
+
+// roundHalfEven returns coef divided by 10^n and rounded half to even.
+func roundHalfEven(coef *big.Int, n int) *big.Int {
+	d := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	q, r := new(big.Int).QuoRem(coef, d, new(big.Int))
+
+	switch r.Lsh(r, 1).Cmp(d) {
+	case 1:
+		q.Add(q, big.NewInt(1))
+	case 0:
+		if q.Bit(0) == 1 {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	return q
+}
+
+// IsNaN returns true if the value is NaN.
+func (d Decimal128) IsNaN() bool {
+	return d.parts().kind == decimal128NaNKind
+}
+
+// IsInf returns true if the value is infinity, according to sign.
+// If sign > 0, IsInf reports whether the value is positive infinity.
+// If sign < 0, IsInf reports whether the value is negative infinity.
+// If sign == 0, IsInf reports whether the value is either infinity.
+func (d Decimal128) IsInf(sign int) bool {
+	p := d.parts()
+	if p.kind != decimal128Inf {
+		return false
+	}
+
+	return sign == 0 || (sign > 0) == !p.neg
+}
+
+// IsZero returns true if the value is zero of any sign and exponent.
+func (d Decimal128) IsZero() bool {
+	p := d.parts()
+	return p.kind == decimal128Finite && p.coef.Sign() == 0
+}
+
+// String returns the canonical string representation of the value, like MongoDB does.
+func (d Decimal128) String() string {
+	p := d.parts()
+
+	var sign string
+	if p.neg {
+		sign = "-"
+	}
+
+	switch p.kind {
+	case decimal128NaNKind:
+		return "NaN"
+	case decimal128Inf:
+		return sign + "Infinity"
+	}
+
+	digits := p.coef.String()
+	adjusted := p.exp + len(digits) - 1
+
+	if p.exp > 0 || adjusted < -6 {
+		res := digits[:1]
+		if len(digits) > 1 {
+			res += "." + digits[1:]
+		}
+
+		return fmt.Sprintf("%s%sE%+d", sign, res, adjusted)
+	}
+
+	if p.exp == 0 {
+		return sign + digits
+	}
+
+	n := -p.exp
+	if len(digits) > n {
+		return sign + digits[:len(digits)-n] + "." + digits[len(digits)-n:]
+	}
+
+	return sign + "0." + strings.Repeat("0", n-len(digits)) + digits
+}
+
+// Neg returns the value with the opposite sign.
+func (d Decimal128) Neg() Decimal128 {
+	if d.IsNaN() {
+		return d
+	}
+
+	d.H ^= 1 << 63
+
+	return d
+}
+
+// Add returns the sum of d and v rounded to 34 significant digits.
+func (d Decimal128) Add(v Decimal128) Decimal128 {
+	a, b := d.parts(), v.parts()
+
+	switch {
+	case a.kind == decimal128NaNKind || b.kind == decimal128NaNKind:
+		return decimal128NaN
+	case a.kind == decimal128Inf && b.kind == decimal128Inf:
+		if a.neg != b.neg {
+			return decimal128NaN
+		}
+
+		return a.round()
+	case a.kind == decimal128Inf:
+		return a.round()
+	case b.kind == decimal128Inf:
+		return b.round()
+	}
+
+	exp := min(a.exp, b.exp)
+
+	x := a.scaled(a.exp - exp)
+	y := b.scaled(b.exp - exp)
+	sum := new(big.Int).Add(x, y)
+
+	res := decimal128Parts{coef: new(big.Int).Abs(sum), exp: exp}
+
+	switch sum.Sign() {
+	case -1:
+		res.neg = true
+	case 0:
+		// the sum of zeros with different signs is positive zero
+		res.neg = a.neg && b.neg
+	}
+
+	return res.round()
+}
+
+// Mul returns the product of d and v rounded to 34 significant digits.
+func (d Decimal128) Mul(v Decimal128) Decimal128 {
+	a, b := d.parts(), v.parts()
+	neg := a.neg != b.neg
+
+	switch {
+	case a.kind == decimal128NaNKind || b.kind == decimal128NaNKind:
+		return decimal128NaN
+	case a.kind == decimal128Inf || b.kind == decimal128Inf:
+		if (a.kind == decimal128Finite && a.coef.Sign() == 0) || (b.kind == decimal128Finite && b.coef.Sign() == 0) {
+			return decimal128NaN
+		}
+
+		return decimal128Parts{kind: decimal128Inf, neg: neg}.round()
+	}
+
+	return decimal128Parts{
+		coef: new(big.Int).Mul(a.coef, b.coef),
+		exp:  a.exp + b.exp,
+		neg:  neg,
+	}.round()
+}
+
+// Quo returns the quotient of d and v rounded to 34 significant digits.
+//
+// Like IEEE 754 division, exact results use the exponent closest to the difference of exponents,
+// division of non-zero value by zero returns infinity, and 0/0 returns NaN.
+func (d Decimal128) Quo(v Decimal128) Decimal128 {
+	a, b := d.parts(), v.parts()
+	neg := a.neg != b.neg
+
+	switch {
+	case a.kind == decimal128NaNKind || b.kind == decimal128NaNKind:
+		return decimal128NaN
+	case a.kind == decimal128Inf && b.kind == decimal128Inf:
+		return decimal128NaN
+	case a.kind == decimal128Inf:
+		return decimal128Parts{kind: decimal128Inf, neg: neg}.round()
+	case b.kind == decimal128Inf:
+		return decimal128Parts{coef: new(big.Int), exp: decimal128MinExp, neg: neg}.round()
+	case b.coef.Sign() == 0:
+		if a.coef.Sign() == 0 {
+			return decimal128NaN
+		}
+
+		return decimal128Parts{kind: decimal128Inf, neg: neg}.round()
+	}
+
+	ideal := a.exp - b.exp
+
+	if a.coef.Sign() == 0 {
+		return decimal128Parts{coef: new(big.Int), exp: ideal, neg: neg}.round()
+	}
+
+	num := new(big.Int).Set(a.coef)
+	den := new(big.Int).Set(b.coef)
+
+	// scale the dividend, so the quotient has at least one digit more than needed for rounding
+	k := decimal128MaxDigits + 1 - (len(num.String()) - len(den.String()))
+	if k > 0 {
+		num.Mul(num, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(k)), nil))
+	} else {
+		den.Mul(den, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-k)), nil))
+	}
+
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	exp := ideal - k
+
+	ten := big.NewInt(10)
+
+	if r.Sign() == 0 {
+		// remove trailing zeros of the exact result up to the ideal exponent
+		for exp < ideal {
+			q10, r10 := new(big.Int).QuoRem(q, ten, new(big.Int))
+			if r10.Sign() != 0 {
+				break
+			}
+
+			q = q10
+			exp++
+		}
+	} else {
+		// add sticky digit, so the inexact result is never rounded as exact half
+		q.Mul(q, ten)
+		q.Add(q, big.NewInt(1))
+		exp--
+	}
+
+	return decimal128Parts{coef: q, exp: exp, neg: neg}.round()
+}
+
+// Rem returns the remainder of d divided by v truncated towards zero; it has the sign of d.
+//
+// NaN is returned if d is infinity or v is zero.
+func (d Decimal128) Rem(v Decimal128) Decimal128 {
+	a, b := d.parts(), v.parts()
+
+	switch {
+	case a.kind != decimal128Finite || b.kind == decimal128NaNKind:
+		return decimal128NaN
+	case b.kind == decimal128Inf:
+		return d
+	case b.coef.Sign() == 0:
+		return decimal128NaN
+	}
+
+	exp := min(a.exp, b.exp)
+
+	x := a.scaled(a.exp - exp)
+	y := b.scaled(b.exp - exp)
+	r := new(big.Int).Rem(x, y)
+
+	return decimal128Parts{coef: r.Abs(r), exp: exp, neg: a.neg}.round()
+}
+
+// Round returns the value rounded half to even to the given number of decimal places.
+//
+// Negative places round to the left of the decimal point.
+func (d Decimal128) Round(places int) Decimal128 {
+	return d.quantize(places, false)
+}
+
+// Trunc returns the value truncated towards zero to the given number of decimal places.
+//
+// Negative places truncate to the left of the decimal point.
+func (d Decimal128) Trunc(places int) Decimal128 {
+	return d.quantize(places, true)
+}
+
+// quantize implements Round and Trunc.
+func (d Decimal128) quantize(places int, trunc bool) Decimal128 {
+	p := d.parts()
+	if p.kind != decimal128Finite || p.exp >= -places {
+		return d
+	}
+
+	drop := -places - p.exp
+
+	var coef *big.Int
+	if trunc {
+		coef = new(big.Int).Quo(p.coef, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(drop)), nil))
+	} else {
+		coef = roundHalfEven(p.coef, drop)
+	}
+
+	return decimal128Parts{coef: coef, exp: -places, neg: p.neg}.round()
+}
+
+// scaled returns the signed coefficient multiplied by 10^n.
+func (p decimal128Parts) scaled(n int) *big.Int {
+	res := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	res.Mul(res, p.coef)
+
+	if p.neg {
+		res.Neg(res)
+	}
+
+	return res
+}
+
+// rat returns the exact value of finite Decimal128 as a rational number.
+func (p decimal128Parts) rat() *big.Rat {
+	if p.kind != decimal128Finite {
+		panic("types.decimal128Parts.rat: value is not finite")
+	}
+
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(p.exp))), nil)
+
+	res := new(big.Rat).SetInt(p.coef)
+	if p.exp >= 0 {
+		res.Mul(res, new(big.Rat).SetInt(pow))
+	} else {
+		res.Quo(res, new(big.Rat).SetInt(pow))
+	}
+
+	if p.neg {
+		res.Neg(res)
+	}
+
+	return res
+}
+
+// infRank returns -1 for negative infinity, 1 for positive infinity, and 0 for other values.
+func (p decimal128Parts) infRank() int {
+	if p.kind != decimal128Inf {
+		return 0
+	}
+
+	if p.neg {
+		return -1
+	}
+
+	return 1
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// Float64 returns the nearest double value.
+func (d Decimal128) Float64() float64 {
+	p := d.parts()
+
+	switch p.kind {
+	case decimal128NaNKind:
+		return math.NaN()
+	case decimal128Inf:
+		if p.neg {
+			return math.Inf(-1)
+		}
+
+		return math.Inf(1)
+	}
+
+	f, _ := p.rat().Float64()
+
+	if f == 0 && p.neg {
+		return math.Copysign(0, -1)
+	}
+
+	return f
+}
+
+// Int64 returns the value truncated towards zero.
+//
+// It returns false if the value is NaN, infinity, or does not fit into int64.
+func (d Decimal128) Int64() (int64, bool) {
+	p := d.parts()
+	if p.kind != decimal128Finite {
+		return 0, false
+	}
+
+	r := p.rat()
+
+	i := new(big.Int).Quo(r.Num(), r.Denom())
+	if !i.IsInt64() {
+		return 0, false
+	}
+
+	return i.Int64(), true
+}
@@ -0,0 +1,252 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecimal128String(t *testing.T) {
+	t.Parallel()
+
+	for s, expected := range map[string]string{
+		"0":                                   "0",
+		"-0":                                  "-0",
+		"0.00":                                "0.00",
+		"1.50":                                "1.50",
+		"-123.456":                            "-123.456",
+		"1E+3":                                "1E+3",
+		"1000":                                "1000",
+		"0.0000001":                           "1E-7",
+		"0.000001":                            "0.000001",
+		"12345678901234567890123456789012345": "1.234567890123456789012345678901234E+34",
+		"NaN":                                 "NaN",
+		"-nan":                                "NaN",
+		"Infinity":                            "Infinity",
+		"-inf":                                "-Infinity",
+		"1e6112":                              "1.0E+6112",
+		"1e6145":                              "Infinity",
+		"1e-6177":                             "0E-6176",
+		"+42":                                 "42",
+		"2.5e-3":                              "0.0025",
+	} {
+		t.Run(s, func(t *testing.T) {
+			t.Parallel()
+
+			d, err := ParseDecimal128(s)
+			require.NoError(t, err)
+			assert.Equal(t, expected, d.String())
+
+			// canonical string round-trips
+			assert.Equal(t, d, MustParseDecimal128(d.String()))
+		})
+	}
+
+	for _, s := range []string{"", "-", "1.2.3", "1e", "abc", "0x10"} {
+		_, err := ParseDecimal128(s)
+		assert.Error(t, err, "%q", s)
+	}
+}
+
+func TestDecimal128Arithmetic(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		a, b          string
+		add, mul, quo string
+		rem           string
+	}{
+		"Simple": {
+			a: "1.1", b: "2.20",
+			add: "3.30", mul: "2.420", quo: "0.5",
+			rem: "1.10",
+		},
+		"Negative": {
+			a: "-5", b: "3",
+			add: "-2", mul: "-15", quo: "-1.666666666666666666666666666666667",
+			rem: "-2",
+		},
+		"Zero": {
+			a: "0", b: "-0",
+			add: "0", mul: "-0", quo: "NaN",
+			rem: "NaN",
+		},
+		"Rounding": {
+			a: "9999999999999999999999999999999999", b: "1",
+			add: "1.000000000000000000000000000000000E+34", mul: "9999999999999999999999999999999999",
+			quo: "9999999999999999999999999999999999", rem: "0",
+		},
+		"Inf": {
+			a: "Infinity", b: "-Infinity",
+			add: "NaN", mul: "-Infinity", quo: "NaN",
+			rem: "NaN",
+		},
+		"NaN": {
+			a: "NaN", b: "1",
+			add: "NaN", mul: "NaN", quo: "NaN",
+			rem: "NaN",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			a, b := MustParseDecimal128(tc.a), MustParseDecimal128(tc.b)
+			assert.Equal(t, tc.add, a.Add(b).String())
+			assert.Equal(t, tc.mul, a.Mul(b).String())
+			assert.Equal(t, tc.quo, a.Quo(b).String())
+			assert.Equal(t, tc.rem, a.Rem(b).String())
+		})
+	}
+}
+
+func TestDecimal128Round(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		v            string
+		places       int
+		round, trunc string
+	}{
+		"Places": {
+			v: "1.2345", places: 2,
+			round: "1.23", trunc: "1.23",
+		},
+		"HalfEven": {
+			v: "2.5", places: 0,
+			round: "2", trunc: "2",
+		},
+		"HalfEvenUp": {
+			v: "-3.5", places: 0,
+			round: "-4", trunc: "-3",
+		},
+		"NegativePlaces": {
+			v: "1250", places: -2,
+			round: "1.2E+3", trunc: "1.2E+3",
+		},
+		"Exact": {
+			v: "1.5", places: 3,
+			round: "1.5", trunc: "1.5",
+		},
+		"Inf": {
+			v: "-Infinity", places: 1,
+			round: "-Infinity", trunc: "-Infinity",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v := MustParseDecimal128(tc.v)
+			assert.Equal(t, tc.round, v.Round(tc.places).String())
+			assert.Equal(t, tc.trunc, v.Trunc(tc.places).String())
+		})
+	}
+}
+
+func TestDecimal128Conversion(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "42", NewDecimal128FromInt64(42).String())
+	assert.Equal(t, "-9223372036854775808", NewDecimal128FromInt64(math.MinInt64).String())
+	assert.Equal(t, "2.50000000000000", NewDecimal128FromFloat64(2.5).String())
+	assert.Equal(t, "0", NewDecimal128FromFloat64(0).String())
+	assert.Equal(t, "-0", NewDecimal128FromFloat64(math.Copysign(0, -1)).String())
+	assert.True(t, NewDecimal128FromFloat64(math.NaN()).IsNaN())
+	assert.True(t, NewDecimal128FromFloat64(math.Inf(-1)).IsInf(-1))
+
+	assert.Equal(t, 1.5, MustParseDecimal128("1.50").Float64())
+	assert.True(t, math.IsInf(MustParseDecimal128("-Infinity").Float64(), -1))
+
+	for s, expected := range map[string]int64{
+		"-2.9":                 -2,
+		"1E+3":                 1000,
+		"9223372036854775807":  math.MaxInt64,
+		"-9223372036854775808": math.MinInt64,
+	} {
+		i, ok := MustParseDecimal128(s).Int64()
+		assert.True(t, ok, s)
+		assert.Equal(t, expected, i, s)
+	}
+
+	for _, s := range []string{"NaN", "Infinity", "9223372036854775808", "1E+100"} {
+		_, ok := MustParseDecimal128(s).Int64()
+		assert.False(t, ok, s)
+	}
+}
+
+func TestDecimal128Compare(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		a        any
+		b        any
+		expected CompareResult
+	}{
+		"EqualScale": {
+			a:        MustParseDecimal128("1.0"),
+			b:        MustParseDecimal128("1.00"),
+			expected: Equal,
+		},
+		"Int32": {
+			a:        MustParseDecimal128("1.5"),
+			b:        int32(2),
+			expected: Less,
+		},
+		"Float64": {
+			a:        float64(0.1),
+			b:        MustParseDecimal128("0.1"),
+			expected: Greater,
+		},
+		"Int64": {
+			a:        int64(math.MaxInt64),
+			b:        MustParseDecimal128("9223372036854775806"),
+			expected: Greater,
+		},
+		"Inf": {
+			a:        MustParseDecimal128("Infinity"),
+			b:        math.MaxFloat64,
+			expected: Greater,
+		},
+		"NegInf": {
+			a:        MustParseDecimal128("-Infinity"),
+			b:        math.Inf(-1),
+			expected: Equal,
+		},
+		"NaN": {
+			a:        MustParseDecimal128("NaN"),
+			b:        math.NaN(),
+			expected: Equal,
+		},
+		"NaNNumber": {
+			a:        MustParseDecimal128("NaN"),
+			b:        int32(0),
+			expected: Less,
+		},
+		"String": {
+			a:        MustParseDecimal128("1"),
+			b:        "1",
+			expected: Less,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, Compare(tc.a, tc.b))
+		})
+	}
+}
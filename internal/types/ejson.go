@@ -0,0 +1,585 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ejsonDateFormat is the format of relaxed Extended JSON dates.
+const ejsonDateFormat = "2006-01-02T15:04:05.999Z07:00"
+
+// MarshalExtJSON encodes BSON value (usually *Document or *Array) to Extended JSON v2.
+//
+// If canonical is true, the canonical flavor that preserves all type information is used.
+// Otherwise, the relaxed flavor is used: int32, int64, and finite double values are encoded as JSON numbers,
+// and dates between years 1970 and 9999 are encoded as ISO-8601 strings.
+//
+// See https://github.com/mongodb/specifications/blob/master/source/extended-json.rst.
+func MarshalExtJSON(v any, canonical bool) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := marshalExtJSON(&buf, v, canonical); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalExtJSON decodes BSON value from canonical or relaxed Extended JSON v2.
+//
+// JSON numbers are decoded as int32, int64, or double, whichever is the first to represent the value.
+// Objects with keys starting with '$' that are not type wrappers (like query operators)
+// are decoded as documents.
+func UnmarshalExtJSON(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	v, err := readExtJSON(dec)
+	if err != nil {
+		return nil, fmt.Errorf("types.UnmarshalExtJSON: %w", err)
+	}
+
+	if _, err = dec.Token(); err != io.EOF {
+		return nil, errors.New("types.UnmarshalExtJSON: unexpected data after top-level value")
+	}
+
+	res, err := convertExtJSON(v)
+	if err != nil {
+		return nil, fmt.Errorf("types.UnmarshalExtJSON: %w", err)
+	}
+
+	return res, nil
+}
+
+// MarshalJSON implements [json.Marshaler] interface.
+//
+// It returns relaxed Extended JSON representation of the document.
+func (d *Document) MarshalJSON() ([]byte, error) {
+	return MarshalExtJSON(d, false)
+}
+
+// MarshalJSON implements [json.Marshaler] interface.
+//
+// It returns relaxed Extended JSON representation of the array.
+func (a *Array) MarshalJSON() ([]byte, error) {
+	return MarshalExtJSON(a, false)
+}
+
+// marshalExtJSON writes Extended JSON representation of v to buf.
+func marshalExtJSON(buf *bytes.Buffer, v any, canonical bool) error {
+	switch v := v.(type) {
+	case *Document:
+		if v == nil {
+			buf.WriteString("null")
+			break
+		}
+
+		buf.WriteByte('{')
+
+		for i, f := range v.fields {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			writeJSONString(buf, f.key)
+			buf.WriteByte(':')
+
+			if err := marshalExtJSON(buf, f.value, canonical); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte('}')
+
+	case *Array:
+		if v == nil {
+			buf.WriteString("null")
+			break
+		}
+
+		buf.WriteByte('[')
+
+		for i, e := range v.s {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := marshalExtJSON(buf, e, canonical); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte(']')
+
+	case float64:
+		s := formatExtJSONDouble(v)
+
+		if canonical || math.IsNaN(v) || math.IsInf(v, 0) {
+			buf.WriteString(`{"$numberDouble":"` + s + `"}`)
+			break
+		}
+
+		buf.WriteString(s)
+
+	case string:
+		writeJSONString(buf, v)
+
+	case Binary:
+		fmt.Fprintf(
+			buf, `{"$binary":{"base64":"%s","subType":"%02x"}}`,
+			base64.StdEncoding.EncodeToString(v.B), byte(v.Subtype),
+		)
+
+	case ObjectID:
+		buf.WriteString(`{"$oid":"` + hex.EncodeToString(v[:]) + `"}`)
+
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+
+	case time.Time:
+		ms := v.UnixMilli()
+
+		if y := v.UTC().Year(); !canonical && y >= 1970 && y <= 9999 {
+			buf.WriteString(`{"$date":"` + time.UnixMilli(ms).UTC().Format(ejsonDateFormat) + `"}`)
+			break
+		}
+
+		buf.WriteString(`{"$date":{"$numberLong":"` + strconv.FormatInt(ms, 10) + `"}}`)
+
+	case NullType:
+		buf.WriteString("null")
+
+	case Regex:
+		buf.WriteString(`{"$regularExpression":{"pattern":`)
+		writeJSONString(buf, v.Pattern)
+		buf.WriteString(`,"options":`)
+		writeJSONString(buf, v.Options)
+		buf.WriteString(`}}`)
+
+	case int32:
+		if canonical {
+			buf.WriteString(`{"$numberInt":"` + strconv.FormatInt(int64(v), 10) + `"}`)
+			break
+		}
+
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+
+	case Timestamp:
+		fmt.Fprintf(buf, `{"$timestamp":{"t":%d,"i":%d}}`, uint32(v>>32), uint32(v))
+
+	case int64:
+		if canonical {
+			buf.WriteString(`{"$numberLong":"` + strconv.FormatInt(v, 10) + `"}`)
+			break
+		}
+
+		buf.WriteString(strconv.FormatInt(v, 10))
+
+	case Decimal128:
+		buf.WriteString(`{"$numberDecimal":"` + v.String() + `"}`)
+
+	default:
+		return fmt.Errorf("types.MarshalExtJSON: unsupported type %T", v)
+	}
+
+	return nil
+}
+
+// writeJSONString writes JSON string without escaping HTML characters.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+
+	// encoding a string can't fail
+	_ = enc.Encode(s)
+
+	// remove newline added by Encode
+	buf.Truncate(buf.Len() - 1)
+}
+
+// formatExtJSONDouble returns Extended JSON string representation of double value.
+//
+// Integer values are written with a single decimal place, other values use
+// as many digits as needed to represent them exactly.
+func formatExtJSONDouble(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+
+	s := strconv.FormatFloat(f, 'G', -1, 64)
+	if !strings.ContainsAny(s, ".E") {
+		s += ".0"
+	}
+
+	return s
+}
+
+// ejsonObject represents a JSON object with keys in the original order.
+type ejsonObject struct {
+	keys   []string
+	values []any
+}
+
+// readExtJSON reads a single JSON value from the decoder.
+//
+// Objects are returned as *ejsonObject, arrays as []any,
+// and scalars as returned by [json.Decoder.Token].
+func readExtJSON(dec *json.Decoder) (any, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	d, ok := t.(json.Delim)
+	if !ok {
+		return t, nil
+	}
+
+	switch d {
+	case '{':
+		var obj ejsonObject
+
+		for dec.More() {
+			if t, err = dec.Token(); err != nil {
+				return nil, err
+			}
+
+			var v any
+			if v, err = readExtJSON(dec); err != nil {
+				return nil, err
+			}
+
+			obj.keys = append(obj.keys, t.(string))
+			obj.values = append(obj.values, v)
+		}
+
+		if _, err = dec.Token(); err != nil {
+			return nil, err
+		}
+
+		return &obj, nil
+
+	case '[':
+		arr := []any{}
+
+		for dec.More() {
+			var v any
+			if v, err = readExtJSON(dec); err != nil {
+				return nil, err
+			}
+
+			arr = append(arr, v)
+		}
+
+		if _, err = dec.Token(); err != nil {
+			return nil, err
+		}
+
+		return arr, nil
+
+	default:
+		return nil, fmt.Errorf("unexpected delimiter %s", d)
+	}
+}
+
+// convertExtJSON converts value returned by readExtJSON to BSON value.
+func convertExtJSON(v any) (any, error) {
+	switch v := v.(type) {
+	case *ejsonObject:
+		if len(v.keys) > 0 && strings.HasPrefix(v.keys[0], "$") {
+			res, ok, err := convertExtJSONWrapper(v)
+			if err != nil {
+				return nil, err
+			}
+
+			if ok {
+				return res, nil
+			}
+		}
+
+		pairs := make([]any, 0, len(v.keys)*2)
+
+		for i, k := range v.keys {
+			value, err := convertExtJSON(v.values[i])
+			if err != nil {
+				return nil, err
+			}
+
+			pairs = append(pairs, k, value)
+		}
+
+		return NewDocument(pairs...)
+
+	case []any:
+		arr := MakeArray(len(v))
+
+		for _, e := range v {
+			value, err := convertExtJSON(e)
+			if err != nil {
+				return nil, err
+			}
+
+			arr.Append(value)
+		}
+
+		return arr, nil
+
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			if i >= math.MinInt32 && i <= math.MaxInt32 {
+				return int32(i), nil
+			}
+
+			return i, nil
+		}
+
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", v)
+		}
+
+		return f, nil
+
+	case string:
+		return v, nil
+
+	case bool:
+		return v, nil
+
+	case nil:
+		return Null, nil
+
+	default:
+		panic(fmt.Sprintf("unexpected type %T", v))
+	}
+}
+
+// convertExtJSONWrapper converts Extended JSON type wrapper object like {"$oid": "..."} to BSON value.
+//
+// It returns false if the object is not a type wrapper.
+func convertExtJSONWrapper(obj *ejsonObject) (any, bool, error) {
+	key := obj.keys[0]
+
+	switch key {
+	case "$oid", "$numberInt", "$numberLong", "$numberDouble", "$numberDecimal",
+		"$binary", "$date", "$regularExpression", "$timestamp":
+		if len(obj.keys) != 1 {
+			return nil, false, fmt.Errorf("invalid %s: unexpected keys %q", key, obj.keys)
+		}
+
+	case "$minKey", "$maxKey", "$undefined", "$symbol", "$code", "$dbPointer":
+		return nil, false, fmt.Errorf("unsupported type wrapper %s", key)
+
+	default:
+		return nil, false, nil
+	}
+
+	v := obj.values[0]
+
+	var res any
+	var err error
+
+	switch key {
+	case "$oid":
+		var b []byte
+		if s, ok := v.(string); ok {
+			b, err = hex.DecodeString(s)
+		}
+
+		if err != nil || len(b) != len(ObjectID{}) {
+			return nil, false, fmt.Errorf("invalid $oid %v", v)
+		}
+
+		res = ObjectID(b)
+
+	case "$numberInt":
+		var i int64
+		if i, err = strconv.ParseInt(extJSONString(v), 10, 32); err != nil {
+			return nil, false, fmt.Errorf("invalid $numberInt %v", v)
+		}
+
+		res = int32(i)
+
+	case "$numberLong":
+		if res, err = strconv.ParseInt(extJSONString(v), 10, 64); err != nil {
+			return nil, false, fmt.Errorf("invalid $numberLong %v", v)
+		}
+
+	case "$numberDouble":
+		if res, err = parseExtJSONDouble(extJSONString(v)); err != nil {
+			return nil, false, fmt.Errorf("invalid $numberDouble %v", v)
+		}
+
+	case "$numberDecimal":
+		if res, err = ParseDecimal128(extJSONString(v)); err != nil {
+			return nil, false, fmt.Errorf("invalid $numberDecimal %v", v)
+		}
+
+	case "$binary":
+		fields, ok := extJSONFields(v, "base64", "subType")
+		if !ok {
+			return nil, false, fmt.Errorf("invalid $binary %v", v)
+		}
+
+		var b []byte
+		var subtype uint64
+
+		b, err = base64.StdEncoding.DecodeString(extJSONString(fields[0]))
+		if err == nil {
+			subtype, err = strconv.ParseUint(extJSONString(fields[1]), 16, 8)
+		}
+
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid $binary %v", v)
+		}
+
+		res = Binary{B: b, Subtype: BinarySubtype(subtype)}
+
+	case "$date":
+		switch d := v.(type) {
+		case string:
+			var t time.Time
+			if t, err = time.Parse(time.RFC3339Nano, d); err != nil {
+				return nil, false, fmt.Errorf("invalid $date %q", d)
+			}
+
+			res = time.UnixMilli(t.UnixMilli()).UTC()
+
+		case *ejsonObject:
+			var ms int64
+
+			fields, ok := extJSONFields(d, "$numberLong")
+			if ok {
+				ms, err = strconv.ParseInt(extJSONString(fields[0]), 10, 64)
+			}
+
+			if !ok || err != nil {
+				return nil, false, fmt.Errorf("invalid $date %v", v)
+			}
+
+			res = time.UnixMilli(ms).UTC()
+
+		default:
+			return nil, false, fmt.Errorf("invalid $date %v", v)
+		}
+
+	case "$regularExpression":
+		fields, ok := extJSONFields(v, "pattern", "options")
+		if !ok {
+			return nil, false, fmt.Errorf("invalid $regularExpression %v", v)
+		}
+
+		pattern, ok1 := fields[0].(string)
+		options, ok2 := fields[1].(string)
+
+		if !ok1 || !ok2 {
+			return nil, false, fmt.Errorf("invalid $regularExpression %v", v)
+		}
+
+		res = Regex{Pattern: pattern, Options: options}
+
+	case "$timestamp":
+		fields, ok := extJSONFields(v, "t", "i")
+		if !ok {
+			return nil, false, fmt.Errorf("invalid $timestamp %v", v)
+		}
+
+		var t, i uint64
+
+		t, err = strconv.ParseUint(extJSONString(fields[0]), 10, 32)
+		if err == nil {
+			i, err = strconv.ParseUint(extJSONString(fields[1]), 10, 32)
+		}
+
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid $timestamp %v", v)
+		}
+
+		res = Timestamp(t<<32 | i)
+	}
+
+	return res, true, nil
+}
+
+// extJSONFields returns values of the object with exactly the given keys in any order.
+func extJSONFields(v any, keys ...string) ([]any, bool) {
+	obj, ok := v.(*ejsonObject)
+	if !ok || len(obj.keys) != len(keys) {
+		return nil, false
+	}
+
+	res := make([]any, len(keys))
+
+	for i, k := range keys {
+		j := -1
+
+		for n, objKey := range obj.keys {
+			if objKey == k {
+				j = n
+				break
+			}
+		}
+
+		if j < 0 {
+			return nil, false
+		}
+
+		res[i] = obj.values[j]
+	}
+
+	return res, true
+}
+
+// extJSONString returns the string or number value as a string, or an empty string for other values.
+func extJSONString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return string(v)
+	default:
+		return ""
+	}
+}
+
+// parseExtJSONDouble parses Extended JSON string representation of double value.
+func parseExtJSONDouble(s string) (float64, error) {
+	switch s {
+	case "NaN":
+		return math.NaN(), nil
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	}
+
+	return strconv.ParseFloat(s, 64)
+}
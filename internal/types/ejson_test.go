@@ -0,0 +1,283 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestExtJSON(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2021, 11, 1, 10, 18, 42, 123000000, time.UTC)
+
+	for name, tc := range map[string]struct {
+		v         any
+		canonical string
+		relaxed   string // if empty, the same as canonical
+		lossy     bool   // relaxed form does not round-trip
+	}{
+		"Double": {
+			v:         42.13,
+			canonical: `{"$numberDouble":"42.13"}`,
+			relaxed:   `42.13`,
+		},
+		"DoubleWhole": {
+			v:         float64(42),
+			canonical: `{"$numberDouble":"42.0"}`,
+			relaxed:   `42.0`,
+		},
+		"DoubleBig": {
+			v:         1e300,
+			canonical: `{"$numberDouble":"1E+300"}`,
+			relaxed:   `1E+300`,
+		},
+		"DoubleSmall": {
+			v:         5e-324,
+			canonical: `{"$numberDouble":"5E-324"}`,
+			relaxed:   `5E-324`,
+		},
+		"DoubleNegZero": {
+			v:         math.Copysign(0, -1),
+			canonical: `{"$numberDouble":"-0.0"}`,
+			relaxed:   `-0.0`,
+		},
+		"DoubleNaN": {
+			v:         math.NaN(),
+			canonical: `{"$numberDouble":"NaN"}`,
+		},
+		"DoubleInf": {
+			v:         math.Inf(1),
+			canonical: `{"$numberDouble":"Infinity"}`,
+		},
+		"DoubleNegInf": {
+			v:         math.Inf(-1),
+			canonical: `{"$numberDouble":"-Infinity"}`,
+		},
+		"String": {
+			v:         "foo <\"bar\">\n",
+			canonical: `"foo <\"bar\">\n"`,
+		},
+		"StringEmpty": {
+			v:         "",
+			canonical: `""`,
+		},
+		"Binary": {
+			v:         Binary{B: []byte{0x42, 0x13}, Subtype: BinaryUser},
+			canonical: `{"$binary":{"base64":"QhM=","subType":"80"}}`,
+		},
+		"BinaryEmpty": {
+			v:         Binary{B: []byte{}},
+			canonical: `{"$binary":{"base64":"","subType":"00"}}`,
+		},
+		"ObjectID": {
+			v:         ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0xff, 0xff, 0xff},
+			canonical: `{"$oid":"6256c5ba0badc0ffeeffffff"}`,
+		},
+		"Bool": {
+			v:         true,
+			canonical: `true`,
+		},
+		"Date": {
+			v:         date,
+			canonical: `{"$date":{"$numberLong":"1635761922123"}}`,
+			relaxed:   `{"$date":"2021-11-01T10:18:42.123Z"}`,
+		},
+		"DateEpoch": {
+			v:         time.UnixMilli(0).UTC(),
+			canonical: `{"$date":{"$numberLong":"0"}}`,
+			relaxed:   `{"$date":"1970-01-01T00:00:00Z"}`,
+		},
+		"DateBeforeEpoch": {
+			v:         time.UnixMilli(-1).UTC(),
+			canonical: `{"$date":{"$numberLong":"-1"}}`,
+		},
+		"Null": {
+			v:         Null,
+			canonical: `null`,
+		},
+		"Regex": {
+			v:         Regex{Pattern: `^foo\.`, Options: "i"},
+			canonical: `{"$regularExpression":{"pattern":"^foo\\.","options":"i"}}`,
+		},
+		"Int32": {
+			v:         int32(-42),
+			canonical: `{"$numberInt":"-42"}`,
+			relaxed:   `-42`,
+		},
+		"Int32Max": {
+			v:         int32(math.MaxInt32),
+			canonical: `{"$numberInt":"2147483647"}`,
+			relaxed:   `2147483647`,
+		},
+		"Timestamp": {
+			v:         Timestamp(42<<32 | 13),
+			canonical: `{"$timestamp":{"t":42,"i":13}}`,
+		},
+		"Int64": {
+			v:         int64(math.MaxInt64),
+			canonical: `{"$numberLong":"9223372036854775807"}`,
+			relaxed:   `9223372036854775807`,
+		},
+		"Int64Small": {
+			v:         int64(42),
+			canonical: `{"$numberLong":"42"}`,
+			relaxed:   `42`,
+			lossy:     true,
+		},
+		"Decimal128": {
+			v:         MustParseDecimal128("-1.50E+10"),
+			canonical: `{"$numberDecimal":"-1.50E+10"}`,
+		},
+		"Document": {
+			v: must.NotFail(NewDocument(
+				"_id", int32(1),
+				"$gt", "foo",
+				"a", must.NotFail(NewArray(int64(1), NullType{}, must.NotFail(NewDocument()))),
+			)),
+			canonical: `{"_id":{"$numberInt":"1"},"$gt":"foo","a":[{"$numberLong":"1"},null,{}]}`,
+			relaxed:   `{"_id":1,"$gt":"foo","a":[1,null,{}]}`,
+			lossy:     true,
+		},
+		"DocumentDuplicateKeys": {
+			v:         must.NotFail(NewDocument("foo", "bar", "foo", true)),
+			canonical: `{"foo":"bar","foo":true}`,
+		},
+		"ArrayEmpty": {
+			v:         must.NotFail(NewArray()),
+			canonical: `[]`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			relaxed := tc.relaxed
+			if relaxed == "" {
+				relaxed = tc.canonical
+			}
+
+			b, err := MarshalExtJSON(tc.v, true)
+			require.NoError(t, err)
+			assert.Equal(t, tc.canonical, string(b))
+
+			b, err = MarshalExtJSON(tc.v, false)
+			require.NoError(t, err)
+			assert.Equal(t, relaxed, string(b))
+
+			actual, err := UnmarshalExtJSON([]byte(tc.canonical))
+			require.NoError(t, err)
+			assertExtJSONEqual(t, tc.v, actual)
+
+			actual, err = UnmarshalExtJSON([]byte(relaxed))
+			require.NoError(t, err)
+
+			if tc.lossy {
+				assert.Equal(t, Equal, Compare(tc.v, actual))
+				return
+			}
+
+			assertExtJSONEqual(t, tc.v, actual)
+		})
+	}
+}
+
+// assertExtJSONEqual asserts that values are identical; NaNs are equal to each other.
+func assertExtJSONEqual(t *testing.T, expected, actual any) {
+	t.Helper()
+
+	if f, ok := expected.(float64); ok && math.IsNaN(f) {
+		a, ok := actual.(float64)
+		assert.True(t, ok && math.IsNaN(a), "expected NaN, got %v", actual)
+
+		return
+	}
+
+	assert.Equal(t, expected, actual)
+}
+
+func TestUnmarshalExtJSON(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		json     string
+		expected any
+		err      bool
+	}{
+		"Number": {
+			json:     `[1, 2147483648, 1.5, 1e2]`,
+			expected: must.NotFail(NewArray(int32(1), int64(2147483648), 1.5, float64(100))),
+		},
+		"DateOffset": {
+			json:     `{"$date":"2021-11-01T12:18:42.123+02:00"}`,
+			expected: time.Date(2021, 11, 1, 10, 18, 42, 123000000, time.UTC),
+		},
+		"BinaryKeyOrder": {
+			json:     `{"$binary":{"subType":"4","base64":"AA=="}}`,
+			expected: Binary{B: []byte{0}, Subtype: BinaryUUID},
+		},
+		"Operator": {
+			json:     `{"v":{"$in":[{"$numberLong":"1"}]}}`,
+			expected: must.NotFail(NewDocument("v", must.NotFail(NewDocument("$in", must.NotFail(NewArray(int64(1))))))),
+		},
+		"WrapperExtraKeys": {
+			json: `{"$oid":"6256c5ba0badc0ffeeffffff","foo":1}`,
+			err:  true,
+		},
+		"InvalidObjectID": {
+			json: `{"$oid":"foo"}`,
+			err:  true,
+		},
+		"InvalidInt32": {
+			json: `{"$numberInt":"2147483648"}`,
+			err:  true,
+		},
+		"InvalidDate": {
+			json: `{"$date":"yesterday"}`,
+			err:  true,
+		},
+		"Unsupported": {
+			json: `{"$minKey":1}`,
+			err:  true,
+		},
+		"Trailing": {
+			json: `{} {}`,
+			err:  true,
+		},
+		"Invalid": {
+			json: `{"foo":}`,
+			err:  true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := UnmarshalExtJSON([]byte(tc.json))
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
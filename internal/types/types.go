@@ -34,19 +34,20 @@
 //
 // Scalar types (passed by values)
 //
-//	Alias      types package    Description
-//
-//	double     float64          64-bit binary floating point
-//	string     string           UTF-8 string
-//	binData    types.Binary     Binary data
-//	objectId   types.ObjectID   Object ID
-//	bool       bool             Boolean
-//	date       time.Time        UTC datetime
-//	null       types.NullType   Null
-//	regex      types.Regex      Regular expression
-//	int        int32            32-bit integer
-//	timestamp  types.Timestamp  Timestamp
-//	long       int64            64-bit integer
+//	Alias      types package     Description
+//
+//	double     float64           64-bit binary floating point
+//	string     string            UTF-8 string
+//	binData    types.Binary      Binary data
+//	objectId   types.ObjectID    Object ID
+//	bool       bool              Boolean
+//	date       time.Time         UTC datetime
+//	null       types.NullType    Null
+//	regex      types.Regex       Regular expression
+//	int        int32             32-bit integer
+//	timestamp  types.Timestamp   Timestamp
+//	long       int64             64-bit integer
+//	decimal    types.Decimal128  128-bit decimal floating point
 //
 //nolint:dupword // false positive
 package types
@@ -66,7 +67,7 @@ const MaxSafeDouble = float64(1<<53 - 1) // 52bit mantissa max value = 900719925
 
 // ScalarType represents scalar type.
 type ScalarType interface {
-	float64 | string | Binary | ObjectID | bool | time.Time | NullType | Regex | int32 | Timestamp | int64
+	float64 | string | Binary | ObjectID | bool | time.Time | NullType | Regex | int32 | Timestamp | int64 | Decimal128
 }
 
 // CompositeType represents composite type - *Document or *Array.
@@ -101,7 +102,7 @@ func assertType(value any) {
 	switch value := value.(type) {
 	case *Document, *Array:
 		return
-	case float64, string, Binary, ObjectID, bool, time.Time, NullType, Regex, int32, Timestamp, int64:
+	case float64, string, Binary, ObjectID, bool, time.Time, NullType, Regex, int32, Timestamp, int64, Decimal128:
 		return
 	case nil:
 		panic("types: unexpected nil type")
@@ -117,7 +118,7 @@ func isScalar(value any) bool {
 	assertType(value)
 
 	switch value.(type) {
-	case float64, string, Binary, ObjectID, bool, time.Time, NullType, Regex, int32, Timestamp, int64:
+	case float64, string, Binary, ObjectID, bool, time.Time, NullType, Regex, int32, Timestamp, int64, Decimal128:
 		return true
 	}
 
@@ -178,6 +179,8 @@ func deepCopy(value any) any {
 		return value
 	case int64:
 		return value
+	case Decimal128:
+		return value
 
 	default:
 		panic(fmt.Sprintf("types.deepCopy: unexpected type %[1]T (%#[1]v)", value))
@@ -0,0 +1,25 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctxutil
+
+import "context"
+
+// SigHup returns a channel that receives a value every time hangup signal arrives,
+// until the given context is done.
+//
+// There is no hangup signal on Windows, so the returned channel never receives.
+func SigHup(ctx context.Context) <-chan struct{} {
+	return make(chan struct{})
+}
@@ -66,6 +66,7 @@ type ListenOpts struct {
 	TCPAddr string
 	L       *zap.Logger
 	R       prometheus.Registerer
+	Token   string // if not empty, required for state pages
 }
 
 // Listen creates a new debug handler and starts listener on the given TCP address.
@@ -112,30 +113,28 @@ func Listen(opts *ListenOpts) (*Handler, error) {
 		rw.WriteHeader(http.StatusOK)
 	})
 
-	// healthz handler, which is used for liveness probe, returns StatusOK when reached.
-	// This ensures that listener is running.
-	healthzHandler := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
-		rw.WriteHeader(http.StatusOK)
-	})
-
-	readyHandler := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
-		// TODO https://github.com/FerretDB/FerretDB/issues/4306
-		rw.WriteHeader(http.StatusOK)
-	})
-
 	http.Handle("/debug/started", promhttp.InstrumentHandlerCounter(
 		requestCount.MustCurryWith(prometheus.Labels{"handler": "/debug/started"}),
 		startedHandler,
 	))
 
-	http.Handle("/debug/healthz", promhttp.InstrumentHandlerCounter(
-		requestCount.MustCurryWith(prometheus.Labels{"handler": "/debug/healthz"}),
-		healthzHandler,
-	))
+	// /debug/healthz and /debug/ready are kept for compatibility
+	for path, h := range map[string]http.Handler{
+		"/debug/livez":   http.HandlerFunc(livezHandler),
+		"/debug/healthz": http.HandlerFunc(livezHandler),
+		"/debug/readyz":  http.HandlerFunc(readyzHandler),
+		"/debug/ready":   http.HandlerFunc(readyzHandler),
+		"/debug/health":  healthHandler(opts.Token),
+	} {
+		http.Handle(path, promhttp.InstrumentHandlerCounter(
+			requestCount.MustCurryWith(prometheus.Labels{"handler": path}),
+			h,
+		))
+	}
 
-	http.Handle("/debug/ready", promhttp.InstrumentHandlerCounter(
-		requestCount.MustCurryWith(prometheus.Labels{"handler": "/debug/ready"}),
-		readyHandler,
+	http.Handle(statePrefix, promhttp.InstrumentHandlerCounter(
+		requestCount.MustCurryWith(prometheus.Labels{"handler": statePrefix}),
+		stateHandler(opts.Token),
 	))
 
 	handlers := map[string]string{
@@ -145,16 +144,18 @@ func Listen(opts *ListenOpts) (*Handler, error) {
 
 		// custom handlers for Kubernetes probes
 		"/debug/started": "Check if listener have started",
-		"/debug/healthz": "Check if listener is healthy",
-		"/debug/ready":   "Check if listener and backend are ready for queries",
+		"/debug/livez":   "Check if the process is alive",
+		"/debug/healthz": "Check if the process is alive (same as /debug/livez)",
+		"/debug/readyz":  "Check if listener and backend are ready for queries",
+		"/debug/ready":   "Check if listener and backend are ready for queries (same as /debug/readyz)",
+		"/debug/health":  "Detailed results of readiness probes as JSON",
 
 		// stdlib handlers
 		"/debug/vars":  "Expvar package metrics",
 		"/debug/pprof": "Runtime profiling data for pprof",
 	}
 
-	var page bytes.Buffer
-	must.NoError(template.Must(template.New("debug").Parse(`
+	page := template.Must(template.New("debug").Parse(`
 	<html>
 	<body>
 	<ul>
@@ -164,10 +165,16 @@ func Listen(opts *ListenOpts) (*Handler, error) {
 	</ul>
 	</body>
 	</html>
-	`)).Execute(&page, handlers))
+	`))
 
 	http.HandleFunc("/debug", func(rw http.ResponseWriter, _ *http.Request) {
-		rw.Write(page.Bytes())
+		// state pages could be registered after Listen
+		all := stateHandlers()
+		maps.Copy(all, handlers)
+
+		var buf bytes.Buffer
+		must.NoError(page.Execute(&buf, all))
+		rw.Write(buf.Bytes())
 	})
 
 	http.HandleFunc("/", func(rw http.ResponseWriter, req *http.Request) {
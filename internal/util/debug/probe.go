@@ -0,0 +1,152 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/maps"
+)
+
+// probeTimeout is the maximum time spent running all readiness probes.
+const probeTimeout = 5 * time.Second
+
+// ProbeFunc checks that some component is ready to serve requests.
+// It returns nil if it is.
+type ProbeFunc func(ctx context.Context) error
+
+var (
+	probesM sync.RWMutex
+	probes  = map[string]ProbeFunc{}
+)
+
+// RegisterProbe registers a readiness probe with the given name.
+//
+// FerretDB is ready only if at least one probe is registered and all probes pass.
+// It could be called before or after [Listen].
+func RegisterProbe(name string, f ProbeFunc) {
+	probesM.Lock()
+	defer probesM.Unlock()
+
+	if _, ok := probes[name]; ok {
+		panic("probe " + name + " is already registered")
+	}
+
+	probes[name] = f
+}
+
+// ProbeResult represents the result of a single readiness probe.
+type ProbeResult struct {
+	Ready    bool   `json:"ready"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// HealthStatus represents the detailed health status.
+type HealthStatus struct {
+	Ready  bool                    `json:"ready"`
+	Probes map[string]*ProbeResult `json:"probes"`
+}
+
+// runProbes runs all registered probes concurrently.
+func runProbes(ctx context.Context) *HealthStatus {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	probesM.RLock()
+	fs := maps.Clone(probes)
+	probesM.RUnlock()
+
+	res := &HealthStatus{
+		Ready:  len(fs) > 0,
+		Probes: make(map[string]*ProbeResult, len(fs)),
+	}
+
+	var wg sync.WaitGroup
+
+	for name, f := range fs {
+		r := new(ProbeResult)
+		res.Probes[name] = r
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			start := time.Now()
+			err := f(ctx)
+
+			r.Ready = err == nil
+			r.Duration = time.Since(start).String()
+
+			if err != nil {
+				r.Error = err.Error()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for _, r := range res.Probes {
+		res.Ready = res.Ready && r.Ready
+	}
+
+	return res
+}
+
+// livezHandler returns StatusOK when reached.
+// This ensures that the process and the debug listener are running.
+func livezHandler(rw http.ResponseWriter, _ *http.Request) {
+	rw.WriteHeader(http.StatusOK)
+}
+
+// readyzHandler returns StatusOK if all readiness probes pass, and StatusServiceUnavailable otherwise.
+func readyzHandler(rw http.ResponseWriter, req *http.Request) {
+	if !runProbes(req.Context()).Ready {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// healthHandler returns a handler that serves results of all readiness probes as JSON.
+//
+// Probe errors may contain internal details like backend addresses,
+// so token is checked the same way as for state pages.
+func healthHandler(token string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if token != "" && !checkToken(req, token) {
+			http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		res := runProbes(req.Context())
+
+		rw.Header().Set("Content-Type", "application/json")
+
+		if !res.Ready {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		enc := json.NewEncoder(rw)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(res)
+	})
+}
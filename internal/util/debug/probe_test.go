@@ -0,0 +1,80 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbes(t *testing.T) {
+	t.Parallel()
+
+	// probes are global, so subtests are not parallel
+
+	get := func(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return rec
+	}
+
+	t.Run("NoProbes", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, get(t, http.HandlerFunc(livezHandler), "/debug/livez").Code)
+		assert.Equal(t, http.StatusServiceUnavailable, get(t, http.HandlerFunc(readyzHandler), "/debug/readyz").Code)
+	})
+
+	t.Run("Ready", func(t *testing.T) {
+		RegisterProbe("ok", func(context.Context) error { return nil })
+
+		assert.Equal(t, http.StatusOK, get(t, http.HandlerFunc(readyzHandler), "/debug/readyz").Code)
+
+		rec := get(t, healthHandler(""), "/debug/health")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var res HealthStatus
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.True(t, res.Ready)
+		assert.True(t, res.Probes["ok"].Ready)
+	})
+
+	t.Run("NotReady", func(t *testing.T) {
+		RegisterProbe("fail", func(context.Context) error { return errors.New("backend is down") })
+
+		assert.Equal(t, http.StatusOK, get(t, http.HandlerFunc(livezHandler), "/debug/livez").Code)
+		assert.Equal(t, http.StatusServiceUnavailable, get(t, http.HandlerFunc(readyzHandler), "/debug/readyz").Code)
+
+		assert.Equal(t, http.StatusUnauthorized, get(t, healthHandler("secret"), "/debug/health").Code)
+
+		rec := get(t, healthHandler("secret"), "/debug/health?token=secret")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+		var res HealthStatus
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.False(t, res.Ready)
+		assert.True(t, res.Probes["ok"].Ready)
+		assert.False(t, res.Probes["fail"].Ready)
+		assert.Equal(t, "backend is down", res.Probes["fail"].Error)
+	})
+}
@@ -0,0 +1,184 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// statePrefix is a path prefix for state pages.
+const statePrefix = "/debug/state/"
+
+// StateFunc returns JSON-serializable state of some component.
+type StateFunc func() (any, error)
+
+// statePage represents a single registered state page.
+type statePage struct {
+	desc string
+	f    StateFunc
+}
+
+var (
+	statePagesM sync.RWMutex
+	statePages  = map[string]statePage{}
+)
+
+// RegisterState registers a state page with the given name and description.
+//
+// The page is served on /debug/state/<name> as JSON.
+// It could be called before or after [Listen].
+func RegisterState(name, desc string, f StateFunc) {
+	statePagesM.Lock()
+	defer statePagesM.Unlock()
+
+	if _, ok := statePages[name]; ok {
+		panic("state page " + name + " is already registered")
+	}
+
+	statePages[name] = statePage{desc: desc, f: f}
+}
+
+// stateHandlers returns paths and descriptions of registered state pages.
+func stateHandlers() map[string]string {
+	statePagesM.RLock()
+	defer statePagesM.RUnlock()
+
+	res := make(map[string]string, len(statePages))
+	for name, p := range statePages {
+		res[statePrefix+name] = p.desc
+	}
+
+	return res
+}
+
+// stateHandler returns a handler that serves registered state pages.
+//
+// If token is not empty, requests must provide it
+// either in the `Authorization: Bearer <token>` header or in the `token` query parameter.
+func stateHandler(token string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if token != "" && !checkToken(req, token) {
+			http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		statePagesM.RLock()
+		p, ok := statePages[strings.TrimPrefix(req.URL.Path, statePrefix)]
+		statePagesM.RUnlock()
+
+		if !ok {
+			http.NotFound(rw, req)
+			return
+		}
+
+		v, err := p.f()
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+
+		enc := json.NewEncoder(rw)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(v)
+	})
+}
+
+// checkToken returns true if the request contains the given token.
+func checkToken(req *http.Request, token string) bool {
+	actual, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		actual = req.URL.Query().Get("token")
+	}
+
+	return subtle.ConstantTimeCompare([]byte(actual), []byte(token)) == 1
+}
+
+// metricState represents a single metric value.
+type metricState struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+// CollectorState returns a [StateFunc] that returns current values of metrics from the given collector
+// as a map of metric names to values.
+//
+// Histograms and summaries are represented by sample sums.
+func CollectorState(c prometheus.Collector) StateFunc {
+	return func() (any, error) {
+		r := prometheus.NewRegistry()
+		if err := r.Register(c); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		mfs, err := r.Gather()
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res := make(map[string][]metricState, len(mfs))
+
+		for _, mf := range mfs {
+			metrics := make([]metricState, len(mf.GetMetric()))
+
+			for i, m := range mf.GetMetric() {
+				var labels map[string]string
+				if len(m.GetLabel()) > 0 {
+					labels = make(map[string]string, len(m.GetLabel()))
+					for _, l := range m.GetLabel() {
+						labels[l.GetName()] = l.GetValue()
+					}
+				}
+
+				metrics[i] = metricState{
+					Labels: labels,
+					Value:  metricValue(mf.GetType(), m),
+				}
+			}
+
+			res[mf.GetName()] = metrics
+		}
+
+		return res, nil
+	}
+}
+
+// metricValue returns a single value of the given metric.
+func metricValue(t dto.MetricType, m *dto.Metric) float64 {
+	switch t {
+	case dto.MetricType_COUNTER:
+		return m.GetCounter().GetValue()
+	case dto.MetricType_GAUGE:
+		return m.GetGauge().GetValue()
+	case dto.MetricType_SUMMARY:
+		return m.GetSummary().GetSampleSum()
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		return m.GetHistogram().GetSampleSum()
+	case dto.MetricType_UNTYPED:
+		fallthrough
+	default:
+		return m.GetUntyped().GetValue()
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateHandler(t *testing.T) {
+	t.Parallel()
+
+	RegisterState("test", "Test page", func() (any, error) {
+		return map[string]int{"answer": 42}, nil
+	})
+
+	h := stateHandler("secret")
+
+	for name, tc := range map[string]struct {
+		path   string
+		header string
+		code   int
+		body   string
+	}{
+		"NoToken": {
+			path: "/debug/state/test",
+			code: http.StatusUnauthorized,
+		},
+		"WrongToken": {
+			path:   "/debug/state/test",
+			header: "Bearer wrong",
+			code:   http.StatusUnauthorized,
+		},
+		"Header": {
+			path:   "/debug/state/test",
+			header: "Bearer secret",
+			code:   http.StatusOK,
+			body:   "{\n  \"answer\": 42\n}\n",
+		},
+		"Query": {
+			path: "/debug/state/test?token=secret",
+			code: http.StatusOK,
+			body: "{\n  \"answer\": 42\n}\n",
+		},
+		"NotFound": {
+			path: "/debug/state/unknown?token=secret",
+			code: http.StatusNotFound,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.code, rec.Code)
+
+			if tc.body != "" {
+				assert.Equal(t, tc.body, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestCollectorState(t *testing.T) {
+	t.Parallel()
+
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_gauge", Help: "Test gauge."}, []string{"pool"})
+	g.WithLabelValues("a").Set(3)
+
+	actual, err := CollectorState(g)()
+	require.NoError(t, err)
+
+	expected := map[string][]metricState{
+		"test_gauge": {{Labels: map[string]string{"pool": "a"}, Value: 3}},
+	}
+	assert.Equal(t, expected, actual)
+}
@@ -78,12 +78,16 @@ func (db *DB) Ping(ctx context.Context) error {
 func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*Rows, error) {
 	defer observability.FuncCall(ctx)()
 
+	ctx, span := startSpan(ctx, query)
+	defer span.End()
+
 	start := time.Now()
 
 	fields := []any{zap.Any("args", args)}
 	db.l.Sugar().With(fields...).Debugf(">>> %s", query)
 
 	rows, err := db.sqlDB.QueryContext(ctx, query, args...)
+	setSpanStatus(span, err)
 
 	fields = append(fields, zap.Duration("time", time.Since(start)), zap.Error(err))
 	db.l.Sugar().With(fields...).Debugf("<<< %s", query)
@@ -95,12 +99,16 @@ func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*Row
 func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
 	defer observability.FuncCall(ctx)()
 
+	ctx, span := startSpan(ctx, query)
+	defer span.End()
+
 	start := time.Now()
 
 	fields := []any{zap.Any("args", args)}
 	db.l.Sugar().With(fields...).Debugf(">>> %s", query)
 
 	row := db.sqlDB.QueryRowContext(ctx, query, args...)
+	setSpanStatus(span, row.Err())
 
 	fields = append(fields, zap.Duration("time", time.Since(start)), zap.Error(row.Err()))
 	db.l.Sugar().With(fields...).Debugf("<<< %s", query)
@@ -112,12 +120,16 @@ func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *s
 func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	defer observability.FuncCall(ctx)()
 
+	ctx, span := startSpan(ctx, query)
+	defer span.End()
+
 	start := time.Now()
 
 	fields := []any{zap.Any("args", args)}
 	db.l.Sugar().With(fields...).Debugf(">>> %s", query)
 
 	res, err := db.sqlDB.ExecContext(ctx, query, args...)
+	setSpanStatus(span, err)
 
 	// to differentiate between 0 and nil
 	var ra *int64
@@ -0,0 +1,53 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fsql
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	otelsemconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// startSpan starts a new client span for the given SQL query.
+//
+// The span is named after the SQL operation (like SELECT or INSERT).
+func startSpan(ctx context.Context, query string) (context.Context, oteltrace.Span) {
+	op, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	op = strings.ToUpper(op)
+
+	return otel.Tracer("").Start(
+		ctx,
+		op,
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(
+			otelsemconv.DBOperationKey.String(op),
+			otelsemconv.DBStatementKey.String(query),
+		),
+	)
+}
+
+// setSpanStatus sets the span status based on the given error.
+func setSpanStatus(span oteltrace.Span, err error) {
+	if err != nil {
+		span.SetStatus(otelcodes.Error, err.Error())
+		return
+	}
+
+	span.SetStatus(otelcodes.Ok, "")
+}
@@ -67,12 +67,16 @@ func (tx *Tx) Rollback() error {
 func (tx *Tx) QueryContext(ctx context.Context, query string, args ...any) (*Rows, error) {
 	defer observability.FuncCall(ctx)()
 
+	ctx, span := startSpan(ctx, query)
+	defer span.End()
+
 	start := time.Now()
 
 	fields := []any{zap.Any("args", args)}
 	tx.l.Sugar().With(fields...).Debugf(">>> %s", query)
 
 	rows, err := tx.sqlTx.QueryContext(ctx, query, args...)
+	setSpanStatus(span, err)
 
 	fields = append(fields, zap.Duration("time", time.Since(start)), zap.Error(err))
 	tx.l.Sugar().With(fields...).Debugf("<<< %s", query)
@@ -84,12 +88,16 @@ func (tx *Tx) QueryContext(ctx context.Context, query string, args ...any) (*Row
 func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
 	defer observability.FuncCall(ctx)()
 
+	ctx, span := startSpan(ctx, query)
+	defer span.End()
+
 	start := time.Now()
 
 	fields := []any{zap.Any("args", args)}
 	tx.l.Sugar().With(fields...).Debugf(">>> %s", query)
 
 	row := tx.sqlTx.QueryRowContext(ctx, query, args...)
+	setSpanStatus(span, row.Err())
 
 	fields = append(fields, zap.Duration("time", time.Since(start)), zap.Error(row.Err()))
 	tx.l.Sugar().With(fields...).Debugf("<<< %s", query)
@@ -101,12 +109,16 @@ func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...any) *s
 func (tx *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	defer observability.FuncCall(ctx)()
 
+	ctx, span := startSpan(ctx, query)
+	defer span.End()
+
 	start := time.Now()
 
 	fields := []any{zap.Any("args", args)}
 	tx.l.Sugar().With(fields...).Debugf(">>> %s", query)
 
 	res, err := tx.sqlTx.ExecContext(ctx, query, args...)
+	setSpanStatus(span, err)
 
 	// to differentiate between 0 and nil
 	var ra *int64
@@ -15,15 +15,15 @@
 package logging
 
 import (
-	"encoding/json"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
 	"github.com/FerretDB/FerretDB/internal/types"
-	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
 // RecentEntries implements zap logging entries interception
@@ -35,6 +35,10 @@ type circularBuffer struct {
 	mu    sync.RWMutex
 	log   []*zapcore.Entry
 	index int64
+	total int64 // total number of appended entries, including overwritten ones
+
+	// startup warnings are also kept there so they are not overwritten
+	startupWarnings []*zapcore.Entry
 }
 
 // NewCircularBuffer creates a circular buffer for log entries in memory.
@@ -55,6 +59,7 @@ func (l *circularBuffer) append(entry *zapcore.Entry) {
 
 	l.log[l.index] = entry
 	l.index = (l.index + 1) % int64(len(l.log))
+	l.total++
 }
 
 // get returns entries from circularBuffer with level at minLevel or above.
@@ -75,27 +80,64 @@ func (l *circularBuffer) get(minLevel zapcore.Level) []*zapcore.Entry {
 	return entries
 }
 
-// GetArray is a version of Get that returns an array as expected by mongosh.
+// AddStartupWarning logs a startup warning with the given logger and keeps it,
+// so it is returned by [circularBuffer.GetStartupWarnings] even after being overwritten in the buffer.
+//
+// Warnings with the same message are logged and stored only once.
+func (l *circularBuffer) AddStartupWarning(logger *zap.Logger, msg string) {
+	l.mu.Lock()
+
+	if slices.ContainsFunc(l.startupWarnings, func(e *zapcore.Entry) bool { return e.Message == msg }) {
+		l.mu.Unlock()
+		return
+	}
+
+	l.startupWarnings = append(l.startupWarnings, &zapcore.Entry{
+		Level:      zap.WarnLevel,
+		Time:       time.Now(),
+		LoggerName: logger.Name(),
+		Message:    msg,
+	})
+
+	// unlock before logging because hooks append entries to the buffer
+	l.mu.Unlock()
+
+	logger.Warn(msg)
+}
+
+// GetStartupWarnings returns stored startup warnings as an array of log lines
+// in MongoDB structured log format with "startupWarnings" tag.
+func (l *circularBuffer) GetStartupWarnings() *types.Array {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	res := types.MakeArray(len(l.startupWarnings))
+
+	for _, e := range l.startupWarnings {
+		line := mongoLine(e.Time, SlogLevel(e.Level), e.LoggerName, e.Message, nil, "startupWarnings")
+		res.Append(string(line))
+	}
+
+	return res
+}
+
+// TotalLinesWritten returns the total number of entries appended to circularBuffer,
+// including entries that were already overwritten.
+func (l *circularBuffer) TotalLinesWritten() int64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.total
+}
+
+// GetArray is a version of Get that returns an array of log lines
+// in MongoDB structured log format as expected by mongosh.
 func (l *circularBuffer) GetArray(minLevel zapcore.Level) (*types.Array, error) {
 	entries := l.get(minLevel)
 	res := types.MakeArray(len(entries))
 
 	for _, e := range entries {
-		b, err := json.Marshal(map[string]any{
-			"t": map[string]time.Time{
-				"$date": e.Time,
-			},
-			"l":   e.Level,
-			"ln":  e.LoggerName,
-			"msg": e.Message,
-			"c":   e.Caller,
-			"s":   e.Stack,
-		})
-		if err != nil {
-			return nil, lazyerrors.Error(err)
-		}
-
-		res.Append(string(b))
+		res.Append(string(mongoLine(e.Time, SlogLevel(e.Level), e.LoggerName, e.Message, nil)))
 	}
 
 	return res, nil
@@ -16,12 +16,16 @@ package logging
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 func TestCircularBuffer(t *testing.T) {
@@ -139,3 +143,52 @@ func TestCircularBuffer(t *testing.T) {
 		})
 	}
 }
+
+func TestCircularBufferGetArray(t *testing.T) {
+	t.Parallel()
+
+	b := NewCircularBuffer(2)
+
+	for i := range 3 {
+		b.append(&zapcore.Entry{
+			Level:      zapcore.WarnLevel,
+			Time:       time.Date(2024, 6, 1, 12, 0, i, 0, time.UTC),
+			LoggerName: "listener",
+			Message:    fmt.Sprintf("message %d", i),
+		})
+	}
+
+	assert.Equal(t, int64(3), b.TotalLinesWritten())
+
+	arr, err := b.GetArray(zap.DebugLevel)
+	require.NoError(t, err)
+	require.Equal(t, 2, arr.Len())
+
+	id := mongoMessageID("message 2")
+	expected := fmt.Sprintf(
+		`{"t":{"$date":"2024-06-01T12:00:02.000+00:00"},"s":"W","c":"NETWORK","id":%d,"ctx":"listener","msg":"message 2"}`,
+		id,
+	)
+	assert.Equal(t, expected, must.NotFail(arr.Get(1)))
+
+	arr, err = b.GetArray(zap.ErrorLevel)
+	require.NoError(t, err)
+	assert.Equal(t, 0, arr.Len())
+}
+
+func TestCircularBufferStartupWarnings(t *testing.T) {
+	b := NewCircularBuffer(1)
+	l := zap.NewNop().Named("listener")
+
+	b.AddStartupWarning(l, "warning 1")
+	b.AddStartupWarning(l, "warning 2")
+	b.AddStartupWarning(l, "warning 1")
+
+	arr := b.GetStartupWarnings()
+	require.Equal(t, 2, arr.Len())
+
+	line := must.NotFail(arr.Get(1)).(string)
+	assert.Contains(t, line, `"s":"W","c":"NETWORK"`)
+	assert.Contains(t, line, fmt.Sprintf(`"id":%d,"ctx":"listener","msg":"warning 2"`, mongoMessageID("warning 2")))
+	assert.True(t, strings.HasSuffix(line, `"tags":["startupWarnings"]}`), line)
+}
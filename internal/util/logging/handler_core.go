@@ -0,0 +1,112 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// loggerNameKey is the attribute key for zap logger name.
+const loggerNameKey = "logger"
+
+// handlerCore is a [zapcore.Core] that sends zap entries to [slog.Handler].
+//
+// It allows additional slog handlers (like OTLP) to receive zap logs
+// until we replace zap with slog everywhere.
+// TODO https://github.com/FerretDB/FerretDB/issues/4013
+type handlerCore struct {
+	h slog.Handler
+}
+
+// newHandlerCore creates a new handlerCore.
+func newHandlerCore(h slog.Handler) *handlerCore {
+	return &handlerCore{
+		h: h,
+	}
+}
+
+// Enabled implements [zapcore.Core].
+func (c *handlerCore) Enabled(level zapcore.Level) bool {
+	return c.h.Enabled(context.Background(), SlogLevel(level))
+}
+
+// With implements [zapcore.Core].
+func (c *handlerCore) With(fields []zapcore.Field) zapcore.Core {
+	return &handlerCore{
+		h: c.h.WithAttrs(slogAttrs(fields)),
+	}
+}
+
+// Check implements [zapcore.Core].
+func (c *handlerCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+
+	return ce
+}
+
+// Write implements [zapcore.Core].
+func (c *handlerCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	r := slog.NewRecord(entry.Time, SlogLevel(entry.Level), entry.Message, 0)
+
+	if entry.LoggerName != "" {
+		r.AddAttrs(slog.String(loggerNameKey, entry.LoggerName))
+	}
+
+	if entry.Caller.Defined {
+		r.AddAttrs(slog.String("caller", entry.Caller.TrimmedPath()))
+	}
+
+	r.AddAttrs(slogAttrs(fields)...)
+
+	return c.h.Handle(context.Background(), r)
+}
+
+// Sync implements [zapcore.Core].
+func (c *handlerCore) Sync() error {
+	return nil
+}
+
+// slogAttrs converts zap fields to slog attributes.
+func slogAttrs(fields []zapcore.Field) []slog.Attr {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	keys := make([]string, 0, len(enc.Fields))
+	for k := range enc.Fields {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	res := make([]slog.Attr, len(keys))
+	for i, k := range keys {
+		res[i] = slog.Any(k, enc.Fields[k])
+	}
+
+	return res
+}
+
+// check interfaces
+var (
+	_ zapcore.Core = (*handlerCore)(nil)
+)
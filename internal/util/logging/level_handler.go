@@ -0,0 +1,76 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"go.uber.org/zap"
+)
+
+// levelHandler is a [slog.Handler] that drops records below the given level
+// before passing them to the wrapped handler.
+type levelHandler struct {
+	h     slog.Handler
+	level slog.Leveler
+}
+
+// newLevelHandler creates a new levelHandler.
+func newLevelHandler(h slog.Handler, level slog.Leveler) *levelHandler {
+	return &levelHandler{
+		h:     h,
+		level: level,
+	}
+}
+
+// Enabled implements [slog.Handler].
+func (h *levelHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return l >= h.level.Level() && h.h.Enabled(ctx, l)
+}
+
+// Handle implements [slog.Handler].
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.h.Handle(ctx, r)
+}
+
+// WithAttrs implements [slog.Handler].
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return newLevelHandler(h.h.WithAttrs(attrs), h.level)
+}
+
+// WithGroup implements [slog.Handler].
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return newLevelHandler(h.h.WithGroup(name), h.level)
+}
+
+// NewZapLogger returns a zap logger that sends all entries to the given slog handler,
+// so FerretDB logs could be handled by the application's logging.
+//
+// If level is not nil, entries below it are dropped even if the handler is enabled for them;
+// level could be changed at runtime with [*slog.LevelVar].
+// Logger names are passed as attributes with "logger" key.
+func NewZapLogger(h slog.Handler, level slog.Leveler) *zap.Logger {
+	if level != nil {
+		h = newLevelHandler(h, level)
+	}
+
+	return zap.New(newHandlerCore(h), zap.AddCaller())
+}
+
+// check interfaces
+var (
+	_ slog.Handler = (*levelHandler)(nil)
+)
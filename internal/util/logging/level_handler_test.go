@@ -0,0 +1,52 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewZapLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	h := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "caller" {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	})
+
+	level := new(slog.LevelVar)
+	l := NewZapLogger(h, level).Named("test")
+
+	l.Debug("dropped")
+	l.Info("logged")
+
+	level.Set(slog.LevelDebug)
+	l.Debug("debug")
+
+	expected := "level=INFO msg=logged logger=test\n" +
+		"level=DEBUG msg=debug logger=test\n"
+	assert.Equal(t, expected, buf.String())
+}
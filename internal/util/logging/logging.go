@@ -17,6 +17,7 @@ package logging
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
 	"os"
@@ -38,12 +39,58 @@ var logLevels = map[zapcore.Level]slog.Level{
 	zapcore.FatalLevel:  slog.LevelError,
 }
 
+// SlogLevel converts zap log level to slog log level.
+func SlogLevel(level zapcore.Level) slog.Level {
+	if l, ok := logLevels[level]; ok {
+		return l
+	}
+
+	return slog.LevelError
+}
+
+// SetupOpts represents logging setup options.
+type SetupOpts struct {
+	Level    zapcore.Level
+	Encoding string
+	UUID     string
+
+	// Output is used instead of the standard error stream if set (for example, [*RotatingFile]).
+	Output io.Writer
+
+	// Additional handlers (like [OTLPHandler]) receive both slog and zap records
+	// in addition to the output.
+	Handlers []slog.Handler
+
+	// If true, debug entries with the same message are sampled.
+	SampleDebug bool
+}
+
 // Setup initializes logging with a given level.
 func Setup(level zapcore.Level, encoding, uuid string) {
-	setupSlog(level, encoding)
+	SetupWithOpts(&SetupOpts{
+		Level:    level,
+		Encoding: encoding,
+		UUID:     uuid,
+	})
+}
+
+// SetupWithOpts initializes logging with given options.
+func SetupWithOpts(opts *SetupOpts) {
+	level, encoding, uuid, handlers := opts.Level, opts.Encoding, opts.UUID, opts.Handlers
+
+	output := opts.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	// levels could be changed at runtime; see Verbosity
+	Verbosity.reset(SlogLevel(level))
+
+	setupSlog(level, encoding, output, handlers)
 
 	config := zap.Config{
-		Level:             zap.NewAtomicLevelAt(level),
+		// entries are filtered by verbosityCore
+		Level:             zap.NewAtomicLevelAt(zapcore.DebugLevel),
 		Development:       debugbuild.Enabled,
 		DisableCaller:     false,
 		DisableStacktrace: false,
@@ -75,7 +122,66 @@ func Setup(level zapcore.Level, encoding, uuid string) {
 		config.InitialFields = map[string]any{"uuid": uuid}
 	}
 
-	logger, err := config.Build()
+	var zapOpts []zap.Option
+
+	// zap's initial fields are not added to replaced cores
+	withUUID := func(c zapcore.Core) zapcore.Core {
+		if uuid == "" {
+			return c
+		}
+
+		return c.With([]zapcore.Field{zap.String("uuid", uuid)})
+	}
+
+	switch {
+	case encoding == "mongo":
+		// zap does not support that format; send entries to slog handler instead
+		config.Encoding = "json"
+		config.OutputPaths = nil
+
+		h := NewMongoHandler(output, &NewMongoHandlerOpts{Level: slog.LevelDebug})
+
+		zapOpts = append(zapOpts, zap.WrapCore(func(zapcore.Core) zapcore.Core {
+			return withUUID(newHandlerCore(h))
+		}))
+
+	case opts.Output != nil:
+		// replace stderr core with the core that writes to the given output
+		config.OutputPaths = nil
+
+		zapOpts = append(zapOpts, zap.WrapCore(func(zapcore.Core) zapcore.Core {
+			var enc zapcore.Encoder
+			if encoding == "json" {
+				enc = zapcore.NewJSONEncoder(config.EncoderConfig)
+			} else {
+				enc = zapcore.NewConsoleEncoder(config.EncoderConfig)
+			}
+
+			return withUUID(zapcore.NewCore(enc, zapcore.AddSync(opts.Output), config.Level))
+		}))
+	}
+
+	if len(handlers) > 0 {
+		cores := make([]zapcore.Core, len(handlers))
+		for i, h := range handlers {
+			if uuid != "" {
+				h = h.WithAttrs([]slog.Attr{slog.String("uuid", uuid)})
+			}
+
+			cores[i] = newHandlerCore(h)
+		}
+
+		zapOpts = append(zapOpts, zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(append([]zapcore.Core{c}, cores...)...)
+		}))
+	}
+
+	// should be the last to wrap all cores above
+	zapOpts = append(zapOpts, zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return newVerbosityCore(c, Verbosity, opts.SampleDebug)
+	}))
+
+	logger, err := config.Build(zapOpts...)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -91,8 +197,8 @@ func WithHooks(logger *zap.Logger) *zap.Logger {
 	}))
 }
 
-// setupSlog initializes slog logging with a given level.
-func setupSlog(level zapcore.Level, encoding string) {
+// setupSlog initializes slog logging with a given level, output, and additional handlers.
+func setupSlog(level zapcore.Level, encoding string, output io.Writer, handlers []slog.Handler) {
 	// We either should replace zap with slog everywhere,
 	// or use zap's handler for slog,
 	// See https://github.com/uber-go/zap/issues/1270 and https://github.com/uber-go/zap/issues/1333.
@@ -100,27 +206,32 @@ func setupSlog(level zapcore.Level, encoding string) {
 	//
 	// For now, just setup slog in parallel.
 
-	slogLevel, ok := logLevels[level]
-	if !ok {
+	if _, ok := logLevels[level]; !ok {
 		panic(fmt.Sprintf("invalid log level %d", level))
 	}
 
 	slogOpts := &slog.HandlerOptions{
 		AddSource: false,
-		Level:     slogLevel,
+		Level:     Verbosity.level,
 	}
 
 	var slogHandler slog.Handler
 
 	switch encoding {
 	case "console":
-		slogHandler = slog.NewTextHandler(os.Stderr, slogOpts)
+		slogHandler = slog.NewTextHandler(output, slogOpts)
 	case "json":
-		slogHandler = slog.NewJSONHandler(os.Stderr, slogOpts)
+		slogHandler = slog.NewJSONHandler(output, slogOpts)
+	case "mongo":
+		slogHandler = NewMongoHandler(output, &NewMongoHandlerOpts{Level: Verbosity.level})
 	default:
 		panic(fmt.Sprintf("invalid log encoding %q", encoding))
 	}
 
+	if len(handlers) > 0 {
+		slogHandler = newMultiHandler(append([]slog.Handler{slogHandler}, handlers...)...)
+	}
+
 	slog.SetDefault(slog.New(slogHandler))
 }
 
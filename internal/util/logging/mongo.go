@@ -0,0 +1,413 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// mongoTimeFormat is the timestamp format used by MongoDB ("iso8601-local").
+const mongoTimeFormat = "2006-01-02T15:04:05.000-07:00"
+
+// mongoComponents maps parts of the logger name to MongoDB log components.
+var mongoComponents = map[string]string{
+	"access":     "ACCESS",
+	"listener":   "NETWORK",
+	"cursors":    "QUERY",
+	"oplog":      "REPL",
+	"postgresql": "STORAGE",
+	"sqlite":     "STORAGE",
+	"mysql":      "STORAGE",
+	"hana":       "STORAGE",
+	"setup":      "STORAGE",
+	"debug":      "CONTROL",
+	"telemetry":  "CONTROL",
+}
+
+// NewMongoHandlerOpts represents MongoDB log handler options.
+type NewMongoHandlerOpts struct {
+	Level slog.Leveler
+}
+
+// mongoGroupOrAttrs holds either a group name or attributes added with WithGroup or WithAttrs.
+type mongoGroupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// MongoHandler is a [slog.Handler] that writes records in MongoDB structured log format
+// (one JSON document per line with t, s, c, id, ctx, msg, and attr fields),
+// so tools built for mongod logs (like mtools) could parse FerretDB logs.
+//
+// Component (c) and context (ctx) fields are derived from the logger name
+// (the top-level attribute with [loggerNameKey] key).
+// Message ID (id) is a hash of the message, so it is stable for the same message.
+type MongoHandler struct {
+	out   io.Writer
+	mu    *sync.Mutex
+	level slog.Leveler
+	goas  []mongoGroupOrAttrs
+	name  string // logger name
+}
+
+// NewMongoHandler creates a new MongoDB log handler that writes to the given writer.
+func NewMongoHandler(out io.Writer, opts *NewMongoHandlerOpts) *MongoHandler {
+	if opts == nil {
+		opts = new(NewMongoHandlerOpts)
+	}
+
+	level := opts.Level
+	if level == nil {
+		level = slog.LevelInfo
+	}
+
+	return &MongoHandler{
+		out:   out,
+		mu:    new(sync.Mutex),
+		level: level,
+	}
+}
+
+// Enabled implements [slog.Handler].
+func (h *MongoHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+// Handle implements [slog.Handler].
+func (h *MongoHandler) Handle(_ context.Context, r slog.Record) error {
+	name := h.name
+
+	var recordAttrs []slog.Attr
+
+	r.Attrs(func(attr slog.Attr) bool {
+		if !h.grouped() {
+			if n, ok := mongoLoggerName(attr); ok {
+				name = n
+				return true
+			}
+		}
+
+		recordAttrs = append(recordAttrs, attr)
+
+		return true
+	})
+
+	// build attr object, nesting groups
+	root := map[string]any{}
+	cur := root
+
+	for _, goa := range h.goas {
+		if goa.group != "" {
+			m := map[string]any{}
+			cur[goa.group] = m
+			cur = m
+
+			continue
+		}
+
+		addMongoAttrs(cur, goa.attrs)
+	}
+
+	addMongoAttrs(cur, recordAttrs)
+	removeEmptyGroups(root)
+
+	b := mongoLine(r.Time, r.Level, name, r.Message, root)
+	b = append(b, '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, err := h.out.Write(b); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// WithAttrs implements [slog.Handler].
+func (h *MongoHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	res := *h
+
+	if !h.grouped() {
+		rest := make([]slog.Attr, 0, len(attrs))
+
+		for _, attr := range attrs {
+			if n, ok := mongoLoggerName(attr); ok {
+				res.name = n
+				continue
+			}
+
+			rest = append(rest, attr)
+		}
+
+		attrs = rest
+	}
+
+	res.goas = append(h.goas[:len(h.goas):len(h.goas)], mongoGroupOrAttrs{attrs: attrs})
+
+	return &res
+}
+
+// WithGroup implements [slog.Handler].
+func (h *MongoHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	res := *h
+	res.goas = append(h.goas[:len(h.goas):len(h.goas)], mongoGroupOrAttrs{group: name})
+
+	return &res
+}
+
+// mongoLine returns a single log line (without a trailing newline) in MongoDB structured log format.
+//
+// Tags (like "startupWarnings") are added if not empty.
+func mongoLine(t time.Time, l slog.Level, name, msg string, attr map[string]any, tags ...string) []byte {
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	c, ctx := mongoComponentContext(name)
+
+	var buf bytes.Buffer
+
+	buf.WriteString(`{"t":{"$date":`)
+	buf.WriteString(strconv.Quote(t.Format(mongoTimeFormat)))
+	buf.WriteString(`},"s":`)
+	buf.WriteString(strconv.Quote(mongoSeverity(l)))
+	buf.WriteString(`,"c":`)
+	buf.Write(mongoJSON(c))
+	buf.WriteString(`,"id":`)
+	buf.WriteString(strconv.FormatUint(uint64(mongoMessageID(msg)), 10))
+	buf.WriteString(`,"ctx":`)
+	buf.Write(mongoJSON(ctx))
+	buf.WriteString(`,"msg":`)
+	buf.Write(mongoJSON(msg))
+
+	if len(attr) > 0 {
+		buf.WriteString(`,"attr":`)
+		buf.Write(mongoJSON(attr))
+	}
+
+	if len(tags) > 0 {
+		buf.WriteString(`,"tags":`)
+		buf.Write(mongoJSON(tags))
+	}
+
+	buf.WriteString("}")
+
+	return buf.Bytes()
+}
+
+// grouped returns true if WithGroup was called.
+func (h *MongoHandler) grouped() bool {
+	for _, goa := range h.goas {
+		if goa.group != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mongoLoggerName returns the logger name if the given attribute contains it.
+func mongoLoggerName(attr slog.Attr) (string, bool) {
+	if attr.Key != loggerNameKey {
+		return "", false
+	}
+
+	return attr.Value.Resolve().String(), true
+}
+
+// mongoSeverity converts slog level to MongoDB severity.
+//
+// Levels below [slog.LevelDebug] are mapped to increasing debug verbosity levels (D2-D5).
+func mongoSeverity(l slog.Level) string {
+	switch {
+	case l > slog.LevelError:
+		return "F"
+	case l >= slog.LevelError:
+		return "E"
+	case l >= slog.LevelWarn:
+		return "W"
+	case l >= slog.LevelInfo:
+		return "I"
+	case l >= slog.LevelDebug:
+		return "D1"
+	default:
+		return "D" + strconv.Itoa(min(int(slog.LevelDebug-l)+1, 5))
+	}
+}
+
+// mongoComponentContext returns MongoDB log component and context for the given logger name.
+func mongoComponentContext(name string) (string, string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "-", "main"
+	}
+
+	// client connection loggers are named "// <remote> -> <local> "
+	if conn, ok := strings.CutPrefix(name, "// "); ok {
+		return "COMMAND", "conn " + strings.TrimSpace(conn)
+	}
+
+	// the most specific part wins: "postgresql.oplog" is REPL
+	parts := strings.Split(name, ".")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if c, ok := mongoComponents[parts[i]]; ok {
+			return c, name
+		}
+	}
+
+	return "-", name
+}
+
+// mongoMessageID returns a stable message ID for the given message.
+func mongoMessageID(msg string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(msg))
+
+	// MongoDB uses up to 8 decimal digits
+	return h.Sum32() % 100_000_000
+}
+
+// addMongoAttrs adds slog attributes to the given map.
+//
+// Empty attributes are ignored, and groups with empty keys are inlined, see [slog.Handler].
+func addMongoAttrs(m map[string]any, attrs []slog.Attr) {
+	for _, attr := range attrs {
+		attr.Value = attr.Value.Resolve()
+
+		if attr.Equal(slog.Attr{}) {
+			continue
+		}
+
+		if attr.Value.Kind() == slog.KindGroup {
+			if attr.Key == "" {
+				addMongoAttrs(m, attr.Value.Group())
+				continue
+			}
+
+			g := map[string]any{}
+			addMongoAttrs(g, attr.Value.Group())
+			m[attr.Key] = g
+
+			continue
+		}
+
+		m[attr.Key] = mongoValue(attr.Value)
+	}
+}
+
+// removeEmptyGroups removes nested maps without values, see [slog.Handler].
+func removeEmptyGroups(m map[string]any) {
+	for k, v := range m {
+		g, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		removeEmptyGroups(g)
+
+		if len(g) == 0 {
+			delete(m, k)
+		}
+	}
+}
+
+// mongoValue converts resolved slog value to a value that could be marshaled to JSON.
+func mongoValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+
+	case slog.KindInt64:
+		return v.Int64()
+
+	case slog.KindUint64:
+		return v.Uint64()
+
+	case slog.KindFloat64:
+		return v.Float64()
+
+	case slog.KindBool:
+		return v.Bool()
+
+	case slog.KindDuration:
+		return v.Duration().String()
+
+	case slog.KindTime:
+		return map[string]any{"$date": v.Time().Format(mongoTimeFormat)}
+
+	case slog.KindAny:
+		switch a := v.Any().(type) {
+		case error:
+			return a.Error()
+		case json.Marshaler:
+			return a
+		case fmt.Stringer:
+			return a.String()
+		default:
+			if _, err := json.Marshal(a); err != nil {
+				return fmt.Sprintf("%+v", a)
+			}
+
+			return a
+		}
+
+	case slog.KindGroup, slog.KindLogValuer:
+		fallthrough
+
+	default:
+		panic(fmt.Sprintf("unexpected slog value kind %s", v.Kind()))
+	}
+}
+
+// mongoJSON marshals the given value to JSON without escaping HTML characters.
+func mongoJSON(v any) []byte {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(v); err != nil {
+		b, _ := json.Marshal(fmt.Sprintf("%+v", v))
+		return b
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+// check interfaces
+var (
+	_ slog.Handler = (*MongoHandler)(nil)
+)
@@ -0,0 +1,116 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestMongoHandler(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 6, 1, 12, 0, 0, 123_000_000, time.UTC)
+
+	t.Run("Slog", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		h := NewMongoHandler(&buf, &NewMongoHandlerOpts{Level: slog.LevelDebug})
+
+		l := slog.New(h).With(slog.String(loggerNameKey, "postgresql.oplog"), slog.Int("n", 1)).WithGroup("g")
+
+		r := slog.NewRecord(ts, slog.LevelWarn, "Hello <world>", 0)
+		r.AddAttrs(
+			slog.String("s", "v"),
+			slog.Any("err", errors.New("boom")),
+			slog.Any("doc", must.NotFail(types.NewDocument("_id", int64(42), "v", "<x>"))),
+			slog.Group("empty"),
+		)
+		require.NoError(t, l.Handler().Handle(context.Background(), r))
+
+		id := strconv.FormatUint(uint64(mongoMessageID("Hello <world>")), 10)
+		expected := `{"t":{"$date":"2024-06-01T12:00:00.123+00:00"},"s":"W","c":"REPL","id":` + id +
+			`,"ctx":"postgresql.oplog","msg":"Hello <world>","attr":{"g":{"doc":{"_id":42,"v":"<x>"},"err":"boom","s":"v"},"n":1}}` + "\n"
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("Zap", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		h := NewMongoHandler(&buf, nil)
+
+		l := zap.New(newHandlerCore(h)).Named("// 127.0.0.1:1234 -> 127.0.0.1:27017 ")
+		l.Info("Connection started", zap.Int("id", 42))
+		l.Debug("Not logged")
+
+		assert.Regexp(
+			t,
+			`^\{"t":\{"\$date":"[^"]+"\},"s":"I","c":"COMMAND","id":\d+,`+
+				`"ctx":"conn 127.0.0.1:1234 -> 127.0.0.1:27017","msg":"Connection started","attr":\{"id":42\}\}\n$`,
+			buf.String(),
+		)
+	})
+}
+
+func TestMongoSeverity(t *testing.T) {
+	t.Parallel()
+
+	for l, expected := range map[slog.Level]string{
+		SlogLevel(zapcore.FatalLevel): "E",
+		slog.LevelError + 1:           "F",
+		slog.LevelError:               "E",
+		slog.LevelWarn:                "W",
+		slog.LevelInfo:                "I",
+		slog.LevelInfo - 1:            "D1",
+		slog.LevelDebug:               "D1",
+		slog.LevelDebug - 1:           "D2",
+		slog.LevelDebug - 4:           "D5",
+		slog.LevelDebug - 100:         "D5",
+	} {
+		assert.Equal(t, expected, mongoSeverity(l), "level %s", l)
+	}
+}
+
+func TestMongoComponentContext(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string][2]string{
+		"":                  {"-", "main"},
+		"listener":          {"NETWORK", "listener"},
+		"postgresql":        {"STORAGE", "postgresql"},
+		"postgresql.oplog":  {"REPL", "postgresql.oplog"},
+		"unknown":           {"-", "unknown"},
+		"// a -> b ":        {"COMMAND", "conn a -> b"},
+		"telemetry.unknown": {"CONTROL", "telemetry.unknown"},
+	} {
+		c, ctx := mongoComponentContext(name)
+		assert.Equal(t, expected, [2]string{c, ctx}, "name %q", name)
+	}
+}
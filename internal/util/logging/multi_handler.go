@@ -0,0 +1,86 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// multiHandler is a [slog.Handler] that sends records to all enabled handlers.
+type multiHandler struct {
+	hs []slog.Handler
+}
+
+// newMultiHandler creates a new multiHandler.
+func newMultiHandler(hs ...slog.Handler) *multiHandler {
+	return &multiHandler{
+		hs: hs,
+	}
+}
+
+// Enabled implements [slog.Handler].
+func (h *multiHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, h := range h.hs {
+		if h.Enabled(ctx, l) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Handle implements [slog.Handler].
+func (h *multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+
+	for _, h := range h.hs {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+
+		if err := h.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithAttrs implements [slog.Handler].
+func (h *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	hs := make([]slog.Handler, len(h.hs))
+	for i, h := range h.hs {
+		hs[i] = h.WithAttrs(attrs)
+	}
+
+	return newMultiHandler(hs...)
+}
+
+// WithGroup implements [slog.Handler].
+func (h *multiHandler) WithGroup(name string) slog.Handler {
+	hs := make([]slog.Handler, len(h.hs))
+	for i, h := range h.hs {
+		hs[i] = h.WithGroup(name)
+	}
+
+	return newMultiHandler(hs...)
+}
+
+// check interfaces
+var (
+	_ slog.Handler = (*multiHandler)(nil)
+)
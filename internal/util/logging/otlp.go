@@ -0,0 +1,279 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	otellog "go.opentelemetry.io/otel/log"
+	otelsdklog "go.opentelemetry.io/otel/sdk/log"
+	otelsdkresource "go.opentelemetry.io/otel/sdk/resource"
+	otelsemconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// NewOTLPHandlerOpts represents OTLP handler options.
+type NewOTLPHandlerOpts struct {
+	// OTLP/HTTP logs endpoint URL, for example, "http://127.0.0.1:4318/v1/logs".
+	URL string
+
+	Level   slog.Leveler
+	Service string
+	Version string
+
+	// Batching settings; zero values mean defaults.
+	ExportInterval time.Duration
+	MaxBatchSize   int
+}
+
+// OTLPHandler is a [slog.Handler] that ships log records to OpenTelemetry collector over OTLP/HTTP.
+//
+// Records are batched and exported in the background.
+// Exports that failed with retryable errors are retried with exponential backoff.
+//
+// Trace and span IDs are taken from the context passed to Handle, if any.
+type OTLPHandler struct {
+	p      *otelsdklog.LoggerProvider
+	l      otellog.Logger
+	level  slog.Leveler
+	attrs  []otellog.KeyValue
+	prefix string // group names joined and followed by dots
+}
+
+// NewOTLPHandler creates a new OTLP handler.
+//
+// [OTLPHandler.Shutdown] should be called to flush buffered records on exit.
+func NewOTLPHandler(opts *NewOTLPHandlerOpts) (*OTLPHandler, error) {
+	exporter, err := otlploghttp.New(
+		context.TODO(),
+		otlploghttp.WithEndpointURL(opts.URL),
+		otlploghttp.WithRetry(otlploghttp.RetryConfig{
+			Enabled:         true,
+			InitialInterval: time.Second,
+			MaxInterval:     10 * time.Second,
+			MaxElapsedTime:  time.Minute,
+		}),
+	)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var batchOpts []otelsdklog.BatchProcessorOption
+
+	if opts.ExportInterval > 0 {
+		batchOpts = append(batchOpts, otelsdklog.WithExportInterval(opts.ExportInterval))
+	}
+
+	if opts.MaxBatchSize > 0 {
+		batchOpts = append(batchOpts, otelsdklog.WithExportMaxBatchSize(opts.MaxBatchSize))
+	}
+
+	resource := otelsdkresource.NewSchemaless(
+		otelsemconv.ServiceNameKey.String(opts.Service),
+		otelsemconv.ServiceVersionKey.String(opts.Version),
+	)
+
+	p := otelsdklog.NewLoggerProvider(
+		otelsdklog.WithProcessor(otelsdklog.NewBatchProcessor(exporter, batchOpts...)),
+		otelsdklog.WithResource(resource),
+	)
+
+	level := opts.Level
+	if level == nil {
+		level = slog.LevelInfo
+	}
+
+	return &OTLPHandler{
+		p:     p,
+		l:     p.Logger("github.com/FerretDB/FerretDB"),
+		level: level,
+	}, nil
+}
+
+// Enabled implements [slog.Handler].
+func (h *OTLPHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+// Handle implements [slog.Handler].
+func (h *OTLPHandler) Handle(ctx context.Context, r slog.Record) error {
+	var record otellog.Record
+
+	record.SetTimestamp(r.Time)
+	record.SetObservedTimestamp(time.Now())
+	record.SetSeverity(otlpSeverity(r.Level))
+	record.SetSeverityText(r.Level.String())
+	record.SetBody(otellog.StringValue(r.Message))
+
+	record.AddAttributes(h.attrs...)
+
+	r.Attrs(func(attr slog.Attr) bool {
+		record.AddAttributes(otlpKeyValues(h.prefix, attr)...)
+
+		return true
+	})
+
+	h.l.Emit(ctx, record)
+
+	return nil
+}
+
+// WithAttrs implements [slog.Handler].
+func (h *OTLPHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	res := *h
+	res.attrs = make([]otellog.KeyValue, len(h.attrs), len(h.attrs)+len(attrs))
+	copy(res.attrs, h.attrs)
+
+	for _, attr := range attrs {
+		res.attrs = append(res.attrs, otlpKeyValues(h.prefix, attr)...)
+	}
+
+	return &res
+}
+
+// WithGroup implements [slog.Handler].
+func (h *OTLPHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	res := *h
+	res.prefix = h.prefix + name + "."
+
+	return &res
+}
+
+// Shutdown flushes buffered records and stops the exporter.
+func (h *OTLPHandler) Shutdown(ctx context.Context) error {
+	return h.p.Shutdown(ctx)
+}
+
+// otlpSeverity converts slog level to OpenTelemetry severity.
+//
+// Standard slog levels are mapped to the first severity of the corresponding range
+// (for example, [slog.LevelWarn] to [otellog.SeverityWarn1]);
+// levels in between are mapped to the following severities in that range.
+func otlpSeverity(l slog.Level) otellog.Severity {
+	s := otellog.Severity(l - slog.LevelInfo + slog.Level(otellog.SeverityInfo))
+
+	switch {
+	case s < otellog.SeverityTrace1:
+		return otellog.SeverityTrace1
+	case s > otellog.SeverityFatal4:
+		return otellog.SeverityFatal4
+	default:
+		return s
+	}
+}
+
+// otlpKeyValues converts slog attribute to OpenTelemetry key-value pairs with a given key prefix.
+//
+// Empty attributes are ignored, and groups with empty keys are inlined, see [slog.Handler].
+func otlpKeyValues(prefix string, attr slog.Attr) []otellog.KeyValue {
+	attr.Value = attr.Value.Resolve()
+
+	if attr.Equal(slog.Attr{}) {
+		return nil
+	}
+
+	if attr.Key == "" {
+		if attr.Value.Kind() != slog.KindGroup {
+			return nil
+		}
+
+		var res []otellog.KeyValue
+		for _, a := range attr.Value.Group() {
+			res = append(res, otlpKeyValues(prefix, a)...)
+		}
+
+		return res
+	}
+
+	return []otellog.KeyValue{{
+		Key:   prefix + attr.Key,
+		Value: otlpValue(attr.Value),
+	}}
+}
+
+// otlpValue converts resolved slog value to OpenTelemetry value.
+func otlpValue(v slog.Value) otellog.Value {
+	switch v.Kind() {
+	case slog.KindString:
+		return otellog.StringValue(v.String())
+
+	case slog.KindInt64:
+		return otellog.Int64Value(v.Int64())
+
+	case slog.KindUint64:
+		u := v.Uint64()
+		if u > math.MaxInt64 {
+			return otellog.StringValue(fmt.Sprint(u))
+		}
+
+		return otellog.Int64Value(int64(u))
+
+	case slog.KindFloat64:
+		return otellog.Float64Value(v.Float64())
+
+	case slog.KindBool:
+		return otellog.BoolValue(v.Bool())
+
+	case slog.KindDuration:
+		return otellog.StringValue(v.Duration().String())
+
+	case slog.KindTime:
+		return otellog.StringValue(v.Time().Format(time.RFC3339Nano))
+
+	case slog.KindGroup:
+		attrs := v.Group()
+		kvs := make([]otellog.KeyValue, 0, len(attrs))
+
+		for _, attr := range attrs {
+			kvs = append(kvs, otlpKeyValues("", attr)...)
+		}
+
+		return otellog.MapValue(kvs...)
+
+	case slog.KindAny:
+		switch a := v.Any().(type) {
+		case error:
+			return otellog.StringValue(a.Error())
+		case []byte:
+			return otellog.BytesValue(a)
+		case fmt.Stringer:
+			return otellog.StringValue(a.String())
+		default:
+			return otellog.StringValue(strings.TrimSpace(fmt.Sprintf("%+v", a)))
+		}
+
+	case slog.KindLogValuer:
+		fallthrough
+
+	default:
+		panic(fmt.Sprintf("unexpected slog value kind %s", v.Kind()))
+	}
+}
+
+// check interfaces
+var (
+	_ slog.Handler = (*OTLPHandler)(nil)
+)
@@ -0,0 +1,89 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	otellog "go.opentelemetry.io/otel/log"
+)
+
+func TestOTLPSeverity(t *testing.T) {
+	t.Parallel()
+
+	for l, expected := range map[slog.Level]otellog.Severity{
+		slog.LevelDebug:       otellog.SeverityDebug,
+		slog.LevelInfo:        otellog.SeverityInfo,
+		slog.LevelWarn:        otellog.SeverityWarn,
+		slog.LevelError:       otellog.SeverityError,
+		slog.LevelError + 1:   otellog.SeverityError2,
+		slog.LevelDebug - 8:   otellog.SeverityTrace1,
+		slog.LevelError + 100: otellog.SeverityFatal4,
+	} {
+		assert.Equal(t, expected, otlpSeverity(l), "level %s", l)
+	}
+}
+
+func TestOTLPKeyValues(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		attr     slog.Attr
+		expected []otellog.KeyValue
+	}{
+		"String": {
+			attr:     slog.String("s", "v"),
+			expected: []otellog.KeyValue{otellog.String("p.s", "v")},
+		},
+		"Int": {
+			attr:     slog.Int("i", 42),
+			expected: []otellog.KeyValue{otellog.Int64("p.i", 42)},
+		},
+		"Duration": {
+			attr:     slog.Duration("d", time.Second),
+			expected: []otellog.KeyValue{otellog.String("p.d", "1s")},
+		},
+		"Error": {
+			attr:     slog.Any("err", errors.New("boom")),
+			expected: []otellog.KeyValue{otellog.String("p.err", "boom")},
+		},
+		"Group": {
+			attr: slog.Group("g", slog.Bool("b", true)),
+			expected: []otellog.KeyValue{
+				otellog.Map("p.g", otellog.Bool("b", true)),
+			},
+		},
+		"InlineGroup": {
+			attr: slog.Group("", slog.Bool("b", true), slog.Float64("f", 1.5)),
+			expected: []otellog.KeyValue{
+				otellog.Bool("p.b", true),
+				otellog.Float64("p.f", 1.5),
+			},
+		},
+		"Empty": {
+			attr: slog.Attr{},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, otlpKeyValues("p.", tc.attr))
+		})
+	}
+}
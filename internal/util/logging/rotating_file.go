@@ -0,0 +1,282 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// rotatingFileTimeFormat is used for suffixes of rotated files.
+//
+// It sorts lexicographically and does not contain characters that are invalid in file names on Windows.
+const rotatingFileTimeFormat = "2006-01-02T15-04-05.000"
+
+// NewRotatingFileOpts represents rotating file options.
+type NewRotatingFileOpts struct {
+	Path string
+
+	// The file is rotated when its size exceeds MaxSize bytes; 0 disables size-based rotation.
+	MaxSize int64
+
+	// The file is rotated when it is older than MaxAge; 0 disables age-based rotation.
+	MaxAge time.Duration
+
+	// Maximum number of rotated files to keep; 0 keeps all of them.
+	MaxBackups int
+}
+
+// RotatingFile is an [io.Writer] that writes to a file and rotates it by size and age.
+//
+// Rotated files are renamed to the original path with a timestamp suffix.
+//
+// [RotatingFile.Reopen] could be used to reopen the file after it was renamed or removed externally
+// (for example, by logrotate on SIGHUP).
+type RotatingFile struct {
+	opts *NewRotatingFileOpts
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	opened time.Time
+}
+
+// NewRotatingFile opens or creates a file for appending.
+//
+// [RotatingFile.Close] should be called on exit.
+func NewRotatingFile(opts *NewRotatingFileOpts) (*RotatingFile, error) {
+	rf := &RotatingFile{
+		opts: opts,
+	}
+
+	if err := rf.open(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return rf, nil
+}
+
+// open opens or creates a file for appending.
+//
+// It should be called with locked mutex (or before the file is shared).
+func (rf *RotatingFile) open() error {
+	f, err := os.OpenFile(rf.opts.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	rf.f = f
+	rf.size = fi.Size()
+	rf.opened = time.Now()
+
+	return nil
+}
+
+// Write implements [io.Writer].
+//
+// The file is rotated before writing if needed.
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.f == nil {
+		return 0, os.ErrClosed
+	}
+
+	if rf.shouldRotate(int64(len(p))) {
+		if err := rf.rotate(); err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+	}
+
+	n, err := rf.f.Write(p)
+	rf.size += int64(n)
+
+	return n, err
+}
+
+// shouldRotate returns true if the file should be rotated before writing n bytes.
+//
+// It should be called with locked mutex.
+func (rf *RotatingFile) shouldRotate(n int64) bool {
+	// do not rotate empty files to avoid creating empty backups for large writes
+	if rf.size == 0 {
+		return false
+	}
+
+	if rf.opts.MaxSize > 0 && rf.size+n > rf.opts.MaxSize {
+		return true
+	}
+
+	if rf.opts.MaxAge > 0 && time.Since(rf.opened) > rf.opts.MaxAge {
+		return true
+	}
+
+	return false
+}
+
+// rotate renames the current file, opens a new one, and removes old backups.
+//
+// It should be called with locked mutex.
+func (rf *RotatingFile) rotate() error {
+	if err := rf.f.Close(); err != nil {
+		return err
+	}
+
+	rf.f = nil
+
+	backup := rf.opts.Path + "." + time.Now().Format(rotatingFileTimeFormat)
+	if err := os.Rename(rf.opts.Path, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := rf.open(); err != nil {
+		return err
+	}
+
+	return rf.removeBackups()
+}
+
+// removeBackups removes the oldest rotated files exceeding MaxBackups.
+//
+// It should be called with locked mutex.
+func (rf *RotatingFile) removeBackups() error {
+	if rf.opts.MaxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := rf.backups()
+	if err != nil {
+		return err
+	}
+
+	if len(backups) <= rf.opts.MaxBackups {
+		return nil
+	}
+
+	var errs []error
+
+	for _, b := range backups[:len(backups)-rf.opts.MaxBackups] {
+		if err := os.Remove(b); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// backups returns paths of rotated files sorted from the oldest to the newest.
+func (rf *RotatingFile) backups() ([]string, error) {
+	dir, base := filepath.Split(rf.opts.Path)
+	if dir == "" {
+		dir = "."
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		suffix, ok := strings.CutPrefix(e.Name(), base+".")
+		if !ok {
+			continue
+		}
+
+		if _, err := time.Parse(rotatingFileTimeFormat, suffix); err != nil {
+			continue
+		}
+
+		res = append(res, filepath.Join(dir, e.Name()))
+	}
+
+	slices.Sort(res)
+
+	return res, nil
+}
+
+// Sync commits the current contents of the file to stable storage.
+func (rf *RotatingFile) Sync() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.f == nil {
+		return nil
+	}
+
+	return rf.f.Sync()
+}
+
+// Reopen closes and reopens the file by its path.
+//
+// It should be called after the file was renamed or removed externally.
+func (rf *RotatingFile) Reopen() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.f != nil {
+		if err := rf.f.Close(); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		rf.f = nil
+	}
+
+	if err := rf.open(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// Close closes the file.
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.f == nil {
+		return nil
+	}
+
+	err := rf.f.Close()
+	rf.f = nil
+
+	return err
+}
+
+// check interfaces
+var (
+	_ io.WriteCloser = (*RotatingFile)(nil)
+)
@@ -0,0 +1,130 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Size", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "ferretdb.log")
+
+		rf, err := NewRotatingFile(&NewRotatingFileOpts{
+			Path:       path,
+			MaxSize:    20,
+			MaxBackups: 2,
+		})
+		require.NoError(t, err)
+
+		t.Cleanup(func() { require.NoError(t, rf.Close()) })
+
+		for _, s := range []string{"12345678\n", "abcdefgh\n", "ABCDEFGH\n", "xyz\n"} {
+			_, err = rf.Write([]byte(s))
+			require.NoError(t, err)
+
+			// make sure backup names are distinct
+			time.Sleep(2 * time.Millisecond)
+		}
+
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "ABCDEFGH\nxyz\n", string(b))
+
+		backups, err := rf.backups()
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+
+		b, err = os.ReadFile(backups[0])
+		require.NoError(t, err)
+		assert.Equal(t, "12345678\nabcdefgh\n", string(b))
+	})
+
+	t.Run("Backups", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "ferretdb.log")
+
+		rf, err := NewRotatingFile(&NewRotatingFileOpts{
+			Path:       path,
+			MaxSize:    1,
+			MaxBackups: 2,
+		})
+		require.NoError(t, err)
+
+		t.Cleanup(func() { require.NoError(t, rf.Close()) })
+
+		for _, s := range []string{"1", "2", "3", "4", "5"} {
+			_, err = rf.Write([]byte(s))
+			require.NoError(t, err)
+
+			time.Sleep(2 * time.Millisecond)
+		}
+
+		backups, err := rf.backups()
+		require.NoError(t, err)
+		require.Len(t, backups, 2)
+
+		var actual []string
+		for _, p := range backups {
+			b, err := os.ReadFile(p)
+			require.NoError(t, err)
+			actual = append(actual, string(b))
+		}
+
+		assert.Equal(t, []string{"3", "4"}, actual)
+	})
+
+	t.Run("Reopen", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "ferretdb.log")
+
+		rf, err := NewRotatingFile(&NewRotatingFileOpts{Path: path})
+		require.NoError(t, err)
+
+		t.Cleanup(func() { require.NoError(t, rf.Close()) })
+
+		_, err = rf.Write([]byte("before\n"))
+		require.NoError(t, err)
+
+		// what logrotate does
+		moved := filepath.Join(dir, "ferretdb.log.1")
+		require.NoError(t, os.Rename(path, moved))
+		require.NoError(t, rf.Reopen())
+
+		_, err = rf.Write([]byte("after\n"))
+		require.NoError(t, err)
+
+		b, err := os.ReadFile(moved)
+		require.NoError(t, err)
+		assert.Equal(t, "before\n", string(b))
+
+		b, err = os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "after\n", string(b))
+	})
+}
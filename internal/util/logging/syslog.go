@@ -0,0 +1,374 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// syslogFacilityDaemon is the syslog facility for system daemons.
+const syslogFacilityDaemon = 3
+
+// syslogSDID is the structured data ID for log record attributes.
+//
+// 32473 is the Private Enterprise Number reserved for documentation (RFC 5612).
+const syslogSDID = "ferretdb@32473"
+
+// syslogDialTimeout is the maximum time to wait for a connection to syslog server.
+const syslogDialTimeout = 5 * time.Second
+
+// syslogWriteTimeout is the maximum time to wait for a single message to be written.
+const syslogWriteTimeout = time.Second
+
+// syslogLocalSockets contains well-known paths of the local syslog socket.
+var syslogLocalSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// NewSyslogHandlerOpts represents syslog handler options.
+type NewSyslogHandlerOpts struct {
+	// Network is "udp", "tcp", "unixgram", or "unix".
+	// If empty, the local syslog socket is used, and Addr is ignored.
+	Network string
+	Addr    string
+
+	Level   slog.Leveler
+	AppName string
+
+	// Syslog facility; 0 means "daemon".
+	Facility int
+}
+
+// syslogConn represents a connection to syslog server shared by all handlers created by WithAttrs and WithGroup.
+type syslogConn struct {
+	mu      sync.Mutex
+	network string
+	addr    string
+	c       net.Conn
+}
+
+// SyslogHandler is a [slog.Handler] that sends log records to syslog in RFC 5424 format.
+//
+// Record attributes are sent as structured data.
+// Messages sent over TCP are framed with octet counting (RFC 6587).
+type SyslogHandler struct {
+	conn     *syslogConn
+	level    slog.Leveler
+	facility int
+	hostname string
+	appName  string
+	procID   string
+	attrs    []slog.Attr
+	prefix   string // group names joined and followed by dots
+}
+
+// NewSyslogHandler creates a new syslog handler.
+//
+// [SyslogHandler.Close] should be called on exit.
+func NewSyslogHandler(opts *NewSyslogHandlerOpts) (*SyslogHandler, error) {
+	conn := &syslogConn{
+		network: opts.Network,
+		addr:    opts.Addr,
+	}
+
+	if err := conn.connect(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	hostname, _ := os.Hostname()
+
+	level := opts.Level
+	if level == nil {
+		level = slog.LevelInfo
+	}
+
+	facility := opts.Facility
+	if facility == 0 {
+		facility = syslogFacilityDaemon
+	}
+
+	return &SyslogHandler{
+		conn:     conn,
+		level:    level,
+		facility: facility,
+		hostname: syslogHeaderField(hostname, 255),
+		appName:  syslogHeaderField(opts.AppName, 48),
+		procID:   strconv.Itoa(os.Getpid()),
+	}, nil
+}
+
+// connect (re)establishes a connection to syslog server.
+//
+// It should be called with locked mutex (or before the connection is shared).
+func (sc *syslogConn) connect() error {
+	if sc.c != nil {
+		_ = sc.c.Close()
+		sc.c = nil
+	}
+
+	if sc.network != "" {
+		c, err := net.DialTimeout(sc.network, sc.addr, syslogDialTimeout)
+		if err != nil {
+			return err
+		}
+
+		sc.c = c
+
+		return nil
+	}
+
+	for _, path := range syslogLocalSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			c, err := net.DialTimeout(network, path, syslogDialTimeout)
+			if err == nil {
+				sc.c = c
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("local syslog socket not found in %v", syslogLocalSockets)
+}
+
+// write writes a single message, reconnecting once on error.
+//
+// Dials and writes are bounded by timeouts, so an unresponsive server makes logging slow, but does not block it.
+func (sc *syslogConn) write(msg []byte) error {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	b := msg
+
+	switch sc.network {
+	case "tcp", "tcp4", "tcp6":
+		b = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	case "unix":
+		b = append(msg, '\n')
+	}
+
+	var err error
+
+	for range 2 {
+		if sc.c == nil {
+			if err = sc.connect(); err != nil {
+				continue
+			}
+		}
+
+		if err = sc.c.SetWriteDeadline(time.Now().Add(syslogWriteTimeout)); err == nil {
+			if _, err = sc.c.Write(b); err == nil {
+				return nil
+			}
+		}
+
+		_ = sc.c.Close()
+		sc.c = nil
+	}
+
+	return err
+}
+
+// Enabled implements [slog.Handler].
+func (h *SyslogHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+// Handle implements [slog.Handler].
+func (h *SyslogHandler) Handle(_ context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	// HEADER: <PRI>VERSION SP TIMESTAMP SP HOSTNAME SP APP-NAME SP PROCID SP MSGID
+	fmt.Fprintf(
+		&buf, "<%d>1 %s %s %s %s - ",
+		h.facility*8+syslogSeverity(r.Level),
+		t.Format(time.RFC3339Nano),
+		h.hostname, h.appName, h.procID,
+	)
+
+	// STRUCTURED-DATA
+	params := make([]string, 0, len(h.attrs)+r.NumAttrs())
+
+	for _, attr := range h.attrs {
+		params = appendSyslogParams(params, "", attr)
+	}
+
+	r.Attrs(func(attr slog.Attr) bool {
+		params = appendSyslogParams(params, h.prefix, attr)
+		return true
+	})
+
+	if len(params) == 0 {
+		buf.WriteString("-")
+	} else {
+		buf.WriteString("[" + syslogSDID)
+
+		for _, p := range params {
+			buf.WriteString(" " + p)
+		}
+
+		buf.WriteString("]")
+	}
+
+	// MSG
+	if r.Message != "" {
+		buf.WriteString(" " + r.Message)
+	}
+
+	return h.conn.write(buf.Bytes())
+}
+
+// WithAttrs implements [slog.Handler].
+func (h *SyslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	res := *h
+	res.attrs = make([]slog.Attr, len(h.attrs), len(h.attrs)+len(attrs))
+	copy(res.attrs, h.attrs)
+
+	for _, attr := range attrs {
+		if h.prefix != "" {
+			attr.Key = h.prefix + attr.Key
+		}
+
+		res.attrs = append(res.attrs, attr)
+	}
+
+	return &res
+}
+
+// WithGroup implements [slog.Handler].
+func (h *SyslogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	res := *h
+	res.prefix = h.prefix + name + "."
+
+	return &res
+}
+
+// Close closes the connection to syslog server.
+func (h *SyslogHandler) Close() error {
+	h.conn.mu.Lock()
+	defer h.conn.mu.Unlock()
+
+	if h.conn.c == nil {
+		return nil
+	}
+
+	err := h.conn.c.Close()
+	h.conn.c = nil
+
+	return err
+}
+
+// syslogSeverity converts slog level to syslog severity.
+func syslogSeverity(l slog.Level) int {
+	switch {
+	case l >= slog.LevelError:
+		return 3 // err
+	case l >= slog.LevelWarn:
+		return 4 // warning
+	case l >= slog.LevelInfo:
+		return 6 // info
+	default:
+		return 7 // debug
+	}
+}
+
+// syslogHeaderField returns a valid RFC 5424 header field value
+// (printable US-ASCII without spaces, limited in length), or "-" (nil value).
+func syslogHeaderField(s string, maxLen int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return -1
+		}
+
+		return r
+	}, s)
+
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+// syslogParamValueReplacer escapes characters in structured data parameter values.
+var syslogParamValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// appendSyslogParams appends structured data parameters (`name="value"`) for the given attribute.
+//
+// Groups are flattened with dots.
+func appendSyslogParams(params []string, prefix string, attr slog.Attr) []string {
+	attr.Value = attr.Value.Resolve()
+
+	if attr.Equal(slog.Attr{}) {
+		return params
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+
+		for _, a := range attr.Value.Group() {
+			params = appendSyslogParams(params, prefix, a)
+		}
+
+		return params
+	}
+
+	// PARAM-NAME is SD-NAME: printable US-ASCII except '=', SP, ']', '"'; up to 32 characters
+	name := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+
+		return r
+	}, prefix+attr.Key)
+
+	if name == "" {
+		return params
+	}
+
+	if len(name) > 32 {
+		name = name[:32]
+	}
+
+	return append(params, name+`="`+syslogParamValueReplacer.Replace(attr.Value.String())+`"`)
+}
+
+// check interfaces
+var (
+	_ slog.Handler = (*SyslogHandler)(nil)
+)
@@ -0,0 +1,135 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogHandler(t *testing.T) {
+	t.Parallel()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, pc.Close()) })
+
+	h, err := NewSyslogHandler(&NewSyslogHandlerOpts{
+		Network: "udp",
+		Addr:    pc.LocalAddr().String(),
+		Level:   slog.LevelDebug,
+		AppName: "ferret db",
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, h.Close()) })
+
+	h.hostname = "host"
+
+	l := slog.New(h).With(slog.String("name", "test")).WithGroup("g")
+	l.Warn("Hello", slog.String("q", `a "b" ] \`), slog.Int("n", 42))
+
+	require.NoError(t, pc.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	b := make([]byte, 1024)
+	n, _, err := pc.ReadFrom(b)
+	require.NoError(t, err)
+
+	actual := string(b[:n])
+
+	// <28> is daemon.warning
+	expectedPrefix := "<28>1 "
+	expectedSuffix := " host ferretdb " + strconv.Itoa(os.Getpid()) + ` - ` +
+		`[ferretdb@32473 name="test" g.q="a \"b\" \] \\" g.n="42"] Hello`
+
+	assert.Regexp(t, `^`+expectedPrefix+`\d{4}-\d{2}-\d{2}T`, actual)
+	assert.Contains(t, actual, expectedSuffix)
+}
+
+func TestSyslogHeaderField(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "-", syslogHeaderField("", 48))
+	assert.Equal(t, "-", syslogHeaderField(" ", 48))
+	assert.Equal(t, "ferretdb", syslogHeaderField("ferret db", 48))
+	assert.Equal(t, "ferr", syslogHeaderField("ferretdb", 4))
+}
+
+func TestSyslogHandlerStalled(t *testing.T) {
+	t.Parallel()
+
+	// the server accepts connections, but never reads from them
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var conns []net.Conn
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for {
+			c, err := lis.Accept()
+			if err != nil {
+				return
+			}
+
+			conns = append(conns, c)
+		}
+	}()
+
+	t.Cleanup(func() {
+		require.NoError(t, lis.Close())
+		<-done
+
+		for _, c := range conns {
+			_ = c.Close()
+		}
+	})
+
+	h, err := NewSyslogHandler(&NewSyslogHandlerOpts{
+		Network: "tcp",
+		Addr:    lis.Addr().String(),
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, h.Close()) })
+
+	msg := make([]byte, 1024*1024)
+
+	// fill socket buffers until a write has to wait for the deadline
+	for range 64 {
+		start := time.Now()
+		_ = h.conn.write(msg)
+
+		d := time.Since(start)
+		require.Less(t, d, 2*syslogWriteTimeout+syslogDialTimeout)
+
+		if d >= syslogWriteTimeout {
+			return
+		}
+	}
+
+	t.Fatal("socket buffers were not filled")
+}
@@ -0,0 +1,245 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Debug logs sampling parameters: the first debugSamplingFirst entries with the same message
+// are logged each second, then every debugSamplingThereafter-th.
+const (
+	debugSamplingFirst      = 100
+	debugSamplingThereafter = 100
+)
+
+// VerbosityComponents maps MongoDB's logComponentVerbosity keys to log components
+// (see mongoComponentContext).
+var VerbosityComponents = map[string]string{
+	"accessControl": "ACCESS",
+	"command":       "COMMAND",
+	"control":       "CONTROL",
+	"network":       "NETWORK",
+	"query":         "QUERY",
+	"replication":   "REPL",
+	"storage":       "STORAGE",
+}
+
+// Verbosity contains log levels that could be changed at runtime.
+var Verbosity = newVerbosity()
+
+// verbosity stores the global log level and per-component overrides.
+//
+// Verbosity levels are MongoDB-like: 0 is the default (info level),
+// 1 enables debug level, and higher values enable more verbose slog levels below [slog.LevelDebug].
+// Levels set at startup that are less verbose than info (like warn) are kept until changed.
+type verbosity struct {
+	level *slog.LevelVar // also used by slog handlers
+
+	rw         sync.RWMutex
+	components map[string]slog.Level // by component
+}
+
+// newVerbosity creates a new verbosity with info level and no component overrides.
+func newVerbosity() *verbosity {
+	return &verbosity{
+		level:      new(slog.LevelVar),
+		components: map[string]slog.Level{},
+	}
+}
+
+// levelFromVerbosity converts MongoDB verbosity level to slog level.
+func levelFromVerbosity(v int32) slog.Level {
+	if v <= 0 {
+		return slog.LevelInfo
+	}
+
+	return slog.LevelDebug - slog.Level(v-1)
+}
+
+// verbosityFromLevel converts slog level to MongoDB verbosity level.
+func verbosityFromLevel(l slog.Level) int32 {
+	if l > slog.LevelDebug {
+		return 0
+	}
+
+	return int32(slog.LevelDebug-l) + 1
+}
+
+// reset sets the given level and removes all component overrides.
+func (v *verbosity) reset(l slog.Level) {
+	v.rw.Lock()
+	defer v.rw.Unlock()
+
+	v.level.Set(l)
+	v.components = map[string]slog.Level{}
+}
+
+// Level returns the global verbosity level.
+func (v *verbosity) Level() int32 {
+	return verbosityFromLevel(v.level.Level())
+}
+
+// LevelVar returns the global level that could be changed directly.
+func (v *verbosity) LevelVar() *slog.LevelVar {
+	return v.level
+}
+
+// SetLevel sets the global verbosity level and returns the previous one.
+func (v *verbosity) SetLevel(n int32) int32 {
+	old := v.Level()
+	v.level.Set(levelFromVerbosity(n))
+
+	return old
+}
+
+// Components returns verbosity levels of all components keyed by [VerbosityComponents] keys;
+// -1 means that the global level is used.
+func (v *verbosity) Components() map[string]int32 {
+	v.rw.RLock()
+	defer v.rw.RUnlock()
+
+	res := make(map[string]int32, len(VerbosityComponents))
+
+	for k, c := range VerbosityComponents {
+		res[k] = -1
+
+		if l, ok := v.components[c]; ok {
+			res[k] = verbosityFromLevel(l)
+		}
+	}
+
+	return res
+}
+
+// SetComponent sets verbosity level of the component with the given [VerbosityComponents] key;
+// negative value removes the override.
+func (v *verbosity) SetComponent(key string, n int32) error {
+	c, ok := VerbosityComponents[key]
+	if !ok {
+		return fmt.Errorf("invalid component name %q", key)
+	}
+
+	v.rw.Lock()
+	defer v.rw.Unlock()
+
+	if n < 0 {
+		delete(v.components, c)
+		return nil
+	}
+
+	v.components[c] = levelFromVerbosity(n)
+
+	return nil
+}
+
+// minLevel returns the most verbose level of all components.
+func (v *verbosity) minLevel() slog.Level {
+	res := v.level.Level()
+
+	v.rw.RLock()
+	defer v.rw.RUnlock()
+
+	for _, l := range v.components {
+		res = min(res, l)
+	}
+
+	return res
+}
+
+// enabled returns true if the entry with the given logger name and level should be logged.
+func (v *verbosity) enabled(name string, level zapcore.Level) bool {
+	l := v.level.Level()
+
+	v.rw.RLock()
+
+	if len(v.components) > 0 {
+		c, _ := mongoComponentContext(name)
+		if cl, ok := v.components[c]; ok {
+			l = cl
+		}
+	}
+
+	v.rw.RUnlock()
+
+	return SlogLevel(level) >= l
+}
+
+// verbosityCore is a [zapcore.Core] that filters entries using [Verbosity].
+//
+// The wrapped core should accept all levels.
+type verbosityCore struct {
+	zapcore.Core
+	v       *verbosity
+	sampled zapcore.Core // used for debug entries if not nil
+}
+
+// newVerbosityCore wraps the given core.
+//
+// If sampleDebug is true, debug entries with the same message are sampled.
+func newVerbosityCore(c zapcore.Core, v *verbosity, sampleDebug bool) *verbosityCore {
+	res := &verbosityCore{
+		Core: c,
+		v:    v,
+	}
+
+	if sampleDebug {
+		res.sampled = zapcore.NewSamplerWithOptions(c, time.Second, debugSamplingFirst, debugSamplingThereafter)
+	}
+
+	return res
+}
+
+// Enabled implements [zapcore.Core].
+func (c *verbosityCore) Enabled(level zapcore.Level) bool {
+	return SlogLevel(level) >= c.v.minLevel()
+}
+
+// With implements [zapcore.Core].
+func (c *verbosityCore) With(fields []zapcore.Field) zapcore.Core {
+	res := &verbosityCore{
+		Core: c.Core.With(fields),
+		v:    c.v,
+	}
+
+	if c.sampled != nil {
+		res.sampled = c.sampled.With(fields)
+	}
+
+	return res
+}
+
+// Check implements [zapcore.Core].
+func (c *verbosityCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.v.enabled(entry.LoggerName, entry.Level) {
+		return ce
+	}
+
+	if entry.Level == zapcore.DebugLevel && c.sampled != nil {
+		return c.sampled.Check(entry, ce)
+	}
+
+	return c.Core.Check(entry, ce)
+}
+
+// check interfaces
+var (
+	_ zapcore.Core = (*verbosityCore)(nil)
+)
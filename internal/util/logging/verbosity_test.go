@@ -0,0 +1,107 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestVerbosityLevels(t *testing.T) {
+	t.Parallel()
+
+	for v, l := range map[int32]slog.Level{
+		0: slog.LevelInfo,
+		1: slog.LevelDebug,
+		2: slog.LevelDebug - 1,
+		5: slog.LevelDebug - 4,
+	} {
+		assert.Equal(t, l, levelFromVerbosity(v), "verbosity %d", v)
+		assert.Equal(t, v, verbosityFromLevel(l), "level %s", l)
+	}
+
+	assert.Equal(t, int32(0), verbosityFromLevel(slog.LevelWarn))
+}
+
+func TestVerbosityCore(t *testing.T) {
+	t.Parallel()
+
+	v := newVerbosity()
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := zap.New(newVerbosityCore(core, v, false))
+
+	log := func() {
+		l.Named("listener").Debug("network")
+		l.Named("postgresql").Debug("storage")
+		l.Named("postgresql").Info("storage info")
+	}
+
+	log()
+	assert.Equal(t, []string{"storage info"}, messages(logs.TakeAll()))
+
+	require.NoError(t, v.SetComponent("network", 1))
+	assert.Equal(t, int32(1), v.Components()["network"])
+	assert.Equal(t, int32(-1), v.Components()["storage"])
+
+	log()
+	assert.Equal(t, []string{"network", "storage info"}, messages(logs.TakeAll()))
+
+	assert.Equal(t, int32(0), v.SetLevel(1))
+	require.NoError(t, v.SetComponent("network", 0))
+
+	log()
+	assert.Equal(t, []string{"storage", "storage info"}, messages(logs.TakeAll()))
+
+	require.NoError(t, v.SetComponent("network", -1))
+
+	log()
+	assert.Equal(t, []string{"network", "storage", "storage info"}, messages(logs.TakeAll()))
+
+	assert.Error(t, v.SetComponent("unknown", 1))
+}
+
+func TestVerbosityCoreSampling(t *testing.T) {
+	t.Parallel()
+
+	v := newVerbosity()
+	v.SetLevel(1)
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := zap.New(newVerbosityCore(core, v, true))
+
+	for range debugSamplingFirst * 2 {
+		l.Debug("chatty")
+		l.Info("important")
+	}
+
+	assert.Equal(t, debugSamplingFirst+1, logs.FilterMessage("chatty").Len())
+	assert.Equal(t, debugSamplingFirst*2, logs.FilterMessage("important").Len())
+}
+
+// messages returns messages of the given log entries.
+func messages(entries []observer.LoggedEntry) []string {
+	res := make([]string, len(entries))
+	for i, e := range entries {
+		res[i] = e.Message
+	}
+
+	return res
+}
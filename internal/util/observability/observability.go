@@ -19,34 +19,74 @@ package observability
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel"
+	otelattribute "go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
 	otelsdkresource "go.opentelemetry.io/otel/sdk/resource"
 	otelsdktrace "go.opentelemetry.io/otel/sdk/trace"
 	otelsemconv "go.opentelemetry.io/otel/semconv/v1.21.0"
 
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
+// tracingEnabled is true if OpenTelemetry tracer provider was set up.
+var tracingEnabled atomic.Bool
+
+// TracingEnabled returns true if OpenTelemetry tracer provider was set up with [SetupOtelWithOpts].
+func TracingEnabled() bool {
+	return tracingEnabled.Load()
+}
+
+// OtelOpts represents OpenTelemetry tracing options.
+type OtelOpts struct {
+	Service string
+	Version string
+
+	// OTLP/HTTP traces endpoint URL, for example, "http://127.0.0.1:4318/v1/traces".
+	TracesURL string
+}
+
 // SetupOtel sets up OpenTelemetry exporter with fixed values.
 func SetupOtel(service string) func(context.Context) error {
-	exporter := must.NotFail(otlptracehttp.New(
+	return must.NotFail(SetupOtelWithOpts(&OtelOpts{
+		Service:   service,
+		TracesURL: "http://127.0.0.1:4318/v1/traces",
+	}))
+}
+
+// SetupOtelWithOpts sets up OpenTelemetry OTLP/HTTP traces exporter
+// and W3C trace context propagator as global ones.
+//
+// The returned function flushes buffered spans and shuts down the exporter.
+func SetupOtelWithOpts(opts *OtelOpts) (func(context.Context) error, error) {
+	exporter, err := otlptracehttp.New(
 		context.TODO(),
-		otlptracehttp.WithEndpoint("127.0.0.1:4318"),
-		otlptracehttp.WithInsecure(),
-	))
+		otlptracehttp.WithEndpointURL(opts.TracesURL),
+	)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	attrs := []otelattribute.KeyValue{otelsemconv.ServiceNameKey.String(opts.Service)}
+	if opts.Version != "" {
+		attrs = append(attrs, otelsemconv.ServiceVersionKey.String(opts.Version))
+	}
 
 	tp := otelsdktrace.NewTracerProvider(
 		otelsdktrace.WithBatcher(exporter, otelsdktrace.WithBatchTimeout(time.Second)),
 		otelsdktrace.WithSampler(otelsdktrace.AlwaysSample()),
-		otelsdktrace.WithResource(otelsdkresource.NewSchemaless(
-			otelsemconv.ServiceNameKey.String(service),
-		)),
+		otelsdktrace.WithResource(otelsdkresource.NewSchemaless(attrs...)),
 	)
 
 	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	tracingEnabled.Store(true)
 
-	return tp.Shutdown
+	return tp.Shutdown, nil
 }
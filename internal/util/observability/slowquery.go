@@ -0,0 +1,100 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observability
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// SlowQueryOpts represents options for logging slow backend queries.
+//
+// Threshold could be changed at runtime.
+type SlowQueryOpts struct {
+	threshold atomic.Int64 // time.Duration
+
+	// If true, query plans are captured and logged too (if supported by the backend).
+	Explain bool
+}
+
+// NewSlowQueryOpts creates options for logging queries running longer than threshold;
+// zero disables logging.
+func NewSlowQueryOpts(threshold time.Duration, explain bool) *SlowQueryOpts {
+	opts := &SlowQueryOpts{
+		Explain: explain,
+	}
+	opts.SetThreshold(threshold)
+
+	return opts
+}
+
+// Threshold returns the current threshold.
+func (opts *SlowQueryOpts) Threshold() time.Duration {
+	return time.Duration(opts.threshold.Load())
+}
+
+// SetThreshold sets a new threshold; zero disables logging.
+func (opts *SlowQueryOpts) SetThreshold(threshold time.Duration) {
+	opts.threshold.Store(int64(threshold))
+}
+
+// Enabled returns true if slow queries should be logged.
+func (opts *SlowQueryOpts) Enabled() bool {
+	return opts != nil && opts.Threshold() > 0
+}
+
+// CommandFields returns log fields that correlate backend activity with the client's command
+// that caused it: command, database, and collection names (taken from pprof labels)
+// and the trace ID, if present in the context.
+func CommandFields(ctx context.Context) []zap.Field {
+	var res []zap.Field
+
+	for _, l := range []string{"command", "db", "collection"} {
+		if v, ok := pprof.Label(ctx, l); ok {
+			res = append(res, zap.String(l, v))
+		}
+	}
+
+	if sc := oteltrace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		res = append(res, zap.Stringer("trace_id", sc.TraceID()))
+	}
+
+	return res
+}
+
+// RedactArgs returns query arguments' types without values,
+// so they could be logged without exposing user data.
+func RedactArgs(args []any) []string {
+	res := make([]string, len(args))
+
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case []byte:
+			res[i] = fmt.Sprintf("[]byte(%d)", len(arg))
+		case string:
+			res[i] = fmt.Sprintf("string(%d)", len(arg))
+		default:
+			res[i] = fmt.Sprintf("%T", arg)
+		}
+	}
+
+	return res
+}
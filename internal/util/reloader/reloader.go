@@ -0,0 +1,89 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reloader provides runtime configuration reloading.
+package reloader
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/util/ctxutil"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Func re-reads configuration, applies runtime-changeable settings,
+// and returns human-readable descriptions of applied changes.
+type Func func() ([]string, error)
+
+// Reloader serializes configuration reloads and records them in the audit log.
+type Reloader struct {
+	f Func
+	l *zap.Logger
+
+	m sync.Mutex
+}
+
+// New creates a new Reloader with the given reload function.
+func New(f Func, l *zap.Logger) *Reloader {
+	must.BeTrue(f != nil)
+
+	return &Reloader{
+		f: f,
+		l: l.Named("audit"),
+	}
+}
+
+// Reload reloads configuration and returns applied changes.
+//
+// Source describes what triggered the reload (for example, "SIGHUP" or "reloadConfiguration")
+// and is only used for logging.
+func (r *Reloader) Reload(source string) ([]string, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	changes, err := r.f()
+	if err != nil {
+		r.l.Warn("Configuration reload failed", zap.String("source", source), zap.Error(err))
+
+		// error is returned as-is to be shown to the user
+		return nil, err
+	}
+
+	if changes == nil {
+		changes = []string{}
+	}
+
+	r.l.Info("Configuration reloaded", zap.String("source", source), zap.Strings("changes", changes))
+
+	return changes, nil
+}
+
+// RunOnSigHup reloads configuration on every hangup signal until ctx is done.
+//
+// Errors are logged and otherwise ignored.
+func (r *Reloader) RunOnSigHup(ctx context.Context) {
+	sigHup := ctxutil.SigHup(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigHup:
+			_, _ = r.Reload("SIGHUP")
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reloader
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestReloader(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	var fail bool
+	r := New(func() ([]string, error) {
+		if fail {
+			return nil, errors.New("bad config")
+		}
+
+		return []string{"log-level: info -> debug"}, nil
+	}, zap.New(core))
+
+	changes, err := r.Reload("test")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"log-level: info -> debug"}, changes)
+
+	fail = true
+	_, err = r.Reload("test")
+	require.ErrorContains(t, err, "bad config")
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "audit", entries[0].LoggerName)
+	assert.Equal(t, "Configuration reloaded", entries[0].Message)
+	assert.Equal(t, map[string]any{"source": "test", "changes": []any{"log-level: info -> debug"}}, entries[0].ContextMap())
+
+	assert.Equal(t, zapcore.WarnLevel, entries[1].Level)
+	assert.Equal(t, "Configuration reload failed", entries[1].Message)
+}
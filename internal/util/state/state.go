@@ -30,6 +30,7 @@ type State struct {
 	// all following fields are never persisted
 
 	TelemetryLocked bool      `json:"-"`
+	TelemetryLevel  string    `json:"-"` // see telemetry.Level
 	Start           time.Time `json:"-"`
 
 	// may be empty if FerretDB did not connect to the backend yet
@@ -92,6 +93,7 @@ func (s *State) deepCopy() *State {
 		UUID:            s.UUID,
 		Telemetry:       telemetry,
 		TelemetryLocked: s.TelemetryLocked,
+		TelemetryLevel:  s.TelemetryLevel,
 		Start:           s.Start,
 		BackendName:     s.BackendName,
 		BackendVersion:  s.BackendVersion,
@@ -21,7 +21,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
 	"runtime"
+	"slices"
 	"time"
 
 	"github.com/AlekSi/pointer"
@@ -53,12 +55,24 @@ type request struct {
 	UUID   string        `json:"uuid"`
 	Uptime time.Duration `json:"uptime"`
 
+	Level Level `json:"level"`
+
 	// opcode (e.g. "OP_MSG", "OP_QUERY") ->
 	// command (e.g. "find", "aggregate") ->
 	// argument that caused an error (e.g. "sort", "$count (stage)"; or "unknown") ->
 	// result (e.g. "NotImplemented", "InternalError"; or "ok") ->
 	// count.
+	//
+	// For LevelErrors, only failed results are present.
+	// For LevelCommands, it is null.
 	CommandMetrics map[string]map[string]map[string]map[string]int `json:"command_metrics"`
+
+	// opcode ->
+	// command ->
+	// count.
+	//
+	// It is set only for LevelCommands.
+	CommandCounts map[string]map[string]int `json:"command_counts,omitempty"`
 }
 
 // response represents telemetry response.
@@ -86,10 +100,24 @@ type NewReporterOpts struct {
 	UndecidedDelay time.Duration
 	ReportInterval time.Duration
 	ReportTimeout  time.Duration
+
+	// Level is LevelFull if empty.
+	Level Level
+
+	// If set, reports are appended to that file instead of being sent (for air-gapped environments).
+	File string
 }
 
 // NewReporter creates a new reporter.
 func NewReporter(opts *NewReporterOpts) (*Reporter, error) {
+	if opts.Level == "" {
+		opts.Level = Level(AllLevels[0])
+	}
+
+	if !slices.Contains(AllLevels, string(opts.Level)) {
+		return nil, fmt.Errorf("unknown telemetry level %q", opts.Level)
+	}
+
 	t, locked, err := initialState(opts.F, opts.DNT, opts.ExecName, opts.P.Get().Telemetry, opts.L)
 	if err != nil {
 		return nil, err
@@ -98,6 +126,7 @@ func NewReporter(opts *NewReporterOpts) (*Reporter, error) {
 	err = opts.P.Update(func(s *state.State) {
 		s.Telemetry = t
 		s.TelemetryLocked = locked
+		s.TelemetryLevel = string(opts.Level)
 	})
 	if err != nil {
 		return nil, err
@@ -165,13 +194,39 @@ func (r *Reporter) firstReportDelay(ctx context.Context, ch <-chan struct{}) {
 	}
 }
 
-// makeRequest creates a new telemetry request.
+// Report returns a telemetry report for the given state and metrics
+// exactly as it would be sent or written to the file.
+func Report(s *state.State, m *connmetrics.ConnMetrics) ([]byte, error) {
+	return json.Marshal(makeRequest(s, m))
+}
+
+// makeRequest creates a new telemetry request with data allowed by the state's telemetry level.
 func makeRequest(s *state.State, m *connmetrics.ConnMetrics) *request {
+	level := Level(s.TelemetryLevel)
+	if level == "" {
+		level = LevelFull
+	}
+
 	commandMetrics := map[string]map[string]map[string]map[string]int{}
+	commandCounts := map[string]map[string]int{}
 
 	for opcode, commands := range m.GetResponses() {
 		for command, arguments := range commands {
 			for argument, m := range arguments {
+				if level == LevelCommands {
+					if _, ok := commandCounts[opcode]; !ok {
+						commandCounts[opcode] = map[string]int{}
+					}
+
+					commandCounts[opcode][command] += m.Total
+
+					continue
+				}
+
+				if level == LevelErrors && len(m.Failures) == 0 {
+					continue
+				}
+
 				if _, ok := commandMetrics[opcode]; !ok {
 					commandMetrics[opcode] = map[string]map[string]map[string]int{}
 				}
@@ -194,11 +249,19 @@ func makeRequest(s *state.State, m *connmetrics.ConnMetrics) *request {
 					failures += c
 				}
 
-				commandMetrics[opcode][command][argument]["ok"] = m.Total - failures
+				if level == LevelFull {
+					commandMetrics[opcode][command][argument]["ok"] = m.Total - failures
+				}
 			}
 		}
 	}
 
+	if level == LevelCommands {
+		commandMetrics = nil
+	} else {
+		commandCounts = nil
+	}
+
 	info := version.Get()
 
 	buildEnvironment := make(map[string]any, info.BuildEnvironment.Len())
@@ -236,13 +299,18 @@ func makeRequest(s *state.State, m *connmetrics.ConnMetrics) *request {
 		UUID:   s.UUID,
 		Uptime: time.Since(s.Start),
 
+		Level: level,
+
 		CommandMetrics: commandMetrics,
+		CommandCounts:  commandCounts,
 	}
 }
 
 // report sends http POST request to telemetry unless telemetry is disabled.
 // It fetches available update and the latest version, then updates the state of provider
 // with update available and latest version if any update is available.
+//
+// If the file is set, the report is appended to it instead, and updates are not checked.
 func (r *Reporter) report(ctx context.Context) {
 	s := r.P.Get()
 
@@ -253,7 +321,6 @@ func (r *Reporter) report(ctx context.Context) {
 	}
 
 	request := makeRequest(s, r.ConnMetrics)
-	r.L.Info("Reporting telemetry.", zap.String("url", r.URL), zap.Any("data", request))
 
 	b, err := json.Marshal(request)
 	if err != nil {
@@ -261,6 +328,18 @@ func (r *Reporter) report(ctx context.Context) {
 		return
 	}
 
+	if r.File != "" {
+		r.L.Info("Writing telemetry report.", zap.String("file", r.File), zap.Any("data", request))
+
+		if err = appendReport(r.File, b); err != nil {
+			r.L.Error("Failed to write telemetry report.", zap.Error(err))
+		}
+
+		return
+	}
+
+	r.L.Info("Reporting telemetry.", zap.String("url", r.URL), zap.Any("data", request))
+
 	reqCtx, reqCancel := context.WithTimeout(ctx, r.ReportTimeout)
 	defer reqCancel()
 
@@ -310,3 +389,18 @@ func (r *Reporter) report(ctx context.Context) {
 		return
 	}
 }
+
+// appendReport appends a single report line to the file, creating it if needed.
+func appendReport(file string, b []byte) error {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(append(b, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
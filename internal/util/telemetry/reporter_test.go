@@ -18,6 +18,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -225,3 +228,100 @@ func TestReporterReport(t *testing.T) {
 		assert.Empty(t, s.LatestVersion)
 	})
 }
+
+func TestMakeRequestLevels(t *testing.T) {
+	t.Parallel()
+
+	m := connmetrics.NewListenerMetrics().ConnMetrics
+	m.Responses.WithLabelValues("OP_MSG", "find", "unknown", "ok").Add(3)
+	m.Responses.WithLabelValues("OP_MSG", "aggregate", "unknown", "ok").Add(2)
+	m.Responses.WithLabelValues("OP_MSG", "aggregate", "$bucket (stage)", "NotImplemented").Inc()
+
+	for name, tc := range map[string]struct {
+		level          Level
+		commandMetrics map[string]map[string]map[string]map[string]int
+		commandCounts  map[string]map[string]int
+	}{
+		"Full": {
+			level: LevelFull,
+			commandMetrics: map[string]map[string]map[string]map[string]int{
+				"OP_MSG": {
+					"find": {"unknown": {"ok": 3}},
+					"aggregate": {
+						"unknown":         {"ok": 2},
+						"$bucket (stage)": {"NotImplemented": 1, "ok": 0},
+					},
+				},
+			},
+		},
+		"Commands": {
+			level: LevelCommands,
+			commandCounts: map[string]map[string]int{
+				"OP_MSG": {"find": 3, "aggregate": 3},
+			},
+		},
+		"Errors": {
+			level: LevelErrors,
+			commandMetrics: map[string]map[string]map[string]map[string]int{
+				"OP_MSG": {
+					"aggregate": {"$bucket (stage)": {"NotImplemented": 1}},
+				},
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := makeRequest(&state.State{TelemetryLevel: string(tc.level)}, m)
+			assert.Equal(t, tc.level, req.Level)
+			assert.Equal(t, tc.commandMetrics, req.CommandMetrics)
+			assert.Equal(t, tc.commandCounts, req.CommandCounts)
+		})
+	}
+}
+
+func TestReporterReportFile(t *testing.T) {
+	t.Parallel()
+
+	var serverCalled int
+	bs := beaconServer(t, &serverCalled, new(response))
+
+	sp, err := state.NewProvider("")
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "telemetry.jsonl")
+
+	opts := NewReporterOpts{
+		URL:           bs.URL,
+		F:             &Flag{v: pointer.ToBool(true)},
+		ConnMetrics:   connmetrics.NewListenerMetrics().ConnMetrics,
+		P:             sp,
+		L:             zap.L(),
+		ReportTimeout: 1 * time.Minute,
+		Level:         LevelErrors,
+		File:          file,
+	}
+
+	r, err := NewReporter(&opts)
+	require.NoError(t, err)
+
+	r.report(testutil.Ctx(t))
+	r.report(testutil.Ctx(t))
+	assert.Equal(t, 0, serverCalled)
+
+	b, err := os.ReadFile(file)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	require.Len(t, lines, 2)
+
+	var req request
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &req))
+	assert.Equal(t, LevelErrors, req.Level)
+	assert.Equal(t, sp.Get().UUID, req.UUID)
+
+	_, err = NewReporter(&NewReporterOpts{F: new(Flag), P: sp, L: zap.L(), Level: "none"})
+	assert.EqualError(t, err, `unknown telemetry level "none"`)
+}
@@ -55,6 +55,24 @@ func (s *Flag) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// Level represents telemetry reporting level – which categories of data are reported.
+type Level string
+
+// Telemetry reporting levels.
+const (
+	// LevelFull reports all data, including command arguments and results.
+	LevelFull = Level("full")
+
+	// LevelCommands reports only names of executed commands with their counts.
+	LevelCommands = Level("commands")
+
+	// LevelErrors reports only failed commands with their arguments and errors.
+	LevelErrors = Level("errors")
+)
+
+// AllLevels contains all telemetry reporting levels; the first one is the default.
+var AllLevels = []string{string(LevelFull), string(LevelCommands), string(LevelErrors)}
+
 // initialState returns initial telemetry state based on:
 //   - Kong flag value (including `FERRETDB_TELEMETRY` environment variable);
 //   - common DO_NOT_TRACK environment variable;
@@ -67,6 +67,22 @@ func AssertEqualSlices[T types.Type](tb testtb.TB, expected, actual []T) bool {
 	return assert.Fail(tb, msg)
 }
 
+// AssertEqualExtJSON asserts that BSON value is equal to the value
+// represented by canonical or relaxed Extended JSON.
+func AssertEqualExtJSON[T types.Type](tb testtb.TB, expectedJSON string, actual T) bool {
+	tb.Helper()
+
+	v, err := types.UnmarshalExtJSON([]byte(expectedJSON))
+	require.NoError(tb, err)
+
+	expected, ok := v.(T)
+	if !ok {
+		return assert.Fail(tb, fmt.Sprintf("Not equal: \nexpected: %s (%T)\nactual  : %T", expectedJSON, v, actual))
+	}
+
+	return AssertEqual(tb, expected, actual)
+}
+
 // AssertNotEqual asserts that two BSON values are not equal.
 func AssertNotEqual[T types.Type](tb testtb.TB, expected, actual T) bool {
 	tb.Helper()
@@ -318,6 +334,13 @@ func equalScalars(tb testtb.TB, v1, v2 any) bool {
 		}
 		return s1 == s2
 
+	case types.Decimal128:
+		s2, ok := v2.(types.Decimal128)
+		if !ok {
+			return false
+		}
+		return s1 == s2
+
 	default:
 		tb.Fatalf("unhandled types %T, %T", v1, v2)
 		panic("not reached")
@@ -65,3 +65,18 @@ func TestEqual(t *testing.T) {
 		time.Date(2022, time.March, 11, 8, 8, 42, 123456789, time.UTC),
 	)
 }
+
+func TestEqualExtJSON(t *testing.T) {
+	t.Parallel()
+
+	AssertEqualExtJSON(
+		t,
+		`{"foo":"bar","baz":{"$numberLong":"42"},"v":[1,{"$numberDouble":"-0.0"}]}`,
+		must.NotFail(types.NewDocument(
+			"foo", "bar",
+			"baz", int64(42),
+			"v", must.NotFail(types.NewArray(int32(1), math.Copysign(0, -1))),
+		)),
+	)
+	AssertEqualExtJSON(t, `{"$date":"2022-03-11T05:08:42.123Z"}`, time.Date(2022, time.March, 11, 5, 8, 42, 123000000, time.UTC))
+}
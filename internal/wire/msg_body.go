@@ -56,16 +56,40 @@ var ErrZeroRead = errors.New("zero bytes read")
 //
 // Error is (possibly wrapped) ErrZeroRead if zero bytes was read.
 func ReadMessage(r *bufio.Reader) (*MsgHeader, MsgBody, error) {
+	return ReadValidatedMessage(r, ValidationOff)
+}
+
+// ReadValidatedMessage is [ReadMessage] that also validates BSON documents
+// of OP_MSG and OP_QUERY messages with the given level.
+//
+// Validation failures are returned as (possibly wrapped) [*ValidationError] together with the header.
+func ReadValidatedMessage(r *bufio.Reader, level ValidationLevel) (*MsgHeader, MsgBody, error) {
+	return readMessage(r, level, func(n int) []byte { return make([]byte, n) })
+}
+
+// readMessage is [ReadValidatedMessage] that uses the given function to get buffers for the message.
+//
+// Returned message's raw documents are slices of those buffers.
+func readMessage(r *bufio.Reader, level ValidationLevel, getBuffer func(int) []byte) (*MsgHeader, MsgBody, error) {
 	var header MsgHeader
 	if err := header.readFrom(r); err != nil {
 		return nil, nil, lazyerrors.Error(err)
 	}
 
-	b := make([]byte, header.MessageLength-MsgHeaderLen)
+	b := getBuffer(int(header.MessageLength - MsgHeaderLen))
 	if n, err := io.ReadFull(r, b); err != nil {
 		return nil, nil, lazyerrors.Errorf("expected %d, read %d: %w", len(b), n, err)
 	}
 
+	if header.OpCode == OpCodeCompressed {
+		h, body, err := decompress(&header, b, getBuffer)
+		if err != nil {
+			return nil, nil, lazyerrors.Error(err)
+		}
+
+		header, b = *h, body
+	}
+
 	switch header.OpCode {
 	case OpCodeReply: // not sent by clients, but we should be able to read replies from a proxy
 		var reply OpReply
@@ -81,7 +105,7 @@ func ReadMessage(r *bufio.Reader) (*MsgHeader, MsgBody, error) {
 		}
 
 		var msg OpMsg
-		if err := msg.UnmarshalBinaryNocopy(b); err != nil {
+		if err := msg.unmarshalBinaryNocopy(b, level); err != nil {
 			return &header, nil, lazyerrors.Error(err)
 		}
 
@@ -89,8 +113,8 @@ func ReadMessage(r *bufio.Reader) (*MsgHeader, MsgBody, error) {
 
 	case OpCodeQuery:
 		var query OpQuery
-		if err := query.UnmarshalBinaryNocopy(b); err != nil {
-			return nil, nil, lazyerrors.Error(err)
+		if err := query.unmarshalBinaryNocopy(b, level); err != nil {
+			return &header, nil, lazyerrors.Error(err)
 		}
 
 		return &header, &query, nil
@@ -107,7 +131,7 @@ func ReadMessage(r *bufio.Reader) (*MsgHeader, MsgBody, error) {
 		fallthrough
 	case OpCodeKillCursors:
 		fallthrough
-	case OpCodeCompressed:
+	case OpCodeCompressed: // nested OP_COMPRESSED messages are rejected above
 		return nil, nil, lazyerrors.Errorf("unhandled opcode %s", header.OpCode)
 
 	default:
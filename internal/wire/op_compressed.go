@@ -0,0 +1,127 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// Compressor represents compressor ID used in OP_COMPRESSED messages.
+type Compressor uint8
+
+const (
+	// CompressorNoop is used for messages that are not actually compressed.
+	CompressorNoop = Compressor(0)
+
+	// CompressorSnappy is not supported yet.
+	CompressorSnappy = Compressor(1)
+
+	// CompressorZlib is zlib compressor.
+	CompressorZlib = Compressor(2)
+
+	// CompressorZstd is not supported yet.
+	CompressorZstd = Compressor(3)
+)
+
+// opCompressedHeaderLen is the length of OP_COMPRESSED fields before the compressed message:
+// original opcode, uncompressed size, and compressor ID.
+const opCompressedHeaderLen = 9
+
+// decompress returns the header and the body of the original message
+// for the given OP_COMPRESSED message header and body.
+//
+// The size of the original message is limited by MaxMsgLen,
+// so a small compressed message can't make us allocate much more memory.
+func decompress(header *MsgHeader, b []byte, getBuffer func(int) []byte) (*MsgHeader, []byte, error) {
+	if len(b) < opCompressedHeaderLen {
+		return nil, nil, lazyerrors.Errorf("OP_COMPRESSED message is too short: %d bytes", len(b))
+	}
+
+	opCode := OpCode(binary.LittleEndian.Uint32(b[0:4]))
+	size := int32(binary.LittleEndian.Uint32(b[4:8]))
+	compressor := Compressor(b[8])
+	data := b[opCompressedHeaderLen:]
+
+	if opCode == OpCodeCompressed {
+		return nil, nil, lazyerrors.New("nested OP_COMPRESSED message")
+	}
+
+	if size < 0 || size > MaxMsgLen-MsgHeaderLen {
+		return nil, nil, lazyerrors.Errorf("invalid uncompressed size %d", size)
+	}
+
+	var body []byte
+
+	switch compressor {
+	case CompressorNoop:
+		if len(data) != int(size) {
+			return nil, nil, lazyerrors.Errorf("expected %d bytes, got %d", size, len(data))
+		}
+
+		body = data
+
+	case CompressorZlib:
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, lazyerrors.Error(err)
+		}
+
+		defer r.Close()
+
+		body = getBuffer(int(size))
+
+		n, err := io.ReadFull(r, body)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			return nil, nil, lazyerrors.Error(err)
+		}
+
+		if n == int(size) {
+			// read one more byte to check that there is no extra data and to verify the checksum
+			var extra [1]byte
+
+			var m int
+			if m, err = io.ReadFull(r, extra[:]); err != nil && !errors.Is(err, io.EOF) {
+				return nil, nil, lazyerrors.Error(err)
+			}
+
+			n += m
+		}
+
+		if n != int(size) {
+			return nil, nil, lazyerrors.Errorf("expected %d bytes, got %d", size, n)
+		}
+
+	case CompressorSnappy, CompressorZstd:
+		return nil, nil, lazyerrors.Errorf("unsupported compressor %d", compressor)
+
+	default:
+		return nil, nil, lazyerrors.Errorf("unexpected compressor %d", compressor)
+	}
+
+	res := &MsgHeader{
+		MessageLength: int32(MsgHeaderLen + len(body)),
+		RequestID:     header.RequestID,
+		ResponseTo:    header.ResponseTo,
+		OpCode:        opCode,
+	}
+
+	return res, body, nil
+}
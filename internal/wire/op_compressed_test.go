@@ -0,0 +1,203 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil/testtb"
+)
+
+// compressMessage returns OP_COMPRESSED message for the given message (header and body).
+//
+// Original opcode and uncompressed size are taken from the message header;
+// they could be overridden by non-zero values.
+func compressMessage(tb testtb.TB, b []byte, compressor Compressor, opCode OpCode, size int32) []byte {
+	tb.Helper()
+
+	require.GreaterOrEqual(tb, len(b), MsgHeaderLen)
+
+	header, body := b[:MsgHeaderLen], b[MsgHeaderLen:]
+
+	if opCode == 0 {
+		opCode = OpCode(binary.LittleEndian.Uint32(header[12:16]))
+	}
+
+	if size == 0 {
+		size = int32(len(body))
+	}
+
+	var data []byte
+
+	switch compressor {
+	case CompressorZlib:
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		must.NotFail(w.Write(body))
+		require.NoError(tb, w.Close())
+		data = buf.Bytes()
+	default:
+		data = body
+	}
+
+	res := make([]byte, MsgHeaderLen+opCompressedHeaderLen+len(data))
+	copy(res, header)
+	binary.LittleEndian.PutUint32(res[0:4], uint32(len(res)))
+	binary.LittleEndian.PutUint32(res[12:16], uint32(OpCodeCompressed))
+	binary.LittleEndian.PutUint32(res[MsgHeaderLen:MsgHeaderLen+4], uint32(opCode))
+	binary.LittleEndian.PutUint32(res[MsgHeaderLen+4:MsgHeaderLen+8], uint32(size))
+	res[MsgHeaderLen+8] = byte(compressor)
+	copy(res[MsgHeaderLen+opCompressedHeaderLen:], data)
+
+	return res
+}
+
+// truncateMessage returns the first n bytes of the message with the fixed message length.
+func truncateMessage(b []byte, n int) []byte {
+	res := bytes.Clone(b[:n])
+	binary.LittleEndian.PutUint32(res[0:4], uint32(n))
+
+	return res
+}
+
+// setCompressor returns OP_COMPRESSED message with the compressor ID replaced without recompressing data.
+func setCompressor(b []byte, compressor Compressor) []byte {
+	res := bytes.Clone(b)
+	res[MsgHeaderLen+8] = byte(compressor)
+
+	return res
+}
+
+// compressedTestCases returns compressed variants of given test cases without errors.
+func compressedTestCases(tb testtb.TB, testCases []testCase) []testCase {
+	tb.Helper()
+
+	var res []testCase
+
+	for _, tc := range testCases {
+		if tc.err != "" || tc.msgHeader == nil {
+			continue
+		}
+
+		tc.setExpectedB(tb)
+
+		for _, compressor := range []Compressor{CompressorNoop, CompressorZlib} {
+			res = append(res, testCase{
+				name:      fmt.Sprintf("%s/%d", tc.name, compressor),
+				expectedB: compressMessage(tb, tc.expectedB, compressor, 0, 0),
+				msgHeader: tc.msgHeader,
+				msgBody:   tc.msgBody,
+			})
+		}
+	}
+
+	return res
+}
+
+func TestCompressed(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range compressedTestCases(t, slices.Concat(msgTestCases, queryTestCases)) {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			br := bytes.NewReader(tc.expectedB)
+			bufr := bufio.NewReader(br)
+
+			msgHeader, msgBody, err := ReadMessage(bufr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.msgHeader, msgHeader)
+			assert.Equal(t, tc.msgBody, msgBody)
+			assert.Zero(t, br.Len(), "not all br bytes were consumed")
+			assert.Zero(t, bufr.Buffered(), "not all bufr bytes were consumed")
+		})
+	}
+}
+
+func TestCompressedErrors(t *testing.T) {
+	t.Parallel()
+
+	tc := msgTestCases[0]
+	tc.setExpectedB(t)
+	b := tc.expectedB
+
+	for name, tc := range map[string]struct {
+		b   []byte
+		err string
+	}{
+		"TooShort": {
+			b:   truncateMessage(compressMessage(t, b, CompressorNoop, 0, 0), MsgHeaderLen+4),
+			err: "OP_COMPRESSED message is too short: 4 bytes",
+		},
+		"Nested": {
+			b:   compressMessage(t, b, CompressorNoop, OpCodeCompressed, 0),
+			err: "nested OP_COMPRESSED message",
+		},
+		"NegativeSize": {
+			b:   compressMessage(t, b, CompressorNoop, 0, -1),
+			err: "invalid uncompressed size -1",
+		},
+		"TooLarge": {
+			b:   compressMessage(t, b, CompressorZlib, 0, MaxMsgLen),
+			err: "invalid uncompressed size 48000000",
+		},
+		"NoopSize": {
+			b:   compressMessage(t, b, CompressorNoop, 0, int32(len(b))),
+			err: fmt.Sprintf("expected %d bytes, got %d", len(b), len(b)-MsgHeaderLen),
+		},
+		"ZlibSmaller": {
+			b:   compressMessage(t, b, CompressorZlib, 0, int32(len(b))),
+			err: fmt.Sprintf("expected %d bytes, got %d", len(b), len(b)-MsgHeaderLen),
+		},
+		"ZlibLarger": {
+			b:   compressMessage(t, b, CompressorZlib, 0, 1),
+			err: "expected 1 bytes, got 2",
+		},
+		"ZlibInvalid": {
+			b:   setCompressor(compressMessage(t, b, CompressorNoop, 0, 0), CompressorZlib),
+			err: "zlib: invalid header",
+		},
+		"Snappy": {
+			b:   compressMessage(t, b, CompressorSnappy, 0, 0),
+			err: "unsupported compressor 1",
+		},
+		"Unexpected": {
+			b:   compressMessage(t, b, Compressor(42), 0, 0),
+			err: "unexpected compressor 42",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, _, err := ReadMessage(bufio.NewReader(bytes.NewReader(tc.b)))
+			require.Error(t, err)
+			assert.Equal(t, tc.err, lastErr(err).Error())
+		})
+	}
+}
+
+func FuzzCompressed(f *testing.F) {
+	fuzzMessages(f, compressedTestCases(f, slices.Concat(msgTestCases, queryTestCases)))
+}
@@ -17,6 +17,7 @@ package wire
 import (
 	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 
 	"github.com/FerretDB/FerretDB/internal/bson"
 	"github.com/FerretDB/FerretDB/internal/types"
@@ -211,6 +212,11 @@ func (msg *OpMsg) check() error {
 
 // UnmarshalBinaryNocopy implements [MsgBody].
 func (msg *OpMsg) UnmarshalBinaryNocopy(b []byte) error {
+	return msg.unmarshalBinaryNocopy(b, ValidationOff)
+}
+
+// unmarshalBinaryNocopy is [OpMsg.UnmarshalBinaryNocopy] that also validates documents with the given level.
+func (msg *OpMsg) unmarshalBinaryNocopy(b []byte, level ValidationLevel) error {
 	if len(b) < 6 {
 		return lazyerrors.Errorf("len=%d", len(b))
 	}
@@ -306,6 +312,12 @@ func (msg *OpMsg) UnmarshalBinaryNocopy(b []byte) error {
 		return lazyerrors.Error(err)
 	}
 
+	for _, section := range msg.sections {
+		if err := validateDocuments(level, section.documents...); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
 	if debugbuild.Enabled {
 		if err := msg.check(); err != nil {
 			return lazyerrors.Error(err)
@@ -339,7 +351,22 @@ func (msg *OpMsg) MarshalBinary() ([]byte, error) {
 		}
 	}
 
-	b := make([]byte, 4, 16)
+	// calculate the size first to allocate the buffer once and copy documents only once
+	size := flagsSize
+
+	for _, section := range msg.sections {
+		size += 1 + sectionSize(&section)
+
+		if section.kind == 1 {
+			size += 4
+		}
+	}
+
+	if msg.Flags.FlagSet(OpMsgChecksumPresent) {
+		size += crc32.Size
+	}
+
+	b := make([]byte, flagsSize, size)
 
 	binary.LittleEndian.PutUint32(b, uint32(msg.Flags))
 
@@ -351,18 +378,16 @@ func (msg *OpMsg) MarshalBinary() ([]byte, error) {
 			b = append(b, section.documents[0]...)
 
 		case 1:
-			sec := make([]byte, bson.SizeCString(section.identifier))
-			bson.EncodeCString(sec, section.identifier)
+			b = binary.LittleEndian.AppendUint32(b, uint32(sectionSize(&section)+4))
+
+			n := len(b)
+			b = b[:n+bson.SizeCString(section.identifier)]
+			bson.EncodeCString(b[n:], section.identifier)
 
 			for _, doc := range section.documents {
-				sec = append(sec, doc...)
+				b = append(b, doc...)
 			}
 
-			var size [4]byte
-			binary.LittleEndian.PutUint32(size[:], uint32(len(sec)+4))
-			b = append(b, size[:]...)
-			b = append(b, sec...)
-
 		default:
 			return nil, lazyerrors.Errorf("kind is %d", section.kind)
 		}
@@ -379,6 +404,21 @@ func (msg *OpMsg) MarshalBinary() ([]byte, error) {
 	return b, nil
 }
 
+// sectionSize returns the size of the encoded section's identifier (for kind 1) and documents.
+func sectionSize(section *opMsgSection) int {
+	var res int
+
+	if section.kind == 1 {
+		res = bson.SizeCString(section.identifier)
+	}
+
+	for _, doc := range section.documents {
+		res += len(doc)
+	}
+
+	return res
+}
+
 // logMessage returns a string representation for logging.
 func (msg *OpMsg) logMessage(logFunc func(v any) string) string {
 	if msg == nil {
@@ -68,6 +68,11 @@ func (query *OpQuery) check() error {
 
 // UnmarshalBinaryNocopy implements [MsgBody].
 func (query *OpQuery) UnmarshalBinaryNocopy(b []byte) error {
+	return query.unmarshalBinaryNocopy(b, ValidationOff)
+}
+
+// unmarshalBinaryNocopy is [OpQuery.UnmarshalBinaryNocopy] that also validates documents with the given level.
+func (query *OpQuery) unmarshalBinaryNocopy(b []byte, level ValidationLevel) error {
 	if len(b) < 4 {
 		return lazyerrors.Errorf("len=%d", len(b))
 	}
@@ -108,6 +113,16 @@ func (query *OpQuery) UnmarshalBinaryNocopy(b []byte) error {
 		query.returnFieldsSelector = b[selectorLow:]
 	}
 
+	if err = validateDocuments(level, query.query); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if query.returnFieldsSelector != nil {
+		if err = validateDocuments(level, query.returnFieldsSelector); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
 	if debugbuild.Enabled {
 		if err := query.check(); err != nil {
 			return lazyerrors.Error(err)
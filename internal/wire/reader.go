@@ -0,0 +1,126 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"math/bits"
+	"sync"
+)
+
+const (
+	// minBufferShift is the binary logarithm of the smallest pooled buffer capacity (4 KiB).
+	minBufferShift = 12
+
+	// maxBufferShift is the binary logarithm of the largest pooled buffer capacity (64 MiB);
+	// it is large enough for MaxMsgLen.
+	maxBufferShift = 26
+)
+
+// bufferPools contains pools of message buffers with capacities of powers of two,
+// from 1<<minBufferShift to 1<<maxBufferShift bytes.
+var bufferPools [maxBufferShift - minBufferShift + 1]sync.Pool
+
+// bufferClass returns the index of the pool for buffers of the given size, or -1 if it is too large.
+func bufferClass(n int) int {
+	shift := max(bits.Len(uint(max(n, 1)-1)), minBufferShift)
+	if shift > maxBufferShift {
+		return -1
+	}
+
+	return shift - minBufferShift
+}
+
+// getBuffer returns a buffer of the given length from the pool.
+//
+// Its content is not zeroed.
+func getBuffer(n int) []byte {
+	i := bufferClass(n)
+	if i < 0 {
+		return make([]byte, n)
+	}
+
+	if p, _ := bufferPools[i].Get().(*[]byte); p != nil {
+		return (*p)[:n]
+	}
+
+	return make([]byte, n, 1<<(i+minBufferShift))
+}
+
+// putBuffer returns a buffer obtained by getBuffer to the pool.
+func putBuffer(b []byte) {
+	i := bufferClass(cap(b))
+	if i < 0 || cap(b) != 1<<(i+minBufferShift) {
+		return
+	}
+
+	b = b[:0]
+	bufferPools[i].Put(&b)
+}
+
+// Reader reads wire protocol messages into pooled buffers.
+//
+// Unlike [ReadValidatedMessage], it does not allocate a new buffer for every message.
+// Raw documents of returned messages are slices of that buffer, not copies;
+// for that reason, they are valid only until the next Read or Close call.
+// Decoded and converted documents (like ones returned by [OpMsg.Document]) do not reference the buffer
+// and could be used after that.
+//
+// Reader should be used by a single goroutine that handles messages one by one.
+type Reader struct {
+	r     *bufio.Reader
+	bufs  [][]byte // buffers of the last read message
+	level ValidationLevel
+}
+
+// NewReader creates a new Reader that validates BSON documents with the given level.
+func NewReader(r *bufio.Reader, level ValidationLevel) *Reader {
+	return &Reader{
+		r:     r,
+		level: level,
+	}
+}
+
+// Read is [ReadValidatedMessage] that reads the message into a pooled buffer.
+//
+// It releases the buffer of the previously read message.
+func (r *Reader) Read() (*MsgHeader, MsgBody, error) {
+	r.release()
+
+	return readMessage(r.r, r.level, r.getBuffer)
+}
+
+// Close releases the buffer of the last read message.
+func (r *Reader) Close() {
+	r.release()
+}
+
+// getBuffer returns a pooled buffer and remembers it to release it later.
+func (r *Reader) getBuffer(n int) []byte {
+	b := getBuffer(n)
+	r.bufs = append(r.bufs, b)
+
+	return b
+}
+
+// release returns all remembered buffers to the pool.
+func (r *Reader) release() {
+	for i, b := range r.bufs {
+		putBuffer(b)
+		r.bufs[i] = nil
+	}
+
+	r.bufs = r.bufs[:0]
+}
@@ -0,0 +1,172 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// makeInsertMessage returns an encoded OP_MSG insert message with the given number of documents
+// of about 1 KiB each in a section of kind 1.
+func makeInsertMessage(tb testing.TB, n int) []byte {
+	tb.Helper()
+
+	docs := make([]bson.RawDocument, n)
+	for i := range docs {
+		docs[i] = makeRawDocument("_id", int32(i), "v", strings.Repeat("x", 1000))
+	}
+
+	var msg OpMsg
+	require.NoError(tb, msg.SetSections(
+		MakeOpMsgSection(must.NotFail(types.NewDocument("insert", "test", "$db", "test"))),
+		opMsgSection{kind: 1, identifier: "documents", documents: docs},
+	))
+
+	body, err := msg.MarshalBinary()
+	require.NoError(tb, err)
+
+	header := &MsgHeader{
+		MessageLength: int32(MsgHeaderLen + len(body)),
+		RequestID:     1,
+		OpCode:        OpCodeMsg,
+	}
+
+	var buf bytes.Buffer
+	bufw := bufio.NewWriter(&buf)
+	require.NoError(tb, WriteMessage(bufw, header, &msg))
+	require.NoError(tb, bufw.Flush())
+
+	return buf.Bytes()
+}
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	large := makeInsertMessage(t, 100)
+
+	var expected []MsgBody
+	var b []byte
+
+	// interleave small messages with large ones to check that reused buffers do not affect them
+	for _, tc := range slices.Concat(msgTestCases, queryTestCases) {
+		if tc.err != "" || tc.msgHeader == nil {
+			continue
+		}
+
+		tc.setExpectedB(t)
+
+		_, body, err := ReadMessage(bufio.NewReader(bytes.NewReader(tc.expectedB)))
+		require.NoError(t, err)
+
+		expected = append(expected, body)
+
+		b = append(b, tc.expectedB...)
+		b = append(b, large...)
+	}
+
+	r := NewReader(bufio.NewReader(bytes.NewReader(b)), ValidationOff)
+	t.Cleanup(r.Close)
+
+	for _, body := range expected {
+		_, actual, err := r.Read()
+		require.NoError(t, err)
+		assert.Equal(t, body, actual)
+
+		_, actual, err = r.Read()
+		require.NoError(t, err)
+
+		doc, err := actual.(*OpMsg).Document()
+		require.NoError(t, err)
+		assert.Equal(t, 100, must.NotFail(doc.Get("documents")).(*types.Array).Len())
+	}
+
+	_, _, err := r.Read()
+	assert.ErrorIs(t, err, ErrZeroRead)
+}
+
+func TestBufferPool(t *testing.T) {
+	t.Parallel()
+
+	for n, expected := range map[int]int{
+		0:             4096,
+		1:             4096,
+		4096:          4096,
+		4097:          8192,
+		MaxMsgLen:     1 << 26,
+		1<<26 + 1:     1<<26 + 1,
+		1<<26 + 12345: 1<<26 + 12345,
+	} {
+		b := getBuffer(n)
+		assert.Len(t, b, n)
+		assert.Equal(t, expected, cap(b), "n = %d", n)
+		putBuffer(b)
+	}
+}
+
+func BenchmarkReadMessage(b *testing.B) {
+	msg := makeInsertMessage(b, 10_000)
+
+	b.Run("ReadMessage", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msg)))
+
+		for range b.N {
+			_, _, err := ReadMessage(bufio.NewReader(bytes.NewReader(msg)))
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("Reader", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msg)))
+
+		r := NewReader(nil, ValidationOff)
+		b.Cleanup(r.Close)
+
+		for range b.N {
+			r.r = bufio.NewReader(bytes.NewReader(msg))
+
+			_, _, err := r.Read()
+			require.NoError(b, err)
+		}
+	})
+}
+
+func BenchmarkMsgMarshalBinary(b *testing.B) {
+	msg := makeInsertMessage(b, 10_000)
+
+	_, body, err := ReadMessage(bufio.NewReader(bytes.NewReader(msg)))
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(msg)))
+	b.ResetTimer()
+
+	for range b.N {
+		_, err = body.MarshalBinary()
+		require.NoError(b, err)
+	}
+}
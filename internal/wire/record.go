@@ -34,6 +34,9 @@ type Record struct {
 	// those are always set
 	HeaderB []byte
 	BodyB   []byte
+
+	// Path of the .bin file; all records of a single file were received by a single connection
+	Path string
 }
 
 // LoadRecords finds all .bin files recursively, selects up to the limit at random (or all if limit <= 0), and parses them.
@@ -120,6 +123,7 @@ func loadRecordFile(file string) ([]Record, error) {
 			Body:    body,
 			HeaderB: headerB,
 			BodyB:   bodyB,
+			Path:    file,
 		})
 	}
 
@@ -16,12 +16,41 @@ package wire
 
 import (
 	"errors"
+	"fmt"
 	"math"
 
+	"github.com/FerretDB/FerretDB/internal/bson"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
+// ValidationLevel represents the strictness of validation of BSON documents in incoming messages.
+type ValidationLevel string
+
+const (
+	// ValidationOff disables validation;
+	// documents that could not be decoded still make reading fail with non-validation error.
+	ValidationOff ValidationLevel = "off"
+
+	// ValidationBasic checks the structure of documents:
+	// sizes of nested documents, arrays and strings, field types, null terminators, and the nesting depth.
+	ValidationBasic ValidationLevel = "basic"
+
+	// ValidationStrict additionally checks that strings are valid UTF-8
+	// and that documents do not contain duplicate field names.
+	ValidationStrict ValidationLevel = "strict"
+)
+
+// AllValidationLevels includes all validation levels, with the first one being the default.
+var AllValidationLevels = []string{
+	string(ValidationBasic),
+	string(ValidationOff),
+	string(ValidationStrict),
+}
+
+// maxDocumentDepth is the maximum nesting depth of documents and arrays, the same as MongoDB's default.
+const maxDocumentDepth = 200
+
 // ValidationError is used for reporting validation errors.
 type ValidationError struct {
 	err error
@@ -40,6 +69,37 @@ func newValidationError(err error) error {
 	return &ValidationError{err: err}
 }
 
+// Unwrap implements standard error unwrapping interface.
+func (v *ValidationError) Unwrap() error {
+	return v.err
+}
+
+// validateDocuments checks given raw documents with the given level.
+//
+// Returned error wraps [*bson.ValidationError].
+func validateDocuments(level ValidationLevel, docs ...bson.RawDocument) error {
+	var opts *bson.ValidateOpts
+
+	switch level {
+	case ValidationOff:
+		return nil
+	case ValidationBasic:
+		opts = &bson.ValidateOpts{MaxDepth: maxDocumentDepth}
+	case ValidationStrict:
+		opts = &bson.ValidateOpts{MaxDepth: maxDocumentDepth, UTF8: true, DuplicateFields: true}
+	default:
+		panic(fmt.Sprintf("unexpected validation level %q", level))
+	}
+
+	for _, doc := range docs {
+		if err := doc.Validate(opts); err != nil {
+			return newValidationError(err)
+		}
+	}
+
+	return nil
+}
+
 // validateValue checks given value and returns error if not supported value was encountered.
 func validateValue(v any) error {
 	switch v := v.(type) {
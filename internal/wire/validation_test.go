@@ -0,0 +1,113 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// makeOpMsg returns a binary OP_MSG message with a single section of kind 0 with the given document.
+func makeOpMsg(doc bson.RawDocument) []byte {
+	body := append([]byte{0, 0, 0, 0, 0}, doc...) // flags and section kind
+
+	header := &MsgHeader{
+		MessageLength: int32(MsgHeaderLen + len(body)),
+		RequestID:     1,
+		OpCode:        OpCodeMsg,
+	}
+
+	return append(must.NotFail(header.MarshalBinary()), body...)
+}
+
+func TestReadValidatedMessage(t *testing.T) {
+	t.Parallel()
+
+	duplicate := must.NotFail(must.NotFail(bson.NewDocument(
+		"find", "test",
+		"filter", must.NotFail(bson.NewDocument("v", int32(1), "v", int32(2))),
+		"$db", "test",
+	)).Encode())
+
+	nested := must.NotFail(bson.NewDocument("v", int32(42)))
+	for range maxDocumentDepth {
+		nested = must.NotFail(bson.NewDocument("v", nested))
+	}
+
+	deep := must.NotFail(must.NotFail(bson.NewDocument(
+		"find", "test",
+		"filter", nested,
+		"$db", "test",
+	)).Encode())
+
+	for name, tc := range map[string]struct {
+		doc   bson.RawDocument
+		level ValidationLevel
+
+		code bson.ValidationErrorCode // zero if no error is expected
+	}{
+		"DuplicateOff": {
+			doc:   duplicate,
+			level: ValidationOff,
+		},
+		"DuplicateBasic": {
+			doc:   duplicate,
+			level: ValidationBasic,
+		},
+		"DuplicateStrict": {
+			doc:   duplicate,
+			level: ValidationStrict,
+			code:  bson.ValidationErrorNonConformant,
+		},
+		"DepthOff": {
+			doc:   deep,
+			level: ValidationOff,
+		},
+		"DepthBasic": {
+			doc:   deep,
+			level: ValidationBasic,
+			code:  bson.ValidationErrorDepth,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			header, body, err := ReadValidatedMessage(bufio.NewReader(bytes.NewReader(makeOpMsg(tc.doc))), tc.level)
+			require.NotNil(t, header)
+
+			if tc.code == 0 {
+				require.NoError(t, err)
+				require.NotNil(t, body)
+
+				return
+			}
+
+			var validationErr *ValidationError
+			require.True(t, errors.As(err, &validationErr))
+
+			var bsonErr *bson.ValidationError
+			require.True(t, errors.As(validationErr, &bsonErr))
+			assert.Equal(t, tc.code, bsonErr.Code())
+		})
+	}
+}
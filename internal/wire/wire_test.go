@@ -17,6 +17,7 @@ package wire
 import (
 	"bufio"
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"testing"
 	"time"
@@ -196,6 +197,9 @@ func fuzzMessages(f *testing.F, testCases []testCase) {
 		var err error
 		var expectedB []byte
 
+		// OP_COMPRESSED messages are decompressed by ReadMessage and written uncompressed by WriteMessage
+		compressed := len(b) >= MsgHeaderLen && OpCode(binary.LittleEndian.Uint32(b[12:16])) == OpCodeCompressed
+
 		// test ReadMessage
 		{
 			br := bytes.NewReader(b)
@@ -232,7 +236,10 @@ func fuzzMessages(f *testing.F, testCases []testCase) {
 			require.NoError(t, err)
 			err = bufw.Flush()
 			require.NoError(t, err)
-			assert.Equal(t, expectedB, bw.Bytes())
+
+			if !compressed {
+				assert.Equal(t, expectedB, bw.Bytes())
+			}
 		}
 	})
 }
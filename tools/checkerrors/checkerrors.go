@@ -0,0 +1,158 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main contains linter for errors.
+package main
+
+import (
+	"flag"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+// ignoreDirective is a comment that disables checks for the return statement on the same or the next line,
+// or for the whole function if present in its doc comment.
+//
+// It should be followed by the reason, e.g. `//checkerrors:ignore // user-facing configuration error`.
+const ignoreDirective = "//checkerrors:ignore"
+
+// replacements contains functions that lose error provenance and their lazyerrors replacements.
+var replacements = map[string]string{
+	"errors.New": "lazyerrors.New",
+	"fmt.Errorf": "lazyerrors.Errorf",
+}
+
+// analyzer represents the checkerrors analyzer.
+var analyzer = &analysis.Analyzer{
+	Name:  "checkerrors",
+	Doc:   "check that returned errors are created with lazyerrors",
+	Run:   run,
+	Flags: *flag.NewFlagSet("", flag.ExitOnError),
+}
+
+// init initializes the analyzer flags.
+func init() {
+	analyzer.Flags.String(
+		"packages",
+		"github.com/FerretDB/FerretDB/internal/handler,github.com/FerretDB/FerretDB/internal/backends",
+		"comma-separated list of checked package path prefixes; empty value checks all packages",
+	)
+}
+
+// main runs the analyzer.
+func main() {
+	singlechecker.Main(analyzer)
+}
+
+// run analyses return statements.
+func run(pass *analysis.Pass) (any, error) {
+	if !checkedPackage(pass) {
+		return nil, nil
+	}
+
+	for _, f := range pass.Files {
+		if strings.HasSuffix(pass.Fset.File(f.Pos()).Name(), "_test.go") {
+			continue
+		}
+
+		ignored := map[int]struct{}{}
+
+		for _, cg := range f.Comments {
+			for _, c := range cg.List {
+				if strings.HasPrefix(c.Text, ignoreDirective) {
+					ignored[pass.Fset.Position(c.Pos()).Line] = struct{}{}
+				}
+			}
+		}
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				if n.Doc == nil {
+					return true
+				}
+
+				for _, c := range n.Doc.List {
+					if strings.HasPrefix(c.Text, ignoreDirective) {
+						return false
+					}
+				}
+
+			case *ast.ReturnStmt:
+				line := pass.Fset.Position(n.Pos()).Line
+
+				if _, ok := ignored[line]; ok {
+					return true
+				}
+
+				if _, ok := ignored[line-1]; ok {
+					return true
+				}
+
+				for _, res := range n.Results {
+					name := calledFunc(pass, res)
+
+					if r, ok := replacements[name]; ok {
+						pass.Reportf(res.Pos(), "%s should be used instead of %s for returned errors", r, name)
+					}
+				}
+			}
+
+			return true
+		})
+	}
+
+	return nil, nil
+}
+
+// checkedPackage returns true if the package should be checked.
+func checkedPackage(pass *analysis.Pass) bool {
+	packages := pass.Analyzer.Flags.Lookup("packages").Value.String()
+	if packages == "" {
+		return true
+	}
+
+	for _, prefix := range strings.Split(packages, ",") {
+		if path := pass.Pkg.Path(); path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// calledFunc returns the package-qualified name of the function called by the given expression
+// (like `fmt.Errorf`), or an empty string.
+func calledFunc(pass *analysis.Pass, expr ast.Expr) string {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return ""
+	}
+
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+
+	f, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok || f.Pkg() == nil {
+		return ""
+	}
+
+	return f.Pkg().Path() + "." + f.Name()
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testdata provides vet tool test data.
+package testdata
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errSentinel is fine.
+var errSentinel = errors.New("sentinel")
+
+func testErrorf() error {
+	return fmt.Errorf("error: %d", 42) // want "lazyerrors.Errorf should be used instead of fmt.Errorf for returned errors"
+}
+
+func testNew() (int, error) {
+	return 0, errors.New("error") // want "lazyerrors.New should be used instead of errors.New for returned errors"
+}
+
+func testMultiline() error {
+	return fmt.Errorf( // want "lazyerrors.Errorf should be used instead of fmt.Errorf for returned errors"
+		"error: %d",
+		42,
+	)
+}
+
+func testClosure() func() error {
+	return func() error {
+		return errors.New("error") // want "lazyerrors.New should be used instead of errors.New for returned errors"
+	}
+}
+
+func testCorrect() error {
+	if err := fmt.Errorf("error"); err != nil {
+		return wrap(err)
+	}
+
+	return errSentinel
+}
+
+func testIgnoreLine() error {
+	return fmt.Errorf("user-facing error") //checkerrors:ignore // for testing
+}
+
+func testIgnorePreviousLine() error {
+	//checkerrors:ignore // for testing
+	return fmt.Errorf(
+		"user-facing error: %d",
+		42,
+	)
+}
+
+func testIgnoreOtherLine() error {
+	//checkerrors:ignore // for testing
+
+	return fmt.Errorf("error") // want "lazyerrors.Errorf should be used instead of fmt.Errorf for returned errors"
+}
+
+// testIgnoreFunc checks that the whole function could be ignored.
+//
+//checkerrors:ignore // for testing
+func testIgnoreFunc() error {
+	return errors.New("user-facing error")
+}
+
+func wrap(err error) error {
+	return err
+}
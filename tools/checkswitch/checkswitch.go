@@ -16,7 +16,6 @@
 package main
 
 import (
-	"fmt"
 	"go/ast"
 
 	"golang.org/x/tools/go/analysis"
@@ -26,9 +25,11 @@ import (
 // orderTypes is the preferred order of types in the switch.
 var orderTypes = map[string]int{
 	"Document":     1,
+	"RawDocument":  1,
 	"documentType": 1,
 
 	"Array":     2,
+	"RawArray":  2,
 	"arrayType": 2,
 
 	"float64":    3,
@@ -80,71 +81,54 @@ func main() {
 // run is the function to be called by the driver to execute analysis on a single package.
 //
 // It analyzes the presence of types in 'case' in ascending order of indexes 'orderTypes'.
+// Cases are ordered by their first types; multiple types of a single case should be ordered too.
+// Types that are not present in 'orderTypes' are ignored.
 func run(pass *analysis.Pass) (any, error) {
 	for _, file := range pass.Files {
 		ast.Inspect(file, func(n ast.Node) bool {
+			ts, ok := n.(*ast.TypeSwitchStmt)
+			if !ok {
+				return true
+			}
+
 			var idx int
 			var lastName string
-			switch n := n.(type) {
-			case *ast.TypeSwitchStmt:
-				var name string
-				for _, el := range n.Body.List {
-					if len(el.(*ast.CaseClause).List) < 1 {
-						continue
-					}
 
-					firstTypeCase := el.(*ast.CaseClause).List[0]
-					switch firstTypeCase := firstTypeCase.(type) {
-					case *ast.StarExpr:
-						if sexp, ok := firstTypeCase.X.(*ast.SelectorExpr); ok {
-							name = sexp.Sel.Name
-						}
-						if sexp, ok := firstTypeCase.X.(*ast.Ident); ok {
-							name = sexp.Name
-						}
-
-					case *ast.SelectorExpr:
-						name = firstTypeCase.Sel.Name
-
-					case *ast.Ident:
-						name = firstTypeCase.Name
+			for _, stmt := range ts.Body.List {
+				cc := stmt.(*ast.CaseClause)
+
+				// skip default
+				if len(cc.List) == 0 {
+					continue
+				}
+
+				name := typeName(cc.List[0])
+				if i, ok := orderTypes[name]; ok {
+					if i < idx {
+						pass.Reportf(ts.Pos(), "%s should go before %s in the switch", name, lastName)
 					}
 
-					idxSl, ok := orderTypes[name]
-					if ok && (idxSl < idx) {
-						pass.Reportf(n.Pos(), "%s should go before %s in the switch", name, lastName)
+					idx, lastName = i, name
+				}
+
+				// handling with multiple types,
+				// e.g. 'case int32, int64'
+				var subidx int
+				var sublastName string
+
+				for _, expr := range cc.List {
+					name = typeName(expr)
+
+					i, ok := orderTypes[name]
+					if !ok {
+						continue
 					}
-					idx, lastName = idxSl, name
-
-					// handling with multiple types,
-					// e.g. 'case int32, int64'
-					if len(el.(*ast.CaseClause).List) > 1 {
-						subidx, sublastName := idx, lastName
-						for i := 0; i < len(el.(*ast.CaseClause).List); i++ {
-							cs := el.(*ast.CaseClause).List[i]
-							switch cs := cs.(type) {
-							case *ast.StarExpr:
-								if sexp, ok := cs.X.(*ast.SelectorExpr); ok {
-									name = sexp.Sel.Name
-								}
-								if sexp, ok := cs.X.(*ast.Ident); ok {
-									name = sexp.Name
-								}
-
-							case *ast.SelectorExpr:
-								name = fmt.Sprintf("%s.%s", cs.X, cs.Sel.Name)
-
-							case *ast.Ident:
-								name = cs.Name
-							}
-
-							subidxSl, ok := orderTypes[name]
-							if ok && (subidxSl < subidx) {
-								pass.Reportf(n.Pos(), "%s should go before %s in the switch", name, sublastName)
-							}
-							subidx, sublastName = subidxSl, name
-						}
+
+					if i < subidx {
+						pass.Reportf(ts.Pos(), "%s should go before %s in the switch", name, sublastName)
 					}
+
+					subidx, sublastName = i, name
 				}
 			}
 
@@ -154,3 +138,20 @@ func run(pass *analysis.Pass) (any, error) {
 
 	return nil, nil
 }
+
+// typeName returns the name of the type in the case clause without package name and pointer,
+// or an empty string.
+func typeName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	switch expr := expr.(type) {
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	case *ast.Ident:
+		return expr.Name
+	default:
+		return ""
+	}
+}
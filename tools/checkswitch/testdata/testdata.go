@@ -57,3 +57,44 @@ func testIncorrectMultiple(v any) {
 	case float64, int64, int32:
 	}
 }
+
+func testIncorrectMultipleSelectors(v any) {
+	switch v.(type) { // want "Binary should go before ObjectID in the switch"
+	case types.ObjectID, types.Binary:
+	}
+}
+
+func testIncorrectAfterUnexpected(v any) {
+	switch v.(type) { // want "string should go before int32 in the switch"
+	case int32:
+	case int8: // unexpected type does not reset the order
+	case string:
+	}
+}
+
+func testCorrectRaw(v any) {
+	switch v.(type) {
+	case *types.Document, types.RawDocument:
+	case *types.Array, types.RawArray:
+	case int32:
+	default:
+	}
+}
+
+func testIncorrectRaw(v any) {
+	switch v.(type) { // want "RawDocument should go before RawArray in the switch"
+	case types.RawArray:
+	case types.RawDocument:
+	}
+}
+
+func testIncorrectNested(v any) {
+	switch v.(type) {
+	case *types.Document:
+		switch v.(type) { // want "Document should go before Array in the switch"
+		case *types.Array:
+		case *types.Document:
+		}
+	case *types.Array:
+	}
+}
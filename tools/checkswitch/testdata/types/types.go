@@ -17,8 +17,12 @@ package types
 
 type Document struct{}
 
+type RawDocument []byte
+
 type Array struct{}
 
+type RawArray []byte
+
 type Binary struct{}
 
 type ObjectID struct{}
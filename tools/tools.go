@@ -30,6 +30,7 @@ import (
 //go:generate go build -v -o ../bin/ ./checkdocs
 //go:generate go build -v -o ../bin/ ./checkswitch
 //go:generate go build -v -o ../bin/ ./checkcomments
+//go:generate go build -v -o ../bin/ ./checkerrors
 
 //go:generate go build -v -o ../bin/ github.com/go-task/task/v3/cmd/task
 //go:generate go build -v -o ../bin/ github.com/goreleaser/nfpm/v2/cmd/nfpm